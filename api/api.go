@@ -24,15 +24,17 @@ import (
 	"github.com/sirupsen/logrus"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
-	"google.golang.org/grpc/credentials/insecure"
 	"google.golang.org/grpc/keepalive"
 	"google.golang.org/grpc/reflection"
 	grpc_status "google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
 
 	"github.com/featureform/fferr"
 	"github.com/featureform/health"
 	"github.com/featureform/helpers"
 	help "github.com/featureform/helpers"
+	"github.com/featureform/helpers/grpctls"
+	"github.com/featureform/helpers/interceptors"
 	"github.com/featureform/logging"
 	"github.com/featureform/metadata"
 	pb "github.com/featureform/metadata/proto"
@@ -253,6 +255,54 @@ func (serv *MetadataServer) GetFeatureVariants(stream pb.Api_GetFeatureVariantsS
 	}
 }
 
+func (serv *MetadataServer) GetDefaultVariant(ctx context.Context, req *pb.GetDefaultVariantRequest) (*pb.NameVariant, error) {
+	requestID, ctx, logger := serv.Logger.InitializeRequestID(ctx)
+	logger.Infow("Getting default variant", "name", req.Name, "resource_type", req.ResourceType)
+	req.RequestId = requestID.String()
+	resp, err := serv.meta.GetDefaultVariant(ctx, req)
+	if err != nil {
+		logger.Errorw("Failed to get default variant", "error", err)
+		return nil, err
+	}
+	return resp, nil
+}
+
+func (serv *MetadataServer) ListVariants(ctx context.Context, req *pb.ListVariantsRequest) (*pb.ListVariantsResponse, error) {
+	requestID, ctx, logger := serv.Logger.InitializeRequestID(ctx)
+	logger.Infow("Listing variants", "name", req.Name, "resource_type", req.ResourceType)
+	req.RequestId = requestID.String()
+	resp, err := serv.meta.ListVariants(ctx, req)
+	if err != nil {
+		logger.Errorw("Failed to list variants", "error", err)
+		return nil, err
+	}
+	return resp, nil
+}
+
+func (serv *MetadataServer) AddTags(ctx context.Context, req *pb.UpdateTagsRequest) (*pb.UpdateTagsResponse, error) {
+	requestID, ctx, logger := serv.Logger.InitializeRequestID(ctx)
+	logger.Infow("Adding tags", "resource_id", req.ResourceId, "tags", req.Tags)
+	req.RequestId = requestID.String()
+	resp, err := serv.meta.AddTags(ctx, req)
+	if err != nil {
+		logger.Errorw("Failed to add tags", "error", err)
+		return nil, err
+	}
+	return resp, nil
+}
+
+func (serv *MetadataServer) RemoveTags(ctx context.Context, req *pb.UpdateTagsRequest) (*pb.UpdateTagsResponse, error) {
+	requestID, ctx, logger := serv.Logger.InitializeRequestID(ctx)
+	logger.Infow("Removing tags", "resource_id", req.ResourceId, "tags", req.Tags)
+	req.RequestId = requestID.String()
+	resp, err := serv.meta.RemoveTags(ctx, req)
+	if err != nil {
+		logger.Errorw("Failed to remove tags", "error", err)
+		return nil, err
+	}
+	return resp, nil
+}
+
 func (serv *MetadataServer) GetLabels(stream pb.Api_GetLabelsServer) error {
 	requestID, ctx, logger := serv.Logger.InitializeRequestID(stream.Context())
 	logger.Infow("Getting Labels")
@@ -619,7 +669,7 @@ func (serv *MetadataServer) GetEquivalent(ctx context.Context, req *pb.GetEquiva
 	ctx = logging.AttachRequestID(logging.RequestID(req.RequestId), ctx, serv.Logger)
 	logger := logging.GetLoggerFromContext(ctx)
 
-	preprocessSourceVariant(req)
+	preprocessSourceVariant(req.Variant.GetSourceVariant())
 
 	// Log start of the request
 	logger.Info("Handling GetEquivalent call")
@@ -632,8 +682,7 @@ func (serv *MetadataServer) GetEquivalent(ctx context.Context, req *pb.GetEquiva
 	return resp, nil
 }
 
-func preprocessSourceVariant(req *pb.GetEquivalentRequest) {
-	sv := req.Variant.GetSourceVariant()
+func preprocessSourceVariant(sv *pb.SourceVariant) {
 	if sv == nil {
 		return
 	}
@@ -653,6 +702,21 @@ func preprocessSourceVariant(req *pb.GetEquivalentRequest) {
 	return
 }
 
+func (serv *MetadataServer) Plan(ctx context.Context, req *pb.PlanRequest) (*pb.PlanResponse, error) {
+	requestID, ctx, logger := serv.Logger.InitializeRequestID(ctx)
+	req.RequestId = requestID.String()
+	logger.Infow("Planning resources", "count", len(req.Resources))
+	for _, res := range req.Resources {
+		preprocessSourceVariant(res.GetSourceVariant())
+	}
+	resp, err := serv.meta.Plan(ctx, req)
+	if err != nil {
+		logger.Errorw("Plan failed", "error", err)
+		return nil, err
+	}
+	return resp, nil
+}
+
 func (serv *MetadataServer) Run(ctx context.Context, req *pb.RunRequest) (*pb.Empty, error) {
 	ctx = logging.AttachRequestID(logging.RequestID(req.RequestId), ctx, serv.Logger)
 	logger := logging.GetLoggerFromContext(ctx)
@@ -705,6 +769,8 @@ func (serv *MetadataServer) ListFeatures(listRequest *pb.ListRequest, stream pb.
 		res, err := proxyStream.Recv()
 		if err == io.EOF {
 			logger.Debugw("End of stream reached. Stream request completed")
+			// Paged lists return the next page's token in the trailer.
+			stream.SetTrailer(proxyStream.Trailer())
 			return nil
 		}
 		if err != nil {
@@ -733,6 +799,8 @@ func (serv *MetadataServer) ListLabels(listRequest *pb.ListRequest, stream pb.Ap
 		res, err := proxyStream.Recv()
 		if err == io.EOF {
 			logger.Debugw("End of stream reached. Stream request completed")
+			// Paged lists return the next page's token in the trailer.
+			stream.SetTrailer(proxyStream.Trailer())
 			return nil
 		}
 		if err != nil {
@@ -762,6 +830,8 @@ func (serv *MetadataServer) ListSources(listRequest *pb.ListRequest, stream pb.A
 		res, err := proxyStream.Recv()
 		if err == io.EOF {
 			logger.Debugw("End of stream reached. Stream request completed")
+			// Paged lists return the next page's token in the trailer.
+			stream.SetTrailer(proxyStream.Trailer())
 			return nil
 		}
 		if err != nil {
@@ -790,6 +860,8 @@ func (serv *MetadataServer) ListTrainingSets(listRequest *pb.ListRequest, stream
 		res, err := proxyStream.Recv()
 		if err == io.EOF {
 			logger.Debugw("End of stream reached. Stream request completed")
+			// Paged lists return the next page's token in the trailer.
+			stream.SetTrailer(proxyStream.Trailer())
 			return nil
 		}
 		if err != nil {
@@ -927,7 +999,7 @@ func (serv *MetadataServer) CreateProvider(ctx context.Context, providerRequest
 	if shouldCheckProviderHealth {
 		logger.Infow("Checking provider health", "name", provider.Name)
 
-		err := serv.checkProviderHealth(ctx, provider.Name)
+		_, err := serv.checkProviderHealth(ctx, provider.Name)
 		if err != nil {
 			logger.Errorw("Failed to set provider status", "error", err, "health check error", err)
 			return nil, err
@@ -979,7 +1051,27 @@ func (serv *MetadataServer) shouldCheckProviderHealth(ctx context.Context, provi
 	return doHealthCheck, nil
 }
 
-func (serv *MetadataServer) checkProviderHealth(ctx context.Context, providerName string) error {
+// CheckProviderHealth checks that a registered provider can be connected to
+// with its config. The result is saved as the provider's status, so it's
+// also what the dashboard shows.
+func (serv *MetadataServer) CheckProviderHealth(ctx context.Context, req *pb.NameRequest) (*pb.ResourceStatus, error) {
+	_, ctx, logger := serv.Logger.InitializeRequestID(ctx)
+	name := req.GetName().GetName()
+	logger = logger.WithResource(logging.Provider, name, logging.NoVariant)
+	ctx = logger.AttachToContext(ctx)
+	rec, err := serv.client.GetProvider(ctx, name)
+	if err != nil {
+		logger.Errorw("Failed to get provider", "error", err)
+		return nil, err
+	}
+	if !serv.health.IsSupportedProvider(pt.Type(rec.Type())) {
+		logger.Infow("Provider type is currently not supported for health check", "type", rec.Type())
+		return nil, fferr.NewUnimplementedErrorf("health checks are not supported for %s providers", rec.Type())
+	}
+	return serv.checkProviderHealth(ctx, name)
+}
+
+func (serv *MetadataServer) checkProviderHealth(ctx context.Context, providerName string) (*pb.ResourceStatus, error) {
 	var status *pb.ResourceStatus
 	logger := logging.GetLoggerFromContext(ctx)
 	logger.Infow("Checking provider health")
@@ -990,7 +1082,7 @@ func (serv *MetadataServer) checkProviderHealth(ctx context.Context, providerNam
 		errorStatus, ok := grpc_status.FromError(err)
 		if !ok {
 			logger.Infow("Unknown codes", "error status", errorStatus, "error", err)
-			return err
+			return nil, err
 		}
 		errorProto := errorStatus.Proto()
 		var errorStatusProto *pb.ErrorStatus
@@ -1020,8 +1112,137 @@ func (serv *MetadataServer) checkProviderHealth(ctx context.Context, providerNam
 		},
 		Status: status,
 	}
-	_, statusErr := serv.meta.SetResourceStatus(ctx, statusReq)
-	return statusErr
+	if _, err := serv.meta.SetResourceStatus(ctx, statusReq); err != nil {
+		return nil, err
+	}
+	return status, nil
+}
+
+// GetMaterializationStatus returns the status of a feature variant's latest
+// materialization run along with the progress it has reported.
+func (serv *MetadataServer) GetMaterializationStatus(ctx context.Context, req *pb.NameVariantRequest) (*pb.MaterializationStatus, error) {
+	_, ctx, logger := serv.Logger.InitializeRequestID(ctx)
+	nv := req.GetNameVariant()
+	logger = logger.WithResource(logging.FeatureVariant, nv.GetName(), nv.GetVariant())
+	ctx = logger.AttachToContext(ctx)
+	feature, err := serv.client.GetFeatureVariant(ctx, metadata.NameVariant{Name: nv.GetName(), Variant: nv.GetVariant()})
+	if err != nil {
+		logger.Errorw("Failed to get feature variant", "error", err)
+		return nil, err
+	}
+	taskIDs, err := feature.TaskIDs()
+	if err != nil {
+		logger.Errorw("Failed to get feature variant tasks", "error", err)
+		return nil, err
+	}
+	noStatus := &pb.MaterializationStatus{Status: &pb.ResourceStatus{Status: pb.ResourceStatus_NO_STATUS}}
+	if len(taskIDs) == 0 {
+		return noStatus, nil
+	}
+	// The latest task is the one that materializes the feature.
+	run, err := serv.client.Tasks.GetLatestRun(taskIDs[len(taskIDs)-1])
+	if err != nil && fferr.FromErr(err).GetType() == fferr.NO_RUNS_FOR_TASK {
+		return noStatus, nil
+	} else if err != nil {
+		logger.Errorw("Failed to get latest run", "error", err)
+		return nil, err
+	}
+	status := &pb.MaterializationStatus{
+		Status: &pb.ResourceStatus{Status: run.Status.Proto(), ErrorMessage: run.Error},
+	}
+	if run.Progress != nil {
+		status.Phase = string(run.Progress.Phase)
+		status.RowsProcessed = run.Progress.RowsProcessed
+		status.TotalRows = run.Progress.TotalRows
+		status.Updated = timestamppb.New(run.Progress.UpdatedAt)
+	}
+	return status, nil
+}
+
+// ProfileSource starts a task that profiles a source variant in its offline
+// store. The profile is saved with the variant once the task's run is done, so
+// the dashboard can show its column summaries.
+func (serv *MetadataServer) ProfileSource(ctx context.Context, req *pb.ProfileSourceRequest) (*pb.ProfileSourceResponse, error) {
+	_, ctx, logger := serv.Logger.InitializeRequestID(ctx)
+	nv := metadata.NameVariant{Name: req.GetSource().GetName(), Variant: req.GetSource().GetVariant()}
+	logger = logger.WithResource(logging.SourceVariant, nv.Name, nv.Variant)
+	ctx = logger.AttachToContext(ctx)
+	source, err := serv.client.GetSourceVariant(ctx, nv)
+	if err != nil {
+		logger.Errorw("Failed to get source variant", "error", err)
+		return nil, err
+	}
+	providerEntry, err := source.FetchProvider(serv.client, ctx)
+	if err != nil {
+		logger.Errorw("Failed to fetch provider", "error", err)
+		return nil, err
+	}
+	p, err := provider.Get(pt.Type(providerEntry.Type()), providerEntry.SerializedConfig())
+	if err != nil {
+		logger.Errorw("Failed to get provider", "error", err)
+		return nil, err
+	}
+	defer func() {
+		if err := p.Close(); err != nil {
+			logger.Errorw("Failed to close provider", "error", err)
+		}
+	}()
+	caps, err := p.Capabilities()
+	if err != nil {
+		logger.Errorw("Failed to get provider capabilities", "error", err)
+		return nil, err
+	}
+	if !caps.SourceProfiling {
+		logger.Infow("Provider type is currently not supported for source profiling", "type", providerEntry.Type())
+		return nil, fferr.NewUnimplementedErrorf("source profiling is not supported for %s providers", providerEntry.Type())
+	}
+	logger.Infow("Starting source profile task", "sample_size", req.SampleSize)
+	resp, err := serv.client.ProfileSource(ctx, nv, req.SampleSize)
+	if err != nil {
+		logger.Errorw("Failed to start source profile task", "error", err)
+		return nil, err
+	}
+	return resp, nil
+}
+
+// GetProviderCapabilities returns the optional features a provider supports.
+func (serv *MetadataServer) GetProviderCapabilities(ctx context.Context, req *pb.NameRequest) (*pb.ProviderCapabilities, error) {
+	_, ctx, logger := serv.Logger.InitializeRequestID(ctx)
+	name := req.GetName().GetName()
+	logger = logger.WithResource(logging.Provider, name, logging.NoVariant)
+	ctx = logger.AttachToContext(ctx)
+	rec, err := serv.client.GetProvider(ctx, name)
+	if err != nil {
+		logger.Errorw("Failed to get provider", "error", err)
+		return nil, err
+	}
+	p, err := provider.Get(pt.Type(rec.Type()), rec.SerializedConfig())
+	if err != nil {
+		logger.Errorw("Failed to get provider", "error", err)
+		return nil, err
+	}
+	defer func() {
+		if err := p.Close(); err != nil {
+			logger.Errorw("Failed to close provider", "error", err)
+		}
+	}()
+	caps, err := p.Capabilities()
+	if err != nil {
+		logger.Errorw("Failed to get provider capabilities", "error", err)
+		return nil, err
+	}
+	return &pb.ProviderCapabilities{
+		ProviderType:              rec.Type(),
+		Catalog:                   caps.Catalog,
+		DirectCopyDynamo:          caps.DirectCopyDynamo,
+		DirectCopyRedis:           caps.DirectCopyRedis,
+		DirectCopyCosmos:          caps.DirectCopyCosmos,
+		FilteredMaterialization:   caps.FilteredMaterialization,
+		IncrementalTransformation: caps.IncrementalTransformation,
+		ResumableTransformation:   caps.ResumableTransformation,
+		SourceProfiling:           caps.SourceProfiling,
+		VectorSearch:              caps.VectorSearch,
+	}, nil
 }
 
 // rpc CreateSourceVariant(SourceVariant) returns (Empty);
@@ -1235,6 +1456,24 @@ func (serv *OnlineServer) FeatureServe(ctx context.Context, req *srv.FeatureServ
 	return serv.client.FeatureServe(ctx, req)
 }
 
+func (serv *OnlineServer) BatchGetFeatures(ctx context.Context, req *srv.BatchGetFeaturesRequest) (*srv.BatchGetFeaturesResponse, error) {
+	_, ctx, logger := serv.Logger.InitializeRequestID(ctx)
+	logger.Infow("Batch Getting Features", "request", req.String())
+	return serv.client.BatchGetFeatures(ctx, req)
+}
+
+func (serv *OnlineServer) EvaluateOnDemandFeature(ctx context.Context, req *srv.OnDemandFeatureRequest) (*srv.Value, error) {
+	_, ctx, logger := serv.Logger.InitializeRequestID(ctx)
+	logger.Infow("Evaluating On-Demand Feature", "request", req.String())
+	return serv.client.EvaluateOnDemandFeature(ctx, req)
+}
+
+func (serv *OnlineServer) GetFeature(ctx context.Context, req *srv.GetFeatureRequest) (*srv.ValueList, error) {
+	_, ctx, logger := serv.Logger.InitializeRequestID(ctx)
+	logger.Infow("Getting Feature", "request", req.String())
+	return serv.client.GetFeature(ctx, req)
+}
+
 func (serv *OnlineServer) BatchFeatureServe(req *srv.BatchFeatureServeRequest, stream srv.Feature_BatchFeatureServeServer) error {
 	_, ctx, logger := serv.Logger.InitializeRequestID(context.Background())
 	logger.Infow("Serving Batch Features", "request", req.String())
@@ -1333,6 +1572,39 @@ func (serv *OnlineServer) TrainTestSplit(stream srv.Feature_TrainTestSplitServer
 	}
 }
 
+func (serv *OnlineServer) WriteFeatures(stream srv.Feature_WriteFeaturesServer) error {
+	_, ctx, logger := serv.Logger.InitializeRequestID(stream.Context())
+	logger.Infow("Starting Write Features Stream")
+	clientStream, err := serv.client.WriteFeatures(ctx)
+	if err != nil {
+		logger.Errorw("Failed to start write features stream", "error", err)
+		return fmt.Errorf("could not write features: %v", err)
+	}
+	for {
+		req, err := stream.Recv()
+		if err == io.EOF {
+			resp, err := clientStream.CloseAndRecv()
+			if err != nil {
+				logger.Errorw("Failed to write features", "error", err)
+				return err
+			}
+			return stream.SendAndClose(resp)
+		}
+		if err != nil {
+			logger.Errorw("Error receiving from client stream", "error", err)
+			return err
+		}
+		if err := clientStream.Send(req); err != nil {
+			// The downstream error is returned by CloseAndRecv.
+			if err == io.EOF {
+				_, err = clientStream.CloseAndRecv()
+			}
+			logger.Errorw("Failed to send records to downstream service", "error", err)
+			return err
+		}
+	}
+}
+
 func (serv *OnlineServer) TrainingDataColumns(ctx context.Context, req *srv.TrainingDataColumnsRequest) (*srv.TrainingColumns, error) {
 	_, ctx, logger := serv.Logger.InitializeRequestID(ctx)
 	logger.Infow("Serving Training Set Columns", "id", req.Id.String())
@@ -1391,6 +1663,26 @@ func (serv *OnlineServer) GetResourceLocation(ctx context.Context, req *srv.Reso
 	return loc, err
 }
 
+func (serv *OnlineServer) GetResourceStats(ctx context.Context, req *srv.ResourceIdRequest) (*srv.ResourceStats, error) {
+	_, ctx, logger := serv.Logger.InitializeRequestID(ctx)
+	logger.Infow("Serving Resource Stats", "resource", req.String())
+	stats, err := serv.client.GetResourceStats(ctx, req)
+	if err != nil {
+		logger.Errorw("Failed to get resource stats", "error", err)
+	}
+	return stats, err
+}
+
+func (serv *OnlineServer) ExportTrainingSet(ctx context.Context, req *srv.ExportTrainingSetRequest) (*srv.ExportTrainingSetResponse, error) {
+	_, ctx, logger := serv.Logger.InitializeRequestID(ctx)
+	logger.Infow("Exporting Training Set", "training_set", req.GetId().String(), "location", req.GetLocation())
+	resp, err := serv.client.ExportTrainingSet(ctx, req)
+	if err != nil {
+		logger.Errorw("Failed to export training set", "error", err)
+	}
+	return resp, err
+}
+
 func (serv *ApiServer) Serve() error {
 	logger := logging.NewLogger("serve")
 	logger.Infow("Starting server", "address", serv.address)
@@ -1403,10 +1695,15 @@ func (serv *ApiServer) Serve() error {
 		logger.Errorw("Failed to listen", "error", err)
 		return fferr.NewInternalError(err)
 	}
+	credsOpt, err := grpctls.FromEnv().DialOption()
+	if err != nil {
+		logger.Errorw("Failed to load TLS credentials", "error", err)
+		return err
+	}
 	opts := []grpc.DialOption{
-		grpc.WithTransportCredentials(insecure.NewCredentials()),
-		// grpc.WithUnaryInterceptor(fferr.UnaryClientInterceptor()),
-		// grpc.WithStreamInterceptor(fferr.StreamClientInterceptor()),
+		credsOpt,
+		grpc.WithChainUnaryInterceptor(interceptors.UnaryClientTracingInterceptor, interceptors.UnaryClientAuthForwardingInterceptor),
+		grpc.WithChainStreamInterceptor(interceptors.StreamClientTracingInterceptor, interceptors.StreamClientAuthForwardingInterceptor),
 	}
 	metaConn, err := grpc.Dial(serv.metadata.address, opts...)
 	if err != nil {
@@ -1486,11 +1783,13 @@ func (serv *ApiServer) ServeOnListener(lis net.Listener) error {
 	opt := []grpc.ServerOption{
 		grpc.StreamInterceptor(
 			grpc_middleware.ChainStreamServer(
+				interceptors.StreamServerTracingInterceptor,
 				grpc_logrus.StreamServerInterceptor(logrusEntry, lorgusOpts...),
 			),
 		),
 		grpc.UnaryInterceptor(
 			grpc_middleware.ChainUnaryServer(
+				interceptors.UnaryServerTracingInterceptor,
 				grpc_logrus.UnaryServerInterceptor(logrusEntry, lorgusOpts...),
 			),
 		),
@@ -1560,7 +1859,8 @@ func handleStatus(w http.ResponseWriter, r *http.Request) {
 	w.Write(jsonResponse)
 }
 
-func StartHttpsServer(port string) error {
+// NewHttpsServer creates the status and health check server.
+func NewHttpsServer(port string) *http.Server {
 	mux := &http.ServeMux{}
 
 	mux.HandleFunc("/status", handleStatus)
@@ -1582,7 +1882,10 @@ func StartHttpsServer(port string) error {
 		Addr:         port,
 	}
 
-	fmt.Printf("starting HTTP server on port %s", port)
+	return httpsSrv
+}
 
-	return httpsSrv.ListenAndServe()
+func StartHttpsServer(port string) error {
+	fmt.Printf("starting HTTP server on port %s", port)
+	return NewHttpsServer(port).ListenAndServe()
 }
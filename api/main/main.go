@@ -8,11 +8,13 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 
 	"github.com/featureform/api"
 	help "github.com/featureform/helpers"
+	"github.com/featureform/helpers/tracing"
 	"github.com/featureform/logging"
 	"github.com/joho/godotenv"
 )
@@ -36,6 +38,14 @@ func main() {
 	logger.Infow("Retrieved serving host from ENV", "host", servingHost)
 	servingPort := help.GetEnv("SERVING_PORT", "8080")
 	logger.Infow("Retrieved serving port from ENV", "port", servingPort)
+	shutdownTracing, err := tracing.Init(context.Background(), "api", help.GetEnv(tracing.EndpointEnv, ""))
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	defer func() {
+		logger.LogIfErr("Failed to shut down tracing", shutdownTracing(context.Background()))
+	}()
 	apiConn := fmt.Sprintf("0.0.0.0:%s", apiPort)
 	metadataConn := fmt.Sprintf("%s:%s", metadataHost, metadataPort)
 	servingConn := fmt.Sprintf("%s:%s", servingHost, servingPort)
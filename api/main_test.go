@@ -10,13 +10,21 @@ package api
 import (
 	"context"
 	"fmt"
+	"net"
 	"reflect"
 	"testing"
 
+	"github.com/featureform/fferr"
+	"github.com/featureform/health"
 	"github.com/featureform/logging"
+	"github.com/featureform/metadata"
 	pb "github.com/featureform/metadata/proto"
 	"github.com/featureform/proto"
 	srv "github.com/featureform/proto"
+	"github.com/featureform/provider"
+	pc "github.com/featureform/provider/provider_config"
+	pt "github.com/featureform/provider/provider_type"
+	"github.com/featureform/scheduling"
 	"go.uber.org/zap"
 	"google.golang.org/grpc"
 )
@@ -48,6 +56,22 @@ func (m *mockFeatureClient) Nearest(ctx context.Context, in *srv.NearestRequest,
 	return &srv.NearestResponse{}, nil // Nearest was the method we aimed to mock for positive response in the test.
 }
 
+func (m *mockFeatureClient) BatchGetFeatures(ctx context.Context, in *srv.BatchGetFeaturesRequest, opts ...grpc.CallOption) (*srv.BatchGetFeaturesResponse, error) {
+	return &srv.BatchGetFeaturesResponse{}, nil
+}
+
+func (m *mockFeatureClient) EvaluateOnDemandFeature(ctx context.Context, in *srv.OnDemandFeatureRequest, opts ...grpc.CallOption) (*srv.Value, error) {
+	return &srv.Value{}, nil
+}
+
+func (m *mockFeatureClient) GetFeature(ctx context.Context, in *srv.GetFeatureRequest, opts ...grpc.CallOption) (*srv.ValueList, error) {
+	return &srv.ValueList{}, nil
+}
+
+func (m *mockFeatureClient) WriteFeatures(ctx context.Context, opts ...grpc.CallOption) (srv.Feature_WriteFeaturesClient, error) {
+	return nil, nil
+}
+
 func (m *mockFeatureClient) BatchFeatureServe(ctx context.Context, in *srv.BatchFeatureServeRequest, opts ...grpc.CallOption) (srv.Feature_BatchFeatureServeClient, error) {
 	return nil, nil
 }
@@ -59,6 +83,14 @@ func (m *mockFeatureClient) GetResourceLocation(ctx context.Context, in *srv.Res
 	return &srv.ResourceLocation{}, nil
 }
 
+func (m *mockFeatureClient) GetResourceStats(ctx context.Context, in *srv.ResourceIdRequest, opts ...grpc.CallOption) (*srv.ResourceStats, error) {
+	return &srv.ResourceStats{}, nil
+}
+
+func (m *mockFeatureClient) ExportTrainingSet(ctx context.Context, in *srv.ExportTrainingSetRequest, opts ...grpc.CallOption) (*srv.ExportTrainingSetResponse, error) {
+	return &srv.ExportTrainingSetResponse{}, nil
+}
+
 func (m *mockFeatureClient) TrainTestSplit(ctx context.Context, opts ...grpc.CallOption) (srv.Feature_TrainTestSplitClient, error) {
 	return nil, nil
 }
@@ -396,3 +428,279 @@ func (m *mockAPIClient) ListModels(ctx context.Context, in *pb.Empty, opts ...gr
 func (m *mockAPIClient) WriteFeatures(ctx context.Context, opts ...grpc.CallOption) (pb.Api_WriteFeaturesClient, error) {
 	return nil, fmt.Errorf("Not implemented")
 }
+
+func (m *mockAPIClient) GetMaterializationStatus(ctx context.Context, in *pb.NameVariantRequest, opts ...grpc.CallOption) (*pb.MaterializationStatus, error) {
+	return nil, fmt.Errorf("Not implemented")
+}
+
+func startMetadataServer(t *testing.T, ctx context.Context, logger logging.Logger) *metadata.Client {
+	manager, err := scheduling.NewMemoryTaskMetadataManager(ctx)
+	if err != nil {
+		t.Fatalf("Failed to create memory task metadata manager: %s", err)
+	}
+	serv, err := metadata.NewMetadataServer(&metadata.Config{
+		Logger:      logger,
+		TaskManager: manager,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create metadata server: %s", err)
+	}
+	lis, err := net.Listen("tcp", ":0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %s", err)
+	}
+	go func() {
+		if err := serv.ServeOnListener(lis); err != nil {
+			t.Logf("Metadata server error: %s", err)
+		}
+	}()
+	t.Cleanup(func() { serv.Stop() })
+	client, err := metadata.NewClient(lis.Addr().String(), logger)
+	if err != nil {
+		t.Fatalf("Failed to create metadata client: %s", err)
+	}
+	return client
+}
+
+func TestMetadataServerCheckProviderHealth(t *testing.T) {
+	ctx, logger := logging.NewTestContextAndLogger(t)
+	client := startMetadataServer(t, ctx, logger)
+	serv := &MetadataServer{
+		Logger: logger,
+		meta:   client.GrpcConn,
+		client: client,
+		health: health.NewHealth(client),
+	}
+	// Nothing is listening on this address, so the health check fails.
+	redisConfig := pc.RedisConfig{Addr: "localhost:1"}
+	providers := []metadata.ResourceDef{
+		metadata.UserDef{Name: "Featureform"},
+		metadata.ProviderDef{
+			Name:             "unreachable-redis",
+			Type:             string(pt.RedisOnline),
+			SerializedConfig: redisConfig.Serialized(),
+		},
+		metadata.ProviderDef{
+			Name:             "local",
+			Type:             string(pt.LocalOnline),
+			SerializedConfig: []byte("{}"),
+		},
+	}
+	if err := client.CreateAll(ctx, providers); err != nil {
+		t.Fatalf("Failed to create providers: %s", err)
+	}
+
+	status, err := serv.CheckProviderHealth(ctx, &pb.NameRequest{Name: &pb.Name{Name: "unreachable-redis"}})
+	if err != nil {
+		t.Fatalf("Failed to check provider health: %s", err)
+	}
+	if status.Status != pb.ResourceStatus_FAILED || status.ErrorMessage == "" {
+		t.Fatalf("Expected a failed status with an error message, got: %v", status)
+	}
+	provider, err := client.GetProvider(ctx, "unreachable-redis")
+	if err != nil {
+		t.Fatalf("Failed to get provider: %s", err)
+	}
+	if provider.Status() != scheduling.FAILED {
+		t.Fatalf("Expected the provider's status to be saved as FAILED, got: %s", provider.Status())
+	}
+
+	_, err = serv.CheckProviderHealth(ctx, &pb.NameRequest{Name: &pb.Name{Name: "local"}})
+	if _, ok := err.(*fferr.UnimplementedError); !ok {
+		t.Fatalf("Expected an UnimplementedError for an unsupported provider type, got: %v", err)
+	}
+}
+
+func TestMetadataServerProfileSourceUnsupported(t *testing.T) {
+	ctx, logger := logging.NewTestContextAndLogger(t)
+	client := startMetadataServer(t, ctx, logger)
+	serv := &MetadataServer{
+		Logger: logger,
+		meta:   client.GrpcConn,
+		client: client,
+	}
+	resources := []metadata.ResourceDef{
+		metadata.UserDef{Name: "Featureform"},
+		metadata.ProviderDef{Name: "offline", Type: pt.MemoryOffline.String()},
+		metadata.SourceDef{
+			Name:    "transactions",
+			Variant: "default",
+			Definition: metadata.PrimaryDataSource{
+				Location: metadata.SQLTable{Name: "transactions"},
+			},
+			Owner:    "Featureform",
+			Provider: "offline",
+		},
+	}
+	if err := client.CreateAll(ctx, resources); err != nil {
+		t.Fatalf("Failed to create resources: %s", err)
+	}
+	req := &pb.ProfileSourceRequest{Source: &pb.NameVariant{Name: "transactions", Variant: "default"}, SampleSize: 100}
+	_, err := serv.ProfileSource(ctx, req)
+	if _, ok := err.(*fferr.UnimplementedError); !ok {
+		t.Fatalf("Expected an UnimplementedError for an unsupported provider type, got: %v", err)
+	}
+
+	req = &pb.ProfileSourceRequest{Source: &pb.NameVariant{Name: "missing", Variant: "default"}}
+	if _, err := serv.ProfileSource(ctx, req); err == nil {
+		t.Fatalf("Expected an error for a missing source variant")
+	}
+}
+
+func TestMetadataServerGetProviderCapabilities(t *testing.T) {
+	ctx, logger := logging.NewTestContextAndLogger(t)
+	client := startMetadataServer(t, ctx, logger)
+	serv := &MetadataServer{
+		Logger: logger,
+		meta:   client.GrpcConn,
+		client: client,
+	}
+	resources := []metadata.ResourceDef{
+		metadata.UserDef{Name: "Featureform"},
+		metadata.ProviderDef{Name: "offline", Type: pt.MemoryOffline.String()},
+	}
+	if err := client.CreateAll(ctx, resources); err != nil {
+		t.Fatalf("Failed to create resources: %s", err)
+	}
+	caps, err := serv.GetProviderCapabilities(ctx, &pb.NameRequest{Name: &pb.Name{Name: "offline"}})
+	if err != nil {
+		t.Fatalf("Failed to get provider capabilities: %s", err)
+	}
+	if caps.ProviderType != pt.MemoryOffline.String() {
+		t.Fatalf("Expected provider type %s, got %s", pt.MemoryOffline, caps.ProviderType)
+	}
+	if caps.Catalog || caps.IncrementalTransformation || caps.SourceProfiling || caps.VectorSearch {
+		t.Fatalf("Expected the memory offline store to support no optional features, got %v", caps)
+	}
+
+	if _, err := serv.GetProviderCapabilities(ctx, &pb.NameRequest{Name: &pb.Name{Name: "missing"}}); err == nil {
+		t.Fatalf("Expected an error for a missing provider")
+	}
+}
+
+func TestMetadataServerGetMaterializationStatus(t *testing.T) {
+	ctx, logger := logging.NewTestContextAndLogger(t)
+	client := startMetadataServer(t, ctx, logger)
+	serv := &MetadataServer{
+		Logger: logger,
+		meta:   client.GrpcConn,
+		client: client,
+	}
+	resources := []metadata.ResourceDef{
+		metadata.UserDef{Name: "Featureform"},
+		metadata.ProviderDef{Name: "offline", Type: pt.MemoryOffline.String()},
+		metadata.EntityDef{Name: "user"},
+		metadata.SourceDef{
+			Name:    "transactions",
+			Variant: "default",
+			Definition: metadata.PrimaryDataSource{
+				Location: metadata.SQLTable{Name: "transactions"},
+			},
+			Owner:    "Featureform",
+			Provider: "offline",
+		},
+		metadata.FeatureDef{
+			Name:    "avg_transaction",
+			Variant: "default",
+			Owner:   "Featureform",
+			Source:  metadata.NameVariant{Name: "transactions", Variant: "default"},
+			Location: metadata.ResourceVariantColumns{
+				Entity: "user",
+				Value:  "amount",
+				Source: "transactions",
+			},
+			Entity: "user",
+		},
+	}
+	if err := client.CreateAll(ctx, resources); err != nil {
+		t.Fatalf("Failed to create resources: %s", err)
+	}
+	req := &pb.NameVariantRequest{NameVariant: &pb.NameVariant{Name: "avg_transaction", Variant: "default"}}
+
+	status, err := serv.GetMaterializationStatus(ctx, req)
+	if err != nil {
+		t.Fatalf("Failed to get materialization status: %s", err)
+	}
+	if status.Phase != "" || status.RowsProcessed != 0 {
+		t.Fatalf("Expected no progress before the run reports any, got: %v", status)
+	}
+
+	feature, err := client.GetFeatureVariant(ctx, metadata.NameVariant{Name: "avg_transaction", Variant: "default"})
+	if err != nil {
+		t.Fatalf("Failed to get feature variant: %s", err)
+	}
+	taskIDs, err := feature.TaskIDs()
+	if err != nil || len(taskIDs) == 0 {
+		t.Fatalf("Expected the feature variant to have a task, got: %v %v", taskIDs, err)
+	}
+	taskID := taskIDs[len(taskIDs)-1]
+	run, err := client.Tasks.GetLatestRun(taskID)
+	if err != nil {
+		t.Fatalf("Failed to get latest run: %s", err)
+	}
+	if err := client.Tasks.SetRunStatus(taskID, run.ID, scheduling.RUNNING, nil); err != nil {
+		t.Fatalf("Failed to set run status: %s", err)
+	}
+	progress := scheduling.RunProgress{Phase: scheduling.WritingPhase, RowsProcessed: 50, TotalRows: 200}
+	if err := client.Tasks.SetRunProgress(taskID, run.ID, progress); err != nil {
+		t.Fatalf("Failed to set run progress: %s", err)
+	}
+
+	status, err = serv.GetMaterializationStatus(ctx, req)
+	if err != nil {
+		t.Fatalf("Failed to get materialization status: %s", err)
+	}
+	if status.Status.Status != pb.ResourceStatus_RUNNING {
+		t.Fatalf("Expected RUNNING, got: %s", status.Status.Status)
+	}
+	if status.Phase != string(scheduling.WritingPhase) || status.RowsProcessed != 50 || status.TotalRows != 200 || status.Updated == nil {
+		t.Fatalf("Unexpected progress: %v", status)
+	}
+
+	req = &pb.NameVariantRequest{NameVariant: &pb.NameVariant{Name: "missing", Variant: "default"}}
+	if _, err := serv.GetMaterializationStatus(ctx, req); err == nil {
+		t.Fatalf("Expected an error for a missing feature variant")
+	}
+}
+
+type closeCountingProvider struct {
+	provider.BaseProvider
+	closed *int
+}
+
+func (p closeCountingProvider) Close() error {
+	*p.closed++
+	return nil
+}
+
+func TestMetadataServerGetProviderCapabilitiesClosesProvider(t *testing.T) {
+	ctx, logger := logging.NewTestContextAndLogger(t)
+	client := startMetadataServer(t, ctx, logger)
+	serv := &MetadataServer{
+		Logger: logger,
+		meta:   client.GrpcConn,
+		client: client,
+	}
+	providerType := pt.Type("CLOSE_COUNTING_TEST")
+	closed := 0
+	if err := provider.RegisterFactory(providerType, func(config pc.SerializedConfig) (provider.Provider, error) {
+		return closeCountingProvider{BaseProvider: provider.BaseProvider{ProviderType: providerType}, closed: &closed}, nil
+	}); err != nil {
+		t.Fatalf("Failed to register provider factory: %s", err)
+	}
+	resources := []metadata.ResourceDef{
+		metadata.UserDef{Name: "Featureform"},
+		metadata.ProviderDef{Name: "counting", Type: providerType.String()},
+	}
+	if err := client.CreateAll(ctx, resources); err != nil {
+		t.Fatalf("Failed to create resources: %s", err)
+	}
+	for i := 0; i < 2; i++ {
+		if _, err := serv.GetProviderCapabilities(ctx, &pb.NameRequest{Name: &pb.Name{Name: "counting"}}); err != nil {
+			t.Fatalf("Failed to get provider capabilities: %s", err)
+		}
+	}
+	if closed != 2 {
+		t.Fatalf("Expected the provider to be closed after each call, got %d closes", closed)
+	}
+}
@@ -22,6 +22,7 @@ from .register import (
     FeatureColumnResource,
     LabelColumnResource,
     FileStoreProvider,
+    KafkaProvider,
     OfflineSQLProvider,
     OfflineSparkProvider,
     OnlineProvider,
@@ -39,6 +40,7 @@ from .resources import (
     AzureFileStoreConfig,
     BasicCredentials,
     BigQueryConfig,
+    BigtableConfig,
     CassandraConfig,
     ClickHouseConfig,
     DynamodbConfig,
@@ -46,6 +48,7 @@ from .resources import (
     GCPCredentials,
     GCSFileStoreConfig,
     HDFSConfig,
+    KafkaConfig,
     MongoDBConfig,
     OnlineBlobConfig,
     PineconeConfig,
@@ -476,6 +479,36 @@ class Client(ResourceClient, ServingClient):
         location = self.impl.location(name, variant, resource_type)
         return location
 
+    def export_training_set(
+        self,
+        name: Union[str, TrainingSetVariant],
+        location: str,
+        variant: Optional[str] = None,
+        format: str = "parquet",
+    ) -> List[str]:
+        """
+        Exports a training set to a directory in S3, GCS, Azure Blob Store or HDFS and returns the written files.
+
+        **Example:**
+        ```py title="definitions.py"
+        files = client.export_training_set("fraud_training", "s3://my-bucket/exports", "quickstart")
+        ```
+
+        Args:
+            name (Union[str, TrainingSetVariant]): The training set or its name
+            location (str): URI of the directory to export to, e.g. s3://bucket/path
+            variant (str): The training set variant; can't be None if name is a string
+            format (str): The file format, either parquet or csv
+
+        Returns:
+            files (List[str]): URIs of the exported files
+        """
+        if isinstance(name, TrainingSetVariant):
+            name, variant = name.name, name.variant
+        elif not variant:
+            raise ValueError("variant must be specified if name is a string")
+        return self.impl.export_training_set(name, variant, location, format)
+
     def close(self):
         """
         Closes the client, closes channel for hosted mode
@@ -569,6 +602,7 @@ class Client(ResourceClient, ServingClient):
             user=deserialized_config["Username"],
             password=deserialized_config["Password"],
             sslmode=deserialized_config["SSLMode"],
+            iam_role=deserialized_config.get("IAMRole", ""),
         )
 
         offline_provider = self.__create_provider(
@@ -818,6 +852,93 @@ class Client(ResourceClient, ServingClient):
 
         return OnlineProvider(global_registrar, online_provider)
 
+    def get_kafka(self, name):
+        """Get a Kafka provider. The returned object can be used to register topics as sources.
+
+        **Examples**:
+        ``` py
+        kafka = client.get_kafka("kafka-quickstart")
+        transactions = kafka.register_topic(name="transactions", topic="transactions")
+        ```
+        Args:
+            name (str): Name of Kafka provider to be retrieved
+        Returns:
+            kafka (KafkaProvider): Provider
+        """
+
+        provider = self.__get_provider(name)
+        config = provider.serialized_config
+        deserialized_config = json.loads(config.decode("utf-8"))
+
+        kafka_config = KafkaConfig(
+            brokers=deserialized_config["Brokers"],
+            format=deserialized_config.get("Format", "JSON"),
+            schema_registry_url=deserialized_config.get("SchemaRegistryURL", ""),
+            schema_registry_username=deserialized_config.get(
+                "SchemaRegistryUsername", ""
+            ),
+            schema_registry_password=deserialized_config.get(
+                "SchemaRegistryPassword", ""
+            ),
+            username=deserialized_config.get("Username", ""),
+            password=deserialized_config.get("Password", ""),
+            tls=deserialized_config.get("TLS", False),
+            raw_events_store_type=deserialized_config.get("RawEventsStoreType", ""),
+            raw_events_store_config=deserialized_config.get("RawEventsStoreConfig"),
+            raw_events_path=deserialized_config.get("RawEventsPath", ""),
+        )
+
+        kafka_provider = self.__create_provider(
+            provider.name,
+            kafka_config,
+            "OFFLINE",
+            provider.description,
+            provider.team,
+            provider.tags,
+            provider.properties,
+        )
+
+        return KafkaProvider(global_registrar, kafka_provider)
+
+    def get_bigtable(self, name):
+        """Get a Bigtable provider. The returned object can be used to register additional resources.
+
+        **Examples**:
+        ``` py
+        bigtable = client.get_bigtable("bigtable-quickstart")
+        ```
+        Args:
+            name (str): Name of Bigtable provider to be retrieved
+        Returns:
+            bigtable (OnlineProvider): Provider
+        """
+
+        provider = self.__get_provider(name)
+        config = provider.serialized_config
+        deserialized_config = json.loads(config.decode("utf-8"))
+
+        bigtable_config = BigtableConfig(
+            project_id=deserialized_config["ProjectID"],
+            instance_id=deserialized_config["InstanceID"],
+            table_prefix=deserialized_config.get("TablePrefix", ""),
+            credentials=GCPCredentials(
+                project_id=deserialized_config["ProjectID"],
+                credential_json=deserialized_config["Credentials"],
+            ),
+        )
+
+        online_provider = self.__create_provider(
+            provider.name,
+            bigtable_config,
+            "ONLINE",
+            provider.description,
+            provider.team,
+            provider.tags,
+            provider.properties,
+        )
+
+        return OnlineProvider(global_registrar, online_provider)
+
     def get_dynamodb(self, name):
         """Get a DynamoDB provider. The returned object can be used to register additional resources.
 
@@ -884,6 +1005,17 @@ class Client(ResourceClient, ServingClient):
             port=port,
             password=deserialized_config["Password"],
             db=deserialized_config["DB"],
+            cluster_nodes=deserialized_config.get("ClusterAddrs"),
+            sentinel_master=deserialized_config.get("SentinelMasterName", ""),
+            sentinel_addrs=deserialized_config.get("SentinelAddrs"),
+            username=deserialized_config.get("Username", ""),
+            tls=deserialized_config.get("TLS", False),
+            tls_ca_cert=deserialized_config.get("TLSCACert", ""),
+            tls_cert=deserialized_config.get("TLSCert", ""),
+            tls_key=deserialized_config.get("TLSKey", ""),
+            tls_insecure_skip_verify=deserialized_config.get(
+                "TLSInsecureSkipVerify", False
+            ),
         )
 
         online_provider = self.__create_provider(
@@ -996,6 +1128,8 @@ class Client(ResourceClient, ServingClient):
             host=deserialized_config["Host"],
             port=deserialized_config["Port"],
             throughput=deserialized_config["Throughput"],
+            vector_index=deserialized_config.get("VectorIndex", ""),
+            vector_similarity=deserialized_config.get("VectorSimilarity", ""),
         )
 
         online_provider = self.__create_provider(
@@ -29,6 +29,7 @@ class ScalarType(str, Enum):
         STRING: A string representing a string type.
         BOOL: A string representing a boolean type.
         DATETIME: A string representing a datetime type.
+        JSON: A string representing a JSON type, served as an unparsed string.
     """
 
     NIL = ""
@@ -40,6 +41,7 @@ class ScalarType(str, Enum):
     STRING = "string"
     BOOL = "bool"
     DATETIME = "datetime"
+    JSON = "json"
 
     @classmethod
     def has_value(cls, value):
@@ -68,6 +70,7 @@ class ScalarType(str, Enum):
             ScalarType.STRING: pb.ScalarType.STRING,
             ScalarType.BOOL: pb.ScalarType.BOOL,
             ScalarType.DATETIME: pb.ScalarType.DATETIME,
+            ScalarType.JSON: pb.ScalarType.JSON,
         }
         return mapping[self]
 
@@ -83,6 +86,7 @@ class ScalarType(str, Enum):
             pb.ScalarType.STRING: ScalarType.STRING,
             pb.ScalarType.BOOL: ScalarType.BOOL,
             pb.ScalarType.DATETIME: ScalarType.DATETIME,
+            pb.ScalarType.JSON: ScalarType.JSON,
         }
         return mapping[proto_val]
 
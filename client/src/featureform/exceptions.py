@@ -51,3 +51,18 @@ class InvalidSQLQuery(Exception):
             error_message = f"{error_message} {message}"
 
         Exception.__init__(self, error_message)
+
+
+class FeatureformServerError(Exception):
+    """
+    FeatureformServerError is raised for errors returned by the Featureform server. Branch on
+    error_code (e.g. "RESOURCE_CHANGED" or "PROVIDER_CONNECTION") rather than the message, which
+    can change between versions.
+    """
+
+    def __init__(self, message, error_code=None, reason=None, metadata=None):
+        self.error_code = error_code
+        self.reason = reason
+        self.metadata = metadata or {}
+
+        Exception.__init__(self, message)
@@ -15,6 +15,11 @@ from typing import Dict, Optional
 import grpc
 from google.rpc import error_details_pb2, status_pb2
 
+from .exceptions import FeatureformServerError
+
+# ERROR_CODE_DOMAIN is the domain of the ErrorInfo that carries the server's error code
+ERROR_CODE_DOMAIN = "errors.featureform.com"
+
 
 @dataclass
 class FFGrpcErrorDetails:
@@ -26,6 +31,7 @@ class FFGrpcErrorDetails:
     message: str
     reason: str
     metadata: Dict[str, str] = field(default_factory=dict)
+    error_code: Optional[str] = None
 
     @staticmethod
     def from_grpc_error(e: grpc.RpcError) -> Optional["FFGrpcErrorDetails"]:
@@ -34,20 +40,26 @@ class FFGrpcErrorDetails:
         """
         status_proto = _extract_error_details(e)
 
+        details = None
+        error_code = None
         for detail in status_proto.details:
-            # should only be one detail
-            if detail.Is(error_details_pb2.ErrorInfo.DESCRIPTOR):
-                error_info = error_details_pb2.ErrorInfo()
-                detail.Unpack(error_info)
-
-                return FFGrpcErrorDetails(
+            if not detail.Is(error_details_pb2.ErrorInfo.DESCRIPTOR):
+                continue
+            error_info = error_details_pb2.ErrorInfo()
+            detail.Unpack(error_info)
+            # the stable error code is sent in its own ErrorInfo
+            if error_info.domain == ERROR_CODE_DOMAIN:
+                error_code = error_info.reason
+            elif details is None:
+                details = FFGrpcErrorDetails(
                     code=status_proto.code,
                     message=status_proto.message,
                     reason=error_info.reason,
                     metadata=dict(error_info.metadata),
                 )
-            else:
-                return None
+        if details is not None:
+            details.error_code = error_code
+        return details
 
 
 class GrpcClient:
@@ -144,7 +156,12 @@ class GrpcClient:
             )
             if self.debug:
                 self.logger.debug(detailed_message)
-            raise Exception(detailed_message) from (e if self.debug else None)
+            raise FeatureformServerError(
+                detailed_message,
+                error_code=grpc_error_details.error_code,
+                reason=grpc_error_details.reason,
+                metadata=grpc_error_details.metadata,
+            ) from (e if self.debug else None)
         raise e
 
 
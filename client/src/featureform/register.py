@@ -7,7 +7,7 @@
 
 from collections.abc import Iterable
 from datetime import timedelta
-from typing import Dict, List, Optional, Union, get_args
+from typing import Any, Dict, List, Optional, Union, get_args
 
 import pandas as pd
 import logging
@@ -153,6 +153,7 @@ class OfflineSQLProvider(OfflineProvider):
         tags: List[str] = None,
         properties: dict = None,
         resource_snowflake_config: Optional[ResourceSnowflakeConfig] = None,
+        incremental_column: str = "",
     ):
         """
         Register a SQL transformation source.
@@ -178,6 +179,7 @@ class OfflineSQLProvider(OfflineProvider):
             owner (Union[str, UserRegistrar]): Owner
             description (str): Description of primary data to be registered
             inputs (list): A list of Source NameVariant Tuples to input into the transformation
+            incremental_column (str): A column that only increases, like a timestamp. Updates append only the rows whose value in it is greater than the last run's, instead of rebuilding the transformation.
 
 
         Returns:
@@ -202,6 +204,7 @@ class OfflineSQLProvider(OfflineProvider):
             tags=tags,
             properties=properties,
             resource_snowflake_config=resource_snowflake_config,
+            incremental_column=incremental_column,
         )
 
     def register_training_set(
@@ -218,6 +221,10 @@ class OfflineSQLProvider(OfflineProvider):
         properties: Optional[Dict] = None,
         resource_snowflake_config: Optional[ResourceSnowflakeConfig] = None,
         type: TrainingSetType = TrainingSetType.DYNAMIC,
+        persist_as: Optional[TrainingSetPersistAs] = None,
+        labels: Optional[List] = None,
+        split: Optional[TrainingSetSplit] = None,
+        max_feature_age: Optional[timedelta] = None,
     ):
         return self.__registrar.register_training_set(
             name=name,
@@ -233,6 +240,10 @@ class OfflineSQLProvider(OfflineProvider):
             provider=self.name(),
             resource_snowflake_config=resource_snowflake_config,
             type=type,
+            persist_as=persist_as,
+            labels=labels if labels is not None else [],
+            split=split,
+            max_feature_age=max_feature_age,
         )
 
     def __eq__(self, __value: object) -> bool:
@@ -481,6 +492,10 @@ class OfflineSparkProvider(OfflineProvider):
         description: str = "",
         tags: List[str] = [],
         properties: dict = {},
+        delimiter: str = "",
+        quote: str = "",
+        escape: str = "",
+        header: bool = True,
     ):
         """Register a Spark data source as a primary data source.
 
@@ -496,22 +511,43 @@ class OfflineSparkProvider(OfflineProvider):
         )
         ```
 
+        Delimited files that aren't comma separated, like TSV or pipe-delimited files,
+        can be registered by setting their delimiter:
+
+        ```
+        transactions = spark.register_file(
+            name="transactions",
+            file_path="s3://featureform-spark/featureform/transactions.tsv",
+            delimiter="\t",
+        )
+        ```
+
         Args:
             name (str): Name of table to be registered
             variant (str): Name of variant to be registered
             file_path (str): The URI of the file. Must be the full path
             owner (Union[str, UserRegistrar]): Owner
             description (str): Description of table to be registered
+            delimiter (str): The field delimiter of a delimited file; defaults to a comma
+            quote (str): The quote character of a delimited file; defaults to a double quote
+            escape (str): The character that escapes a quote inside a quoted field; defaults to the quote character
+            header (bool): Whether the first row of a delimited file is a header; columns of a file without one are named _c0, _c1, etc.
 
         Returns:
             source (ColumnSourceRegistrar): source
         """
         FilePrefix.validate(self.__provider.config.store_type, file_path)
+        csv_options = CSVOptions(
+            delimiter=delimiter, quote=quote, escape=escape, header=header
+        )
 
         return self.__registrar.register_primary_data(
             name=name,
             variant=variant,
-            location=FileStore(file_path),
+            location=FileStore(
+                file_path,
+                csv_options=None if csv_options.is_default() else csv_options,
+            ),
             owner=owner,
             provider=self.name(),
             description=description,
@@ -547,6 +583,9 @@ class OfflineSparkProvider(OfflineProvider):
         spark_params: Optional[Dict[str, str]] = None,
         write_options: Optional[Dict[str, str]] = None,
         table_properties: Optional[Dict[str, str]] = None,
+        spark_submit_configs: Optional[Dict[str, str]] = None,
+        spark_packages: Optional[List[str]] = None,
+        incremental_column: str = "",
     ):
         """
         Register a SQL transformation source. The spark.sql_transformation decorator takes the returned string in the
@@ -571,6 +610,9 @@ class OfflineSparkProvider(OfflineProvider):
             description (str): Description of primary data to be registered
             inputs (list[Tuple(str, str)]): A list of Source NameVariant Tuples to input into the transformation
             max_job_duration (timedelta): Maximum duration FeatureForm will wait for the job to complete; default is 48 hours; jobs that exceed this duration will be canceled
+            spark_submit_configs (Dict[str, str]): Extra --conf values passed to spark-submit for this transformation (e.g. {"spark.sql.shuffle.partitions": "400"}). Keys must start with "spark.".
+            spark_packages (List[str]): Maven coordinates of packages passed to spark-submit with --packages (e.g. ["org.apache.spark:spark-avro_2.12:3.5.0"]).
+            incremental_column (str): A column that only increases, like a timestamp. Updates append only the rows whose value in it is greater than the last run's, instead of rebuilding the transformation.
 
 
         Returns:
@@ -590,6 +632,9 @@ class OfflineSparkProvider(OfflineProvider):
             spark_params=spark_params,
             write_options=write_options,
             table_properties=table_properties,
+            spark_submit_configs=spark_submit_configs,
+            spark_packages=spark_packages,
+            incremental_column=incremental_column,
         )
 
     def df_transformation(
@@ -605,6 +650,8 @@ class OfflineSparkProvider(OfflineProvider):
         spark_params: Optional[Dict[str, str]] = None,
         write_options: Optional[Dict[str, str]] = None,
         table_properties: Optional[Dict[str, str]] = None,
+        spark_submit_configs: Optional[Dict[str, str]] = None,
+        spark_packages: Optional[List[str]] = None,
     ):
         """
         Register a Dataframe transformation source. The spark.df_transformation decorator takes the contents
@@ -628,6 +675,8 @@ class OfflineSparkProvider(OfflineProvider):
             description (str): Description of primary data to be registered
             inputs (list[Tuple(str, str)]): A list of Source NameVariant Tuples to input into the transformation
             max_job_duration (timedelta): Maximum duration FeatureForm will wait for the job to complete; default is 48 hours; jobs that exceed this duration will be canceled
+            spark_submit_configs (Dict[str, str]): Extra --conf values passed to spark-submit for this transformation (e.g. {"spark.sql.shuffle.partitions": "400"}). Keys must start with "spark.".
+            spark_packages (List[str]): Maven coordinates of packages passed to spark-submit with --packages (e.g. ["org.apache.spark:spark-avro_2.12:3.5.0"]).
 
         Returns:
             source (ColumnSourceRegistrar): Source
@@ -645,6 +694,8 @@ class OfflineSparkProvider(OfflineProvider):
             spark_params=spark_params or {},
             write_options=write_options or {},
             table_properties=table_properties or {},
+            spark_submit_configs=spark_submit_configs,
+            spark_packages=spark_packages,
         )
 
     def register_training_set(
@@ -659,6 +710,10 @@ class OfflineSparkProvider(OfflineProvider):
         schedule: str = "",
         tags: List[str] = [],
         properties: dict = {},
+        persist_as: Optional[TrainingSetPersistAs] = None,
+        labels: list = [],
+        split: Optional[TrainingSetSplit] = None,
+        max_feature_age: Optional[timedelta] = None,
     ):
         """Register a training set on the Spark provider.
 
@@ -682,6 +737,10 @@ class OfflineSparkProvider(OfflineProvider):
             schedule (str): Kubernetes CronJob schedule string ("* * * * *")
             tags (List[str]): Optional grouping mechanism for resources
             properties (dict): Optional grouping mechanism for resources
+            persist_as (TrainingSetPersistAs): Copies the training set into a Glue catalog table once it's created
+            labels (List[NameVariant]): Labels of a multi-task training set, one column each, instead of a single label. They must share an entity
+            split (TrainingSetSplit): Assigns each row to a train or test split, so each can be served on its own
+            max_feature_age (timedelta): The oldest a feature value may be relative to its label's timestamp; older values are joined as null
 
         Returns:
             resource (ResourceRegistrar): resource
@@ -698,6 +757,10 @@ class OfflineSparkProvider(OfflineProvider):
             tags=tags,
             properties=properties,
             provider=self.name(),
+            persist_as=persist_as,
+            labels=labels,
+            split=split,
+            max_feature_age=max_feature_age,
         )
 
     def __eq__(self, __value: object) -> bool:
@@ -709,6 +772,65 @@ class OfflineSparkProvider(OfflineProvider):
         )
 
 
+class KafkaProvider(OfflineProvider):
+    def __init__(self, registrar, provider):
+        super().__init__(registrar, provider)
+        self.__registrar = registrar
+        self.__provider = provider
+
+    def register_topic(
+        self,
+        name: str,
+        topic: str,
+        variant: str = "",
+        timestamp_column: str = "",
+        owner: Union[str, UserRegistrar] = "",
+        description: str = "",
+        tags: List[str] = [],
+        properties: dict = {},
+    ):
+        """Register a Kafka topic as a primary data source.
+
+        Features registered on a topic are streamed into their inference store as messages
+        arrive. Each message is written at least once, so values may be rewritten after a restart.
+
+        **Example**
+
+        ```
+        kafka = client.get_kafka("kafka-quickstart")
+        transactions = kafka.register_topic(
+            name="transactions",
+            topic="transactions",
+            timestamp_column="timestamp",
+        )
+        ```
+
+        Args:
+            name (str): Name of source to be registered
+            topic (str): Name of the Kafka topic
+            variant (str): Name of variant to be registered
+            timestamp_column (str): Optional field holding each message's event time; the message time is used otherwise
+            owner (Union[str, UserRegistrar]): Owner
+            description (str): Description of source to be registered
+            tags (List[str]): Optional grouping mechanism for resources
+            properties (dict): Optional grouping mechanism for resources
+
+        Returns:
+            source (ColumnSourceRegistrar): source
+        """
+        return self.__registrar.register_primary_data(
+            name=name,
+            variant=variant,
+            location=KafkaTopic(topic=topic),
+            owner=owner,
+            provider=self.name(),
+            description=description,
+            tags=tags,
+            properties=properties,
+            timestamp_column=timestamp_column,
+        )
+
+
 class OfflineK8sProvider(OfflineProvider):
     def __init__(self, registrar, provider):
         super().__init__(registrar, provider)
@@ -1048,6 +1170,7 @@ class SQLTransformationDecorator:
     max_job_duration: timedelta = timedelta(hours=48)
     spark_flags: SparkFlags = field(default_factory=lambda: EmptySparkFlags)
     resource_snowflake_config: Optional[ResourceSnowflakeConfig] = None
+    incremental_column: str = ""
 
     def __post_init__(self):
         if self.inputs is None:
@@ -1119,6 +1242,7 @@ class SQLTransformationDecorator:
                 partition_options=self.partition_options,
                 spark_flags=self.spark_flags,
                 resource_snowflake_config=self.resource_snowflake_config,
+                incremental_column=self.incremental_column,
             ),
             owner=self.owner,
             schedule=self.schedule,
@@ -1235,6 +1359,7 @@ class DFTransformationDecorator:
     source_text: str = ""
     canonical_func_text: str = ""
     max_job_duration: timedelta = timedelta(hours=48)
+    spark_flags: SparkFlags = field(default_factory=lambda: EmptySparkFlags)
 
     def __call__(self, fn):
         if self.description == "" and fn.__doc__ is not None:
@@ -1289,6 +1414,7 @@ class DFTransformationDecorator:
                 args=self.args,
                 source_text=self.source_text,
                 canonical_func_text=self.canonical_func_text,
+                spark_flags=self.spark_flags,
             ),
             owner=self.owner,
             provider=self.provider,
@@ -1497,6 +1623,12 @@ class ColumnResource(ABC):
         name: str = "",
         variant: str = "",
         resource_snowflake_config: Optional[ResourceSnowflakeConfig] = None,
+        allow_breaking: bool = False,
+        materialization_filter: str = "",
+        default_value: Any = None,
+        column_casts: Optional[Dict[str, Union[ScalarType, str]]] = None,
+        timestamp_format: Optional[TimestampFormat] = None,
+        materialization_partition: Optional[MaterializationPartition] = None,
     ):
         registrar, source_name_variant, columns = transformation_args
         self.type = type if isinstance(type, str) else type.value
@@ -1522,6 +1654,12 @@ class ColumnResource(ABC):
         self.properties = properties
         self.variant = variant
         self.resource_snowflake_config = resource_snowflake_config
+        self.allow_breaking = allow_breaking
+        self.materialization_filter = materialization_filter
+        self.default_value = default_value
+        self.column_casts = column_casts
+        self.timestamp_format = timestamp_format
+        self.materialization_partition = materialization_partition
 
     def register(self):
         features, labels = self.get_resources_by_type(self.resource_type)
@@ -1552,6 +1690,12 @@ class ColumnResource(ABC):
                 "tags": self.tags,
                 "properties": self.properties,
                 "resource_snowflake_config": self.resource_snowflake_config,
+                "allow_breaking": self.allow_breaking,
+                "materialization_filter": self.materialization_filter,
+                "default_value": self.default_value,
+                "column_casts": self.column_casts,
+                "timestamp_format": self.timestamp_format,
+                "materialization_partition": self.materialization_partition,
             }
         ]
 
@@ -1613,6 +1757,12 @@ class FeatureColumnResource(ColumnResource):
         tags: Optional[List[str]] = None,
         properties: Optional[Dict[str, str]] = None,
         resource_snowflake_config: Optional[ResourceSnowflakeConfig] = None,
+        allow_breaking: bool = False,
+        materialization_filter: str = "",
+        default_value: Any = None,
+        column_casts: Optional[Dict[str, Union[ScalarType, str]]] = None,
+        timestamp_format: Optional[TimestampFormat] = None,
+        materialization_partition: Optional[MaterializationPartition] = None,
     ):
         """
         Feature registration object.
@@ -1635,6 +1785,12 @@ class FeatureColumnResource(ColumnResource):
             variant (str): An optional variant name for the feature.
             type (Union[ScalarType, str]): The type of the value in for the feature.
             inference_store (Union[str, OnlineProvider, FileStoreProvider]): Where to store for online serving.
+            allow_breaking (bool): Allows the type to change in a way that isn't backward compatible with the feature's current default variant (e.g. int to string).
+            materialization_filter (str): A SQL predicate over the source's columns (e.g. "status = 'active'"). Only the rows it matches are materialized.
+            default_value (Any): A value of the feature's type that's served for entities without a value in the inference store.
+            column_casts (Dict[str, Union[ScalarType, str]]): Casts the entity or value column to another type when the feature is registered (e.g. {"Amount": ff.Int}). Entity columns can only be cast to strings.
+            timestamp_format (TimestampFormat): Parses the timestamp column when the feature is registered, if it holds strings or epoch numbers instead of timestamps.
+            materialization_partition (MaterializationPartition): Splits the feature's materialized files into a directory per partition (e.g. ff.MaterializationPartition(type="DATE")).
         """
         super().__init__(
             transformation_args=transformation_args,
@@ -1651,6 +1807,12 @@ class FeatureColumnResource(ColumnResource):
             tags=tags,
             properties=properties,
             resource_snowflake_config=resource_snowflake_config,
+            allow_breaking=allow_breaking,
+            materialization_filter=materialization_filter,
+            default_value=default_value,
+            column_casts=column_casts,
+            timestamp_format=timestamp_format,
+            materialization_partition=materialization_partition,
         )
 
 
@@ -1827,6 +1989,8 @@ class LabelColumnResource(ColumnResource):
         tags: List[str] = [],
         properties: Dict[str, str] = {},
         resource_snowflake_config: Optional[ResourceSnowflakeConfig] = None,
+        column_casts: Optional[Dict[str, Union[ScalarType, str]]] = None,
+        timestamp_format: Optional[TimestampFormat] = None,
     ):
         """
         Label registration object.
@@ -1847,6 +2011,8 @@ class LabelColumnResource(ColumnResource):
             transformation_args (tuple): A transformation or source function and the columns name in the format: <transformation_function>[[<entity_column>, <value_column>, <timestamp_column (optional)>]]
             variant (str): An optional variant name for the label.
             type (Union[ScalarType, str]): The type of the value in for the label.
+            column_casts (Dict[str, Union[ScalarType, str]]): Casts the entity or value column to another type when the label is registered (e.g. {"Amount": ff.Int}). Entity columns can only be cast to strings.
+            timestamp_format (TimestampFormat): Parses the timestamp column when the label is registered, if it holds strings or epoch numbers instead of timestamps.
         """
         super().__init__(
             transformation_args=transformation_args,
@@ -1862,6 +2028,8 @@ class LabelColumnResource(ColumnResource):
             tags=tags,
             properties=properties,
             resource_snowflake_config=resource_snowflake_config,
+            column_casts=column_casts,
+            timestamp_format=timestamp_format,
         )
 
 
@@ -2737,7 +2905,7 @@ class Registrar:
     def register_redis(
         self,
         name: str,
-        host: str,
+        host: str = "",
         port: int = 6379,
         db: int = 0,
         password: str = "",
@@ -2745,6 +2913,16 @@ class Registrar:
         team: str = "",
         tags: Optional[List[str]] = None,
         properties: Optional[dict] = None,
+        cluster_nodes: Optional[List[str]] = None,
+        sentinel_master: str = "",
+        sentinel_addrs: Optional[List[str]] = None,
+        username: str = "",
+        tls: bool = False,
+        tls_ca_cert: str = "",
+        tls_cert: str = "",
+        tls_key: str = "",
+        tls_insecure_skip_verify: bool = False,
+        direct_copy: bool = False,
     ):
         """Register a Redis provider.
 
@@ -2759,6 +2937,16 @@ class Registrar:
         )
         ```
 
+        A Redis Cluster is registered with its seed nodes, and a deployment
+        monitored by Sentinel with its master name and Sentinel addresses:
+        ```
+        redis = ff.register_redis(
+            name="redis-cluster",
+            cluster_nodes=["redis-node-1:6379", "redis-node-2:6379"],
+            password="password",
+        )
+        ```
+
         Args:
             name (str): (Immutable) Name of Redis provider to be registered
             host (str): (Immutable) Hostname for Redis
@@ -2769,12 +2957,37 @@ class Registrar:
             team (str): (Mutable) Name of team
             tags (Optional[List[str]]): (Mutable) Optional grouping mechanism for resources
             properties (Optional[dict]): (Mutable) Optional grouping mechanism for resources
+            cluster_nodes (Optional[List[str]]): (Mutable) Seed nodes of a Redis Cluster, as host:port
+            sentinel_master (str): (Immutable) Name that the Sentinels monitor the primary as
+            sentinel_addrs (Optional[List[str]]): (Mutable) Sentinel addresses, as host:port
+            username (str): (Mutable) Redis ACL username
+            tls (bool): (Immutable) Encrypt connections to Redis with TLS
+            tls_ca_cert (str): (Mutable) PEM encoded CA certificate to verify the server with, defaults to the system's CAs
+            tls_cert (str): (Mutable) PEM encoded client certificate
+            tls_key (str): (Mutable) PEM encoded client key
+            tls_insecure_skip_verify (bool): (Immutable) Skip verifying the server's certificate
+            direct_copy (bool): (Mutable) Let Spark write materialized features straight into Redis, unless TLS or an ACL username is used
 
         Returns:
             redis (OnlineProvider): Provider
         """
         tags, properties = set_tags_properties(tags, properties)
-        config = RedisConfig(host=host, port=port, password=password, db=db)
+        config = RedisConfig(
+            host=host,
+            port=port,
+            password=password,
+            db=db,
+            cluster_nodes=cluster_nodes,
+            sentinel_master=sentinel_master,
+            sentinel_addrs=sentinel_addrs,
+            username=username,
+            tls=tls,
+            tls_ca_cert=tls_ca_cert,
+            tls_cert=tls_cert,
+            tls_key=tls_key,
+            tls_insecure_skip_verify=tls_insecure_skip_verify,
+            direct_copy=direct_copy,
+        )
         provider = Provider(
             name=name,
             function="ONLINE",
@@ -2787,6 +3000,50 @@ class Registrar:
         self.__resources.append(provider)
         return OnlineProvider(self, provider)
 
+    def register_memory_online(
+        self,
+        name: str,
+        description: str = "",
+        team: str = "",
+        tags: Optional[List[str]] = None,
+        properties: Optional[dict] = None,
+    ):
+        """Register an in-memory online store, for local development and tests.
+
+        Values are kept in the memory of the Featureform server that materializes
+        and serves them. They aren't persisted, so they're lost when it restarts.
+
+        **Examples**:
+        ```
+        memory = ff.register_memory_online(
+            name="memory-quickstart",
+            description="An in-memory online store for the Featureform quickstart"
+        )
+        ```
+
+        Args:
+            name (str): (Immutable) Name of the provider to be registered
+            description (str): (Mutable) Description of the provider to be registered
+            team (str): (Mutable) Name of team
+            tags (Optional[List[str]]): (Mutable) Optional grouping mechanism for resources
+            properties (Optional[dict]): (Mutable) Optional grouping mechanism for resources
+
+        Returns:
+            memory (OnlineProvider): Provider
+        """
+        tags, properties = set_tags_properties(tags, properties)
+        provider = Provider(
+            name=name,
+            function="ONLINE",
+            description=description,
+            team=team,
+            config=MemoryOnlineConfig(),
+            tags=tags,
+            properties=properties,
+        )
+        self.__resources.append(provider)
+        return OnlineProvider(self, provider)
+
     def register_pinecone(
         self,
         name: str,
@@ -3223,6 +3480,65 @@ class Registrar:
         self.__resources.append(provider)
         return OnlineProvider(self, provider)
 
+    def register_bigtable(
+        self,
+        name: str,
+        project_id: str,
+        instance_id: str,
+        credentials: GCPCredentials,
+        table_prefix: str = "",
+        description: str = "",
+        team: str = "",
+        tags: List[str] = [],
+        properties: dict = {},
+    ):
+        """Register a Bigtable provider.
+
+        **Examples**:
+        ```
+        bigtable = ff.register_bigtable(
+            name="bigtable-quickstart",
+            description="A Bigtable deployment we created for the Featureform quickstart",
+            project_id="quickstart-project",
+            instance_id="quickstart-instance",
+            table_prefix="featureform_",
+            credentials=ff.GCPCredentials(...)
+        )
+        ```
+
+        Args:
+            name (str): (Immutable) Name of Bigtable provider to be registered
+            project_id (str): (Immutable) The Project name in GCP
+            instance_id (str): (Immutable) The Bigtable instance under the given project ID
+            credentials (GCPCredentials): (Mutable) GCP credentials to access Bigtable
+            table_prefix (str): (Immutable) Prefix of the tables Featureform creates in the instance
+            description (str): (Mutable) Description of Bigtable provider to be registered
+            team (str): (Mutable) The name of the team registering the provider
+            tags (List[str]): (Mutable) Optional grouping mechanism for resources
+            properties (dict): (Mutable) Optional grouping mechanism for resources
+
+        Returns:
+            bigtable (OnlineProvider): Provider
+        """
+        tags, properties = set_tags_properties(tags, properties)
+        config = BigtableConfig(
+            project_id=project_id,
+            instance_id=instance_id,
+            credentials=credentials,
+            table_prefix=table_prefix,
+        )
+        provider = Provider(
+            name=name,
+            function="ONLINE",
+            description=description,
+            team=team,
+            config=config,
+            tags=tags,
+            properties=properties,
+        )
+        self.__resources.append(provider)
+        return OnlineProvider(self, provider)
+
     # TODO: Check these fields
     def register_cassandra(
         self,
@@ -3362,6 +3678,10 @@ class Registrar:
         team: str = "",
         tags: List[str] = [],
         properties: dict = {},
+        vector_index: str = "",
+        vector_similarity: str = "",
+        direct_copy: bool = False,
+        partition_key: str = "",
     ):
         """Register a MongoDB provider.
 
@@ -3375,7 +3695,9 @@ class Registrar:
             database="featureform_database"
             host="my-mongodb.host.com",
             port="10225",
-            throughput=10000
+            throughput=10000,
+            vector_index="featureform_embeddings",
+            vector_similarity="cosine",
         )
         ```
 
@@ -3391,6 +3713,10 @@ class Registrar:
             team (str): (Mutable) Name of team
             tags (List[str]): (Mutable) Optional grouping mechanism for resources
             properties (dict): (Mutable) Optional grouping mechanism for resources
+            vector_index (str): (Immutable) Name of the Atlas Vector Search index created for each embedding; required to store embeddings
+            vector_similarity (str): (Immutable) Similarity metric of the vector index, either "cosine" (default) or "euclidean"
+            direct_copy (bool): (Mutable) Let Spark write materialized features straight into Cosmos DB
+            partition_key (str): (Immutable) Field to shard each feature's collection on; only "entity" is supported, and collections are unsharded if it's empty
 
         Returns:
             mongodb (OnlineProvider): Provider
@@ -3403,6 +3729,10 @@ class Registrar:
             port=port,
             database=database,
             throughput=throughput,
+            vector_index=vector_index,
+            vector_similarity=vector_similarity,
+            direct_copy=direct_copy,
+            partition_key=partition_key,
         )
         provider = Provider(
             name=name,
@@ -3432,6 +3762,8 @@ class Registrar:
         properties: dict = {},
         catalog: Optional[SnowflakeCatalog] = None,
         session_params: Optional[Dict[str, str]] = None,
+        private_key: str = "",
+        private_key_passphrase: str = "",
     ):
         """Register a Snowflake provider using legacy credentials.
 
@@ -3472,6 +3804,8 @@ class Registrar:
             team (str): (Mutable) Name of team
             catalog (SnowflakeCatalog): Catalog configuration and default dynamic table configuration
             session_params (Dict[str, str]): (Mutable) Session parameters to set for the Snowflake session
+            private_key (str): (Mutable) PEM encoded private key to authenticate with instead of the password
+            private_key_passphrase (str): (Mutable) Passphrase of the private key, if it's encrypted
             tags (List[str]): (Mutable) Optional grouping mechanism for resources
             properties (dict): (Mutable) Optional grouping mechanism for resources
 
@@ -3489,6 +3823,8 @@ class Registrar:
             role=role,
             catalog=catalog,
             session_params=session_params,
+            private_key=private_key,
+            private_key_passphrase=private_key_passphrase,
         )
         provider = Provider(
             name=name,
@@ -3520,6 +3856,8 @@ class Registrar:
         properties: dict = {},
         catalog: Optional[SnowflakeCatalog] = None,
         session_params: Optional[Dict[str, str]] = None,
+        private_key: str = "",
+        private_key_passphrase: str = "",
     ):
         """Register a Snowflake provider.
 
@@ -3562,6 +3900,8 @@ class Registrar:
             team (str): (Mutable) Name of team
             catalog (SnowflakeCatalog): Catalog configuration and default dynamic table configuration
             session_params (Dict[str, str]): (Mutable) Session parameters to set for the Snowflake session
+            private_key (str): (Mutable) PEM encoded private key to authenticate with instead of the password
+            private_key_passphrase (str): (Mutable) Passphrase of the private key, if it's encrypted
             tags (List[str]): (Mutable) Optional grouping mechanism for resources
             properties (dict): (Mutable) Optional grouping mechanism for resources
 
@@ -3580,6 +3920,8 @@ class Registrar:
             role=role,
             catalog=catalog,
             session_params=session_params,
+            private_key=private_key,
+            private_key_passphrase=private_key_passphrase,
         )
         provider = Provider(
             name=name,
@@ -3726,6 +4068,70 @@ class Registrar:
         self.__resources.append(provider)
         return OfflineSQLProvider(self, provider)
 
+    def register_databricks_sql(
+        self,
+        name: str,
+        host: str,
+        http_path: str,
+        token: str,
+        catalog: str = "",
+        schema: str = "",
+        description: str = "",
+        team: str = "",
+        tags: List[str] = [],
+        properties: dict = {},
+    ):
+        """Register a Databricks SQL Warehouse provider. Transformations run as SQL
+        on the warehouse, without submitting a Spark job to a cluster.
+
+        **Examples**:
+        ```
+        databricks_sql = ff.register_databricks_sql(
+            name="databricks-sql-quickstart",
+            description="A Databricks SQL Warehouse we created for the Featureform quickstart",
+            host="dbc-1234abcd-5678.cloud.databricks.com",
+            http_path="/sql/1.0/warehouses/1234567890abcdef",
+            token="<token>",
+            catalog="main",
+            schema="featureform",
+        )
+        ```
+
+        Args:
+            name (str): (Immutable) Name of Databricks SQL provider to be registered
+            host (str): (Immutable) Hostname of the Databricks workspace
+            http_path (str): (Mutable) HTTP path of the SQL Warehouse, from its connection details
+            token (str): (Mutable) Databricks personal access token
+            catalog (str): (Immutable) Unity Catalog catalog, the warehouse's default if unset
+            schema (str): (Immutable) Schema, the warehouse's default if unset
+            description (str): (Mutable) Description of Databricks SQL provider to be registered
+            team (str): (Mutable) Name of team
+            tags (List[str]): (Mutable) Optional grouping mechanism for resources
+            properties (dict): (Mutable) Optional grouping mechanism for resources
+
+        Returns:
+            databricks_sql (OfflineSQLProvider): Provider
+        """
+        tags, properties = set_tags_properties(tags, properties)
+        config = DatabricksSQLConfig(
+            host=host,
+            http_path=http_path,
+            token=token,
+            catalog=catalog,
+            schema=schema,
+        )
+        provider = Provider(
+            name=name,
+            function="OFFLINE",
+            description=description,
+            team=team,
+            config=config,
+            tags=tags,
+            properties=properties,
+        )
+        self.__resources.append(provider)
+        return OfflineSQLProvider(self, provider)
+
     def register_redshift(
         self,
         name: str,
@@ -3737,6 +4143,7 @@ class Registrar:
         description: str = "",
         team: str = "",
         sslmode: str = "disable",
+        iam_role: str = "",
         tags: List[str] = [],
         properties: dict = {},
     ):
@@ -3763,6 +4170,7 @@ class Registrar:
             user (str): (Mutable) User
             password (str): (Mutable) Redshift password
             sslmode (str): (Mutable) SSL mode
+            iam_role (str): (Mutable) ARN of the IAM role Redshift assumes to export training sets to S3 and import sources from it, or "default" to use the cluster's default role
             description (str): (Mutable) Description of Redshift provider to be registered
             team (str): (Mutable) Name of team
             tags (List[str]): (Mutable) Optional grouping mechanism for resources
@@ -3779,6 +4187,7 @@ class Registrar:
             user=user,
             password=password,
             sslmode=sslmode,
+            iam_role=iam_role,
         )
         provider = Provider(
             name=name,
@@ -3804,6 +4213,8 @@ class Registrar:
         team: str = "",
         tags: List[str] = [],
         properties: dict = {},
+        location: str = "",
+        labels: Optional[Dict[str, str]] = None,
     ):
         """Register a BigQuery provider.
 
@@ -3814,7 +4225,9 @@ class Registrar:
             description="A BigQuery deployment we created for the Featureform quickstart",
             project_id="quickstart-project",
             dataset_id="quickstart-dataset",
-            credentials=GCPCredentials(...)
+            credentials=GCPCredentials(...),
+            location="europe-west2",
+            labels={"team": "fraud"},
         )
         ```
 
@@ -3827,6 +4240,8 @@ class Registrar:
             team (str): (Mutable) Name of team
             tags (List[str]): (Mutable) Optional grouping mechanism for resources
             properties (dict): (Mutable) Optional grouping mechanism for resources
+            location (str): (Immutable) Location that BigQuery jobs run in; must match the dataset's location
+            labels (Dict[str, str]): (Mutable) Labels added to every BigQuery job, e.g. for cost attribution
 
         Returns:
             bigquery (OfflineSQLProvider): Provider
@@ -3837,6 +4252,8 @@ class Registrar:
             project_id=project_id,
             dataset_id=dataset_id,
             credentials=credentials,
+            location=location,
+            labels=labels,
         )
         provider = Provider(
             name=name,
@@ -3962,6 +4379,88 @@ class Registrar:
         self.__resources.append(provider)
         return OfflineK8sProvider(self, provider)
 
+    def register_kafka(
+        self,
+        name: str,
+        brokers: List[str],
+        format: str = "JSON",
+        schema_registry_url: str = "",
+        schema_registry_username: str = "",
+        schema_registry_password: str = "",
+        username: str = "",
+        password: str = "",
+        tls: bool = False,
+        raw_events_store: Optional[FileStoreProvider] = None,
+        raw_events_path: str = "",
+        description: str = "",
+        team: str = "",
+        tags: List[str] = [],
+        properties: dict = {},
+    ):
+        """Register a Kafka cluster. Topics registered on it can be used as streaming sources.
+
+        **Examples**:
+        ```
+        kafka = ff.register_kafka(
+            name="kafka-quickstart",
+            brokers=["broker-1:9092", "broker-2:9092"],
+            format="AVRO",
+            schema_registry_url="http://schema-registry:8081",
+            raw_events_store=s3,
+            raw_events_path="kafka/raw",
+        )
+        ```
+
+        Args:
+            name (str): (Immutable) Name of Kafka provider to be registered
+            brokers (List[str]): (Mutable) Addresses of the Kafka brokers
+            format (str): (Immutable) Encoding of message values, either JSON or AVRO
+            schema_registry_url (str): (Mutable) Confluent schema registry used to decode AVRO messages
+            schema_registry_username (str): (Mutable) Username for the schema registry
+            schema_registry_password (str): (Mutable) Password for the schema registry
+            username (str): (Mutable) SASL/PLAIN username for the brokers
+            password (str): (Mutable) SASL/PLAIN password for the brokers
+            tls (bool): (Immutable) Whether to connect to the brokers over TLS
+            raw_events_store (FileStoreProvider): (Immutable) Optional file store that received messages are also landed in
+            raw_events_path (str): (Immutable) Path in the raw events store to write messages under
+            description (str): (Mutable) Description of Kafka provider to be registered
+            team (str): (Mutable) The name of the team registering the provider
+            tags (List[str]): (Mutable) Optional grouping mechanism for resources
+            properties (dict): (Mutable) Optional grouping mechanism for resources
+
+        Returns:
+            kafka (KafkaProvider): Provider
+        """
+        tags, properties = set_tags_properties(tags, properties)
+        config = KafkaConfig(
+            brokers=brokers,
+            format=format,
+            schema_registry_url=schema_registry_url,
+            schema_registry_username=schema_registry_username,
+            schema_registry_password=schema_registry_password,
+            username=username,
+            password=password,
+            tls=tls,
+            raw_events_store_type=(
+                raw_events_store.store_type() if raw_events_store else ""
+            ),
+            raw_events_store_config=(
+                raw_events_store.config() if raw_events_store else None
+            ),
+            raw_events_path=raw_events_path,
+        )
+        provider = Provider(
+            name=name,
+            function="OFFLINE",
+            description=description,
+            team=team,
+            config=config,
+            tags=tags,
+            properties=properties,
+        )
+        self.__resources.append(provider)
+        return KafkaProvider(self, provider)
+
     def register_primary_data(
         self,
         name: str,
@@ -4085,6 +4584,9 @@ class Registrar:
         write_options: Optional[Dict[str, str]] = None,
         table_properties: Optional[Dict[str, str]] = None,
         resource_snowflake_config: Optional[ResourceSnowflakeConfig] = None,
+        spark_submit_configs: Optional[Dict[str, str]] = None,
+        spark_packages: Optional[List[str]] = None,
+        incremental_column: str = "",
     ):
         """SQL transformation decorator.
 
@@ -4100,6 +4602,9 @@ class Registrar:
             tags (List[str]): Optional grouping mechanism for resources
             properties (dict): Optional grouping mechanism for resources
             max_job_duration (timedelta): Maximum duration FeatureForm will wait for the job to complete; default is 48 hours; jobs that exceed this duration will be canceled
+            spark_submit_configs (Dict[str, str]): Extra --conf values passed to spark-submit for this transformation (e.g. {"spark.sql.shuffle.partitions": "400"}). Keys must start with "spark.".
+            spark_packages (List[str]): Maven coordinates of packages passed to spark-submit with --packages (e.g. ["org.apache.spark:spark-avro_2.12:3.5.0"]).
+            incremental_column (str): A column that only increases, like a timestamp. Updates append only the rows whose value in it is greater than the last run's, instead of rebuilding the transformation.
 
         Returns:
             decorator (SQLTransformationDecorator): decorator
@@ -4130,8 +4635,11 @@ class Registrar:
                 spark_params=spark_params or {},
                 write_options=write_options or {},
                 table_properties=table_properties or {},
+                submit_configs=spark_submit_configs or {},
+                submit_packages=spark_packages or [],
             ),
             resource_snowflake_config=resource_snowflake_config,
+            incremental_column=incremental_column,
         )
         return decorator
 
@@ -4206,6 +4714,8 @@ class Registrar:
         spark_params: Optional[Dict[str, str]] = None,
         write_options: Optional[Dict[str, str]] = None,
         table_properties: Optional[Dict[str, str]] = None,
+        spark_submit_configs: Optional[Dict[str, str]] = None,
+        spark_packages: Optional[List[str]] = None,
     ):
         """Dataframe transformation decorator.
 
@@ -4220,6 +4730,8 @@ class Registrar:
             tags (List[str]): Optional grouping mechanism for resources
             properties (dict): Optional grouping mechanism for resources
             max_job_duration (timedelta): Maximum duration FeatureForm will wait for the job to complete; default is 48 hours; jobs that exceed this duration will be canceled
+            spark_submit_configs (Dict[str, str]): Extra --conf values passed to spark-submit for this transformation (e.g. {"spark.sql.shuffle.partitions": "400"}). Keys must start with "spark.".
+            spark_packages (List[str]): Maven coordinates of packages passed to spark-submit with --packages (e.g. ["org.apache.spark:spark-avro_2.12:3.5.0"]).
 
         Returns:
             decorator (DFTransformationDecorator): decorator
@@ -4265,6 +4777,13 @@ class Registrar:
             tags=tags,
             properties=properties,
             max_job_duration=max_job_duration,
+            spark_flags=SparkFlags(
+                spark_params=spark_params or {},
+                write_options=write_options or {},
+                table_properties=table_properties or {},
+                submit_configs=spark_submit_configs or {},
+                submit_packages=spark_packages or [],
+            ),
         )
         return decorator
 
@@ -4547,6 +5066,12 @@ class Registrar:
                 properties=feature_properties,
                 additional_parameters=additional_Parameters,
                 resource_snowflake_config=feature.get("resource_snowflake_config"),
+                allow_breaking=feature.get("allow_breaking", False),
+                materialization_filter=feature.get("materialization_filter", ""),
+                default_value=feature.get("default_value"),
+                column_casts=feature.get("column_casts"),
+                timestamp_format=feature.get("timestamp_format"),
+                materialization_partition=feature.get("materialization_partition"),
             )
             self.__resources.append(resource)
             self.map_client_object_to_resource(client_object, resource)
@@ -4583,6 +5108,8 @@ class Registrar:
                 ),
                 tags=label_tags,
                 properties=label_properties,
+                column_casts=label.get("column_casts"),
+                timestamp_format=label.get("timestamp_format"),
             )
             self.__resources.append(resource)
             self.map_client_object_to_resource(client_object, resource)
@@ -4678,6 +5205,13 @@ class Registrar:
         self.__resources.append(stream)
         return ResourceRegistrar(self, [stream], [])
 
+    def __label_nv(self, label):
+        if isinstance(label, str):
+            return (label, self.__run)
+        if isinstance(label, tuple) and label[1] == "":
+            return (label[0], self.__run)
+        return label
+
     def __get_feature_nv(self, features, run):
         feature_nv_list = []
         feature_lags = []
@@ -4747,6 +5281,10 @@ class Registrar:
         provider: str = "",
         resource_snowflake_config: Optional[ResourceSnowflakeConfig] = None,
         type: TrainingSetType = TrainingSetType.DYNAMIC,
+        persist_as: Optional[TrainingSetPersistAs] = None,
+        labels: list = [],
+        split: Optional[TrainingSetSplit] = None,
+        max_feature_age: Optional[timedelta] = None,
     ):
         """Register a training set.
 
@@ -4770,6 +5308,10 @@ class Registrar:
             schedule (str): Kubernetes CronJob schedule string ("* * * * *")
             tags (List[str]): Optional grouping mechanism for resources
             properties (dict): Optional grouping mechanism for resources
+            persist_as (TrainingSetPersistAs): Copies the training set into a table once it's created, so it can be queried and registered as a primary source
+            labels (List[NameVariant]): Labels of a multi-task training set, one column each, instead of a single label. They must share an entity
+            split (TrainingSetSplit): Assigns each row to a train or test split, so each can be served on its own
+            max_feature_age (timedelta): The oldest a feature value may be relative to its label's timestamp; older values are joined as null
 
         Returns:
             resource (ResourceRegistrar): resource
@@ -4792,6 +5334,12 @@ class Registrar:
                 "Label must be entered as a name-variant tuple (e.g. ('fraudulent', 'quickstart')), a resource name, or an instance of LabelColumnResource."
             )
 
+        if labels:
+            if label != ("", ""):
+                raise ValueError("A training set can't set both label and labels")
+            labels = [self.__label_nv(lbl) for lbl in labels]
+            label = labels[0]
+
         for resource in resources:
             features += resource.features()
             resource_label = resource.label()
@@ -4832,6 +5380,10 @@ class Registrar:
             provider=provider,
             resource_snowflake_config=resource_snowflake_config,
             type=type,
+            persist_as=persist_as,
+            labels=labels,
+            split=split,
+            max_feature_age=max_feature_age,
         )
         self.map_client_object_to_resource(resource, resource)
         self.__resources.append(resource)
@@ -6347,12 +6899,15 @@ set_variant_prefix = global_registrar.set_variant_prefix
 get_run = global_registrar.get_run
 register_user = global_registrar.register_user
 register_redis = global_registrar.register_redis
+register_memory_online = global_registrar.register_memory_online
 register_pinecone = global_registrar.register_pinecone
 register_weaviate = global_registrar.register_weaviate
 register_blob_store = global_registrar.register_blob_store
 register_bigquery = global_registrar.register_bigquery
 register_clickhouse = global_registrar.register_clickhouse
+register_databricks_sql = global_registrar.register_databricks_sql
 register_firestore = global_registrar.register_firestore
+register_bigtable = global_registrar.register_bigtable
 register_cassandra = global_registrar.register_cassandra
 register_dynamodb = global_registrar.register_dynamodb
 register_mongodb = global_registrar.register_mongodb
@@ -6362,6 +6917,7 @@ register_postgres = global_registrar.register_postgres
 register_redshift = global_registrar.register_redshift
 register_spark = global_registrar.register_spark
 register_k8s = global_registrar.register_k8s
+register_kafka = global_registrar.register_kafka
 register_s3 = global_registrar.register_s3
 register_hdfs = global_registrar.register_hdfs
 register_gcs = global_registrar.register_gcs
@@ -6401,3 +6957,4 @@ Float32 = ScalarType.FLOAT32
 Float64 = ScalarType.FLOAT64
 Bool = ScalarType.BOOL
 DateTime = ScalarType.DATETIME
+JSON = ScalarType.JSON
@@ -10,10 +10,11 @@ import logging
 import os
 import re
 import sys
+import time
 import uuid
 from abc import ABC, abstractmethod
 from dataclasses import field
-from datetime import timedelta
+from datetime import datetime, timedelta, timezone
 from typing import List, Protocol, Tuple, Union, Optional, Any, Dict, runtime_checkable
 from urllib.parse import urlencode, urlunparse
 
@@ -102,6 +103,16 @@ class RedisConfig:
     port: int
     password: str
     db: int
+    cluster_nodes: Optional[List[str]] = None
+    sentinel_master: str = ""
+    sentinel_addrs: Optional[List[str]] = None
+    username: str = ""
+    tls: bool = False
+    tls_ca_cert: str = ""
+    tls_cert: str = ""
+    tls_key: str = ""
+    tls_insecure_skip_verify: bool = False
+    direct_copy: bool = False
 
     def software(self) -> str:
         return "redis"
@@ -115,6 +126,23 @@ class RedisConfig:
             "Password": self.password,
             "DB": self.db,
         }
+        if self.cluster_nodes:
+            config["Topology"] = "cluster"
+            config["ClusterAddrs"] = self.cluster_nodes
+        elif self.sentinel_master:
+            config["Topology"] = "sentinel"
+            config["SentinelMasterName"] = self.sentinel_master
+            config["SentinelAddrs"] = self.sentinel_addrs or []
+        if self.username:
+            config["Username"] = self.username
+        if self.tls:
+            config["TLS"] = True
+            config["TLSCACert"] = self.tls_ca_cert
+            config["TLSCert"] = self.tls_cert
+            config["TLSKey"] = self.tls_key
+            config["TLSInsecureSkipVerify"] = self.tls_insecure_skip_verify
+        if self.direct_copy:
+            config["DirectCopy"] = True
         return bytes(json.dumps(config), "utf-8")
 
     def __eq__(self, __value: object) -> bool:
@@ -125,9 +153,35 @@ class RedisConfig:
             and self.port == __value.port
             and self.password == __value.password
             and self.db == __value.db
+            and self.cluster_nodes == __value.cluster_nodes
+            and self.sentinel_master == __value.sentinel_master
+            and self.sentinel_addrs == __value.sentinel_addrs
+            and self.username == __value.username
+            and self.tls == __value.tls
+            and self.tls_ca_cert == __value.tls_ca_cert
+            and self.tls_cert == __value.tls_cert
+            and self.tls_key == __value.tls_key
+            and self.tls_insecure_skip_verify == __value.tls_insecure_skip_verify
+            and self.direct_copy == __value.direct_copy
         )
 
 
+@typechecked
+@dataclass
+class MemoryOnlineConfig:
+    def software(self) -> str:
+        return "memory"
+
+    def type(self) -> str:
+        return "MEMORY_ONLINE"
+
+    def serialize(self) -> bytes:
+        return bytes(json.dumps({}), "utf-8")
+
+    def __eq__(self, __value: object) -> bool:
+        return isinstance(__value, MemoryOnlineConfig)
+
+
 @typechecked
 @dataclass
 class PineconeConfig:
@@ -664,6 +718,39 @@ class FirestoreConfig:
         )
 
 
+@typechecked
+@dataclass
+class BigtableConfig:
+    project_id: str
+    instance_id: str
+    credentials: GCPCredentials
+    table_prefix: str = ""
+
+    def software(self) -> str:
+        return "bigtable"
+
+    def type(self) -> str:
+        return "BIGTABLE_ONLINE"
+
+    def serialize(self) -> bytes:
+        config = {
+            "ProjectID": self.project_id,
+            "InstanceID": self.instance_id,
+            "TablePrefix": self.table_prefix,
+            "Credentials": self.credentials.to_json(),
+        }
+        return bytes(json.dumps(config), "utf-8")
+
+    def __eq__(self, __value: object) -> bool:
+        if not isinstance(__value, BigtableConfig):
+            return False
+        return (
+            self.project_id == __value.project_id
+            and self.instance_id == __value.instance_id
+            and self.table_prefix == __value.table_prefix
+        )
+
+
 @typechecked
 @dataclass
 class CassandraConfig:
@@ -816,6 +903,10 @@ class MongoDBConfig:
     port: str
     database: str
     throughput: int
+    vector_index: str = ""
+    vector_similarity: str = ""
+    direct_copy: bool = False
+    partition_key: str = ""
 
     def software(self) -> str:
         return "mongodb"
@@ -832,6 +923,14 @@ class MongoDBConfig:
             "Database": self.database,
             "Throughput": self.throughput,
         }
+        if self.vector_index:
+            config["VectorIndex"] = self.vector_index
+        if self.vector_similarity:
+            config["VectorSimilarity"] = self.vector_similarity
+        if self.direct_copy:
+            config["DirectCopy"] = True
+        if self.partition_key:
+            config["PartitionKey"] = self.partition_key
         return bytes(json.dumps(config), "utf-8")
 
     def __eq__(self, __value: object) -> bool:
@@ -844,6 +943,10 @@ class MongoDBConfig:
             and self.port == __value.port
             and self.database == __value.database
             and self.throughput == __value.throughput
+            and self.vector_index == __value.vector_index
+            and self.vector_similarity == __value.vector_similarity
+            and self.direct_copy == __value.direct_copy
+            and self.partition_key == __value.partition_key
         )
 
 
@@ -927,6 +1030,43 @@ class ClickHouseConfig:
         )
 
 
+@typechecked
+@dataclass
+class DatabricksSQLConfig:
+    host: str
+    http_path: str
+    token: str
+    catalog: str = ""
+    schema: str = ""
+
+    def software(self) -> str:
+        return "databricks_sql"
+
+    def type(self) -> str:
+        return "DATABRICKS_SQL_OFFLINE"
+
+    def serialize(self) -> bytes:
+        config = {
+            "Host": self.host,
+            "HTTPPath": self.http_path,
+            "Token": self.token,
+            "Catalog": self.catalog,
+            "Schema": self.schema,
+        }
+        return bytes(json.dumps(config), "utf-8")
+
+    def __eq__(self, __value: object) -> bool:
+        if not isinstance(__value, DatabricksSQLConfig):
+            return False
+        return (
+            self.host == __value.host
+            and self.http_path == __value.http_path
+            and self.token == __value.token
+            and self.catalog == __value.catalog
+            and self.schema == __value.schema
+        )
+
+
 @typechecked
 @dataclass
 class RedshiftConfig:
@@ -936,6 +1076,7 @@ class RedshiftConfig:
     user: str
     password: str
     sslmode: str
+    iam_role: str = ""
 
     def software(self) -> str:
         return "redshift"
@@ -951,6 +1092,7 @@ class RedshiftConfig:
             "Password": self.password,
             "Database": self.database,
             "SSLMode": self.sslmode,
+            "IAMRole": self.iam_role,
         }
         return bytes(json.dumps(config), "utf-8")
 
@@ -964,6 +1106,7 @@ class RedshiftConfig:
             and self.user == __value.user
             and self.password == __value.password
             and self.sslmode == __value.sslmode
+            and self.iam_role == __value.iam_role
         )
 
 
@@ -973,6 +1116,8 @@ class BigQueryConfig:
     project_id: str
     dataset_id: str
     credentials: GCPCredentials
+    location: str = ""
+    labels: Optional[Dict[str, str]] = None
 
     def software(self) -> str:
         return "bigquery"
@@ -986,6 +1131,10 @@ class BigQueryConfig:
             "DatasetID": self.dataset_id,
             "Credentials": self.credentials.to_json(),
         }
+        if self.location:
+            config["Location"] = self.location
+        if self.labels:
+            config["Labels"] = self.labels
         return bytes(json.dumps(config), "utf-8")
 
     def __eq__(self, __value: object) -> bool:
@@ -994,6 +1143,8 @@ class BigQueryConfig:
         return (
             self.project_id == __value.project_id
             and self.dataset_id == __value.dataset_id
+            and self.location == __value.location
+            and self.labels == __value.labels
         )
 
 
@@ -1064,6 +1215,92 @@ class SnowflakeDynamicTableConfig:
         )
 
 
+@typechecked
+@dataclass
+class TimestampFormat:
+    """
+    How a source's timestamp column is parsed if it isn't a native timestamp.
+
+    Args:
+        encoding (str): One of "epoch_seconds", "epoch_millis", or "iso8601".
+        timezone (str): The IANA zone of ISO-8601 timestamps without an offset (e.g. "America/New_York"). Defaults to UTC.
+    """
+
+    encoding: str
+    timezone: str = ""
+
+    def to_proto(self) -> pb.TimestampFormat:
+        return pb.TimestampFormat(encoding=self.encoding, timezone=self.timezone)
+
+
+@typechecked
+@dataclass
+class MaterializationPartition:
+    """
+    How a feature's materialized files are split into a directory per partition.
+    It only applies to offline stores that materialize features to files, like Spark.
+
+    Args:
+        type (str): Either "DATE", to partition by the date of each value's timestamp, or "ENTITY_BUCKET", to partition by a hash of the entity.
+        buckets (int): The number of buckets when partitioning by "ENTITY_BUCKET".
+    """
+
+    type: str
+    buckets: int = 0
+
+    def to_proto(self) -> pb.MaterializationPartition:
+        return pb.MaterializationPartition(type=self.type, buckets=self.buckets)
+
+
+@typechecked
+@dataclass
+class TrainingSetPersistAs:
+    """
+    A table a training set is copied into once it's created, so it can be queried
+    and registered as a primary source.
+
+    Args:
+        table (str): The table to copy the training set into.
+        overwrite (bool): Replaces the table if it already exists. Otherwise creating the training set fails.
+        dedup (bool): Only copies one of the rows that are identical across every column.
+    """
+
+    table: str
+    overwrite: bool = False
+    dedup: bool = False
+
+    def to_proto(self) -> pb.TrainingSetPersistAs:
+        return pb.TrainingSetPersistAs(
+            table=self.table, overwrite=self.overwrite, dedup=self.dedup
+        )
+
+
+@typechecked
+@dataclass
+class TrainingSetSplit:
+    """
+    A deterministic train/test split of a training set. Each row is assigned by
+    hashing its label's entity and timestamp with the seed, so the same seed always
+    gives the same split. Each split is served with `client.training_set(..., split="train")`.
+
+    Args:
+        train_fraction (float): The fraction of rows in the train split, between 0 and 1 exclusive.
+        seed (int): Changes which rows land in each split.
+    """
+
+    train_fraction: float
+    seed: int = 0
+
+    def __post_init__(self):
+        if not 0 < self.train_fraction < 1:
+            raise ValueError(
+                f"train_fraction must be between 0 and 1, got {self.train_fraction}"
+            )
+
+    def to_proto(self) -> pb.TrainingSetSplit:
+        return pb.TrainingSetSplit(train_fraction=self.train_fraction, seed=self.seed)
+
+
 @dataclass
 class ResourceSnowflakeConfig:
     dynamic_table_config: Optional[SnowflakeDynamicTableConfig] = None
@@ -1181,6 +1418,8 @@ class SnowflakeConfig:
     role: str = ""
     catalog: Optional[SnowflakeCatalog] = None
     session_params: Optional[Dict[str, str]] = None
+    private_key: str = ""
+    private_key_passphrase: str = ""
 
     def __post_init__(self):
         if self.session_params:
@@ -1231,6 +1470,9 @@ class SnowflakeConfig:
             "Catalog": self.catalog.config() if self.catalog is not None else None,
             "SessionParams": self.session_params,
         }
+        if self.private_key:
+            config["PrivateKey"] = self.private_key
+            config["PrivateKeyPassphrase"] = self.private_key_passphrase
         return bytes(json.dumps(config), "utf-8")
 
     def __eq__(self, __value: object) -> bool:
@@ -1262,6 +1504,8 @@ class SnowflakeConfig:
             and self.role == __value.role
             and is_catalog_equal
             and self.session_params == __value.session_params
+            and self.private_key == __value.private_key
+            and self.private_key_passphrase == __value.private_key_passphrase
         )
 
 
@@ -1270,12 +1514,16 @@ class SparkFlags:
     spark_params: Dict[str, str] = field(default_factory=dict)
     write_options: Dict[str, str] = field(default_factory=dict)
     table_properties: Dict[str, str] = field(default_factory=dict)
+    submit_configs: Dict[str, str] = field(default_factory=dict)
+    submit_packages: List[str] = field(default_factory=list)
 
     def serialize(self) -> dict:
         return {
             "SparkParams": self.spark_params,
             "WriteOptions": self.write_options,
             "TableProperties": self.table_properties,
+            "SubmitConfigs": self.submit_configs,
+            "SubmitPackages": self.submit_packages,
         }
 
     @classmethod
@@ -1295,6 +1543,8 @@ class SparkFlags:
             spark_params=config.get("SparkParams", {}),
             write_options=config.get("WriteOptions", {}),
             table_properties=config.get("TableProperties", {}),
+            submit_configs=config.get("SubmitConfigs", {}),
+            submit_packages=config.get("SubmitPackages", []),
         )
 
     def to_proto(self) -> pb.SparkFlags:
@@ -1309,6 +1559,8 @@ class SparkFlags:
                 pb.TableProperty(key=k, value=v)
                 for k, v in self.table_properties.items()
             ],
+            submit_configs=self.submit_configs,
+            submit_packages=self.submit_packages,
         )
 
 
@@ -1410,6 +1662,55 @@ class K8sConfig:
         return bytes(json.dumps(config), "utf-8")
 
 
+@typechecked
+@dataclass
+class KafkaConfig:
+    brokers: List[str]
+    format: str = "JSON"
+    schema_registry_url: str = ""
+    schema_registry_username: str = ""
+    schema_registry_password: str = ""
+    username: str = ""
+    password: str = ""
+    tls: bool = False
+    raw_events_store_type: str = ""
+    raw_events_store_config: Optional[dict] = None
+    raw_events_path: str = ""
+
+    def software(self) -> str:
+        return "kafka"
+
+    def type(self) -> str:
+        return "KAFKA"
+
+    def serialize(self) -> bytes:
+        config = {
+            "Brokers": self.brokers,
+            "Format": self.format,
+            "SchemaRegistryURL": self.schema_registry_url,
+            "SchemaRegistryUsername": self.schema_registry_username,
+            "SchemaRegistryPassword": self.schema_registry_password,
+            "Username": self.username,
+            "Password": self.password,
+            "TLS": self.tls,
+            "RawEventsStoreType": self.raw_events_store_type,
+            "RawEventsStoreConfig": self.raw_events_store_config,
+            "RawEventsPath": self.raw_events_path,
+        }
+        return bytes(json.dumps(config), "utf-8")
+
+    def __eq__(self, __value: object) -> bool:
+        if not isinstance(__value, KafkaConfig):
+            return False
+        return (
+            self.brokers == __value.brokers
+            and self.format == __value.format
+            and self.schema_registry_url == __value.schema_registry_url
+            and self.raw_events_store_type == __value.raw_events_store_type
+            and self.raw_events_path == __value.raw_events_path
+        )
+
+
 @typechecked
 @dataclass
 class EmptyConfig:
@@ -1428,19 +1729,23 @@ class EmptyConfig:
 
 Config = Union[
     RedisConfig,
+    MemoryOnlineConfig,
     PineconeConfig,
     SnowflakeConfig,
     PostgresConfig,
     ClickHouseConfig,
+    DatabricksSQLConfig,
     RedshiftConfig,
     PineconeConfig,
     BigQueryConfig,
     FirestoreConfig,
+    BigtableConfig,
     SparkConfig,
     OnlineBlobConfig,
     AzureFileStoreConfig,
     S3StoreConfig,
     K8sConfig,
+    KafkaConfig,
     MongoDBConfig,
     GCSFileStoreConfig,
     EmptyConfig,
@@ -1525,6 +1830,7 @@ class Provider:
             "SPARK_OFFLINE",
             "REDSHIFT_OFFLINE",
             "CLICKHOUSE_OFFLINE",
+            "DATABRICKS_SQL_OFFLINE",
         ]:
             self.has_health_check = True
 
@@ -1569,7 +1875,7 @@ class Provider:
             ),
             request_id="",
         )
-        stub.CreateProvider(serialized)
+        _send_create(stub.CreateProvider, serialized)
         return None, None
 
     def to_dictionary(self):
@@ -1612,7 +1918,7 @@ class User:
             request_id="",
         )
 
-        stub.CreateUser(serialized)
+        _send_create(stub.CreateUser, serialized)
         return None, None
 
     def to_dictionary(self):
@@ -1652,6 +1958,49 @@ class SQLTable(Location):
         )
 
 
+@typechecked
+@dataclass
+class CSVOptions:
+    """
+    Describes how a delimited text file, like a TSV or pipe-delimited file, is parsed.
+    Empty options keep the defaults: comma delimited, double-quoted fields where a quote
+    is escaped by doubling it, and a header row.
+    """
+
+    delimiter: str = ""
+    quote: str = ""
+    escape: str = ""
+    header: bool = True
+
+    def __post_init__(self):
+        for name in ("delimiter", "quote", "escape"):
+            value = getattr(self, name)
+            if len(value) > 1 or value in ("\r", "\n"):
+                raise ValueError(
+                    f"CSV {name} must be a single character, got {value!r}"
+                )
+
+    def is_default(self) -> bool:
+        return self == CSVOptions()
+
+    def to_proto(self):
+        return pb.CSVOptions(
+            delimiter=self.delimiter,
+            quote=self.quote,
+            escape=self.escape,
+            no_header=not self.header,
+        )
+
+    @staticmethod
+    def from_proto(csv_options):
+        return CSVOptions(
+            delimiter=csv_options.delimiter,
+            quote=csv_options.quote,
+            escape=csv_options.escape,
+            header=not csv_options.no_header,
+        )
+
+
 @typechecked
 @dataclass
 class FileStore(Location):
@@ -1660,13 +2009,17 @@ class FileStore(Location):
     """
 
     path_uri: str
+    csv_options: Optional[CSVOptions] = None
 
     def resource_identifier(self):
         return self.path_uri
 
     @staticmethod
     def from_proto(source_filestore):
-        return FileStore(path_uri=source_filestore.path)
+        csv_options = None
+        if source_filestore.HasField("csv_options"):
+            csv_options = CSVOptions.from_proto(source_filestore.csv_options)
+        return FileStore(path_uri=source_filestore.path, csv_options=csv_options)
 
 
 @typechecked
@@ -1750,8 +2103,14 @@ class PrimaryData:
                 name=self.location.name,
             )
         elif isinstance(self.location, FileStore):
+            csv_options = self.location.csv_options
             primary_data_kwargs["filestore"] = pb.FileStoreTable(
                 path=self.location.resource_identifier(),
+                csv_options=(
+                    csv_options.to_proto()
+                    if csv_options is not None and not csv_options.is_default()
+                    else None
+                ),
             )
         elif isinstance(self.location, GlueCatalogTable):
             primary_data_kwargs["catalog"] = pb.CatalogTable(
@@ -1804,6 +2163,7 @@ class SQLTransformation(Transformation):
     partition_options: Optional[PartitionType] = None
     spark_flags: SparkFlags = field(default_factory=lambda: EmptySparkFlags)
     resource_snowflake_config: Optional[ResourceSnowflakeConfig] = None
+    incremental_column: str = ""
 
     _sql_placeholder_regex: str = field(
         default=r"\{\{\s*\w+\s*\}\}", init=False, repr=False
@@ -1865,6 +2225,7 @@ class SQLTransformation(Transformation):
                     if self.resource_snowflake_config
                     else None
                 ),
+                incremental_column=self.incremental_column,
             ),
             spark_flags=self.spark_flags.to_proto() if self.spark_flags else None,
             **partition_kwargs,
@@ -2076,7 +2437,7 @@ class SourceVariant(ResourceVariant):
             req_id, stub
         )
         if existing_variant is None:
-            stub.CreateSourceVariant(serialized)
+            _send_create(stub.CreateSourceVariant, serialized)
         return serialized.source_variant.variant, existing_variant
 
     def get_status(self):
@@ -2117,7 +2478,7 @@ class Entity:
             request_id="",
         )
 
-        stub.CreateEntity(serialized)
+        _send_create(stub.CreateEntity, serialized)
         return None, None
 
     def to_dictionary(self):
@@ -2194,6 +2555,27 @@ Additional_Parameters = Union[
 ]
 
 
+def encode_column_casts(
+    casts: Optional[Dict[str, Union[ScalarType, str]]],
+) -> Dict[str, pb.ValueType]:
+    """Serializes the types source columns are cast to when a resource is registered."""
+    if not casts:
+        return {}
+    return {column: ScalarType(cast).to_proto() for column, cast in casts.items()}
+
+
+def encode_default_value(value: Any) -> str:
+    """JSON encodes a feature's default value. Datetimes are encoded as RFC 3339
+    strings in UTC; naive datetimes are assumed to already be in UTC."""
+    if value is None:
+        return ""
+    if isinstance(value, datetime):
+        if value.tzinfo is None:
+            value = value.replace(tzinfo=timezone.utc)
+        value = value.astimezone(timezone.utc).isoformat().replace("+00:00", "Z")
+    return json.dumps(value)
+
+
 @typechecked
 @dataclass
 class FeatureVariant(ResourceVariant):
@@ -2216,6 +2598,12 @@ class FeatureVariant(ResourceVariant):
     additional_parameters: Optional[Additional_Parameters] = None
     server_status: Optional[ServerStatus] = None
     resource_snowflake_config: Optional[ResourceSnowflakeConfig] = None
+    allow_breaking: bool = False
+    materialization_filter: str = ""
+    default_value: Any = None
+    column_casts: Optional[dict] = None
+    timestamp_format: Optional[TimestampFormat] = None
+    materialization_partition: Optional[MaterializationPartition] = None
 
     def __post_init__(self):
         if isinstance(self.value_type, str):
@@ -2298,6 +2686,18 @@ class FeatureVariant(ResourceVariant):
                 if self.resource_snowflake_config
                 else None
             ),
+            allow_breaking=self.allow_breaking,
+            materialization_filter=self.materialization_filter,
+            default_value=encode_default_value(self.default_value),
+            column_casts=encode_column_casts(self.column_casts),
+            timestamp_format=(
+                self.timestamp_format.to_proto() if self.timestamp_format else None
+            ),
+            materialization_partition=(
+                self.materialization_partition.to_proto()
+                if self.materialization_partition
+                else None
+            ),
         )
 
         # Initialize the FeatureVariantRequest message with the FeatureVariant message
@@ -2319,7 +2719,7 @@ class FeatureVariant(ResourceVariant):
             req_id, stub
         )
         if existing_variant is None:
-            stub.CreateFeatureVariant(serialized)
+            _send_create(stub.CreateFeatureVariant, serialized)
         return serialized.feature_variant.variant, existing_variant
 
     def get_status(self):
@@ -2445,7 +2845,7 @@ class StreamFeature(BaseStream):
             tags=pb.Tags(tag=self.tags),
             properties=Properties(self.properties).serialized,
         )
-        stub.CreateFeatureVariant(serialized)
+        _send_create(stub.CreateFeatureVariant, serialized)
         return None, None
 
 
@@ -2493,7 +2893,7 @@ class StreamLabel(BaseStream):
             tags=pb.Tags(tag=self.tags),
             properties=Properties(self.properties).serialized,
         )
-        stub.CreateLabelVariant(serialized)
+        _send_create(stub.CreateLabelVariant, serialized)
         return None, None
 
 
@@ -2565,7 +2965,7 @@ class OnDemandFeatureVariant(ResourceVariant):
             req_id, stub
         )
         if existing_variant is None:
-            stub.CreateFeatureVariant(serialized)
+            _send_create(stub.CreateFeatureVariant, serialized)
         return serialized.feature_variant.variant, existing_variant
 
     def get(self, stub) -> "OnDemandFeatureVariant":
@@ -2661,6 +3061,8 @@ class LabelVariant(ResourceVariant):
     error: Optional[str] = None
     server_status: Optional[ServerStatus] = None
     resource_snowflake_config: Optional[ResourceSnowflakeConfig] = None
+    column_casts: Optional[dict] = None
+    timestamp_format: Optional[TimestampFormat] = None
 
     def __post_init__(self):
         if isinstance(self.value_type, str):
@@ -2749,6 +3151,10 @@ class LabelVariant(ResourceVariant):
                 if self.resource_snowflake_config
                 else None
             ),
+            column_casts=encode_column_casts(self.column_casts),
+            timestamp_format=(
+                self.timestamp_format.to_proto() if self.timestamp_format else None
+            ),
         )
         if isinstance(self.location, ResourceLocation):
             label_variant.entity_mappings.CopyFrom(
@@ -2776,7 +3182,7 @@ class LabelVariant(ResourceVariant):
             req_id, stub
         )
         if existing_variant is None:
-            stub.CreateLabelVariant(serialized)
+            _send_create(stub.CreateLabelVariant, serialized)
         return serialized.label_variant.variant, existing_variant
 
     def get_status(self):
@@ -2912,6 +3318,10 @@ class TrainingSetVariant(ResourceVariant):
     server_status: Optional[ServerStatus] = None
     resource_snowflake_config: Optional[ResourceSnowflakeConfig] = None
     type: TrainingSetType = field(default=TrainingSetType.DYNAMIC)
+    persist_as: Optional[TrainingSetPersistAs] = None
+    labels: list = field(default_factory=list)
+    split: Optional[TrainingSetSplit] = None
+    max_feature_age: Optional[timedelta] = None
 
     def update_schedule(self, schedule) -> None:
         self.schedule_obj = Schedule(
@@ -2936,6 +3346,12 @@ class TrainingSetVariant(ResourceVariant):
                 feature, FeatureColumnResource
             ) and not valid_name_variant(feature):
                 raise ValueError("Invalid Feature")
+        if self.max_feature_age is not None and self.max_feature_age < timedelta(
+            seconds=1
+        ):
+            raise ValueError(
+                f"max_feature_age must be at least one second, got {self.max_feature_age}"
+            )
 
     @staticmethod
     def operation_type() -> OperationType:
@@ -2992,12 +3408,20 @@ class TrainingSetVariant(ResourceVariant):
             )
             feature_lags.append(feature_lag)
 
+        max_feature_age = None
+        if self.max_feature_age is not None:
+            max_feature_age = Duration()
+            max_feature_age.FromTimedelta(self.max_feature_age)
+
         for i, f in enumerate(self.features):
             if hasattr(f, "name_variant"):
                 self.features[i] = f.name_variant()
 
         if hasattr(self.label, "name_variant"):
             self.label = self.label.name_variant()
+        for i, lbl in enumerate(self.labels):
+            if hasattr(lbl, "name_variant"):
+                self.labels[i] = lbl.name_variant()
 
         serialized = pb.TrainingSetVariantRequest(
             training_set_variant=pb.TrainingSetVariant(
@@ -3022,6 +3446,10 @@ class TrainingSetVariant(ResourceVariant):
                     else None
                 ),
                 type=self.type.to_proto(),
+                persist_as=self.persist_as.to_proto() if self.persist_as else None,
+                labels=[pb.NameVariant(name=v[0], variant=v[1]) for v in self.labels],
+                split=self.split.to_proto() if self.split else None,
+                max_feature_age=max_feature_age,
             ),
             request_id="",
         )
@@ -3038,7 +3466,7 @@ class TrainingSetVariant(ResourceVariant):
             req_id, stub
         )
         if existing_variant is None:
-            stub.CreateTrainingSetVariant(serialized)
+            _send_create(stub.CreateTrainingSetVariant, serialized)
         return serialized.training_set_variant.variant, existing_variant
 
     def get_status(self):
@@ -3075,7 +3503,7 @@ class Model:
             request_id="",
         )
 
-        stub.CreateModel(serialized)
+        _send_create(stub.CreateModel, serialized)
         return None, None
 
     def to_dictionary(self):
@@ -3593,6 +4021,25 @@ class SparkCredentials:
         }
 
 
+# How many times a create is sent while the metadata server is unavailable.
+_CREATE_ATTEMPTS = 3
+
+
+def _send_create(create, request):
+    """Sends a create request, retrying it while the metadata server is
+    unavailable. Retries carry the same idempotency key as the first attempt,
+    so a create whose response was lost isn't applied twice."""
+    request.idempotency_key = str(uuid.uuid4())
+    for attempt in range(_CREATE_ATTEMPTS):
+        try:
+            return create(request)
+        except grpc.RpcError as e:
+            last_attempt = attempt == _CREATE_ATTEMPTS - 1
+            if last_attempt or e.code() != grpc.StatusCode.UNAVAILABLE:
+                raise
+            time.sleep(0.5 * (attempt + 1))
+
+
 # Looks to see if there is an existing resource variant that matches on a resources key fields
 # and sets the serialized to it.
 #
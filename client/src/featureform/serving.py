@@ -95,6 +95,8 @@ class ServingClient:
         variant="",
         include_label_timestamp=False,
         model: Union[str, Model] = None,
+        batch_size: int = 0,
+        split: str = "",
     ) -> "Dataset":
         """Return an iterator that iterates through the specified training set.
 
@@ -110,6 +112,8 @@ class ServingClient:
         Args:
             name (str): Name of training set to be retrieved
             variant (str): Variant of training set to be retrieved
+            batch_size (int): Number of rows the server sends per message. Defaults to the server's batch size; 1 sends one row per message.
+            split (str): Only returns the "train" or "test" rows of a training set registered with a TrainingSetSplit. Defaults to every row.
 
         Returns:
             training_set (Dataset): A training set iterator
@@ -117,7 +121,9 @@ class ServingClient:
         if isinstance(name, TrainingSetVariant):
             variant = name.variant
             name = name.name
-        return self.impl.training_set(name, variant, include_label_timestamp, model)
+        return self.impl.training_set(
+            name, variant, include_label_timestamp, model, batch_size, split
+        )
 
     def features(
         self, features, entities, model: Union[str, Model] = None, params: list = None
@@ -145,7 +151,7 @@ class ServingClient:
         """Closes the connection to the Featureform instance."""
         self.impl.close()
 
-    def batch_features(self, features):
+    def batch_features(self, features, as_of=None):
         """
         Return an iterator that iterates over each entity and corresponding features in feats.
         **Example:**
@@ -154,17 +160,27 @@ class ServingClient:
             print(feature_values)
         ```
 
+        If `as_of` is set, a row is returned for each of its entities with the feature values as they were at the
+        entity's timestamp, e.g. to backtest a model. It's only supported for features in Spark; other offline stores
+        return an unimplemented error.
+        ```py title="definitions.py"
+        as_of = [("user_1", datetime(2024, 1, 1)), ("user_1", datetime(2024, 2, 1))]
+        for entity, feature_values, ts in client.batch_features([("feature1", "variant")], as_of=as_of):
+            print(entity, feature_values, ts)
+        ```
+
         Args:
             features (List[NameVariant]): The features to iterate over
+            as_of (List[Tuple[str, datetime]]): The entities and timestamps to read feature values at
 
         Returns:
-            iterator: An iterator of entity and feature values
+            iterator: An iterator of entity and feature values, along with the timestamp if `as_of` is set
 
         """
         if len(features) == 0:
             raise ValueError("No features provided")
         feature_tuples = check_feature_type(features)
-        return self.impl.batch_features(feature_tuples)
+        return self.impl.batch_features(feature_tuples, as_of)
 
 
 class HostedClientImpl:
@@ -192,9 +208,17 @@ class HostedClientImpl:
             return secure_channel(host, cert_path)
 
     def training_set(
-        self, name, variation, include_label_timestamp, model: Union[str, Model] = None
+        self,
+        name,
+        variation,
+        include_label_timestamp,
+        model: Union[str, Model] = None,
+        batch_size: int = 0,
+        split: str = "",
     ):
-        training_set_stream = TrainingSetStream(self._stub, name, variation, model)
+        training_set_stream = TrainingSetStream(
+            self._stub, name, variation, model, batch_size, split
+        )
         return Dataset(training_set_stream)
 
     def features(
@@ -257,8 +281,8 @@ class HostedClientImpl:
 
         return feature_values
 
-    def batch_features(self, features):
-        return FeatureSetIterator(self._stub, features)
+    def batch_features(self, features, as_of=None):
+        return FeatureSetIterator(self._stub, features, as_of)
 
     def _get_source_as_df(self, name, variant, limit):
         columns = self._get_source_columns(name, variant)
@@ -303,15 +327,38 @@ class HostedClientImpl:
 
         return resp.location
 
+    def export_training_set(
+        self, name: str, variant: str, location: str, format: str = "parquet"
+    ) -> List[str]:
+        req = serving_pb2.ExportTrainingSetRequest()
+        req.id.name = name
+        req.id.version = variant
+        req.location = location
+        req.format = format
+
+        resp = self._stub.ExportTrainingSet(req)
+
+        return list(resp.files)
+
     def close(self):
         self._channel.close()
 
 
 class TrainingSetStream(Iterator):
-    def __init__(self, stub, name, version, model: Union[str, Model] = None):
+    def __init__(
+        self,
+        stub,
+        name,
+        version,
+        model: Union[str, Model] = None,
+        batch_size=0,
+        split="",
+    ):
         req = serving_pb2.TrainingDataRequest()
         req.id.name = name
         req.id.version = version
+        req.batch_size = batch_size
+        req.split = split
         if model is not None:
             req.model.name = model if isinstance(model, str) else model.name
         self.name = name
@@ -861,6 +908,23 @@ def parse_proto_value(value):
     return getattr(value, value.WhichOneof("value"))
 
 
+INT_SCALAR_TYPES = {"int", "int8", "int16", "int32", "int64"}
+FLOAT_SCALAR_TYPES = {"float32", "float64"}
+
+
+def coerce_proto_value(value, value_type):
+    """coerce_proto_value parses a Value and casts it to the feature's value type,
+    since a whole float can be serialized as an int and vice versa"""
+    parsed = parse_proto_value(value)
+    if value_type is None or value_type.is_vector:
+        return parsed
+    if value_type.scalar in FLOAT_SCALAR_TYPES and type(parsed) is int:
+        return float(parsed)
+    if value_type.scalar in INT_SCALAR_TYPES and type(parsed) is float:
+        return int(parsed)
+    return parsed
+
+
 def proto_type_to_np_type(value):
     type_mapping = {
         "str_value": str,
@@ -882,16 +946,21 @@ def get_numpy_array_type(types):
 
 
 class FeatureSetIterator:
-    def __init__(self, stub, features):
+    def __init__(self, stub, features, as_of=None):
         req = serving_pb2.BatchFeatureServeRequest()
         for name, variant in features:
             feature_id = req.features.add()
             feature_id.name = name
             feature_id.version = variant
+        for entity, ts in as_of or []:
+            as_of_entity = req.as_of.add()
+            as_of_entity.entity = str(entity)
+            as_of_entity.ts.FromDatetime(ts)
         self._stub = stub
         self._req = req
         self._iter = stub.BatchFeatureServe(req)
         self._buf = []
+        self._value_types = []
         self._index = 0
 
     def __iter__(self):
@@ -899,21 +968,39 @@ class FeatureSetIterator:
 
     def __next__(self):
         if self._index >= len(self._buf):
-            self._buf = next(self._iter).rows
+            batch = next(self._iter)
+            self._buf = batch.rows
+            self._value_types = list(batch.value_types)
             self._index = 0
 
-        row = FeatureSetRow(self._buf[self._index]).to_tuple()
+        row = FeatureSetRow(self._buf[self._index], self._value_types).to_tuple()
         self._index += 1
         return row
 
+    def value_types(self):
+        """The value type of each feature, in request order. They're known once the
+        first row has been read."""
+        return self._value_types
+
     def restart(self):
         self._iter = self._stub.BatchFeatureServe(self._req)
 
 
 class FeatureSetRow:
-    def __init__(self, proto_row):
-        self._features = [parse_proto_value(feature) for feature in proto_row.features]
+    def __init__(self, proto_row, value_types=None):
+        if value_types:
+            self._features = [
+                coerce_proto_value(feature, value_type)
+                for feature, value_type in zip(proto_row.features, value_types)
+            ]
+        else:
+            self._features = [
+                parse_proto_value(feature) for feature in proto_row.features
+            ]
         self._entity = parse_proto_value(proto_row.entity)
+        self._as_of = (
+            proto_row.as_of.ToDatetime() if proto_row.HasField("as_of") else None
+        )
         self._row = [self._entity, self._features]
 
     def features(self):
@@ -925,7 +1012,12 @@ class FeatureSetRow:
     def to_numpy(self):
         return np.array(self._row)
 
+    def as_of(self):
+        return self._as_of
+
     def to_tuple(self):
+        if self._as_of is not None:
+            return tuple((self._entity, self._features, self._as_of))
         return tuple((self._entity, self._features))
 
     def to_dict(self, feature_columns: List[str], entity_column: str):
@@ -13,7 +13,9 @@ import pytest
 sys.path.insert(0, "client/src/")
 from featureform.resources import (
     BigQueryConfig,
+    BigtableConfig,
     ClickHouseConfig,
+    DatabricksSQLConfig,
     FirestoreConfig,
     RedisConfig,
     PineconeConfig,
@@ -32,6 +34,7 @@ from featureform.resources import (
     PostgresConfig,
     SparkConfig,
     K8sConfig,
+    KafkaConfig,
     RedshiftConfig,
     SnowflakeCatalog,
     Config,
@@ -166,6 +169,34 @@ def test_firestore():
     assert json.loads(serialized_config) == expected_config
 
 
+@pytest.mark.local
+def test_bigtable():
+    expected_config = connection_configs["BigtableConfig"]
+    conf = BigtableConfig(
+        project_id="some-project-id",
+        instance_id="some-instance-id",
+        table_prefix="featureform_",
+        credentials=GCPCredentials(
+            project_id="id",
+            credentials_path="provider/connection/gcp_test_credentials.json",
+        ),
+    )
+    serialized_config = conf.serialize()
+    assert json.loads(serialized_config) == expected_config
+
+
+@pytest.mark.local
+def test_kafka():
+    expected_config = connection_configs["KafkaConfig"]
+    conf = KafkaConfig(
+        brokers=["localhost:9092"],
+        format="AVRO",
+        schema_registry_url="http://localhost:8081",
+    )
+    serialized_config = conf.serialize()
+    assert json.loads(serialized_config) == expected_config
+
+
 @pytest.mark.local
 def test_cassandra():
     expected_config = connection_configs["CassandraConfig"]
@@ -325,6 +356,20 @@ def test_clickhouse():
     assert json.loads(serialized_config) == expected_config
 
 
+@pytest.mark.local
+def test_databricks_sql():
+    expected_config = connection_configs["DatabricksSQLConfig"]
+    conf = DatabricksSQLConfig(
+        host="host",
+        http_path="/sql/1.0/warehouses/warehouse",
+        token="token",
+        catalog="catalog",
+        schema="schema",
+    )
+    serialized_config = conf.serialize()
+    assert json.loads(serialized_config) == expected_config
+
+
 @pytest.mark.local
 def test_redshift():
     expected_config = connection_configs["RedshiftConfig"]
@@ -301,6 +301,24 @@ def test_register_clickhouse():
     assert isinstance(result, OfflineSQLProvider)
 
 
+@pytest.mark.local
+def test_register_databricks_sql():
+    reg = Registrar()
+    result = reg.register_databricks_sql(
+        name="name",
+        description="description",
+        team="team",
+        host="host",
+        http_path="/sql/1.0/warehouses/warehouse",
+        token="token",
+        catalog="main",
+        schema="default",
+        tags=[],
+        properties={},
+    )
+    assert isinstance(result, OfflineSQLProvider)
+
+
 @pytest.mark.local
 def test_register_redshift():
     reg = Registrar()
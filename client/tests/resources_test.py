@@ -6,12 +6,14 @@
 #
 
 import os.path
+from datetime import datetime, timedelta, timezone
 import sys
 import unittest
 
 sys.path.insert(0, "client/src/")
 import pytest
 from featureform.resources import (
+    encode_default_value,
     DailyPartition,
     DatabricksCredentials,
     FileStore,
@@ -1385,3 +1387,20 @@ def test_df_source_equivalence(spark_provider):
     return df"""
 
     assert my_function.transformation.canonical_func_text == expected
+
+
+@pytest.mark.parametrize(
+    "value,expected",
+    [
+        (datetime(2024, 1, 2, 3, 4, 5), '"2024-01-02T03:04:05Z"'),
+        (
+            datetime(2024, 1, 2, 3, 4, 5, tzinfo=timezone(timedelta(hours=2))),
+            '"2024-01-02T01:04:05Z"',
+        ),
+        (datetime(2024, 1, 2, 3, 4, 5, 123000), '"2024-01-02T03:04:05.123000Z"'),
+        (None, ""),
+        (1.5, "1.5"),
+    ],
+)
+def test_encode_default_value(value, expected):
+    assert encode_default_value(value) == expected
@@ -14,6 +14,7 @@ import (
 
 	"github.com/featureform/config"
 	"github.com/featureform/fferr"
+	"github.com/featureform/ffsync"
 	"github.com/featureform/helpers/postgres"
 	"github.com/featureform/logging"
 	"github.com/featureform/scheduling"
@@ -27,6 +28,8 @@ type Initializer struct {
 	tm     scheduling.TaskMetadataManager
 	tmErr  error
 	initTm sync.Once
+	locker ffsync.Locker
+	idGen  ffsync.OrderedIdGenerator
 }
 
 // TODO(simba) I want to make the loger to be initialized by this object later
@@ -88,6 +91,25 @@ func (i *Initializer) GetOrCreateTaskMetadataManager(ctx context.Context) (sched
 			)
 			logger.Errorw(errMsg)
 			i.tmErr = fferr.NewInternalErrorf(errMsg)
+			return
+		}
+		if i.tmErr == nil && i.config.LockerType == config.RedisLocker {
+			logger.Debug("Replacing task manager locker with redis locker")
+			i.locker, i.tmErr = ffsync.NewRedisLocker(ctx, *i.config.RedisLocker)
+			if i.tmErr != nil {
+				logger.Errorw("Failed to create redis locker", "err", i.tmErr)
+				return
+			}
+			i.tm.Storage.Locker = i.locker
+		}
+		if i.tmErr == nil && i.config.IdGeneratorType == config.ETCDIdGenerator {
+			logger.Debug("Replacing task manager id generator with etcd id generator")
+			i.idGen, i.tmErr = ffsync.NewETCDOrderedIdGenerator(ctx, *i.config.ETCDIdGenerator)
+			if i.tmErr != nil {
+				logger.Errorw("Failed to create etcd id generator", "err", i.tmErr)
+				return
+			}
+			i.tm.SetIdGenerator(i.idGen)
 		}
 	})
 	if i.tmErr != nil {
@@ -104,5 +126,11 @@ func (i *Initializer) Close() error {
 	if i.pg != nil {
 		i.pg.Close()
 	}
+	if i.locker != nil {
+		i.locker.Close()
+	}
+	if i.idGen != nil {
+		i.idGen.Close()
+	}
 	return nil
 }
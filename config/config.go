@@ -11,11 +11,13 @@ import (
 	"crypto/md5"
 	"fmt"
 	"os"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
 
 	"github.com/featureform/fferr"
+	"github.com/featureform/ffsync"
 	"github.com/featureform/helpers"
 	"github.com/featureform/helpers/postgres"
 	"github.com/featureform/logging"
@@ -53,6 +55,41 @@ const (
 	EnvFFStateProvider                   = "FF_STATE_PROVIDER"
 	EnvSlackChannelId                    = "SLACK_CHANNEL_ID"
 	EnvFFInitTimeout                     = "FF_INIT_TIMEOUT"
+	EnvFFShutdownTimeout                 = "FF_SHUTDOWN_TIMEOUT"
+	EnvFFLocker                          = "FF_LOCKER"
+	EnvFFIdGenerator                     = "FF_ID_GENERATOR"
+	EnvSkipJSONValidation                = "SKIP_JSON_VALIDATION"
+	EnvTransformationCacheProviders      = "TRANSFORMATION_CACHE_PROVIDERS"
+	EnvEnableGRPCReflection              = "ENABLE_GRPC_REFLECTION"
+	EnvGRPCTLSCertPath                   = "GRPC_TLS_CERT_PATH"
+	EnvGRPCTLSKeyPath                    = "GRPC_TLS_KEY_PATH"
+	EnvGRPCTLSCAPath                     = "GRPC_TLS_CA_PATH"
+	EnvGRPCTLSServerName                 = "GRPC_TLS_SERVER_NAME"
+	EnvAuthJWTSecret                     = "FF_AUTH_JWT_SECRET"
+	EnvAuthJWTPublicKeyPath              = "FF_AUTH_JWT_PUBLIC_KEY_PATH"
+	EnvAuthJWTIssuer                     = "FF_AUTH_JWT_ISSUER"
+	EnvAuthJWTAudience                   = "FF_AUTH_JWT_AUDIENCE"
+	EnvConfigEncryptionKeyProvider       = "FF_CONFIG_ENCRYPTION_KEY_PROVIDER"
+	EnvConfigEncryptionLocalKey          = "FF_CONFIG_ENCRYPTION_LOCAL_KEY"
+	EnvConfigEncryptionKMSKeyID          = "FF_CONFIG_ENCRYPTION_KMS_KEY_ID"
+	EnvSecretManager                     = "FF_SECRET_MANAGER"
+	EnvSecretCacheTTL                    = "FF_SECRET_CACHE_TTL"
+	EnvVaultAddr                         = "VAULT_ADDR"
+	EnvVaultToken                        = "VAULT_TOKEN"
+	EnvVaultKVMount                      = "FF_VAULT_KV_MOUNT"
+	EnvGCPSecretManagerProject           = "FF_GCP_SECRET_MANAGER_PROJECT"
+	EnvMetadataEventSink                 = "FF_METADATA_EVENT_SINK"
+	EnvMetadataEventQueueSize            = "FF_METADATA_EVENT_QUEUE_SIZE"
+	EnvMetadataEventWebhookURL           = "FF_METADATA_EVENT_WEBHOOK_URL"
+	EnvMetadataEventKafkaBrokers         = "FF_METADATA_EVENT_KAFKA_BROKERS"
+	EnvMetadataEventKafkaTopic           = "FF_METADATA_EVENT_KAFKA_TOPIC"
+	EnvMetadataEventSNSTopicARN          = "FF_METADATA_EVENT_SNS_TOPIC_ARN"
+	EnvIdempotencyKeyTTL                 = "FF_IDEMPOTENCY_KEY_TTL"
+	EnvBannedNameStrings                 = "FF_BANNED_NAME_STRINGS"
+	EnvBannedNamePrefixes                = "FF_BANNED_NAME_PREFIXES"
+	EnvBannedNameSuffixes                = "FF_BANNED_NAME_SUFFIXES"
+	EnvAllowedNamePattern                = "FF_ALLOWED_NAME_PATTERN"
+	EnvLocalStateDir                     = "FF_LOCAL_STATE_DIR"
 )
 
 type SparkFileConfigs struct {
@@ -78,6 +115,38 @@ func ShouldSkipSparkHealthCheck() bool {
 	return helpers.GetEnvBool(EnvSkipSparkHealthCheck, false)
 }
 
+// ShouldSkipJSONValidation turns off checking that JSON feature values are
+// well-formed when they're written to an online store.
+func ShouldSkipJSONValidation() bool {
+	return helpers.GetEnvBool(EnvSkipJSONValidation, false)
+}
+
+// ShouldEnableGRPCReflection registers the gRPC reflection service on the
+// metadata and serving servers so tools like grpcurl can list their APIs.
+// It's off by default so production servers don't expose their schema.
+func ShouldEnableGRPCReflection() bool {
+	return helpers.GetEnvBool(EnvEnableGRPCReflection, false)
+}
+
+// IsTransformationCacheEnabled returns true if the provider is in the
+// comma-separated list of providers whose transformations are skipped when
+// their query and inputs haven't changed since the last run.
+func IsTransformationCacheEnabled(provider string) bool {
+	for _, name := range strings.Split(helpers.GetEnv(EnvTransformationCacheProviders, ""), ",") {
+		if name = strings.TrimSpace(name); name != "" && name == provider {
+			return true
+		}
+	}
+	return false
+}
+
+// GetLocalStateDir is the directory the memory offline store is saved to, so
+// local mode keeps its tables and training sets across restarts. It's empty,
+// and nothing is saved, by default.
+func GetLocalStateDir() string {
+	return helpers.GetEnv(EnvLocalStateDir, "")
+}
+
 func ShouldUseDBFS() bool {
 	return helpers.GetEnvBool(EnvShouldUseDBFS, false)
 }
@@ -180,6 +249,32 @@ var AllStateProviderTypes = []StateProviderType{
 	NoStateProvider, PostgresStateProvider,
 }
 
+// LockerType is the backend used to coordinate locks between replicas. By
+// default the state provider's own locker is used.
+type LockerType string
+
+const (
+	StateProviderLocker LockerType = ""
+	RedisLocker         LockerType = "redis"
+)
+
+var AllLockerTypes = []LockerType{
+	StateProviderLocker, RedisLocker,
+}
+
+// IdGeneratorType is the backend that task and run IDs are generated from. By
+// default the state provider's own generator is used.
+type IdGeneratorType string
+
+const (
+	StateProviderIdGenerator IdGeneratorType = ""
+	ETCDIdGenerator          IdGeneratorType = "etcd"
+)
+
+var AllIdGeneratorTypes = []IdGeneratorType{
+	StateProviderIdGenerator, ETCDIdGenerator,
+}
+
 var cached *FeatureformApp
 var cachedErr error
 
@@ -201,10 +296,20 @@ func parseFeatureformApp(logger logging.Logger) (*FeatureformApp, fferr.Error) {
 		logger.Errorw("Failed to parse init config", "err", err)
 		return nil, err
 	}
+	parseShutdownConfig(logger, &cfg)
+	parseIdempotencyConfig(logger, &cfg)
 	if err := parseStateProvider(logger, &cfg); err != nil {
 		logger.Errorw("Failed to parse state backend", "err", err)
 		return nil, err
 	}
+	if err := parseLocker(logger, &cfg); err != nil {
+		logger.Errorw("Failed to parse locker", "err", err)
+		return nil, err
+	}
+	if err := parseIdGenerator(logger, &cfg); err != nil {
+		logger.Errorw("Failed to parse id generator", "err", err)
+		return nil, err
+	}
 	return &cfg, nil
 }
 
@@ -227,6 +332,35 @@ func parseInitConfig(logger logging.Logger, cfg *FeatureformApp) fferr.Error {
 	return nil
 }
 
+func parseShutdownConfig(logger logging.Logger, cfg *FeatureformApp) {
+	// Kubernetes kills pods 30 seconds after sending SIGTERM by default, this
+	// leaves time to flush logs and close connections after the timeout.
+	defaultTimeout := time.Second * 25
+	logger.Debug("Looking up shutdown timeout from env")
+	timeout, err := helpers.LookupEnvDuration(EnvFFShutdownTimeout)
+	if _, ok := err.(*helpers.EnvNotFound); ok {
+		logger.Infof("ENV %s not set, using default timeout %v", EnvFFShutdownTimeout, defaultTimeout)
+		timeout = defaultTimeout
+	} else if err != nil {
+		logger.Infof("Unable to parse ENV %s, using default timeout %v", EnvFFShutdownTimeout, defaultTimeout)
+		timeout = defaultTimeout
+	}
+	cfg.ShutdownTimeout = timeout
+}
+
+func parseIdempotencyConfig(logger logging.Logger, cfg *FeatureformApp) {
+	logger.Debug("Looking up idempotency key TTL from env")
+	ttl, err := helpers.LookupEnvDuration(EnvIdempotencyKeyTTL)
+	if _, ok := err.(*helpers.EnvNotFound); ok {
+		logger.Infof("ENV %s not set, using default idempotency key TTL", EnvIdempotencyKeyTTL)
+		return
+	} else if err != nil {
+		logger.Infof("Unable to parse ENV %s, using default idempotency key TTL", EnvIdempotencyKeyTTL)
+		return
+	}
+	cfg.IdempotencyKeyTTL = ttl
+}
+
 func needsMigration(logger logging.Logger) bool {
 	stateProviderType, err := getStateProvider(logger)
 	if err != nil {
@@ -302,6 +436,90 @@ func parsePostgres(logger logging.Logger) (*postgres.Config, fferr.Error) {
 	return &cfg, nil
 }
 
+func parseLocker(logger logging.Logger, cfg *FeatureformApp) fferr.Error {
+	lockerType := LockerType(helpers.GetEnv(EnvFFLocker, string(StateProviderLocker)))
+	lockerLogger := logger.With("locker", lockerType)
+	cfg.LockerType = lockerType
+	switch lockerType {
+	case StateProviderLocker:
+		lockerLogger.Debug("Using state provider locker, nothing to parse")
+		return nil
+	case RedisLocker:
+		lockerLogger.Debug("Parsing Redis locker config from env")
+		redisCfg, err := parseRedisLocker(lockerLogger)
+		if err != nil {
+			lockerLogger.Errorw("Failed to parse redis locker config", "err", err)
+			return err
+		}
+		cfg.RedisLocker = redisCfg
+	default:
+		lockerLogger.Errorw("Invalid locker")
+		return fferr.NewInvalidConfigEnv(EnvFFLocker, string(lockerType), AllLockerTypes)
+	}
+	return nil
+}
+
+func parseRedisLocker(logger logging.Logger) (*ffsync.RedisLockerConfig, fferr.Error) {
+	defaultEnvs := map[string]string{
+		"REDIS_LOCKER_HOST":     "localhost",
+		"REDIS_LOCKER_PORT":     "6379",
+		"REDIS_LOCKER_PASSWORD": "",
+		"REDIS_LOCKER_DB":       "0",
+		"REDIS_LOCKER_TTL":      "1m",
+	}
+	envs := fillEnvMap(logger, defaultEnvs)
+	db, err := strconv.Atoi(envs["REDIS_LOCKER_DB"])
+	if err != nil {
+		return nil, fferr.NewInvalidConfigEnv("REDIS_LOCKER_DB", envs["REDIS_LOCKER_DB"], "an integer")
+	}
+	ttl, err := time.ParseDuration(envs["REDIS_LOCKER_TTL"])
+	if err != nil {
+		return nil, fferr.NewInvalidConfigEnv("REDIS_LOCKER_TTL", envs["REDIS_LOCKER_TTL"], "a duration")
+	}
+	cfg := ffsync.RedisLockerConfig{
+		Addr:     fmt.Sprintf("%s:%s", envs["REDIS_LOCKER_HOST"], envs["REDIS_LOCKER_PORT"]),
+		Password: envs["REDIS_LOCKER_PASSWORD"],
+		DB:       db,
+		TTL:      ttl,
+	}
+	logger.Infow("Redis locker config parsed from env", "addr", cfg.Addr, "db", cfg.DB, "ttl", cfg.TTL)
+	return &cfg, nil
+}
+
+func parseIdGenerator(logger logging.Logger, cfg *FeatureformApp) fferr.Error {
+	generatorType := IdGeneratorType(helpers.GetEnv(EnvFFIdGenerator, string(StateProviderIdGenerator)))
+	generatorLogger := logger.With("id-generator", generatorType)
+	cfg.IdGeneratorType = generatorType
+	switch generatorType {
+	case StateProviderIdGenerator:
+		generatorLogger.Debug("Using state provider id generator, nothing to parse")
+		return nil
+	case ETCDIdGenerator:
+		generatorLogger.Debug("Parsing etcd id generator config from env")
+		cfg.ETCDIdGenerator = parseETCDIdGenerator(generatorLogger)
+	default:
+		generatorLogger.Errorw("Invalid id generator")
+		return fferr.NewInvalidConfigEnv(EnvFFIdGenerator, string(generatorType), AllIdGeneratorTypes)
+	}
+	return nil
+}
+
+func parseETCDIdGenerator(logger logging.Logger) *ffsync.ETCDOrderedIdGeneratorConfig {
+	defaultEnvs := map[string]string{
+		"ETCD_ID_GENERATOR_ENDPOINTS": "localhost:2379",
+		"ETCD_ID_GENERATOR_USERNAME":  "",
+		"ETCD_ID_GENERATOR_PASSWORD":  "",
+	}
+	envs := fillEnvMap(logger, defaultEnvs)
+	cfg := ffsync.ETCDOrderedIdGeneratorConfig{
+		Endpoints: strings.Split(envs["ETCD_ID_GENERATOR_ENDPOINTS"], ","),
+		Username:  envs["ETCD_ID_GENERATOR_USERNAME"],
+		Password:  envs["ETCD_ID_GENERATOR_PASSWORD"],
+	}
+	logger.Infow("Etcd id generator config parsed from env", "endpoints", cfg.Endpoints)
+	return &cfg
+}
+
 func fillEnvMap(logger logging.Logger, defaultEnvs map[string]string) map[string]string {
 	envs := make(map[string]string)
 	for env, defVal := range defaultEnvs {
@@ -324,8 +542,22 @@ func getEnvWithDefault(logger logging.Logger, env, defVal string) string {
 type FeatureformApp struct {
 	// InitTimeout specifies how long the service has to initialize
 	InitTimeout time.Duration
+	// ShutdownTimeout specifies how long the service has to finish in-flight
+	// requests and tasks after it's signaled to stop
+	ShutdownTimeout time.Duration
+	// IdempotencyKeyTTL specifies how long the metadata server remembers the
+	// idempotency keys of successful creates. If zero, its default is used.
+	IdempotencyKeyTTL time.Duration
 	// StateProviderType specifies where app-state is to be stored
 	StateProviderType StateProviderType
 	// This will only be set when StateProviderType is PostgresStateProvider
 	Postgres *postgres.Config
+	// LockerType specifies how locks are coordinated between replicas
+	LockerType LockerType
+	// This will only be set when LockerType is RedisLocker
+	RedisLocker *ffsync.RedisLockerConfig
+	// IdGeneratorType specifies where task and run IDs are generated
+	IdGeneratorType IdGeneratorType
+	// This will only be set when IdGeneratorType is ETCDIdGenerator
+	ETCDIdGenerator *ffsync.ETCDOrderedIdGeneratorConfig
 }
@@ -0,0 +1,208 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+//
+// Copyright 2024 FeatureForm Inc.
+//
+
+package config
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/featureform/fferr"
+	"github.com/featureform/logging"
+)
+
+// Environment variable names for the hosts and ports of the Featureform servers
+const (
+	EnvAPIPort           = "API_PORT"
+	EnvMetadataHost      = "METADATA_HOST"
+	EnvMetadataPort      = "METADATA_PORT"
+	EnvMetadataHTTPPort  = "METADATA_HTTP_PORT"
+	EnvServingHost       = "SERVING_HOST"
+	EnvServingPort       = "SERVING_PORT"
+	EnvServingFlightPort = "SERVING_FLIGHT_PORT"
+	EnvServingHTTPPort   = "SERVING_HTTP_PORT"
+	EnvHealthCheckPort   = "HEALTH_CHECK_PORT"
+	EnvPprofPort         = "PPROF_PORT"
+)
+
+// ServerConfig holds the hosts and ports that the Featureform servers listen on
+// and connect to each other with.
+type ServerConfig struct {
+	// APIPort is the port of the API server that clients connect to
+	APIPort int
+	// MetadataHost is the host that the other servers connect to metadata with
+	MetadataHost string
+	// MetadataPort is the port of the metadata gRPC server
+	MetadataPort int
+	// MetadataHTTPPort is the port of the dashboard's metadata HTTP server
+	MetadataHTTPPort int
+	// ServingHost is the host that the serving servers listen on
+	ServingHost string
+	// ServingPort is the port of the serving gRPC server
+	ServingPort int
+	// ServingFlightPort is the port of the serving Arrow Flight server
+	ServingFlightPort int
+	// ServingHTTPPort is the port of the serving HTTP/JSON gateway. The gateway
+	// is disabled if it's 0.
+	ServingHTTPPort int
+	// HealthCheckPort is the port of the API's HTTPS health check server
+	HealthCheckPort int
+	// PprofPort is the port that pprof is served on, on localhost only
+	PprofPort int
+}
+
+// GetServerConfig reads the server config from the environment, using the
+// default host or port of any that isn't set, and validates it.
+func GetServerConfig(logger logging.Logger) (ServerConfig, error) {
+	var problems []string
+	port := func(env string, defVal int) int {
+		val := getEnvWithDefault(logger, env, strconv.Itoa(defVal))
+		parsed, err := strconv.Atoi(val)
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("%s must be a port number, got %q", env, val))
+		}
+		return parsed
+	}
+	cfg := ServerConfig{
+		APIPort:           port(EnvAPIPort, 7878),
+		MetadataHost:      getEnvWithDefault(logger, EnvMetadataHost, "localhost"),
+		MetadataPort:      port(EnvMetadataPort, 8080),
+		MetadataHTTPPort:  port(EnvMetadataHTTPPort, 3001),
+		ServingHost:       getEnvWithDefault(logger, EnvServingHost, "0.0.0.0"),
+		ServingPort:       port(EnvServingPort, 8081),
+		ServingFlightPort: port(EnvServingFlightPort, 8087),
+		ServingHTTPPort:   port(EnvServingHTTPPort, 0),
+		HealthCheckPort:   port(EnvHealthCheckPort, 8443),
+		PprofPort:         port(EnvPprofPort, 6060),
+	}
+	if len(problems) > 0 {
+		return ServerConfig{}, fferr.NewInvalidArgumentErrorf("invalid server config: %s", strings.Join(problems, "; "))
+	}
+	if err := cfg.Validate(); err != nil {
+		return ServerConfig{}, err
+	}
+	return cfg, nil
+}
+
+// ParsePort parses the value of a port environment variable, checking that it's
+// a number between 1 and 65535.
+func ParsePort(env, val string) (int, error) {
+	port, err := strconv.Atoi(strings.TrimSpace(val))
+	if err != nil {
+		return 0, fferr.NewInvalidArgumentErrorf("%s must be a port number, got %q", env, val)
+	}
+	if port < 1 || port > 65535 {
+		return 0, fferr.NewInvalidArgumentErrorf("%s must be between 1 and 65535, got %d", env, port)
+	}
+	return port, nil
+}
+
+// Validate checks that every port is in range, that the hosts are set, and that
+// no two servers listen on the same port.
+func (cfg ServerConfig) Validate() error {
+	if problems := cfg.problems(); len(problems) > 0 {
+		return fferr.NewInvalidArgumentErrorf("invalid server config: %s", strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+func (cfg ServerConfig) problems() []string {
+	var problems []string
+	hosts := []struct {
+		env, host string
+	}{
+		{EnvMetadataHost, cfg.MetadataHost},
+		{EnvServingHost, cfg.ServingHost},
+	}
+	for _, h := range hosts {
+		if strings.TrimSpace(h.host) == "" {
+			problems = append(problems, fmt.Sprintf("%s is required", h.env))
+		}
+	}
+	ports := []struct {
+		env  string
+		port int
+	}{
+		{EnvAPIPort, cfg.APIPort},
+		{EnvMetadataPort, cfg.MetadataPort},
+		{EnvMetadataHTTPPort, cfg.MetadataHTTPPort},
+		{EnvServingPort, cfg.ServingPort},
+		{EnvServingFlightPort, cfg.ServingFlightPort},
+		{EnvHealthCheckPort, cfg.HealthCheckPort},
+		{EnvPprofPort, cfg.PprofPort},
+	}
+	if cfg.ServingHTTPEnabled() {
+		ports = append(ports, struct {
+			env  string
+			port int
+		}{EnvServingHTTPPort, cfg.ServingHTTPPort})
+	}
+	bound := make(map[int]string, len(ports))
+	for _, p := range ports {
+		if p.port < 1 || p.port > 65535 {
+			problems = append(problems, fmt.Sprintf("%s must be between 1 and 65535, got %d", p.env, p.port))
+			continue
+		}
+		if other, has := bound[p.port]; has {
+			problems = append(problems, fmt.Sprintf("%s and %s are both set to port %d", other, p.env, p.port))
+			continue
+		}
+		bound[p.port] = p.env
+	}
+	return problems
+}
+
+// APIAddress is the address the API server listens on.
+func (cfg ServerConfig) APIAddress() string {
+	return fmt.Sprintf("0.0.0.0:%d", cfg.APIPort)
+}
+
+// MetadataAddress is the address that the other servers connect to metadata with.
+func (cfg ServerConfig) MetadataAddress() string {
+	return fmt.Sprintf("%s:%d", cfg.MetadataHost, cfg.MetadataPort)
+}
+
+// MetadataListenAddress is the address the metadata server listens on.
+func (cfg ServerConfig) MetadataListenAddress() string {
+	return fmt.Sprintf(":%d", cfg.MetadataPort)
+}
+
+// MetadataHTTPListenAddress is the address the dashboard's metadata server listens on.
+func (cfg ServerConfig) MetadataHTTPListenAddress() string {
+	return fmt.Sprintf(":%d", cfg.MetadataHTTPPort)
+}
+
+// ServingAddress is the address the serving server listens on.
+func (cfg ServerConfig) ServingAddress() string {
+	return fmt.Sprintf("%s:%d", cfg.ServingHost, cfg.ServingPort)
+}
+
+// ServingFlightAddress is the address the serving Flight server listens on.
+func (cfg ServerConfig) ServingFlightAddress() string {
+	return fmt.Sprintf("%s:%d", cfg.ServingHost, cfg.ServingFlightPort)
+}
+
+// ServingHTTPEnabled returns true if the serving HTTP gateway has a port.
+func (cfg ServerConfig) ServingHTTPEnabled() bool {
+	return cfg.ServingHTTPPort != 0
+}
+
+// ServingHTTPAddress is the address the serving HTTP gateway listens on.
+func (cfg ServerConfig) ServingHTTPAddress() string {
+	return fmt.Sprintf("%s:%d", cfg.ServingHost, cfg.ServingHTTPPort)
+}
+
+// HealthCheckListenAddress is the address the health check server listens on.
+func (cfg ServerConfig) HealthCheckListenAddress() string {
+	return fmt.Sprintf(":%d", cfg.HealthCheckPort)
+}
+
+// PprofAddress is the address pprof is served on.
+func (cfg ServerConfig) PprofAddress() string {
+	return fmt.Sprintf("localhost:%d", cfg.PprofPort)
+}
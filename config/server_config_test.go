@@ -0,0 +1,97 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+//
+// Copyright 2024 FeatureForm Inc.
+//
+
+package config
+
+import (
+	"testing"
+
+	"github.com/featureform/logging"
+)
+
+func TestGetServerConfigDefaults(t *testing.T) {
+	cfg, err := GetServerConfig(logging.NewTestLogger(t))
+	if err != nil {
+		t.Fatalf("Failed to get default server config: %v", err)
+	}
+	tests := []struct {
+		actual, expected string
+	}{
+		{cfg.APIAddress(), "0.0.0.0:7878"},
+		{cfg.MetadataAddress(), "localhost:8080"},
+		{cfg.MetadataListenAddress(), ":8080"},
+		{cfg.MetadataHTTPListenAddress(), ":3001"},
+		{cfg.ServingAddress(), "0.0.0.0:8081"},
+		{cfg.ServingFlightAddress(), "0.0.0.0:8087"},
+		{cfg.HealthCheckListenAddress(), ":8443"},
+		{cfg.PprofAddress(), "localhost:6060"},
+	}
+	for _, test := range tests {
+		if test.actual != test.expected {
+			t.Errorf("Expected address %s, got %s", test.expected, test.actual)
+		}
+	}
+	if cfg.ServingHTTPEnabled() {
+		t.Errorf("Expected the serving HTTP gateway to be disabled by default")
+	}
+}
+
+func TestGetServerConfigInvalid(t *testing.T) {
+	tests := map[string]map[string]string{
+		"NotANumber":    {EnvServingPort: "http"},
+		"OutOfRange":    {EnvAPIPort: "70000"},
+		"Zero":          {EnvMetadataHTTPPort: "0"},
+		"DuplicatePort": {EnvServingFlightPort: "8081"},
+		"EmptyHost":     {EnvMetadataHost: " "},
+		"HTTPDuplicate": {EnvServingHTTPPort: "8087"},
+		"HTTPNegative":  {EnvServingHTTPPort: "-1"},
+	}
+	for name, envs := range tests {
+		t.Run(name, func(t *testing.T) {
+			for env, val := range envs {
+				t.Setenv(env, val)
+			}
+			if _, err := GetServerConfig(logging.NewTestLogger(t)); err == nil {
+				t.Fatalf("Expected an error for %v", envs)
+			}
+		})
+	}
+}
+
+func TestGetServerConfigOverrides(t *testing.T) {
+	t.Setenv(EnvMetadataHost, "metadata")
+	t.Setenv(EnvMetadataPort, "9090")
+	t.Setenv(EnvHealthCheckPort, "9443")
+	cfg, err := GetServerConfig(logging.NewTestLogger(t))
+	if err != nil {
+		t.Fatalf("Failed to get server config: %v", err)
+	}
+	if addr := cfg.MetadataAddress(); addr != "metadata:9090" {
+		t.Fatalf("Expected metadata address metadata:9090, got %s", addr)
+	}
+	if addr := cfg.HealthCheckListenAddress(); addr != ":9443" {
+		t.Fatalf("Expected health check address :9443, got %s", addr)
+	}
+	t.Setenv(EnvServingHTTPPort, "8088")
+	if cfg, err = GetServerConfig(logging.NewTestLogger(t)); err != nil {
+		t.Fatalf("Failed to get server config: %v", err)
+	}
+	if !cfg.ServingHTTPEnabled() || cfg.ServingHTTPAddress() != "0.0.0.0:8088" {
+		t.Fatalf("Expected serving HTTP gateway at 0.0.0.0:8088, got %s", cfg.ServingHTTPAddress())
+	}
+}
+
+func TestParsePort(t *testing.T) {
+	if port, err := ParsePort(EnvServingHTTPPort, "8088"); err != nil || port != 8088 {
+		t.Fatalf("Expected port 8088, got %d, %v", port, err)
+	}
+	for _, val := range []string{"", "http", "0", "-1", "65536", ":8088"} {
+		if _, err := ParsePort(EnvServingHTTPPort, val); err == nil {
+			t.Errorf("Expected an error for %q", val)
+		}
+	}
+}
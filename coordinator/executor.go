@@ -8,6 +8,7 @@
 package coordinator
 
 import (
+	"errors"
 	"fmt"
 	"runtime/debug"
 	"time"
@@ -19,20 +20,71 @@ import (
 	"github.com/featureform/fferr"
 	"github.com/featureform/logging"
 	"github.com/featureform/metadata"
+	"github.com/featureform/metrics"
 	"github.com/featureform/scheduling"
 	"github.com/google/uuid"
 )
 
 type ExecutorConfig struct {
 	DependencyPollInterval time.Duration
+	// MaxRetries is how many times a run that failed with a retryable error is
+	// attempted again before it's marked as failed.
+	MaxRetries int
+	// RetryBaseDelay is the wait before the first retry. It doubles with each
+	// following retry, up to RetryMaxDelay if it's set.
+	RetryBaseDelay time.Duration
+	RetryMaxDelay  time.Duration
+}
+
+// retryDelay is how long to wait before retrying a run that has failed
+// attempts times.
+func (c ExecutorConfig) retryDelay(attempts int) time.Duration {
+	delay := c.RetryBaseDelay
+	for i := 1; i < attempts; i++ {
+		if c.RetryMaxDelay > 0 && delay >= c.RetryMaxDelay {
+			break
+		}
+		delay *= 2
+	}
+	if c.RetryMaxDelay > 0 && delay > c.RetryMaxDelay {
+		delay = c.RetryMaxDelay
+	}
+	return delay
+}
+
+// retryAt is when a run that has failed with a retryable error may be
+// attempted again. It's zero for runs that haven't been retried.
+func (c ExecutorConfig) retryAt(run scheduling.TaskRunMetadata) time.Time {
+	if run.Attempts == 0 || run.LastFailedAttempt.IsZero() {
+		return time.Time{}
+	}
+	return run.LastFailedAttempt.Add(c.retryDelay(run.Attempts))
 }
 
 type Executor struct {
 	locker   *metadata.TaskLocker
 	metadata *metadata.Client
 	spawner  spawner.JobSpawner
+	metrics  metrics.JobMetricsHandler
 	logger   logging.Logger
 	config   ExecutorConfig
+	// providers is shared by the tasks the executor runs.
+	providers *tasks.ProviderCache
+}
+
+func (e *Executor) jobMetrics() metrics.JobMetricsHandler {
+	if e.metrics == nil {
+		return &metrics.NoOpJobMetricsHandler{}
+	}
+	return e.metrics
+}
+
+// jobResourceType is the resource type that a run's metrics are labeled with.
+func jobResourceType(run scheduling.TaskRunMetadata) string {
+	if nv, ok := run.Target.(scheduling.NameVariant); ok {
+		return nv.ResourceType
+	}
+	return string(run.TargetType)
 }
 
 // We should only need to pass the runID here, but the way the data is stored doesn't allow that atm
@@ -93,6 +145,13 @@ func (e *Executor) RunTask(tid scheduling.TaskID, rid scheduling.TaskRunID) erro
 		return nil
 	}
 
+	// A run that failed with a retryable error stays running and is picked up by
+	// the scheduler again once its backoff has passed.
+	if retryAt := e.config.retryAt(run); time.Now().Before(retryAt) {
+		logger.Debugw("Run is waiting to be retried, skipping...", "retry_at", retryAt)
+		return nil
+	}
+
 	logger.Info("Checking task dependencies")
 	err = e.waitForPendingDependencies(run, logger)
 	if _, ok := err.(*fferr.DependencyFailedError); ok {
@@ -123,8 +182,10 @@ func (e *Executor) RunTask(tid scheduling.TaskID, rid scheduling.TaskRunID) erro
 		isUpdate = true
 	}
 
-	task, err := e.getTaskRunner(run, lastSuccessfulRun, isUpdate, logger)
+	observer := e.jobMetrics().BeginObservingJob(jobResourceType(run))
+	task, err := e.getTaskRunner(run, lastSuccessfulRun, isUpdate, observer, logger)
 	if err != nil {
+		observer.SetError()
 		return err
 	}
 	logger.Debugw("Setting run status to running", "runner", task)
@@ -135,6 +196,8 @@ func (e *Executor) RunTask(tid scheduling.TaskID, rid scheduling.TaskRunID) erro
 			logger.Errorw("Failed to set run status to running", "error", err)
 			return err
 		}
+	} else if run.Status == scheduling.RUNNING && run.Attempts > 0 {
+		logger.Infow("Retrying run", "attempts", run.Attempts)
 	} else if run.Status == scheduling.RUNNING {
 		logger.Infow("Rerunning previously attempted task")
 		if err := e.metadata.Tasks.AddRunLog(tid, rid, "Previous run did not complete. Restarting..."); err != nil {
@@ -160,13 +223,25 @@ func (e *Executor) RunTask(tid scheduling.TaskID, rid scheduling.TaskRunID) erro
 	//	return err
 
 	case err := <-runErrChan:
+		// An interrupted run is left running, so that it's resumed once the
+		// scheduler picks it up again.
+		if errors.Is(err, tasks.ErrInterrupted) {
+			logger.Info("Run interrupted by shutdown")
+			return nil
+		}
+		if err != nil && e.retryRun(tid, rid, err, logger) {
+			observer.SetError()
+			return nil
+		}
 		if err != nil {
+			observer.SetError()
 			logger.Errorf("Run Failed: %s", err.Error())
 			if err := e.handleRunStatus(tid, rid, scheduling.FAILED, err); err != nil {
 				logger.Error(err.Error())
 			}
 			return fferr.NewTaskRunFailedError(tid.String(), rid.String(), err)
 		}
+		observer.Finish()
 		logger.Info("Run Ready")
 		if err := e.handleRunStatus(tid, rid, scheduling.READY, err); err != nil {
 			logger.Error(err.Error())
@@ -175,6 +250,32 @@ func (e *Executor) RunTask(tid scheduling.TaskID, rid scheduling.TaskRunID) erro
 	}
 }
 
+// retryRun records a failed attempt of the run if it should be attempted again.
+// The run is left running, so the scheduler attempts it again once the backoff
+// has passed. Only retryable errors are retried, and only until the run has
+// failed more than MaxRetries times.
+func (e *Executor) retryRun(tid scheduling.TaskID, rid scheduling.TaskRunID, runErr error, logger logging.Logger) bool {
+	if e.config.MaxRetries <= 0 || !fferr.IsRetryable(runErr) {
+		return false
+	}
+	attempts, err := e.metadata.Tasks.IncrementRunAttempts(tid, rid)
+	if err != nil {
+		logger.Errorw("Failed to increment run attempts", "error", err)
+		return false
+	}
+	if attempts > e.config.MaxRetries {
+		logger.Infow("Run has no retries left", "attempts", attempts, "max_retries", e.config.MaxRetries)
+		return false
+	}
+	delay := e.config.retryDelay(attempts)
+	logger.Warnw("Run failed with a retryable error", "attempts", attempts, "delay", delay, "error", runErr)
+	msg := fmt.Sprintf("Attempt %d failed: %s. Retrying in %s...", attempts, runErr.Error(), delay)
+	if err := e.metadata.Tasks.AddRunLog(tid, rid, msg); err != nil {
+		logger.Errorw("Failed to add run log", "error", err)
+	}
+	return true
+}
+
 func (e *Executor) handleRunStatus(tid scheduling.TaskID, rid scheduling.TaskRunID, status scheduling.Status, err error) error {
 	if err := e.metadata.Tasks.SetRunStatus(tid, rid, status, err); err != nil {
 		return err
@@ -202,12 +303,13 @@ func (e *Executor) Run(task tasks.Task) chan error {
 	return errChan
 }
 
-func (e *Executor) getTaskRunner(runMetadata scheduling.TaskRunMetadata, lastSuccessfulRun scheduling.TaskRunMetadata, isUpdate bool, logger logging.Logger) (tasks.Task, error) {
+func (e *Executor) getTaskRunner(runMetadata scheduling.TaskRunMetadata, lastSuccessfulRun scheduling.TaskRunMetadata, isUpdate bool, observer metrics.JobObserver, logger logging.Logger) (tasks.Task, error) {
 	logger.Infow("getTaskRunner", "last task", lastSuccessfulRun)
 	taskConfig := tasks.TaskConfig{
 		DependencyPollInterval: e.config.DependencyPollInterval,
+		Providers:              e.providers,
 	}
-	baseTask := tasks.NewBaseTask(e.metadata, runMetadata, lastSuccessfulRun, isUpdate, runMetadata.IsDelete, e.spawner, logger, taskConfig)
+	baseTask := tasks.NewBaseTask(e.metadata, runMetadata, lastSuccessfulRun, isUpdate, runMetadata.IsDelete, e.spawner, observer, logger, taskConfig)
 	e.logger.Infow("Base task created", "task", baseTask.Redacted())
 	return tasks.Get(runMetadata.TargetType, baseTask)
 }
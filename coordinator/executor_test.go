@@ -125,6 +125,31 @@ func (m MyMockedTaskClient) SetRunResumeID(taskID s.TaskID, runID s.TaskRunID, r
 	panic("implement me")
 }
 
+func (m *MyMockedTaskClient) SetRunProgress(taskID s.TaskID, runID s.TaskRunID, progress s.RunProgress) error {
+	//TODO implement me
+	panic("implement me")
+}
+
+func (m *MyMockedTaskClient) GetTransformationCacheEntry(name, variant string) (s.TransformationCacheEntry, error) {
+	//TODO implement me
+	panic("implement me")
+}
+
+func (m *MyMockedTaskClient) SetTransformationCacheEntry(name, variant, hash string) error {
+	//TODO implement me
+	panic("implement me")
+}
+
+func (m *MyMockedTaskClient) DeleteTransformationCacheEntry(name, variant string) error {
+	//TODO implement me
+	panic("implement me")
+}
+
+func (m *MyMockedTaskClient) IncrementRunAttempts(tid s.TaskID, rid s.TaskRunID) (int, error) {
+	args := m.Called(tid, rid)
+	return args.Int(0), args.Error(1)
+}
+
 func (m MyMockedTaskClient) EndRun(tid s.TaskID, rid s.TaskRunID) error {
 	args := m.Called(tid, rid)
 	return args.Error(0)
@@ -1206,6 +1231,32 @@ func TestTaskRecovery(t *testing.T) {
 
 }
 
+func TestExecutorRetryDelay(t *testing.T) {
+	config := ExecutorConfig{RetryBaseDelay: time.Second, RetryMaxDelay: 5 * time.Second}
+	expected := []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 5 * time.Second, 5 * time.Second}
+	for i, delay := range expected {
+		if got := config.retryDelay(i + 1); got != delay {
+			t.Fatalf("Attempt %d: expected %s, got %s", i+1, delay, got)
+		}
+	}
+	uncapped := ExecutorConfig{RetryBaseDelay: time.Second}
+	if got := uncapped.retryDelay(6); got != 32*time.Second {
+		t.Fatalf("Expected 32s without a max delay, got %s", got)
+	}
+}
+
+func TestExecutorRetryAt(t *testing.T) {
+	config := ExecutorConfig{RetryBaseDelay: time.Second, RetryMaxDelay: 5 * time.Second}
+	if retryAt := config.retryAt(s.TaskRunMetadata{}); !retryAt.IsZero() {
+		t.Fatalf("Expected a run without attempts to be runnable, got %s", retryAt)
+	}
+	failed := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	run := s.TaskRunMetadata{Attempts: 2, LastFailedAttempt: failed}
+	if retryAt := config.retryAt(run); !retryAt.Equal(failed.Add(2 * time.Second)) {
+		t.Fatalf("Expected retry 2s after the failed attempt, got %s", retryAt)
+	}
+}
+
 func difference(a, b []s.TaskRunMetadata) []s.TaskRunMetadata {
 	var diff []s.TaskRunMetadata
 
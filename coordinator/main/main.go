@@ -10,6 +10,8 @@ package main
 import (
 	"context"
 	"fmt"
+	"os/signal"
+	"syscall"
 	"time"
 
 	"github.com/featureform/config"
@@ -18,8 +20,11 @@ import (
 
 	"github.com/featureform/coordinator/spawner"
 	help "github.com/featureform/helpers"
+	"github.com/featureform/helpers/tracing"
 	"github.com/featureform/logging"
 	"github.com/featureform/metadata"
+	"github.com/featureform/metrics"
+	"github.com/featureform/scheduling"
 )
 
 func main() {
@@ -46,7 +51,17 @@ func main() {
 		logger.Errorw("Failed to bootstrap service from config", "err", err)
 		panic(err)
 	}
-	defer logger.LogIfErr("Failed to close service-level resources", init.Close())
+	defer func() {
+		logger.LogIfErr("Failed to close service-level resources", init.Close())
+	}()
+	shutdownTracing, err := tracing.Init(ctx, "coordinator", help.GetEnv(tracing.EndpointEnv, ""))
+	if err != nil {
+		logger.Errorw("Failed to initialize tracing", "err", err)
+		panic(err)
+	}
+	defer func() {
+		logger.LogIfErr("Failed to shut down tracing", shutdownTracing(context.Background()))
+	}()
 
 	logger.Infof("connecting to metadata: %s\n", metadataUrl)
 	client, err := metadata.NewClient(metadataUrl, logger)
@@ -96,13 +111,57 @@ func main() {
 			}
 			return interval
 		}(),
+		MaxRetries: help.GetEnvInt("TASK_MAX_RETRIES", 3),
+		RetryBaseDelay: func() time.Duration {
+			delay, err := time.ParseDuration(help.GetEnv("TASK_RETRY_BASE_DELAY", "30s"))
+			if err != nil {
+				logger.Errorw("Invalid TASK_RETRY_BASE_DELAY")
+				panic(err.Error())
+			}
+			return delay
+		}(),
+		RetryMaxDelay: func() time.Duration {
+			delay, err := time.ParseDuration(help.GetEnv("TASK_RETRY_MAX_DELAY", "10m"))
+			if err != nil {
+				logger.Errorw("Invalid TASK_RETRY_MAX_DELAY")
+				panic(err.Error())
+			}
+			return delay
+		}(),
+		ProviderIdleTimeout: func() time.Duration {
+			timeout, err := time.ParseDuration(help.GetEnv("PROVIDER_CACHE_IDLE_TIMEOUT", "5m"))
+			if err != nil {
+				logger.Errorw("Invalid PROVIDER_CACHE_IDLE_TIMEOUT")
+				panic(err.Error())
+			}
+			return timeout
+		}(),
+		ShutdownTimeout: appConfig.ShutdownTimeout,
+	}
+
+	jobMetrics := metrics.NewJobMetrics("")
+	metricsPort := help.GetEnv("METRICS_PORT", ":9090")
+	logger.Infow("Serving metrics", "port", metricsPort)
+	go jobMetrics.ExposePort(metricsPort)
+
+	cronPollInterval, err := time.ParseDuration(help.GetEnv("CRON_SCHEDULE_POLL_INTERVAL", "30s"))
+	if err != nil {
+		logger.Errorw("Invalid CRON_SCHEDULE_POLL_INTERVAL")
+		panic(err.Error())
 	}
+	// The schedulers stop watching for tasks once the coordinator is signaled
+	// to stop, and the running tasks are finished before it exits.
+	watchCtx, stopWatch := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stopWatch()
+	cronScheduler := scheduling.NewCronScheduler(&manager, manager.Storage.Locker, cronPollInterval, logger.With("component", "cron-scheduler"))
+	go cronScheduler.Start(watchCtx)
 
 	logger.Info("Dependencies created. Starting Scheduler...")
-	scheduler := coordinator.NewScheduler(client, logger, spawnerInstance, manager.Storage.Locker, config)
+	scheduler := coordinator.NewScheduler(client, logger, spawnerInstance, manager.Storage.Locker, jobMetrics, config)
 
-	err = scheduler.Start()
+	err = scheduler.Start(watchCtx)
 	if err != nil {
 		panic(err.Error())
 	}
+	logger.Info("Scheduler stopped, shutting down")
 }
@@ -8,16 +8,25 @@
 package coordinator
 
 import (
+	"context"
+	"sync"
 	"time"
 
 	"github.com/featureform/coordinator/spawner"
+	"github.com/featureform/coordinator/tasks"
+	"github.com/featureform/fferr"
 	"github.com/featureform/ffsync"
 	"github.com/featureform/logging"
 	"github.com/featureform/metadata"
+	"github.com/featureform/metrics"
 	"github.com/featureform/scheduling"
 )
 
-func NewScheduler(client *metadata.Client, logger logging.Logger, spawner spawner.JobSpawner, locker ffsync.Locker, config SchedulerConfig) *Scheduler {
+func NewScheduler(client *metadata.Client, logger logging.Logger, spawner spawner.JobSpawner, locker ffsync.Locker, jobMetrics metrics.JobMetricsHandler, config SchedulerConfig) *Scheduler {
+	var providers *tasks.ProviderCache
+	if config.ProviderIdleTimeout > 0 {
+		providers = tasks.NewProviderCache(config.ProviderIdleTimeout, logger.With("component", "provider-cache"))
+	}
 	return &Scheduler{
 		Metadata: client,
 		Logger:   logger,
@@ -27,8 +36,15 @@ func NewScheduler(client *metadata.Client, logger logging.Logger, spawner spawne
 			locker: &metadata.TaskLocker{
 				Locker: locker,
 			},
-			spawner: spawner,
-			config:  ExecutorConfig{DependencyPollInterval: config.DependencyPollInterval},
+			spawner:   spawner,
+			metrics:   jobMetrics,
+			providers: providers,
+			config: ExecutorConfig{
+				DependencyPollInterval: config.DependencyPollInterval,
+				MaxRetries:             config.MaxRetries,
+				RetryBaseDelay:         config.RetryBaseDelay,
+				RetryMaxDelay:          config.RetryMaxDelay,
+			},
 		},
 		Config: config,
 	}
@@ -38,6 +54,15 @@ type SchedulerConfig struct {
 	TaskPollInterval       time.Duration
 	TaskStatusSyncInterval time.Duration
 	DependencyPollInterval time.Duration
+	MaxRetries             int
+	RetryBaseDelay         time.Duration
+	RetryMaxDelay          time.Duration
+	// ProviderIdleTimeout is how long an unused provider is kept open for
+	// later tasks to reuse. Providers aren't reused if it's zero.
+	ProviderIdleTimeout time.Duration
+	// ShutdownTimeout is how long Start waits for running tasks to finish once
+	// it's stopped. It waits until they finish if it's zero.
+	ShutdownTimeout time.Duration
 }
 
 type Scheduler struct {
@@ -45,13 +70,19 @@ type Scheduler struct {
 	Logger       logging.Logger
 	Executor     *Executor
 	Config       SchedulerConfig
-	stop         bool
 	lastSyncTime time.Time
 }
 
-func (c *Scheduler) Start() error {
+// Start watches for unfinished runs and runs them until ctx is done. It then
+// waits up to Config.ShutdownTimeout for the runs it started to finish before
+// returning, so that a shutdown doesn't leave them half done. It returns an
+// error if they haven't finished by then.
+func (c *Scheduler) Start(ctx context.Context) error {
 	c.Logger.Info("Watching for new jobs")
-	for !c.stop {
+	var running sync.WaitGroup
+	ticker := time.NewTicker(c.Config.TaskPollInterval)
+	defer ticker.Stop()
+	for {
 		if c.shouldSyncTaskStatus() {
 			err := c.Metadata.Tasks.SyncUnfinishedRuns()
 			if err != nil {
@@ -66,16 +97,52 @@ func (c *Scheduler) Start() error {
 		}
 
 		for _, run := range runs {
+			running.Add(1)
 			go func(run scheduling.TaskRunMetadata) {
-				err = c.Executor.RunTask(run.TaskId, run.ID)
+				defer running.Done()
+				err := c.Executor.RunTask(run.TaskId, run.ID)
 				if err != nil {
 					c.Logger.Error(err.Error())
 				}
 			}(run)
 		}
-		time.Sleep(c.Config.TaskPollInterval)
+		select {
+		case <-ctx.Done():
+			c.Logger.Info("Stopped watching for new jobs, waiting for running tasks to finish")
+			// Streams run until they're stopped, so they're stopped here for
+			// the coordinator that picks their runs up next to resume.
+			tasks.StopStreams()
+			finished := waitTimeout(&running, c.Config.ShutdownTimeout)
+			c.Executor.providers.Close()
+			if !finished {
+				return fferr.NewInternalErrorf("running tasks didn't finish within the shutdown timeout of %v", c.Config.ShutdownTimeout)
+			}
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// waitTimeout waits for wg until timeout passes, or indefinitely if timeout is
+// zero. It returns false if wg hadn't finished by then.
+func waitTimeout(wg *sync.WaitGroup, timeout time.Duration) bool {
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+	if timeout <= 0 {
+		<-done
+		return true
+	}
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case <-done:
+		return true
+	case <-timer.C:
+		return false
 	}
-	return nil
 }
 
 func (c *Scheduler) shouldSyncTaskStatus() bool {
@@ -85,7 +152,3 @@ func (c *Scheduler) shouldSyncTaskStatus() bool {
 	}
 	return false
 }
-
-func (c *Scheduler) Stop() {
-	c.stop = true
-}
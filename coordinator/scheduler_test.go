@@ -9,6 +9,7 @@ package coordinator
 
 import (
 	"context"
+	"sync"
 	"testing"
 	"time"
 
@@ -53,10 +54,11 @@ func newScheduler(t *testing.T) (*Scheduler, *metadata.MetadataServer, *metadata
 
 func TestCreatePrimary(t *testing.T) {
 	scheduler, _, client := newScheduler(t)
-	defer scheduler.Stop()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
 
 	go func() {
-		err := scheduler.Start()
+		err := scheduler.Start(ctx)
 		if err != nil {
 			t.Errorf(err.Error())
 			return
@@ -104,15 +106,16 @@ func TestCreatePrimary(t *testing.T) {
 	if !ready {
 		t.Fatalf("source not ready")
 	}
-	scheduler.Stop()
+	cancel()
 }
 
 func TestCreateFeature(t *testing.T) {
 	scheduler, _, client := newScheduler(t)
-	defer scheduler.Stop()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
 
 	go func() {
-		err := scheduler.Start()
+		err := scheduler.Start(ctx)
 		if err != nil {
 			t.Errorf(err.Error())
 			return
@@ -182,3 +185,36 @@ func TestCreateFeature(t *testing.T) {
 		t.Fatalf("feature not ready")
 	}
 }
+
+func TestSchedulerStopsWhenContextIsDone(t *testing.T) {
+	scheduler, _, _ := newScheduler(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- scheduler.Start(ctx)
+	}()
+	cancel()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("Scheduler stopped with error: %v", err)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatalf("Scheduler didn't stop after its context was cancelled")
+	}
+}
+
+func TestWaitTimeout(t *testing.T) {
+	var wg sync.WaitGroup
+	wg.Add(1)
+	if waitTimeout(&wg, 10*time.Millisecond) {
+		t.Fatalf("Expected wait for a stuck task to time out")
+	}
+	wg.Done()
+	if !waitTimeout(&wg, time.Second) {
+		t.Fatalf("Expected wait to finish once the task is done")
+	}
+	if !waitTimeout(&wg, 0) {
+		t.Fatalf("Expected wait without a timeout to finish")
+	}
+}
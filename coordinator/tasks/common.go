@@ -29,7 +29,7 @@ func getOfflineStore(
 	client *metadata.Client,
 	pf offlineProviderFetcher,
 	logger logging.Logger,
-) (provider.OfflineStore, error) {
+) (provider.OfflineStore, func(), error) {
 	logMessage := "Fetching Provider..."
 	if err := client.Tasks.AddRunLog(baseTask.taskDef.TaskId, baseTask.taskDef.ID, logMessage); err != nil {
 		logger.Warnw("Failed to add run log; continuing.", "error", err)
@@ -39,29 +39,40 @@ func getOfflineStore(
 	providerEntry, err := pf.FetchProvider(client, ctx)
 	if err != nil {
 		logger.Errorw("Failed to fetch provider", "error", err)
-		return nil, err
+		return nil, nil, err
 	}
+	baseTask.jobObserver().SetProvider(providerEntry.Type())
 
 	logMessage = "Fetching Offline Store..."
 	if err := client.Tasks.AddRunLog(baseTask.taskDef.TaskId, baseTask.taskDef.ID, logMessage); err != nil {
 		logger.Warnw("Failed to add run log; continuing", "error", err, "message", logMessage)
 	}
-	p, err := provider.Get(pt.Type(providerEntry.Type()), providerEntry.SerializedConfig())
+	p, release, err := baseTask.config.Providers.Get(providerEntry.Name(), pt.Type(providerEntry.Type()), providerEntry.SerializedConfig(), logger)
 	if err != nil {
 		logger.Errorw("Failed to get provider", "error", err)
-		return nil, err
+		return nil, nil, err
 	}
 
 	store, err := p.AsOfflineStore()
 	if err != nil {
 		logger.Errorw("Retrieved provider is not an offline store", "provider-type", p.Type(), "error", err)
-		return nil, err
+		release()
+		return nil, nil, err
 	}
 
 	if store == nil {
 		logger.Errorw("Offline store is nil", "provider-type", p.Type())
-		return nil, fferr.NewInternalErrorf("offline store is nil")
+		release()
+		return nil, nil, fferr.NewInternalErrorf("offline store is nil")
 	}
 
-	return store, nil
+	return store, release, nil
+}
+
+// closeProvider closes a provider that's no longer used. A failure to close
+// is only logged since the work done with it is already complete.
+func closeProvider(p provider.Provider, logger logging.Logger) {
+	if err := p.Close(); err != nil {
+		logger.Warnw("Failed to close provider", "provider-type", p.Type(), "error", err)
+	}
 }
@@ -11,8 +11,13 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io"
+	"sync"
 	"time"
 
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
 	"github.com/featureform/logging"
 	"github.com/featureform/provider/provider_schema"
 
@@ -69,6 +74,16 @@ func (t *FeatureTask) Run() error {
 		return err
 	}
 
+	if source.IsPrimaryData() {
+		location, err := source.GetPrimaryLocation()
+		if err != nil {
+			return err
+		}
+		if kafkaLocation, ok := location.(*pl.KafkaLocation); ok {
+			return t.streamFeature(ctx, feature, source, kafkaLocation, logger)
+		}
+	}
+
 	if err := t.metadata.Tasks.AddRunLog(t.taskDef.TaskId, t.taskDef.ID, "Fetching Offline Store..."); err != nil {
 		return err
 	}
@@ -77,14 +92,34 @@ func (t *FeatureTask) Run() error {
 	if err != nil {
 		return err
 	}
-	p, err := provider.Get(pt.Type(sourceProvider.Type()), sourceProvider.SerializedConfig())
+	t.jobObserver().SetProvider(sourceProvider.Type())
+	p, release, err := t.config.Providers.Get(sourceProvider.Name(), pt.Type(sourceProvider.Type()), sourceProvider.SerializedConfig(), logger)
 	if err != nil {
 		return err
 	}
+	defer release()
 	sourceStore, err := p.AsOfflineStore()
 	if err != nil {
 		return err
 	}
+	filter := feature.MaterializationFilter()
+	if filter != "" {
+		supported, err := sourceStore.SupportsMaterializationOption(provider.FilteredMaterialization)
+		if err != nil {
+			return err
+		}
+		if !supported {
+			return fferr.NewInvalidArgumentErrorf("%s doesn't support materialization filters", sourceStore.Type())
+		}
+	}
+
+	var partitionBy provider.MaterializationPartition
+	if partition := feature.MaterializationPartition(); partition != nil {
+		partitionBy = provider.MaterializationPartition{
+			Type:    provider.MaterializationPartitionType(partition.Type),
+			Buckets: partition.Buckets,
+		}
+	}
 
 	var inferenceStore *metadata.Provider
 	if feature.Provider() != "" {
@@ -125,6 +160,7 @@ func (t *FeatureTask) Run() error {
 		Value:       tmpSchema.Value,
 		TS:          tmpSchema.TS,
 		SourceTable: sourceLocation,
+		ValueType:   vType,
 		EntityMappings: metadata.EntityMappings{
 			Mappings: []metadata.EntityMapping{
 				{Name: feature.Entity(), EntityColumn: tmpSchema.Entity},
@@ -138,7 +174,23 @@ func (t *FeatureTask) Run() error {
 		return err
 	}
 
-	if _, err := sourceStore.RegisterResourceFromSourceTable(featID, schema); err != nil {
+	var resourceOpts []provider.ResourceOption
+	columnCasts, err := feature.ColumnCasts()
+	if err != nil {
+		return err
+	}
+	if len(columnCasts) > 0 {
+		resourceOpts = append(resourceOpts, &provider.ColumnCastOption{Casts: columnCasts})
+	}
+	if format := feature.TimestampFormat(); format != nil {
+		resourceOpts = append(resourceOpts, &provider.TimestampFormatOption{
+			Format: provider.TimestampFormat{
+				Encoding: provider.TimestampEncoding(format.Encoding),
+				Timezone: format.Timezone,
+			},
+		})
+	}
+	if _, err := sourceStore.RegisterResourceFromSourceTable(featID, schema, resourceOpts...); err != nil {
 		return err
 	}
 	logger.Debugw("Resource Table Created")
@@ -162,12 +214,16 @@ func (t *FeatureTask) Run() error {
 
 	providerResID := provider.ResourceID{Name: nv.Name, Variant: nv.Variant, Type: provider.Feature}
 	materializedRunnerConfig := runner.MaterializedRunnerConfig{
-		OfflineType:   pt.Type(sourceProvider.Type()),
-		OfflineConfig: sourceProvider.SerializedConfig(),
-		ResourceID:    providerResID,
-		VType:         types.ValueTypeJSONWrapper{ValueType: vType},
-		Cloud:         runner.LocalMaterializeRunner,
-		IsUpdate:      t.isUpdate,
+		OfflineType:    pt.Type(sourceProvider.Type()),
+		OfflineConfig:  sourceProvider.SerializedConfig(),
+		ResourceID:     providerResID,
+		VType:          types.ValueTypeJSONWrapper{ValueType: vType},
+		Cloud:          runner.LocalMaterializeRunner,
+		IsUpdate:       t.isUpdate,
+		MaxConcurrency: helpers.GetEnvInt("MATERIALIZE_MAX_CONCURRENCY", runner.DefaultMaxConcurrency),
+		TTL:            feature.TTL(),
+		// Sample the copied values against the materialization, if enabled.
+		VerifySampleSize: helpers.GetEnvInt("MATERIALIZE_VERIFY_SAMPLE_SIZE", 0),
 		Options: provider.MaterializationOptions{
 			Output:                  filestore.Parquet,
 			ShouldIncludeHeaders:    true,
@@ -175,6 +231,12 @@ func (t *FeatureTask) Run() error {
 			JobName:                 fmt.Sprintf("featureform-materialization--%s--%s", nv.Name, nv.Variant),
 			ResourceSnowflakeConfig: resourceSnowflakeConfig,
 			Schema:                  schema,
+			Filter:                  filter,
+			PartitionBy:             partitionBy,
+			// Updates only read the rows past the materialization's watermark, if
+			// enabled. Stores that can't do this fail the update rather than
+			// silently recomputing the feature.
+			Incremental: t.isUpdate && helpers.GetEnvBool("MATERIALIZE_INCREMENTAL", false),
 		},
 	}
 
@@ -188,11 +250,12 @@ func (t *FeatureTask) Run() error {
 	supportsDirectCopy := false
 	var onlineStore provider.OnlineStore
 	if inferenceStore != nil {
-		onlineProvider, err := provider.Get(pt.Type(inferenceStore.Type()), inferenceStore.SerializedConfig())
+		onlineProvider, release, err := t.config.Providers.Get(inferenceStore.Name(), pt.Type(inferenceStore.Type()), inferenceStore.SerializedConfig(), logger)
 		if err != nil {
 			logger.Errorw("Failed to get online provider", "error", err)
 			return err
 		}
+		defer release()
 		casted, err := onlineProvider.AsOnlineStore()
 		if err != nil {
 			logger.Errorw("Failed to cast provider as online store", "error", err)
@@ -205,7 +268,9 @@ func (t *FeatureTask) Run() error {
 		if err != nil {
 			return err
 		}
-		supportsDirectCopy = supports
+		// Direct copies write values without an expiry, so they can't be used with a TTL.
+		// Redis keeps vectors in a search index rather than a hash, so those go through the runner.
+		supportsDirectCopy = supports && feature.TTL() == 0 && !(matOpt == provider.DirectCopyRedis && vType.IsVector())
 	}
 
 	if err := t.metadata.Tasks.AddRunLog(t.taskDef.TaskId, t.taskDef.ID, "Starting Materialization..."); err != nil {
@@ -224,11 +289,30 @@ func (t *FeatureTask) Run() error {
 				return err
 			}
 		}
-		_, materializationErr = sourceStore.CreateMaterialization(providerResID, provider.MaterializationOptions{
+		// Direct copies run as a single job, so only the phase is reported.
+		progress := t.progressReporter()
+		progress.ReportProgress(scheduling.RunProgress{Phase: scheduling.WritingPhase})
+		var materialization provider.Materialization
+		materialization, materializationErr = sourceStore.CreateMaterialization(providerResID, provider.MaterializationOptions{
 			MaxJobDuration: maxJobDuration,
 			JobName:        fmt.Sprintf("featureform-materialization--%s--%s", nv.Name, nv.Variant),
 			DirectCopyTo:   onlineStore,
+			Schema:         schema,
+			Filter:         filter,
 		})
+		if materializationErr == nil {
+			var rows int64
+			// Some providers don't return a materialization for direct copies.
+			if materialization != nil {
+				var err error
+				if rows, err = materialization.NumRows(); err != nil {
+					logger.Warnw("Failed to count materialized rows", "error", err)
+				} else {
+					t.jobObserver().AddRows(rows)
+				}
+			}
+			progress.ReportProgress(scheduling.RunProgress{Phase: scheduling.DonePhase, RowsProcessed: rows, TotalRows: rows})
+		}
 	} else {
 		materializationErr = t.materializeFeature(resID, materializedRunnerConfig)
 	}
@@ -266,15 +350,31 @@ func (t *FeatureTask) handleDeletion(ctx context.Context, resID metadata.Resourc
 		return err
 	}
 
+	offlineProvider, err := featureToDelete.FetchOfflineStoreProvider(t.metadata, ctx)
+	if err != nil {
+		logger.Errorw("Failed to fetch offline provider", "error", err)
+		return err
+	}
+	if pt.Type(offlineProvider.Type()) == pt.Kafka {
+		// Streamed features are only written to the online store, which is only
+		// deleted from once the stream has stopped writing to it.
+		if err := t.awaitStreamStopped(featureToDelete, logger); err != nil {
+			logger.Errorw("Failed to wait for Kafka stream to stop", "error", err)
+			return err
+		}
+		return t.finalizeFeatureDeletion(ctx, featureToDelete, resID, nv, logger)
+	}
+
 	logger.Debugf("Deleting feature at location")
 	offlineStoreLocations := featureToDelete.GetOfflineStoreLocations()
 	logger = logger.With("offline_store_locations", offlineStoreLocations)
 	logger.Debug("Getting offline store")
-	sourceStore, err := getOfflineStore(ctx, t.BaseTask, t.metadata, &offlineProviderFeatureAdapter{feature: featureToDelete}, logger)
+	sourceStore, release, err := getOfflineStore(ctx, t.BaseTask, t.metadata, &offlineProviderFeatureAdapter{feature: featureToDelete}, logger)
 	if err != nil {
 		logger.Errorw("Failed to get store", "error", err)
 		return err
 	}
+	defer release()
 
 	logger.Debug("Deleting feature at locations", "locations", offlineStoreLocations)
 	for _, offlineStoreLocation := range offlineStoreLocations {
@@ -297,6 +397,33 @@ func (t *FeatureTask) handleDeletion(ctx context.Context, resID metadata.Resourc
 	}
 
 	logger.Info("Successfully deleted feature from offline store")
+	return t.finalizeFeatureDeletion(ctx, featureToDelete, resID, nv, logger)
+}
+
+// awaitStreamStopped waits for the runs of a streamed feature's tasks to finish.
+// They stop streaming once they see that the feature is staged for deletion.
+func (t *FeatureTask) awaitStreamStopped(feature *metadata.FeatureVariant, logger logging.Logger) error {
+	taskIDs, err := feature.TaskIDs()
+	if err != nil {
+		return err
+	}
+	for _, id := range taskIDs {
+		for {
+			run, err := t.metadata.Tasks.GetLatestRun(id)
+			if err != nil {
+				return err
+			}
+			if run.Status != scheduling.PENDING && run.Status != scheduling.RUNNING {
+				break
+			}
+			logger.Debugw("Waiting for Kafka stream to stop", "stream_task_id", id, "stream_run_id", run.ID)
+			time.Sleep(kafkaStreamCheckInterval)
+		}
+	}
+	return nil
+}
+
+func (t *FeatureTask) finalizeFeatureDeletion(ctx context.Context, featureToDelete *metadata.FeatureVariant, resID metadata.ResourceID, nv metadata.NameVariant, logger logging.Logger) error {
 	deleteFromOnlineStoreErr := t.deleteFromOnlineStore(ctx, featureToDelete, logger, nv)
 	if deleteFromOnlineStoreErr != nil {
 		logger.Errorw("Failed to delete feature from online store", "error", deleteFromOnlineStoreErr)
@@ -334,11 +461,12 @@ func (t *FeatureTask) deleteFromOnlineStore(ctx context.Context, featureToDelete
 	}
 
 	logger.Debugw("Attempting to delete feature from online store", "name", nv.Name, "variant", nv.Variant)
-	onlineProvider, err := provider.Get(pt.Type(inferenceStore.Type()), inferenceStore.SerializedConfig())
+	onlineProvider, release, err := t.config.Providers.Get(inferenceStore.Name(), pt.Type(inferenceStore.Type()), inferenceStore.SerializedConfig(), logger)
 	if err != nil {
 		logger.Errorw("Failed to get online provider", "error", err)
 		return err
 	}
+	defer release()
 	casted, err := onlineProvider.AsOnlineStore()
 	if err != nil {
 		logger.Errorw("Failed to cast provider as online store", "error", err)
@@ -361,6 +489,154 @@ func (t *FeatureTask) deleteFromOnlineStore(ctx context.Context, featureToDelete
 	return nil
 }
 
+// ErrInterrupted is returned by a task that was stopped because the coordinator
+// is shutting down. Its run is left running, so that it's resumed once a
+// coordinator picks it up again.
+var ErrInterrupted = errors.New("task interrupted by shutdown")
+
+// kafkaStreamCheckInterval is how often a stream checks if its feature has been
+// staged for deletion.
+var kafkaStreamCheckInterval = 10 * time.Second
+
+var (
+	streamsShutdown     = make(chan struct{})
+	streamsShutdownOnce sync.Once
+)
+
+// StopStreams stops the streams that this process is running and makes their
+// tasks return ErrInterrupted. Streams started after it's called are stopped
+// immediately.
+func StopStreams() {
+	streamsShutdownOnce.Do(func() {
+		close(streamsShutdown)
+	})
+}
+
+// stoppableRunner is a runner that runs until it's stopped.
+type stoppableRunner interface {
+	Stop()
+}
+
+// streamFeature runs a runner that writes a Kafka topic's messages to the feature's online
+// store as they arrive. Messages are committed only after they're written, so delivery is
+// at-least-once.
+//
+// The task runs for as long as the stream does, so its run stays running and the stream is
+// resumed from its committed offsets by whichever coordinator picks the run up after a
+// restart. The task fails if the stream does, and completes once the feature is staged for
+// deletion.
+func (t *FeatureTask) streamFeature(ctx context.Context, feature *metadata.FeatureVariant, source *metadata.SourceVariant, location *pl.KafkaLocation, logger logging.Logger) error {
+	logger = logger.With("topic", location.Topic)
+	if feature.Provider() == "" {
+		return fferr.NewInvalidArgumentErrorf("features on Kafka topics must have an inference store")
+	}
+	kafkaProvider, err := source.FetchProvider(t.metadata, ctx)
+	if err != nil {
+		return err
+	}
+	t.jobObserver().SetProvider(kafkaProvider.Type())
+	inferenceStore, err := feature.FetchProvider(t.metadata, ctx)
+	if err != nil {
+		return err
+	}
+	vType, err := feature.Type()
+	if err != nil {
+		return err
+	}
+	onlineProvider, release, err := t.config.Providers.Get(inferenceStore.Name(), pt.Type(inferenceStore.Type()), inferenceStore.SerializedConfig(), logger)
+	if err != nil {
+		logger.Errorw("Failed to get online provider", "error", err)
+		return err
+	}
+	defer release()
+	onlineStore, err := onlineProvider.AsOnlineStore()
+	if err != nil {
+		logger.Errorw("Failed to cast provider as online store", "error", err)
+		return err
+	}
+	if _, err := onlineStore.CreateTable(feature.Name(), feature.Variant(), vType); err != nil {
+		var tableExistsErr *fferr.DatasetAlreadyExistsError
+		if !errors.As(err, &tableExistsErr) {
+			return err
+		}
+	}
+
+	columns := feature.LocationColumns().(metadata.ResourceVariantColumns)
+	config := runner.KafkaStreamRunnerConfig{
+		KafkaConfig:   kafkaProvider.SerializedConfig(),
+		Topic:         location.Topic,
+		GroupID:       fmt.Sprintf("featureform-%s-%s", feature.Name(), feature.Variant()),
+		OnlineType:    pt.Type(inferenceStore.Type()),
+		OnlineConfig:  inferenceStore.SerializedConfig(),
+		ResourceID:    provider.ResourceID{Name: feature.Name(), Variant: feature.Variant(), Type: provider.Feature},
+		VType:         types.ValueTypeJSONWrapper{ValueType: vType},
+		EntityColumn:  columns.Entity,
+		ValueColumn:   columns.Value,
+		TSColumn:      columns.TS,
+		BatchSize:     helpers.GetEnvInt("KAFKA_STREAM_BATCH_SIZE", runner.DefaultKafkaStreamBatchSize),
+		FlushInterval: runner.DefaultKafkaStreamFlushInterval,
+	}
+	serialized, err := config.Serialize()
+	if err != nil {
+		return err
+	}
+	if err := t.metadata.Tasks.AddRunLog(t.taskDef.TaskId, t.taskDef.ID, "Starting Kafka stream..."); err != nil {
+		return err
+	}
+	id := metadata.ResourceID{Name: feature.Name(), Variant: feature.Variant(), Type: metadata.FEATURE_VARIANT}
+	jobRunner, err := t.spawner.GetJobRunner(runner.STREAM_TO_ONLINE, serialized, id)
+	if err != nil {
+		return err
+	}
+	stream, ok := jobRunner.(stoppableRunner)
+	if !ok {
+		return fferr.NewInternalErrorf("expected a stoppable runner for Kafka streams, got %T", jobRunner)
+	}
+	completionWatcher, err := jobRunner.Run()
+	if err != nil {
+		return err
+	}
+	done := make(chan error, 1)
+	go func() {
+		done <- completionWatcher.Wait()
+	}()
+	if err := t.metadata.Tasks.AddRunLog(t.taskDef.TaskId, t.taskDef.ID, "Kafka stream started..."); err != nil {
+		logger.Warnw("Failed to add run log; continuing.", "error", err)
+	}
+	nv := metadata.NameVariant{Name: feature.Name(), Variant: feature.Variant()}
+	ticker := time.NewTicker(kafkaStreamCheckInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case err := <-done:
+			if err != nil {
+				logger.Errorw("Kafka stream failed", "error", err)
+				return err
+			}
+			logger.Info("Kafka stream stopped")
+			return nil
+		case <-streamsShutdown:
+			logger.Info("Stopping Kafka stream for shutdown")
+			stream.Stop()
+			if err := <-done; err != nil {
+				logger.Errorw("Kafka stream failed while stopping", "error", err)
+			}
+			return ErrInterrupted
+		case <-ticker.C:
+			// A feature that's staged for deletion isn't found anymore.
+			if _, err := t.metadata.GetFeatureVariant(ctx, nv); status.Code(err) != codes.NotFound {
+				continue
+			}
+			logger.Info("Feature is staged for deletion, stopping Kafka stream")
+			stream.Stop()
+			if err := <-done; err != nil {
+				logger.Errorw("Kafka stream failed while stopping", "error", err)
+			}
+			return t.metadata.Tasks.AddRunLog(t.taskDef.TaskId, t.taskDef.ID, "Kafka stream stopped...")
+		}
+	}
+}
+
 func (t *FeatureTask) materializeFeature(id metadata.ResourceID, config runner.MaterializedRunnerConfig) error {
 	t.logger.Infow("Starting Feature Materialization", "id", id)
 	err := t.metadata.Tasks.AddRunLog(t.taskDef.TaskId, t.taskDef.ID, "Starting Materialization via Copy...")
@@ -375,6 +651,19 @@ func (t *FeatureTask) materializeFeature(id metadata.ResourceID, config runner.M
 	if err != nil {
 		return err
 	}
+	// Runners spawned in this process can report the rows they copy directly.
+	if materializeRunner, ok := jobRunner.(*runner.MaterializeRunner); ok {
+		materializeRunner.Observer = t.jobObserver()
+		materializeRunner.Progress = t.progressReporter()
+	}
+	// Runners spawned in this process hold their stores open until closed.
+	if closer, ok := jobRunner.(io.Closer); ok {
+		defer func() {
+			if err := closer.Close(); err != nil {
+				t.logger.Warnw("Failed to close materialize runner", "error", err)
+			}
+		}()
+	}
 	completionWatcher, err := jobRunner.Run()
 	if err != nil {
 		return err
@@ -59,6 +59,11 @@ func (t *LabelTask) Run() error {
 		return err
 	}
 	logger.Debugw("Label Location", "location", loc)
+	vType, err := label.Type()
+	if err != nil {
+		logger.Errorw("Failed to get label type", "error", err)
+		return err
+	}
 
 	if err := t.metadata.Tasks.AddRunLog(t.taskDef.TaskId, t.taskDef.ID, "Waiting for dependencies to complete..."); err != nil {
 		logger.Errorw("Failed to add run log", "error", err)
@@ -76,17 +81,11 @@ func (t *LabelTask) Run() error {
 		return err
 	}
 
-	sourceStore, getStoreErr := getOfflineStore(ctx, t.BaseTask, t.metadata, source, logger)
+	sourceStore, release, getStoreErr := getOfflineStore(ctx, t.BaseTask, t.metadata, source, logger)
 	if getStoreErr != nil {
 		return getStoreErr
 	}
-	defer func(sourceStore provider.OfflineStore, logger logging.Logger) {
-		err := sourceStore.Close()
-		if err != nil {
-			logger.Errorf("could not close offline store: %v", err)
-		}
-		logger.Debug("Closed offline store")
-	}(sourceStore, logger)
+	defer release()
 	var sourceLocation pl.Location
 	var sourceLocationErr error
 	if source.IsSQLTransformation() || source.IsDFTransformation() {
@@ -110,6 +109,7 @@ func (t *LabelTask) Run() error {
 	schema := provider.ResourceSchema{
 		SourceTable:    sourceLocation,
 		EntityMappings: loc,
+		ValueType:      vType,
 	}
 	logger.Debugw("Creating Label Resource Table", "id", labelID, "schema", schema)
 
@@ -117,8 +117,24 @@ func (t *LabelTask) Run() error {
 		logger.Errorw("Failed to add run log", "error", err)
 		return err
 	}
+	var resourceOpts []provider.ResourceOption
+	columnCasts, err := label.ColumnCasts()
+	if err != nil {
+		return err
+	}
+	if len(columnCasts) > 0 {
+		resourceOpts = append(resourceOpts, &provider.ColumnCastOption{Casts: columnCasts})
+	}
+	if format := label.TimestampFormat(); format != nil {
+		resourceOpts = append(resourceOpts, &provider.TimestampFormatOption{
+			Format: provider.TimestampFormat{
+				Encoding: provider.TimestampEncoding(format.Encoding),
+				Timezone: format.Timezone,
+			},
+		})
+	}
 	logger.Debugw("Calling offline store to register resource from source table")
-	if _, err := sourceStore.RegisterResourceFromSourceTable(labelID, schema); err != nil {
+	if _, err := sourceStore.RegisterResourceFromSourceTable(labelID, schema, resourceOpts...); err != nil {
 		logger.Errorw("Failed to register resource from source table", "id", labelID, "error", err)
 		return err
 	}
@@ -154,11 +170,12 @@ func (t *LabelTask) handleDeletion(ctx context.Context, resID metadata.ResourceI
 		return tableNameErr
 	}
 
-	sourceStore, err := getOfflineStore(ctx, t.BaseTask, t.metadata, labelToDelete, logger)
+	sourceStore, release, err := getOfflineStore(ctx, t.BaseTask, t.metadata, labelToDelete, logger)
 	if err != nil {
 		logger.Errorw("Failed to get store", "error", err)
 		return err
 	}
+	defer release()
 
 	labelLocation := pl.NewSQLLocation(labelTableName)
 	logger = logger.With("location", labelLocation)
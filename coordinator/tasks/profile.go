@@ -0,0 +1,103 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+//
+// Copyright 2024 FeatureForm Inc.
+//
+
+package tasks
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/featureform/fferr"
+	"github.com/featureform/logging"
+	"github.com/featureform/metadata"
+	pb "github.com/featureform/metadata/proto"
+	"github.com/featureform/provider"
+	"github.com/featureform/scheduling"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func NewSourceProfileFactory(task BaseTask) (Task, error) {
+	return &SourceProfileTask{BaseTask: task}, nil
+}
+
+// SourceProfileTask profiles the columns of a source in its offline store and
+// saves the profile with the source variant.
+type SourceProfileTask struct {
+	BaseTask
+}
+
+func (t *SourceProfileTask) Run() error {
+	_, ctx, logger := t.logger.InitializeRequestID(context.TODO())
+	target, ok := t.taskDef.Target.(scheduling.SourceProfile)
+	if !ok {
+		errMsg := fmt.Sprintf("cannot profile a source from target type: %s", t.taskDef.TargetType)
+		logger.Error(errMsg)
+		return fferr.NewInternalErrorf(errMsg)
+	}
+	nv := metadata.NameVariant{Name: target.Name, Variant: target.Variant}
+	logger = logger.WithResource(logging.SourceVariant, nv.Name, nv.Variant).
+		With("task_id", t.taskDef.TaskId, "task_run_id", t.taskDef.ID, "sample_size", target.SampleSize)
+
+	if err := t.metadata.Tasks.AddRunLog(t.taskDef.TaskId, t.taskDef.ID, "Fetching Metadata..."); err != nil {
+		logger.Warnw("Failed to add run log; continuing.", "error", err)
+	}
+	source, err := t.metadata.GetSourceVariant(ctx, nv)
+	if err != nil {
+		logger.Errorw("Failed to get source variant", "error", err)
+		return err
+	}
+	store, release, err := getOfflineStore(ctx, t.BaseTask, t.metadata, source, logger)
+	if err != nil {
+		logger.Errorw("Failed to get store", "error", err)
+		return err
+	}
+	defer release()
+	profiler, ok := store.(provider.SourceProfiler)
+	if !ok {
+		logger.Errorw("Offline store doesn't support source profiling", "type", store.Type())
+		return fferr.NewUnimplementedErrorf("source profiling is not supported for %s providers", store.Type())
+	}
+	id := provider.ResourceID{Name: nv.Name, Variant: nv.Variant, Type: provider.Transformation}
+	if source.IsPrimaryData() {
+		id.Type = provider.Primary
+	}
+
+	if err := t.metadata.Tasks.AddRunLog(t.taskDef.TaskId, t.taskDef.ID, "Profiling Source..."); err != nil {
+		logger.Warnw("Failed to add run log; continuing.", "error", err)
+	}
+	logger.Info("Profiling source")
+	profile, err := profiler.ProfileSource(id, *source, provider.ProfileOptions{SampleSize: target.SampleSize})
+	if err != nil {
+		logger.Errorw("Failed to profile source", "error", err)
+		return err
+	}
+	if err := t.metadata.SetSourceProfile(ctx, nv, sourceProfileProto(profile, target.SampleSize)); err != nil {
+		logger.Errorw("Failed to save source profile", "error", err)
+		return err
+	}
+	logger.Info("Saved source profile")
+	return nil
+}
+
+func sourceProfileProto(profile provider.SourceProfile, sampleSize int64) *pb.SourceProfile {
+	resp := &pb.SourceProfile{
+		RowCount:   profile.RowCount,
+		SampleSize: sampleSize,
+		Profiled:   timestamppb.Now(),
+		Columns:    make([]*pb.ColumnProfile, len(profile.Columns)),
+	}
+	for i, column := range profile.Columns {
+		resp.Columns[i] = &pb.ColumnProfile{
+			Name:          column.Name,
+			NullCount:     column.NullCount,
+			DistinctCount: column.DistinctCount,
+			Min:           column.Min,
+			Max:           column.Max,
+		}
+	}
+	return resp
+}
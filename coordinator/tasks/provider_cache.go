@@ -0,0 +1,236 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+//
+// Copyright 2024 FeatureForm Inc.
+//
+
+package tasks
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/featureform/logging"
+	"github.com/featureform/provider"
+	pc "github.com/featureform/provider/provider_config"
+	pt "github.com/featureform/provider/provider_type"
+)
+
+// ProviderCache keeps providers open between tasks, so back-to-back jobs on
+// the same provider reuse its connections instead of reopening them.
+// Providers are keyed by their type and a hash of their config, so a task
+// never gets a provider built from an outdated config. When a provider's
+// config is updated, the instance built from the old one is closed as soon
+// as no task is using it. Providers that go unused for the idle timeout are
+// closed too.
+//
+// A nil ProviderCache doesn't cache anything: every Get creates a provider
+// and its release closes it.
+type ProviderCache struct {
+	idleTimeout time.Duration
+	logger      logging.Logger
+	get         func(t pt.Type, config pc.SerializedConfig) (provider.Provider, error)
+	mu          sync.Mutex
+	entries     map[string]*cachedProvider
+	// current is the key of the latest config seen for each provider name.
+	current map[string]string
+	closed  bool
+}
+
+type cachedProvider struct {
+	key      string
+	provider provider.Provider
+	// refs is the number of tasks using the provider. It's only closed once
+	// refs is zero.
+	refs int
+	// idle closes the provider after the idle timeout. It's only set while
+	// refs is zero.
+	idle *time.Timer
+}
+
+func NewProviderCache(idleTimeout time.Duration, logger logging.Logger) *ProviderCache {
+	return &ProviderCache{
+		idleTimeout: idleTimeout,
+		logger:      logger,
+		get:         provider.Get,
+		entries:     make(map[string]*cachedProvider),
+		current:     make(map[string]string),
+	}
+}
+
+func providerCacheKey(t pt.Type, config pc.SerializedConfig) string {
+	sum := sha256.Sum256(config)
+	return fmt.Sprintf("%s:%s", t, hex.EncodeToString(sum[:]))
+}
+
+// Get returns a provider for the config, reusing a cached one if there is
+// one. name is the provider's name in metadata and is used to tell when its
+// config has been updated, it can be empty if it isn't known. The returned
+// release func must be called once the caller is done with the provider.
+func (c *ProviderCache) Get(name string, t pt.Type, config pc.SerializedConfig, logger logging.Logger) (provider.Provider, func(), error) {
+	if c == nil {
+		return getUncachedProvider(provider.Get, t, config, logger)
+	}
+	key := providerCacheKey(t, config)
+	c.mu.Lock()
+	if c.closed {
+		c.mu.Unlock()
+		return getUncachedProvider(c.get, t, config, logger)
+	}
+	stale := c.setCurrent(name, key)
+	entry := c.acquire(key)
+	c.mu.Unlock()
+	c.closeAll(stale)
+	if entry != nil {
+		logger.Debugw("Reusing cached provider", "provider-type", t)
+		return entry.provider, c.releaseFunc(entry), nil
+	}
+
+	// Providers can take a while to connect, so they're created without
+	// holding the lock. If another task cached one in the meantime, it's
+	// used instead.
+	p, err := c.get(t, config)
+	if err != nil {
+		return nil, nil, err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	// Don't cache it if its config was updated while it was being created.
+	if c.closed || (name != "" && c.current[name] != key) {
+		return p, func() { closeProvider(p, logger) }, nil
+	}
+	if existing := c.acquire(key); existing != nil {
+		closeProvider(p, logger)
+		return existing.provider, c.releaseFunc(existing), nil
+	}
+	entry = &cachedProvider{key: key, provider: p, refs: 1}
+	c.entries[key] = entry
+	return p, c.releaseFunc(entry), nil
+}
+
+func getUncachedProvider(
+	get func(pt.Type, pc.SerializedConfig) (provider.Provider, error),
+	t pt.Type,
+	config pc.SerializedConfig,
+	logger logging.Logger,
+) (provider.Provider, func(), error) {
+	p, err := get(t, config)
+	if err != nil {
+		return nil, nil, err
+	}
+	return p, func() { closeProvider(p, logger) }, nil
+}
+
+// setCurrent records key as the latest config for the named provider. If the
+// config changed, the provider built from the old config is removed from the
+// cache and returned if nothing is using it, so it can be closed. The caller
+// must hold c.mu.
+func (c *ProviderCache) setCurrent(name, key string) []*cachedProvider {
+	if name == "" {
+		return nil
+	}
+	previous, has := c.current[name]
+	c.current[name] = key
+	if !has || previous == key {
+		return nil
+	}
+	entry, has := c.entries[previous]
+	if !has {
+		return nil
+	}
+	c.logger.Infow("Provider config changed, invalidating cached provider", "name", name)
+	delete(c.entries, previous)
+	if entry.refs > 0 {
+		// It's closed when its last task releases it.
+		return nil
+	}
+	entry.stopIdleTimer()
+	return []*cachedProvider{entry}
+}
+
+// acquire returns the cached provider for key, marking it as in use, or nil
+// if there isn't one. The caller must hold c.mu.
+func (c *ProviderCache) acquire(key string) *cachedProvider {
+	entry, has := c.entries[key]
+	if !has {
+		return nil
+	}
+	entry.refs++
+	entry.stopIdleTimer()
+	return entry
+}
+
+func (c *ProviderCache) releaseFunc(entry *cachedProvider) func() {
+	var once sync.Once
+	return func() {
+		once.Do(func() { c.release(entry) })
+	}
+}
+
+func (c *ProviderCache) release(entry *cachedProvider) {
+	c.mu.Lock()
+	entry.refs--
+	if entry.refs > 0 {
+		c.mu.Unlock()
+		return
+	}
+	if c.entries[entry.key] != entry {
+		// It was invalidated or the cache was closed while it was in use.
+		c.mu.Unlock()
+		c.closeAll([]*cachedProvider{entry})
+		return
+	}
+	entry.idle = time.AfterFunc(c.idleTimeout, func() { c.evictIdle(entry) })
+	c.mu.Unlock()
+}
+
+func (c *ProviderCache) evictIdle(entry *cachedProvider) {
+	c.mu.Lock()
+	// The provider may have been reused after the timer fired.
+	if entry.refs > 0 || c.entries[entry.key] != entry {
+		c.mu.Unlock()
+		return
+	}
+	delete(c.entries, entry.key)
+	entry.idle = nil
+	c.mu.Unlock()
+	c.logger.Debugw("Closing idle provider", "provider-type", entry.provider.Type())
+	c.closeAll([]*cachedProvider{entry})
+}
+
+func (c *ProviderCache) closeAll(entries []*cachedProvider) {
+	for _, entry := range entries {
+		closeProvider(entry.provider, c.logger)
+	}
+}
+
+// Close closes every cached provider. Providers that are in use are closed
+// when they're released, and providers fetched afterwards aren't cached.
+func (c *ProviderCache) Close() {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	c.closed = true
+	var idle []*cachedProvider
+	for key, entry := range c.entries {
+		delete(c.entries, key)
+		if entry.refs == 0 {
+			entry.stopIdleTimer()
+			idle = append(idle, entry)
+		}
+	}
+	c.mu.Unlock()
+	c.closeAll(idle)
+}
+
+func (entry *cachedProvider) stopIdleTimer() {
+	if entry.idle != nil {
+		entry.idle.Stop()
+		entry.idle = nil
+	}
+}
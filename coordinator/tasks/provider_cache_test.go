@@ -0,0 +1,140 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+//
+// Copyright 2024 FeatureForm Inc.
+//
+
+package tasks
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/featureform/logging"
+	"github.com/featureform/provider"
+	pc "github.com/featureform/provider/provider_config"
+	pt "github.com/featureform/provider/provider_type"
+)
+
+type closeCountingProvider struct {
+	provider.BaseProvider
+	mu     sync.Mutex
+	closes int
+}
+
+func (p *closeCountingProvider) Close() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.closes++
+	return nil
+}
+
+func (p *closeCountingProvider) closeCount() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.closes
+}
+
+func newTestProviderCache(t *testing.T, idleTimeout time.Duration) (*ProviderCache, *[]*closeCountingProvider) {
+	cache := NewProviderCache(idleTimeout, logging.NewTestLogger(t))
+	var created []*closeCountingProvider
+	cache.get = func(typ pt.Type, config pc.SerializedConfig) (provider.Provider, error) {
+		p := &closeCountingProvider{BaseProvider: provider.BaseProvider{ProviderType: typ, ProviderConfig: config}}
+		created = append(created, p)
+		return p, nil
+	}
+	return cache, &created
+}
+
+func TestProviderCacheReusesProviders(t *testing.T) {
+	logger := logging.NewTestLogger(t)
+	cache, created := newTestProviderCache(t, time.Hour)
+	for i := 0; i < 10; i++ {
+		_, release, err := cache.Get("postgres", pt.PostgresOffline, pc.SerializedConfig("config"), logger)
+		if err != nil {
+			t.Fatalf("Failed to get provider: %v", err)
+		}
+		release()
+	}
+	if len(*created) != 1 {
+		t.Fatalf("Expected 1 provider to be created, got %d", len(*created))
+	}
+	if closes := (*created)[0].closeCount(); closes != 0 {
+		t.Fatalf("Expected the cached provider to stay open, closed %d times", closes)
+	}
+	cache.Close()
+	if closes := (*created)[0].closeCount(); closes != 1 {
+		t.Fatalf("Expected the provider to be closed with the cache, closed %d times", closes)
+	}
+}
+
+func TestProviderCacheEvictsIdleProviders(t *testing.T) {
+	logger := logging.NewTestLogger(t)
+	cache, created := newTestProviderCache(t, 10*time.Millisecond)
+	defer cache.Close()
+	_, release, err := cache.Get("redis", pt.RedisOnline, pc.SerializedConfig("config"), logger)
+	if err != nil {
+		t.Fatalf("Failed to get provider: %v", err)
+	}
+	time.Sleep(50 * time.Millisecond)
+	if closes := (*created)[0].closeCount(); closes != 0 {
+		t.Fatalf("Expected a provider in use not to be evicted, closed %d times", closes)
+	}
+	release()
+	time.Sleep(50 * time.Millisecond)
+	if closes := (*created)[0].closeCount(); closes != 1 {
+		t.Fatalf("Expected the idle provider to be closed, closed %d times", closes)
+	}
+	if _, release, err = cache.Get("redis", pt.RedisOnline, pc.SerializedConfig("config"), logger); err != nil {
+		t.Fatalf("Failed to get provider: %v", err)
+	}
+	defer release()
+	if len(*created) != 2 {
+		t.Fatalf("Expected an evicted provider to be recreated, got %d providers", len(*created))
+	}
+}
+
+func TestProviderCacheConfigUpdateInvalidates(t *testing.T) {
+	logger := logging.NewTestLogger(t)
+	cache, created := newTestProviderCache(t, time.Hour)
+	defer cache.Close()
+	old, releaseOld, err := cache.Get("postgres", pt.PostgresOffline, pc.SerializedConfig("password=old"), logger)
+	if err != nil {
+		t.Fatalf("Failed to get provider: %v", err)
+	}
+	updated, releaseUpdated, err := cache.Get("postgres", pt.PostgresOffline, pc.SerializedConfig("password=new"), logger)
+	if err != nil {
+		t.Fatalf("Failed to get provider: %v", err)
+	}
+	defer releaseUpdated()
+	if old == updated {
+		t.Fatalf("Expected a new provider after the config was updated")
+	}
+	if closes := (*created)[0].closeCount(); closes != 0 {
+		t.Fatalf("Expected the old provider to stay open while in use, closed %d times", closes)
+	}
+	releaseOld()
+	if closes := (*created)[0].closeCount(); closes != 1 {
+		t.Fatalf("Expected the old provider to be closed once released, closed %d times", closes)
+	}
+	// Releasing twice doesn't close it again.
+	releaseOld()
+	if closes := (*created)[0].closeCount(); closes != 1 {
+		t.Fatalf("Expected the old provider to be closed once, closed %d times", closes)
+	}
+}
+
+func TestNilProviderCache(t *testing.T) {
+	var cache *ProviderCache
+	p, release, err := cache.Get("local", pt.LocalOnline, pc.SerializedConfig(""), logging.NewTestLogger(t))
+	if err != nil {
+		t.Fatalf("Failed to get provider: %v", err)
+	}
+	if p.Type() != pt.LocalOnline {
+		t.Fatalf("Expected a %s provider, got %s", pt.LocalOnline, p.Type())
+	}
+	release()
+	cache.Close()
+}
@@ -73,17 +73,24 @@ func (t *SourceTask) Run() error {
 		logger.Errorw("Failed to get source variant", "error", err)
 		return err
 	}
-	sourceStore, err := getOfflineStore(ctx, t.BaseTask, t.metadata, source, logger)
+	if source.IsPrimaryData() {
+		location, err := source.GetPrimaryLocation()
+		if err != nil {
+			logger.Errorw("Failed to get primary location", "error", err)
+			return err
+		}
+		// Kafka topics aren't tables in an offline store; they're read when a feature is registered on them.
+		if kafkaLocation, ok := location.(*pl.KafkaLocation); ok {
+			logger.Info("Checking Kafka topic")
+			return t.checkKafkaTopic(ctx, source, kafkaLocation, logger)
+		}
+	}
+	sourceStore, release, err := getOfflineStore(ctx, t.BaseTask, t.metadata, source, logger)
 	if err != nil {
 		logger.Errorw("Failed to get store", "error", err)
 		return err
 	}
-	defer func(sourceStore provider.OfflineStore) {
-		err := sourceStore.Close()
-		if err != nil {
-			logger.Errorf("could not close offline store: %v", err)
-		}
-	}(sourceStore)
+	defer release()
 	logger = logger.With(
 		"resource_id", resID,
 		"is_primary", source.IsPrimaryData(),
@@ -106,6 +113,33 @@ func (t *SourceTask) Run() error {
 	}
 }
 
+func (t *SourceTask) checkKafkaTopic(ctx context.Context, source *metadata.SourceVariant, location *pl.KafkaLocation, logger logging.Logger) error {
+	if err := t.metadata.Tasks.AddRunLog(t.taskDef.TaskId, t.taskDef.ID, "Checking Kafka topic..."); err != nil {
+		logger.Warnw("Failed to add run log; continuing.", "error", err)
+	}
+	providerEntry, err := source.FetchProvider(t.metadata, ctx)
+	if err != nil {
+		logger.Errorw("Failed to fetch provider", "error", err)
+		return err
+	}
+	t.jobObserver().SetProvider(providerEntry.Type())
+	p, release, err := t.config.Providers.Get(providerEntry.Name(), pt.Type(providerEntry.Type()), providerEntry.SerializedConfig(), logger)
+	if err != nil {
+		logger.Errorw("Failed to get provider", "error", err)
+		return err
+	}
+	defer release()
+	stream, ok := p.(*provider.KafkaStream)
+	if !ok {
+		return fferr.NewInvalidArgumentErrorf("Kafka topics can only be registered on a Kafka provider, got %s", providerEntry.Type())
+	}
+	if err := stream.CheckTopic(ctx, location.Topic); err != nil {
+		logger.Errorw("Failed to find Kafka topic", "topic", location.Topic, "error", err)
+		return err
+	}
+	return nil
+}
+
 func (t *SourceTask) handleDeletion(ctx context.Context, resID metadata.ResourceID, logger logging.Logger) error {
 	logger.Infow("Deleting source")
 	sourceToDelete, stagedDeleteErr := t.metadata.GetStagedForDeletionSourceVariant(
@@ -129,11 +163,12 @@ func (t *SourceTask) handleDeletion(ctx context.Context, resID metadata.Resource
 		}
 
 		logger.Debugw("Deleting source at location", "location", tfLocation, "error", tfLocationErr)
-		sourceStore, err := getOfflineStore(ctx, t.BaseTask, t.metadata, sourceToDelete, logger)
+		sourceStore, release, err := getOfflineStore(ctx, t.BaseTask, t.metadata, sourceToDelete, logger)
 		if err != nil {
 			logger.Errorw("Failed to get store", "error", err)
 			return err
 		}
+		defer release()
 
 		deleteErr := sourceStore.Delete(tfLocation)
 		if deleteErr != nil {
@@ -145,6 +180,10 @@ func (t *SourceTask) handleDeletion(ctx context.Context, resID metadata.Resource
 				return deleteErr
 			}
 		}
+		if err := t.metadata.Tasks.DeleteTransformationCacheEntry(resID.Name, resID.Variant); err != nil {
+			logger.Errorw("Failed to delete transformation cache entry", "error", err)
+			return err
+		}
 	}
 
 	logger.Debugw("Deleting source metadata", "resource_id", resID)
@@ -200,6 +239,10 @@ func (t *SourceTask) runSQLTransformationJob(
 		return err
 	}
 
+	if err := t.exportCrossProviderSources(sources, sourceTableMapping, offlineStore, logger); err != nil {
+		return err
+	}
+
 	sourceMapping, err := getSourceMapping(templateString, sourceTableMapping)
 	logger.Debugw("Source Mapping", "mapping", sourceMapping)
 	if err != nil {
@@ -238,10 +281,12 @@ func (t *SourceTask) runSQLTransformationJob(
 		LastRunTimestamp:        t.lastSuccessfulTask.EndTime.UTC(),
 		IsUpdate:                t.isUpdate,
 		SparkFlags:              transformSource.SparkFlags(),
+		SparkSubmitOptions:      transformSource.SparkSubmitOptions(),
+		IncrementalColumn:       transformSource.SQLTransformationIncrementalColumn(),
 		ResourceSnowflakeConfig: resourceSnowflakeConfig,
 	}
 	logger.Debugw("Transformation Config", "config", transformationConfig)
-	if err := t.runTransformationJob(transformationConfig, offlineStore, logger); err != nil {
+	if err := t.runCachedTransformationJob(transformSource, sources, transformationConfig, offlineStore, logger); err != nil {
 		return err
 	}
 
@@ -299,12 +344,13 @@ func (t *SourceTask) runDFTransformationJob(
 		MaxJobDuration: transformSource.MaxJobDuration(),
 		// StartTime begins when its PENDING and waiting for deps. That causes it to re-read data.
 		// EndTime is the lesser evil.
-		LastRunTimestamp: t.lastSuccessfulTask.EndTime.UTC(),
-		IsUpdate:         t.isUpdate,
+		LastRunTimestamp:   t.lastSuccessfulTask.EndTime.UTC(),
+		IsUpdate:           t.isUpdate,
+		SparkSubmitOptions: transformSource.SparkSubmitOptions(),
 	}
 	logger.Debugw("Transformation Config", "config", transformationConfig)
 
-	if err := t.runTransformationJob(transformationConfig, offlineStore, logger); err != nil {
+	if err := t.runCachedTransformationJob(transformSource, sources, transformationConfig, offlineStore, logger); err != nil {
 		return err
 	}
 
@@ -531,6 +577,96 @@ func (t *SourceTask) mapNameVariantsToTables(
 	return sourceMap, nil
 }
 
+// exportCrossProviderSources copies the sources that offlineStore can't read in
+// place into it, and points their table mappings at the copies. It fails if a
+// source is on a provider that offlineStore can't read from at all.
+func (t *SourceTask) exportCrossProviderSources(
+	sources metadata.NameVariants,
+	sourceMap map[string]tableMapping,
+	offlineStore provider.OfflineStore,
+	logger logging.Logger,
+) error {
+	for _, nameVariant := range sources {
+		key := nameVariant.ClientString()
+		mapping := sourceMap[key]
+		access, err := provider.GetSourceAccess(offlineStore.Type(), mapping.providerType)
+		if err != nil {
+			logger.Errorw("Source provider is incompatible", "source", key, "source_provider_type", mapping.providerType, "error", err)
+			return err
+		}
+		if access == provider.NativeSourceAccess {
+			continue
+		}
+		logger.Infow("Exporting source to offline store", "source", key, "source_provider_type", mapping.providerType)
+		if err := t.metadata.Tasks.AddRunLog(t.taskDef.TaskId, t.taskDef.ID, fmt.Sprintf("Exporting %s from %s...", key, mapping.providerType)); err != nil {
+			logger.Errorw("Unable to add run log", "error", err)
+			// We can continue without the run log
+		}
+		source, err := t.metadata.GetSourceVariant(t.ctx, nameVariant)
+		if err != nil {
+			return err
+		}
+		location, err := t.exportSource(key, source, mapping, offlineStore, logger)
+		if err != nil {
+			return err
+		}
+		sqlLocation, ok := location.(*pl.SQLLocation)
+		if !ok {
+			return fferr.NewInternalErrorf("exported source location should be of type SQLLocation: %T", location)
+		}
+		tableName := sqlLocation.TableLocation().String()
+		if offlineStore.Type() == pt.SnowflakeOffline {
+			tableName = provider.SanitizeSnowflakeIdentifier(sqlLocation.TableLocation())
+		}
+		logger.Debugw("Exported source", "source", key, "location", location.Location())
+		sourceMap[key] = tableMapping{
+			name:                tableName,
+			providerType:        offlineStore.Type(),
+			providerConfig:      offlineStore.Config(),
+			timestampColumnName: mapping.timestampColumnName,
+			location:            location,
+		}
+	}
+	return nil
+}
+
+// exportSource copies a single source from its provider into offlineStore.
+// The source's provider is closed once the copy is done.
+func (t *SourceTask) exportSource(
+	key string,
+	source *metadata.SourceVariant,
+	mapping tableMapping,
+	offlineStore provider.OfflineStore,
+	logger logging.Logger,
+) (pl.Location, error) {
+	p, release, err := t.config.Providers.Get("", mapping.providerType, mapping.providerConfig, logger)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+	sourceStore, err := p.AsOfflineStore()
+	if err != nil {
+		return nil, err
+	}
+	var table provider.PrimaryTable
+	if source.IsTransformation() {
+		table, err = sourceStore.GetTransformationTable(provider.ResourceID{Name: source.Name(), Variant: source.Variant(), Type: provider.Transformation})
+	} else {
+		table, err = sourceStore.GetPrimaryTable(provider.ResourceID{Name: source.Name(), Variant: source.Variant(), Type: provider.Primary}, *source)
+	}
+	if err != nil {
+		logger.Errorw("Failed to get source table", "source", key, "error", err)
+		return nil, err
+	}
+	exportID := provider.ResourceID{Name: source.Name(), Variant: source.Variant(), Type: provider.Primary}
+	location, err := provider.ExportSourceTable(offlineStore, exportID, table, t.isUpdate)
+	if err != nil {
+		logger.Errorw("Failed to export source", "source", key, "error", err)
+		return nil, err
+	}
+	return location, nil
+}
+
 func (t *SourceTask) verifyCompletionOfSources(sources []metadata.NameVariant) error {
 	allReady := false
 	for !allReady {
@@ -654,7 +790,7 @@ func getReplacementString(offlineStore provider.OfflineStore, tableMapping table
 			return "", fferr.NewInvalidArgumentError(fmt.Errorf("expected SQLLocation for Snowflake; got: %T", tableMapping.location))
 		}
 		return provider.SanitizeSnowflakeIdentifier(sqlLocation.TableLocation()), nil
-	case pt.PostgresOffline, pt.RedshiftOffline:
+	case pt.PostgresOffline, pt.RedshiftOffline, pt.DatabricksSQLOffline:
 		sqlLocation, isSqlLocation := tableMapping.location.(*pl.SQLLocation)
 		if !isSqlLocation {
 			return "", fferr.NewInvalidArgumentError(fmt.Errorf("expected SQLLocation for Postgres; got: %T", tableMapping.location))
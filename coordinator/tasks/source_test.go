@@ -10,11 +10,16 @@ package tasks
 import (
 	"context"
 	"testing"
+	"time"
 
+	"github.com/featureform/config"
 	"github.com/featureform/coordinator/spawner"
 	"github.com/featureform/logging"
 	"github.com/featureform/metadata"
+	"github.com/featureform/provider"
+	pc "github.com/featureform/provider/provider_config"
 	pt "github.com/featureform/provider/provider_type"
+	"github.com/featureform/scheduling"
 )
 
 func TestSourceTaskRun(t *testing.T) {
@@ -132,3 +137,67 @@ func TestSqlVariableReplace(t *testing.T) {
 		})
 	}
 }
+
+func TestTransformationCacheInputsHash(t *testing.T) {
+	updated := time.Date(2024, time.May, 1, 12, 0, 0, 0, time.UTC)
+	base := transformationCacheInputs{
+		Target:         provider.ResourceID{Name: "transform", Variant: "v1", Type: provider.Transformation},
+		Type:           provider.SQLTransformation,
+		Query:          "SELECT * FROM source",
+		ProviderType:   pt.SnowflakeOffline,
+		ProviderConfig: []byte(`{"Warehouse": "wh"}`),
+		Sources:        []sourceVersion{{Name: "source", Variant: "v1", LastUpdated: updated}},
+	}
+	baseHash, err := base.hash()
+	if err != nil {
+		t.Fatalf("failed to hash inputs: %v", err)
+	}
+	if sameHash, err := base.hash(); err != nil || sameHash != baseHash {
+		t.Fatalf("expected identical inputs to hash to %s, got %s (%v)", baseHash, sameHash, err)
+	}
+	changes := map[string]func(*transformationCacheInputs){
+		"Query": func(inputs *transformationCacheInputs) {
+			inputs.Query = "SELECT id FROM source"
+		},
+		"Target": func(inputs *transformationCacheInputs) {
+			inputs.Target.Variant = "v2"
+		},
+		"Provider Config": func(inputs *transformationCacheInputs) {
+			inputs.ProviderConfig = []byte(`{"Warehouse": "other"}`)
+		},
+		"Source Updated": func(inputs *transformationCacheInputs) {
+			inputs.Sources = []sourceVersion{{Name: "source", Variant: "v1", LastUpdated: updated.Add(time.Second)}}
+		},
+		"Spark Submit Options": func(inputs *transformationCacheInputs) {
+			inputs.SparkSubmitOptions = pc.SparkSubmitOptions{Packages: []string{"org.apache.spark:spark-avro_2.12:3.5.0"}}
+		},
+	}
+	for name, change := range changes {
+		t.Run(name, func(t *testing.T) {
+			changed := base
+			change(&changed)
+			hash, err := changed.hash()
+			if err != nil {
+				t.Fatalf("failed to hash inputs: %v", err)
+			}
+			if hash == baseHash {
+				t.Fatalf("expected a change to %s to change the hash", name)
+			}
+		})
+	}
+}
+
+func TestScheduledRunsSkipTransformationCache(t *testing.T) {
+	t.Setenv(config.EnvTransformationCacheProviders, "snowflake")
+	task := SourceTask{BaseTask: BaseTask{taskDef: scheduling.TaskRunMetadata{TriggerType: scheduling.OnApplyTriggerType}}}
+	if !task.usesTransformationCache("snowflake") {
+		t.Fatalf("expected on apply runs to use the transformation cache")
+	}
+	if task.usesTransformationCache("postgres") {
+		t.Fatalf("expected providers without the cache enabled to skip it")
+	}
+	task.taskDef.TriggerType = scheduling.ScheduleTriggerType
+	if task.usesTransformationCache("snowflake") {
+		t.Fatalf("expected scheduled runs to skip the transformation cache")
+	}
+}
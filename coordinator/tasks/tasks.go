@@ -16,6 +16,7 @@ import (
 	"github.com/featureform/fferr"
 	"github.com/featureform/logging"
 	"github.com/featureform/metadata"
+	"github.com/featureform/metrics"
 	"github.com/featureform/scheduling"
 )
 
@@ -48,7 +49,8 @@ type Task interface {
 
 func init() {
 	unregisteredFactories := map[scheduling.TargetType]Factory{
-		scheduling.NameVariantTarget: NewResourceCreationFactory,
+		scheduling.NameVariantTarget:   NewResourceCreationFactory,
+		scheduling.SourceProfileTarget: NewSourceProfileFactory,
 	}
 	for name, factory := range unregisteredFactories {
 		if err := RegisterFactory(name, factory); err != nil {
@@ -79,6 +81,9 @@ func Get(t scheduling.TargetType, base BaseTask) (Task, error) {
 
 type TaskConfig struct {
 	DependencyPollInterval time.Duration
+	// Providers is shared by every task so they can reuse provider
+	// connections. Providers aren't cached if it's nil.
+	Providers *ProviderCache
 }
 
 type BaseTask struct {
@@ -88,6 +93,7 @@ type BaseTask struct {
 	isUpdate           bool
 	isDelete           bool
 	spawner            spawner.JobSpawner
+	observer           metrics.JobObserver
 	logger             logging.Logger
 	config             TaskConfig
 }
@@ -99,6 +105,7 @@ func NewBaseTask(
 	isUpdate bool,
 	isDelete bool,
 	spawner spawner.JobSpawner,
+	observer metrics.JobObserver,
 	logger logging.Logger,
 	config TaskConfig,
 ) BaseTask {
@@ -109,6 +116,7 @@ func NewBaseTask(
 		isUpdate:           isUpdate,
 		isDelete:           isDelete,
 		spawner:            spawner,
+		observer:           observer,
 		logger:             logger,
 		config:             config,
 	}
@@ -125,6 +133,39 @@ func (bt *BaseTask) Redacted() map[string]any {
 
 }
 
+// jobObserver returns the observer that the task reports its provider and
+// processed rows to.
+func (bt *BaseTask) jobObserver() metrics.JobObserver {
+	if bt.observer == nil {
+		return &metrics.NoOpJobObserver{}
+	}
+	return bt.observer
+}
+
+// runProgressReporter saves a job's progress on the task run so clients can
+// poll it.
+type runProgressReporter struct {
+	tasks  metadata.TaskService
+	taskID scheduling.TaskID
+	runID  scheduling.TaskRunID
+	logger logging.Logger
+}
+
+func (r runProgressReporter) ReportProgress(progress scheduling.RunProgress) {
+	if err := r.tasks.SetRunProgress(r.taskID, r.runID, progress); err != nil {
+		r.logger.Warnw("Failed to set run progress", "phase", progress.Phase, "error", err)
+	}
+}
+
+func (bt *BaseTask) progressReporter() runProgressReporter {
+	return runProgressReporter{
+		tasks:  bt.metadata.Tasks,
+		taskID: bt.taskDef.TaskId,
+		runID:  bt.taskDef.ID,
+		logger: bt.logger,
+	}
+}
+
 func (bt *BaseTask) waitForRunCompletion(id []scheduling.TaskRunID) error {
 	return nil
 }
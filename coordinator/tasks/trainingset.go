@@ -57,17 +57,12 @@ func (t *TrainingSetTask) Run() error {
 		return err
 	}
 
-	store, getStoreErr := getOfflineStore(ctx, t.BaseTask, t.metadata, ts, logger)
+	store, release, getStoreErr := getOfflineStore(ctx, t.BaseTask, t.metadata, ts, logger)
 	if getStoreErr != nil {
 		return getStoreErr
 	}
+	defer release()
 	logger.Debugw("Training set offline store", "type", fmt.Sprintf("%T", store))
-	defer func(store provider.OfflineStore) {
-		err := store.Close()
-		if err != nil {
-			logger.Errorf("could not close offline store: %v", err)
-		}
-	}(store)
 
 	providerResID := provider.ResourceID{Name: nv.Name, Variant: nv.Variant, Type: provider.TrainingSet}
 
@@ -127,26 +122,31 @@ func (t *TrainingSetTask) Run() error {
 		}
 	}
 
-	label, err := ts.FetchLabel(t.metadata, ctx)
-	if err != nil {
-		logger.Errorw("Failed to fetch label", "error", err)
-		return err
-	}
-	labelSourceNameVariant := label.Source()
-	_, err = t.awaitPendingSource(labelSourceNameVariant)
-	if err != nil {
-		logger.Errorw("Failed to wait on pending label source", "error", err)
-		return err
-	}
-	label, err = t.AwaitPendingLabel(ctx, metadata.NameVariant{Name: label.Name(), Variant: label.Variant()})
-	if err != nil {
-		logger.Errorw("Failed to wait on pending label variant", "error", err)
-		return err
-	}
-	labelSourceMapping, err := t.getLabelSourceMapping(ctx, label)
-	if err != nil {
-		logger.Errorw("Failed to get label source mapping", "error", err)
-		return err
+	labelNameVariants := ts.Labels()
+	labelList := make([]provider.ResourceID, len(labelNameVariants))
+	labelSourceMappings := make([]provider.SourceMapping, len(labelNameVariants))
+	for i, labelNameVariant := range labelNameVariants {
+		label, err := t.metadata.GetLabelVariant(ctx, labelNameVariant)
+		if err != nil {
+			logger.Errorw("Failed to fetch label", "label", labelNameVariant, "error", err)
+			return err
+		}
+		_, err = t.awaitPendingSource(label.Source())
+		if err != nil {
+			logger.Errorw("Failed to wait on pending label source", "label", labelNameVariant, "error", err)
+			return err
+		}
+		label, err = t.AwaitPendingLabel(ctx, labelNameVariant)
+		if err != nil {
+			logger.Errorw("Failed to wait on pending label variant", "label", labelNameVariant, "error", err)
+			return err
+		}
+		labelSourceMappings[i], err = t.getLabelSourceMapping(ctx, label)
+		if err != nil {
+			logger.Errorw("Failed to get label source mapping", "label", labelNameVariant, "error", err)
+			return err
+		}
+		labelList[i] = provider.ResourceID{Name: label.Name(), Variant: label.Variant(), Type: provider.Label}
 	}
 	resourceSnowflakeConfig := &metadata.ResourceSnowflakeConfig{}
 	if store.Type() == pt.SnowflakeOffline {
@@ -160,13 +160,32 @@ func (t *TrainingSetTask) Run() error {
 
 	trainingSetDef := provider.TrainingSetDef{
 		ID:                      providerResID,
-		Label:                   provider.ResourceID{Name: label.Name(), Variant: label.Variant(), Type: provider.Label},
-		LabelSourceMapping:      labelSourceMapping,
+		Label:                   labelList[0],
+		LabelSourceMapping:      labelSourceMappings[0],
 		Features:                featureList,
 		FeatureSourceMappings:   featureSourceMappings,
 		LagFeatures:             lagFeaturesList,
 		ResourceSnowflakeConfig: resourceSnowflakeConfig,
 		Type:                    ts.TrainingSetType(),
+		MaxFeatureAge:           ts.MaxFeatureAge(),
+	}
+	// Stores that only join a single label fail on training sets that set Labels.
+	if len(labelList) > 1 {
+		trainingSetDef.Labels = labelList
+		trainingSetDef.LabelSourceMappings = labelSourceMappings
+	}
+	if persist := ts.PersistAs(); persist != nil {
+		trainingSetDef.PersistAs = &provider.TrainingSetPersistAs{
+			Table:     persist.Table,
+			Overwrite: persist.Overwrite,
+			Dedup:     persist.Dedup,
+		}
+	}
+	if split := ts.Split(); split != nil {
+		trainingSetDef.Split = &provider.TrainingSetSplit{
+			TrainFraction: split.TrainFraction,
+			Seed:          split.Seed,
+		}
 	}
 	logger.Debugw("Successfully created training set def", "def", trainingSetDef)
 	return t.runTrainingSetJob(trainingSetDef, store)
@@ -190,11 +209,12 @@ func (t *TrainingSetTask) handleDeletion(ctx context.Context, tsId metadata.Reso
 		return err
 	}
 	logger.Debugw("Deleting training set at location", "location", trainingSetTable)
-	store, getStoreErr := getOfflineStore(ctx, t.BaseTask, t.metadata, tsToDelete, logger)
+	store, release, getStoreErr := getOfflineStore(ctx, t.BaseTask, t.metadata, tsToDelete, logger)
 	if getStoreErr != nil {
 		logger.Errorw("Failed to get store", "error", getStoreErr)
 		return getStoreErr
 	}
+	defer release()
 
 	trainingSetLocation := pl.NewSQLLocation(trainingSetTable)
 
@@ -443,7 +463,7 @@ func (t *TrainingSetTask) getFeatureSourceTableName(p *metadata.Provider, featur
 	switch pt.Type(p.Type()) {
 	case pt.SnowflakeOffline:
 		return t.getSourceTableNameForSnowflake(feature)
-	case pt.MemoryOffline, pt.MySqlOffline, pt.PostgresOffline, pt.ClickHouseOffline, pt.RedshiftOffline, pt.SparkOffline, pt.BigQueryOffline, pt.K8sOffline:
+	case pt.MemoryOffline, pt.MySqlOffline, pt.PostgresOffline, pt.ClickHouseOffline, pt.RedshiftOffline, pt.SparkOffline, pt.BigQueryOffline, pt.K8sOffline, pt.DatabricksSQLOffline:
 		resourceType = provider.Feature
 	default:
 		t.logger.Errorw("unsupported provider type", "type", p.Type())
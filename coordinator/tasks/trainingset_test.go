@@ -10,6 +10,7 @@ package tasks
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/featureform/coordinator/spawner"
 	"github.com/featureform/logging"
@@ -88,6 +89,114 @@ func TestTrainingSetTaskRun(t *testing.T) {
 	}
 }
 
+func TestTrainingSetTaskRunMaxFeatureAge(t *testing.T) {
+	ctx, logger := logging.NewTestContextAndLogger(t)
+
+	serv, addr := startServ(t, ctx, logger)
+	defer serv.Stop()
+	client, err := metadata.NewClient(addr, logger)
+	if err != nil {
+		panic(err)
+	}
+
+	preReqTaskRuns := createPreqTrainingSetResources(t, ctx, client)
+	for _, run := range preReqTaskRuns {
+		if err := client.Tasks.SetRunStatus(run.TaskId, run.ID, scheduling.RUNNING, nil); err != nil {
+			t.Fatalf(err.Error())
+		}
+		if err := client.Tasks.SetRunStatus(run.TaskId, run.ID, scheduling.READY, nil); err != nil {
+			t.Fatalf(err.Error())
+		}
+	}
+
+	p, err := provider.Get(pt.MemoryOffline, provider_config.SerializedConfig{})
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	store, err := p.AsOfflineStore()
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	labelTS := time.UnixMilli(0).UTC().Add(24 * time.Hour)
+	features, err := store.GetResourceTable(provider.ResourceID{Name: "featureName", Variant: "featureVariant", Type: provider.Feature})
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	featureRecs := []provider.ResourceRecord{
+		{Entity: "fresh", Value: 1, TS: labelTS.Add(-30 * time.Minute)},
+		{Entity: "stale", Value: 2, TS: labelTS.Add(-2 * time.Hour)},
+	}
+	if err := features.WriteBatch(featureRecs); err != nil {
+		t.Fatalf(err.Error())
+	}
+	labels, err := store.GetResourceTable(provider.ResourceID{Name: "labelName", Variant: "labelVariant", Type: provider.Label})
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	labelRecs := []provider.ResourceRecord{
+		{Entity: "fresh", Value: "fresh", TS: labelTS},
+		{Entity: "stale", Value: "stale", TS: labelTS},
+	}
+	if err := labels.WriteBatch(labelRecs); err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	err = client.CreateTrainingSetVariant(ctx, metadata.TrainingSetDef{
+		Name:     "maxAgeTrainingSet",
+		Variant:  "trainingSetVariant",
+		Owner:    "mockOwner",
+		Provider: "mockProvider",
+		Label:    metadata.NameVariant{Name: "labelName", Variant: "labelVariant"},
+		Features: metadata.NameVariants{
+			{Name: "featureName", Variant: "featureVariant"},
+		},
+		MaxFeatureAge: time.Hour,
+	})
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	runs, err := client.Tasks.GetAllRuns()
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	runDiff := difference(runs, preReqTaskRuns)
+	if len(runDiff) != 1 {
+		t.Fatalf("Expected 1 run to be different, got: %d", len(runDiff))
+	}
+	task := TrainingSetTask{
+		BaseTask{
+			metadata: client,
+			taskDef:  runDiff[0],
+			spawner:  &spawner.MemoryJobSpawner{},
+			logger:   logging.NewTestLogger(t),
+		},
+	}
+	if err := task.Run(); err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	iter, err := store.GetTrainingSet(provider.ResourceID{Name: "maxAgeTrainingSet", Variant: "trainingSetVariant", Type: provider.TrainingSet})
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	// The stale feature is older than the max feature age, so it's joined as null.
+	expected := map[string]interface{}{"fresh": 1, "stale": nil}
+	rows := 0
+	for iter.Next() {
+		rows++
+		label := iter.Label().(string)
+		if got := iter.Features()[0]; got != expected[label] {
+			t.Fatalf("%s: expected feature %v, got %v", label, expected[label], got)
+		}
+	}
+	if err := iter.Err(); err != nil {
+		t.Fatalf(err.Error())
+	}
+	if rows != len(expected) {
+		t.Fatalf("Expected %d rows, got %d", len(expected), rows)
+	}
+}
+
 func createPreqTrainingSetResources(t *testing.T, ctx context.Context, client *metadata.Client) []scheduling.TaskRunMetadata {
 	err := client.CreateUser(ctx, metadata.UserDef{
 		Name: "mockOwner",
@@ -0,0 +1,183 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+//
+// Copyright 2024 FeatureForm Inc.
+//
+
+package tasks
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"sort"
+	"time"
+
+	"github.com/featureform/config"
+	"github.com/featureform/fferr"
+	"github.com/featureform/logging"
+	"github.com/featureform/metadata"
+	"github.com/featureform/provider"
+	pc "github.com/featureform/provider/provider_config"
+	pt "github.com/featureform/provider/provider_type"
+	"github.com/featureform/scheduling"
+)
+
+// transformationCacheInputs is everything the result of a transformation
+// depends on. A change to any of them gives a different hash, which reruns
+// the transformation.
+type transformationCacheInputs struct {
+	Target                  provider.ResourceID
+	Type                    provider.TransformationType
+	Query                   string
+	Code                    []byte
+	Args                    metadata.TransformationArgs
+	SparkFlags              pc.SparkFlags
+	SparkSubmitOptions      pc.SparkSubmitOptions
+	IncrementalColumn       string
+	ResourceSnowflakeConfig *metadata.ResourceSnowflakeConfig
+	LastRunTimestamp        time.Time
+	ProviderType            pt.Type
+	ProviderConfig          pc.SerializedConfig
+	Sources                 []sourceVersion
+}
+
+// sourceVersion identifies the state of an upstream source. Its last update
+// changes each time the source is rerun.
+type sourceVersion struct {
+	Name        string
+	Variant     string
+	LastUpdated time.Time
+}
+
+func (inputs transformationCacheInputs) hash() (string, error) {
+	serialized, err := json.Marshal(inputs)
+	if err != nil {
+		return "", fferr.NewInternalError(err)
+	}
+	sum := sha256.Sum256(serialized)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// runCachedTransformationJob skips the transformation if its provider has the
+// transformation cache enabled and the existing result was built from the same
+// query, config and source versions. Otherwise, it runs the transformation and
+// records the hash of its inputs. Scheduled runs are never skipped.
+func (t *SourceTask) runCachedTransformationJob(
+	transformSource *metadata.SourceVariant,
+	sources metadata.NameVariants,
+	transformationConfig provider.TransformationConfig,
+	offlineStore provider.OfflineStore,
+	logger logging.Logger,
+) error {
+	if !t.usesTransformationCache(transformSource.Provider()) {
+		return t.runTransformationJob(transformationConfig, offlineStore, logger)
+	}
+	id := transformationConfig.TargetTableID
+	inputs, err := t.transformationCacheInputs(transformationConfig, sources, offlineStore)
+	if err != nil {
+		return err
+	}
+	hash, err := inputs.hash()
+	if err != nil {
+		return err
+	}
+	logger = logger.With("cache_hash", hash)
+	if t.hasCachedTransformation(id, hash, offlineStore, logger) {
+		logger.Infow("Transformation inputs are unchanged, skipping transformation")
+		if err := t.metadata.Tasks.AddRunLog(t.taskDef.TaskId, t.taskDef.ID, "Inputs unchanged, reusing previous result."); err != nil {
+			logger.Errorw("Unable to add run log", "error", err)
+			// We can continue without the run log
+		}
+		return nil
+	}
+	// The existing result is about to be replaced, so it can't be reused if
+	// this run fails part of the way through.
+	if err := t.metadata.Tasks.DeleteTransformationCacheEntry(id.Name, id.Variant); err != nil {
+		logger.Errorw("Failed to delete transformation cache entry", "error", err)
+		return err
+	}
+	if err := t.runTransformationJob(transformationConfig, offlineStore, logger); err != nil {
+		return err
+	}
+	if err := t.metadata.Tasks.SetTransformationCacheEntry(id.Name, id.Variant, hash); err != nil {
+		logger.Warnw("Failed to set transformation cache entry; continuing", "error", err)
+	}
+	return nil
+}
+
+// usesTransformationCache returns false for scheduled runs. They exist to pick
+// up new data in the primary tables, which doesn't change the last update of
+// their sources, so their inputs can look unchanged when the result isn't.
+func (t *SourceTask) usesTransformationCache(providerName string) bool {
+	if t.taskDef.TriggerType == scheduling.ScheduleTriggerType {
+		return false
+	}
+	return config.IsTransformationCacheEnabled(providerName)
+}
+
+func (t *SourceTask) transformationCacheInputs(
+	transformationConfig provider.TransformationConfig,
+	sources metadata.NameVariants,
+	offlineStore provider.OfflineStore,
+) (transformationCacheInputs, error) {
+	sourceVariants, err := t.metadata.GetSourceVariants(t.ctx, sources)
+	if err != nil {
+		return transformationCacheInputs{}, err
+	}
+	versions := make([]sourceVersion, len(sourceVariants))
+	for i, source := range sourceVariants {
+		versions[i] = sourceVersion{
+			Name:        source.Name(),
+			Variant:     source.Variant(),
+			LastUpdated: source.LastUpdated().UTC(),
+		}
+	}
+	sort.Slice(versions, func(i, j int) bool {
+		if versions[i].Name != versions[j].Name {
+			return versions[i].Name < versions[j].Name
+		}
+		return versions[i].Variant < versions[j].Variant
+	})
+	inputs := transformationCacheInputs{
+		Target:                  transformationConfig.TargetTableID,
+		Type:                    transformationConfig.Type,
+		Query:                   transformationConfig.Query,
+		Code:                    transformationConfig.Code,
+		Args:                    transformationConfig.Args,
+		SparkFlags:              transformationConfig.SparkFlags,
+		SparkSubmitOptions:      transformationConfig.SparkSubmitOptions,
+		IncrementalColumn:       transformationConfig.IncrementalColumn,
+		ResourceSnowflakeConfig: transformationConfig.ResourceSnowflakeConfig,
+		ProviderType:            offlineStore.Type(),
+		ProviderConfig:          offlineStore.Config(),
+		Sources:                 versions,
+	}
+	// SQL queries already have the last run's timestamp substituted in, but
+	// DF transformations are given it separately when updating.
+	if transformationConfig.Type == provider.DFTransformation && transformationConfig.IsUpdate {
+		inputs.LastRunTimestamp = transformationConfig.LastRunTimestamp
+	}
+	return inputs, nil
+}
+
+// hasCachedTransformation returns true if the transformation's result was
+// built from inputs with the given hash and still exists. Failures to check
+// are treated as a miss.
+func (t *SourceTask) hasCachedTransformation(id provider.ResourceID, hash string, offlineStore provider.OfflineStore, logger logging.Logger) bool {
+	entry, err := t.metadata.Tasks.GetTransformationCacheEntry(id.Name, id.Variant)
+	if err != nil {
+		logger.Warnw("Failed to get transformation cache entry; rerunning transformation", "error", err)
+		return false
+	}
+	if entry.Hash != hash {
+		logger.Debugw("Transformation inputs have changed", "previous_hash", entry.Hash)
+		return false
+	}
+	if _, err := offlineStore.GetTransformationTable(id); err != nil {
+		logger.Infow("Cached transformation result is missing; rerunning transformation", "error", err)
+		return false
+	}
+	return true
+}
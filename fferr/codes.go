@@ -0,0 +1,144 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+//
+// Copyright 2024 FeatureForm Inc.
+//
+
+package fferr
+
+import (
+	"errors"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/status"
+)
+
+// ErrorCode is a stable, machine-readable code for an error that clients can
+// branch on. Unlike the error's type and message, codes never change once added.
+type ErrorCode string
+
+func (c ErrorCode) String() string {
+	return string(c)
+}
+
+// ErrorCodeDomain is the domain of the ErrorInfo detail that carries an error's
+// code. It separates it from the ErrorInfo that carries the error's type and details.
+const ErrorCodeDomain = "errors.featureform.com"
+
+const (
+	CodeUnknown                 ErrorCode = "UNKNOWN"
+	CodeInternal                ErrorCode = "INTERNAL"
+	CodeInvalidArgument         ErrorCode = "INVALID_ARGUMENT"
+	CodeUnimplemented           ErrorCode = "UNIMPLEMENTED"
+	CodeUnauthenticated         ErrorCode = "UNAUTHENTICATED"
+	CodePermissionDenied        ErrorCode = "PERMISSION_DENIED"
+	CodeProviderConnection      ErrorCode = "PROVIDER_CONNECTION"
+	CodeProviderExecution       ErrorCode = "PROVIDER_EXECUTION"
+	CodeResourceNotFound        ErrorCode = "RESOURCE_NOT_FOUND"
+	CodeResourceAlreadyExists   ErrorCode = "RESOURCE_ALREADY_EXISTS"
+	CodeResourceChanged         ErrorCode = "RESOURCE_CHANGED"
+	CodeResourceHasDependents   ErrorCode = "RESOURCE_HAS_DEPENDENTS"
+	CodeInvalidResourceType     ErrorCode = "INVALID_RESOURCE_TYPE"
+	CodeResourceNotReady        ErrorCode = "RESOURCE_NOT_READY"
+	CodeResourceFailed          ErrorCode = "RESOURCE_FAILED"
+	CodeResourceAlreadyComplete ErrorCode = "RESOURCE_ALREADY_COMPLETE"
+	CodeResourceAlreadyFailed   ErrorCode = "RESOURCE_ALREADY_FAILED"
+	CodeDatasetNotFound         ErrorCode = "DATASET_NOT_FOUND"
+	CodeEntityNotFound          ErrorCode = "ENTITY_NOT_FOUND"
+	CodeDataTypeNotFound        ErrorCode = "DATATYPE_NOT_FOUND"
+	CodeTypeMismatch            ErrorCode = "TYPE_MISMATCH"
+	CodeInvalidFileType         ErrorCode = "INVALID_FILE_TYPE"
+	CodeJobNotFound             ErrorCode = "JOB_NOT_FOUND"
+	CodeJobAlreadyExists        ErrorCode = "JOB_ALREADY_EXISTS"
+	CodeLockConflict            ErrorCode = "LOCK_CONFLICT"
+)
+
+var errorCodes = map[string]ErrorCode{
+	EXECUTION_ERROR:               CodeProviderExecution,
+	CONNECTION_ERROR:              CodeProviderConnection,
+	DATASET_NOT_FOUND:             CodeDatasetNotFound,
+	DATASET_ALREADY_EXISTS:        CodeResourceAlreadyExists,
+	DATATYPE_NOT_FOUND:            CodeDataTypeNotFound,
+	TRANSFORMATION_NOT_FOUND:      CodeResourceNotFound,
+	ENTITY_NOT_FOUND:              CodeEntityNotFound,
+	FEATURE_NOT_FOUND:             CodeResourceNotFound,
+	TRAINING_SET_NOT_FOUND:        CodeResourceNotFound,
+	INVALID_RESOURCE_TYPE:         CodeInvalidResourceType,
+	INVALID_RESOURCE_NAME_VARIANT: CodeInvalidArgument,
+	INVALID_FILE_TYPE:             CodeInvalidFileType,
+	RESOURCE_CHANGED:              CodeResourceChanged,
+	TYPE_ERROR:                    CodeTypeMismatch,
+	COLUMN_TYPE_MISMATCH:          CodeTypeMismatch,
+	RESOURCE_HAS_DEPENDENTS:       CodeResourceHasDependents,
+	INTERNAL_ERROR:                CodeInternal,
+	INVALID_ARGUMENT:              CodeInvalidArgument,
+	PARSING_ERROR:                 CodeInvalidArgument,
+	UNIMPLEMENTED_ERROR:           CodeUnimplemented,
+	JOB_DOES_NOT_EXIST:            CodeJobNotFound,
+	JOB_ALREADY_EXISTS:            CodeJobAlreadyExists,
+	RESOURCE_ALREADY_COMPLETE:     CodeResourceAlreadyComplete,
+	RESOURCE_ALREADY_FAILED:       CodeResourceAlreadyFailed,
+	RESOURCE_NOT_READY:            CodeResourceNotReady,
+	RESOURCE_FAILED:               CodeResourceFailed,
+	INVALID_JOB_TARGET:            CodeInternal,
+	DEPENDENCY_FAILED:             CodeResourceFailed,
+	TASK_RUN_FAILED:               CodeResourceFailed,
+	RESOURCE_TASK_FAILED:          CodeResourceFailed,
+	NO_RUNS_FOR_TASK:              CodeResourceNotFound,
+	KEY_NOT_FOUND:                 CodeResourceNotFound,
+	KEY_ALREADY_LOCKED:            CodeLockConflict,
+	KEY_NOT_LOCKED:                CodeLockConflict,
+	LOCK_EMPTY_KEY:                CodeInvalidArgument,
+	UNLOCK_EMPTY_KEY:              CodeInvalidArgument,
+	EXCEEDED_WAIT_TIME:            CodeLockConflict,
+	UNAUTHENTICATED:               CodeUnauthenticated,
+	PERMISSION_DENIED:             CodePermissionDenied,
+}
+
+// GetErrorCode returns the code of an error. It works on both fferr errors and the
+// gRPC status errors that clients receive, and returns CodeUnknown for anything else.
+func GetErrorCode(err error) ErrorCode {
+	if err == nil {
+		return CodeUnknown
+	}
+	var ffErr Error
+	if errors.As(err, &ffErr) {
+		return errorCodeOfType(ffErr.GetType())
+	}
+	st, ok := status.FromError(err)
+	if !ok {
+		return CodeUnknown
+	}
+	for _, detail := range st.Details() {
+		if info, ok := detail.(*errdetails.ErrorInfo); ok && isErrorCodeDetail(info) {
+			return ErrorCode(info.Reason)
+		}
+	}
+	return CodeUnknown
+}
+
+func errorCodeOfType(errorType string) ErrorCode {
+	if code, has := errorCodes[errorType]; has {
+		return code
+	}
+	return CodeUnknown
+}
+
+// ToErrWithCode converts the error to a gRPC status error, like ToErr, with its
+// code attached as an extra ErrorInfo detail in ErrorCodeDomain.
+func ToErrWithCode(err Error) error {
+	st := status.Convert(err.ToErr())
+	withCode, detailErr := st.WithDetails(&errdetails.ErrorInfo{
+		Reason: errorCodeOfType(err.GetType()).String(),
+		Domain: ErrorCodeDomain,
+	})
+	if detailErr != nil {
+		return st.Err()
+	}
+	return withCode.Err()
+}
+
+func isErrorCodeDetail(info *errdetails.ErrorInfo) bool {
+	return info.Domain == ErrorCodeDomain
+}
@@ -0,0 +1,78 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+//
+// Copyright 2024 FeatureForm Inc.
+//
+
+package fferr
+
+import (
+	"fmt"
+	"testing"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestGetErrorCode(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		expected ErrorCode
+	}{
+		{"Resource Changed", NewResourceChangedError("name", "variant", FEATURE_VARIANT, nil), CodeResourceChanged},
+		{"Dataset Already Exists", NewDatasetAlreadyExistsError("name", "variant", nil), CodeResourceAlreadyExists},
+		{"Connection", NewConnectionError("provider", fmt.Errorf("refused")), CodeProviderConnection},
+		{"Execution", NewExecutionError("provider", fmt.Errorf("failed")), CodeProviderExecution},
+		{"Feature Not Found", NewFeatureNotFoundError("name", "variant", nil), CodeResourceNotFound},
+		{"Wrapped", fmt.Errorf("wrapped: %w", NewInvalidArgumentErrorf("bad")), CodeInvalidArgument},
+		{"Plain", fmt.Errorf("plain"), CodeUnknown},
+		{"Status Without Code", status.Error(codes.Internal, "internal"), CodeUnknown},
+		{"Nil", nil, CodeUnknown},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if code := GetErrorCode(tt.err); code != tt.expected {
+				t.Fatalf("Expected code %s, got %s", tt.expected, code)
+			}
+		})
+	}
+}
+
+func TestErrorCodesCoverErrorTypes(t *testing.T) {
+	types := []string{
+		EXECUTION_ERROR, CONNECTION_ERROR, DATASET_NOT_FOUND, DATASET_ALREADY_EXISTS, DATATYPE_NOT_FOUND,
+		TRANSFORMATION_NOT_FOUND, ENTITY_NOT_FOUND, FEATURE_NOT_FOUND, TRAINING_SET_NOT_FOUND,
+		INVALID_RESOURCE_TYPE, INVALID_RESOURCE_NAME_VARIANT, INVALID_FILE_TYPE, RESOURCE_CHANGED, TYPE_ERROR,
+		COLUMN_TYPE_MISMATCH, RESOURCE_HAS_DEPENDENTS, INTERNAL_ERROR, INVALID_ARGUMENT, PARSING_ERROR, UNIMPLEMENTED_ERROR,
+		JOB_DOES_NOT_EXIST, JOB_ALREADY_EXISTS, RESOURCE_ALREADY_COMPLETE, RESOURCE_ALREADY_FAILED,
+		RESOURCE_NOT_READY, RESOURCE_FAILED, INVALID_JOB_TARGET, DEPENDENCY_FAILED, TASK_RUN_FAILED,
+		KEY_NOT_FOUND, KEY_ALREADY_LOCKED, KEY_NOT_LOCKED, LOCK_EMPTY_KEY, UNLOCK_EMPTY_KEY, EXCEEDED_WAIT_TIME,
+		UNAUTHENTICATED, PERMISSION_DENIED, RESOURCE_TASK_FAILED, NO_RUNS_FOR_TASK,
+	}
+	for _, errorType := range types {
+		if code := errorCodeOfType(errorType); code == CodeUnknown {
+			t.Errorf("Error type %q has no error code", errorType)
+		}
+	}
+}
+
+func TestToErrWithCode(t *testing.T) {
+	original := NewResourceChangedError("name", "variant", FEATURE_VARIANT, nil)
+	err := ToErrWithCode(original)
+	if code := GetErrorCode(err); code != CodeResourceChanged {
+		t.Fatalf("Expected code %s from status, got %s", CodeResourceChanged, code)
+	}
+	if st := status.Convert(err); st.Code() != original.GetCode() {
+		t.Fatalf("Expected gRPC code %s, got %s", original.GetCode(), st.Code())
+	}
+	// The code detail doesn't replace the error's type and details
+	converted := FromErr(err)
+	if converted.GetType() != RESOURCE_CHANGED {
+		t.Fatalf("Expected type %s, got %s", RESOURCE_CHANGED, converted.GetType())
+	}
+	if GetErrorCode(converted) != CodeResourceChanged {
+		t.Fatalf("Expected converted error to keep code %s", CodeResourceChanged)
+	}
+}
@@ -9,6 +9,7 @@ package fferr
 
 import (
 	"fmt"
+	"strings"
 
 	"google.golang.org/grpc/codes"
 )
@@ -177,6 +178,25 @@ func NewTypeErrorf(valueType string, value any, tmp string, args ...any) *TypeEr
 	return NewTypeError(valueType, value, err)
 }
 
+// NewColumnTypeMismatchError is returned when a source column can't back a
+// resource's declared value type.
+func NewColumnTypeMismatchError(column, expectedType, foundType string, err error) *ColumnTypeMismatchError {
+	if err == nil {
+		err = fmt.Errorf("column %s has type %s, which can't be used as %s", column, foundType, expectedType)
+	}
+	baseError := newBaseError(err, COLUMN_TYPE_MISMATCH, codes.InvalidArgument)
+	baseError.AddDetail("column", column)
+	baseError.AddDetail("expected_type", expectedType)
+	baseError.AddDetail("found_type", foundType)
+	return &ColumnTypeMismatchError{
+		baseError,
+	}
+}
+
+type ColumnTypeMismatchError struct {
+	baseError
+}
+
 type TrainingSetNotFoundError struct {
 	baseError
 }
@@ -255,3 +275,23 @@ func NewResourceChangedError(resourceName, resourceVariant string, resourceType
 type ResourceChangedError struct {
 	baseError
 }
+
+// NewResourceHasDependentsError is returned when a resource can't be deleted
+// because other resources still depend on it.
+func NewResourceHasDependentsError(resourceName, resourceVariant string, resourceType ResourceType, dependents []string) *ResourceHasDependentsError {
+	err := fmt.Errorf("resource is used by %s", strings.Join(dependents, ", "))
+	baseError := newBaseError(err, RESOURCE_HAS_DEPENDENTS, codes.FailedPrecondition)
+	baseError.AddDetail("resource_name", resourceName)
+	baseError.AddDetail("resource_variant", resourceVariant)
+	baseError.AddDetail("resource_type", string(resourceType))
+	baseError.AddDetail("dependents", strings.Join(dependents, ", "))
+	return &ResourceHasDependentsError{
+		baseError:  baseError,
+		Dependents: dependents,
+	}
+}
+
+type ResourceHasDependentsError struct {
+	baseError
+	Dependents []string
+}
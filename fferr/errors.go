@@ -46,6 +46,8 @@ const (
 	INVALID_FILE_TYPE             = "Invalid File Type"
 	RESOURCE_CHANGED              = "Resource Changed"
 	TYPE_ERROR                    = "Type Error"
+	COLUMN_TYPE_MISMATCH          = "Column Type Mismatch"
+	RESOURCE_HAS_DEPENDENTS       = "Resource Has Dependents"
 
 	// MISCELLANEOUS:
 	INTERNAL_ERROR      = "Internal Error"
@@ -74,6 +76,10 @@ const (
 	UNLOCK_EMPTY_KEY   = "Cannot Unlock Empty Key"
 	EXCEEDED_WAIT_TIME = "Lock Exceeded Wait Time"
 
+	// AUTH
+	UNAUTHENTICATED   = "Unauthenticated"
+	PERMISSION_DENIED = "Permission Denied"
+
 	// RESOURCE TYPES:
 	PRIMARY_DATASET         ResourceType = "PRIMARY_DATASET"
 	TRANSFORMATION          ResourceType = "TRANSFORMATION"
@@ -117,7 +123,7 @@ func ToDashboardError(status *pb.ResourceStatus) string {
 	details := make(map[string]string)
 	for _, detail := range errorStatus.GetDetails() {
 		errorInfo := &errdetails.ErrorInfo{}
-		if err := anypb.UnmarshalTo(detail, errorInfo, proto.UnmarshalOptions{}); err == nil {
+		if err := anypb.UnmarshalTo(detail, errorInfo, proto.UnmarshalOptions{}); err == nil && !isErrorCodeDetail(errorInfo) {
 			reason = errorInfo.Reason
 			details = errorInfo.Metadata
 			break // Assuming we only care about the first error detail.
@@ -161,7 +167,12 @@ func FromErr(err error) Error {
 	// and cast them to ErrorInfo. If we find one, we'll create the appropriate error type
 	// and return it
 	for _, detail := range st.Details() {
-		if errorInfo, ok := detail.(*errdetails.ErrorInfo); ok {
+		errorInfo, ok := detail.(*errdetails.ErrorInfo)
+		// The error's code is derived from its type, so it doesn't need to be kept
+		if ok && isErrorCodeDetail(errorInfo) {
+			continue
+		}
+		if ok {
 			errorMsg := err.Error()
 			// This addresses the edge case where we receive a status error from another service and persist the
 			// error message to ETCD, which currently only occurs in the coordinator service. If the error message
@@ -191,6 +202,9 @@ func FromErr(err error) Error {
 			grpcError = NewInternalError(err)
 		}
 	}
+	if grpcError == nil {
+		return NewInternalError(fmt.Errorf(st.Message()))
+	}
 	return grpcError
 }
 
@@ -774,3 +774,26 @@ func Test_newBaseError(t *testing.T) {
 		})
 	}
 }
+
+func TestIsRetryable(t *testing.T) {
+	tests := map[string]struct {
+		err      error
+		expected bool
+	}{
+		"nil":              {nil, false},
+		"connection":       {NewConnectionError("dynamodb", errors.New("timeout")), true},
+		"execution":        {NewExecutionError("postgres", errors.New("syntax error at or near \"SELEC\"")), false},
+		"wrapped":          {fmt.Errorf("materialize: %w", NewConnectionError("dynamodb", errors.New("timeout"))), true},
+		"exceeded wait":    {NewExceededWaitTimeError("memory", "key"), true},
+		"invalid argument": {NewInvalidArgumentErrorf("bad config"), false},
+		"provider config":  {NewProviderConfigError("dynamodb", errors.New("bad credentials")), false},
+		"plain":            {errors.New("unknown"), false},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			if got := IsRetryable(test.err); got != test.expected {
+				t.Fatalf("Expected %v, got %v", test.expected, got)
+			}
+		})
+	}
+}
@@ -8,6 +8,7 @@
 package fferr
 
 import (
+	"errors"
 	"fmt"
 
 	"google.golang.org/grpc/codes"
@@ -133,6 +134,23 @@ type TaskRunFailedError struct {
 	baseError
 }
 
+// IsRetryable returns true if a job that failed with err may succeed when
+// run again, such as when a provider is briefly unreachable or a lock is held
+// for too long. Errors are classified by type, and any error that isn't known
+// to be transient, like a failed query, is not retryable.
+func IsRetryable(err error) bool {
+	var connErr *ConnectionError
+	var waitErr *ExceededWaitTimeError
+	switch {
+	case errors.As(err, &connErr):
+		return true
+	case errors.As(err, &waitErr):
+		return true
+	default:
+		return false
+	}
+}
+
 func NewJobAlreadyExistsError(key string, err error) *JobAlreadyExistsError {
 	if err == nil {
 		err = fmt.Errorf("job already exists")
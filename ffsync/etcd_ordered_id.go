@@ -0,0 +1,119 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+//
+// Copyright 2024 FeatureForm Inc.
+//
+
+package ffsync
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/featureform/fferr"
+	"github.com/featureform/logging"
+
+	clientv3 "go.etcd.io/etcd/client/v3"
+)
+
+const etcdOrderedIdPrefix = "/ff_ordered_id"
+
+type ETCDOrderedIdGeneratorConfig struct {
+	Endpoints   []string
+	Username    string
+	Password    string
+	DialTimeout time.Duration
+}
+
+// NewETCDOrderedIdGenerator creates an ID generator whose counters are stored
+// in etcd, so IDs keep increasing across restarts and between processes.
+func NewETCDOrderedIdGenerator(ctx context.Context, cfg ETCDOrderedIdGeneratorConfig) (OrderedIdGenerator, error) {
+	logger := logging.GetLoggerFromContext(ctx).With("ordered-id-etcd-endpoints", cfg.Endpoints)
+	dialTimeout := cfg.DialTimeout
+	if dialTimeout <= 0 {
+		dialTimeout = 5 * time.Second
+	}
+	client, err := clientv3.New(clientv3.Config{
+		Endpoints:   cfg.Endpoints,
+		Username:    cfg.Username,
+		Password:    cfg.Password,
+		DialTimeout: dialTimeout,
+	})
+	if err != nil {
+		logger.Errorw("Failed to create etcd client", "err", err)
+		return nil, fferr.NewConnectionError("etcd", err)
+	}
+	logger.Info("Successfully created OrderedIDGenerator for etcd")
+	return &etcdIdGenerator{
+		client: client,
+		prefix: etcdOrderedIdPrefix,
+		logger: logger,
+	}, nil
+}
+
+type etcdIdGenerator struct {
+	client *clientv3.Client
+	prefix string
+	logger logging.Logger
+}
+
+// NextId increments the namespace's counter with a compare-and-swap on the
+// counter key's revision. If another process incremented it first, the
+// counter is read again and the swap retried.
+func (e *etcdIdGenerator) NextId(ctx context.Context, namespace string) (OrderedId, error) {
+	logger := e.logger.WithRequestIDFromContext(ctx).With("ordered-id-namespace", namespace)
+	logger.Debug("Getting next ordered ID from etcd")
+	if namespace == "" {
+		errMsg := "cannot generate ID for empty namespace"
+		logger.Error(errMsg)
+		return nil, fferr.NewInternalErrorf(errMsg)
+	}
+
+	key := createLockKey(e.prefix, namespace)
+	for attempt := 1; ; attempt++ {
+		if err := ctx.Err(); err != nil {
+			logger.Errorw("Context done while getting next ID", "err", err)
+			return nil, fferr.NewInternalErrorf("failed to get next ID in namespace %s: %w", namespace, err)
+		}
+		resp, err := e.client.Get(ctx, key)
+		if err != nil {
+			logger.Errorw("Failed to get current ID", "err", err)
+			return nil, fferr.NewInternalErrorf("failed to get current ID in namespace %s: %w", namespace, err)
+		}
+
+		var current uint64
+		// A ModRevision of 0 only matches a key that doesn't exist yet.
+		var modRevision int64
+		if len(resp.Kvs) > 0 {
+			current, err = strconv.ParseUint(string(resp.Kvs[0].Value), 10, 64)
+			if err != nil {
+				logger.Errorw("Failed to parse current ID", "value", string(resp.Kvs[0].Value), "err", err)
+				return nil, fferr.NewInternalErrorf("failed to parse current ID in namespace %s: %w", namespace, err)
+			}
+			modRevision = resp.Kvs[0].ModRevision
+		}
+
+		next := current + 1
+		txnResp, err := e.client.Txn(ctx).
+			If(clientv3.Compare(clientv3.ModRevision(key), "=", modRevision)).
+			Then(clientv3.OpPut(key, strconv.FormatUint(next, 10))).
+			Commit()
+		if err != nil {
+			logger.Errorw("Failed to update ID", "err", err)
+			return nil, fferr.NewInternalErrorf("failed to update ID in namespace %s: %w", namespace, err)
+		}
+		if txnResp.Succeeded {
+			logger.Debugw("Got next ID", "next-id", next, "attempts", attempt)
+			return Uint64OrderedId(next), nil
+		}
+		logger.Debugw("ID was updated concurrently, retrying", "attempt", attempt)
+	}
+}
+
+func (e *etcdIdGenerator) Close() {
+	if err := e.client.Close(); err != nil {
+		e.logger.Errorw("Failed to close etcd client", "err", err)
+	}
+}
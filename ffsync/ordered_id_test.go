@@ -10,6 +10,7 @@ package ffsync
 import (
 	"context"
 	"fmt"
+	"sync"
 	"testing"
 
 	"github.com/featureform/helpers"
@@ -17,6 +18,7 @@ import (
 	"github.com/featureform/logging"
 
 	_ "github.com/lib/pq"
+	clientv3 "go.etcd.io/etcd/client/v3"
 )
 
 func TestUint64OrderedId(t *testing.T) {
@@ -76,6 +78,15 @@ func TestOrderedIdGenerator(t *testing.T) {
 				pg.Close()
 			},
 		},
+		{
+			name:      "ETCD",
+			shortTest: false,
+			createGen: createETCDIdGenerator,
+			deferFunc: func(generator OrderedIdGenerator, t *testing.T) {
+				cleanupETCDIdGenerator(t, generator)
+				generator.Close()
+			},
+		},
 	}
 
 	for _, tc := range testCases {
@@ -157,3 +168,126 @@ func createPSQLIdGenerator(t *testing.T) (OrderedIdGenerator, error) {
 	}
 	return NewPSQLOrderedIdGenerator(ctx, pool)
 }
+
+func etcdIdGeneratorConfig() ETCDOrderedIdGeneratorConfig {
+	if *useEnv {
+		host := helpers.GetEnv("ETCD_HOST", "localhost")
+		port := helpers.GetEnv("ETCD_PORT", "2379")
+		return ETCDOrderedIdGeneratorConfig{
+			Endpoints: []string{fmt.Sprintf("%s:%s", host, port)},
+			Username:  helpers.GetEnv("ETCD_USERNAME", ""),
+			Password:  helpers.GetEnv("ETCD_PASSWORD", ""),
+		}
+	}
+	return ETCDOrderedIdGeneratorConfig{
+		Endpoints: []string{fmt.Sprintf("127.0.0.1:%s", etcdPort)},
+	}
+}
+
+func createETCDIdGenerator(t *testing.T) (OrderedIdGenerator, error) {
+	return NewETCDOrderedIdGenerator(logging.NewTestContext(t), etcdIdGeneratorConfig())
+}
+
+func cleanupETCDIdGenerator(t *testing.T, generator OrderedIdGenerator) {
+	etcd := generator.(*etcdIdGenerator)
+	if _, err := etcd.client.Delete(context.Background(), etcd.prefix, clientv3.WithPrefix()); err != nil {
+		t.Errorf("failed to delete keys with prefix %s: %v", etcd.prefix, err)
+	}
+}
+
+func TestETCDOrderedIdGeneratorContention(t *testing.T) {
+	if testing.Short() {
+		t.Skip()
+	}
+	ctx := logging.NewTestContext(t)
+	const numGenerators = 4
+	const idsPerGenerator = 25
+
+	// Each generator has its own client, like separate processes would.
+	generators := make([]OrderedIdGenerator, numGenerators)
+	for i := range generators {
+		generator, err := createETCDIdGenerator(t)
+		if err != nil {
+			t.Fatalf("failed to create etcd ID generator: %v", err)
+		}
+		generators[i] = generator
+	}
+	defer func() {
+		cleanupETCDIdGenerator(t, generators[0])
+		for _, generator := range generators {
+			generator.Close()
+		}
+	}()
+
+	var mu sync.Mutex
+	seen := make(map[uint64]bool)
+	var wg sync.WaitGroup
+	for _, generator := range generators {
+		wg.Add(1)
+		go func(generator OrderedIdGenerator) {
+			defer wg.Done()
+			var prevId OrderedId
+			for i := 0; i < idsPerGenerator; i++ {
+				id, err := generator.NextId(ctx, "contention")
+				if err != nil {
+					t.Errorf("failed to get next id: %v", err)
+					return
+				}
+				if prevId != nil && !prevId.Less(id) {
+					t.Errorf("expected id '%s' to be greater than previous id '%s'", id, prevId)
+				}
+				prevId = id
+				mu.Lock()
+				if seen[id.Value().(uint64)] {
+					t.Errorf("id '%s' was generated more than once", id)
+				}
+				seen[id.Value().(uint64)] = true
+				mu.Unlock()
+			}
+		}(generator)
+	}
+	wg.Wait()
+
+	total := numGenerators * idsPerGenerator
+	if len(seen) != total {
+		t.Fatalf("expected %d unique ids, got %d", total, len(seen))
+	}
+	for i := 1; i <= total; i++ {
+		if !seen[uint64(i)] {
+			t.Fatalf("expected ids to be contiguous, missing id %d", i)
+		}
+	}
+}
+
+func TestETCDOrderedIdGeneratorRestart(t *testing.T) {
+	if testing.Short() {
+		t.Skip()
+	}
+	ctx := logging.NewTestContext(t)
+	generator, err := createETCDIdGenerator(t)
+	if err != nil {
+		t.Fatalf("failed to create etcd ID generator: %v", err)
+	}
+	defer cleanupETCDIdGenerator(t, generator)
+
+	var lastId OrderedId
+	for i := 0; i < 5; i++ {
+		if lastId, err = generator.NextId(ctx, "restart"); err != nil {
+			t.Fatalf("failed to get next id: %v", err)
+		}
+	}
+	generator.Close()
+
+	restarted, err := createETCDIdGenerator(t)
+	if err != nil {
+		t.Fatalf("failed to recreate etcd ID generator: %v", err)
+	}
+	defer restarted.Close()
+	id, err := restarted.NextId(ctx, "restart")
+	if err != nil {
+		t.Fatalf("failed to get next id after restart: %v", err)
+	}
+	if !id.Equals(Uint64OrderedId(6)) || !lastId.Less(id) {
+		t.Fatalf("expected id 6 after restart, following '%s', got '%s'", lastId, id)
+	}
+}
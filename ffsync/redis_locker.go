@@ -0,0 +1,239 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+//
+// Copyright 2024 FeatureForm Inc.
+//
+
+package ffsync
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/featureform/fferr"
+	"github.com/featureform/logging"
+
+	"github.com/google/uuid"
+	"github.com/jonboulle/clockwork"
+	"github.com/redis/rueidis"
+)
+
+const redisLockKeyPrefix = "ff_lock:"
+
+// Releasing and extending a lock are done in scripts so that checking the
+// owner's token and changing the key happen atomically. Otherwise a lock that
+// expired between the two could be released or extended for its new owner.
+var (
+	redisUnlockScript = rueidis.NewLuaScript(`
+local owner = redis.call("GET", KEYS[1])
+if not owner then
+	return -1
+end
+if owner == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0
+`)
+	redisExtendScript = rueidis.NewLuaScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("PEXPIRE", KEYS[1], ARGV[2])
+end
+return 0
+`)
+)
+
+type RedisLockerConfig struct {
+	Addr     string
+	Password string
+	DB       int
+	// TTL is how long a lock lives if its owner stops extending it, such as
+	// when the process holding it dies. Defaults to one minute.
+	TTL time.Duration
+}
+
+type redisKey struct {
+	owner string
+	key   string
+	done  chan error
+}
+
+func (k redisKey) Owner() string {
+	return k.owner
+}
+
+func (k redisKey) Key() string {
+	return k.key
+}
+
+// NewRedisLocker creates a locker that lets multiple processes coordinate
+// through a single Redis instance. Each lock is a key set with NX and a TTL to
+// a token unique to its owner; only the owner can release it, and it's kept
+// alive in the background until it's released.
+func NewRedisLocker(ctx context.Context, cfg RedisLockerConfig) (Locker, error) {
+	logger := logging.GetLoggerFromContext(ctx).With("redis-lock-addr", cfg.Addr)
+	client, err := rueidis.NewClient(rueidis.ClientOption{
+		InitAddress:  []string{cfg.Addr},
+		Password:     cfg.Password,
+		SelectDB:     cfg.DB,
+		DisableCache: true,
+	})
+	if err != nil {
+		logger.Errorw("Failed to create redis client", "err", err)
+		return nil, fferr.NewConnectionError("redis", err)
+	}
+	ttl := cfg.TTL
+	if ttl <= 0 {
+		ttl = validTimePeriod.Duration()
+	}
+	return &redisLocker{
+		client: client,
+		ttl:    ttl,
+		logger: logger,
+		clock:  clockwork.NewRealClock(),
+	}, nil
+}
+
+type redisLocker struct {
+	client rueidis.Client
+	ttl    time.Duration
+	logger logging.Logger
+	clock  clockwork.Clock
+}
+
+func (l *redisLocker) runLockCommand(ctx context.Context, key string, owner string) error {
+	cmd := l.client.B().Set().Key(redisLockKeyPrefix + key).Value(owner).Nx().PxMilliseconds(l.ttl.Milliseconds()).Build()
+	err := l.client.Do(ctx, cmd).Error()
+	if rueidis.IsRedisNil(err) {
+		return fferr.NewKeyAlreadyLockedError(key, owner, nil)
+	} else if err != nil {
+		return fferr.NewInternalErrorf("failed to lock key %s: %v", key, err)
+	}
+	return nil
+}
+
+func (l *redisLocker) attemptLock(ctx context.Context, key string, owner string, shouldWait bool, logger logging.Logger) error {
+	startTime := l.clock.Now()
+	for {
+		if hasExceededWaitTime(startTime) {
+			logger.Error("Exceeded time waiting for lock")
+			return fferr.NewExceededWaitTimeError("redis", key)
+		}
+		if err := l.runLockCommand(ctx, key, owner); err == nil {
+			return nil
+		} else if fferr.IsKeyAlreadyLockedError(err) && shouldWait {
+			l.clock.Sleep(100 * time.Millisecond)
+		} else {
+			return err
+		}
+	}
+}
+
+func (l *redisLocker) Lock(ctx context.Context, key string, shouldWait bool) (Key, error) {
+	owner := uuid.New().String()
+	logger := l.logger.WithRequestIDFromContext(ctx).With(
+		"lock-key", key, "should-wait-for-lock", shouldWait, "lock-owner", owner,
+	)
+	logger.Debug("Locking key")
+	if key == "" {
+		return nil, fferr.NewLockEmptyKeyError()
+	} else if len(key) > maxKeyLength {
+		errMsg := fmt.Sprintf("key is too long: %d, max length: %d", len(key), maxKeyLength)
+		logger.Error(errMsg)
+		return nil, fferr.NewInternalErrorf(errMsg)
+	}
+	if err := l.attemptLock(ctx, key, owner, shouldWait, logger); err != nil {
+		if !fferr.IsKeyAlreadyLockedError(err) {
+			logger.Errorw("Failed to lock key", "err", err)
+		} else {
+			logger.Debug("Key was already locked and didnt wait", "err", err)
+		}
+		return nil, err
+	}
+
+	lockKey := &redisKey{
+		owner: owner,
+		key:   key,
+		done:  make(chan error),
+	}
+	go l.updateLockTime(lockKey, logger)
+
+	logger.Debug("Successfully locked key")
+	return lockKey, nil
+}
+
+// updateLockTime extends the lock's TTL a few times per TTL until it's
+// released, so long running jobs keep their lock.
+func (l *redisLocker) updateLockTime(key *redisKey, logger logging.Logger) {
+	ticker := l.clock.NewTicker(l.ttl / 3)
+	defer ticker.Stop()
+
+	failedUpdatesInARow := 0
+	for {
+		select {
+		case <-key.done:
+			logger.Debug("Lock time extender received signal to stop")
+			return
+		case <-ticker.Chan():
+			ttl := fmt.Sprintf("%d", l.ttl.Milliseconds())
+			extended, err := redisExtendScript.Exec(context.Background(), l.client, []string{redisLockKeyPrefix + key.key}, []string{key.owner, ttl}).AsInt64()
+			if err != nil {
+				failedUpdatesInARow++
+				errLogger := logger.With("error", err, "lock-failed-updates-in-row", failedUpdatesInARow)
+				if failedUpdatesInARow >= tickerFailedUpdateLimit {
+					errLogger.Error("Failed to extend lock, not trying again")
+					return
+				}
+				errLogger.Warn("Failed to extend lock, trying again")
+				continue
+			}
+			if extended == 0 {
+				logger.Warn("Lock expired or is owned by someone else, exiting lock extender thread")
+				return
+			}
+			failedUpdatesInARow = 0
+		}
+	}
+}
+
+func (l *redisLocker) Unlock(ctx context.Context, key Key) error {
+	if key == nil {
+		return fferr.NewInternalError(fmt.Errorf("cannot unlock a nil key"))
+	}
+	if key.Key() == "" {
+		return fferr.NewUnlockEmptyKeyError()
+	}
+	logger := l.logger.WithRequestIDFromContext(ctx).With(
+		"unlock-key-owner", key.Owner(),
+		"unlock-key-key", key.Key(),
+	)
+	logger.Debug("Unlocking key")
+	rKey, ok := key.(*redisKey)
+	if !ok {
+		errMsg := fmt.Sprintf("Trying to unlock key %#v as a redisKey, wrong type.", key)
+		logger.Error(errMsg)
+		return fferr.NewInternalErrorf(errMsg)
+	}
+
+	deleted, err := redisUnlockScript.Exec(ctx, l.client, []string{redisLockKeyPrefix + key.Key()}, []string{key.Owner()}).AsInt64()
+	if err != nil {
+		logger.Errorw("Failed to unlock key", "error", err)
+		return fferr.NewInternalErrorf("failed to unlock key %s: %v", key.Key(), err)
+	}
+	switch deleted {
+	case -1:
+		return fferr.NewKeyNotLockedError(key.Key(), nil)
+	case 0:
+		err := fferr.NewKeyAlreadyLockedError(key.Key(), "", fmt.Errorf("attempting to unlock with incorrect key"))
+		err.AddDetail("received key", key.Owner())
+		return err
+	}
+	close(rKey.done)
+	logger.Debug("Successfully unlocked key")
+	return nil
+}
+
+func (l *redisLocker) Close() {
+	l.client.Close()
+}
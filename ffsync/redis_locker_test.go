@@ -0,0 +1,150 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+//
+// Copyright 2024 FeatureForm Inc.
+//
+
+package ffsync
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis"
+	"github.com/jonboulle/clockwork"
+
+	"github.com/featureform/fferr"
+	"github.com/featureform/logging"
+)
+
+func newTestRedisLocker(t *testing.T, addr string, clock clockwork.Clock) *redisLocker {
+	t.Helper()
+	locker, err := NewRedisLocker(logging.NewTestContext(t), RedisLockerConfig{Addr: addr, TTL: 30 * time.Second})
+	if err != nil {
+		t.Fatalf("Failed to create Redis locker: %v", err)
+	}
+	rLocker := locker.(*redisLocker)
+	rLocker.clock = clock
+	return rLocker
+}
+
+func TestRedisLocker(t *testing.T) {
+	server, err := miniredis.Run()
+	if err != nil {
+		t.Fatalf("Failed to start miniredis: %v", err)
+	}
+	defer server.Close()
+
+	clock := clockwork.NewFakeClock()
+	locker := newTestRedisLocker(t, server.Addr(), clock)
+	defer locker.Close()
+
+	test := LockerTest{
+		t:          t,
+		locker:     locker,
+		lockerType: "redis",
+	}
+	test.Run(clock)
+}
+
+// TestRedisLockerContention runs two lockers with their own connections, like
+// two replicas would, against the same key.
+func TestRedisLockerContention(t *testing.T) {
+	server, err := miniredis.Run()
+	if err != nil {
+		t.Fatalf("Failed to start miniredis: %v", err)
+	}
+	defer server.Close()
+
+	ctx := context.Background()
+	key := "/tasks/metadata/task_id=7"
+	first := newTestRedisLocker(t, server.Addr(), clockwork.NewRealClock())
+	second := newTestRedisLocker(t, server.Addr(), clockwork.NewRealClock())
+	defer second.Close()
+
+	firstLock, err := first.Lock(ctx, key, false)
+	if err != nil {
+		t.Fatalf("Lock failed: %v", err)
+	}
+	if _, err := second.Lock(ctx, key, false); !fferr.IsKeyAlreadyLockedError(err) {
+		t.Fatalf("Expected the second process to fail to lock the key, got: %v", err)
+	}
+
+	// Only the owner's token can release the lock.
+	forged := &redisKey{owner: "not-the-owner", key: key, done: make(chan error)}
+	if err := second.Unlock(ctx, forged); !fferr.IsKeyAlreadyLockedError(err) {
+		t.Fatalf("Expected unlocking with the wrong token to fail, got: %v", err)
+	}
+
+	// The second process gets the lock once the first one releases it.
+	lockCh := make(chan Key, 1)
+	errCh := make(chan error, 1)
+	go lockGoRoutine(second, key, true, lockCh, errCh)
+	time.Sleep(200 * time.Millisecond)
+	if err := first.Unlock(ctx, firstLock); err != nil {
+		t.Fatalf("Unlock failed: %v", err)
+	}
+	secondLock := <-lockCh
+	if err := <-errCh; err != nil {
+		t.Fatalf("Waiting for lock failed: %v", err)
+	}
+	if secondLock.Owner() == firstLock.Owner() {
+		t.Fatalf("Expected each lock to have its own owner token")
+	}
+
+	// If the owner dies without releasing the lock it stops being extended
+	// and expires after its TTL.
+	first.Close()
+	if err := second.Unlock(ctx, secondLock); err != nil {
+		t.Fatalf("Unlock failed: %v", err)
+	}
+	third := newTestRedisLocker(t, server.Addr(), clockwork.NewRealClock())
+	if _, err := third.Lock(ctx, key, false); err != nil {
+		t.Fatalf("Lock failed: %v", err)
+	}
+	third.Close()
+	if _, err := second.Lock(ctx, key, false); !fferr.IsKeyAlreadyLockedError(err) {
+		t.Fatalf("Expected the lock of the closed process to still be held, got: %v", err)
+	}
+	server.FastForward(31 * time.Second)
+	if _, err := second.Lock(ctx, key, false); err != nil {
+		t.Fatalf("Expected the lock to expire after its TTL: %v", err)
+	}
+}
+
+func TestRedisLockerExtendsLock(t *testing.T) {
+	server, err := miniredis.Run()
+	if err != nil {
+		t.Fatalf("Failed to start miniredis: %v", err)
+	}
+	defer server.Close()
+
+	ctx := context.Background()
+	key := "/tasks/metadata/task_id=8"
+	clock := clockwork.NewFakeClock()
+	locker := newTestRedisLocker(t, server.Addr(), clock)
+	defer locker.Close()
+
+	lock, err := locker.Lock(ctx, key, false)
+	if err != nil {
+		t.Fatalf("Lock failed: %v", err)
+	}
+	// Past the TTL overall, but the lock is extended every third of it.
+	for i := 0; i < 4; i++ {
+		server.FastForward(locker.ttl / 3)
+		clock.BlockUntil(1)
+		clock.Advance(locker.ttl / 3)
+		time.Sleep(100 * time.Millisecond)
+	}
+	if ttl := server.TTL(redisLockKeyPrefix + key); ttl <= 0 {
+		t.Fatalf("Expected the lock to have been extended, TTL: %v", ttl)
+	}
+	if err := locker.Unlock(ctx, lock); err != nil {
+		t.Fatalf("Unlock failed: %v", err)
+	}
+	if err := locker.Unlock(ctx, lock); err == nil {
+		t.Fatalf("Expected unlocking an unlocked key to fail")
+	}
+}
@@ -18,6 +18,7 @@ import (
 )
 
 var pgPort string
+var etcdPort string
 
 // Flag to enable whether to use env vars or built-in values for dockertest
 var useEnv = flag.Bool("use-env", false, "Use environment variables for ETCD configuration")
@@ -43,6 +44,11 @@ func initTestDocker() (*dockertest.Pool, []*dockertest.Resource) {
 
 	log.Printf("pg conection: %s", res.GetHostPort("5432/tcp"))
 	log.Printf("pg bound ip: %s", res.GetBoundIP("5432/tcp"))
+
+	etcdRes := tests.InitETCD(pool)
+	etcdPort = etcdRes.GetPort("2379/tcp")
+	etcdRes.Expire(120)
+	resources = append(resources, etcdRes)
 	return pool, resources
 }
 
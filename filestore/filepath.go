@@ -38,6 +38,7 @@ const (
 	NilFileType FileType = ""
 	Parquet     FileType = "parquet"
 	CSV         FileType = "csv"
+	Avro        FileType = "avro"
 	JSON        FileType = "json"
 	DB          FileType = "db"
 )
@@ -56,13 +57,43 @@ var ValidSchemes = []string{
 	GSPrefix, S3Prefix, S3APrefix, S3NPrefix, AzureBlobPrefix, HDFSPrefix, FileSystemPrefix,
 }
 
+// Compression is the codec a file is compressed with, as given by a trailing
+// extension like the .gz in transactions.csv.gz.
+type Compression string
+
+const (
+	NoCompression Compression = ""
+	Gzip          Compression = "gzip"
+	Zstd          Compression = "zstd"
+)
+
+var compressionExtensions = map[string]Compression{
+	".gz":  Gzip,
+	".zst": Zstd,
+}
+
+// CompressionOf returns the codec that file is compressed with, if any.
+func CompressionOf(file string) Compression {
+	return compressionExtensions[filepath.Ext(file)]
+}
+
+// trimCompressionExt removes the compression extension from file so that the
+// extension before it gives the file's type.
+func trimCompressionExt(file string) string {
+	ext := filepath.Ext(file)
+	if _, ok := compressionExtensions[ext]; ok {
+		return strings.TrimSuffix(file, ext)
+	}
+	return file
+}
+
 func (ft FileType) Matches(file string) bool {
 	ext := GetFileExtension(file)
 	return FileType(ext) == ft
 }
 
 func IsValidFileType(file string) bool {
-	for _, fileType := range []FileType{Parquet, CSV, DB} {
+	for _, fileType := range []FileType{Parquet, CSV, Avro, DB} {
 		if fileType.Matches(file) {
 			return true
 		}
@@ -70,8 +101,10 @@ func IsValidFileType(file string) bool {
 	return false
 }
 
+// GetFileExtension returns the type extension of file, ignoring a compression
+// extension after it.
 func GetFileExtension(file string) string {
-	ext := filepath.Ext(file)
+	ext := filepath.Ext(trimCompressionExt(file))
 	return strings.ReplaceAll(ext, ".", "")
 }
 
@@ -104,8 +137,11 @@ type Filepath interface {
 	IsDir() bool
 	SetIsDir(isDir bool)
 
-	// Returns the file extension (e.g. "parquet", "csv", etc. of the object)
+	// Returns the file extension (e.g. "parquet", "csv", etc. of the object). The
+	// extension of a compressed file is the one before its compression extension.
 	Ext() FileType
+	// Returns the codec the object is compressed with, if any
+	Compression() Compression
 
 	// Returns the full path to the object, including the scheme and bucket/container
 	ToURI() string
@@ -166,6 +202,32 @@ func NewEmptyDirpath(storeType FileStoreType) (Filepath, error) {
 	}
 }
 
+// NewDirpathFromURI parses uri, like s3://bucket/path, into a directory path in
+// the file store its scheme belongs to.
+func NewDirpathFromURI(uri string) (Filepath, error) {
+	var storeType FileStoreType
+	switch {
+	case strings.HasPrefix(uri, S3Prefix), strings.HasPrefix(uri, S3APrefix), strings.HasPrefix(uri, S3NPrefix):
+		storeType = S3
+	case strings.HasPrefix(uri, GSPrefix):
+		storeType = GCS
+	case strings.HasPrefix(uri, AzureBlobPrefix):
+		storeType = Azure
+	case strings.HasPrefix(uri, HDFSPrefix):
+		storeType = HDFS
+	default:
+		return nil, fferr.NewInvalidArgumentErrorf("%s isn't a file store URI, expected one of the schemes %v", uri, ValidSchemes)
+	}
+	dir, err := NewEmptyDirpath(storeType)
+	if err != nil {
+		return nil, err
+	}
+	if err := dir.ParseDirPath(uri); err != nil {
+		return nil, err
+	}
+	return dir, nil
+}
+
 type FilePath struct {
 	scheme  string
 	bucket  string
@@ -226,12 +288,16 @@ func (fp *FilePath) KeyPrefix() string {
 }
 
 func (fp *FilePath) Ext() FileType {
-	ext := filepath.Ext(fp.key)
+	ext := filepath.Ext(trimCompressionExt(fp.key))
 	// filepath.Ext returns the extension with the "." prefix, so we need to trim it
 	// to match our FileType type.
 	return FileType(strings.TrimPrefix(ext, "."))
 }
 
+func (fp *FilePath) Compression() Compression {
+	return CompressionOf(fp.key)
+}
+
 func (fp *FilePath) ToURI() string {
 	return fmt.Sprintf("%s%s/%s", fp.scheme, fp.bucket, fp.key)
 }
@@ -583,6 +649,8 @@ const (
 type FilePathGroup struct {
 	Groups     map[string][]Filepath
 	SortedKeys []string
+	// Directories holds the key of the directory each group was found in.
+	Directories map[string]string
 }
 
 func (fg FilePathGroup) GetFirst() ([]Filepath, error) {
@@ -599,6 +667,15 @@ func (fg FilePathGroup) GetLast() ([]Filepath, error) {
 	return fg.Groups[fg.SortedKeys[len(fg.SortedKeys)-1]], nil
 }
 
+// GetFirstDirectory returns the key of the directory holding the first group. Unlike
+// the key prefix of its files, this doesn't include any partition directories.
+func (fg FilePathGroup) GetFirstDirectory() (string, error) {
+	if len(fg.SortedKeys) == 0 {
+		return "", fferr.NewInternalErrorf("no groups found")
+	}
+	return fg.Directories[fg.SortedKeys[0]], nil
+}
+
 // Currently, grouping files by date time directory written out by Spark is the only use case
 // for grouping files; however, this method can be extended to support other grouping types
 // in the future.
@@ -615,22 +692,38 @@ func NewFilePathGroup(files []Filepath, grouping FilePathGroupingType) (FilePath
 	}
 }
 
+// isPartitionDirectory reports whether a path component is a Hive-style partition
+// directory (e.g. ts_date=2024-01-01), as written by Spark when output is partitioned.
+func isPartitionDirectory(part string) bool {
+	return strings.Contains(part, "=")
+}
+
 func groupByDateTimeDirectory(files []Filepath) (FilePathGroup, error) {
 	groups := make(map[string][]Filepath, 0)
+	directories := make(map[string]string, 0)
 	for _, file := range files {
 		pathParts := strings.Split(file.Key(), "/")
+		// Partitioned output has one or more partition directories between the datetime
+		// directory and the file, so they're skipped to find the datetime directory.
+		datetimeIdx := len(pathParts) - 2
+		for datetimeIdx > 0 && isPartitionDirectory(pathParts[datetimeIdx]) {
+			datetimeIdx--
+		}
 		// The path to a file follows the format:
-		// <OPTIONAL PATH>/featureform/<TYPE>/<NAME DIR>/<VARIANT DIR>/<DATETIME DIR>/<FILENAME>
+		// <OPTIONAL PATH>/featureform/<TYPE>/<NAME DIR>/<VARIANT DIR>/<DATETIME DIR>/<OPTIONAL PARTITION DIRS>/<FILENAME>
 		// or in the case of batch features:
 		// <OPTIONAL PATH>/featureform/BatchFeatures/<UUID 5>/<DATETIME DIR>/<FILENAME>
-		// so there should be at least 5 path components.
-		if len(pathParts) < 5 {
+		// so there should be at least 3 path components before the datetime directory.
+		if datetimeIdx < 3 {
 			return FilePathGroup{}, fferr.NewInternalError(fmt.Errorf("expected at least 5 path components, but found: %s", file.Key()))
 		}
-		// The datetime directory is the second to last path component and follows the format:
+		// The datetime directory follows the format:
 		// <YEAR>-<MONTH>-<DAY>-<HOUR>-<MINUTE>-<SECOND>-<FRACTIONAL SECONDS>
-		datetime := pathParts[len(pathParts)-2]
+		datetime := pathParts[datetimeIdx]
 		fractionalSecondsIdx := strings.LastIndex(datetime, "-")
+		if fractionalSecondsIdx < 0 {
+			return FilePathGroup{}, fferr.NewInvalidArgumentError(fmt.Errorf("expected path component %s to be a valid datetime", datetime))
+		}
 		// The format written out by Spark presents issues for parsing the datetime due to the fractional
 		// seconds component; given we're only interested in validating that this part of the path is a
 		// valid datetime, we'll remove the fractional seconds component.
@@ -640,6 +733,7 @@ func groupByDateTimeDirectory(files []Filepath) (FilePathGroup, error) {
 		}
 		if _, exists := groups[datetime]; !exists {
 			groups[datetime] = []Filepath{file}
+			directories[datetime] = strings.Join(pathParts[:datetimeIdx+1], "/")
 		} else {
 			groups[datetime] = append(groups[datetime], file)
 		}
@@ -658,7 +752,8 @@ func groupByDateTimeDirectory(files []Filepath) (FilePathGroup, error) {
 	})
 
 	return FilePathGroup{
-		Groups:     groups,
-		SortedKeys: keys,
+		Groups:      groups,
+		SortedKeys:  keys,
+		Directories: directories,
 	}, nil
 }
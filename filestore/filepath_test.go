@@ -407,3 +407,107 @@ func TestNewEmptyPath(t *testing.T) {
 		})
 	}
 }
+
+func TestGroupByDateTimeDirectory(t *testing.T) {
+	newPath := func(key string) Filepath {
+		return &S3Filepath{
+			FilePath: FilePath{
+				bucket: "bucket",
+				scheme: "s3://",
+				key:    key,
+			},
+		}
+	}
+	older := "featureform/Materialization/name/variant/2024-01-01-10-00-00-000000"
+	newer := "featureform/Materialization/name/variant/2024-01-02-10-00-00-000000"
+	files := []Filepath{
+		newPath(older + "/part-0000.parquet"),
+		newPath(newer + "/ts_date=2024-01-01/part-0000.parquet"),
+		newPath(newer + "/ts_date=2024-01-02/part-0000.parquet"),
+	}
+	groups, err := NewFilePathGroup(files, DateTimeDirectoryGrouping)
+	if err != nil {
+		t.Fatalf("Failed to group files: %v", err)
+	}
+	newest, err := groups.GetFirst()
+	if err != nil {
+		t.Fatalf("Failed to get newest group: %v", err)
+	}
+	if len(newest) != 2 {
+		t.Fatalf("Expected both partitions in the newest group, got %v", newest)
+	}
+	dir, err := groups.GetFirstDirectory()
+	if err != nil {
+		t.Fatalf("Failed to get newest directory: %v", err)
+	}
+	if dir != newer {
+		t.Fatalf("Expected directory %s, got %s", newer, dir)
+	}
+	oldest, err := groups.GetLast()
+	if err != nil {
+		t.Fatalf("Failed to get oldest group: %v", err)
+	}
+	if len(oldest) != 1 || oldest[0].Key() != older+"/part-0000.parquet" {
+		t.Fatalf("Unexpected oldest group: %v", oldest)
+	}
+	if _, err := NewFilePathGroup([]Filepath{newPath("variant/ts_date=2024-01-01/part-0000.parquet")}, DateTimeDirectoryGrouping); err == nil {
+		t.Fatalf("Succeeded to group a file without a datetime directory")
+	}
+}
+
+func TestCompressedFileExtensions(t *testing.T) {
+	tests := []struct {
+		key         string
+		ext         FileType
+		compression Compression
+	}{
+		{"data/transactions.csv", CSV, NoCompression},
+		{"data/transactions.csv.gz", CSV, Gzip},
+		{"data/transactions.json.gz", JSON, Gzip},
+		{"data/transactions.csv.zst", CSV, Zstd},
+		{"data/transactions.zst", NilFileType, Zstd},
+		{"data/part-0000.snappy.parquet", Parquet, NoCompression},
+	}
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			fp := FilePath{scheme: S3Prefix, bucket: "bucket", key: tt.key}
+			if fp.Ext() != tt.ext {
+				t.Errorf("expected extension %q, got %q", tt.ext, fp.Ext())
+			}
+			if fp.Compression() != tt.compression {
+				t.Errorf("expected compression %q, got %q", tt.compression, fp.Compression())
+			}
+			if !tt.ext.Matches(tt.key) {
+				t.Errorf("expected %s to match file type %q", tt.key, tt.ext)
+			}
+		})
+	}
+}
+
+func TestNewDirpathFromURI(t *testing.T) {
+	tests := []struct {
+		uri     string
+		scheme  string
+		wantErr bool
+	}{
+		{"s3://bucket/exports", S3Prefix, false},
+		{"s3a://bucket/exports", S3APrefix, false},
+		{"gs://bucket/exports", GSPrefix, false},
+		{"/tmp/exports", "", true},
+		{"postgres://host/db", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.uri, func(t *testing.T) {
+			dir, err := NewDirpathFromURI(tt.uri)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("expected error %v, got %v", tt.wantErr, err)
+			}
+			if tt.wantErr {
+				return
+			}
+			if !dir.IsDir() || dir.Scheme() != tt.scheme || dir.Bucket() != "bucket" || dir.Key() != "exports" {
+				t.Fatalf("unexpected dir path for %s: %s", tt.uri, dir.ToURI())
+			}
+		})
+	}
+}
@@ -12,7 +12,7 @@ set -e
 cp metadata/proto/metadata.proto client/src/featureform/proto/metadata.proto
 cp proto/serving.proto client/src/featureform/proto/serving.proto
 
-protoc --go_out=. --go_opt=paths=source_relative     --go-grpc_out=. --go-grpc_opt=paths=source_relative     ./proto/serving.proto
+protoc --go_out=. --go_opt=paths=source_relative     --go-grpc_out=. --go-grpc_opt=paths=source_relative     --grpc-gateway_out=. --grpc-gateway_opt=paths=source_relative,grpc_api_configuration=./proto/serving_gateway.yaml ./proto/serving.proto
 python3 -m grpc_tools.protoc -I ./client/src --python_out=./client/src  --mypy_out=./client/src --grpc_python_out=./client/src/ ./client/src/featureform/proto/serving.proto
 
 protoc --go_out=. --go_opt=paths=source_relative     --go-grpc_out=. --go-grpc_opt=paths=source_relative     ./metadata/proto/metadata.proto
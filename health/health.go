@@ -54,7 +54,12 @@ func (h *Health) IsSupportedProvider(t pt.Type) bool {
 		pt.ClickHouseOffline,
 		pt.SparkOffline,
 		pt.RedshiftOffline,
-		pt.FirestoreOnline:
+		pt.DatabricksSQLOffline,
+		pt.FirestoreOnline,
+		pt.CassandraOnline,
+		pt.MongoDBOnline,
+		pt.BigtableOnline,
+		pt.Kafka:
 		return true
 	default:
 		return false
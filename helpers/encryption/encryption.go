@@ -0,0 +1,187 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+//
+// Copyright 2024 FeatureForm Inc.
+//
+
+// Package encryption encrypts secrets, like provider configs, before they're
+// stored. Each value is encrypted with its own random data key, and the data
+// key is encrypted ("wrapped") by a key encryption key held in AWS KMS, GCP
+// KMS, or locally for development.
+package encryption
+
+import (
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+
+	"github.com/featureform/config"
+	"github.com/featureform/fferr"
+	"github.com/featureform/helpers"
+)
+
+// KeyProvider is the name of a KeyWrapper implementation, selected with
+// FF_CONFIG_ENCRYPTION_KEY_PROVIDER.
+type KeyProvider string
+
+const (
+	LocalKeyProvider  KeyProvider = "local"
+	AWSKMSKeyProvider KeyProvider = "aws_kms"
+	GCPKMSKeyProvider KeyProvider = "gcp_kms"
+)
+
+const dataKeySize = 32
+
+// envelopePrefix marks an encrypted value, so values stored before
+// encryption was enabled can still be read.
+var envelopePrefix = []byte("ffenc:v1:")
+
+// KeyWrapper encrypts and decrypts data keys with a key encryption key.
+type KeyWrapper interface {
+	Provider() KeyProvider
+	Wrap(ctx context.Context, dataKey []byte) ([]byte, error)
+	Unwrap(ctx context.Context, wrapped []byte) ([]byte, error)
+}
+
+type envelope struct {
+	Provider   KeyProvider `json:"provider"`
+	WrappedKey []byte      `json:"wrapped_key"`
+	Nonce      []byte      `json:"nonce"`
+	Ciphertext []byte      `json:"ciphertext"`
+}
+
+// Encrypter does envelope encryption with a KeyWrapper. A nil Encrypter
+// leaves values in plaintext.
+type Encrypter struct {
+	wrapper KeyWrapper
+}
+
+func NewEncrypter(wrapper KeyWrapper) *Encrypter {
+	return &Encrypter{wrapper: wrapper}
+}
+
+// FromEnv creates an Encrypter using the key provider set in the
+// FF_CONFIG_ENCRYPTION_* environment variables. It returns nil if no key
+// provider is set.
+func FromEnv(ctx context.Context) (*Encrypter, error) {
+	provider := KeyProvider(helpers.GetEnv(config.EnvConfigEncryptionKeyProvider, ""))
+	var wrapper KeyWrapper
+	var err error
+	switch provider {
+	case "":
+		return nil, nil
+	case LocalKeyProvider:
+		wrapper, err = NewLocalKeyWrapper(helpers.GetEnv(config.EnvConfigEncryptionLocalKey, ""))
+	case AWSKMSKeyProvider:
+		wrapper, err = NewAWSKMSKeyWrapper(ctx, helpers.GetEnv(config.EnvConfigEncryptionKMSKeyID, ""))
+	case GCPKMSKeyProvider:
+		wrapper, err = NewGCPKMSKeyWrapper(ctx, helpers.GetEnv(config.EnvConfigEncryptionKMSKeyID, ""))
+	default:
+		possible := []KeyProvider{LocalKeyProvider, AWSKMSKeyProvider, GCPKMSKeyProvider}
+		return nil, fferr.NewInvalidConfigEnv(config.EnvConfigEncryptionKeyProvider, provider, possible)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return NewEncrypter(wrapper), nil
+}
+
+// IsEncrypted returns true if value was created by Encrypt.
+func IsEncrypted(value []byte) bool {
+	return bytes.HasPrefix(value, envelopePrefix)
+}
+
+func (e *Encrypter) Encrypt(ctx context.Context, plaintext []byte) ([]byte, error) {
+	if e == nil {
+		return plaintext, nil
+	}
+	dataKey := make([]byte, dataKeySize)
+	if _, err := rand.Read(dataKey); err != nil {
+		return nil, fferr.NewInternalError(err)
+	}
+	nonce, ciphertext, err := seal(dataKey, plaintext)
+	if err != nil {
+		return nil, err
+	}
+	wrapped, err := e.wrapper.Wrap(ctx, dataKey)
+	if err != nil {
+		return nil, err
+	}
+	serialized, err := json.Marshal(envelope{
+		Provider:   e.wrapper.Provider(),
+		WrappedKey: wrapped,
+		Nonce:      nonce,
+		Ciphertext: ciphertext,
+	})
+	if err != nil {
+		return nil, fferr.NewInternalError(err)
+	}
+	return append(append([]byte{}, envelopePrefix...), serialized...), nil
+}
+
+// Decrypt decrypts a value created by Encrypt. Values that aren't encrypted
+// are returned as is.
+func (e *Encrypter) Decrypt(ctx context.Context, value []byte) ([]byte, error) {
+	if !IsEncrypted(value) {
+		return value, nil
+	}
+	if e == nil {
+		return nil, fferr.NewInvalidConfigf("value is encrypted but %s is not set", config.EnvConfigEncryptionKeyProvider)
+	}
+	var env envelope
+	if err := json.Unmarshal(value[len(envelopePrefix):], &env); err != nil {
+		return nil, fferr.NewParsingError(err)
+	}
+	if env.Provider != e.wrapper.Provider() {
+		return nil, fferr.NewInvalidConfigf("value was encrypted with key provider %s, but %s is configured", env.Provider, e.wrapper.Provider())
+	}
+	dataKey, err := e.wrapper.Unwrap(ctx, env.WrappedKey)
+	if err != nil {
+		return nil, err
+	}
+	return open(dataKey, env.Nonce, env.Ciphertext)
+}
+
+func seal(key, plaintext []byte) ([]byte, []byte, error) {
+	aead, err := newGCM(key)
+	if err != nil {
+		return nil, nil, err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, nil, fferr.NewInternalError(err)
+	}
+	return nonce, aead.Seal(nil, nonce, plaintext, nil), nil
+}
+
+func open(key, nonce, ciphertext []byte) ([]byte, error) {
+	aead, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(nonce) != aead.NonceSize() {
+		return nil, fferr.NewInternalErrorf("invalid nonce size %d", len(nonce))
+	}
+	plaintext, err := aead.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		return nil, fferr.NewInternalError(fmt.Errorf("failed to decrypt value: %w", err))
+	}
+	return plaintext, nil
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fferr.NewInternalError(err)
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fferr.NewInternalError(err)
+	}
+	return aead, nil
+}
@@ -0,0 +1,132 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+//
+// Copyright 2024 FeatureForm Inc.
+//
+
+package encryption
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"testing"
+
+	"github.com/featureform/config"
+)
+
+func newLocalKey(t *testing.T) string {
+	key := make([]byte, dataKeySize)
+	if _, err := rand.Read(key); err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+	return base64.StdEncoding.EncodeToString(key)
+}
+
+func newLocalEncrypter(t *testing.T) *Encrypter {
+	wrapper, err := NewLocalKeyWrapper(newLocalKey(t))
+	if err != nil {
+		t.Fatalf("Failed to create key wrapper: %v", err)
+	}
+	return NewEncrypter(wrapper)
+}
+
+func TestEncryptDecrypt(t *testing.T) {
+	ctx := context.Background()
+	encrypter := newLocalEncrypter(t)
+	plaintext := []byte(`{"Password": "hunter2"}`)
+	encrypted, err := encrypter.Encrypt(ctx, plaintext)
+	if err != nil {
+		t.Fatalf("Failed to encrypt: %v", err)
+	}
+	if !IsEncrypted(encrypted) {
+		t.Fatalf("Expected value to be encrypted: %s", encrypted)
+	}
+	if bytes.Contains(encrypted, []byte("hunter2")) {
+		t.Fatalf("Encrypted value contains the plaintext: %s", encrypted)
+	}
+	decrypted, err := encrypter.Decrypt(ctx, encrypted)
+	if err != nil {
+		t.Fatalf("Failed to decrypt: %v", err)
+	}
+	if !bytes.Equal(decrypted, plaintext) {
+		t.Fatalf("Expected %s, got %s", plaintext, decrypted)
+	}
+	again, err := encrypter.Encrypt(ctx, plaintext)
+	if err != nil {
+		t.Fatalf("Failed to encrypt: %v", err)
+	}
+	if bytes.Equal(encrypted, again) {
+		t.Fatalf("Expected each encryption to use a new data key and nonce")
+	}
+}
+
+func TestDecryptPlaintext(t *testing.T) {
+	plaintext := []byte(`{"Password": "hunter2"}`)
+	for name, encrypter := range map[string]*Encrypter{"Configured": newLocalEncrypter(t), "Nil": nil} {
+		t.Run(name, func(t *testing.T) {
+			decrypted, err := encrypter.Decrypt(context.Background(), plaintext)
+			if err != nil {
+				t.Fatalf("Failed to decrypt: %v", err)
+			}
+			if !bytes.Equal(decrypted, plaintext) {
+				t.Fatalf("Expected plaintext to be returned as is, got %s", decrypted)
+			}
+		})
+	}
+}
+
+func TestDecryptFailures(t *testing.T) {
+	ctx := context.Background()
+	encrypted, err := newLocalEncrypter(t).Encrypt(ctx, []byte("secret"))
+	if err != nil {
+		t.Fatalf("Failed to encrypt: %v", err)
+	}
+	if _, err := newLocalEncrypter(t).Decrypt(ctx, encrypted); err == nil {
+		t.Fatalf("Expected decrypting with a different key to fail")
+	}
+	var unconfigured *Encrypter
+	if _, err := unconfigured.Decrypt(ctx, encrypted); err == nil {
+		t.Fatalf("Expected decrypting without a key to fail")
+	}
+	corrupted := append([]byte{}, envelopePrefix...)
+	corrupted = append(corrupted, []byte("not json")...)
+	if _, err := newLocalEncrypter(t).Decrypt(ctx, corrupted); err == nil {
+		t.Fatalf("Expected decrypting a corrupted value to fail")
+	}
+}
+
+func TestFromEnv(t *testing.T) {
+	ctx := context.Background()
+	encrypter, err := FromEnv(ctx)
+	if err != nil {
+		t.Fatalf("Failed to create encrypter: %v", err)
+	}
+	if encrypter != nil {
+		t.Fatalf("Expected no encrypter without a key provider")
+	}
+
+	t.Setenv(config.EnvConfigEncryptionKeyProvider, string(LocalKeyProvider))
+	if _, err := FromEnv(ctx); err == nil {
+		t.Fatalf("Expected an error without a local key")
+	}
+	t.Setenv(config.EnvConfigEncryptionLocalKey, base64.StdEncoding.EncodeToString([]byte("short")))
+	if _, err := FromEnv(ctx); err == nil {
+		t.Fatalf("Expected an error with a short local key")
+	}
+	t.Setenv(config.EnvConfigEncryptionLocalKey, newLocalKey(t))
+	encrypter, err = FromEnv(ctx)
+	if err != nil {
+		t.Fatalf("Failed to create encrypter: %v", err)
+	}
+	if encrypter == nil {
+		t.Fatalf("Expected an encrypter with a local key")
+	}
+
+	t.Setenv(config.EnvConfigEncryptionKeyProvider, "vault")
+	if _, err := FromEnv(ctx); err == nil {
+		t.Fatalf("Expected an error with an unknown key provider")
+	}
+}
@@ -0,0 +1,141 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+//
+// Copyright 2024 FeatureForm Inc.
+//
+
+package encryption
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+
+	gcpkms "cloud.google.com/go/kms/apiv1"
+	"cloud.google.com/go/kms/apiv1/kmspb"
+	awsv2config "github.com/aws/aws-sdk-go-v2/config"
+	awskms "github.com/aws/aws-sdk-go-v2/service/kms"
+
+	"github.com/featureform/config"
+	"github.com/featureform/fferr"
+)
+
+// LocalKeyWrapper wraps data keys with a key from the environment. It's meant
+// for development, since the key is stored alongside the deployment.
+type LocalKeyWrapper struct {
+	key []byte
+}
+
+// NewLocalKeyWrapper creates a LocalKeyWrapper from a base64 encoded 32 byte
+// key, like the output of `openssl rand -base64 32`.
+func NewLocalKeyWrapper(encodedKey string) (*LocalKeyWrapper, error) {
+	if encodedKey == "" {
+		return nil, fferr.NewMissingConfigEnv(config.EnvConfigEncryptionLocalKey)
+	}
+	key, err := base64.StdEncoding.DecodeString(encodedKey)
+	if err != nil || len(key) != dataKeySize {
+		return nil, fferr.NewInvalidConfigf("%s must be a base64 encoded %d byte key", config.EnvConfigEncryptionLocalKey, dataKeySize)
+	}
+	return &LocalKeyWrapper{key: key}, nil
+}
+
+func (w *LocalKeyWrapper) Provider() KeyProvider {
+	return LocalKeyProvider
+}
+
+func (w *LocalKeyWrapper) Wrap(ctx context.Context, dataKey []byte) ([]byte, error) {
+	nonce, ciphertext, err := seal(w.key, dataKey)
+	if err != nil {
+		return nil, err
+	}
+	return append(nonce, ciphertext...), nil
+}
+
+func (w *LocalKeyWrapper) Unwrap(ctx context.Context, wrapped []byte) ([]byte, error) {
+	aead, err := newGCM(w.key)
+	if err != nil {
+		return nil, err
+	}
+	if len(wrapped) < aead.NonceSize() {
+		return nil, fferr.NewInternalErrorf("wrapped key is too short")
+	}
+	return open(w.key, wrapped[:aead.NonceSize()], wrapped[aead.NonceSize():])
+}
+
+// AWSKMSKeyWrapper wraps data keys with a symmetric AWS KMS key. Credentials
+// and region come from the default AWS config chain.
+type AWSKMSKeyWrapper struct {
+	client *awskms.Client
+	keyID  string
+}
+
+func NewAWSKMSKeyWrapper(ctx context.Context, keyID string) (*AWSKMSKeyWrapper, error) {
+	if keyID == "" {
+		return nil, fferr.NewMissingConfigEnv(config.EnvConfigEncryptionKMSKeyID)
+	}
+	cfg, err := awsv2config.LoadDefaultConfig(ctx)
+	if err != nil {
+		return nil, fferr.NewInternalError(fmt.Errorf("failed to load AWS config: %w", err))
+	}
+	return &AWSKMSKeyWrapper{client: awskms.NewFromConfig(cfg), keyID: keyID}, nil
+}
+
+func (w *AWSKMSKeyWrapper) Provider() KeyProvider {
+	return AWSKMSKeyProvider
+}
+
+func (w *AWSKMSKeyWrapper) Wrap(ctx context.Context, dataKey []byte) ([]byte, error) {
+	resp, err := w.client.Encrypt(ctx, &awskms.EncryptInput{KeyId: &w.keyID, Plaintext: dataKey})
+	if err != nil {
+		return nil, fferr.NewExecutionError("AWS KMS", err)
+	}
+	return resp.CiphertextBlob, nil
+}
+
+func (w *AWSKMSKeyWrapper) Unwrap(ctx context.Context, wrapped []byte) ([]byte, error) {
+	resp, err := w.client.Decrypt(ctx, &awskms.DecryptInput{KeyId: &w.keyID, CiphertextBlob: wrapped})
+	if err != nil {
+		return nil, fferr.NewExecutionError("AWS KMS", err)
+	}
+	return resp.Plaintext, nil
+}
+
+// GCPKMSKeyWrapper wraps data keys with a GCP Cloud KMS key, named like
+// projects/<project>/locations/<location>/keyRings/<ring>/cryptoKeys/<key>.
+// It uses application default credentials.
+type GCPKMSKeyWrapper struct {
+	client  *gcpkms.KeyManagementClient
+	keyName string
+}
+
+func NewGCPKMSKeyWrapper(ctx context.Context, keyName string) (*GCPKMSKeyWrapper, error) {
+	if keyName == "" {
+		return nil, fferr.NewMissingConfigEnv(config.EnvConfigEncryptionKMSKeyID)
+	}
+	client, err := gcpkms.NewKeyManagementClient(ctx)
+	if err != nil {
+		return nil, fferr.NewInternalError(fmt.Errorf("failed to create GCP KMS client: %w", err))
+	}
+	return &GCPKMSKeyWrapper{client: client, keyName: keyName}, nil
+}
+
+func (w *GCPKMSKeyWrapper) Provider() KeyProvider {
+	return GCPKMSKeyProvider
+}
+
+func (w *GCPKMSKeyWrapper) Wrap(ctx context.Context, dataKey []byte) ([]byte, error) {
+	resp, err := w.client.Encrypt(ctx, &kmspb.EncryptRequest{Name: w.keyName, Plaintext: dataKey})
+	if err != nil {
+		return nil, fferr.NewExecutionError("GCP KMS", err)
+	}
+	return resp.Ciphertext, nil
+}
+
+func (w *GCPKMSKeyWrapper) Unwrap(ctx context.Context, wrapped []byte) ([]byte, error) {
+	resp, err := w.client.Decrypt(ctx, &kmspb.DecryptRequest{Name: w.keyName, Ciphertext: wrapped})
+	if err != nil {
+		return nil, fferr.NewExecutionError("GCP KMS", err)
+	}
+	return resp.Plaintext, nil
+}
@@ -0,0 +1,188 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+//
+// Copyright 2024 FeatureForm Inc.
+//
+
+// Package events publishes metadata change events, like a resource being
+// created or changing status, to an external sink so catalogs can follow
+// along. Delivery is best-effort: events are queued and sent in the
+// background, and they're dropped if the queue is full or the sink keeps
+// failing.
+package events
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/avast/retry-go/v4"
+
+	"github.com/featureform/config"
+	"github.com/featureform/fferr"
+	"github.com/featureform/helpers"
+	"github.com/featureform/logging"
+)
+
+// SinkType is the name of a Sink implementation, selected with
+// FF_METADATA_EVENT_SINK.
+type SinkType string
+
+const (
+	WebhookSinkType SinkType = "webhook"
+	KafkaSinkType   SinkType = "kafka"
+	SNSSinkType     SinkType = "sns"
+)
+
+type Operation string
+
+const (
+	CreateOperation       Operation = "create"
+	UpdateOperation       Operation = "update"
+	StatusChangeOperation Operation = "status_change"
+)
+
+// Event describes a change to a resource.
+type Event struct {
+	ResourceType string    `json:"resource_type"`
+	Name         string    `json:"name"`
+	Variant      string    `json:"variant,omitempty"`
+	Operation    Operation `json:"operation"`
+	// Status is only set on status changes.
+	Status    string    `json:"status,omitempty"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// Sink delivers events to an external system.
+type Sink interface {
+	Publish(ctx context.Context, event Event) error
+	Close() error
+}
+
+const (
+	defaultQueueSize   = 1000
+	defaultAttempts    = 3
+	defaultRetryDelay  = 500 * time.Millisecond
+	defaultSendTimeout = 10 * time.Second
+)
+
+// Emitter queues events and publishes them to a Sink from a background
+// goroutine, so emitting never blocks the caller. A nil Emitter drops every
+// event, which is how event publishing is turned off.
+type Emitter struct {
+	sink       Sink
+	queue      chan Event
+	logger     logging.Logger
+	attempts   uint
+	retryDelay time.Duration
+	// mu guards closed so Emit never sends on the closed queue.
+	mu     sync.RWMutex
+	closed bool
+	done   chan struct{}
+}
+
+// NewEmitter starts an Emitter that holds at most queueSize unsent events.
+func NewEmitter(sink Sink, queueSize int, logger logging.Logger) *Emitter {
+	if queueSize <= 0 {
+		queueSize = defaultQueueSize
+	}
+	emitter := &Emitter{
+		sink:       sink,
+		queue:      make(chan Event, queueSize),
+		logger:     logger,
+		attempts:   defaultAttempts,
+		retryDelay: defaultRetryDelay,
+		done:       make(chan struct{}),
+	}
+	go emitter.run()
+	return emitter
+}
+
+// FromEnv creates an Emitter from the FF_METADATA_EVENT_* environment
+// variables. It returns nil if no sink is configured.
+func FromEnv(ctx context.Context, logger logging.Logger) (*Emitter, error) {
+	sinkType := SinkType(helpers.GetEnv(config.EnvMetadataEventSink, ""))
+	var sink Sink
+	var err error
+	switch sinkType {
+	case "":
+		return nil, nil
+	case WebhookSinkType:
+		sink, err = NewWebhookSink(helpers.GetEnv(config.EnvMetadataEventWebhookURL, ""))
+	case KafkaSinkType:
+		brokers := strings.Split(helpers.GetEnv(config.EnvMetadataEventKafkaBrokers, ""), ",")
+		sink, err = NewKafkaSink(brokers, helpers.GetEnv(config.EnvMetadataEventKafkaTopic, ""))
+	case SNSSinkType:
+		sink, err = NewSNSSink(ctx, helpers.GetEnv(config.EnvMetadataEventSNSTopicARN, ""))
+	default:
+		possible := []SinkType{WebhookSinkType, KafkaSinkType, SNSSinkType}
+		return nil, fferr.NewInvalidConfigEnv(config.EnvMetadataEventSink, sinkType, possible)
+	}
+	if err != nil {
+		return nil, err
+	}
+	logger.Infow("Publishing metadata events", "sink", sinkType)
+	return NewEmitter(sink, helpers.GetEnvInt(config.EnvMetadataEventQueueSize, defaultQueueSize), logger), nil
+}
+
+// Emit queues an event to be published. If the queue is full the event is
+// dropped rather than making the caller wait.
+func (e *Emitter) Emit(event Event) {
+	if e == nil {
+		return
+	}
+	if event.Timestamp.IsZero() {
+		event.Timestamp = time.Now().UTC()
+	}
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	if e.closed {
+		return
+	}
+	select {
+	case e.queue <- event:
+	default:
+		e.logger.Warnw("Metadata event queue is full, dropping event", "event", event)
+	}
+}
+
+// Close publishes the events that are already queued and closes the sink.
+// Events emitted after Close are dropped.
+func (e *Emitter) Close() error {
+	if e == nil {
+		return nil
+	}
+	e.mu.Lock()
+	if !e.closed {
+		e.closed = true
+		close(e.queue)
+	}
+	e.mu.Unlock()
+	<-e.done
+	return e.sink.Close()
+}
+
+func (e *Emitter) run() {
+	defer close(e.done)
+	for event := range e.queue {
+		if err := e.publish(event); err != nil {
+			e.logger.Errorw("Failed to publish metadata event", "event", event, "error", err)
+		}
+	}
+}
+
+func (e *Emitter) publish(event Event) error {
+	return retry.Do(
+		func() error {
+			ctx, cancel := context.WithTimeout(context.Background(), defaultSendTimeout)
+			defer cancel()
+			return e.sink.Publish(ctx, event)
+		},
+		retry.Attempts(e.attempts),
+		retry.Delay(e.retryDelay),
+		retry.DelayType(retry.BackOffDelay),
+		retry.LastErrorOnly(true),
+	)
+}
@@ -0,0 +1,152 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+//
+// Copyright 2024 FeatureForm Inc.
+//
+
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/featureform/logging"
+)
+
+type recordingSink struct {
+	mu       sync.Mutex
+	events   []Event
+	failures int
+	block    chan struct{}
+}
+
+func (s *recordingSink) Publish(ctx context.Context, event Event) error {
+	if s.block != nil {
+		<-s.block
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.failures > 0 {
+		s.failures--
+		return fmt.Errorf("unavailable")
+	}
+	s.events = append(s.events, event)
+	return nil
+}
+
+func (s *recordingSink) Close() error {
+	return nil
+}
+
+func newTestEmitter(t *testing.T, sink Sink, queueSize int) *Emitter {
+	emitter := NewEmitter(sink, queueSize, logging.NewTestLogger(t))
+	emitter.retryDelay = time.Millisecond
+	return emitter
+}
+
+func TestEmitterRetries(t *testing.T) {
+	sink := &recordingSink{failures: 2}
+	emitter := newTestEmitter(t, sink, 10)
+	emitter.Emit(Event{ResourceType: "FEATURE_VARIANT", Name: "avg", Variant: "v1", Operation: CreateOperation})
+	if err := emitter.Close(); err != nil {
+		t.Fatalf("Failed to close emitter: %v", err)
+	}
+	if len(sink.events) != 1 {
+		t.Fatalf("Expected 1 event after retrying, got %d", len(sink.events))
+	}
+	if sink.events[0].Timestamp.IsZero() {
+		t.Fatalf("Expected the event to be timestamped")
+	}
+}
+
+func TestEmitterDropsWhenFull(t *testing.T) {
+	sink := &recordingSink{block: make(chan struct{})}
+	emitter := newTestEmitter(t, sink, 1)
+	// The first event is taken off the queue by the worker, which blocks on
+	// the sink. The second fills the queue and the rest are dropped.
+	for i := 0; i < 5; i++ {
+		emitter.Emit(Event{Name: fmt.Sprintf("event-%d", i), Operation: UpdateOperation})
+		time.Sleep(10 * time.Millisecond)
+	}
+	close(sink.block)
+	if err := emitter.Close(); err != nil {
+		t.Fatalf("Failed to close emitter: %v", err)
+	}
+	if len(sink.events) != 2 {
+		t.Fatalf("Expected 2 events to be published, got %d: %v", len(sink.events), sink.events)
+	}
+	// Emitting after Close is a no-op.
+	emitter.Emit(Event{Name: "late"})
+}
+
+func TestNilEmitter(t *testing.T) {
+	var emitter *Emitter
+	emitter.Emit(Event{Name: "ignored"})
+	if err := emitter.Close(); err != nil {
+		t.Fatalf("Failed to close nil emitter: %v", err)
+	}
+}
+
+func TestWebhookSink(t *testing.T) {
+	received := make(chan Event, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var event Event
+		if err := json.NewDecoder(r.Body).Decode(&event); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		received <- event
+	}))
+	defer server.Close()
+	sink, err := NewWebhookSink(server.URL)
+	if err != nil {
+		t.Fatalf("Failed to create webhook sink: %v", err)
+	}
+	event := Event{ResourceType: "SOURCE_VARIANT", Name: "transactions", Variant: "v1", Operation: StatusChangeOperation, Status: "READY"}
+	if err := sink.Publish(context.Background(), event); err != nil {
+		t.Fatalf("Failed to publish: %v", err)
+	}
+	if got := <-received; got != event {
+		t.Fatalf("Expected %v, got %v", event, got)
+	}
+
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer failing.Close()
+	sink, err = NewWebhookSink(failing.URL)
+	if err != nil {
+		t.Fatalf("Failed to create webhook sink: %v", err)
+	}
+	if err := sink.Publish(context.Background(), event); err == nil {
+		t.Fatalf("Expected an error for a non-2xx response")
+	}
+}
+
+func TestFromEnv(t *testing.T) {
+	logger := logging.NewTestLogger(t)
+	emitter, err := FromEnv(context.Background(), logger)
+	if err != nil || emitter != nil {
+		t.Fatalf("Expected no emitter by default, got %v, %v", emitter, err)
+	}
+	t.Setenv("FF_METADATA_EVENT_SINK", "carrier_pigeon")
+	if _, err := FromEnv(context.Background(), logger); err == nil {
+		t.Fatalf("Expected an error for an unknown sink")
+	}
+	t.Setenv("FF_METADATA_EVENT_SINK", "kafka")
+	if _, err := FromEnv(context.Background(), logger); err == nil {
+		t.Fatalf("Expected an error for kafka without brokers")
+	}
+	t.Setenv("FF_METADATA_EVENT_SINK", "sns")
+	t.Setenv("FF_METADATA_EVENT_SNS_TOPIC_ARN", "not-an-arn")
+	if _, err := FromEnv(context.Background(), logger); err == nil {
+		t.Fatalf("Expected an error for an invalid topic ARN")
+	}
+}
@@ -0,0 +1,187 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+//
+// Copyright 2024 FeatureForm Inc.
+//
+
+package events
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	v4 "github.com/aws/aws-sdk-go-v2/aws/signer/v4"
+	awsv2config "github.com/aws/aws-sdk-go-v2/config"
+	"github.com/segmentio/kafka-go"
+
+	"github.com/featureform/config"
+	"github.com/featureform/fferr"
+)
+
+// WebhookSink POSTs each event as JSON to a URL. Any non-2xx response is
+// treated as a failure so it's retried.
+type WebhookSink struct {
+	url    string
+	client *http.Client
+}
+
+func NewWebhookSink(endpoint string) (*WebhookSink, error) {
+	if endpoint == "" {
+		return nil, fferr.NewMissingConfigEnv(config.EnvMetadataEventWebhookURL)
+	}
+	return &WebhookSink{url: endpoint, client: &http.Client{Timeout: defaultSendTimeout}}, nil
+}
+
+func (s *WebhookSink) Publish(ctx context.Context, event Event) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return fferr.NewInternalError(err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(body))
+	if err != nil {
+		return fferr.NewInternalError(err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	return doRequest(s.client, req)
+}
+
+func (s *WebhookSink) Close() error {
+	return nil
+}
+
+// KafkaSink writes each event as a JSON message to a topic, keyed by the
+// resource so a resource's events stay in order within a partition.
+type KafkaSink struct {
+	writer *kafka.Writer
+}
+
+func NewKafkaSink(brokers []string, topic string) (*KafkaSink, error) {
+	addrs := make([]string, 0, len(brokers))
+	for _, broker := range brokers {
+		if broker = strings.TrimSpace(broker); broker != "" {
+			addrs = append(addrs, broker)
+		}
+	}
+	if len(addrs) == 0 {
+		return nil, fferr.NewMissingConfigEnv(config.EnvMetadataEventKafkaBrokers)
+	}
+	if topic == "" {
+		return nil, fferr.NewMissingConfigEnv(config.EnvMetadataEventKafkaTopic)
+	}
+	writer := &kafka.Writer{
+		Addr:         kafka.TCP(addrs...),
+		Topic:        topic,
+		Balancer:     &kafka.Hash{},
+		RequiredAcks: kafka.RequireOne,
+		// The emitter already retries, so don't retry inside the writer too.
+		MaxAttempts: 1,
+	}
+	return &KafkaSink{writer: writer}, nil
+}
+
+func (s *KafkaSink) Publish(ctx context.Context, event Event) error {
+	value, err := json.Marshal(event)
+	if err != nil {
+		return fferr.NewInternalError(err)
+	}
+	key := fmt.Sprintf("%s.%s.%s", event.ResourceType, event.Name, event.Variant)
+	if err := s.writer.WriteMessages(ctx, kafka.Message{Key: []byte(key), Value: value}); err != nil {
+		return fferr.NewConnectionError("kafka", err)
+	}
+	return nil
+}
+
+func (s *KafkaSink) Close() error {
+	return s.writer.Close()
+}
+
+// SNSSink publishes each event as a JSON message to an SNS topic. It calls
+// the SNS query API directly and signs requests with credentials from the
+// default AWS config chain. The region is taken from the topic ARN.
+type SNSSink struct {
+	topicARN    string
+	region      string
+	endpoint    string
+	credentials aws.CredentialsProvider
+	signer      *v4.Signer
+	client      *http.Client
+}
+
+func NewSNSSink(ctx context.Context, topicARN string) (*SNSSink, error) {
+	if topicARN == "" {
+		return nil, fferr.NewMissingConfigEnv(config.EnvMetadataEventSNSTopicARN)
+	}
+	// arn:aws:sns:<region>:<account>:<topic>
+	parts := strings.Split(topicARN, ":")
+	if len(parts) != 6 || parts[2] != "sns" {
+		return nil, fferr.NewInvalidConfigf("%s is not an SNS topic ARN: %s", config.EnvMetadataEventSNSTopicARN, topicARN)
+	}
+	cfg, err := awsv2config.LoadDefaultConfig(ctx)
+	if err != nil {
+		return nil, fferr.NewInternalError(fmt.Errorf("failed to load AWS config: %w", err))
+	}
+	return &SNSSink{
+		topicARN:    topicARN,
+		region:      parts[3],
+		endpoint:    fmt.Sprintf("https://sns.%s.amazonaws.com/", parts[3]),
+		credentials: cfg.Credentials,
+		signer:      v4.NewSigner(),
+		client:      &http.Client{Timeout: defaultSendTimeout},
+	}, nil
+}
+
+func (s *SNSSink) Publish(ctx context.Context, event Event) error {
+	message, err := json.Marshal(event)
+	if err != nil {
+		return fferr.NewInternalError(err)
+	}
+	form := url.Values{
+		"Action":   {"Publish"},
+		"Version":  {"2010-03-31"},
+		"TopicArn": {s.topicARN},
+		"Message":  {string(message)},
+	}
+	body := form.Encode()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.endpoint, strings.NewReader(body))
+	if err != nil {
+		return fferr.NewInternalError(err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	creds, err := s.credentials.Retrieve(ctx)
+	if err != nil {
+		return fferr.NewConnectionError("sns", err)
+	}
+	payloadHash := sha256.Sum256([]byte(body))
+	if err := s.signer.SignHTTP(ctx, creds, req, hex.EncodeToString(payloadHash[:]), "sns", s.region, time.Now()); err != nil {
+		return fferr.NewInternalError(err)
+	}
+	return doRequest(s.client, req)
+}
+
+func (s *SNSSink) Close() error {
+	return nil
+}
+
+func doRequest(client *http.Client, req *http.Request) error {
+	resp, err := client.Do(req)
+	if err != nil {
+		return fferr.NewConnectionError(req.URL.Host, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fferr.NewExecutionError(req.URL.Host, fmt.Errorf("unexpected status %d: %s", resp.StatusCode, msg))
+	}
+	return nil
+}
@@ -0,0 +1,145 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+//
+// Copyright 2024 FeatureForm Inc.
+//
+
+// Package grpctls sets up mutual TLS between Featureform's gRPC services.
+// Each service uses the same certificate as a server and as a client, and
+// verifies its peers against a shared CA. When no certificates are
+// configured, connections fall back to plaintext for local development.
+package grpctls
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+
+	"github.com/featureform/config"
+	"github.com/featureform/fferr"
+	"github.com/featureform/helpers"
+)
+
+// Config is the paths to the PEM encoded certificate, private key and CA
+// bundle used for mTLS. ServerName overrides the name that clients expect
+// on the server's certificate, which defaults to the dialed host.
+type Config struct {
+	CertPath   string
+	KeyPath    string
+	CAPath     string
+	ServerName string
+}
+
+// FromEnv reads the TLS config from the GRPC_TLS_* environment variables.
+func FromEnv() Config {
+	return Config{
+		CertPath:   helpers.GetEnv(config.EnvGRPCTLSCertPath, ""),
+		KeyPath:    helpers.GetEnv(config.EnvGRPCTLSKeyPath, ""),
+		CAPath:     helpers.GetEnv(config.EnvGRPCTLSCAPath, ""),
+		ServerName: helpers.GetEnv(config.EnvGRPCTLSServerName, ""),
+	}
+}
+
+// Enabled returns true if any of the certificate paths are set.
+func (c Config) Enabled() bool {
+	return c.CertPath != "" || c.KeyPath != "" || c.CAPath != ""
+}
+
+func (c Config) Validate() error {
+	paths := []struct {
+		env  string
+		path string
+	}{
+		{config.EnvGRPCTLSCertPath, c.CertPath},
+		{config.EnvGRPCTLSKeyPath, c.KeyPath},
+		{config.EnvGRPCTLSCAPath, c.CAPath},
+	}
+	for _, p := range paths {
+		if p.path == "" {
+			return fferr.NewMissingConfigEnv(p.env)
+		}
+	}
+	return nil
+}
+
+// ServerOptions returns the options that make a gRPC server require clients
+// to present a certificate signed by the CA. It returns no options if TLS
+// isn't configured.
+func (c Config) ServerOptions() ([]grpc.ServerOption, error) {
+	if !c.Enabled() {
+		return nil, nil
+	}
+	cert, pool, err := c.load()
+	if err != nil {
+		return nil, err
+	}
+	tlsConfig := &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		ClientCAs:    pool,
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+		MinVersion:   tls.VersionTLS12,
+	}
+	return []grpc.ServerOption{grpc.Creds(credentials.NewTLS(tlsConfig))}, nil
+}
+
+// DialOption returns the transport credentials for connecting to a server
+// that uses ServerOptions. It's insecure if TLS isn't configured.
+func (c Config) DialOption() (grpc.DialOption, error) {
+	if !c.Enabled() {
+		return grpc.WithTransportCredentials(insecure.NewCredentials()), nil
+	}
+	cert, pool, err := c.load()
+	if err != nil {
+		return nil, err
+	}
+	tlsConfig := &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		RootCAs:      pool,
+		ServerName:   c.ServerName,
+		MinVersion:   tls.VersionTLS12,
+	}
+	return grpc.WithTransportCredentials(credentials.NewTLS(tlsConfig)), nil
+}
+
+// ProbeTLSConfig returns the TLS config for an HTTP server, like a health
+// check endpoint, that's called by clients without a certificate such as the
+// kubelet. It uses the same certificate as the gRPC server, but doesn't ask
+// for a client certificate. It returns nil if TLS isn't configured.
+func (c Config) ProbeTLSConfig() (*tls.Config, error) {
+	if !c.Enabled() {
+		return nil, nil
+	}
+	cert, _, err := c.load()
+	if err != nil {
+		return nil, err
+	}
+	return &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		MinVersion:   tls.VersionTLS12,
+	}, nil
+}
+
+func (c Config) load() (tls.Certificate, *x509.CertPool, error) {
+	if err := c.Validate(); err != nil {
+		return tls.Certificate{}, nil, err
+	}
+	cert, err := tls.LoadX509KeyPair(c.CertPath, c.KeyPath)
+	if err != nil {
+		return tls.Certificate{}, nil, fferr.NewInternalError(fmt.Errorf("could not load TLS certificate %s: %w", c.CertPath, err))
+	}
+	ca, err := os.ReadFile(c.CAPath)
+	if err != nil {
+		return tls.Certificate{}, nil, fferr.NewInternalError(fmt.Errorf("could not read TLS CA %s: %w", c.CAPath, err))
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(ca) {
+		return tls.Certificate{}, nil, fferr.NewInternalErrorf("TLS CA %s has no PEM encoded certificates", c.CAPath)
+	}
+	return cert, pool, nil
+}
@@ -0,0 +1,208 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+//
+// Copyright 2024 FeatureForm Inc.
+//
+
+package grpctls
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+// writeCerts writes a CA and a certificate it signed for localhost, usable by
+// both servers and clients, to dir.
+func writeCerts(t *testing.T, dir string) Config {
+	caKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("Failed to generate CA key: %v", err)
+	}
+	caTemplate := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test-ca"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}
+	caDER, err := x509.CreateCertificate(rand.Reader, caTemplate, caTemplate, &caKey.PublicKey, caKey)
+	if err != nil {
+		t.Fatalf("Failed to create CA certificate: %v", err)
+	}
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(2),
+		Subject:      pkix.Name{CommonName: "localhost"},
+		DNSNames:     []string{"localhost"},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, caTemplate, &key.PublicKey, caKey)
+	if err != nil {
+		t.Fatalf("Failed to create certificate: %v", err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatalf("Failed to marshal key: %v", err)
+	}
+	cfg := Config{
+		CertPath: filepath.Join(dir, "tls.crt"),
+		KeyPath:  filepath.Join(dir, "tls.key"),
+		CAPath:   filepath.Join(dir, "ca.crt"),
+	}
+	files := map[string]*pem.Block{
+		cfg.CertPath: {Type: "CERTIFICATE", Bytes: der},
+		cfg.KeyPath:  {Type: "EC PRIVATE KEY", Bytes: keyDER},
+		cfg.CAPath:   {Type: "CERTIFICATE", Bytes: caDER},
+	}
+	for path, block := range files {
+		if err := os.WriteFile(path, pem.EncodeToMemory(block), 0600); err != nil {
+			t.Fatalf("Failed to write %s: %v", path, err)
+		}
+	}
+	return cfg
+}
+
+func startHealthServer(t *testing.T, opts ...grpc.ServerOption) string {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	server := grpc.NewServer(opts...)
+	healthpb.RegisterHealthServer(server, health.NewServer())
+	go server.Serve(lis)
+	t.Cleanup(server.Stop)
+	return lis.Addr().String()
+}
+
+func checkHealth(addr string, opt grpc.DialOption) error {
+	conn, err := grpc.NewClient(addr, opt)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	_, err = healthpb.NewHealthClient(conn).Check(ctx, &healthpb.HealthCheckRequest{})
+	return err
+}
+
+func TestMutualTLS(t *testing.T) {
+	cfg := writeCerts(t, t.TempDir())
+	serverOpts, err := cfg.ServerOptions()
+	if err != nil {
+		t.Fatalf("Failed to create server options: %v", err)
+	}
+	addr := startHealthServer(t, serverOpts...)
+
+	dialOpt, err := cfg.DialOption()
+	if err != nil {
+		t.Fatalf("Failed to create dial option: %v", err)
+	}
+	if err := checkHealth(addr, dialOpt); err != nil {
+		t.Fatalf("Failed to call server over mTLS: %v", err)
+	}
+	if err := checkHealth(addr, grpc.WithTransportCredentials(insecure.NewCredentials())); err == nil {
+		t.Fatalf("Expected a plaintext call to fail")
+	}
+	pool := x509.NewCertPool()
+	ca, err := os.ReadFile(cfg.CAPath)
+	if err != nil {
+		t.Fatalf("Failed to read CA: %v", err)
+	}
+	pool.AppendCertsFromPEM(ca)
+	noClientCert := credentials.NewTLS(&tls.Config{RootCAs: pool})
+	if err := checkHealth(addr, grpc.WithTransportCredentials(noClientCert)); err == nil {
+		t.Fatalf("Expected a call without a client certificate to fail")
+	}
+}
+
+func TestInsecureFallback(t *testing.T) {
+	cfg := Config{}
+	serverOpts, err := cfg.ServerOptions()
+	if err != nil {
+		t.Fatalf("Failed to create server options: %v", err)
+	}
+	if len(serverOpts) != 0 {
+		t.Fatalf("Expected no server options without TLS, got %d", len(serverOpts))
+	}
+	addr := startHealthServer(t, serverOpts...)
+	dialOpt, err := cfg.DialOption()
+	if err != nil {
+		t.Fatalf("Failed to create dial option: %v", err)
+	}
+	if err := checkHealth(addr, dialOpt); err != nil {
+		t.Fatalf("Failed to call server without TLS: %v", err)
+	}
+}
+
+func TestProbeTLSConfig(t *testing.T) {
+	cfg := writeCerts(t, t.TempDir())
+	tlsConfig, err := cfg.ProbeTLSConfig()
+	if err != nil {
+		t.Fatalf("Failed to create probe TLS config: %v", err)
+	}
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	server.TLS = tlsConfig
+	server.StartTLS()
+	defer server.Close()
+
+	pool := x509.NewCertPool()
+	ca, err := os.ReadFile(cfg.CAPath)
+	if err != nil {
+		t.Fatalf("Failed to read CA: %v", err)
+	}
+	pool.AppendCertsFromPEM(ca)
+	client := &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{RootCAs: pool}}}
+	resp, err := client.Get(server.URL)
+	if err != nil {
+		t.Fatalf("Expected a call without a client certificate to succeed: %v", err)
+	}
+	resp.Body.Close()
+
+	if tlsConfig, err := (Config{}).ProbeTLSConfig(); err != nil || tlsConfig != nil {
+		t.Fatalf("Expected no TLS config without certificates, got %v: %v", tlsConfig, err)
+	}
+}
+
+func TestPartialConfig(t *testing.T) {
+	cfg := writeCerts(t, t.TempDir())
+	cfg.KeyPath = ""
+	if _, err := cfg.ServerOptions(); err == nil {
+		t.Fatalf("Expected an error creating server options without a key")
+	}
+	if _, err := cfg.DialOption(); err == nil {
+		t.Fatalf("Expected an error creating a dial option without a key")
+	}
+}
@@ -0,0 +1,235 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+//
+// Copyright 2024 FeatureForm Inc.
+//
+
+package interceptors
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v5"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+
+	"github.com/featureform/config"
+	"github.com/featureform/fferr"
+	"github.com/featureform/helpers"
+)
+
+const authorizationHeader = "authorization"
+
+// Identity is the authenticated caller of a request.
+type Identity struct {
+	Subject string
+}
+
+type identityKey struct{}
+
+func ContextWithIdentity(ctx context.Context, identity Identity) context.Context {
+	return context.WithValue(ctx, identityKey{}, identity)
+}
+
+// IdentityFromContext returns the caller attached by the auth interceptor. It's
+// false for requests that didn't have a token.
+func IdentityFromContext(ctx context.Context) (Identity, bool) {
+	identity, ok := ctx.Value(identityKey{}).(Identity)
+	return identity, ok
+}
+
+// Authenticator validates the bearer token sent with a request.
+type Authenticator interface {
+	// Enabled is false if requests aren't authenticated at all.
+	Enabled() bool
+	Authenticate(token string) (Identity, error)
+}
+
+// NoopAuthenticator is used when no token validation is configured. It
+// attaches no identity, and authorization checks are skipped.
+type NoopAuthenticator struct{}
+
+func (NoopAuthenticator) Enabled() bool {
+	return false
+}
+
+func (NoopAuthenticator) Authenticate(token string) (Identity, error) {
+	return Identity{}, nil
+}
+
+type JWTConfig struct {
+	// Secret verifies HMAC signed tokens.
+	Secret []byte
+	// PublicKeyPEM verifies RSA or ECDSA signed tokens.
+	PublicKeyPEM []byte
+	// Issuer and Audience are checked against the token's claims if set.
+	Issuer   string
+	Audience string
+}
+
+// JWTAuthenticator validates JWTs and uses their subject claim as the
+// caller's identity. Tokens must have an expiry.
+type JWTAuthenticator struct {
+	key     interface{}
+	methods []string
+	options []jwt.ParserOption
+}
+
+func NewJWTAuthenticator(cfg JWTConfig) (*JWTAuthenticator, error) {
+	auth := &JWTAuthenticator{}
+	switch {
+	case len(cfg.Secret) > 0 && len(cfg.PublicKeyPEM) > 0:
+		return nil, fferr.NewInvalidConfigf("only one of a JWT secret or public key can be set")
+	case len(cfg.Secret) > 0:
+		auth.key = cfg.Secret
+		auth.methods = []string{"HS256", "HS384", "HS512"}
+	case len(cfg.PublicKeyPEM) > 0:
+		if key, err := jwt.ParseRSAPublicKeyFromPEM(cfg.PublicKeyPEM); err == nil {
+			auth.key = key
+			auth.methods = []string{"RS256", "RS384", "RS512", "PS256", "PS384", "PS512"}
+		} else if key, err := jwt.ParseECPublicKeyFromPEM(cfg.PublicKeyPEM); err == nil {
+			auth.key = key
+			auth.methods = []string{"ES256", "ES384", "ES512"}
+		} else {
+			return nil, fferr.NewInvalidConfigf("JWT public key must be a PEM encoded RSA or ECDSA key")
+		}
+	default:
+		return nil, fferr.NewInvalidConfigf("a JWT secret or public key is required")
+	}
+	auth.options = []jwt.ParserOption{jwt.WithValidMethods(auth.methods), jwt.WithExpirationRequired()}
+	if cfg.Issuer != "" {
+		auth.options = append(auth.options, jwt.WithIssuer(cfg.Issuer))
+	}
+	if cfg.Audience != "" {
+		auth.options = append(auth.options, jwt.WithAudience(cfg.Audience))
+	}
+	return auth, nil
+}
+
+func (auth *JWTAuthenticator) Enabled() bool {
+	return true
+}
+
+func (auth *JWTAuthenticator) Authenticate(token string) (Identity, error) {
+	keyFn := func(*jwt.Token) (interface{}, error) {
+		return auth.key, nil
+	}
+	parsed, err := jwt.Parse(token, keyFn, auth.options...)
+	if err != nil {
+		return Identity{}, fferr.NewUnauthenticatedError(fmt.Errorf("invalid token: %w", err))
+	}
+	subject, err := parsed.Claims.GetSubject()
+	if err != nil || subject == "" {
+		return Identity{}, fferr.NewUnauthenticatedErrorf("token is missing a subject")
+	}
+	return Identity{Subject: subject}, nil
+}
+
+// AuthenticatorFromEnv creates a JWTAuthenticator from the FF_AUTH_JWT_*
+// environment variables, or a NoopAuthenticator if neither a secret nor a
+// public key is set.
+func AuthenticatorFromEnv() (Authenticator, error) {
+	cfg := JWTConfig{
+		Secret:   []byte(helpers.GetEnv(config.EnvAuthJWTSecret, "")),
+		Issuer:   helpers.GetEnv(config.EnvAuthJWTIssuer, ""),
+		Audience: helpers.GetEnv(config.EnvAuthJWTAudience, ""),
+	}
+	if path := helpers.GetEnv(config.EnvAuthJWTPublicKeyPath, ""); path != "" {
+		key, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fferr.NewInvalidConfigf("could not read JWT public key %s: %v", path, err)
+		}
+		cfg.PublicKeyPEM = key
+	}
+	if len(cfg.Secret) == 0 && len(cfg.PublicKeyPEM) == 0 {
+		return NoopAuthenticator{}, nil
+	}
+	return NewJWTAuthenticator(cfg)
+}
+
+// authenticate attaches the caller's identity to the context if the request
+// has a bearer token. Requests without one are let through without an
+// identity, so health checks and reads still work; mutations check for it.
+func authenticate(ctx context.Context, auth Authenticator) (context.Context, error) {
+	if !auth.Enabled() {
+		return ctx, nil
+	}
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return ctx, nil
+	}
+	values := md.Get(authorizationHeader)
+	if len(values) == 0 {
+		return ctx, nil
+	}
+	token, found := strings.CutPrefix(values[0], "Bearer ")
+	if !found {
+		return nil, fferr.NewUnauthenticatedErrorf("authorization header must be a bearer token")
+	}
+	identity, err := auth.Authenticate(token)
+	if err != nil {
+		return nil, err
+	}
+	return ContextWithIdentity(ctx, identity), nil
+}
+
+// UnaryServerAuthInterceptor authenticates each call with auth.
+func UnaryServerAuthInterceptor(auth Authenticator) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		ctx, err := authenticate(ctx, auth)
+		if err != nil {
+			return nil, fferr.FromErr(err).ToErr()
+		}
+		return handler(ctx, req)
+	}
+}
+
+type authenticatedServerStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *authenticatedServerStream) Context() context.Context {
+	return s.ctx
+}
+
+// StreamServerAuthInterceptor authenticates each stream with auth.
+func StreamServerAuthInterceptor(auth Authenticator) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, err := authenticate(ss.Context(), auth)
+		if err != nil {
+			return fferr.FromErr(err).ToErr()
+		}
+		return handler(srv, &authenticatedServerStream{ServerStream: ss, ctx: ctx})
+	}
+}
+
+// forwardAuthorization copies the caller's authorization header onto calls
+// made while handling its request, so a proxy like the API server acts as
+// the caller.
+func forwardAuthorization(ctx context.Context) context.Context {
+	incoming, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return ctx
+	}
+	values := incoming.Get(authorizationHeader)
+	if len(values) == 0 {
+		return ctx
+	}
+	if outgoing, ok := metadata.FromOutgoingContext(ctx); ok && len(outgoing.Get(authorizationHeader)) > 0 {
+		return ctx
+	}
+	return metadata.AppendToOutgoingContext(ctx, authorizationHeader, values[0])
+}
+
+func UnaryClientAuthForwardingInterceptor(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+	return invoker(forwardAuthorization(ctx), method, req, reply, cc, opts...)
+}
+
+func StreamClientAuthForwardingInterceptor(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+	return streamer(forwardAuthorization(ctx), desc, cc, method, opts...)
+}
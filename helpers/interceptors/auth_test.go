@@ -0,0 +1,205 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+//
+// Copyright 2024 FeatureForm Inc.
+//
+
+package interceptors
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+var testSecret = []byte("test-secret")
+
+func signHS256(t *testing.T, secret []byte, claims jwt.MapClaims) string {
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(secret)
+	if err != nil {
+		t.Fatalf("Failed to sign token: %v", err)
+	}
+	return token
+}
+
+func validClaims() jwt.MapClaims {
+	return jwt.MapClaims{
+		"sub": "alice",
+		"iss": "featureform",
+		"aud": "metadata",
+		"exp": time.Now().Add(time.Hour).Unix(),
+	}
+}
+
+func TestJWTAuthenticator(t *testing.T) {
+	auth, err := NewJWTAuthenticator(JWTConfig{Secret: testSecret, Issuer: "featureform", Audience: "metadata"})
+	if err != nil {
+		t.Fatalf("Failed to create authenticator: %v", err)
+	}
+	withClaim := func(key string, value interface{}) jwt.MapClaims {
+		claims := validClaims()
+		if value == nil {
+			delete(claims, key)
+		} else {
+			claims[key] = value
+		}
+		return claims
+	}
+	tests := []struct {
+		name    string
+		token   string
+		wantErr bool
+	}{
+		{"Valid", signHS256(t, testSecret, validClaims()), false},
+		{"Expired", signHS256(t, testSecret, withClaim("exp", time.Now().Add(-time.Hour).Unix())), true},
+		{"NoExpiry", signHS256(t, testSecret, withClaim("exp", nil)), true},
+		{"WrongIssuer", signHS256(t, testSecret, withClaim("iss", "someone-else")), true},
+		{"WrongAudience", signHS256(t, testSecret, withClaim("aud", "serving")), true},
+		{"NoSubject", signHS256(t, testSecret, withClaim("sub", nil)), true},
+		{"WrongSecret", signHS256(t, []byte("other-secret"), validClaims()), true},
+		{"Malformed", "not-a-token", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			identity, err := auth.Authenticate(tt.token)
+			if tt.wantErr {
+				if status.Code(err) != codes.Unauthenticated {
+					t.Fatalf("Expected an unauthenticated error, got: %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Failed to authenticate: %v", err)
+			}
+			if identity.Subject != "alice" {
+				t.Fatalf("Expected subject alice, got %s", identity.Subject)
+			}
+		})
+	}
+}
+
+func TestJWTAuthenticatorPublicKey(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+	der, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	if err != nil {
+		t.Fatalf("Failed to marshal public key: %v", err)
+	}
+	publicKey := pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})
+	auth, err := NewJWTAuthenticator(JWTConfig{PublicKeyPEM: publicKey})
+	if err != nil {
+		t.Fatalf("Failed to create authenticator: %v", err)
+	}
+	token, err := jwt.NewWithClaims(jwt.SigningMethodES256, validClaims()).SignedString(key)
+	if err != nil {
+		t.Fatalf("Failed to sign token: %v", err)
+	}
+	if _, err := auth.Authenticate(token); err != nil {
+		t.Fatalf("Failed to authenticate: %v", err)
+	}
+	// An HMAC token signed with the public key as its secret must not verify.
+	if _, err := auth.Authenticate(signHS256(t, publicKey, validClaims())); err == nil {
+		t.Fatalf("Expected a token with the wrong algorithm to fail")
+	}
+}
+
+func TestNewJWTAuthenticatorInvalidConfig(t *testing.T) {
+	configs := map[string]JWTConfig{
+		"Empty":        {},
+		"SecretAndKey": {Secret: testSecret, PublicKeyPEM: []byte("key")},
+		"BadKey":       {PublicKeyPEM: []byte("not a key")},
+	}
+	for name, cfg := range configs {
+		t.Run(name, func(t *testing.T) {
+			if _, err := NewJWTAuthenticator(cfg); err == nil {
+				t.Fatalf("Expected an error")
+			}
+		})
+	}
+}
+
+func TestAuthenticatorFromEnv(t *testing.T) {
+	auth, err := AuthenticatorFromEnv()
+	if err != nil {
+		t.Fatalf("Failed to create authenticator: %v", err)
+	}
+	if auth.Enabled() {
+		t.Fatalf("Expected authentication to be disabled by default")
+	}
+	t.Setenv("FF_AUTH_JWT_SECRET", string(testSecret))
+	auth, err = AuthenticatorFromEnv()
+	if err != nil {
+		t.Fatalf("Failed to create authenticator: %v", err)
+	}
+	if !auth.Enabled() {
+		t.Fatalf("Expected authentication to be enabled with a secret")
+	}
+}
+
+func TestUnaryServerAuthInterceptor(t *testing.T) {
+	auth, err := NewJWTAuthenticator(JWTConfig{Secret: testSecret})
+	if err != nil {
+		t.Fatalf("Failed to create authenticator: %v", err)
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		identity, _ := IdentityFromContext(ctx)
+		return identity.Subject, nil
+	}
+	tests := []struct {
+		name          string
+		auth          Authenticator
+		authorization string
+		wantSubject   string
+		wantCode      codes.Code
+	}{
+		{"Noop", NoopAuthenticator{}, "Bearer " + signHS256(t, testSecret, validClaims()), "", codes.OK},
+		{"Valid", auth, "Bearer " + signHS256(t, testSecret, validClaims()), "alice", codes.OK},
+		{"Anonymous", auth, "", "", codes.OK},
+		{"Invalid", auth, "Bearer not-a-token", "", codes.Unauthenticated},
+		{"NotBearer", auth, "Basic YWxpY2U6cGFzc3dvcmQ=", "", codes.Unauthenticated},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			if tt.authorization != "" {
+				ctx = metadata.NewIncomingContext(ctx, metadata.Pairs(authorizationHeader, tt.authorization))
+			}
+			interceptor := UnaryServerAuthInterceptor(tt.auth)
+			got, err := interceptor(ctx, nil, &grpc.UnaryServerInfo{}, handler)
+			if code := status.Code(err); code != tt.wantCode {
+				t.Fatalf("Expected code %s, got %s: %v", tt.wantCode, code, err)
+			}
+			if err == nil && got != tt.wantSubject {
+				t.Fatalf("Expected subject %q, got %q", tt.wantSubject, got)
+			}
+		})
+	}
+}
+
+func TestForwardAuthorization(t *testing.T) {
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(authorizationHeader, "Bearer token"))
+	invoker := func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+		md, _ := metadata.FromOutgoingContext(ctx)
+		if got := md.Get(authorizationHeader); len(got) != 1 || got[0] != "Bearer token" {
+			t.Fatalf("Expected the authorization header to be forwarded, got %v", got)
+		}
+		return nil
+	}
+	if err := UnaryClientAuthForwardingInterceptor(ctx, "", nil, nil, nil, invoker); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+}
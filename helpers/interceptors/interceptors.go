@@ -25,7 +25,7 @@ func UnaryServerErrorInterceptor(ctx context.Context, req interface{}, info *grp
 		var grpcErr fferr.Error
 		if errors.As(err, &grpcErr) {
 			logging.GlobalLogger.Errorw("GRPCError", "error", grpcErr, "method", info.FullMethod, "request", req, "response", h, "stack_trace", grpcErr.Stack())
-			return h, grpcErr.ToErr()
+			return h, fferr.ToErrWithCode(grpcErr)
 		}
 	}
 
@@ -40,7 +40,7 @@ func StreamServerErrorInterceptor(srv interface{}, ss grpc.ServerStream, info *g
 		var grpcErr fferr.Error
 		if errors.As(err, &grpcErr) {
 			logging.GlobalLogger.Errorw("GRPCError", "error", grpcErr, "method", info.FullMethod, "stackTrace", grpcErr.Stack())
-			return grpcErr.ToErr()
+			return fferr.ToErrWithCode(grpcErr)
 		}
 	}
 
@@ -8,9 +8,11 @@
 package interceptors
 
 import (
+	"fmt"
 	"reflect"
 	"testing"
 
+	"github.com/featureform/fferr"
 	"golang.org/x/net/context"
 	"google.golang.org/grpc"
 )
@@ -22,13 +24,46 @@ func TestUnaryServerErrorInterceptor(t *testing.T) {
 		info    *grpc.UnaryServerInfo
 		handler grpc.UnaryHandler
 	}
+	info := &grpc.UnaryServerInfo{FullMethod: "/featureform.Test/Method"}
 	tests := []struct {
-		name    string
-		args    args
-		want    interface{}
-		wantErr bool
+		name     string
+		args     args
+		want     interface{}
+		wantErr  bool
+		wantCode fferr.ErrorCode
 	}{
-		// TODO: Add test cases.
+		{
+			name: "Success",
+			args: args{context.Background(), "req", info, func(ctx context.Context, req interface{}) (interface{}, error) {
+				return "resp", nil
+			}},
+			want:     "resp",
+			wantCode: fferr.CodeUnknown,
+		},
+		{
+			name: "Resource Changed",
+			args: args{context.Background(), "req", info, func(ctx context.Context, req interface{}) (interface{}, error) {
+				return nil, fferr.NewResourceChangedError("name", "variant", fferr.FEATURE_VARIANT, nil)
+			}},
+			wantErr:  true,
+			wantCode: fferr.CodeResourceChanged,
+		},
+		{
+			name: "Wrapped Connection Error",
+			args: args{context.Background(), "req", info, func(ctx context.Context, req interface{}) (interface{}, error) {
+				return nil, fmt.Errorf("wrapped: %w", fferr.NewConnectionError("provider", fmt.Errorf("refused")))
+			}},
+			wantErr:  true,
+			wantCode: fferr.CodeProviderConnection,
+		},
+		{
+			name: "Plain Error",
+			args: args{context.Background(), "req", info, func(ctx context.Context, req interface{}) (interface{}, error) {
+				return nil, fmt.Errorf("plain")
+			}},
+			wantErr:  true,
+			wantCode: fferr.CodeUnknown,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -40,6 +75,9 @@ func TestUnaryServerErrorInterceptor(t *testing.T) {
 			if !reflect.DeepEqual(got, tt.want) {
 				t.Errorf("UnaryServerErrorInterceptor() got = %v, want %v", got, tt.want)
 			}
+			if code := fferr.GetErrorCode(err); code != tt.wantCode {
+				t.Errorf("UnaryServerErrorInterceptor() code = %v, want %v", code, tt.wantCode)
+			}
 		})
 	}
 }
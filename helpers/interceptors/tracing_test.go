@@ -0,0 +1,65 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+//
+// Copyright 2024 FeatureForm Inc.
+//
+
+package interceptors
+
+import (
+	"errors"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+func TestTracingInterceptorsPropagate(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	prevProvider, prevPropagator := otel.GetTracerProvider(), otel.GetTextMapPropagator()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	t.Cleanup(func() {
+		otel.SetTracerProvider(prevProvider)
+		otel.SetTextMapPropagator(prevPropagator)
+	})
+
+	method := "/featureform.serving.proto.Feature/FeatureServe"
+	handlerErr := errors.New("handler failed")
+	// The client's outgoing metadata is handed to the server as incoming metadata.
+	invoker := func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+		md, _ := metadata.FromOutgoingContext(ctx)
+		serverCtx := metadata.NewIncomingContext(context.Background(), md)
+		handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+			return nil, handlerErr
+		}
+		_, err := UnaryServerTracingInterceptor(serverCtx, req, &grpc.UnaryServerInfo{FullMethod: method}, handler)
+		return err
+	}
+	err := UnaryClientTracingInterceptor(context.Background(), method, nil, nil, nil, invoker)
+	if !errors.Is(err, handlerErr) {
+		t.Fatalf("Expected handler error, got %v", err)
+	}
+
+	spans := recorder.Ended()
+	if len(spans) != 2 {
+		t.Fatalf("Expected 2 spans, got %d", len(spans))
+	}
+	server, client := spans[0], spans[1]
+	if server.SpanContext().TraceID() != client.SpanContext().TraceID() {
+		t.Fatalf("Expected server span to be in the client's trace")
+	}
+	if server.Parent().SpanID() != client.SpanContext().SpanID() {
+		t.Fatalf("Expected server span to be a child of the client span")
+	}
+	if server.Status().Code != codes.Error {
+		t.Fatalf("Expected server span to record the error, got status %v", server.Status())
+	}
+}
@@ -0,0 +1,159 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+//
+// Copyright 2024 FeatureForm Inc.
+//
+
+package secrets
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	awsv2config "github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/secretsmanager"
+	gcpsecretmanager "google.golang.org/api/secretmanager/v1"
+
+	"github.com/featureform/config"
+	"github.com/featureform/fferr"
+)
+
+// VaultFetcher reads secrets from a Vault KV version 2 secrets engine. A
+// secret is returned as a JSON object of its keys, so references to it
+// should name a field, like {{secret:prod/snowflake#password}}.
+type VaultFetcher struct {
+	addr   string
+	token  string
+	mount  string
+	client *http.Client
+}
+
+func NewVaultFetcher(addr, token, mount string) (*VaultFetcher, error) {
+	if addr == "" {
+		return nil, fferr.NewMissingConfigEnv(config.EnvVaultAddr)
+	}
+	if token == "" {
+		return nil, fferr.NewMissingConfigEnv(config.EnvVaultToken)
+	}
+	return &VaultFetcher{
+		addr:   strings.TrimSuffix(addr, "/"),
+		token:  token,
+		mount:  strings.Trim(mount, "/"),
+		client: &http.Client{Timeout: 30 * time.Second},
+	}, nil
+}
+
+func (f *VaultFetcher) Fetch(ctx context.Context, name string) (string, error) {
+	url := fmt.Sprintf("%s/v1/%s/data/%s", f.addr, f.mount, strings.TrimPrefix(name, "/"))
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return "", fferr.NewInternalError(err)
+	}
+	req.Header.Set("X-Vault-Token", f.token)
+	resp, err := f.client.Do(req)
+	if err != nil {
+		return "", fferr.NewExecutionError("Vault", err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", fferr.NewExecutionError("Vault", err)
+	}
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return "", fferr.NewKeyNotFoundError(name, nil)
+	case resp.StatusCode != http.StatusOK:
+		return "", fferr.NewExecutionError("Vault", fmt.Errorf("reading secret %s failed with status %d: %s", name, resp.StatusCode, body))
+	}
+	var secret struct {
+		Data struct {
+			Data map[string]interface{} `json:"data"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(body, &secret); err != nil {
+		return "", fferr.NewParsingError(err)
+	}
+	data, err := json.Marshal(secret.Data.Data)
+	if err != nil {
+		return "", fferr.NewInternalError(err)
+	}
+	return string(data), nil
+}
+
+// AWSSecretsManagerFetcher reads secrets from AWS Secrets Manager by name or
+// ARN. Credentials and region come from the default AWS config chain.
+type AWSSecretsManagerFetcher struct {
+	client *secretsmanager.Client
+}
+
+func NewAWSSecretsManagerFetcher(ctx context.Context) (*AWSSecretsManagerFetcher, error) {
+	cfg, err := awsv2config.LoadDefaultConfig(ctx)
+	if err != nil {
+		return nil, fferr.NewInternalError(fmt.Errorf("failed to load AWS config: %w", err))
+	}
+	return &AWSSecretsManagerFetcher{client: secretsmanager.NewFromConfig(cfg)}, nil
+}
+
+func (f *AWSSecretsManagerFetcher) Fetch(ctx context.Context, name string) (string, error) {
+	resp, err := f.client.GetSecretValue(ctx, &secretsmanager.GetSecretValueInput{SecretId: &name})
+	if err != nil {
+		return "", fferr.NewExecutionError("AWS Secrets Manager", err)
+	}
+	if resp.SecretString != nil {
+		return *resp.SecretString, nil
+	}
+	return string(resp.SecretBinary), nil
+}
+
+// GCPSecretManagerFetcher reads the latest version of secrets from GCP Secret
+// Manager. Names can be a secret ID in the configured project, or a full
+// resource name like projects/<project>/secrets/<secret>/versions/<version>.
+// It uses application default credentials.
+type GCPSecretManagerFetcher struct {
+	service *gcpsecretmanager.Service
+	project string
+}
+
+func NewGCPSecretManagerFetcher(ctx context.Context, project string) (*GCPSecretManagerFetcher, error) {
+	service, err := gcpsecretmanager.NewService(ctx)
+	if err != nil {
+		return nil, fferr.NewInternalError(fmt.Errorf("failed to create GCP Secret Manager client: %w", err))
+	}
+	return &GCPSecretManagerFetcher{service: service, project: project}, nil
+}
+
+func (f *GCPSecretManagerFetcher) versionName(name string) (string, error) {
+	if strings.HasPrefix(name, "projects/") {
+		if !strings.Contains(name, "/versions/") {
+			name += "/versions/latest"
+		}
+		return name, nil
+	}
+	if f.project == "" {
+		return "", fferr.NewMissingConfigEnv(config.EnvGCPSecretManagerProject)
+	}
+	return fmt.Sprintf("projects/%s/secrets/%s/versions/latest", f.project, name), nil
+}
+
+func (f *GCPSecretManagerFetcher) Fetch(ctx context.Context, name string) (string, error) {
+	version, err := f.versionName(name)
+	if err != nil {
+		return "", err
+	}
+	resp, err := f.service.Projects.Secrets.Versions.Access(version).Context(ctx).Do()
+	if err != nil {
+		return "", fferr.NewExecutionError("GCP Secret Manager", err)
+	}
+	data, err := base64.StdEncoding.DecodeString(resp.Payload.Data)
+	if err != nil {
+		return "", fferr.NewParsingError(err)
+	}
+	return string(data), nil
+}
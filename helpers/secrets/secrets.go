@@ -0,0 +1,229 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+//
+// Copyright 2024 FeatureForm Inc.
+//
+
+// Package secrets resolves references to secrets in provider configs, so raw
+// credentials never have to be stored in the registry. A reference is a
+// string like {{secret:prod/snowflake_password}}, or
+// {{secret:prod/snowflake#password}} to read one field of a JSON secret. It's
+// replaced with the secret's value, read from the secret manager selected by
+// FF_SECRET_MANAGER, when the provider is created.
+package secrets
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sync"
+	"time"
+
+	"github.com/featureform/config"
+	"github.com/featureform/fferr"
+	"github.com/featureform/helpers"
+)
+
+// Manager is the name of a Fetcher implementation, selected with
+// FF_SECRET_MANAGER.
+type Manager string
+
+const (
+	Vault                Manager = "vault"
+	AWSSecretsManager    Manager = "aws_secrets_manager"
+	GCPSecretManager     Manager = "gcp_secret_manager"
+	defaultCacheDuration         = time.Minute
+)
+
+var referenceRegex = regexp.MustCompile(`\{\{secret:([^}#]+)(?:#([^}]+))?\}\}`)
+
+// Fetcher reads the value of a secret from a secret manager.
+type Fetcher interface {
+	Fetch(ctx context.Context, name string) (string, error)
+}
+
+type cachedSecret struct {
+	value   string
+	expires time.Time
+}
+
+// Resolver replaces secret references with values from a Fetcher. Values are
+// cached briefly, so creating many providers doesn't call the secret manager
+// each time, but rotated secrets are still picked up.
+type Resolver struct {
+	fetcher Fetcher
+	ttl     time.Duration
+	now     func() time.Time
+	mu      sync.Mutex
+	cache   map[string]cachedSecret
+}
+
+func NewResolver(fetcher Fetcher, ttl time.Duration) *Resolver {
+	return &Resolver{
+		fetcher: fetcher,
+		ttl:     ttl,
+		now:     time.Now,
+		cache:   make(map[string]cachedSecret),
+	}
+}
+
+// FromEnv creates a Resolver for the secret manager set in FF_SECRET_MANAGER.
+// It returns nil if none is set.
+func FromEnv(ctx context.Context) (*Resolver, error) {
+	manager := Manager(helpers.GetEnv(config.EnvSecretManager, ""))
+	var fetcher Fetcher
+	var err error
+	switch manager {
+	case "":
+		return nil, nil
+	case Vault:
+		fetcher, err = NewVaultFetcher(
+			helpers.GetEnv(config.EnvVaultAddr, ""),
+			helpers.GetEnv(config.EnvVaultToken, ""),
+			helpers.GetEnv(config.EnvVaultKVMount, "secret"),
+		)
+	case AWSSecretsManager:
+		fetcher, err = NewAWSSecretsManagerFetcher(ctx)
+	case GCPSecretManager:
+		fetcher, err = NewGCPSecretManagerFetcher(ctx, helpers.GetEnv(config.EnvGCPSecretManagerProject, ""))
+	default:
+		possible := []Manager{Vault, AWSSecretsManager, GCPSecretManager}
+		return nil, fferr.NewInvalidConfigEnv(config.EnvSecretManager, manager, possible)
+	}
+	if err != nil {
+		return nil, err
+	}
+	ttl, err := helpers.LookupEnvDuration(config.EnvSecretCacheTTL)
+	if _, ok := err.(*helpers.EnvNotFound); ok {
+		ttl = defaultCacheDuration
+	} else if err != nil {
+		return nil, fferr.NewInvalidConfigf("%s must be a duration: %v", config.EnvSecretCacheTTL, err)
+	}
+	return NewResolver(fetcher, ttl), nil
+}
+
+// HasReferences returns true if a config references any secrets.
+func HasReferences(serialized []byte) bool {
+	return referenceRegex.Match(serialized)
+}
+
+// Resolve returns a copy of a JSON config with each secret reference in its
+// string values replaced by the secret. Configs without references are
+// returned as is.
+func (r *Resolver) Resolve(ctx context.Context, serialized []byte) ([]byte, error) {
+	if !HasReferences(serialized) {
+		return serialized, nil
+	}
+	if r == nil {
+		return nil, fferr.NewInvalidConfigf("config references secrets but %s is not set", config.EnvSecretManager)
+	}
+	decoder := json.NewDecoder(bytes.NewReader(serialized))
+	// Numbers are kept as written so large integers aren't rounded.
+	decoder.UseNumber()
+	var parsed interface{}
+	if err := decoder.Decode(&parsed); err != nil {
+		return nil, fferr.NewParsingError(err)
+	}
+	resolved, err := r.resolveValue(ctx, parsed)
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(resolved); err != nil {
+		return nil, fferr.NewInternalError(err)
+	}
+	return bytes.TrimSuffix(buf.Bytes(), []byte("\n")), nil
+}
+
+func (r *Resolver) resolveValue(ctx context.Context, value interface{}) (interface{}, error) {
+	switch v := value.(type) {
+	case string:
+		return r.resolveString(ctx, v)
+	case map[string]interface{}:
+		for key, elem := range v {
+			resolved, err := r.resolveValue(ctx, elem)
+			if err != nil {
+				return nil, err
+			}
+			v[key] = resolved
+		}
+		return v, nil
+	case []interface{}:
+		for i, elem := range v {
+			resolved, err := r.resolveValue(ctx, elem)
+			if err != nil {
+				return nil, err
+			}
+			v[i] = resolved
+		}
+		return v, nil
+	default:
+		return v, nil
+	}
+}
+
+func (r *Resolver) resolveString(ctx context.Context, value string) (string, error) {
+	var resolveErr error
+	resolved := referenceRegex.ReplaceAllStringFunc(value, func(reference string) string {
+		if resolveErr != nil {
+			return reference
+		}
+		match := referenceRegex.FindStringSubmatch(reference)
+		secret, err := r.get(ctx, match[1])
+		if err != nil {
+			resolveErr = err
+			return reference
+		}
+		if field := match[2]; field != "" {
+			secret, err = jsonField(match[1], secret, field)
+			if err != nil {
+				resolveErr = err
+				return reference
+			}
+		}
+		return secret
+	})
+	if resolveErr != nil {
+		return "", resolveErr
+	}
+	return resolved, nil
+}
+
+func (r *Resolver) get(ctx context.Context, name string) (string, error) {
+	r.mu.Lock()
+	cached, ok := r.cache[name]
+	r.mu.Unlock()
+	if ok && r.now().Before(cached.expires) {
+		return cached.value, nil
+	}
+	value, err := r.fetcher.Fetch(ctx, name)
+	if err != nil {
+		return "", err
+	}
+	r.mu.Lock()
+	r.cache[name] = cachedSecret{value: value, expires: r.now().Add(r.ttl)}
+	r.mu.Unlock()
+	return value, nil
+}
+
+// jsonField reads a field from a secret that's a JSON object, which is how
+// AWS Secrets Manager stores key/value secrets.
+func jsonField(name, secret, field string) (string, error) {
+	var fields map[string]interface{}
+	if err := json.Unmarshal([]byte(secret), &fields); err != nil {
+		return "", fferr.NewInvalidArgumentErrorf("secret %s is not a JSON object, so field %s can't be read", name, field)
+	}
+	value, ok := fields[field]
+	if !ok {
+		return "", fferr.NewKeyNotFoundError(fmt.Sprintf("%s#%s", name, field), nil)
+	}
+	if str, ok := value.(string); ok {
+		return str, nil
+	}
+	return fmt.Sprint(value), nil
+}
@@ -0,0 +1,189 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+//
+// Copyright 2024 FeatureForm Inc.
+//
+
+package secrets
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/featureform/fferr"
+)
+
+type mapFetcher struct {
+	secrets map[string]string
+	calls   int
+}
+
+func (f *mapFetcher) Fetch(ctx context.Context, name string) (string, error) {
+	f.calls++
+	secret, ok := f.secrets[name]
+	if !ok {
+		return "", fferr.NewKeyNotFoundError(name, nil)
+	}
+	return secret, nil
+}
+
+func TestResolve(t *testing.T) {
+	fetcher := &mapFetcher{secrets: map[string]string{
+		"prod/snowflake_password": `pa"ss`,
+		"prod/aws":                `{"access_key": "AKIA", "secret_key": "shh"}`,
+	}}
+	resolver := NewResolver(fetcher, time.Minute)
+	config := []byte(`{
+		"Username": "featureform",
+		"Password": "{{secret:prod/snowflake_password}}",
+		"Credentials": {"Key": "{{secret:prod/aws#access_key}}:{{secret:prod/aws#secret_key}}"},
+		"Hosts": ["{{secret:prod/snowflake_password}}"],
+		"Port": 12345678901234567890
+	}`)
+	resolved, err := resolver.Resolve(context.Background(), config)
+	if err != nil {
+		t.Fatalf("Failed to resolve secrets: %v", err)
+	}
+	var got map[string]interface{}
+	if err := json.Unmarshal(resolved, &got); err != nil {
+		t.Fatalf("Resolved config isn't valid JSON: %v: %s", err, resolved)
+	}
+	var expected map[string]interface{}
+	expectedJSON := `{
+		"Username": "featureform",
+		"Password": "pa\"ss",
+		"Credentials": {"Key": "AKIA:shh"},
+		"Hosts": ["pa\"ss"],
+		"Port": 12345678901234567890
+	}`
+	if err := json.Unmarshal([]byte(expectedJSON), &expected); err != nil {
+		t.Fatalf("Failed to parse expected config: %v", err)
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Fatalf("Expected %v, got %v", expected, got)
+	}
+	if fetcher.calls != 2 {
+		t.Fatalf("Expected each secret to be fetched once, got %d calls", fetcher.calls)
+	}
+}
+
+func TestResolveWithoutReferences(t *testing.T) {
+	config := []byte(`{"Password": "plaintext"}`)
+	var resolver *Resolver
+	resolved, err := resolver.Resolve(context.Background(), config)
+	if err != nil {
+		t.Fatalf("Failed to resolve config: %v", err)
+	}
+	if string(resolved) != string(config) {
+		t.Fatalf("Expected config to be unchanged, got %s", resolved)
+	}
+}
+
+func TestResolveErrors(t *testing.T) {
+	fetcher := &mapFetcher{secrets: map[string]string{"plain": "value"}}
+	configs := map[string]string{
+		"MissingSecret": `{"Password": "{{secret:missing}}"}`,
+		"NotJSONSecret": `{"Password": "{{secret:plain#field}}"}`,
+		"MissingField":  `{"Password": "{{secret:plain}}", "Other": "{{secret:missing#field}}"}`,
+		"InvalidConfig": `{"Password": "{{secret:plain}}"`,
+	}
+	for name, config := range configs {
+		t.Run(name, func(t *testing.T) {
+			if _, err := NewResolver(fetcher, time.Minute).Resolve(context.Background(), []byte(config)); err == nil {
+				t.Fatalf("Expected an error")
+			}
+		})
+	}
+	var unconfigured *Resolver
+	if _, err := unconfigured.Resolve(context.Background(), []byte(`{"Password": "{{secret:plain}}"}`)); err == nil {
+		t.Fatalf("Expected an error resolving without a secret manager")
+	}
+}
+
+func TestResolveCacheExpiry(t *testing.T) {
+	fetcher := &mapFetcher{secrets: map[string]string{"password": "v1"}}
+	resolver := NewResolver(fetcher, time.Minute)
+	now := time.Now()
+	resolver.now = func() time.Time { return now }
+	config := []byte(`{"Password": "{{secret:password}}"}`)
+	resolve := func() string {
+		resolved, err := resolver.Resolve(context.Background(), config)
+		if err != nil {
+			t.Fatalf("Failed to resolve secrets: %v", err)
+		}
+		return string(resolved)
+	}
+	resolve()
+	fetcher.secrets["password"] = "v2"
+	if got := resolve(); got != `{"Password":"v1"}` {
+		t.Fatalf("Expected cached secret, got %s", got)
+	}
+	now = now.Add(2 * time.Minute)
+	if got := resolve(); got != `{"Password":"v2"}` {
+		t.Fatalf("Expected secret to be refetched after expiring, got %s", got)
+	}
+}
+
+func TestVaultFetcher(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Vault-Token") != "token" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		if r.URL.Path != "/v1/kv/data/prod/snowflake" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Write([]byte(`{"data": {"data": {"password": "shh"}, "metadata": {"version": 1}}}`))
+	}))
+	defer server.Close()
+
+	fetcher, err := NewVaultFetcher(server.URL, "token", "kv")
+	if err != nil {
+		t.Fatalf("Failed to create fetcher: %v", err)
+	}
+	resolved, err := NewResolver(fetcher, time.Minute).Resolve(context.Background(), []byte(`{"Password": "{{secret:prod/snowflake#password}}"}`))
+	if err != nil {
+		t.Fatalf("Failed to resolve secrets: %v", err)
+	}
+	if string(resolved) != `{"Password":"shh"}` {
+		t.Fatalf("Expected resolved password, got %s", resolved)
+	}
+	if _, err := fetcher.Fetch(context.Background(), "prod/missing"); err == nil {
+		t.Fatalf("Expected an error fetching a missing secret")
+	}
+	badToken, err := NewVaultFetcher(server.URL, "wrong", "kv")
+	if err != nil {
+		t.Fatalf("Failed to create fetcher: %v", err)
+	}
+	if _, err := badToken.Fetch(context.Background(), "prod/snowflake"); err == nil {
+		t.Fatalf("Expected an error fetching with a bad token")
+	}
+}
+
+func TestGCPSecretVersionName(t *testing.T) {
+	fetcher := &GCPSecretManagerFetcher{project: "my-project"}
+	names := map[string]string{
+		"snowflake":                                   "projects/my-project/secrets/snowflake/versions/latest",
+		"projects/other/secrets/snowflake":            "projects/other/secrets/snowflake/versions/latest",
+		"projects/other/secrets/snowflake/versions/3": "projects/other/secrets/snowflake/versions/3",
+	}
+	for name, expected := range names {
+		got, err := fetcher.versionName(name)
+		if err != nil {
+			t.Fatalf("Failed to get version name for %s: %v", name, err)
+		}
+		if got != expected {
+			t.Fatalf("Expected %s, got %s", expected, got)
+		}
+	}
+	if _, err := (&GCPSecretManagerFetcher{}).versionName("snowflake"); err == nil {
+		t.Fatalf("Expected an error without a project")
+	}
+}
@@ -0,0 +1,58 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+//
+// Copyright 2024 FeatureForm Inc.
+//
+
+package tests
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/ory/dockertest/v3"
+	clientv3 "go.etcd.io/etcd/client/v3"
+)
+
+// InitETCD initializes an etcd instance for testing. It runs the etcd container on a random port that can be
+// fetched with resource.GetPort("2379/tcp"). Authentication is disabled.
+// This instance will be torn down when the test completes.
+func InitETCD(pool *dockertest.Pool) *dockertest.Resource {
+	resource, err := pool.RunWithOptions(&dockertest.RunOptions{
+		Repository: "quay.io/coreos/etcd",
+		Tag:        "v3.5.13",
+		Cmd: []string{
+			"etcd",
+			"--listen-client-urls", "http://0.0.0.0:2379",
+			"--advertise-client-urls", "http://0.0.0.0:2379",
+		},
+		ExposedPorts: []string{"2379/tcp"},
+	})
+	if err != nil {
+		log.Fatalf("Could not start resource: %s", err)
+	}
+	endpoint := fmt.Sprintf("localhost:%s", resource.GetPort("2379/tcp"))
+
+	log.Println("Connecting to etcd on: ", endpoint)
+
+	if err = pool.Retry(func() error {
+		client, err := clientv3.New(clientv3.Config{
+			Endpoints:   []string{endpoint},
+			DialTimeout: time.Second,
+		})
+		if err != nil {
+			return err
+		}
+		defer client.Close()
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		_, err = client.Status(ctx, endpoint)
+		return err
+	}); err != nil {
+		log.Fatalf("Could not connect to etcd: %s", err)
+	}
+	return resource
+}
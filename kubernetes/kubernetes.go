@@ -268,6 +268,15 @@ func (k KubernetesCompletionWatcher) String() string {
 	return fmt.Sprintf("%d jobs succeeded. %d jobs active. %d jobs failed", job.Status.Succeeded, job.Status.Active, job.Status.Failed)
 }
 
+// Succeeded is the number of the job's tasks that have finished successfully.
+func (k KubernetesCompletionWatcher) Succeeded() (int32, error) {
+	job, err := k.jobClient.Get()
+	if err != nil {
+		return 0, err
+	}
+	return job.Status.Succeeded, nil
+}
+
 func getPodLogs(namespace string, name string) string {
 	podLogOpts := corev1.PodLogOptions{}
 	config, err := rest.InClusterConfig()
@@ -18,6 +18,8 @@ import (
 	"github.com/featureform/helpers"
 
 	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
 	"go.uber.org/zap/zaptest"
@@ -303,6 +305,12 @@ func AttachRequestID(id RequestID, ctx context.Context, logger Logger) context.C
 	}
 	ctx = context.WithValue(ctx, RequestIDKey, RequestID(id))
 	logger = logger.WithRequestID(RequestID(id))
+	// Tie the request ID to the trace, if there is one, so that either can be
+	// used to find the other.
+	if span := trace.SpanFromContext(ctx); span.SpanContext().IsValid() {
+		span.SetAttributes(attribute.String("request_id", string(id)))
+		logger = logger.With("trace-id", span.SpanContext().TraceID().String())
+	}
 	ctx = context.WithValue(ctx, LoggerKey, logger)
 	return ctx
 }
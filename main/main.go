@@ -15,8 +15,12 @@ import (
 	"net/http"
 	_ "net/http/pprof"
 	"os"
+	"os/signal"
+	"sync"
+	"syscall"
 	"time"
 
+	"github.com/apache/arrow/go/v17/arrow/flight"
 	"github.com/featureform/api"
 	"github.com/featureform/config"
 	"github.com/featureform/config/bootstrap"
@@ -24,14 +28,21 @@ import (
 	"github.com/featureform/coordinator/spawner"
 	"github.com/featureform/db"
 	help "github.com/featureform/helpers"
+	"github.com/featureform/helpers/encryption"
+	"github.com/featureform/helpers/grpctls"
+	"github.com/featureform/helpers/interceptors"
+	"github.com/featureform/helpers/tracing"
 	"github.com/featureform/logging"
 	"github.com/featureform/metadata"
 	dm "github.com/featureform/metadata/dashboard"
 	"github.com/featureform/metrics"
 	pb "github.com/featureform/proto"
+	"github.com/featureform/runner"
+	"github.com/featureform/scheduling"
 	"github.com/featureform/serving"
 
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/reflection"
 )
 
 func main() {
@@ -56,15 +67,13 @@ func main() {
 	} else {
 		log.Println("FF_STATE_PROVIDER set to", os.Getenv("FF_STATE_PROVIDER"))
 	}
-	apiPort := help.GetEnv("API_PORT", "7878")
-	metadataHost := help.GetEnv("METADATA_HOST", "localhost")
-	metadataPort := help.GetEnv("METADATA_PORT", "8080")
-	metadataHTTPPort := help.GetEnv("METADATA_HTTP_PORT", "3001")
-	servingHost := help.GetEnv("SERVING_HOST", "0.0.0.0")
-	servingPort := help.GetEnv("SERVING_PORT", "8081")
-	apiConn := fmt.Sprintf("0.0.0.0:%s", apiPort)
-	metadataConn := fmt.Sprintf("%s:%s", metadataHost, metadataPort)
-	servingConn := fmt.Sprintf("%s:%s", servingHost, servingPort)
+	if _, set := os.LookupEnv("MATERIALIZE_MAX_CONCURRENCY"); !set {
+		if err := os.Setenv("MATERIALIZE_MAX_CONCURRENCY", fmt.Sprint(runner.DefaultMaxConcurrency)); err != nil {
+			log.Fatalf("err %v", err)
+		}
+	}
+	log.Println("MATERIALIZE_MAX_CONCURRENCY set to", os.Getenv("MATERIALIZE_MAX_CONCURRENCY"))
+	otelEndpoint := help.GetEnv(tracing.EndpointEnv, "")
 	local := help.GetEnvBool("FEATUREFORM_LOCAL", true)
 	logger := logging.NewLogger("init-logger")
 	defer logger.Sync()
@@ -74,6 +83,11 @@ func main() {
 		logger.Errorw("Invalid App Config", "err", err)
 		panic(err)
 	}
+	servers, err := config.GetServerConfig(logger)
+	if err != nil {
+		logger.Errorw("Invalid server config", "err", err)
+		panic(err)
+	}
 	ctx, cancelFn := context.WithTimeout(context.Background(), appConfig.InitTimeout)
 	defer cancelFn()
 	initCtx := logger.AttachToContext(ctx)
@@ -84,7 +98,17 @@ func main() {
 		logger.Errorw("Failed to bootstrap service from config", "err", err)
 		panic(err)
 	}
-	defer logger.LogIfErr("Failed to close service-level resources", init.Close())
+	defer func() {
+		logger.LogIfErr("Failed to close service-level resources", init.Close())
+	}()
+	shutdownTracing, err := tracing.Init(ctx, "featureform", otelEndpoint)
+	if err != nil {
+		logger.Errorw("Failed to initialize tracing", "err", err)
+		panic(err)
+	}
+	defer func() {
+		logger.LogIfErr("Failed to shut down tracing", shutdownTracing(context.Background()))
+	}()
 
 	/****************************************** DB Migrations **********************************************************/
 
@@ -109,15 +133,16 @@ func main() {
 
 	// PPROF
 	go func() {
-		if err := http.ListenAndServe("localhost:6060", nil); err != nil {
+		if err := http.ListenAndServe(servers.PprofAddress(), nil); err != nil {
 			log.Printf("pprof server failed to start: %v", err)
 		}
 	}()
 
 	/****************************************** API Server ************************************************************/
 
+	healthServer := api.NewHttpsServer(servers.HealthCheckListenAddress())
 	go func() {
-		err := api.StartHttpsServer(":8443")
+		err := healthServer.ListenAndServe()
 		if err != nil && err != http.ErrServerClosed {
 			panic(fmt.Sprintf("health check HTTP server failed: %+v", err))
 		}
@@ -126,14 +151,27 @@ func main() {
 	/******************************************** Metadata ************************************************************/
 
 	mLogger := logging.NewLogger("metadata")
+	defer mLogger.Sync()
 
-	config := &metadata.Config{
-		Logger:      mLogger,
-		Address:     fmt.Sprintf(":%s", metadataPort),
-		TaskManager: manager,
+	authenticator, err := interceptors.AuthenticatorFromEnv()
+	if err != nil {
+		logger.Panicw("Failed to create authenticator", "Err", err)
 	}
 
-	server, err := metadata.NewMetadataServer(config)
+	encrypter, err := encryption.FromEnv(initCtx)
+	if err != nil {
+		logger.Panicw("Failed to create provider config encrypter", "Err", err)
+	}
+
+	metadataConfig := &metadata.Config{
+		Logger:        mLogger,
+		Address:       servers.MetadataListenAddress(),
+		TaskManager:   manager,
+		Authenticator: authenticator,
+		Encrypter:     encrypter,
+	}
+
+	server, err := metadata.NewMetadataServer(metadataConfig)
 	if err != nil {
 		logger.Panicw("Failed to create metadata server", "Err", err)
 	}
@@ -143,7 +181,7 @@ func main() {
 	cLogger := logging.NewLogger("coordinator")
 	defer cLogger.Sync()
 
-	client, err := metadata.NewClient(metadataConn, cLogger)
+	client, err := metadata.NewClient(servers.MetadataAddress(), cLogger)
 	if err != nil {
 		cLogger.Errorw("Failed to connect: %v", err)
 		panic(err)
@@ -153,26 +191,33 @@ func main() {
 		TaskPollInterval:       1 * time.Second,
 		TaskStatusSyncInterval: 1 * time.Minute,
 		DependencyPollInterval: 1 * time.Second,
+		MaxRetries:             3,
+		RetryBaseDelay:         5 * time.Second,
+		RetryMaxDelay:          1 * time.Minute,
+		ShutdownTimeout:        appConfig.ShutdownTimeout,
 	}
-	scheduler := coordinator.NewScheduler(client, cLogger, &spawner.MemoryJobSpawner{}, manager.Storage.Locker, sconfig)
+	scheduler := coordinator.NewScheduler(client, cLogger, &spawner.MemoryJobSpawner{}, manager.Storage.Locker, &metrics.NoOpJobMetricsHandler{}, sconfig)
 
 	/**************************************** Dashboard Backend *******************************************************/
 	dbLogger := logging.NewLogger("dashboard-metadata")
+	defer dbLogger.Sync()
 
-	dbLogger.Infof("Serving metadata at: %s\n", metadataConn)
+	dbLogger.Infof("Serving metadata at: %s\n", servers.MetadataAddress())
 
-	metadataServer, err := dm.NewMetadataServer(dbLogger, client, manager.Storage)
+	metadataServer, err := dm.NewMetadataServer(dbLogger, client, manager.Storage, encrypter)
 	if err != nil {
 		logger.Panicw("Failed to create server", "error", err)
 	}
 
-	metadataServingPort := fmt.Sprintf(":%s", metadataHTTPPort)
+	metadataServingPort := servers.MetadataHTTPListenAddress()
 	dbLogger.Infof("Serving HTTP Metadata on port: %s\n", metadataServingPort)
 
 	/**************************************** Serving *******************************************************/
 
 	sLogger := logging.NewLogger("serving")
+	defer sLogger.Sync()
 
+	servingConn := servers.ServingAddress()
 	sLogger.Infof("Serving feature serving at: %s\n", servingConn)
 
 	lis, err := net.Listen("tcp", servingConn)
@@ -184,20 +229,63 @@ func main() {
 	if err != nil {
 		sLogger.Panicw("Failed to create training server", "Err", err)
 	}
-	grpcServer := grpc.NewServer()
+	tlsOpts, err := grpctls.FromEnv().ServerOptions()
+	if err != nil {
+		sLogger.Panicw("Failed to load TLS credentials", "Err", err)
+	}
+	grpcServer := grpc.NewServer(append([]grpc.ServerOption{
+		grpc.UnaryInterceptor(interceptors.UnaryServerTracingInterceptor),
+		grpc.StreamInterceptor(interceptors.StreamServerTracingInterceptor),
+	}, tlsOpts...)...)
 
 	pb.RegisterFeatureServer(grpcServer, serv)
+	if config.ShouldEnableGRPCReflection() {
+		sLogger.Infow("Registering gRPC reflection service")
+		reflection.Register(grpcServer)
+	}
 	sLogger.Infow("Server starting", "Port", servingConn)
 
+	flightLis, err := net.Listen("tcp", servers.ServingFlightAddress())
+	if err != nil {
+		sLogger.Panicw("Failed to listen on Flight port", "Err", err)
+	}
+	// The Flight server uses the same mTLS config as the serving gRPC server.
+	flightServer := grpc.NewServer(append([]grpc.ServerOption{
+		grpc.StreamInterceptor(interceptors.StreamServerTracingInterceptor),
+	}, tlsOpts...)...)
+	flight.RegisterFlightServiceServer(flightServer, serving.NewFlightServer(serv))
+	sLogger.Infow("Flight server starting", "Port", servers.ServingFlightAddress())
+
+	var gatewayServer *http.Server
+	if servers.ServingHTTPEnabled() {
+		dialOpt, err := grpctls.FromEnv().DialOption()
+		if err != nil {
+			sLogger.Panicw("Failed to load TLS credentials", "Err", err)
+		}
+		gateway, err := serving.NewGateway(context.Background(), servingConn, dialOpt)
+		if err != nil {
+			sLogger.Panicw("Failed to create HTTP gateway", "Err", err)
+		}
+		gatewayServer = &http.Server{Addr: servers.ServingHTTPAddress(), Handler: gateway}
+		sLogger.Infow("HTTP gateway starting", "Port", servers.ServingHTTPAddress())
+	}
+
 	/******************************************** Start Servers *******************************************************/
 
+	aLogger := logging.NewLogger("api")
+	defer aLogger.Sync()
+	apiServer, err := api.NewApiServer(aLogger, servers.APIAddress(), servers.MetadataAddress(), servingConn)
+	if err != nil {
+		aLogger.Panicw("Failed to create API server", "Err", err)
+	}
+
+	// Signals cancel the watch context, which stops the schedulers from picking
+	// up new tasks, and start the graceful shutdown below.
+	watchCtx, stopWatch := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stopWatch()
+
 	go func() {
-		logger = logging.NewLogger("api")
-		serv, err := api.NewApiServer(logger, apiConn, metadataConn, servingConn)
-		if err != nil {
-			panic(err)
-		}
-		fmt.Println(serv.Serve())
+		fmt.Println(apiServer.Serve())
 	}()
 
 	go func() {
@@ -209,16 +297,20 @@ func main() {
 		}
 	}()
 
+	schedulerDone := make(chan struct{})
 	go func() {
-		err = scheduler.Start()
-		if err != nil {
+		defer close(schedulerDone)
+		if err := scheduler.Start(watchCtx); err != nil {
 			cLogger.Errorw(err.Error())
 		}
 	}()
 
+	cronScheduler := scheduling.NewCronScheduler(&manager, manager.Storage.Locker, 30*time.Second, cLogger.With("component", "cron-scheduler"))
+	go cronScheduler.Start(watchCtx)
+
 	go func() {
 		err := metadataServer.Start(metadataServingPort, local)
-		if err != nil {
+		if err != nil && err != http.ErrServerClosed {
 			panic(err)
 		}
 	}()
@@ -231,7 +323,64 @@ func main() {
 		}
 	}()
 
-	for {
-		time.Sleep(1 * time.Second)
+	go func() {
+		if err := flightServer.Serve(flightLis); err != nil {
+			logger.Errorw("Flight serve failed with error", "Err", err)
+			panic(err)
+		}
+	}()
+
+	if gatewayServer != nil {
+		go func() {
+			if err := gatewayServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+				logger.Errorw("HTTP gateway failed with error", "Err", err)
+				panic(err)
+			}
+		}()
+	}
+
+	/******************************************** Shutdown ************************************************************/
+
+	<-watchCtx.Done()
+	logger.Infow("Received shutdown signal, stopping servers", "timeout", appConfig.ShutdownTimeout)
+	shutdownCtx, cancelShutdown := context.WithTimeout(context.Background(), appConfig.ShutdownTimeout)
+	defer cancelShutdown()
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		var wg sync.WaitGroup
+		gracefulStops := []func(){
+			grpcServer.GracefulStop,
+			flightServer.GracefulStop,
+			func() { logger.LogIfErr("Failed to stop API server", apiServer.GracefulStop()) },
+			func() { logger.LogIfErr("Failed to stop health check server", healthServer.Shutdown(shutdownCtx)) },
+			func() {
+				logger.LogIfErr("Failed to stop dashboard metadata server", metadataServer.Shutdown(shutdownCtx))
+			},
+		}
+		if gatewayServer != nil {
+			gracefulStops = append(gracefulStops, func() {
+				logger.LogIfErr("Failed to stop HTTP gateway", gatewayServer.Shutdown(shutdownCtx))
+			})
+		}
+		for _, gracefulStop := range gracefulStops {
+			wg.Add(1)
+			go func(gracefulStop func()) {
+				defer wg.Done()
+				gracefulStop()
+			}(gracefulStop)
+		}
+		wg.Wait()
+		<-schedulerDone
+		<-cronScheduler.Stop().Done()
+		// The metadata server is stopped last since the other servers and the
+		// running tasks use it.
+		logger.LogIfErr("Failed to stop metadata server", server.GracefulStop())
+	}()
+	select {
+	case <-stopped:
+		logger.Info("Servers stopped, shutting down")
+	case <-shutdownCtx.Done():
+		logger.Warnw("Timed out waiting for in-flight requests and tasks, shutting down", "timeout", appConfig.ShutdownTimeout)
 	}
 }
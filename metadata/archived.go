@@ -0,0 +1,99 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+//
+// Copyright 2024 FeatureForm Inc.
+//
+
+package metadata
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+
+	mapset "github.com/deckarep/golang-set/v2"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/featureform/fferr"
+)
+
+// archivedVariantKeyPrefix is where the index of archived variants is kept in
+// the metadata store, so hiding them doesn't need a scan of every variant.
+const archivedVariantKeyPrefix = "/archived_variants/"
+
+func archivedVariantKey(id ResourceID) string {
+	return archivedVariantKeyPrefix + createKey(id)
+}
+
+// setArchivedIndex adds id to the archived variant index, or removes it.
+func (serv *MetadataServer) setArchivedIndex(id ResourceID, archived bool) error {
+	if !archived {
+		_, err := serv.taskManager.Storage.Delete(archivedVariantKey(id))
+		var notFound *fferr.KeyNotFoundError
+		if errors.As(err, &notFound) {
+			return nil
+		}
+		return err
+	}
+	serialized, err := json.Marshal(id)
+	if err != nil {
+		return fferr.NewInternalError(fmt.Errorf("failed to serialize archived variant %s: %w", id, err))
+	}
+	return serv.taskManager.Storage.Create(archivedVariantKey(id), string(serialized))
+}
+
+// archivedVariants returns the archived variants of type t, ordered by key. If
+// name is set, only that resource's archived variants are returned.
+func (serv *MetadataServer) archivedVariants(ctx context.Context, t ResourceType, name string) ([]ResourceID, error) {
+	prefix := fmt.Sprintf("%s%s__", archivedVariantKeyPrefix, t)
+	if name != "" {
+		prefix = archivedVariantKeyPrefix + variantLookupPrefix(t, name)
+	}
+	records, err := serv.taskManager.Storage.List(prefix)
+	if err != nil {
+		return nil, err
+	}
+	keys := make([]string, 0, len(records))
+	for key := range records {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	archived := make([]ResourceID, 0, len(keys))
+	for _, key := range keys {
+		id := ResourceID{}
+		if err := json.Unmarshal([]byte(records[key]), &id); err != nil {
+			return nil, fferr.NewInternalError(fmt.Errorf("failed to deserialize archived variant %s: %w", key, err))
+		}
+		// Names can contain the key separator, so the prefix can match others.
+		if name != "" && id.Name != name {
+			continue
+		}
+		archived = append(archived, id)
+	}
+	return archived, nil
+}
+
+// visibleParent hides the archived variants listed on the parent resource id,
+// as withoutArchivedVariants does. A parent whose variants are all archived
+// isn't found.
+func (serv *MetadataServer) visibleParent(ctx context.Context, id ResourceID, msg proto.Message) (proto.Message, error) {
+	variantType, hasVariants := variantTypeOf(id.Type)
+	if !hasVariants {
+		return msg, nil
+	}
+	archived, err := serv.archivedVariants(ctx, variantType, id.Name)
+	if err != nil {
+		return nil, err
+	}
+	if len(archived) == 0 {
+		return msg, nil
+	}
+	visible, isVisible := withoutArchivedVariants(msg, mapset.NewSet[ResourceID](archived...), variantType)
+	if !isVisible {
+		return nil, fferr.NewKeyNotFoundError(id.String(), fmt.Errorf("every variant of %s is archived", id.Name))
+	}
+	return visible, nil
+}
@@ -24,10 +24,14 @@ import (
 	"github.com/featureform/scheduling"
 	sch "github.com/featureform/scheduling/proto"
 
+	"github.com/featureform/helpers/grpctls"
+	"github.com/featureform/helpers/interceptors"
 	help "github.com/featureform/helpers/notifications"
 
+	"github.com/google/uuid"
+	spb "google.golang.org/genproto/googleapis/rpc/status"
 	"google.golang.org/grpc"
-	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/codes"
 	grpc_status "google.golang.org/grpc/status"
 	"google.golang.org/protobuf/encoding/protojson"
 	"google.golang.org/protobuf/proto"
@@ -169,6 +173,57 @@ func (client *Client) Create(ctx context.Context, def ResourceDef) error {
 	}
 }
 
+// Plan reports what creating defs in order would do, without creating them.
+func (client *Client) Plan(ctx context.Context, defs []ResourceDef) ([]PlannedChange, error) {
+	requestID := logging.GetRequestIDFromContext(ctx)
+	resources := make([]*pb.PlanResource, len(defs))
+	for i, def := range defs {
+		res := &pb.PlanResource{}
+		switch casted := def.(type) {
+		case FeatureDef:
+			serialized, err := casted.Serialize(requestID)
+			if err != nil {
+				return nil, err
+			}
+			res.Resource = &pb.PlanResource_FeatureVariant{FeatureVariant: serialized.FeatureVariant}
+		case LabelDef:
+			serialized, err := casted.Serialize(requestID)
+			if err != nil {
+				return nil, err
+			}
+			res.Resource = &pb.PlanResource_LabelVariant{LabelVariant: serialized.LabelVariant}
+		case TrainingSetDef:
+			res.Resource = &pb.PlanResource_TrainingSetVariant{TrainingSetVariant: casted.Serialize(requestID).TrainingSetVariant}
+		case SourceDef:
+			serialized, err := casted.Serialize(requestID)
+			if err != nil {
+				return nil, err
+			}
+			res.Resource = &pb.PlanResource_SourceVariant{SourceVariant: serialized.SourceVariant}
+		case UserDef:
+			res.Resource = &pb.PlanResource_User{User: casted.Serialize(requestID).User}
+		case ProviderDef:
+			res.Resource = &pb.PlanResource_Provider{Provider: casted.Serialize(requestID).Provider}
+		case EntityDef:
+			res.Resource = &pb.PlanResource_Entity{Entity: casted.Serialize(requestID).Entity}
+		case ModelDef:
+			res.Resource = &pb.PlanResource_Model{Model: casted.Serialize(requestID).Model}
+		default:
+			return nil, fferr.NewInvalidArgumentError(fmt.Errorf("%T not implemented in Plan", casted))
+		}
+		resources[i] = res
+	}
+	resp, err := client.GrpcConn.Plan(ctx, &pb.PlanRequest{Resources: resources, RequestId: requestID.String()})
+	if err != nil {
+		return nil, err
+	}
+	changes := make([]PlannedChange, len(resp.Changes))
+	for i, change := range resp.Changes {
+		changes[i] = parsePlannedChange(change)
+	}
+	return changes, nil
+}
+
 func (client *Client) ListFeatures(ctx context.Context) ([]*Feature, error) {
 	logger := logging.GetLoggerFromContext(ctx)
 	stream, err := client.GrpcConn.ListFeatures(ctx, &pb.ListRequest{RequestId: logging.GetRequestIDFromContext(ctx).String()})
@@ -179,6 +234,40 @@ func (client *Client) ListFeatures(ctx context.Context) ([]*Feature, error) {
 	return client.parseFeatureStream(stream)
 }
 
+func listPageRequest(ctx context.Context, pageSize int, pageToken string) *pb.ListRequest {
+	return &pb.ListRequest{
+		RequestId: logging.GetRequestIDFromContext(ctx).String(),
+		PageSize:  int32(pageSize),
+		PageToken: pageToken,
+	}
+}
+
+// nextPageToken returns the token of the next page from a paged list's
+// trailer, once its stream has been read to the end.
+func nextPageToken(stream grpc.ClientStream) string {
+	tokens := stream.Trailer().Get(nextPageTokenTrailer)
+	if len(tokens) == 0 {
+		return ""
+	}
+	return tokens[0]
+}
+
+// ListFeaturesPage lists a page of features ordered by name. Pass the returned
+// token to get the next page; it's empty on the last page.
+func (client *Client) ListFeaturesPage(ctx context.Context, pageSize int, pageToken string) ([]*Feature, string, error) {
+	logger := logging.GetLoggerFromContext(ctx)
+	stream, err := client.GrpcConn.ListFeatures(ctx, listPageRequest(ctx, pageSize, pageToken))
+	if err != nil {
+		logger.Errorw("Failed to list features", "error", err)
+		return nil, "", err
+	}
+	features, err := client.parseFeatureStream(stream)
+	if err != nil {
+		return nil, "", err
+	}
+	return features, nextPageToken(stream), nil
+}
+
 func (client *Client) GetFeature(ctx context.Context, feature string) (*Feature, error) {
 	featureList, err := client.GetFeatures(ctx, []string{feature})
 	if err != nil {
@@ -225,6 +314,111 @@ func (client *Client) GetFeatureVariants(ctx context.Context, ids []NameVariant)
 	return client.parseFeatureVariantStream(stream)
 }
 
+// GetDefaultVariant returns the default variant of a feature, label, source or
+// training set. t can be the parent type or its variant type.
+func (client *Client) GetDefaultVariant(ctx context.Context, name string, t ResourceType) (string, error) {
+	resp, err := client.GrpcConn.GetDefaultVariant(ctx, &pb.GetDefaultVariantRequest{
+		Name:         name,
+		ResourceType: t.Serialized(),
+		RequestId:    logging.GetRequestIDFromContext(ctx).String(),
+	})
+	if err != nil {
+		return "", err
+	}
+	return resp.Variant, nil
+}
+
+// ListVariants returns every variant of a feature, label, source or training
+// set with its status, newest first, leaving out archived variants. t can be
+// the parent type or its variant type.
+func (client *Client) ListVariants(ctx context.Context, name string, t ResourceType) ([]*VariantSummary, error) {
+	return client.listVariants(ctx, name, t, false)
+}
+
+// ListVariantsWithArchived is ListVariants, but includes archived variants.
+func (client *Client) ListVariantsWithArchived(ctx context.Context, name string, t ResourceType) ([]*VariantSummary, error) {
+	return client.listVariants(ctx, name, t, true)
+}
+
+func (client *Client) listVariants(ctx context.Context, name string, t ResourceType, includeArchived bool) ([]*VariantSummary, error) {
+	resp, err := client.GrpcConn.ListVariants(ctx, &pb.ListVariantsRequest{
+		Name:            name,
+		ResourceType:    t.Serialized(),
+		RequestId:       logging.GetRequestIDFromContext(ctx).String(),
+		IncludeArchived: includeArchived,
+	})
+	if err != nil {
+		return nil, err
+	}
+	variants := make([]*VariantSummary, len(resp.Variants))
+	for i, variant := range resp.Variants {
+		variants[i] = &VariantSummary{serialized: variant}
+	}
+	return variants, nil
+}
+
+// VariantSummary is a variant of a resource with its status, as returned by
+// ListVariants.
+type VariantSummary struct {
+	serialized *pb.VariantSummary
+}
+
+func (summary *VariantSummary) Variant() string {
+	return summary.serialized.GetVariant()
+}
+
+func (summary *VariantSummary) Status() scheduling.Status {
+	if summary.serialized.GetStatus() != nil {
+		return scheduling.Status(summary.serialized.GetStatus().Status)
+	}
+	return scheduling.CREATED
+}
+
+func (summary *VariantSummary) Error() string {
+	if summary.serialized.GetStatus() != nil {
+		return fferr.ToDashboardError(summary.serialized.GetStatus())
+	}
+	return ""
+}
+
+func (summary *VariantSummary) Created() time.Time {
+	return summary.serialized.GetCreated().AsTime()
+}
+
+func (summary *VariantSummary) IsArchived() bool {
+	return summary.serialized.GetArchived()
+}
+
+// BatchGetFeatureVariants gets many feature variants in a single round trip.
+// Variants that couldn't be fetched are returned in the error map rather than
+// failing the whole batch.
+func (client *Client) BatchGetFeatureVariants(ctx context.Context, ids []NameVariant) ([]*FeatureVariant, map[NameVariant]error, error) {
+	logger := logging.GetLoggerFromContext(ctx)
+	nameVariants := make([]*pb.NameVariant, len(ids))
+	for i, id := range ids {
+		nameVariants[i] = id.Serialize()
+	}
+	resp, err := client.GrpcConn.BatchGetFeatureVariants(ctx, &pb.BatchGetFeatureVariantsRequest{
+		NameVariants: nameVariants,
+		RequestId:    logging.GetRequestIDFromContext(ctx).String(),
+	})
+	if err != nil {
+		logger.Errorw("Failed to batch get feature variants", "ids", ids, "error", err)
+		return nil, nil, err
+	}
+	variants := make([]*FeatureVariant, len(resp.FeatureVariants))
+	for i, fv := range resp.FeatureVariants {
+		variants[i] = WrapProtoFeatureVariant(fv)
+	}
+	errs := make(map[NameVariant]error, len(resp.Errors))
+	for _, itemErr := range resp.Errors {
+		id := NameVariant{Name: itemErr.NameVariant.GetName(), Variant: itemErr.NameVariant.GetVariant()}
+		status := &spb.Status{Code: itemErr.Error.GetCode(), Message: itemErr.Error.GetMessage(), Details: itemErr.Error.GetDetails()}
+		errs[id] = fferr.FromErr(grpc_status.ErrorProto(status))
+	}
+	return variants, errs, nil
+}
+
 func (client *Client) GetFeatureVariant(ctx context.Context, id NameVariant) (*FeatureVariant, error) {
 	variants, err := client.GetFeatureVariants(ctx, []NameVariant{id})
 	if err != nil {
@@ -253,6 +447,84 @@ type FeatureDef struct {
 	IsOnDemand  bool
 	Definition  string
 	Type        types.ValueType
+	TTL         time.Duration
+	// AllowBreaking allows Type to change in a way that isn't backward
+	// compatible with the feature's current default variant.
+	AllowBreaking bool
+	// MaterializationFilter is a SQL predicate over the source's columns that
+	// limits which rows are materialized.
+	MaterializationFilter string
+	// DefaultValue is a JSON encoded value of Type that's served for entities
+	// without a value in the online store.
+	DefaultValue string
+	// ColumnCasts casts source columns to another type when the feature is
+	// registered from its source.
+	ColumnCasts map[string]types.ScalarType
+	// TimestampFormat parses the source's timestamp column when the feature is
+	// registered from it.
+	TimestampFormat *TimestampFormat
+	// MaterializationPartition splits the feature's materialized files into a
+	// directory per partition.
+	MaterializationPartition *MaterializationPartition
+}
+
+// MaterializationPartition is how a feature's materialized files are
+// partitioned.
+type MaterializationPartition struct {
+	// Type is one of DATE or ENTITY_BUCKET.
+	Type string
+	// Buckets is the number of buckets when partitioning by ENTITY_BUCKET.
+	Buckets int
+}
+
+func (partition *MaterializationPartition) Serialize() *pb.MaterializationPartition {
+	if partition == nil {
+		return nil
+	}
+	return &pb.MaterializationPartition{
+		Type:    partition.Type,
+		Buckets: int32(partition.Buckets),
+	}
+}
+
+func parseMaterializationPartition(serialized *pb.MaterializationPartition) *MaterializationPartition {
+	if serialized == nil {
+		return nil
+	}
+	return &MaterializationPartition{
+		Type:    serialized.GetType(),
+		Buckets: int(serialized.GetBuckets()),
+	}
+}
+
+// TimestampFormat is how a source's timestamp column is parsed if it isn't a
+// native timestamp.
+type TimestampFormat struct {
+	// Encoding is one of epoch_seconds, epoch_millis or iso8601.
+	Encoding string
+	// Timezone is the IANA zone of ISO-8601 timestamps without an offset. It's
+	// UTC if unset.
+	Timezone string
+}
+
+func (format *TimestampFormat) Serialize() *pb.TimestampFormat {
+	if format == nil {
+		return nil
+	}
+	return &pb.TimestampFormat{
+		Encoding: format.Encoding,
+		Timezone: format.Timezone,
+	}
+}
+
+func parseTimestampFormat(serialized *pb.TimestampFormat) *TimestampFormat {
+	if serialized == nil {
+		return nil
+	}
+	return &TimestampFormat{
+		Encoding: serialized.GetEncoding(),
+		Timezone: serialized.GetTimezone(),
+	}
 }
 
 type ResourceVariantColumns struct {
@@ -335,21 +607,31 @@ func (def FeatureDef) Serialize(requestID logging.RequestID) (*pb.FeatureVariant
 	} else {
 		typeProto = def.Type.ToProto()
 	}
+	columnCasts, err := serializeColumnCasts(def.ColumnCasts)
+	if err != nil {
+		return nil, err
+	}
 	serialized := &pb.FeatureVariantRequest{
 		FeatureVariant: &pb.FeatureVariant{
-			Name:        def.Name,
-			Variant:     def.Variant,
-			Source:      def.Source.Serialize(),
-			Type:        typeProto,
-			Entity:      def.Entity,
-			Owner:       def.Owner,
-			Description: def.Description,
-			Status:      &pb.ResourceStatus{Status: pb.ResourceStatus_CREATED},
-			Provider:    def.Provider,
-			Schedule:    def.Schedule,
-			Tags:        &pb.Tags{Tag: def.Tags},
-			Properties:  def.Properties.Serialize(),
-			Mode:        pb.ComputationMode(def.Mode),
+			Name:                     def.Name,
+			Variant:                  def.Variant,
+			Source:                   def.Source.Serialize(),
+			Type:                     typeProto,
+			Entity:                   def.Entity,
+			Owner:                    def.Owner,
+			Description:              def.Description,
+			Status:                   &pb.ResourceStatus{Status: pb.ResourceStatus_CREATED},
+			Provider:                 def.Provider,
+			Schedule:                 def.Schedule,
+			Tags:                     &pb.Tags{Tag: def.Tags},
+			Properties:               def.Properties.Serialize(),
+			Mode:                     pb.ComputationMode(def.Mode),
+			AllowBreaking:            def.AllowBreaking,
+			MaterializationFilter:    def.MaterializationFilter,
+			DefaultValue:             def.DefaultValue,
+			ColumnCasts:              columnCasts,
+			TimestampFormat:          def.TimestampFormat.Serialize(),
+			MaterializationPartition: def.MaterializationPartition.Serialize(),
 		},
 		RequestId: requestID.String(),
 	}
@@ -367,17 +649,54 @@ func (def FeatureDef) Serialize(requestID logging.RequestID) (*pb.FeatureVariant
 	default:
 		return nil, fferr.NewInvalidArgumentError(fmt.Errorf("FeatureDef Columns has unexpected type %T", x))
 	}
+	if def.TTL < 0 {
+		return nil, fferr.NewInvalidArgumentErrorf("FeatureDef TTL can't be negative: %s", def.TTL)
+	}
+	if def.TTL > 0 {
+		serialized.FeatureVariant.Ttl = durationpb.New(def.TTL)
+	}
+	if def.Definition != "" {
+		serialized.FeatureVariant.AdditionalParameters = &pb.FeatureParameters{
+			FeatureType: &pb.FeatureParameters_Ondemand{
+				Ondemand: &pb.OndemandFeatureParameters{Definition: def.Definition},
+			},
+		}
+	}
 	return serialized, nil
 }
 
+// createAttempts is how many times a create is sent while the metadata server
+// is unavailable.
+const createAttempts = 3
+
+// sendCreate sends a create, retrying it while the metadata server is
+// unavailable. Retries carry the same idempotency key as the first attempt, so
+// a create whose response was lost isn't applied twice.
+func sendCreate[Req any](ctx context.Context, req Req, create func(context.Context, Req, ...grpc.CallOption) (*pb.Empty, error)) error {
+	var err error
+	for attempt := 0; attempt < createAttempts; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-ctx.Done():
+				return err
+			case <-time.After(time.Duration(attempt) * 500 * time.Millisecond):
+			}
+		}
+		if _, err = create(ctx, req); grpc_status.Code(err) != codes.Unavailable {
+			return err
+		}
+	}
+	return err
+}
+
 func (client *Client) CreateFeatureVariant(ctx context.Context, def FeatureDef) error {
 	requestID := logging.GetRequestIDFromContext(ctx)
 	serialized, err := def.Serialize(requestID)
 	if err != nil {
 		return err
 	}
-	_, err = client.GrpcConn.CreateFeatureVariant(ctx, serialized)
-	return err
+	serialized.IdempotencyKey = uuid.NewString()
+	return sendCreate(ctx, serialized, client.GrpcConn.CreateFeatureVariant)
 }
 
 type featureStream interface {
@@ -426,6 +745,20 @@ func (client *Client) ListLabels(ctx context.Context) ([]*Label, error) {
 	return client.parseLabelStream(stream)
 }
 
+func (client *Client) ListLabelsPage(ctx context.Context, pageSize int, pageToken string) ([]*Label, string, error) {
+	logger := logging.GetLoggerFromContext(ctx)
+	stream, err := client.GrpcConn.ListLabels(ctx, listPageRequest(ctx, pageSize, pageToken))
+	if err != nil {
+		logger.Errorw("Failed to list labels", "error", err)
+		return nil, "", err
+	}
+	labels, err := client.parseLabelStream(stream)
+	if err != nil {
+		return nil, "", err
+	}
+	return labels, nextPageToken(stream), nil
+}
+
 func (client *Client) GetLabel(ctx context.Context, label string) (*Label, error) {
 	labelList, err := client.GetLabels(ctx, []string{label})
 	if err != nil {
@@ -465,6 +798,12 @@ type LabelDef struct {
 	Location    interface{}
 	Tags        Tags
 	Properties  Properties
+	// ColumnCasts casts source columns to another type when the label is
+	// registered from its source.
+	ColumnCasts map[string]types.ScalarType
+	// TimestampFormat parses the source's timestamp column when the label is
+	// registered from it.
+	TimestampFormat *TimestampFormat
 }
 
 func (def LabelDef) ResourceType() ResourceType {
@@ -486,19 +825,25 @@ func (def LabelDef) Serialize(requestID logging.RequestID) (*pb.LabelVariantRequ
 	} else {
 		typeProto = def.Type.ToProto()
 	}
+	columnCasts, err := serializeColumnCasts(def.ColumnCasts)
+	if err != nil {
+		return nil, err
+	}
 	serialized := &pb.LabelVariantRequest{
 		LabelVariant: &pb.LabelVariant{
-			Name:        def.Name,
-			Variant:     def.Variant,
-			Description: def.Description,
-			Type:        typeProto,
-			Source:      def.Source.Serialize(),
-			Entity:      def.Entity,
-			Owner:       def.Owner,
-			Status:      &pb.ResourceStatus{Status: pb.ResourceStatus_NO_STATUS},
-			Provider:    def.Provider,
-			Tags:        &pb.Tags{Tag: def.Tags},
-			Properties:  def.Properties.Serialize(),
+			Name:            def.Name,
+			Variant:         def.Variant,
+			Description:     def.Description,
+			Type:            typeProto,
+			Source:          def.Source.Serialize(),
+			Entity:          def.Entity,
+			Owner:           def.Owner,
+			Status:          &pb.ResourceStatus{Status: pb.ResourceStatus_NO_STATUS},
+			Provider:        def.Provider,
+			Tags:            &pb.Tags{Tag: def.Tags},
+			Properties:      def.Properties.Serialize(),
+			ColumnCasts:     columnCasts,
+			TimestampFormat: def.TimestampFormat.Serialize(),
 		},
 		RequestId: requestID.String(),
 	}
@@ -524,8 +869,8 @@ func (client *Client) CreateLabelVariant(ctx context.Context, def LabelDef) erro
 	if err != nil {
 		return err
 	}
-	_, err = client.GrpcConn.CreateLabelVariant(ctx, serialized)
-	return err
+	serialized.IdempotencyKey = uuid.NewString()
+	return sendCreate(ctx, serialized, client.GrpcConn.CreateLabelVariant)
 }
 
 func (client *Client) GetLabelVariants(ctx context.Context, ids []NameVariant) ([]*LabelVariant, error) {
@@ -604,6 +949,20 @@ func (client *Client) ListTrainingSets(ctx context.Context) ([]*TrainingSet, err
 	return client.parseTrainingSetStream(stream)
 }
 
+func (client *Client) ListTrainingSetsPage(ctx context.Context, pageSize int, pageToken string) ([]*TrainingSet, string, error) {
+	logger := logging.GetLoggerFromContext(ctx)
+	stream, err := client.GrpcConn.ListTrainingSets(ctx, listPageRequest(ctx, pageSize, pageToken))
+	if err != nil {
+		logger.Errorw("Failed to list training sets", "error", err)
+		return nil, "", err
+	}
+	trainingSets, err := client.parseTrainingSetStream(stream)
+	if err != nil {
+		return nil, "", err
+	}
+	return trainingSets, nextPageToken(stream), nil
+}
+
 func (client *Client) GetTrainingSet(ctx context.Context, trainingSet string) (*TrainingSet, error) {
 	trainingSetList, err := client.GetTrainingSets(ctx, []string{trainingSet})
 	if err != nil {
@@ -639,10 +998,60 @@ type TrainingSetDef struct {
 	Provider    string
 	Schedule    string
 	Label       NameVariant
-	Features    NameVariants
-	Tags        Tags
-	Properties  Properties
-	Type        TrainingSetType
+	// Labels, when set, joins several labels into the training set for
+	// multi-task models, one column each. Label is the first of them, and is
+	// filled in from Labels if it's unset.
+	Labels     NameVariants
+	Features   NameVariants
+	Tags       Tags
+	Properties Properties
+	Type       TrainingSetType
+	// PersistAs, when set, copies the training set into a table once it's
+	// created.
+	PersistAs *TrainingSetPersistAs
+	// Split, when set, assigns each row to a train or test split.
+	Split *TrainingSetSplit
+	// MaxFeatureAge, when non-zero, is the oldest a feature value may be
+	// relative to its label's timestamp. Older values are joined as null.
+	MaxFeatureAge time.Duration
+}
+
+// TrainingSetSplit is a deterministic train/test split. Rows are assigned by
+// hashing their label's entity and timestamp with Seed.
+type TrainingSetSplit struct {
+	TrainFraction float64
+	Seed          int64
+}
+
+func (split *TrainingSetSplit) Serialize() *pb.TrainingSetSplit {
+	if split == nil {
+		return nil
+	}
+	return &pb.TrainingSetSplit{
+		TrainFraction: split.TrainFraction,
+		Seed:          split.Seed,
+	}
+}
+
+// TrainingSetPersistAs is a table a training set is copied into, so it can be
+// queried and registered as a primary source.
+type TrainingSetPersistAs struct {
+	Table string
+	// Overwrite replaces Table if it already exists.
+	Overwrite bool
+	// Dedup only copies one of the rows that are identical across every column.
+	Dedup bool
+}
+
+func (persist *TrainingSetPersistAs) Serialize() *pb.TrainingSetPersistAs {
+	if persist == nil {
+		return nil
+	}
+	return &pb.TrainingSetPersistAs{
+		Table:     persist.Table,
+		Overwrite: persist.Overwrite,
+		Dedup:     persist.Dedup,
+	}
 }
 
 func (def TrainingSetDef) ResourceType() ResourceType {
@@ -658,20 +1067,35 @@ func (def TrainingSetDef) ResourceID() ResourceID {
 }
 
 func (def TrainingSetDef) Serialize(requestID logging.RequestID) *pb.TrainingSetVariantRequest {
+	if def.Label == (NameVariant{}) && len(def.Labels) != 0 {
+		def.Label = def.Labels[0]
+	}
+	var labels []*pb.NameVariant
+	if len(def.Labels) != 0 {
+		labels = def.Labels.Serialize()
+	}
+	var maxFeatureAge *durationpb.Duration
+	if def.MaxFeatureAge != 0 {
+		maxFeatureAge = durationpb.New(def.MaxFeatureAge)
+	}
 	return &pb.TrainingSetVariantRequest{
 		TrainingSetVariant: &pb.TrainingSetVariant{
-			Name:        def.Name,
-			Variant:     def.Variant,
-			Description: def.Description,
-			Owner:       def.Owner,
-			Provider:    def.Provider,
-			Status:      &pb.ResourceStatus{Status: pb.ResourceStatus_CREATED},
-			Label:       def.Label.Serialize(),
-			Features:    def.Features.Serialize(),
-			Schedule:    def.Schedule,
-			Tags:        &pb.Tags{Tag: def.Tags},
-			Properties:  def.Properties.Serialize(),
-			Type:        TrainingSetTypeToProto(def.Type),
+			Name:          def.Name,
+			Variant:       def.Variant,
+			Description:   def.Description,
+			Owner:         def.Owner,
+			Provider:      def.Provider,
+			Status:        &pb.ResourceStatus{Status: pb.ResourceStatus_CREATED},
+			Label:         def.Label.Serialize(),
+			Features:      def.Features.Serialize(),
+			Schedule:      def.Schedule,
+			Tags:          &pb.Tags{Tag: def.Tags},
+			Properties:    def.Properties.Serialize(),
+			Type:          TrainingSetTypeToProto(def.Type),
+			PersistAs:     def.PersistAs.Serialize(),
+			Labels:        labels,
+			Split:         def.Split.Serialize(),
+			MaxFeatureAge: maxFeatureAge,
 		},
 		RequestId: requestID.String(),
 	}
@@ -681,8 +1105,8 @@ func (def TrainingSetDef) Serialize(requestID logging.RequestID) *pb.TrainingSet
 func (client *Client) CreateTrainingSetVariant(ctx context.Context, def TrainingSetDef) error {
 	requestID := logging.GetRequestIDFromContext(ctx)
 	serialized := def.Serialize(requestID)
-	_, err := client.GrpcConn.CreateTrainingSetVariant(ctx, serialized)
-	return err
+	serialized.IdempotencyKey = uuid.NewString()
+	return sendCreate(ctx, serialized, client.GrpcConn.CreateTrainingSetVariant)
 }
 
 func (client *Client) GetTrainingSetVariant(ctx context.Context, id NameVariant) (*TrainingSetVariant, error) {
@@ -758,6 +1182,20 @@ func (client *Client) ListSources(ctx context.Context) ([]*Source, error) {
 	return client.parseSourceStream(stream)
 }
 
+func (client *Client) ListSourcesPage(ctx context.Context, pageSize int, pageToken string) ([]*Source, string, error) {
+	logger := logging.GetLoggerFromContext(ctx)
+	stream, err := client.GrpcConn.ListSources(ctx, listPageRequest(ctx, pageSize, pageToken))
+	if err != nil {
+		logger.Errorw("Failed to list sources", "error", err)
+		return nil, "", err
+	}
+	sources, err := client.parseSourceStream(stream)
+	if err != nil {
+		return nil, "", err
+	}
+	return sources, nextPageToken(stream), nil
+}
+
 func (client *Client) GetSource(ctx context.Context, source string) (*Source, error) {
 	sourceList, err := client.GetSources(ctx, []string{source})
 	if err != nil {
@@ -823,6 +1261,10 @@ type TransformationType interface {
 type SQLTransformationType struct {
 	Query   string
 	Sources NameVariants
+	// IncrementalColumn makes updates append only the rows whose value in this
+	// column is greater than the last run's, instead of rebuilding the
+	// transformation.
+	IncrementalColumn string
 }
 
 type PrimaryDataSource struct {
@@ -853,8 +1295,9 @@ func (t TransformationSource) Serialize() (*pb.SourceVariant_Transformation, err
 		transformation = &pb.Transformation{
 			Type: &pb.Transformation_SQLTransformation{
 				SQLTransformation: &pb.SQLTransformation{
-					Query:  t.TransformationType.(SQLTransformationType).Query,
-					Source: t.TransformationType.(SQLTransformationType).Sources.Serialize(),
+					Query:             t.TransformationType.(SQLTransformationType).Query,
+					Source:            t.TransformationType.(SQLTransformationType).Sources.Serialize(),
+					IncrementalColumn: t.TransformationType.(SQLTransformationType).IncrementalColumn,
 				},
 			},
 		}
@@ -940,8 +1383,8 @@ func (client *Client) CreateSourceVariant(ctx context.Context, def SourceDef) er
 	if err != nil {
 		return err
 	}
-	_, err = client.GrpcConn.CreateSourceVariant(ctx, serialized)
-	return err
+	serialized.IdempotencyKey = uuid.NewString()
+	return sendCreate(ctx, serialized, client.GrpcConn.CreateSourceVariant)
 }
 
 func (client *Client) GetSourceVariants(ctx context.Context, ids []NameVariant) ([]*SourceVariant, error) {
@@ -999,6 +1442,123 @@ func (client *Client) FinalizeDelete(ctx context.Context, resId ResourceID) erro
 	return err
 }
 
+// GetLineage returns the upstream and downstream dependency graph of a resource,
+// following at most maxDepth hops in each direction. A maxDepth of zero follows
+// the whole graph.
+func (client *Client) GetLineage(ctx context.Context, id ResourceID, maxDepth int) (*Lineage, error) {
+	resp, err := client.GrpcConn.GetLineage(ctx, &pb.GetLineageRequest{
+		ResourceId: id.Proto(),
+		MaxDepth:   int32(maxDepth),
+		RequestId:  logging.GetRequestIDFromContext(ctx).String(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return parseLineage(resp), nil
+}
+
+// DeleteResourceVariant deletes a variant that nothing depends on. It returns a
+// ResourceHasDependentsError listing the blockers otherwise.
+func (client *Client) DeleteResourceVariant(ctx context.Context, resId ResourceID) error {
+	_, err := client.GrpcConn.DeleteResourceVariant(ctx, &pb.DeleteResourceVariantRequest{
+		ResourceId: resId.Proto(),
+		RequestId:  logging.GetRequestIDFromContext(ctx).String(),
+	})
+	return err
+}
+
+// ForceDeleteResourceVariant deletes a variant and prunes every variant that
+// depends on it. confirm must be the variant's name. It returns the deleted
+// resources.
+func (client *Client) ForceDeleteResourceVariant(ctx context.Context, resId ResourceID, confirm string) ([]ResourceID, error) {
+	resp, err := client.GrpcConn.DeleteResourceVariant(ctx, &pb.DeleteResourceVariantRequest{
+		ResourceId: resId.Proto(),
+		Force:      true,
+		Confirm:    confirm,
+		RequestId:  logging.GetRequestIDFromContext(ctx).String(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	deleted := make([]ResourceID, len(resp.Deleted))
+	for i, id := range resp.Deleted {
+		deleted[i] = parseResourceIDProto(id)
+	}
+	return deleted, nil
+}
+
+// ArchiveResourceVariant hides a variant from listings and equivalence checks
+// while keeping it for lineage.
+func (client *Client) ArchiveResourceVariant(ctx context.Context, resId ResourceID) error {
+	_, err := client.GrpcConn.ArchiveResourceVariant(ctx, &pb.ArchiveResourceVariantRequest{ResourceId: resId.Proto()})
+	return err
+}
+
+func (client *Client) UnarchiveResourceVariant(ctx context.Context, resId ResourceID) error {
+	_, err := client.GrpcConn.ArchiveResourceVariant(ctx, &pb.ArchiveResourceVariantRequest{ResourceId: resId.Proto(), Unarchive: true})
+	return err
+}
+
+func (client *Client) ListArchived(ctx context.Context, t ResourceType) ([]ResourceID, error) {
+	resp, err := client.GrpcConn.ListArchived(ctx, &pb.ListArchivedRequest{
+		ResourceType: t.Serialized(),
+		RequestId:    logging.GetRequestIDFromContext(ctx).String(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]ResourceID, len(resp.ResourceIds))
+	for i, id := range resp.ResourceIds {
+		ids[i] = parseResourceIDProto(id)
+	}
+	return ids, nil
+}
+
+func (client *Client) SetSourceProfile(ctx context.Context, source NameVariant, profile *pb.SourceProfile) error {
+	_, err := client.GrpcConn.SetSourceProfile(ctx, &pb.SetSourceProfileRequest{
+		Source:    source.Serialize(),
+		Profile:   profile,
+		RequestId: logging.GetRequestIDFromContext(ctx).String(),
+	})
+	return err
+}
+
+// AddTags adds tags to a variant and returns all of its tags.
+func (client *Client) AddTags(ctx context.Context, resId ResourceID, tags ...string) (Tags, error) {
+	resp, err := client.GrpcConn.AddTags(ctx, &pb.UpdateTagsRequest{
+		ResourceId: resId.Proto(),
+		Tags:       tags,
+		RequestId:  logging.GetRequestIDFromContext(ctx).String(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return resp.Tags, nil
+}
+
+// RemoveTags removes tags from a variant and returns the tags it has left.
+func (client *Client) RemoveTags(ctx context.Context, resId ResourceID, tags ...string) (Tags, error) {
+	resp, err := client.GrpcConn.RemoveTags(ctx, &pb.UpdateTagsRequest{
+		ResourceId: resId.Proto(),
+		Tags:       tags,
+		RequestId:  logging.GetRequestIDFromContext(ctx).String(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return resp.Tags, nil
+}
+
+// ProfileSource starts a task that profiles a source variant, returning the
+// task and run that it's profiled in.
+func (client *Client) ProfileSource(ctx context.Context, source NameVariant, sampleSize int64) (*pb.ProfileSourceResponse, error) {
+	return client.GrpcConn.ProfileSource(ctx, &pb.ProfileSourceRequest{
+		Source:     source.Serialize(),
+		SampleSize: sampleSize,
+		RequestId:  logging.GetRequestIDFromContext(ctx).String(),
+	})
+}
+
 type sourceStream interface {
 	Recv() (*pb.Source, error)
 }
@@ -1104,10 +1664,8 @@ func (def UserDef) ResourceID() ResourceID {
 	}
 }
 
-func (client *Client) CreateUser(ctx context.Context, def UserDef) error {
-	requestID := logging.GetRequestIDFromContext(ctx)
-
-	serialized := &pb.UserRequest{
+func (def UserDef) Serialize(requestID logging.RequestID) *pb.UserRequest {
+	return &pb.UserRequest{
 		User: &pb.User{
 			Name:       def.Name,
 			Tags:       &pb.Tags{Tag: def.Tags},
@@ -1115,9 +1673,13 @@ func (client *Client) CreateUser(ctx context.Context, def UserDef) error {
 		},
 		RequestId: requestID.String(),
 	}
+}
 
-	_, err := client.GrpcConn.CreateUser(ctx, serialized)
-	return err
+func (client *Client) CreateUser(ctx context.Context, def UserDef) error {
+	requestID := logging.GetRequestIDFromContext(ctx)
+	serialized := def.Serialize(requestID)
+	serialized.IdempotencyKey = uuid.NewString()
+	return sendCreate(ctx, serialized, client.GrpcConn.CreateUser)
 }
 
 type userStream interface {
@@ -1201,10 +1763,8 @@ func (def ProviderDef) ResourceID() ResourceID {
 	}
 }
 
-func (client *Client) CreateProvider(ctx context.Context, def ProviderDef) error {
-	requestID := logging.GetRequestIDFromContext(ctx)
-
-	serialized := &pb.ProviderRequest{
+func (def ProviderDef) Serialize(requestID logging.RequestID) *pb.ProviderRequest {
+	return &pb.ProviderRequest{
 		Provider: &pb.Provider{
 			Name:             def.Name,
 			Description:      def.Description,
@@ -1218,9 +1778,13 @@ func (client *Client) CreateProvider(ctx context.Context, def ProviderDef) error
 		},
 		RequestId: requestID.String(),
 	}
+}
 
-	_, err := client.GrpcConn.CreateProvider(ctx, serialized)
-	return err
+func (client *Client) CreateProvider(ctx context.Context, def ProviderDef) error {
+	requestID := logging.GetRequestIDFromContext(ctx)
+	serialized := def.Serialize(requestID)
+	serialized.IdempotencyKey = uuid.NewString()
+	return sendCreate(ctx, serialized, client.GrpcConn.CreateProvider)
 }
 
 type providerStream interface {
@@ -1297,9 +1861,8 @@ func (def EntityDef) ResourceID() ResourceID {
 
 }
 
-func (client *Client) CreateEntity(ctx context.Context, def EntityDef) error {
-	requestID := logging.GetRequestIDFromContext(ctx)
-	serialized := &pb.EntityRequest{
+func (def EntityDef) Serialize(requestID logging.RequestID) *pb.EntityRequest {
+	return &pb.EntityRequest{
 		Entity: &pb.Entity{
 			Name:        def.Name,
 			Status:      &pb.ResourceStatus{Status: pb.ResourceStatus_NO_STATUS},
@@ -1309,9 +1872,13 @@ func (client *Client) CreateEntity(ctx context.Context, def EntityDef) error {
 		},
 		RequestId: requestID.String(),
 	}
+}
 
-	_, err := client.GrpcConn.CreateEntity(ctx, serialized)
-	return err
+func (client *Client) CreateEntity(ctx context.Context, def EntityDef) error {
+	requestID := logging.GetRequestIDFromContext(ctx)
+	serialized := def.Serialize(requestID)
+	serialized.IdempotencyKey = uuid.NewString()
+	return sendCreate(ctx, serialized, client.GrpcConn.CreateEntity)
 }
 
 type entityStream interface {
@@ -1493,9 +2060,8 @@ func (def ModelDef) ResourceID() ResourceID {
 	}
 }
 
-func (client *Client) CreateModel(ctx context.Context, def ModelDef) error {
-	requestID := logging.GetRequestIDFromContext(ctx)
-	serialized := &pb.ModelRequest{
+func (def ModelDef) Serialize(requestID logging.RequestID) *pb.ModelRequest {
+	return &pb.ModelRequest{
 		Model: &pb.Model{
 			Name:         def.Name,
 			Description:  def.Description,
@@ -1506,8 +2072,13 @@ func (client *Client) CreateModel(ctx context.Context, def ModelDef) error {
 		},
 		RequestId: requestID.String(),
 	}
-	_, err := client.GrpcConn.CreateModel(ctx, serialized)
-	return err
+}
+
+func (client *Client) CreateModel(ctx context.Context, def ModelDef) error {
+	requestID := logging.GetRequestIDFromContext(ctx)
+	serialized := def.Serialize(requestID)
+	serialized.IdempotencyKey = uuid.NewString()
+	return sendCreate(ctx, serialized, client.GrpcConn.CreateModel)
 }
 
 type modelStream interface {
@@ -1831,6 +2402,12 @@ func WrapProtoFeature(serialized *pb.Feature) *Feature {
 	}
 }
 
+// TypeChanges is the history of value type changes between the feature's
+// default variants, oldest first.
+func (feature Feature) TypeChanges() []*pb.FeatureTypeChange {
+	return feature.serialized.GetTypeChanges()
+}
+
 func (feature Feature) FetchVariants(client *Client, ctx context.Context) ([]*FeatureVariant, error) {
 	return client.GetFeatureVariants(ctx, feature.NameVariants())
 }
@@ -2038,6 +2615,11 @@ func (variant *FeatureVariant) Tags() Tags {
 	return variant.fetchTagsFn.Tags()
 }
 
+// IsArchived is true if the variant is hidden from listings and search.
+func (variant *FeatureVariant) IsArchived() bool {
+	return variant.serialized.GetArchived()
+}
+
 func (variant *FeatureVariant) Properties() Properties {
 	return variant.fetchPropertiesFn.Properties()
 }
@@ -2046,6 +2628,99 @@ func (variant *FeatureVariant) Mode() ComputationMode {
 	return ComputationMode(variant.serialized.GetMode())
 }
 
+// TTL is how long online values last after they're written. Zero means they
+// never expire.
+func (variant *FeatureVariant) TTL() time.Duration {
+	return variant.serialized.GetTtl().AsDuration()
+}
+
+// AllowBreaking is true if the variant was allowed to change its type in a way
+// that isn't backward compatible with the previous default variant.
+func (variant *FeatureVariant) AllowBreaking() bool {
+	return variant.serialized.GetAllowBreaking()
+}
+
+// MaterializationFilter is the SQL predicate that limits which of the source's
+// rows are materialized, or empty if they all are.
+func (variant *FeatureVariant) MaterializationFilter() string {
+	return variant.serialized.GetMaterializationFilter()
+}
+
+// HasDefaultValue is true if the variant serves a default value for entities
+// without a value in the online store.
+func (variant *FeatureVariant) HasDefaultValue() bool {
+	return variant.serialized.GetDefaultValue() != ""
+}
+
+// DefaultValue is the value served for entities without a value in the online
+// store, decoded to the variant's type.
+func (variant *FeatureVariant) DefaultValue() (interface{}, error) {
+	return featureDefaultValue(variant.serialized)
+}
+
+func featureDefaultValue(variant *pb.FeatureVariant) (interface{}, error) {
+	valueType, err := types.ValueTypeFromProto(variant.GetType())
+	if err != nil {
+		return nil, err
+	}
+	return types.ParseJSONValue(valueType, variant.GetDefaultValue())
+}
+
+// ColumnCasts are the types source columns are cast to when the variant is
+// registered from its source.
+func (variant *FeatureVariant) ColumnCasts() (map[string]types.ScalarType, error) {
+	return parseColumnCasts(variant.serialized.GetColumnCasts())
+}
+
+// TimestampFormat is how the source's timestamp column is parsed, or nil if it's
+// already a timestamp.
+func (variant *FeatureVariant) TimestampFormat() *TimestampFormat {
+	return parseTimestampFormat(variant.serialized.GetTimestampFormat())
+}
+
+// MaterializationPartition is how the variant's materialized files are
+// partitioned, or nil if they aren't.
+func (variant *FeatureVariant) MaterializationPartition() *MaterializationPartition {
+	return parseMaterializationPartition(variant.serialized.GetMaterializationPartition())
+}
+
+func serializeColumnCasts(casts map[string]types.ScalarType) (map[string]*pb.ValueType, error) {
+	if len(casts) == 0 {
+		return nil, nil
+	}
+	serialized := make(map[string]*pb.ValueType, len(casts))
+	for column, scalar := range casts {
+		if _, err := scalar.ToProtoEnum(); err != nil {
+			return nil, fferr.NewInvalidArgumentErrorf("column %s can't be cast to unknown type %s", column, scalar)
+		}
+		serialized[column] = scalar.ToProto()
+	}
+	return serialized, nil
+}
+
+func parseColumnCasts(serialized map[string]*pb.ValueType) (map[string]types.ScalarType, error) {
+	casts := make(map[string]types.ScalarType, len(serialized))
+	for column, protoType := range serialized {
+		valueType, err := types.ValueTypeFromProto(protoType)
+		if err != nil {
+			return nil, err
+		}
+		if valueType.IsVector() {
+			return nil, fferr.NewInvalidArgumentErrorf("column %s can't be cast to vector type %s", column, valueType)
+		}
+		casts[column] = valueType.Scalar()
+	}
+	return casts, nil
+}
+
+func (variant *FeatureVariant) TaskIDs() ([]scheduling.TaskID, error) {
+	// Check if using a deprecated taskID singleton
+	if variant.serialized.TaskId != "" {
+		return parseResourceTasks([]string{variant.serialized.TaskId})
+	}
+	return parseResourceTasks(variant.serialized.TaskIdList)
+}
+
 func (variant *FeatureVariant) IsOnDemand() bool {
 	switch variant.Mode() {
 	case PRECOMPUTED, STREAMING:
@@ -2470,6 +3145,11 @@ func (variant *LabelVariant) Tags() Tags {
 	return variant.fetchTagsFn.Tags()
 }
 
+// IsArchived is true if the variant is hidden from listings and search.
+func (variant *LabelVariant) IsArchived() bool {
+	return variant.serialized.GetArchived()
+}
+
 func (variant *LabelVariant) Properties() Properties {
 	return variant.fetchPropertiesFn.Properties()
 }
@@ -2488,6 +3168,18 @@ func (variant *LabelVariant) ResourceSnowflakeConfig() (*ResourceSnowflakeConfig
 	return getResourceSnowflakeConfig(variant.serialized)
 }
 
+// ColumnCasts are the types source columns are cast to when the variant is
+// registered from its source.
+func (variant *LabelVariant) ColumnCasts() (map[string]types.ScalarType, error) {
+	return parseColumnCasts(variant.serialized.GetColumnCasts())
+}
+
+// TimestampFormat is how the source's timestamp column is parsed, or nil if it's
+// already a timestamp.
+func (variant *LabelVariant) TimestampFormat() *TimestampFormat {
+	return parseTimestampFormat(variant.serialized.GetTimestampFormat())
+}
+
 type TrainingSet struct {
 	serialized *pb.TrainingSet
 	variantsFns
@@ -2580,6 +3272,15 @@ func (variant *TrainingSetVariant) Label() NameVariant {
 	return parseNameVariant(variant.serialized.GetLabel())
 }
 
+// Labels are every label joined into the training set, the first of which is
+// Label.
+func (variant *TrainingSetVariant) Labels() NameVariants {
+	if len(variant.serialized.GetLabels()) == 0 {
+		return NameVariants{variant.Label()}
+	}
+	return parseNameVariants(variant.serialized.GetLabels())
+}
+
 func (variant *TrainingSetVariant) LagFeatures() []*pb.FeatureLag {
 	return variant.serialized.GetFeatureLags()
 }
@@ -2596,6 +3297,11 @@ func (variant *TrainingSetVariant) Tags() Tags {
 	return variant.fetchTagsFn.Tags()
 }
 
+// IsArchived is true if the variant is hidden from listings and search.
+func (variant *TrainingSetVariant) IsArchived() bool {
+	return variant.serialized.GetArchived()
+}
+
 func (variant *TrainingSetVariant) Properties() Properties {
 	return variant.fetchPropertiesFn.Properties()
 }
@@ -2604,6 +3310,37 @@ func (variant *TrainingSetVariant) ResourceSnowflakeConfig() (*ResourceSnowflake
 	return getResourceSnowflakeConfig(variant.serialized)
 }
 
+// PersistAs is the table the training set is copied into, or nil if it isn't.
+func (variant *TrainingSetVariant) PersistAs() *TrainingSetPersistAs {
+	persist := variant.serialized.GetPersistAs()
+	if persist == nil {
+		return nil
+	}
+	return &TrainingSetPersistAs{
+		Table:     persist.GetTable(),
+		Overwrite: persist.GetOverwrite(),
+		Dedup:     persist.GetDedup(),
+	}
+}
+
+// Split is the training set's train/test split, or nil if it isn't split.
+func (variant *TrainingSetVariant) Split() *TrainingSetSplit {
+	split := variant.serialized.GetSplit()
+	if split == nil {
+		return nil
+	}
+	return &TrainingSetSplit{
+		TrainFraction: split.GetTrainFraction(),
+		Seed:          split.GetSeed(),
+	}
+}
+
+// MaxFeatureAge is the oldest a feature value may be relative to its label's
+// timestamp, or zero if feature values never expire.
+func (variant *TrainingSetVariant) MaxFeatureAge() time.Duration {
+	return variant.serialized.GetMaxFeatureAge().AsDuration()
+}
+
 func (variant *TrainingSetVariant) TrainingSetType() TrainingSetType {
 	logger := logging.GlobalLogger.Named("TrainingSetType")
 	typ, err := TrainingSetTypeFromProto(variant.serialized.GetType())
@@ -3061,6 +3798,7 @@ func (variant *SourceVariant) ToShallowMap() SourceVariantResource {
 		Error:          variant.Error(),
 		Specifications: getSourceArgs(variant),
 		Inputs:         variant.getInputs(),
+		Profile:        getSourceProfile(variant),
 	}
 }
 
@@ -3072,6 +3810,11 @@ func (variant *SourceVariant) Schedule() string {
 	return variant.serialized.GetSchedule()
 }
 
+// Profile returns the variant's column profile, or nil if it hasn't been profiled.
+func (variant *SourceVariant) Profile() *pb.SourceProfile {
+	return variant.serialized.GetProfile()
+}
+
 func (variant *SourceVariant) Variant() string {
 	return variant.serialized.GetVariant()
 }
@@ -3141,6 +3884,15 @@ func (variant *SourceVariant) SQLTransformationQuery() string {
 	return variant.serialized.GetTransformation().GetSQLTransformation().GetQuery()
 }
 
+// SQLTransformationIncrementalColumn is the column that updates to the SQL
+// transformation append new rows by, or empty if updates rebuild it.
+func (variant *SourceVariant) SQLTransformationIncrementalColumn() string {
+	if !variant.IsSQLTransformation() {
+		return ""
+	}
+	return variant.serialized.GetTransformation().GetSQLTransformation().GetIncrementalColumn()
+}
+
 func (variant *SourceVariant) SQLTransformationSources() []NameVariant {
 	if !variant.IsSQLTransformation() {
 		return nil
@@ -3237,9 +3989,15 @@ func (variant *SourceVariant) GetPrimaryLocation() (pl.Location, error) {
 		if err := fp.ParseFilePath(pt.Filestore.GetPath()); err != nil {
 			return nil, err
 		}
-		return pl.NewFileLocation(&fp), nil
+		csvOpts := pl.CSVOptionsFromProto(pt.Filestore.GetCsvOptions())
+		if err := csvOpts.Validate(); err != nil {
+			return nil, err
+		}
+		return pl.NewCSVFileLocation(&fp, csvOpts), nil
 	case *pb.PrimaryData_Catalog:
 		return pl.NewCatalogLocation(pt.Catalog.GetDatabase(), pt.Catalog.GetTable(), pt.Catalog.GetTableFormat()), nil
+	case *pb.PrimaryData_Kafka:
+		return pl.NewKafkaLocation(pt.Kafka.GetTopic()), nil
 	default:
 		fmt.Printf("Default case. Unknown primary data type: %v\n", reflect.TypeOf(pt))
 		return nil, nil
@@ -3323,10 +4081,28 @@ func (variant *SourceVariant) SparkFlags() pc.SparkFlags {
 	}
 }
 
+// SparkSubmitOptions are the extra --conf values and --packages passed to
+// spark-submit when the transformation runs on Spark.
+func (variant *SourceVariant) SparkSubmitOptions() pc.SparkSubmitOptions {
+	if !variant.IsTransformation() {
+		return pc.SparkSubmitOptions{}
+	}
+	sparkFlagsProto := variant.serialized.GetTransformation().GetSparkFlags()
+	return pc.SparkSubmitOptions{
+		Configs:  sparkFlagsProto.GetSubmitConfigs(),
+		Packages: sparkFlagsProto.GetSubmitPackages(),
+	}
+}
+
 func (variant *SourceVariant) Tags() Tags {
 	return variant.fetchTagsFn.Tags()
 }
 
+// IsArchived is true if the variant is hidden from listings and search.
+func (variant *SourceVariant) IsArchived() bool {
+	return variant.serialized.GetArchived()
+}
+
 func (variant *SourceVariant) Properties() Properties {
 	return variant.fetchPropertiesFn.Properties()
 }
@@ -3398,9 +4174,17 @@ func (entity *Entity) Properties() Properties {
 	return entity.fetchPropertiesFn.Properties()
 }
 
+// NewClient connects to the metadata server at host, using mTLS if it's
+// configured in the environment.
 func NewClient(host string, logger logging.Logger) (*Client, error) {
+	credsOpt, err := grpctls.FromEnv().DialOption()
+	if err != nil {
+		return nil, err
+	}
 	opts := []grpc.DialOption{
-		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		credsOpt,
+		grpc.WithChainUnaryInterceptor(interceptors.UnaryClientTracingInterceptor, interceptors.UnaryClientAuthForwardingInterceptor),
+		grpc.WithChainStreamInterceptor(interceptors.StreamClientTracingInterceptor, interceptors.StreamClientAuthForwardingInterceptor),
 	}
 	conn, err := grpc.Dial(host, opts...)
 	if err != nil {
@@ -109,6 +109,40 @@ func TestGetVersionMap(t *testing.T) {
 	assert.Equal(t, version, data["version"])
 }
 
+type recordingSearcher struct {
+	search.SearchMock
+	filters search.SearchFilters
+}
+
+func (s *recordingSearcher) Search(q string, filters search.SearchFilters) (search.SearchResults, error) {
+	s.filters = filters
+	return search.SearchResults{
+		Groups: []search.SearchResultGroup{{Type: "Feature", Count: 1, Resources: []search.ResourceDoc{{Name: q, Type: "Feature"}}}},
+	}, nil
+}
+
+func TestGetGroupedSearch(t *testing.T) {
+	mockRecorder := httptest.NewRecorder()
+	ctx := GetTestGinContext(mockRecorder)
+	searcher := &recordingSearcher{}
+	SearchClient = searcher
+	MockGet(ctx, url.Values{"q": {"fraud"}, "type": {"Feature", "Label"}, "tag": {"prod"}}, nil, "")
+	serv := GetMetadataServer(t)
+	serv.GetGroupedSearch(ctx)
+
+	assert.Equal(t, http.StatusOK, mockRecorder.Code)
+	assert.Equal(t, search.SearchFilters{Types: []string{"Feature", "Label"}, Tags: []string{"prod"}}, searcher.filters)
+	var data search.SearchResults
+	assert.NoError(t, json.Unmarshal(mockRecorder.Body.Bytes(), &data))
+	assert.Equal(t, "fraud", data.Groups[0].Resources[0].Name)
+
+	mockRecorder = httptest.NewRecorder()
+	ctx = GetTestGinContext(mockRecorder)
+	MockGet(ctx, url.Values{}, nil, "")
+	serv.GetGroupedSearch(ctx)
+	assert.Equal(t, http.StatusBadRequest, mockRecorder.Code)
+}
+
 func TestPostTags(t *testing.T) {
 	name := "transactions"
 	variant := "default"
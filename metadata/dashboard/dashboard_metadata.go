@@ -20,6 +20,7 @@ import (
 	"github.com/featureform/config"
 	"github.com/featureform/fferr"
 	help "github.com/featureform/helpers"
+	"github.com/featureform/helpers/encryption"
 	"github.com/featureform/helpers/postgres"
 	"github.com/featureform/logging"
 	"github.com/featureform/metadata"
@@ -74,16 +75,21 @@ type MetadataServer struct {
 	client          *metadata.Client
 	logger          logging.Logger
 	StorageProvider storage.MetadataStorage
+	server          *http.Server
 }
 
-func NewMetadataServer(logger logging.Logger, client *metadata.Client, storageProvider storage.MetadataStorage) (*MetadataServer, error) {
+// NewMetadataServer creates the dashboard's metadata server. The encrypter must
+// be the one the metadata server stores provider configs with, so that encrypted
+// configs can be read; it can be nil if they're stored in plaintext.
+func NewMetadataServer(logger logging.Logger, client *metadata.Client, storageProvider storage.MetadataStorage, encrypter *encryption.Encrypter) (*MetadataServer, error) {
 	logger.Debug("Creating new metadata server")
 
 	return &MetadataServer{
 		client:          client,
 		logger:          logger,
 		StorageProvider: storageProvider,
-		lookup:          &metadata.MemoryResourceLookup{Connection: storageProvider},
+		lookup:          &metadata.MemoryResourceLookup{Connection: storageProvider, Encrypter: encrypter},
+		server:          &http.Server{},
 	}, nil
 }
 
@@ -169,10 +175,13 @@ func (m *MetadataServer) getTrainingSets(nameVariants []metadata.NameVariant) (m
 
 func (m *MetadataServer) getFeatures(nameVariants []metadata.NameVariant) (map[string][]metadata.FeatureVariantResource, error) {
 	featureMap := make(map[string][]metadata.FeatureVariantResource)
-	featureVariants, err := m.client.GetFeatureVariants(context.Background(), nameVariants)
+	featureVariants, variantErrs, err := m.client.BatchGetFeatureVariants(context.Background(), nameVariants)
 	if err != nil {
 		return nil, err
 	}
+	for id, variantErr := range variantErrs {
+		m.logger.Errorw("Could not get feature variant", "name", id.Name, "variant", id.Variant, "error", variantErr)
+	}
 	for _, variant := range featureVariants {
 		if _, has := featureMap[variant.Name()]; !has {
 			featureMap[variant.Name()] = []metadata.FeatureVariantResource{}
@@ -2092,6 +2101,27 @@ func (m *MetadataServer) GetSearch(c *gin.Context) {
 	c.JSON(http.StatusOK, result)
 }
 
+// GetGroupedSearch searches resources, optionally filtered by any number of
+// type and tag query params, and returns the results grouped by type.
+func (m *MetadataServer) GetGroupedSearch(c *gin.Context) {
+	query, ok := c.GetQuery("q")
+	if !ok {
+		c.JSON(http.StatusBadRequest, "Missing query")
+		return
+	}
+	filters := search.SearchFilters{
+		Types: c.QueryArray("type"),
+		Tags:  c.QueryArray("tag"),
+	}
+	result, err := SearchClient.Search(query, filters)
+	if err != nil {
+		m.logger.Errorw("Failed to fetch resources", "error", err)
+		c.JSON(http.StatusInternalServerError, "Failed to fetch resources")
+		return
+	}
+	c.JSON(http.StatusOK, result)
+}
+
 func (m *MetadataServer) GetVersionMap(c *gin.Context) {
 	versionMap := map[string]string{
 		"version": help.GetEnv("FEATUREFORM_VERSION", ""),
@@ -3067,6 +3097,7 @@ func (m *MetadataServer) Start(port string, local bool) error {
 	router.GET("/data/failrunning", m.FailRunningJobs)
 	router.GET("/data/:type/:resource", m.GetMetadata)
 	router.GET("/data/search", m.GetSearch)
+	router.GET("/data/search/grouped", m.GetGroupedSearch)
 	router.GET("/data/version", m.GetVersionMap)
 	router.GET("/data/sourcedata", m.GetSourceData)
 	router.POST("/data/:type/:resource/gettags", m.GetTags)
@@ -3084,5 +3115,13 @@ func (m *MetadataServer) Start(port string, local bool) error {
 	router.GET("/data/:type/prop/owners", m.GetTypeOwners)
 	router.GET("/data/stream", m.GetIcebergData)
 
-	return router.Run(port)
+	m.server.Addr = port
+	m.server.Handler = router
+	return m.server.ListenAndServe()
+}
+
+// Shutdown stops the server once its in-flight requests finish or ctx is done.
+// Start returns http.ErrServerClosed once it's called.
+func (m *MetadataServer) Shutdown(ctx context.Context) error {
+	return m.server.Shutdown(ctx)
 }
@@ -14,6 +14,7 @@ import (
 	"github.com/featureform/config"
 	"github.com/featureform/config/bootstrap"
 	help "github.com/featureform/helpers"
+	"github.com/featureform/helpers/encryption"
 	"github.com/featureform/logging"
 	"github.com/featureform/metadata"
 	dm "github.com/featureform/metadata/dashboard"
@@ -43,18 +44,9 @@ func main() {
 	defer logger.LogIfErr("Failed to close service-level resources", init.Close())
 	metadataHost := help.GetEnv("METADATA_HOST", "localhost")
 	metadataPort := help.GetEnv("METADATA_PORT", "8080")
-	searchHost := help.GetEnv("MEILISEARCH_HOST", "localhost")
-	searchPort := help.GetEnv("MEILISEARCH_PORT", "7700")
-	searchEndpoint := fmt.Sprintf("http://%s:%s", searchHost, searchPort)
-	searchApiKey := help.GetEnv("MEILISEARCH_APIKEY", "")
-	logger.Infof("Connecting to typesense at: %s\n", searchEndpoint)
-	sc, err := search.NewMeilisearch(&search.MeilisearchParams{
-		Host:   searchHost,
-		Port:   searchPort,
-		ApiKey: searchApiKey,
-	})
+	sc, err := search.NewSearcherFromEnv()
 	if err != nil {
-		logger.Panicw("Failed to create new meil search", err)
+		logger.Panicw("Failed to connect to search", "error", err)
 	}
 	dm.SearchClient = sc
 	metadataAddress := fmt.Sprintf("%s:%s", metadataHost, metadataPort)
@@ -70,7 +62,12 @@ func main() {
 		panic(err.Error())
 	}
 
-	metadataServer, err := dm.NewMetadataServer(logger, client, manager.Storage)
+	encrypter, err := encryption.FromEnv(initCtx)
+	if err != nil {
+		logger.Panicw("Failed to create provider config encrypter", "error", err)
+	}
+
+	metadataServer, err := dm.NewMetadataServer(logger, client, manager.Storage, encrypter)
 	if err != nil {
 		logger.Panicw("Failed to create server", "error", err)
 	}
@@ -8,8 +8,10 @@
 package equivalence
 
 import (
+	"github.com/featureform/fferr"
 	"github.com/featureform/logging"
 	pb "github.com/featureform/metadata/proto"
+	"github.com/featureform/provider/types"
 )
 
 var logger = logging.NewLogger("equivalence")
@@ -42,6 +44,38 @@ func (n nameVariant) IsEquivalent(other Equivalencer) bool {
 	return n.Name == otherNameVariant.Name && n.Variant == otherNameVariant.Variant
 }
 
+// columnCastsFromProto returns nil if there are no casts, so unset and empty
+// casts are equivalent.
+func columnCastsFromProto(proto map[string]*pb.ValueType) (map[string]types.ValueType, error) {
+	if len(proto) == 0 {
+		return nil, nil
+	}
+	casts := make(map[string]types.ValueType, len(proto))
+	for column, protoType := range proto {
+		valueType, err := types.ValueTypeFromProto(protoType)
+		if err != nil {
+			return nil, fferr.NewParsingError(err)
+		}
+		casts[column] = valueType
+	}
+	return casts, nil
+}
+
+type timestampFormat struct {
+	Encoding string
+	Timezone string
+}
+
+func timestampFormatFromProto(proto *pb.TimestampFormat) *timestampFormat {
+	if proto == nil {
+		return nil
+	}
+	return &timestampFormat{
+		Encoding: proto.Encoding,
+		Timezone: proto.Timezone,
+	}
+}
+
 type resourceSnowflakeConfig struct {
 	DynamicTableConfig snowflakeDynamicTableConfig
 	Warehouse          string
@@ -9,6 +9,7 @@ package equivalence
 
 import (
 	"reflect"
+	"time"
 
 	"github.com/featureform/fferr"
 	pb "github.com/featureform/metadata/proto"
@@ -23,6 +24,27 @@ type featureVariant struct {
 	ComputationMode         string // TODO move definition from metadata to common
 	Location                featureLocation
 	ResourceSnowflakeConfig resourceSnowflakeConfig
+	TTL                     time.Duration
+	MaterializationFilter   string
+	DefaultValue            string
+	ColumnCasts             map[string]types.ValueType
+	TimestampFormat         *timestampFormat
+	Partition               *materializationPartition
+}
+
+type materializationPartition struct {
+	Type    string
+	Buckets int32
+}
+
+func materializationPartitionFromProto(proto *pb.MaterializationPartition) *materializationPartition {
+	if proto == nil {
+		return nil
+	}
+	return &materializationPartition{
+		Type:    proto.Type,
+		Buckets: proto.Buckets,
+	}
 }
 
 func FeatureVariantFromProto(proto *pb.FeatureVariant) (featureVariant, error) {
@@ -44,6 +66,10 @@ func FeatureVariantFromProto(proto *pb.FeatureVariant) (featureVariant, error) {
 	if err != nil {
 		return featureVariant{}, fferr.NewParsingError(err)
 	}
+	columnCasts, err := columnCastsFromProto(proto.GetColumnCasts())
+	if err != nil {
+		return featureVariant{}, err
+	}
 
 	return featureVariant{
 		Name:                    proto.Name,
@@ -52,6 +78,12 @@ func FeatureVariantFromProto(proto *pb.FeatureVariant) (featureVariant, error) {
 		ComputationMode:         proto.Mode.String(),
 		Location:                location,
 		ResourceSnowflakeConfig: resourceSnowflakeConfigFromProto(proto.ResourceSnowflakeConfig),
+		TTL:                     proto.GetTtl().AsDuration(),
+		MaterializationFilter:   proto.GetMaterializationFilter(),
+		DefaultValue:            proto.GetDefaultValue(),
+		ColumnCasts:             columnCasts,
+		TimestampFormat:         timestampFormatFromProto(proto.GetTimestampFormat()),
+		Partition:               materializationPartitionFromProto(proto.GetMaterializationPartition()),
 	}, nil
 }
 
@@ -68,6 +100,12 @@ func (f featureVariant) IsEquivalent(other Equivalencer) bool {
 				f1.ValueType == f2.ValueType &&
 				f1.ComputationMode == f2.ComputationMode &&
 				f1.Location.IsEquivalent(f2.Location) &&
+				f1.TTL == f2.TTL &&
+				f1.MaterializationFilter == f2.MaterializationFilter &&
+				f1.DefaultValue == f2.DefaultValue &&
+				reflect.DeepEqual(f1.ColumnCasts, f2.ColumnCasts) &&
+				reflect.DeepEqual(f1.TimestampFormat, f2.TimestampFormat) &&
+				reflect.DeepEqual(f1.Partition, f2.Partition) &&
 				reflect.DeepEqual(f1.ResourceSnowflakeConfig, f2.ResourceSnowflakeConfig)
 		}),
 	}
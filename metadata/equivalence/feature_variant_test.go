@@ -10,6 +10,7 @@ package equivalence
 import (
 	pb "github.com/featureform/metadata/proto"
 	"testing"
+	"time"
 
 	"github.com/featureform/provider/types"
 	"github.com/stretchr/testify/assert"
@@ -219,6 +220,59 @@ func TestFeatureVariantIsEquivalent(t *testing.T) {
 			},
 			expected: false,
 		},
+		{
+			name: "Different TTLs",
+			fv1: featureVariant{
+				Name:     "Feature1",
+				Location: stream{OfflineProvider: "OfflineProvider1"},
+				TTL:      time.Hour,
+			},
+			fv2: featureVariant{
+				Name:     "Feature1",
+				Location: stream{OfflineProvider: "OfflineProvider1"},
+			},
+			expected: false,
+		},
+		{
+			name: "Different Materialization Filters",
+			fv1: featureVariant{
+				Name:                  "Feature1",
+				Location:              column{Entity: "Entity1", Value: "Value1"},
+				MaterializationFilter: "status = 'active'",
+			},
+			fv2: featureVariant{
+				Name:     "Feature1",
+				Location: column{Entity: "Entity1", Value: "Value1"},
+			},
+			expected: false,
+		},
+		{
+			name: "Different Materialization Partitions",
+			fv1: featureVariant{
+				Name:      "Feature1",
+				Location:  column{Entity: "Entity1", Value: "Value1"},
+				Partition: &materializationPartition{Type: "ENTITY_BUCKET", Buckets: 8},
+			},
+			fv2: featureVariant{
+				Name:      "Feature1",
+				Location:  column{Entity: "Entity1", Value: "Value1"},
+				Partition: &materializationPartition{Type: "ENTITY_BUCKET", Buckets: 16},
+			},
+			expected: false,
+		},
+		{
+			name: "Different Default Values",
+			fv1: featureVariant{
+				Name:         "Feature1",
+				Location:     column{Entity: "Entity1", Value: "Value1"},
+				DefaultValue: "0",
+			},
+			fv2: featureVariant{
+				Name:     "Feature1",
+				Location: column{Entity: "Entity1", Value: "Value1"},
+			},
+			expected: false,
+		},
 		{
 			name: "Different Types",
 			fv1: featureVariant{
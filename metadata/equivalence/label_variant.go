@@ -60,6 +60,8 @@ type labelVariant struct {
 	Type                    types.ValueType
 	ResourceSnowflakeConfig resourceSnowflakeConfig
 	EntityMappings          entityMappings
+	ColumnCasts             map[string]types.ValueType
+	TimestampFormat         *timestampFormat
 }
 
 func LabelVariantFromProto(proto *pb.LabelVariant) (labelVariant, error) {
@@ -67,6 +69,10 @@ func LabelVariantFromProto(proto *pb.LabelVariant) (labelVariant, error) {
 	if err != nil {
 		return labelVariant{}, fferr.NewParsingError(err)
 	}
+	columnCasts, err := columnCastsFromProto(proto.GetColumnCasts())
+	if err != nil {
+		return labelVariant{}, err
+	}
 
 	return labelVariant{
 		Name:                    proto.Name,
@@ -75,6 +81,8 @@ func LabelVariantFromProto(proto *pb.LabelVariant) (labelVariant, error) {
 		Type:                    valueType,
 		ResourceSnowflakeConfig: resourceSnowflakeConfigFromProto(proto.ResourceSnowflakeConfig),
 		EntityMappings:          entityMappingsFromProto(proto.GetEntityMappings()),
+		ColumnCasts:             columnCasts,
+		TimestampFormat:         timestampFormatFromProto(proto.GetTimestampFormat()),
 	}, nil
 }
 
@@ -92,7 +100,9 @@ func (l labelVariant) IsEquivalent(other Equivalencer) bool {
 				l1.Type == l2.Type &&
 				reflect.DeepEqual(l1.Columns, l2.Columns) &&
 				reflect.DeepEqual(l1.ResourceSnowflakeConfig, l2.ResourceSnowflakeConfig) &&
-				reflect.DeepEqual(l1.EntityMappings, l2.EntityMappings)
+				reflect.DeepEqual(l1.EntityMappings, l2.EntityMappings) &&
+				reflect.DeepEqual(l1.ColumnCasts, l2.ColumnCasts) &&
+				reflect.DeepEqual(l1.TimestampFormat, l2.TimestampFormat)
 		}),
 	}
 
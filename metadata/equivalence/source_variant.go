@@ -87,8 +87,13 @@ func primaryDataFromProto(proto *pb.PrimaryData) primaryData {
 			Schema:   l.Table.Schema,
 		}
 	case *pb.PrimaryData_Filestore:
+		csvOpts := l.Filestore.GetCsvOptions()
 		location = &fileStoreTable{
-			Path: l.Filestore.Path,
+			Path:         l.Filestore.Path,
+			CSVDelimiter: csvOpts.GetDelimiter(),
+			CSVQuote:     csvOpts.GetQuote(),
+			CSVEscape:    csvOpts.GetEscape(),
+			CSVNoHeader:  csvOpts.GetNoHeader(),
 		}
 	case *pb.PrimaryData_Catalog:
 		location = &catalogTable{
@@ -182,7 +187,11 @@ func (s *sqlTable) IsEquivalent(other Equivalencer) bool {
 }
 
 type fileStoreTable struct {
-	Path string
+	Path         string
+	CSVDelimiter string
+	CSVQuote     string
+	CSVEscape    string
+	CSVNoHeader  bool
 }
 
 func (f *fileStoreTable) IsLocationType() {}
@@ -192,7 +201,7 @@ func (f *fileStoreTable) IsEquivalent(other Equivalencer) bool {
 	if !ok {
 		return false
 	}
-	return f.Path == otherLoc.Path
+	return *f == *otherLoc
 }
 
 type catalogTable struct {
@@ -258,6 +267,7 @@ type sqlTransformation struct {
 	Sources                 []nameVariant
 	IncrementalSources      []nameVariant
 	ResourceSnowflakeConfig resourceSnowflakeConfig
+	IncrementalColumn       string
 }
 
 func sqlTransformationFromProto(proto *pb.SQLTransformation) sqlTransformation {
@@ -267,6 +277,7 @@ func sqlTransformationFromProto(proto *pb.SQLTransformation) sqlTransformation {
 		Query:                   proto.Query,
 		Sources:                 sources,
 		ResourceSnowflakeConfig: resourceSnowflakeConfigFromProto(proto.ResourceSnowflakeConfig),
+		IncrementalColumn:       proto.GetIncrementalColumn(),
 	}
 }
 
@@ -281,7 +292,8 @@ func (s sqlTransformation) IsEquivalent(other Equivalencer) bool {
 	return isSqlEqual(s.Query, otherSQL.Query) &&
 		reflect.DeepEqual(s.Sources, otherSQL.Sources) &&
 		reflect.DeepEqual(s.IncrementalSources, otherSQL.IncrementalSources) &&
-		reflect.DeepEqual(s.ResourceSnowflakeConfig, otherSQL.ResourceSnowflakeConfig)
+		reflect.DeepEqual(s.ResourceSnowflakeConfig, otherSQL.ResourceSnowflakeConfig) &&
+		s.IncrementalColumn == otherSQL.IncrementalColumn
 }
 
 // isSqlEqual checks if two SQL strings are equal after normalizing whitespace.
@@ -330,6 +330,17 @@ func TestSqlTransformationIsEquivalent(t *testing.T) {
 			},
 			expected: false,
 		},
+		{
+			name: "Different incremental columns",
+			sql1: sqlTransformation{
+				Query:             "SELECT * FROM table1",
+				IncrementalColumn: "event_ts",
+			},
+			sql2: sqlTransformation{
+				Query: "SELECT * FROM table1",
+			},
+			expected: false,
+		},
 	}
 
 	for _, tt := range tests {
@@ -452,6 +463,29 @@ func TestFileStoreTableIsEquivalent(t *testing.T) {
 			},
 			expected: false,
 		},
+		{
+			name: "Different CSV Delimiters",
+			table1: &fileStoreTable{
+				Path:         "/data/users.tsv",
+				CSVDelimiter: "\t",
+			},
+			table2: &fileStoreTable{
+				Path:         "/data/users.tsv",
+				CSVDelimiter: "|",
+			},
+			expected: false,
+		},
+		{
+			name: "Different CSV Header Toggle",
+			table1: &fileStoreTable{
+				Path: "/data/users.csv",
+			},
+			table2: &fileStoreTable{
+				Path:        "/data/users.csv",
+				CSVNoHeader: true,
+			},
+			expected: false,
+		},
 		{
 			name: "Different Types",
 			table1: &fileStoreTable{
@@ -9,6 +9,7 @@ package equivalence
 
 import (
 	"reflect"
+	"time"
 
 	"github.com/featureform/fferr"
 	"github.com/featureform/logging"
@@ -49,9 +50,45 @@ type trainingSetVariant struct {
 	Name                    string
 	Features                []nameVariant
 	Label                   nameVariant
+	Labels                  []nameVariant
 	LagFeatures             []featureLag
 	ResourceSnowflakeConfig resourceSnowflakeConfig
 	Type                    trainingSetType
+	PersistAs               *trainingSetPersistAs
+	Split                   *trainingSetSplit
+	MaxFeatureAge           time.Duration
+}
+
+type trainingSetPersistAs struct {
+	Table     string
+	Overwrite bool
+	Dedup     bool
+}
+
+func trainingSetPersistAsFromProto(proto *pb.TrainingSetPersistAs) *trainingSetPersistAs {
+	if proto == nil {
+		return nil
+	}
+	return &trainingSetPersistAs{
+		Table:     proto.Table,
+		Overwrite: proto.Overwrite,
+		Dedup:     proto.Dedup,
+	}
+}
+
+type trainingSetSplit struct {
+	TrainFraction float64
+	Seed          int64
+}
+
+func trainingSetSplitFromProto(proto *pb.TrainingSetSplit) *trainingSetSplit {
+	if proto == nil {
+		return nil
+	}
+	return &trainingSetSplit{
+		TrainFraction: proto.TrainFraction,
+		Seed:          proto.Seed,
+	}
 }
 
 func TrainingSetVariantFromProto(proto *pb.TrainingSetVariant) (trainingSetVariant, error) {
@@ -59,13 +96,23 @@ func TrainingSetVariantFromProto(proto *pb.TrainingSetVariant) (trainingSetVaria
 	if err != nil {
 		return trainingSetVariant{}, err
 	}
+	// A training set whose only label is in labels is the same as one that only
+	// sets label.
+	labels := nameVariantsFromProto(proto.Labels)
+	if len(labels) == 1 {
+		labels = nil
+	}
 	return trainingSetVariant{
 		Name:                    proto.Name,
 		Features:                nameVariantsFromProto(proto.Features),
 		Label:                   nameVariantFromProto(proto.Label),
+		Labels:                  labels,
 		LagFeatures:             featureLagsFromProto(proto.FeatureLags),
 		ResourceSnowflakeConfig: resourceSnowflakeConfigFromProto(proto.ResourceSnowflakeConfig),
 		Type:                    trainingSetType,
+		PersistAs:               trainingSetPersistAsFromProto(proto.PersistAs),
+		Split:                   trainingSetSplitFromProto(proto.Split),
+		MaxFeatureAge:           proto.GetMaxFeatureAge().AsDuration(),
 	}, nil
 }
 
@@ -81,8 +128,12 @@ func (t trainingSetVariant) IsEquivalent(other Equivalencer) bool {
 				reflect.DeepEqual(t1.Features, t2.Features) &&
 				reflect.DeepEqual(t1.LagFeatures, t2.LagFeatures) &&
 				t1.Label.IsEquivalent(t2.Label) &&
+				reflect.DeepEqual(t1.Labels, t2.Labels) &&
 				reflect.DeepEqual(t1.ResourceSnowflakeConfig, t2.ResourceSnowflakeConfig) &&
-				t1.Type == t2.Type
+				t1.Type == t2.Type &&
+				reflect.DeepEqual(t1.PersistAs, t2.PersistAs) &&
+				reflect.DeepEqual(t1.Split, t2.Split) &&
+				t1.MaxFeatureAge == t2.MaxFeatureAge
 		}),
 	}
 
@@ -166,6 +166,21 @@ func TestTrainingSetVariantIsEquivalent(t *testing.T) {
 			},
 			expected: false,
 		},
+		{
+			name: "Different Additional Labels",
+			ts1: trainingSetVariant{
+				Name:     "set1",
+				Label:    nameVariant{Name: "label1", Variant: "v1"},
+				Labels:   []nameVariant{{Name: "label1", Variant: "v1"}, {Name: "label2", Variant: "v1"}},
+				Features: []nameVariant{{Name: "feature1", Variant: "v1"}},
+			},
+			ts2: trainingSetVariant{
+				Name:     "set1",
+				Label:    nameVariant{Name: "label1", Variant: "v1"},
+				Features: []nameVariant{{Name: "feature1", Variant: "v1"}},
+			},
+			expected: false,
+		},
 	}
 
 	for _, tt := range tests {
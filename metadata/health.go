@@ -0,0 +1,145 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+//
+// Copyright 2024 FeatureForm Inc.
+//
+
+package metadata
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"github.com/featureform/fferr"
+	pb "github.com/featureform/metadata/proto"
+
+	"google.golang.org/grpc/codes"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+)
+
+const healthCheckTimeout = 5 * time.Second
+
+// HealthChecker reports whether the metadata server is ready, over both the
+// standard gRPC health service and the HTTP /healthz and /readyz endpoints.
+// It's created before the server so that probes get NOT_SERVING while the
+// lookup is still initializing rather than a connection error. Readiness
+// depends on the server's backends; liveness, served on /livez, doesn't.
+type HealthChecker struct {
+	healthpb.UnimplementedHealthServer
+	server atomic.Pointer[MetadataServer]
+}
+
+func NewHealthChecker() *HealthChecker {
+	return &HealthChecker{}
+}
+
+// SetServer marks the lookup as initialized; from then on the checker reports
+// the health of serv's backends.
+func (h *HealthChecker) SetServer(serv *MetadataServer) {
+	h.server.Store(serv)
+}
+
+// Healthy returns an error if the server is still initializing or any of its
+// backends are unreachable.
+func (h *HealthChecker) Healthy(ctx context.Context) error {
+	serv := h.server.Load()
+	if serv == nil {
+		return fferr.NewInternalErrorf("metadata server is initializing")
+	}
+	return serv.checkHealth(ctx)
+}
+
+func (h *HealthChecker) Check(ctx context.Context, req *healthpb.HealthCheckRequest) (*healthpb.HealthCheckResponse, error) {
+	if service := req.GetService(); service != "" && service != pb.Metadata_ServiceDesc.ServiceName {
+		return nil, status.Errorf(codes.NotFound, "unknown service %s", service)
+	}
+	if err := h.Healthy(ctx); err != nil {
+		return &healthpb.HealthCheckResponse{Status: healthpb.HealthCheckResponse_NOT_SERVING}, nil
+	}
+	return &healthpb.HealthCheckResponse{Status: healthpb.HealthCheckResponse_SERVING}, nil
+}
+
+func (h *HealthChecker) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), healthCheckTimeout)
+	defer cancel()
+	if err := h.Healthy(ctx); err != nil {
+		http.Error(w, err.Error(), http.StatusServiceUnavailable)
+		return
+	}
+	writeHealthOK(w)
+}
+
+// serveLiveness reports that the process is up. It doesn't check any
+// backends, so an outage of one doesn't get the server restarted.
+func serveLiveness(w http.ResponseWriter, r *http.Request) {
+	writeHealthOK(w)
+}
+
+func writeHealthOK(w http.ResponseWriter) {
+	w.WriteHeader(http.StatusOK)
+	if _, err := io.WriteString(w, "OK"); err != nil {
+		fmt.Printf("health check write response error: %+v", err)
+	}
+}
+
+// NewHealthServer creates a server for liveness on /livez and the checker's
+// readiness on /healthz and /readyz at addr. It serves HTTPS if tlsConfig is
+// set.
+func NewHealthServer(addr string, checker *HealthChecker, tlsConfig *tls.Config) *http.Server {
+	mux := &http.ServeMux{}
+	mux.HandleFunc("/livez", serveLiveness)
+	mux.Handle("/healthz", checker)
+	mux.Handle("/readyz", checker)
+
+	// Set timeouts so that a slow or malicious client doesn't hold resources forever.
+	srv := &http.Server{
+		ReadTimeout:  5 * time.Second,
+		WriteTimeout: healthCheckTimeout + 5*time.Second,
+		IdleTimeout:  60 * time.Second,
+		Handler:      mux,
+		Addr:         addr,
+		TLSConfig:    tlsConfig,
+	}
+	return srv
+}
+
+// StartHealthServer serves a server created by NewHealthServer until it's shut
+// down.
+func StartHealthServer(srv *http.Server) error {
+	if srv.TLSConfig != nil {
+		return srv.ListenAndServeTLS("", "")
+	}
+	return srv.ListenAndServe()
+}
+
+// checkHealth verifies that the storage backing the lookup and, if enabled,
+// search are reachable.
+func (serv *MetadataServer) checkHealth(ctx context.Context) error {
+	errs := make(chan error, 1)
+	go func() {
+		if err := serv.taskManager.Storage.Ping(); err != nil {
+			errs <- fferr.NewInternalErrorf("metadata storage is unreachable: %v", err)
+			return
+		}
+		if wrapper, ok := serv.lookup.(*SearchWrapper); ok {
+			if err := wrapper.Searcher.Health(); err != nil {
+				errs <- fferr.NewInternalErrorf("search is unavailable: %v", err)
+				return
+			}
+		}
+		errs <- nil
+	}()
+	select {
+	case err := <-errs:
+		return err
+	case <-ctx.Done():
+		return fferr.NewInternalErrorf("health check timed out: %v", ctx.Err())
+	}
+}
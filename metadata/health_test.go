@@ -0,0 +1,111 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+//
+// Copyright 2024 FeatureForm Inc.
+//
+
+package metadata
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/featureform/logging"
+	pb "github.com/featureform/metadata/proto"
+	"github.com/featureform/metadata/search"
+	"github.com/featureform/scheduling"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+type unhealthySearcher struct {
+	MockSearcher
+}
+
+func (s *unhealthySearcher) Health() error {
+	return fmt.Errorf("search is down")
+}
+
+func TestHealthCheckerInitializing(t *testing.T) {
+	ctx := logging.NewTestContext(t)
+	health := NewHealthChecker()
+	resp, err := health.Check(ctx, &healthpb.HealthCheckRequest{})
+	if err != nil {
+		t.Fatalf("Failed to check health: %v", err)
+	}
+	if resp.Status != healthpb.HealthCheckResponse_NOT_SERVING {
+		t.Fatalf("Expected NOT_SERVING while initializing, got: %s", resp.Status)
+	}
+	rec := httptest.NewRecorder()
+	health.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Fatalf("Expected %d while initializing, got: %d", http.StatusServiceUnavailable, rec.Code)
+	}
+	rec = httptest.NewRecorder()
+	serveLiveness(rec, httptest.NewRequest(http.MethodGet, "/livez", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected liveness to pass while initializing, got: %d", rec.Code)
+	}
+}
+
+func TestHealthCheckerServing(t *testing.T) {
+	ctx, logger := logging.NewTestContextAndLogger(t)
+	serv, addr := startServ(t, ctx, logger)
+	defer serv.Stop()
+
+	rec := httptest.NewRecorder()
+	serv.health.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected %d, got: %d: %s", http.StatusOK, rec.Code, rec.Body.String())
+	}
+
+	conn, err := grpc.NewClient(addr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatalf("Failed to connect to metadata server: %v", err)
+	}
+	defer conn.Close()
+	client := healthpb.NewHealthClient(conn)
+	for _, service := range []string{"", pb.Metadata_ServiceDesc.ServiceName} {
+		resp, err := client.Check(ctx, &healthpb.HealthCheckRequest{Service: service})
+		if err != nil {
+			t.Fatalf("Failed to check health of %q: %v", service, err)
+		}
+		if resp.Status != healthpb.HealthCheckResponse_SERVING {
+			t.Fatalf("Expected %q to be SERVING, got: %s", service, resp.Status)
+		}
+	}
+	if _, err := client.Check(ctx, &healthpb.HealthCheckRequest{Service: "unknown"}); err == nil {
+		t.Fatalf("Expected an error checking an unknown service")
+	}
+}
+
+func TestHealthCheckerSearchDown(t *testing.T) {
+	ctx, logger := logging.NewTestContextAndLogger(t)
+	manager, err := scheduling.NewMemoryTaskMetadataManager(ctx)
+	if err != nil {
+		t.Fatalf("Failed to create memory task metadata manager: %v", err)
+	}
+	config := &Config{
+		Logger:       logger,
+		TaskManager:  manager,
+		SearchParams: &search.MeilisearchParams{},
+	}
+	baseLookup := MemoryResourceLookup{Connection: config.TaskManager.Storage}
+	lookup, err := initializeLookup(config, &baseLookup, func(search.Params) (search.Searcher, error) {
+		return &unhealthySearcher{}, nil
+	})
+	if err != nil {
+		t.Fatalf("Failed to initialize lookup: %v", err)
+	}
+	serv := &MetadataServer{lookup: lookup, taskManager: &config.TaskManager}
+	health := NewHealthChecker()
+	health.SetServer(serv)
+	if err := health.Healthy(ctx); err == nil {
+		t.Fatalf("Expected an error when search is down")
+	}
+}
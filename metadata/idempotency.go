@@ -0,0 +1,213 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+//
+// Copyright 2024 FeatureForm Inc.
+//
+
+package metadata
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/featureform/fferr"
+	"github.com/featureform/logging"
+	pb "github.com/featureform/metadata/proto"
+	"github.com/featureform/storage"
+)
+
+// defaultIdempotencyKeyTTL is how long the result of a create is kept for
+// retries when no TTL is configured.
+const defaultIdempotencyKeyTTL = 10 * time.Minute
+
+const idempotencyKeyPrefix = "/idempotency_keys/"
+
+func idempotencyKeyPath(key string) string {
+	return fmt.Sprintf("%skey=%s", idempotencyKeyPrefix, key)
+}
+
+// idempotencyRecord is what's stored for a successful create made with an
+// idempotency key.
+type idempotencyRecord struct {
+	Resource ResourceID `json:"resource"`
+	Expires  time.Time  `json:"expires"`
+}
+
+func (record *idempotencyRecord) Marshal() (string, error) {
+	serialized, err := json.Marshal(record)
+	if err != nil {
+		errMessage := fmt.Errorf("failed to serialize idempotency record: %w", err)
+		return "", fferr.NewInternalError(errMessage)
+	}
+	return string(serialized), nil
+}
+
+func (record *idempotencyRecord) Unmarshal(data string) error {
+	if err := json.Unmarshal([]byte(data), record); err != nil {
+		errMessage := fmt.Errorf("failed to deserialize idempotency record: %w", err)
+		return fferr.NewInternalError(errMessage)
+	}
+	return nil
+}
+
+// idempotencyKeys remembers the resources created with recent idempotency
+// keys in the metadata store, so a retried create, possibly on another
+// replica, returns the first result rather than running its equivalence
+// checks again. A key is locked while its create runs, so concurrent retries
+// wait for the first one and then replay it. Keys expire after the TTL. A nil
+// idempotencyKeys remembers nothing.
+type idempotencyKeys struct {
+	ttl     time.Duration
+	now     func() time.Time
+	storage storage.MetadataStorage
+	logger  logging.Logger
+
+	sweepMu   sync.Mutex
+	lastSweep time.Time
+}
+
+func newIdempotencyKeys(ttl time.Duration, storage storage.MetadataStorage, logger logging.Logger) *idempotencyKeys {
+	if ttl <= 0 {
+		ttl = defaultIdempotencyKeyTTL
+	}
+	return &idempotencyKeys{
+		ttl:     ttl,
+		now:     time.Now,
+		storage: storage,
+		logger:  logger,
+	}
+}
+
+// Do runs create unless a create of id with key already succeeded. The key is
+// held until create returns. Reusing a key to create a different resource is
+// an error.
+func (keys *idempotencyKeys) Do(ctx context.Context, key string, id ResourceID, create func() (*pb.Empty, error)) (*pb.Empty, error) {
+	if keys == nil || key == "" {
+		return create()
+	}
+	path := idempotencyKeyPath(key)
+	logger := keys.logger.With("idempotency_key", key, "resource_id", id.String())
+	lock, err := keys.storage.Locker.Lock(ctx, path, true)
+	if err != nil {
+		logger.Errorw("Failed to lock idempotency key", "error", err)
+		return nil, err
+	}
+	defer func() {
+		if err := keys.storage.Locker.Unlock(ctx, lock); err != nil {
+			logger.Errorw("Failed to unlock idempotency key", "error", err)
+		}
+	}()
+	// The key's lock is held, so its record is read and written directly
+	// rather than through the locking MetadataStorage methods.
+	replayed, err := keys.replay(path, key, id)
+	if err != nil {
+		return nil, err
+	}
+	if replayed {
+		logger.Info("Replaying create with idempotency key")
+		return &pb.Empty{}, nil
+	}
+	resp, err := create()
+	if err != nil {
+		return nil, err
+	}
+	now := keys.now()
+	record := idempotencyRecord{Resource: id, Expires: now.Add(keys.ttl)}
+	serialized, err := record.Marshal()
+	if err != nil {
+		return nil, err
+	}
+	// The resource was created, so failing to remember the key only means a
+	// retry will go through the usual equivalence checks.
+	if err := keys.storage.Storage.Set(path, serialized); err != nil {
+		logger.Warnw("Failed to record idempotency key", "error", err)
+	}
+	keys.sweep(ctx, now, logger)
+	return resp, nil
+}
+
+// replay returns true if the key's record is for an unexpired create of id.
+func (keys *idempotencyKeys) replay(path, key string, id ResourceID) (bool, error) {
+	serialized, err := keys.storage.Storage.Get(path)
+	var notFound *fferr.KeyNotFoundError
+	if errors.As(err, &notFound) {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+	record := idempotencyRecord{}
+	if err := record.Unmarshal(serialized); err != nil {
+		return false, err
+	}
+	if !keys.now().Before(record.Expires) {
+		return false, nil
+	}
+	if record.Resource != id {
+		err := fferr.NewInvalidArgumentErrorf("idempotency key %s was already used to create %s", key, record.Resource)
+		err.AddDetail("resource_id", id.String())
+		return false, err
+	}
+	return true, nil
+}
+
+// sweep deletes expired keys, at most once per TTL on each replica. Keys that
+// are locked by a create in progress are left for the next sweep.
+func (keys *idempotencyKeys) sweep(ctx context.Context, now time.Time, logger logging.Logger) {
+	keys.sweepMu.Lock()
+	if now.Sub(keys.lastSweep) < keys.ttl {
+		keys.sweepMu.Unlock()
+		return
+	}
+	keys.lastSweep = now
+	keys.sweepMu.Unlock()
+
+	records, err := keys.storage.Storage.List(idempotencyKeyPrefix)
+	if err != nil {
+		logger.Warnw("Failed to list idempotency keys to sweep", "error", err)
+		return
+	}
+	for path, serialized := range records {
+		record := idempotencyRecord{}
+		if err := record.Unmarshal(serialized); err == nil && now.Before(record.Expires) {
+			continue
+		}
+		lock, err := keys.storage.Locker.Lock(ctx, path, false)
+		if err != nil {
+			continue
+		}
+		// The key may have been reused since it was listed.
+		if keys.expired(path, now) {
+			if _, err := keys.storage.Storage.Delete(path); err != nil {
+				logger.Warnw("Failed to delete expired idempotency key", "key", path, "error", err)
+			}
+		}
+		if err := keys.storage.Locker.Unlock(ctx, lock); err != nil {
+			logger.Errorw("Failed to unlock idempotency key", "key", path, "error", err)
+		}
+	}
+}
+
+// expired returns true if the record at path has expired or can't be read.
+func (keys *idempotencyKeys) expired(path string, now time.Time) bool {
+	serialized, err := keys.storage.Storage.Get(path)
+	if err != nil {
+		return false
+	}
+	record := idempotencyRecord{}
+	if err := record.Unmarshal(serialized); err != nil {
+		return true
+	}
+	return !now.Before(record.Expires)
+}
+
+// createIdempotent runs create unless a create of id with key already
+// succeeded, in which case the earlier result is returned. Every create RPC
+// goes through it.
+func (serv *MetadataServer) createIdempotent(ctx context.Context, key string, id ResourceID, create func() (*pb.Empty, error)) (*pb.Empty, error) {
+	return serv.idempotencyKeys.Do(ctx, key, id, create)
+}
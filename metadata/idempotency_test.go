@@ -0,0 +1,198 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+//
+// Copyright 2024 FeatureForm Inc.
+//
+
+package metadata
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/featureform/fferr"
+	"github.com/featureform/logging"
+	pb "github.com/featureform/metadata/proto"
+	"github.com/featureform/scheduling"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func newTestIdempotencyKeys(t *testing.T, ttl time.Duration) *idempotencyKeys {
+	ctx, logger := logging.NewTestContextAndLogger(t)
+	manager, err := scheduling.NewMemoryTaskMetadataManager(ctx)
+	if err != nil {
+		t.Fatalf("could not create task manager: %v", err)
+	}
+	return newIdempotencyKeys(ttl, manager.Storage, logger)
+}
+
+func TestIdempotencyKeys(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+	keys := newTestIdempotencyKeys(t, time.Minute)
+	keys.now = func() time.Time { return now }
+	id := ResourceID{Name: "feature", Variant: "v1", Type: FEATURE_VARIANT}
+	creates := 0
+	create := func() (*pb.Empty, error) {
+		creates++
+		return &pb.Empty{}, nil
+	}
+
+	if _, err := keys.Do(ctx, "key", id, create); err != nil || creates != 1 {
+		t.Fatalf("expected unknown key to create, got creates=%d err=%v", creates, err)
+	}
+	if _, err := keys.Do(ctx, "key", id, create); err != nil || creates != 1 {
+		t.Fatalf("expected key to replay, got creates=%d err=%v", creates, err)
+	}
+
+	other := ResourceID{Name: "feature", Variant: "v2", Type: FEATURE_VARIANT}
+	if _, err := keys.Do(ctx, "key", other, create); err == nil || creates != 1 {
+		t.Fatalf("expected reusing a key for another resource to fail, got creates=%d err=%v", creates, err)
+	}
+
+	if _, err := keys.Do(ctx, "", id, create); err != nil || creates != 2 {
+		t.Fatalf("expected an empty key to never replay, got creates=%d err=%v", creates, err)
+	}
+
+	failed := func() (*pb.Empty, error) {
+		return nil, fferr.NewInternalErrorf("create failed")
+	}
+	if _, err := keys.Do(ctx, "failed", id, failed); err == nil {
+		t.Fatalf("expected failed create to return its error")
+	}
+	if _, err := keys.Do(ctx, "failed", id, create); err != nil || creates != 3 {
+		t.Fatalf("expected failed create to not be recorded, got creates=%d err=%v", creates, err)
+	}
+
+	now = now.Add(time.Minute)
+	if _, err := keys.Do(ctx, "key", id, create); err != nil || creates != 4 {
+		t.Fatalf("expected expired key to create, got creates=%d err=%v", creates, err)
+	}
+}
+
+func TestIdempotencyKeysShared(t *testing.T) {
+	ctx := context.Background()
+	keys := newTestIdempotencyKeys(t, time.Minute)
+	// Another replica backed by the same metadata store.
+	replica := newIdempotencyKeys(time.Minute, keys.storage, keys.logger)
+	id := ResourceID{Name: "user", Type: ENTITY}
+	var creates atomic.Int32
+	create := func() (*pb.Empty, error) {
+		creates.Add(1)
+		time.Sleep(50 * time.Millisecond)
+		return &pb.Empty{}, nil
+	}
+	var wg sync.WaitGroup
+	errs := make(chan error, 4)
+	for _, k := range []*idempotencyKeys{keys, replica, keys, replica} {
+		wg.Add(1)
+		go func(k *idempotencyKeys) {
+			defer wg.Done()
+			_, err := k.Do(ctx, "key", id, create)
+			errs <- err
+		}(k)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Fatalf("expected concurrent retries to succeed: %v", err)
+		}
+	}
+	if creates.Load() != 1 {
+		t.Fatalf("expected concurrent retries to create once, got %d", creates.Load())
+	}
+}
+
+func TestIdempotencyKeysSweep(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+	keys := newTestIdempotencyKeys(t, time.Minute)
+	keys.now = func() time.Time { return now }
+	create := func() (*pb.Empty, error) { return &pb.Empty{}, nil }
+	if _, err := keys.Do(ctx, "old", ResourceID{Name: "old", Type: ENTITY}, create); err != nil {
+		t.Fatalf("could not create: %v", err)
+	}
+	now = now.Add(2 * time.Minute)
+	if _, err := keys.Do(ctx, "new", ResourceID{Name: "new", Type: ENTITY}, create); err != nil {
+		t.Fatalf("could not create: %v", err)
+	}
+	if _, err := keys.storage.Storage.Get(idempotencyKeyPath("old")); err == nil {
+		t.Fatalf("expected expired key to be swept")
+	}
+	if _, err := keys.storage.Storage.Get(idempotencyKeyPath("new")); err != nil {
+		t.Fatalf("expected new key to be recorded: %v", err)
+	}
+}
+
+func TestCreateIdempotencyKey(t *testing.T) {
+	ctx, logger := logging.NewTestContextAndLogger(t)
+	serv, addr := startServ(t, ctx, logger)
+	defer serv.Stop()
+	client := client(t, ctx, logger, addr)
+	defer client.Close()
+
+	req := &pb.EntityRequest{
+		Entity:         &pb.Entity{Name: "user", Description: "first"},
+		IdempotencyKey: "create-user",
+	}
+	if _, err := serv.CreateEntity(ctx, req); err != nil {
+		t.Fatalf("could not create entity: %v", err)
+	}
+	retry := &pb.EntityRequest{
+		Entity:         &pb.Entity{Name: "user", Description: "retry"},
+		IdempotencyKey: "create-user",
+	}
+	if _, err := serv.CreateEntity(ctx, retry); err != nil {
+		t.Fatalf("expected retried create to succeed: %v", err)
+	}
+	entity, err := client.GetEntity(ctx, "user")
+	if err != nil {
+		t.Fatalf("could not get entity: %v", err)
+	}
+	if entity.Description() != "first" {
+		t.Fatalf("expected retried create to be replayed, got description %q", entity.Description())
+	}
+
+	reused := &pb.EntityRequest{
+		Entity:         &pb.Entity{Name: "item"},
+		IdempotencyKey: "create-user",
+	}
+	if _, err := serv.CreateEntity(ctx, reused); err == nil {
+		t.Fatalf("expected reusing a key for another entity to fail")
+	}
+}
+
+func TestSendCreateRetriesUnavailable(t *testing.T) {
+	ctx := context.Background()
+	req := &pb.EntityRequest{Entity: &pb.Entity{Name: "user"}, IdempotencyKey: "key"}
+	keys := make([]string, 0)
+	create := func(ctx context.Context, req *pb.EntityRequest, opts ...grpc.CallOption) (*pb.Empty, error) {
+		keys = append(keys, req.IdempotencyKey)
+		if len(keys) < 2 {
+			return nil, status.Error(codes.Unavailable, "unavailable")
+		}
+		return &pb.Empty{}, nil
+	}
+	if err := sendCreate(ctx, req, create); err != nil {
+		t.Fatalf("expected create to succeed after a retry: %v", err)
+	}
+	if len(keys) != 2 || keys[0] != "key" || keys[1] != "key" {
+		t.Fatalf("expected one retry with the same idempotency key, got %v", keys)
+	}
+
+	attempts := 0
+	invalid := func(ctx context.Context, req *pb.EntityRequest, opts ...grpc.CallOption) (*pb.Empty, error) {
+		attempts++
+		return nil, status.Error(codes.InvalidArgument, "invalid")
+	}
+	if err := sendCreate(ctx, req, invalid); err == nil || attempts != 1 {
+		t.Fatalf("expected other errors to not be retried, got attempts=%d err=%v", attempts, err)
+	}
+}
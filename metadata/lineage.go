@@ -0,0 +1,304 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+//
+// Copyright 2024 FeatureForm Inc.
+//
+
+package metadata
+
+import (
+	"context"
+	"errors"
+
+	"github.com/featureform/fferr"
+	"github.com/featureform/logging"
+	pb "github.com/featureform/metadata/proto"
+)
+
+// LineageEdge points from a resource to a resource that depends on it.
+type LineageEdge struct {
+	Upstream   ResourceID
+	Downstream ResourceID
+}
+
+// Lineage is the dependency graph around a resource. Nodes are in the order
+// they were reached, starting with the resource itself.
+type Lineage struct {
+	Nodes []ResourceID
+	Edges []LineageEdge
+}
+
+func (lineage *Lineage) Proto() *pb.Lineage {
+	nodes := make([]*pb.ResourceID, len(lineage.Nodes))
+	for i, node := range lineage.Nodes {
+		nodes[i] = node.Proto()
+	}
+	edges := make([]*pb.LineageEdge, len(lineage.Edges))
+	for i, edge := range lineage.Edges {
+		edges[i] = &pb.LineageEdge{Upstream: edge.Upstream.Proto(), Downstream: edge.Downstream.Proto()}
+	}
+	return &pb.Lineage{Nodes: nodes, Edges: edges}
+}
+
+func parseLineage(lineage *pb.Lineage) *Lineage {
+	parsed := &Lineage{
+		Nodes: make([]ResourceID, len(lineage.Nodes)),
+		Edges: make([]LineageEdge, len(lineage.Edges)),
+	}
+	for i, node := range lineage.Nodes {
+		parsed.Nodes[i] = parseResourceIDProto(node)
+	}
+	for i, edge := range lineage.Edges {
+		parsed.Edges[i] = LineageEdge{Upstream: parseResourceIDProto(edge.Upstream), Downstream: parseResourceIDProto(edge.Downstream)}
+	}
+	return parsed
+}
+
+func parseResourceIDProto(id *pb.ResourceID) ResourceID {
+	return ResourceID{Name: id.GetResource().GetName(), Variant: id.GetResource().GetVariant(), Type: ResourceType(id.GetResourceType())}
+}
+
+type lineageBuilder struct {
+	lineage *Lineage
+	nodes   map[ResourceID]struct{}
+	edges   map[LineageEdge]struct{}
+}
+
+func newLineageBuilder(root ResourceID) *lineageBuilder {
+	return &lineageBuilder{
+		lineage: &Lineage{Nodes: []ResourceID{root}, Edges: []LineageEdge{}},
+		nodes:   map[ResourceID]struct{}{root: {}},
+		edges:   make(map[LineageEdge]struct{}),
+	}
+}
+
+func (builder *lineageBuilder) addEdge(upstream, downstream ResourceID) {
+	for _, id := range []ResourceID{upstream, downstream} {
+		if _, has := builder.nodes[id]; !has {
+			builder.nodes[id] = struct{}{}
+			builder.lineage.Nodes = append(builder.lineage.Nodes, id)
+		}
+	}
+	edge := LineageEdge{Upstream: upstream, Downstream: downstream}
+	if _, has := builder.edges[edge]; !has {
+		builder.edges[edge] = struct{}{}
+		builder.lineage.Edges = append(builder.lineage.Edges, edge)
+	}
+}
+
+// GetLineage returns the upstream and downstream dependency graph of a resource.
+// Upstream resources are found through Dependencies and downstream ones through
+// the relationships that Notify records on each resource.
+func (serv *MetadataServer) GetLineage(ctx context.Context, req *pb.GetLineageRequest) (*pb.Lineage, error) {
+	ctx = logging.AttachRequestID(logging.RequestID(req.RequestId), ctx, serv.Logger)
+	logger := logging.GetLoggerFromContext(ctx)
+	logger.Infow("Getting lineage", "resource_id", req.ResourceId, "max_depth", req.MaxDepth)
+	if req.MaxDepth < 0 {
+		return nil, fferr.NewInvalidArgumentErrorf("max depth must be positive, got %d", req.MaxDepth)
+	}
+	id := parseResourceIDProto(req.ResourceId)
+	root, err := serv.lookup.Lookup(ctx, id)
+	if err != nil {
+		logger.Errorw("Unable to look up resource", "error", err)
+		return nil, err
+	}
+	builder := newLineageBuilder(id)
+	maxDepth := int(req.MaxDepth)
+	upstream := func(res Resource) ([]Resource, error) {
+		deps, err := res.Dependencies(ctx, serv.lookup)
+		if err != nil {
+			return nil, err
+		}
+		return deps.List(ctx)
+	}
+	err = walkLineage(root, maxDepth, upstream, func(from, to ResourceID) {
+		builder.addEdge(to, from)
+	})
+	if err != nil {
+		logger.Errorw("Unable to get upstream lineage", "error", err)
+		return nil, err
+	}
+	downstream := func(res Resource) ([]Resource, error) {
+		return serv.lineageDependents(ctx, res)
+	}
+	err = walkLineage(root, maxDepth, downstream, builder.addEdge)
+	if err != nil {
+		logger.Errorw("Unable to get downstream lineage", "error", err)
+		return nil, err
+	}
+	return builder.lineage.Proto(), nil
+}
+
+// walkLineage visits resources breadth-first in one direction, calling addEdge
+// for every hop. Each resource is expanded at most once, which also stops the
+// walk from looping on cycles.
+func walkLineage(root Resource, maxDepth int, next func(Resource) ([]Resource, error), addEdge func(from, to ResourceID)) error {
+	visited := map[ResourceID]struct{}{root.ID(): {}}
+	frontier := []Resource{root}
+	for depth := 0; len(frontier) > 0 && (maxDepth == 0 || depth < maxDepth); depth++ {
+		nextFrontier := make([]Resource, 0)
+		for _, res := range frontier {
+			neighbors, err := next(res)
+			if err != nil {
+				return err
+			}
+			for _, neighbor := range neighbors {
+				id := neighbor.ID()
+				addEdge(res.ID(), id)
+				if _, has := visited[id]; has {
+					continue
+				}
+				visited[id] = struct{}{}
+				nextFrontier = append(nextFrontier, neighbor)
+			}
+		}
+		frontier = nextFrontier
+	}
+	return nil
+}
+
+// lineageDependents returns the resources that depend on res, as recorded when
+// they notified it on creation. Dependents that no longer exist are skipped.
+func (serv *MetadataServer) lineageDependents(ctx context.Context, res Resource) ([]Resource, error) {
+	ids := dependentIDs(res)
+	dependents := make([]Resource, 0, len(ids))
+	for _, id := range ids {
+		dependent, err := serv.lookup.Lookup(ctx, id)
+		var notFound *fferr.KeyNotFoundError
+		if errors.As(err, &notFound) {
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+		dependents = append(dependents, dependent)
+	}
+	return dependents, nil
+}
+
+// deletionDependents returns every resource downstream of res, followed by the
+// models that use res or any of those resources.
+func (serv *MetadataServer) deletionDependents(ctx context.Context, res Resource) ([]ResourceID, error) {
+	downstream := map[ResourceID]struct{}{res.ID(): {}}
+	dependents := make([]ResourceID, 0)
+	next := func(r Resource) ([]Resource, error) {
+		return serv.lineageDependents(ctx, r)
+	}
+	err := walkLineage(res, 0, next, func(_, to ResourceID) {
+		if _, has := downstream[to]; !has {
+			downstream[to] = struct{}{}
+			dependents = append(dependents, to)
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	models, err := serv.lookup.ListForType(ctx, MODEL)
+	if err != nil {
+		return nil, err
+	}
+	for _, model := range models {
+		casted, ok := model.(*modelResource)
+		if ok && modelUses(casted.serialized, downstream) {
+			dependents = append(dependents, model.ID())
+		}
+	}
+	return dependents, nil
+}
+
+func modelUses(model *pb.Model, ids map[ResourceID]struct{}) bool {
+	uses := func(t ResourceType, nameVariants []*pb.NameVariant) bool {
+		for _, nv := range nameVariants {
+			if _, has := ids[ResourceID{Name: nv.Name, Variant: nv.Variant, Type: t}]; has {
+				return true
+			}
+		}
+		return false
+	}
+	return uses(FEATURE_VARIANT, model.Features) ||
+		uses(LABEL_VARIANT, model.Labels) ||
+		uses(TRAINING_SET_VARIANT, model.Trainingsets)
+}
+
+// removeModelReferences drops the deleted resources from every model that
+// uses them, so no model is left pointing at a variant that no longer exists.
+func (serv *MetadataServer) removeModelReferences(ctx context.Context, deleted []ResourceID) error {
+	ids := make(map[ResourceID]struct{}, len(deleted))
+	for _, id := range deleted {
+		ids[id] = struct{}{}
+	}
+	models, err := serv.lookup.ListForType(ctx, MODEL)
+	if err != nil {
+		return err
+	}
+	for _, model := range models {
+		casted, ok := model.(*modelResource)
+		if !ok || !modelUses(casted.serialized, ids) {
+			continue
+		}
+		casted.serialized.Features = withoutNameVariants(FEATURE_VARIANT, casted.serialized.Features, ids)
+		casted.serialized.Labels = withoutNameVariants(LABEL_VARIANT, casted.serialized.Labels, ids)
+		casted.serialized.Trainingsets = withoutNameVariants(TRAINING_SET_VARIANT, casted.serialized.Trainingsets, ids)
+		if err := serv.lookup.Set(ctx, model.ID(), model); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func withoutNameVariants(t ResourceType, nameVariants []*pb.NameVariant, ids map[ResourceID]struct{}) []*pb.NameVariant {
+	kept := make([]*pb.NameVariant, 0, len(nameVariants))
+	for _, nv := range nameVariants {
+		if _, has := ids[ResourceID{Name: nv.Name, Variant: nv.Variant, Type: t}]; !has {
+			kept = append(kept, nv)
+		}
+	}
+	return kept
+}
+
+func dependentIDs(res Resource) []ResourceID {
+	ids := make([]ResourceID, 0)
+	add := func(t ResourceType, nameVariants []*pb.NameVariant) {
+		for _, nv := range nameVariants {
+			ids = append(ids, ResourceID{Name: nv.Name, Variant: nv.Variant, Type: t})
+		}
+	}
+	addVariants := func(t ResourceType, name string, variants []string) {
+		for _, variant := range variants {
+			ids = append(ids, ResourceID{Name: name, Variant: variant, Type: t})
+		}
+	}
+	switch casted := res.(type) {
+	case *sourceResource:
+		addVariants(SOURCE_VARIANT, casted.serialized.Name, casted.serialized.Variants)
+	case *featureResource:
+		addVariants(FEATURE_VARIANT, casted.serialized.Name, casted.serialized.Variants)
+	case *labelResource:
+		addVariants(LABEL_VARIANT, casted.serialized.Name, casted.serialized.Variants)
+	case *trainingSetResource:
+		addVariants(TRAINING_SET_VARIANT, casted.serialized.Name, casted.serialized.Variants)
+	case *sourceVariantResource:
+		add(FEATURE_VARIANT, casted.serialized.Features)
+		add(LABEL_VARIANT, casted.serialized.Labels)
+		add(TRAINING_SET_VARIANT, casted.serialized.Trainingsets)
+	case *featureVariantResource:
+		add(TRAINING_SET_VARIANT, casted.serialized.Trainingsets)
+	case *labelVariantResource:
+		add(TRAINING_SET_VARIANT, casted.serialized.Trainingsets)
+	case *userResource:
+		add(SOURCE_VARIANT, casted.serialized.Sources)
+		add(FEATURE_VARIANT, casted.serialized.Features)
+		add(LABEL_VARIANT, casted.serialized.Labels)
+		add(TRAINING_SET_VARIANT, casted.serialized.Trainingsets)
+	case *providerResource:
+		add(SOURCE_VARIANT, casted.serialized.Sources)
+		add(FEATURE_VARIANT, casted.serialized.Features)
+		add(LABEL_VARIANT, casted.serialized.Labels)
+		add(TRAINING_SET_VARIANT, casted.serialized.Trainingsets)
+	case *entityResource:
+		add(FEATURE_VARIANT, casted.serialized.Features)
+		add(LABEL_VARIANT, casted.serialized.Labels)
+		add(TRAINING_SET_VARIANT, casted.serialized.Trainingsets)
+	}
+	return ids
+}
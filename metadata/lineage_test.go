@@ -0,0 +1,231 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+//
+// Copyright 2024 FeatureForm Inc.
+//
+
+package metadata
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/featureform/fferr"
+	"github.com/featureform/logging"
+	pb "github.com/featureform/metadata/proto"
+	pc "github.com/featureform/provider/provider_config"
+	pt "github.com/featureform/provider/provider_type"
+	"github.com/featureform/provider/types"
+)
+
+func lineageTestDefs() []ResourceDef {
+	snowflakeConfig := pc.SnowflakeConfig{
+		Username:     "featureformer",
+		Password:     "password",
+		Organization: "featureform",
+		Account:      "featureform-test",
+		Database:     "transactions_db",
+		Schema:       "fraud",
+		Warehouse:    "ff_wh_xs",
+		Role:         "sysadmin",
+	}
+	columns := ResourceVariantColumns{Entity: "col1", Value: "col2", TS: "col3"}
+	return []ResourceDef{
+		UserDef{Name: "Featureform", Tags: Tags{}, Properties: Properties{}},
+		ProviderDef{
+			Name:             "mockOffline",
+			Type:             string(pt.SnowflakeOffline),
+			Software:         "snowflake",
+			SerializedConfig: snowflakeConfig.Serialize(),
+			Tags:             Tags{},
+			Properties:       Properties{},
+		},
+		EntityDef{Name: "user", Tags: Tags{}, Properties: Properties{}},
+		SourceDef{
+			Name:    "transactions",
+			Variant: "v1",
+			Definition: TransformationSource{
+				TransformationType: SQLTransformationType{
+					Query:   "SELECT * FROM dummy",
+					Sources: []NameVariant{{Name: "mockName", Variant: "mockVariant"}},
+				},
+			},
+			Owner:      "Featureform",
+			Provider:   "mockOffline",
+			Tags:       Tags{},
+			Properties: Properties{},
+		},
+		FeatureDef{
+			Name:       "avg_amount",
+			Variant:    "v1",
+			Owner:      "Featureform",
+			Source:     NameVariant{Name: "transactions", Variant: "v1"},
+			Entity:     "user",
+			Location:   columns,
+			Tags:       Tags{},
+			Properties: Properties{},
+			Mode:       PRECOMPUTED,
+		},
+		LabelDef{
+			Name:       "is_fraud",
+			Variant:    "v1",
+			Type:       types.Bool,
+			Provider:   "mockOffline",
+			Entity:     "user",
+			Source:     NameVariant{Name: "transactions", Variant: "v1"},
+			Owner:      "Featureform",
+			Location:   columns,
+			Tags:       Tags{},
+			Properties: Properties{},
+		},
+		TrainingSetDef{
+			Name:       "fraud",
+			Variant:    "v1",
+			Provider:   "mockOffline",
+			Label:      NameVariant{Name: "is_fraud", Variant: "v1"},
+			Features:   NameVariants{{Name: "avg_amount", Variant: "v1"}},
+			Owner:      "Featureform",
+			Tags:       Tags{},
+			Properties: Properties{},
+			Type:       DynamicTrainingSet,
+		},
+	}
+}
+
+func TestGetLineage(t *testing.T) {
+	_, ctx, logger := logging.InitializeTestRequestID(t)
+	_, addr := startServNoPanic(t, ctx, logger)
+	client := client(t, ctx, logger, addr)
+	if err := client.CreateAll(ctx, lineageTestDefs()); err != nil {
+		t.Fatalf("Failed to create resources: %s", err)
+	}
+
+	source := ResourceID{Name: "transactions", Variant: "v1", Type: SOURCE_VARIANT}
+	feature := ResourceID{Name: "avg_amount", Variant: "v1", Type: FEATURE_VARIANT}
+	label := ResourceID{Name: "is_fraud", Variant: "v1", Type: LABEL_VARIANT}
+	trainingSet := ResourceID{Name: "fraud", Variant: "v1", Type: TRAINING_SET_VARIANT}
+	provider := ResourceID{Name: "mockOffline", Type: PROVIDER}
+
+	lineage, err := client.GetLineage(ctx, source, 0)
+	if err != nil {
+		t.Fatalf("Failed to get lineage: %s", err)
+	}
+	if lineage.Nodes[0] != source {
+		t.Fatalf("Expected the first node to be %v, got %v", source, lineage.Nodes[0])
+	}
+	edges := make(map[LineageEdge]bool)
+	for _, edge := range lineage.Edges {
+		edges[edge] = true
+	}
+	for _, expected := range []LineageEdge{
+		{Upstream: provider, Downstream: source},
+		{Upstream: source, Downstream: feature},
+		{Upstream: source, Downstream: label},
+		{Upstream: feature, Downstream: trainingSet},
+		{Upstream: label, Downstream: trainingSet},
+	} {
+		if !edges[expected] {
+			t.Fatalf("Expected edge %v -> %v in %v", expected.Upstream, expected.Downstream, lineage.Edges)
+		}
+	}
+
+	// The source is two hops upstream of the training set, through its feature and label.
+	lineage, err = client.GetLineage(ctx, trainingSet, 1)
+	if err != nil {
+		t.Fatalf("Failed to get lineage: %s", err)
+	}
+	for _, node := range lineage.Nodes {
+		if node == source {
+			t.Fatalf("Expected depth limit to exclude %v", source)
+		}
+	}
+
+	if _, err := client.GetLineage(ctx, ResourceID{Name: "missing", Variant: "v1", Type: SOURCE_VARIANT}, 0); err == nil {
+		t.Fatalf("Expected error getting the lineage of a missing resource")
+	}
+	if _, err := client.GetLineage(ctx, source, -1); err == nil {
+		t.Fatalf("Expected error for a negative max depth")
+	}
+}
+
+func TestWalkLineageCycle(t *testing.T) {
+	a := &userResource{&pb.User{Name: "a"}}
+	b := &userResource{&pb.User{Name: "b"}}
+	graph := map[string][]Resource{"a": {b}, "b": {a}}
+	next := func(res Resource) ([]Resource, error) {
+		return graph[res.ID().Name], nil
+	}
+	builder := newLineageBuilder(a.ID())
+	if err := walkLineage(a, 0, next, builder.addEdge); err != nil {
+		t.Fatalf("Failed to walk lineage: %s", err)
+	}
+	if len(builder.lineage.Nodes) != 2 || len(builder.lineage.Edges) != 2 {
+		t.Fatalf("Expected 2 nodes and 2 edges, got %v and %v", builder.lineage.Nodes, builder.lineage.Edges)
+	}
+}
+
+func TestDeleteResourceVariantDependents(t *testing.T) {
+	_, ctx, logger := logging.InitializeTestRequestID(t)
+	_, addr := startServNoPanic(t, ctx, logger)
+	client := client(t, ctx, logger, addr)
+	defs := append(lineageTestDefs(), ModelDef{
+		Name:         "fraud_model",
+		Trainingsets: NameVariants{{Name: "fraud", Variant: "v1"}},
+		Tags:         Tags{},
+		Properties:   Properties{},
+	})
+	if err := client.CreateAll(ctx, defs); err != nil {
+		t.Fatalf("Failed to create resources: %s", err)
+	}
+
+	feature := ResourceID{Name: "avg_amount", Variant: "v1", Type: FEATURE_VARIANT}
+	err := client.DeleteResourceVariant(ctx, feature)
+	if code := fferr.GetErrorCode(err); code != fferr.CodeResourceHasDependents {
+		t.Fatalf("Expected %s, got %s: %v", fferr.CodeResourceHasDependents, code, err)
+	}
+	for _, blocker := range []string{"fraud (v1)", "fraud_model"} {
+		if !strings.Contains(err.Error(), blocker) {
+			t.Fatalf("Expected %q in blockers: %s", blocker, err)
+		}
+	}
+
+	if _, err := client.ForceDeleteResourceVariant(ctx, feature, "wrong"); fferr.GetErrorCode(err) != fferr.CodeInvalidArgument {
+		t.Fatalf("Expected unconfirmed forced delete to fail with invalid argument, got %v", err)
+	}
+	if err := client.DeleteResourceVariant(ctx, ResourceID{Name: "Featureform", Type: USER}); err == nil {
+		t.Fatalf("Expected error deleting a user")
+	}
+}
+
+func TestRemoveModelReferences(t *testing.T) {
+	_, ctx, _ := logging.InitializeTestRequestID(t)
+	model := &modelResource{&pb.Model{
+		Name:         "fraud_model",
+		Features:     []*pb.NameVariant{{Name: "avg_amount", Variant: "v1"}, {Name: "avg_amount", Variant: "v2"}},
+		Trainingsets: []*pb.NameVariant{{Name: "fraud", Variant: "v1"}},
+	}}
+	unrelated := &modelResource{&pb.Model{
+		Name:     "other_model",
+		Features: []*pb.NameVariant{{Name: "avg_amount", Variant: "v2"}},
+	}}
+	lookup := LocalResourceLookup{model.ID(): model, unrelated.ID(): unrelated}
+	serv := &MetadataServer{lookup: lookup}
+	deleted := []ResourceID{
+		{Name: "avg_amount", Variant: "v1", Type: FEATURE_VARIANT},
+		{Name: "fraud", Variant: "v1", Type: TRAINING_SET_VARIANT},
+	}
+	if err := serv.removeModelReferences(ctx, deleted); err != nil {
+		t.Fatalf("Failed to remove model references: %s", err)
+	}
+	updated := lookup[model.ID()].(*modelResource).serialized
+	if len(updated.Features) != 1 || updated.Features[0].Variant != "v2" {
+		t.Fatalf("Expected only avg_amount (v2) to remain, got %v", updated.Features)
+	}
+	if len(updated.Trainingsets) != 0 {
+		t.Fatalf("Expected deleted training set to be removed, got %v", updated.Trainingsets)
+	}
+	if features := lookup[unrelated.ID()].(*modelResource).serialized.Features; len(features) != 1 {
+		t.Fatalf("Expected unrelated model to be unchanged, got %v", features)
+	}
+}
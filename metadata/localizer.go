@@ -145,7 +145,7 @@ func GetLocalizer(providerType pt.Type, config pc.SerializedConfig) (Localizer,
 	switch providerType {
 	case pt.SparkOffline:
 		return NewSparkLocalizer(config)
-	case pt.SnowflakeOffline, pt.BigQueryOffline, pt.RedshiftOffline, pt.ClickHouseOffline, pt.PostgresOffline:
+	case pt.SnowflakeOffline, pt.BigQueryOffline, pt.RedshiftOffline, pt.ClickHouseOffline, pt.PostgresOffline, pt.DatabricksSQLOffline:
 		return &SqlLocalizer{}, nil
 	default:
 		return nil, fferr.NewInternalErrorf("provider type %s does not support localizer interface", providerType)
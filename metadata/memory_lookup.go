@@ -11,19 +11,28 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"sort"
 	"time"
 
 	"github.com/featureform/fferr"
+	"github.com/featureform/helpers/encryption"
+	"github.com/featureform/helpers/tracing"
 	"github.com/featureform/logging"
 	pb "github.com/featureform/metadata/proto"
 	"github.com/featureform/storage"
+	"github.com/featureform/storage/query"
 	"github.com/pkg/errors"
+	"go.opentelemetry.io/otel/attribute"
 	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
 )
 
 // Create Resource Lookup Using ETCD
 type MemoryResourceLookup struct {
 	Connection storage.MetadataStorage
+	// Encrypter encrypts provider configs before they're stored. If nil,
+	// they're stored in plaintext.
+	Encrypter *encryption.Encrypter
 }
 
 // Wrapper around Resource/Job messages. Allows top level storage for info about saved value
@@ -42,7 +51,11 @@ type MemoryRowTemp struct {
 }
 
 // Serializes the entire ETCD Storage Object to be put into ETCD
-func (lookup MemoryResourceLookup) serializeResource(res Resource) ([]byte, error) {
+func (lookup MemoryResourceLookup) serializeResource(ctx context.Context, res Resource) ([]byte, error) {
+	res, err := lookup.encryptConfig(ctx, res)
+	if err != nil {
+		return nil, err
+	}
 	p, err := protojson.Marshal(res.Proto())
 	if err != nil {
 		return nil, err
@@ -77,6 +90,13 @@ func (lookup MemoryResourceLookup) deserialize(value []byte) (StoredRow, error)
 }
 
 func (lookup MemoryResourceLookup) Lookup(ctx context.Context, id ResourceID, opts ...ResourceLookupOption) (Resource, error) {
+	ctx, span := tracing.StartSpan(ctx, "metadata.Lookup", attribute.String("resource_id", id.String()))
+	res, err := lookup.lookup(ctx, id, opts...)
+	tracing.EndSpan(span, err)
+	return res, err
+}
+
+func (lookup MemoryResourceLookup) lookup(ctx context.Context, id ResourceID, opts ...ResourceLookupOption) (Resource, error) {
 	logger := logging.GetLoggerFromContext(ctx)
 	key := createKey(id)
 	logger.With("lookup-key", key)
@@ -97,6 +117,43 @@ func (lookup MemoryResourceLookup) Lookup(ctx context.Context, id ResourceID, op
 		logger.Debug("Key not found")
 		return nil, fferr.NewKeyNotFoundError(key, err)
 	}
+	resource, err := lookup.decodeResource(ctx, resp)
+	if err != nil {
+		return nil, err
+	}
+	logger.Info("DB lookup successful")
+	return resource, nil
+}
+
+// Update replaces the resource with the one returned by updateFn, holding the
+// resource's lock from when it's read until the update is written, so
+// concurrent updates to the same resource aren't lost.
+func (lookup MemoryResourceLookup) Update(ctx context.Context, id ResourceID, updateFn func(Resource) (Resource, error)) (Resource, error) {
+	var updated Resource
+	err := lookup.Connection.Update(createKey(id), func(current string) (string, error) {
+		resource, err := lookup.decodeResource(ctx, current)
+		if err != nil {
+			return "", err
+		}
+		updated, err = updateFn(resource)
+		if err != nil {
+			return "", err
+		}
+		serialized, err := lookup.serializeResource(ctx, updated)
+		if err != nil {
+			return "", err
+		}
+		return string(serialized), nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return updated, nil
+}
+
+// decodeResource parses a stored resource and decrypts its config.
+func (lookup MemoryResourceLookup) decodeResource(ctx context.Context, resp string) (Resource, error) {
+	logger := logging.GetLoggerFromContext(ctx)
 	logger.Debug("Deserializing key")
 	msg, err := lookup.deserialize([]byte(resp))
 	if err != nil {
@@ -115,7 +172,10 @@ func (lookup MemoryResourceLookup) Lookup(ctx context.Context, id ResourceID, op
 		logger.Errorw("Failed to parse resource from DB", "err", err)
 		return nil, err
 	}
-	logger.Info("DB lookup successful")
+	if err := lookup.decryptConfig(ctx, resource); err != nil {
+		logger.Errorw("Failed to decrypt provider config", "err", err)
+		return nil, err
+	}
 	return resource, nil
 }
 
@@ -203,7 +263,7 @@ func (lookup MemoryResourceLookup) SetSchedule(ctx context.Context, id ResourceI
 }
 
 func (lookup MemoryResourceLookup) Set(ctx context.Context, id ResourceID, res Resource) error {
-	serRes, err := lookup.serializeResource(res)
+	serRes, err := lookup.serializeResource(ctx, res)
 	if err != nil {
 		return err
 	}
@@ -215,6 +275,13 @@ func (lookup MemoryResourceLookup) Set(ctx context.Context, id ResourceID, res R
 }
 
 func (lookup MemoryResourceLookup) Submap(ctx context.Context, ids []ResourceID) (ResourceLookup, error) {
+	_, span := tracing.StartSpan(ctx, "metadata.Submap", attribute.Int("resource_count", len(ids)))
+	resources, err := lookup.submap(ctx, ids)
+	tracing.EndSpan(span, err)
+	return resources, err
+}
+
+func (lookup MemoryResourceLookup) submap(ctx context.Context, ids []ResourceID) (ResourceLookup, error) {
 	resources := make(LocalResourceLookup, len(ids))
 
 	for _, id := range ids {
@@ -237,6 +304,9 @@ func (lookup MemoryResourceLookup) Submap(ctx context.Context, ids []ResourceID)
 		if err != nil {
 			return nil, err
 		}
+		if err := lookup.decryptConfig(ctx, res); err != nil {
+			return nil, err
+		}
 		resources[id] = res
 	}
 	return resources, nil
@@ -262,6 +332,9 @@ func (lookup MemoryResourceLookup) ListForType(ctx context.Context, t ResourceTy
 			logging.GlobalLogger.Errorw("Failed to parse resource", "error", err)
 			return nil, err
 		}
+		if err := lookup.decryptConfig(ctx, parsedResource); err != nil {
+			return nil, err
+		}
 		if parsedResource.ID().Type == t {
 			resources = append(resources, parsedResource)
 		}
@@ -269,6 +342,44 @@ func (lookup MemoryResourceLookup) ListForType(ctx context.Context, t ResourceTy
 	return resources, nil
 }
 
+func (lookup MemoryResourceLookup) ListForTypePage(ctx context.Context, t ResourceType, after ResourceID, limit int) ([]Resource, error) {
+	opts := []query.Query{query.KeySort{}, query.Limit{Limit: limit}}
+	if after != (ResourceID{}) {
+		opts = append(opts, query.KeyAfter{Key: createKey(after)})
+	}
+	// The separator keeps the prefix from also matching the type's variants.
+	resp, err := lookup.Connection.List(fmt.Sprintf("%s__", t), opts...)
+	if err != nil {
+		return nil, err
+	}
+	// Keys start with the resource name, so sorting them orders the page by name.
+	keys := make([]string, 0, len(resp))
+	for key := range resp {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	resources := make([]Resource, 0, len(keys))
+	for _, key := range keys {
+		storedRow, err := lookup.deserialize([]byte(resp[key]))
+		if err != nil {
+			return nil, err
+		}
+		resource, err := CreateEmptyResource(storedRow.ResourceType)
+		if err != nil {
+			return nil, err
+		}
+		parsedResource, err := ParseResource(storedRow, resource)
+		if err != nil {
+			return nil, err
+		}
+		if err := lookup.decryptConfig(ctx, parsedResource); err != nil {
+			return nil, err
+		}
+		resources = append(resources, parsedResource)
+	}
+	return resources, nil
+}
+
 func (lookup MemoryResourceLookup) ListVariants(ctx context.Context, t ResourceType, name string, opts ...ResourceLookupOption) ([]Resource, error) {
 	logger := logging.NewLogger("memmory_lookup.go:ListVariants")
 	startTime := time.Now()
@@ -301,6 +412,9 @@ func (lookup MemoryResourceLookup) ListVariants(ctx context.Context, t ResourceT
 		if err != nil {
 			return nil, err
 		}
+		if err := lookup.decryptConfig(ctx, resource); err != nil {
+			return nil, err
+		}
 		id := resource.ID()
 		if id.Type == t && id.Name == name {
 			resources = append(resources, resource)
@@ -329,11 +443,45 @@ func (lookup MemoryResourceLookup) List(ctx context.Context) ([]Resource, error)
 		if err != nil {
 			return nil, err
 		}
+		if err := lookup.decryptConfig(ctx, resource); err != nil {
+			return nil, err
+		}
 		resources = append(resources, resource)
 	}
 	return resources, nil
 }
 
+// encryptConfig returns a copy of res with its config encrypted if it's a
+// provider. Configs that are already encrypted are left as is.
+func (lookup MemoryResourceLookup) encryptConfig(ctx context.Context, res Resource) (Resource, error) {
+	provider, ok := res.(*providerResource)
+	if !ok || lookup.Encrypter == nil || encryption.IsEncrypted(provider.serialized.SerializedConfig) {
+		return res, nil
+	}
+	encrypted, err := lookup.Encrypter.Encrypt(ctx, provider.serialized.SerializedConfig)
+	if err != nil {
+		return nil, err
+	}
+	serialized := proto.Clone(provider.serialized).(*pb.Provider)
+	serialized.SerializedConfig = encrypted
+	return &providerResource{serialized}, nil
+}
+
+// decryptConfig decrypts res's config in place if it's a provider. Configs
+// stored before encryption was enabled are plaintext and left as is.
+func (lookup MemoryResourceLookup) decryptConfig(ctx context.Context, res Resource) error {
+	provider, ok := res.(*providerResource)
+	if !ok {
+		return nil
+	}
+	decrypted, err := lookup.Encrypter.Decrypt(ctx, provider.serialized.SerializedConfig)
+	if err != nil {
+		return err
+	}
+	provider.serialized.SerializedConfig = decrypted
+	return nil
+}
+
 func (lookup *MemoryResourceLookup) SetStatus(ctx context.Context, id ResourceID, status *pb.ResourceStatus) error {
 	res, err := lookup.Lookup(ctx, id)
 	if err != nil {
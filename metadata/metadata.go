@@ -12,10 +12,12 @@ package metadata
 
 import (
 	"context"
+	"encoding/base64"
 	"fmt"
 	"io"
 	"net"
 	"os"
+	"reflect"
 	"sort"
 	"strings"
 	"time"
@@ -24,11 +26,19 @@ import (
 	"github.com/pkg/errors"
 	"golang.org/x/exp/slices"
 	"google.golang.org/grpc"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	grpcmeta "google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/reflection"
+	grpcstatus "google.golang.org/grpc/status"
 	"google.golang.org/protobuf/proto"
 	tspb "google.golang.org/protobuf/types/known/timestamppb"
 
+	"github.com/featureform/config"
 	"github.com/featureform/fferr"
 	"github.com/featureform/filestore"
+	"github.com/featureform/helpers/encryption"
+	"github.com/featureform/helpers/events"
+	"github.com/featureform/helpers/grpctls"
 	"github.com/featureform/helpers/interceptors"
 	"github.com/featureform/helpers/notifications"
 	"github.com/featureform/logging"
@@ -39,6 +49,7 @@ import (
 	pl "github.com/featureform/provider/location"
 	pc "github.com/featureform/provider/provider_config"
 	pt "github.com/featureform/provider/provider_type"
+	"github.com/featureform/provider/sqlcheck"
 	ptypes "github.com/featureform/provider/types"
 	"github.com/featureform/scheduling"
 	schproto "github.com/featureform/scheduling/proto"
@@ -259,43 +270,13 @@ func (id ResourceID) ToCommonResourceID() common.ResourceID {
 	}
 }
 
-var bannedStrings = [...]string{"__"}
-var bannedPrefixes = [...]string{"_"}
-var bannedSuffixes = [...]string{"_"}
-
-func resourceNamedSafely(id ResourceID) error {
-	for _, substr := range bannedStrings {
-		if strings.Contains(id.Name, substr) {
-			return fferr.NewInvalidResourceVariantNameError(id.Name, id.Variant, fferr.ResourceType(id.Type.String()), fmt.Errorf("resource name contains banned string %s", substr))
-		}
-		if strings.Contains(id.Variant, substr) {
-			return fferr.NewInvalidResourceVariantNameError(id.Name, id.Variant, fferr.ResourceType(id.Type.String()), fmt.Errorf("resource variant %s contains banned string %s", id.Name, substr))
-		}
-	}
-	for _, substr := range bannedPrefixes {
-		if strings.HasPrefix(id.Name, substr) {
-			return fferr.NewInvalidResourceVariantNameError(id.Name, id.Variant, fferr.ResourceType(id.Type.String()), fmt.Errorf("resource name %s contains banned prefix %s", id.Name, substr))
-		}
-		if strings.HasPrefix(id.Variant, substr) {
-			return fferr.NewInvalidResourceVariantNameError(id.Name, id.Variant, fferr.ResourceType(id.Type.String()), fmt.Errorf("resource variant %s contains banned prefix %s", id.Name, substr))
-		}
-	}
-	for _, substr := range bannedSuffixes {
-		if strings.HasSuffix(id.Name, substr) {
-			return fferr.NewInvalidResourceVariantNameError(id.Name, id.Variant, fferr.ResourceType(id.Type.String()), fmt.Errorf("resource name %s contains banned suffix %s", id.Name, substr))
-		}
-		if strings.HasSuffix(id.Variant, substr) {
-			return fferr.NewInvalidResourceVariantNameError(id.Name, id.Variant, fferr.ResourceType(id.Type.String()), fmt.Errorf("resource variant %s contains banned suffix %s", id.Name, substr))
-		}
-	}
-	return nil
-}
-
 type ResourceVariant interface {
 	ID() ResourceID
 	IsEquivalent(ResourceVariant) (bool, error)
 	ToResourceVariantProto() *pb.ResourceVariant
 	Owner() string
+	IsArchived() bool
+	SetArchived(bool)
 }
 
 type Resource interface {
@@ -409,8 +390,15 @@ type ResourceLookup interface {
 	Lookup(context.Context, ResourceID, ...ResourceLookupOption) (Resource, error)
 	Has(context.Context, ResourceID) (bool, error)
 	Set(context.Context, ResourceID, Resource) error
+	// Update replaces a resource with the one returned by the update function,
+	// without other writes to the resource being interleaved.
+	Update(context.Context, ResourceID, func(Resource) (Resource, error)) (Resource, error)
 	Submap(context.Context, []ResourceID) (ResourceLookup, error)
 	ListForType(context.Context, ResourceType) ([]Resource, error)
+	// ListForTypePage lists up to limit resources of a type, ordered by name,
+	// starting strictly after the resource after. A zero after starts at the
+	// first resource.
+	ListForTypePage(ctx context.Context, t ResourceType, after ResourceID, limit int) ([]Resource, error)
 	List(context.Context) ([]Resource, error)
 	ListVariants(context.Context, ResourceType, string, ...ResourceLookupOption) ([]Resource, error)
 	HasJob(context.Context, ResourceID) (bool, error)
@@ -438,6 +426,24 @@ func (wrapper SearchWrapper) Set(ctx context.Context, id ResourceID, res Resourc
 	if err := wrapper.ResourceLookup.Set(ctx, id, res); err != nil {
 		return err
 	}
+	return wrapper.index(id, res)
+}
+
+func (wrapper SearchWrapper) Update(ctx context.Context, id ResourceID, updateFn func(Resource) (Resource, error)) (Resource, error) {
+	updated, err := wrapper.ResourceLookup.Update(ctx, id, updateFn)
+	if err != nil {
+		return nil, err
+	}
+	return updated, wrapper.index(id, updated)
+}
+
+// index updates a resource's search document after it's written.
+func (wrapper SearchWrapper) index(id ResourceID, res Resource) error {
+	// Archived variants are hidden from listings, so they're hidden from search
+	// too until they're unarchived.
+	if variant, ok := res.(ResourceVariant); ok && variant.IsArchived() {
+		return wrapper.RemoveFromSearch(id)
+	}
 
 	var allTags []string
 	switch res.(type) {
@@ -463,6 +469,24 @@ func (wrapper SearchWrapper) Set(ctx context.Context, id ResourceID, res Resourc
 	return wrapper.Searcher.Upsert(doc)
 }
 
+func (wrapper SearchWrapper) Delete(ctx context.Context, id ResourceID) error {
+	if err := wrapper.ResourceLookup.Delete(ctx, id); err != nil {
+		return err
+	}
+	return wrapper.RemoveFromSearch(id)
+}
+
+// RemoveFromSearch deletes a resource's document from search without touching
+// the resource itself.
+func (wrapper SearchWrapper) RemoveFromSearch(id ResourceID) error {
+	docID := search.DocumentID(search.ResourceDoc{
+		Name:    id.Name,
+		Type:    id.Type.String(),
+		Variant: id.Variant,
+	})
+	return wrapper.Searcher.Delete(docID)
+}
+
 type LocalResourceLookup map[ResourceID]Resource
 
 func (lookup LocalResourceLookup) Lookup(ctx context.Context, id ResourceID, opts ...ResourceLookupOption) (Resource, error) {
@@ -491,6 +515,19 @@ func (lookup LocalResourceLookup) Set(ctx context.Context, id ResourceID, res Re
 	return nil
 }
 
+func (lookup LocalResourceLookup) Update(ctx context.Context, id ResourceID, updateFn func(Resource) (Resource, error)) (Resource, error) {
+	current, err := lookup.Lookup(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	updated, err := updateFn(current)
+	if err != nil {
+		return nil, err
+	}
+	lookup[id] = updated
+	return updated, nil
+}
+
 func (lookup LocalResourceLookup) Submap(ctx context.Context, ids []ResourceID) (ResourceLookup, error) {
 	resources := make(LocalResourceLookup, len(ids))
 	for _, id := range ids {
@@ -515,6 +552,27 @@ func (lookup LocalResourceLookup) ListForType(ctx context.Context, t ResourceTyp
 	return resources, nil
 }
 
+func (lookup LocalResourceLookup) ListForTypePage(ctx context.Context, t ResourceType, after ResourceID, limit int) ([]Resource, error) {
+	resources, err := lookup.ListForType(ctx, t)
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(resources, func(i, j int) bool {
+		return createKey(resources[i].ID()) < createKey(resources[j].ID())
+	})
+	start := 0
+	if after != (ResourceID{}) {
+		start = sort.Search(len(resources), func(i int) bool {
+			return createKey(resources[i].ID()) > createKey(after)
+		})
+	}
+	end := start + limit
+	if end > len(resources) {
+		end = len(resources)
+	}
+	return resources[start:end], nil
+}
+
 func (lookup LocalResourceLookup) ListVariants(ctx context.Context, t ResourceType, name string, opts ...ResourceLookupOption) ([]Resource, error) {
 	if len(opts) > 0 {
 		return nil, fferr.NewInternalErrorf("lookup options not supported for local resource lookup")
@@ -791,6 +849,14 @@ func (resource *sourceVariantResource) Owner() string {
 	return resource.serialized.Owner
 }
 
+func (resource *sourceVariantResource) IsArchived() bool {
+	return resource.serialized.Archived
+}
+
+func (resource *sourceVariantResource) SetArchived(archived bool) {
+	resource.serialized.Archived = archived
+}
+
 type featureResource struct {
 	serialized *pb.Feature
 }
@@ -1030,6 +1096,14 @@ func (resource *featureVariantResource) Owner() string {
 	return resource.serialized.Owner
 }
 
+func (resource *featureVariantResource) IsArchived() bool {
+	return resource.serialized.Archived
+}
+
+func (resource *featureVariantResource) SetArchived(archived bool) {
+	resource.serialized.Archived = archived
+}
+
 type labelResource struct {
 	serialized *pb.Label
 }
@@ -1275,6 +1349,14 @@ func (resource *labelVariantResource) Owner() string {
 	return resource.serialized.Owner
 }
 
+func (resource *labelVariantResource) IsArchived() bool {
+	return resource.serialized.Archived
+}
+
+func (resource *labelVariantResource) SetArchived(archived bool) {
+	resource.serialized.Archived = archived
+}
+
 type trainingSetResource struct {
 	serialized *pb.TrainingSet
 }
@@ -1386,16 +1468,12 @@ func (resource *trainingSetVariantResource) Dependencies(ctx context.Context, lo
 			Name: serialized.Provider,
 			Type: PROVIDER,
 		},
-		{
-			Name:    serialized.Label.Name,
-			Variant: serialized.Label.Variant,
-			Type:    LABEL_VARIANT,
-		},
 		{
 			Name: serialized.Name,
 			Type: TRAINING_SET,
 		},
 	}
+	depIds = append(depIds, trainingSetLabelIDs(serialized)...)
 	for _, feature := range serialized.Features {
 		depIds = append(depIds, ResourceID{
 			Name:    feature.Name,
@@ -1410,6 +1488,20 @@ func (resource *trainingSetVariantResource) Dependencies(ctx context.Context, lo
 	return deps, nil
 }
 
+// trainingSetLabelIDs returns the IDs of each of the training set's labels,
+// starting with its first.
+func trainingSetLabelIDs(serialized *pb.TrainingSetVariant) []ResourceID {
+	labels := serialized.GetLabels()
+	if len(labels) == 0 {
+		labels = []*pb.NameVariant{serialized.GetLabel()}
+	}
+	ids := make([]ResourceID, len(labels))
+	for i, label := range labels {
+		ids[i] = ResourceID{Name: label.GetName(), Variant: label.GetVariant(), Type: LABEL_VARIANT}
+	}
+	return ids
+}
+
 func (resource *trainingSetVariantResource) Proto() proto.Message {
 	return resource.serialized
 }
@@ -1488,31 +1580,59 @@ func (resource *trainingSetVariantResource) Owner() string {
 	return resource.serialized.Owner
 }
 
+func (resource *trainingSetVariantResource) IsArchived() bool {
+	return resource.serialized.Archived
+}
+
+func (resource *trainingSetVariantResource) SetArchived(archived bool) {
+	resource.serialized.Archived = archived
+}
+
 func (resource *trainingSetVariantResource) Validate(ctx context.Context, lookup ResourceLookup) error {
 	logger := logging.GetLoggerFromContext(ctx)
-	resId := ResourceID{Name: resource.serialized.Label.Name, Variant: resource.serialized.Label.Variant, Type: LABEL_VARIANT}
-	label, err := lookup.Lookup(ctx, resId)
-	if err != nil {
-		return err
+	if persist := resource.serialized.GetPersistAs(); persist != nil && strings.TrimSpace(persist.Table) == "" {
+		return fferr.NewInvalidArgumentErrorf("training set %s variant %s must name the table it's persisted to", resource.serialized.Name, resource.serialized.Variant)
 	}
-	labelVariant, isLabelVariant := label.(*labelVariantResource)
-	if !isLabelVariant {
-		return fferr.NewDatasetNotFoundError(resource.ID().Name, resource.ID().Variant, fmt.Errorf("label variant not found"))
+	if split := resource.serialized.GetSplit(); split != nil && (split.TrainFraction <= 0 || split.TrainFraction >= 1) {
+		return fferr.NewInvalidArgumentErrorf("training set %s variant %s split fraction must be between 0 and 1, got %v", resource.serialized.Name, resource.serialized.Variant, split.TrainFraction)
 	}
-	entityMap := make(map[string]struct{})
-	loc := labelVariant.serialized.GetLocation()
-	switch loc := loc.(type) {
-	case *pb.LabelVariant_Columns:
-		entityMap[labelVariant.serialized.Entity] = struct{}{}
-	case *pb.LabelVariant_EntityMappings:
-		for _, mapping := range loc.EntityMappings.Mappings {
-			entityMap[mapping.Name] = struct{}{}
+	// Generated queries express the max feature age in whole seconds.
+	if age := resource.serialized.GetMaxFeatureAge(); age != nil && age.AsDuration() < time.Second {
+		return fferr.NewInvalidArgumentErrorf("training set %s variant %s max feature age must be at least one second, got %s", resource.serialized.Name, resource.serialized.Variant, age.AsDuration())
+	}
+	labelIDs := trainingSetLabelIDs(resource.serialized)
+	if labels := resource.serialized.GetLabels(); len(labels) != 0 {
+		first := resource.serialized.GetLabel()
+		if first.GetName() != labels[0].GetName() || first.GetVariant() != labels[0].GetVariant() {
+			return fferr.NewInvalidArgumentErrorf("training set label %s (%s) must be the first of its labels", first.GetName(), first.GetVariant())
+		}
+	}
+	var labelVariant *labelVariantResource
+	var entityMap map[string]struct{}
+	seen := make(map[ResourceID]struct{}, len(labelIDs))
+	for _, resId := range labelIDs {
+		if _, isDuplicate := seen[resId]; isDuplicate {
+			return fferr.NewInvalidArgumentErrorf("training set has label %s (%s) more than once", resId.Name, resId.Variant)
+		}
+		seen[resId] = struct{}{}
+		label, err := lookup.Lookup(ctx, resId)
+		if err != nil {
+			return err
+		}
+		variant, isLabelVariant := label.(*labelVariantResource)
+		if !isLabelVariant {
+			return fferr.NewDatasetNotFoundError(resource.ID().Name, resource.ID().Variant, fmt.Errorf("label variant not found"))
+		}
+		entities, err := labelEntities(logger, variant)
+		if err != nil {
+			return err
+		}
+		if labelVariant == nil {
+			labelVariant, entityMap = variant, entities
+		} else if !reflect.DeepEqual(entities, entityMap) {
+			return fferr.NewInvalidArgumentErrorf("training set labels must share the entity: label %s (%s) doesn't match label %s (%s)",
+				resId.Name, resId.Variant, labelVariant.serialized.Name, labelVariant.serialized.Variant)
 		}
-	case *pb.LabelVariant_Stream:
-		// There's nothing to be done here; however, we don't want the match on stream to result in an error.
-		logger.Debugw("stream location type detected for training set variant resource", "location", loc)
-	default:
-		return fferr.NewInternalErrorf("unknown location type %T", loc)
 	}
 	for _, feature := range resource.serialized.Features {
 		fvResId := ResourceID{Name: feature.Name, Variant: feature.Variant, Type: FEATURE_VARIANT}
@@ -1538,6 +1658,26 @@ func (resource *trainingSetVariantResource) Validate(ctx context.Context, lookup
 	return nil
 }
 
+// labelEntities returns the entities that a label is keyed by.
+func labelEntities(logger logging.Logger, labelVariant *labelVariantResource) (map[string]struct{}, error) {
+	entityMap := make(map[string]struct{})
+	loc := labelVariant.serialized.GetLocation()
+	switch loc := loc.(type) {
+	case *pb.LabelVariant_Columns:
+		entityMap[labelVariant.serialized.Entity] = struct{}{}
+	case *pb.LabelVariant_EntityMappings:
+		for _, mapping := range loc.EntityMappings.Mappings {
+			entityMap[mapping.Name] = struct{}{}
+		}
+	case *pb.LabelVariant_Stream:
+		// There's nothing to be done here; however, we don't want the match on stream to result in an error.
+		logger.Debugw("stream location type detected for training set variant resource", "location", loc)
+	default:
+		return nil, fferr.NewInternalErrorf("unknown location type %T", loc)
+	}
+	return entityMap, nil
+}
+
 type modelResource struct {
 	serialized *pb.Model
 }
@@ -1815,6 +1955,11 @@ func (resource *providerResource) Update(lookup ResourceLookup, resourceUpdate R
 }
 
 func (resource *providerResource) isValidConfigUpdate(configUpdate pc.SerializedConfig) (bool, error) {
+	// The lookup decrypts configs when they're read, so one that's still
+	// encrypted couldn't be decrypted and can't be compared field by field.
+	if encryption.IsEncrypted(resource.serialized.SerializedConfig) || encryption.IsEncrypted(configUpdate) {
+		return false, fferr.NewInternalErrorf("cannot compare encrypted provider configs for %s", resource.ID())
+	}
 	switch pt.Type(resource.serialized.Type) {
 	case pt.BigQueryOffline:
 		return isValidBigQueryConfigUpdate(resource.serialized.SerializedConfig, configUpdate)
@@ -1826,10 +1971,14 @@ func (resource *providerResource) isValidConfigUpdate(configUpdate pc.Serialized
 		return isValidFirestoreConfigUpdate(resource.serialized.SerializedConfig, configUpdate)
 	case pt.MongoDBOnline:
 		return isValidMongoConfigUpdate(resource.serialized.SerializedConfig, configUpdate)
+	case pt.BigtableOnline:
+		return isValidBigtableConfigUpdate(resource.serialized.SerializedConfig, configUpdate)
 	case pt.PostgresOffline:
 		return isValidPostgresConfigUpdate(resource.serialized.SerializedConfig, configUpdate)
 	case pt.ClickHouseOffline:
 		return isValidClickHouseConfigUpdate(resource.serialized.SerializedConfig, configUpdate)
+	case pt.DatabricksSQLOffline:
+		return isValidDatabricksSQLConfigUpdate(resource.serialized.SerializedConfig, configUpdate)
 	case pt.RedisOnline:
 		return isValidRedisConfigUpdate(resource.serialized.SerializedConfig, configUpdate)
 	case pt.SnowflakeOffline:
@@ -1840,7 +1989,9 @@ func (resource *providerResource) isValidConfigUpdate(configUpdate pc.Serialized
 		return isValidK8sConfigUpdate(resource.serialized.SerializedConfig, configUpdate)
 	case pt.SparkOffline:
 		return isValidSparkConfigUpdate(resource.serialized.SerializedConfig, configUpdate)
-	case pt.S3, pt.HDFS, pt.GCS, pt.AZURE, pt.BlobOnline:
+	case pt.Kafka:
+		return isValidKafkaConfigUpdate(resource.serialized.SerializedConfig, configUpdate)
+	case pt.S3, pt.HDFS, pt.GCS, pt.AZURE, pt.BlobOnline, pt.MemoryOnline:
 		return true, nil
 	default:
 		return false, fferr.NewInternalError(fmt.Errorf("unable to update config for provider. Provider type %s not found", resource.serialized.Type))
@@ -1935,6 +2086,11 @@ type MetadataServer struct {
 	schproto.UnimplementedTasksServer
 	slackNotifier       notifications.SlackNotifier
 	resourcesRepository ResourcesRepository
+	health              *HealthChecker
+	authenticator       interceptors.Authenticator
+	events              *events.Emitter
+	idempotencyKeys     *idempotencyKeys
+	namingRules         NamingRules
 }
 
 func (serv *MetadataServer) CreateTaskRun(ctx context.Context, request *schproto.CreateRunRequest) (*schproto.RunID, error) {
@@ -1968,8 +2124,8 @@ func NewMetadataServer(config *Config) (*MetadataServer, error) {
 
 	config.Logger.Infow("Creating new metadata server", "address", config.Address)
 
-	baseLookup := MemoryResourceLookup{config.TaskManager.Storage}
-	wrappedLookup, err := initializeLookup(config, &baseLookup, search.NewMeilisearch)
+	baseLookup := MemoryResourceLookup{Connection: config.TaskManager.Storage, Encrypter: config.Encrypter}
+	wrappedLookup, err := initializeLookup(config, &baseLookup, search.NewSearcher)
 	if err != nil {
 		config.Logger.Errorw("Failed to initialize lookup", "error", err)
 		return nil, fferr.NewInternalErrorf("failed to initialize lookup: %w", err)
@@ -1986,17 +2142,35 @@ func NewMetadataServer(config *Config) (*MetadataServer, error) {
 		return nil, fferr.NewInternalErrorf("resources repository is nil")
 	}
 
-	return &MetadataServer{
+	health := config.Health
+	if health == nil {
+		health = NewHealthChecker()
+	}
+	authenticator := config.Authenticator
+	if authenticator == nil {
+		authenticator = interceptors.NoopAuthenticator{}
+	}
+	serv := &MetadataServer{
 		lookup:              wrappedLookup,
 		address:             config.Address,
 		Logger:              config.Logger,
 		taskManager:         &config.TaskManager,
 		resourcesRepository: resourcesRepo,
 		slackNotifier:       *notifications.NewSlackNotifier(os.Getenv("SLACK_CHANNEL_ID"), config.Logger),
-	}, nil
+		health:              health,
+		authenticator:       authenticator,
+		events:              config.Events,
+		idempotencyKeys:     newIdempotencyKeys(config.IdempotencyKeyTTL, config.TaskManager.Storage, config.Logger),
+		namingRules:         DefaultNamingRules(),
+	}
+	if config.NamingRules != nil {
+		serv.namingRules = serv.namingRules.Extend(*config.NamingRules)
+	}
+	health.SetServer(serv)
+	return serv, nil
 }
 
-func initializeLookup(config *Config, lookup *MemoryResourceLookup, newSearchStub search.NewMeilisearchFunc) (ResourceLookup, error) {
+func initializeLookup(config *Config, lookup *MemoryResourceLookup, newSearchStub search.NewSearcherFunc) (ResourceLookup, error) {
 	if config.SearchParams == nil {
 		config.Logger.Debug("No configuration search params are present, using non-search wrappped lookup")
 		return lookup, nil
@@ -2211,6 +2385,103 @@ func (serv *MetadataServer) SetRunResumeID(ctx context.Context, update *schproto
 	return &schproto.Empty{}, nil
 }
 
+func (serv *MetadataServer) IncrementRunAttempts(ctx context.Context, id *schproto.TaskRunID) (*schproto.RunAttempts, error) {
+	_, _, logger := serv.Logger.InitializeRequestID(ctx)
+	logger = logger.WithValues(map[string]interface{}{
+		"task_id": id.GetTaskID().GetId(),
+		"run_id":  id.GetRunID().GetId(),
+	})
+	tid, err := scheduling.ParseTaskID(id.GetTaskID().GetId())
+	if err != nil {
+		logger.Errorw("failed to parse task id", "error", err)
+		return nil, err
+	}
+	rid, err := scheduling.ParseTaskRunID(id.GetRunID().GetId())
+	if err != nil {
+		logger.Errorw("failed to parse run id", "error", err)
+		return nil, err
+	}
+	attempts, err := serv.taskManager.IncrementRunAttempts(rid, tid)
+	if err != nil {
+		logger.Errorw("failed to increment run attempts", "error", err)
+		return nil, err
+	}
+	logger.Infow("Incremented run attempts", "attempts", attempts)
+	return &schproto.RunAttempts{Attempts: int32(attempts)}, nil
+}
+
+func (serv *MetadataServer) SetRunProgress(ctx context.Context, update *schproto.RunProgressUpdate) (*schproto.Empty, error) {
+	_, _, logger := serv.Logger.InitializeRequestID(ctx)
+	logger = logger.WithValues(map[string]interface{}{
+		"task_id": update.GetTaskID().GetId(),
+		"run_id":  update.GetRunID().GetId(),
+	})
+	tid, err := scheduling.ParseTaskID(update.GetTaskID().GetId())
+	if err != nil {
+		logger.Errorw("failed to parse task id", "error", err)
+		return nil, err
+	}
+	rid, err := scheduling.ParseTaskRunID(update.GetRunID().GetId())
+	if err != nil {
+		logger.Errorw("failed to parse run id", "error", err)
+		return nil, err
+	}
+	if update.GetProgress() == nil {
+		return nil, fferr.NewInvalidArgumentErrorf("progress update for run %s is missing progress", rid)
+	}
+	progress := scheduling.RunProgressFromProto(update.GetProgress())
+	if update.GetProgress().GetUpdated() == nil {
+		progress.UpdatedAt = time.Time{}
+	}
+	if err := serv.taskManager.SetRunProgress(rid, tid, progress); err != nil {
+		logger.Errorw("failed to set run progress", "error", err)
+		return nil, err
+	}
+	logger.Debugw("Set run progress", "phase", progress.Phase, "rows_processed", progress.RowsProcessed, "total_rows", progress.TotalRows)
+	return &schproto.Empty{}, nil
+}
+
+func (serv *MetadataServer) GetTransformationCacheEntry(ctx context.Context, key *schproto.TransformationCacheKey) (*schproto.TransformationCacheEntry, error) {
+	_, _, logger := serv.Logger.InitializeRequestID(ctx)
+	logger = logger.WithResource(logging.SourceVariant, key.GetName(), key.GetVariant())
+	entry, err := serv.taskManager.GetTransformationCacheEntry(key.GetName(), key.GetVariant())
+	var notFound *fferr.KeyNotFoundError
+	if errors.As(err, &notFound) {
+		logger.Debugw("No transformation cache entry")
+		return &schproto.TransformationCacheEntry{}, nil
+	}
+	if err != nil {
+		logger.Errorw("failed to get transformation cache entry", "error", err)
+		return nil, err
+	}
+	return &schproto.TransformationCacheEntry{
+		Hash:    entry.Hash,
+		Created: tspb.New(entry.CreatedAt),
+	}, nil
+}
+
+func (serv *MetadataServer) SetTransformationCacheEntry(ctx context.Context, update *schproto.TransformationCacheUpdate) (*schproto.Empty, error) {
+	_, _, logger := serv.Logger.InitializeRequestID(ctx)
+	key := update.GetKey()
+	logger = logger.WithResource(logging.SourceVariant, key.GetName(), key.GetVariant())
+	if err := serv.taskManager.SetTransformationCacheEntry(key.GetName(), key.GetVariant(), update.GetHash()); err != nil {
+		logger.Errorw("failed to set transformation cache entry", "error", err)
+		return nil, err
+	}
+	logger.Debugw("Set transformation cache entry", "hash", update.GetHash())
+	return &schproto.Empty{}, nil
+}
+
+func (serv *MetadataServer) DeleteTransformationCacheEntry(ctx context.Context, key *schproto.TransformationCacheKey) (*schproto.Empty, error) {
+	_, _, logger := serv.Logger.InitializeRequestID(ctx)
+	logger = logger.WithResource(logging.SourceVariant, key.GetName(), key.GetVariant())
+	if err := serv.taskManager.DeleteTransformationCacheEntry(key.GetName(), key.GetVariant()); err != nil {
+		logger.Errorw("failed to delete transformation cache entry", "error", err)
+		return nil, err
+	}
+	return &schproto.Empty{}, nil
+}
+
 func (serv *MetadataServer) WatchForCancel(ctx context.Context, id *schproto.TaskRunID) (*pb.ResourceStatus, error) {
 	_, _, logger := serv.Logger.InitializeRequestID(ctx)
 	tid, err := scheduling.ParseTaskID(id.TaskID.GetId())
@@ -2277,9 +2548,31 @@ func (serv *MetadataServer) serveOnListener(lis net.Listener) error {
 		return fferr.NewInternalErrorf("Can't serve metadata server on a NIL port/listerner")
 	}
 	serv.listener = lis
-	grpcServer := grpc.NewServer(grpc.UnaryInterceptor(interceptors.UnaryServerErrorInterceptor), grpc.StreamInterceptor(interceptors.StreamServerErrorInterceptor))
+	tlsOpts, err := grpctls.FromEnv().ServerOptions()
+	if err != nil {
+		serv.Logger.Errorw("Failed to load TLS credentials", "error", err)
+		return err
+	}
+	opts := append([]grpc.ServerOption{
+		grpc.ChainUnaryInterceptor(
+			interceptors.UnaryServerTracingInterceptor,
+			interceptors.UnaryServerAuthInterceptor(serv.authenticator),
+			interceptors.UnaryServerErrorInterceptor,
+		),
+		grpc.ChainStreamInterceptor(
+			interceptors.StreamServerTracingInterceptor,
+			interceptors.StreamServerAuthInterceptor(serv.authenticator),
+			interceptors.StreamServerErrorInterceptor,
+		),
+	}, tlsOpts...)
+	grpcServer := grpc.NewServer(opts...)
 	pb.RegisterMetadataServer(grpcServer, serv)
 	schproto.RegisterTasksServer(grpcServer, serv)
+	healthpb.RegisterHealthServer(grpcServer, serv.health)
+	if config.ShouldEnableGRPCReflection() {
+		serv.Logger.Infow("Registering gRPC reflection service")
+		reflection.Register(grpcServer)
+	}
 	serv.grpcServer = grpcServer
 	serv.Logger.Infow("Server starting", "Address", serv.listener.Addr().String())
 	return grpcServer.Serve(lis)
@@ -2319,17 +2612,75 @@ func (sp LocalStorageProvider) GetResourceLookup() (ResourceLookup, error) {
 
 type Config struct {
 	Logger       logging.Logger
-	SearchParams *search.MeilisearchParams
+	SearchParams search.Params
 	TaskManager  scheduling.TaskMetadataManager
 	Address      string
+	// Health is updated once the server is created. If nil, the server
+	// creates its own.
+	Health *HealthChecker
+	// Authenticator validates the callers of requests. If nil, requests
+	// aren't authenticated and anyone can create or update resources.
+	Authenticator interceptors.Authenticator
+	// Encrypter encrypts provider configs at rest. If nil, they're stored in
+	// plaintext.
+	Encrypter *encryption.Encrypter
+	// Events publishes resource changes to an external sink. If nil, no
+	// events are published.
+	Events *events.Emitter
+	// IdempotencyKeyTTL is how long the idempotency keys of successful creates
+	// are remembered. If zero, a default is used.
+	IdempotencyKeyTTL time.Duration
+	// NamingRules are rules resource names and variants must follow in
+	// addition to DefaultNamingRules, which always apply.
+	NamingRules *NamingRules
 }
 
 func (serv *MetadataServer) RequestScheduleChange(ctx context.Context, req *pb.ScheduleChangeRequest) (*pb.Empty, error) {
 	_, ctx, logger := serv.Logger.InitializeRequestID(ctx)
 	logger.Infow("Requesting schedule change", "resource_id", req.ResourceId, "schedule", req.Schedule)
+	if req.Schedule != "" {
+		if _, err := scheduling.ParseSchedule(req.Schedule); err != nil {
+			logger.Errorw("Invalid schedule", "error", err)
+			return nil, err
+		}
+	}
 	resID := ResourceID{Name: req.ResourceId.Resource.Name, Variant: req.ResourceId.Resource.Variant, Type: ResourceType(req.ResourceId.ResourceType)}
-	err := serv.lookup.SetSchedule(ctx, resID, req.Schedule)
-	return &pb.Empty{}, err
+	if err := serv.authorizeChange(ctx, "schedule", resID); err != nil {
+		logger.Errorw("Caller is not allowed to schedule resource", "error", err)
+		return nil, err
+	}
+	if err := serv.lookup.SetSchedule(ctx, resID, req.Schedule); err != nil {
+		return nil, err
+	}
+	if err := serv.scheduleResourceTasks(ctx, resID, req.Schedule); err != nil {
+		logger.Errorw("Failed to schedule resource tasks", "error", err)
+		return nil, err
+	}
+	return &pb.Empty{}, nil
+}
+
+// scheduleResourceTasks sets a cron schedule on the tasks that create the
+// resource, so the coordinator's cron scheduler reruns them. An empty schedule
+// stops them from being rerun.
+func (serv *MetadataServer) scheduleResourceTasks(ctx context.Context, id ResourceID, schedule string) error {
+	res, err := serv.lookup.Lookup(ctx, id)
+	if err != nil {
+		return err
+	}
+	taskImpl, hasTasks := res.(resourceTaskImplementation)
+	if !hasTasks {
+		return nil
+	}
+	taskIDs, err := taskImpl.TaskIDs()
+	if err != nil {
+		return err
+	}
+	for _, taskID := range taskIDs {
+		if err := serv.taskManager.SetTaskSchedule(taskID, schedule); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 func (serv *MetadataServer) SetResourceStatus(ctx context.Context, req *pb.SetStatusRequest) (*pb.Empty, error) {
@@ -2340,6 +2691,7 @@ func (serv *MetadataServer) SetResourceStatus(ctx context.Context, req *pb.SetSt
 	if err != nil {
 		logger.Errorw("Could not set resource status", "error", err.Error())
 	} else {
+		serv.emitEvent(resID, events.StatusChangeOperation, req.Status.Status.String())
 		//if no error, notify slack
 		go func() {
 			slackError := serv.slackNotifier.ChangeNotification(
@@ -2362,7 +2714,7 @@ func (serv *MetadataServer) SetResourceStatus(ctx context.Context, req *pb.SetSt
 func (serv *MetadataServer) ListFeatures(request *pb.ListRequest, stream pb.Metadata_ListFeaturesServer) error {
 	ctx := logging.AttachRequestID(logging.RequestID(request.RequestId), stream.Context(), serv.Logger)
 	logging.GetLoggerFromContext(ctx).Info("Opened List Features stream")
-	return serv.genericList(ctx, FEATURE, func(msg proto.Message) error {
+	return serv.genericList(ctx, FEATURE, request, stream, func(msg proto.Message) error {
 		return stream.Send(msg.(*pb.Feature))
 	})
 }
@@ -2373,6 +2725,13 @@ func (serv *MetadataServer) CreateFeatureVariant(ctx context.Context, variantReq
 	logger.Info("Creating Feature Variant")
 
 	variant := variantRequest.FeatureVariant
+	id := ResourceID{Name: variant.Name, Variant: variant.Variant, Type: FEATURE_VARIANT}
+	return serv.createIdempotent(ctx, variantRequest.IdempotencyKey, id, func() (*pb.Empty, error) {
+		return serv.createFeatureVariant(ctx, id, variant, logger)
+	})
+}
+
+func (serv *MetadataServer) createFeatureVariant(ctx context.Context, id ResourceID, variant *pb.FeatureVariant, logger logging.Logger) (*pb.Empty, error) {
 	variant.Created = tspb.New(time.Now())
 
 	logger.Debugw("Adding feature location")
@@ -2381,13 +2740,37 @@ func (serv *MetadataServer) CreateFeatureVariant(ctx context.Context, variantReq
 		return nil, err
 	}
 
+	if variant.Schedule != "" {
+		if _, err := scheduling.ParseSchedule(variant.Schedule); err != nil {
+			logger.Errorw("Invalid schedule", "error", err)
+			return nil, err
+		}
+	}
+
+	if variant.DefaultValue != "" {
+		if err := validateFeatureDefaultValue(variant); err != nil {
+			logger.Errorw("Invalid default value", "error", err)
+			return nil, err
+		}
+	}
+
+	if variant.MaterializationFilter != "" {
+		// The source's columns are checked by the provider when it materializes.
+		if err := sqlcheck.CheckFilterSyntax(variant.MaterializationFilter); err != nil {
+			logger.Errorw("Invalid materialization filter", "error", err)
+			wrapped := fferr.NewInvalidArgumentErrorf("feature %s variant %s has an invalid materialization filter: %v", variant.Name, variant.Variant, err)
+			wrapped.AddDetail("filter", variant.MaterializationFilter)
+			return nil, wrapped
+		}
+	}
+
 	taskTarget := scheduling.NameVariant{Name: variant.Name, Variant: variant.Variant, ResourceType: FEATURE_VARIANT.String()}
 	task, err := serv.taskManager.CreateTask(ctx, "mytask", scheduling.ResourceCreation, taskTarget)
 	if err != nil {
 		return nil, err
 	}
 	variant.TaskIdList = []string{task.ID.String()}
-	return serv.genericCreate(ctx, &featureVariantResource{variant}, func(name, variant string) Resource {
+	resp, err := serv.genericCreate(ctx, &featureVariantResource{variant}, func(name, variant string) Resource {
 		return &featureResource{
 			&pb.Feature{
 				Name:           name,
@@ -2397,6 +2780,30 @@ func (serv *MetadataServer) CreateFeatureVariant(ctx context.Context, variantReq
 			},
 		}
 	})
+	if err != nil {
+		return nil, err
+	}
+	if variant.Schedule != "" {
+		if err := serv.scheduleResourceTasks(ctx, id, variant.Schedule); err != nil {
+			logger.Errorw("Failed to schedule feature variant tasks", "error", err)
+			return nil, err
+		}
+	}
+	return resp, nil
+}
+
+// validateFeatureDefaultValue checks that a feature variant's default value is
+// of its type. Only features served from an online store can have defaults.
+func validateFeatureDefaultValue(variant *pb.FeatureVariant) error {
+	if CLIENT_COMPUTED.Equals(variant.Mode) {
+		return fferr.NewInvalidArgumentErrorf("on-demand feature %s variant %s can't have a default value", variant.Name, variant.Variant)
+	}
+	if _, err := featureDefaultValue(variant); err != nil {
+		wrapped := fferr.NewInvalidArgumentError(fmt.Errorf("feature %s variant %s has an invalid default value: %w", variant.Name, variant.Variant, err))
+		wrapped.AddDetail("default_value", variant.DefaultValue)
+		return wrapped
+	}
+	return nil
 }
 
 func (serv *MetadataServer) PruneResource(ctx context.Context, request *pb.PruneResourceRequest) (*pb.PruneResourceResponse, error) {
@@ -2438,6 +2845,10 @@ func (serv *MetadataServer) MarkForDeletion(ctx context.Context, request *pb.Mar
 		logger.Errorw("Could not find resource to delete", "error", err.Error())
 		return &pb.MarkForDeletionResponse{}, err
 	}
+	if err := serv.authorize(ctx, "delete", notCommonResId, resource); err != nil {
+		logger.Errorw("Caller is not allowed to delete resource", "error", err)
+		return &pb.MarkForDeletionResponse{}, err
+	}
 
 	isDeletableErr := serv.isDeletable(ctx, resource, logger)
 	if isDeletableErr != nil {
@@ -2450,31 +2861,317 @@ func (serv *MetadataServer) MarkForDeletion(ctx context.Context, request *pb.Mar
 		logger.Errorw("Could not delete resource", "error", deleteErr.Error())
 		return &pb.MarkForDeletionResponse{}, deleteErr
 	}
+	// The resource is already marked, so a stale search result isn't worth
+	// failing the request over.
+	if wrapper, ok := serv.lookup.(*SearchWrapper); ok {
+		if err := wrapper.RemoveFromSearch(notCommonResId); err != nil {
+			logger.Errorw("Could not remove deleted resource from search", "error", err.Error())
+		}
+	}
+	if err := serv.setArchivedIndex(notCommonResId, false); err != nil {
+		logger.Errorw("Could not remove deleted resource from archived variant index", "error", err)
+	}
 
 	logger.Info("Successfully marked resource for deletion")
 	return &pb.MarkForDeletionResponse{}, nil
 }
 
-// ensures dependent feature variants of a resource have updated fields
-// (offlineStoreProvider and offlineStoreLocations) before deletion. This allows
-// feature variants to be deleted independently of their sources, which may have
-// already been removed.
-func (serv *MetadataServer) ensureDependentFeatureBackwardsCompatability(ctx context.Context, resId common.ResourceID, logger logging.Logger) error {
-	deps, err := serv.resourcesRepository.GetDependencies(ctx, resId)
-	if err != nil {
-		logger.Errorw("failed to get dependencies", "error", err)
-		return err
-	}
+// DeleteResourceVariant deletes a variant once nothing depends on it. If other
+// variants or models use it, it fails with a ResourceHasDependentsError that
+// lists them. Setting Force prunes the dependent variants along with it, so it
+// has to be confirmed with the variant's name and the caller has to own every
+// dependent. Models are kept, but stop referencing the deleted variants.
+func (serv *MetadataServer) DeleteResourceVariant(ctx context.Context, request *pb.DeleteResourceVariantRequest) (*pb.DeleteResourceVariantResponse, error) {
+	ctx = logging.AttachRequestID(logging.RequestID(request.RequestId), ctx, serv.Logger)
+	logger := logging.GetLoggerFromContext(ctx)
+	logger.Infow("Deleting resource variant", "resource_id", request.ResourceId, "force", request.Force)
 
-	for _, dep := range deps {
-		if dep.Type == common.FEATURE_VARIANT {
-			if err := serv.updateFeatureVariant(ctx, dep, logger); err != nil {
-				return fferr.NewInternalErrorf("failed to update feature variant %s: %v", dep.Name, err)
-			}
-		}
+	resId := parseResourceIDProto(request.ResourceId)
+	if _, isVariant := parentMapping[resId.Type]; !isVariant {
+		logger.Errorw("Resource type cannot be deleted", "type", resId.Type)
+		return nil, fferr.NewInvalidResourceTypeError(resId.Name, resId.Variant, fferr.ResourceType(resId.Type.String()), fmt.Errorf("only feature, label, source, and training set variants can be deleted"))
+	}
+	if request.Force && request.Confirm != resId.Name {
+		return nil, fferr.NewInvalidArgumentErrorf("a forced delete of %s must be confirmed with its name", resId)
+	}
+	resource, err := serv.lookup.Lookup(ctx, resId)
+	if err != nil {
+		logger.Errorw("Could not find resource to delete", "error", err.Error())
+		return nil, err
+	}
+	if err := serv.authorize(ctx, "delete", resId, resource); err != nil {
+		logger.Errorw("Caller is not allowed to delete resource", "error", err)
+		return nil, err
+	}
+	if err := serv.isDeletable(ctx, resource, logger); err != nil {
+		logger.Errorw("Could not delete resource", "error", err.Error())
+		return nil, err
+	}
+	dependents, err := serv.deletionDependents(ctx, resource)
+	if err != nil {
+		logger.Errorw("Unable to get dependents", "error", err.Error())
+		return nil, err
+	}
+	if len(dependents) > 0 && !request.Force {
+		blockers := make([]string, len(dependents))
+		for i, dep := range dependents {
+			blockers[i] = dep.String()
+		}
+		logger.Infow("Resource variant has dependents", "dependents", blockers)
+		return nil, fferr.NewResourceHasDependentsError(resId.Name, resId.Variant, fferr.ResourceType(resId.Type.String()), blockers)
+	}
+
+	commonResId := resId.ToCommonResourceID()
+	deleted := []ResourceID{resId}
+	if len(dependents) == 0 {
+		if err := serv.resourcesRepository.MarkForDeletion(ctx, commonResId, serv.deletionTaskStarter); err != nil {
+			logger.Errorw("Could not delete resource", "error", err.Error())
+			return nil, err
+		}
+	} else {
+		for _, dep := range dependents {
+			if err := serv.authorizeChange(ctx, "delete", dep); err != nil {
+				logger.Errorw("Caller is not allowed to delete dependent", "dependent", dep, "error", err)
+				return nil, err
+			}
+		}
+		if err := serv.ensureDependentFeatureBackwardsCompatability(ctx, commonResId, logger); err != nil {
+			logger.Errorw("Failed to ensure feature variant backwards compatibility", "error", err)
+			return nil, err
+		}
+		pruned, err := serv.resourcesRepository.PruneResource(ctx, commonResId, serv.deletionTaskStarter)
+		if err != nil {
+			logger.Errorw("Could not prune resource", "error", err.Error())
+			return nil, err
+		}
+		deleted = make([]ResourceID, len(pruned))
+		for i, id := range pruned {
+			deleted[i] = ResourceID{Name: id.Name, Variant: id.Variant, Type: ResourceType(id.Type)}
+		}
+	}
+	if err := serv.removeModelReferences(ctx, deleted); err != nil {
+		logger.Errorw("Could not remove deleted variants from models", "error", err.Error())
+		return nil, err
+	}
+	ids := make([]*pb.ResourceID, len(deleted))
+	for i, id := range deleted {
+		ids[i] = id.Proto()
+		if wrapper, ok := serv.lookup.(*SearchWrapper); ok {
+			if err := wrapper.RemoveFromSearch(id); err != nil {
+				logger.Errorw("Could not remove deleted resource from search", "resource_id", id, "error", err.Error())
+			}
+		}
+		if err := serv.setArchivedIndex(id, false); err != nil {
+			logger.Errorw("Could not remove deleted resource from archived variant index", "resource_id", id, "error", err)
+		}
+	}
+	logger.Infow("Successfully deleted resource variant", "deleted", len(deleted))
+	return &pb.DeleteResourceVariantResponse{Deleted: ids}, nil
+}
+
+// ArchiveResourceVariant hides a variant from listings and from GetEquivalent
+// without deleting it, so it's still available for lineage. Setting Unarchive
+// restores the variant.
+func (serv *MetadataServer) ArchiveResourceVariant(ctx context.Context, request *pb.ArchiveResourceVariantRequest) (*pb.ArchiveResourceVariantResponse, error) {
+	_, ctx, logger := serv.Logger.InitializeRequestID(ctx)
+	logger.Infow("Archiving resource variant", "resource_id", request.ResourceId, "unarchive", request.Unarchive)
+
+	resId := ResourceID{Name: request.ResourceId.Resource.Name, Variant: request.ResourceId.Resource.Variant, Type: ResourceType(request.ResourceId.ResourceType)}
+	if _, archivable := parentMapping[resId.Type]; !archivable {
+		logger.Errorw("Resource type cannot be archived", "type", resId.Type)
+		return nil, fferr.NewInvalidResourceTypeError(resId.Name, resId.Variant, fferr.ResourceType(resId.Type.String()), fmt.Errorf("only feature, label, source, and training set variants can be archived"))
+	}
+	resource, err := serv.lookup.Lookup(ctx, resId)
+	if err != nil {
+		logger.Errorw("Could not find resource to archive", "error", err.Error())
+		return nil, err
+	}
+	if err := serv.authorize(ctx, "archive", resId, resource); err != nil {
+		logger.Errorw("Caller is not allowed to archive resource", "error", err)
+		return nil, err
+	}
+	variant, ok := resource.(ResourceVariant)
+	if !ok {
+		logger.DPanic("lookup returned wrong type")
+		return nil, fferr.NewInternalErrorf("lookup should have returned a resource variant, but returned %T", resource)
+	}
+	variant.SetArchived(!request.Unarchive)
+	if err := serv.lookup.Set(ctx, resId, resource); err != nil {
+		logger.Errorw("Could not save archived resource", "error", err.Error())
+		return nil, err
+	}
+	if err := serv.setArchivedIndex(resId, !request.Unarchive); err != nil {
+		logger.Errorw("Could not update archived variant index", "error", err)
+		return nil, err
+	}
+
+	logger.Info("Successfully archived resource variant")
+	return &pb.ArchiveResourceVariantResponse{}, nil
+}
+
+// ListArchived returns the archived variants of a resource type. Either the
+// variant type or its parent type can be requested.
+func (serv *MetadataServer) ListArchived(ctx context.Context, request *pb.ListArchivedRequest) (*pb.ListArchivedResponse, error) {
+	ctx = logging.AttachRequestID(logging.RequestID(request.RequestId), ctx, serv.Logger)
+	logger := logging.GetLoggerFromContext(ctx)
+	logger.Infow("Listing archived resource variants", "type", request.ResourceType)
+
+	t := ResourceType(request.ResourceType)
+	if variantType, has := variantTypeOf(t); has {
+		t = variantType
+	}
+	if _, archivable := parentMapping[t]; !archivable {
+		logger.Errorw("Resource type cannot be archived", "type", t)
+		return nil, fferr.NewInvalidArgumentErrorf("resource type %s cannot be archived", t)
+	}
+	archived, err := serv.archivedVariants(ctx, t, "")
+	if err != nil {
+		logger.Errorw("Unable to list archived variants", "error", err)
+		return nil, err
+	}
+	ids := make([]*pb.ResourceID, len(archived))
+	for i, id := range archived {
+		ids[i] = id.Proto()
+	}
+	return &pb.ListArchivedResponse{ResourceIds: ids}, nil
+}
+
+// SetSourceProfile replaces the column profile saved with a source variant.
+func (serv *MetadataServer) SetSourceProfile(ctx context.Context, request *pb.SetSourceProfileRequest) (*pb.Empty, error) {
+	ctx = logging.AttachRequestID(logging.RequestID(request.RequestId), ctx, serv.Logger)
+	logger := logging.GetLoggerFromContext(ctx).WithResource(logging.SourceVariant, request.Source.GetName(), request.Source.GetVariant())
+	logger.Info("Setting source profile")
+
+	resId := ResourceID{Name: request.Source.GetName(), Variant: request.Source.GetVariant(), Type: SOURCE_VARIANT}
+	resource, err := serv.lookup.Lookup(ctx, resId)
+	if err != nil {
+		logger.Errorw("Could not find source variant to profile", "error", err.Error())
+		return nil, err
+	}
+	if err := serv.authorize(ctx, "profile", resId, resource); err != nil {
+		logger.Errorw("Caller is not allowed to profile source", "error", err)
+		return nil, err
+	}
+	source, ok := resource.(*sourceVariantResource)
+	if !ok {
+		logger.DPanic("lookup returned wrong type")
+		return nil, fferr.NewInternalErrorf("lookup should have returned a source variant, but returned %T", resource)
+	}
+	source.serialized.Profile = request.Profile
+	if err := serv.lookup.Set(ctx, resId, source); err != nil {
+		logger.Errorw("Could not save source profile", "error", err.Error())
+		return nil, err
+	}
+	return &pb.Empty{}, nil
+}
+
+// ProfileSource starts a task that profiles a source variant. Profiling can run
+// a job in the source's provider, so it's left to the coordinator.
+func (serv *MetadataServer) ProfileSource(ctx context.Context, request *pb.ProfileSourceRequest) (*pb.ProfileSourceResponse, error) {
+	ctx = logging.AttachRequestID(logging.RequestID(request.RequestId), ctx, serv.Logger)
+	logger := logging.GetLoggerFromContext(ctx).WithResource(logging.SourceVariant, request.Source.GetName(), request.Source.GetVariant())
+	logger.Infow("Starting source profile task", "sample_size", request.SampleSize)
+
+	resId := ResourceID{Name: request.Source.GetName(), Variant: request.Source.GetVariant(), Type: SOURCE_VARIANT}
+	if _, err := serv.lookup.Lookup(ctx, resId); err != nil {
+		logger.Errorw("Could not find source variant to profile", "error", err.Error())
+		return nil, err
+	}
+	taskTarget := scheduling.SourceProfile{
+		Name:       resId.Name,
+		Variant:    resId.Variant,
+		SampleSize: request.SampleSize,
+	}
+	task, err := serv.taskManager.CreateTask(ctx, "profile-task", scheduling.Monitoring, taskTarget)
+	if err != nil {
+		logger.Errorw("unable to create source profile task", "error", err)
+		return nil, err
+	}
+	taskName := fmt.Sprintf("Profiling Resource %s", resId.String())
+	trigger := scheduling.OnApplyTrigger{TriggerName: "Apply"}
+	taskRun, err := serv.taskManager.CreateTaskRun(ctx, taskName, task.ID, trigger)
+	if err != nil {
+		logger.Errorw("unable to create task run",
+			"task_name", taskName,
+			"task_id", task.ID,
+			"trigger", trigger.TriggerName,
+			"error", err)
+		return nil, err
+	}
+	logger.Infow("Successfully created source profile task", "task_id", taskRun.TaskId, "taskrun_id", taskRun.ID)
+	return &pb.ProfileSourceResponse{TaskId: taskRun.TaskId.String(), RunId: taskRun.ID.String()}, nil
+}
+
+// variantTypeOf returns the variant type of a parent resource type.
+func variantTypeOf(t ResourceType) (ResourceType, bool) {
+	for variantType, parentType := range parentMapping {
+		if parentType == t {
+			return variantType, true
+		}
+	}
+	return 0, false
+}
+
+// withoutArchivedVariants returns a copy of a parent resource's proto with its
+// archived variants removed. If the default variant is archived, the most
+// recent remaining variant becomes the default. It returns false if every
+// variant is archived.
+func withoutArchivedVariants(msg proto.Message, archived mapset.Set[ResourceID], variantType ResourceType) (proto.Message, bool) {
+	clone := proto.Clone(msg)
+	var name string
+	var variants *[]string
+	var defaultVariant *string
+	switch parent := clone.(type) {
+	case *pb.Feature:
+		name, variants, defaultVariant = parent.Name, &parent.Variants, &parent.DefaultVariant
+	case *pb.Label:
+		name, variants, defaultVariant = parent.Name, &parent.Variants, &parent.DefaultVariant
+	case *pb.Source:
+		name, variants, defaultVariant = parent.Name, &parent.Variants, &parent.DefaultVariant
+	case *pb.TrainingSet:
+		name, variants, defaultVariant = parent.Name, &parent.Variants, &parent.DefaultVariant
+	default:
+		return msg, true
+	}
+	isArchived := func(variant string) bool {
+		return archived.Contains(ResourceID{Name: name, Variant: variant, Type: variantType})
+	}
+	visible := make([]string, 0, len(*variants))
+	for _, variant := range *variants {
+		if !isArchived(variant) {
+			visible = append(visible, variant)
+		}
+	}
+	if len(visible) == 0 {
+		return nil, false
+	}
+	*variants = visible
+	if isArchived(*defaultVariant) {
+		*defaultVariant = visible[len(visible)-1]
+	}
+	return clone, true
+}
+
+// ensures dependent feature variants of a resource have updated fields
+// (offlineStoreProvider and offlineStoreLocations) before deletion. This allows
+// feature variants to be deleted independently of their sources, which may have
+// already been removed.
+func (serv *MetadataServer) ensureDependentFeatureBackwardsCompatability(ctx context.Context, resId common.ResourceID, logger logging.Logger) error {
+	deps, err := serv.resourcesRepository.GetDependencies(ctx, resId)
+	if err != nil {
+		logger.Errorw("failed to get dependencies", "error", err)
+		return err
 	}
-	return nil
-}
+
+	for _, dep := range deps {
+		if dep.Type == common.FEATURE_VARIANT {
+			if err := serv.updateFeatureVariant(ctx, dep, logger); err != nil {
+				return fferr.NewInternalErrorf("failed to update feature variant %s: %v", dep.Name, err)
+			}
+		}
+	}
+	return nil
+}
 
 func (serv *MetadataServer) updateFeatureVariant(ctx context.Context, dep common.ResourceID, logger logging.Logger) error {
 	fv, err := serv.lookup.Lookup(ctx, ResourceID{
@@ -2724,10 +3421,182 @@ func (serv *MetadataServer) GetFeatureVariants(stream pb.Metadata_GetFeatureVari
 	})
 }
 
+// BatchGetFeatureVariants looks up all of the requested feature variants in one
+// call. A variant that can't be found is returned as an error for that item
+// rather than failing the whole batch.
+func (serv *MetadataServer) BatchGetFeatureVariants(ctx context.Context, req *pb.BatchGetFeatureVariantsRequest) (*pb.BatchGetFeatureVariantsResponse, error) {
+	ctx = logging.AttachRequestID(logging.RequestID(req.RequestId), ctx, serv.Logger)
+	logger := logging.GetLoggerFromContext(ctx)
+	logger.Infow("Batch getting feature variants", "count", len(req.NameVariants))
+	ids := make([]ResourceID, len(req.NameVariants))
+	for i, nv := range req.NameVariants {
+		ids[i] = ResourceID{Name: nv.GetName(), Variant: nv.GetVariant(), Type: FEATURE_VARIANT}
+	}
+	// Submap fails if any variant is missing, in which case we fall back to
+	// looking each one up so the rest of the batch is still returned.
+	lookup, err := serv.lookup.Submap(ctx, ids)
+	if err != nil {
+		logger.Debugw("Batch lookup failed, looking up variants individually", "error", err)
+		lookup = serv.lookup
+	}
+	resp := &pb.BatchGetFeatureVariantsResponse{
+		FeatureVariants: make([]*pb.FeatureVariant, 0, len(ids)),
+		Errors:          make([]*pb.BatchGetError, 0),
+	}
+	for _, id := range ids {
+		fv, err := serv.batchGetFeatureVariant(ctx, lookup, id)
+		if err != nil {
+			logger.Errorw("Unable to get feature variant", "resource_id", id.String(), "error", err)
+			resp.Errors = append(resp.Errors, &pb.BatchGetError{
+				NameVariant: id.NameVariantProto(),
+				Error:       errorStatusProto(err),
+			})
+			continue
+		}
+		resp.FeatureVariants = append(resp.FeatureVariants, fv)
+	}
+	return resp, nil
+}
+
+// GetDefaultVariant returns the default variant stored on a resource's parent,
+// which is set to the latest variant each time one is created.
+func (serv *MetadataServer) GetDefaultVariant(ctx context.Context, req *pb.GetDefaultVariantRequest) (*pb.NameVariant, error) {
+	ctx = logging.AttachRequestID(logging.RequestID(req.RequestId), ctx, serv.Logger)
+	logger := logging.GetLoggerFromContext(ctx)
+	logger.Infow("Getting default variant", "name", req.Name, "resource_type", req.ResourceType)
+	variant, err := serv.defaultVariant(ctx, req.Name, ResourceType(req.ResourceType))
+	if err != nil {
+		logger.Errorw("Unable to get default variant", "error", err)
+		return nil, err
+	}
+	return &pb.NameVariant{Name: req.Name, Variant: variant}, nil
+}
+
+// defaultVariant looks up the default variant of name. t can be either a
+// parent type, like FEATURE, or its variant type, like FEATURE_VARIANT.
+func (serv *MetadataServer) defaultVariant(ctx context.Context, name string, t ResourceType) (string, error) {
+	id := ResourceID{Name: name, Type: t}
+	if parentId, hasParent := id.Parent(); hasParent {
+		id = parentId
+	}
+	parent, err := serv.lookup.Lookup(ctx, id)
+	if err != nil {
+		return "", err
+	}
+	visible, err := serv.visibleParent(ctx, id, parent.Proto())
+	if err != nil {
+		return "", err
+	}
+	withDefault, hasDefault := visible.(interface{ GetDefaultVariant() string })
+	if !hasDefault {
+		return "", fferr.NewInvalidArgumentErrorf("%s resources don't have variants", t)
+	}
+	return withDefault.GetDefaultVariant(), nil
+}
+
+// ListVariants returns every variant of a resource with its status and created
+// time, newest first, so callers don't have to get each variant on its own.
+// Archived variants are left out unless IncludeArchived is set.
+func (serv *MetadataServer) ListVariants(ctx context.Context, req *pb.ListVariantsRequest) (*pb.ListVariantsResponse, error) {
+	ctx = logging.AttachRequestID(logging.RequestID(req.RequestId), ctx, serv.Logger)
+	logger := logging.GetLoggerFromContext(ctx)
+	logger.Infow("Listing variants", "name", req.Name, "resource_type", req.ResourceType)
+	variants, err := serv.variantSummaries(ctx, req.Name, ResourceType(req.ResourceType), req.IncludeArchived)
+	if err != nil {
+		logger.Errorw("Unable to list variants", "error", err)
+		return nil, err
+	}
+	return &pb.ListVariantsResponse{Name: req.Name, Variants: variants}, nil
+}
+
+// variantSummaries looks up the variants listed on name's parent resource. t
+// can be either a parent type, like FEATURE, or its variant type.
+func (serv *MetadataServer) variantSummaries(ctx context.Context, name string, t ResourceType, includeArchived bool) ([]*pb.VariantSummary, error) {
+	parentId := ResourceID{Name: name, Type: t}
+	if id, hasParent := parentId.Parent(); hasParent {
+		parentId = id
+	}
+	variantType, hasVariants := variantTypeOf(parentId.Type)
+	if !hasVariants {
+		return nil, fferr.NewInvalidArgumentErrorf("%s resources don't have variants", t)
+	}
+	parent, err := serv.lookup.Lookup(ctx, parentId)
+	if err != nil {
+		return nil, err
+	}
+	listed := parent.Proto()
+	if !includeArchived {
+		if listed, err = serv.visibleParent(ctx, parentId, listed); err != nil {
+			return nil, err
+		}
+	}
+	withVariants, ok := listed.(interface{ GetVariants() []string })
+	if !ok {
+		return nil, fferr.NewInternalErrorf("expected %s to list its variants, got %T", parentId, listed)
+	}
+	ids := make([]ResourceID, len(withVariants.GetVariants()))
+	for i, variant := range withVariants.GetVariants() {
+		ids[i] = ResourceID{Name: name, Variant: variant, Type: variantType}
+	}
+	lookup, err := serv.lookup.Submap(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	summaries := make([]*pb.VariantSummary, 0, len(ids))
+	for _, id := range ids {
+		res, err := lookup.Lookup(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		if serv.needsJob(res) {
+			if _, err := serv.getStatusFromTasks(ctx, res); err != nil {
+				return nil, err
+			}
+		}
+		summary := &pb.VariantSummary{Variant: id.Variant, Status: res.GetStatus()}
+		if created, hasCreated := res.Proto().(interface{ GetCreated() *tspb.Timestamp }); hasCreated {
+			summary.Created = created.GetCreated()
+		}
+		if variant, isVariant := res.(ResourceVariant); isVariant {
+			summary.Archived = variant.IsArchived()
+		}
+		summaries = append(summaries, summary)
+	}
+	sort.SliceStable(summaries, func(i, j int) bool {
+		return summaries[i].GetCreated().AsTime().After(summaries[j].GetCreated().AsTime())
+	})
+	return summaries, nil
+}
+
+func (serv *MetadataServer) batchGetFeatureVariant(ctx context.Context, lookup ResourceLookup, id ResourceID) (*pb.FeatureVariant, error) {
+	resource, err := lookup.Lookup(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if serv.needsJob(resource) {
+		if _, err := serv.getStatusFromTasks(ctx, resource); err != nil {
+			return nil, err
+		}
+	}
+	fv, isFeatureVariant := resource.Proto().(*pb.FeatureVariant)
+	if !isFeatureVariant {
+		return nil, fferr.NewInternalErrorf("expected feature variant, got %T", resource.Proto())
+	}
+	if err := serv.featureVariantBackwardsCompatibility(ctx, fv, true); err != nil {
+		return nil, err
+	}
+	return fv, nil
+}
+
+func errorStatusProto(err error) *pb.ErrorStatus {
+	status := grpcstatus.Convert(err).Proto()
+	return &pb.ErrorStatus{Code: status.Code, Message: status.Message, Details: status.Details}
+}
+
 func (serv *MetadataServer) ListLabels(request *pb.ListRequest, stream pb.Metadata_ListLabelsServer) error {
 	ctx := logging.AttachRequestID(logging.RequestID(request.RequestId), stream.Context(), serv.Logger)
 	logging.GetLoggerFromContext(ctx).Info("Opened List Labels stream")
-	return serv.genericList(ctx, LABEL, func(msg proto.Message) error {
+	return serv.genericList(ctx, LABEL, request, stream, func(msg proto.Message) error {
 		return stream.Send(msg.(*pb.Label))
 	})
 }
@@ -2738,6 +3607,13 @@ func (serv *MetadataServer) CreateLabelVariant(ctx context.Context, variantReque
 	logger.Info("Creating Label Variant")
 
 	variant := variantRequest.LabelVariant
+	id := ResourceID{Name: variant.Name, Variant: variant.Variant, Type: LABEL_VARIANT}
+	return serv.createIdempotent(ctx, variantRequest.IdempotencyKey, id, func() (*pb.Empty, error) {
+		return serv.createLabelVariant(ctx, variant, logger)
+	})
+}
+
+func (serv *MetadataServer) createLabelVariant(ctx context.Context, variant *pb.LabelVariant, logger logging.Logger) (*pb.Empty, error) {
 	variant.Created = tspb.New(time.Now())
 	taskTarget := scheduling.NameVariant{Name: variant.Name, Variant: variant.Variant, ResourceType: LABEL_VARIANT.String()}
 	task, err := serv.taskManager.CreateTask(ctx, "mytask", scheduling.ResourceCreation, taskTarget)
@@ -2746,7 +3622,7 @@ func (serv *MetadataServer) CreateLabelVariant(ctx context.Context, variantReque
 		return nil, err
 	}
 	variant.TaskIdList = []string{task.ID.String()}
-	return serv.genericCreate(ctx, &labelVariantResource{variant}, func(name, variant string) Resource {
+	resp, err := serv.genericCreate(ctx, &labelVariantResource{variant}, func(name, variant string) Resource {
 		return &labelResource{
 			&pb.Label{
 				Name:           name,
@@ -2756,6 +3632,10 @@ func (serv *MetadataServer) CreateLabelVariant(ctx context.Context, variantReque
 			},
 		}
 	})
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
 }
 
 func (serv *MetadataServer) GetLabels(stream pb.Metadata_GetLabelsServer) error {
@@ -2777,7 +3657,7 @@ func (serv *MetadataServer) GetLabelVariants(stream pb.Metadata_GetLabelVariants
 func (serv *MetadataServer) ListTrainingSets(request *pb.ListRequest, stream pb.Metadata_ListTrainingSetsServer) error {
 	ctx := logging.AttachRequestID(logging.RequestID(request.RequestId), stream.Context(), serv.Logger)
 	logging.GetLoggerFromContext(ctx).Info("Opened List Training Sets stream")
-	return serv.genericList(ctx, TRAINING_SET, func(msg proto.Message) error {
+	return serv.genericList(ctx, TRAINING_SET, request, stream, func(msg proto.Message) error {
 		return stream.Send(msg.(*pb.TrainingSet))
 	})
 }
@@ -2788,6 +3668,13 @@ func (serv *MetadataServer) CreateTrainingSetVariant(ctx context.Context, varian
 	logger.Info("Creating TrainingSet Variant")
 
 	variant := variantRequest.TrainingSetVariant
+	id := ResourceID{Name: variant.Name, Variant: variant.Variant, Type: TRAINING_SET_VARIANT}
+	return serv.createIdempotent(ctx, variantRequest.IdempotencyKey, id, func() (*pb.Empty, error) {
+		return serv.createTrainingSetVariant(ctx, variant, logger)
+	})
+}
+
+func (serv *MetadataServer) createTrainingSetVariant(ctx context.Context, variant *pb.TrainingSetVariant, logger logging.Logger) (*pb.Empty, error) {
 	tsRes := &trainingSetVariantResource{variant}
 	if err := tsRes.Validate(ctx, serv.lookup); err != nil {
 		return nil, err
@@ -2804,7 +3691,7 @@ func (serv *MetadataServer) CreateTrainingSetVariant(ctx context.Context, varian
 		return nil, err
 	}
 
-	return serv.genericCreate(ctx, tsRes, func(name, variant string) Resource {
+	resp, err := serv.genericCreate(ctx, tsRes, func(name, variant string) Resource {
 		return &trainingSetResource{
 			&pb.TrainingSet{
 				Name:           name,
@@ -2814,6 +3701,10 @@ func (serv *MetadataServer) CreateTrainingSetVariant(ctx context.Context, varian
 			},
 		}
 	})
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
 }
 
 func (serv *MetadataServer) GetTrainingSets(stream pb.Metadata_GetTrainingSetsServer) error {
@@ -2835,7 +3726,7 @@ func (serv *MetadataServer) GetTrainingSetVariants(stream pb.Metadata_GetTrainin
 func (serv *MetadataServer) ListSources(request *pb.ListRequest, stream pb.Metadata_ListSourcesServer) error {
 	ctx := logging.AttachRequestID(logging.RequestID(request.RequestId), stream.Context(), serv.Logger)
 	logging.GetLoggerFromContext(ctx).Info("Opened List Sources stream")
-	return serv.genericList(ctx, SOURCE, func(msg proto.Message) error {
+	return serv.genericList(ctx, SOURCE, request, stream, func(msg proto.Message) error {
 		return stream.Send(msg.(*pb.Source))
 	})
 }
@@ -2846,7 +3737,24 @@ func (serv *MetadataServer) CreateSourceVariant(ctx context.Context, variantRequ
 	logger.Info("Creating Source Variant")
 
 	variant := variantRequest.SourceVariant
+	id := ResourceID{Name: variant.Name, Variant: variant.Variant, Type: SOURCE_VARIANT}
+	return serv.createIdempotent(ctx, variantRequest.IdempotencyKey, id, func() (*pb.Empty, error) {
+		return serv.createSourceVariant(ctx, id, variant, logger)
+	})
+}
+
+func (serv *MetadataServer) createSourceVariant(ctx context.Context, id ResourceID, variant *pb.SourceVariant, logger logging.Logger) (*pb.Empty, error) {
 	variant.Created = tspb.New(time.Now())
+	if variant.Schedule != "" {
+		if _, err := scheduling.ParseSchedule(variant.Schedule); err != nil {
+			logger.Errorw("Invalid schedule", "error", err)
+			return nil, err
+		}
+	}
+	if err := serv.validateSQLTransformation(ctx, variant); err != nil {
+		logger.Errorw("Invalid SQL transformation", "error", err)
+		return nil, err
+	}
 	taskTarget := scheduling.NameVariant{Name: variant.Name, Variant: variant.Variant, ResourceType: SOURCE_VARIANT.String()}
 	logger.Debug("Creating task for source variant")
 	task, err := serv.taskManager.CreateTask(ctx, "mytask", scheduling.ResourceCreation, taskTarget)
@@ -2862,7 +3770,7 @@ func (serv *MetadataServer) CreateSourceVariant(ctx context.Context, variantRequ
 			return nil, err
 		}
 	}
-	return serv.genericCreate(ctx, &sourceVariantResource{variant}, func(name, variant string) Resource {
+	resp, err := serv.genericCreate(ctx, &sourceVariantResource{variant}, func(name, variant string) Resource {
 		return &sourceResource{
 			&pb.Source{
 				Name:           name,
@@ -2872,6 +3780,57 @@ func (serv *MetadataServer) CreateSourceVariant(ctx context.Context, variantRequ
 			},
 		}
 	})
+	if err != nil {
+		return nil, err
+	}
+	if variant.Schedule != "" {
+		if err := serv.scheduleResourceTasks(ctx, id, variant.Schedule); err != nil {
+			logger.Errorw("Failed to schedule source variant tasks", "error", err)
+			return nil, err
+		}
+	}
+	return resp, nil
+}
+
+// validateSQLTransformation checks a SQL transformation's query as written,
+// before its templates are replaced, so mistakes are caught when it's
+// registered rather than once its job is running.
+func (serv *MetadataServer) validateSQLTransformation(ctx context.Context, variant *pb.SourceVariant) error {
+	transformation := variant.GetTransformation().GetSQLTransformation()
+	if transformation == nil {
+		return nil
+	}
+	invalid := func(format string, a ...any) error {
+		err := fferr.NewInvalidArgumentErrorf(format, a...)
+		err.AddDetail("transformation", variant.Name)
+		err.AddDetail("variant", variant.Variant)
+		return err
+	}
+	if strings.TrimSpace(transformation.Query) == "" {
+		return invalid("transformation query is empty")
+	}
+	sources := make(map[string]bool, len(transformation.Source))
+	for _, source := range transformation.Source {
+		sources[NameVariant{Name: source.Name, Variant: source.Variant}.ClientString()] = true
+	}
+	unresolved := make([]string, 0)
+	for _, template := range sqlcheck.Templates(transformation.Query) {
+		if !sources[template] {
+			unresolved = append(unresolved, template)
+		}
+	}
+	if len(unresolved) > 0 {
+		return invalid("transformation query references unresolved sources: %s", strings.Join(unresolved, ", "))
+	}
+	providerResource, err := serv.lookup.Lookup(ctx, ResourceID{Name: variant.Provider, Type: PROVIDER})
+	if err != nil {
+		return err
+	}
+	providerType := pt.Type(providerResource.Proto().(*pb.Provider).GetType())
+	if err := sqlcheck.CheckQuery(transformation.Query, sqlcheck.BackslashEscapes(providerType)); err != nil {
+		return invalid("transformation query is not valid SQL: %v", err)
+	}
+	return nil
 }
 
 func (serv *MetadataServer) addFeatureProviderAndLocation(ctx context.Context, fv *pb.FeatureVariant, logger logging.Logger) error {
@@ -3192,7 +4151,7 @@ func (serv *MetadataServer) sourceVariantBackwardsCompatibility(ctx context.Cont
 func (serv *MetadataServer) ListUsers(request *pb.ListRequest, stream pb.Metadata_ListUsersServer) error {
 	ctx := logging.AttachRequestID(logging.RequestID(request.RequestId), stream.Context(), serv.Logger)
 	logging.GetLoggerFromContext(ctx).Info("Opened List Users stream")
-	return serv.genericList(ctx, USER, func(msg proto.Message) error {
+	return serv.genericList(ctx, USER, request, stream, func(msg proto.Message) error {
 		return stream.Send(msg.(*pb.User))
 	})
 }
@@ -3201,7 +4160,10 @@ func (serv *MetadataServer) CreateUser(ctx context.Context, userRequest *pb.User
 	ctx = logging.AttachRequestID(logging.RequestID(userRequest.RequestId), ctx, serv.Logger)
 	logger := logging.GetLoggerFromContext(ctx).WithResource(logging.User, userRequest.User.Name, logging.NoVariant)
 	logger.Info("Creating User")
-	return serv.genericCreate(ctx, &userResource{userRequest.User}, nil)
+	id := ResourceID{Name: userRequest.User.Name, Type: USER}
+	return serv.createIdempotent(ctx, userRequest.IdempotencyKey, id, func() (*pb.Empty, error) {
+		return serv.genericCreate(ctx, &userResource{userRequest.User}, nil)
+	})
 }
 
 func (serv *MetadataServer) GetUsers(stream pb.Metadata_GetUsersServer) error {
@@ -3215,7 +4177,7 @@ func (serv *MetadataServer) GetUsers(stream pb.Metadata_GetUsersServer) error {
 func (serv *MetadataServer) ListProviders(request *pb.ListRequest, stream pb.Metadata_ListProvidersServer) error {
 	ctx := logging.AttachRequestID(logging.RequestID(request.RequestId), stream.Context(), serv.Logger)
 	logging.GetLoggerFromContext(ctx).Info("Opened List Providers stream")
-	return serv.genericList(ctx, PROVIDER, func(msg proto.Message) error {
+	return serv.genericList(ctx, PROVIDER, request, stream, func(msg proto.Message) error {
 		return stream.Send(msg.(*pb.Provider))
 	})
 }
@@ -3226,7 +4188,10 @@ func (serv *MetadataServer) CreateProvider(ctx context.Context, providerRequest
 		WithResource("provider", providerRequest.Provider.Name, "").
 		WithProvider(providerRequest.Provider.Type, providerRequest.Provider.Name)
 	logger.Info("Creating Provider")
-	return serv.genericCreate(ctx, &providerResource{providerRequest.Provider}, nil)
+	id := ResourceID{Name: providerRequest.Provider.Name, Type: PROVIDER}
+	return serv.createIdempotent(ctx, providerRequest.IdempotencyKey, id, func() (*pb.Empty, error) {
+		return serv.genericCreate(ctx, &providerResource{providerRequest.Provider}, nil)
+	})
 }
 
 func (serv *MetadataServer) GetProviders(stream pb.Metadata_GetProvidersServer) error {
@@ -3240,7 +4205,7 @@ func (serv *MetadataServer) GetProviders(stream pb.Metadata_GetProvidersServer)
 func (serv *MetadataServer) ListEntities(request *pb.ListRequest, stream pb.Metadata_ListEntitiesServer) error {
 	ctx := logging.AttachRequestID(logging.RequestID(request.RequestId), stream.Context(), serv.Logger)
 	logging.GetLoggerFromContext(ctx).Info("Opened List Entities stream")
-	return serv.genericList(ctx, ENTITY, func(msg proto.Message) error {
+	return serv.genericList(ctx, ENTITY, request, stream, func(msg proto.Message) error {
 		return stream.Send(msg.(*pb.Entity))
 	})
 }
@@ -3249,7 +4214,10 @@ func (serv *MetadataServer) CreateEntity(ctx context.Context, entityRequest *pb.
 	ctx = logging.AttachRequestID(logging.RequestID(entityRequest.RequestId), ctx, serv.Logger)
 	logger := logging.GetLoggerFromContext(ctx).WithResource(logging.Entity, entityRequest.Entity.Name, logging.NoVariant)
 	logger.Info("Creating Entity")
-	return serv.genericCreate(ctx, &entityResource{entityRequest.Entity}, nil)
+	id := ResourceID{Name: entityRequest.Entity.Name, Type: ENTITY}
+	return serv.createIdempotent(ctx, entityRequest.IdempotencyKey, id, func() (*pb.Empty, error) {
+		return serv.genericCreate(ctx, &entityResource{entityRequest.Entity}, nil)
+	})
 }
 
 func (serv *MetadataServer) GetEntities(stream pb.Metadata_GetEntitiesServer) error {
@@ -3263,7 +4231,7 @@ func (serv *MetadataServer) GetEntities(stream pb.Metadata_GetEntitiesServer) er
 func (serv *MetadataServer) ListModels(request *pb.ListRequest, stream pb.Metadata_ListModelsServer) error {
 	ctx := logging.AttachRequestID(logging.RequestID(request.RequestId), stream.Context(), serv.Logger)
 	logging.GetLoggerFromContext(ctx).Info("Opened List Models stream")
-	return serv.genericList(ctx, MODEL, func(msg proto.Message) error {
+	return serv.genericList(ctx, MODEL, request, stream, func(msg proto.Message) error {
 		return stream.Send(msg.(*pb.Model))
 	})
 }
@@ -3272,7 +4240,10 @@ func (serv *MetadataServer) CreateModel(ctx context.Context, modelRequest *pb.Mo
 	ctx = logging.AttachRequestID(logging.RequestID(modelRequest.RequestId), ctx, serv.Logger)
 	logger := logging.GetLoggerFromContext(ctx).WithResource(logging.Model, modelRequest.Model.Name, logging.NoVariant)
 	logger.Info("Creating Model")
-	return serv.genericCreate(ctx, &modelResource{modelRequest.Model}, nil)
+	id := ResourceID{Name: modelRequest.Model.Name, Type: MODEL}
+	return serv.createIdempotent(ctx, modelRequest.IdempotencyKey, id, func() (*pb.Empty, error) {
+		return serv.genericCreate(ctx, &modelResource{modelRequest.Model}, nil)
+	})
 }
 
 func (serv *MetadataServer) GetModels(stream pb.Metadata_GetModelsServer) error {
@@ -3432,6 +4403,10 @@ func (serv *MetadataServer) filterResources(ctx context.Context, resources []Res
 func (serv *MetadataServer) findEquivalent(ctx context.Context, resources []ResourceVariant, resource ResourceVariant) (ResourceVariant, error) {
 	logger := logging.GetLoggerFromContext(ctx)
 	for _, other := range resources {
+		// Archived variants are kept for lineage but should never be reused.
+		if other.IsArchived() {
+			continue
+		}
 		logger.Infow("finding equivalent", "this", resource.ID().String(), "other", other.ID().String())
 		equivalent, err := resource.IsEquivalent(other)
 		if err != nil {
@@ -3492,7 +4467,7 @@ func (serv *MetadataServer) genericCreate(ctx context.Context, res Resource, ini
 
 	id := res.ID()
 	logger.Debug("Checking if resource is named safely")
-	if err := resourceNamedSafely(id); err != nil {
+	if err := serv.namingRules.Validate(id); err != nil {
 		logger.Errorw("Resource name is not valid", "error", err)
 		return nil, err
 	}
@@ -3502,6 +4477,11 @@ func (serv *MetadataServer) genericCreate(ctx context.Context, res Resource, ini
 		logger.Errorw("Error looking up resource", "resource ID", id, "error", err)
 		return nil, fferr.NewInternalError(err)
 	}
+	if err := serv.authorizeCreate(ctx, res, existing); err != nil {
+		logger.Errorw("Caller is not allowed to create resource", "error", err)
+		return nil, err
+	}
+	updated := false
 	if existing != nil {
 		logger.Debug("Resource exists, validating...")
 		if err := serv.validateExisting(logger.AttachToContext(ctx), res, existing); err != nil {
@@ -3509,15 +4489,28 @@ func (serv *MetadataServer) genericCreate(ctx context.Context, res Resource, ini
 			return nil, err
 		}
 		logger.Debug("Updating existing resource")
+		before := proto.Clone(existing.Proto())
 		if err := existing.Update(serv.lookup, res); err != nil {
 			logger.Errorw("Error updating existing resource", "error", err)
 			return nil, err
 		}
+		// Re-applying an unchanged definition is a no-op, so it isn't reported
+		// as an update.
+		updated = !proto.Equal(before, existing.Proto())
 		res = existing
 	} else {
 		logger.Info("Resource does not exist, creating now")
 	}
 
+	var typeChange *pb.FeatureTypeChange
+	if existing == nil && res.ID().Type == FEATURE_VARIANT {
+		typeChange, err = serv.checkFeatureTypeChange(logger.AttachToContext(ctx), res)
+		if err != nil {
+			logger.Errorw("Feature variant's type isn't compatible with the default variant", "error", err)
+			return nil, err
+		}
+	}
+
 	// Create the parent first. Better to have a hanging parent than a hanging dependency.
 	logger.Debug("Checking if ID has parent")
 	parentId, hasParent := id.Parent()
@@ -3541,6 +4534,9 @@ func (serv *MetadataServer) genericCreate(ctx context.Context, res Resource, ini
 			}
 		} else {
 			logger.Debug("Parent exists, setting default variant")
+			if feature, ok := parent.(*featureResource); ok && typeChange != nil {
+				feature.serialized.TypeChanges = append(feature.serialized.TypeChanges, typeChange)
+			}
 			if err := serv.setDefaultVariant(logger.AttachToContext(ctx), parent, res.ID().Variant); err != nil {
 				logger.Errorw("Error setting default variant", "error", err)
 				return nil, err
@@ -3586,10 +4582,92 @@ func (serv *MetadataServer) genericCreate(ctx context.Context, res Resource, ini
 			logger.Errorw("Failed to propogate change", "error", err)
 			return nil, err
 		}
+		serv.emitEvent(id, events.CreateOperation, "")
+	} else if updated {
+		serv.emitEvent(id, events.UpdateOperation, "")
 	}
 	return &pb.Empty{}, nil
 }
 
+// emitEvent publishes a resource change in the background. It never blocks
+// or fails the request that made the change.
+func (serv *MetadataServer) emitEvent(id ResourceID, op events.Operation, status string) {
+	serv.events.Emit(events.Event{
+		ResourceType: id.Type.String(),
+		Name:         id.Name,
+		Variant:      id.Variant,
+		Operation:    op,
+		Status:       status,
+	})
+}
+
+// checkFeatureTypeChange compares a new feature variant's value type with its
+// feature's current default variant. Breaking changes, like int to string, are
+// rejected unless the variant sets AllowBreaking. It returns the change to record
+// in the feature's history, or nil if the type didn't change.
+func (serv *MetadataServer) checkFeatureTypeChange(ctx context.Context, res Resource) (*pb.FeatureTypeChange, error) {
+	logger := logging.GetLoggerFromContext(ctx)
+	variant, ok := res.Proto().(*pb.FeatureVariant)
+	if !ok || variant.Type == nil {
+		return nil, nil
+	}
+	parentId, _ := res.ID().Parent()
+	parent, err := serv.lookup.Lookup(ctx, parentId)
+	if _, isNotFound := err.(*fferr.KeyNotFoundError); isNotFound {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	feature, ok := parent.Proto().(*pb.Feature)
+	if !ok || feature.DefaultVariant == "" || feature.DefaultVariant == variant.Variant {
+		return nil, nil
+	}
+	defaultId := ResourceID{Name: variant.Name, Variant: feature.DefaultVariant, Type: FEATURE_VARIANT}
+	defaultRes, err := serv.lookup.Lookup(ctx, defaultId)
+	if _, isNotFound := err.(*fferr.KeyNotFoundError); isNotFound {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	defaultVariant, ok := defaultRes.Proto().(*pb.FeatureVariant)
+	if !ok || defaultVariant.Type == nil {
+		return nil, nil
+	}
+	fromType, err := ptypes.ValueTypeFromProto(defaultVariant.Type)
+	if err != nil {
+		logger.Warnw("Unable to parse default variant's type, skipping type check", "error", err)
+		return nil, nil
+	}
+	toType, err := ptypes.ValueTypeFromProto(variant.Type)
+	if err != nil {
+		logger.Warnw("Unable to parse new variant's type, skipping type check", "error", err)
+		return nil, nil
+	}
+	if fromType == toType {
+		return nil, nil
+	}
+	change := &pb.FeatureTypeChange{
+		FromVariant: feature.DefaultVariant,
+		ToVariant:   variant.Variant,
+		FromType:    defaultVariant.Type,
+		ToType:      variant.Type,
+		Changed:     tspb.Now(),
+	}
+	if ptypes.IsBackwardCompatible(fromType, toType) {
+		logger.Infow("Feature type changed", "from", fromType, "to", toType)
+		return change, nil
+	}
+	if !variant.AllowBreaking {
+		return nil, fferr.NewInvalidArgumentErrorf(
+			"feature %s variant %s changes type from %s (variant %s) to %s, which isn't backward compatible; set allow_breaking to override",
+			variant.Name, variant.Variant, fromType, feature.DefaultVariant, toType,
+		)
+	}
+	logger.Warnw("Allowing breaking feature type change", "from", fromType, "to", toType)
+	change.Breaking = true
+	return change, nil
+}
+
 func (serv *MetadataServer) setDefaultVariant(ctx context.Context, parent Resource, defaultVariant string) error {
 	logger := logging.GetLoggerFromContext(ctx)
 	logger.With("new-default-variant", defaultVariant)
@@ -3623,6 +4701,51 @@ func (serv *MetadataServer) setDefaultVariant(ctx context.Context, parent Resour
 	logger.Debug("Successfully set default variant")
 	return nil
 }
+
+// authorizeCreate checks that the caller owns the resource being created, and
+// the existing resource if it's being updated. Resources without an owner can
+// be created by any authenticated caller.
+func (serv *MetadataServer) authorizeCreate(ctx context.Context, res Resource, existing Resource) error {
+	return serv.authorize(ctx, "create", res.ID(), res, existing)
+}
+
+// authorizeChange checks that the caller owns the resource that id refers to
+// before it's changed or deleted. Resources without an owner can be changed by
+// any authenticated caller.
+func (serv *MetadataServer) authorizeChange(ctx context.Context, action string, id ResourceID) error {
+	if serv.authenticator == nil || !serv.authenticator.Enabled() {
+		return nil
+	}
+	res, err := serv.lookup.Lookup(ctx, id)
+	if err != nil {
+		return err
+	}
+	return serv.authorize(ctx, action, id, res)
+}
+
+// authorize checks that the request has a caller, and that it owns each of the
+// given resources that has an owner. Nil resources are skipped.
+func (serv *MetadataServer) authorize(ctx context.Context, action string, id ResourceID, resources ...Resource) error {
+	if serv.authenticator == nil || !serv.authenticator.Enabled() {
+		return nil
+	}
+	identity, ok := interceptors.IdentityFromContext(ctx)
+	if !ok {
+		return fferr.NewUnauthenticatedErrorf("a bearer token is required to %s %s", action, id)
+	}
+	for _, r := range resources {
+		owned, isOwned := r.(interface{ Owner() string })
+		if !isOwned {
+			continue
+		}
+		if owner := owned.Owner(); owner != "" && owner != identity.Subject {
+			err := fmt.Errorf("%s is owned by %s", r.ID(), owner)
+			return fferr.NewPermissionDeniedError(identity.Subject, err)
+		}
+	}
+	return nil
+}
+
 func (serv *MetadataServer) validateExisting(ctx context.Context, newRes Resource, existing Resource) error {
 	// It's possible we found a resource with the same name and variant but different contents, if different contents
 	// we'll let the user know to ideally use a different variant
@@ -3761,6 +4884,14 @@ func (serv *MetadataServer) genericGet(ctx context.Context, stream interface{},
 				Type:    t,
 			}
 			ctx = logging.AttachRequestID(logging.RequestID(req.GetRequestId()), ctx, logger)
+			if id.Variant == "" {
+				variant, err := serv.defaultVariant(ctx, id.Name, t)
+				if err != nil {
+					logging.GetLoggerFromContext(ctx).Errorw("Unable to resolve default variant", "name", id.Name, "error", err)
+					return err
+				}
+				id.Variant = variant
+			}
 			loggerWithResource = logging.GetLoggerFromContext(ctx).WithResource(id.Type.ToLoggingResourceType(), id.Name, id.Variant)
 		default:
 			logger.Errorw("Invalid Stream for Get", "type", fmt.Sprintf("%T", casted))
@@ -3783,8 +4914,14 @@ func (serv *MetadataServer) genericGet(ctx context.Context, stream interface{},
 				return err
 			}
 		}
-		loggerWithResource.Debug("Sending Resource")
 		serialized := resource.Proto()
+		if _, isParent := variantTypeOf(id.Type); isParent {
+			if serialized, err = serv.visibleParent(ctx, id, serialized); err != nil {
+				loggerWithResource.Errorw("Unable to hide archived variants", "error", err)
+				return err
+			}
+		}
+		loggerWithResource.Debug("Sending Resource")
 		if err := send(serialized); err != nil {
 			loggerWithResource.Errorw("Error sending resource", "error", err)
 			return fferr.NewInternalError(err)
@@ -3816,18 +4953,44 @@ func (serv *MetadataServer) getStatusFromTasks(ctx context.Context, resource Res
 	return resource.GetStatus().GetStatus(), nil
 }
 
-func (serv *MetadataServer) genericList(ctx context.Context, t ResourceType, send sendFn) error {
+// nextPageTokenTrailer is the trailer a paged list returns the token of the
+// next page in.
+const nextPageTokenTrailer = "next-page-token"
+
+func (serv *MetadataServer) genericList(ctx context.Context, t ResourceType, request *pb.ListRequest, stream grpc.ServerStream, send sendFn) error {
 	logger := logging.GetLoggerFromContext(ctx)
+	if request.GetPageSize() != 0 || request.GetPageToken() != "" {
+		page, nextPageToken, err := serv.listPage(ctx, t, request)
+		if err != nil {
+			return err
+		}
+		for _, serialized := range page {
+			if err := send(serialized); err != nil {
+				logger.Errorw("Error sending resource", "type", t, "error", err)
+				return fferr.NewInternalError(err)
+			}
+		}
+		stream.SetTrailer(grpcmeta.Pairs(nextPageTokenTrailer, nextPageToken))
+		return nil
+	}
 	logger.Infow("Listing Resources", "type", t)
 	resources, err := serv.lookup.ListForType(ctx, t)
 	if err != nil {
 		logger.Error("Unable to lookup list for type %v: %v", t, err)
 		return err
 	}
+	visible, err := serv.visibleFilter(ctx, t)
+	if err != nil {
+		return err
+	}
 	for _, res := range resources {
 		loggerWithResource := logger.WithResource(t.ToLoggingResourceType(), res.ID().Name, res.ID().Variant)
 		loggerWithResource.Debug("Getting %v", t)
-		serialized := res.Proto()
+		serialized, isVisible := visible(res.Proto())
+		if !isVisible {
+			loggerWithResource.Debug("Skipping resource with only archived variants")
+			continue
+		}
 		if err := send(serialized); err != nil {
 			loggerWithResource.Errorw("Error sending resource", "error", err)
 			return fferr.NewInternalError(err)
@@ -3836,6 +4999,104 @@ func (serv *MetadataServer) genericList(ctx context.Context, t ResourceType, sen
 	return nil
 }
 
+// visibleFilter returns a function that hides archived variants from their
+// parents' variant lists. It returns false for a parent whose variants are all
+// archived.
+func (serv *MetadataServer) visibleFilter(ctx context.Context, t ResourceType) (func(proto.Message) (proto.Message, bool), error) {
+	all := func(msg proto.Message) (proto.Message, bool) {
+		return msg, true
+	}
+	variantType, hasVariants := variantTypeOf(t)
+	if !hasVariants {
+		return all, nil
+	}
+	archivedIDs, err := serv.archivedVariants(ctx, variantType, "")
+	if err != nil {
+		logging.GetLoggerFromContext(ctx).Errorw("Unable to list archived variants", "type", variantType, "error", err)
+		return nil, err
+	}
+	if len(archivedIDs) == 0 {
+		return all, nil
+	}
+	archived := mapset.NewSet[ResourceID](archivedIDs...)
+	return func(msg proto.Message) (proto.Message, bool) {
+		return withoutArchivedVariants(msg, archived, variantType)
+	}, nil
+}
+
+// listPage returns a page of resources of a type, ordered by name, along with
+// the token of the next page. The token is empty on the last page. Resources
+// with only archived variants are skipped before the page is filled, so every
+// page but the last is full.
+func (serv *MetadataServer) listPage(ctx context.Context, t ResourceType, request *pb.ListRequest) ([]proto.Message, string, error) {
+	logger := logging.GetLoggerFromContext(ctx)
+	logger.Infow("Listing resource page", "type", t, "page_size", request.GetPageSize())
+	pageSize := int(request.GetPageSize())
+	if pageSize <= 0 {
+		return nil, "", fferr.NewInvalidArgumentErrorf("page size must be positive, got %d", pageSize)
+	}
+	after, err := parsePageToken(request.GetPageToken(), t)
+	if err != nil {
+		return nil, "", err
+	}
+	visible, err := serv.visibleFilter(ctx, t)
+	if err != nil {
+		return nil, "", err
+	}
+	page := make([]proto.Message, 0, pageSize)
+	var last ResourceID
+	for {
+		resources, err := serv.lookup.ListForTypePage(ctx, t, after, pageSize)
+		if err != nil {
+			logger.Errorw("Unable to list page", "type", t, "after", after.Name, "error", err)
+			return nil, "", err
+		}
+		for _, res := range resources {
+			serialized, isVisible := visible(res.Proto())
+			if !isVisible {
+				continue
+			}
+			// A visible resource past the end of the page means there's another one.
+			if len(page) == pageSize {
+				return page, encodePageToken(last), nil
+			}
+			page = append(page, serialized)
+			last = res.ID()
+		}
+		if len(resources) < pageSize {
+			return page, "", nil
+		}
+		after = resources[len(resources)-1].ID()
+	}
+}
+
+// encodePageToken returns a token for the page that starts after the resource
+// id. Pages start after a name rather than at a position, so resources created
+// or deleted between requests don't shift later pages.
+func encodePageToken(id ResourceID) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(fmt.Sprintf("%s:%s", id.Type, id.Name)))
+}
+
+// parsePageToken returns the resource a token's page starts after, or a zero
+// id for the first page. A token from a list of a different type is rejected.
+func parsePageToken(token string, t ResourceType) (ResourceID, error) {
+	if token == "" {
+		return ResourceID{}, nil
+	}
+	decoded, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return ResourceID{}, fferr.NewInvalidArgumentErrorf("invalid page token: %v", err)
+	}
+	tokenType, name, found := strings.Cut(string(decoded), ":")
+	if !found || name == "" {
+		return ResourceID{}, fferr.NewInvalidArgumentErrorf("invalid page token %s", token)
+	}
+	if tokenType != t.String() {
+		return ResourceID{}, fferr.NewInvalidArgumentErrorf("page token is for %s, not %s", tokenType, t)
+	}
+	return ResourceID{Name: name, Type: t}, nil
+}
+
 func (serv *MetadataServer) GetResourceDAG(ctx context.Context, r Resource) (ResourceDAG, error) {
 	_, ctx, logger := serv.Logger.InitializeRequestID(ctx)
 	dag, err := NewResourceDAG(ctx, serv.lookup, r)
@@ -3927,6 +5188,23 @@ type SourceVariantResource struct {
 	Error          string                                  `json:"error"`
 	Specifications map[string]string                       `json:"specifications"`
 	Inputs         []NameVariant                           `json:"inputs"`
+	Profile        *SourceProfileResource                  `json:"profile"`
+}
+
+// SourceProfileResource holds the column summaries of a profiled source.
+type SourceProfileResource struct {
+	Columns    []ColumnProfileResource `json:"columns"`
+	RowCount   int64                   `json:"row-count"`
+	SampleSize int64                   `json:"sample-size"`
+	Profiled   time.Time               `json:"profiled"`
+}
+
+type ColumnProfileResource struct {
+	Name          string `json:"name"`
+	NullCount     int64  `json:"null-count"`
+	DistinctCount int64  `json:"distinct-count"`
+	Min           string `json:"min"`
+	Max           string `json:"max"`
 }
 
 // TODO: Might need to modify this to add number of features and number of labels
@@ -4016,5 +5294,30 @@ func SourceShallowMap(variant *SourceVariant) SourceVariantResource {
 		Properties:     variant.Properties(),
 		Error:          variant.Error(),
 		Specifications: getSourceArgs(variant),
+		Profile:        getSourceProfile(variant),
+	}
+}
+
+// getSourceProfile returns nil if the variant hasn't been profiled.
+func getSourceProfile(variant *SourceVariant) *SourceProfileResource {
+	profile := variant.Profile()
+	if profile == nil {
+		return nil
+	}
+	columns := make([]ColumnProfileResource, len(profile.Columns))
+	for i, column := range profile.Columns {
+		columns[i] = ColumnProfileResource{
+			Name:          column.Name,
+			NullCount:     column.NullCount,
+			DistinctCount: column.DistinctCount,
+			Min:           column.Min,
+			Max:           column.Max,
+		}
+	}
+	return &SourceProfileResource{
+		Columns:    columns,
+		RowCount:   profile.RowCount,
+		SampleSize: profile.SampleSize,
+		Profiled:   profile.Profiled.AsTime(),
 	}
 }
@@ -247,6 +247,10 @@ func (MetadataServerMock) GetFeatureVariants(ctx context.Context, opts ...grpc.C
 		sent: false,
 	}, nil
 }
+func (MetadataServerMock) BatchGetFeatureVariants(ctx context.Context, in *pb.BatchGetFeatureVariantsRequest, opts ...grpc.CallOption) (*pb.BatchGetFeatureVariantsResponse, error) {
+	fv, _ := (&mockFeatureClient{}).Recv()
+	return &pb.BatchGetFeatureVariantsResponse{FeatureVariants: []*pb.FeatureVariant{fv}}, nil
+}
 func (MetadataServerMock) ListLabels(ctx context.Context, in *pb.ListRequest, opts ...grpc.CallOption) (pb.Metadata_ListLabelsClient, error) {
 	return nil, nil
 }
@@ -359,3 +363,47 @@ func (m MetadataServerMock) FinalizeDeletion(ctx context.Context, in *pb.Finaliz
 func (m MetadataServerMock) PruneResource(ctx context.Context, in *pb.PruneResourceRequest, opts ...grpc.CallOption) (*pb.PruneResourceResponse, error) {
 	return &pb.PruneResourceResponse{}, nil
 }
+
+func (m MetadataServerMock) DeleteResourceVariant(ctx context.Context, in *pb.DeleteResourceVariantRequest, opts ...grpc.CallOption) (*pb.DeleteResourceVariantResponse, error) {
+	return &pb.DeleteResourceVariantResponse{}, nil
+}
+
+func (m MetadataServerMock) GetDefaultVariant(ctx context.Context, in *pb.GetDefaultVariantRequest, opts ...grpc.CallOption) (*pb.NameVariant, error) {
+	return &pb.NameVariant{Name: in.Name}, nil
+}
+
+func (m MetadataServerMock) ListVariants(ctx context.Context, in *pb.ListVariantsRequest, opts ...grpc.CallOption) (*pb.ListVariantsResponse, error) {
+	return &pb.ListVariantsResponse{Name: in.Name}, nil
+}
+
+func (m MetadataServerMock) GetLineage(ctx context.Context, in *pb.GetLineageRequest, opts ...grpc.CallOption) (*pb.Lineage, error) {
+	return &pb.Lineage{}, nil
+}
+
+func (m MetadataServerMock) Plan(ctx context.Context, in *pb.PlanRequest, opts ...grpc.CallOption) (*pb.PlanResponse, error) {
+	return &pb.PlanResponse{}, nil
+}
+
+func (m MetadataServerMock) ArchiveResourceVariant(ctx context.Context, in *pb.ArchiveResourceVariantRequest, opts ...grpc.CallOption) (*pb.ArchiveResourceVariantResponse, error) {
+	return &pb.ArchiveResourceVariantResponse{}, nil
+}
+
+func (m MetadataServerMock) AddTags(ctx context.Context, in *pb.UpdateTagsRequest, opts ...grpc.CallOption) (*pb.UpdateTagsResponse, error) {
+	return &pb.UpdateTagsResponse{}, nil
+}
+
+func (m MetadataServerMock) RemoveTags(ctx context.Context, in *pb.UpdateTagsRequest, opts ...grpc.CallOption) (*pb.UpdateTagsResponse, error) {
+	return &pb.UpdateTagsResponse{}, nil
+}
+
+func (m MetadataServerMock) ListArchived(ctx context.Context, in *pb.ListArchivedRequest, opts ...grpc.CallOption) (*pb.ListArchivedResponse, error) {
+	return &pb.ListArchivedResponse{}, nil
+}
+
+func (m MetadataServerMock) SetSourceProfile(ctx context.Context, in *pb.SetSourceProfileRequest, opts ...grpc.CallOption) (*pb.Empty, error) {
+	return &pb.Empty{}, nil
+}
+
+func (m MetadataServerMock) ProfileSource(ctx context.Context, in *pb.ProfileSourceRequest, opts ...grpc.CallOption) (*pb.ProfileSourceResponse, error) {
+	return &pb.ProfileSourceResponse{}, nil
+}
@@ -12,15 +12,27 @@ import (
 	"fmt"
 	"net"
 	"reflect"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 
 	"github.com/featureform/scheduling"
+	schproto "github.com/featureform/scheduling/proto"
 
+	"github.com/featureform/config"
+	"github.com/featureform/fferr"
+	"github.com/featureform/helpers/encryption"
+	"github.com/featureform/helpers/events"
+	"github.com/featureform/helpers/interceptors"
 	"github.com/featureform/logging"
 	pb "github.com/featureform/metadata/proto"
 	"github.com/featureform/metadata/search"
 	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	reflectionpb "google.golang.org/grpc/reflection/grpc_reflection_v1"
 	grpc_status "google.golang.org/grpc/status"
 	"google.golang.org/protobuf/proto"
 	tspb "google.golang.org/protobuf/types/known/timestamppb"
@@ -195,10 +207,11 @@ func filledResourceDefs() []ResourceDef {
 				Value:  "col2",
 				TS:     "col3",
 			},
-			Tags:       Tags{},
-			Properties: Properties{},
-			Mode:       PRECOMPUTED,
-			IsOnDemand: false,
+			Tags:          Tags{},
+			Properties:    Properties{},
+			Mode:          PRECOMPUTED,
+			IsOnDemand:    false,
+			AllowBreaking: true,
 		},
 		FeatureDef{
 			Name:        "feature2",
@@ -683,11 +696,141 @@ func TestServeGracefulStop(t *testing.T) {
 	}
 }
 
+func TestServeGRPCReflection(t *testing.T) {
+	for _, enabled := range []bool{true, false} {
+		t.Run(fmt.Sprintf("enabled=%t", enabled), func(t *testing.T) {
+			t.Setenv(config.EnvEnableGRPCReflection, fmt.Sprintf("%t", enabled))
+			ctx, logger := logging.NewTestContextAndLogger(t)
+			serv, addr := startServ(t, ctx, logger)
+			defer serv.Stop()
+
+			conn, err := grpc.NewClient(addr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+			if err != nil {
+				t.Fatalf("Failed to connect to metadata server: %v", err)
+			}
+			defer conn.Close()
+			stream, err := reflectionpb.NewServerReflectionClient(conn).ServerReflectionInfo(ctx)
+			if err != nil {
+				t.Fatalf("Failed to open reflection stream: %v", err)
+			}
+			req := &reflectionpb.ServerReflectionRequest{
+				MessageRequest: &reflectionpb.ServerReflectionRequest_ListServices{},
+			}
+			if err := stream.Send(req); err != nil {
+				t.Fatalf("Failed to send reflection request: %v", err)
+			}
+			resp, err := stream.Recv()
+			if !enabled {
+				if grpc_status.Code(err) != codes.Unimplemented {
+					t.Fatalf("Expected reflection to be unimplemented, got: %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Failed to list services: %v", err)
+			}
+			services := make(map[string]bool)
+			for _, service := range resp.GetListServicesResponse().GetService() {
+				services[service.GetName()] = true
+			}
+			for _, service := range []string{pb.Metadata_ServiceDesc.ServiceName, schproto.Tasks_ServiceDesc.ServiceName} {
+				if !services[service] {
+					t.Fatalf("Expected %s in reflected services, got: %v", service, services)
+				}
+			}
+		})
+	}
+}
+
+func TestAuthorizeCreate(t *testing.T) {
+	auth, err := interceptors.NewJWTAuthenticator(interceptors.JWTConfig{Secret: []byte("secret")})
+	if err != nil {
+		t.Fatalf("Failed to create authenticator: %v", err)
+	}
+	source := func(owner string) Resource {
+		return &sourceVariantResource{&pb.SourceVariant{Name: "source", Variant: "v1", Owner: owner}}
+	}
+	alice := interceptors.ContextWithIdentity(context.Background(), interceptors.Identity{Subject: "alice"})
+	tests := []struct {
+		name     string
+		auth     interceptors.Authenticator
+		ctx      context.Context
+		res      Resource
+		existing Resource
+		wantCode codes.Code
+	}{
+		{"Disabled", interceptors.NoopAuthenticator{}, context.Background(), source("bob"), nil, codes.OK},
+		{"Anonymous", auth, context.Background(), source("alice"), nil, codes.Unauthenticated},
+		{"Owner", auth, alice, source("alice"), nil, codes.OK},
+		{"NotOwner", auth, alice, source("bob"), nil, codes.PermissionDenied},
+		{"UpdateOwned", auth, alice, source("alice"), source("alice"), codes.OK},
+		{"UpdateNotOwned", auth, alice, source("alice"), source("bob"), codes.PermissionDenied},
+		{"NoOwner", auth, alice, &entityResource{&pb.Entity{Name: "user"}}, nil, codes.OK},
+		{"EmptyOwner", auth, alice, source("alice"), source(""), codes.OK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			serv := &MetadataServer{authenticator: tt.auth}
+			err := serv.authorizeCreate(tt.ctx, tt.res, tt.existing)
+			if code := grpc_status.Code(err); code != tt.wantCode {
+				t.Fatalf("Expected code %s, got %s: %v", tt.wantCode, code, err)
+			}
+		})
+	}
+}
+
+func TestAuthorizeChange(t *testing.T) {
+	ctx, logger := logging.NewTestContextAndLogger(t)
+	manager, err := scheduling.NewMemoryTaskMetadataManager(ctx)
+	if err != nil {
+		t.Fatalf("Failed to create memory manager: %v", err)
+	}
+	auth, err := interceptors.NewJWTAuthenticator(interceptors.JWTConfig{Secret: []byte("secret")})
+	if err != nil {
+		t.Fatalf("Failed to create authenticator: %v", err)
+	}
+	lookup := &MemoryResourceLookup{Connection: manager.Storage}
+	serv := &MetadataServer{authenticator: auth, lookup: lookup, Logger: logger, taskManager: &manager}
+	id := ResourceID{Name: "source", Variant: "v1", Type: SOURCE_VARIANT}
+	source := &sourceVariantResource{&pb.SourceVariant{Name: "source", Variant: "v1", Owner: "bob"}}
+	if err := lookup.Set(ctx, id, source); err != nil {
+		t.Fatalf("Failed to set source: %v", err)
+	}
+	tests := []struct {
+		name     string
+		subject  string
+		wantCode codes.Code
+	}{
+		{"Anonymous", "", codes.Unauthenticated},
+		{"Owner", "bob", codes.OK},
+		{"NotOwner", "alice", codes.PermissionDenied},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reqCtx := ctx
+			if tt.subject != "" {
+				reqCtx = interceptors.ContextWithIdentity(ctx, interceptors.Identity{Subject: tt.subject})
+			}
+			if code := grpc_status.Code(serv.authorizeChange(reqCtx, "archive", id)); code != tt.wantCode {
+				t.Fatalf("Expected code %s, got %s", tt.wantCode, code)
+			}
+			_, err := serv.ArchiveResourceVariant(reqCtx, &pb.ArchiveResourceVariantRequest{ResourceId: id.Proto()})
+			if code := grpc_status.Code(err); code != tt.wantCode {
+				t.Fatalf("Expected ArchiveResourceVariant code %s, got %s: %v", tt.wantCode, code, err)
+			}
+		})
+	}
+}
+
 type MockSearcher struct {
 	search.Searcher
 }
 
-func mockNewMeilisearch(params *search.MeilisearchParams) (search.Searcher, error) {
+func (s *MockSearcher) Health() error {
+	return nil
+}
+
+func mockNewMeilisearch(params search.Params) (search.Searcher, error) {
 	return &MockSearcher{}, nil
 }
 
@@ -710,7 +853,7 @@ func TestLookupWrapInitialize(t *testing.T) {
 		TaskManager:  manager,
 	}
 
-	lookup := MemoryResourceLookup{config.TaskManager.Storage}
+	lookup := MemoryResourceLookup{Connection: config.TaskManager.Storage}
 	resultWrap, err := initializeLookup(config, &lookup, mockNewMeilisearch)
 	if err != nil {
 		t.Fatal("initialize returned an error:", err.Error())
@@ -722,6 +865,152 @@ func TestLookupWrapInitialize(t *testing.T) {
 	}
 }
 
+func TestProviderConfigEncryption(t *testing.T) {
+	ctx, _ := logging.NewTestContextAndLogger(t)
+	manager, err := scheduling.NewMemoryTaskMetadataManager(ctx)
+	if err != nil {
+		t.Fatalf("Failed to create memory manager: %v", err)
+	}
+	wrapper, err := encryption.NewLocalKeyWrapper("MDEyMzQ1Njc4OWFiY2RlZjAxMjM0NTY3ODlhYmNkZWY=")
+	if err != nil {
+		t.Fatalf("Failed to create key wrapper: %v", err)
+	}
+	lookup := MemoryResourceLookup{Connection: manager.Storage, Encrypter: encryption.NewEncrypter(wrapper)}
+	secretConfig := []byte(`{"Password": "hunter2"}`)
+	res := &providerResource{&pb.Provider{Name: "postgres", Type: pt.PostgresOffline.String(), SerializedConfig: secretConfig}}
+	if err := lookup.Set(ctx, res.ID(), res); err != nil {
+		t.Fatalf("Failed to set provider: %v", err)
+	}
+	if !reflect.DeepEqual(res.serialized.SerializedConfig, secretConfig) {
+		t.Fatalf("Expected Set not to modify the resource, got %s", res.serialized.SerializedConfig)
+	}
+	stored, err := manager.Storage.Get(createKey(res.ID()))
+	if err != nil {
+		t.Fatalf("Failed to get stored provider: %v", err)
+	}
+	if strings.Contains(stored, "hunter2") {
+		t.Fatalf("Provider config is stored in plaintext: %s", stored)
+	}
+	found, err := lookup.Lookup(ctx, res.ID())
+	if err != nil {
+		t.Fatalf("Failed to lookup provider: %v", err)
+	}
+	if got := found.(*providerResource).serialized.SerializedConfig; !reflect.DeepEqual(got, secretConfig) {
+		t.Fatalf("Expected decrypted config %s, got %s", secretConfig, got)
+	}
+	list, err := lookup.ListForType(ctx, PROVIDER)
+	if err != nil {
+		t.Fatalf("Failed to list providers: %v", err)
+	}
+	if len(list) != 1 || !reflect.DeepEqual(list[0].(*providerResource).serialized.SerializedConfig, secretConfig) {
+		t.Fatalf("Expected one decrypted provider, got %v", list)
+	}
+
+	// Providers stored before encryption was enabled can still be read.
+	plaintextLookup := MemoryResourceLookup{Connection: manager.Storage}
+	legacy := &providerResource{&pb.Provider{Name: "legacy", Type: pt.PostgresOffline.String(), SerializedConfig: secretConfig}}
+	if err := plaintextLookup.Set(ctx, legacy.ID(), legacy); err != nil {
+		t.Fatalf("Failed to set provider: %v", err)
+	}
+	found, err = lookup.Lookup(ctx, legacy.ID())
+	if err != nil {
+		t.Fatalf("Failed to lookup plaintext provider: %v", err)
+	}
+	if got := found.(*providerResource).serialized.SerializedConfig; !reflect.DeepEqual(got, secretConfig) {
+		t.Fatalf("Expected plaintext config %s, got %s", secretConfig, got)
+	}
+
+	// Encrypted providers can't be read without the key.
+	if _, err := plaintextLookup.Lookup(ctx, res.ID()); err == nil {
+		t.Fatalf("Expected lookup without the key to fail")
+	}
+
+	// Config updates are only compared once decrypted.
+	encryptedConfig, err := lookup.Encrypter.Encrypt(ctx, secretConfig)
+	if err != nil {
+		t.Fatalf("Failed to encrypt config: %v", err)
+	}
+	encrypted := &providerResource{&pb.Provider{Name: "postgres", Type: pt.PostgresOffline.String(), SerializedConfig: encryptedConfig}}
+	if _, err := encrypted.isValidConfigUpdate(secretConfig); err == nil {
+		t.Fatalf("Expected comparing an encrypted config to fail")
+	}
+}
+
+// memorySearcher indexes documents in a map and matches searches on name.
+type memorySearcher struct {
+	MockSearcher
+	docs map[string]search.ResourceDoc
+}
+
+func (s *memorySearcher) Upsert(doc search.ResourceDoc) error {
+	s.docs[search.DocumentID(doc)] = doc
+	return nil
+}
+
+func (s *memorySearcher) Delete(id string) error {
+	delete(s.docs, id)
+	return nil
+}
+
+func (s *memorySearcher) RunSearch(q string) ([]search.ResourceDoc, error) {
+	var results []search.ResourceDoc
+	for _, doc := range s.docs {
+		if strings.Contains(doc.Name, q) {
+			results = append(results, doc)
+		}
+	}
+	return results, nil
+}
+
+func TestSearchWrapperDelete(t *testing.T) {
+	ctx := logging.NewTestContext(t)
+	manager, err := scheduling.NewMemoryTaskMetadataManager(ctx)
+	if err != nil {
+		t.Fatalf("Failed to create memory task metadata manager: %v", err)
+	}
+	searcher := &memorySearcher{docs: make(map[string]search.ResourceDoc)}
+	wrapper := SearchWrapper{
+		Searcher:       searcher,
+		ResourceLookup: &MemoryResourceLookup{Connection: manager.Storage},
+	}
+	newFeature := func(variant string) (ResourceID, *featureVariantResource) {
+		id := ResourceID{Name: "fraud", Variant: variant, Type: FEATURE_VARIANT}
+		return id, &featureVariantResource{serialized: &pb.FeatureVariant{Name: id.Name, Variant: id.Variant, Tags: &pb.Tags{}}}
+	}
+	assertHits := func(expected int) {
+		t.Helper()
+		hits, err := searcher.RunSearch("fraud")
+		if err != nil {
+			t.Fatalf("Failed to search: %v", err)
+		}
+		if len(hits) != expected {
+			t.Fatalf("Expected %d hits, got: %#v", expected, hits)
+		}
+	}
+	archivedID, archived := newFeature("v1")
+	deletedID, deleted := newFeature("v2")
+	for id, res := range map[ResourceID]*featureVariantResource{archivedID: archived, deletedID: deleted} {
+		if err := wrapper.Set(ctx, id, res); err != nil {
+			t.Fatalf("Failed to set %s: %v", id, err)
+		}
+	}
+	assertHits(2)
+	archived.SetArchived(true)
+	if err := wrapper.Set(ctx, archivedID, archived); err != nil {
+		t.Fatalf("Failed to archive %s: %v", archivedID, err)
+	}
+	assertHits(1)
+	if err := wrapper.Delete(ctx, deletedID); err != nil {
+		t.Fatalf("Failed to delete %s: %v", deletedID, err)
+	}
+	assertHits(0)
+	archived.SetArchived(false)
+	if err := wrapper.Set(ctx, archivedID, archived); err != nil {
+		t.Fatalf("Failed to unarchive %s: %v", archivedID, err)
+	}
+	assertHits(1)
+}
+
 func TestCreate(t *testing.T) {
 	ctx := testContext{
 		Defs: filledResourceDefs(),
@@ -2156,32 +2445,33 @@ func testFetchProvider(t *testing.T, client *Client, fetcher providerFetcher) {
 }
 
 func TestBannedStrings(t *testing.T) {
+	rules := DefaultNamingRules()
 	resourceInvalidName := ResourceID{"nam__e", "variant", FEATURE}
 	resourceInvalidVariant := ResourceID{"name", "varian__t", FEATURE}
-	if err := resourceNamedSafely(resourceInvalidName); err == nil {
+	if err := rules.Validate(resourceInvalidName); err == nil {
 		t.Fatalf("testing didn't catch error on valid resource name")
 	}
-	if err := resourceNamedSafely(resourceInvalidVariant); err == nil {
+	if err := rules.Validate(resourceInvalidVariant); err == nil {
 		t.Fatalf("testing didn't catch error on valid resource name")
 	}
 	invalidNamePrefix := ResourceID{"_name", "variant", FEATURE}
 	invalidVariantPrefix := ResourceID{"name", "_variant", FEATURE}
-	if err := resourceNamedSafely(invalidNamePrefix); err == nil {
+	if err := rules.Validate(invalidNamePrefix); err == nil {
 		t.Fatalf("testing didn't catch error on valid resource prefix")
 	}
-	if err := resourceNamedSafely(invalidVariantPrefix); err == nil {
+	if err := rules.Validate(invalidVariantPrefix); err == nil {
 		t.Fatalf("testing didn't catch error on valid variant prefix")
 	}
 	invalidNameSuffix := ResourceID{"name_", "variant", FEATURE}
 	invalidVariantSuffix := ResourceID{"name", "variant_", FEATURE}
-	if err := resourceNamedSafely(invalidNameSuffix); err == nil {
+	if err := rules.Validate(invalidNameSuffix); err == nil {
 		t.Fatalf("testing didn't catch error on valid resource prefix")
 	}
-	if err := resourceNamedSafely(invalidVariantSuffix); err == nil {
+	if err := rules.Validate(invalidVariantSuffix); err == nil {
 		t.Fatalf("testing didn't catch error on valid variant prefix")
 	}
 	validName := ResourceID{"name", "variant", FEATURE}
-	if err := resourceNamedSafely(validName); err != nil {
+	if err := rules.Validate(validName); err != nil {
 		t.Fatalf("valid resource triggered an error")
 	}
 }
@@ -2929,3 +3219,1050 @@ func TestInvalidCommonEntity(t *testing.T) {
 	}
 	defer ctx.Destroy()
 }
+
+func Test_ArchiveResourceVariant(t *testing.T) {
+	requestID, ctx, logger := logging.InitializeTestRequestID(t)
+	serv, addr := startServNoPanic(t, ctx, logger)
+	client := client(t, ctx, logger, addr)
+
+	snowflakeConfig := pc.SnowflakeConfig{
+		Username:     "featureformer",
+		Password:     "password",
+		Organization: "featureform",
+		Account:      "featureform-test",
+		Database:     "transactions_db",
+		Schema:       "fraud",
+		Warehouse:    "ff_wh_xs",
+		Role:         "sysadmin",
+	}
+	userDef := UserDef{
+		Name:       "Featureform",
+		Tags:       Tags{},
+		Properties: Properties{},
+	}
+	offlineDef := ProviderDef{
+		Name:             "mockOffline",
+		Description:      "A mock offline provider",
+		Type:             string(pt.SnowflakeOffline),
+		Software:         "snowflake",
+		Team:             "recommendations",
+		SerializedConfig: snowflakeConfig.Serialize(),
+		Tags:             Tags{},
+		Properties:       Properties{},
+	}
+	sourceDef := func(variant, query string) SourceDef {
+		return SourceDef{
+			Name:        "mockSource",
+			Variant:     variant,
+			Description: "A transformation",
+			Definition: TransformationSource{
+				TransformationType: SQLTransformationType{
+					Query:   query,
+					Sources: []NameVariant{{Name: "mockName", Variant: "mockVariant"}},
+				},
+			},
+			Owner:      "Featureform",
+			Provider:   "mockOffline",
+			Tags:       Tags{},
+			Properties: Properties{},
+		}
+	}
+	first := sourceDef("var", "SELECT * FROM dummy")
+	second := sourceDef("var2", "SELECT count(*) FROM dummy")
+	if err := client.CreateAll(ctx, []ResourceDef{userDef, offlineDef, first, second}); err != nil {
+		t.Fatalf("Failed to create resources: %s", err)
+	}
+
+	archivedID := ResourceID{Name: "mockSource", Variant: "var2", Type: SOURCE_VARIANT}
+	if err := client.ArchiveResourceVariant(ctx, archivedID); err != nil {
+		t.Fatalf("Failed to archive variant: %s", err)
+	}
+	sources, err := client.ListSources(ctx)
+	if err != nil {
+		t.Fatalf("Failed to list sources: %s", err)
+	}
+	if len(sources) != 1 {
+		t.Fatalf("Expected 1 source, got %d", len(sources))
+	}
+	if variants := sources[0].Variants(); !reflect.DeepEqual(variants, []string{"var"}) {
+		t.Fatalf("Expected archived variant to be hidden, got %v", variants)
+	}
+	if sources[0].DefaultVariant() != "var" {
+		t.Fatalf("Expected default variant to fall back to var, got %s", sources[0].DefaultVariant())
+	}
+	source, err := client.GetSource(ctx, "mockSource")
+	if err != nil {
+		t.Fatalf("Failed to get source: %s", err)
+	}
+	if !reflect.DeepEqual(source.Variants(), []string{"var"}) || source.DefaultVariant() != "var" {
+		t.Fatalf("Expected get to hide the archived variant like list, got %v with default %s", source.Variants(), source.DefaultVariant())
+	}
+	defaultVariant, err := client.GetDefaultVariant(ctx, "mockSource", SOURCE)
+	if err != nil {
+		t.Fatalf("Failed to get default variant: %s", err)
+	}
+	if defaultVariant != "var" {
+		t.Fatalf("Expected default variant to fall back to var, got %s", defaultVariant)
+	}
+	// The archived variant is still available for lineage.
+	if _, err := client.GetSourceVariant(ctx, NameVariant{Name: "mockSource", Variant: "var2"}); err != nil {
+		t.Fatalf("Failed to get archived variant: %s", err)
+	}
+	archived, err := client.ListArchived(ctx, SOURCE)
+	if err != nil {
+		t.Fatalf("Failed to list archived variants: %s", err)
+	}
+	if !reflect.DeepEqual(archived, []ResourceID{archivedID}) {
+		t.Fatalf("Expected %v to be archived, got %v", archivedID, archived)
+	}
+
+	getEquivalent := func(def SourceDef) *pb.ResourceVariant {
+		def.Variant = "var3"
+		svProto, err := def.Serialize(requestID)
+		if err != nil {
+			t.Fatalf("Failed to serialize source def: %s", err)
+		}
+		equivalent, err := serv.getEquivalent(ctx, &pb.GetEquivalentRequest{
+			Variant:   &pb.ResourceVariant{Resource: &pb.ResourceVariant_SourceVariant{SourceVariant: svProto.SourceVariant}},
+			RequestId: requestID.String(),
+		}, false, "")
+		if err != nil {
+			t.Fatalf("Failed to get equivalent: %s", err)
+		}
+		return equivalent
+	}
+	if !proto.Equal(getEquivalent(second), &pb.ResourceVariant{}) {
+		t.Fatalf("Archived variant should not be equivalent")
+	}
+
+	if err := client.UnarchiveResourceVariant(ctx, archivedID); err != nil {
+		t.Fatalf("Failed to unarchive variant: %s", err)
+	}
+	if proto.Equal(getEquivalent(second), &pb.ResourceVariant{}) {
+		t.Fatalf("Unarchived variant should be equivalent")
+	}
+	sources, err = client.ListSources(ctx)
+	if err != nil {
+		t.Fatalf("Failed to list sources: %s", err)
+	}
+	if variants := sources[0].Variants(); len(variants) != 2 {
+		t.Fatalf("Expected both variants after unarchiving, got %v", variants)
+	}
+
+	for _, variant := range []string{"var", "var2"} {
+		if err := client.ArchiveResourceVariant(ctx, ResourceID{Name: "mockSource", Variant: variant, Type: SOURCE_VARIANT}); err != nil {
+			t.Fatalf("Failed to archive variant %s: %s", variant, err)
+		}
+	}
+	if _, err := client.GetSource(ctx, "mockSource"); err == nil {
+		t.Fatalf("Expected an error getting a source whose variants are all archived")
+	}
+
+	if err := client.ArchiveResourceVariant(ctx, ResourceID{Name: "Featureform", Type: USER}); err == nil {
+		t.Fatalf("Expected error archiving a user")
+	}
+}
+
+func Test_SetSourceProfile(t *testing.T) {
+	_, ctx, logger := logging.InitializeTestRequestID(t)
+	_, addr := startServNoPanic(t, ctx, logger)
+	client := client(t, ctx, logger, addr)
+
+	userDef := UserDef{
+		Name:       "Featureform",
+		Tags:       Tags{},
+		Properties: Properties{},
+	}
+	offlineDef := ProviderDef{
+		Name:             "mockOffline",
+		Description:      "A mock offline provider",
+		Type:             string(pt.MemoryOffline),
+		Software:         "memory",
+		SerializedConfig: []byte{},
+		Tags:             Tags{},
+		Properties:       Properties{},
+	}
+	sourceDef := SourceDef{
+		Name:    "mockSource",
+		Variant: "var",
+		Definition: PrimaryDataSource{
+			Location: SQLTable{Name: "mockTable"},
+		},
+		Owner:      "Featureform",
+		Provider:   "mockOffline",
+		Tags:       Tags{},
+		Properties: Properties{},
+	}
+	if err := client.CreateAll(ctx, []ResourceDef{userDef, offlineDef, sourceDef}); err != nil {
+		t.Fatalf("Failed to create resources: %s", err)
+	}
+	nv := NameVariant{Name: "mockSource", Variant: "var"}
+	source, err := client.GetSourceVariant(ctx, nv)
+	if err != nil {
+		t.Fatalf("Failed to get source: %s", err)
+	}
+	if source.Profile() != nil {
+		t.Fatalf("Expected unprofiled source, got %v", source.Profile())
+	}
+	profile := &pb.SourceProfile{
+		RowCount:   10,
+		SampleSize: 5,
+		Columns: []*pb.ColumnProfile{
+			{Name: "amount", NullCount: 1, DistinctCount: 3, Min: "1", Max: "9"},
+			{Name: "user", DistinctCount: 4},
+		},
+	}
+	if err := client.SetSourceProfile(ctx, nv, profile); err != nil {
+		t.Fatalf("Failed to set source profile: %s", err)
+	}
+	source, err = client.GetSourceVariant(ctx, nv)
+	if err != nil {
+		t.Fatalf("Failed to get source: %s", err)
+	}
+	if !proto.Equal(source.Profile(), profile) {
+		t.Fatalf("Expected profile %v, got %v", profile, source.Profile())
+	}
+	if err := client.SetSourceProfile(ctx, NameVariant{Name: "missing", Variant: "var"}, profile); err == nil {
+		t.Fatalf("Expected error profiling a missing source")
+	}
+}
+
+func Test_ProfileSource(t *testing.T) {
+	_, ctx, logger := logging.InitializeTestRequestID(t)
+	_, addr := startServNoPanic(t, ctx, logger)
+	client := client(t, ctx, logger, addr)
+
+	userDef := UserDef{
+		Name:       "Featureform",
+		Tags:       Tags{},
+		Properties: Properties{},
+	}
+	offlineDef := ProviderDef{
+		Name:             "mockOffline",
+		Description:      "A mock offline provider",
+		Type:             string(pt.MemoryOffline),
+		Software:         "memory",
+		SerializedConfig: []byte{},
+		Tags:             Tags{},
+		Properties:       Properties{},
+	}
+	sourceDef := SourceDef{
+		Name:    "mockSource",
+		Variant: "var",
+		Definition: PrimaryDataSource{
+			Location: SQLTable{Name: "mockTable"},
+		},
+		Owner:      "Featureform",
+		Provider:   "mockOffline",
+		Tags:       Tags{},
+		Properties: Properties{},
+	}
+	if err := client.CreateAll(ctx, []ResourceDef{userDef, offlineDef, sourceDef}); err != nil {
+		t.Fatalf("Failed to create resources: %s", err)
+	}
+	nv := NameVariant{Name: "mockSource", Variant: "var"}
+	resp, err := client.ProfileSource(ctx, nv, 100)
+	if err != nil {
+		t.Fatalf("Failed to profile source: %s", err)
+	}
+	tid, err := scheduling.ParseTaskID(resp.TaskId)
+	if err != nil {
+		t.Fatalf("Failed to parse task id %q: %s", resp.TaskId, err)
+	}
+	rid, err := scheduling.ParseTaskRunID(resp.RunId)
+	if err != nil {
+		t.Fatalf("Failed to parse run id %q: %s", resp.RunId, err)
+	}
+	run, err := client.Tasks.GetRun(tid, rid)
+	if err != nil {
+		t.Fatalf("Failed to get task run: %s", err)
+	}
+	expected := scheduling.SourceProfile{Name: "mockSource", Variant: "var", SampleSize: 100}
+	if !reflect.DeepEqual(run.Target, expected) {
+		t.Fatalf("Expected target %v, got %v", expected, run.Target)
+	}
+	if _, err := client.ProfileSource(ctx, NameVariant{Name: "missing", Variant: "var"}, 100); err == nil {
+		t.Fatalf("Expected error profiling a missing source")
+	}
+}
+
+func Test_BatchGetFeatureVariants(t *testing.T) {
+	_, ctx, logger := logging.InitializeTestRequestID(t)
+	_, addr := startServNoPanic(t, ctx, logger)
+	client := client(t, ctx, logger, addr)
+
+	userDef := UserDef{
+		Name:       "Featureform",
+		Tags:       Tags{},
+		Properties: Properties{},
+	}
+	featureDef := func(variant string) FeatureDef {
+		return FeatureDef{
+			Name:        "feature",
+			Variant:     variant,
+			Description: "On-demand feature",
+			Owner:       "Featureform",
+			Location: PythonFunction{
+				Query: []byte(PythonFunc),
+			},
+			Tags:       Tags{},
+			Properties: Properties{},
+			Mode:       CLIENT_COMPUTED,
+			IsOnDemand: true,
+		}
+	}
+	if err := client.CreateAll(ctx, []ResourceDef{userDef, featureDef("v1"), featureDef("v2")}); err != nil {
+		t.Fatalf("Failed to create resources: %s", err)
+	}
+
+	ids := []NameVariant{{Name: "feature", Variant: "v1"}, {Name: "feature", Variant: "v2"}}
+	variants, errs, err := client.BatchGetFeatureVariants(ctx, ids)
+	if err != nil {
+		t.Fatalf("Failed to batch get feature variants: %s", err)
+	}
+	if len(errs) != 0 {
+		t.Fatalf("Expected no errors, got %v", errs)
+	}
+	if len(variants) != len(ids) {
+		t.Fatalf("Expected %d variants, got %d", len(ids), len(variants))
+	}
+	for i, variant := range variants {
+		if variant.Name() != ids[i].Name || variant.Variant() != ids[i].Variant {
+			t.Fatalf("Expected %v, got %s (%s)", ids[i], variant.Name(), variant.Variant())
+		}
+	}
+
+	missing := NameVariant{Name: "feature", Variant: "missing"}
+	variants, errs, err = client.BatchGetFeatureVariants(ctx, []NameVariant{ids[0], missing, ids[1]})
+	if err != nil {
+		t.Fatalf("Expected a partial result, got error: %s", err)
+	}
+	if len(variants) != 2 {
+		t.Fatalf("Expected 2 variants, got %d", len(variants))
+	}
+	if len(errs) != 1 || errs[missing] == nil {
+		t.Fatalf("Expected an error for %v, got %v", missing, errs)
+	}
+	if errType := fferr.FromErr(errs[missing]).GetType(); errType != fferr.KEY_NOT_FOUND {
+		t.Fatalf("Expected %s error, got %s", fferr.KEY_NOT_FOUND, errType)
+	}
+}
+
+func Test_GetDefaultVariant(t *testing.T) {
+	_, ctx, logger := logging.InitializeTestRequestID(t)
+	_, addr := startServNoPanic(t, ctx, logger)
+	client := client(t, ctx, logger, addr)
+
+	defs := []ResourceDef{UserDef{
+		Name:       "Featureform",
+		Tags:       Tags{},
+		Properties: Properties{},
+	}}
+	for _, variant := range []string{"v1", "v2"} {
+		defs = append(defs, FeatureDef{
+			Name:        "feature",
+			Variant:     variant,
+			Description: "On-demand feature",
+			Owner:       "Featureform",
+			Location: PythonFunction{
+				Query: []byte(PythonFunc),
+			},
+			Tags:       Tags{},
+			Properties: Properties{},
+			Mode:       CLIENT_COMPUTED,
+			IsOnDemand: true,
+		})
+	}
+	if err := client.CreateAll(ctx, defs); err != nil {
+		t.Fatalf("Failed to create resources: %s", err)
+	}
+
+	for _, resType := range []ResourceType{FEATURE, FEATURE_VARIANT} {
+		variant, err := client.GetDefaultVariant(ctx, "feature", resType)
+		if err != nil {
+			t.Fatalf("Failed to get default variant for %s: %s", resType, err)
+		}
+		if variant != "v2" {
+			t.Fatalf("Expected default variant v2 for %s, got %s", resType, variant)
+		}
+	}
+	if _, err := client.GetDefaultVariant(ctx, "missing", FEATURE); err == nil {
+		t.Fatalf("Expected an error getting the default variant of a missing feature")
+	}
+	if _, err := client.GetDefaultVariant(ctx, "Featureform", USER); err == nil {
+		t.Fatalf("Expected an error getting the default variant of a user")
+	}
+
+	variants, err := client.GetFeatureVariants(ctx, []NameVariant{{Name: "feature"}})
+	if err != nil {
+		t.Fatalf("Failed to get feature variant without a variant: %s", err)
+	}
+	if variants[0].Variant() != "v2" {
+		t.Fatalf("Expected an empty variant to resolve to v2, got %s", variants[0].Variant())
+	}
+}
+
+func Test_ListVariants(t *testing.T) {
+	_, ctx, logger := logging.InitializeTestRequestID(t)
+	_, addr := startServNoPanic(t, ctx, logger)
+	client := client(t, ctx, logger, addr)
+
+	if err := client.CreateAll(ctx, []ResourceDef{UserDef{
+		Name:       "Featureform",
+		Tags:       Tags{},
+		Properties: Properties{},
+	}}); err != nil {
+		t.Fatalf("Failed to create user: %s", err)
+	}
+	for _, variant := range []string{"v1", "v2", "v3"} {
+		def := FeatureDef{
+			Name:        "feature",
+			Variant:     variant,
+			Description: "On-demand feature",
+			Owner:       "Featureform",
+			Location: PythonFunction{
+				Query: []byte(PythonFunc),
+			},
+			Tags:       Tags{},
+			Properties: Properties{},
+			Mode:       CLIENT_COMPUTED,
+			IsOnDemand: true,
+		}
+		if err := client.CreateAll(ctx, []ResourceDef{def}); err != nil {
+			t.Fatalf("Failed to create feature variant %s: %s", variant, err)
+		}
+	}
+	if err := client.ArchiveResourceVariant(ctx, ResourceID{Name: "feature", Variant: "v1", Type: FEATURE_VARIANT}); err != nil {
+		t.Fatalf("Failed to archive feature variant: %s", err)
+	}
+
+	for _, resType := range []ResourceType{FEATURE, FEATURE_VARIANT} {
+		variants, err := client.ListVariants(ctx, "feature", resType)
+		if err != nil {
+			t.Fatalf("Failed to list variants for %s: %s", resType, err)
+		}
+		names := make([]string, len(variants))
+		for i, variant := range variants {
+			names[i] = variant.Variant()
+			if variant.Created().IsZero() {
+				t.Fatalf("Expected variant %s to have a created time", variant.Variant())
+			}
+		}
+		if !reflect.DeepEqual(names, []string{"v3", "v2"}) {
+			t.Fatalf("Expected unarchived variants newest first, got %v", names)
+		}
+		withArchived, err := client.ListVariantsWithArchived(ctx, "feature", resType)
+		if err != nil {
+			t.Fatalf("Failed to list variants with archived for %s: %s", resType, err)
+		}
+		if len(withArchived) != 3 || withArchived[2].Variant() != "v1" {
+			t.Fatalf("Expected v1 to be listed last with archived variants, got %d variants", len(withArchived))
+		}
+		if !withArchived[2].IsArchived() || withArchived[0].IsArchived() {
+			t.Fatalf("Expected only v1 to be archived")
+		}
+	}
+	if _, err := client.ListVariants(ctx, "missing", FEATURE); err == nil {
+		t.Fatalf("Expected an error listing the variants of a missing feature")
+	}
+	if _, err := client.ListVariants(ctx, "Featureform", USER); err == nil {
+		t.Fatalf("Expected an error listing the variants of a user")
+	}
+}
+
+func Test_ValidateSQLTransformation(t *testing.T) {
+	ctx := logging.NewTestContext(t)
+	lookup := LocalResourceLookup{
+		ResourceID{Name: "spark", Type: PROVIDER}:    &providerResource{&pb.Provider{Name: "spark", Type: string(pt.SparkOffline)}},
+		ResourceID{Name: "postgres", Type: PROVIDER}: &providerResource{&pb.Provider{Name: "postgres", Type: string(pt.PostgresOffline)}},
+	}
+	serv := &MetadataServer{lookup: lookup}
+	transformation := func(provider, query string) *pb.SourceVariant {
+		return &pb.SourceVariant{
+			Name:     "transactions",
+			Variant:  "v1",
+			Provider: provider,
+			Definition: &pb.SourceVariant_Transformation{
+				Transformation: &pb.Transformation{
+					Type: &pb.Transformation_SQLTransformation{
+						SQLTransformation: &pb.SQLTransformation{
+							Query:  query,
+							Source: []*pb.NameVariant{{Name: "raw", Variant: "v1"}},
+						},
+					},
+				},
+			},
+		}
+	}
+	tests := map[string]struct {
+		variant *pb.SourceVariant
+		valid   bool
+	}{
+		"Valid":              {transformation("postgres", "SELECT * FROM {{ raw.v1 }}"), true},
+		"Spark Escape":       {transformation("spark", `SELECT 'it\'s' FROM {{ raw.v1 }}`), true},
+		"Postgres Escape":    {transformation("postgres", `SELECT 'it\'s' FROM {{ raw.v1 }}`), false},
+		"Unresolved":         {transformation("postgres", "SELECT * FROM {{ raw.v1 }} JOIN {{ other.v1 }}"), false},
+		"Unbalanced":         {transformation("postgres", "SELECT COUNT(* FROM {{ raw.v1 }}"), false},
+		"Not Select":         {transformation("postgres", "DROP TABLE {{ raw.v1 }}"), false},
+		"Empty":              {transformation("postgres", " "), false},
+		"Primary Is Skipped": {&pb.SourceVariant{Name: "raw", Variant: "v1"}, true},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := serv.validateSQLTransformation(ctx, test.variant)
+			if test.valid && err != nil {
+				t.Fatalf("Expected query to be valid: %s", err)
+			}
+			if !test.valid && err == nil {
+				t.Fatalf("Expected query to be invalid")
+			}
+		})
+	}
+}
+
+func Test_ValidateFeatureDefaultValue(t *testing.T) {
+	tests := map[string]struct {
+		variant *pb.FeatureVariant
+		valid   bool
+	}{
+		"Matching Type":    {&pb.FeatureVariant{Type: types.Float64.ToProto(), DefaultValue: "0.5"}, true},
+		"Mismatched Type":  {&pb.FeatureVariant{Type: types.Int.ToProto(), DefaultValue: `"zero"`}, false},
+		"Untyped":          {&pb.FeatureVariant{Type: types.NilType.ToProto(), DefaultValue: "0"}, false},
+		"Client Computed":  {&pb.FeatureVariant{Type: types.Int.ToProto(), DefaultValue: "0", Mode: pb.ComputationMode_CLIENT_COMPUTED}, false},
+		"Invalid Encoding": {&pb.FeatureVariant{Type: types.String.ToProto(), DefaultValue: "unknown"}, false},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := validateFeatureDefaultValue(test.variant)
+			if test.valid && err != nil {
+				t.Fatalf("Expected default value %s to be valid: %s", test.variant.DefaultValue, err)
+			}
+			if !test.valid && err == nil {
+				t.Fatalf("Expected default value %s to be invalid", test.variant.DefaultValue)
+			}
+		})
+	}
+}
+
+func Test_ColumnCastsRoundTrip(t *testing.T) {
+	casts := map[string]types.ScalarType{"entity": types.String, "value": types.Int}
+	featureReq, err := FeatureDef{
+		Type:        types.Int,
+		Location:    ResourceVariantColumns{Entity: "entity", Value: "value"},
+		ColumnCasts: casts,
+	}.Serialize("")
+	if err != nil {
+		t.Fatalf("Failed to serialize feature: %s", err)
+	}
+	featureCasts, err := WrapProtoFeatureVariant(featureReq.FeatureVariant).ColumnCasts()
+	if err != nil {
+		t.Fatalf("Failed to parse feature column casts: %s", err)
+	}
+	labelReq, err := LabelDef{
+		Type:        types.Int,
+		Location:    ResourceVariantColumns{Entity: "entity", Value: "value"},
+		ColumnCasts: casts,
+	}.Serialize("")
+	if err != nil {
+		t.Fatalf("Failed to serialize label: %s", err)
+	}
+	labelCasts, err := WrapProtoLabelVariant(labelReq.LabelVariant).ColumnCasts()
+	if err != nil {
+		t.Fatalf("Failed to parse label column casts: %s", err)
+	}
+	if !reflect.DeepEqual(featureCasts, casts) || !reflect.DeepEqual(labelCasts, casts) {
+		t.Fatalf("Expected casts %v, got %v and %v", casts, featureCasts, labelCasts)
+	}
+	if _, err := (FeatureDef{Location: ResourceVariantColumns{}, ColumnCasts: map[string]types.ScalarType{"value": "unknown"}}).Serialize(""); err == nil {
+		t.Fatalf("Expected an unknown cast type to fail")
+	}
+}
+
+func Test_TimestampFormatRoundTrip(t *testing.T) {
+	format := &TimestampFormat{Encoding: "iso8601", Timezone: "America/New_York"}
+	location := ResourceVariantColumns{Entity: "entity", Value: "value", TS: "ts"}
+	featureReq, err := FeatureDef{Type: types.Int, Location: location, TimestampFormat: format}.Serialize("")
+	if err != nil {
+		t.Fatalf("Failed to serialize feature: %s", err)
+	}
+	labelReq, err := LabelDef{Type: types.Int, Location: location, TimestampFormat: format}.Serialize("")
+	if err != nil {
+		t.Fatalf("Failed to serialize label: %s", err)
+	}
+	featureFormat := WrapProtoFeatureVariant(featureReq.FeatureVariant).TimestampFormat()
+	labelFormat := WrapProtoLabelVariant(labelReq.LabelVariant).TimestampFormat()
+	if !reflect.DeepEqual(featureFormat, format) || !reflect.DeepEqual(labelFormat, format) {
+		t.Fatalf("Expected format %v, got %v and %v", format, featureFormat, labelFormat)
+	}
+	unset, err := FeatureDef{Type: types.Int, Location: location}.Serialize("")
+	if err != nil {
+		t.Fatalf("Failed to serialize feature: %s", err)
+	}
+	if parsed := WrapProtoFeatureVariant(unset.FeatureVariant).TimestampFormat(); parsed != nil {
+		t.Fatalf("Expected no timestamp format, got %v", parsed)
+	}
+}
+
+func Test_MaterializationPartitionRoundTrip(t *testing.T) {
+	partition := &MaterializationPartition{Type: "ENTITY_BUCKET", Buckets: 16}
+	location := ResourceVariantColumns{Entity: "entity", Value: "value"}
+	req, err := FeatureDef{Type: types.Int, Location: location, MaterializationPartition: partition}.Serialize("")
+	if err != nil {
+		t.Fatalf("Failed to serialize feature: %s", err)
+	}
+	if parsed := WrapProtoFeatureVariant(req.FeatureVariant).MaterializationPartition(); !reflect.DeepEqual(parsed, partition) {
+		t.Fatalf("Expected partition %v, got %v", partition, parsed)
+	}
+	unset, err := FeatureDef{Type: types.Int, Location: location}.Serialize("")
+	if err != nil {
+		t.Fatalf("Failed to serialize feature: %s", err)
+	}
+	if parsed := WrapProtoFeatureVariant(unset.FeatureVariant).MaterializationPartition(); parsed != nil {
+		t.Fatalf("Expected no partition, got %v", parsed)
+	}
+}
+
+func Test_SourceVariantSparkSubmitOptions(t *testing.T) {
+	serialized := &pb.SourceVariant{
+		Definition: &pb.SourceVariant_Transformation{
+			Transformation: &pb.Transformation{
+				Type: &pb.Transformation_SQLTransformation{
+					SQLTransformation: &pb.SQLTransformation{Query: "SELECT * FROM {{ source.v1 }}"},
+				},
+				SparkFlags: &pb.SparkFlags{
+					SubmitConfigs:  map[string]string{"spark.sql.shuffle.partitions": "400"},
+					SubmitPackages: []string{"org.apache.spark:spark-avro_2.12:3.5.0"},
+				},
+			},
+		},
+	}
+	expected := pc.SparkSubmitOptions{
+		Configs:  map[string]string{"spark.sql.shuffle.partitions": "400"},
+		Packages: []string{"org.apache.spark:spark-avro_2.12:3.5.0"},
+	}
+	if opts := WrapProtoSourceVariant(serialized).SparkSubmitOptions(); !reflect.DeepEqual(opts, expected) {
+		t.Fatalf("Expected %v, got %v", expected, opts)
+	}
+	primary := &pb.SourceVariant{Definition: &pb.SourceVariant_PrimaryData{PrimaryData: &pb.PrimaryData{}}}
+	if opts := WrapProtoSourceVariant(primary).SparkSubmitOptions(); !opts.IsEmpty() {
+		t.Fatalf("Expected no options for primary data, got %v", opts)
+	}
+}
+
+func Test_SQLTransformationIncrementalColumn(t *testing.T) {
+	definition, err := TransformationSource{
+		TransformationType: SQLTransformationType{
+			Query:             "SELECT * FROM {{ events.v1 }}",
+			Sources:           NameVariants{{Name: "events", Variant: "v1"}},
+			IncrementalColumn: "event_ts",
+		},
+	}.Serialize()
+	if err != nil {
+		t.Fatalf("Failed to serialize transformation: %s", err)
+	}
+	variant := WrapProtoSourceVariant(&pb.SourceVariant{Definition: definition})
+	if column := variant.SQLTransformationIncrementalColumn(); column != "event_ts" {
+		t.Fatalf("Expected incremental column event_ts, got %q", column)
+	}
+}
+
+func Test_ValidateMultiLabelTrainingSet(t *testing.T) {
+	ctx := logging.NewTestContext(t)
+	label := func(name, entity string) *labelVariantResource {
+		return &labelVariantResource{&pb.LabelVariant{
+			Name:     name,
+			Variant:  "v1",
+			Entity:   entity,
+			Location: &pb.LabelVariant_Columns{Columns: &pb.Columns{Entity: entity, Value: "value"}},
+		}}
+	}
+	lookup := LocalResourceLookup{
+		ResourceID{Name: "churned", Variant: "v1", Type: LABEL_VARIANT}:  label("churned", "user"),
+		ResourceID{Name: "upgraded", Variant: "v1", Type: LABEL_VARIANT}: label("upgraded", "user"),
+		ResourceID{Name: "refunded", Variant: "v1", Type: LABEL_VARIANT}: label("refunded", "order"),
+		ResourceID{Name: "spend", Variant: "v1", Type: FEATURE_VARIANT}: &featureVariantResource{&pb.FeatureVariant{
+			Name:    "spend",
+			Variant: "v1",
+			Entity:  "user",
+			Mode:    pb.ComputationMode_PRECOMPUTED,
+		}},
+	}
+	trainingSet := func(labels ...string) *trainingSetVariantResource {
+		def := TrainingSetDef{Name: "multi_task", Variant: "v1", Features: NameVariants{{Name: "spend", Variant: "v1"}}}
+		for _, name := range labels {
+			def.Labels = append(def.Labels, NameVariant{Name: name, Variant: "v1"})
+		}
+		return &trainingSetVariantResource{def.Serialize("").TrainingSetVariant}
+	}
+	tests := map[string]struct {
+		resource *trainingSetVariantResource
+		valid    bool
+	}{
+		"Shared Entity":    {trainingSet("churned", "upgraded"), true},
+		"Different Entity": {trainingSet("churned", "refunded"), false},
+		"Duplicate Label":  {trainingSet("churned", "churned"), false},
+		"Missing Label":    {trainingSet("churned", "missing"), false},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := test.resource.Validate(ctx, lookup)
+			if test.valid && err != nil {
+				t.Fatalf("Expected training set to be valid: %s", err)
+			}
+			if !test.valid && err == nil {
+				t.Fatalf("Expected training set to be invalid")
+			}
+		})
+	}
+	mismatched := trainingSet("churned", "upgraded")
+	mismatched.serialized.Label = &pb.NameVariant{Name: "upgraded", Variant: "v1"}
+	if err := mismatched.Validate(ctx, lookup); err == nil {
+		t.Fatalf("Expected a label that isn't the first of the labels to be invalid")
+	}
+	labels := WrapProtoTrainingSetVariant(trainingSet("churned", "upgraded").serialized).Labels()
+	if expected := (NameVariants{{"churned", "v1"}, {"upgraded", "v1"}}); !reflect.DeepEqual(labels, expected) {
+		t.Fatalf("Expected labels %v, got %v", expected, labels)
+	}
+}
+
+func Test_TrainingSetPersistAsRoundTrip(t *testing.T) {
+	persist := &TrainingSetPersistAs{Table: "fraud_training", Overwrite: true}
+	serialized := TrainingSetDef{PersistAs: persist}.Serialize("")
+	if parsed := WrapProtoTrainingSetVariant(serialized.TrainingSetVariant).PersistAs(); !reflect.DeepEqual(parsed, persist) {
+		t.Fatalf("Expected %v, got %v", persist, parsed)
+	}
+	unset := TrainingSetDef{}.Serialize("")
+	if parsed := WrapProtoTrainingSetVariant(unset.TrainingSetVariant).PersistAs(); parsed != nil {
+		t.Fatalf("Expected no persisted table, got %v", parsed)
+	}
+	resource := &trainingSetVariantResource{&pb.TrainingSetVariant{PersistAs: &pb.TrainingSetPersistAs{Table: " "}}}
+	if err := resource.Validate(context.Background(), nil); err == nil {
+		t.Fatalf("Expected a persisted training set without a table to be invalid")
+	}
+}
+
+func Test_TrainingSetSplitRoundTrip(t *testing.T) {
+	split := &TrainingSetSplit{TrainFraction: 0.8, Seed: 42}
+	serialized := TrainingSetDef{Split: split}.Serialize("")
+	if parsed := WrapProtoTrainingSetVariant(serialized.TrainingSetVariant).Split(); !reflect.DeepEqual(parsed, split) {
+		t.Fatalf("Expected %v, got %v", split, parsed)
+	}
+	unset := TrainingSetDef{}.Serialize("")
+	if parsed := WrapProtoTrainingSetVariant(unset.TrainingSetVariant).Split(); parsed != nil {
+		t.Fatalf("Expected no split, got %v", parsed)
+	}
+	resource := &trainingSetVariantResource{&pb.TrainingSetVariant{Split: &pb.TrainingSetSplit{TrainFraction: 1}}}
+	if err := resource.Validate(context.Background(), nil); err == nil {
+		t.Fatalf("Expected a split without a test fraction to be invalid")
+	}
+}
+
+type recordingEventSink struct {
+	mu     sync.Mutex
+	events []events.Event
+}
+
+func (s *recordingEventSink) Publish(ctx context.Context, event events.Event) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.events = append(s.events, event)
+	return nil
+}
+
+func (s *recordingEventSink) Close() error {
+	return nil
+}
+
+func Test_ReapplyEmitsUpdateOnlyOnChange(t *testing.T) {
+	_, ctx, logger := logging.InitializeTestRequestID(t)
+	serv, addr := startServNoPanic(t, ctx, logger)
+	sink := &recordingEventSink{}
+	serv.events = events.NewEmitter(sink, 0, logger)
+	client := client(t, ctx, logger, addr)
+
+	userDef := UserDef{Name: "Featureform", Tags: Tags{"team"}, Properties: Properties{}}
+	for i := 0; i < 2; i++ {
+		if err := client.CreateUser(ctx, userDef); err != nil {
+			t.Fatalf("Failed to apply user: %s", err)
+		}
+	}
+	userDef.Tags = Tags{"team", "owner"}
+	if err := client.CreateUser(ctx, userDef); err != nil {
+		t.Fatalf("Failed to re-apply user with a new tag: %s", err)
+	}
+	if err := serv.events.Close(); err != nil {
+		t.Fatalf("Failed to close emitter: %s", err)
+	}
+	ops := make([]events.Operation, len(sink.events))
+	for i, event := range sink.events {
+		ops[i] = event.Operation
+	}
+	expected := []events.Operation{events.CreateOperation, events.UpdateOperation}
+	if !reflect.DeepEqual(ops, expected) {
+		t.Fatalf("Expected events %v, got %v", expected, ops)
+	}
+}
+
+func Test_VariantIsArchived(t *testing.T) {
+	archived := []interface{ IsArchived() bool }{
+		WrapProtoFeatureVariant(&pb.FeatureVariant{Archived: true}),
+		WrapProtoLabelVariant(&pb.LabelVariant{Archived: true}),
+		WrapProtoTrainingSetVariant(&pb.TrainingSetVariant{Archived: true}),
+		WrapProtoSourceVariant(&pb.SourceVariant{Archived: true}),
+	}
+	for _, variant := range archived {
+		if !variant.IsArchived() {
+			t.Fatalf("Expected %T to be archived", variant)
+		}
+	}
+	if WrapProtoFeatureVariant(&pb.FeatureVariant{}).IsArchived() {
+		t.Fatalf("Expected an unarchived feature variant")
+	}
+}
+
+func Test_FeatureMaterializationFilterCheckedAtRegistration(t *testing.T) {
+	_, ctx, logger := logging.InitializeTestRequestID(t)
+	_, addr := startServNoPanic(t, ctx, logger)
+	client := client(t, ctx, logger, addr)
+
+	userDef := UserDef{Name: "Featureform", Tags: Tags{}, Properties: Properties{}}
+	if err := client.CreateUser(ctx, userDef); err != nil {
+		t.Fatalf("Failed to create user: %s", err)
+	}
+	featureDef := FeatureDef{
+		Name:                  "feature",
+		Variant:               "v1",
+		Owner:                 "Featureform",
+		Location:              PythonFunction{Query: []byte(PythonFunc)},
+		Tags:                  Tags{},
+		Properties:            Properties{},
+		Mode:                  CLIENT_COMPUTED,
+		IsOnDemand:            true,
+		Type:                  types.Int,
+		MaterializationFilter: "status = 'active'; DROP TABLE users",
+	}
+	err := client.CreateFeatureVariant(ctx, featureDef)
+	if err == nil {
+		t.Fatalf("Expected an invalid materialization filter to be rejected")
+	}
+	if !strings.Contains(err.Error(), "materialization filter") {
+		t.Fatalf("Expected error to mention the materialization filter, got: %s", err)
+	}
+	if _, err := client.GetFeatureVariant(ctx, NameVariant{Name: "feature", Variant: "v1"}); err == nil {
+		t.Fatalf("Expected rejected variant not to be created")
+	}
+}
+
+func Test_FeatureTypeChanges(t *testing.T) {
+	_, ctx, logger := logging.InitializeTestRequestID(t)
+	_, addr := startServNoPanic(t, ctx, logger)
+	client := client(t, ctx, logger, addr)
+
+	featureDef := func(variant string, valueType types.ValueType) FeatureDef {
+		return FeatureDef{
+			Name:        "feature",
+			Variant:     variant,
+			Description: "On-demand feature",
+			Owner:       "Featureform",
+			Location: PythonFunction{
+				Query: []byte(PythonFunc),
+			},
+			Tags:       Tags{},
+			Properties: Properties{},
+			Mode:       CLIENT_COMPUTED,
+			IsOnDemand: true,
+			Type:       valueType,
+		}
+	}
+	userDef := UserDef{Name: "Featureform", Tags: Tags{}, Properties: Properties{}}
+	if err := client.CreateAll(ctx, []ResourceDef{userDef, featureDef("v1", types.Int32), featureDef("v2", types.Int64)}); err != nil {
+		t.Fatalf("Failed to create backward compatible variants: %s", err)
+	}
+
+	breaking := featureDef("v3", types.String)
+	err := client.CreateFeatureVariant(ctx, breaking)
+	if err == nil {
+		t.Fatalf("Expected int64 to string to be rejected")
+	}
+	if !strings.Contains(err.Error(), "allow_breaking") {
+		t.Fatalf("Expected error to mention allow_breaking, got: %s", err)
+	}
+	if _, err := client.GetFeatureVariant(ctx, NameVariant{Name: "feature", Variant: "v3"}); err == nil {
+		t.Fatalf("Expected rejected variant not to be created")
+	}
+
+	breaking.AllowBreaking = true
+	if err := client.CreateFeatureVariant(ctx, breaking); err != nil {
+		t.Fatalf("Failed to create variant with allowed breaking change: %s", err)
+	}
+	feature, err := client.GetFeature(ctx, "feature")
+	if err != nil {
+		t.Fatalf("Failed to get feature: %s", err)
+	}
+	if feature.DefaultVariant() != "v3" {
+		t.Fatalf("Expected default variant v3, got %s", feature.DefaultVariant())
+	}
+	changes := feature.TypeChanges()
+	if len(changes) != 2 {
+		t.Fatalf("Expected 2 type changes, got %v", changes)
+	}
+	expected := []struct {
+		from, to         string
+		fromType, toType types.ValueType
+		breaking         bool
+	}{
+		{"v1", "v2", types.Int32, types.Int64, false},
+		{"v2", "v3", types.Int64, types.String, true},
+	}
+	for i, change := range changes {
+		fromType, _ := types.ValueTypeFromProto(change.FromType)
+		toType, _ := types.ValueTypeFromProto(change.ToType)
+		exp := expected[i]
+		if change.FromVariant != exp.from || change.ToVariant != exp.to || fromType != exp.fromType || toType != exp.toType || change.Breaking != exp.breaking {
+			t.Fatalf("Expected type change %d to be %v, got %v", i, exp, change)
+		}
+	}
+}
+
+func Test_ListPagination(t *testing.T) {
+	_, ctx, logger := logging.InitializeTestRequestID(t)
+	_, addr := startServNoPanic(t, ctx, logger)
+	client := client(t, ctx, logger, addr)
+
+	defs := []ResourceDef{UserDef{
+		Name:       "Featureform",
+		Tags:       Tags{},
+		Properties: Properties{},
+	}}
+	// Created out of order to check that pages are ordered by name.
+	for _, name := range []string{"feature_c", "feature_a", "feature_e", "feature_b", "feature_d"} {
+		defs = append(defs, FeatureDef{
+			Name:        name,
+			Variant:     "variant",
+			Description: "On-demand feature",
+			Owner:       "Featureform",
+			Location: PythonFunction{
+				Query: []byte(PythonFunc),
+			},
+			Tags:       Tags{},
+			Properties: Properties{},
+			Mode:       CLIENT_COMPUTED,
+			IsOnDemand: true,
+		})
+	}
+	if err := client.CreateAll(ctx, defs); err != nil {
+		t.Fatalf("Failed to create resources: %s", err)
+	}
+
+	expectedPages := [][]string{
+		{"feature_a", "feature_b"},
+		{"feature_c", "feature_d"},
+		{"feature_e"},
+	}
+	token := ""
+	for i, expected := range expectedPages {
+		features, nextToken, err := client.ListFeaturesPage(ctx, 2, token)
+		if err != nil {
+			t.Fatalf("Failed to list page %d: %s", i, err)
+		}
+		names := make([]string, len(features))
+		for j, feature := range features {
+			names[j] = feature.Name()
+		}
+		if !reflect.DeepEqual(names, expected) {
+			t.Fatalf("Page %d: expected %v, got %v", i, expected, names)
+		}
+		isLastPage := i == len(expectedPages)-1
+		if isLastPage != (nextToken == "") {
+			t.Fatalf("Page %d: unexpected next page token %q", i, nextToken)
+		}
+		token = nextToken
+	}
+
+	all, err := client.ListFeatures(ctx)
+	if err != nil {
+		t.Fatalf("Failed to list features: %s", err)
+	}
+	if len(all) != 5 {
+		t.Fatalf("Expected an unpaginated list of 5 features, got %d", len(all))
+	}
+	if _, _, err := client.ListFeaturesPage(ctx, 2, "not-a-token"); err == nil {
+		t.Fatalf("Expected error for an invalid page token")
+	}
+	if _, _, err := client.ListFeaturesPage(ctx, -1, ""); err == nil {
+		t.Fatalf("Expected error for a negative page size")
+	}
+	_, featureToken, err := client.ListFeaturesPage(ctx, 2, "")
+	if err != nil {
+		t.Fatalf("Failed to list first page: %s", err)
+	}
+	if _, _, err := client.ListLabelsPage(ctx, 2, featureToken); err == nil {
+		t.Fatalf("Expected error for a page token from another resource type")
+	}
+
+	// Archived resources are skipped before pages are filled, so pages stay full.
+	archived := ResourceID{Name: "feature_b", Variant: "variant", Type: FEATURE_VARIANT}
+	if err := client.ArchiveResourceVariant(ctx, archived); err != nil {
+		t.Fatalf("Failed to archive feature: %s", err)
+	}
+	expectedPages = [][]string{
+		{"feature_a", "feature_c"},
+		{"feature_d", "feature_e"},
+	}
+	token = ""
+	for i, expected := range expectedPages {
+		features, nextToken, err := client.ListFeaturesPage(ctx, 2, token)
+		if err != nil {
+			t.Fatalf("Failed to list page %d: %s", i, err)
+		}
+		names := make([]string, len(features))
+		for j, feature := range features {
+			names[j] = feature.Name()
+		}
+		if !reflect.DeepEqual(names, expected) {
+			t.Fatalf("Page %d: expected %v, got %v", i, expected, names)
+		}
+		isLastPage := i == len(expectedPages)-1
+		if isLastPage != (nextToken == "") {
+			t.Fatalf("Page %d: unexpected next page token %q", i, nextToken)
+		}
+		token = nextToken
+	}
+
+	// Pages start after the last name of the previous page, so resources
+	// created before it don't repeat or shift the rows that follow.
+	firstPage, token, err := client.ListFeaturesPage(ctx, 2, "")
+	if err != nil {
+		t.Fatalf("Failed to list first page: %s", err)
+	}
+	if len(firstPage) != 2 || firstPage[1].Name() != "feature_c" {
+		t.Fatalf("Expected the first page to end at feature_c, got %v", firstPage)
+	}
+	inserted := FeatureDef{
+		Name:        "feature_aa",
+		Variant:     "variant",
+		Description: "On-demand feature",
+		Owner:       "Featureform",
+		Location: PythonFunction{
+			Query: []byte(PythonFunc),
+		},
+		Tags:       Tags{},
+		Properties: Properties{},
+		Mode:       CLIENT_COMPUTED,
+		IsOnDemand: true,
+	}
+	if err := client.CreateAll(ctx, []ResourceDef{inserted}); err != nil {
+		t.Fatalf("Failed to create feature: %s", err)
+	}
+	secondPage, _, err := client.ListFeaturesPage(ctx, 2, token)
+	if err != nil {
+		t.Fatalf("Failed to list second page: %s", err)
+	}
+	names := make([]string, len(secondPage))
+	for i, feature := range secondPage {
+		names[i] = feature.Name()
+	}
+	if expected := []string{"feature_d", "feature_e"}; !reflect.DeepEqual(names, expected) {
+		t.Fatalf("Expected %v after a create, got %v", expected, names)
+	}
+}
@@ -0,0 +1,159 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+//
+// Copyright 2024 FeatureForm Inc.
+//
+
+package metadata
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/featureform/config"
+	"github.com/featureform/fferr"
+)
+
+// NamingRule identifies the rule a resource name or variant violated.
+type NamingRule string
+
+const (
+	BannedStringRule   NamingRule = "banned_string"
+	BannedPrefixRule   NamingRule = "banned_prefix"
+	BannedSuffixRule   NamingRule = "banned_suffix"
+	AllowedPatternRule NamingRule = "allowed_pattern"
+)
+
+// NamingRules are the rules every resource name and variant must follow.
+type NamingRules struct {
+	BannedStrings  []string
+	BannedPrefixes []string
+	BannedSuffixes []string
+	// AllowedPattern, if set, must match the whole of every name and variant.
+	AllowedPattern *regexp.Regexp
+}
+
+// DefaultNamingRules bans double underscores anywhere and underscores at
+// the start or end of names and variants.
+func DefaultNamingRules() NamingRules {
+	return NamingRules{
+		BannedStrings:  []string{"__"},
+		BannedPrefixes: []string{"_"},
+		BannedSuffixes: []string{"_"},
+	}
+}
+
+// NamingRulesFromEnv reads additional naming rules from the FF_BANNED_NAME_*
+// and FF_ALLOWED_NAME_PATTERN environment variables. Banned lists are comma
+// separated. The rules only add to DefaultNamingRules, which can't be removed
+// since table names are split on the banned strings.
+func NamingRulesFromEnv() (NamingRules, error) {
+	rules := NamingRules{}
+	lists := []struct {
+		env  string
+		list *[]string
+	}{
+		{config.EnvBannedNameStrings, &rules.BannedStrings},
+		{config.EnvBannedNamePrefixes, &rules.BannedPrefixes},
+		{config.EnvBannedNameSuffixes, &rules.BannedSuffixes},
+	}
+	for _, l := range lists {
+		*l.list = splitNamingList(os.Getenv(l.env))
+	}
+	if pattern := os.Getenv(config.EnvAllowedNamePattern); pattern != "" {
+		allowed, err := regexp.Compile(fmt.Sprintf("^(?:%s)$", pattern))
+		if err != nil {
+			return NamingRules{}, fferr.NewInvalidConfigEnv(config.EnvAllowedNamePattern, pattern, "a valid regular expression")
+		}
+		rules.AllowedPattern = allowed
+	}
+	return rules, nil
+}
+
+func splitNamingList(value string) []string {
+	list := make([]string, 0)
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	return list
+}
+
+// Extend returns the rules with the banned strings, prefixes and suffixes of
+// other added. The AllowedPattern of other is used if set.
+func (rules NamingRules) Extend(other NamingRules) NamingRules {
+	extended := NamingRules{
+		BannedStrings:  appendNamingList(rules.BannedStrings, other.BannedStrings),
+		BannedPrefixes: appendNamingList(rules.BannedPrefixes, other.BannedPrefixes),
+		BannedSuffixes: appendNamingList(rules.BannedSuffixes, other.BannedSuffixes),
+		AllowedPattern: rules.AllowedPattern,
+	}
+	if other.AllowedPattern != nil {
+		extended.AllowedPattern = other.AllowedPattern
+	}
+	return extended
+}
+
+func appendNamingList(list, other []string) []string {
+	appended := append([]string{}, list...)
+	for _, item := range other {
+		if !slices.Contains(appended, item) {
+			appended = append(appended, item)
+		}
+	}
+	return appended
+}
+
+// Validate returns an error naming the violated rule if the name or variant
+// of id breaks one of the rules.
+func (rules NamingRules) Validate(id ResourceID) error {
+	fields := []struct {
+		kind  string
+		value string
+	}{
+		{"name", id.Name},
+		{"variant", id.Variant},
+	}
+	for _, field := range fields {
+		if rule, value, violated := rules.violation(field.value); violated {
+			err := fferr.NewInvalidResourceVariantNameError(
+				id.Name, id.Variant, fferr.ResourceType(id.Type.String()),
+				fmt.Errorf("resource %s %s violates %s rule %q", field.kind, field.value, rule, value),
+			)
+			err.AddDetail("naming_rule", string(rule))
+			err.AddDetail("naming_rule_value", value)
+			return err
+		}
+	}
+	return nil
+}
+
+// violation returns the first rule s breaks and the value of that rule.
+func (rules NamingRules) violation(s string) (NamingRule, string, bool) {
+	for _, substr := range rules.BannedStrings {
+		if strings.Contains(s, substr) {
+			return BannedStringRule, substr, true
+		}
+	}
+	for _, prefix := range rules.BannedPrefixes {
+		if strings.HasPrefix(s, prefix) {
+			return BannedPrefixRule, prefix, true
+		}
+	}
+	for _, suffix := range rules.BannedSuffixes {
+		if strings.HasSuffix(s, suffix) {
+			return BannedSuffixRule, suffix, true
+		}
+	}
+	// Resources without variants, like entities and users, have an empty
+	// variant that the pattern doesn't apply to.
+	if rules.AllowedPattern != nil && s != "" && !rules.AllowedPattern.MatchString(s) {
+		return AllowedPatternRule, rules.AllowedPattern.String(), true
+	}
+	return "", "", false
+}
@@ -0,0 +1,104 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+//
+// Copyright 2024 FeatureForm Inc.
+//
+
+package metadata
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/featureform/config"
+	"github.com/featureform/fferr"
+)
+
+func TestNamingRulesValidate(t *testing.T) {
+	custom := NamingRules{
+		BannedStrings:  []string{"--"},
+		AllowedPattern: regexp.MustCompile(`^(?:[a-z_][a-z0-9_-]*)$`),
+	}
+	tests := map[string]struct {
+		rules NamingRules
+		id    ResourceID
+		rule  NamingRule
+		value string
+	}{
+		"Default Banned String":  {DefaultNamingRules(), ResourceID{"nam__e", "variant", FEATURE}, BannedStringRule, "__"},
+		"Default Banned Prefix":  {DefaultNamingRules(), ResourceID{"name", "_variant", FEATURE}, BannedPrefixRule, "_"},
+		"Default Banned Suffix":  {DefaultNamingRules(), ResourceID{"name_", "variant", FEATURE}, BannedSuffixRule, "_"},
+		"Custom Banned String":   {custom, ResourceID{"team--name", "variant", FEATURE}, BannedStringRule, "--"},
+		"Custom Allowed Pattern": {custom, ResourceID{"Name", "variant", FEATURE}, AllowedPatternRule, custom.AllowedPattern.String()},
+		"Custom Pattern Variant": {custom, ResourceID{"name", "v.1", FEATURE_VARIANT}, AllowedPatternRule, custom.AllowedPattern.String()},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := test.rules.Validate(test.id)
+			if err == nil {
+				t.Fatalf("expected %v to violate %s", test.id, test.rule)
+			}
+			details := err.(*fferr.InvalidResourceTypeError).Details()
+			if details["naming_rule"] != string(test.rule) || details["naming_rule_value"] != test.value {
+				t.Fatalf("expected rule %s %q, got %v", test.rule, test.value, details)
+			}
+		})
+	}
+
+	valid := map[string]struct {
+		rules NamingRules
+		id    ResourceID
+	}{
+		"Default":               {DefaultNamingRules(), ResourceID{"name", "variant", FEATURE}},
+		"Custom Leading Prefix": {custom, ResourceID{"_team_name_", "variant", FEATURE}},
+		"Custom No Variant":     {custom, ResourceID{"user", "", ENTITY}},
+		"No Rules":              {NamingRules{}, ResourceID{"__Any.Name__", "", ENTITY}},
+	}
+	for name, test := range valid {
+		t.Run(name, func(t *testing.T) {
+			if err := test.rules.Validate(test.id); err != nil {
+				t.Fatalf("expected %v to be valid: %v", test.id, err)
+			}
+		})
+	}
+}
+
+func TestNamingRulesFromEnv(t *testing.T) {
+	rules, err := NamingRulesFromEnv()
+	if err != nil {
+		t.Fatalf("could not read rules: %v", err)
+	}
+	if err := rules.Validate(ResourceID{"name", "", ENTITY}); err != nil {
+		t.Fatalf("expected no additional rules when no env is set: %v", err)
+	}
+
+	t.Setenv(config.EnvBannedNamePrefixes, "")
+	t.Setenv(config.EnvBannedNameStrings, "__, --")
+	t.Setenv(config.EnvAllowedNamePattern, "[a-z_-]+")
+	rules, err = NamingRulesFromEnv()
+	if err != nil {
+		t.Fatalf("could not read rules: %v", err)
+	}
+	rules = DefaultNamingRules().Extend(rules)
+	if err := rules.Validate(ResourceID{"_name", "", ENTITY}); err == nil {
+		t.Fatalf("expected an empty env to keep the default banned prefixes")
+	}
+	if err := rules.Validate(ResourceID{"na--me", "", ENTITY}); err == nil {
+		t.Fatalf("expected configured banned string to be rejected")
+	}
+	if err := rules.Validate(ResourceID{"na__me", "", ENTITY}); err == nil {
+		t.Fatalf("expected default banned string to be rejected")
+	}
+	if err := rules.Validate(ResourceID{"name1", "", ENTITY}); err == nil {
+		t.Fatalf("expected name outside of the allowed pattern to be rejected")
+	}
+	if len(rules.BannedStrings) != 2 {
+		t.Fatalf("expected banned strings to not repeat, got %v", rules.BannedStrings)
+	}
+
+	t.Setenv(config.EnvAllowedNamePattern, "[a-z")
+	if _, err := NamingRulesFromEnv(); err == nil {
+		t.Fatalf("expected an invalid pattern to fail")
+	}
+}
@@ -0,0 +1,225 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+//
+// Copyright 2024 FeatureForm Inc.
+//
+
+package metadata
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"google.golang.org/protobuf/proto"
+
+	"github.com/featureform/fferr"
+	"github.com/featureform/logging"
+	pb "github.com/featureform/metadata/proto"
+)
+
+// PlanAction is what applying a resource would do.
+type PlanAction int32
+
+const (
+	PlanCreate  PlanAction = PlanAction(pb.PlanAction_PLAN_ACTION_CREATE)
+	PlanUpdate  PlanAction = PlanAction(pb.PlanAction_PLAN_ACTION_UPDATE)
+	PlanNoOp    PlanAction = PlanAction(pb.PlanAction_PLAN_ACTION_NO_OP)
+	PlanInvalid PlanAction = PlanAction(pb.PlanAction_PLAN_ACTION_INVALID)
+)
+
+func (action PlanAction) String() string {
+	return pb.PlanAction(action).String()
+}
+
+// PlannedChange is the result of planning a single resource. Reason is set
+// when the resource would be rejected.
+type PlannedChange struct {
+	ID     ResourceID
+	Action PlanAction
+	Reason string
+}
+
+func (change PlannedChange) Proto() *pb.PlannedChange {
+	return &pb.PlannedChange{
+		ResourceId: change.ID.Proto(),
+		Action:     pb.PlanAction(change.Action),
+		Reason:     change.Reason,
+	}
+}
+
+func parsePlannedChange(change *pb.PlannedChange) PlannedChange {
+	return PlannedChange{
+		ID:     parseResourceIDProto(change.ResourceId),
+		Action: PlanAction(change.Action),
+		Reason: change.Reason,
+	}
+}
+
+func planResourceFromProto(res *pb.PlanResource) (Resource, error) {
+	switch casted := res.Resource.(type) {
+	case *pb.PlanResource_User:
+		return &userResource{casted.User}, nil
+	case *pb.PlanResource_Provider:
+		return &providerResource{casted.Provider}, nil
+	case *pb.PlanResource_Entity:
+		return &entityResource{casted.Entity}, nil
+	case *pb.PlanResource_SourceVariant:
+		return &sourceVariantResource{casted.SourceVariant}, nil
+	case *pb.PlanResource_FeatureVariant:
+		return &featureVariantResource{casted.FeatureVariant}, nil
+	case *pb.PlanResource_LabelVariant:
+		return &labelVariantResource{casted.LabelVariant}, nil
+	case *pb.PlanResource_TrainingSetVariant:
+		return &trainingSetVariantResource{casted.TrainingSetVariant}, nil
+	case *pb.PlanResource_Model:
+		return &modelResource{casted.Model}, nil
+	default:
+		return nil, fferr.NewInvalidArgumentErrorf("unsupported plan resource %T", casted)
+	}
+}
+
+// dependencyRecorder records the dependencies a resource asks for instead of
+// looking them up, so they can be checked against both storage and the
+// resources planned earlier in the same request.
+type dependencyRecorder struct {
+	ResourceLookup
+	ids []ResourceID
+}
+
+func (recorder *dependencyRecorder) Submap(ctx context.Context, ids []ResourceID) (ResourceLookup, error) {
+	recorder.ids = append(recorder.ids, ids...)
+	return make(LocalResourceLookup), nil
+}
+
+// Plan runs the checks that genericCreate does before writing a resource, for
+// each resource in the request, without persisting anything or creating
+// tasks. Fields the Create RPCs fill in on the server, like feature and
+// transformation locations, are skipped since they don't affect equivalence.
+func (serv *MetadataServer) Plan(ctx context.Context, req *pb.PlanRequest) (*pb.PlanResponse, error) {
+	ctx = logging.AttachRequestID(logging.RequestID(req.RequestId), ctx, serv.Logger)
+	logger := logging.GetLoggerFromContext(ctx)
+	logger.Infow("Planning resources", "count", len(req.Resources))
+	planned := make(map[ResourceID]struct{})
+	resp := &pb.PlanResponse{Changes: make([]*pb.PlannedChange, 0, len(req.Resources))}
+	for _, planRes := range req.Resources {
+		res, err := planResourceFromProto(planRes)
+		if err != nil {
+			return nil, err
+		}
+		change, err := serv.planResource(ctx, res, planned)
+		if err != nil {
+			logger.Errorw("Unable to plan resource", "resource_id", res.ID(), "error", err)
+			return nil, err
+		}
+		if change.Action != PlanInvalid {
+			planned[change.ID] = struct{}{}
+			if parentId, hasParent := change.ID.Parent(); hasParent {
+				planned[parentId] = struct{}{}
+			}
+		}
+		resp.Changes = append(resp.Changes, change.Proto())
+	}
+	return resp, nil
+}
+
+// planResource returns the change applying res would make. Validation failures
+// are returned as invalid changes; only errors reading storage fail the plan.
+func (serv *MetadataServer) planResource(ctx context.Context, res Resource, planned map[ResourceID]struct{}) (PlannedChange, error) {
+	id := res.ID()
+	logger := logging.GetLoggerFromContext(ctx).WithResource(id.Type.ToLoggingResourceType(), id.Name, id.Variant)
+	invalid := func(err error) (PlannedChange, error) {
+		logger.Debugw("Resource would be rejected", "error", err)
+		return PlannedChange{ID: id, Action: PlanInvalid, Reason: err.Error()}, nil
+	}
+	if err := serv.namingRules.Validate(id); err != nil {
+		return invalid(err)
+	}
+	existing, err := serv.lookup.Lookup(ctx, id)
+	if _, isKeyNotFoundErr := err.(*fferr.KeyNotFoundError); err != nil && !isKeyNotFoundErr {
+		return PlannedChange{}, err
+	}
+	if err := serv.authorizeCreate(ctx, res, existing); err != nil {
+		return invalid(err)
+	}
+	if existing != nil {
+		if err := serv.validateExisting(ctx, res, existing); err != nil {
+			return invalid(err)
+		}
+		before := proto.Clone(existing.Proto())
+		if err := existing.Update(serv.lookup, res); err != nil {
+			return invalid(err)
+		}
+		if proto.Equal(before, existing.Proto()) {
+			return PlannedChange{ID: id, Action: PlanNoOp}, nil
+		}
+		return PlannedChange{ID: id, Action: PlanUpdate}, nil
+	}
+	missing, err := serv.missingDependencies(ctx, res, planned)
+	if err != nil {
+		return PlannedChange{}, err
+	}
+	if len(missing) > 0 {
+		names := make([]string, len(missing))
+		for i, dep := range missing {
+			names[i] = dep.String()
+		}
+		return invalid(fferr.NewInvalidArgumentErrorf("missing dependencies: %s", strings.Join(names, ", ")))
+	}
+	if id.Type == FEATURE_VARIANT {
+		if _, err := serv.checkFeatureTypeChange(ctx, res); err != nil {
+			var invalidArg *fferr.InvalidArgumentError
+			if errors.As(err, &invalidArg) {
+				return invalid(err)
+			}
+			return PlannedChange{}, err
+		}
+	}
+	if tsRes, isTrainingSet := res.(*trainingSetVariantResource); isTrainingSet && !dependsOnPlanned(tsRes, planned) {
+		if err := tsRes.Validate(ctx, serv.lookup); err != nil {
+			return invalid(err)
+		}
+	}
+	return PlannedChange{ID: id, Action: PlanCreate}, nil
+}
+
+// missingDependencies returns the dependencies of res that neither exist nor
+// are planned. A variant's own parent is created along with it, so it's never
+// missing.
+func (serv *MetadataServer) missingDependencies(ctx context.Context, res Resource, planned map[ResourceID]struct{}) ([]ResourceID, error) {
+	recorder := &dependencyRecorder{ResourceLookup: serv.lookup}
+	if _, err := res.Dependencies(ctx, recorder); err != nil {
+		return nil, err
+	}
+	parentId, hasParent := res.ID().Parent()
+	missing := make([]ResourceID, 0)
+	for _, dep := range recorder.ids {
+		if _, isPlanned := planned[dep]; isPlanned || (hasParent && dep == parentId) {
+			continue
+		}
+		has, err := serv.lookup.Has(ctx, dep)
+		if err != nil {
+			return nil, err
+		}
+		if !has {
+			missing = append(missing, dep)
+		}
+	}
+	return missing, nil
+}
+
+// dependsOnPlanned returns true if a training set's labels or features are
+// only planned, in which case they can't be looked up to validate it.
+func dependsOnPlanned(res *trainingSetVariantResource, planned map[ResourceID]struct{}) bool {
+	ids := trainingSetLabelIDs(res.serialized)
+	for _, feature := range res.serialized.Features {
+		ids = append(ids, ResourceID{Name: feature.Name, Variant: feature.Variant, Type: FEATURE_VARIANT})
+	}
+	for _, dep := range ids {
+		if _, isPlanned := planned[dep]; isPlanned {
+			return true
+		}
+	}
+	return false
+}
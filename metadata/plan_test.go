@@ -0,0 +1,125 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+//
+// Copyright 2024 FeatureForm Inc.
+//
+
+package metadata
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/featureform/logging"
+	"github.com/featureform/provider/types"
+)
+
+func TestPlan(t *testing.T) {
+	_, ctx, logger := logging.InitializeTestRequestID(t)
+	_, addr := startServNoPanic(t, ctx, logger)
+	client := client(t, ctx, logger, addr)
+	defs := lineageTestDefs()
+
+	// Everything is new, and later resources can depend on earlier ones.
+	changes, err := client.Plan(ctx, defs)
+	if err != nil {
+		t.Fatalf("Failed to plan: %s", err)
+	}
+	if len(changes) != len(defs) {
+		t.Fatalf("Expected %d changes, got %v", len(defs), changes)
+	}
+	for i, change := range changes {
+		if change.ID != defs[i].ResourceID() || change.Action != PlanCreate {
+			t.Fatalf("Expected %v to be created, got %v", defs[i].ResourceID(), change)
+		}
+	}
+	sources, err := client.ListSources(ctx)
+	if err != nil {
+		t.Fatalf("Failed to list sources: %s", err)
+	}
+	if len(sources) != 0 {
+		t.Fatalf("Expected plan not to write anything, got %v", sources)
+	}
+
+	if err := client.CreateAll(ctx, defs); err != nil {
+		t.Fatalf("Failed to create resources: %s", err)
+	}
+	changes, err = client.Plan(ctx, defs)
+	if err != nil {
+		t.Fatalf("Failed to plan: %s", err)
+	}
+	for _, change := range changes {
+		if change.Action != PlanNoOp {
+			t.Fatalf("Expected reapplying %v to be a no-op, got %v", change.ID, change.Action)
+		}
+	}
+
+	feature := defs[4].(FeatureDef)
+	tagged := feature
+	tagged.Tags = Tags{"fraud"}
+	changed := feature
+	changed.Location = ResourceVariantColumns{Entity: "col1", Value: "col4", TS: "col3"}
+	missingSource := feature
+	missingSource.Variant = "v2"
+	missingSource.Source = NameVariant{Name: "missing", Variant: "v1"}
+	badName := feature
+	badName.Name = "_avg_amount"
+	changes, err = client.Plan(ctx, []ResourceDef{tagged, changed, missingSource, badName})
+	if err != nil {
+		t.Fatalf("Failed to plan: %s", err)
+	}
+	expected := []PlanAction{PlanUpdate, PlanInvalid, PlanInvalid, PlanInvalid}
+	for i, change := range changes {
+		if change.Action != expected[i] {
+			t.Fatalf("Expected change %d to be %v, got %v", i, expected[i], change)
+		}
+	}
+	if !strings.Contains(changes[2].Reason, "missing") {
+		t.Fatalf("Expected the missing source in the reason, got %s", changes[2].Reason)
+	}
+
+	features, err := client.GetFeatureVariants(ctx, []NameVariant{{Name: "avg_amount", Variant: "v1"}})
+	if err != nil {
+		t.Fatalf("Failed to get feature variant: %s", err)
+	}
+	if len(features[0].Tags()) != 0 {
+		t.Fatalf("Expected plan not to update tags, got %v", features[0].Tags())
+	}
+}
+
+func TestPlanFeatureTypeChange(t *testing.T) {
+	_, ctx, logger := logging.InitializeTestRequestID(t)
+	_, addr := startServNoPanic(t, ctx, logger)
+	client := client(t, ctx, logger, addr)
+	defs := lineageTestDefs()
+	feature := defs[4].(FeatureDef)
+	feature.Type = types.Int
+	defs[4] = feature
+	if err := client.CreateAll(ctx, defs); err != nil {
+		t.Fatalf("Failed to create resources: %s", err)
+	}
+
+	widened := feature
+	widened.Variant = "v2"
+	widened.Type = types.Int64
+	breaking := feature
+	breaking.Variant = "v3"
+	breaking.Type = types.String
+	allowed := breaking
+	allowed.Variant = "v4"
+	allowed.AllowBreaking = true
+	changes, err := client.Plan(ctx, []ResourceDef{widened, breaking, allowed})
+	if err != nil {
+		t.Fatalf("Failed to plan: %s", err)
+	}
+	expected := []PlanAction{PlanCreate, PlanInvalid, PlanCreate}
+	for i, change := range changes {
+		if change.Action != expected[i] {
+			t.Fatalf("Expected change %d to be %v, got %v", i, expected[i], change)
+		}
+	}
+	if !strings.Contains(changes[1].Reason, "backward compatible") {
+		t.Fatalf("Expected the type change in the reason, got %s", changes[1].Reason)
+	}
+}
@@ -73,6 +73,38 @@ func isValidFirestoreConfigUpdate(sa, sb pc.SerializedConfig) (bool, error) {
 	return a.MutableFields().Contains(diff), nil
 }
 
+func isValidBigtableConfigUpdate(sa, sb pc.SerializedConfig) (bool, error) {
+	a := pc.BigtableConfig{}
+	b := pc.BigtableConfig{}
+	if err := a.Deserialize(sa); err != nil {
+		return false, err
+	}
+	if err := b.Deserialize(sb); err != nil {
+		return false, err
+	}
+	diff, err := a.DifferingFields(b)
+	if err != nil {
+		return false, err
+	}
+	return a.MutableFields().Contains(diff), nil
+}
+
+func isValidKafkaConfigUpdate(sa, sb pc.SerializedConfig) (bool, error) {
+	a := pc.KafkaConfig{}
+	b := pc.KafkaConfig{}
+	if err := a.Deserialize(sa); err != nil {
+		return false, err
+	}
+	if err := b.Deserialize(sb); err != nil {
+		return false, err
+	}
+	diff, err := a.DifferingFields(b)
+	if err != nil {
+		return false, err
+	}
+	return a.MutableFields().Contains(diff), nil
+}
+
 func isValidMongoConfigUpdate(sa, sb pc.SerializedConfig) (bool, error) {
 	a := pc.MongoDBConfig{}
 	b := pc.MongoDBConfig{}
@@ -137,6 +169,22 @@ func isValidClickHouseConfigUpdate(sa, sb pc.SerializedConfig) (bool, error) {
 	return a.MutableFields().Contains(diff), nil
 }
 
+func isValidDatabricksSQLConfigUpdate(sa, sb pc.SerializedConfig) (bool, error) {
+	a := pc.DatabricksSQLConfig{}
+	b := pc.DatabricksSQLConfig{}
+	if err := a.Deserialize(sa); err != nil {
+		return false, err
+	}
+	if err := b.Deserialize(sb); err != nil {
+		return false, err
+	}
+	diff, err := a.DifferingFields(b)
+	if err != nil {
+		return false, err
+	}
+	return a.MutableFields().Contains(diff), nil
+}
+
 func isValidRedisConfigUpdate(sa, sb pc.SerializedConfig) (bool, error) {
 	a := pc.RedisConfig{}
 	b := pc.RedisConfig{}
@@ -146,6 +194,11 @@ func isValidRedisConfigUpdate(sa, sb pc.SerializedConfig) (bool, error) {
 	if err := b.Deserialize(sb); err != nil {
 		return false, err
 	}
+	if err := b.Validate(); err != nil {
+		return false, err
+	}
+	// An unset topology is standalone, so setting it explicitly isn't a change.
+	a.Topology, b.Topology = a.GetTopology(), b.GetTopology()
 	diff, err := a.DifferingFields(b)
 	if err != nil {
 		return false, err
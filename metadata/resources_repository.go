@@ -254,13 +254,23 @@ func (r *sqlResourcesRepository) MarkForDeletion(ctx context.Context, resourceID
 				return err
 			}
 
+			resId := ResourceID{Name: resourceID.Name, Variant: resourceID.Variant, Type: ResourceType(resourceID.Type)}
+			resource, err := r.Lookup(ctx, resId)
+			if err != nil {
+				logger.Errorw("error looking up resource", "error", err)
+				return err
+			}
+
 			status, err := r.getStatus(ctx, tx, resourceID, logger)
 			if err != nil {
 				logger.Errorw("error getting status", "error", err)
 				return err
 			}
 
-			if status != scheduling.READY && status != scheduling.CREATED {
+			// A streamed feature's run stays running for as long as it streams.
+			// Its stream stops once the feature is marked for deletion.
+			streaming := status == scheduling.RUNNING && r.isStreamed(ctx, resource)
+			if status != scheduling.READY && status != scheduling.CREATED && !streaming {
 				return retry.Unrecoverable(fferr.NewInternalErrorf(
 					"cannot delete resource %s %s (%s) because it is not in READY or CREATED status",
 					resourceID.Type, resourceID.Name, resourceID.Variant,
@@ -282,13 +292,6 @@ func (r *sqlResourcesRepository) MarkForDeletion(ctx context.Context, resourceID
 				return err
 			}
 
-			resId := ResourceID{Name: resourceID.Name, Variant: resourceID.Variant, Type: ResourceType(resourceID.Type)}
-			resource, err := r.Lookup(ctx, resId)
-			if err != nil {
-				logger.Errorw("error looking up resource", "error", err)
-				return err
-			}
-
 			if needsJob(resource) {
 				if err := deletionHandler(ctx, resId, logger); err != nil {
 					logger.Errorw("error executing deletion handler", "error", err)
@@ -458,6 +461,21 @@ func needsJob(res Resource) bool {
 	return false
 }
 
+// isStreamed returns true if res is a feature on a Kafka topic.
+func (r *sqlResourcesRepository) isStreamed(ctx context.Context, res Resource) bool {
+	fv, ok := res.(*featureVariantResource)
+	if !ok {
+		return false
+	}
+	source := fv.serialized.GetSource()
+	sourceRes, err := r.Lookup(ctx, ResourceID{Name: source.GetName(), Variant: source.GetVariant(), Type: SOURCE_VARIANT})
+	if err != nil {
+		return false
+	}
+	sv, ok := sourceRes.(*sourceVariantResource)
+	return ok && sv.serialized.GetPrimaryData().GetKafka() != nil
+}
+
 func (r *sqlResourcesRepository) getDependencies(ctx context.Context, tx pgx.Tx, resourceID common.ResourceID, logger logging.Logger) ([]common.ResourceID, error) {
 	var dependencies []common.ResourceID
 	rows, err := tx.Query(ctx, getDependencies, resourceID.Type, resourceID.Name, resourceID.Variant)
@@ -0,0 +1,61 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+//
+// Copyright 2024 FeatureForm Inc.
+//
+
+package search
+
+import (
+	"github.com/featureform/fferr"
+	"github.com/featureform/helpers"
+)
+
+// EnableSearchEnv selects the search backend of every service that uses one.
+const EnableSearchEnv = "ENABLE_SEARCH"
+
+// ParamsFromEnv returns the params of the search backend named by backend, with
+// its connection settings read from the environment. Backend is "true" or
+// "meilisearch" for Meilisearch, and "elasticsearch" or "opensearch" for
+// Elasticsearch/OpenSearch. "false" or an empty backend disables search and
+// returns nil params.
+func ParamsFromEnv(backend string) (Params, error) {
+	switch backend {
+	case "", "false":
+		return nil, nil
+	case "true", "meilisearch":
+		return &MeilisearchParams{
+			Port:   helpers.GetEnv("MEILISEARCH_PORT", "7700"),
+			Host:   helpers.GetEnv("MEILISEARCH_HOST", "localhost"),
+			ApiKey: helpers.GetEnv("MEILISEARCH_APIKEY", ""),
+		}, nil
+	case "elasticsearch", "opensearch":
+		return &ElasticsearchParams{
+			Port:     helpers.GetEnv("ELASTICSEARCH_PORT", "9200"),
+			Host:     helpers.GetEnv("ELASTICSEARCH_HOST", "localhost"),
+			ApiKey:   helpers.GetEnv("ELASTICSEARCH_APIKEY", ""),
+			Username: helpers.GetEnv("ELASTICSEARCH_USERNAME", ""),
+			Password: helpers.GetEnv("ELASTICSEARCH_PASSWORD", ""),
+			UseTLS:   helpers.GetEnv("ELASTICSEARCH_USE_TLS", "false") == "true",
+		}, nil
+	default:
+		return nil, fferr.NewInvalidArgumentErrorf(
+			"unknown search backend %q; expected meilisearch, elasticsearch, opensearch, true or false", backend,
+		)
+	}
+}
+
+// NewSearcherFromEnv connects to the search backend selected by ENABLE_SEARCH,
+// for services that can't run without search.
+func NewSearcherFromEnv() (Searcher, error) {
+	backend := helpers.GetEnv(EnableSearchEnv, "true")
+	params, err := ParamsFromEnv(backend)
+	if err != nil {
+		return nil, err
+	}
+	if params == nil {
+		return nil, fferr.NewInvalidArgumentErrorf("%s is %q, but a search backend is required", EnableSearchEnv, backend)
+	}
+	return params.NewSearcher()
+}
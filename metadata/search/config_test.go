@@ -0,0 +1,47 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+//
+// Copyright 2024 FeatureForm Inc.
+//
+
+package search
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParamsFromEnv(t *testing.T) {
+	t.Setenv("MEILISEARCH_HOST", "meili")
+	t.Setenv("ELASTICSEARCH_HOST", "elastic")
+	t.Setenv("ELASTICSEARCH_USE_TLS", "true")
+	cases := []struct {
+		backend  string
+		expected Params
+	}{
+		{"true", &MeilisearchParams{Host: "meili", Port: "7700"}},
+		{"meilisearch", &MeilisearchParams{Host: "meili", Port: "7700"}},
+		{"opensearch", &ElasticsearchParams{Host: "elastic", Port: "9200", UseTLS: true}},
+		{"false", nil},
+		{"", nil},
+	}
+	for _, c := range cases {
+		t.Run(c.backend, func(t *testing.T) {
+			params, err := ParamsFromEnv(c.backend)
+			if err != nil {
+				t.Fatalf("Failed to get params: %s", err)
+			}
+			if !reflect.DeepEqual(params, c.expected) {
+				t.Fatalf("Expected %#v, got %#v", c.expected, params)
+			}
+		})
+	}
+	if _, err := ParamsFromEnv("solr"); err == nil {
+		t.Fatalf("Expected an unknown backend to fail")
+	}
+	t.Setenv(EnableSearchEnv, "false")
+	if _, err := NewSearcherFromEnv(); err == nil {
+		t.Fatalf("Expected disabled search to fail for services that require it")
+	}
+}
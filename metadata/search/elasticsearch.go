@@ -0,0 +1,384 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+//
+// Copyright 2024 FeatureForm Inc.
+//
+
+package search
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	re "github.com/avast/retry-go/v4"
+)
+
+const (
+	elasticsearchIndex = "resources"
+	// elasticsearchHitLimit matches Meilisearch's default search limit.
+	elasticsearchHitLimit = 20
+	// elasticsearchFacetLimit caps how many distinct types and tags are counted.
+	elasticsearchFacetLimit = 100
+)
+
+// ElasticsearchParams configures an Elasticsearch or OpenSearch cluster. If
+// ApiKey is set it's used, otherwise Username and Password are, if set.
+type ElasticsearchParams struct {
+	Host     string
+	Port     string
+	ApiKey   string
+	Username string
+	Password string
+	UseTLS   bool
+}
+
+func (params *ElasticsearchParams) NewSearcher() (Searcher, error) {
+	return NewElasticsearch(params)
+}
+
+func (params *ElasticsearchParams) address() string {
+	scheme := "http"
+	if params.UseTLS {
+		scheme = "https"
+	}
+	return fmt.Sprintf("%s://%s:%s", scheme, params.Host, params.Port)
+}
+
+// Elasticsearch is a Searcher backed by Elasticsearch or OpenSearch. It only
+// uses the REST API both have in common.
+type Elasticsearch struct {
+	client  *http.Client
+	address string
+	params  *ElasticsearchParams
+}
+
+func NewElasticsearch(params *ElasticsearchParams) (Searcher, error) {
+	search := &Elasticsearch{
+		client:  &http.Client{Timeout: 30 * time.Second},
+		address: params.address(),
+		params:  params,
+	}
+	if err := search.healthCheck(); err != nil {
+		return nil, fmt.Errorf("could not connect: %v", err)
+	}
+	if err := search.initializeIndex(); err != nil {
+		return nil, fmt.Errorf("could not initialize index: %v", err)
+	}
+	return search, nil
+}
+
+// elasticsearchError is returned when a request gets a non-2xx response.
+type elasticsearchError struct {
+	status int
+	kind   string
+	reason string
+}
+
+func (e *elasticsearchError) Error() string {
+	if e.kind == "" {
+		return fmt.Sprintf("status %d", e.status)
+	}
+	return fmt.Sprintf("status %d: %s: %s", e.status, e.kind, e.reason)
+}
+
+func (s *Elasticsearch) do(method, path string, body interface{}, result interface{}) error {
+	var reader io.Reader
+	if body != nil {
+		serialized, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("could not serialize request: %v", err)
+		}
+		reader = bytes.NewReader(serialized)
+	}
+	req, err := http.NewRequest(method, s.address+path, reader)
+	if err != nil {
+		return err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if s.params.ApiKey != "" {
+		req.Header.Set("Authorization", "ApiKey "+s.params.ApiKey)
+	} else if s.params.Username != "" {
+		req.SetBasicAuth(s.params.Username, s.params.Password)
+	}
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("could not read response: %v", err)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return parseElasticsearchError(resp.StatusCode, respBody)
+	}
+	if result == nil {
+		return nil
+	}
+	if err := json.Unmarshal(respBody, result); err != nil {
+		return fmt.Errorf("could not parse response: %v", err)
+	}
+	return nil
+}
+
+func parseElasticsearchError(status int, body []byte) error {
+	var parsed struct {
+		Error struct {
+			Type   string `json:"type"`
+			Reason string `json:"reason"`
+		} `json:"error"`
+	}
+	// Some errors, like auth failures from a proxy, aren't JSON.
+	_ = json.Unmarshal(body, &parsed)
+	return &elasticsearchError{status: status, kind: parsed.Error.Type, reason: parsed.Error.Reason}
+}
+
+func (s *Elasticsearch) healthCheck() error {
+	return re.Do(
+		func() error {
+			if err := s.Health(); err != nil {
+				if strings.Contains(err.Error(), "connection refused") {
+					fmt.Printf("could not connect to search. retrying...\n")
+				} else {
+					return re.Unrecoverable(err)
+				}
+				return err
+			}
+			return nil
+		},
+		re.DelayType(func(n uint, err error, config *re.Config) time.Duration {
+			return re.BackOffDelay(n, err, config)
+		}),
+		re.Attempts(10),
+	)
+}
+
+// Health returns an error if the cluster is unreachable or red.
+func (s *Elasticsearch) Health() error {
+	var health struct {
+		Status string `json:"status"`
+	}
+	if err := s.do(http.MethodGet, "/_cluster/health", nil, &health); err != nil {
+		return fmt.Errorf("could not reach search: %v", err)
+	}
+	if health.Status == "red" {
+		return fmt.Errorf("search is %s", health.Status)
+	}
+	return nil
+}
+
+// elasticsearchIndexSettings splits names on underscores and punctuation, and
+// indexes prefixes of each word so partial names match like they do in
+// Meilisearch. Types and tags are keywords so they can be filtered and faceted.
+func elasticsearchIndexSettings() map[string]interface{} {
+	nameField := map[string]interface{}{
+		"type":            "text",
+		"analyzer":        "resource_prefix",
+		"search_analyzer": "resource_name",
+		"fields": map[string]interface{}{
+			"keyword": map[string]interface{}{"type": "keyword"},
+		},
+	}
+	return map[string]interface{}{
+		"settings": map[string]interface{}{
+			"analysis": map[string]interface{}{
+				"tokenizer": map[string]interface{}{
+					"resource_words": map[string]interface{}{
+						"type":    "pattern",
+						"pattern": `[\W_]+`,
+					},
+				},
+				"filter": map[string]interface{}{
+					"resource_edge_ngram": map[string]interface{}{
+						"type":     "edge_ngram",
+						"min_gram": 1,
+						"max_gram": 20,
+					},
+				},
+				"analyzer": map[string]interface{}{
+					"resource_name": map[string]interface{}{
+						"type":      "custom",
+						"tokenizer": "resource_words",
+						"filter":    []string{"lowercase", "asciifolding"},
+					},
+					"resource_prefix": map[string]interface{}{
+						"type":      "custom",
+						"tokenizer": "resource_words",
+						"filter":    []string{"lowercase", "asciifolding", "resource_edge_ngram"},
+					},
+				},
+			},
+		},
+		"mappings": map[string]interface{}{
+			"properties": map[string]interface{}{
+				"ID":          map[string]interface{}{"type": "keyword"},
+				"Parsed":      map[string]interface{}{"type": "text", "analyzer": "resource_prefix", "search_analyzer": "resource_name"},
+				"Name":        nameField,
+				"Variant":     nameField,
+				typeAttribute: map[string]interface{}{"type": "keyword"},
+				tagsAttribute: map[string]interface{}{"type": "keyword"},
+			},
+		},
+	}
+}
+
+func (s *Elasticsearch) initializeIndex() error {
+	err := s.do(http.MethodPut, "/"+elasticsearchIndex, elasticsearchIndexSettings(), nil)
+	if esErr, ok := err.(*elasticsearchError); ok && esErr.kind == "resource_already_exists_exception" {
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("index creation request failed: %v", err)
+	}
+	return nil
+}
+
+// Upsert waits for the document to be searchable before returning, like the
+// Meilisearch backend does.
+func (s *Elasticsearch) Upsert(doc ResourceDoc) error {
+	document := resourceDocument(doc)
+	path := fmt.Sprintf("/%s/_doc/%s?refresh=wait_for", elasticsearchIndex, url.PathEscape(DocumentID(doc)))
+	if err := s.do(http.MethodPut, path, document, nil); err != nil {
+		return fmt.Errorf("could not upsert %#v: %v", document, err)
+	}
+	return nil
+}
+
+func (s *Elasticsearch) Delete(id string) error {
+	path := fmt.Sprintf("/%s/_doc/%s?refresh=wait_for", elasticsearchIndex, url.PathEscape(id))
+	err := s.do(http.MethodDelete, path, nil, nil)
+	if esErr, ok := err.(*elasticsearchError); ok && esErr.status == http.StatusNotFound {
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("failed to delete document %s: %v", id, err)
+	}
+	return nil
+}
+
+// DeleteAll deletes every document but keeps the index, so its mappings stay
+// configured.
+func (s *Elasticsearch) DeleteAll() error {
+	body := map[string]interface{}{
+		"query": map[string]interface{}{"match_all": map[string]interface{}{}},
+	}
+	path := fmt.Sprintf("/%s/_delete_by_query?refresh=true&conflicts=proceed", elasticsearchIndex)
+	if err := s.do(http.MethodPost, path, body, nil); err != nil {
+		return fmt.Errorf("failed to delete documents: %v", err)
+	}
+	return nil
+}
+
+type elasticsearchBucket struct {
+	Key      string `json:"key"`
+	DocCount int64  `json:"doc_count"`
+}
+
+type elasticsearchResponse struct {
+	Hits struct {
+		Hits []struct {
+			Source map[string]interface{} `json:"_source"`
+		} `json:"hits"`
+	} `json:"hits"`
+	Aggregations map[string]struct {
+		Buckets []elasticsearchBucket `json:"buckets"`
+	} `json:"aggregations"`
+}
+
+func (s *Elasticsearch) RunSearch(q string) ([]ResourceDoc, error) {
+	resp, err := s.search(buildElasticsearchQuery(q, SearchFilters{}, false))
+	if err != nil {
+		return nil, err
+	}
+	var searchResults []ResourceDoc
+	for _, hit := range resp.Hits.Hits {
+		searchResults = append(searchResults, docFromHit(hit.Source))
+	}
+	return searchResults, nil
+}
+
+// Search runs a search with filters and groups its results by type.
+func (s *Elasticsearch) Search(q string, filters SearchFilters) (SearchResults, error) {
+	resp, err := s.search(buildElasticsearchQuery(q, filters, true))
+	if err != nil {
+		return SearchResults{}, err
+	}
+	docs := make([]ResourceDoc, 0, len(resp.Hits.Hits))
+	for _, hit := range resp.Hits.Hits {
+		docs = append(docs, docFromHit(hit.Source))
+	}
+	return groupResults(docs, bucketCounts(resp, typeAttribute), bucketCounts(resp, tagsAttribute)), nil
+}
+
+func (s *Elasticsearch) search(query map[string]interface{}) (elasticsearchResponse, error) {
+	var resp elasticsearchResponse
+	if err := s.do(http.MethodPost, fmt.Sprintf("/%s/_search", elasticsearchIndex), query, &resp); err != nil {
+		return elasticsearchResponse{}, fmt.Errorf("failed to search: %v", err)
+	}
+	return resp, nil
+}
+
+func bucketCounts(resp elasticsearchResponse, attr string) map[string]int64 {
+	counts := make(map[string]int64)
+	for _, bucket := range resp.Aggregations[attr].Buckets {
+		counts[bucket.Key] = bucket.DocCount
+	}
+	return counts
+}
+
+// buildElasticsearchQuery matches q against names, variants, and tags. Values
+// within a filter are ORed and the filters are ANDed, like buildFilter.
+func buildElasticsearchQuery(q string, filters SearchFilters, facets bool) map[string]interface{} {
+	var match map[string]interface{}
+	if strings.TrimSpace(q) == "" {
+		match = map[string]interface{}{"match_all": map[string]interface{}{}}
+	} else {
+		match = map[string]interface{}{
+			"multi_match": map[string]interface{}{
+				"query":  q,
+				"fields": []string{"Name^3", "Variant", "Parsed", tagsAttribute},
+			},
+		}
+	}
+	filter := make([]interface{}, 0, 2)
+	for _, attr := range []struct {
+		name   string
+		values []string
+	}{
+		{typeAttribute, filters.Types},
+		{tagsAttribute, filters.Tags},
+	} {
+		if len(attr.values) == 0 {
+			continue
+		}
+		filter = append(filter, map[string]interface{}{
+			"terms": map[string]interface{}{attr.name: attr.values},
+		})
+	}
+	query := map[string]interface{}{
+		"size": elasticsearchHitLimit,
+		"query": map[string]interface{}{
+			"bool": map[string]interface{}{
+				"must":   match,
+				"filter": filter,
+			},
+		},
+	}
+	if facets {
+		aggs := make(map[string]interface{})
+		for _, attr := range []string{typeAttribute, tagsAttribute} {
+			aggs[attr] = map[string]interface{}{
+				"terms": map[string]interface{}{"field": attr, "size": elasticsearchFacetLimit},
+			}
+		}
+		query["aggs"] = aggs
+	}
+	return query
+}
@@ -0,0 +1,157 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+//
+// Copyright 2024 FeatureForm Inc.
+//
+
+package search
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"testing"
+
+	help "github.com/featureform/helpers"
+)
+
+func TestBuildElasticsearchQuery(t *testing.T) {
+	query := buildElasticsearchQuery("fraud", SearchFilters{Types: []string{"Feature"}, Tags: []string{"prod", "dev"}}, true)
+	filter := query["query"].(map[string]interface{})["bool"].(map[string]interface{})["filter"]
+	expected := []interface{}{
+		map[string]interface{}{"terms": map[string]interface{}{"Type": []string{"Feature"}}},
+		map[string]interface{}{"terms": map[string]interface{}{"Tags": []string{"prod", "dev"}}},
+	}
+	if !reflect.DeepEqual(filter, expected) {
+		t.Fatalf("Expected %v, got: %v", expected, filter)
+	}
+	if _, ok := query["aggs"]; !ok {
+		t.Fatalf("Expected facet aggregations")
+	}
+	query = buildElasticsearchQuery("", SearchFilters{}, false)
+	must := query["query"].(map[string]interface{})["bool"].(map[string]interface{})["must"]
+	if _, ok := must.(map[string]interface{})["match_all"]; !ok {
+		t.Fatalf("Expected an empty query to match all, got: %v", must)
+	}
+	if _, ok := query["aggs"]; ok {
+		t.Fatalf("Expected no aggregations")
+	}
+}
+
+func TestElasticsearchStub(t *testing.T) {
+	created := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if user, pass, _ := r.BasicAuth(); user != "user" || pass != "pass" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/_cluster/health":
+			w.Write([]byte(`{"status":"yellow"}`))
+		case r.Method == http.MethodPut && r.URL.Path == "/resources":
+			if created {
+				w.WriteHeader(http.StatusBadRequest)
+				w.Write([]byte(`{"error":{"type":"resource_already_exists_exception","reason":"exists"}}`))
+				return
+			}
+			created = true
+			w.Write([]byte(`{"acknowledged":true}`))
+		case r.Method == http.MethodPost && r.URL.Path == "/resources/_search":
+			w.Write([]byte(`{
+				"hits": {"hits": [
+					{"_source": {"Name": "fraud", "Variant": "v1", "Type": "Feature", "Tags": ["prod"]}},
+					{"_source": {"Name": "fraud", "Variant": "v1", "Type": "Label", "Tags": null}}
+				]},
+				"aggregations": {
+					"Type": {"buckets": [{"key": "Feature", "doc_count": 5}, {"key": "Label", "doc_count": 1}]},
+					"Tags": {"buckets": [{"key": "prod", "doc_count": 3}]}
+				}
+			}`))
+		case r.Method == http.MethodPut && r.URL.Path == "/resources/_doc/Feature__fraud__v1":
+			var doc map[string]interface{}
+			if err := json.NewDecoder(r.Body).Decode(&doc); err != nil || doc["Name"] != "fraud" {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			w.Write([]byte(`{"result":"created"}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+	addr, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatalf("Failed to parse server address: %v", err)
+	}
+	params := &ElasticsearchParams{Host: addr.Hostname(), Port: addr.Port(), Username: "user", Password: "pass"}
+	if _, err := NewElasticsearch(params); err != nil {
+		t.Fatalf("Failed to initialize: %v", err)
+	}
+	// The index already exists the second time.
+	searcher, err := NewElasticsearch(params)
+	if err != nil {
+		t.Fatalf("Failed to initialize with an existing index: %v", err)
+	}
+	if err := searcher.Upsert(ResourceDoc{Name: "fraud", Variant: "v1", Type: "Feature"}); err != nil {
+		t.Fatalf("Failed to Upsert: %v", err)
+	}
+	results, err := searcher.Search("fraud", SearchFilters{})
+	if err != nil {
+		t.Fatalf("Failed to search: %v", err)
+	}
+	expected := SearchResults{
+		Groups: []SearchResultGroup{
+			{Type: "Feature", Count: 5, Resources: []ResourceDoc{{Name: "fraud", Variant: "v1", Type: "Feature", Tags: []string{"prod"}}}},
+			{Type: "Label", Count: 1, Resources: []ResourceDoc{{Name: "fraud", Variant: "v1", Type: "Label"}}},
+		},
+		TagCounts: map[string]int64{"prod": 3},
+	}
+	if !reflect.DeepEqual(results, expected) {
+		t.Fatalf("Expected %#v, got: %#v", expected, results)
+	}
+	if err := searcher.DeleteAll(); err == nil {
+		t.Fatalf("Expected an error from an unhandled request")
+	}
+}
+
+func TestElasticsearchFilteredSearch(t *testing.T) {
+	if testing.Short() {
+		t.Skip()
+	}
+	params := ElasticsearchParams{
+		Host:     "localhost",
+		Port:     help.GetEnv("ELASTICSEARCH_PORT", "9200"),
+		Username: help.GetEnv("ELASTICSEARCH_USERNAME", ""),
+		Password: help.GetEnv("ELASTICSEARCH_PASSWORD", ""),
+	}
+	searcher, err := NewElasticsearch(&params)
+	if err != nil {
+		t.Fatalf("Failed to initialize %s", err)
+	}
+	if err := searcher.DeleteAll(); err != nil {
+		t.Fatalf("Failed to Delete %s", err)
+	}
+	resources := []ResourceDoc{
+		{Name: "fraud_model", Variant: "v1", Type: "Feature", Tags: []string{"prod"}},
+		{Name: "fraud_model", Variant: "v2", Type: "Feature", Tags: []string{"dev"}},
+		{Name: "fraud_model", Variant: "v1", Type: "Label", Tags: []string{"prod"}},
+	}
+	for _, resource := range resources {
+		if err := searcher.Upsert(resource); err != nil {
+			t.Fatalf("Failed to Upsert %s", err)
+		}
+	}
+	results, err := searcher.Search("fra", SearchFilters{Tags: []string{"prod"}})
+	if err != nil {
+		t.Fatalf("Failed to search %s", err)
+	}
+	if len(results.Groups) != 2 || results.TagCounts["prod"] != 2 {
+		t.Fatalf("Expected a prod feature and label, got: %#v", results)
+	}
+	if err := searcher.DeleteAll(); err != nil {
+		t.Fatalf("Failed to Delete %s", err)
+	}
+}
@@ -11,6 +11,7 @@ import (
 	"fmt"
 
 	"regexp"
+	"sort"
 	"strings"
 	"time"
 
@@ -21,10 +22,25 @@ import (
 type Searcher interface {
 	Upsert(ResourceDoc) error
 	RunSearch(q string) ([]ResourceDoc, error)
+	Search(q string, filters SearchFilters) (SearchResults, error)
+	// Delete removes the document with the given ID, as returned by
+	// DocumentID. Deleting a document that isn't indexed isn't an error.
+	Delete(id string) error
 	DeleteAll() error
+	Health() error
 }
 
-type NewMeilisearchFunc func(params *MeilisearchParams) (Searcher, error)
+// Params configures a search backend.
+type Params interface {
+	NewSearcher() (Searcher, error)
+}
+
+type NewSearcherFunc func(params Params) (Searcher, error)
+
+// NewSearcher connects to the backend configured by params.
+func NewSearcher(params Params) (Searcher, error) {
+	return params.NewSearcher()
+}
 
 type MeilisearchParams struct {
 	Host   string
@@ -32,6 +48,10 @@ type MeilisearchParams struct {
 	ApiKey string
 }
 
+func (params *MeilisearchParams) NewSearcher() (Searcher, error) {
+	return NewMeilisearch(params)
+}
+
 type Search struct {
 	client *ms.Client
 }
@@ -56,6 +76,9 @@ func NewMeilisearch(params *MeilisearchParams) (Searcher, error) {
 	if err := search.initializeCollection(); err != nil {
 		return nil, fmt.Errorf("could not initialize collection: %v", err)
 	}
+	if err := search.configureAttributes(); err != nil {
+		return nil, fmt.Errorf("could not configure index attributes: %v", err)
+	}
 	return &search, nil
 }
 
@@ -80,6 +103,18 @@ func healthCheck(client *ms.Client) error {
 	return err
 }
 
+// Health returns an error if meilisearch is unreachable or not available.
+func (s Search) Health() error {
+	health, err := s.client.Health()
+	if err != nil {
+		return fmt.Errorf("could not reach search: %v", err)
+	}
+	if health.Status != "available" {
+		return fmt.Errorf("search is %s", health.Status)
+	}
+	return nil
+}
+
 type ResourceDoc struct {
 	Name    string
 	Variant string
@@ -87,6 +122,32 @@ type ResourceDoc struct {
 	Tags    []string
 }
 
+// SearchFilters narrows a search to resources of any of the given types that
+// have any of the given tags. Empty fields don't filter.
+type SearchFilters struct {
+	Types []string
+	Tags  []string
+}
+
+// SearchResults holds the hits of a search grouped by resource type. Counts
+// are of all matching resources, which can be more than the hits returned.
+type SearchResults struct {
+	Groups    []SearchResultGroup
+	TagCounts map[string]int64
+}
+
+type SearchResultGroup struct {
+	Type      string
+	Count     int64
+	Resources []ResourceDoc
+}
+
+const (
+	typeAttribute = "Type"
+	tagsAttribute = "Tags"
+	nameAttribute = "Name"
+)
+
 func (s Search) waitForSync(taskUID int64) error {
 	task, err := s.client.GetTask(taskUID)
 	if err != nil {
@@ -123,17 +184,50 @@ func (s Search) initializeCollection() error {
 	return nil
 }
 
-func (s Search) Upsert(doc ResourceDoc) error {
-	rgx := regexp.MustCompile(`[@.\s]`)
-	documentId := rgx.ReplaceAllString(fmt.Sprintf("%s__%s__%s", doc.Type, doc.Name, doc.Variant), "_")
-	document := map[string]interface{}{
-		"ID":      documentId,
+// configureAttributes makes types and tags filterable so they can be faceted
+// on. It's safe to run on an index that's already configured.
+func (s Search) configureAttributes() error {
+	index := s.client.Index("resources")
+	filterable := []string{typeAttribute, tagsAttribute}
+	resp, err := index.UpdateFilterableAttributes(&filterable)
+	if err != nil {
+		return fmt.Errorf("failed to update filterable attributes: %v", err)
+	}
+	if err := s.waitForSync(resp.TaskUID); err != nil {
+		return fmt.Errorf("could not update filterable attributes: %v", err)
+	}
+	sortable := []string{nameAttribute, typeAttribute}
+	resp, err = index.UpdateSortableAttributes(&sortable)
+	if err != nil {
+		return fmt.Errorf("failed to update sortable attributes: %v", err)
+	}
+	if err := s.waitForSync(resp.TaskUID); err != nil {
+		return fmt.Errorf("could not update sortable attributes: %v", err)
+	}
+	return nil
+}
+
+var documentIdRgx = regexp.MustCompile(`[@.\s]`)
+
+// DocumentID is the ID a ResourceDoc is indexed under.
+func DocumentID(doc ResourceDoc) string {
+	return documentIdRgx.ReplaceAllString(fmt.Sprintf("%s__%s__%s", doc.Type, doc.Name, doc.Variant), "_")
+}
+
+// resourceDocument is the shape a ResourceDoc is indexed in by every backend.
+func resourceDocument(doc ResourceDoc) map[string]interface{} {
+	return map[string]interface{}{
+		"ID":      DocumentID(doc),
 		"Parsed":  strings.ReplaceAll(fmt.Sprintf("%s__%s__%s", doc.Type, doc.Name, doc.Variant), "_", " "),
 		"Name":    doc.Name,
 		"Type":    doc.Type,
 		"Variant": doc.Variant,
 		"Tags":    doc.Tags,
 	}
+}
+
+func (s Search) Upsert(doc ResourceDoc) error {
+	document := resourceDocument(doc)
 	resp, err := s.client.Index("resources").UpdateDocuments(document)
 	if err != nil {
 		return err
@@ -144,10 +238,26 @@ func (s Search) Upsert(doc ResourceDoc) error {
 	return nil
 }
 
+func (s Search) Delete(id string) error {
+	resp, err := s.client.Index("resources").DeleteDocument(id)
+	if err != nil {
+		return fmt.Errorf("failed to delete document %s: %v", id, err)
+	}
+	if err := s.waitForSync(resp.TaskUID); err != nil {
+		return fmt.Errorf("could not delete document %s: %v", id, err)
+	}
+	return nil
+}
+
+// DeleteAll deletes every document but keeps the index, so its filterable and
+// sortable attributes stay configured.
 func (s Search) DeleteAll() error {
-	_, err := s.client.DeleteIndex("resources")
+	resp, err := s.client.Index("resources").DeleteAllDocuments()
 	if err != nil {
-		return fmt.Errorf("failed to delete index: %v", err)
+		return fmt.Errorf("failed to delete documents: %v", err)
+	}
+	if err := s.waitForSync(resp.TaskUID); err != nil {
+		return fmt.Errorf("could not delete documents: %v", err)
 	}
 	return nil
 }
@@ -159,27 +269,133 @@ func (s Search) RunSearch(q string) ([]ResourceDoc, error) {
 	}
 
 	var searchResults []ResourceDoc
+	for _, hit := range results.Hits {
+		searchResults = append(searchResults, docFromHit(hit))
+	}
+	return searchResults, nil
+}
 
+// Search runs a search with filters and groups its results by type.
+func (s Search) Search(q string, filters SearchFilters) (SearchResults, error) {
+	request := &ms.SearchRequest{
+		Facets: []string{typeAttribute, tagsAttribute},
+	}
+	if filter := buildFilter(filters); len(filter) > 0 {
+		request.Filter = filter
+	}
+	results, err := s.client.Index("resources").Search(q, request)
+	if err != nil {
+		return SearchResults{}, fmt.Errorf("failed to search: %v", err)
+	}
+	docs := make([]ResourceDoc, 0, len(results.Hits))
 	for _, hit := range results.Hits {
-		doc := hit.(map[string]interface{})
+		docs = append(docs, docFromHit(hit))
+	}
+	facets := parseFacetDistribution(results.FacetDistribution)
+	return groupResults(docs, facets[typeAttribute], facets[tagsAttribute]), nil
+}
+
+func docFromHit(hit interface{}) ResourceDoc {
+	doc := hit.(map[string]interface{})
+
+	var tags []string
+	if tagSlice, ok := doc["Tags"].([]interface{}); ok {
+		for _, tag := range tagSlice {
+			if strTag, ok := tag.(string); ok {
+				tags = append(tags, strTag)
+			}
+		}
+	}
+	return ResourceDoc{
+		Name:    doc["Name"].(string),
+		Type:    doc["Type"].(string),
+		Variant: doc["Variant"].(string),
+		Tags:    tags,
+	}
+}
+
+// buildFilter creates a filter in Meilisearch's array syntax, where the outer
+// conditions are ANDed and the inner ones are ORed.
+func buildFilter(filters SearchFilters) [][]string {
+	filter := make([][]string, 0, 2)
+	for _, attr := range []struct {
+		name   string
+		values []string
+	}{
+		{typeAttribute, filters.Types},
+		{tagsAttribute, filters.Tags},
+	} {
+		if len(attr.values) == 0 {
+			continue
+		}
+		conditions := make([]string, len(attr.values))
+		for i, val := range attr.values {
+			conditions[i] = fmt.Sprintf("%s = %s", attr.name, quoteFilterValue(val))
+		}
+		filter = append(filter, conditions)
+	}
+	return filter
+}
+
+func quoteFilterValue(val string) string {
+	escaped := strings.ReplaceAll(val, `\`, `\\`)
+	escaped = strings.ReplaceAll(escaped, `"`, `\"`)
+	return `"` + escaped + `"`
+}
 
-		var tags []string
-		if tagSlice, ok := doc["Tags"].([]interface{}); ok {
-			for _, tag := range tagSlice {
-				if strTag, ok := tag.(string); ok {
-					tags = append(tags, strTag)
+func parseFacetDistribution(distribution interface{}) map[string]map[string]int64 {
+	facets := make(map[string]map[string]int64)
+	attrs, ok := distribution.(map[string]interface{})
+	if !ok {
+		return facets
+	}
+	for attr, values := range attrs {
+		counts := make(map[string]int64)
+		if valueCounts, ok := values.(map[string]interface{}); ok {
+			for val, count := range valueCounts {
+				if num, ok := count.(float64); ok {
+					counts[val] = int64(num)
 				}
 			}
 		}
-		searchResults = append(searchResults, ResourceDoc{
-			Name:    doc["Name"].(string),
-			Type:    doc["Type"].(string),
-			Variant: doc["Variant"].(string),
-			Tags:    tags,
-		})
+		facets[attr] = counts
+	}
+	return facets
+}
 
+func groupResults(docs []ResourceDoc, typeCounts, tagCounts map[string]int64) SearchResults {
+	groups := make(map[string]*SearchResultGroup)
+	for _, doc := range docs {
+		group, ok := groups[doc.Type]
+		if !ok {
+			group = &SearchResultGroup{Type: doc.Type, Count: typeCounts[doc.Type]}
+			groups[doc.Type] = group
+		}
+		group.Resources = append(group.Resources, doc)
 	}
-	return searchResults, nil
+	// Types can match without any of their hits being returned.
+	for resourceType, count := range typeCounts {
+		if _, ok := groups[resourceType]; !ok && count > 0 {
+			groups[resourceType] = &SearchResultGroup{Type: resourceType, Count: count, Resources: []ResourceDoc{}}
+		}
+	}
+	results := SearchResults{
+		Groups:    make([]SearchResultGroup, 0, len(groups)),
+		TagCounts: tagCounts,
+	}
+	for _, group := range groups {
+		if group.Count < int64(len(group.Resources)) {
+			group.Count = int64(len(group.Resources))
+		}
+		results.Groups = append(results.Groups, *group)
+	}
+	sort.Slice(results.Groups, func(i, j int) bool {
+		return results.Groups[i].Type < results.Groups[j].Type
+	})
+	if results.TagCounts == nil {
+		results.TagCounts = map[string]int64{}
+	}
+	return results
 }
 
 type SearchMock struct {
@@ -189,6 +405,10 @@ func (s SearchMock) Upsert(doc ResourceDoc) error {
 	return nil
 }
 
+func (s SearchMock) Delete(id string) error {
+	return nil
+}
+
 func (s SearchMock) DeleteAll() error {
 	return nil
 }
@@ -196,3 +416,11 @@ func (s SearchMock) DeleteAll() error {
 func (s SearchMock) RunSearch(q string) ([]ResourceDoc, error) {
 	return nil, nil
 }
+
+func (s SearchMock) Search(q string, filters SearchFilters) (SearchResults, error) {
+	return SearchResults{Groups: []SearchResultGroup{}, TagCounts: map[string]int64{}}, nil
+}
+
+func (s SearchMock) Health() error {
+	return nil
+}
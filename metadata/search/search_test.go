@@ -8,6 +8,7 @@
 package search
 
 import (
+	"reflect"
 	"testing"
 
 	help "github.com/featureform/helpers"
@@ -198,3 +199,140 @@ func TestOrder(t *testing.T) {
 	//	t.Fatalf("Failed to Delete %s", err)
 	//}
 }
+
+func TestBuildFilter(t *testing.T) {
+	tests := map[string]struct {
+		Filters  SearchFilters
+		Expected [][]string
+	}{
+		"none":  {SearchFilters{}, [][]string{}},
+		"types": {SearchFilters{Types: []string{"Feature", "Label"}}, [][]string{{`Type = "Feature"`, `Type = "Label"`}}},
+		"types and tags": {
+			SearchFilters{Types: []string{"Feature"}, Tags: []string{"prod", "fraud"}},
+			[][]string{{`Type = "Feature"`}, {`Tags = "prod"`, `Tags = "fraud"`}},
+		},
+		"escaped": {SearchFilters{Tags: []string{`a "quoted" \tag`}}, [][]string{{`Tags = "a \"quoted\" \\tag"`}}},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			if filter := buildFilter(test.Filters); !reflect.DeepEqual(filter, test.Expected) {
+				t.Fatalf("Expected %v, got: %v", test.Expected, filter)
+			}
+		})
+	}
+}
+
+func TestGroupResults(t *testing.T) {
+	distribution := map[string]interface{}{
+		"Type": map[string]interface{}{"Feature": float64(30), "Label": float64(1), "Provider": float64(2)},
+		"Tags": map[string]interface{}{"prod": float64(4)},
+	}
+	facets := parseFacetDistribution(distribution)
+	docs := []ResourceDoc{
+		{Name: "label", Variant: "v1", Type: "Label"},
+		{Name: "feature", Variant: "v1", Type: "Feature", Tags: []string{"prod"}},
+		{Name: "feature", Variant: "v2", Type: "Feature"},
+	}
+	results := groupResults(docs, facets["Type"], facets["Tags"])
+	expected := SearchResults{
+		Groups: []SearchResultGroup{
+			{Type: "Feature", Count: 30, Resources: docs[1:]},
+			{Type: "Label", Count: 1, Resources: docs[:1]},
+			{Type: "Provider", Count: 2, Resources: []ResourceDoc{}},
+		},
+		TagCounts: map[string]int64{"prod": 4},
+	}
+	if !reflect.DeepEqual(results, expected) {
+		t.Fatalf("Expected %#v, got: %#v", expected, results)
+	}
+}
+
+func TestFilteredSearch(t *testing.T) {
+	if testing.Short() {
+		t.Skip()
+	}
+	params := MeilisearchParams{
+		Host:   "localhost",
+		Port:   getPort(),
+		ApiKey: getApikey(),
+	}
+	searcher, err := NewMeilisearch(&params)
+	if err != nil {
+		t.Fatalf("Failed to initialize %s", err)
+	}
+	if err := searcher.DeleteAll(); err != nil {
+		t.Fatalf("Failed to Delete %s", err)
+	}
+	resources := []ResourceDoc{
+		{Name: "fraud", Variant: "v1", Type: "Feature", Tags: []string{"prod"}},
+		{Name: "fraud", Variant: "v2", Type: "Feature", Tags: []string{"dev"}},
+		{Name: "fraud", Variant: "v1", Type: "Label", Tags: []string{"prod"}},
+		{Name: "fraud", Variant: "v1", Type: "Trainingset"},
+	}
+	for _, resource := range resources {
+		if err := searcher.Upsert(resource); err != nil {
+			t.Fatalf("Failed to Upsert %s", err)
+		}
+	}
+	results, err := searcher.Search("fraud", SearchFilters{})
+	if err != nil {
+		t.Fatalf("Failed to search %s", err)
+	}
+	if len(results.Groups) != 3 || results.Groups[0].Type != "Feature" || results.Groups[0].Count != 2 {
+		t.Fatalf("Expected 3 groups starting with 2 features, got: %#v", results.Groups)
+	}
+	if results.TagCounts["prod"] != 2 {
+		t.Fatalf("Expected 2 resources tagged prod, got: %#v", results.TagCounts)
+	}
+	results, err = searcher.Search("fraud", SearchFilters{Types: []string{"Feature", "Label"}, Tags: []string{"prod"}})
+	if err != nil {
+		t.Fatalf("Failed to search %s", err)
+	}
+	if len(results.Groups) != 2 || results.Groups[0].Count != 1 || results.Groups[1].Count != 1 {
+		t.Fatalf("Expected a prod feature and label, got: %#v", results.Groups)
+	}
+	if err := searcher.DeleteAll(); err != nil {
+		t.Fatalf("Failed to Delete %s", err)
+	}
+}
+
+func TestDelete(t *testing.T) {
+	if testing.Short() {
+		t.Skip()
+	}
+	params := MeilisearchParams{
+		Host:   "localhost",
+		Port:   getPort(),
+		ApiKey: getApikey(),
+	}
+	searcher, err := NewMeilisearch(&params)
+	if err != nil {
+		t.Fatalf("Failed to initialize %s", err)
+	}
+	if err := searcher.DeleteAll(); err != nil {
+		t.Fatalf("Failed to Delete %s", err)
+	}
+	kept := ResourceDoc{Name: "churn", Variant: "v1", Type: "Feature"}
+	deleted := ResourceDoc{Name: "churn", Variant: "v2", Type: "Feature"}
+	for _, resource := range []ResourceDoc{kept, deleted} {
+		if err := searcher.Upsert(resource); err != nil {
+			t.Fatalf("Failed to Upsert %s", err)
+		}
+	}
+	if err := searcher.Delete(DocumentID(deleted)); err != nil {
+		t.Fatalf("Failed to delete %s", err)
+	}
+	results, err := searcher.RunSearch("churn")
+	if err != nil {
+		t.Fatalf("Failed to search %s", err)
+	}
+	if !reflect.DeepEqual(results, []ResourceDoc{kept}) {
+		t.Fatalf("Expected only %#v, got: %#v", kept, results)
+	}
+	if err := searcher.Delete(DocumentID(deleted)); err != nil {
+		t.Fatalf("Expected deleting a missing document to succeed: %s", err)
+	}
+	if err := searcher.DeleteAll(); err != nil {
+		t.Fatalf("Failed to Delete %s", err)
+	}
+}
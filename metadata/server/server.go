@@ -10,12 +10,19 @@ package main
 import (
 	"context"
 	"fmt"
-	"os"
+	"net/http"
+	"os/signal"
+	"syscall"
 
 	"github.com/featureform/config"
 	"github.com/featureform/config/bootstrap"
 	"github.com/featureform/db"
 	"github.com/featureform/helpers"
+	"github.com/featureform/helpers/encryption"
+	"github.com/featureform/helpers/events"
+	"github.com/featureform/helpers/grpctls"
+	"github.com/featureform/helpers/interceptors"
+	"github.com/featureform/helpers/tracing"
 	"github.com/featureform/logging"
 	"github.com/featureform/metadata"
 	"github.com/featureform/metadata/search"
@@ -23,10 +30,29 @@ import (
 
 func main() {
 	addr := helpers.GetEnv("METADATA_PORT", "8080")
-	enableSearch := helpers.GetEnv("ENABLE_SEARCH", "true")
+	healthAddr := helpers.GetEnv("METADATA_HEALTH_PORT", "8081")
+	enableSearch := helpers.GetEnv(search.EnableSearchEnv, "true")
 
 	logger := logging.NewLogger("metadata")
 	defer logger.Sync()
+
+	// Serve health checks right away so the liveness probe passes, and the
+	// readiness probe sees NOT_SERVING rather than nothing, while the lookup
+	// initializes.
+	health := metadata.NewHealthChecker()
+	healthTLS, err := grpctls.FromEnv().ProbeTLSConfig()
+	if err != nil {
+		logger.Panicw("Failed to load health check TLS config", "Err", err)
+	}
+	healthServer := metadata.NewHealthServer(fmt.Sprintf(":%s", healthAddr), health, healthTLS)
+	go func() {
+		logger.Infow("Starting health check server", "port", healthAddr, "tls", healthTLS != nil)
+		err := metadata.StartHealthServer(healthServer)
+		if err != nil && err != http.ErrServerClosed {
+			logger.Errorw("Health check server failed", "err", err)
+		}
+	}()
+
 	logger.Info("Parsing Featureform App Config")
 	appConfig, err := config.Get(logger)
 	if err != nil {
@@ -45,6 +71,14 @@ func main() {
 		panic(err)
 	}
 	defer logger.LogIfErr("Failed to close service-level resources", init.Close())
+	shutdownTracing, err := tracing.Init(ctx, "metadata", helpers.GetEnv(tracing.EndpointEnv, ""))
+	if err != nil {
+		logger.Errorw("Failed to initialize tracing", "err", err)
+		panic(err)
+	}
+	defer func() {
+		logger.LogIfErr("Failed to shut down tracing", shutdownTracing(context.Background()))
+	}()
 
 	if config.ShouldRunGooseMigrationMetadata() {
 		logger.Info("Running goose migrations for metadata")
@@ -65,24 +99,78 @@ func main() {
 		panic(err.Error())
 	}
 
+	authenticator, err := interceptors.AuthenticatorFromEnv()
+	if err != nil {
+		logger.Panicw("Failed to create authenticator", "Err", err)
+	}
+
+	encrypter, err := encryption.FromEnv(initCtx)
+	if err != nil {
+		logger.Panicw("Failed to create provider config encrypter", "Err", err)
+	}
+
+	emitter, err := events.FromEnv(initCtx, logger)
+	if err != nil {
+		logger.Panicw("Failed to create metadata event emitter", "Err", err)
+	}
+	defer func() {
+		logger.LogIfErr("Failed to close metadata event emitter", emitter.Close())
+	}()
+
+	namingRules, err := metadata.NamingRulesFromEnv()
+	if err != nil {
+		logger.Panicw("Failed to parse resource naming rules", "Err", err)
+	}
+
 	config := &metadata.Config{
-		Logger:      logger,
-		Address:     fmt.Sprintf(":%s", addr),
-		TaskManager: manager,
+		Logger:            logger,
+		Address:           fmt.Sprintf(":%s", addr),
+		TaskManager:       manager,
+		Health:            health,
+		Authenticator:     authenticator,
+		Encrypter:         encrypter,
+		Events:            emitter,
+		IdempotencyKeyTTL: appConfig.IdempotencyKeyTTL,
+		NamingRules:       &namingRules,
 	}
-	if enableSearch == "true" {
-		logger.Infow("Connecting to search", "host", os.Getenv("MEILISEARCH_HOST"), "port", os.Getenv("MEILISEARCH_PORT"))
-		config.SearchParams = &search.MeilisearchParams{
-			Port:   helpers.GetEnv("MEILISEARCH_PORT", "7700"),
-			Host:   helpers.GetEnv("MEILISEARCH_HOST", "localhost"),
-			ApiKey: helpers.GetEnv("MEILISEARCH_APIKEY", ""),
-		}
+	searchParams, err := search.ParamsFromEnv(enableSearch)
+	if err != nil {
+		logger.Panicw("Invalid search config", "error", err)
+	}
+	if searchParams != nil {
+		logger.Infow("Connecting to search", "backend", enableSearch)
+		config.SearchParams = searchParams
 	}
 	server, err := metadata.NewMetadataServer(config)
 	if err != nil {
 		logger.Panicw("Failed to create metadata server", "Err", err)
 	}
-	if err := server.Serve(); err != nil {
-		logger.Errorw("Serve failed with error", "Err", err)
+	// Signals start a graceful shutdown: the health server is stopped first,
+	// then the metadata server once its in-flight requests finish or the
+	// shutdown timeout passes.
+	signalCtx, stopSignals := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stopSignals()
+	go func() {
+		if err := server.Serve(); err != nil {
+			logger.Errorw("Serve failed with error", "Err", err)
+			stopSignals()
+		}
+	}()
+
+	<-signalCtx.Done()
+	logger.Infow("Received shutdown signal, stopping servers", "timeout", appConfig.ShutdownTimeout)
+	shutdownCtx, cancelShutdown := context.WithTimeout(context.Background(), appConfig.ShutdownTimeout)
+	defer cancelShutdown()
+	logger.LogIfErr("Failed to stop health check server", healthServer.Shutdown(shutdownCtx))
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		logger.LogIfErr("Failed to stop metadata server", server.GracefulStop())
+	}()
+	select {
+	case <-stopped:
+		logger.Info("Servers stopped, shutting down")
+	case <-shutdownCtx.Done():
+		logger.Warnw("Timed out waiting for in-flight requests, shutting down", "timeout", appConfig.ShutdownTimeout)
 	}
 }
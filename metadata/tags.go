@@ -0,0 +1,151 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+//
+// Copyright 2024 FeatureForm Inc.
+//
+
+package metadata
+
+import (
+	"context"
+	"fmt"
+	"unicode/utf8"
+
+	"google.golang.org/protobuf/proto"
+
+	"github.com/featureform/fferr"
+	"github.com/featureform/logging"
+	pb "github.com/featureform/metadata/proto"
+)
+
+const (
+	// MaxTags is the most tags a variant can have.
+	MaxTags = 64
+	// MaxTagLength is the longest a tag can be, in characters.
+	MaxTagLength = 128
+)
+
+// AddTags adds tags to an existing variant through its Update path, so they're
+// indexed in search without the variant having to be registered again.
+func (serv *MetadataServer) AddTags(ctx context.Context, request *pb.UpdateTagsRequest) (*pb.UpdateTagsResponse, error) {
+	ctx = logging.AttachRequestID(logging.RequestID(request.RequestId), ctx, serv.Logger)
+	logger := logging.GetLoggerFromContext(ctx)
+	logger.Infow("Adding tags", "resource_id", request.ResourceId, "tags", request.Tags)
+
+	resId, err := serv.taggedVariant(ctx, request)
+	if err != nil {
+		logger.Errorw("Could not find variant to tag", "error", err)
+		return nil, err
+	}
+	updated, err := serv.lookup.Update(ctx, resId, func(variant Resource) (Resource, error) {
+		// Check the limit before updating since Update merges the tags into
+		// variant itself.
+		current := variant.Proto().(taggedProto).GetTags()
+		if count := len(UnionTags(current, &pb.Tags{Tag: request.Tags}).GetTag()); count > MaxTags {
+			return nil, fferr.NewInvalidArgumentErrorf("%s would have %d tags, the most a variant can have is %d", resId, count, MaxTags)
+		}
+		update, err := variantWithTags(variant, &pb.Tags{Tag: request.Tags})
+		if err != nil {
+			return nil, err
+		}
+		if err := variant.Update(serv.lookup, update); err != nil {
+			return nil, err
+		}
+		return variant, nil
+	})
+	if err != nil {
+		logger.Errorw("Could not add tags", "error", err)
+		return nil, err
+	}
+	return &pb.UpdateTagsResponse{Tags: updated.Proto().(taggedProto).GetTags().GetTag()}, nil
+}
+
+// RemoveTags removes tags from an existing variant. Tags the variant doesn't
+// have are ignored.
+func (serv *MetadataServer) RemoveTags(ctx context.Context, request *pb.UpdateTagsRequest) (*pb.UpdateTagsResponse, error) {
+	ctx = logging.AttachRequestID(logging.RequestID(request.RequestId), ctx, serv.Logger)
+	logger := logging.GetLoggerFromContext(ctx)
+	logger.Infow("Removing tags", "resource_id", request.ResourceId, "tags", request.Tags)
+
+	resId, err := serv.taggedVariant(ctx, request)
+	if err != nil {
+		logger.Errorw("Could not find variant to untag", "error", err)
+		return nil, err
+	}
+	updated, err := serv.lookup.Update(ctx, resId, func(variant Resource) (Resource, error) {
+		tags := DifferenceTags(variant.Proto().(taggedProto).GetTags(), &pb.Tags{Tag: request.Tags})
+		return variantWithTags(variant, tags)
+	})
+	if err != nil {
+		logger.Errorw("Could not remove tags", "error", err)
+		return nil, err
+	}
+	return &pb.UpdateTagsResponse{Tags: updated.Proto().(taggedProto).GetTags().GetTag()}, nil
+}
+
+type taggedProto interface {
+	GetTags() *pb.Tags
+}
+
+// taggedVariant validates the request's tags and checks that the variant they
+// apply to exists and that the caller owns it.
+func (serv *MetadataServer) taggedVariant(ctx context.Context, request *pb.UpdateTagsRequest) (ResourceID, error) {
+	resId := parseResourceIDProto(request.ResourceId)
+	if _, taggable := parentMapping[resId.Type]; !taggable {
+		return ResourceID{}, fferr.NewInvalidResourceTypeError(resId.Name, resId.Variant, fferr.ResourceType(resId.Type.String()), fmt.Errorf("only feature, label, source, and training set variants can be tagged"))
+	}
+	if err := validateTags(request.Tags); err != nil {
+		return ResourceID{}, err
+	}
+	resource, err := serv.lookup.Lookup(ctx, resId)
+	if err != nil {
+		return ResourceID{}, err
+	}
+	if err := serv.authorize(ctx, "tag", resId, resource); err != nil {
+		return ResourceID{}, err
+	}
+	return resId, nil
+}
+
+func validateTags(tags []string) error {
+	if len(tags) == 0 {
+		return fferr.NewInvalidArgumentErrorf("at least one tag is required")
+	}
+	if len(tags) > MaxTags {
+		return fferr.NewInvalidArgumentErrorf("%d tags given, the most a variant can have is %d", len(tags), MaxTags)
+	}
+	for _, tag := range tags {
+		if tag == "" {
+			return fferr.NewInvalidArgumentErrorf("tags cannot be empty")
+		}
+		if length := utf8.RuneCountInString(tag); length > MaxTagLength {
+			return fferr.NewInvalidArgumentErrorf("tag %q is %d characters, the longest a tag can be is %d", tag, length, MaxTagLength)
+		}
+	}
+	return nil
+}
+
+// variantWithTags returns a copy of variant with its tags replaced.
+func variantWithTags(variant Resource, tags *pb.Tags) (Resource, error) {
+	switch casted := variant.(type) {
+	case *sourceVariantResource:
+		serialized := proto.Clone(casted.serialized).(*pb.SourceVariant)
+		serialized.Tags = tags
+		return &sourceVariantResource{serialized}, nil
+	case *featureVariantResource:
+		serialized := proto.Clone(casted.serialized).(*pb.FeatureVariant)
+		serialized.Tags = tags
+		return &featureVariantResource{serialized}, nil
+	case *labelVariantResource:
+		serialized := proto.Clone(casted.serialized).(*pb.LabelVariant)
+		serialized.Tags = tags
+		return &labelVariantResource{serialized}, nil
+	case *trainingSetVariantResource:
+		serialized := proto.Clone(casted.serialized).(*pb.TrainingSetVariant)
+		serialized.Tags = tags
+		return &trainingSetVariantResource{serialized}, nil
+	default:
+		return nil, fferr.NewInternalErrorf("cannot set tags on %T", variant)
+	}
+}
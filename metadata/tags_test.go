@@ -0,0 +1,153 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+//
+// Copyright 2024 FeatureForm Inc.
+//
+
+package metadata
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/featureform/logging"
+	pb "github.com/featureform/metadata/proto"
+	"github.com/featureform/metadata/search"
+	"github.com/featureform/scheduling"
+)
+
+func TestAddRemoveTags(t *testing.T) {
+	ctx, logger := logging.NewTestContextAndLogger(t)
+	manager, err := scheduling.NewMemoryTaskMetadataManager(ctx)
+	if err != nil {
+		t.Fatalf("Failed to create memory task metadata manager: %v", err)
+	}
+	searcher := &memorySearcher{docs: make(map[string]search.ResourceDoc)}
+	serv := &MetadataServer{
+		Logger: logger,
+		lookup: &SearchWrapper{
+			Searcher:       searcher,
+			ResourceLookup: &MemoryResourceLookup{Connection: manager.Storage},
+		},
+	}
+	id := ResourceID{Name: "fraud", Variant: "v1", Type: FEATURE_VARIANT}
+	feature := &featureVariantResource{serialized: &pb.FeatureVariant{Name: id.Name, Variant: id.Variant, Tags: &pb.Tags{Tag: []string{"pii"}}}}
+	if err := serv.lookup.Set(ctx, id, feature); err != nil {
+		t.Fatalf("Failed to set %s: %v", id, err)
+	}
+	searchTags := func() []string {
+		t.Helper()
+		doc, has := searcher.docs[search.DocumentID(search.ResourceDoc{Name: id.Name, Type: id.Type.String(), Variant: id.Variant})]
+		if !has {
+			t.Fatalf("Expected %s to be in search", id)
+		}
+		return doc.Tags
+	}
+	storedTags := func() []string {
+		t.Helper()
+		res, err := serv.lookup.Lookup(ctx, id)
+		if err != nil {
+			t.Fatalf("Failed to look up %s: %v", id, err)
+		}
+		return res.(*featureVariantResource).serialized.Tags.GetTag()
+	}
+
+	resp, err := serv.AddTags(ctx, &pb.UpdateTagsRequest{ResourceId: id.Proto(), Tags: []string{"reviewed", "pii"}})
+	if err != nil {
+		t.Fatalf("Failed to add tags: %v", err)
+	}
+	expected := []string{"pii", "reviewed"}
+	if !reflect.DeepEqual(resp.Tags, expected) || !reflect.DeepEqual(storedTags(), expected) || !reflect.DeepEqual(searchTags(), expected) {
+		t.Fatalf("Expected tags %v, got response %v, stored %v, and searchable %v", expected, resp.Tags, storedTags(), searchTags())
+	}
+
+	resp, err = serv.RemoveTags(ctx, &pb.UpdateTagsRequest{ResourceId: id.Proto(), Tags: []string{"pii", "missing"}})
+	if err != nil {
+		t.Fatalf("Failed to remove tags: %v", err)
+	}
+	expected = []string{"reviewed"}
+	if !reflect.DeepEqual(resp.Tags, expected) || !reflect.DeepEqual(storedTags(), expected) || !reflect.DeepEqual(searchTags(), expected) {
+		t.Fatalf("Expected tags %v, got response %v, stored %v, and searchable %v", expected, resp.Tags, storedTags(), searchTags())
+	}
+
+	tooMany := make([]string, MaxTags)
+	for i := range tooMany {
+		tooMany[i] = strings.Repeat("t", i+1)
+	}
+	invalid := map[string]*pb.UpdateTagsRequest{
+		"No Tags":         {ResourceId: id.Proto()},
+		"Empty Tag":       {ResourceId: id.Proto(), Tags: []string{""}},
+		"Long Tag":        {ResourceId: id.Proto(), Tags: []string{strings.Repeat("t", MaxTagLength+1)}},
+		"Too Many Tags":   {ResourceId: id.Proto(), Tags: tooMany},
+		"Untaggable Type": {ResourceId: ResourceID{Name: "user", Type: USER}.Proto(), Tags: []string{"pii"}},
+		"Missing Variant": {ResourceId: ResourceID{Name: "fraud", Variant: "v2", Type: FEATURE_VARIANT}.Proto(), Tags: []string{"pii"}},
+	}
+	for name, req := range invalid {
+		t.Run(name, func(t *testing.T) {
+			if _, err := serv.AddTags(ctx, req); err == nil {
+				t.Fatalf("Expected adding tags to fail")
+			}
+		})
+	}
+	if tags := storedTags(); !reflect.DeepEqual(tags, expected) {
+		t.Fatalf("Expected failed requests to leave tags %v, got %v", expected, tags)
+	}
+}
+
+func TestConcurrentTagUpdates(t *testing.T) {
+	ctx, logger := logging.NewTestContextAndLogger(t)
+	manager, err := scheduling.NewMemoryTaskMetadataManager(ctx)
+	if err != nil {
+		t.Fatalf("Failed to create memory task metadata manager: %v", err)
+	}
+	serv := &MetadataServer{
+		Logger: logger,
+		lookup: &MemoryResourceLookup{Connection: manager.Storage},
+	}
+	id := ResourceID{Name: "fraud", Variant: "v1", Type: FEATURE_VARIANT}
+	feature := &featureVariantResource{serialized: &pb.FeatureVariant{Name: id.Name, Variant: id.Variant, Tags: &pb.Tags{Tag: []string{"stale"}}}}
+	if err := serv.lookup.Set(ctx, id, feature); err != nil {
+		t.Fatalf("Failed to set %s: %v", id, err)
+	}
+	const numTags = 20
+	var wg sync.WaitGroup
+	errs := make(chan error, numTags+1)
+	for i := 0; i < numTags; i++ {
+		wg.Add(1)
+		go func(tag string) {
+			defer wg.Done()
+			if _, err := serv.AddTags(ctx, &pb.UpdateTagsRequest{ResourceId: id.Proto(), Tags: []string{tag}}); err != nil {
+				errs <- err
+			}
+		}(fmt.Sprintf("tag_%02d", i))
+	}
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		if _, err := serv.RemoveTags(ctx, &pb.UpdateTagsRequest{ResourceId: id.Proto(), Tags: []string{"stale"}}); err != nil {
+			errs <- err
+		}
+	}()
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Fatalf("Failed to update tags: %v", err)
+	}
+	res, err := serv.lookup.Lookup(ctx, id)
+	if err != nil {
+		t.Fatalf("Failed to look up %s: %v", id, err)
+	}
+	tags := res.(*featureVariantResource).serialized.Tags.GetTag()
+	if len(tags) != numTags {
+		t.Fatalf("Expected %d tags after concurrent updates, got %v", numTags, tags)
+	}
+	for _, tag := range tags {
+		if tag == "stale" {
+			t.Fatalf("Expected removed tag to stay removed, got %v", tags)
+		}
+	}
+}
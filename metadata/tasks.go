@@ -37,6 +37,13 @@ type TaskService interface {
 	SetRunStatus(tid s.TaskID, runID s.TaskRunID, status s.Status, errMsg error) error
 	SetRunResumeID(tid s.TaskID, runID s.TaskRunID, resumeID ptypes.ResumeID) error
 	AddRunLog(taskID s.TaskID, runID s.TaskRunID, msg string) error
+	IncrementRunAttempts(tid s.TaskID, runID s.TaskRunID) (int, error)
+	SetRunProgress(tid s.TaskID, runID s.TaskRunID, progress s.RunProgress) error
+	// GetTransformationCacheEntry returns an entry with an empty hash if the
+	// transformation doesn't have one.
+	GetTransformationCacheEntry(name, variant string) (s.TransformationCacheEntry, error)
+	SetTransformationCacheEntry(name, variant, hash string) error
+	DeleteTransformationCacheEntry(name, variant string) error
 	EndRun(tid s.TaskID, runID s.TaskRunID) error
 }
 
@@ -264,6 +271,60 @@ func (t *Tasks) AddRunLog(tid s.TaskID, runID s.TaskRunID, msg string) error {
 	return nil
 }
 
+// IncrementRunAttempts records a retry of the run and returns how many times
+// it has been attempted.
+func (t *Tasks) IncrementRunAttempts(tid s.TaskID, runID s.TaskRunID) (int, error) {
+	t.logger.Debugw("Incrementing run attempts", "task_id", tid.String(), "run_id", runID.String())
+	id := &schproto.TaskRunID{RunID: &schproto.RunID{Id: runID.String()}, TaskID: &schproto.TaskID{Id: tid.String()}}
+	resp, err := t.GrpcConn.IncrementRunAttempts(context.Background(), id)
+	if err != nil {
+		return 0, err
+	}
+	return int(resp.GetAttempts()), nil
+}
+
+func (t *Tasks) SetRunProgress(tid s.TaskID, runID s.TaskRunID, progress s.RunProgress) error {
+	t.logger.Debugw("Setting run progress", "task_id", tid.String(), "run_id", runID.String(), "phase", progress.Phase, "rows_processed", progress.RowsProcessed, "total_rows", progress.TotalRows)
+	update := &schproto.RunProgressUpdate{
+		RunID:    &schproto.RunID{Id: runID.String()},
+		TaskID:   &schproto.TaskID{Id: tid.String()},
+		Progress: progress.ToProto(),
+	}
+	_, err := t.GrpcConn.SetRunProgress(context.Background(), update)
+	return err
+}
+
+func (t *Tasks) GetTransformationCacheEntry(name, variant string) (s.TransformationCacheEntry, error) {
+	t.logger.Debugw("Getting transformation cache entry", "name", name, "variant", variant)
+	entry, err := t.GrpcConn.GetTransformationCacheEntry(
+		context.Background(),
+		&schproto.TransformationCacheKey{Name: name, Variant: variant},
+	)
+	if err != nil {
+		return s.TransformationCacheEntry{}, err
+	}
+	return s.TransformationCacheEntry{Hash: entry.GetHash(), CreatedAt: entry.GetCreated().AsTime()}, nil
+}
+
+func (t *Tasks) SetTransformationCacheEntry(name, variant, hash string) error {
+	t.logger.Debugw("Setting transformation cache entry", "name", name, "variant", variant, "hash", hash)
+	update := &schproto.TransformationCacheUpdate{
+		Key:  &schproto.TransformationCacheKey{Name: name, Variant: variant},
+		Hash: hash,
+	}
+	_, err := t.GrpcConn.SetTransformationCacheEntry(context.Background(), update)
+	return err
+}
+
+func (t *Tasks) DeleteTransformationCacheEntry(name, variant string) error {
+	t.logger.Debugw("Deleting transformation cache entry", "name", name, "variant", variant)
+	_, err := t.GrpcConn.DeleteTransformationCacheEntry(
+		context.Background(),
+		&schproto.TransformationCacheKey{Name: name, Variant: variant},
+	)
+	return err
+}
+
 func (t *Tasks) EndRun(tid s.TaskID, runID s.TaskRunID) error {
 	t.logger.Debugw("Ending run", "task_id", tid.String(), "run_id", runID.String())
 	update := &schproto.RunEndTimeUpdate{
@@ -35,6 +35,9 @@ func unionNameVariants(destination, source []*pb.NameVariant) []*pb.NameVariant
 }
 
 func UnionTags(destination, source *pb.Tags) *pb.Tags {
+	if destination == nil {
+		destination = &pb.Tags{}
+	}
 	set := make(map[string]bool)
 
 	for _, tag := range destination.GetTag() {
@@ -50,6 +53,21 @@ func UnionTags(destination, source *pb.Tags) *pb.Tags {
 	return destination
 }
 
+// DifferenceTags returns the tags in destination that aren't in source.
+func DifferenceTags(destination, source *pb.Tags) *pb.Tags {
+	remove := make(map[string]bool)
+	for _, tag := range source.GetTag() {
+		remove[tag] = true
+	}
+	tags := &pb.Tags{Tag: make([]string, 0, len(destination.GetTag()))}
+	for _, tag := range destination.GetTag() {
+		if !remove[tag] {
+			tags.Tag = append(tags.Tag, tag)
+		}
+	}
+	return tags
+}
+
 func mergeProperties(destination, source *pb.Properties) *pb.Properties {
 	if destination == nil {
 		destination = &pb.Properties{}
@@ -23,3 +23,17 @@ type NoOpFeatureObserver struct{}
 func (nop *NoOpFeatureObserver) SetError() {}
 func (nop *NoOpFeatureObserver) ServeRow() {}
 func (nop *NoOpFeatureObserver) Finish()   {}
+
+type NoOpJobMetricsHandler struct{}
+
+func (nop *NoOpJobMetricsHandler) BeginObservingJob(resourceType string) JobObserver {
+	return &NoOpJobObserver{}
+}
+func (nop *NoOpJobMetricsHandler) ExposePort(port string) {}
+
+type NoOpJobObserver struct{}
+
+func (nop *NoOpJobObserver) SetProvider(provider string) {}
+func (nop *NoOpJobObserver) AddRows(rows int64)          {}
+func (nop *NoOpJobObserver) SetError()                   {}
+func (nop *NoOpJobObserver) Finish()                     {}
@@ -0,0 +1,127 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+//
+// Copyright 2024 FeatureForm Inc.
+//
+
+package metrics
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// JobMetricsHandler observes the materialization jobs run by the coordinator.
+type JobMetricsHandler interface {
+	BeginObservingJob(resourceType string) JobObserver
+	ExposePort(port string)
+}
+
+// JobObserver tracks a single job run. The provider is usually only known once
+// the job has started, so it's set by the job itself rather than up front.
+type JobObserver interface {
+	SetProvider(provider string)
+	AddRows(rows int64)
+	SetError()
+	Finish()
+}
+
+type PromJobMetricsHandler struct {
+	Duration *prometheus.HistogramVec
+	Jobs     *prometheus.CounterVec
+	Rows     *prometheus.CounterVec
+	Name     string
+}
+
+type PromJobObserver struct {
+	Timer        *prometheus.Timer
+	Handler      PromJobMetricsHandler
+	ResourceType string
+	mtx          sync.Mutex
+	provider     string
+	rows         int64
+	status       string
+}
+
+func NewJobMetrics(name string) PromJobMetricsHandler {
+	jobCounter := prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: fmt.Sprintf("%sjobs", name),
+			Help: "Counter for finished materialization jobs, labeled by resource type, provider and status",
+		},
+		[]string{"instance", "resource_type", "provider", "status"},
+	)
+	jobDuration := prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name: fmt.Sprintf("%sjob_duration_seconds", name),
+			Help: "Duration of materialization jobs, labeled by resource type, provider and status",
+			// From one second up to roughly nine hours.
+			Buckets: prometheus.ExponentialBuckets(1, 2, 16),
+		},
+		[]string{"instance", "resource_type", "provider", "status"},
+	)
+	rowCounter := prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: fmt.Sprintf("%sjob_rows", name),
+			Help: "Counter for rows processed by materialization jobs, labeled by resource type and provider",
+		},
+		[]string{"instance", "resource_type", "provider"},
+	)
+	prometheus.MustRegister(jobCounter)
+	prometheus.MustRegister(jobDuration)
+	prometheus.MustRegister(rowCounter)
+	return PromJobMetricsHandler{
+		Duration: jobDuration,
+		Jobs:     jobCounter,
+		Rows:     rowCounter,
+		Name:     name,
+	}
+}
+
+func (p PromJobMetricsHandler) BeginObservingJob(resourceType string) JobObserver {
+	obs := &PromJobObserver{
+		Handler:      p,
+		ResourceType: resourceType,
+		status:       "running",
+	}
+	obs.Timer = prometheus.NewTimer(prometheus.ObserverFunc(func(v float64) {
+		p.Duration.WithLabelValues(p.Name, resourceType, obs.provider, obs.status).Observe(v)
+	}))
+	return obs
+}
+
+func (p PromJobMetricsHandler) ExposePort(port string) {
+	PromMetricsHandler{Name: p.Name}.ExposePort(port)
+}
+
+func (p *PromJobObserver) SetProvider(provider string) {
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+	p.provider = provider
+}
+
+func (p *PromJobObserver) AddRows(rows int64) {
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+	p.rows += rows
+}
+
+func (p *PromJobObserver) SetError() {
+	p.end(string(ERROR))
+}
+
+func (p *PromJobObserver) Finish() {
+	p.end(string(SUCCESS))
+}
+
+func (p *PromJobObserver) end(status string) {
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+	p.status = status
+	p.Timer.ObserveDuration()
+	p.Handler.Jobs.WithLabelValues(p.Handler.Name, p.ResourceType, p.provider, status).Inc()
+	p.Handler.Rows.WithLabelValues(p.Handler.Name, p.ResourceType, p.provider).Add(float64(p.rows))
+}
@@ -127,3 +127,40 @@ func TestMetrics(t *testing.T) {
 	assert.Equal(t, int(latencyTrainingCounterValue), latencyTrainingCount, "Training latency records 6 events")
 
 }
+
+func TestJobMetrics(t *testing.T) {
+	instanceName := "jobtest"
+	jobMetrics := NewJobMetrics(instanceName)
+	resourceType := "FEATURE_VARIANT"
+	provider := "SNOWFLAKE_OFFLINE"
+
+	success := jobMetrics.BeginObservingJob(resourceType)
+	success.SetProvider(provider)
+	success.AddRows(10)
+	success.AddRows(5)
+	success.Finish()
+	failure := jobMetrics.BeginObservingJob(resourceType)
+	failure.SetProvider(provider)
+	failure.SetError()
+
+	successCount, err := GetCounterValue(jobMetrics.Jobs, instanceName, resourceType, provider, string(SUCCESS))
+	if err != nil {
+		t.Fatalf("Could not fetch value: %v", err)
+	}
+	assert.Equal(t, 1, int(successCount), "1 successful job should be recorded")
+	errorCount, err := GetCounterValue(jobMetrics.Jobs, instanceName, resourceType, provider, string(ERROR))
+	if err != nil {
+		t.Fatalf("Could not fetch value: %v", err)
+	}
+	assert.Equal(t, 1, int(errorCount), "1 failed job should be recorded")
+	rows, err := GetCounterValue(jobMetrics.Rows, instanceName, resourceType, provider)
+	if err != nil {
+		t.Fatalf("Could not fetch value: %v", err)
+	}
+	assert.Equal(t, 15, int(rows), "15 rows should be recorded")
+	durations, err := GetHistogramValue(jobMetrics.Duration, instanceName, resourceType, provider, string(SUCCESS))
+	if err != nil {
+		t.Fatalf("Could not fetch value: %v", err)
+	}
+	assert.Equal(t, 1, int(durations), "1 successful job duration should be recorded")
+}
@@ -0,0 +1,498 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+//
+// Copyright 2024 FeatureForm Inc.
+//
+
+package provider
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/featureform/fferr"
+	"github.com/featureform/filestore"
+	"github.com/linkedin/goavro/v2"
+)
+
+// Longs with this logical type are decoded to and encoded from time.Time.
+const avroTimestampMicros = "timestamp-micros"
+
+// readAvroContainer decodes every record in an Avro object container file.
+// Records come back as maps keyed by field name; unions decode to the value of
+// their branch, with null branches decoding to nil.
+func readAvroContainer(data []byte) ([]map[string]interface{}, error) {
+	_, records, err := decodeAvroContainer(data)
+	return records, err
+}
+
+// decodeAvroContainer decodes an Avro object container file, returning the names
+// of its record's fields along with its records.
+func decodeAvroContainer(data []byte) ([]string, []map[string]interface{}, error) {
+	reader, err := goavro.NewOCFReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, nil, avroReadError(err)
+	}
+	schema, err := newAvroSchema(reader.Codec())
+	if err != nil {
+		return nil, nil, err
+	}
+	records := make([]map[string]interface{}, 0)
+	for reader.Scan() {
+		datum, err := reader.Read()
+		if err != nil {
+			return nil, nil, avroReadError(err)
+		}
+		record, ok := schema.native(datum).(map[string]interface{})
+		if !ok {
+			return nil, nil, fferr.NewInvalidArgumentErrorf("expected Avro records, got %T", datum)
+		}
+		records = append(records, record)
+	}
+	if err := reader.Err(); err != nil {
+		return nil, nil, avroReadError(err)
+	}
+	return schema.fieldNames(), records, nil
+}
+
+// getAvroNumRows counts the records in an Avro object container file using the
+// counts in its block headers, without decoding the records.
+func getAvroNumRows(data []byte) (int64, error) {
+	reader, err := goavro.NewOCFReader(bytes.NewReader(data))
+	if err != nil {
+		return 0, avroReadError(err)
+	}
+	rows := int64(0)
+	for reader.Scan() {
+		rows += reader.RemainingBlockItems()
+		reader.SkipThisBlockAndReset()
+	}
+	if err := reader.Err(); err != nil {
+		return 0, avroReadError(err)
+	}
+	return rows, nil
+}
+
+func avroReadError(err error) error {
+	return fferr.NewInvalidArgumentErrorf("could not decode Avro data: %v", err)
+}
+
+// encodeAvroContainer encodes records as a deflate compressed Avro object
+// container file. rawSchema must be a record schema, such as one derived with
+// TableSchema.AsAvroSchema, and each record's values must be in the order of its
+// fields. Values of union fields are written as the union's first non-null type.
+func encodeAvroContainer(rawSchema string, records []GenericRecord) ([]byte, error) {
+	out := new(bytes.Buffer)
+	writer, err := goavro.NewOCFWriter(goavro.OCFConfig{
+		W:               out,
+		Schema:          rawSchema,
+		CompressionName: goavro.CompressionDeflateLabel,
+	})
+	if err != nil {
+		return nil, fferr.NewInvalidArgumentErrorf("invalid Avro schema: %v", err)
+	}
+	schema, err := newAvroSchema(writer.Codec())
+	if err != nil {
+		return nil, err
+	}
+	fields := schema.fields()
+	if fields == nil {
+		return nil, fferr.NewInvalidArgumentErrorf("expected an Avro record schema, got %s", rawSchema)
+	}
+	// An empty block can't be read back, so a file without records is only a header.
+	if len(records) == 0 {
+		return out.Bytes(), nil
+	}
+	natives := make([]interface{}, len(records))
+	for i, record := range records {
+		if len(record) != len(fields) {
+			return nil, fferr.NewInvalidArgumentErrorf("record has %d values but the Avro schema has %d fields", len(record), len(fields))
+		}
+		native := make(map[string]interface{}, len(fields))
+		for j, field := range fields {
+			name, _ := field["name"].(string)
+			native[name] = avroUnionValue(field["type"], record[j])
+		}
+		natives[i] = native
+	}
+	if err := writer.Append(natives); err != nil {
+		return nil, fferr.NewInvalidArgumentErrorf("could not encode Avro records: %v", err)
+	}
+	return out.Bytes(), nil
+}
+
+// avroUnionValue wraps a value of a union in the map goavro encodes unions
+// from, using the union's first non-null type. Integers are widened to the
+// types goavro accepts.
+func avroUnionValue(schema interface{}, value interface{}) interface{} {
+	if value == nil {
+		return nil
+	}
+	if widened, ok := avroInteger(value); ok {
+		value = widened
+	}
+	branches, ok := schema.([]interface{})
+	if !ok {
+		return value
+	}
+	for _, branch := range branches {
+		if name := avroTypeName(branch); name != "null" {
+			return goavro.Union(name, value)
+		}
+	}
+	return value
+}
+
+// avroInteger converts the Go integer types that goavro doesn't accept to an
+// int64.
+func avroInteger(value interface{}) (int64, bool) {
+	switch v := value.(type) {
+	case int8:
+		return int64(v), true
+	case int16:
+		return int64(v), true
+	case uint8:
+		return int64(v), true
+	case uint16:
+		return int64(v), true
+	case uint32:
+		return int64(v), true
+	case uint64:
+		if v > math.MaxInt64 {
+			return 0, false
+		}
+		return int64(v), true
+	default:
+		return 0, false
+	}
+}
+
+// avroSchema is the JSON form of a goavro codec's schema. goavro decodes each
+// union's value to a map keyed by the name of its type, and the schema is used
+// to tell those maps apart from records and maps so they can be unwrapped.
+type avroSchema struct {
+	root interface{}
+	// named holds the named types that the schema defines.
+	named map[string]interface{}
+}
+
+func newAvroSchema(codec *goavro.Codec) (*avroSchema, error) {
+	var root interface{}
+	if err := json.Unmarshal([]byte(codec.Schema()), &root); err != nil {
+		return nil, fferr.NewInvalidArgumentErrorf("could not parse Avro schema: %v", err)
+	}
+	schema := &avroSchema{root: root, named: map[string]interface{}{}}
+	schema.register(root)
+	return schema, nil
+}
+
+func (s *avroSchema) register(schema interface{}) {
+	switch casted := schema.(type) {
+	case []interface{}:
+		for _, branch := range casted {
+			s.register(branch)
+		}
+	case map[string]interface{}:
+		if name, ok := casted["name"].(string); ok {
+			s.named[name] = casted
+			if namespace, ok := casted["namespace"].(string); ok && namespace != "" {
+				s.named[namespace+"."+name] = casted
+			}
+		}
+		fields, _ := casted["fields"].([]interface{})
+		for _, rawField := range fields {
+			if field, ok := rawField.(map[string]interface{}); ok {
+				s.register(field["type"])
+			}
+		}
+		s.register(casted["items"])
+		s.register(casted["values"])
+	}
+}
+
+// fields returns the fields of a record schema, or nil for other schemas.
+func (s *avroSchema) fields() []map[string]interface{} {
+	record, ok := s.root.(map[string]interface{})
+	if !ok || (record["type"] != "record" && record["type"] != "error") {
+		return nil
+	}
+	rawFields, _ := record["fields"].([]interface{})
+	fields := make([]map[string]interface{}, 0, len(rawFields))
+	for _, rawField := range rawFields {
+		if field, ok := rawField.(map[string]interface{}); ok {
+			fields = append(fields, field)
+		}
+	}
+	return fields
+}
+
+func (s *avroSchema) fieldNames() []string {
+	fields := s.fields()
+	names := make([]string, len(fields))
+	for i, field := range fields {
+		names[i], _ = field["name"].(string)
+	}
+	return names
+}
+
+// native unwraps the unions in a value decoded with the schema.
+func (s *avroSchema) native(value interface{}) interface{} {
+	return s.unwrap(s.root, value)
+}
+
+func (s *avroSchema) unwrap(schema interface{}, value interface{}) interface{} {
+	if value == nil {
+		return nil
+	}
+	switch casted := schema.(type) {
+	case string:
+		if named, ok := s.named[casted]; ok {
+			return s.unwrap(named, value)
+		}
+	case []interface{}:
+		wrapped, ok := value.(map[string]interface{})
+		if !ok || len(wrapped) != 1 {
+			return value
+		}
+		for typeName, branchValue := range wrapped {
+			return s.unwrap(avroUnionBranch(casted, typeName), branchValue)
+		}
+	case map[string]interface{}:
+		switch casted["type"] {
+		case "record", "error":
+			record, ok := value.(map[string]interface{})
+			if !ok {
+				return value
+			}
+			fields, _ := casted["fields"].([]interface{})
+			for _, rawField := range fields {
+				field, _ := rawField.(map[string]interface{})
+				name, _ := field["name"].(string)
+				if fieldValue, has := record[name]; has {
+					record[name] = s.unwrap(field["type"], fieldValue)
+				}
+			}
+			return record
+		case "array":
+			items, ok := value.([]interface{})
+			if !ok {
+				return value
+			}
+			for i, item := range items {
+				items[i] = s.unwrap(casted["items"], item)
+			}
+			return items
+		case "map":
+			values, ok := value.(map[string]interface{})
+			if !ok {
+				return value
+			}
+			for key, item := range values {
+				values[key] = s.unwrap(casted["values"], item)
+			}
+			return values
+		}
+	}
+	return value
+}
+
+// avroUnionBranch returns the type of a union that goavro names typeName, or
+// nil if there's none.
+func avroUnionBranch(branches []interface{}, typeName string) interface{} {
+	for _, branch := range branches {
+		name := avroTypeName(branch)
+		// Named types can be referred to without the namespace goavro includes.
+		if name == typeName || strings.HasSuffix(typeName, "."+name) {
+			return branch
+		}
+	}
+	return nil
+}
+
+// avroTypeName returns the name goavro gives a type in a union: the name of a
+// named type, or the type followed by its logical type, if it has one.
+func avroTypeName(schema interface{}) string {
+	switch casted := schema.(type) {
+	case string:
+		return casted
+	case map[string]interface{}:
+		if name, ok := casted["name"].(string); ok {
+			if namespace, ok := casted["namespace"].(string); ok && namespace != "" {
+				return namespace + "." + name
+			}
+			return name
+		}
+		typeName := fmt.Sprint(casted["type"])
+		if logicalType, ok := casted["logicalType"].(string); ok {
+			return typeName + "." + logicalType
+		}
+		return typeName
+	default:
+		return ""
+	}
+}
+
+// avroIterator iterates over the records of one or more Avro files. Avro files
+// are decoded in full, so records are held in memory.
+type avroIterator struct {
+	records        []map[string]interface{}
+	idx            int
+	featureColumns []string
+	labelColumns   []string
+}
+
+func avroIteratorFromBytes(data []byte) (Iterator, error) {
+	return avroIteratorFromFiles([][]byte{data})
+}
+
+func avroIteratorOverMultipleFiles(files []filestore.Filepath, store FileStore) (Iterator, error) {
+	contents := make([][]byte, len(files))
+	for i, file := range files {
+		data, err := store.Read(file)
+		if err != nil {
+			return nil, err
+		}
+		contents[i] = data
+	}
+	return avroIteratorFromFiles(contents)
+}
+
+func avroIteratorFromFiles(contents [][]byte) (*avroIterator, error) {
+	iter := &avroIterator{records: make([]map[string]interface{}, 0)}
+	for i, data := range contents {
+		fieldNames, records, err := decodeAvroContainer(data)
+		if err != nil {
+			return nil, err
+		}
+		// Every file of a directory is written with the same schema, so the
+		// columns are taken from the first.
+		if i == 0 {
+			columns := parquetSchema{}
+			for _, name := range fieldNames {
+				columns.setColumn(columns.getColumnType(name), name)
+			}
+			iter.featureColumns = columns.featureColumns
+			iter.labelColumns = columns.labelColumns
+		}
+		iter.records = append(iter.records, records...)
+	}
+	return iter, nil
+}
+
+func (it *avroIterator) Next() (map[string]interface{}, error) {
+	if it.idx >= len(it.records) {
+		return nil, nil
+	}
+	row := it.records[it.idx]
+	it.idx++
+	for name, value := range row {
+		row[name] = avroToGoValue(value)
+	}
+	return row, nil
+}
+
+func (it *avroIterator) FeatureColumns() []string {
+	return it.featureColumns
+}
+
+func (it *avroIterator) LabelColumn() string {
+	return firstLabelColumn(it.labelColumns)
+}
+
+func (it *avroIterator) LabelColumns() []string {
+	return it.labelColumns
+}
+
+// avroTableIterator is the GenericTableIterator over Avro files.
+type avroTableIterator struct {
+	records       []map[string]interface{}
+	columns       []string
+	currentValues GenericRecord
+	idx           int64
+	limit         int64
+}
+
+func newMultipleFileAvroIterator(files []filestore.Filepath, store FileStore, limit int64) (GenericTableIterator, error) {
+	if len(files) == 0 {
+		return nil, fferr.NewInvalidArgumentError(fmt.Errorf("no files to read"))
+	}
+	if limit == -1 {
+		limit = math.MaxInt64
+	}
+	iter := &avroTableIterator{records: make([]map[string]interface{}, 0), limit: limit}
+	for i, file := range files {
+		if file.Ext() != filestore.Avro {
+			return nil, fferr.NewInvalidArgumentError(fmt.Errorf("one or more files have an extension that is not .avro: %s", file.Ext()))
+		}
+		data, err := store.Read(file)
+		if err != nil {
+			return nil, err
+		}
+		fieldNames, records, err := decodeAvroContainer(data)
+		if err != nil {
+			return nil, err
+		}
+		if i == 0 {
+			iter.columns = fieldNames
+		}
+		iter.records = append(iter.records, records...)
+	}
+	return iter, nil
+}
+
+func (it *avroTableIterator) Next() bool {
+	if it.idx >= it.limit || it.idx >= int64(len(it.records)) {
+		return false
+	}
+	row := it.records[it.idx]
+	values := make(GenericRecord, len(it.columns))
+	for i, column := range it.columns {
+		values[i] = avroToGoValue(row[column])
+	}
+	it.currentValues = values
+	it.idx++
+	return true
+}
+
+func (it *avroTableIterator) Values() GenericRecord {
+	return it.currentValues
+}
+
+func (it *avroTableIterator) Columns() []string {
+	return it.columns
+}
+
+func (it *avroTableIterator) Err() error {
+	return nil
+}
+
+func (it *avroTableIterator) Close() error {
+	return nil
+}
+
+// avroToGoValue converts a decoded Avro value to the types the parquet iterators
+// return: integers become int and float arrays become []float32.
+func avroToGoValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case int32:
+		return int(v)
+	case int64:
+		return int(v)
+	case []interface{}:
+		vec := make([]float32, len(v))
+		for i, item := range v {
+			f, ok := item.(float32)
+			if !ok {
+				return v
+			}
+			vec[i] = f
+		}
+		return vec
+	default:
+		return v
+	}
+}
@@ -0,0 +1,188 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+//
+// Copyright 2024 FeatureForm Inc.
+//
+
+package provider
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/featureform/filestore"
+	ps "github.com/featureform/provider/provider_schema"
+	"github.com/featureform/provider/types"
+)
+
+func testAvroFileStore(t *testing.T) FileStore {
+	t.Helper()
+	store, err := NewLocalFileStore([]byte(fmt.Sprintf(`{"DirPath": "file://%s/"}`, t.TempDir())))
+	if err != nil {
+		t.Fatalf("could not create local file store: %v", err)
+	}
+	return store
+}
+
+func TestAvroRoundTrip(t *testing.T) {
+	ts := time.Date(2024, 5, 1, 12, 30, 0, 123456000, time.UTC)
+	schema := TableSchema{
+		Columns: []TableColumn{
+			{Name: "entity", ValueType: types.String},
+			{Name: "count", ValueType: types.Int},
+			{Name: "small", ValueType: types.Int32},
+			{Name: "score", ValueType: types.Float64},
+			{Name: "active", ValueType: types.Bool},
+			{Name: "ts", ValueType: types.Timestamp},
+			{Name: "embedding", ValueType: types.VectorType{ScalarType: types.Float32, Dimension: 2}},
+		},
+	}
+	records := []GenericRecord{
+		{"a", 1, int32(2), 0.5, true, ts, []float32{1, 2}},
+		{"b", nil, nil, nil, nil, nil, nil},
+	}
+	rawSchema, err := schema.AsAvroSchema()
+	if err != nil {
+		t.Fatalf("could not derive Avro schema: %v", err)
+	}
+	data, err := encodeAvroContainer(rawSchema, records)
+	if err != nil {
+		t.Fatalf("could not encode records: %v", err)
+	}
+	rows, err := getAvroNumRows(data)
+	if err != nil {
+		t.Fatalf("could not count rows: %v", err)
+	}
+	if rows != int64(len(records)) {
+		t.Fatalf("expected %d rows, got %d", len(records), rows)
+	}
+
+	store := testAvroFileStore(t)
+	fp, err := store.CreateFilePath("table/part-00000.avro", false)
+	if err != nil {
+		t.Fatalf("could not create file path: %v", err)
+	}
+	if err := store.Write(fp, data); err != nil {
+		t.Fatalf("could not write file: %v", err)
+	}
+	iter, err := newMultipleFileAvroIterator([]filestore.Filepath{fp}, store, -1)
+	if err != nil {
+		t.Fatalf("could not create iterator: %v", err)
+	}
+	expectedColumns := []string{"entity", "count", "small", "score", "active", "ts", "embedding"}
+	if !reflect.DeepEqual(iter.Columns(), expectedColumns) {
+		t.Fatalf("expected columns %v, got %v", expectedColumns, iter.Columns())
+	}
+	expected := []GenericRecord{
+		{"a", 1, 2, 0.5, true, ts, []float32{1, 2}},
+		{"b", nil, nil, nil, nil, nil, nil},
+	}
+	for i := 0; iter.Next(); i++ {
+		if !reflect.DeepEqual(iter.Values(), expected[i]) {
+			t.Fatalf("row %d: expected %v, got %v", i, expected[i], iter.Values())
+		}
+	}
+	if err := iter.Err(); err != nil {
+		t.Fatalf("iterator failed: %v", err)
+	}
+}
+
+func TestAsAvroSchemaUnsupportedType(t *testing.T) {
+	schema := TableSchema{Columns: []TableColumn{{Name: "unknown", ValueType: types.NilType}}}
+	if _, err := schema.AsAvroSchema(); err == nil {
+		t.Fatalf("expected error deriving an Avro schema for a column without a type")
+	}
+}
+
+func TestEncodeAvroContainerInvalidValue(t *testing.T) {
+	schema := TableSchema{Columns: []TableColumn{{Name: "count", ValueType: types.Int}}}
+	rawSchema, err := schema.AsAvroSchema()
+	if err != nil {
+		t.Fatalf("could not derive Avro schema: %v", err)
+	}
+	if _, err := encodeAvroContainer(rawSchema, []GenericRecord{{"not an int"}}); err == nil {
+		t.Fatalf("expected error encoding a string as an int")
+	}
+	if _, err := encodeAvroContainer(rawSchema, []GenericRecord{{1, 2}}); err == nil {
+		t.Fatalf("expected error encoding a record with too many values")
+	}
+}
+
+func TestFileStoreMaterializationAvro(t *testing.T) {
+	store := testAvroFileStore(t)
+	id := ResourceID{Name: "avg_price", Variant: "v1", Type: Feature}
+	schema := TableSchema{
+		Columns: []TableColumn{
+			{Name: "entity", ValueType: types.String},
+			{Name: "value", ValueType: types.Float64},
+			{Name: "ts", ValueType: types.Timestamp},
+		},
+	}
+	rawSchema, err := schema.AsAvroSchema()
+	if err != nil {
+		t.Fatalf("could not derive Avro schema: %v", err)
+	}
+	ts := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	parts := [][]GenericRecord{
+		{{"a", 1.0, ts}},
+		{{"b", 2.0, ts.Add(time.Hour)}},
+	}
+	dir := ps.ResourceToDirectoryPath(id.Type.String(), id.Name, id.Variant)
+	for i, part := range parts {
+		data, err := encodeAvroContainer(rawSchema, part)
+		if err != nil {
+			t.Fatalf("could not encode records: %v", err)
+		}
+		writeIcebergTestFile(t, store, fmt.Sprintf("%s/2024-01-01-00-00-00-000000/part-%05d.avro", dir, i), data)
+	}
+	mat := FileStoreMaterialization{id: id, store: store}
+	rows, err := mat.NumRows()
+	if err != nil {
+		t.Fatalf("could not get row count: %v", err)
+	}
+	if rows != 2 {
+		t.Fatalf("expected 2 rows, got %d", rows)
+	}
+	chunks, err := mat.NumChunks()
+	if err != nil {
+		t.Fatalf("could not get chunk count: %v", err)
+	}
+	if chunks != len(parts) {
+		t.Fatalf("expected %d chunks, got %d", len(parts), chunks)
+	}
+	iter, err := mat.IterateSegment(0, rows)
+	if err != nil {
+		t.Fatalf("could not iterate materialization: %v", err)
+	}
+	records := make([]ResourceRecord, 0)
+	for iter.Next() {
+		records = append(records, iter.Value())
+	}
+	if err := iter.Err(); err != nil {
+		t.Fatalf("iterator failed: %v", err)
+	}
+	expected := []ResourceRecord{
+		{Entity: "a", Value: 1.0, TS: ts},
+		{Entity: "b", Value: 2.0, TS: ts.Add(time.Hour)},
+	}
+	if !reflect.DeepEqual(records, expected) {
+		t.Fatalf("expected %v, got %v", expected, records)
+	}
+	maxTS, err := mat.maxTimestamp()
+	if err != nil {
+		t.Fatalf("could not get max timestamp: %v", err)
+	}
+	if !maxTS.Equal(ts.Add(time.Hour)) {
+		t.Fatalf("expected max timestamp %v, got %v", ts.Add(time.Hour), maxTS)
+	}
+}
+
+func TestHDFSServeEmptyDirectory(t *testing.T) {
+	store := &HDFSFileStore{}
+	if _, err := store.ServeDirectory(nil); err == nil {
+		t.Fatalf("expected an error serving an empty directory")
+	}
+}
@@ -17,6 +17,7 @@ import (
 
 	"cloud.google.com/go/bigquery"
 	"github.com/featureform/fferr"
+	"github.com/featureform/filestore"
 	"github.com/featureform/logging"
 	"github.com/featureform/metadata"
 	pl "github.com/featureform/provider/location"
@@ -47,6 +48,7 @@ const (
 type defaultBQQueries struct {
 	ProjectId string
 	DatasetId string
+	Labels    map[string]string
 	Ctx       context.Context
 	logger    logging.Logger
 }
@@ -80,7 +82,7 @@ func (pt *bqPrimaryTable) IterateSegment(n int64) (GenericTableIterator, error)
 	} else {
 		query = fmt.Sprintf("SELECT * FROM `%s` LIMIT %d", tableName, n)
 	}
-	bqQ := pt.client.Query(query)
+	bqQ := pt.query.newQuery(pt.client, query)
 
 	columns, err := pt.query.getColumns(pt.client, pt.name)
 	if err != nil {
@@ -105,7 +107,7 @@ func (pt *bqPrimaryTable) NumRows() (int64, error) {
 	tableName := pt.query.getTableName(pt.name)
 	query := fmt.Sprintf("SELECT COUNT(*) FROM `%s`", tableName)
 
-	bqQ := pt.client.Query(query)
+	bqQ := pt.query.newQuery(pt.client, query)
 
 	it, err := bqQ.Read(pt.query.getContext())
 	if err != nil {
@@ -288,7 +290,7 @@ func (q defaultBQQueries) registerResources(client *bigquery.Client, tableName s
 	sb.WriteString(fmt.Sprintf("FROM `%s`", q.getTableNameFromLocation(*sourceLocation)))
 
 	logger.Infow("Running register resource query", "query", sb.String())
-	bqQ := client.Query(sb.String())
+	bqQ := q.newQuery(client, sb.String())
 	if _, err := bqQ.Read(q.getContext()); err != nil {
 		logger.Errorw("Failed to register resource", "error", err)
 		wrapped := fferr.NewExecutionError(p_type.BigQueryOffline.String(), err)
@@ -386,6 +388,13 @@ func (q defaultBQQueries) getNumRowsQuery(tableName string) string {
 	return fmt.Sprintf("SELECT COUNT(*) FROM `%s`", q.getTableName(tableName))
 }
 
+func (q defaultBQQueries) resourceStatsQuery(tableName string, hasTimestamp bool) string {
+	if hasTimestamp {
+		return fmt.Sprintf("SELECT COUNT(*), MAX(ts) FROM `%s`", q.getTableName(tableName))
+	}
+	return q.getNumRowsQuery(tableName)
+}
+
 func (q *defaultBQQueries) getTablePrefix() string {
 	return fmt.Sprintf("%s.%s", q.ProjectId, q.DatasetId)
 }
@@ -403,6 +412,14 @@ func (q *defaultBQQueries) getContext() context.Context {
 	return q.Ctx
 }
 
+// newQuery creates a query that's labeled with the provider's labels, so the cost
+// of its job can be attributed.
+func (q defaultBQQueries) newQuery(client *bigquery.Client, query string) *bigquery.Query {
+	bqQ := client.Query(query)
+	bqQ.Labels = q.Labels
+	return bqQ
+}
+
 func (q defaultBQQueries) castTableItemType(v interface{}, t interface{}) interface{} {
 	if v == nil {
 		return v
@@ -453,7 +470,7 @@ func (q defaultBQQueries) materializationUpdate(client *bigquery.Client, tableNa
 
 	query := fmt.Sprintf("%s %s %s", materializationCreateQuery, alterTables, dropTable)
 
-	bqQ := client.Query(query)
+	bqQ := q.newQuery(client, query)
 	job, err := bqQ.Run(q.getContext())
 	if err != nil {
 		q.logger.Errorw("Failed to update materialization", "table", tableName, "err", err)
@@ -503,7 +520,7 @@ func (q defaultBQQueries) transformationCreate(location pl.SQLLocation, query st
 func (q defaultBQQueries) getColumns(client *bigquery.Client, name string) ([]TableColumn, error) {
 	qry := fmt.Sprintf("SELECT column_name FROM `%s.INFORMATION_SCHEMA.COLUMNS` WHERE table_name=\"%s\" ORDER BY ordinal_position", q.getTablePrefix(), name)
 
-	bqQ := client.Query(qry)
+	bqQ := q.newQuery(client, qry)
 	it, err := bqQ.Read(q.getContext())
 	if err != nil {
 		q.logger.Errorw("Failed to get columns", "table", name, "err", err)
@@ -554,7 +571,7 @@ func (q defaultBQQueries) atomicUpdate(client *bigquery.Client, tableName string
 			"DROP TABLE `%s`;"+
 			"", query, bqTableName, bqTableName, bqTempTableName, bqTempTableName)
 
-	bdQ := client.Query(updateQuery)
+	bdQ := q.newQuery(client, updateQuery)
 	job, err := bdQ.Run(q.getContext())
 	if err != nil {
 		q.logger.Errorw("Failed to update table", "table", tableName, "query", query, "err", err)
@@ -576,6 +593,12 @@ func (q defaultBQQueries) trainingSetUpdate(store *bqOfflineStore, def TrainingS
 }
 
 func (q defaultBQQueries) trainingSetQuery(store *bqOfflineStore, def TrainingSetDef, tableName string, labelName string, isUpdate bool) error {
+	if err := def.checkSingleLabel(p_type.BigQueryOffline); err != nil {
+		return err
+	}
+	if err := def.checkNotPersisted(p_type.BigQueryOffline); err != nil {
+		return err
+	}
 	columns := make([]string, 0)
 	selectColumns := make([]string, 0)
 	query := ""
@@ -589,23 +612,45 @@ func (q defaultBQQueries) trainingSetQuery(store *bqOfflineStore, def TrainingSe
 		tableJoinAlias := fmt.Sprintf("t%d", i+1)
 		selectColumns = append(selectColumns, fmt.Sprintf("%s_rnk", tableJoinAlias))
 		columns = append(columns, santizedName)
-		query = fmt.Sprintf("%s LEFT OUTER JOIN (SELECT entity, value AS `%s`, ts, RANK() OVER (ORDER BY ts DESC, insert_ts DESC) AS %s_rnk FROM `%s` ORDER BY ts desc) AS %s ON (%s.entity=t0.entity AND %s.ts <= t0.ts)",
-			query, santizedName, tableJoinAlias, q.getTableName(tableName), tableJoinAlias, tableJoinAlias, tableJoinAlias)
-		if i == len(def.Features)-1 {
-			query = fmt.Sprintf("%s )) WHERE rn=1", query)
+		ageFilter := ""
+		if def.MaxFeatureAge > 0 {
+			ageFilter = fmt.Sprintf(" AND %s.ts >= %s", tableJoinAlias, bqShiftTimestamp("t0.ts", -def.MaxFeatureAge))
+		}
+		query = fmt.Sprintf("%s LEFT OUTER JOIN (SELECT entity, value AS `%s`, ts, RANK() OVER (ORDER BY ts DESC, insert_ts DESC) AS %s_rnk FROM `%s` ORDER BY ts desc) AS %s ON (%s.entity=t0.entity AND %s.ts <= t0.ts%s)",
+			query, santizedName, tableJoinAlias, q.getTableName(tableName), tableJoinAlias, tableJoinAlias, tableJoinAlias, ageFilter)
+	}
+	for i, lagFeature := range def.LagFeatures {
+		tableName, err := store.getResourceTableName(ResourceID{lagFeature.FeatureName, lagFeature.FeatureVariant, Feature})
+		if err != nil {
+			q.logger.Errorw("Failed to get table name", "lag_feature", lagFeature, "err", err)
+			return err
 		}
+		lagColumnName := strings.Replace(lagFeatureColumnName(lagFeature, tableName), "-", "_", -1)
+		tableJoinAlias := fmt.Sprintf("t%d", len(def.Features)+i+1)
+		selectColumns = append(selectColumns, fmt.Sprintf("%s_rnk", tableJoinAlias))
+		columns = append(columns, fmt.Sprintf("`%s`", lagColumnName))
+		lagTS := bqShiftTimestamp(fmt.Sprintf("%s.ts", tableJoinAlias), lagFeature.LagDelta)
+		query = fmt.Sprintf("%s LEFT OUTER JOIN (SELECT entity, value AS `%s`, ts, RANK() OVER (ORDER BY ts DESC, insert_ts DESC) AS %s_rnk FROM `%s` ORDER BY ts desc) AS %s ON (%s.entity=t0.entity AND %s <= t0.ts)",
+			query, lagColumnName, tableJoinAlias, q.getTableName(tableName), tableJoinAlias, tableJoinAlias, lagTS)
+	}
+	if len(columns) > 0 {
+		query = fmt.Sprintf("%s )) WHERE rn=1", query)
 	}
 	columnStr := strings.Join(columns, ", ")
 	selectColumnStr := strings.Join(selectColumns, ", ")
+	splitSelect := ""
+	if def.Split != nil {
+		splitSelect = trainingSetSplitSelect(*def.Split, q.trainingSetSplitBucket("e", "time", def.Split.Seed))
+	}
 
 	if !isUpdate {
 		fullQuery := fmt.Sprintf(
-			"CREATE TABLE `%s` AS (SELECT %s, label FROM ("+
+			"CREATE TABLE `%s` AS (SELECT %s, label%s FROM ("+
 				"SELECT *, row_number() over(PARTITION BY e, label, time ORDER BY \"time\", %s DESC) AS rn FROM ( "+
 				"SELECT t0.entity AS e, t0.value AS label, t0.ts AS time, %s, %s FROM `%s` AS t0 %s )",
-			q.getTableName(tableName), columnStr, selectColumnStr, columnStr, selectColumnStr, q.getTableName(labelName), query)
+			q.getTableName(tableName), columnStr, splitSelect, selectColumnStr, columnStr, selectColumnStr, q.getTableName(labelName), query)
 
-		bqQ := store.client.Query(fullQuery)
+		bqQ := q.newQuery(store.client, fullQuery)
 		job, err := bqQ.Run(store.query.getContext())
 		if err != nil {
 			return fferr.NewResourceExecutionError(p_type.BigQueryOffline.String(), def.ID.Name, def.ID.Variant, fferr.ResourceType(def.ID.Type.String()), err)
@@ -616,15 +661,27 @@ func (q defaultBQQueries) trainingSetQuery(store *bqOfflineStore, def TrainingSe
 	} else {
 		tempTable := fmt.Sprintf("tmp_%s", tableName)
 		fullQuery := fmt.Sprintf(
-			"CREATE TABLE `%s` AS (SELECT %s, label FROM ("+
+			"CREATE TABLE `%s` AS (SELECT %s, label%s FROM ("+
 				"SELECT *, row_number() over(PARTITION BY e, label, time ORDER BY \"time\", %s desc) AS rn FROM ( "+
 				"SELECT t0.entity AS e, t0.value AS label, t0.ts AS time, %s, %s FROM `%s` AS t0 %s )",
-			q.getTableName(tempTable), columnStr, selectColumnStr, columnStr, selectColumnStr, q.getTableName(labelName), query)
+			q.getTableName(tempTable), columnStr, splitSelect, selectColumnStr, columnStr, selectColumnStr, q.getTableName(labelName), query)
 		err := q.atomicUpdate(store.client, tableName, tempTable, fullQuery)
 		return err
 	}
 }
 
+// trainingSetSplitBucket formats the entity and timestamp with %T so NULLs still
+// hash, and takes ABS after MOD since FARM_FINGERPRINT can return the minimum
+// INT64, which ABS overflows on.
+func (q defaultBQQueries) trainingSetSplitBucket(entity, ts string, seed int64) string {
+	return fmt.Sprintf("ABS(MOD(FARM_FINGERPRINT(FORMAT('%%T|%%T|%%d', %s, %s, %d)), %d))", entity, ts, seed, trainingSetSplitBuckets)
+}
+
+// bqShiftTimestamp moves ts forward by delta, for lag features.
+func bqShiftTimestamp(ts string, delta time.Duration) string {
+	return fmt.Sprintf("TIMESTAMP_ADD(%s, INTERVAL %d SECOND)", ts, int64(delta.Seconds()))
+}
+
 func (q defaultBQQueries) trainingRowSelect(columns string, trainingSetName string) string {
 	return fmt.Sprintf("SELECT %s FROM `%s`", columns, q.getTableName(trainingSetName))
 }
@@ -674,7 +731,7 @@ func (mat *bqMaterialization) NumRows() (int64, error) {
 	var n []bigquery.Value
 	query := mat.query.getNumRowsQuery(mat.tableName)
 
-	bqQ := mat.client.Query(query)
+	bqQ := mat.query.newQuery(mat.client, query)
 	it, err := bqQ.Read(mat.query.getContext())
 	if err != nil {
 		mat.logger.Errorw("Error reading rows")
@@ -699,7 +756,7 @@ func (mat *bqMaterialization) NumRows() (int64, error) {
 func (mat *bqMaterialization) IterateSegment(start, end int64) (FeatureIterator, error) {
 	query := mat.query.materializationIterateSegment(mat.tableName, start, end)
 
-	bqQ := mat.client.Query(query)
+	bqQ := mat.query.newQuery(mat.client, query)
 	it, err := bqQ.Read(mat.query.getContext())
 	if err != nil {
 		return nil, fferr.NewExecutionError(p_type.BigQueryOffline.String(), err)
@@ -794,7 +851,7 @@ func (table *bqOfflineTable) Write(rec ResourceRecord) error {
 	var n []bigquery.Value
 	existsQuery := table.query.writeExists(tb)
 
-	bqQ := table.client.Query(existsQuery)
+	bqQ := table.query.newQuery(table.client, existsQuery)
 	bqQ.Parameters = []bigquery.QueryParameter{
 		{
 			Value: rec.Entity,
@@ -831,7 +888,7 @@ func (table *bqOfflineTable) Write(rec ResourceRecord) error {
 		params = []bigquery.QueryParameter{bigquery.QueryParameter{Value: rec.Value}, bigquery.QueryParameter{Value: rec.Entity}, bigquery.QueryParameter{Value: rec.TS}}
 	}
 
-	bqQ = table.client.Query(writeQuery)
+	bqQ = table.query.newQuery(table.client, writeQuery)
 	bqQ.Parameters = params
 
 	if _, err = bqQ.Read(table.query.getContext()); err != nil {
@@ -868,6 +925,10 @@ func NewBQOfflineStore(config pc.SerializedConfig, logger logging.Logger) (*bqOf
 	if err := sc.Deserialize(config); err != nil {
 		return nil, err
 	}
+	if err := sc.Validate(); err != nil {
+		logger.Errorw("Invalid BigQuery config", "error", err)
+		return nil, err
+	}
 
 	creds, err := json.Marshal(sc.Credentials)
 	if err != nil {
@@ -880,13 +941,23 @@ func NewBQOfflineStore(config pc.SerializedConfig, logger logging.Logger) (*bqOf
 		return nil, fferr.NewConnectionError(string(pt.BigQueryOffline), err)
 	}
 	defer client.Close()
+	// Jobs run in the client's location, which BigQuery infers from the tables they
+	// reference if it's unset.
+	client.Location = sc.Location
 
 	queries := defaultBQQueries{
 		ProjectId: sc.ProjectId,
 		DatasetId: sc.DatasetId,
+		Labels:    sc.Labels,
 		logger:    logger,
 	}
 	queries.setContext()
+	if sc.Location != "" {
+		if err := checkBQDatasetLocation(queries.getContext(), client, sc); err != nil {
+			logger.Errorw("BigQuery dataset location doesn't match config", "location", sc.Location, "error", err)
+			return nil, err
+		}
+	}
 
 	return &bqOfflineStore{
 		client: client,
@@ -899,6 +970,20 @@ func NewBQOfflineStore(config pc.SerializedConfig, logger logging.Logger) (*bqOf
 	}, nil
 }
 
+// checkBQDatasetLocation verifies that the configured dataset is in the configured
+// location, since jobs in one location can't read or write tables in another.
+func checkBQDatasetLocation(ctx context.Context, client *bigquery.Client, sc pc.BigQueryConfig) error {
+	dataset, err := client.Dataset(sc.DatasetId).Metadata(ctx)
+	if err != nil {
+		return fferr.NewConnectionError(string(pt.BigQueryOffline), err)
+	}
+	if !strings.EqualFold(dataset.Location, sc.Location) {
+		err := fmt.Errorf("dataset %s.%s is in location %s, not %s", sc.ProjectId, sc.DatasetId, dataset.Location, sc.Location)
+		return fferr.NewProviderConfigError(string(pt.BigQueryOffline), err)
+	}
+	return nil
+}
+
 func bigQueryOfflineStoreFactory(config pc.SerializedConfig) (Provider, error) {
 	sc := pc.BigQueryConfig{}
 	logger := logging.NewLogger("bigquery")
@@ -919,10 +1004,9 @@ func (store *bqOfflineStore) RegisterResourceFromSourceTable(id ResourceID, sche
 
 	logger.Debug("BigQuery store registering resource from source table")
 
-	if len(opts) > 0 {
-		errorMsg := "BigQuery does not support resource options"
-		logger.Error(errorMsg)
-		return nil, fferr.NewInternalErrorf(errorMsg)
+	if err := schema.checkUnparsed(p_type.BigQueryOffline, opts...); err != nil {
+		logger.Errorw("Unsupported resource options", "error", err)
+		return nil, err
 	}
 	if err := id.check(Feature, Label); err != nil {
 		logger.Error("BigQuery only supports registering feature and label resources")
@@ -954,11 +1038,17 @@ func (store *bqOfflineStore) RegisterResourceFromSourceTable(id ResourceID, sche
 	return store.newBqOfflineTable(tableName)
 }
 
-func (store *bqOfflineStore) RegisterPrimaryFromSourceTable(id ResourceID, tableLocation pl.Location) (PrimaryTable, error) {
+func (store *bqOfflineStore) RegisterPrimaryFromSourceTable(id ResourceID, tableLocation pl.Location, opts ...PrimaryOption) (PrimaryTable, error) {
 	logger := store.logger.With("resourceId", id)
 
 	logger.Debug("Registering primary from source table")
 
+	if len(opts) > 0 {
+		errorMsg := "BigQuery does not support primary table options"
+		logger.Error(errorMsg)
+		return nil, fferr.NewInternalErrorf(errorMsg)
+	}
+
 	sqlLocation, isSqlLocation := tableLocation.(*pl.SQLLocation)
 	if !isSqlLocation {
 		errorMsg := fmt.Sprintf("source table %s is not a SQLLocation, actual: %T", tableLocation, tableLocation)
@@ -985,6 +1075,9 @@ func (store *bqOfflineStore) CreateTransformation(config TransformationConfig, o
 	if len(opts) > 0 {
 		return fferr.NewInternalErrorf("BigQuery does not support transformation options")
 	}
+	if err := ValidateTransformation(config); err != nil {
+		return err
+	}
 	name, err := store.getTableName(config.TargetTableID)
 	if err != nil {
 		logger.Errorw("Error getting table name", "error", err)
@@ -995,7 +1088,7 @@ func (store *bqOfflineStore) CreateTransformation(config TransformationConfig, o
 	location := pl.NewSQLLocation(name).(*pl.SQLLocation)
 	query := store.query.transformationCreate(*location, config.Query)
 
-	bqQ := store.client.Query(query)
+	bqQ := store.query.newQuery(store.client, query)
 	job, err := bqQ.Run(store.query.getContext())
 	if err != nil {
 		logger.Errorw("Error creating transformation", "error", err)
@@ -1020,7 +1113,7 @@ func (store *bqOfflineStore) GetTransformationTable(id ResourceID) (Transformati
 	}
 
 	existsQuery := store.query.tableExists(name)
-	bqQ := store.client.Query(existsQuery)
+	bqQ := store.query.newQuery(store.client, existsQuery)
 	it, err := bqQ.Read(store.query.getContext())
 	if err != nil {
 		store.logger.Errorw("Error getting table name", "error", err)
@@ -1100,6 +1193,50 @@ func (store *bqOfflineStore) CreatePrimaryTable(id ResourceID, schema TableSchem
 	return table, nil
 }
 
+// ImportSourceFiles loads Parquet files from GCS into the primary table for id
+// with a load job. Files on another file store are unimplemented so the rows
+// are copied by the caller instead.
+func (store *bqOfflineStore) ImportSourceFiles(id ResourceID, files []filestore.Filepath) error {
+	logger := store.logger.With("resourceId", id)
+	if err := id.check(Primary); err != nil {
+		logger.Errorw("Resource type is not primary", "err", err)
+		return err
+	}
+	tableName, err := GetPrimaryTableName(id)
+	if err != nil {
+		logger.Errorw("Error getting table name", "error", err)
+		return err
+	}
+	uris := make([]string, len(files))
+	for i, file := range files {
+		if file.Scheme() != filestore.GSPrefix {
+			return fferr.NewUnimplementedErrorf("bigquery can only import sources from GCS, got %s", file.ToURI())
+		}
+		uris[i] = file.ToURI()
+	}
+	gcsRef := bigquery.NewGCSReference(uris...)
+	gcsRef.SourceFormat = bigquery.Parquet
+	loader := store.client.Dataset(store.config.DatasetId).Table(tableName).LoaderFrom(gcsRef)
+	loader.WriteDisposition = bigquery.WriteAppend
+	loader.Labels = store.query.Labels
+
+	logger.Infow("Importing source files", "table_name", tableName, "files", len(files))
+	job, err := loader.Run(store.query.getContext())
+	if err == nil {
+		var status *bigquery.JobStatus
+		if status, err = job.Wait(store.query.getContext()); err == nil {
+			err = status.Err()
+		}
+	}
+	if err != nil {
+		logger.Errorw("Error importing source files", "error", err)
+		wrapped := fferr.NewExecutionError(p_type.BigQueryOffline.String(), err)
+		wrapped.AddDetail("table_name", tableName)
+		return wrapped
+	}
+	return nil
+}
+
 func (store *bqOfflineStore) GetPrimaryTable(id ResourceID, source metadata.SourceVariant) (PrimaryTable, error) {
 	logger := store.logger.With("resourceId", id)
 
@@ -1184,7 +1321,7 @@ func (store *bqOfflineStore) createBqOfflineTable(client *bigquery.Client, name
 		return nil, err
 	}
 	tableCreateQry := store.query.newBQOfflineTableQuery(name, string(columnType))
-	bqQ := client.Query(tableCreateQry)
+	bqQ := store.query.newQuery(client, tableCreateQry)
 	_, err = bqQ.Read(store.query.getContext())
 	if err != nil {
 		wrapped := fferr.NewExecutionError(store.Type().String(), err)
@@ -1244,7 +1381,7 @@ func (store *bqOfflineStore) CreateMaterialization(id ResourceID, opts Materiali
 	matTableName = fmt.Sprintf("%s.%s.%s", store.query.ProjectId, store.query.DatasetId, matTableName)
 	materializeQry := store.query.materializationCreate(matTableName, opts.Schema, *sqlLocation)
 
-	bqQ := store.client.Query(materializeQry)
+	bqQ := store.query.newQuery(store.client, materializeQry)
 	_, err = bqQ.Read(store.query.getContext())
 	if err != nil {
 		logger.Errorw("Error creating materialization", "error", err)
@@ -1258,6 +1395,10 @@ func (store *bqOfflineStore) SupportsMaterializationOption(opt MaterializationOp
 	return false, nil
 }
 
+func (store *bqOfflineStore) Capabilities() (Capabilities, error) {
+	return offlineStoreCapabilities(store)
+}
+
 func (store *bqOfflineStore) newBqOfflineTable(tableName string) (*bqOfflineTable, error) {
 	logger := store.logger.With("table", tableName)
 
@@ -1320,7 +1461,7 @@ func (store *bqOfflineStore) GetMaterialization(id MaterializationID) (Materiali
 	}
 	getMatQry := store.query.materializationExists(tableName)
 
-	bqQry := store.client.Query(getMatQry)
+	bqQry := store.query.newQuery(store.client, getMatQry)
 	it, err := bqQry.Read(store.query.getContext())
 	if err != nil {
 		logger.Errorw("Error getting materialization", "error", err)
@@ -1348,6 +1489,9 @@ func (store *bqOfflineStore) GetMaterialization(id MaterializationID) (Materiali
 }
 
 func (store *bqOfflineStore) UpdateMaterialization(id ResourceID, opts MaterializationOptions) (Materialization, error) {
+	if opts.Incremental {
+		return nil, fferr.NewUnimplementedErrorf("incremental materialization is not supported for BigQuery")
+	}
 	logger := store.logger.With("resourceId", id)
 
 	logger.Debug("Updating materialization")
@@ -1369,7 +1513,7 @@ func (store *bqOfflineStore) UpdateMaterialization(id ResourceID, opts Materiali
 		return nil, err
 	}
 
-	bqQ := store.client.Query(getMatQry)
+	bqQ := store.query.newQuery(store.client, getMatQry)
 	it, err := bqQ.Read(store.query.getContext())
 	if err != nil {
 		logger.Errorw("Error running materialization query", "error", err)
@@ -1418,7 +1562,7 @@ func (store *bqOfflineStore) DeleteMaterialization(id MaterializationID) error {
 		return fferr.NewDatasetNotFoundError(string(id), "", nil)
 	}
 	query := store.query.materializationDrop(tableName)
-	bqQ := store.client.Query(query)
+	bqQ := store.query.newQuery(store.client, query)
 	if _, err := bqQ.Read(store.query.getContext()); err != nil {
 		logger.Errorw("Error deleting materialization", "error", err)
 		wrapped := fferr.NewExecutionError(store.Type().String(), err)
@@ -1449,7 +1593,7 @@ func (store *bqOfflineStore) materializationExists(id MaterializationID) (bool,
 	}
 	getMatQry := store.query.materializationExists(tableName)
 
-	bqQ := store.client.Query(getMatQry)
+	bqQ := store.query.newQuery(store.client, getMatQry)
 	it, err := bqQ.Read(store.query.getContext())
 	if err != nil {
 		logger.Errorw("Error checking if materialization exists", "error", err)
@@ -1477,9 +1621,10 @@ func (bq *bqOfflineStore) buildTrainingSetQuery(tableName string, def TrainingSe
 	}
 
 	queryConfig := tsq.QueryConfig{
-		UseAsOfJoin: false,
-		QuoteChar:   "`",
-		QuoteTable:  true,
+		UseAsOfJoin:    false,
+		QuoteChar:      "`",
+		QuoteTable:     true,
+		ShiftTimestamp: bqShiftTimestamp,
 	}
 	ts := tsq.NewTrainingSet(queryConfig, params)
 	sql, err := ts.CompileSQL()
@@ -1509,7 +1654,7 @@ func (bq *bqOfflineStore) CreateTrainingSet(def TrainingSetDef) error {
 		logger.Errorw("Error building training set query", "error", err)
 		return err
 	}
-	qry := bq.client.Query(query)
+	qry := bq.query.newQuery(bq.client, query)
 	_, err = qry.Read(bq.query.getContext())
 	if err != nil {
 		logger.Errorw("Error running training set query", "error", err)
@@ -1551,7 +1696,20 @@ func (store *bqOfflineStore) UpdateTrainingSet(def TrainingSetDef) error {
 }
 
 func (store *bqOfflineStore) GetTrainingSet(id ResourceID) (TrainingSetIterator, error) {
-	logger := store.logger.With("resourceId", id)
+	return store.getTrainingSetRows(id, "")
+}
+
+func (store *bqOfflineStore) GetTrainingSetSplit(id ResourceID, split string) (TrainingSetIterator, error) {
+	if err := checkTrainingSetSplitName(split); err != nil {
+		return nil, err
+	}
+	return store.getTrainingSetRows(id, split)
+}
+
+// getTrainingSetRows returns an iterator over the training set. If split is set, only
+// rows assigned to that split are returned.
+func (store *bqOfflineStore) getTrainingSetRows(id ResourceID, split string) (TrainingSetIterator, error) {
+	logger := store.logger.With("resourceId", id, "split", split)
 
 	logger.Debug("Getting training set")
 
@@ -1577,13 +1735,26 @@ func (store *bqOfflineStore) GetTrainingSet(id ResourceID) (TrainingSetIterator,
 		return nil, err
 	}
 	features := make([]string, 0)
+	hasSplit := false
 	for _, name := range columnNames {
+		// The split column is bookkeeping and is never returned as a feature.
+		if name.Name == trainingSetSplitColumn {
+			hasSplit = true
+			continue
+		}
 		features = append(features, name.Name)
 	}
+	if split != "" && !hasSplit {
+		logger.Errorw("Training set was not created with a split")
+		return nil, fferr.NewInvalidArgumentErrorf("training set %s (%s) was not created with a split", id.Name, id.Variant)
+	}
 	columns := strings.Join(features[:], ", ")
 	trainingSetQry := store.query.trainingRowSelect(columns, trainingSetName)
+	if split != "" {
+		trainingSetQry = fmt.Sprintf("%s WHERE %s = '%s'", trainingSetQry, trainingSetSplitColumn, split)
+	}
 
-	bqQ := store.client.Query(trainingSetQry)
+	bqQ := store.query.newQuery(store.client, trainingSetQry)
 	iter, err := bqQ.Read(store.query.getContext())
 	if err != nil {
 		logger.Errorw("Error getting training set rows", "error", err)
@@ -1601,6 +1772,10 @@ func (store *bqOfflineStore) GetTrainTestSplit(def TrainTestSplitDef) (TrainingS
 	return nil, nil, fmt.Errorf("not Implemented")
 }
 
+func (store *bqOfflineStore) ExportTrainingSet(id ResourceID, location pl.Location, format filestore.FileType) ([]filestore.Filepath, error) {
+	return nil, fferr.NewUnimplementedErrorf("training set export is not supported for BigQuery")
+}
+
 func (store *bqOfflineStore) CheckHealth() (bool, error) {
 	return false, fferr.NewInternalError(fmt.Errorf("provider health check not implemented"))
 }
@@ -1629,6 +1804,40 @@ func (store *bqOfflineStore) ResourceLocation(id ResourceID, resource any) (pl.L
 	return pl.NewSQLLocation(tableName), nil
 }
 
+func (store *bqOfflineStore) GetResourceStats(id ResourceID, resource any) (ResourceStats, error) {
+	logger := store.logger.With("resourceId", id)
+	tableName, err := ps.ResourceToTableName(id.Type.String(), id.Name, id.Variant)
+	if err != nil {
+		logger.Errorw("Error getting table name", "error", err)
+		return ResourceStats{}, err
+	}
+	hasTimestamp := id.Type == Feature || id.Type == Label
+	bqQ := store.query.newQuery(store.client, store.query.resourceStatsQuery(tableName, hasTimestamp))
+	it, err := bqQ.Read(store.query.getContext())
+	if err != nil {
+		logger.Errorw("Error reading resource stats", "error", err)
+		wrapped := fferr.NewExecutionError(p_type.BigQueryOffline.String(), err)
+		wrapped.AddDetail("table_name", tableName)
+		return ResourceStats{}, wrapped
+	}
+	var row []bigquery.Value
+	if err := it.Next(&row); err != nil {
+		wrapped := fferr.NewExecutionError(p_type.BigQueryOffline.String(), err)
+		wrapped.AddDetail("table_name", tableName)
+		return ResourceStats{}, wrapped
+	}
+	stats := ResourceStats{}
+	if len(row) > 0 && row[0] != nil {
+		stats.RowCount = row[0].(int64)
+	}
+	if len(row) > 1 {
+		if maxTS, ok := row[1].(time.Time); ok {
+			stats.MaxTimestamp = maxTS.UTC()
+		}
+	}
+	return stats, nil
+}
+
 func (store bqOfflineStore) Delete(location pl.Location) error {
 	return fferr.NewInternalErrorf("delete not implemented")
 }
@@ -1698,6 +1907,10 @@ func (it *bqTrainingRowsIterator) Label() interface{} {
 	return it.currentLabel
 }
 
+func (it *bqTrainingRowsIterator) Labels() []interface{} {
+	return []interface{}{it.currentLabel}
+}
+
 func (store *bqOfflineStore) AsOfflineStore() (OfflineStore, error) {
 	return store, nil
 }
@@ -1736,7 +1949,7 @@ func (store *bqOfflineStore) tableExists(location pl.Location) (bool, error) {
 
 	var n []bigquery.Value
 	query := store.query.tableExists(sqlLocation.Location())
-	bqQ := store.client.Query(query)
+	bqQ := store.query.newQuery(store.client, query)
 
 	iter, err := bqQ.Read(store.query.getContext())
 	if err != nil {
@@ -1753,7 +1966,7 @@ func (store *bqOfflineStore) tableExists(location pl.Location) (bool, error) {
 	}
 
 	query = store.query.viewExists(sqlLocation.Location())
-	bqQ = store.client.Query(query)
+	bqQ = store.query.newQuery(store.client, query)
 
 	iter, err = bqQ.Read(store.query.getContext())
 	if err != nil {
@@ -1782,7 +1995,7 @@ func (store *bqOfflineStore) createNewBigQueryPrimaryTable(client *bigquery.Clie
 		return nil, err
 	}
 
-	qry := client.Query(query)
+	qry := store.query.newQuery(client, query)
 	_, err = qry.Read(store.query.getContext())
 	if err != nil {
 		logger.Errorw("Error creating new bigquery primary table", "error", err)
@@ -1825,5 +2038,10 @@ func (bq *bqOfflineStore) adaptTsDefToBuilderParams(def TrainingSetDef) (tsq.Bui
 		return bq.query.getTableNameFromLocation(*lblLoc), nil
 	}
 
-	return def.ToBuilderParams(bq.logger, sanitizeTableNameFn)
+	params, err := def.ToBuilderParams(bq.logger, sanitizeTableNameFn)
+	if err != nil {
+		return tsq.BuilderParams{}, err
+	}
+	params.SplitColumn = def.splitColumn(bq.query.trainingSetSplitBucket, "`")
+	return params, nil
 }
@@ -239,3 +239,33 @@ func getConfiguredBigQueryTester(t *testing.T, useCrossDBJoins bool) offlineSqlT
 		sanitizeTableName:   sanitizeTableNameFunc,
 	}
 }
+
+func TestOfflineStoreBigQuery(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration tests")
+	}
+
+	bigQueryConfig, err := getBigQueryConfig(t)
+	if err != nil {
+		t.Fatalf("could not get BigQuery config: %s", err)
+	}
+	if err := createBigQueryDataset(bigQueryConfig); err != nil {
+		t.Fatalf("Cannot create BigQuery Dataset: %v", err)
+	}
+	t.Cleanup(func() {
+		if err := destroyBigQueryDataset(bigQueryConfig); err != nil {
+			t.Logf("failed to cleanup database: %s\n", err)
+		}
+	})
+
+	store, err := GetOfflineStore(pt.BigQueryOffline, bigQueryConfig.Serialize())
+	if err != nil {
+		t.Fatalf("could not initialize store: %s\n", err)
+	}
+
+	test := OfflineStoreTest{
+		t:     t,
+		store: store,
+	}
+	test.Run()
+}
@@ -0,0 +1,542 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+//
+// Copyright 2024 FeatureForm Inc.
+//
+
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"time"
+
+	"cloud.google.com/go/bigtable"
+	"github.com/featureform/fferr"
+	"github.com/featureform/logging"
+	pl "github.com/featureform/provider/location"
+	pc "github.com/featureform/provider/provider_config"
+	pt "github.com/featureform/provider/provider_type"
+	se "github.com/featureform/provider/serialization"
+	vt "github.com/featureform/provider/types"
+	"go.uber.org/zap"
+	"google.golang.org/api/option"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	// bigtableFeaturesTable holds the values of every feature variant. Each
+	// feature is a column family, each of its variants a column within it, and
+	// each entity a row.
+	bigtableFeaturesTable = "features"
+	// bigtableMetadataTable holds a row with the value type of each feature
+	// variant, keyed by its table key.
+	bigtableMetadataTable   = "metadata"
+	bigtableMetadataFamily  = "metadata"
+	bigtableValueTypeColumn = "value_type"
+)
+
+// maxBigtableBatchSize is the max amount of items written in a single bulk
+// mutation. The client splits requests over Bigtable's limit of 100,000
+// mutations on its own, this just bounds the size of the runner's buffers.
+const maxBigtableBatchSize = 10000
+
+// bigtableFamilyPattern matches the names Bigtable allows for column families.
+// Since features are stored as column families, their names have to match it.
+var bigtableFamilyPattern = regexp.MustCompile(`^[_a-zA-Z0-9][-_.a-zA-Z0-9]{0,63}$`)
+
+// The serializer versions.
+const (
+	// bigtableSerializeV0 stores scalars as strings and vectors as JSON arrays.
+	bigtableSerializeV0 se.SerializeVersion = iota
+)
+
+type bigtableSerializerV0 struct{}
+
+func (ser bigtableSerializerV0) Version() se.SerializeVersion { return bigtableSerializeV0 }
+
+func (ser bigtableSerializerV0) Serialize(t vt.ValueType, value any) ([]byte, error) {
+	if value == nil {
+		return []byte{}, nil
+	}
+	if t.IsVector() {
+		vector, ok := value.([]float32)
+		if !ok {
+			return nil, fferr.NewDataTypeNotFoundErrorf(value, "expected []float32 for vector")
+		}
+		serialized, err := json.Marshal(vector)
+		if err != nil {
+			return nil, fferr.NewInternalError(err)
+		}
+		return serialized, nil
+	}
+	if t == vt.JSON {
+		casted, err := se.CastJSON(value, false)
+		if err != nil {
+			return nil, fferr.NewDataTypeNotFoundErrorf(value, err.Error())
+		}
+		return []byte(casted), nil
+	}
+	var serialized string
+	switch v := value.(type) {
+	case string:
+		serialized = v
+	case int:
+		serialized = strconv.Itoa(v)
+	case int8:
+		serialized = strconv.FormatInt(int64(v), 10)
+	case int16:
+		serialized = strconv.FormatInt(int64(v), 10)
+	case int32:
+		serialized = strconv.FormatInt(int64(v), 10)
+	case int64:
+		serialized = strconv.FormatInt(v, 10)
+	case float32:
+		serialized = strconv.FormatFloat(float64(v), 'f', -1, 32)
+	case float64:
+		serialized = strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		serialized = strconv.FormatBool(v)
+	case time.Time:
+		serialized = v.Format(time.RFC3339Nano)
+	default:
+		return nil, fferr.NewDataTypeNotFoundErrorf(value, "unsupported data type")
+	}
+	return []byte(serialized), nil
+}
+
+func (ser bigtableSerializerV0) Deserialize(t vt.ValueType, value []byte) (any, error) {
+	if t.IsVector() {
+		var vector []float32
+		if err := json.Unmarshal(value, &vector); err != nil {
+			return nil, fferr.NewInternalError(err)
+		}
+		return vector, nil
+	}
+	str := string(value)
+	switch t {
+	case vt.String, vt.JSON:
+		return str, nil
+	}
+	// Values of other types are only empty if they were written as nil.
+	if str == "" {
+		return nil, nil
+	}
+	var result any
+	var err error
+	switch t {
+	case vt.NilType:
+		result = str
+	case vt.Int:
+		result, err = strconv.Atoi(str)
+	case vt.Int8:
+		var parsed int64
+		parsed, err = strconv.ParseInt(str, 10, 8)
+		result = int8(parsed)
+	case vt.Int16:
+		var parsed int64
+		parsed, err = strconv.ParseInt(str, 10, 16)
+		result = int16(parsed)
+	case vt.Int32:
+		var parsed int64
+		parsed, err = strconv.ParseInt(str, 10, 32)
+		result = int32(parsed)
+	case vt.Int64:
+		result, err = strconv.ParseInt(str, 10, 64)
+	case vt.Float32:
+		var parsed float64
+		parsed, err = strconv.ParseFloat(str, 32)
+		result = float32(parsed)
+	case vt.Float64:
+		result, err = strconv.ParseFloat(str, 64)
+	case vt.Bool:
+		result, err = strconv.ParseBool(str)
+	case vt.Timestamp, vt.Datetime:
+		result, err = time.Parse(time.RFC3339Nano, str)
+	default:
+		wrapped := fferr.NewInternalErrorf("Unsupported casting value %v into %v", str, t)
+		wrapped.AddDetail("version", ser.Version().String())
+		return nil, wrapped
+	}
+	if err != nil {
+		wrapped := fferr.NewInternalError(fmt.Errorf("could not cast value: %v to %s: %w", str, t, err))
+		wrapped.AddDetail("version", ser.Version().String())
+		return nil, wrapped
+	}
+	return result, nil
+}
+
+type bigtableOnlineStore struct {
+	client   *bigtable.Client
+	admin    *bigtable.AdminClient
+	features *bigtable.Table
+	metadata *bigtable.Table
+	prefix   string
+	logger   *zap.SugaredLogger
+	BaseProvider
+}
+
+type bigtableOnlineTable struct {
+	table      *bigtable.Table
+	key        bigtableTableKey
+	valueType  vt.ValueType
+	serializer se.Serializer[[]byte]
+	logger     *zap.SugaredLogger
+}
+
+type bigtableTableKey struct {
+	Feature, Variant string
+}
+
+func (t bigtableTableKey) String() string {
+	return fmt.Sprintf("%s__%s", t.Feature, t.Variant)
+}
+
+// filter matches the newest cell of the variant's column.
+func (t bigtableTableKey) filter() bigtable.Filter {
+	return bigtable.ChainFilters(
+		bigtable.FamilyFilter(fmt.Sprintf("^%s$", regexp.QuoteMeta(t.Feature))),
+		bigtable.ColumnFilter(fmt.Sprintf("^%s$", regexp.QuoteMeta(t.Variant))),
+		bigtable.LatestNFilter(1),
+	)
+}
+
+func bigtableOnlineStoreFactory(serialized pc.SerializedConfig) (Provider, error) {
+	bigtableConfig := &pc.BigtableConfig{}
+	if err := bigtableConfig.Deserialize(serialized); err != nil {
+		return nil, err
+	}
+	return NewBigtableOnlineStore(bigtableConfig)
+}
+
+func NewBigtableOnlineStore(options *pc.BigtableConfig) (*bigtableOnlineStore, error) {
+	var clientOpts []option.ClientOption
+	// Without credentials, the client falls back to the application default
+	// credentials or to the emulator set by BIGTABLE_EMULATOR_HOST.
+	if len(options.Credentials) > 0 {
+		credBytes, err := json.Marshal(options.Credentials)
+		if err != nil {
+			return nil, fferr.NewInternalError(err)
+		}
+		clientOpts = append(clientOpts, option.WithCredentialsJSON(credBytes))
+	}
+	return newBigtableOnlineStore(options, clientOpts...)
+}
+
+func newBigtableOnlineStore(options *pc.BigtableConfig, clientOpts ...option.ClientOption) (*bigtableOnlineStore, error) {
+	if err := options.Validate(); err != nil {
+		return nil, err
+	}
+	ctx := context.TODO()
+	client, err := bigtable.NewClient(ctx, options.ProjectID, options.InstanceID, clientOpts...)
+	if err != nil {
+		return nil, fferr.NewConnectionError(pt.BigtableOnline.String(), err)
+	}
+	admin, err := bigtable.NewAdminClient(ctx, options.ProjectID, options.InstanceID, clientOpts...)
+	if err != nil {
+		client.Close()
+		return nil, fferr.NewConnectionError(pt.BigtableOnline.String(), err)
+	}
+	logger := logging.NewLogger("bigtable")
+	store := &bigtableOnlineStore{
+		client:   client,
+		admin:    admin,
+		features: client.Open(options.TablePrefix + bigtableFeaturesTable),
+		metadata: client.Open(options.TablePrefix + bigtableMetadataTable),
+		prefix:   options.TablePrefix,
+		logger:   logger.SugaredLogger,
+		BaseProvider: BaseProvider{
+			ProviderType:   pt.BigtableOnline,
+			ProviderConfig: options.Serialize(),
+		},
+	}
+	if err := store.createTables(ctx); err != nil {
+		store.Close()
+		return nil, err
+	}
+	return store, nil
+}
+
+// createTables creates the features and metadata tables if they don't exist.
+// The features table starts without column families; one is added for each
+// feature when its first variant is created.
+func (store *bigtableOnlineStore) createTables(ctx context.Context) error {
+	tables := []*bigtable.TableConf{
+		{TableID: store.prefix + bigtableFeaturesTable},
+		{
+			TableID: store.prefix + bigtableMetadataTable,
+			ColumnFamilies: map[string]bigtable.Family{
+				bigtableMetadataFamily: {GCPolicy: bigtable.MaxVersionsPolicy(1)},
+			},
+		},
+	}
+	for _, conf := range tables {
+		err := store.admin.CreateTableFromConf(ctx, conf)
+		if err != nil && status.Code(err) != codes.AlreadyExists {
+			wrapped := fferr.NewConnectionError(pt.BigtableOnline.String(), err)
+			wrapped.AddDetail("table_name", conf.TableID)
+			return wrapped
+		}
+	}
+	return nil
+}
+
+func (store *bigtableOnlineStore) AsOnlineStore() (OnlineStore, error) {
+	return store, nil
+}
+
+func (store *bigtableOnlineStore) Close() error {
+	adminErr := store.admin.Close()
+	if err := store.client.Close(); err != nil {
+		return fferr.NewExecutionError(pt.BigtableOnline.String(), err)
+	}
+	if adminErr != nil {
+		return fferr.NewExecutionError(pt.BigtableOnline.String(), adminErr)
+	}
+	return nil
+}
+
+func (store *bigtableOnlineStore) newTable(key bigtableTableKey, valueType vt.ValueType) *bigtableOnlineTable {
+	return &bigtableOnlineTable{
+		table:      store.features,
+		key:        key,
+		valueType:  valueType,
+		serializer: bigtableSerializerV0{},
+		logger:     store.logger.With("feature", key.Feature, "variant", key.Variant),
+	}
+}
+
+func (store *bigtableOnlineStore) GetTable(feature, variant string) (OnlineStoreTable, error) {
+	key := bigtableTableKey{feature, variant}
+	row, err := store.metadata.ReadRow(context.TODO(), key.String(), bigtable.RowFilter(bigtable.LatestNFilter(1)))
+	if err != nil {
+		wrapped := fferr.NewResourceExecutionError(pt.BigtableOnline.String(), feature, variant, fferr.FEATURE_VARIANT, err)
+		wrapped.AddDetail("table_name", store.prefix+bigtableMetadataTable)
+		return nil, wrapped
+	}
+	column := fmt.Sprintf("%s:%s", bigtableMetadataFamily, bigtableValueTypeColumn)
+	for _, cell := range row[bigtableMetadataFamily] {
+		if cell.Column != column {
+			continue
+		}
+		valueType, err := vt.DeserializeType(string(cell.Value))
+		if err != nil {
+			return nil, fferr.NewInternalError(err)
+		}
+		return store.newTable(key, valueType), nil
+	}
+	wrapped := fferr.NewDatasetNotFoundError(feature, variant, nil)
+	wrapped.AddDetail("table_key", key.String())
+	return nil, wrapped
+}
+
+func (store *bigtableOnlineStore) CreateTable(feature, variant string, valueType vt.ValueType) (OnlineStoreTable, error) {
+	if !bigtableFamilyPattern.MatchString(feature) {
+		return nil, fferr.NewInvalidArgumentErrorf(
+			"Bigtable feature names can only contain letters, numbers, '-', '_' and '.' and be at most 64 characters: %s", feature)
+	}
+	ctx := context.TODO()
+	key := bigtableTableKey{feature, variant}
+	if err := store.createFamily(ctx, feature); err != nil {
+		return nil, fferr.NewResourceExecutionError(pt.BigtableOnline.String(), feature, variant, fferr.FEATURE_VARIANT, err)
+	}
+	set := bigtable.NewMutation()
+	set.Set(bigtableMetadataFamily, bigtableValueTypeColumn, bigtable.Now().TruncateToMilliseconds(), []byte(vt.SerializeType(valueType)))
+	// The metadata row is only written if it doesn't exist yet, so that
+	// concurrent creates of the same variant can't both succeed.
+	var exists bool
+	err := store.metadata.Apply(ctx, key.String(), bigtable.NewCondMutation(bigtable.PassAllFilter(), nil, set), bigtable.GetCondMutationResult(&exists))
+	if err != nil {
+		return nil, fferr.NewResourceExecutionError(pt.BigtableOnline.String(), feature, variant, fferr.FEATURE_VARIANT, err)
+	}
+	if exists {
+		return nil, fferr.NewDatasetAlreadyExistsError(feature, variant, nil)
+	}
+	return store.newTable(key, valueType), nil
+}
+
+// createFamily adds the feature's column family to the features table if it
+// doesn't exist yet. Only the newest version of a value is read, so older
+// versions are garbage collected.
+func (store *bigtableOnlineStore) createFamily(ctx context.Context, feature string) error {
+	family := bigtable.Family{GCPolicy: bigtable.MaxVersionsPolicy(1)}
+	err := store.admin.CreateColumnFamilyWithConfig(ctx, store.prefix+bigtableFeaturesTable, feature, family)
+	if err != nil && status.Code(err) != codes.AlreadyExists {
+		return err
+	}
+	return nil
+}
+
+// DeleteTable removes the variant's metadata and its column from every row.
+// The feature's column family is kept since other variants may still use it.
+func (store *bigtableOnlineStore) DeleteTable(feature, variant string) error {
+	ctx := context.TODO()
+	key := bigtableTableKey{feature, variant}
+	deleteMetadata := bigtable.NewMutation()
+	deleteMetadata.DeleteRow()
+	if err := store.metadata.Apply(ctx, key.String(), deleteMetadata); err != nil {
+		return fferr.NewResourceExecutionError(pt.BigtableOnline.String(), feature, variant, fferr.FEATURE_VARIANT, err)
+	}
+	var rowKeys []string
+	err := store.features.ReadRows(ctx, bigtable.InfiniteRange(""), func(row bigtable.Row) bool {
+		rowKeys = append(rowKeys, row.Key())
+		return true
+	}, bigtable.RowFilter(bigtable.ChainFilters(key.filter(), bigtable.StripValueFilter())))
+	if err != nil {
+		return fferr.NewResourceExecutionError(pt.BigtableOnline.String(), feature, variant, fferr.FEATURE_VARIANT, err)
+	}
+	if len(rowKeys) == 0 {
+		return nil
+	}
+	muts := make([]*bigtable.Mutation, len(rowKeys))
+	for i := range rowKeys {
+		muts[i] = bigtable.NewMutation()
+		muts[i].DeleteCellsInColumn(feature, variant)
+	}
+	errs, err := store.features.ApplyBulk(ctx, rowKeys, muts)
+	if err == nil {
+		for _, rowErr := range errs {
+			if rowErr != nil {
+				err = rowErr
+				break
+			}
+		}
+	}
+	if err != nil {
+		return fferr.NewResourceExecutionError(pt.BigtableOnline.String(), feature, variant, fferr.FEATURE_VARIANT, err)
+	}
+	return nil
+}
+
+func (store *bigtableOnlineStore) CheckHealth() (bool, error) {
+	if _, err := store.admin.TableInfo(context.TODO(), store.prefix+bigtableFeaturesTable); err != nil {
+		store.logger.Error("Health check failed, unable to connect to bigtable")
+		return false, fferr.NewConnectionError(pt.BigtableOnline.String(), err)
+	}
+	store.logger.Info("Health check successful")
+	return true, nil
+}
+
+func (store *bigtableOnlineStore) Delete(location pl.Location) error {
+	return fferr.NewInternalErrorf("delete not implemented")
+}
+
+func (table bigtableOnlineTable) Set(entity string, value interface{}) error {
+	// Set is just a special case of batch writing.
+	err := table.BatchSet([]SetItem{{
+		Entity: entity,
+		Value:  value,
+	}})
+	if err != nil {
+		wrapped := fferr.NewResourceExecutionError(pt.BigtableOnline.String(), table.key.Feature, table.key.Variant, fferr.ENTITY, err)
+		wrapped.AddDetail("entity", entity)
+		return wrapped
+	}
+	return nil
+}
+
+func (table bigtableOnlineTable) Get(entity string) (interface{}, error) {
+	row, err := table.table.ReadRow(context.TODO(), entity, bigtable.RowFilter(table.key.filter()))
+	if err != nil {
+		wrapped := fferr.NewResourceExecutionError(pt.BigtableOnline.String(), table.key.Feature, table.key.Variant, fferr.ENTITY, err)
+		wrapped.AddDetail("entity", entity)
+		return nil, wrapped
+	}
+	cells := row[table.key.Feature]
+	if len(cells) == 0 {
+		return nil, fferr.NewEntityNotFoundError(table.key.Feature, table.key.Variant, entity, nil)
+	}
+	value, err := table.serializer.Deserialize(table.valueType, cells[0].Value)
+	if err != nil {
+		table.logger.Errorw("Error deserializing value", "entity", entity, "err", err)
+		return nil, err
+	}
+	return value, nil
+}
+
+func (table bigtableOnlineTable) MaxBatchSize() (int, error) { return maxBigtableBatchSize, nil }
+
+// BatchSet writes the items' values in a single bulk mutation. Each value is
+// written as a cell with the item's timestamp, or the current time if it has
+// none, and reads return the cell with the newest timestamp, so a value is
+// never overwritten by an older one. Values with the same timestamp are
+// overwritten. If some of the rows fail to be written, a ConnectionError with
+// the number of written and failed rows is returned.
+func (table bigtableOnlineTable) BatchSet(items []SetItem) error {
+	if len(items) > maxBigtableBatchSize {
+		return fferr.NewInternalErrorf(
+			"Cannot batch write %d items.\nMax: %d\n", len(items), maxBigtableBatchSize)
+	}
+	if len(items) == 0 {
+		return nil
+	}
+	now := bigtable.Now()
+	rowKeys := make([]string, 0, len(items))
+	muts := make([]*bigtable.Mutation, 0, len(items))
+	timestamps := make([]bigtable.Timestamp, 0, len(items))
+	indexes := make(map[string]int, len(items))
+	for _, item := range items {
+		serializedValue, err := table.serializer.Serialize(table.valueType, item.Value)
+		if err != nil {
+			table.logger.Errorw("Error serializing value", "entity", item.Entity, "value", item.Value, "err", err)
+			return err
+		}
+		ts := now
+		if !item.TS.IsZero() {
+			ts = bigtable.Time(item.TS)
+		}
+		// Bigtable only accepts timestamps with millisecond granularity.
+		ts = ts.TruncateToMilliseconds()
+		mut := bigtable.NewMutation()
+		mut.Set(table.key.Feature, table.key.Variant, ts, serializedValue)
+		// Bigtable doesn't order the mutations of a bulk request, so only the
+		// newest item of each entity is written. If its items have the same
+		// timestamp, the last one wins.
+		if idx, has := indexes[item.Entity]; has {
+			if ts >= timestamps[idx] {
+				muts[idx] = mut
+				timestamps[idx] = ts
+			}
+			continue
+		}
+		indexes[item.Entity] = len(rowKeys)
+		rowKeys = append(rowKeys, item.Entity)
+		muts = append(muts, mut)
+		timestamps = append(timestamps, ts)
+	}
+	errs, err := table.table.ApplyBulk(context.TODO(), rowKeys, muts)
+	if err != nil {
+		table.logger.Errorw("Error applying bulk mutation", "rows", len(rowKeys), "err", err)
+		return table.batchError(len(rowKeys), len(rowKeys), err)
+	}
+	failed := 0
+	var firstErr error
+	for i, rowErr := range errs {
+		if rowErr == nil {
+			continue
+		}
+		table.logger.Errorw("Error writing row", "entity", rowKeys[i], "err", rowErr)
+		failed++
+		if firstErr == nil {
+			firstErr = rowErr
+		}
+	}
+	return table.batchError(len(rowKeys), failed, firstErr)
+}
+
+func (table bigtableOnlineTable) batchError(total, failed int, err error) error {
+	if failed == 0 {
+		return nil
+	}
+	wrapped := fferr.NewConnectionError(pt.BigtableOnline.String(), err)
+	wrapped.AddDetail("feature", table.key.Feature)
+	wrapped.AddDetail("variant", table.key.Variant)
+	wrapped.AddDetail("written", strconv.Itoa(total-failed))
+	wrapped.AddDetail("failed", strconv.Itoa(failed))
+	return wrapped
+}
@@ -0,0 +1,227 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+//
+// Copyright 2024 FeatureForm Inc.
+//
+
+package provider
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"cloud.google.com/go/bigtable/bttest"
+	"github.com/featureform/fferr"
+	pc "github.com/featureform/provider/provider_config"
+	vt "github.com/featureform/provider/types"
+	"google.golang.org/api/option"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+func newBigtableTestStore(t *testing.T) *bigtableOnlineStore {
+	srv, err := bttest.NewServer("localhost:0")
+	if err != nil {
+		t.Fatalf("Failed to start Bigtable emulator: %v", err)
+	}
+	t.Cleanup(srv.Close)
+	conn, err := grpc.Dial(srv.Addr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatalf("Failed to connect to Bigtable emulator: %v", err)
+	}
+	config := &pc.BigtableConfig{ProjectID: "project", InstanceID: "instance", TablePrefix: "test_"}
+	store, err := newBigtableOnlineStore(config, option.WithGRPCConn(conn))
+	if err != nil {
+		t.Fatalf("Failed to create Bigtable store: %v", err)
+	}
+	t.Cleanup(func() { store.Close() })
+	return store
+}
+
+func newBigtableTestTable(t *testing.T, store *bigtableOnlineStore, valueType vt.ValueType) BatchOnlineTable {
+	table, err := store.CreateTable("transactions", "default", valueType)
+	if err != nil {
+		t.Fatalf("Failed to create table: %v", err)
+	}
+	return table.(BatchOnlineTable)
+}
+
+func assertBigtableValue(t *testing.T, table OnlineStoreTable, entity string, expected interface{}) {
+	t.Helper()
+	value, err := table.Get(entity)
+	if err != nil {
+		t.Fatalf("Failed to get %s: %v", entity, err)
+	}
+	if !reflect.DeepEqual(value, expected) {
+		t.Fatalf("Expected %s to be %#v, got %#v", entity, expected, value)
+	}
+}
+
+func TestBigtableTables(t *testing.T) {
+	store := newBigtableTestStore(t)
+	if _, err := store.GetTable("transactions", "default"); err == nil {
+		t.Fatalf("Expected an error getting a table that doesn't exist")
+	} else if _, ok := err.(*fferr.DatasetNotFoundError); !ok {
+		t.Fatalf("Expected a DatasetNotFoundError, got: %v", err)
+	}
+	vectorType := vt.VectorType{ScalarType: vt.Float32, Dimension: 2}
+	if _, err := store.CreateTable("transactions", "default", vectorType); err != nil {
+		t.Fatalf("Failed to create table: %v", err)
+	}
+	if _, err := store.CreateTable("transactions", "default", vt.Int); err == nil {
+		t.Fatalf("Expected an error creating a table that exists")
+	} else if _, ok := err.(*fferr.DatasetAlreadyExistsError); !ok {
+		t.Fatalf("Expected a DatasetAlreadyExistsError, got: %v", err)
+	}
+	// Other variants share the feature's column family.
+	if _, err := store.CreateTable("transactions", "v2", vt.String); err != nil {
+		t.Fatalf("Failed to create second variant: %v", err)
+	}
+	table, err := store.GetTable("transactions", "default")
+	if err != nil {
+		t.Fatalf("Failed to get table: %v", err)
+	}
+	if valueType := table.(*bigtableOnlineTable).valueType; !reflect.DeepEqual(valueType, vectorType) {
+		t.Fatalf("Expected value type %v, got %v", vectorType, valueType)
+	}
+	if _, err := store.CreateTable("transactions/amount", "default", vt.Int); err == nil {
+		t.Fatalf("Expected an error creating a table with an invalid family name")
+	} else if _, ok := err.(*fferr.InvalidArgumentError); !ok {
+		t.Fatalf("Expected an InvalidArgumentError, got: %v", err)
+	}
+}
+
+func TestBigtableValues(t *testing.T) {
+	ts := time.Date(2024, 1, 2, 3, 4, 5, 6000000, time.UTC)
+	tests := map[string]struct {
+		valueType vt.ValueType
+		value     interface{}
+	}{
+		"Int":       {vt.Int, 10},
+		"Int32":     {vt.Int32, int32(-3)},
+		"Int64":     {vt.Int64, int64(1) << 40},
+		"Float32":   {vt.Float32, float32(1.5)},
+		"Float64":   {vt.Float64, 2.25},
+		"Bool":      {vt.Bool, true},
+		"String":    {vt.String, "value"},
+		"Timestamp": {vt.Timestamp, ts},
+		"Nil":       {vt.Int, nil},
+		"Vector":    {vt.VectorType{ScalarType: vt.Float32, Dimension: 2}, []float32{0.5, -1}},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			table := newBigtableTestTable(t, newBigtableTestStore(t), test.valueType)
+			if err := table.Set("a", test.value); err != nil {
+				t.Fatalf("Failed to set value: %v", err)
+			}
+			assertBigtableValue(t, table, "a", test.value)
+		})
+	}
+}
+
+func TestBigtableOverwrite(t *testing.T) {
+	store := newBigtableTestStore(t)
+	table := newBigtableTestTable(t, store, vt.Int)
+	if _, err := table.Get("a"); err == nil {
+		t.Fatalf("Expected an error getting an entity that doesn't exist")
+	} else if _, ok := err.(*fferr.EntityNotFoundError); !ok {
+		t.Fatalf("Expected an EntityNotFoundError, got: %v", err)
+	}
+
+	// Values without a timestamp are overwritten by the next write.
+	if err := table.Set("a", 1); err != nil {
+		t.Fatalf("Failed to set value: %v", err)
+	}
+	if err := table.Set("a", 2); err != nil {
+		t.Fatalf("Failed to overwrite value: %v", err)
+	}
+	assertBigtableValue(t, table, "a", 2)
+
+	// Values with a timestamp are only overwritten by newer or equal ones.
+	newer := time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)
+	older := newer.Add(-time.Hour)
+	items := []SetItem{
+		{Entity: "b", Value: 1, TS: newer},
+		{Entity: "c", Value: 1, TS: older},
+	}
+	if err := table.BatchSet(items); err != nil {
+		t.Fatalf("Failed to batch set: %v", err)
+	}
+	items = []SetItem{
+		{Entity: "b", Value: 2, TS: older},
+		{Entity: "c", Value: 2, TS: newer},
+	}
+	if err := table.BatchSet(items); err != nil {
+		t.Fatalf("Failed to batch set: %v", err)
+	}
+	assertBigtableValue(t, table, "b", 1)
+	assertBigtableValue(t, table, "c", 2)
+	if err := table.BatchSet([]SetItem{{Entity: "c", Value: 3, TS: newer}}); err != nil {
+		t.Fatalf("Failed to batch set: %v", err)
+	}
+	assertBigtableValue(t, table, "c", 3)
+
+	// Only the newest item of an entity in a batch is written, or the last
+	// one if they have the same timestamp.
+	items = []SetItem{
+		{Entity: "d", Value: 1, TS: newer},
+		{Entity: "d", Value: 2, TS: older},
+		{Entity: "e", Value: 1, TS: newer},
+		{Entity: "e", Value: 2, TS: newer},
+	}
+	if err := table.BatchSet(items); err != nil {
+		t.Fatalf("Failed to batch set: %v", err)
+	}
+	assertBigtableValue(t, table, "d", 1)
+	assertBigtableValue(t, table, "e", 2)
+
+	// Variants of the same feature don't overwrite each other.
+	other, err := store.CreateTable("transactions", "v2", vt.Int)
+	if err != nil {
+		t.Fatalf("Failed to create second variant: %v", err)
+	}
+	if err := other.Set("a", 20); err != nil {
+		t.Fatalf("Failed to set value: %v", err)
+	}
+	assertBigtableValue(t, table, "a", 2)
+	assertBigtableValue(t, other, "a", 20)
+}
+
+func TestBigtableBatchSetLimit(t *testing.T) {
+	table := newBigtableTestTable(t, newBigtableTestStore(t), vt.Int)
+	items := make([]SetItem, maxBigtableBatchSize+1)
+	if err := table.BatchSet(items); err == nil {
+		t.Fatalf("Expected an error writing more than %d items", maxBigtableBatchSize)
+	}
+}
+
+func TestBigtableDeleteTable(t *testing.T) {
+	store := newBigtableTestStore(t)
+	table := newBigtableTestTable(t, store, vt.Int)
+	other, err := store.CreateTable("transactions", "v2", vt.Int)
+	if err != nil {
+		t.Fatalf("Failed to create second variant: %v", err)
+	}
+	for _, tbl := range []OnlineStoreTable{table, other} {
+		if err := tbl.Set("a", 1); err != nil {
+			t.Fatalf("Failed to set value: %v", err)
+		}
+	}
+	if err := store.DeleteTable("transactions", "default"); err != nil {
+		t.Fatalf("Failed to delete table: %v", err)
+	}
+	if _, err := store.GetTable("transactions", "default"); err == nil {
+		t.Fatalf("Expected deleted table to not exist")
+	}
+	if _, err := table.Get("a"); err == nil {
+		t.Fatalf("Expected deleted table's values to be removed")
+	}
+	assertBigtableValue(t, other, "a", 1)
+	// A variant can be created again after it's deleted.
+	recreated := newBigtableTestTable(t, store, vt.String)
+	if _, err := recreated.Get("a"); err == nil {
+		t.Fatalf("Expected recreated table to be empty")
+	}
+}
@@ -202,7 +202,12 @@ func (store *cassandraOnlineStore) DeleteTable(feature, variant string) error {
 }
 
 func (store *cassandraOnlineStore) CheckHealth() (bool, error) {
-	return false, fferr.NewInternalError(fmt.Errorf("not implemented"))
+	if err := store.session.Query("SELECT now() FROM system.local").Exec(); err != nil {
+		wrapped := fferr.NewConnectionError(pt.CassandraOnline.String(), err)
+		wrapped.AddDetail("action", "query system.local")
+		return false, wrapped
+	}
+	return true, nil
 }
 
 func (store cassandraOnlineStore) Delete(location pl.Location) error {
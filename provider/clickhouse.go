@@ -21,6 +21,7 @@ import (
 
 	"github.com/ClickHouse/clickhouse-go/v2"
 	"github.com/featureform/fferr"
+	"github.com/featureform/filestore"
 	"github.com/featureform/metadata"
 	pl "github.com/featureform/provider/location"
 	pc "github.com/featureform/provider/provider_config"
@@ -229,27 +230,15 @@ func NewClickHouseOfflineStore(config pc.SerializedConfig) (*clickHouseOfflineSt
 	if err := cc.Deserialize(config); err != nil {
 		return nil, err
 	}
-	var t *tls.Config
-	if cc.SSL {
-		t = &tls.Config{}
-	}
+	conns := newSQLConnections()
 	getDbFunc := func(database, schema string) (*sql.DB, error) {
 		clickhouseDb := database
 		if database != "" {
 			clickhouseDb = cc.Database
 		}
-		return clickhouse.OpenDB(&clickhouse.Options{
-			Addr: []string{fmt.Sprintf("%s:%d", cc.Host, cc.Port)},
-			Auth: clickhouse.Auth{
-				Database: clickhouseDb,
-				Username: cc.Username,
-				Password: cc.Password,
-			},
-			Settings: clickhouse.Settings{
-				"final": "1",
-			},
-			TLS: t,
-		}), nil
+		return conns.get(clickhouseDb, func() (*sql.DB, error) {
+			return clickhouse.OpenDB(clickhouseOptions(cc, clickhouseDb)), nil
+		})
 	}
 
 	queries := clickhouseSQLQueries{}
@@ -275,9 +264,40 @@ func NewClickHouseOfflineStore(config pc.SerializedConfig) (*clickHouseOfflineSt
 			ProviderConfig: config,
 		},
 		getDb: getDbFunc,
+		conns: conns,
 	}}, nil
 }
 
+// clickhouseOptions connects over the native protocol, which sends batched inserts as
+// columnar blocks rather than as SQL text.
+func clickhouseOptions(cc pc.ClickHouseConfig, database string) *clickhouse.Options {
+	var t *tls.Config
+	if cc.SSL {
+		t = &tls.Config{}
+	}
+	settings := clickhouse.Settings{
+		"final": "1",
+	}
+	if cc.AsyncInsert {
+		// Waiting for the buffer to be flushed keeps inserts visible to the
+		// materialization that follows them, and keeps them in order so the
+		// ReplacingMergeTree still keeps the latest write for an entity and timestamp.
+		settings["async_insert"] = "1"
+		settings["wait_for_async_insert"] = "1"
+	}
+	return &clickhouse.Options{
+		Addr:     []string{fmt.Sprintf("%s:%d", cc.Host, cc.Port)},
+		Protocol: clickhouse.Native,
+		Auth: clickhouse.Auth{
+			Database: database,
+			Username: cc.Username,
+			Password: cc.Password,
+		},
+		Settings: settings,
+		TLS:      t,
+	}
+}
+
 type clickhouseOfflineTable struct {
 	db    *sql.DB
 	query OfflineTableQueries
@@ -301,61 +321,53 @@ func (table *clickhouseOfflineTable) Write(rec ResourceRecord) error {
 
 const batchSize = 10000
 
+// WriteBatch sends recs as native protocol batches of up to batchSize rows, each
+// of which ClickHouse writes as a single insert.
 func (table *clickhouseOfflineTable) WriteBatch(recs []ResourceRecord) error {
-	tb := SanitizeClickHouseIdentifier(table.name)
-	scope, err := table.db.Begin()
-	if err != nil {
-		wrapped := fferr.NewExecutionError(pt.ClickHouseOffline.String(), err)
-		wrapped.AddDetail("table_name", table.name)
-		return wrapped
-	}
-	batch, err := scope.Prepare(fmt.Sprintf("INSERT INTO %s (entity, value, ts)", tb))
-	if err != nil {
-		wrapped := fferr.NewExecutionError(pt.ClickHouseOffline.String(), err)
-		wrapped.AddDetail("table_name", table.name)
-		return wrapped
-	}
-	b := 0
-	for i, _ := range recs {
+	for i := range recs {
 		if recs[i].Entity == "" && recs[i].Value == nil && recs[i].TS.IsZero() {
 			wrapped := fferr.NewInvalidArgumentError(fmt.Errorf("invalid record at offset %d", i))
 			wrapped.AddDetail("table_name", table.name)
 			return wrapped
 		}
-		ts := recs[i].TS
-		// insert empty time.Time{} as 1970
-		ts = checkZeroTime(recs[i].TS)
-		_, err := batch.Exec(recs[i].Entity, recs[i].Value, ts)
-		if err != nil {
+	}
+	for start := 0; start < len(recs); start += batchSize {
+		end := start + batchSize
+		if end > len(recs) {
+			end = len(recs)
+		}
+		if err := table.writeBatch(recs[start:end]); err != nil {
 			wrapped := fferr.NewExecutionError(pt.ClickHouseOffline.String(), err)
 			wrapped.AddDetail("table_name", table.name)
 			return wrapped
 		}
-		b += 1
-		if b == batchSize {
-			err = scope.Commit()
-			if err != nil {
-				wrapped := fferr.NewExecutionError(pt.ClickHouseOffline.String(), err)
-				wrapped.AddDetail("table_name", table.name)
-				return wrapped
-			}
-			batch, err = scope.Prepare(fmt.Sprintf("INSERT INTO %s (entity, value, ts)", tb))
-			if err != nil {
-				wrapped := fferr.NewExecutionError(pt.ClickHouseOffline.String(), err)
-				wrapped.AddDetail("table_name", table.name)
-				return wrapped
-			}
-			b = 0
-		}
-	}
-	if err := scope.Commit(); err != nil {
-		wrapped := fferr.NewExecutionError(pt.ClickHouseOffline.String(), err)
-		wrapped.AddDetail("table_name", table.name)
-		return wrapped
 	}
 	return nil
 }
 
+// writeBatch sends recs as a single batch. A batch is a transaction in the
+// ClickHouse driver, so each one needs its own.
+func (table *clickhouseOfflineTable) writeBatch(recs []ResourceRecord) error {
+	tb := SanitizeClickHouseIdentifier(table.name)
+	scope, err := table.db.Begin()
+	if err != nil {
+		return err
+	}
+	batch, err := scope.Prepare(fmt.Sprintf("INSERT INTO %s (entity, value, ts)", tb))
+	if err != nil {
+		scope.Rollback()
+		return err
+	}
+	for _, rec := range recs {
+		// insert empty time.Time{} as 1970
+		if _, err := batch.Exec(rec.Entity, rec.Value, checkZeroTime(rec.TS)); err != nil {
+			scope.Rollback()
+			return err
+		}
+	}
+	return scope.Commit()
+}
+
 func (table *clickhouseOfflineTable) Location() pl.Location {
 	return pl.NewSQLLocation(table.name)
 }
@@ -577,8 +589,8 @@ func (it *clickHouseTableIterator) Close() error {
 }
 
 func (store *clickHouseOfflineStore) RegisterResourceFromSourceTable(id ResourceID, schema ResourceSchema, opts ...ResourceOption) (OfflineTable, error) {
-	if len(opts) > 0 {
-		return nil, fferr.NewInternalErrorf("ClickHouse does not support resource options")
+	if err := schema.checkUnparsed(pt.ClickHouseOffline, opts...); err != nil {
+		return nil, err
 	}
 	if err := id.check(Feature, Label); err != nil {
 		return nil, err
@@ -630,7 +642,10 @@ func (store *clickHouseOfflineStore) Delete(location pl.Location) error {
 	return fferr.NewInternalErrorf("delete not implemented")
 }
 
-func (store *clickHouseOfflineStore) RegisterPrimaryFromSourceTable(id ResourceID, tableLocation pl.Location) (PrimaryTable, error) {
+func (store *clickHouseOfflineStore) RegisterPrimaryFromSourceTable(id ResourceID, tableLocation pl.Location, opts ...PrimaryOption) (PrimaryTable, error) {
+	if len(opts) > 0 {
+		return nil, fferr.NewInternalErrorf("ClickHouse does not support primary table options")
+	}
 	if err := id.check(Primary); err != nil {
 		return nil, err
 	}
@@ -672,6 +687,9 @@ func (store *clickHouseOfflineStore) CreateTransformation(config TransformationC
 	if len(opts) > 0 {
 		return fferr.NewInternalErrorf("ClickHouse does not support transformation options")
 	}
+	if err := ValidateTransformation(config); err != nil {
+		return err
+	}
 	name, err := store.getTransformationTableName(config.TargetTableID)
 	if err != nil {
 		return err
@@ -922,6 +940,13 @@ func (store *clickHouseOfflineStore) SupportsMaterializationOption(opt Materiali
 	return false, nil
 }
 
+// Capabilities is overridden so that the ClickHouse Supports methods are used
+// rather than the embedded sqlOfflineStore's. Incremental updates aren't
+// supported, UpdateTransformation always rebuilds.
+func (store *clickHouseOfflineStore) Capabilities() (Capabilities, error) {
+	return offlineStoreCapabilities(store)
+}
+
 func (store *clickHouseOfflineStore) GetMaterialization(id MaterializationID) (Materialization, error) {
 	name, variant, err := ps.MaterializationIDToResource(string(id))
 	if err != nil {
@@ -952,6 +977,9 @@ func (store *clickHouseOfflineStore) GetMaterialization(id MaterializationID) (M
 }
 
 func (store *clickHouseOfflineStore) UpdateMaterialization(id ResourceID, opts MaterializationOptions) (Materialization, error) {
+	if opts.Incremental {
+		return nil, fferr.NewUnimplementedErrorf("incremental materialization is not supported for ClickHouse")
+	}
 	matID, err := NewMaterializationID(id)
 	if err != nil {
 		return nil, err
@@ -1050,6 +1078,8 @@ func (store *clickHouseOfflineStore) UpdateTrainingSet(def TrainingSetDef) error
 type TrainingSetPreparation struct {
 	TrainingSetName string
 	Columns         string
+	// HasSplit is true if the training set was created with a Split.
+	HasSplit bool
 }
 
 func (store *clickHouseOfflineStore) prepareTrainingSetQuery(id ResourceID) (*TrainingSetPreparation, error) {
@@ -1070,7 +1100,13 @@ func (store *clickHouseOfflineStore) prepareTrainingSetQuery(id ResourceID) (*Tr
 		return nil, err
 	}
 	features := make([]string, 0)
+	hasSplit := false
 	for _, name := range columnNames {
+		// The split column is bookkeeping and is never returned as a feature.
+		if name.Name == trainingSetSplitColumn {
+			hasSplit = true
+			continue
+		}
 		features = append(features, SanitizeClickHouseIdentifier(name.Name))
 	}
 	columns := strings.Join(features, ", ")
@@ -1078,6 +1114,7 @@ func (store *clickHouseOfflineStore) prepareTrainingSetQuery(id ResourceID) (*Tr
 	return &TrainingSetPreparation{
 		TrainingSetName: trainingSetName,
 		Columns:         columns,
+		HasSplit:        hasSplit,
 	}, nil
 }
 
@@ -1097,7 +1134,7 @@ func (store *clickHouseOfflineStore) GetTrainingSet(id ResourceID) (TrainingSetI
 	if err != nil {
 		return nil, err
 	}
-	return store.newsqlTrainingSetIterator(rows, colTypes), nil
+	return store.newsqlTrainingSetIterator(rows, colTypes, 1), nil
 }
 
 func (store *clickHouseOfflineStore) CreateTrainTestSplit(def TrainTestSplitDef) (func() error, error) {
@@ -1162,6 +1199,33 @@ func (store *clickHouseOfflineStore) getTrainTestSplitTableName(trainingSetTable
 	return trainTestSplitViewName
 }
 
+func (store *clickHouseOfflineStore) GetTrainingSetSplit(id ResourceID, split string) (TrainingSetIterator, error) {
+	if err := checkTrainingSetSplitName(split); err != nil {
+		return nil, err
+	}
+	prep, err := store.prepareTrainingSetQuery(id)
+	if err != nil {
+		return nil, err
+	}
+	if !prep.HasSplit {
+		return nil, fferr.NewInvalidArgumentErrorf("training set %s (%s) was not created with a split", id.Name, id.Variant)
+	}
+	trainingSetQry := clickHouseSplitRowSelect(prep.Columns, prep.TrainingSetName, split)
+	rows, err := store.db.Query(trainingSetQry)
+	if err != nil {
+		return nil, fferr.NewResourceExecutionError(pt.ClickHouseOffline.String(), id.Name, id.Variant, fferr.ResourceType(id.Type.String()), err)
+	}
+	colTypes, err := store.getValueColumnTypes(prep.TrainingSetName)
+	if err != nil {
+		return nil, err
+	}
+	return store.newsqlTrainingSetIterator(rows, colTypes, 1), nil
+}
+
+func (store *clickHouseOfflineStore) ExportTrainingSet(id ResourceID, location pl.Location, format filestore.FileType) ([]filestore.Filepath, error) {
+	return nil, fferr.NewUnimplementedErrorf("training set export is not supported for ClickHouse")
+}
+
 func (store *clickHouseOfflineStore) GetTrainTestSplit(def TrainTestSplitDef) (TrainingSetIterator, TrainingSetIterator, error) {
 	prep, err := store.prepareTrainingSetQuery(ResourceID{Name: def.TrainingSetName, Variant: def.TrainingSetVariant})
 	if err != nil {
@@ -1188,7 +1252,7 @@ func (store *clickHouseOfflineStore) GetTrainTestSplit(def TrainTestSplitDef) (T
 		return nil, nil, fmt.Errorf("could not get column types: %v", err)
 	}
 
-	return store.newsqlTrainingSetIterator(trainRows, colTypes), store.newsqlTrainingSetIterator(testRows, colTypes), nil
+	return store.newsqlTrainingSetIterator(trainRows, colTypes, 1), store.newsqlTrainingSetIterator(testRows, colTypes, 1), nil
 
 }
 
@@ -1207,8 +1271,45 @@ func (store *clickHouseOfflineStore) ResourceLocation(id ResourceID, resource an
 	return pl.NewSQLLocation(tableName), err
 }
 
+func (store *clickHouseOfflineStore) GetResourceStats(id ResourceID, resource any) (ResourceStats, error) {
+	var tableName string
+	if id.Type == Primary {
+		sv, ok := resource.(*metadata.SourceVariant)
+		if !ok {
+			return ResourceStats{}, fferr.NewInvalidArgumentErrorf("resource is not a SourceVariant")
+		}
+		tableName = sv.PrimaryDataSQLTableName()
+	} else {
+		if err := id.check(Feature, Label, TrainingSet, Transformation); err != nil {
+			return ResourceStats{}, err
+		}
+		var err error
+		if tableName, err = ps.ResourceToTableName(id.Type.String(), id.Name, id.Variant); err != nil {
+			return ResourceStats{}, err
+		}
+	}
+	var count int64
+	var maxTS sql.NullTime
+	query := fmt.Sprintf("SELECT COUNT(*) FROM %s", SanitizeClickHouseIdentifier(tableName))
+	dest := []interface{}{&count}
+	if id.Type == Feature || id.Type == Label {
+		query = fmt.Sprintf("SELECT COUNT(*), MAX(ts) FROM %s", SanitizeClickHouseIdentifier(tableName))
+		dest = append(dest, &maxTS)
+	}
+	if err := store.db.QueryRow(query).Scan(dest...); err != nil {
+		wrapped := fferr.NewExecutionError(pt.ClickHouseOffline.String(), err)
+		wrapped.AddDetail("table_name", tableName)
+		return ResourceStats{}, wrapped
+	}
+	stats := ResourceStats{RowCount: count}
+	if maxTS.Valid {
+		stats.MaxTimestamp = maxTS.Time.UTC()
+	}
+	return stats, nil
+}
+
 func (store *clickHouseOfflineStore) Close() error {
-	return store.db.Close()
+	return store.sqlOfflineStore.Close()
 }
 
 type clickhouseSQLQueries struct {
@@ -1232,6 +1333,12 @@ func (q clickhouseSQLQueries) trainingRowSelect(columns string, trainingSetName
 	return fmt.Sprintf("SELECT * EXCEPT _row FROM (SELECT %s FROM %s ORDER BY _row ASC)", columns, SanitizeClickHouseIdentifier(trainingSetName))
 }
 
+// clickHouseSplitRowSelect selects the rows of a training set created with a
+// Split that were assigned to split.
+func clickHouseSplitRowSelect(columns string, trainingSetName string, split string) string {
+	return fmt.Sprintf("SELECT * EXCEPT _row FROM (SELECT %s FROM %s WHERE %s = '%s' ORDER BY _row ASC)", columns, SanitizeClickHouseIdentifier(trainingSetName), trainingSetSplitColumn, split)
+}
+
 func (q clickhouseSQLQueries) trainingRowSplitSelect(columns string, trainingSetSplitName string) (string, string) {
 	testSplitQuery := fmt.Sprintf("SELECT * EXCEPT _row FROM (SELECT %s FROM %s WHERE `is_test` = 1 ORDER BY _row ASC)", columns, trainingSetSplitName)
 	trainSplitQuery := fmt.Sprintf("SELECT * EXCEPT _row FROM (SELECT %s FROM %s WHERE `is_test` = 0 ORDER BY _row ASC)", columns, trainingSetSplitName)
@@ -1351,9 +1458,16 @@ func (q clickhouseSQLQueries) writeUpdate(table string) string {
 	return fmt.Sprintf("INSERT INTO %s (entity, value, ts) VALUES (%s, %s, %s)", table, bind.Next(), bind.Next(), bind.Next())
 }
 
+// writeInserts inserts a single row. Each insert would otherwise create its own
+// part, so rows are sent as async inserts, which ClickHouse buffers and writes
+// together with other rows inserted into the table at the same time. Waiting for
+// the buffer to be flushed keeps the row readable once the insert returns.
 func (q clickhouseSQLQueries) writeInserts(table string) string {
 	bind := q.newVariableBindingIterator()
-	return fmt.Sprintf("INSERT INTO %s (entity, value, ts) VALUES (%s, %s, %s)", table, bind.Next(), bind.Next(), bind.Next())
+	return fmt.Sprintf(
+		"INSERT INTO %s (entity, value, ts) SETTINGS async_insert=1, wait_for_async_insert=1 VALUES (%s, %s, %s)",
+		table, bind.Next(), bind.Next(), bind.Next(),
+	)
 }
 
 func (q clickhouseSQLQueries) createValuePlaceholderString(columns []TableColumn) string {
@@ -1382,17 +1496,37 @@ func buildTrainingSelect(store *sqlOfflineStore, def TrainingSetDef, tableName s
 		}
 		santizedName := SanitizeClickHouseIdentifier(tableName)
 		tableJoinAlias := fmt.Sprintf("t%d", i)
-		columns = append(columns, fmt.Sprintf("%s.value AS %s", tableJoinAlias, santizedName))
+		value := fmt.Sprintf("%s.value", tableJoinAlias)
+		// An ASOF join can't bound how old its match is, so values older than
+		// the max feature age are nulled out instead.
+		if def.MaxFeatureAge > 0 {
+			value = fmt.Sprintf("if(%s.ts >= l.ts - INTERVAL %d SECOND, %s, NULL)", tableJoinAlias, int64(def.MaxFeatureAge.Seconds()), value)
+		}
+		columns = append(columns, fmt.Sprintf("%s AS %s", value, santizedName))
 		query = fmt.Sprintf("%s ASOF LEFT JOIN (SELECT entity, value, ts FROM %s) AS %s ON (%s.entity = l.entity) AND (%s.ts <= l.ts)",
 			query, santizedName, tableJoinAlias, tableJoinAlias, tableJoinAlias)
 	}
 	columnStr := strings.Join(columns, ", ")
+	splitSelect := ""
+	if def.Split != nil {
+		splitSelect = trainingSetSplitSelect(*def.Split, store.query.trainingSetSplitBucket("l.entity", "l.ts", def.Split.Seed))
+	}
 	// rand gives us a UInt32 to ensure random order
-	query = fmt.Sprintf("SELECT %s, l.value as label, rand() as _row FROM %s AS l %s", columnStr, SanitizeClickHouseIdentifier(labelName), query)
+	query = fmt.Sprintf("SELECT %s, l.value as label, rand() as _row%s FROM %s AS l %s", columnStr, splitSelect, SanitizeClickHouseIdentifier(labelName), query)
 	return query, nil
 }
 
+func (q clickhouseSQLQueries) trainingSetSplitBucket(entity, ts string, seed int64) string {
+	return fmt.Sprintf("cityHash64(toString(%s), toString(%s), %d) %% %d", entity, ts, seed, trainingSetSplitBuckets)
+}
+
 func (q clickhouseSQLQueries) trainingSetQuery(store *sqlOfflineStore, def TrainingSetDef, tableName string, labelName string, isUpdate bool) error {
+	if err := def.checkSingleLabel(pt.ClickHouseOffline); err != nil {
+		return err
+	}
+	if err := def.checkNotPersisted(pt.ClickHouseOffline); err != nil {
+		return err
+	}
 	query, err := buildTrainingSelect(store, def, tableName, labelName)
 	if err != nil {
 		return err
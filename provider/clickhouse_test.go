@@ -11,13 +11,17 @@ import (
 	"database/sql"
 	"fmt"
 
+	"github.com/ClickHouse/clickhouse-go/v2"
+	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/featureform/helpers"
 	pc "github.com/featureform/provider/provider_config"
 	pt "github.com/featureform/provider/provider_type"
 	"github.com/joho/godotenv"
 
 	"os"
+	"regexp"
 	"strconv"
+	"strings"
 	"testing"
 	"time"
 )
@@ -84,7 +88,6 @@ func TestOfflineStoreClickhouse(t *testing.T) {
 		store: store,
 	}
 	test.Run()
-	// test.RunSQL()
 }
 
 func createClickHouseDatabase(c pc.ClickHouseConfig) error {
@@ -223,3 +226,103 @@ func TestSplit(t *testing.T) {
 		t.Fatalf("health check failed")
 	}
 }
+
+func TestClickHouseOptions(t *testing.T) {
+	config := pc.ClickHouseConfig{
+		Host:     "localhost",
+		Port:     9000,
+		Username: "default",
+		Password: "password",
+		Database: "featureform",
+	}
+	opts := clickhouseOptions(config, "featureform")
+	if opts.Protocol != clickhouse.Native {
+		t.Fatalf("expected native protocol, got %s", opts.Protocol)
+	}
+	if opts.Addr[0] != "localhost:9000" || opts.Auth.Database != "featureform" {
+		t.Fatalf("unexpected address or database: %v %s", opts.Addr, opts.Auth.Database)
+	}
+	if _, ok := opts.Settings["async_insert"]; ok {
+		t.Fatalf("expected async inserts to be off by default: %v", opts.Settings)
+	}
+
+	config.AsyncInsert = true
+	opts = clickhouseOptions(config, "featureform")
+	if opts.Settings["async_insert"] != "1" || opts.Settings["wait_for_async_insert"] != "1" {
+		t.Fatalf("expected async inserts that wait to be flushed, got: %v", opts.Settings)
+	}
+	if opts.Settings["final"] != "1" {
+		t.Fatalf("expected reads to still use final, got: %v", opts.Settings)
+	}
+}
+
+func TestClickHouseTrainingSelectMaxFeatureAge(t *testing.T) {
+	def := TrainingSetDef{
+		ID:            ResourceID{"training_set", "default", TrainingSet},
+		Label:         ResourceID{"fraudulent", "default", Label},
+		Features:      []ResourceID{{"avg_transactions", "default", Feature}},
+		MaxFeatureAge: time.Hour,
+	}
+	query, err := buildTrainingSelect(&sqlOfflineStore{}, def, "training_set", "label_table")
+	if err != nil {
+		t.Fatalf("could not build training select: %s", err)
+	}
+	expected := "if(t0.ts >= l.ts - INTERVAL 3600 SECOND, t0.value, NULL) AS"
+	if !strings.Contains(query, expected) {
+		t.Fatalf("expected query to contain %q, got: %s", expected, query)
+	}
+	def.MaxFeatureAge = 0
+	if query, err = buildTrainingSelect(&sqlOfflineStore{}, def, "training_set", "label_table"); err != nil {
+		t.Fatalf("could not build training select: %s", err)
+	}
+	if strings.Contains(query, "INTERVAL") {
+		t.Fatalf("expected no age filter without a max feature age, got: %s", query)
+	}
+}
+
+func TestClickHouseTrainingSelectSplit(t *testing.T) {
+	def := TrainingSetDef{
+		ID:       ResourceID{"training_set", "default", TrainingSet},
+		Label:    ResourceID{"fraudulent", "default", Label},
+		Features: []ResourceID{{"avg_transactions", "default", Feature}},
+		Split:    &TrainingSetSplit{TrainFraction: 0.8, Seed: 7},
+	}
+	store := &sqlOfflineStore{query: &clickhouseSQLQueries{}}
+	query, err := buildTrainingSelect(store, def, "training_set", "label_table")
+	if err != nil {
+		t.Fatalf("could not build training select: %s", err)
+	}
+	expected := "rand() as _row, CASE WHEN cityHash64(toString(l.entity), toString(l.ts), 7) % 10000 < 8000 THEN 'train' ELSE 'test' END AS ff_split FROM"
+	if !strings.Contains(query, expected) {
+		t.Fatalf("expected query to contain %q, got: %s", expected, query)
+	}
+	expected = "SELECT * EXCEPT _row FROM (SELECT a, label FROM `training_set` WHERE ff_split = 'test' ORDER BY _row ASC)"
+	if query := clickHouseSplitRowSelect("a, label", "training_set", TrainingSetSplitTest); query != expected {
+		t.Fatalf("expected split select %q, got: %s", expected, query)
+	}
+}
+
+func TestClickHouseWriteBatchInsertsInOneTransaction(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("could not create mock db: %v", err)
+	}
+	defer db.Close()
+	table := &clickhouseOfflineTable{db: db, query: &clickhouseSQLQueries{}, name: "resource_table"}
+	ts := time.UnixMilli(0).UTC()
+	recs := []ResourceRecord{
+		{Entity: "a", Value: 1, TS: ts},
+		{Entity: "b", Value: 2, TS: ts},
+	}
+	mock.ExpectBegin()
+	prep := mock.ExpectPrepare(regexp.QuoteMeta("INSERT INTO `resource_table` (entity, value, ts)"))
+	prep.ExpectExec().WithArgs("a", 1, ts).WillReturnResult(sqlmock.NewResult(0, 1))
+	prep.ExpectExec().WithArgs("b", 2, ts).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+	if err := table.WriteBatch(recs); err != nil {
+		t.Fatalf("WriteBatch failed: %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
@@ -0,0 +1,231 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+//
+// Copyright 2024 FeatureForm Inc.
+//
+
+package provider
+
+import (
+	"strings"
+
+	"github.com/parquet-go/parquet-go"
+
+	"github.com/featureform/fferr"
+	"github.com/featureform/filestore"
+	pl "github.com/featureform/provider/location"
+	"github.com/featureform/provider/types"
+)
+
+// columnTypeFamily groups the types of source columns and declared value types
+// into the kinds of values they hold, so types from different stores can be
+// compared.
+type columnTypeFamily string
+
+const (
+	unknownTypeFamily   columnTypeFamily = ""
+	intTypeFamily       columnTypeFamily = "int"
+	floatTypeFamily     columnTypeFamily = "float"
+	numericTypeFamily   columnTypeFamily = "numeric"
+	stringTypeFamily    columnTypeFamily = "string"
+	boolTypeFamily      columnTypeFamily = "bool"
+	timestampTypeFamily columnTypeFamily = "timestamp"
+	jsonTypeFamily      columnTypeFamily = "json"
+)
+
+// compatibleTypeFamilies maps the family of a declared value type to the
+// families of source columns that can back it. Numeric columns, like
+// Snowflake's NUMBER, can hold either ints or floats.
+var compatibleTypeFamilies = map[columnTypeFamily][]columnTypeFamily{
+	intTypeFamily:       {intTypeFamily, numericTypeFamily},
+	floatTypeFamily:     {intTypeFamily, floatTypeFamily, numericTypeFamily},
+	stringTypeFamily:    {stringTypeFamily, jsonTypeFamily},
+	boolTypeFamily:      {boolTypeFamily},
+	timestampTypeFamily: {timestampTypeFamily},
+	jsonTypeFamily:      {jsonTypeFamily, stringTypeFamily},
+}
+
+// typeFamiliesCompatible returns true if a column of the found family can back
+// a value of the expected family. Unknown families are always compatible since
+// there's nothing to check them against.
+func typeFamiliesCompatible(expected, found columnTypeFamily) bool {
+	if expected == unknownTypeFamily || found == unknownTypeFamily {
+		return true
+	}
+	for _, family := range compatibleTypeFamilies[expected] {
+		if family == found {
+			return true
+		}
+	}
+	return false
+}
+
+func valueTypeFamily(valueType types.ValueType) columnTypeFamily {
+	if valueType == nil || valueType.IsVector() {
+		return unknownTypeFamily
+	}
+	switch valueType.Scalar() {
+	case types.Int, types.Int8, types.Int16, types.Int32, types.Int64,
+		types.UInt8, types.UInt16, types.UInt32, types.UInt64:
+		return intTypeFamily
+	case types.Float32, types.Float64:
+		return floatTypeFamily
+	case types.String:
+		return stringTypeFamily
+	case types.Bool:
+		return boolTypeFamily
+	case types.Timestamp, types.Datetime:
+		return timestampTypeFamily
+	case types.JSON:
+		return jsonTypeFamily
+	default:
+		return unknownTypeFamily
+	}
+}
+
+// sqlColumnTypeFamily returns the family of a data_type from an
+// information_schema.columns table. Types we don't recognize, and MySQL's
+// tinyint which is also used for booleans, are unknown.
+func sqlColumnTypeFamily(dataType string) columnTypeFamily {
+	dataType = strings.ToLower(strings.TrimSpace(dataType))
+	if idx := strings.Index(dataType, "("); idx != -1 {
+		dataType = strings.TrimSpace(dataType[:idx])
+	}
+	switch dataType {
+	case "smallint", "integer", "int", "bigint", "int2", "int4", "int8", "mediumint", "byteint":
+		return intTypeFamily
+	case "numeric", "decimal", "number":
+		return numericTypeFamily
+	case "real", "double precision", "double", "float", "float4", "float8":
+		return floatTypeFamily
+	case "varchar", "character varying", "char", "character", "text", "string", "bpchar":
+		return stringTypeFamily
+	case "boolean", "bool":
+		return boolTypeFamily
+	case "date", "datetime":
+		return timestampTypeFamily
+	case "json", "jsonb", "variant", "object", "super":
+		return jsonTypeFamily
+	}
+	if strings.HasPrefix(dataType, "timestamp") {
+		return timestampTypeFamily
+	}
+	return unknownTypeFamily
+}
+
+// parquetFieldTypeFamily returns the family of a parquet column from its
+// physical and logical types. Nested and repeated columns are unknown.
+func parquetFieldTypeFamily(field parquet.Field) columnTypeFamily {
+	if !field.Leaf() || field.Repeated() {
+		return unknownTypeFamily
+	}
+	logical := field.Type().LogicalType()
+	switch field.Type().Kind() {
+	case parquet.Boolean:
+		return boolTypeFamily
+	case parquet.Int32, parquet.Int64:
+		switch {
+		case logical != nil && (logical.Timestamp != nil || logical.Date != nil):
+			return timestampTypeFamily
+		case logical != nil && logical.Decimal != nil:
+			return numericTypeFamily
+		case logical != nil && logical.Time != nil:
+			return unknownTypeFamily
+		}
+		return intTypeFamily
+	case parquet.Int96:
+		return timestampTypeFamily
+	case parquet.Float, parquet.Double:
+		return floatTypeFamily
+	case parquet.ByteArray, parquet.FixedLenByteArray:
+		switch {
+		case logical != nil && (logical.UTF8 != nil || logical.Enum != nil):
+			return stringTypeFamily
+		case logical != nil && logical.Json != nil:
+			return jsonTypeFamily
+		case logical != nil && logical.Decimal != nil:
+			return numericTypeFamily
+		}
+	}
+	return unknownTypeFamily
+}
+
+// valueColumn returns the column the resource's values are read from.
+func (r ResourceSchema) valueColumn() string {
+	if r.Value != "" {
+		return r.Value
+	}
+	return r.EntityMappings.ValueColumn
+}
+
+// checkValueColumnType fails with a ColumnTypeMismatchError if the value column
+// can't back the schema's ValueType. columnType returns the name and family of a
+// source column's type, or an unknown family if the column isn't found. If the
+// value column is cast, the cast type is checked instead of the source's.
+func (r ResourceSchema) checkValueColumnType(columnType func(column string) (string, columnTypeFamily, error)) error {
+	expected := valueTypeFamily(r.ValueType)
+	column := r.valueColumn()
+	if expected == unknownTypeFamily || column == "" {
+		return nil
+	}
+	var found string
+	var family columnTypeFamily
+	if target, isCast := r.Casts[column]; isCast {
+		found, family = target.String(), valueTypeFamily(target)
+	} else {
+		var err error
+		if found, family, err = columnType(column); err != nil {
+			return err
+		}
+	}
+	if !typeFamiliesCompatible(expected, family) {
+		return fferr.NewColumnTypeMismatchError(column, r.ValueType.String(), found, nil)
+	}
+	return nil
+}
+
+// fileColumnType returns a columnType func for checkValueColumnType that reads
+// the schema of a CSV or parquet file. If the location is a directory, like
+// the output of a transformation, its newest parquet file is read. Other files
+// have an unknown type.
+func fileColumnType(store FileStore, location pl.FileStoreLocation) func(column string) (string, columnTypeFamily, error) {
+	return func(column string) (string, columnTypeFamily, error) {
+		path := location.Filepath()
+		if path.IsDir() {
+			newest, err := store.NewestFileOfType(path, filestore.Parquet)
+			if err != nil {
+				return "", unknownTypeFamily, err
+			}
+			path = newest
+		}
+		switch {
+		case isDelimitedFile(path, location.CSVOptions()):
+			file, err := openDecompressed(store, path)
+			if err != nil {
+				return "", unknownTypeFamily, err
+			}
+			columns, err := detectCSVColumns(file, defaultSchemaDetectionSampleSize, location.CSVOptions())
+			file.Close()
+			if err != nil {
+				return "", unknownTypeFamily, err
+			}
+			for _, col := range columns {
+				if col.Name == column {
+					return col.ValueType.String(), valueTypeFamily(col.ValueType), nil
+				}
+			}
+		case path.Ext() == filestore.Parquet:
+			src, err := store.ReaderAt(path)
+			if err != nil {
+				return "", unknownTypeFamily, err
+			}
+			for _, field := range parquet.NewReader(src).Schema().Fields() {
+				if field.Name() == column {
+					return field.Type().String(), parquetFieldTypeFamily(field), nil
+				}
+			}
+		}
+		return "", unknownTypeFamily, nil
+	}
+}
@@ -0,0 +1,140 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+//
+// Copyright 2024 FeatureForm Inc.
+//
+
+package provider
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+
+	"github.com/parquet-go/parquet-go"
+	"go.uber.org/zap/zaptest"
+
+	"github.com/featureform/fferr"
+	"github.com/featureform/metadata"
+	pl "github.com/featureform/provider/location"
+	"github.com/featureform/provider/types"
+)
+
+func TestSQLColumnTypeFamily(t *testing.T) {
+	tests := map[string]columnTypeFamily{
+		"integer":                     intTypeFamily,
+		"BIGINT":                      intTypeFamily,
+		"NUMBER":                      numericTypeFamily,
+		"numeric(10, 2)":              numericTypeFamily,
+		"double precision":            floatTypeFamily,
+		"FLOAT":                       floatTypeFamily,
+		"character varying":           stringTypeFamily,
+		"TEXT":                        stringTypeFamily,
+		"boolean":                     boolTypeFamily,
+		"timestamp without time zone": timestampTypeFamily,
+		"TIMESTAMP_NTZ":               timestampTypeFamily,
+		"date":                        timestampTypeFamily,
+		"jsonb":                       jsonTypeFamily,
+		"VARIANT":                     jsonTypeFamily,
+		"tinyint":                     unknownTypeFamily,
+		"ARRAY":                       unknownTypeFamily,
+	}
+	for dataType, expected := range tests {
+		t.Run(dataType, func(t *testing.T) {
+			if family := sqlColumnTypeFamily(dataType); family != expected {
+				t.Fatalf("expected %s to be %q, got %q", dataType, expected, family)
+			}
+		})
+	}
+}
+
+func TestCheckValueColumnType(t *testing.T) {
+	tests := map[string]struct {
+		valueType types.ValueType
+		dataType  string
+		casts     map[string]types.ScalarType
+		mismatch  bool
+	}{
+		"Int From Integer":       {valueType: types.Int64, dataType: "integer"},
+		"Int From Number":        {valueType: types.Int, dataType: "NUMBER"},
+		"Float From Integer":     {valueType: types.Float64, dataType: "bigint"},
+		"String From Json":       {valueType: types.String, dataType: "jsonb"},
+		"Unknown Source Type":    {valueType: types.Bool, dataType: "tinyint"},
+		"Missing Column":         {valueType: types.Bool, dataType: ""},
+		"Vector":                 {valueType: types.VectorType{ScalarType: types.Float32, Dimension: 3}, dataType: "text"},
+		"Undeclared Type":        {valueType: nil, dataType: "text"},
+		"Int From Float":         {valueType: types.Int64, dataType: "double precision", mismatch: true},
+		"Int From Text":          {valueType: types.Int32, dataType: "character varying", mismatch: true},
+		"Timestamp From Text":    {valueType: types.Timestamp, dataType: "text", mismatch: true},
+		"Bool From Integer":      {valueType: types.Bool, dataType: "integer", mismatch: true},
+		"Cast Text To Int":       {valueType: types.Int64, dataType: "text", casts: map[string]types.ScalarType{"value": types.Int64}},
+		"Cast Text To Bad Float": {valueType: types.Int64, dataType: "text", casts: map[string]types.ScalarType{"value": types.Float64}, mismatch: true},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			schema := ResourceSchema{Entity: "entity", Value: "value", ValueType: test.valueType, Casts: test.casts}
+			err := schema.checkValueColumnType(func(column string) (string, columnTypeFamily, error) {
+				if column != "value" {
+					t.Fatalf("expected the value column to be checked, got %s", column)
+				}
+				return test.dataType, sqlColumnTypeFamily(test.dataType), nil
+			})
+			_, isMismatch := err.(*fferr.ColumnTypeMismatchError)
+			if err != nil && !isMismatch {
+				t.Fatalf("expected a column type mismatch, got %v", err)
+			}
+			if isMismatch != test.mismatch {
+				t.Fatalf("expected mismatch %v, got %v", test.mismatch, err)
+			}
+		})
+	}
+}
+
+func TestBlobRegisterResourceValueType(t *testing.T) {
+	store, err := NewLocalFileStore([]byte(fmt.Sprintf(`{"DirPath": "file://%s/"}`, t.TempDir())))
+	if err != nil {
+		t.Fatalf("could not create local file store: %v", err)
+	}
+	csvPath, err := store.CreateFilePath("transactions.csv", false)
+	if err != nil {
+		t.Fatalf("could not create file path: %v", err)
+	}
+	if err := store.Write(csvPath, []byte("user,amount,note\nalice,1.5,a\nbob,2,b\n")); err != nil {
+		t.Fatalf("could not write CSV: %v", err)
+	}
+	type transaction struct {
+		User   string  `parquet:"user"`
+		Amount float64 `parquet:"amount"`
+		Note   string  `parquet:"note"`
+	}
+	buf := new(bytes.Buffer)
+	if err := parquet.Write(buf, []transaction{{User: "alice", Amount: 1.5, Note: "a"}}); err != nil {
+		t.Fatalf("could not write parquet: %v", err)
+	}
+	parquetPath, err := store.CreateFilePath("transactions.parquet", false)
+	if err != nil {
+		t.Fatalf("could not create file path: %v", err)
+	}
+	if err := store.Write(parquetPath, buf.Bytes()); err != nil {
+		t.Fatalf("could not write parquet: %v", err)
+	}
+	logger := zaptest.NewLogger(t).Sugar()
+	for i, path := range []pl.Location{pl.NewFileLocation(csvPath), pl.NewFileLocation(parquetPath)} {
+		feature := ResourceSchema{Entity: "user", Value: "amount", SourceTable: path, ValueType: types.Float64}
+		id := ResourceID{Name: "amount", Variant: fmt.Sprintf("v%d", i), Type: Feature}
+		if _, err := blobRegisterResourceFromSourceTable(id, feature, logger, store); err != nil {
+			t.Fatalf("could not register feature from %s: %v", path.Location(), err)
+		}
+		label := ResourceSchema{
+			SourceTable:    path,
+			EntityMappings: metadata.EntityMappings{Mappings: []metadata.EntityMapping{{Name: "user", EntityColumn: "user"}}, ValueColumn: "note"},
+			ValueType:      types.Int64,
+		}
+		id = ResourceID{Name: "note", Variant: fmt.Sprintf("v%d", i), Type: Label}
+		_, err := blobRegisterResourceFromSourceTable(id, label, logger, store)
+		if _, isMismatch := err.(*fferr.ColumnTypeMismatchError); !isMismatch {
+			t.Fatalf("expected a column type mismatch registering a label from %s, got %v", path.Location(), err)
+		}
+	}
+}
@@ -0,0 +1,64 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+//
+// Copyright 2024 FeatureForm Inc.
+//
+
+package provider
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+
+	"github.com/featureform/fferr"
+	"github.com/featureform/filestore"
+	"github.com/klauspost/compress/zstd"
+)
+
+// openDecompressed opens the file at path, decompressing it if its extension
+// names a compression codec. The reader must be closed to release the decoder.
+func openDecompressed(store FileStore, path filestore.Filepath) (io.ReadCloser, error) {
+	src, err := store.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	reader, err := decompressReader(src, path.Compression())
+	if err != nil {
+		wrapped := fferr.NewInvalidArgumentError(fmt.Errorf("could not decompress file: %w", err))
+		wrapped.AddDetail("uri", path.ToURI())
+		wrapped.AddDetail("compression", string(path.Compression()))
+		return nil, wrapped
+	}
+	return reader, nil
+}
+
+// decompressReader returns a reader of the decompressed contents of src.
+// Closing it releases the decoder but doesn't close src.
+func decompressReader(src io.Reader, compression filestore.Compression) (io.ReadCloser, error) {
+	switch compression {
+	case filestore.NoCompression:
+		return io.NopCloser(src), nil
+	case filestore.Gzip:
+		return gzip.NewReader(src)
+	case filestore.Zstd:
+		decoder, err := zstd.NewReader(src)
+		if err != nil {
+			return nil, err
+		}
+		return decoder.IOReadCloser(), nil
+	default:
+		return nil, fmt.Errorf("unsupported compression %s", compression)
+	}
+}
+
+// checkUncompressed returns an error if the file at path is compressed. Parquet
+// and Avro files are compressed internally and are read in place, so they can't
+// be compressed as a whole.
+func checkUncompressed(path filestore.Filepath) error {
+	if compression := path.Compression(); compression != filestore.NoCompression {
+		return fferr.NewInvalidArgumentErrorf("%s files compressed with %s are not supported: %s", path.Ext(), compression, path.ToURI())
+	}
+	return nil
+}
@@ -0,0 +1,135 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+//
+// Copyright 2024 FeatureForm Inc.
+//
+
+package provider
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"reflect"
+	"testing"
+
+	pl "github.com/featureform/provider/location"
+	"github.com/klauspost/compress/zstd"
+	"go.uber.org/zap/zaptest"
+)
+
+func gzipBytes(t *testing.T, data []byte) []byte {
+	t.Helper()
+	buf := new(bytes.Buffer)
+	writer := gzip.NewWriter(buf)
+	if _, err := writer.Write(data); err != nil {
+		t.Fatalf("could not gzip: %v", err)
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatalf("could not close gzip writer: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func zstdBytes(t *testing.T, data []byte) []byte {
+	t.Helper()
+	encoder, err := zstd.NewWriter(nil)
+	if err != nil {
+		t.Fatalf("could not create zstd encoder: %v", err)
+	}
+	defer encoder.Close()
+	return encoder.EncodeAll(data, nil)
+}
+
+func TestCompressedPrimaryTableRoundTrip(t *testing.T) {
+	csvData := []byte("user,amount\nalice,1.5\nbob,2\n")
+	jsonData := []byte("{\"user\": \"alice\", \"amount\": 1.5}\n{\"user\": \"bob\", \"amount\": 2}\n")
+	tests := map[string]struct {
+		file string
+		data []byte
+	}{
+		"Gzip CSV":  {"transactions.csv.gz", gzipBytes(t, csvData)},
+		"Zstd CSV":  {"transactions.csv.zst", zstdBytes(t, csvData)},
+		"Gzip JSON": {"transactions.json.gz", gzipBytes(t, jsonData)},
+		"Zstd JSON": {"transactions.json.zst", zstdBytes(t, jsonData)},
+	}
+	expected := []GenericRecord{{"alice", 1.5}, {"bob", 2}}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			store, err := NewLocalFileStore([]byte(fmt.Sprintf(`{"DirPath": "file://%s/"}`, t.TempDir())))
+			if err != nil {
+				t.Fatalf("could not create local file store: %v", err)
+			}
+			path, err := store.CreateFilePath(test.file, false)
+			if err != nil {
+				t.Fatalf("could not create file path: %v", err)
+			}
+			if err := store.Write(path, test.data); err != nil {
+				t.Fatalf("could not write file: %v", err)
+			}
+			logger := zaptest.NewLogger(t).Sugar()
+			id := ResourceID{Name: "transactions", Variant: "default", Type: Primary}
+			if _, err := blobRegisterPrimary(id, *pl.NewFileLocation(path).(*pl.FileStoreLocation), logger, store); err != nil {
+				t.Fatalf("could not register primary: %v", err)
+			}
+			table, err := fileStoreGetPrimary(id, store, logger)
+			if err != nil {
+				t.Fatalf("could not get primary: %v", err)
+			}
+			iter, err := table.IterateSegment(-1)
+			if err != nil {
+				t.Fatalf("could not iterate primary: %v", err)
+			}
+			if columns := iter.Columns(); !reflect.DeepEqual(columns, []string{"user", "amount"}) {
+				t.Fatalf("expected columns [user amount], got %v", columns)
+			}
+			rows := make([]GenericRecord, 0)
+			for iter.Next() {
+				rows = append(rows, iter.Values())
+			}
+			if err := iter.Err(); err != nil {
+				t.Fatalf("could not iterate: %v", err)
+			}
+			if !reflect.DeepEqual(rows, expected) {
+				t.Fatalf("expected %v, got %v", expected, rows)
+			}
+		})
+	}
+}
+
+func TestDetectSchemaCompressed(t *testing.T) {
+	store, err := NewLocalFileStore([]byte(fmt.Sprintf(`{"DirPath": "file://%s/"}`, t.TempDir())))
+	if err != nil {
+		t.Fatalf("could not create local file store: %v", err)
+	}
+	path, err := store.CreateFilePath("transactions.csv.gz", false)
+	if err != nil {
+		t.Fatalf("could not create file path: %v", err)
+	}
+	if err := store.Write(path, gzipBytes(t, []byte("user,amount\nalice,1\n"))); err != nil {
+		t.Fatalf("could not write file: %v", err)
+	}
+	schema, err := DetectSchema(store, *pl.NewFileLocation(path).(*pl.FileStoreLocation), 0)
+	if err != nil {
+		t.Fatalf("could not detect schema: %v", err)
+	}
+	if len(schema.Columns) != 2 || schema.Columns[1].Name != "amount" {
+		t.Fatalf("unexpected columns %v", schema.Columns)
+	}
+}
+
+func TestCompressedParquetUnsupported(t *testing.T) {
+	store, err := NewLocalFileStore([]byte(fmt.Sprintf(`{"DirPath": "file://%s/"}`, t.TempDir())))
+	if err != nil {
+		t.Fatalf("could not create local file store: %v", err)
+	}
+	path, err := store.CreateFilePath("transactions.parquet.gz", false)
+	if err != nil {
+		t.Fatalf("could not create file path: %v", err)
+	}
+	table := &FileStorePrimaryTable{store, path, TableSchema{}, false, ResourceID{Name: "transactions", Type: Primary}}
+	if _, err := table.IterateSegment(-1); err == nil {
+		t.Fatalf("expected iterating a gzipped parquet file to fail")
+	}
+}
@@ -0,0 +1,171 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+//
+// Copyright 2024 FeatureForm Inc.
+//
+
+package provider
+
+import (
+	"bufio"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/featureform/filestore"
+	pl "github.com/featureform/provider/location"
+)
+
+// csvRecordReader reads the records of a delimited text file.
+type csvRecordReader interface {
+	Read() ([]string, error)
+}
+
+// newCSVRecordReader returns a reader for src that parses records using opts.
+// encoding/csv only supports double quotes escaped by doubling them, so files
+// that use another quote or escape character are parsed by delimitedReader.
+func newCSVRecordReader(src io.Reader, opts pl.CSVOptions) csvRecordReader {
+	if opts.QuoteRune() == '"' && opts.EscapeRune() == '"' {
+		reader := csv.NewReader(src)
+		reader.Comma = opts.DelimiterRune()
+		return reader
+	}
+	return &delimitedReader{
+		src:       bufio.NewReader(src),
+		delimiter: opts.DelimiterRune(),
+		quote:     opts.QuoteRune(),
+		escape:    opts.EscapeRune(),
+	}
+}
+
+// isDelimitedFile reports whether the file at path is read as delimited text.
+// Files with CSV options, like .tsv or .txt files, are delimited text unless
+// their extension is a binary format.
+func isDelimitedFile(path filestore.Filepath, opts *pl.CSVOptions) bool {
+	switch path.Ext() {
+	case filestore.CSV:
+		return true
+	case filestore.Parquet, filestore.Avro, filestore.JSON, filestore.DB:
+		return false
+	default:
+		return opts != nil
+	}
+}
+
+// csvColumnNames returns the names Spark gives to the columns of a file without
+// a header row.
+func csvColumnNames(n int) []string {
+	names := make([]string, n)
+	for i := range names {
+		names[i] = fmt.Sprintf("_c%d", i)
+	}
+	return names
+}
+
+// delimitedReader parses delimited records with a configurable quote and escape
+// character. Inside a quoted field, the escape character followed by a quote or
+// by itself is read as that character. Like encoding/csv, records end at \n or
+// \r\n outside of quotes and empty lines are skipped.
+type delimitedReader struct {
+	src       *bufio.Reader
+	delimiter rune
+	quote     rune
+	escape    rune
+	line      int
+	fields    int
+}
+
+func (r *delimitedReader) Read() ([]string, error) {
+	for {
+		start := r.line + 1
+		record, err := r.readRecord()
+		if err != nil {
+			return nil, err
+		}
+		if record == nil {
+			continue
+		}
+		// Like encoding/csv, every record must have as many fields as the first.
+		if r.fields == 0 {
+			r.fields = len(record)
+		} else if len(record) != r.fields {
+			return nil, &csv.ParseError{StartLine: start, Line: r.line, Err: csv.ErrFieldCount}
+		}
+		return record, nil
+	}
+}
+
+// readRecord returns the next record, or nil if the line was empty.
+func (r *delimitedReader) readRecord() ([]string, error) {
+	r.line++
+	var record []string
+	var field strings.Builder
+	quoted, inQuotes, empty := false, false, true
+	for {
+		c, _, err := r.src.ReadRune()
+		if err == io.EOF {
+			if inQuotes {
+				return nil, &csv.ParseError{StartLine: r.line, Line: r.line, Err: csv.ErrQuote}
+			}
+			if empty {
+				return nil, io.EOF
+			}
+			return append(record, field.String()), nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		empty = false
+		if inQuotes {
+			switch {
+			case c == r.escape && r.escapesNext():
+				next, _, _ := r.src.ReadRune()
+				field.WriteRune(next)
+			case c == r.quote:
+				inQuotes = false
+			default:
+				if c == '\n' {
+					r.line++
+				}
+				field.WriteRune(c)
+			}
+			continue
+		}
+		switch {
+		case c == r.quote && field.Len() == 0 && !quoted:
+			quoted, inQuotes = true, true
+		case c == r.delimiter:
+			record = append(record, field.String())
+			field.Reset()
+			quoted = false
+		case c == '\n':
+			if len(record) == 0 && field.Len() == 0 && !quoted {
+				return nil, nil
+			}
+			return append(record, field.String()), nil
+		case c == '\r' && r.peek() == '\n':
+			// The \n ends the record on the next pass.
+		default:
+			field.WriteRune(c)
+		}
+	}
+}
+
+// escapesNext reports whether the escape character just read applies to the
+// following character. When the escape and quote characters are the same, a
+// lone quote closes the field instead.
+func (r *delimitedReader) escapesNext() bool {
+	next := r.peek()
+	return next == r.quote || next == r.escape
+}
+
+func (r *delimitedReader) peek() rune {
+	next, _, err := r.src.ReadRune()
+	if err != nil {
+		return 0
+	}
+	r.src.UnreadRune()
+	return next
+}
@@ -0,0 +1,225 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+//
+// Copyright 2024 FeatureForm Inc.
+//
+
+package provider
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+
+	pl "github.com/featureform/provider/location"
+	"github.com/featureform/provider/types"
+	"go.uber.org/zap/zaptest"
+)
+
+func readCSVRecords(t *testing.T, src string, opts pl.CSVOptions) ([][]string, error) {
+	t.Helper()
+	reader := newCSVRecordReader(strings.NewReader(src), opts)
+	records := make([][]string, 0)
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			return records, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, record)
+	}
+}
+
+func TestCSVRecordReader(t *testing.T) {
+	tests := map[string]struct {
+		csv      string
+		opts     pl.CSVOptions
+		expected [][]string
+	}{
+		"Default": {
+			csv:      "a,b\n\"x,1\",\"say \"\"hi\"\"\"\n",
+			expected: [][]string{{"a", "b"}, {"x,1", `say "hi"`}},
+		},
+		"Tab Delimited": {
+			csv:      "a\tb\nx,1\t2\n",
+			opts:     pl.CSVOptions{Delimiter: "\t"},
+			expected: [][]string{{"a", "b"}, {"x,1", "2"}},
+		},
+		"Pipe Delimited": {
+			csv:      "a|b\n\"x|1\"|2\n",
+			opts:     pl.CSVOptions{Delimiter: "|"},
+			expected: [][]string{{"a", "b"}, {"x|1", "2"}},
+		},
+		"Single Quote With Backslash Escape": {
+			csv:      "a,b\n'it\\'s, here',\"2\"\n",
+			opts:     pl.CSVOptions{Quote: "'", Escape: "\\"},
+			expected: [][]string{{"a", "b"}, {"it's, here", `"2"`}},
+		},
+		"Backslash Escape": {
+			csv:      "a|b\n\"c:\\\\dir\"|\"\\\"q\\\"\"\n",
+			opts:     pl.CSVOptions{Delimiter: "|", Escape: "\\"},
+			expected: [][]string{{"a", "b"}, {`c:\dir`, `"q"`}},
+		},
+		"Multiline Quoted Field": {
+			csv:      "a;b\r\n'one\ntwo';3\r\n\r\n4;5",
+			opts:     pl.CSVOptions{Delimiter: ";", Quote: "'"},
+			expected: [][]string{{"a", "b"}, {"one\ntwo", "3"}, {"4", "5"}},
+		},
+		"Empty Fields": {
+			csv:      "a|b|c\n||''\n",
+			opts:     pl.CSVOptions{Delimiter: "|", Quote: "'"},
+			expected: [][]string{{"a", "b", "c"}, {"", "", ""}},
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			records, err := readCSVRecords(t, test.csv, test.opts)
+			if err != nil {
+				t.Fatalf("could not read records: %v", err)
+			}
+			if !reflect.DeepEqual(records, test.expected) {
+				t.Fatalf("expected %q, got %q", test.expected, records)
+			}
+		})
+	}
+}
+
+func TestCSVRecordReaderFail(t *testing.T) {
+	tests := map[string]struct {
+		csv  string
+		opts pl.CSVOptions
+	}{
+		"Unterminated Quote": {"a|b\n'x|y\n", pl.CSVOptions{Delimiter: "|", Quote: "'"}},
+		"Uneven Rows":        {"a|b\n1|2|3\n", pl.CSVOptions{Delimiter: "|", Quote: "'"}},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			if _, err := readCSVRecords(t, test.csv, test.opts); err == nil {
+				t.Fatalf("expected an error reading %q", test.csv)
+			}
+		})
+	}
+}
+
+func TestCSVIteratorNoHeader(t *testing.T) {
+	iter, err := newCSVIterator(io.NopCloser(strings.NewReader("alice\t1\nbob\t2\n")), -1, pl.CSVOptions{Delimiter: "\t", NoHeader: true})
+	if err != nil {
+		t.Fatalf("could not create iterator: %v", err)
+	}
+	if columns := iter.Columns(); !reflect.DeepEqual(columns, []string{"_c0", "_c1"}) {
+		t.Fatalf("expected generated column names, got %v", columns)
+	}
+	rows := make([]GenericRecord, 0)
+	for iter.Next() {
+		rows = append(rows, iter.Values())
+	}
+	if err := iter.Err(); err != nil {
+		t.Fatalf("could not iterate: %v", err)
+	}
+	expected := []GenericRecord{{"alice", 1}, {"bob", 2}}
+	if !reflect.DeepEqual(rows, expected) {
+		t.Fatalf("expected %v, got %v", expected, rows)
+	}
+}
+
+type closeTrackingReader struct {
+	io.Reader
+	closed bool
+}
+
+func (r *closeTrackingReader) Close() error {
+	r.closed = true
+	return nil
+}
+
+func TestFileIteratorsCloseSource(t *testing.T) {
+	csvSrc := &closeTrackingReader{Reader: strings.NewReader("name,value\nalice,1\n")}
+	csvIter, err := newCSVIterator(csvSrc, -1, pl.CSVOptions{})
+	if err != nil {
+		t.Fatalf("could not create CSV iterator: %v", err)
+	}
+	jsonSrc := &closeTrackingReader{Reader: strings.NewReader(`{"name": "alice", "value": 1}`)}
+	jsonIter, err := newJSONIterator(jsonSrc, -1)
+	if err != nil {
+		t.Fatalf("could not create JSON iterator: %v", err)
+	}
+	for _, iter := range []GenericTableIterator{csvIter, jsonIter} {
+		if err := iter.Close(); err != nil {
+			t.Fatalf("could not close iterator: %v", err)
+		}
+	}
+	if !csvSrc.closed || !jsonSrc.closed {
+		t.Fatalf("expected iterators to close their source")
+	}
+}
+
+func TestDetectCSVColumnsNoHeader(t *testing.T) {
+	columns, err := detectCSVColumns(strings.NewReader("alice|1.5\nbob|2\n"), 0, &pl.CSVOptions{Delimiter: "|", NoHeader: true})
+	if err != nil {
+		t.Fatalf("could not detect columns: %v", err)
+	}
+	expected := []TableColumn{
+		{Name: "_c0", ValueType: types.String},
+		{Name: "_c1", ValueType: types.Float64},
+	}
+	if !reflect.DeepEqual(columns, expected) {
+		t.Fatalf("expected %v, got %v", expected, columns)
+	}
+}
+
+func TestBlobRegisterPrimaryCSVOptions(t *testing.T) {
+	store, err := NewLocalFileStore([]byte(fmt.Sprintf(`{"DirPath": "file://%s/"}`, t.TempDir())))
+	if err != nil {
+		t.Fatalf("could not create local file store: %v", err)
+	}
+	path, err := store.CreateFilePath("transactions.tsv", false)
+	if err != nil {
+		t.Fatalf("could not create file path: %v", err)
+	}
+	if err := store.Write(path, []byte("user\tamount\nalice\t1.5\nbob\t2\n")); err != nil {
+		t.Fatalf("could not write TSV: %v", err)
+	}
+	logger := zaptest.NewLogger(t).Sugar()
+	id := ResourceID{Name: "transactions", Variant: "default", Type: Primary}
+	location := pl.NewCSVFileLocation(path, pl.CSVOptions{Delimiter: "\t"}).(*pl.FileStoreLocation)
+	if _, err := blobRegisterPrimary(id, *location, logger, store); err != nil {
+		t.Fatalf("could not register primary: %v", err)
+	}
+	table, err := fileStoreGetPrimary(id, store, logger)
+	if err != nil {
+		t.Fatalf("could not get primary: %v", err)
+	}
+	iter, err := table.IterateSegment(-1)
+	if err != nil {
+		t.Fatalf("could not iterate primary: %v", err)
+	}
+	if columns := iter.Columns(); !reflect.DeepEqual(columns, []string{"user", "amount"}) {
+		t.Fatalf("expected header columns, got %v", columns)
+	}
+	rows := make([]GenericRecord, 0)
+	for iter.Next() {
+		rows = append(rows, iter.Values())
+	}
+	expected := []GenericRecord{{"alice", 1.5}, {"bob", 2}}
+	if !reflect.DeepEqual(rows, expected) {
+		t.Fatalf("expected %v, got %v", expected, rows)
+	}
+
+	parquetPath, err := store.CreateFilePath("transactions.parquet", false)
+	if err != nil {
+		t.Fatalf("could not create file path: %v", err)
+	}
+	if err := store.Write(parquetPath, []byte("PAR1")); err != nil {
+		t.Fatalf("could not write parquet: %v", err)
+	}
+	parquetID := ResourceID{Name: "transactions_parquet", Variant: "default", Type: Primary}
+	parquetLocation := pl.NewCSVFileLocation(parquetPath, pl.CSVOptions{Delimiter: "\t"}).(*pl.FileStoreLocation)
+	if _, err := blobRegisterPrimary(parquetID, *parquetLocation, logger, store); err == nil {
+		t.Fatalf("expected csv options on a parquet file to fail")
+	}
+}
@@ -30,7 +30,6 @@ import (
 	"github.com/databricks/databricks-sdk-go/apierr"
 	dbClient "github.com/databricks/databricks-sdk-go/client"
 	dbConfig "github.com/databricks/databricks-sdk-go/config"
-	"github.com/databricks/databricks-sdk-go/retries"
 	"github.com/databricks/databricks-sdk-go/service/compute"
 	dbfs "github.com/databricks/databricks-sdk-go/service/files"
 	"github.com/databricks/databricks-sdk-go/service/jobs"
@@ -143,21 +142,37 @@ func (db *DatabricksExecutor) RunSparkJob(cmd *spark.Command, store SparkFileSto
 		return wrapped
 	}
 
-	weekTimeout := retries.Timeout[jobs.Run](opts.MaxJobDuration)
-	_, err = db.client.Jobs.RunNowAndWait(ctx, jobs.RunNow{
+	run, err := db.client.Jobs.RunNow(ctx, jobs.RunNow{
 		JobId: jobToRun.JobId,
-	}, weekTimeout)
+	})
 	if err != nil {
+		logger.Errorw("could not start job", "error", err)
+		wrapped := fferr.NewExecutionError(pt.SparkOffline.String(), err)
+		wrapped.AddDetails("job_name", fmt.Sprintf("%s-%s", opts.JobName, id), "job_id", fmt.Sprint(jobToRun.JobId), "executor_type", "Databricks", "store_type", store.Type())
+		wrapped.AddFixSuggestion("Check the cluster logs for more information")
+		return wrapped
+	}
+	logger = logger.With("run_id", run.RunId)
+	if _, err = run.GetWithTimeout(opts.MaxJobDuration); err != nil {
 		logger.Errorw("job failed", "error", err)
-		errorMessage := err
+		jobErr := err
+		failure := databricksRunFailure{}
 		if db.errorMessageClient != nil {
-			errorMessage, err = db.getErrorMessage(jobToRun.JobId)
-			if err != nil {
+			if failure, err = db.getRunFailure(run.RunId); err != nil {
 				logger.Errorf("the '%v' job failed, could not get error message: %v\n", jobToRun.JobId, err)
 			}
 		}
-		wrapped := fferr.NewExecutionError(pt.SparkOffline.String(), fmt.Errorf("job failed: %v", errorMessage))
-		wrapped.AddDetails("job_name", fmt.Sprintf("%s-%s", opts.JobName, id), "job_id", fmt.Sprint(jobToRun.JobId), "executor_type", "Databricks", "store_type", store.Type())
+		if failure.Message == "" {
+			failure.Message = jobErr.Error()
+		}
+		wrapped := fferr.NewExecutionError(pt.SparkOffline.String(), fmt.Errorf("job failed: %s", failure.Message))
+		wrapped.AddDetails("job_name", fmt.Sprintf("%s-%s", opts.JobName, id), "job_id", fmt.Sprint(jobToRun.JobId), "run_id", fmt.Sprint(run.RunId), "executor_type", "Databricks", "store_type", store.Type())
+		if failure.RunPageURL != "" {
+			wrapped.AddDetail("tracking_url", failure.RunPageURL)
+		}
+		if failure.LogTail != "" {
+			wrapped.AddDetail("driver_stderr", failure.LogTail)
+		}
 		wrapped.AddFixSuggestion("Check the cluster logs for more information")
 		return wrapped
 	}
@@ -196,54 +211,53 @@ func (db *DatabricksExecutor) InitializeExecutor(store SparkFileStoreV2) error {
 	return nil
 }
 
-func (db *DatabricksExecutor) getErrorMessage(jobId int64) (error, error) {
-	ctx := context.Background()
-
-	jobIdStr := fmt.Sprintf("%d", jobId)
-	runRequest := jobs.ListRunsRequest{
-		JobId: jobId,
-	}
+// databricksRunFailure is what we could find out about why a job run failed.
+type databricksRunFailure struct {
+	Message string
+	// RunPageURL links to the run in the Databricks workspace.
+	RunPageURL string
+	// LogTail is the end of the task's stdout and stderr, or of its error trace
+	// if no logs were captured. See sparkLogTail.
+	LogTail string
+}
 
-	runs, err := db.client.Jobs.ListRunsAll(ctx, runRequest)
-	if err != nil {
-		wrapped := fferr.NewExecutionError(pt.SparkOffline.String(), fmt.Errorf("could not get runs for job: %v", err))
-		wrapped.AddDetail("job_id", jobIdStr)
-		wrapped.AddDetail("executor_type", "Databricks")
-		return nil, wrapped
-	}
+func (db *DatabricksExecutor) getRunFailure(runID int64) (databricksRunFailure, error) {
+	ctx := context.Background()
 
-	if len(runs) == 0 {
-		wrapped := fferr.NewInternalError(fmt.Errorf("no runs found for job"))
-		wrapped.AddDetail("job_id", jobIdStr)
-		wrapped.AddDetail("executor_type", "Databricks")
-		return nil, wrapped
-	}
-	runID := runs[0].RunId
+	runIdStr := fmt.Sprintf("%d", runID)
+	failure := databricksRunFailure{}
 	run, err := db.client.Jobs.GetRun(ctx, jobs.GetRunRequest{IncludeHistory: true, RunId: runID})
 	if err != nil {
 		wrapped := fferr.NewExecutionError("Databricks", fmt.Errorf("could not get run for job: %v", err))
-		wrapped.AddDetail("job_id", jobIdStr)
+		wrapped.AddDetail("run_id", runIdStr)
 		wrapped.AddDetail("executor_type", "Databricks")
-		return nil, wrapped
+		return failure, wrapped
 	}
+	failure.RunPageURL = run.RunPageUrl
 	if len(run.Tasks) == 0 {
 		innerErr := fmt.Errorf("no tasks found for job run %d", runID)
 		noTasksErr := fferr.NewExecutionError("Databricks", innerErr)
-		noTasksErr.AddDetail("job_id", jobIdStr)
+		noTasksErr.AddDetail("run_id", runIdStr)
 		noTasksErr.AddDetail("executor_type", "Databricks")
-		return nil, noTasksErr
+		return failure, noTasksErr
 	}
 	task := run.Tasks[0]
 	// use task.RunId to get output for the task
 	output, err := db.client.Jobs.GetRunOutputByRunId(ctx, task.RunId)
 	if err != nil {
 		wrapped := fferr.NewExecutionError(pt.SparkOffline.String(), fmt.Errorf("could not get task output for job: %v", err))
-		wrapped.AddDetail("job_id", jobIdStr)
+		wrapped.AddDetail("run_id", runIdStr)
 		wrapped.AddDetail("task_id", fmt.Sprintf("%d", task.RunId))
 		wrapped.AddDetail("executor_type", "Databricks")
-		return nil, wrapped
+		return failure, wrapped
+	}
+	failure.Message = output.Error
+	if output.Logs != "" {
+		failure.LogTail = sparkLogTail(output.Logs)
+	} else {
+		failure.LogTail = sparkLogTail(output.ErrorTrace)
 	}
-	return fferr.NewExecutionError("Databricks", errors.New(output.Error)), nil
+	return failure, nil
 }
 
 func (db *DatabricksExecutor) readAndUploadFileDBFS(
@@ -0,0 +1,326 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+//
+// Copyright 2024 FeatureForm Inc.
+//
+
+package provider
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/featureform/fferr"
+	pl "github.com/featureform/provider/location"
+	pc "github.com/featureform/provider/provider_config"
+	pt "github.com/featureform/provider/provider_type"
+	"github.com/featureform/provider/types"
+)
+
+// databricksSQLOfflineStoreFactory creates an offline store that runs its
+// transformations as SQL on a Databricks SQL Warehouse, rather than submitting
+// them as Spark jobs like the Databricks executor does.
+func databricksSQLOfflineStoreFactory(config pc.SerializedConfig) (Provider, error) {
+	sc := pc.DatabricksSQLConfig{}
+	if err := sc.Deserialize(config); err != nil {
+		return nil, err
+	}
+	if _, err := sc.WarehouseID(); err != nil {
+		return nil, err
+	}
+	queries := databricksSQLQueries{}
+	queries.setVariableBinding(MySQLBindingStyle)
+	connectionBuilder := func(catalog, schema string) (string, error) {
+		conf := sc
+		if catalog != "" {
+			conf.Catalog = catalog
+		}
+		if schema != "" {
+			conf.Schema = schema
+		}
+		return databricksSQLDSN(conf), nil
+	}
+	sgConfig := SQLOfflineStoreConfig{
+		Config:                  config,
+		ConnectionURL:           databricksSQLDSN(sc),
+		Driver:                  pt.DatabricksSQLOffline.String(),
+		ProviderType:            pt.DatabricksSQLOffline,
+		QueryImpl:               &queries,
+		ConnectionStringBuilder: connectionBuilder,
+		ConnectionPool:          sc.ConnectionPool,
+	}
+	store, err := NewSQLOfflineStore(sgConfig)
+	if err != nil {
+		return nil, err
+	}
+	return store, nil
+}
+
+// databricksSQLQueries is the Databricks SQL dialect. Warehouses don't support
+// multi-statement transactions, so updates replace their tables with a single
+// CREATE OR REPLACE TABLE, which Delta Lake applies atomically.
+type databricksSQLQueries struct {
+	defaultOfflineSQLQueries
+}
+
+// Unity Catalog stores table names in lower case.
+const databricksSQLTableExists = "SELECT COUNT(*) FROM information_schema.tables WHERE table_name = lower(?) AND table_schema = current_schema()"
+const databricksSQLTableName = "SELECT DISTINCT (table_name) FROM information_schema.tables WHERE table_name = lower(?) AND table_schema = current_schema()"
+
+func (q databricksSQLQueries) tableExists() string {
+	return databricksSQLTableExists
+}
+
+func (q databricksSQLQueries) viewExists() string {
+	return databricksSQLTableExists
+}
+
+func (q databricksSQLQueries) getTable() string {
+	return databricksSQLTableName
+}
+
+func (q databricksSQLQueries) materializationExists() string {
+	return databricksSQLTableName
+}
+
+func (q databricksSQLQueries) transformationExists() string {
+	return databricksSQLTableName
+}
+
+func (q databricksSQLQueries) registerResources(db *sql.DB, tableName string, schema ResourceSchema, timestamp bool) error {
+	entity, err := schema.castColumn(schema.Entity, sanitize(schema.Entity), q.determineColumnType)
+	if err != nil {
+		return err
+	}
+	value, err := schema.castColumn(schema.Value, sanitize(schema.Value), q.determineColumnType)
+	if err != nil {
+		return err
+	}
+	ts := fmt.Sprintf("CAST('%s' AS TIMESTAMP)", time.UnixMilli(0).UTC().Format(time.RFC3339))
+	if timestamp {
+		// Databricks SQL is Spark SQL, so timestamps are parsed the same way.
+		ts = schema.parseTimestamp(sanitize(schema.TS), sparkParseTimestamp)
+	}
+	query := fmt.Sprintf("CREATE VIEW %s AS SELECT %s as entity, %s as value, %s as ts FROM %s", sanitize(tableName),
+		entity, value, ts, sanitize(schema.SourceTable.Location()))
+	if _, err := db.Exec(query); err != nil {
+		wrapped := fferr.NewExecutionError(pt.DatabricksSQLOffline.String(), err)
+		wrapped.AddDetail("table_name", tableName)
+		return wrapped
+	}
+	return nil
+}
+
+// trainingSetPersist copies the training set in tableName into persist.Table with
+// a single CREATE OR REPLACE TABLE, since warehouses don't support transactions.
+func (q databricksSQLQueries) trainingSetPersist(db *sql.DB, tableName string, persist TrainingSetPersistAs) error {
+	query := fmt.Sprintf("CREATE OR REPLACE TABLE %s AS %s", sanitize(persist.Table), persist.selectQuery(sanitize(tableName)))
+	if _, err := db.Exec(query); err != nil {
+		wrapped := fferr.NewExecutionError(pt.DatabricksSQLOffline.String(), err)
+		wrapped.AddDetail("query", query)
+		return wrapped
+	}
+	return nil
+}
+
+func (q databricksSQLQueries) primaryTableRegister(tableName string, sourceName string) string {
+	return fmt.Sprintf("CREATE VIEW %s AS SELECT * FROM %s", sanitize(tableName), sanitize(sourceName))
+}
+
+func (q databricksSQLQueries) getColumns(db *sql.DB, tableName string) ([]TableColumn, error) {
+	rows, err := db.Query("SELECT column_name FROM information_schema.columns WHERE table_name = lower(?) AND table_schema = current_schema() ORDER BY ordinal_position", tableName)
+	if err != nil {
+		wrapped := fferr.NewExecutionError(pt.DatabricksSQLOffline.String(), err)
+		wrapped.AddDetail("table_name", tableName)
+		return nil, wrapped
+	}
+	defer rows.Close()
+	columnNames := make([]TableColumn, 0)
+	for rows.Next() {
+		var column string
+		if err := rows.Scan(&column); err != nil {
+			wrapped := fferr.NewExecutionError(pt.DatabricksSQLOffline.String(), err)
+			wrapped.AddDetail("table_name", tableName)
+			return nil, wrapped
+		}
+		columnNames = append(columnNames, TableColumn{Name: column})
+	}
+	return columnNames, nil
+}
+
+func databricksSQLMaterializationSelect(sourceName string) string {
+	return fmt.Sprintf(
+		"SELECT entity, value, ts, row_number() OVER (ORDER BY entity) as row_number FROM "+
+			"(SELECT entity, ts, value, row_number() OVER (PARTITION BY entity ORDER BY ts desc) AS rn FROM %s) t WHERE rn=1",
+		sanitize(sourceName),
+	)
+}
+
+func (q databricksSQLQueries) materializationCreate(tableName string, sourceName string) []string {
+	return []string{
+		fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s AS %s", sanitize(tableName), databricksSQLMaterializationSelect(sourceName)),
+	}
+}
+
+func (q databricksSQLQueries) materializationUpdate(db *sql.DB, tableName string, sourceName string) error {
+	return q.replaceTable(db, tableName, databricksSQLMaterializationSelect(sourceName))
+}
+
+func (q databricksSQLQueries) materializationIncrementalUpdate(db *sql.DB, tableName string, sourceName string) error {
+	return q.replaceTable(db, tableName, incrementalMaterializationSelect(tableName, sourceName))
+}
+
+func (q databricksSQLQueries) materializationDrop(tableName string) string {
+	return fmt.Sprintf("DROP TABLE %s", sanitize(tableName))
+}
+
+func (q databricksSQLQueries) materializationIterateSegment(tableName string) string {
+	return fmt.Sprintf("SELECT entity, value, ts FROM %s WHERE row_number > ? AND row_number <= ?", sanitize(tableName))
+}
+
+// replaceTable atomically replaces the contents of tableName with the results
+// of query. The query can read from the table it's replacing.
+func (q databricksSQLQueries) replaceTable(db *sql.DB, tableName string, query string) error {
+	if _, err := db.Exec(fmt.Sprintf("CREATE OR REPLACE TABLE %s AS %s", sanitize(tableName), query)); err != nil {
+		wrapped := fferr.NewExecutionError(pt.DatabricksSQLOffline.String(), err)
+		wrapped.AddDetail("table_name", tableName)
+		return wrapped
+	}
+	return nil
+}
+
+func (q databricksSQLQueries) maxFeatureAgeFilter(featureTS, labelTS string, maxAge time.Duration) string {
+	if maxAge <= 0 {
+		return ""
+	}
+	return fmt.Sprintf(" AND %s >= %s - INTERVAL %d SECONDS", featureTS, labelTS, int64(maxAge.Seconds()))
+}
+
+func (q databricksSQLQueries) shiftTimestamp(ts string, delta time.Duration) string {
+	return fmt.Sprintf("(%s + INTERVAL %d SECONDS)", ts, int64(delta.Seconds()))
+}
+
+func (q databricksSQLQueries) determineColumnType(valueType types.ValueType) (string, error) {
+	switch valueType {
+	case types.Timestamp:
+		return "TIMESTAMP", nil
+	case types.JSON, types.NilType:
+		return "STRING", nil
+	default:
+		return sparkColumnType(valueType)
+	}
+}
+
+func (q databricksSQLQueries) newSQLOfflineTable(name string, columnType string) string {
+	return fmt.Sprintf("CREATE TABLE %s (entity STRING, value %s, ts TIMESTAMP)", sanitize(name), columnType)
+}
+
+func (q databricksSQLQueries) trainingSetCreate(store *sqlOfflineStore, def TrainingSetDef, tableName string, labelName string) error {
+	return q.trainingSetQuery(store, def, tableName, labelName, false)
+}
+
+// trainingSetUpdate builds the training set into a temporary table, then swaps
+// it in.
+func (q databricksSQLQueries) trainingSetUpdate(store *sqlOfflineStore, def TrainingSetDef, tableName string, labelName string) error {
+	tempName := fmt.Sprintf("tmp_%s", tableName)
+	if _, err := store.db.Exec(fmt.Sprintf("DROP TABLE IF EXISTS %s", sanitize(tempName))); err != nil {
+		wrapped := fferr.NewExecutionError(pt.DatabricksSQLOffline.String(), err)
+		wrapped.AddDetail("table_name", tempName)
+		return wrapped
+	}
+	if err := q.trainingSetQuery(store, def, tempName, labelName, false); err != nil {
+		return err
+	}
+	if err := q.replaceTable(store.db, tableName, fmt.Sprintf("SELECT * FROM %s", sanitize(tempName))); err != nil {
+		return err
+	}
+	if _, err := store.db.Exec(q.dropTable(tempName)); err != nil {
+		wrapped := fferr.NewExecutionError(pt.DatabricksSQLOffline.String(), err)
+		wrapped.AddDetail("table_name", tempName)
+		return wrapped
+	}
+	return nil
+}
+
+// castTableItemType passes values through, the driver already returns them as
+// their Go types.
+func (q databricksSQLQueries) castTableItemType(v interface{}, t interface{}) interface{} {
+	if ts, ok := v.(time.Time); ok {
+		return ts.UTC()
+	}
+	return v
+}
+
+func (q databricksSQLQueries) getValueColumnType(t *sql.ColumnType) interface{} {
+	return t.DatabaseTypeName()
+}
+
+func (q databricksSQLQueries) numRows(n interface{}) (int64, error) {
+	num, ok := n.(int64)
+	if !ok {
+		return 0, fferr.NewInternalError(fmt.Errorf("could not convert %T to int64", n))
+	}
+	return num, nil
+}
+
+func (q databricksSQLQueries) transformationUpdate(db *sql.DB, tableName string, query string) error {
+	return q.replaceTable(db, tableName, fmt.Sprintf("SELECT * FROM ( %s )", query))
+}
+
+// transformationIncrementalUpdate appends the rows of query whose column is past
+// watermark, then advances the watermark. Without transactions the two can't be
+// applied together; if the watermark fails to update, the next run appends the
+// same rows again.
+func (q databricksSQLQueries) transformationIncrementalUpdate(db *sql.DB, tableName string, query string, column string, watermark string) error {
+	insert := fmt.Sprintf("INSERT INTO %s SELECT * FROM ( %s ) AS incremental WHERE incremental.%s > ?", sanitize(tableName), query, sanitize(column))
+	if _, err := db.Exec(insert, watermark); err != nil {
+		wrapped := fferr.NewExecutionError(pt.DatabricksSQLOffline.String(), err)
+		wrapped.AddDetail("table_name", tableName)
+		return wrapped
+	}
+	return q.setTransformationWatermark(db, tableName, column)
+}
+
+// setTransformationWatermark replaces the transformation's watermark with the
+// greatest value of column in it, in a single MERGE. An empty transformation has
+// no watermark, so its next update is a full build.
+func (q databricksSQLQueries) setTransformationWatermark(db *sql.DB, tableName string, column string) error {
+	if err := q.createTransformationWatermarkTable(db); err != nil {
+		return err
+	}
+	merge := fmt.Sprintf(
+		"MERGE INTO %s w USING (SELECT ? AS table_name, CAST(MAX(%s) AS STRING) AS watermark FROM %s) s "+
+			"ON w.table_name = s.table_name "+
+			"WHEN MATCHED AND s.watermark IS NULL THEN DELETE "+
+			"WHEN MATCHED THEN UPDATE SET w.watermark = s.watermark "+
+			"WHEN NOT MATCHED AND s.watermark IS NOT NULL THEN INSERT (table_name, watermark) VALUES (s.table_name, s.watermark)",
+		sanitize(transformationWatermarkTable), sanitize(column), sanitize(tableName),
+	)
+	if _, err := db.Exec(merge, tableName); err != nil {
+		wrapped := fferr.NewExecutionError(pt.DatabricksSQLOffline.String(), err)
+		wrapped.AddDetail("table_name", tableName)
+		return wrapped
+	}
+	return nil
+}
+
+func (q databricksSQLQueries) resourceTableColumns(obj pl.FullyQualifiedObject) (string, error) {
+	if obj.Database == "" {
+		return "", fferr.NewInternalErrorf("catalog required for resource table columns query")
+	}
+	if obj.Table == "" {
+		return "", fferr.NewInternalErrorf("table required for resource table columns query")
+	}
+	if obj.Schema == "" {
+		obj.Schema = "default"
+	}
+	var sb strings.Builder
+	sb.WriteString("SELECT column_name, data_type FROM ")
+	sb.WriteString(fmt.Sprintf("%s.information_schema.columns ", sanitize(obj.Database)))
+	sb.WriteString(fmt.Sprintf("WHERE table_schema = '%s' ", strings.ToLower(obj.Schema)))
+	sb.WriteString(fmt.Sprintf("AND table_name = '%s'", strings.ToLower(obj.Table)))
+	return sb.String(), nil
+}
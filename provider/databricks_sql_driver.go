@@ -0,0 +1,216 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+//
+// Copyright 2024 FeatureForm Inc.
+//
+
+package provider
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"net/url"
+	"strings"
+
+	dbsql "github.com/databricks/databricks-sql-go"
+	"github.com/featureform/fferr"
+	pc "github.com/featureform/provider/provider_config"
+	pt "github.com/featureform/provider/provider_type"
+)
+
+// The Databricks SQL driver connects to a SQL Warehouse with databricks-sql-go.
+// It's registered with database/sql so that a warehouse can back a
+// sqlOfflineStore like any other database.
+//
+// Queries are written with ANSI quoting, like the rest of sqlOfflineStore, so the
+// driver rewrites double quoted identifiers to Spark's backticks before handing
+// them to databricks-sql-go.
+func init() {
+	sql.Register(pt.DatabricksSQLOffline.String(), databricksSQLDriver{})
+}
+
+const databricksSQLPort = 443
+
+// databricksSQLDSN returns the data source name of a warehouse, in the same
+// token:<token>@<host><http path> form the Databricks SQL connectors use.
+func databricksSQLDSN(config pc.DatabricksSQLConfig) string {
+	host := strings.TrimPrefix(strings.TrimPrefix(config.Host, "https://"), "http://")
+	query := url.Values{}
+	if config.Catalog != "" {
+		query.Set("catalog", config.Catalog)
+	}
+	if config.Schema != "" {
+		query.Set("schema", config.Schema)
+	}
+	dsn := url.URL{
+		User:     url.UserPassword("token", config.Token),
+		Host:     strings.TrimRight(host, "/"),
+		Path:     config.HTTPPath,
+		RawQuery: query.Encode(),
+	}
+	return strings.TrimPrefix(dsn.String(), "//")
+}
+
+func parseDatabricksSQLDSN(dsn string) (pc.DatabricksSQLConfig, error) {
+	parsed, err := url.Parse("//" + dsn)
+	if err != nil {
+		return pc.DatabricksSQLConfig{}, fferr.NewInvalidArgumentErrorf("invalid Databricks SQL data source name: %v", err)
+	}
+	token, _ := parsed.User.Password()
+	return pc.DatabricksSQLConfig{
+		Host:     parsed.Host,
+		HTTPPath: parsed.Path,
+		Token:    token,
+		Catalog:  parsed.Query().Get("catalog"),
+		Schema:   parsed.Query().Get("schema"),
+	}, nil
+}
+
+type databricksSQLDriver struct{}
+
+func (d databricksSQLDriver) Open(dsn string) (driver.Conn, error) {
+	connector, err := d.OpenConnector(dsn)
+	if err != nil {
+		return nil, err
+	}
+	return connector.Connect(context.Background())
+}
+
+func (d databricksSQLDriver) OpenConnector(dsn string) (driver.Connector, error) {
+	config, err := parseDatabricksSQLDSN(dsn)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := config.WarehouseID(); err != nil {
+		return nil, err
+	}
+	connector, err := dbsql.NewConnector(
+		dbsql.WithServerHostname(config.Host),
+		dbsql.WithPort(databricksSQLPort),
+		dbsql.WithHTTPPath(config.HTTPPath),
+		dbsql.WithAccessToken(config.Token),
+		dbsql.WithInitialNamespace(config.Catalog, config.Schema),
+	)
+	if err != nil {
+		return nil, fferr.NewConnectionError(pt.DatabricksSQLOffline.String(), err)
+	}
+	return &databricksSQLConnector{connector}, nil
+}
+
+// databricksSQLConnector wraps a databricks-sql-go connector so its connections
+// rewrite their queries.
+type databricksSQLConnector struct {
+	driver.Connector
+}
+
+func (c *databricksSQLConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	conn, err := c.Connector.Connect(ctx)
+	if err != nil {
+		return nil, fferr.NewConnectionError(pt.DatabricksSQLOffline.String(), err)
+	}
+	libConn, ok := conn.(databricksSQLLibraryConn)
+	if !ok {
+		conn.Close()
+		return nil, fferr.NewInternalErrorf("unexpected Databricks SQL connection type %T", conn)
+	}
+	return &databricksSQLConn{libConn}, nil
+}
+
+func (c *databricksSQLConnector) Driver() driver.Driver {
+	return databricksSQLDriver{}
+}
+
+// databricksSQLLibraryConn is the databricks-sql-go connection's interfaces that
+// databricksSQLConn passes on to.
+type databricksSQLLibraryConn interface {
+	driver.Conn
+	driver.ConnPrepareContext
+	driver.ExecerContext
+	driver.QueryerContext
+	driver.Pinger
+	driver.NamedValueChecker
+	driver.SessionResetter
+	driver.Validator
+}
+
+// databricksSQLConn rewrites each query and passes it on to the databricks-sql-go
+// connection.
+type databricksSQLConn struct {
+	databricksSQLLibraryConn
+}
+
+func (c *databricksSQLConn) Prepare(query string) (driver.Stmt, error) {
+	return c.PrepareContext(context.Background(), query)
+}
+
+func (c *databricksSQLConn) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+	statement, err := databricksSQLStatement(query)
+	if err != nil {
+		return nil, err
+	}
+	return c.databricksSQLLibraryConn.PrepareContext(ctx, statement)
+}
+
+func (c *databricksSQLConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	statement, err := databricksSQLStatement(query)
+	if err != nil {
+		return nil, err
+	}
+	return c.databricksSQLLibraryConn.ExecContext(ctx, statement, args)
+}
+
+func (c *databricksSQLConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	statement, err := databricksSQLStatement(query)
+	if err != nil {
+		return nil, err
+	}
+	return c.databricksSQLLibraryConn.QueryContext(ctx, statement, args)
+}
+
+// databricksSQLStatement rewrites a query's double quoted identifiers to
+// backticks. Quoted strings and identifiers are left alone.
+func databricksSQLStatement(query string) (string, error) {
+	var sb strings.Builder
+	runes := []rune(query)
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		switch r {
+		case '\'', '`':
+			end := i + 1
+			for ; end < len(runes) && runes[end] != r; end++ {
+				if r == '\'' && runes[end] == '\\' {
+					end++
+				}
+			}
+			if end >= len(runes) {
+				return "", fferr.NewInvalidArgumentErrorf("unterminated %c in query: %s", r, query)
+			}
+			sb.WriteString(string(runes[i : end+1]))
+			i = end
+		case '"':
+			var ident strings.Builder
+			end := i + 1
+			for ; end < len(runes); end++ {
+				if runes[end] == '"' {
+					if end+1 < len(runes) && runes[end+1] == '"' {
+						ident.WriteRune('"')
+						end++
+						continue
+					}
+					break
+				}
+				ident.WriteRune(runes[end])
+			}
+			if end >= len(runes) {
+				return "", fferr.NewInvalidArgumentErrorf("unterminated \" in query: %s", query)
+			}
+			sb.WriteString("`" + strings.ReplaceAll(ident.String(), "`", "``") + "`")
+			i = end
+		default:
+			sb.WriteRune(r)
+		}
+	}
+	return sb.String(), nil
+}
@@ -0,0 +1,95 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+//
+// Copyright 2024 FeatureForm Inc.
+//
+
+package provider
+
+import (
+	"context"
+	"database/sql/driver"
+	"reflect"
+	"testing"
+
+	pc "github.com/featureform/provider/provider_config"
+)
+
+// fakeDatabricksSQLConn records the statements the databricks-sql-go connection
+// would have run.
+type fakeDatabricksSQLConn struct {
+	databricksSQLLibraryConn
+	statements []string
+	args       [][]driver.NamedValue
+}
+
+func (f *fakeDatabricksSQLConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	f.statements = append(f.statements, query)
+	f.args = append(f.args, args)
+	return driver.RowsAffected(1), nil
+}
+
+func (f *fakeDatabricksSQLConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	f.statements = append(f.statements, query)
+	f.args = append(f.args, args)
+	return nil, nil
+}
+
+func TestDatabricksSQLStatement(t *testing.T) {
+	query := `SELECT "entity", 'it''s "quoted" ?' AS "we""ird", ` + "`ts`" + ` FROM "table" WHERE entity = ? AND ts > ?`
+	statement, err := databricksSQLStatement(query)
+	if err != nil {
+		t.Fatalf("Failed to rewrite statement: %s", err)
+	}
+	expected := "SELECT `entity`, 'it''s \"quoted\" ?' AS `we\"ird`, `ts` FROM `table` WHERE entity = ? AND ts > ?"
+	if statement != expected {
+		t.Fatalf("Expected statement:\n%s\ngot:\n%s", expected, statement)
+	}
+	if _, err := databricksSQLStatement(`SELECT "entity`); err == nil {
+		t.Fatalf("Expected an unterminated identifier to fail")
+	}
+}
+
+func TestDatabricksSQLDSN(t *testing.T) {
+	config := pc.DatabricksSQLConfig{
+		Host:     "https://dbc-1234.cloud.databricks.com/",
+		HTTPPath: "/sql/1.0/warehouses/abc123",
+		Token:    "dapi/secret",
+		Catalog:  "main",
+		Schema:   "features",
+	}
+	parsed, err := parseDatabricksSQLDSN(databricksSQLDSN(config))
+	if err != nil {
+		t.Fatalf("Failed to parse DSN: %s", err)
+	}
+	config.Host = "dbc-1234.cloud.databricks.com"
+	if !reflect.DeepEqual(config, parsed) {
+		t.Fatalf("Expected %v, got %v", config, parsed)
+	}
+}
+
+func TestDatabricksSQLConn(t *testing.T) {
+	fake := &fakeDatabricksSQLConn{}
+	conn := &databricksSQLConn{fake}
+	args := []driver.NamedValue{{Ordinal: 1, Value: "a"}}
+	if _, err := conn.ExecContext(context.Background(), `DROP TABLE "table"`, nil); err != nil {
+		t.Fatalf("Failed to exec: %s", err)
+	}
+	if _, err := conn.QueryContext(context.Background(), `SELECT * FROM "table" WHERE entity = ?`, args); err != nil {
+		t.Fatalf("Failed to query: %s", err)
+	}
+	expected := []string{"DROP TABLE `table`", "SELECT * FROM `table` WHERE entity = ?"}
+	if !reflect.DeepEqual(expected, fake.statements) {
+		t.Fatalf("Expected statements %v, got %v", expected, fake.statements)
+	}
+	if !reflect.DeepEqual(args, fake.args[1]) {
+		t.Fatalf("Expected args %v, got %v", args, fake.args[1])
+	}
+	if _, err := conn.ExecContext(context.Background(), `SELECT "entity`, nil); err == nil {
+		t.Fatalf("Expected an unterminated identifier to fail")
+	}
+	if len(fake.statements) != 2 {
+		t.Fatalf("Expected an invalid statement not to be run")
+	}
+}
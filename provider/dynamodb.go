@@ -57,6 +57,14 @@ const (
 	maxRetries                = 5
 )
 
+// dynamoExpiresAtAttribute is the TTL attribute of tables whose feature has a
+// TTL. It holds the epoch second at which the item expires.
+const dynamoExpiresAtAttribute = "ExpiresAt"
+
+// dynamoTimestampAttribute holds the timestamp of an item's value, in
+// microseconds since the epoch, for items written with SetIfNewer.
+const dynamoTimestampAttribute = "FeatureTS"
+
 type dynamodbTableKey struct {
 	Prefix, Feature, Variant string
 }
@@ -92,6 +100,7 @@ type dynamodbOnlineTable struct {
 	valueType          vt.ValueType
 	version            se.SerializeVersion
 	stronglyConsistent bool
+	ttl                time.Duration
 }
 
 // dynamodbMetadataEntry is the format of each row in the Metadata table.
@@ -347,7 +356,7 @@ func (store *dynamodbOnlineStore) CreateTable(feature, variant string, valueType
 	if err := store.updateMetadataTable(tableName, valueType, dynamoSerializationVersion); err != nil {
 		return nil, err
 	}
-	return &dynamodbOnlineTable{store.client, key, valueType, dynamoSerializationVersion, store.stronglyConsistent}, nil
+	return &dynamodbOnlineTable{client: store.client, key: key, valueType: valueType, version: dynamoSerializationVersion, stronglyConsistent: store.stronglyConsistent}, nil
 }
 
 func (store *dynamodbOnlineStore) DeleteTable(feature, variant string) error {
@@ -400,6 +409,9 @@ func (table dynamodbOnlineTable) BatchSet(items []SetItem) error {
 			table.key.Feature: &types.AttributeValueMemberS{Value: item.Entity},
 			"FeatureValue":    dynamoValue,
 		}
+		if table.ttl > 0 {
+			serialized[i][dynamoExpiresAtAttribute] = table.expiresAt()
+		}
 	}
 	reqs := make([]types.WriteRequest, len(serialized))
 	for i, serItem := range serialized {
@@ -461,6 +473,10 @@ func (table dynamodbOnlineTable) Set(entity string, value interface{}) error {
 		},
 		UpdateExpression: aws.String("set FeatureValue = :val"),
 	}
+	if table.ttl > 0 {
+		input.ExpressionAttributeValues[":exp"] = table.expiresAt()
+		input.UpdateExpression = aws.String(fmt.Sprintf("set FeatureValue = :val, %s = :exp", dynamoExpiresAtAttribute))
+	}
 	if _, err := table.client.UpdateItem(context.TODO(), input); err != nil {
 		wrapped := fferr.NewResourceExecutionError(pt.DynamoDBOnline.String(), table.key.Feature, table.key.Variant, "FEATURE_VARIANT", fmt.Errorf("error setting entity: %w", err))
 		wrapped.AddDetail("entity", entity)
@@ -470,6 +486,97 @@ func (table dynamodbOnlineTable) Set(entity string, value interface{}) error {
 	return nil
 }
 
+// SetIfNewer writes the value with a condition on its stored timestamp, so
+// DynamoDB compares and writes it atomically.
+func (table dynamodbOnlineTable) SetIfNewer(entity string, value interface{}, ts time.Time) (bool, error) {
+	dynamoValue, err := serializers[table.version].Serialize(table.valueType, value)
+	if err != nil {
+		wrap := fferr.NewInternalError(err)
+		wrap.AddDetail("entity", entity)
+		wrap.AddDetail("value", fmt.Sprintf("%v", value))
+		return false, wrap
+	}
+	update := fmt.Sprintf("set FeatureValue = :val, %s = :ts", dynamoTimestampAttribute)
+	values := map[string]types.AttributeValue{
+		":val": dynamoValue,
+		":ts":  &types.AttributeValueMemberN{Value: strconv.FormatInt(ts.UnixMicro(), 10)},
+	}
+	if table.ttl > 0 {
+		values[":exp"] = table.expiresAt()
+		update = fmt.Sprintf("%s, %s = :exp", update, dynamoExpiresAtAttribute)
+	}
+	input := &dynamodb.UpdateItemInput{
+		ExpressionAttributeValues: values,
+		TableName:                 aws.String(formatDynamoTableName(table.key.Prefix, table.key.Feature, table.key.Variant)),
+		Key: map[string]types.AttributeValue{
+			table.key.Feature: &types.AttributeValueMemberS{
+				Value: entity,
+			},
+		},
+		UpdateExpression:    aws.String(update),
+		ConditionExpression: aws.String(fmt.Sprintf("attribute_not_exists(%[1]s) OR %[1]s <= :ts", dynamoTimestampAttribute)),
+	}
+	if _, err := table.client.UpdateItem(context.TODO(), input); err != nil {
+		var conditionErr *types.ConditionalCheckFailedException
+		if errors.As(err, &conditionErr) {
+			return false, nil
+		}
+		wrapped := fferr.NewResourceExecutionError(pt.DynamoDBOnline.String(), table.key.Feature, table.key.Variant, "FEATURE_VARIANT", fmt.Errorf("error setting entity: %w", err))
+		wrapped.AddDetail("entity", entity)
+		return false, wrapped
+	}
+	return true, nil
+}
+
+// WithTTL enables TTL on the table, if it isn't already, and sets an expiry
+// on each item written through the returned table.
+func (table dynamodbOnlineTable) WithTTL(ttl time.Duration) (OnlineStoreTable, error) {
+	if ttl < time.Second {
+		return nil, fferr.NewInvalidArgumentErrorf("TTL must be at least one second, got %s", ttl)
+	}
+	tableName := aws.String(table.key.ToTableName())
+	desc, err := table.client.DescribeTimeToLive(context.TODO(), &dynamodb.DescribeTimeToLiveInput{TableName: tableName})
+	if err != nil {
+		return nil, fferr.NewResourceExecutionError(pt.DynamoDBOnline.String(), table.key.Feature, table.key.Variant, fferr.FEATURE_VARIANT, err)
+	}
+	status := types.TimeToLiveStatusDisabled
+	if desc.TimeToLiveDescription != nil {
+		status = desc.TimeToLiveDescription.TimeToLiveStatus
+	}
+	if status == types.TimeToLiveStatusDisabled || status == types.TimeToLiveStatusDisabling {
+		input := &dynamodb.UpdateTimeToLiveInput{
+			TableName: tableName,
+			TimeToLiveSpecification: &types.TimeToLiveSpecification{
+				AttributeName: aws.String(dynamoExpiresAtAttribute),
+				Enabled:       aws.Bool(true),
+			},
+		}
+		if _, err := table.client.UpdateTimeToLive(context.TODO(), input); err != nil {
+			return nil, fferr.NewResourceExecutionError(pt.DynamoDBOnline.String(), table.key.Feature, table.key.Variant, fferr.FEATURE_VARIANT, err)
+		}
+	}
+	table.ttl = ttl
+	return &table, nil
+}
+
+func (table dynamodbOnlineTable) expiresAt() types.AttributeValue {
+	return &types.AttributeValueMemberN{Value: strconv.FormatInt(time.Now().Add(table.ttl).Unix(), 10)}
+}
+
+// isExpiredDynamoItem checks an item's TTL attribute. DynamoDB deletes expired
+// items in the background, which can take days, so reads have to filter them.
+func isExpiredDynamoItem(item map[string]types.AttributeValue) bool {
+	expiresAt, ok := item[dynamoExpiresAtAttribute].(*types.AttributeValueMemberN)
+	if !ok {
+		return false
+	}
+	epoch, err := strconv.ParseInt(expiresAt.Value, 10, 64)
+	if err != nil {
+		return false
+	}
+	return epoch <= time.Now().Unix()
+}
+
 func (table dynamodbOnlineTable) Get(entity string) (interface{}, error) {
 	input := &dynamodb.GetItemInput{
 		TableName: aws.String(formatDynamoTableName(table.key.Prefix, table.key.Feature, table.key.Variant)),
@@ -490,6 +597,11 @@ func (table dynamodbOnlineTable) Get(entity string) (interface{}, error) {
 		return nil, err
 	}
 	item := output_val.Item
+	if isExpiredDynamoItem(item) {
+		wrapped := fferr.NewEntityNotFoundError(table.key.Feature, table.key.Variant, entity, nil)
+		wrapped.AddDetail("entity", entity)
+		return nil, wrapped
+	}
 	value, ok := item["FeatureValue"]
 	if !ok {
 		wrapped := fferr.NewInternalErrorf("dynamoDB item does not have FeatureValue column")
@@ -499,6 +611,100 @@ func (table dynamodbOnlineTable) Get(entity string) (interface{}, error) {
 	return serializers[table.version].Deserialize(table.valueType, value)
 }
 
+// maxDynamoBatchGetSize is the max amount of keys that can be read from Dynamo at once. It's a dynamo get limitation.
+const maxDynamoBatchGetSize = 100
+
+// BatchGet reads entities with BatchGetItem, splitting the request into groups of
+// maxDynamoBatchGetSize keys and retrying any unprocessed keys with backoff.
+func (table dynamodbOnlineTable) BatchGet(entities []string) ([]interface{}, error) {
+	tableName := formatDynamoTableName(table.key.Prefix, table.key.Feature, table.key.Variant)
+	// BatchGetItem rejects requests containing duplicate keys
+	unique := make([]string, 0, len(entities))
+	seen := make(map[string]struct{}, len(entities))
+	for _, entity := range entities {
+		if _, has := seen[entity]; has {
+			continue
+		}
+		seen[entity] = struct{}{}
+		unique = append(unique, entity)
+	}
+	items := make(map[string]types.AttributeValue, len(unique))
+	for start := 0; start < len(unique); start += maxDynamoBatchGetSize {
+		end := start + maxDynamoBatchGetSize
+		if end > len(unique) {
+			end = len(unique)
+		}
+		keys := make([]map[string]types.AttributeValue, 0, end-start)
+		for _, entity := range unique[start:end] {
+			keys = append(keys, map[string]types.AttributeValue{
+				table.key.Feature: &types.AttributeValueMemberS{Value: entity},
+			})
+		}
+		input := &dynamodb.BatchGetItemInput{
+			RequestItems: map[string]types.KeysAndAttributes{
+				tableName: {
+					Keys:           keys,
+					ConsistentRead: aws.Bool(table.stronglyConsistent),
+				},
+			},
+		}
+		if err := table.batchGetWithRetry(context.TODO(), tableName, input, items); err != nil {
+			return nil, err
+		}
+	}
+	results := make([]interface{}, len(entities))
+	for i, entity := range entities {
+		value, has := items[entity]
+		if !has {
+			wrapped := fferr.NewEntityNotFoundError(table.key.Feature, table.key.Variant, entity, nil)
+			wrapped.AddDetail("entity", entity)
+			return nil, wrapped
+		}
+		deserialized, err := serializers[table.version].Deserialize(table.valueType, value)
+		if err != nil {
+			return nil, err
+		}
+		results[i] = deserialized
+	}
+	return results, nil
+}
+
+func (table dynamodbOnlineTable) batchGetWithRetry(ctx context.Context, tableName string, input *dynamodb.BatchGetItemInput, items map[string]types.AttributeValue) error {
+	totalWaitedTime := time.Duration(0)
+	for attempts := 0; attempts < maxRetries; attempts++ {
+		output, err := table.client.BatchGetItem(ctx, input)
+		if err != nil {
+			return fferr.NewExecutionError(pt.DynamoDBOnline.String(), err)
+		}
+		for _, item := range output.Responses[tableName] {
+			entity, ok := item[table.key.Feature].(*types.AttributeValueMemberS)
+			if !ok {
+				return fferr.NewInternalErrorf("dynamoDB item does not have a string %s column", table.key.Feature)
+			}
+			if isExpiredDynamoItem(item) {
+				continue
+			}
+			value, ok := item["FeatureValue"]
+			if !ok {
+				wrapped := fferr.NewInternalErrorf("dynamoDB item does not have FeatureValue column")
+				wrapped.AddDetail("entity", entity.Value)
+				return wrapped
+			}
+			items[entity.Value] = value
+		}
+		if len(output.UnprocessedKeys) == 0 {
+			return nil
+		}
+
+		input.RequestItems = output.UnprocessedKeys
+
+		waitTime, newTotalWait := exponentialBackoff(attempts, totalWaitedTime)
+		time.Sleep(waitTime)
+		totalWaitedTime = newTotalWait
+	}
+	return fferr.NewExecutionError(pt.DynamoDBOnline.String(), fmt.Errorf("failed to read all items after %d retries, unprocessed keys: %d", maxRetries, len(input.RequestItems[tableName].Keys)))
+}
+
 // waitForDynamoDB waits for DynamoDB to return a valid response with exponential backoff.
 // We can't use waitForDynamoTable since we need to ignore most tcp and network errors and
 // continue to retry.
@@ -753,6 +959,14 @@ func (ser serializerV1) serializeScalar(t vt.ValueType, value any) (types.Attrib
 			return nil, wrapped
 		}
 		return &types.AttributeValueMemberS{Value: casted}, nil
+	case vt.JSON:
+		casted, err := serializeJSON(value)
+		if err != nil {
+			wrapped := fferr.NewTypeError(t.String(), value, err)
+			wrapped.AddDetail("version", ser.Version().String())
+			return nil, wrapped
+		}
+		return &types.AttributeValueMemberS{Value: casted}, nil
 	case vt.Timestamp, vt.Datetime:
 		ts, isTs := value.(time.Time)
 		if isTs {
@@ -914,7 +1128,7 @@ func deserializeScalar(t vt.ScalarType, value types.AttributeValue, version stri
 			return nil, wrapped
 		}
 		return castedValue.Value, nil
-	case vt.String:
+	case vt.String, vt.JSON:
 		castedValue, ok := value.(*types.AttributeValueMemberS)
 		if !ok {
 			wrapped := fferr.NewInternalErrorf("unable to deserialize dynamodb value into string, is %T", value)
@@ -28,11 +28,13 @@ func TestOnlineStoreDynamoDB(t *testing.T) {
 	}
 	store := GetTestingDynamoDB(t, map[string]string{})
 	test := OnlineStoreTest{
-		t:            t,
-		store:        store,
-		testNil:      true,
-		testFloatVec: true,
-		testBatch:    true,
+		t:              t,
+		store:          store,
+		testNil:        true,
+		testFloatVec:   true,
+		testBatch:      true,
+		testTTL:        true,
+		testSetIfNewer: true,
 	}
 	test.Run()
 }
@@ -121,6 +123,10 @@ func TestDynamoSerializers(t *testing.T) {
 		vt.Datetime:  date,
 	}
 	timeSerializers := []se.SerializeVersion{serializeV1}
+	jsonTests := testCases{
+		vt.JSON: `{"name": "apple", "tags": ["red", "fruit"]}`,
+	}
+	jsonSerializers := []se.SerializeVersion{serializeV1}
 	uintTests := testCases{
 		vt.UInt8:  uint8(1),
 		vt.UInt16: uint16(1),
@@ -174,6 +180,7 @@ func TestDynamoSerializers(t *testing.T) {
 	}
 	runTestCases(t, allSerializers, simpleTests)
 	runTestCases(t, timeSerializers, timeTests)
+	runTestCases(t, jsonSerializers, jsonTests)
 	runTestCases(t, uintSerializers, uintTests)
 	runTestCases(t, smallBitSerializers, smallBitTests)
 	runTestCases(t, nilSerializers, nilTests)
@@ -292,6 +299,8 @@ func TestFailSerializeV1(t *testing.T) {
 		{vt.Int64, []int64{1}},
 		{vt.Bool, "not"},
 		{vt.String, true},
+		{vt.JSON, `{"name": "apple"`},
+		{vt.JSON, 123},
 		{vt.Timestamp, true},
 		{vt.Timestamp, "123/23/2033"},
 		{vt.VectorType{vt.Float32, 1, false}, []string{"abc"}},
@@ -37,6 +37,10 @@ import (
 // of the AWS SDK this message could change, so it's important to keep an eye on this.
 const EMR_MAX_WAIT_DURATION_ERROR = "exceeded max wait time for StepComplete waiter"
 
+// emrStderrMaxWait bounds how long we wait for a failed step's stderr to be
+// shipped to the log bucket, since it's only used to enrich the error.
+const emrStderrMaxWait = 5 * time.Minute
+
 func NewEMRExecutor(emrConfig pc.EMRConfig, logger logging.Logger) (SparkExecutor, error) {
 	var useServiceAccount bool
 	var awsAccessKeyId, awsSecretKey string
@@ -87,6 +91,7 @@ func NewEMRExecutor(emrConfig pc.EMRConfig, logger logging.Logger) (SparkExecuto
 		client:       client,
 		logger:       logger,
 		clusterName:  emrConfig.ClusterName,
+		region:       emrConfig.ClusterRegion,
 		logFileStore: logFileStore,
 		baseExecutor: base,
 	}
@@ -96,6 +101,7 @@ func NewEMRExecutor(emrConfig pc.EMRConfig, logger logging.Logger) (SparkExecuto
 type EMRExecutor struct {
 	client       *emr.Client
 	clusterName  string
+	region       string
 	logger       logging.Logger
 	logFileStore *FileStore
 	baseExecutor
@@ -229,20 +235,25 @@ func (e *EMRExecutor) waitForStep(ctx context.Context, clusterId, stepId string,
 		if err.Error() == EMR_MAX_WAIT_DURATION_ERROR {
 			return e.cancelStep(stepId, maxWait)
 		}
-		errorMessage, getErr := e.getStepErrorMessage(e.clusterName, stepId, maxWait)
+		failure, getErr := e.getStepFailure(e.clusterName, stepId, maxWait)
 		if getErr != nil {
 			e.logger.Infof("could not get error message for EMR step '%s': %s", stepId, getErr)
 		}
-		if errorMessage != "" {
-			wrapped := fferr.NewExecutionError(pt.SparkOffline.String(), fmt.Errorf("step failed: %s", errorMessage))
-			wrapped.AddDetails("executor_type", "EMR", "cluster_id", clusterId, "step_id", stepId, "wait_duration", maxWait.String())
-			wrapped.AddFixSuggestion("Check the cluster logs for more information")
-			return wrapped
+		var wrapped *fferr.ExecutionError
+		if failure.Message != "" {
+			wrapped = fferr.NewExecutionError(pt.SparkOffline.String(), fmt.Errorf("step failed: %s", failure.Message))
+		} else {
+			e.logger.Errorw("Failure waiting for completion of EMR cluster", "error", err, "cluster_id", clusterId, "step_id", stepId, "wait_duration", maxWait)
+			wrapped = fferr.NewExecutionError(pt.SparkOffline.String(), fmt.Errorf("failure waiting for completion of cluster: %w", err))
 		}
-
-		e.logger.Errorw("Failure waiting for completion of EMR cluster", "error", err, "cluster_id", clusterId, "step_id", stepId, "wait_duration", maxWait)
-		wrapped := fferr.NewExecutionError(pt.SparkOffline.String(), fmt.Errorf("failure waiting for completion of cluster: %w", err))
 		wrapped.AddDetails("executor_type", "EMR", "cluster_id", clusterId, "step_id", stepId, "wait_duration", maxWait.String())
+		wrapped.AddDetail("tracking_url", e.trackingURL(clusterId))
+		if failure.LogFile != "" {
+			wrapped.AddDetail("log_file", failure.LogFile)
+		}
+		if failure.StderrTail != "" {
+			wrapped.AddDetail("driver_stderr", failure.StderrTail)
+		}
 		wrapped.AddFixSuggestion("Check the cluster logs for more information")
 		return wrapped
 	}
@@ -280,12 +291,31 @@ func (e EMRExecutor) InitializeExecutor(store SparkFileStoreV2) error {
 	return nil
 }
 
-func (e *EMRExecutor) getStepErrorMessage(clusterId string, stepId string, maxWait time.Duration) (string, error) {
+// trackingURL links to the cluster in the EMR console, where the step's logs
+// and Spark UI can be found.
+func (e *EMRExecutor) trackingURL(clusterId string) string {
+	return fmt.Sprintf(
+		"https://%s.console.aws.amazon.com/emr/home?region=%s#/clusterDetails/%s",
+		e.region, e.region, clusterId,
+	)
+}
+
+// emrStepFailure is what we could find out about why an EMR step failed.
+type emrStepFailure struct {
+	Message string
+	// LogFile is the step's log directory in the cluster's log bucket.
+	LogFile string
+	// StderrTail is the end of the step's stderr, see sparkLogTail.
+	StderrTail string
+}
+
+func (e *EMRExecutor) getStepFailure(clusterId string, stepId string, maxWait time.Duration) (emrStepFailure, error) {
 	logger := e.logger.With("cluster_id", clusterId, "step_id", stepId)
+	failure := emrStepFailure{}
 	if e.logFileStore == nil {
 		errMsg := fmt.Sprintf("cannot get error message for EMR step '%s' because the log file store is not set", stepId)
 		logger.Error(errMsg)
-		return "", fferr.NewInternalErrorf(errMsg)
+		return failure, fferr.NewInternalErrorf(errMsg)
 	}
 
 	stepResults, err := e.client.DescribeStep(context.TODO(), &emr.DescribeStepInput{
@@ -298,54 +328,63 @@ func (e *EMRExecutor) getStepErrorMessage(clusterId string, stepId string, maxWa
 		wrapped.AddDetail("executor_type", "EMR")
 		wrapped.AddDetail("cluster_id", clusterId)
 		wrapped.AddDetail("step_id", stepId)
-		return "", wrapped
+		return failure, wrapped
 	}
 
 	stepStatus := stepResults.Step.Status
-	if stepStatus.State == emrtypes.StepStateFailed {
-		logger.Info("EMR step failed")
-		var errorMsg string
-		// check if there are any errors
-		failureDetails := stepStatus.FailureDetails
-		if failureDetails != nil {
-			if failureDetails.Message != nil {
-				// get the error message
-				errorMsg = *failureDetails.Message
-			}
-			if errorMsg != "" {
-				logger.Infow("EMR step failed with error message", "error_message", errorMsg)
-				return errorMsg, nil
-			}
-			if failureDetails.LogFile != nil {
-				logFile := *failureDetails.LogFile
-				logger = logger.With("emr_fail_log_file", logFile)
-				logger.Infow("EMR step failed with log file")
-
-				errorMessage, err := e.getLogFileMessage(logFile, logger, maxWait)
-				if err != nil {
-					logger.Errorw("Unable to get log file error message", "err", err)
-					wrapped := fferr.NewExecutionError(pt.SparkOffline.String(), fmt.Errorf("could not get error message from log file: %v", err))
-					wrapped.AddDetail("executor_type", "EMR")
-					wrapped.AddDetail("cluster_id", clusterId)
-					wrapped.AddDetail("step_id", stepId)
-					wrapped.AddDetail("log_file", logFile)
-					return "", wrapped
-				}
-				logger.Infow("Got error message from log file", "message", errorMessage)
-
-				return errorMessage, nil
-			}
+	if stepStatus.State != emrtypes.StepStateFailed || stepStatus.FailureDetails == nil {
+		return failure, nil
+	}
+	logger.Info("EMR step failed")
+	failureDetails := stepStatus.FailureDetails
+	if failureDetails.Message != nil {
+		failure.Message = *failureDetails.Message
+		logger.Infow("EMR step failed with error message", "error_message", failure.Message)
+	}
+	if failureDetails.LogFile == nil {
+		if failure.Message == "" {
+			logger.Info("EMR step failed but no error message was found")
 		}
-		logger.Info("EMR step failed but no error message was found")
+		return failure, nil
 	}
+	failure.LogFile = *failureDetails.LogFile
+	logger = logger.With("emr_fail_log_file", failure.LogFile)
+	logger.Infow("EMR step failed with log file")
 
-	return "", nil
+	stderrWait := maxWait
+	if stderrWait > emrStderrMaxWait {
+		stderrWait = emrStderrMaxWait
+	}
+	stderr, err := e.getLogFileMessage(failure.LogFile, "stderr.gz", logger, stderrWait)
+	if err != nil {
+		// The stderr only adds context to the error, so we carry on without it.
+		logger.Warnw("Unable to get stderr of failed step", "err", err)
+	} else {
+		failure.StderrTail = sparkLogTail(stderr)
+	}
+	if failure.Message != "" {
+		return failure, nil
+	}
+
+	errorMessage, err := e.getLogFileMessage(failure.LogFile, "stdout.gz", logger, maxWait)
+	if err != nil {
+		logger.Errorw("Unable to get log file error message", "err", err)
+		wrapped := fferr.NewExecutionError(pt.SparkOffline.String(), fmt.Errorf("could not get error message from log file: %v", err))
+		wrapped.AddDetail("executor_type", "EMR")
+		wrapped.AddDetail("cluster_id", clusterId)
+		wrapped.AddDetail("step_id", stepId)
+		wrapped.AddDetail("log_file", failure.LogFile)
+		return failure, wrapped
+	}
+	logger.Infow("Got error message from log file", "message", errorMessage)
+	failure.Message = errorMessage
+	return failure, nil
 }
 
-func (e *EMRExecutor) getLogFileMessage(logFile string, logger logging.Logger, maxWait time.Duration) (string, error) {
+func (e *EMRExecutor) getLogFileMessage(logFile, fileName string, logger logging.Logger, maxWait time.Duration) (string, error) {
 	logger.Debug("Getting log message")
 	outputFilepath := &filestore.S3Filepath{}
-	filePath := fmt.Sprintf("%s/stdout.gz", logFile)
+	filePath := fmt.Sprintf("%s/%s", logFile, fileName)
 	if err := outputFilepath.ParseFilePath(filePath); err != nil {
 		logger.Errorw("Failed to parse file path", "error", err)
 		return "", err
@@ -417,6 +456,7 @@ func (e *EMRExecutor) cancelStep(stepId string, waitDuration time.Duration) erro
 	e.logger.Errorw("EMR step exceeded max wait duration and was cancelled", "cluster_id", e.clusterName, "step_id", stepId, "wait_duration", waitDuration)
 	wrapped := fferr.NewExecutionError(pt.SparkOffline.String(), fmt.Errorf("EMR step exceeded max wait duration and was cancelled"))
 	wrapped.AddDetails("executor_type", "EMR", "cluster_id", e.clusterName, "step_id", stepId, "wait_duration", waitDuration.String())
+	wrapped.AddDetail("tracking_url", e.trackingURL(e.clusterName))
 	return wrapped
 }
 
@@ -122,10 +122,37 @@ func (r *blobAdapter) Size() int64 {
 	return reader.Size()
 }
 
+// outputFileTypes are the file types that materializations and transformations
+// can be written in.
+var outputFileTypes = []filestore.FileType{filestore.Parquet, filestore.Avro}
+
+// listOutputFiles lists the materialization or transformation output files under
+// dir, whichever of the output file types they were written in.
+func listOutputFiles(store FileStore, dir filestore.Filepath) ([]filestore.Filepath, error) {
+	files := make([]filestore.Filepath, 0)
+	for _, fileType := range outputFileTypes {
+		typedFiles, err := store.List(dir, fileType)
+		if err != nil {
+			return nil, err
+		}
+		// Not every store filters by the file type, so it's checked here as well.
+		for _, file := range typedFiles {
+			if file.Ext() == fileType {
+				files = append(files, file)
+			}
+		}
+	}
+	return files, nil
+}
+
 type Iterator interface {
 	Next() (map[string]interface{}, error)
 	FeatureColumns() []string
+	// LabelColumn returns the first of LabelColumns, or an empty string if there
+	// are none.
 	LabelColumn() string
+	// LabelColumns returns each of a training set's label columns, in order.
+	LabelColumns() []string
 }
 
 type LocalFileStore struct {
@@ -297,10 +324,12 @@ func NewAzureFileStore(config Config) (FileStore, error) {
 }
 
 type S3FileStore struct {
-	Credentials  pc.AWSCredentials
-	BucketRegion string
-	Bucket       string
-	Path         string
+	Credentials    pc.AWSCredentials
+	BucketRegion   string
+	Bucket         string
+	Path           string
+	Endpoint       string
+	ForcePathStyle bool
 	genericFileStore
 }
 
@@ -363,7 +392,10 @@ func NewS3FileStore(config Config) (FileStore, error) {
 		return nil, wrapped
 	}
 
-	clientV2 := s3v2.NewFromConfig(cfg)
+	forcePathStyle := s3UsePathStyle(s3StoreConfig.ForcePathStyle, trimmedBucket)
+	clientV2 := s3v2.NewFromConfig(cfg, func(o *s3v2.Options) {
+		o.UsePathStyle = forcePathStyle
+	})
 	bucket, err := s3blob.OpenBucketV2(context.TODO(), clientV2, trimmedBucket, nil)
 	if err != nil {
 		wrapped := fferr.NewExecutionError(string(filestore.S3), err)
@@ -371,10 +403,12 @@ func NewS3FileStore(config Config) (FileStore, error) {
 		return nil, wrapped
 	}
 	return &S3FileStore{
-		Bucket:       trimmedBucket,
-		BucketRegion: s3StoreConfig.BucketRegion,
-		Credentials:  s3StoreConfig.Credentials,
-		Path:         s3StoreConfig.Path,
+		Bucket:         trimmedBucket,
+		BucketRegion:   s3StoreConfig.BucketRegion,
+		Credentials:    s3StoreConfig.Credentials,
+		Path:           s3StoreConfig.Path,
+		Endpoint:       s3StoreConfig.Endpoint,
+		ForcePathStyle: forcePathStyle,
 		genericFileStore: genericFileStore{
 			bucket:    bucket,
 			storeType: filestore.S3,
@@ -382,6 +416,13 @@ func NewS3FileStore(config Config) (FileStore, error) {
 	}, nil
 }
 
+// s3UsePathStyle returns true if the bucket should be addressed by path rather than by
+// virtual host. Bucket names with dots always use path-style since they'd otherwise add
+// subdomains to the host, which the endpoint's TLS certificate won't match.
+func s3UsePathStyle(forcePathStyle bool, bucket string) bool {
+	return forcePathStyle || strings.Contains(bucket, ".")
+}
+
 func (s3 *S3FileStore) CreateFilePath(key string, isDirectory bool) (filestore.Filepath, error) {
 	fp := filestore.S3Filepath{}
 	// **NOTE:** It's possible we'll need to change this default based on whether the
@@ -608,8 +649,7 @@ func (store *genericFileStore) NewestFileOfType(searchPath filestore.Filepath, f
 }
 
 func (store *genericFileStore) getMoreRecentFile(newObj *blob.ListObject, expectedFileType filestore.FileType, oldTime time.Time, oldKey string) (time.Time, string) {
-	pathParts := strings.Split(newObj.Key, ".")
-	fileType := pathParts[len(pathParts)-1]
+	fileType := filestore.GetFileExtension(newObj.Key)
 	if fileType == string(expectedFileType) && !newObj.IsDir && store.isMostRecentFile(newObj, oldTime) {
 		return newObj.ModTime, newObj.Key
 	}
@@ -746,6 +786,9 @@ func (store *genericFileStore) Read(path filestore.Filepath) ([]byte, error) {
 }
 
 func (store *genericFileStore) ServeDirectory(files []filestore.Filepath) (Iterator, error) {
+	if len(files) > 0 && files[0].Ext() == filestore.Avro {
+		return avroIteratorOverMultipleFiles(files, store)
+	}
 	// assume file type is parquet
 	return parquetIteratorOverMultipleFiles(files, store)
 }
@@ -786,6 +829,12 @@ func (store *genericFileStore) NumRows(path filestore.Filepath) (int64, error) {
 	switch path.Ext() {
 	case filestore.Parquet:
 		return getParquetNumRows(reader)
+	case filestore.Avro:
+		data, err := store.Read(path)
+		if err != nil {
+			return 0, err
+		}
+		return getAvroNumRows(data)
 	default:
 		return 0, fmt.Errorf("unsupported file type")
 	}
@@ -902,6 +951,8 @@ func (store *genericFileStore) ServeFile(path filestore.Filepath) (Iterator, err
 	switch path.Ext() {
 	case filestore.Parquet:
 		return parquetIteratorFromBytes(bytes.NewReader(src))
+	case filestore.Avro:
+		return avroIteratorFromBytes(src)
 	case filestore.CSV:
 		return nil, fferr.NewInternalError(fmt.Errorf("csv iterator not implemented"))
 	default:
@@ -1161,6 +1212,12 @@ func (fs *HDFSFileStore) Read(key filestore.Filepath) ([]byte, error) {
 }
 
 func (fs *HDFSFileStore) ServeDirectory(files []filestore.Filepath) (Iterator, error) {
+	if len(files) == 0 {
+		return nil, fferr.NewInvalidArgumentError(fmt.Errorf("no files to read"))
+	}
+	if files[0].Ext() == filestore.Avro {
+		return avroIteratorOverMultipleFiles(files, fs)
+	}
 	return parquetIteratorOverMultipleFiles(files, fs)
 }
 
@@ -1180,6 +1237,8 @@ func (fs *HDFSFileStore) Serve(files []filestore.Filepath) (Iterator, error) {
 	switch file.Ext() {
 	case "parquet":
 		return parquetIteratorFromBytes(bytes.NewReader(b))
+	case "avro":
+		return avroIteratorFromBytes(b)
 	case "csv":
 		return nil, fmt.Errorf("could not find CSV reader")
 	default:
@@ -1327,6 +1386,9 @@ func (fs *HDFSFileStore) NumRows(key filestore.Filepath) (int64, error) {
 	if err != nil {
 		return 0, err
 	}
+	if key.Ext() == filestore.Avro {
+		return getAvroNumRows(file)
+	}
 	rows, err := getParquetNumRows(bytes.NewReader(file))
 	if err != nil {
 		return 0, err
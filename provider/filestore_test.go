@@ -0,0 +1,99 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+//
+// Copyright 2024 FeatureForm Inc.
+//
+
+package provider
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	pc "github.com/featureform/provider/provider_config"
+)
+
+func TestS3UsePathStyle(t *testing.T) {
+	if s3UsePathStyle(false, "featureform-bucket") {
+		t.Fatalf("expected virtual-hosted style for bucket without dots")
+	}
+	if !s3UsePathStyle(true, "featureform-bucket") {
+		t.Fatalf("expected path style when forced")
+	}
+	if !s3UsePathStyle(false, "featureform.bucket") {
+		t.Fatalf("expected path style for bucket with dots")
+	}
+}
+
+func TestS3FileStoreCustomEndpoint(t *testing.T) {
+	tests := map[string]struct {
+		bucket         string
+		forcePathStyle bool
+	}{
+		"force path style": {"featureform-bucket", true},
+		"dotted bucket":    {"featureform.dotted.bucket", false},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			// A minimal S3 compatible server that only understands path-style requests.
+			var mu sync.Mutex
+			objects := make(map[string][]byte)
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				mu.Lock()
+				defer mu.Unlock()
+				switch r.Method {
+				case http.MethodPut:
+					body, _ := io.ReadAll(r.Body)
+					objects[r.URL.Path] = body
+					w.Header().Set("ETag", `"etag"`)
+				case http.MethodGet:
+					body, ok := objects[r.URL.Path]
+					if !ok {
+						w.WriteHeader(http.StatusNotFound)
+						return
+					}
+					w.Write(body)
+				}
+			}))
+			defer server.Close()
+
+			config := pc.S3FileStoreConfig{
+				Credentials:    pc.AWSStaticCredentials{AccessKeyId: "minio", SecretKey: "minio123"},
+				BucketRegion:   "us-east-1",
+				BucketPath:     test.bucket,
+				Endpoint:       server.URL,
+				ForcePathStyle: test.forcePathStyle,
+			}
+			serialized, err := config.Serialize()
+			if err != nil {
+				t.Fatalf("failed to serialize config: %v", err)
+			}
+			store, err := NewS3FileStore(serialized)
+			if err != nil {
+				t.Fatalf("failed to create s3 filestore: %v", err)
+			}
+			s3Store := store.(*S3FileStore)
+			if !s3Store.ForcePathStyle || s3Store.Endpoint != server.URL {
+				t.Fatalf("expected path style with endpoint %s, got: %v %s", server.URL, s3Store.ForcePathStyle, s3Store.Endpoint)
+			}
+			path, err := store.CreateFilePath("dir/file.txt", false)
+			if err != nil {
+				t.Fatalf("failed to create file path: %v", err)
+			}
+			if err := store.Write(path, []byte("data")); err != nil {
+				t.Fatalf("failed to write file: %v", err)
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			expected := "/" + test.bucket + "/dir/file.txt"
+			if _, ok := objects[expected]; !ok {
+				t.Fatalf("expected object to be written to %s, got: %v", expected, objects)
+			}
+		})
+	}
+}
@@ -29,12 +29,15 @@ import (
 	"google.golang.org/api/option"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
+	"strconv"
 	"time"
 )
 
 const (
 	// valueKey represents the key for the feature in a document.
 	valueKey = "value"
+	// tsKey represents the key for the timestamp of the feature's value in a document.
+	tsKey = "ts"
 )
 
 // The serializer versions. If adding a new one make sure to add to the serializers map variable
@@ -394,35 +397,144 @@ func (table firestoreOnlineTable) Get(entity string) (interface{}, error) {
 	return table.serializer.Deserialize(table.valueType, value)
 }
 
-// maxFirestoreBatchSize is the max amount of items that can be written to Firestore at once.
-const maxFirestoreBatchSize = 20
+// maxFirestoreBatchSize is the max amount of writes Firestore allows in a single batch or transaction.
+const maxFirestoreBatchSize = 500
 
 func (table firestoreOnlineTable) MaxBatchSize() (int, error) { return maxFirestoreBatchSize, nil }
 
+type firestoreDocument struct {
+	entity string
+	fields map[string]interface{}
+	ts     time.Time
+}
+
+// BatchSet overwrites the documents of the items' entities. If the items have
+// timestamps, the documents are read and written in a transaction so that a
+// value is never overwritten by an older one; otherwise they're written with a
+// BulkWriter. If some of the writes fail, a ConnectionError with the number of
+// written and failed items is returned.
 func (table firestoreOnlineTable) BatchSet(items []SetItem) error {
 	if len(items) > maxFirestoreBatchSize {
 		return fferr.NewInternalErrorf(
 			"Cannot batch write %d items.\nMax: %d\n", len(items), maxFirestoreBatchSize)
 	}
+	docs, err := table.latestDocuments(items)
+	if err != nil {
+		return err
+	}
+	for _, doc := range docs {
+		if !doc.ts.IsZero() {
+			return table.transactionalSet(docs)
+		}
+	}
+	return table.bulkSet(docs)
+}
 
-	bulkWriter := table.client.BulkWriter(context.TODO())
-
+// latestDocuments serializes the items into documents, keeping only the newest
+// item of each entity. If an entity's items have the same timestamp, the last
+// one wins.
+func (table firestoreOnlineTable) latestDocuments(items []SetItem) ([]firestoreDocument, error) {
+	docs := make([]firestoreDocument, 0, len(items))
+	indexes := make(map[string]int, len(items))
 	for _, item := range items {
 		serializedValue, err := table.serializer.Serialize(table.valueType, item.Value)
 		if err != nil {
-			table.logger.Error("Error serializing value", "entity", item.Entity, "value", item.Value, "err", err)
-			return err
+			table.logger.Errorw("Error serializing value", "entity", item.Entity, "value", item.Value, "err", err)
+			return nil, err
 		}
-		document := table.collection.Doc(item.Entity)
-		_, err = bulkWriter.Create(document, map[string]interface{}{
-			valueKey: serializedValue,
-		})
-		if err != nil {
-			table.logger.Error("Error creating document", "entity", item.Entity, "value", item.Value, "err", err)
-			return err
+		doc := firestoreDocument{
+			entity: item.Entity,
+			fields: map[string]interface{}{valueKey: serializedValue},
+			ts:     item.TS,
+		}
+		if !item.TS.IsZero() {
+			doc.fields[tsKey] = item.TS
 		}
+		if idx, has := indexes[item.Entity]; has {
+			if !item.TS.Before(docs[idx].ts) {
+				docs[idx] = doc
+			}
+			continue
+		}
+		indexes[item.Entity] = len(docs)
+		docs = append(docs, doc)
 	}
+	return docs, nil
+}
 
+func (table firestoreOnlineTable) bulkSet(docs []firestoreDocument) error {
+	bulkWriter := table.client.BulkWriter(context.TODO())
+	jobs := make([]*firestore.BulkWriterJob, 0, len(docs))
+	failed := 0
+	var firstErr error
+	for _, doc := range docs {
+		job, err := bulkWriter.Set(table.collection.Doc(doc.entity), doc.fields)
+		if err != nil {
+			table.logger.Errorw("Error queueing document", "entity", doc.entity, "err", err)
+			failed++
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		jobs = append(jobs, job)
+	}
+	// End flushes the writer and blocks until every job has finished.
 	bulkWriter.End()
+	for _, job := range jobs {
+		if _, err := job.Results(); err != nil {
+			failed++
+			if firstErr == nil {
+				firstErr = err
+			}
+		}
+	}
+	return table.batchError(len(docs), failed, firstErr)
+}
+
+func (table firestoreOnlineTable) transactionalSet(docs []firestoreDocument) error {
+	refs := make([]*firestore.DocumentRef, len(docs))
+	for i, doc := range docs {
+		refs[i] = table.collection.Doc(doc.entity)
+	}
+	err := table.client.RunTransaction(context.TODO(), func(ctx context.Context, tx *firestore.Transaction) error {
+		snapshots, err := tx.GetAll(refs)
+		if err != nil {
+			return err
+		}
+		for i, snapshot := range snapshots {
+			if snapshot.Exists() && firestoreValueIsNewer(snapshot.Data()[tsKey], docs[i].ts) {
+				continue
+			}
+			if err := tx.Set(refs[i], docs[i].fields); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		table.logger.Errorw("Error writing documents in transaction", "documents", len(docs), "err", err)
+		// Transactions are atomic, so none of the documents were written.
+		return table.batchError(len(docs), len(docs), err)
+	}
 	return nil
 }
+
+// firestoreValueIsNewer returns true if the stored timestamp of a document is
+// after ts. Documents written without a timestamp are never newer.
+func firestoreValueIsNewer(stored interface{}, ts time.Time) bool {
+	storedTS, ok := stored.(time.Time)
+	return ok && storedTS.After(ts)
+}
+
+func (table firestoreOnlineTable) batchError(total, failed int, err error) error {
+	if failed == 0 {
+		return nil
+	}
+	wrapped := fferr.NewConnectionError(pt.FirestoreOnline.String(), err)
+	wrapped.AddDetail("feature", table.key.Feature)
+	wrapped.AddDetail("variant", table.key.Variant)
+	wrapped.AddDetail("written", strconv.Itoa(total-failed))
+	wrapped.AddDetail("failed", strconv.Itoa(failed))
+	return wrapped
+}
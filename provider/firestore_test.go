@@ -9,11 +9,18 @@ package provider
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
 	"os"
+	"reflect"
 	"testing"
+	"time"
 
+	"github.com/featureform/fferr"
+	"github.com/featureform/logging"
 	pc "github.com/featureform/provider/provider_config"
 	pt "github.com/featureform/provider/provider_type"
+	vt "github.com/featureform/provider/types"
 	"github.com/joho/godotenv"
 )
 
@@ -72,3 +79,65 @@ func TestOnlineStoreFirestore(t *testing.T) {
 	}
 	test.Run()
 }
+
+func TestFirestoreLatestDocuments(t *testing.T) {
+	table := firestoreOnlineTable{
+		valueType:  vt.Int,
+		serializer: firestoreSerializerV0{},
+		logger:     logging.NewTestLogger(t).SugaredLogger,
+	}
+	older := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	newer := older.Add(time.Hour)
+	docs, err := table.latestDocuments([]SetItem{
+		{Entity: "a", Value: 2, TS: newer},
+		{Entity: "b", Value: 1},
+		{Entity: "a", Value: 1, TS: older},
+		{Entity: "b", Value: 2},
+	})
+	if err != nil {
+		t.Fatalf("Failed to build documents: %v", err)
+	}
+	expected := []firestoreDocument{
+		{entity: "a", fields: map[string]interface{}{valueKey: 2, tsKey: newer}, ts: newer},
+		{entity: "b", fields: map[string]interface{}{valueKey: 2}},
+	}
+	if !reflect.DeepEqual(docs, expected) {
+		t.Fatalf("Expected %v, got %v", expected, docs)
+	}
+}
+
+func TestFirestoreValueIsNewer(t *testing.T) {
+	ts := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	tests := map[string]struct {
+		stored   interface{}
+		expected bool
+	}{
+		"Newer":        {ts.Add(time.Second), true},
+		"Same":         {ts, false},
+		"Older":        {ts.Add(-time.Second), false},
+		"No Timestamp": {nil, false},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			if actual := firestoreValueIsNewer(test.stored, ts); actual != test.expected {
+				t.Fatalf("Expected %v, got %v", test.expected, actual)
+			}
+		})
+	}
+}
+
+func TestFirestoreBatchError(t *testing.T) {
+	table := firestoreOnlineTable{key: firestoreTableKey{"collection", "feature", "variant"}}
+	if err := table.batchError(10, 0, nil); err != nil {
+		t.Fatalf("Expected no error without failures, got %v", err)
+	}
+	err := table.batchError(10, 3, fmt.Errorf("unavailable"))
+	var connErr *fferr.ConnectionError
+	if !errors.As(err, &connErr) {
+		t.Fatalf("Expected a ConnectionError, got %T", err)
+	}
+	details := connErr.Details()
+	if details["written"] != "7" || details["failed"] != "3" {
+		t.Fatalf("Expected 7 written and 3 failed, got %v", details)
+	}
+}
@@ -0,0 +1,283 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+//
+// Copyright 2024 FeatureForm Inc.
+//
+
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/glue"
+	gluetypes "github.com/aws/aws-sdk-go-v2/service/glue/types"
+	"github.com/featureform/fferr"
+	"github.com/featureform/filestore"
+	pl "github.com/featureform/provider/location"
+	"github.com/featureform/provider/types"
+)
+
+const (
+	// The Glue table parameters set by Iceberg's GlueCatalog.
+	glueTableTypeParameter        = "table_type"
+	glueMetadataLocationParameter = "metadata_location"
+	glueIcebergTableType          = "ICEBERG"
+
+	// Iceberg manifest entries marked as deleted are no longer part of the snapshot.
+	icebergManifestEntryDeleted = 2
+	// Iceberg content type of manifests and files that hold data rather than deletes.
+	icebergDataContent = 0
+)
+
+// icebergTableMetadata is the subset of an Iceberg table metadata file needed to
+// find the data files of the table's current snapshot.
+type icebergTableMetadata struct {
+	Location          string            `json:"location"`
+	CurrentSnapshotID *int64            `json:"current-snapshot-id"`
+	Snapshots         []icebergSnapshot `json:"snapshots"`
+}
+
+type icebergSnapshot struct {
+	SnapshotID   int64             `json:"snapshot-id"`
+	ManifestList string            `json:"manifest-list"`
+	Summary      map[string]string `json:"summary"`
+}
+
+// currentSnapshot returns the table's current snapshot, or false if the table
+// has never been written to.
+func (m icebergTableMetadata) currentSnapshot() (icebergSnapshot, bool) {
+	if m.CurrentSnapshotID == nil || *m.CurrentSnapshotID == -1 {
+		return icebergSnapshot{}, false
+	}
+	for _, snapshot := range m.Snapshots {
+		if snapshot.SnapshotID == *m.CurrentSnapshotID {
+			return snapshot, true
+		}
+	}
+	return icebergSnapshot{}, false
+}
+
+func readIcebergMetadata(store FileStore, metadataLocation string) (icebergTableMetadata, error) {
+	metadata := icebergTableMetadata{}
+	data, err := readIcebergFile(store, metadataLocation)
+	if err != nil {
+		return metadata, err
+	}
+	if err := json.Unmarshal(data, &metadata); err != nil {
+		wrapped := fferr.NewInternalErrorf("could not parse Iceberg table metadata: %v", err)
+		wrapped.AddDetail("metadata_location", metadataLocation)
+		return metadata, wrapped
+	}
+	return metadata, nil
+}
+
+// icebergDataFiles returns the data files of the table's current snapshot by
+// reading its manifest list and manifests. Tables with row-level deletes aren't
+// supported, since reading them correctly requires applying the delete files.
+func icebergDataFiles(store FileStore, metadata icebergTableMetadata) ([]filestore.Filepath, error) {
+	snapshot, ok := metadata.currentSnapshot()
+	if !ok {
+		return []filestore.Filepath{}, nil
+	}
+	if snapshot.ManifestList == "" {
+		return nil, fferr.NewUnimplementedErrorf("Iceberg snapshot %d has no manifest list; only v1 tables with manifest lists and v2 tables are supported", snapshot.SnapshotID)
+	}
+	manifestListData, err := readIcebergFile(store, snapshot.ManifestList)
+	if err != nil {
+		return nil, err
+	}
+	manifests, err := readAvroContainer(manifestListData)
+	if err != nil {
+		return nil, err
+	}
+	files := make([]filestore.Filepath, 0)
+	for _, manifest := range manifests {
+		if content, ok := manifest["content"].(int32); ok && content != icebergDataContent {
+			return nil, fferr.NewUnimplementedErrorf("Iceberg tables with row-level deletes are not supported")
+		}
+		manifestPath, ok := manifest["manifest_path"].(string)
+		if !ok {
+			return nil, fferr.NewInternalErrorf("Iceberg manifest list entry is missing manifest_path")
+		}
+		manifestData, err := readIcebergFile(store, manifestPath)
+		if err != nil {
+			return nil, err
+		}
+		entries, err := readAvroContainer(manifestData)
+		if err != nil {
+			return nil, err
+		}
+		for _, entry := range entries {
+			if status, ok := entry["status"].(int32); ok && status == icebergManifestEntryDeleted {
+				continue
+			}
+			dataFile, ok := entry["data_file"].(map[string]interface{})
+			if !ok {
+				return nil, fferr.NewInternalErrorf("Iceberg manifest entry is missing data_file")
+			}
+			if content, ok := dataFile["content"].(int32); ok && content != icebergDataContent {
+				return nil, fferr.NewUnimplementedErrorf("Iceberg tables with row-level deletes are not supported")
+			}
+			if format, _ := dataFile["file_format"].(string); !strings.EqualFold(format, string(filestore.Parquet)) {
+				return nil, fferr.NewUnimplementedErrorf("Iceberg data files of format %s are not supported", format)
+			}
+			path, ok := dataFile["file_path"].(string)
+			if !ok {
+				return nil, fferr.NewInternalErrorf("Iceberg data file is missing file_path")
+			}
+			fp, err := store.ParseFilePath(path)
+			if err != nil {
+				return nil, err
+			}
+			files = append(files, fp)
+		}
+	}
+	return files, nil
+}
+
+func readIcebergFile(store FileStore, path string) ([]byte, error) {
+	fp, err := store.ParseFilePath(path)
+	if err != nil {
+		return nil, err
+	}
+	return store.Read(fp)
+}
+
+// glueColumnValueType maps a Glue (Hive) column type to a value type. Complex
+// and unknown types map to NilType.
+func glueColumnValueType(glueType string) types.ValueType {
+	baseType := strings.ToLower(glueType)
+	if idx := strings.IndexAny(baseType, "(<"); idx != -1 {
+		baseType = baseType[:idx]
+	}
+	switch strings.TrimSpace(baseType) {
+	case "tinyint":
+		return types.Int8
+	case "smallint":
+		return types.Int16
+	case "int", "integer":
+		return types.Int32
+	case "bigint", "long":
+		return types.Int64
+	case "float":
+		return types.Float32
+	case "double", "decimal":
+		return types.Float64
+	case "string", "varchar", "char":
+		return types.String
+	case "boolean":
+		return types.Bool
+	case "timestamp", "timestamptz", "timestamp_ntz":
+		return types.Timestamp
+	case "date":
+		return types.Datetime
+	default:
+		return types.NilType
+	}
+}
+
+// glueIcebergTable looks up an Iceberg table in the Glue catalog and returns the
+// location of its current metadata file along with its schema.
+func glueIcebergTable(store *SparkGlueS3FileStore, location *pl.CatalogLocation) (string, TableSchema, error) {
+	output, err := store.GlueClient.GetTable(context.TODO(), &glue.GetTableInput{
+		DatabaseName: aws.String(location.Database()),
+		Name:         aws.String(location.Table()),
+	})
+	var notFound *gluetypes.EntityNotFoundException
+	if errors.As(err, &notFound) {
+		return "", TableSchema{}, fferr.NewDatasetNotFoundError(location.Location(), "", err)
+	} else if err != nil {
+		wrapped := fferr.NewExecutionError("Glue", err)
+		wrapped.AddDetail("table", location.Location())
+		return "", TableSchema{}, wrapped
+	}
+	table := output.Table
+	if tableType := table.Parameters[glueTableTypeParameter]; !strings.EqualFold(tableType, glueIcebergTableType) {
+		return "", TableSchema{}, fferr.NewInvalidArgumentErrorf(
+			"Glue table %s is not an Iceberg table; found table type '%s'", location.Location(), tableType,
+		)
+	}
+	metadataLocation := table.Parameters[glueMetadataLocationParameter]
+	if metadataLocation == "" {
+		return "", TableSchema{}, fferr.NewInvalidArgumentErrorf("Glue table %s has no Iceberg metadata location", location.Location())
+	}
+	schema := TableSchema{SourceTable: location.Location()}
+	if table.StorageDescriptor != nil {
+		for _, column := range table.StorageDescriptor.Columns {
+			schema.Columns = append(schema.Columns, TableColumn{
+				Name:      aws.ToString(column.Name),
+				ValueType: glueColumnValueType(aws.ToString(column.Type)),
+			})
+		}
+	}
+	return metadataLocation, schema, nil
+}
+
+// IcebergPrimaryTable is a primary table backed by an Iceberg table in the Glue
+// catalog. The metadata location is looked up on every read so the table always
+// reflects its current snapshot.
+type IcebergPrimaryTable struct {
+	id       ResourceID
+	location *pl.CatalogLocation
+	schema   TableSchema
+	store    *SparkGlueS3FileStore
+}
+
+func (tbl *IcebergPrimaryTable) Write(record GenericRecord) error {
+	return fferr.NewInternalErrorf("You cannot write to a primary table")
+}
+
+func (tbl *IcebergPrimaryTable) WriteBatch(records []GenericRecord) error {
+	return fferr.NewInternalErrorf("You cannot write to a primary table")
+}
+
+func (tbl *IcebergPrimaryTable) GetName() string {
+	return tbl.location.Location()
+}
+
+func (tbl *IcebergPrimaryTable) currentMetadata() (icebergTableMetadata, error) {
+	metadataLocation, _, err := glueIcebergTable(tbl.store, tbl.location)
+	if err != nil {
+		return icebergTableMetadata{}, err
+	}
+	return readIcebergMetadata(tbl.store, metadataLocation)
+}
+
+func (tbl *IcebergPrimaryTable) IterateSegment(n int64) (GenericTableIterator, error) {
+	metadata, err := tbl.currentMetadata()
+	if err != nil {
+		return nil, err
+	}
+	files, err := icebergDataFiles(tbl.store, metadata)
+	if err != nil {
+		return nil, err
+	}
+	if len(files) == 0 {
+		return nil, fferr.NewDatasetNotFoundError(tbl.id.Name, tbl.id.Variant, fmt.Errorf("Iceberg table %s has no data files", tbl.GetName()))
+	}
+	return newMultipleFileParquetIterator(files, tbl.store, n)
+}
+
+// NumRows returns the row count recorded in the current snapshot's summary.
+func (tbl *IcebergPrimaryTable) NumRows() (int64, error) {
+	metadata, err := tbl.currentMetadata()
+	if err != nil {
+		return 0, err
+	}
+	snapshot, ok := metadata.currentSnapshot()
+	if !ok {
+		return 0, nil
+	}
+	rows, err := strconv.ParseInt(snapshot.Summary["total-records"], 10, 64)
+	if err != nil {
+		return 0, fferr.NewInternalErrorf("could not parse Iceberg snapshot row count: %v", err)
+	}
+	return rows, nil
+}
@@ -0,0 +1,184 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+//
+// Copyright 2024 FeatureForm Inc.
+//
+
+package provider
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/featureform/provider/types"
+	"github.com/linkedin/goavro/v2"
+)
+
+const (
+	testManifestListSchema = `{"type": "record", "name": "manifest_file", "fields": [
+		{"name": "manifest_path", "type": "string"},
+		{"name": "content", "type": "int"}
+	]}`
+	testManifestSchema = `{"type": "record", "name": "manifest_entry", "fields": [
+		{"name": "status", "type": "int"},
+		{"name": "snapshot_id", "type": ["null", "long"]},
+		{"name": "data_file", "type": {"type": "record", "name": "r2", "fields": [
+			{"name": "content", "type": "int"},
+			{"name": "file_path", "type": "string"},
+			{"name": "file_format", "type": "string"},
+			{"name": "column_sizes", "type": ["null", {"type": "array", "items": {"type": "record", "name": "k117_v118", "fields": [
+				{"name": "key", "type": "int"},
+				{"name": "value", "type": "long"}
+			]}}]}
+		]}}
+	]}`
+)
+
+// avroTestContainer encodes records as an Avro object container file.
+func avroTestContainer(t *testing.T, schema, codec string, records ...map[string]interface{}) []byte {
+	t.Helper()
+	out := new(bytes.Buffer)
+	writer, err := goavro.NewOCFWriter(goavro.OCFConfig{W: out, Schema: schema, CompressionName: codec})
+	if err != nil {
+		t.Fatalf("could not create Avro writer: %v", err)
+	}
+	natives := make([]interface{}, len(records))
+	for i, record := range records {
+		natives[i] = record
+	}
+	if err := writer.Append(natives); err != nil {
+		t.Fatalf("could not write Avro records: %v", err)
+	}
+	return out.Bytes()
+}
+
+func writeIcebergTestFile(t *testing.T, store FileStore, key string, data []byte) string {
+	t.Helper()
+	fp, err := store.CreateFilePath(key, false)
+	if err != nil {
+		t.Fatalf("could not create file path: %v", err)
+	}
+	if err := store.Write(fp, data); err != nil {
+		t.Fatalf("could not write %s: %v", key, err)
+	}
+	return fp.ToURI()
+}
+
+func TestReadAvroContainer(t *testing.T) {
+	for _, codec := range []string{"null", "deflate"} {
+		t.Run(codec, func(t *testing.T) {
+			entry := map[string]interface{}{
+				"status":      1,
+				"snapshot_id": goavro.Union("long", int64(42)),
+				"data_file": map[string]interface{}{
+					"content":      0,
+					"file_path":    "s3://bucket/data/a.parquet",
+					"file_format":  "PARQUET",
+					"column_sizes": goavro.Union("array", []interface{}{map[string]interface{}{"key": 3, "value": int64(100)}}),
+				},
+			}
+			records, err := readAvroContainer(avroTestContainer(t, testManifestSchema, codec, entry))
+			if err != nil {
+				t.Fatalf("could not read container: %v", err)
+			}
+			// Unions decode to the value of their branch.
+			expected := []map[string]interface{}{
+				{
+					"status":      int32(1),
+					"snapshot_id": int64(42),
+					"data_file": map[string]interface{}{
+						"content":      int32(0),
+						"file_path":    "s3://bucket/data/a.parquet",
+						"file_format":  "PARQUET",
+						"column_sizes": []interface{}{map[string]interface{}{"key": int32(3), "value": int64(100)}},
+					},
+				},
+			}
+			if !reflect.DeepEqual(records, expected) {
+				t.Fatalf("expected %v, got %v", expected, records)
+			}
+		})
+	}
+}
+
+func TestIcebergDataFiles(t *testing.T) {
+	store, err := NewLocalFileStore([]byte(fmt.Sprintf(`{"DirPath": "file://%s/"}`, t.TempDir())))
+	if err != nil {
+		t.Fatalf("could not create local file store: %v", err)
+	}
+	liveFile := writeIcebergTestFile(t, store, "table/data/live.parquet", []byte{})
+	deletedFile := writeIcebergTestFile(t, store, "table/data/deleted.parquet", []byte{})
+
+	entries := make([]map[string]interface{}, 0)
+	for _, entry := range []struct {
+		status int
+		path   string
+	}{{1, liveFile}, {icebergManifestEntryDeleted, deletedFile}} {
+		entries = append(entries, map[string]interface{}{
+			"status":      entry.status,
+			"snapshot_id": nil,
+			"data_file": map[string]interface{}{
+				"content":      0,
+				"file_path":    entry.path,
+				"file_format":  "PARQUET",
+				"column_sizes": nil,
+			},
+		})
+	}
+	manifestPath := writeIcebergTestFile(t, store, "table/metadata/manifest.avro", avroTestContainer(t, testManifestSchema, "deflate", entries...))
+
+	writeManifestList := func(key string, content int) string {
+		manifestList := map[string]interface{}{"manifest_path": manifestPath, "content": content}
+		return writeIcebergTestFile(t, store, key, avroTestContainer(t, testManifestListSchema, "null", manifestList))
+	}
+	currentID := int64(2)
+	metadata := icebergTableMetadata{
+		CurrentSnapshotID: &currentID,
+		Snapshots: []icebergSnapshot{
+			{SnapshotID: 1, ManifestList: writeManifestList("table/metadata/snap-1.avro", 1)},
+			{SnapshotID: 2, ManifestList: writeManifestList("table/metadata/snap-2.avro", icebergDataContent)},
+		},
+	}
+	files, err := icebergDataFiles(store, metadata)
+	if err != nil {
+		t.Fatalf("could not get data files: %v", err)
+	}
+	if len(files) != 1 || files[0].ToURI() != liveFile {
+		t.Fatalf("expected only %s, got %v", liveFile, files)
+	}
+
+	// The first snapshot's manifest list points at a delete manifest.
+	currentID = 1
+	if _, err := icebergDataFiles(store, metadata); err == nil {
+		t.Fatalf("expected error for a table with row-level deletes")
+	}
+
+	empty := icebergTableMetadata{}
+	if files, err := icebergDataFiles(store, empty); err != nil || len(files) != 0 {
+		t.Fatalf("expected no files for a table without snapshots, got %v: %v", files, err)
+	}
+}
+
+func TestGlueColumnValueType(t *testing.T) {
+	tests := map[string]types.ValueType{
+		"bigint":              types.Int64,
+		"int":                 types.Int32,
+		"string":              types.String,
+		"varchar(255)":        types.String,
+		"double":              types.Float64,
+		"decimal(10,2)":       types.Float64,
+		"boolean":             types.Bool,
+		"timestamp":           types.Timestamp,
+		"date":                types.Datetime,
+		"array<string>":       types.NilType,
+		"struct<a:int,b:int>": types.NilType,
+	}
+	for glueType, expected := range tests {
+		if got := glueColumnValueType(glueType); got != expected {
+			t.Fatalf("%s: expected %v, got %v", glueType, expected, got)
+		}
+	}
+}
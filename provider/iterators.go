@@ -8,7 +8,7 @@
 package provider
 
 import (
-	"encoding/csv"
+	"encoding/json"
 	"fmt"
 	"io"
 	"math"
@@ -24,6 +24,7 @@ import (
 
 	"github.com/featureform/fferr"
 	filestore "github.com/featureform/filestore"
+	pl "github.com/featureform/provider/location"
 )
 
 // PARQUET
@@ -318,7 +319,7 @@ type ParquetIteratorMultipleFiles struct {
 	currentIndex   int64
 	fileIterator   Iterator
 	featureColumns []string
-	labelColumn    string
+	labelColumns   []string
 	store          FileStore
 }
 
@@ -337,7 +338,7 @@ func parquetIteratorOverMultipleFiles(fileParts []filestore.Filepath, store File
 		fileIterator:   iterator,
 		store:          store,
 		featureColumns: iterator.FeatureColumns(),
-		labelColumn:    iterator.LabelColumn(),
+		labelColumns:   iterator.LabelColumns(),
 	}, nil
 }
 
@@ -346,7 +347,11 @@ func (p *ParquetIteratorMultipleFiles) FeatureColumns() []string {
 }
 
 func (p *ParquetIteratorMultipleFiles) LabelColumn() string {
-	return p.labelColumn
+	return firstLabelColumn(p.labelColumns)
+}
+
+func (p *ParquetIteratorMultipleFiles) LabelColumns() []string {
+	return p.labelColumns
 }
 
 func (p *ParquetIteratorMultipleFiles) Next() (map[string]interface{}, error) {
@@ -377,7 +382,7 @@ type ParquetIterator struct {
 	reader         *parquet.Reader
 	index          int64
 	featureColumns []string
-	labelColumn    string
+	labelColumns   []string
 	fields         []parquet.Field
 }
 
@@ -427,7 +432,18 @@ func (p *ParquetIterator) FeatureColumns() []string {
 }
 
 func (p *ParquetIterator) LabelColumn() string {
-	return p.labelColumn
+	return firstLabelColumn(p.labelColumns)
+}
+
+func (p *ParquetIterator) LabelColumns() []string {
+	return p.labelColumns
+}
+
+func firstLabelColumn(columns []string) string {
+	if len(columns) == 0 {
+		return ""
+	}
+	return columns[0]
 }
 
 func getParquetNumRows(src io.ReaderAt) (int64, error) {
@@ -444,7 +460,7 @@ const (
 
 type parquetSchema struct {
 	featureColumns []string
-	labelColumn    string
+	labelColumns   []string
 	fields         []parquet.Field
 }
 
@@ -465,7 +481,7 @@ func (s *parquetSchema) getColumnType(name string) columnType {
 
 func (s *parquetSchema) setColumn(colType columnType, name string) {
 	if colType == labelType {
-		s.labelColumn = name
+		s.labelColumns = append(s.labelColumns, name)
 	} else if colType == featureType {
 		s.featureColumns = append(s.featureColumns, name)
 	}
@@ -479,14 +495,18 @@ func parquetIteratorFromBytes(src io.ReaderAt) (Iterator, error) {
 		reader:         r,
 		index:          int64(0),
 		featureColumns: schema.featureColumns,
-		labelColumn:    schema.labelColumn,
+		labelColumns:   schema.labelColumns,
 		fields:         schema.fields,
 	}, nil
 }
 
 // / CSV
 type csvIterator struct {
-	reader        *csv.Reader
+	src    io.ReadCloser
+	reader csvRecordReader
+	// firstRow holds the first record of a file without a header, which is read
+	// to count its columns.
+	firstRow      []string
 	currentValues GenericRecord
 	err           error
 	columnNames   []string
@@ -498,7 +518,13 @@ func (c *csvIterator) Next() bool {
 	if c.idx >= c.limit {
 		return false
 	}
-	row, err := c.reader.Read()
+	var row []string
+	var err error
+	if c.firstRow != nil {
+		row, c.firstRow = c.firstRow, nil
+	} else {
+		row, err = c.reader.Read()
+	}
 	if err != nil {
 		if err == io.EOF {
 			return false
@@ -525,7 +551,7 @@ func (c *csvIterator) Err() error {
 }
 
 func (c *csvIterator) Close() error {
-	return nil
+	return c.src.Close()
 }
 
 func (c *csvIterator) ParseRow(row []string) GenericRecord {
@@ -544,19 +570,166 @@ func (c *csvIterator) ParseRow(row []string) GenericRecord {
 	return records
 }
 
-func newCSVIterator(src io.Reader, limit int64) (GenericTableIterator, error) {
-	reader := csv.NewReader(src)
+func newCSVIterator(src io.ReadCloser, limit int64, opts pl.CSVOptions) (GenericTableIterator, error) {
+	reader := newCSVRecordReader(src, opts)
 	headers, err := reader.Read()
 	if err != nil {
+		src.Close()
 		return nil, fferr.NewInternalError(err)
 	}
+	var firstRow []string
+	if opts.NoHeader {
+		firstRow = headers
+		headers = csvColumnNames(len(firstRow))
+	}
 	if limit == -1 {
 		limit = math.MaxInt64
 	}
 	return &csvIterator{
+		src:         src,
 		reader:      reader,
+		firstRow:    firstRow,
 		columnNames: headers,
 		limit:       limit,
 		idx:         0,
 	}, nil
 }
+
+// / JSON
+// jsonIterator reads newline-delimited JSON objects, the format Spark reads and
+// writes JSON in. Its columns are the keys of the first object, in order; keys
+// missing from later objects are nil and keys not in the first are ignored.
+type jsonIterator struct {
+	src           io.ReadCloser
+	decoder       *json.Decoder
+	firstRow      map[string]interface{}
+	currentValues GenericRecord
+	err           error
+	columnNames   []string
+	idx           int64
+	limit         int64
+}
+
+func (it *jsonIterator) Next() bool {
+	if it.idx >= it.limit {
+		return false
+	}
+	var row map[string]interface{}
+	if it.firstRow != nil {
+		row, it.firstRow = it.firstRow, nil
+	} else {
+		var err error
+		_, row, err = decodeJSONObject(it.decoder)
+		if err != nil {
+			if err != io.EOF {
+				it.err = fferr.NewInvalidArgumentError(fmt.Errorf("could not read JSON row %d: %w", it.idx+1, err))
+			}
+			return false
+		}
+	}
+	record := make(GenericRecord, len(it.columnNames))
+	for i, column := range it.columnNames {
+		record[i] = jsonValue(row[column])
+	}
+	it.currentValues = record
+	it.idx += 1
+	return true
+}
+
+func (it *jsonIterator) Values() GenericRecord {
+	return it.currentValues
+}
+
+func (it *jsonIterator) Columns() []string {
+	return it.columnNames
+}
+
+func (it *jsonIterator) Err() error {
+	return it.err
+}
+
+func (it *jsonIterator) Close() error {
+	return it.src.Close()
+}
+
+func newJSONIterator(src io.ReadCloser, limit int64) (GenericTableIterator, error) {
+	decoder := json.NewDecoder(src)
+	decoder.UseNumber()
+	columns, firstRow, err := decodeJSONObject(decoder)
+	if err != nil {
+		src.Close()
+	}
+	if err == io.EOF {
+		return nil, fferr.NewInvalidArgumentErrorf("JSON file is empty")
+	}
+	if err != nil {
+		return nil, fferr.NewInvalidArgumentError(fmt.Errorf("could not read first JSON row: %w", err))
+	}
+	if limit == -1 {
+		limit = math.MaxInt64
+	}
+	return &jsonIterator{
+		src:         src,
+		decoder:     decoder,
+		firstRow:    firstRow,
+		columnNames: columns,
+		limit:       limit,
+	}, nil
+}
+
+// decodeJSONObject decodes the next object from decoder, returning its keys in
+// the order they appear along with its values.
+func decodeJSONObject(decoder *json.Decoder) ([]string, map[string]interface{}, error) {
+	token, err := decoder.Token()
+	if err != nil {
+		return nil, nil, err
+	}
+	if delim, ok := token.(json.Delim); !ok || delim != '{' {
+		return nil, nil, fmt.Errorf("expected a JSON object, got %v", token)
+	}
+	keys := make([]string, 0)
+	values := make(map[string]interface{})
+	for decoder.More() {
+		token, err := decoder.Token()
+		if err != nil {
+			return nil, nil, err
+		}
+		key := token.(string)
+		var value interface{}
+		if err := decoder.Decode(&value); err != nil {
+			return nil, nil, err
+		}
+		if _, exists := values[key]; !exists {
+			keys = append(keys, key)
+		}
+		values[key] = value
+	}
+	// Consume the closing brace.
+	if _, err := decoder.Token(); err != nil {
+		return nil, nil, err
+	}
+	return keys, values, nil
+}
+
+// jsonValue converts a decoded JSON number to an int when it's integral and a
+// float64 otherwise, matching how CSV values are parsed. Numbers in arrays are
+// converted as well.
+func jsonValue(value interface{}) interface{} {
+	if list, ok := value.([]interface{}); ok {
+		for i, elem := range list {
+			list[i] = jsonValue(elem)
+		}
+		return list
+	}
+	number, ok := value.(json.Number)
+	if !ok {
+		return value
+	}
+	if integer, err := strconv.Atoi(number.String()); err == nil {
+		return integer
+	}
+	if float, err := number.Float64(); err == nil {
+		return float
+	}
+	return number.String()
+}
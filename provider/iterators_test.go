@@ -282,3 +282,33 @@ func TestParseFloatVec(t *testing.T) {
 		})
 	}
 }
+
+func TestParquetIteratorLabelColumns(t *testing.T) {
+	type row struct {
+		Feature float64 `parquet:"Feature__amount__default"`
+		First   bool    `parquet:"Label__clicked__default"`
+		Second  bool    `parquet:"Label__purchased__default"`
+	}
+	buf := new(bytes.Buffer)
+	if err := parquet.Write(buf, []row{{Feature: 1.5, First: true, Second: false}}); err != nil {
+		t.Fatalf("could not write parquet: %v", err)
+	}
+	iter, err := parquetIteratorFromBytes(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatalf("could not create iterator: %v", err)
+	}
+	expected := []string{"Label__clicked__default", "Label__purchased__default"}
+	if !reflect.DeepEqual(iter.LabelColumns(), expected) {
+		t.Fatalf("expected label columns %v, got %v", expected, iter.LabelColumns())
+	}
+	if iter.LabelColumn() != expected[0] {
+		t.Fatalf("expected label column %s, got %s", expected[0], iter.LabelColumn())
+	}
+	ts := &FileStoreTrainingSet{iter: iter}
+	if !ts.Next() {
+		t.Fatalf("expected a row: %v", ts.Err())
+	}
+	if labels := ts.Labels(); !reflect.DeepEqual(labels, []interface{}{true, false}) {
+		t.Fatalf("expected labels [true false], got %v", labels)
+	}
+}
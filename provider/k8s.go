@@ -9,6 +9,7 @@ package provider
 
 import (
 	"bytes"
+	"encoding/csv"
 	"encoding/json"
 	"fmt"
 	"os"
@@ -35,6 +36,7 @@ import (
 	pl "github.com/featureform/provider/location"
 	pc "github.com/featureform/provider/provider_config"
 	ps "github.com/featureform/provider/provider_schema"
+	pt "github.com/featureform/provider/provider_type"
 	"github.com/featureform/provider/types"
 )
 
@@ -42,7 +44,8 @@ type pandasOfflineQueries struct {
 	defaultPythonOfflineQueries
 }
 
-func (q pandasOfflineQueries) trainingSetCreate(def TrainingSetDef, featureSchemas []ResourceSchema, labelSchema ResourceSchema) string {
+func (q pandasOfflineQueries) trainingSetCreate(def TrainingSetDef, featureSchemas []ResourceSchema, labelSchemas []ResourceSchema) string {
+	labelSchema := labelSchemas[0]
 	columns := make([]string, 0)
 	joinQueries := make([]string, 0)
 	featureTimestamps := make([]string, 0)
@@ -500,8 +503,8 @@ func (tbl *BlobOfflineTable) writeRecordsToParquetBytes(records []ResourceRecord
 }
 
 func (k8s *K8sOfflineStore) RegisterResourceFromSourceTable(id ResourceID, schema ResourceSchema, opts ...ResourceOption) (OfflineTable, error) {
-	if len(opts) > 0 {
-		return nil, fferr.NewInternalError(fmt.Errorf("K8s does not support resource options"))
+	if err := schema.checkUnparsed(pt.K8sOffline, opts...); err != nil {
+		return nil, err
 	}
 	return blobRegisterResourceFromSourceTable(id, schema, k8s.logger, k8s.store)
 }
@@ -524,6 +527,12 @@ func blobRegisterResourceFromSourceTable(id ResourceID, sourceSchema ResourceSch
 		logger.Errorw("Resource already exists in blob store", "id", id, "ResourceKey", destination.Key())
 		return nil, fferr.NewDatasetAlreadyExistsError(id.Name, id.Variant, fmt.Errorf("resource already exists in blob store: %s", destination.Key()))
 	}
+	if sourceLocation, isFileStoreLocation := sourceSchema.SourceTable.(*pl.FileStoreLocation); isFileStoreLocation {
+		if err := sourceSchema.checkValueColumnType(fileColumnType(store, *sourceLocation)); err != nil {
+			logger.Errorw("Value column type check failed", "source", sourceLocation.Location(), "error", err)
+			return nil, err
+		}
+	}
 	serializedSchema, err := sourceSchema.Serialize()
 	if err != nil {
 		return nil, err
@@ -535,15 +544,15 @@ func blobRegisterResourceFromSourceTable(id ResourceID, sourceSchema ResourceSch
 	return &BlobOfflineTable{schema: sourceSchema, store: store}, nil
 }
 
-func (k8s *K8sOfflineStore) RegisterPrimaryFromSourceTable(id ResourceID, tableLocation pl.Location) (PrimaryTable, error) {
+func (k8s *K8sOfflineStore) RegisterPrimaryFromSourceTable(id ResourceID, tableLocation pl.Location, opts ...PrimaryOption) (PrimaryTable, error) {
 	fileStoreLocation, isFileStoreLocation := tableLocation.(*pl.FileStoreLocation)
 	if !isFileStoreLocation {
 		return nil, fferr.NewInternalError(fmt.Errorf("location is not a FileStoreLocation"))
 	}
-	return blobRegisterPrimary(id, *fileStoreLocation, k8s.logger, k8s.store)
+	return blobRegisterPrimary(id, *fileStoreLocation, k8s.logger, k8s.store, opts...)
 }
 
-func blobRegisterPrimary(id ResourceID, location pl.FileStoreLocation, logger *zap.SugaredLogger, store FileStore) (PrimaryTable, error) {
+func blobRegisterPrimary(id ResourceID, location pl.FileStoreLocation, logger *zap.SugaredLogger, store FileStore, opts ...PrimaryOption) (PrimaryTable, error) {
 	storeExists, err := store.Exists(&location)
 	if err != nil {
 		return nil, err
@@ -568,10 +577,32 @@ func blobRegisterPrimary(id ResourceID, location pl.FileStoreLocation, logger *z
 		return nil, fferr.NewDatasetAlreadyExistsError(id.Name, id.Variant, fmt.Errorf(location.Location()))
 	}
 	logger.Debugw("Registering primary table", "id", id, "source", location.Location())
-	// TODO: determine how to handle the schema of the primary table; if it's a parquet file, we _could_
-	// read the file and infer a schema based; however, this wouldn't be possible for CSV files.
+	// TODO: determine how to handle the schema of parquet primary tables; we _could_ read the file's
+	// metadata and infer a schema, the same way DetectSchema does for CSV files.
+	if csvOpts := location.CSVOptions(); csvOpts != nil {
+		if err := csvOpts.Validate(); err != nil {
+			return nil, err
+		}
+		if !isDelimitedFile(location.Filepath(), csvOpts) {
+			return nil, fferr.NewInvalidArgumentErrorf("csv options can't be set on %s file %s", location.Filepath().Ext(), location.Location())
+		}
+	}
 	schema := TableSchema{
 		SourceTable: location.Location(),
+		CSVOptions:  location.CSVOptions(),
+	}
+	for _, opt := range opts {
+		detectOpt, ok := opt.(*DetectSchemaOption)
+		if !ok {
+			return nil, fferr.NewInternalErrorf("unsupported primary table option %s", opt.Type())
+		}
+		detected, err := DetectSchema(store, location, detectOpt.SampleSize)
+		if err != nil {
+			logger.Errorw("Could not detect primary table schema", "source", location.Location(), "error", err)
+			return nil, err
+		}
+		logger.Debugw("Detected primary table schema", "source", location.Location(), "columns", detected.Columns)
+		schema = detected
 	}
 	data, err := schema.Serialize()
 	if err != nil {
@@ -598,6 +629,9 @@ func (k8s *K8sOfflineStore) CreateTransformation(config TransformationConfig, op
 	if len(opts) > 0 {
 		return fferr.NewInternalErrorf("K8s does not support transformation options")
 	}
+	if err := ValidateTransformation(config); err != nil {
+		return err
+	}
 	return k8s.transformation(config, false)
 }
 
@@ -905,6 +939,10 @@ func (k8s *K8sOfflineStore) CreateResourceTable(id ResourceID, schema TableSchem
 	return nil, fferr.NewInternalError(fmt.Errorf("not implemented"))
 }
 
+func (k8s *K8sOfflineStore) GetResourceStats(id ResourceID, resource any) (ResourceStats, error) {
+	return fileStoreGetResourceStats(id, k8s.store, k8s.logger)
+}
+
 func (k8s *K8sOfflineStore) GetResourceTable(id ResourceID) (OfflineTable, error) {
 	return fileStoreGetResourceTable(id, k8s.store, k8s.logger)
 }
@@ -941,6 +979,85 @@ func fileStoreGetResourceTable(id ResourceID, store FileStore, logger *zap.Sugar
 	return &BlobOfflineTable{schema: resourceSchema, store: store}, nil
 }
 
+// fileStoreGetResourceStats reads the newest file group of a resource. Primaries, features and labels
+// are pointers to their source table, so the group is read from there. Only features and labels have
+// a max timestamp, which is taken from the schema's TS column.
+func fileStoreGetResourceStats(id ResourceID, store FileStore, logger *zap.SugaredLogger) (ResourceStats, error) {
+	dir, err := store.CreateFilePath(id.ToFilestorePath(), true)
+	if err != nil {
+		return ResourceStats{}, err
+	}
+	tsColumn := ""
+	switch id.Type {
+	case Primary:
+		table, err := fileStoreGetPrimary(id, store, logger)
+		if err != nil {
+			return ResourceStats{}, err
+		}
+		if dir, err = table.(*FileStorePrimaryTable).GetSource(); err != nil {
+			return ResourceStats{}, err
+		}
+	case Feature, Label:
+		table, err := fileStoreGetResourceTable(id, store, logger)
+		if err != nil {
+			return ResourceStats{}, err
+		}
+		blobTable, ok := table.(*BlobOfflineTable)
+		if !ok {
+			return ResourceStats{}, fferr.NewInternalErrorf("could not convert offline table with id %v to BlobOfflineTable", id)
+		}
+		sourcePath, ok := blobTable.schema.SourceTable.(*pl.FileStoreLocation)
+		if !ok {
+			return ResourceStats{}, fferr.NewInternalErrorf("source table is not a filestore location")
+		}
+		dir = sourcePath.Filepath()
+		tsColumn = blobTable.schema.TS
+	}
+	files := []filestore.Filepath{dir}
+	if dir.Ext() == "" {
+		outputFiles, err := listOutputFiles(store, dir)
+		if err != nil {
+			return ResourceStats{}, err
+		}
+		groups, err := filestore.NewFilePathGroup(outputFiles, filestore.DateTimeDirectoryGrouping)
+		if err != nil {
+			return ResourceStats{}, err
+		}
+		if files, err = groups.GetFirst(); err != nil {
+			return ResourceStats{}, err
+		}
+	}
+	logger.Debugw("Getting resource stats", "id", id, "files", len(files), "tsColumn", tsColumn)
+	stats := ResourceStats{}
+	for _, file := range files {
+		n, err := store.NumRows(file)
+		if err != nil {
+			return ResourceStats{}, err
+		}
+		stats.RowCount += n
+	}
+	if tsColumn == "" || stats.RowCount == 0 {
+		return stats, nil
+	}
+	iter, err := store.Serve(files)
+	if err != nil {
+		return ResourceStats{}, err
+	}
+	for {
+		row, err := iter.Next()
+		if err != nil {
+			return ResourceStats{}, err
+		}
+		if row == nil {
+			break
+		}
+		if ts, ok := row[tsColumn].(time.Time); ok && ts.After(stats.MaxTimestamp) {
+			stats.MaxTimestamp = ts.UTC()
+		}
+	}
+	return stats, nil
+}
+
 // **NOTE:** This is a temporary fix for GCS. The primary source table path is stored in the resource schema,
 // which will contain only a directory path for resources other than a primary source. For example, or a Label that was
 // created from a primary source, the value for `SourceTable` on the label schema will be:
@@ -976,6 +1093,10 @@ func (store *K8sOfflineStore) SupportsMaterializationOption(opt MaterializationO
 	return false, nil
 }
 
+func (store *K8sOfflineStore) Capabilities() (Capabilities, error) {
+	return offlineStoreCapabilities(store)
+}
+
 func (k8s *K8sOfflineStore) GetMaterialization(id MaterializationID) (Materialization, error) {
 	return fileStoreGetMaterialization(id, k8s.store, k8s.logger)
 }
@@ -1020,27 +1141,30 @@ func (mat FileStoreMaterialization) ID() MaterializationID {
 }
 
 func (mat FileStoreMaterialization) NumRows() (int64, error) {
-	resourceKey := ps.ResourceToDirectoryPath(mat.id.Type.String(), mat.id.Name, mat.id.Variant)
-	materializationFilepath, err := mat.store.CreateFilePath(resourceKey, false)
+	newestFiles, err := mat.newestFiles()
 	if err != nil {
 		return 0, err
 	}
-	// TODO(simba) THIS IS INCORRECT!
-	latestMaterializationPath, err := mat.store.NewestFileOfType(materializationFilepath, filestore.Parquet)
-	if err != nil {
-		return 0, err
+	rows := int64(0)
+	for _, file := range newestFiles {
+		fileRows, err := mat.store.NumRows(file)
+		if err != nil {
+			return 0, err
+		}
+		rows += fileRows
 	}
-	// TODO: convert NumRows to accept a list of files to get a total count
-	return mat.store.NumRows(latestMaterializationPath)
+	return rows, nil
 }
 
-func (mat FileStoreMaterialization) IterateSegment(begin, end int64) (FeatureIterator, error) {
+// newestFiles returns the output files of the materialization's newest run,
+// whichever file type they were written in.
+func (mat FileStoreMaterialization) newestFiles() ([]filestore.Filepath, error) {
 	resourceKey := ps.ResourceToDirectoryPath(mat.id.Type.String(), mat.id.Name, mat.id.Variant)
 	searchPath, err := mat.store.CreateFilePath(resourceKey, false)
 	if err != nil {
 		return nil, err
 	}
-	files, err := mat.store.List(searchPath, filestore.Parquet)
+	files, err := listOutputFiles(mat.store, searchPath)
 	if err != nil {
 		return nil, err
 	}
@@ -1048,7 +1172,11 @@ func (mat FileStoreMaterialization) IterateSegment(begin, end int64) (FeatureIte
 	if err != nil {
 		return nil, err
 	}
-	newestFiles, err := groups.GetFirst()
+	return groups.GetFirst()
+}
+
+func (mat FileStoreMaterialization) IterateSegment(begin, end int64) (FeatureIterator, error) {
+	newestFiles, err := mat.newestFiles()
 	if err != nil {
 		return nil, err
 	}
@@ -1069,19 +1197,7 @@ func (mat FileStoreMaterialization) IterateSegment(begin, end int64) (FeatureIte
 }
 
 func (mat FileStoreMaterialization) NumChunks() (int, error) {
-	searchPath, err := mat.store.CreateFilePath(ps.ResourceToDirectoryPath(mat.id.Type.String(), mat.id.Name, mat.id.Variant), false)
-	if err != nil {
-		return -1, err
-	}
-	files, err := mat.store.List(searchPath, filestore.Parquet)
-	if err != nil {
-		return -1, err
-	}
-	groups, err := filestore.NewFilePathGroup(files, filestore.DateTimeDirectoryGrouping)
-	if err != nil {
-		return -1, err
-	}
-	newestFiles, err := groups.GetFirst()
+	newestFiles, err := mat.newestFiles()
 	if err != nil {
 		return -1, err
 	}
@@ -1089,19 +1205,7 @@ func (mat FileStoreMaterialization) NumChunks() (int, error) {
 }
 
 func (mat FileStoreMaterialization) IterateChunk(idx int) (FeatureIterator, error) {
-	searchPath, err := mat.store.CreateFilePath(ps.ResourceToDirectoryPath(mat.id.Type.String(), mat.id.Name, mat.id.Variant), false)
-	if err != nil {
-		return nil, err
-	}
-	files, err := mat.store.List(searchPath, filestore.Parquet)
-	if err != nil {
-		return nil, err
-	}
-	groups, err := filestore.NewFilePathGroup(files, filestore.DateTimeDirectoryGrouping)
-	if err != nil {
-		return nil, err
-	}
-	newestFiles, err := groups.GetFirst()
+	newestFiles, err := mat.newestFiles()
 	if err != nil {
 		return nil, err
 	}
@@ -1123,6 +1227,92 @@ func (mat FileStoreMaterialization) Location() pl.Location {
 	return nil
 }
 
+// materializationWatermarkFile sits next to a file store materialization's
+// output directories and holds the latest timestamp it has materialized.
+const materializationWatermarkFile = "watermark"
+
+func (mat FileStoreMaterialization) watermarkPath() (filestore.Filepath, error) {
+	resourceKey := ps.ResourceToDirectoryPath(mat.id.Type.String(), mat.id.Name, mat.id.Variant)
+	return mat.store.CreateFilePath(fmt.Sprintf("%s/%s", resourceKey, materializationWatermarkFile), false)
+}
+
+// Watermark returns the stored watermark of the materialization. If none has been
+// stored yet, it falls back to the latest timestamp in the newest output, which is
+// exact for a full materialization.
+func (mat FileStoreMaterialization) Watermark() (time.Time, error) {
+	path, err := mat.watermarkPath()
+	if err != nil {
+		return time.Time{}, err
+	}
+	exists, err := mat.store.Exists(pl.NewFileLocation(path))
+	if err != nil {
+		return time.Time{}, err
+	}
+	if !exists {
+		return mat.maxTimestamp()
+	}
+	data, err := mat.store.Read(path)
+	if err != nil {
+		return time.Time{}, err
+	}
+	watermark, err := time.Parse(time.RFC3339Nano, string(data))
+	if err != nil {
+		wrapped := fferr.NewInternalErrorf("could not parse materialization watermark: %v", err)
+		wrapped.AddDetail("path", path.ToURI())
+		return time.Time{}, wrapped
+	}
+	return watermark, nil
+}
+
+// SetWatermark stores the watermark of the materialization.
+func (mat FileStoreMaterialization) SetWatermark(watermark time.Time) error {
+	path, err := mat.watermarkPath()
+	if err != nil {
+		return err
+	}
+	return mat.store.Write(path, []byte(watermark.UTC().Format(time.RFC3339Nano)))
+}
+
+// ClearWatermark removes a stored watermark, if any, so that it's recomputed from
+// the newest output on the next incremental run.
+func (mat FileStoreMaterialization) ClearWatermark() error {
+	path, err := mat.watermarkPath()
+	if err != nil {
+		return err
+	}
+	exists, err := mat.store.Exists(pl.NewFileLocation(path))
+	if err != nil || !exists {
+		return err
+	}
+	return mat.store.Delete(path)
+}
+
+func (mat FileStoreMaterialization) maxTimestamp() (time.Time, error) {
+	numChunks, err := mat.NumChunks()
+	if err != nil {
+		return time.Time{}, err
+	}
+	var maxTS time.Time
+	for i := 0; i < numChunks; i++ {
+		iter, err := mat.IterateChunk(i)
+		if err != nil {
+			return time.Time{}, err
+		}
+		for iter.Next() {
+			if ts := iter.Value().TS; ts.After(maxTS) {
+				maxTS = ts
+			}
+		}
+		if err := iter.Err(); err != nil {
+			return time.Time{}, err
+		}
+		if err := iter.Close(); err != nil {
+			return time.Time{}, err
+		}
+	}
+	return maxTS, nil
+}
+
 type FileStoreFeatureIterator struct {
 	iter   Iterator
 	err    error
@@ -1193,6 +1383,9 @@ func (iter *FileStoreFeatureIterator) Close() error {
 }
 
 func (k8s *K8sOfflineStore) UpdateMaterialization(id ResourceID, opts MaterializationOptions) (Materialization, error) {
+	if opts.Incremental {
+		return nil, fferr.NewUnimplementedErrorf("incremental materialization is not supported for K8s")
+	}
 	return k8s.materialization(id, true)
 }
 
@@ -1235,7 +1428,7 @@ func (k8s *K8sOfflineStore) materialization(id ResourceID, isUpdate bool) (Mater
 		k8s.logger.Errorw("Attempted to update a materialization that does not exist", "id", id)
 		return nil, fferr.NewDatasetNotFoundError(id.Name, id.Variant, fmt.Errorf(destinationPath.ToURI()))
 	}
-	materializationQuery, err := k8s.query.materializationCreate(k8sResourceTable.schema)
+	materializationQuery, err := k8s.query.materializationCreate(k8sResourceTable.schema, "")
 	if err != nil {
 		return nil, err
 	}
@@ -1316,6 +1509,12 @@ func (k8s *K8sOfflineStore) trainingSet(def TrainingSetDef, isUpdate bool) error
 		k8s.logger.Errorw("Training set definition not valid", def, err)
 		return err
 	}
+	if err := def.checkSingleLabel(pt.K8sOffline); err != nil {
+		return err
+	}
+	if err := def.checkNotPersisted(pt.K8sOffline); err != nil {
+		return err
+	}
 	sourcePaths := make([]string, 0)
 	featureSchemas := make([]ResourceSchema, 0)
 	resourceKey := ps.ResourceToDirectoryPath(def.ID.Type.String(), def.ID.Name, def.ID.Variant)
@@ -1379,7 +1578,7 @@ func (k8s *K8sOfflineStore) trainingSet(def TrainingSetDef, isUpdate bool) error
 		sourcePaths = append(sourcePaths, featurePath.Filepath().ToURI())
 		featureSchemas = append(featureSchemas, featureSchema)
 	}
-	trainingSetQuery := k8s.query.trainingSetCreate(def, featureSchemas, labelSchema)
+	trainingSetQuery := k8s.query.trainingSetCreate(def, featureSchemas, []ResourceSchema{labelSchema})
 	k8s.logger.Debugw("Source List", "SourceFiles", sourcePaths)
 	k8s.logger.Debugw("Training Set Query", "list", trainingSetQuery)
 	pandasArgs := k8s.pandasRunnerArgs(destinationPath.ToURI(), trainingSetQuery, sourcePaths, types.CreateTrainingSet)
@@ -1398,6 +1597,10 @@ func (k8s *K8sOfflineStore) GetTrainingSet(id ResourceID) (TrainingSetIterator,
 	return fileStoreGetTrainingSet(id, k8s.store, k8s.logger)
 }
 
+func (k8s *K8sOfflineStore) GetTrainingSetSplit(id ResourceID, split string) (TrainingSetIterator, error) {
+	return fileStoreGetTrainingSetSplit(id, split, k8s.store, k8s.logger)
+}
+
 func (k8s *K8sOfflineStore) CreateTrainTestSplit(def TrainTestSplitDef) (func() error, error) {
 	return nil, fmt.Errorf("not Implemented")
 }
@@ -1406,6 +1609,10 @@ func (k8s *K8sOfflineStore) GetTrainTestSplit(def TrainTestSplitDef) (TrainingSe
 	return nil, nil, fmt.Errorf("not Implemented")
 }
 
+func (k8s *K8sOfflineStore) ExportTrainingSet(id ResourceID, location pl.Location, format filestore.FileType) ([]filestore.Filepath, error) {
+	return fileStoreExportTrainingSet(id, k8s.store, location, format, k8s.logger)
+}
+
 func (k8s *K8sOfflineStore) CheckHealth() (bool, error) {
 	return false, fferr.NewInternalError(fmt.Errorf("provider health check not implemented"))
 }
@@ -1415,6 +1622,35 @@ func (k8s K8sOfflineStore) Delete(location pl.Location) error {
 }
 
 func fileStoreGetTrainingSet(id ResourceID, store FileStore, logger *zap.SugaredLogger) (TrainingSetIterator, error) {
+	newestFiles, err := fileStoreTrainingSetFiles(id, store, logger)
+	if err != nil {
+		return nil, err
+	}
+	iterator, err := store.Serve(newestFiles)
+	if err != nil {
+		return nil, err
+	}
+	return &FileStoreTrainingSet{id: id, store: store, iter: iterator}, nil
+}
+
+func fileStoreGetTrainingSetSplit(id ResourceID, split string, store FileStore, logger *zap.SugaredLogger) (TrainingSetIterator, error) {
+	if err := checkTrainingSetSplitName(split); err != nil {
+		return nil, err
+	}
+	newestFiles, err := fileStoreTrainingSetFiles(id, store, logger)
+	if err != nil {
+		return nil, err
+	}
+	iterator, err := store.Serve(newestFiles)
+	if err != nil {
+		return nil, err
+	}
+	return &FileStoreTrainingSet{id: id, store: store, iter: iterator, split: split}, nil
+}
+
+// fileStoreTrainingSetFiles returns the parquet files that make up the most recent
+// run of the training set.
+func fileStoreTrainingSetFiles(id ResourceID, store FileStore, logger *zap.SugaredLogger) ([]filestore.Filepath, error) {
 	if err := id.check(TrainingSet); err != nil {
 		logger.Errorw("Resource is not of type training set", "error", err)
 		return nil, fmt.Errorf("resource is not training set: %w", err)
@@ -1440,28 +1676,149 @@ func fileStoreGetTrainingSet(id ResourceID, store FileStore, logger *zap.Sugared
 	if err != nil {
 		return nil, err
 	}
-	newestFiles, err := groups.GetFirst()
+	return groups.GetFirst()
+}
+
+// trainingSetExportCSVRows is the maximum number of rows written to a single CSV
+// file when exporting a training set.
+const trainingSetExportCSVRows = 1_000_000
+
+// fileStoreExportTrainingSet copies the newest training set files into the directory
+// referenced by location. Parquet exports are a straight copy of the existing files;
+// CSV exports are streamed through the training set iterator and split into parts.
+func fileStoreExportTrainingSet(id ResourceID, store FileStore, location pl.Location, format filestore.FileType, logger *zap.SugaredLogger) ([]filestore.Filepath, error) {
+	fileLocation, ok := location.(*pl.FileStoreLocation)
+	if !ok {
+		return nil, fferr.NewInvalidArgumentErrorf("training set export requires a filestore location, got %T", location)
+	}
+	destination := fileLocation.Filepath()
+	sourceFiles, err := fileStoreTrainingSetFiles(id, store, logger)
 	if err != nil {
 		return nil, err
 	}
-	iterator, err := store.Serve(newestFiles)
+	logger.Infow("Exporting training set", "name", id.Name, "variant", id.Variant, "destination", destination.ToURI(), "format", format)
+	switch format {
+	case filestore.Parquet:
+		return copyTrainingSetParquet(store, sourceFiles, destination)
+	case filestore.CSV:
+		return writeTrainingSetCSV(store, sourceFiles, destination, trainingSetExportCSVRows)
+	default:
+		return nil, fferr.NewInvalidArgumentErrorf("unsupported training set export format: %s", format)
+	}
+}
+
+func exportFilepath(destination filestore.Filepath, part int, format filestore.FileType) (filestore.Filepath, error) {
+	path := destination.Clone()
+	// Not every filestore marks the paths it creates as directories, so treat the
+	// destination as one regardless.
+	path.SetIsDir(true)
+	if err := path.AppendPathString(fmt.Sprintf("part-%05d.%s", part, format), false); err != nil {
+		return nil, err
+	}
+	path.SetIsDir(false)
+	return path, nil
+}
+
+func copyTrainingSetParquet(store FileStore, sourceFiles []filestore.Filepath, destination filestore.Filepath) ([]filestore.Filepath, error) {
+	written := make([]filestore.Filepath, 0, len(sourceFiles))
+	for i, source := range sourceFiles {
+		data, err := store.Read(source)
+		if err != nil {
+			return nil, err
+		}
+		target, err := exportFilepath(destination, i, filestore.Parquet)
+		if err != nil {
+			return nil, err
+		}
+		if err := store.Write(target, data); err != nil {
+			return nil, err
+		}
+		written = append(written, target)
+	}
+	return written, nil
+}
+
+func writeTrainingSetCSV(store FileStore, sourceFiles []filestore.Filepath, destination filestore.Filepath, rowsPerFile int) ([]filestore.Filepath, error) {
+	iter, err := store.Serve(sourceFiles)
 	if err != nil {
 		return nil, err
 	}
-	return &FileStoreTrainingSet{id: id, store: store, iter: iterator}, nil
+	header := append(append([]string{}, iter.FeatureColumns()...), iter.LabelColumns()...)
+	written := make([]filestore.Filepath, 0)
+	var buf bytes.Buffer
+	var writer *csv.Writer
+	rows := 0
+	flush := func() error {
+		writer.Flush()
+		if err := writer.Error(); err != nil {
+			return fferr.NewInternalError(err)
+		}
+		target, err := exportFilepath(destination, len(written), filestore.CSV)
+		if err != nil {
+			return err
+		}
+		if err := store.Write(target, buf.Bytes()); err != nil {
+			return err
+		}
+		written = append(written, target)
+		buf.Reset()
+		writer = nil
+		rows = 0
+		return nil
+	}
+	record := make([]string, len(header))
+	for {
+		row, err := iter.Next()
+		if err != nil {
+			return nil, err
+		}
+		if row == nil {
+			break
+		}
+		if writer == nil {
+			writer = csv.NewWriter(&buf)
+			if err := writer.Write(header); err != nil {
+				return nil, fferr.NewInternalError(err)
+			}
+		}
+		for i, col := range header {
+			if val := row[col]; val != nil {
+				record[i] = fmt.Sprint(val)
+			} else {
+				record[i] = ""
+			}
+		}
+		if err := writer.Write(record); err != nil {
+			return nil, fferr.NewInternalError(err)
+		}
+		rows++
+		if rows == rowsPerFile {
+			if err := flush(); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if writer != nil {
+		if err := flush(); err != nil {
+			return nil, err
+		}
+	}
+	return written, nil
 }
 
 type FileStoreTrainingSet struct {
-	id       ResourceID
-	store    FileStore
-	iter     Iterator
+	id    ResourceID
+	store FileStore
+	iter  Iterator
+	// split, if set, skips rows that weren't assigned to it.
+	split    string
 	Error    error
 	features []interface{}
-	label    interface{}
+	labels   []interface{}
 }
 
 func (ts *FileStoreTrainingSet) Next() bool {
-	row, err := ts.iter.Next()
+	row, err := ts.nextRow()
 	if err != nil {
 		ts.Error = err
 		return false
@@ -1474,16 +1831,43 @@ func (ts *FileStoreTrainingSet) Next() bool {
 		featureValues[i] = row[key]
 	}
 	ts.features = featureValues
-	ts.label = row[ts.iter.LabelColumn()]
+	labelValues := make([]interface{}, len(ts.iter.LabelColumns()))
+	for i, key := range ts.iter.LabelColumns() {
+		labelValues[i] = row[key]
+	}
+	ts.labels = labelValues
 	return true
 }
 
+func (ts *FileStoreTrainingSet) nextRow() (map[string]interface{}, error) {
+	for {
+		row, err := ts.iter.Next()
+		if err != nil || row == nil || ts.split == "" {
+			return row, err
+		}
+		rowSplit, has := row[trainingSetSplitColumn]
+		if !has {
+			return nil, fferr.NewInvalidArgumentErrorf("training set %s (%s) was not created with a split", ts.id.Name, ts.id.Variant)
+		}
+		if rowSplit == ts.split {
+			return row, nil
+		}
+	}
+}
+
 func (ts *FileStoreTrainingSet) Features() []interface{} {
 	return ts.features
 }
 
 func (ts *FileStoreTrainingSet) Label() interface{} {
-	return ts.label
+	if len(ts.labels) == 0 {
+		return nil
+	}
+	return ts.labels[0]
+}
+
+func (ts *FileStoreTrainingSet) Labels() []interface{} {
+	return ts.labels
 }
 
 func (ts *FileStoreTrainingSet) Err() error {
@@ -1497,6 +1881,7 @@ type FileStoreBatchServing struct {
 	Error       error
 	entity      interface{}
 	features    []interface{}
+	asOf        time.Time
 }
 
 func (ts *FileStoreBatchServing) Next() bool {
@@ -1514,9 +1899,20 @@ func (ts *FileStoreBatchServing) Next() bool {
 	}
 	ts.features = featureValues
 	ts.entity = row["entity"]
+	// Rows served as of a timestamp hold it in milliseconds since the epoch.
+	switch asOf := row["as_of"].(type) {
+	case int:
+		ts.asOf = time.UnixMilli(int64(asOf)).UTC()
+	case time.Time:
+		ts.asOf = asOf
+	}
 	return true
 }
 
+func (ts *FileStoreBatchServing) AsOf() time.Time {
+	return ts.asOf
+}
+
 func (ts *FileStoreBatchServing) Features() GenericRecord {
 	return ts.features
 }
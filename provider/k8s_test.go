@@ -26,6 +26,7 @@ import (
 	"github.com/featureform/metadata"
 	pl "github.com/featureform/provider/location"
 	pc "github.com/featureform/provider/provider_config"
+	ps "github.com/featureform/provider/provider_schema"
 	"github.com/featureform/provider/types"
 
 	"github.com/google/uuid"
@@ -1194,3 +1195,96 @@ func TestFileStoreFeatureIterator(t *testing.T) {
 		})
 	}
 }
+
+func TestFileStoreExportTrainingSet(t *testing.T) {
+	type RowType struct {
+		Feature__f1__v1 string
+		Label__field    string
+	}
+	logger := zaptest.NewLogger(t).Sugar()
+	store, err := NewLocalFileStore([]byte(fmt.Sprintf(`{"DirPath": "file://%s/"}`, t.TempDir())))
+	if err != nil {
+		t.Fatalf("could not create local file store: %v", err)
+	}
+	id := ResourceID{Name: "ts", Variant: "v", Type: TrainingSet}
+	key := fmt.Sprintf("%s/2024-01-01-00-00-00-000000/part-00000.parquet", ps.ResourceToDirectoryPath(id.Type.String(), id.Name, id.Variant))
+	source, err := store.CreateFilePath(key, false)
+	if err != nil {
+		t.Fatalf("could not create source path: %v", err)
+	}
+	var buf bytes.Buffer
+	w := parquet.NewWriter(&buf)
+	for i := 0; i < 3; i++ {
+		if err := w.Write(RowType{fmt.Sprintf("feat %d", i), fmt.Sprintf("label %d", i)}); err != nil {
+			t.Fatalf("could not write row: %v", err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("could not close writer: %v", err)
+	}
+	if err := store.Write(source, buf.Bytes()); err != nil {
+		t.Fatalf("could not write training set: %v", err)
+	}
+
+	t.Run("Parquet", func(t *testing.T) {
+		dest, err := store.CreateFilePath("exports/parquet", true)
+		if err != nil {
+			t.Fatalf("could not create destination: %v", err)
+		}
+		files, err := fileStoreExportTrainingSet(id, store, pl.NewFileLocation(dest), filestore.Parquet, logger)
+		if err != nil {
+			t.Fatalf("could not export training set: %v", err)
+		}
+		if len(files) != 1 {
+			t.Fatalf("expected 1 exported file, got %d", len(files))
+		}
+		data, err := store.Read(files[0])
+		if err != nil {
+			t.Fatalf("could not read exported file: %v", err)
+		}
+		if !bytes.Equal(data, buf.Bytes()) {
+			t.Fatalf("exported parquet file does not match the training set")
+		}
+	})
+
+	t.Run("CSV", func(t *testing.T) {
+		dest, err := store.CreateFilePath("exports/csv", true)
+		if err != nil {
+			t.Fatalf("could not create destination: %v", err)
+		}
+		sourceFiles, err := fileStoreTrainingSetFiles(id, store, logger)
+		if err != nil {
+			t.Fatalf("could not list training set files: %v", err)
+		}
+		files, err := writeTrainingSetCSV(store, sourceFiles, dest, 2)
+		if err != nil {
+			t.Fatalf("could not export training set: %v", err)
+		}
+		if len(files) != 2 {
+			t.Fatalf("expected 2 exported files, got %d", len(files))
+		}
+		expected := []string{
+			"Feature__f1__v1,Label__field\nfeat 0,label 0\nfeat 1,label 1\n",
+			"Feature__f1__v1,Label__field\nfeat 2,label 2\n",
+		}
+		for i, file := range files {
+			data, err := store.Read(file)
+			if err != nil {
+				t.Fatalf("could not read exported file: %v", err)
+			}
+			if string(data) != expected[i] {
+				t.Fatalf("file %d: expected %q, got %q", i, expected[i], string(data))
+			}
+		}
+	})
+
+	t.Run("InvalidFormat", func(t *testing.T) {
+		dest, err := store.CreateFilePath("exports/db", true)
+		if err != nil {
+			t.Fatalf("could not create destination: %v", err)
+		}
+		if _, err := fileStoreExportTrainingSet(id, store, pl.NewFileLocation(dest), filestore.DB, logger); err == nil {
+			t.Fatalf("expected an error for an unsupported export format")
+		}
+	})
+}
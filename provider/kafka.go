@@ -0,0 +1,433 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+//
+// Copyright 2024 FeatureForm Inc.
+//
+
+package provider
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/linkedin/goavro/v2"
+	"github.com/segmentio/kafka-go"
+	"github.com/segmentio/kafka-go/sasl/plain"
+
+	"github.com/featureform/fferr"
+	pc "github.com/featureform/provider/provider_config"
+	pt "github.com/featureform/provider/provider_type"
+	vt "github.com/featureform/provider/types"
+)
+
+const kafkaDialTimeout = 10 * time.Second
+
+func kafkaStreamFactory(config pc.SerializedConfig) (Provider, error) {
+	kafkaConfig := &pc.KafkaConfig{}
+	if err := kafkaConfig.Deserialize(config); err != nil {
+		return nil, err
+	}
+	if err := kafkaConfig.Validate(); err != nil {
+		return nil, err
+	}
+	return NewKafkaStream(kafkaConfig)
+}
+
+// KafkaStream is a Kafka cluster that streaming sources read topics from. It's
+// neither an offline nor an online store; runners read from it directly.
+type KafkaStream struct {
+	BaseProvider
+	config  *pc.KafkaConfig
+	dialer  *kafka.Dialer
+	decoder KafkaDecoder
+}
+
+func NewKafkaStream(config *pc.KafkaConfig) (*KafkaStream, error) {
+	dialer := &kafka.Dialer{Timeout: kafkaDialTimeout, DualStack: true}
+	if config.TLS {
+		dialer.TLS = &tls.Config{}
+	}
+	if config.Username != "" {
+		dialer.SASLMechanism = plain.Mechanism{Username: config.Username, Password: config.Password}
+	}
+	var decoder KafkaDecoder
+	switch config.Format {
+	case pc.KafkaAvro:
+		decoder = NewKafkaAvroDecoder(config.SchemaRegistryURL, config.SchemaRegistryUsername, config.SchemaRegistryPassword)
+	default:
+		decoder = kafkaJSONDecoder{}
+	}
+	return &KafkaStream{
+		BaseProvider: BaseProvider{
+			ProviderType:   pt.Kafka,
+			ProviderConfig: config.Serialize(),
+		},
+		config:  config,
+		dialer:  dialer,
+		decoder: decoder,
+	}, nil
+}
+
+func (k *KafkaStream) CheckHealth() (bool, error) {
+	conn, err := k.dial(context.Background())
+	if err != nil {
+		return false, err
+	}
+	defer conn.Close()
+	if _, err := conn.Brokers(); err != nil {
+		return false, fferr.NewConnectionError(pt.Kafka.String(), err)
+	}
+	return true, nil
+}
+
+// CheckTopic returns a DatasetNotFoundError if the topic doesn't exist.
+func (k *KafkaStream) CheckTopic(ctx context.Context, topic string) error {
+	conn, err := k.dial(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	partitions, err := conn.ReadPartitions(topic)
+	if errors.Is(err, kafka.UnknownTopicOrPartition) || (err == nil && len(partitions) == 0) {
+		wrapped := fferr.NewDatasetNotFoundError(topic, "", err)
+		wrapped.AddDetail("provider", pt.Kafka.String())
+		return wrapped
+	}
+	if err != nil {
+		wrapped := fferr.NewExecutionError(pt.Kafka.String(), err)
+		wrapped.AddDetail("topic", topic)
+		return wrapped
+	}
+	return nil
+}
+
+func (k *KafkaStream) dial(ctx context.Context) (*kafka.Conn, error) {
+	var errs []error
+	for _, broker := range k.config.Brokers {
+		conn, err := k.dialer.DialContext(ctx, "tcp", broker)
+		if err == nil {
+			return conn, nil
+		}
+		errs = append(errs, err)
+	}
+	wrapped := fferr.NewConnectionError(pt.Kafka.String(), errors.Join(errs...))
+	wrapped.AddDetail("brokers", strings.Join(k.config.Brokers, ","))
+	return nil, wrapped
+}
+
+// NewReader returns a reader of the topic in the consumer group. Offsets are only
+// committed by the reader's CommitMessages, and a group with no committed offsets
+// starts at the oldest message in the topic.
+func (k *KafkaStream) NewReader(topic, groupID string) KafkaReader {
+	return kafka.NewReader(kafka.ReaderConfig{
+		Brokers:     k.config.Brokers,
+		GroupID:     groupID,
+		Topic:       topic,
+		Dialer:      k.dialer,
+		StartOffset: kafka.FirstOffset,
+	})
+}
+
+// Decoder returns the decoder of the format of the cluster's messages.
+func (k *KafkaStream) Decoder() KafkaDecoder {
+	return k.decoder
+}
+
+// RawEventsStore returns the file store that consumed events are written to, or
+// nil if they aren't written anywhere.
+func (k *KafkaStream) RawEventsStore() (FileStore, error) {
+	if k.config.RawEventsStoreType == "" {
+		return nil, nil
+	}
+	return CreateFileStore(string(k.config.RawEventsStoreType), Config(k.config.RawEventsStoreConfig))
+}
+
+// RawEventsPath is the path in the raw events store that events are written to.
+func (k *KafkaStream) RawEventsPath() string {
+	return k.config.RawEventsPath
+}
+
+// KafkaReader reads and commits the messages of a topic. It's implemented by
+// kafka.Reader.
+type KafkaReader interface {
+	FetchMessage(ctx context.Context) (kafka.Message, error)
+	CommitMessages(ctx context.Context, msgs ...kafka.Message) error
+	Close() error
+}
+
+// KafkaDecoder decodes the value of a message into a record of its fields. JSON
+// numbers are decoded as json.Numbers so that integers aren't truncated, and Avro
+// values are decoded to the Go types of their Avro types.
+type KafkaDecoder interface {
+	Decode(value []byte) (map[string]interface{}, error)
+}
+
+type kafkaJSONDecoder struct{}
+
+func (kafkaJSONDecoder) Decode(value []byte) (map[string]interface{}, error) {
+	return decodeKafkaJSON(value)
+}
+
+func decodeKafkaJSON(value []byte) (map[string]interface{}, error) {
+	decoder := json.NewDecoder(bytes.NewReader(value))
+	decoder.UseNumber()
+	record := map[string]interface{}{}
+	if err := decoder.Decode(&record); err != nil {
+		return nil, fferr.NewParsingError(err)
+	}
+	return record, nil
+}
+
+// kafkaAvroMagicByte starts every message in the Confluent wire format. It's
+// followed by the 4 byte ID of the message's schema in the schema registry.
+const kafkaAvroMagicByte = 0
+
+type kafkaAvroDecoder struct {
+	registryURL string
+	username    string
+	password    string
+	client      *http.Client
+	mu          sync.Mutex
+	codecs      map[uint32]*kafkaAvroCodec
+}
+
+// kafkaAvroCodec decodes the messages written with a registered schema.
+type kafkaAvroCodec struct {
+	codec  *goavro.Codec
+	schema *avroSchema
+}
+
+func NewKafkaAvroDecoder(registryURL, username, password string) KafkaDecoder {
+	return &kafkaAvroDecoder{
+		registryURL: strings.TrimSuffix(registryURL, "/"),
+		username:    username,
+		password:    password,
+		client:      &http.Client{Timeout: kafkaDialTimeout},
+		codecs:      map[uint32]*kafkaAvroCodec{},
+	}
+}
+
+func (d *kafkaAvroDecoder) Decode(value []byte) (map[string]interface{}, error) {
+	if len(value) < 5 || value[0] != kafkaAvroMagicByte {
+		return nil, fferr.NewParsingError(fmt.Errorf("message isn't in the Confluent Avro wire format"))
+	}
+	codec, err := d.codec(binary.BigEndian.Uint32(value[1:5]))
+	if err != nil {
+		return nil, err
+	}
+	decoded, _, err := codec.codec.NativeFromBinary(value[5:])
+	if err != nil {
+		return nil, fferr.NewParsingError(err)
+	}
+	record, ok := codec.schema.native(decoded).(map[string]interface{})
+	if !ok {
+		return nil, fferr.NewParsingError(fmt.Errorf("message's schema isn't an Avro record"))
+	}
+	return record, nil
+}
+
+func (d *kafkaAvroDecoder) codec(id uint32) (*kafkaAvroCodec, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if codec, has := d.codecs[id]; has {
+		return codec, nil
+	}
+	req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("%s/schemas/ids/%d", d.registryURL, id), nil)
+	if err != nil {
+		return nil, fferr.NewInternalError(err)
+	}
+	if d.username != "" {
+		req.SetBasicAuth(d.username, d.password)
+	}
+	resp, err := d.client.Do(req)
+	if err != nil {
+		wrapped := fferr.NewConnectionError(pt.Kafka.String(), err)
+		wrapped.AddDetail("schema_registry_url", d.registryURL)
+		return nil, wrapped
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fferr.NewConnectionError(pt.Kafka.String(), err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		wrapped := fferr.NewExecutionError(pt.Kafka.String(), fmt.Errorf("schema registry returned %s: %s", resp.Status, body))
+		wrapped.AddDetail("schema_id", strconv.FormatUint(uint64(id), 10))
+		return nil, wrapped
+	}
+	var registered struct {
+		Schema string `json:"schema"`
+	}
+	if err := json.Unmarshal(body, &registered); err != nil {
+		return nil, fferr.NewParsingError(err)
+	}
+	codec, err := goavro.NewCodec(registered.Schema)
+	if err != nil {
+		wrapped := fferr.NewParsingError(err)
+		wrapped.AddDetail("schema_id", strconv.FormatUint(uint64(id), 10))
+		return nil, wrapped
+	}
+	schema, err := newAvroSchema(codec)
+	if err != nil {
+		return nil, err
+	}
+	d.codecs[id] = &kafkaAvroCodec{codec: codec, schema: schema}
+	return d.codecs[id], nil
+}
+
+// CastKafkaValue casts a value of a decoded record to the Go type of the value type.
+func CastKafkaValue(value interface{}, valueType vt.ValueType) (interface{}, error) {
+	if value == nil {
+		return nil, nil
+	}
+	if valueType.IsVector() {
+		return castKafkaVector(value)
+	}
+	switch valueType {
+	case vt.Int, vt.Int32, vt.Int64:
+		i, err := castKafkaInt(value)
+		if err != nil {
+			return nil, fferr.NewTypeError(valueType.String(), value, err)
+		}
+		switch valueType {
+		case vt.Int32:
+			return int32(i), nil
+		case vt.Int64:
+			return i, nil
+		default:
+			return int(i), nil
+		}
+	case vt.Float32, vt.Float64:
+		f, err := castKafkaFloat(value)
+		if err != nil {
+			return nil, fferr.NewTypeError(valueType.String(), value, err)
+		}
+		if valueType == vt.Float32 {
+			return float32(f), nil
+		}
+		return f, nil
+	case vt.Bool:
+		switch v := value.(type) {
+		case bool:
+			return v, nil
+		case string:
+			b, err := strconv.ParseBool(v)
+			if err != nil {
+				return nil, fferr.NewTypeError(valueType.String(), value, err)
+			}
+			return b, nil
+		}
+	case vt.String:
+		switch v := value.(type) {
+		case string:
+			return v, nil
+		case []byte:
+			return string(v), nil
+		}
+		return fmt.Sprint(value), nil
+	case vt.JSON:
+		switch v := value.(type) {
+		case string:
+			return v, nil
+		case []byte:
+			return string(v), nil
+		}
+		serialized, err := json.Marshal(value)
+		if err != nil {
+			return nil, fferr.NewTypeError(valueType.String(), value, err)
+		}
+		return string(serialized), nil
+	case vt.Timestamp, vt.Datetime:
+		return CastKafkaTimestamp(value)
+	}
+	return nil, fferr.NewTypeErrorf(valueType.String(), value, "cannot cast %T", value)
+}
+
+// CastKafkaTimestamp casts an RFC 3339 string or a number of milliseconds since
+// the epoch to a time.
+func CastKafkaTimestamp(value interface{}) (time.Time, error) {
+	switch v := value.(type) {
+	case time.Time:
+		return v.UTC(), nil
+	case string:
+		ts, err := time.Parse(time.RFC3339Nano, v)
+		if err != nil {
+			return time.Time{}, fferr.NewTypeError(vt.Timestamp.String(), value, err)
+		}
+		return ts.UTC(), nil
+	default:
+		millis, err := castKafkaInt(value)
+		if err != nil {
+			return time.Time{}, fferr.NewTypeError(vt.Timestamp.String(), value, err)
+		}
+		return time.UnixMilli(millis).UTC(), nil
+	}
+}
+
+func castKafkaInt(value interface{}) (int64, error) {
+	switch v := value.(type) {
+	case json.Number:
+		return v.Int64()
+	case string:
+		return strconv.ParseInt(v, 10, 64)
+	case int:
+		return int64(v), nil
+	case int32:
+		return int64(v), nil
+	case int64:
+		return v, nil
+	case float32:
+		return int64(v), nil
+	case float64:
+		return int64(v), nil
+	}
+	return 0, fmt.Errorf("cannot cast %T to an integer", value)
+}
+
+func castKafkaFloat(value interface{}) (float64, error) {
+	switch v := value.(type) {
+	case json.Number:
+		return v.Float64()
+	case string:
+		return strconv.ParseFloat(v, 64)
+	case float32:
+		return float64(v), nil
+	case float64:
+		return v, nil
+	case int:
+		return float64(v), nil
+	case int32:
+		return float64(v), nil
+	case int64:
+		return float64(v), nil
+	}
+	return 0, fmt.Errorf("cannot cast %T to a float", value)
+}
+
+func castKafkaVector(value interface{}) ([]float32, error) {
+	elems, ok := value.([]interface{})
+	if !ok {
+		return nil, fferr.NewTypeErrorf("vector", value, "cannot cast %T to a vector", value)
+	}
+	vector := make([]float32, len(elems))
+	for i, elem := range elems {
+		f, err := castKafkaFloat(elem)
+		if err != nil {
+			return nil, fferr.NewTypeError("vector", value, err)
+		}
+		vector[i] = float32(f)
+	}
+	return vector, nil
+}
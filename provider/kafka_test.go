@@ -0,0 +1,168 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+//
+// Copyright 2024 FeatureForm Inc.
+//
+
+package provider
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	pc "github.com/featureform/provider/provider_config"
+	vt "github.com/featureform/provider/types"
+	"github.com/linkedin/goavro/v2"
+)
+
+const kafkaTestSchema = `{
+	"type": "record",
+	"name": "Transaction",
+	"fields": [
+		{"name": "user", "type": "string"},
+		{"name": "amount", "type": "double"},
+		{"name": "note", "type": ["null", "string"], "default": null}
+	]
+}`
+
+func TestKafkaJSONDecoder(t *testing.T) {
+	record, err := kafkaJSONDecoder{}.Decode([]byte(`{"user": "a", "amount": 9007199254740993, "tags": ["x"]}`))
+	if err != nil {
+		t.Fatalf("Failed to decode: %v", err)
+	}
+	if record["amount"] != json.Number("9007199254740993") {
+		t.Fatalf("Expected amount to be decoded as a json.Number, got %#v", record["amount"])
+	}
+	if _, err := (kafkaJSONDecoder{}).Decode([]byte("not json")); err == nil {
+		t.Fatalf("Expected an error decoding invalid JSON")
+	}
+}
+
+func TestKafkaAvroDecoder(t *testing.T) {
+	var requests int32
+	registry := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		if r.URL.Path != "/schemas/ids/7" {
+			http.NotFound(w, r)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]string{"schema": kafkaTestSchema})
+	}))
+	defer registry.Close()
+
+	codec, err := goavro.NewCodec(kafkaTestSchema)
+	if err != nil {
+		t.Fatalf("Failed to parse schema: %v", err)
+	}
+	encode := func(schemaID uint32, record map[string]interface{}) []byte {
+		header := make([]byte, 5)
+		binary.BigEndian.PutUint32(header[1:], schemaID)
+		payload, err := codec.BinaryFromNative(header, record)
+		if err != nil {
+			t.Fatalf("Failed to encode: %v", err)
+		}
+		return payload
+	}
+
+	decoder := NewKafkaAvroDecoder(registry.URL+"/", "", "")
+	record, err := decoder.Decode(encode(7, map[string]interface{}{
+		"user":   "a",
+		"amount": 1.5,
+		"note":   goavro.Union("string", "first"),
+	}))
+	if err != nil {
+		t.Fatalf("Failed to decode: %v", err)
+	}
+	expected := map[string]interface{}{"user": "a", "amount": 1.5, "note": "first"}
+	if !reflect.DeepEqual(record, expected) {
+		t.Fatalf("Expected %#v, got %#v", expected, record)
+	}
+	// The schema is only fetched once.
+	if _, err := decoder.Decode(encode(7, map[string]interface{}{"user": "b", "amount": 2.0, "note": nil})); err != nil {
+		t.Fatalf("Failed to decode: %v", err)
+	}
+	if n := atomic.LoadInt32(&requests); n != 1 {
+		t.Fatalf("Expected the schema to be fetched once, got %d requests", n)
+	}
+	if _, err := decoder.Decode(encode(8, map[string]interface{}{"user": "c", "amount": 1.0, "note": nil})); err == nil {
+		t.Fatalf("Expected an error decoding a message with an unknown schema")
+	}
+	if _, err := decoder.Decode([]byte(`{"user": "a"}`)); err == nil {
+		t.Fatalf("Expected an error decoding a message without the wire format header")
+	}
+}
+
+func TestCastKafkaValue(t *testing.T) {
+	ts := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	tests := []struct {
+		name      string
+		value     interface{}
+		valueType vt.ValueType
+		expected  interface{}
+	}{
+		{"Int", json.Number("10"), vt.Int, 10},
+		{"Int32 From String", "-3", vt.Int32, int32(-3)},
+		{"Int64", json.Number("9007199254740993"), vt.Int64, int64(9007199254740993)},
+		{"Float32", json.Number("1.5"), vt.Float32, float32(1.5)},
+		{"Float64", json.Number("2.25"), vt.Float64, 2.25},
+		{"Bool", true, vt.Bool, true},
+		{"Bool From String", "false", vt.Bool, false},
+		{"String", "value", vt.String, "value"},
+		{"String From Number", json.Number("12"), vt.String, "12"},
+		{"JSON", map[string]interface{}{"a": json.Number("1")}, vt.JSON, `{"a":1}`},
+		{"Timestamp", "2024-01-02T03:04:05Z", vt.Timestamp, ts},
+		{"Timestamp From Millis", json.Number("1704164645000"), vt.Timestamp, ts},
+		{"Vector", []interface{}{json.Number("0.5"), json.Number("-1")}, vt.VectorType{ScalarType: vt.Float32, Dimension: 2}, []float32{0.5, -1}},
+		{"Int From Avro Int", int32(7), vt.Int64, int64(7)},
+		{"Float64 From Avro Float", float32(0.5), vt.Float64, 0.5},
+		{"String From Avro Bytes", []byte("value"), vt.String, "value"},
+		{"Timestamp From Avro Timestamp", ts, vt.Timestamp, ts},
+		{"Vector From Avro Array", []interface{}{float32(0.5), float32(-1)}, vt.VectorType{ScalarType: vt.Float32, Dimension: 2}, []float32{0.5, -1}},
+		{"Nil", nil, vt.Int, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			actual, err := CastKafkaValue(tt.value, tt.valueType)
+			if err != nil {
+				t.Fatalf("Failed to cast: %v", err)
+			}
+			if !reflect.DeepEqual(actual, tt.expected) {
+				t.Fatalf("Expected %#v, got %#v", tt.expected, actual)
+			}
+		})
+	}
+	if _, err := CastKafkaValue("abc", vt.Int); err == nil {
+		t.Fatalf("Expected an error casting a non-numeric string to an int")
+	}
+	if _, err := CastKafkaValue(json.Number("1"), vt.VectorType{ScalarType: vt.Float32, Dimension: 1}); err == nil {
+		t.Fatalf("Expected an error casting a number to a vector")
+	}
+}
+
+func TestKafkaStreamRawEventsStore(t *testing.T) {
+	config := &pc.KafkaConfig{Brokers: []string{"localhost:9092"}, Format: pc.KafkaJSON}
+	stream, err := NewKafkaStream(config)
+	if err != nil {
+		t.Fatalf("Failed to create stream: %v", err)
+	}
+	store, err := stream.RawEventsStore()
+	if err != nil || store != nil {
+		t.Fatalf("Expected no raw events store, got %v, %v", store, err)
+	}
+	config.RawEventsStoreType = "LOCAL_FILESYSTEM"
+	config.RawEventsStoreConfig = json.RawMessage(`{"DirPath": "file://` + t.TempDir() + `"}`)
+	config.RawEventsPath = "events"
+	if stream, err = NewKafkaStream(config); err != nil {
+		t.Fatalf("Failed to create stream: %v", err)
+	}
+	if store, err = stream.RawEventsStore(); err != nil || store == nil {
+		t.Fatalf("Expected a raw events store, got %v, %v", store, err)
+	}
+}
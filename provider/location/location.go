@@ -12,6 +12,7 @@ import (
 	"fmt"
 	pb "github.com/featureform/metadata/proto"
 	"strings"
+	"unicode/utf8"
 
 	"github.com/featureform/fferr"
 	"github.com/featureform/filestore"
@@ -66,9 +67,10 @@ func (loc NilLocation) Proto() *pb.Location {
 }
 
 type JSONLocation struct {
-	OutputLocation string  `json:"outputLocation"`
-	LocationType   string  `json:"locationType"`
-	TableFormat    *string `json:"tableFormat,omitempty"`
+	OutputLocation string      `json:"outputLocation"`
+	LocationType   string      `json:"locationType"`
+	TableFormat    *string     `json:"tableFormat,omitempty"`
+	CSVOptions     *CSVOptions `json:"csvOptions,omitempty"`
 }
 
 func NewSQLLocation(table string) Location {
@@ -203,8 +205,18 @@ func NewFileLocation(path filestore.Filepath) Location {
 	return &FileStoreLocation{path: path}
 }
 
+// NewCSVFileLocation returns a file location whose contents are parsed using opts
+// rather than the default comma delimited format.
+func NewCSVFileLocation(path filestore.Filepath, opts CSVOptions) Location {
+	if opts.IsDefault() {
+		return NewFileLocation(path)
+	}
+	return &FileStoreLocation{path: path, csvOptions: &opts}
+}
+
 type FileStoreLocation struct {
-	path filestore.Filepath
+	path       filestore.Filepath
+	csvOptions *CSVOptions
 }
 
 func (l FileStoreLocation) Location() string {
@@ -228,10 +240,17 @@ func (l FileStoreLocation) Filepath() filestore.Filepath {
 	return l.path
 }
 
+// CSVOptions returns the options used to parse the file, or nil if the
+// file uses the default CSV format.
+func (l FileStoreLocation) CSVOptions() *CSVOptions {
+	return l.csvOptions
+}
+
 func (l FileStoreLocation) MarshalJSON() ([]byte, error) {
 	return json.Marshal(JSONLocation{
 		OutputLocation: l.Location(),
 		LocationType:   "filestore",
+		CSVOptions:     l.csvOptions,
 	})
 }
 
@@ -248,6 +267,7 @@ func (l *FileStoreLocation) Deserialize(config []byte) error {
 		return err
 	}
 	l.path = &fp
+	l.csvOptions = jsonLoc.CSVOptions
 	return nil
 }
 
@@ -255,12 +275,102 @@ func (l *FileStoreLocation) Proto() *pb.Location {
 	return &pb.Location{
 		Location: &pb.Location_Filestore{
 			Filestore: &pb.FileStoreTable{
-				Path: l.path.ToURI(),
+				Path:       l.path.ToURI(),
+				CsvOptions: l.csvOptions.Proto(),
 			},
 		},
 	}
 }
 
+// CSVOptions configure how a delimited text file is parsed. Empty fields use the
+// defaults: comma delimited, double-quoted fields where a quote is escaped by
+// doubling it, and a header row.
+type CSVOptions struct {
+	Delimiter string `json:"delimiter,omitempty"`
+	Quote     string `json:"quote,omitempty"`
+	Escape    string `json:"escape,omitempty"`
+	NoHeader  bool   `json:"noHeader,omitempty"`
+}
+
+func CSVOptionsFromProto(opts *pb.CSVOptions) CSVOptions {
+	return CSVOptions{
+		Delimiter: opts.GetDelimiter(),
+		Quote:     opts.GetQuote(),
+		Escape:    opts.GetEscape(),
+		NoHeader:  opts.GetNoHeader(),
+	}
+}
+
+func (o *CSVOptions) Proto() *pb.CSVOptions {
+	if o == nil {
+		return nil
+	}
+	return &pb.CSVOptions{
+		Delimiter: o.Delimiter,
+		Quote:     o.Quote,
+		Escape:    o.Escape,
+		NoHeader:  o.NoHeader,
+	}
+}
+
+func (o CSVOptions) IsDefault() bool {
+	return o == CSVOptions{}
+}
+
+// DelimiterRune returns the field delimiter, defaulting to a comma.
+func (o CSVOptions) DelimiterRune() rune {
+	return csvRune(o.Delimiter, ',')
+}
+
+// QuoteRune returns the quote character, defaulting to a double quote.
+func (o CSVOptions) QuoteRune() rune {
+	return csvRune(o.Quote, '"')
+}
+
+// EscapeRune returns the character that escapes a quote inside a quoted field.
+// It defaults to the quote character itself, in which case quotes are escaped
+// by doubling them.
+func (o CSVOptions) EscapeRune() rune {
+	return csvRune(o.Escape, o.QuoteRune())
+}
+
+func (o CSVOptions) Validate() error {
+	fields := []struct {
+		name  string
+		value string
+	}{
+		{"delimiter", o.Delimiter},
+		{"quote", o.Quote},
+		{"escape", o.Escape},
+	}
+	for _, field := range fields {
+		if field.value == "" {
+			continue
+		}
+		if utf8.RuneCountInString(field.value) != 1 {
+			return fferr.NewInvalidArgumentErrorf("csv %s must be a single character, got %q", field.name, field.value)
+		}
+		if r, _ := utf8.DecodeRuneInString(field.value); r == '\r' || r == '\n' || r == utf8.RuneError {
+			return fferr.NewInvalidArgumentErrorf("invalid csv %s %q", field.name, field.value)
+		}
+	}
+	if o.DelimiterRune() == o.QuoteRune() {
+		return fferr.NewInvalidArgumentErrorf("csv delimiter and quote must differ, both are %q", string(o.DelimiterRune()))
+	}
+	if o.DelimiterRune() == o.EscapeRune() {
+		return fferr.NewInvalidArgumentErrorf("csv delimiter and escape must differ, both are %q", string(o.DelimiterRune()))
+	}
+	return nil
+}
+
+func csvRune(value string, def rune) rune {
+	if value == "" {
+		return def
+	}
+	r, _ := utf8.DecodeRuneInString(value)
+	return r
+}
+
 func NewCatalogLocation(database, table, tableFormat string) Location {
 	return &CatalogLocation{database: database, table: table, tableFormat: tableFormat}
 }
@@ -408,7 +518,7 @@ func FromProto(pbLocation *pb.Location) (Location, error) {
 		if err != nil {
 			return nil, fferr.NewInternalErrorf("invalid filestore path: %v", err)
 		}
-		return NewFileLocation(&fp), nil
+		return NewCSVFileLocation(&fp, CSVOptionsFromProto(loc.Filestore.GetCsvOptions())), nil
 
 	case *pb.Location_Catalog:
 		return NewCatalogLocation(loc.Catalog.Database, loc.Catalog.Table, loc.Catalog.TableFormat), nil
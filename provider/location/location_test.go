@@ -8,7 +8,10 @@
 package location
 
 import (
+	"reflect"
 	"testing"
+
+	"github.com/featureform/filestore"
 )
 
 func TestSQLLocation_TableLocation(t *testing.T) {
@@ -237,3 +240,59 @@ func TestSQLLocation_GetTableFromRoot(t *testing.T) {
 		})
 	}
 }
+
+func TestFileStoreLocationCSVOptions(t *testing.T) {
+	fp := filestore.FilePath{}
+	if err := fp.ParseFilePath("s3://bucket/data/transactions.tsv"); err != nil {
+		t.Fatalf("could not parse path: %v", err)
+	}
+	opts := CSVOptions{Delimiter: "\t", Quote: "'", Escape: "\\", NoHeader: true}
+	loc := NewCSVFileLocation(&fp, opts).(*FileStoreLocation)
+
+	serialized, err := loc.Serialize()
+	if err != nil {
+		t.Fatalf("could not serialize: %v", err)
+	}
+	deserialized := &FileStoreLocation{}
+	if err := deserialized.Deserialize([]byte(serialized)); err != nil {
+		t.Fatalf("could not deserialize: %v", err)
+	}
+	if !reflect.DeepEqual(deserialized.CSVOptions(), &opts) {
+		t.Fatalf("expected options %v after deserializing, got %v", opts, deserialized.CSVOptions())
+	}
+
+	fromProto, err := FromProto(loc.Proto())
+	if err != nil {
+		t.Fatalf("could not convert from proto: %v", err)
+	}
+	if !reflect.DeepEqual(fromProto.(*FileStoreLocation).CSVOptions(), &opts) {
+		t.Fatalf("expected options %v from proto, got %v", opts, fromProto.(*FileStoreLocation).CSVOptions())
+	}
+
+	if NewCSVFileLocation(&fp, CSVOptions{}).(*FileStoreLocation).CSVOptions() != nil {
+		t.Fatalf("expected default options to be unset")
+	}
+}
+
+func TestCSVOptionsValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		opts    CSVOptions
+		wantErr bool
+	}{
+		{"Defaults", CSVOptions{}, false},
+		{"Tab", CSVOptions{Delimiter: "\t"}, false},
+		{"Pipe With Backslash Escape", CSVOptions{Delimiter: "|", Escape: "\\"}, false},
+		{"Multi Character Delimiter", CSVOptions{Delimiter: "||"}, true},
+		{"Newline Quote", CSVOptions{Quote: "\n"}, true},
+		{"Delimiter Matches Quote", CSVOptions{Delimiter: "\""}, true},
+		{"Delimiter Matches Escape", CSVOptions{Delimiter: "|", Escape: "|"}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.opts.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,191 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+//
+// Copyright 2024 FeatureForm Inc.
+//
+
+package provider
+
+import (
+	"encoding/gob"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/featureform/fferr"
+	"golang.org/x/sync/syncmap"
+)
+
+// memoryOfflineSnapshotVersion is bumped whenever memoryOfflineSnapshot changes
+// in a way that older snapshots can't be decoded into.
+const memoryOfflineSnapshotVersion = 1
+
+// memoryOfflineStateFile is the name of the file in the local state directory
+// that the memory offline store is saved to.
+const memoryOfflineStateFile = "memory_offline.gob"
+
+func init() {
+	// Feature and label values are stored as interface{}, gob needs every
+	// concrete type that isn't a builtin registered up front.
+	gob.Register(time.Time{})
+	gob.Register(map[string]interface{}{})
+}
+
+// memoryOfflineSnapshot is the on-disk format of a memoryOfflineStore.
+type memoryOfflineSnapshot struct {
+	Version          int
+	PrimaryTables    []ResourceID
+	ResourceTables   []memoryOfflineTableSnapshot
+	Materializations []MemoryMaterialization
+	Watermarks       []memoryOfflineWatermarkSnapshot
+	TrainingSets     []memoryTrainingSetSnapshot
+}
+
+type memoryOfflineTableSnapshot struct {
+	ID      ResourceID
+	Records []ResourceRecord
+}
+
+type memoryOfflineWatermarkSnapshot struct {
+	ID        ResourceID
+	Watermark time.Time
+}
+
+type memoryTrainingSetSnapshot struct {
+	ID   ResourceID
+	Rows trainingRows
+}
+
+// loadMemoryOfflineStore returns a memory offline store that's restored from
+// stateDir, if it was saved there before, and saved back to it on Close. An
+// empty stateDir returns a store that isn't persisted.
+func loadMemoryOfflineStore(stateDir string) (*memoryOfflineStore, error) {
+	store := NewMemoryOfflineStore()
+	if stateDir == "" {
+		return store, nil
+	}
+	if err := os.MkdirAll(stateDir, 0o755); err != nil {
+		return nil, fferr.NewInternalError(err)
+	}
+	store.statePath = filepath.Join(stateDir, memoryOfflineStateFile)
+	if _, err := os.Stat(store.statePath); os.IsNotExist(err) {
+		return store, nil
+	} else if err != nil {
+		return nil, fferr.NewInternalError(err)
+	}
+	if err := store.Load(store.statePath); err != nil {
+		return nil, err
+	}
+	return store, nil
+}
+
+// Save writes all of the store's tables, materializations, and training sets
+// to path, so they can be restored with Load after a restart. The file is
+// replaced atomically, a failed Save leaves the previous one in place.
+func (store *memoryOfflineStore) Save(path string) error {
+	snapshot := store.snapshot()
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return fferr.NewInternalError(err)
+	}
+	defer os.Remove(tmp.Name())
+	if err := gob.NewEncoder(tmp).Encode(snapshot); err != nil {
+		tmp.Close()
+		return fferr.NewInternalErrorf("failed to encode memory offline store: %v", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fferr.NewInternalError(err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fferr.NewInternalError(err)
+	}
+	return nil
+}
+
+// Load replaces the store's contents with a snapshot written by Save.
+func (store *memoryOfflineStore) Load(path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return fferr.NewInternalError(err)
+	}
+	defer file.Close()
+	var snapshot memoryOfflineSnapshot
+	if err := gob.NewDecoder(file).Decode(&snapshot); err != nil {
+		return fferr.NewInternalErrorf("failed to decode memory offline store from %s: %v", path, err)
+	}
+	if snapshot.Version != memoryOfflineSnapshotVersion {
+		return fferr.NewInternalErrorf(
+			"memory offline store snapshot %s has version %d, expected %d",
+			path, snapshot.Version, memoryOfflineSnapshotVersion,
+		)
+	}
+	store.restore(snapshot)
+	return nil
+}
+
+func (store *memoryOfflineStore) snapshot() memoryOfflineSnapshot {
+	snapshot := memoryOfflineSnapshot{Version: memoryOfflineSnapshotVersion}
+	store.tables.Range(func(key, value interface{}) bool {
+		id := key.(ResourceID)
+		switch table := value.(type) {
+		case *memoryPrimaryTable:
+			snapshot.PrimaryTables = append(snapshot.PrimaryTables, id)
+		case *memoryOfflineTable:
+			snapshot.ResourceTables = append(snapshot.ResourceTables, memoryOfflineTableSnapshot{
+				ID:      id,
+				Records: table.records(),
+			})
+		}
+		return true
+	})
+	store.materializations.Range(func(_, value interface{}) bool {
+		snapshot.Materializations = append(snapshot.Materializations, *value.(*MemoryMaterialization))
+		return true
+	})
+	store.watermarks.Range(func(key, value interface{}) bool {
+		snapshot.Watermarks = append(snapshot.Watermarks, memoryOfflineWatermarkSnapshot{
+			ID:        key.(ResourceID),
+			Watermark: value.(time.Time),
+		})
+		return true
+	})
+	store.trainingSets.Range(func(key, value interface{}) bool {
+		snapshot.TrainingSets = append(snapshot.TrainingSets, memoryTrainingSetSnapshot{
+			ID:   key.(ResourceID),
+			Rows: value.(trainingRows),
+		})
+		return true
+	})
+	return snapshot
+}
+
+func (store *memoryOfflineStore) restore(snapshot memoryOfflineSnapshot) {
+	for _, m := range []*syncmap.Map{&store.tables, &store.materializations, &store.watermarks, &store.trainingSets} {
+		m.Range(func(key, _ interface{}) bool {
+			m.Delete(key)
+			return true
+		})
+	}
+	for _, id := range snapshot.PrimaryTables {
+		store.tables.Store(id, &memoryPrimaryTable{})
+	}
+	for _, table := range snapshot.ResourceTables {
+		memTable := newMemoryOfflineTable()
+		for _, rec := range table.Records {
+			recs, _ := memTable.entityMap.LoadOrStore(rec.Entity, []ResourceRecord{})
+			memTable.entityMap.Store(rec.Entity, append(recs.([]ResourceRecord), rec))
+		}
+		store.tables.Store(table.ID, memTable)
+	}
+	for i := range snapshot.Materializations {
+		mat := snapshot.Materializations[i]
+		store.materializations.Store(mat.Id, &mat)
+	}
+	for _, watermark := range snapshot.Watermarks {
+		store.watermarks.Store(watermark.ID, watermark.Watermark)
+	}
+	for _, ts := range snapshot.TrainingSets {
+		store.trainingSets.Store(ts.ID, ts.Rows)
+	}
+}
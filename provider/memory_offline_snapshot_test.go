@@ -0,0 +1,164 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+//
+// Copyright 2024 FeatureForm Inc.
+//
+
+package provider
+
+import (
+	"encoding/gob"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/featureform/metadata"
+)
+
+func TestMemoryOfflineStoreSaveLoad(t *testing.T) {
+	store := NewMemoryOfflineStore()
+	primaryID := ResourceID{Name: "transactions", Variant: "v1", Type: Primary}
+	featureID := ResourceID{Name: "amount", Variant: "v1", Type: Feature}
+	labelID := ResourceID{Name: "fraud", Variant: "v1", Type: Label}
+	trainingSetID := ResourceID{Name: "fraud", Variant: "v1", Type: TrainingSet}
+	ts := time.UnixMilli(1700000000000).UTC()
+
+	if _, err := store.CreatePrimaryTable(primaryID, TableSchema{}); err != nil {
+		t.Fatalf("Failed to create primary table: %v", err)
+	}
+	feature, err := store.CreateResourceTable(featureID, TableSchema{})
+	if err != nil {
+		t.Fatalf("Failed to create feature table: %v", err)
+	}
+	featureRecs := []ResourceRecord{
+		{Entity: "a", Value: 1, TS: ts},
+		{Entity: "a", Value: 2, TS: ts.Add(time.Hour)},
+		{Entity: "b", Value: []float32{1, 2}, TS: ts},
+	}
+	if err := feature.WriteBatch(featureRecs); err != nil {
+		t.Fatalf("Failed to write features: %v", err)
+	}
+	label, err := store.CreateResourceTable(labelID, TableSchema{})
+	if err != nil {
+		t.Fatalf("Failed to create label table: %v", err)
+	}
+	if err := label.Write(ResourceRecord{Entity: "a", Value: true, TS: ts.Add(2 * time.Hour)}); err != nil {
+		t.Fatalf("Failed to write label: %v", err)
+	}
+	mat, err := store.CreateMaterialization(featureID, MaterializationOptions{})
+	if err != nil {
+		t.Fatalf("Failed to create materialization: %v", err)
+	}
+	def := TrainingSetDef{ID: trainingSetID, Label: labelID, Features: []ResourceID{featureID}}
+	if err := store.CreateTrainingSet(def); err != nil {
+		t.Fatalf("Failed to create training set: %v", err)
+	}
+
+	path := filepath.Join(t.TempDir(), "offline.gob")
+	if err := store.Save(path); err != nil {
+		t.Fatalf("Failed to save store: %v", err)
+	}
+	loaded := NewMemoryOfflineStore()
+	if _, err := loaded.CreatePrimaryTable(ResourceID{Name: "stale", Type: Primary}, TableSchema{}); err != nil {
+		t.Fatalf("Failed to create primary table: %v", err)
+	}
+	if err := loaded.Load(path); err != nil {
+		t.Fatalf("Failed to load store: %v", err)
+	}
+
+	if _, has := loaded.tables.Load(ResourceID{Name: "stale", Type: Primary}); has {
+		t.Fatalf("Expected Load to replace existing tables")
+	}
+	if _, err := loaded.GetPrimaryTable(primaryID, metadata.SourceVariant{}); err != nil {
+		t.Fatalf("Failed to get primary table: %v", err)
+	}
+	loadedFeature, err := loaded.getMemoryResourceTable(featureID)
+	if err != nil {
+		t.Fatalf("Failed to get feature table: %v", err)
+	}
+	gotRecs := loadedFeature.records()
+	expectedRecs := append([]ResourceRecord{}, featureRecs...)
+	for _, recs := range [][]ResourceRecord{gotRecs, expectedRecs} {
+		sort.Slice(recs, func(i, j int) bool {
+			if recs[i].Entity != recs[j].Entity {
+				return recs[i].Entity < recs[j].Entity
+			}
+			return recs[i].TS.Before(recs[j].TS)
+		})
+	}
+	if !reflect.DeepEqual(gotRecs, expectedRecs) {
+		t.Fatalf("Feature records not equal\nExpected: %v\nGot: %v", expectedRecs, gotRecs)
+	}
+	loadedMat, err := loaded.GetMaterialization(mat.ID())
+	if err != nil {
+		t.Fatalf("Failed to get materialization: %v", err)
+	}
+	if !reflect.DeepEqual(loadedMat, mat) {
+		t.Fatalf("Materializations not equal\nExpected: %v\nGot: %v", mat, loadedMat)
+	}
+	if _, err := loaded.UpdateMaterialization(featureID, MaterializationOptions{Incremental: true}); err != nil {
+		t.Fatalf("Failed to incrementally update materialization after load: %v", err)
+	}
+	iter, err := loaded.GetTrainingSet(trainingSetID)
+	if err != nil {
+		t.Fatalf("Failed to get training set: %v", err)
+	}
+	if !iter.Next() {
+		t.Fatalf("Expected a training set row: %v", iter.Err())
+	}
+	if !reflect.DeepEqual(iter.Features(), []interface{}{2}) || iter.Label() != true {
+		t.Fatalf("Unexpected training set row: %v %v", iter.Features(), iter.Label())
+	}
+}
+
+func TestMemoryOfflineStoreLoadVersionMismatch(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "offline.gob")
+	file, err := os.Create(path)
+	if err != nil {
+		t.Fatalf("Failed to create snapshot: %v", err)
+	}
+	if err := gob.NewEncoder(file).Encode(memoryOfflineSnapshot{Version: memoryOfflineSnapshotVersion + 1}); err != nil {
+		t.Fatalf("Failed to write snapshot: %v", err)
+	}
+	file.Close()
+	if err := NewMemoryOfflineStore().Load(path); err == nil {
+		t.Fatalf("Expected loading an unknown snapshot version to fail")
+	}
+}
+
+func TestLoadMemoryOfflineStoreFromStateDir(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "state")
+	store, err := loadMemoryOfflineStore(dir)
+	if err != nil {
+		t.Fatalf("Failed to create store: %v", err)
+	}
+	id := ResourceID{Name: "amount", Variant: "v1", Type: Feature}
+	table, err := store.CreateResourceTable(id, TableSchema{})
+	if err != nil {
+		t.Fatalf("Failed to create feature table: %v", err)
+	}
+	if err := table.Write(ResourceRecord{Entity: "a", Value: 1, TS: time.UnixMilli(0).UTC()}); err != nil {
+		t.Fatalf("Failed to write feature: %v", err)
+	}
+	if err := store.Close(); err != nil {
+		t.Fatalf("Failed to close store: %v", err)
+	}
+	restarted, err := loadMemoryOfflineStore(dir)
+	if err != nil {
+		t.Fatalf("Failed to reload store: %v", err)
+	}
+	if _, err := restarted.GetResourceTable(id); err != nil {
+		t.Fatalf("Expected feature table to survive a restart: %v", err)
+	}
+	unsaved, err := loadMemoryOfflineStore("")
+	if err != nil {
+		t.Fatalf("Failed to create store: %v", err)
+	}
+	if err := unsaved.Close(); err != nil {
+		t.Fatalf("Failed to close store: %v", err)
+	}
+}
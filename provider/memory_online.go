@@ -0,0 +1,37 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+//
+// Copyright 2024 FeatureForm Inc.
+//
+
+package provider
+
+import (
+	"sync"
+
+	pc "github.com/featureform/provider/provider_config"
+	pt "github.com/featureform/provider/provider_type"
+)
+
+var (
+	memoryOnlineSingleton     *localOnlineStore
+	memoryOnlineSingletonOnce sync.Once
+)
+
+// memoryOnlineStoreFactory returns the same store every time, so features that
+// are materialized into it can be served from it by the same process.
+func memoryOnlineStoreFactory(pc.SerializedConfig) (Provider, error) {
+	memoryOnlineSingletonOnce.Do(func() {
+		memoryOnlineSingleton = NewMemoryOnlineStore()
+	})
+	return memoryOnlineSingleton, nil
+}
+
+// NewMemoryOnlineStore returns a local online store that's registered as the
+// memory provider type.
+func NewMemoryOnlineStore() *localOnlineStore {
+	store := NewLocalOnlineStore()
+	store.ProviderType = pt.MemoryOnline
+	return store
+}
@@ -0,0 +1,79 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+//
+// Copyright 2024 FeatureForm Inc.
+//
+
+package provider
+
+import (
+	"testing"
+
+	"github.com/featureform/fferr"
+	pc "github.com/featureform/provider/provider_config"
+	pt "github.com/featureform/provider/provider_type"
+	"github.com/featureform/provider/types"
+)
+
+func TestOnlineStoreMemory(t *testing.T) {
+	store, err := GetOnlineStore(pt.MemoryOnline, pc.SerializedConfig{})
+	if err != nil {
+		t.Fatalf("could not initialize store: %s\n", err)
+	}
+	test := OnlineStoreTest{
+		t:              t,
+		store:          store,
+		testNil:        true,
+		testFloatVec:   true,
+		testBatch:      true,
+		testTTL:        true,
+		testSetIfNewer: true,
+	}
+	test.Run()
+}
+
+func TestMemoryOnlineStoreIsShared(t *testing.T) {
+	first, err := GetOnlineStore(pt.MemoryOnline, pc.SerializedConfig{})
+	if err != nil {
+		t.Fatalf("could not initialize store: %s", err)
+	}
+	feature, variant := randomFeatureVariant()
+	table, err := first.CreateTable(feature, variant, types.Int)
+	if err != nil {
+		t.Fatalf("Failed to create table: %s", err)
+	}
+	if err := table.Set("a", 1); err != nil {
+		t.Fatalf("Failed to set entity: %s", err)
+	}
+
+	// Serving gets its own handle to the provider, but has to see the values
+	// that were materialized through another one.
+	second, err := GetOnlineStore(pt.MemoryOnline, pc.SerializedConfig{})
+	if err != nil {
+		t.Fatalf("could not initialize store: %s", err)
+	}
+	table, err = second.GetTable(feature, variant)
+	if err != nil {
+		t.Fatalf("Failed to get table: %s", err)
+	}
+	if val, err := table.Get("a"); err != nil || val != 1 {
+		t.Fatalf("Expected 1, got %v: %v", val, err)
+	}
+
+	if err := second.DeleteTable(feature, variant); err != nil {
+		t.Fatalf("Failed to delete table: %s", err)
+	}
+	if _, err := first.GetTable(feature, variant); err == nil {
+		t.Fatalf("Expected deleted table to be missing")
+	}
+	table, err = first.CreateTable(feature, variant, types.Int)
+	if err != nil {
+		t.Fatalf("Failed to recreate table: %s", err)
+	}
+	if _, err := table.Get("a"); err == nil {
+		t.Fatalf("Expected deleted table's values to be removed")
+	} else if _, ok := err.(*fferr.EntityNotFoundError); !ok {
+		t.Fatalf("Wrong error for deleted value: %T", err)
+	}
+}
@@ -8,9 +8,12 @@
 package provider
 
 import (
+	"reflect"
 	"testing"
+	"time"
 
 	pt "github.com/featureform/provider/provider_type"
+	"github.com/featureform/provider/types"
 )
 
 func TestOfflineStoreMemory(t *testing.T) {
@@ -25,3 +28,212 @@ func TestOfflineStoreMemory(t *testing.T) {
 	}
 	test.Run()
 }
+
+func TestMemoryTrainingSetMaxFeatureAge(t *testing.T) {
+	store := NewMemoryOfflineStore()
+	schema := TableSchema{
+		Columns: []TableColumn{
+			{Name: "entity", ValueType: types.String},
+			{Name: "value", ValueType: types.Int},
+			{Name: "ts", ValueType: types.Timestamp},
+		},
+	}
+	labelID := ResourceID{"label", "v", Label}
+	featureID := ResourceID{"feature", "v", Feature}
+	labels, err := store.CreateResourceTable(labelID, schema)
+	if err != nil {
+		t.Fatalf("could not create label table: %v", err)
+	}
+	features, err := store.CreateResourceTable(featureID, schema)
+	if err != nil {
+		t.Fatalf("could not create feature table: %v", err)
+	}
+	labelTS := time.UnixMilli(0).UTC().Add(24 * time.Hour)
+	featureRecs := []ResourceRecord{
+		// Within the one hour window.
+		{Entity: "fresh", Value: 1, TS: labelTS.Add(-30 * time.Minute)},
+		// Older than the window, so the feature should come back null.
+		{Entity: "stale", Value: 2, TS: labelTS.Add(-2 * time.Hour)},
+	}
+	if err := features.WriteBatch(featureRecs); err != nil {
+		t.Fatalf("could not write features: %v", err)
+	}
+	labelRecs := []ResourceRecord{
+		{Entity: "fresh", Value: "fresh", TS: labelTS},
+		{Entity: "stale", Value: "stale", TS: labelTS},
+	}
+	if err := labels.WriteBatch(labelRecs); err != nil {
+		t.Fatalf("could not write labels: %v", err)
+	}
+	def := TrainingSetDef{
+		ID:            ResourceID{"ts", "v", TrainingSet},
+		Label:         labelID,
+		Features:      []ResourceID{featureID},
+		MaxFeatureAge: time.Hour,
+	}
+	if err := store.CreateTrainingSet(def); err != nil {
+		t.Fatalf("could not create training set: %v", err)
+	}
+	iter, err := store.GetTrainingSet(def.ID)
+	if err != nil {
+		t.Fatalf("could not get training set: %v", err)
+	}
+	expected := map[string]interface{}{"fresh": 1, "stale": nil}
+	for iter.Next() {
+		label := iter.Label().(string)
+		if got := iter.Features()[0]; got != expected[label] {
+			t.Fatalf("%s: expected feature %v, got %v", label, expected[label], got)
+		}
+	}
+	if err := iter.Err(); err != nil {
+		t.Fatalf("could not iterate training set: %v", err)
+	}
+}
+
+func TestTrainingSetDefMaxFeatureAgeCheck(t *testing.T) {
+	def := TrainingSetDef{
+		ID:       ResourceID{"ts", "v", TrainingSet},
+		Label:    ResourceID{"label", "v", Label},
+		Features: []ResourceID{{"feature", "v", Feature}},
+	}
+	for _, age := range []time.Duration{-time.Second, time.Millisecond} {
+		def.MaxFeatureAge = age
+		if err := def.check(); err == nil {
+			t.Fatalf("expected error for max feature age %s", age)
+		}
+	}
+}
+
+func TestMemoryIncrementalMaterialization(t *testing.T) {
+	store := NewMemoryOfflineStore()
+	schema := TableSchema{
+		Columns: []TableColumn{
+			{Name: "entity", ValueType: types.String},
+			{Name: "value", ValueType: types.Int},
+			{Name: "ts", ValueType: types.Timestamp},
+		},
+	}
+	id := ResourceID{"feature", "v", Feature}
+	table, err := store.CreateResourceTable(id, schema)
+	if err != nil {
+		t.Fatalf("could not create feature table: %v", err)
+	}
+	base := time.UnixMilli(0).UTC()
+	initial := []ResourceRecord{
+		{Entity: "a", Value: 1, TS: base.Add(time.Hour)},
+		{Entity: "b", Value: 2, TS: base.Add(2 * time.Hour)},
+	}
+	if err := table.WriteBatch(initial); err != nil {
+		t.Fatalf("could not write records: %v", err)
+	}
+	if _, err := store.CreateMaterialization(id, MaterializationOptions{}); err != nil {
+		t.Fatalf("could not create materialization: %v", err)
+	}
+	appended := []ResourceRecord{
+		// Arrives after the watermark with an older timestamp, so it's skipped.
+		{Entity: "a", Value: 10, TS: base.Add(30 * time.Minute)},
+		// Written out of order; the latest timestamp must win.
+		{Entity: "b", Value: 30, TS: base.Add(4 * time.Hour)},
+		{Entity: "b", Value: 20, TS: base.Add(3 * time.Hour)},
+		{Entity: "c", Value: 5, TS: base.Add(3 * time.Hour)},
+	}
+	if err := table.WriteBatch(appended); err != nil {
+		t.Fatalf("could not write records: %v", err)
+	}
+	mat, err := store.UpdateMaterialization(id, MaterializationOptions{Incremental: true})
+	if err != nil {
+		t.Fatalf("could not update materialization: %v", err)
+	}
+	expected := map[string]interface{}{"b": 30, "c": 5}
+	iter, err := mat.IterateSegment(0, int64(len(expected)))
+	if err != nil {
+		t.Fatalf("could not iterate materialization: %v", err)
+	}
+	actual := map[string]interface{}{}
+	for iter.Next() {
+		actual[iter.Value().Entity] = iter.Value().Value
+	}
+	if err := iter.Err(); err != nil {
+		t.Fatalf("could not iterate materialization: %v", err)
+	}
+	if len(actual) != len(expected) {
+		t.Fatalf("expected %v, got %v", expected, actual)
+	}
+	for entity, value := range expected {
+		if actual[entity] != value {
+			t.Fatalf("%s: expected %v, got %v", entity, value, actual[entity])
+		}
+	}
+	// Nothing new has been written, so the next run is empty.
+	mat, err = store.UpdateMaterialization(id, MaterializationOptions{Incremental: true})
+	if err != nil {
+		t.Fatalf("could not update materialization: %v", err)
+	}
+	if rows, err := mat.NumRows(); err != nil || rows != 0 {
+		t.Fatalf("expected empty materialization, got %d rows: %v", rows, err)
+	}
+}
+
+func TestMemoryBatchFeaturesAsOf(t *testing.T) {
+	store := NewMemoryOfflineStore()
+	schema := TableSchema{
+		Columns: []TableColumn{
+			{Name: "entity", ValueType: types.String},
+			{Name: "value", ValueType: types.Int},
+			{Name: "ts", ValueType: types.Timestamp},
+		},
+	}
+	base := time.UnixMilli(0).UTC()
+	features := map[ResourceID][]ResourceRecord{
+		{"first", "v", Feature}: {
+			{Entity: "a", Value: 1, TS: base.Add(time.Hour)},
+			{Entity: "a", Value: 2, TS: base.Add(3 * time.Hour)},
+		},
+		{"second", "v", Feature}: {
+			{Entity: "a", Value: 10, TS: base.Add(2 * time.Hour)},
+			{Entity: "b", Value: 20, TS: base.Add(time.Hour)},
+		},
+	}
+	for id, records := range features {
+		table, err := store.CreateResourceTable(id, schema)
+		if err != nil {
+			t.Fatalf("could not create feature table: %v", err)
+		}
+		if err := table.WriteBatch(records); err != nil {
+			t.Fatalf("could not write records: %v", err)
+		}
+	}
+	ids := []ResourceID{{"first", "v", Feature}, {"second", "v", Feature}}
+	entities := []AsOfEntity{
+		{Entity: "a", TS: base},
+		{Entity: "a", TS: base.Add(2 * time.Hour)},
+		{Entity: "a", TS: base.Add(3 * time.Hour)},
+		{Entity: "b", TS: base.Add(3 * time.Hour)},
+	}
+	it, err := store.GetBatchFeaturesAsOf(ids, entities)
+	if err != nil {
+		t.Fatalf("could not get batch features: %v", err)
+	}
+	expected := []GenericRecord{
+		{nil, nil},
+		{1, 10},
+		{2, 10},
+		{nil, 20},
+	}
+	i := 0
+	for it.Next() {
+		if it.Entity() != entities[i].Entity || !it.AsOf().Equal(entities[i].TS) {
+			t.Fatalf("row %d: expected %v, got %v at %v", i, entities[i], it.Entity(), it.AsOf())
+		}
+		if !reflect.DeepEqual(it.Features(), expected[i]) {
+			t.Fatalf("row %d: expected features %v, got %v", i, expected[i], it.Features())
+		}
+		i++
+	}
+	if i != len(entities) {
+		t.Fatalf("expected %d rows, got %d", len(entities), i)
+	}
+	if _, err := store.GetBatchFeaturesAsOf([]ResourceID{{"missing", "v", Feature}}, entities); err == nil {
+		t.Fatalf("succeeded to get batch features of a missing feature")
+	}
+}
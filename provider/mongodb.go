@@ -27,27 +27,56 @@ import (
 type mongoDBMetadataRow struct {
 	Name string
 	T    string
+	// Dimension and IsEmbedding are only set for vector features.
+	Dimension   int32
+	IsEmbedding bool
+}
+
+func (row mongoDBMetadataRow) valueType() types.ValueType {
+	if row.Dimension == 0 {
+		return types.ScalarType(row.T)
+	}
+	return types.VectorType{ScalarType: types.ScalarType(row.T), Dimension: row.Dimension, IsEmbedding: row.IsEmbedding}
 }
 
 type mongoDBOnlineStore struct {
-	client          *mongo.Client
-	database        string
-	tableThroughput int
+	client           *mongo.Client
+	database         string
+	tableThroughput  int
+	vectorIndex      string
+	vectorSimilarity pc.MongoDBVectorSimilarity
+	directCopy       bool
+	partitionKey     string
 	BaseProvider
 }
 
 type mongoDBOnlineTable struct {
-	client    *mongo.Client
-	database  string
-	name      string
-	valueType types.ValueType
+	client      *mongo.Client
+	database    string
+	name        string
+	valueType   types.ValueType
+	vectorIndex string
+}
+
+// NearestNeighbor is an entity found by a vector search and its similarity to
+// the searched vector. Higher scores are more similar.
+type NearestNeighbor struct {
+	Entity string  `bson:"entity"`
+	Score  float64 `bson:"score"`
 }
 
+// mongoDBVectorCandidatesPerResult is how many candidates Atlas considers for
+// each result of a vector search. More candidates are slower, but more accurate.
+const mongoDBVectorCandidatesPerResult = 10
+
 func mongoOnlineStoreFactory(serialized pc.SerializedConfig) (Provider, error) {
 	mongoConfig := &pc.MongoDBConfig{}
 	if err := mongoConfig.Deserialize(serialized); err != nil {
 		return nil, err
 	}
+	if err := mongoConfig.Validate(); err != nil {
+		return nil, err
+	}
 
 	return NewMongoDBOnlineStore(mongoConfig)
 }
@@ -80,9 +109,13 @@ func NewMongoDBOnlineStore(config *pc.MongoDBConfig) (*mongoDBOnlineStore, error
 	}
 
 	return &mongoDBOnlineStore{
-		client:          client,
-		database:        config.Database,
-		tableThroughput: config.Throughput,
+		client:           client,
+		database:         config.Database,
+		tableThroughput:  config.Throughput,
+		vectorIndex:      config.VectorIndex,
+		vectorSimilarity: config.Similarity(),
+		directCopy:       config.DirectCopy,
+		partitionKey:     config.PartitionKey,
 		BaseProvider: BaseProvider{
 			ProviderType:   pt.MongoDBOnline,
 			ProviderConfig: config.Serialized(),
@@ -113,24 +146,32 @@ func (store *mongoDBOnlineStore) GetMetadataTableName() string {
 
 func (store *mongoDBOnlineStore) CreateTable(feature, variant string, valueType types.ValueType) (OnlineStoreTable, error) {
 	tableName := store.GetTableName(feature, variant)
-	vType := string(valueType.Scalar())
 	getTable, _ := store.GetTable(feature, variant)
 	if getTable != nil {
 		return nil, fferr.NewDatasetAlreadyExistsError(feature, variant, nil)
 	}
+	vectorType, isVector := valueType.(types.VectorType)
+	if isVector && vectorType.IsEmbedding && store.vectorIndex == "" {
+		return nil, fferr.NewInvalidArgumentErrorf("MongoDB embeddings require a VectorIndex in the provider config")
+	}
 
+	metadataRow := mongoDBMetadataRow{Name: tableName, T: string(valueType.Scalar())}
+	if isVector {
+		metadataRow.Dimension = vectorType.Dimension
+		metadataRow.IsEmbedding = vectorType.IsEmbedding
+	}
 	metadataTableName := store.GetMetadataTableName()
 	wConcern := writeconcern.New(writeconcern.J(true), writeconcern.WMajority())
 	_, err := store.client.Database(store.database, &options.DatabaseOptions{
 		WriteConcern: wConcern,
-	}).Collection(metadataTableName).InsertOne(context.TODO(), mongoDBMetadataRow{tableName, vType})
+	}).Collection(metadataTableName).InsertOne(context.TODO(), metadataRow)
 	if err != nil {
 		wrapped := fferr.NewResourceExecutionError(pt.MongoDBOnline.String(), feature, variant, fferr.FEATURE_VARIANT, err)
 		wrapped.AddDetail("table_name", tableName)
 		return nil, wrapped
 	}
 
-	command := bson.D{{Key: "customAction", Value: "CreateCollection"}, {Key: "collection", Value: tableName}, {Key: "autoScaleSettings", Value: bson.D{{Key: "maxThroughput", Value: store.tableThroughput}}}}
+	command := mongoDBCreateCollectionCommand(tableName, store.tableThroughput, store.partitionKey)
 	var cmdResult interface{}
 	err = store.client.Database(store.database).RunCommand(context.TODO(), command).Decode(&cmdResult)
 	if err != nil {
@@ -139,14 +180,81 @@ func (store *mongoDBOnlineStore) CreateTable(feature, variant string, valueType
 		return nil, wrapped
 	}
 
-	table := &mongoDBOnlineTable{
-		client:    store.client,
-		database:  store.database,
-		name:      tableName,
-		valueType: valueType,
+	// Atlas can only index collections that exist, so the vector index is
+	// created with the collection rather than in CreateIndex.
+	if isVector && vectorType.IsEmbedding {
+		command := mongoDBCreateVectorIndexCommand(tableName, store.vectorIndex, store.vectorSimilarity, vectorType.Dimension)
+		if err := store.client.Database(store.database).RunCommand(context.TODO(), command).Err(); err != nil {
+			wrapped := fferr.NewResourceExecutionError(pt.MongoDBOnline.String(), feature, variant, fferr.FEATURE_VARIANT, err)
+			wrapped.AddDetail("table_name", tableName)
+			wrapped.AddDetail("vector_index", store.vectorIndex)
+			return nil, wrapped
+		}
 	}
 
-	return table, nil
+	return store.newTable(tableName, valueType), nil
+}
+
+// mongoDBCreateCollectionCommand creates an autoscaled Cosmos DB collection,
+// sharded on partitionKey if it's set.
+func mongoDBCreateCollectionCommand(tableName string, throughput int, partitionKey string) bson.D {
+	command := bson.D{{Key: "customAction", Value: "CreateCollection"}, {Key: "collection", Value: tableName}, {Key: "autoScaleSettings", Value: bson.D{{Key: "maxThroughput", Value: throughput}}}}
+	if partitionKey != "" {
+		command = append(command, bson.E{Key: "shardKey", Value: partitionKey})
+	}
+	return command
+}
+
+func (store *mongoDBOnlineStore) newTable(tableName string, valueType types.ValueType) *mongoDBOnlineTable {
+	return &mongoDBOnlineTable{
+		client:      store.client,
+		database:    store.database,
+		name:        tableName,
+		valueType:   valueType,
+		vectorIndex: store.vectorIndex,
+	}
+}
+
+// CreateIndex checks that vector search is configured and returns the
+// embedding's table. The Atlas index itself is created by CreateTable.
+func (store *mongoDBOnlineStore) CreateIndex(feature, variant string, vectorType types.VectorType) (VectorStoreTable, error) {
+	if store.vectorIndex == "" {
+		return nil, fferr.NewInvalidArgumentErrorf("MongoDB embeddings require a VectorIndex in the provider config")
+	}
+	return store.newTable(store.GetTableName(feature, variant), vectorType), nil
+}
+
+func (store *mongoDBOnlineStore) Capabilities() (Capabilities, error) {
+	return Capabilities{VectorSearch: true}, nil
+}
+
+func (store *mongoDBOnlineStore) DeleteIndex(feature, variant string) error {
+	tableName := store.GetTableName(feature, variant)
+	command := bson.D{{Key: "dropSearchIndex", Value: tableName}, {Key: "name", Value: store.vectorIndex}}
+	if err := store.client.Database(store.database).RunCommand(context.TODO(), command).Err(); err != nil {
+		wrapped := fferr.NewResourceExecutionError(pt.MongoDBOnline.String(), feature, variant, fferr.FEATURE_VARIANT, err)
+		wrapped.AddDetail("table_name", tableName)
+		wrapped.AddDetail("vector_index", store.vectorIndex)
+		return wrapped
+	}
+	return nil
+}
+
+func mongoDBCreateVectorIndexCommand(tableName, index string, similarity pc.MongoDBVectorSimilarity, dimension int32) bson.D {
+	field := bson.D{
+		{Key: "type", Value: "vector"},
+		{Key: "path", Value: "value"},
+		{Key: "numDimensions", Value: dimension},
+		{Key: "similarity", Value: string(similarity)},
+	}
+	return bson.D{
+		{Key: "createSearchIndexes", Value: tableName},
+		{Key: "indexes", Value: bson.A{bson.D{
+			{Key: "name", Value: index},
+			{Key: "type", Value: "vectorSearch"},
+			{Key: "definition", Value: bson.D{{Key: "fields", Value: bson.A{field}}}},
+		}}},
+	}
 }
 
 func (store *mongoDBOnlineStore) GetTable(feature, variant string) (OnlineStoreTable, error) {
@@ -175,13 +283,7 @@ func (store *mongoDBOnlineStore) GetTable(feature, variant string) (OnlineStoreT
 		wrapped.AddDetail("table_name", tableName)
 		return nil, wrapped
 	}
-	table := &mongoDBOnlineTable{
-		client:    store.client,
-		database:  store.database,
-		name:      tableName,
-		valueType: types.ScalarType(row.T),
-	}
-	return table, nil
+	return store.newTable(tableName, row.valueType()), nil
 }
 
 func (store *mongoDBOnlineStore) DeleteTable(feature, variant string) error {
@@ -202,13 +304,27 @@ func (store *mongoDBOnlineStore) DeleteTable(feature, variant string) error {
 }
 
 func (store *mongoDBOnlineStore) CheckHealth() (bool, error) {
-	return false, fferr.NewInternalError(fmt.Errorf("not implemented"))
+	if err := store.client.Ping(context.TODO(), nil); err != nil {
+		wrapped := fferr.NewConnectionError(pt.MongoDBOnline.String(), err)
+		wrapped.AddDetail("action", "ping")
+		return false, wrapped
+	}
+	return true, nil
 }
 
 func (store mongoDBOnlineStore) Delete(location pl.Location) error {
 	return fferr.NewInternalErrorf("delete not implemented")
 }
 func (table mongoDBOnlineTable) Set(entity string, value interface{}) error {
+	if table.valueType.IsVector() {
+		if _, isVector := value.([]float32); !isVector {
+			wrapped := fferr.NewInvalidArgumentError(fmt.Errorf("expected value to be of type []float32, got %T", value))
+			wrapped.AddDetail("provider", pt.MongoDBOnline.String())
+			wrapped.AddDetail("entity", entity)
+			wrapped.AddDetail("table", table.name)
+			return wrapped
+		}
+	}
 	upsert := true
 	_, err := table.client.Database(table.database).
 		Collection(table.name).
@@ -248,6 +364,9 @@ func (table mongoDBOnlineTable) Get(entity string) (interface{}, error) {
 		return nil, wrapped
 	}
 
+	if table.valueType.IsVector() {
+		return mongoDBVector(row.Value)
+	}
 	switch table.valueType {
 	case types.Int:
 		return int(row.Value.(int32)), nil
@@ -264,5 +383,76 @@ func (table mongoDBOnlineTable) Get(entity string) (interface{}, error) {
 	default:
 		return nil, fferr.NewDataTypeNotFoundErrorf(table.valueType, "could not get table value")
 	}
+}
+
+// mongoDBVector converts an embedding array, which is decoded as doubles, back
+// to the []float32 it was written as.
+func mongoDBVector(value interface{}) ([]float32, error) {
+	array, ok := value.(primitive.A)
+	if !ok {
+		return nil, fferr.NewInternalErrorf("expected embedding to be an array, got %T", value)
+	}
+	vector := make([]float32, len(array))
+	for i, element := range array {
+		f, ok := element.(float64)
+		if !ok {
+			return nil, fferr.NewInternalErrorf("expected embedding element to be a double, got %T", element)
+		}
+		vector[i] = float32(f)
+	}
+	return vector, nil
+}
+
+func (table mongoDBOnlineTable) Nearest(feature, variant string, vector []float32, k int32) ([]string, error) {
+	neighbors, err := table.NearestNeighbor(vector, k)
+	if err != nil {
+		return nil, err
+	}
+	entities := make([]string, len(neighbors))
+	for i, neighbor := range neighbors {
+		entities[i] = neighbor.Entity
+	}
+	return entities, nil
+}
+
+// NearestNeighbor runs a $vectorSearch aggregation against the table's Atlas
+// Vector Search index and returns the k entities most similar to vector, most
+// similar first.
+func (table mongoDBOnlineTable) NearestNeighbor(vector []float32, k int32) ([]NearestNeighbor, error) {
+	if table.vectorIndex == "" {
+		return nil, fferr.NewInvalidArgumentErrorf("MongoDB vector search requires a VectorIndex in the provider config")
+	}
+	cur, err := table.client.Database(table.database).
+		Collection(table.name).
+		Aggregate(context.TODO(), mongoDBVectorSearchPipeline(table.vectorIndex, vector, k))
+	if err != nil {
+		wrapped := fferr.NewExecutionError(pt.MongoDBOnline.String(), err)
+		wrapped.AddDetail("table", table.name)
+		wrapped.AddDetail("vector_index", table.vectorIndex)
+		return nil, wrapped
+	}
+	neighbors := make([]NearestNeighbor, 0, k)
+	if err := cur.All(context.TODO(), &neighbors); err != nil {
+		wrapped := fferr.NewExecutionError(pt.MongoDBOnline.String(), err)
+		wrapped.AddDetail("table", table.name)
+		return nil, wrapped
+	}
+	return neighbors, nil
+}
 
+func mongoDBVectorSearchPipeline(index string, vector []float32, k int32) mongo.Pipeline {
+	return mongo.Pipeline{
+		{{Key: "$vectorSearch", Value: bson.D{
+			{Key: "index", Value: index},
+			{Key: "path", Value: "value"},
+			{Key: "queryVector", Value: vector},
+			{Key: "numCandidates", Value: k * mongoDBVectorCandidatesPerResult},
+			{Key: "limit", Value: k},
+		}}},
+		{{Key: "$project", Value: bson.D{
+			{Key: "_id", Value: 0},
+			{Key: "entity", Value: 1},
+			{Key: "score", Value: bson.D{{Key: "$meta", Value: "vectorSearchScore"}}},
+		}}},
+	}
 }
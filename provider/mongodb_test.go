@@ -0,0 +1,112 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+//
+// Copyright 2024 FeatureForm Inc.
+//
+
+package provider
+
+import (
+	"reflect"
+	"testing"
+
+	pc "github.com/featureform/provider/provider_config"
+	"github.com/featureform/provider/types"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestMongoDBVectorSearchPipeline(t *testing.T) {
+	pipeline := mongoDBVectorSearchPipeline("embeddings", []float32{0.5, 1}, 3)
+	if len(pipeline) != 2 {
+		t.Fatalf("Expected a search and a project stage, got %v", pipeline)
+	}
+	search := pipeline[0].Map()["$vectorSearch"].(bson.D).Map()
+	expected := bson.M{
+		"index":         "embeddings",
+		"path":          "value",
+		"queryVector":   []float32{0.5, 1},
+		"numCandidates": int32(30),
+		"limit":         int32(3),
+	}
+	if !reflect.DeepEqual(search, expected) {
+		t.Fatalf("Expected search stage %v, got %v", expected, search)
+	}
+	project := pipeline[1].Map()["$project"].(bson.D).Map()
+	if score := project["score"].(bson.D).Map()["$meta"]; score != "vectorSearchScore" {
+		t.Fatalf("Expected score to be projected from the search score, got %v", score)
+	}
+}
+
+func TestMongoDBCreateVectorIndexCommand(t *testing.T) {
+	command := mongoDBCreateVectorIndexCommand("featureform__emb__v", "embeddings", pc.MongoDBEuclideanSimilarity, 384)
+	if collection := command.Map()["createSearchIndexes"]; collection != "featureform__emb__v" {
+		t.Fatalf("Expected index on featureform__emb__v, got %v", collection)
+	}
+	index := command.Map()["indexes"].(bson.A)[0].(bson.D).Map()
+	if index["name"] != "embeddings" || index["type"] != "vectorSearch" {
+		t.Fatalf("Unexpected index: %v", index)
+	}
+	field := index["definition"].(bson.D).Map()["fields"].(bson.A)[0].(bson.D).Map()
+	expected := bson.M{"type": "vector", "path": "value", "numDimensions": int32(384), "similarity": "euclidean"}
+	if !reflect.DeepEqual(field, expected) {
+		t.Fatalf("Expected vector field %v, got %v", expected, field)
+	}
+}
+
+func TestMongoDBCreateCollectionCommand(t *testing.T) {
+	unsharded := mongoDBCreateCollectionCommand("featureform__f__v", 1000, "").Map()
+	if _, has := unsharded["shardKey"]; has {
+		t.Fatalf("Expected no shard key without a partition key, got %v", unsharded)
+	}
+	sharded := mongoDBCreateCollectionCommand("featureform__f__v", 1000, pc.MongoDBEntityPartitionKey).Map()
+	if sharded["collection"] != "featureform__f__v" || sharded["shardKey"] != "entity" {
+		t.Fatalf("Expected featureform__f__v sharded on entity, got %v", sharded)
+	}
+}
+
+func TestMongoDBDirectCopyOptIn(t *testing.T) {
+	if matOpt := DirectCopyOptionType(&mongoDBOnlineStore{}); matOpt != NullMaterializationOptionType {
+		t.Fatalf("Expected direct copy to be off by default, got %q", matOpt)
+	}
+	if matOpt := DirectCopyOptionType(&mongoDBOnlineStore{directCopy: true}); matOpt != DirectCopyCosmos {
+		t.Fatalf("Expected %q, got %q", DirectCopyCosmos, matOpt)
+	}
+}
+
+func TestMongoDBConfigPartitionKey(t *testing.T) {
+	for _, key := range []string{"", pc.MongoDBEntityPartitionKey} {
+		if err := (pc.MongoDBConfig{PartitionKey: key}).Validate(); err != nil {
+			t.Fatalf("Expected partition key %q to be valid: %v", key, err)
+		}
+	}
+	if err := (pc.MongoDBConfig{PartitionKey: "value"}).Validate(); err == nil {
+		t.Fatalf("Expected a partition key other than entity to be rejected")
+	}
+}
+
+func TestMongoDBMetadataRowValueType(t *testing.T) {
+	scalar := mongoDBMetadataRow{Name: "scalar", T: string(types.Int64)}
+	if scalar.valueType() != types.Int64 {
+		t.Fatalf("Expected int64, got %v", scalar.valueType())
+	}
+	vector := mongoDBMetadataRow{Name: "vector", T: string(types.Float32), Dimension: 3, IsEmbedding: true}
+	expected := types.VectorType{ScalarType: types.Float32, Dimension: 3, IsEmbedding: true}
+	if vector.valueType() != expected {
+		t.Fatalf("Expected %v, got %v", expected, vector.valueType())
+	}
+}
+
+func TestMongoDBVector(t *testing.T) {
+	vector, err := mongoDBVector(primitive.A{0.25, 1.5, -2.0})
+	if err != nil {
+		t.Fatalf("Failed to decode vector: %v", err)
+	}
+	if !reflect.DeepEqual(vector, []float32{0.25, 1.5, -2}) {
+		t.Fatalf("Unexpected vector: %v", vector)
+	}
+	if _, err := mongoDBVector("not a vector"); err == nil {
+		t.Fatalf("Expected an error decoding a non-array value")
+	}
+}
@@ -69,6 +69,12 @@ type mySQLQueries struct {
 	defaultOfflineSQLQueries
 }
 
+// mySQLIdentifier quotes ident with backticks, since MySQL only reads double
+// quoted identifiers in ANSI_QUOTES mode.
+func mySQLIdentifier(ident string) string {
+	return fmt.Sprintf("`%s`", strings.ReplaceAll(ident, "`", "``"))
+}
+
 func (q mySQLQueries) tableExists() string {
 	return "SELECT COUNT(*) FROM pg_tables WHERE  table_name  = $1 AND table_schema = CURRENT_SCHEMA()"
 }
@@ -78,6 +84,9 @@ func (q mySQLQueries) viewExists() string {
 }
 
 func (q mySQLQueries) registerResources(db *sql.DB, tableName string, schema ResourceSchema, timestamp bool) error {
+	if err := schema.checkUnparsed(pt.MySqlOffline); err != nil {
+		return err
+	}
 	var query *sql.Stmt
 	var err error
 	if !timestamp {
@@ -118,6 +127,12 @@ func (q mySQLQueries) materializationUpdate(db *sql.DB, tableName string, source
 	return nil
 }
 
+// materializationIncrementalUpdate isn't supported since materializations are
+// views, which always reflect the full source.
+func (q mySQLQueries) materializationIncrementalUpdate(db *sql.DB, tableName string, sourceName string) error {
+	return fferr.NewUnimplementedErrorf("incremental materialization is not supported for MySQL")
+}
+
 func (q mySQLQueries) materializationExists() string {
 	return "SELECT * FROM information_schema.tables	WHERE table_name = ? AND table_type = 'VIEW' AND table_schema = CURRENT_SCHEMA()"
 }
@@ -165,6 +180,9 @@ func (q mySQLQueries) trainingSetUpdate(store *sqlOfflineStore, def TrainingSetD
 }
 
 func (q mySQLQueries) trainingSetQuery(store *sqlOfflineStore, def TrainingSetDef, tableName string, labelName string, isUpdate bool) error {
+	if err := def.checkSingleLabel(pt.MySqlOffline); err != nil {
+		return err
+	}
 	columns := make([]string, 0)
 	query := fmt.Sprintf("(SELECT entity, value , ts from %s ) l", sanitize(labelName))
 	for i, feature := range def.Features {
@@ -175,24 +193,29 @@ func (q mySQLQueries) trainingSetQuery(store *sqlOfflineStore, def TrainingSetDe
 		santizedName := sanitize(tableName)
 		tableJoinAlias := fmt.Sprintf("t%d", i)
 		columns = append(columns, santizedName)
+		ageFilter := q.maxFeatureAgeFilter("ts", "l.ts", def.MaxFeatureAge)
 		query = fmt.Sprintf("%s LEFT JOIN (SELECT entity, value AS %s, ts FROM %s "+
-			"WHERE entity=l.entity AND ts <= l.ts ORDER BY ts DESC LIMIT 1) AS %s ON %s.entity=l.entity",
-			query, santizedName, santizedName, tableJoinAlias, tableJoinAlias)
+			"WHERE entity=l.entity AND ts <= l.ts%s ORDER BY ts DESC LIMIT 1) AS %s ON %s.entity=l.entity",
+			query, santizedName, santizedName, ageFilter, tableJoinAlias, tableJoinAlias)
 		if i == len(def.Features)-1 {
 			query = fmt.Sprintf("%s )", query)
 		}
 	}
 	columnStr := strings.Join(columns, ", ")
+	splitSelect := ""
+	if def.Split != nil {
+		splitSelect = trainingSetSplitSelect(*def.Split, q.trainingSetSplitBucket("l.entity", "l.ts", def.Split.Seed))
+	}
 
 	if isUpdate {
 		tempName := sanitize(fmt.Sprintf("tmp_%s", tableName))
-		fullQuery := fmt.Sprintf("CREATE TABLE %s AS (SELECT %s, l.value as label FROM %s ", tempName, columnStr, query)
+		fullQuery := fmt.Sprintf("CREATE TABLE %s AS (SELECT %s, l.value as label%s FROM %s ", tempName, columnStr, splitSelect, query)
 		err := q.atomicUpdate(store.db, tableName, tempName, fullQuery)
 		if err != nil {
 			return err
 		}
 	} else {
-		fullQuery := fmt.Sprintf("CREATE TABLE %s AS (SELECT %s, l.value as label FROM %s ", sanitize(tableName), columnStr, query)
+		fullQuery := fmt.Sprintf("CREATE TABLE %s AS (SELECT %s, l.value as label%s FROM %s ", sanitize(tableName), columnStr, splitSelect, query)
 		if _, err := store.db.Exec(fullQuery); err != nil {
 			wrapped := fferr.NewExecutionError(pt.MySqlOffline.String(), err)
 			wrapped.AddDetail("table_name", tableName)
@@ -204,6 +227,18 @@ func (q mySQLQueries) trainingSetQuery(store *sqlOfflineStore, def TrainingSetDe
 	return nil
 }
 
+func (q mySQLQueries) maxFeatureAgeFilter(featureTS, labelTS string, maxAge time.Duration) string {
+	if maxAge <= 0 {
+		return ""
+	}
+	return fmt.Sprintf(" AND %s >= %s - INTERVAL %d SECOND", featureTS, labelTS, int64(maxAge.Seconds()))
+}
+
+func (q mySQLQueries) trainingSetSplitBucket(entity, ts string, seed int64) string {
+	return fmt.Sprintf("MOD(CONV(SUBSTRING(MD5(CONCAT(%s, '|', %s, '|', '%d')), 1, 8), 16, 10), %d)",
+		entity, ts, seed, trainingSetSplitBuckets)
+}
+
 func (q mySQLQueries) castTableItemType(v interface{}, t interface{}) interface{} {
 	if v == nil {
 		return v
@@ -264,6 +299,32 @@ func (q mySQLQueries) transformationUpdate(db *sql.DB, tableName string, query s
 	return q.atomicUpdate(db, tableName, tempName, fullQuery)
 }
 
+// trainingSetPersist copies the training set in tableName into persist.Table.
+// MySQL commits DDL statements implicitly, so they can't share a transaction.
+// Instead the copy is built in a temporary table and swapped in by a single
+// RENAME TABLE, which MySQL applies atomically.
+func (q mySQLQueries) trainingSetPersist(db *sql.DB, tableName string, persist TrainingSetPersistAs) error {
+	persistTable := mySQLIdentifier(persist.Table)
+	tempTable := mySQLIdentifier(fmt.Sprintf("tmp_%s", persist.Table))
+	oldTable := mySQLIdentifier(fmt.Sprintf("old_%s", persist.Table))
+	queries := []string{
+		fmt.Sprintf("DROP TABLE IF EXISTS %s, %s", tempTable, oldTable),
+		fmt.Sprintf("CREATE TABLE %s AS %s", tempTable, persist.selectQuery(mySQLIdentifier(tableName))),
+		// The swap renames persist.Table, so it has to exist the first time.
+		fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s LIKE %s", persistTable, tempTable),
+		fmt.Sprintf("RENAME TABLE %s TO %s, %s TO %s", persistTable, oldTable, tempTable, persistTable),
+		fmt.Sprintf("DROP TABLE %s", oldTable),
+	}
+	for _, query := range queries {
+		if _, err := db.Exec(query); err != nil {
+			wrapped := fferr.NewExecutionError(pt.MySqlOffline.String(), err)
+			wrapped.AddDetail("query", query)
+			return wrapped
+		}
+	}
+	return nil
+}
+
 func (q mySQLQueries) transformationExists() string {
 	return "SELECT * FROM information_schema.tables	WHERE table_name = ? AND table_type = 'VIEW' AND table_schema = CURRENT_SCHEMA()"
 }
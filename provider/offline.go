@@ -12,7 +12,10 @@ import (
 	"errors"
 	"fmt"
 	tsq "github.com/featureform/provider/tsquery"
+	"hash/fnv"
+	"math"
 	"reflect"
+	"regexp"
 	"sort"
 	"strings"
 	"sync"
@@ -25,6 +28,7 @@ import (
 	"golang.org/x/text/cases"
 	"golang.org/x/text/language"
 
+	"github.com/featureform/config"
 	"github.com/featureform/fferr"
 	"github.com/featureform/filestore"
 	fs "github.com/featureform/filestore"
@@ -35,6 +39,7 @@ import (
 	pc "github.com/featureform/provider/provider_config"
 	ps "github.com/featureform/provider/provider_schema"
 	pt "github.com/featureform/provider/provider_type"
+	"github.com/featureform/provider/sqlcheck"
 	"github.com/featureform/provider/types"
 )
 
@@ -168,7 +173,12 @@ type TrainingSetDef struct {
 	ID                 ResourceID
 	Label              ResourceID
 	LabelSourceMapping SourceMapping
-	Features           []ResourceID
+	// Labels, when set, joins several labels into the training set for multi-task
+	// models, one column each. Label and LabelSourceMapping are the first label's,
+	// and are filled in from Labels and LabelSourceMappings if they're unset.
+	Labels              []ResourceID
+	LabelSourceMappings []SourceMapping
+	Features            []ResourceID
 	// **NOTE** The ProviderType and ProviderConfig fields in FeatureSourceMappings correspond
 	// the feature's source provider as the feature's provider will be the inference store.
 	// See getFeatureSourceMapping in coordinator/tasks/trainingset.go for more details.
@@ -176,28 +186,137 @@ type TrainingSetDef struct {
 	LagFeatures             []LagFeatureDef
 	ResourceSnowflakeConfig *metadata.ResourceSnowflakeConfig
 	Type                    metadata.TrainingSetType
+	// Split, when set, assigns every row of the training set to either the train or
+	// test split so it can be read back with GetTrainingSetSplit.
+	Split *TrainingSetSplit
+	// MaxFeatureAge, when non-zero, is the oldest a feature value may be relative to
+	// the label's timestamp. Older values are treated as null instead of being joined.
+	MaxFeatureAge time.Duration
+	// PersistAs, when set, also writes the training set to a named table once it's
+	// created, so it can be queried and registered as a primary source.
+	PersistAs *TrainingSetPersistAs
+}
+
+const (
+	TrainingSetSplitTrain = "train"
+	TrainingSetSplitTest  = "test"
+
+	// trainingSetSplitColumn holds the split a row was assigned to in stores that
+	// persist the training set as a table or as files.
+	trainingSetSplitColumn = "ff_split"
+	// labelColumnPrefix starts the names of a training set's label columns after
+	// the first, which are named after the labels' resource tables.
+	labelColumnPrefix = "featureform_resource_label__"
+	// trainingSetSplitBuckets is the number of hash buckets rows are distributed
+	// across; TrainFraction is applied at this granularity.
+	trainingSetSplitBuckets = 10000
+)
+
+// TrainingSetSplit is a deterministic train/test split. Rows are assigned by hashing
+// the label's entity and timestamp together with Seed, so re-reading or recreating
+// the training set with the same Seed always puts a row in the same split.
+type TrainingSetSplit struct {
+	TrainFraction float64
+	Seed          int64
+}
+
+func (split TrainingSetSplit) check() error {
+	if split.TrainFraction <= 0 || split.TrainFraction >= 1 {
+		return fferr.NewInvalidArgumentErrorf("training set split fraction must be between 0 and 1, got %v", split.TrainFraction)
+	}
+	return nil
+}
+
+// threshold returns the bucket below which rows belong to the train split.
+func (split TrainingSetSplit) threshold() int {
+	return int(math.Round(split.TrainFraction * trainingSetSplitBuckets))
+}
+
+// assign returns the split for a row in stores that compute the split in Go.
+func (split TrainingSetSplit) assign(entity string, ts time.Time) string {
+	h := fnv.New64a()
+	h.Write([]byte(fmt.Sprintf("%s|%d|%d", entity, ts.UnixNano(), split.Seed)))
+	if int(h.Sum64()%trainingSetSplitBuckets) < split.threshold() {
+		return TrainingSetSplitTrain
+	}
+	return TrainingSetSplitTest
+}
+
+// TrainingSetPersistAs is a table that a training set is copied into after it's
+// created. SQL stores create it in the store's database, and Spark creates it in
+// its Glue catalog.
+type TrainingSetPersistAs struct {
+	Table string
+	// Overwrite replaces Table if it already exists. Otherwise creating the training
+	// set fails if it does. Updating the training set always replaces the table it
+	// was persisted to.
+	Overwrite bool
+	// Dedup only writes one copy of rows that are identical across every column.
+	Dedup bool
+}
+
+func (persist TrainingSetPersistAs) check() error {
+	if strings.TrimSpace(persist.Table) == "" {
+		return fferr.NewInvalidArgumentErrorf("training set persist table name must be set")
+	}
+	return nil
+}
+
+// selectQuery returns the query that copies the training set in source into the
+// persisted table.
+func (persist TrainingSetPersistAs) selectQuery(source string) string {
+	if persist.Dedup {
+		return fmt.Sprintf("SELECT DISTINCT * FROM %s", source)
+	}
+	return fmt.Sprintf("SELECT * FROM %s", source)
+}
+
+func checkTrainingSetSplitName(split string) error {
+	if split != TrainingSetSplitTrain && split != TrainingSetSplitTest {
+		return fferr.NewInvalidArgumentErrorf("training set split must be %q or %q, got %q", TrainingSetSplitTrain, TrainingSetSplitTest, split)
+	}
+	return nil
 }
 
 type TrainingSetDefJSON struct {
 	ID                      ResourceID                        `json:"ID"`
 	Label                   ResourceID                        `json:"Label"`
 	LabelSourceMapping      SourceMappingJSON                 `json:"LabelSourceMapping"`
+	Labels                  []ResourceID                      `json:"Labels,omitempty"`
+	LabelSourceMappings     []SourceMappingJSON               `json:"LabelSourceMappings,omitempty"`
 	Features                []ResourceID                      `json:"Features"`
 	FeatureSourceMappings   []SourceMappingJSON               `json:"FeatureSourceMappings"`
 	LagFeatures             []LagFeatureDef                   `json:"LagFeatures"`
 	ResourceSnowflakeConfig *metadata.ResourceSnowflakeConfig `json:"ResourceSnowflakeConfig,omitempty"`
+	Split                   *TrainingSetSplit                 `json:"Split,omitempty"`
+	MaxFeatureAge           time.Duration                     `json:"MaxFeatureAge,omitempty"`
+	PersistAs               *TrainingSetPersistAs             `json:"PersistAs,omitempty"`
 }
 
 func (def *TrainingSetDef) check() error {
 	if err := def.ID.check(TrainingSet); err != nil {
 		return err
 	}
-	if err := def.Label.check(Label); err != nil {
+	if err := def.checkLabels(); err != nil {
 		return err
 	}
 	if len(def.Features) == 0 {
 		return fferr.NewInvalidArgumentError(errors.New("training set must have at least one feature"))
 	}
+	if def.Split != nil {
+		if err := def.Split.check(); err != nil {
+			return err
+		}
+	}
+	if def.PersistAs != nil {
+		if err := def.PersistAs.check(); err != nil {
+			return err
+		}
+	}
+	// Generated queries express the window in whole seconds.
+	if def.MaxFeatureAge < 0 || (def.MaxFeatureAge > 0 && def.MaxFeatureAge < time.Second) {
+		return fferr.NewInvalidArgumentErrorf("training set max feature age must be at least one second, got %s", def.MaxFeatureAge)
+	}
 	for i := range def.Features {
 		// We use features[i] to make sure that the Type value is updated to
 		// Feature if it's unset.
@@ -208,6 +327,97 @@ func (def *TrainingSetDef) check() error {
 	return nil
 }
 
+// checkLabels validates the training set's labels, filling in Label and
+// LabelSourceMapping from Labels and LabelSourceMappings. All of the labels must
+// be keyed by the same entities so their rows can be joined.
+func (def *TrainingSetDef) checkLabels() error {
+	if len(def.Labels) == 0 {
+		return def.Label.check(Label)
+	}
+	if len(def.LabelSourceMappings) != 0 && len(def.LabelSourceMappings) != len(def.Labels) {
+		return fferr.NewInvalidArgumentErrorf("training set has %d labels but %d label source mappings", len(def.Labels), len(def.LabelSourceMappings))
+	}
+	if def.Label.Name == "" {
+		def.Label = def.Labels[0]
+	} else if def.Label.Name != def.Labels[0].Name || def.Label.Variant != def.Labels[0].Variant {
+		return fferr.NewInvalidArgumentErrorf("training set label %s (%s) must be the first of its labels", def.Label.Name, def.Label.Variant)
+	}
+	if len(def.LabelSourceMappings) != 0 && def.LabelSourceMapping.Template == "" && def.LabelSourceMapping.Source == "" {
+		def.LabelSourceMapping = def.LabelSourceMappings[0]
+	}
+	seen := make(map[ResourceID]bool, len(def.Labels))
+	for i := range def.Labels {
+		if err := def.Labels[i].check(Label); err != nil {
+			return err
+		}
+		if seen[def.Labels[i]] {
+			return fferr.NewInvalidArgumentErrorf("training set has label %s (%s) more than once", def.Labels[i].Name, def.Labels[i].Variant)
+		}
+		seen[def.Labels[i]] = true
+	}
+	if err := def.Label.check(Label); err != nil {
+		return err
+	}
+	var entities []string
+	for i, mapping := range def.LabelSourceMappings {
+		if mapping.EntityMappings == nil {
+			continue
+		}
+		labelEntities := make([]string, len(mapping.EntityMappings.Mappings))
+		for j, m := range mapping.EntityMappings.Mappings {
+			labelEntities[j] = m.Name
+		}
+		sort.Strings(labelEntities)
+		if entities == nil {
+			entities = labelEntities
+		} else if !reflect.DeepEqual(entities, labelEntities) {
+			return fferr.NewInvalidArgumentErrorf("training set labels must share the entity: label %s (%s) has %v, expected %v",
+				def.Labels[i].Name, def.Labels[i].Variant, labelEntities, entities)
+		}
+	}
+	return nil
+}
+
+// labelIDs returns the training set's labels, the first of which is Label.
+func (def *TrainingSetDef) labelIDs() []ResourceID {
+	if len(def.Labels) == 0 {
+		return []ResourceID{def.Label}
+	}
+	return def.Labels
+}
+
+// labelSourceMappings returns the source mapping of each of the training set's
+// labels. If only LabelSourceMapping was set, the other labels are assumed to be
+// in the same provider.
+func (def *TrainingSetDef) labelSourceMappings() []SourceMapping {
+	if len(def.LabelSourceMappings) != 0 {
+		return def.LabelSourceMappings
+	}
+	mappings := []SourceMapping{def.LabelSourceMapping}
+	for range def.labelIDs()[1:] {
+		mappings = append(mappings, SourceMapping{ProviderType: def.LabelSourceMapping.ProviderType})
+	}
+	return mappings
+}
+
+// checkSingleLabel fails if the training set has more than one label, for stores
+// that can't join several.
+func (def *TrainingSetDef) checkSingleLabel(store pt.Type) error {
+	if len(def.labelIDs()) > 1 {
+		return fferr.NewInvalidArgumentErrorf("%s doesn't support training sets with multiple labels", store)
+	}
+	return nil
+}
+
+// checkNotPersisted fails if the training set is persisted to a table, for stores
+// that can't persist training sets.
+func (def *TrainingSetDef) checkNotPersisted(store pt.Type) error {
+	if def.PersistAs != nil {
+		return fferr.NewUnimplementedErrorf("%s doesn't support persisting training sets to a table", store)
+	}
+	return nil
+}
+
 type TransformationType string
 
 const (
@@ -249,6 +459,14 @@ type TransformationConfig struct {
 	LastRunTimestamp time.Time
 	IsUpdate         bool
 	SparkFlags       pc.SparkFlags
+	// SparkSubmitOptions are extra --conf values and --packages added to the
+	// spark-submit command of Spark transformations. Other stores ignore them.
+	SparkSubmitOptions pc.SparkSubmitOptions
+	// IncrementalColumn makes updates to a SQL transformation append only the rows
+	// whose value in this column is greater than the last run's watermark, rather
+	// than rebuilding it. The first run is always a full build. Stores that don't
+	// support it rebuild the transformation.
+	IncrementalColumn string
 	// Make sure to update tempConfig in Unmarshal when adding fields
 	OutputLocationType      pl.LocationType
 	TableFormat             string
@@ -276,17 +494,19 @@ func (m *TransformationConfig) MarshalJSON() ([]byte, error) {
 
 func (m *TransformationConfig) UnmarshalJSON(data []byte) error {
 	type tempConfig struct {
-		Type             TransformationType
-		TargetTableID    ResourceID
-		Query            string
-		Code             []byte
-		SourceMapping    []SourceMapping
-		Args             map[string]interface{}
-		ArgType          metadata.TransformationArgType
-		MaxJobDuration   time.Duration
-		LastRunTimestamp time.Time
-		IsUpdate         bool
-		SparkFlags       pc.SparkFlags
+		Type               TransformationType
+		TargetTableID      ResourceID
+		Query              string
+		Code               []byte
+		SourceMapping      []SourceMapping
+		Args               map[string]interface{}
+		ArgType            metadata.TransformationArgType
+		MaxJobDuration     time.Duration
+		LastRunTimestamp   time.Time
+		IsUpdate           bool
+		SparkFlags         pc.SparkFlags
+		SparkSubmitOptions pc.SparkSubmitOptions
+		IncrementalColumn  string
 	}
 
 	var temp tempConfig
@@ -304,6 +524,8 @@ func (m *TransformationConfig) UnmarshalJSON(data []byte) error {
 	m.LastRunTimestamp = temp.LastRunTimestamp
 	m.IsUpdate = temp.IsUpdate
 	m.SparkFlags = temp.SparkFlags
+	m.SparkSubmitOptions = temp.SparkSubmitOptions
+	m.IncrementalColumn = temp.IncrementalColumn
 
 	err = m.decodeArgs(temp.ArgType, temp.Args)
 	if err != nil {
@@ -351,6 +573,82 @@ type MaterializationOptions struct {
 	// the materialized table directly to this online store
 	// itself or fail with an error.
 	DirectCopyTo OnlineStore
+	// Incremental makes UpdateMaterialization read only the source rows newer
+	// than the materialization's watermark (the latest timestamp already
+	// materialized) instead of recomputing the whole feature. This assumes an
+	// append-only source: rows that arrive with a timestamp at or before the
+	// watermark are not picked up. The returned Materialization holds at least
+	// every entity whose value changed, so copying it over the existing online
+	// values yields the same result as a full update.
+	Incremental bool
+	// PartitionBy splits the materialization's output into a directory per
+	// partition. It only applies to providers that write materializations to
+	// files; others ignore it.
+	PartitionBy MaterializationPartition
+	// Filter is a SQL predicate over the feature's source columns. Only the
+	// source rows it matches are materialized. Providers that support it
+	// return true for FilteredMaterialization.
+	Filter string
+}
+
+// validateMaterializationFilter checks filter against the columns of the
+// materialization's source, see sqlcheck.CheckFilter.
+func validateMaterializationFilter(filter string, columns []string) error {
+	if err := sqlcheck.CheckFilter(filter, columns); err != nil {
+		wrapped := fferr.NewInvalidArgumentErrorf("invalid materialization filter: %v", err)
+		wrapped.AddDetail("filter", filter)
+		return wrapped
+	}
+	return nil
+}
+
+type MaterializationPartitionType string
+
+const (
+	NoPartition MaterializationPartitionType = ""
+	// PartitionByDate partitions by the date of each value's timestamp.
+	PartitionByDate MaterializationPartitionType = "DATE"
+	// PartitionByEntityBucket partitions by a hash of the entity into a fixed
+	// number of buckets.
+	PartitionByEntityBucket MaterializationPartitionType = "ENTITY_BUCKET"
+)
+
+// MaterializationPartition is how a materialization's output is partitioned.
+type MaterializationPartition struct {
+	Type MaterializationPartitionType
+	// Buckets is the number of entity buckets when partitioning by entity.
+	Buckets int
+}
+
+// Column returns the name of the column that the output is partitioned by.
+func (p MaterializationPartition) Column() string {
+	switch p.Type {
+	case PartitionByDate:
+		return "ts_date"
+	case PartitionByEntityBucket:
+		return "entity_bucket"
+	default:
+		return ""
+	}
+}
+
+func (p MaterializationPartition) validate(schema ResourceSchema) error {
+	switch p.Type {
+	case NoPartition:
+		return nil
+	case PartitionByDate:
+		if schema.TS == "" {
+			return fferr.NewInvalidArgumentErrorf("partitioning by date requires a timestamp column")
+		}
+		return nil
+	case PartitionByEntityBucket:
+		if p.Buckets <= 0 {
+			return fferr.NewInvalidArgumentErrorf("partitioning by entity requires a positive number of buckets, got %d", p.Buckets)
+		}
+		return nil
+	default:
+		return fferr.NewInvalidArgumentErrorf("unknown materialization partition type %s", p.Type)
+	}
 }
 
 type MaterializationOptionType string
@@ -360,12 +658,37 @@ const (
 	// materialized table directly to DynamoDB.
 	NullMaterializationOptionType MaterializationOptionType = ""
 	DirectCopyDynamo              MaterializationOptionType = "DirectCopyDynamo"
+	// DirectCopyRedis means that the provider is capable of writing its
+	// materialized features directly into a Redis table.
+	DirectCopyRedis MaterializationOptionType = "DirectCopyRedis"
+	// DirectCopyCosmos means that the provider is capable of writing its
+	// materialized features directly into a Cosmos DB collection through
+	// the MongoDB online store.
+	DirectCopyCosmos MaterializationOptionType = "DirectCopyCosmos"
+	// FilteredMaterialization means that the provider applies
+	// MaterializationOptions.Filter to the source before materializing it.
+	FilteredMaterialization MaterializationOptionType = "FilteredMaterialization"
 )
 
 func DirectCopyOptionType(store OnlineStore) MaterializationOptionType {
-	switch store.(type) {
+	switch s := store.(type) {
 	case *dynamodbOnlineStore:
 		return DirectCopyDynamo
+	case *redisOnlineStore:
+		// Direct copies bypass the runner, so Redis has to opt into them. They
+		// also run on every Spark executor, so stores that authenticate with a
+		// TLS client key or an ACL user are copied through the runner instead of
+		// shipping those credentials with the job.
+		if !s.directCopy || s.tls || s.username != "" {
+			return NullMaterializationOptionType
+		}
+		return DirectCopyRedis
+	case *mongoDBOnlineStore:
+		// Direct copies bypass the runner, so Cosmos DB has to opt into them.
+		if !s.directCopy {
+			return NullMaterializationOptionType
+		}
+		return DirectCopyCosmos
 	default:
 		return NullMaterializationOptionType
 	}
@@ -506,6 +829,151 @@ type ResourceOption interface {
 	Type() ResourceOptionType
 }
 
+const (
+	// ColumnCasts casts columns of the source table to another type when a resource
+	// is registered from it.
+	ColumnCasts ResourceOptionType = "ColumnCasts"
+	// TimestampParsing parses a source's timestamp column from strings or numbers
+	// when a resource is registered from it.
+	TimestampParsing ResourceOptionType = "TimestampParsing"
+)
+
+// ColumnCastOption casts columns of the source table when a resource is registered
+// from it, e.g. so a string column of "1"s can back an int feature. Only entity and
+// value columns can be cast, and entity columns can only be cast to strings.
+type ColumnCastOption struct {
+	// Casts maps a source column to the type its values are cast to.
+	Casts map[string]types.ScalarType
+}
+
+func (opt *ColumnCastOption) Type() ResourceOptionType {
+	return ColumnCasts
+}
+
+// castableValueTypes are the types a value column can be cast to.
+var castableValueTypes = map[types.ScalarType]bool{
+	types.Int:     true,
+	types.Int32:   true,
+	types.Int64:   true,
+	types.Float32: true,
+	types.Float64: true,
+	types.String:  true,
+	types.Bool:    true,
+}
+
+func (opt *ColumnCastOption) validate(schema ResourceSchema) error {
+	for column, target := range opt.Casts {
+		switch {
+		case schema.isValueColumn(column):
+			if !castableValueTypes[target] {
+				return fferr.NewInvalidArgumentErrorf("value column %s can't be cast to %s", column, target)
+			}
+		case schema.isEntityColumn(column):
+			if target != types.String {
+				return fferr.NewInvalidArgumentErrorf("entity column %s can only be cast to %s, not %s", column, types.String, target)
+			}
+		default:
+			return fferr.NewInvalidArgumentErrorf("column %s isn't an entity or value column and can't be cast", column)
+		}
+	}
+	return nil
+}
+
+// TimestampEncoding is how a timestamp column that isn't a native timestamp
+// stores its values.
+type TimestampEncoding string
+
+const (
+	// EpochSeconds timestamps are the number of seconds since the Unix epoch.
+	EpochSeconds TimestampEncoding = "epoch_seconds"
+	// EpochMillis timestamps are the number of milliseconds since the Unix epoch.
+	EpochMillis TimestampEncoding = "epoch_millis"
+	// ISO8601 timestamps are strings like 2024-01-02T03:04:05Z. Strings without
+	// an offset are read in the format's Timezone.
+	ISO8601 TimestampEncoding = "iso8601"
+)
+
+// timezonePattern matches IANA zone names, which are inlined into queries.
+var timezonePattern = regexp.MustCompile(`^[A-Za-z0-9_+\-/]+$`)
+
+// TimestampFormat describes how to parse a timestamp column.
+type TimestampFormat struct {
+	Encoding TimestampEncoding
+	// Timezone is the IANA zone of ISO-8601 timestamps that don't have an
+	// offset. It's UTC if unset.
+	Timezone string `json:",omitempty"`
+}
+
+func (f TimestampFormat) Validate() error {
+	switch f.Encoding {
+	case EpochSeconds, EpochMillis:
+		if f.Timezone != "" {
+			return fferr.NewInvalidArgumentErrorf("%s timestamps are always UTC and can't have a timezone", f.Encoding)
+		}
+	case ISO8601:
+		if f.Timezone == "" {
+			return nil
+		}
+		if !timezonePattern.MatchString(f.Timezone) {
+			return fferr.NewInvalidArgumentErrorf("invalid timezone %q", f.Timezone)
+		}
+		if _, err := time.LoadLocation(f.Timezone); err != nil {
+			return fferr.NewInvalidArgumentErrorf("invalid timezone %q: %v", f.Timezone, err)
+		}
+	default:
+		return fferr.NewInvalidArgumentErrorf("unknown timestamp encoding %q", f.Encoding)
+	}
+	return nil
+}
+
+// timezone returns the zone of timestamps without an offset.
+func (f TimestampFormat) timezone() string {
+	if f.Timezone == "" {
+		return "UTC"
+	}
+	return f.Timezone
+}
+
+// TimestampFormatOption parses the timestamp column of the source table when a
+// resource is registered from it. Without it, the column must already be a
+// timestamp, and timezone-naive columns are read in the store's session zone.
+type TimestampFormatOption struct {
+	Format TimestampFormat
+}
+
+func (opt *TimestampFormatOption) Type() ResourceOptionType {
+	return TimestampParsing
+}
+
+func (opt *TimestampFormatOption) validate(schema ResourceSchema) error {
+	if schema.timestampColumn() == "" {
+		return fferr.NewInvalidArgumentErrorf("a timestamp format requires a timestamp column")
+	}
+	return opt.Format.Validate()
+}
+
+type PrimaryOptionType string
+
+type PrimaryOption interface {
+	Type() PrimaryOptionType
+}
+
+const (
+	// SchemaDetection infers the columns of a file-backed primary table when it's
+	// registered, rather than leaving its schema empty.
+	SchemaDetection PrimaryOptionType = "SchemaDetection"
+)
+
+// DetectSchemaOption infers the schema of a CSV primary table from its header and
+// the first SampleSize rows. If SampleSize isn't positive, a default is used.
+type DetectSchemaOption struct {
+	SampleSize int
+}
+
+func (opt *DetectSchemaOption) Type() PrimaryOptionType {
+	return SchemaDetection
+}
+
 type OfflineStore interface {
 	Provider
 	OfflineStoreCore
@@ -521,13 +989,51 @@ type OfflineStoreCore interface {
 	// ResourceLocation passes 'any' object because we currently don't have an interface for the Variant Objects
 	// TODO: Create an interface for Variant Objects
 	ResourceLocation(id ResourceID, resource any) (pl.Location, error)
+	// GetResourceStats takes the same resource as ResourceLocation.
+	GetResourceStats(id ResourceID, resource any) (ResourceStats, error)
 	Provider
 }
 
+// ResourceStats summarizes the data behind a resource so that stale resources
+// can be flagged.
+type ResourceStats struct {
+	RowCount int64
+	// MaxTimestamp is the newest value of the resource's timestamp column. It's
+	// the zero time for resources without one, such as sources and training sets.
+	MaxTimestamp time.Time
+}
+
+// SourceProfiler is implemented by offline stores that can profile the columns
+// of their sources.
+type SourceProfiler interface {
+	ProfileSource(id ResourceID, source metadata.SourceVariant, opts ProfileOptions) (SourceProfile, error)
+}
+
+type ProfileOptions struct {
+	// SampleSize bounds the cost of profiling by only reading the first
+	// SampleSize rows. Every row is read if it isn't positive.
+	SampleSize int64
+}
+
+type SourceProfile struct {
+	// RowCount is the number of rows profiled.
+	RowCount int64
+	Columns  []ColumnProfile
+}
+
+type ColumnProfile struct {
+	Name          string
+	NullCount     int64
+	DistinctCount int64
+	// Min and Max are only set for numeric and timestamp columns.
+	Min string
+	Max string
+}
+
 type OfflineStoreDataset interface {
 	// CreatePrimaryTable is not used outside of the context of tests
 	CreatePrimaryTable(id ResourceID, schema TableSchema) (PrimaryTable, error)
-	RegisterPrimaryFromSourceTable(id ResourceID, tableLocation pl.Location) (PrimaryTable, error)
+	RegisterPrimaryFromSourceTable(id ResourceID, tableLocation pl.Location, opts ...PrimaryOption) (PrimaryTable, error)
 	GetPrimaryTable(id ResourceID, source metadata.SourceVariant) (PrimaryTable, error)
 	SupportsTransformationOption(opt TransformationOptionType) (bool, error)
 	CreateTransformation(config TransformationConfig, opts ...TransformationOption) error
@@ -550,8 +1056,14 @@ type OfflineStoreTrainingSet interface {
 	CreateTrainingSet(TrainingSetDef) error
 	UpdateTrainingSet(TrainingSetDef) error
 	GetTrainingSet(id ResourceID) (TrainingSetIterator, error)
+	// GetTrainingSetSplit returns the rows of a training set created with a Split that
+	// were assigned to split, which is either TrainingSetSplitTrain or TrainingSetSplitTest.
+	GetTrainingSetSplit(id ResourceID, split string) (TrainingSetIterator, error)
 	CreateTrainTestSplit(TrainTestSplitDef) (func() error, error)
 	GetTrainTestSplit(TrainTestSplitDef) (TrainingSetIterator, TrainingSetIterator, error)
+	// ExportTrainingSet writes the training set to location in the given file format
+	// and returns the paths of the files written.
+	ExportTrainingSet(id ResourceID, location pl.Location, format filestore.FileType) ([]filestore.Filepath, error)
 }
 
 type OfflineStoreBatchFeature interface {
@@ -575,6 +1087,9 @@ type TrainingSetIterator interface {
 	Next() bool
 	Features() []interface{}
 	Label() interface{}
+	// Labels returns the values of each of the training set's labels, in the
+	// order they were defined. Label is the first of them.
+	Labels() []interface{}
 	Err() error
 }
 
@@ -616,6 +1131,28 @@ type BatchFeatureIterator interface {
 	Close() error
 }
 
+// AsOfEntity is an entity whose feature values are read as they were at TS.
+type AsOfEntity struct {
+	Entity string
+	TS     time.Time
+}
+
+// AsOfBatchFeatureStore is implemented by offline stores that can serve batch
+// features for each entity as of its own timestamp, e.g. to backtest a model
+// against historical feature values.
+type AsOfBatchFeatureStore interface {
+	// GetBatchFeaturesAsOf returns a row per entity, in the order given, holding
+	// each feature's latest value at or before the entity's timestamp. A
+	// feature with no value by then is nil.
+	GetBatchFeaturesAsOf(ids []ResourceID, entities []AsOfEntity) (AsOfBatchFeatureIterator, error)
+}
+
+type AsOfBatchFeatureIterator interface {
+	BatchFeatureIterator
+	// AsOf is the timestamp the current row's features were read at.
+	AsOf() time.Time
+}
+
 // Used to implement sort.Interface
 type ResourceRecords []ResourceRecord
 
@@ -724,15 +1261,26 @@ type ResourceSchema struct {
 	TS             string
 	EntityMappings metadata.EntityMappings
 	SourceTable    pl.Location
+	// Casts maps entity and value columns to the type they're cast to when read.
+	// It's set by a ColumnCastOption.
+	Casts map[string]types.ScalarType
+	// ValueType is the declared type of the value column. Stores that can read
+	// the source's column types check it at registration. It isn't serialized.
+	ValueType types.ValueType
+	// TSFormat is how the timestamp column is parsed, if it isn't a native
+	// timestamp. It's set by a TimestampFormatOption.
+	TSFormat *TimestampFormat
 }
 
 type ResourceSchemaJSON struct {
-	Entity         string                  `json:"Entity"`
-	Value          string                  `json:"Value"`
-	TS             string                  `json:"TS"`
-	SourceTable    json.RawMessage         `json:"SourceTable"`
-	LocationType   pl.LocationType         `json:"LocationType"`
-	EntityMappings metadata.EntityMappings `json:"EntityMappings"`
+	Entity         string                      `json:"Entity"`
+	Value          string                      `json:"Value"`
+	TS             string                      `json:"TS"`
+	SourceTable    json.RawMessage             `json:"SourceTable"`
+	LocationType   pl.LocationType             `json:"LocationType"`
+	EntityMappings metadata.EntityMappings     `json:"EntityMappings"`
+	Casts          map[string]types.ScalarType `json:"Casts,omitempty"`
+	TSFormat       *TimestampFormat            `json:"TSFormat,omitempty"`
 }
 
 func (schema *ResourceSchema) Serialize() ([]byte, error) {
@@ -752,6 +1300,8 @@ func (schema *ResourceSchema) Serialize() ([]byte, error) {
 		SourceTable:    json.RawMessage(locationData),
 		LocationType:   schema.SourceTable.Type(),
 		EntityMappings: schema.EntityMappings,
+		Casts:          schema.Casts,
+		TSFormat:       schema.TSFormat,
 	}
 
 	return json.Marshal(data)
@@ -768,6 +1318,8 @@ func (schema *ResourceSchema) Deserialize(config []byte) error {
 	schema.Value = data.Value
 	schema.TS = data.TS
 	schema.EntityMappings = data.EntityMappings
+	schema.Casts = data.Casts
+	schema.TSFormat = data.TSFormat
 
 	var location pl.Location
 	switch data.LocationType {
@@ -825,6 +1377,126 @@ func (r ResourceSchema) Validate() error {
 	return nil
 }
 
+func (r ResourceSchema) isValueColumn(column string) bool {
+	return column != "" && (column == r.Value || column == r.EntityMappings.ValueColumn)
+}
+
+func (r ResourceSchema) isEntityColumn(column string) bool {
+	if column != "" && column == r.Entity {
+		return true
+	}
+	for _, m := range r.EntityMappings.Mappings {
+		if column == m.EntityColumn {
+			return true
+		}
+	}
+	return false
+}
+
+// timestampColumn returns the column the resource's timestamps are read from.
+func (r ResourceSchema) timestampColumn() string {
+	if r.TS != "" {
+		return r.TS
+	}
+	return r.EntityMappings.TimestampColumn
+}
+
+// withResourceOptions returns the schema with opts applied. Column casts and
+// timestamp formats are the only resource options, any other is rejected.
+func (r ResourceSchema) withResourceOptions(opts ...ResourceOption) (ResourceSchema, error) {
+	for _, opt := range opts {
+		switch typed := opt.(type) {
+		case *ColumnCastOption:
+			if err := typed.validate(r); err != nil {
+				return r, err
+			}
+			casts := make(map[string]types.ScalarType, len(r.Casts)+len(typed.Casts))
+			for column, target := range r.Casts {
+				casts[column] = target
+			}
+			for column, target := range typed.Casts {
+				casts[column] = target
+			}
+			r.Casts = casts
+		case *TimestampFormatOption:
+			if err := typed.validate(r); err != nil {
+				return r, err
+			}
+			format := typed.Format
+			r.TSFormat = &format
+		default:
+			return r, fferr.NewInvalidArgumentErrorf("unsupported resource option %s", opt.Type())
+		}
+	}
+	return r, nil
+}
+
+// checkNoColumnCasts fails if the schema casts columns, for stores that can't.
+func (r ResourceSchema) checkNoColumnCasts(store pt.Type) error {
+	if len(r.Casts) > 0 {
+		return fferr.NewUnimplementedErrorf("%s doesn't support column casts", store)
+	}
+	return nil
+}
+
+// checkNoTimestampFormat fails if the schema parses its timestamp column, for
+// stores that can't.
+func (r ResourceSchema) checkNoTimestampFormat(store pt.Type) error {
+	if r.TSFormat != nil {
+		return fferr.NewUnimplementedErrorf("%s doesn't support timestamp formats", store)
+	}
+	return nil
+}
+
+// checkUnparsed applies opts to the schema and fails if it casts columns or
+// parses its timestamp column, for stores that read source columns as they are.
+func (r ResourceSchema) checkUnparsed(store pt.Type, opts ...ResourceOption) error {
+	schema, err := r.withResourceOptions(opts...)
+	if err != nil {
+		return err
+	}
+	if err := schema.checkNoColumnCasts(store); err != nil {
+		return err
+	}
+	return schema.checkNoTimestampFormat(store)
+}
+
+// parseTimestamp returns expr, the expression that reads the timestamp column,
+// wrapped so that it's parsed with the schema's TSFormat. parse builds the
+// parsing expression in the store's dialect.
+func (r ResourceSchema) parseTimestamp(expr string, parse func(expr string, format TimestampFormat) string) string {
+	if r.TSFormat == nil {
+		return expr
+	}
+	return parse(expr, *r.TSFormat)
+}
+
+// castColumn returns expr, the expression that reads column, wrapped in a CAST if
+// the schema casts the column. sqlType names a type in the store's dialect and
+// errors if the store can't cast to it.
+func (r ResourceSchema) castColumn(column, expr string, sqlType func(types.ValueType) (string, error)) (string, error) {
+	target, ok := r.Casts[column]
+	if !ok {
+		return expr, nil
+	}
+	typeName, err := sqlType(target)
+	if err != nil {
+		return "", fferr.NewInvalidArgumentErrorf("column %s can't be cast to %s: %v", column, target, err)
+	}
+	return fmt.Sprintf("CAST(%s AS %s)", expr, typeName), nil
+}
+
+// checkCasts fails if sqlType can't name a type one of the schema's columns is
+// cast to.
+func (r ResourceSchema) checkCasts(sqlType func(types.ValueType) (string, error)) error {
+	for column := range r.Casts {
+		if _, err := r.castColumn(column, column, sqlType); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func (r ResourceSchema) ToColumnStringSet(resType OfflineResourceType) (stringset.StringSet, error) {
 	set := make(stringset.StringSet)
 	switch resType {
@@ -851,11 +1523,69 @@ type TableSchema struct {
 	Columns []TableColumn
 	// The complete URL that points to the location of the data file
 	SourceTable string
+	// CSVOptions is set when the data file is delimited text that doesn't
+	// use the default CSV format
+	CSVOptions *pl.CSVOptions
 }
 
 type TableSchemaJSONWrapper struct {
 	Columns     []TableColumnJSONWrapper
 	SourceTable string
+	CSVOptions  *pl.CSVOptions `json:",omitempty"`
+}
+
+// AsAvroSchema derives an Avro record schema from the table's columns and returns
+// it in its JSON form. Every field is a union with null so that missing values
+// round trip, and timestamps are stored as longs with the timestamp-micros logical
+// type, which is what Spark writes.
+func (schema *TableSchema) AsAvroSchema() (string, error) {
+	fields := make([]map[string]interface{}, len(schema.Columns))
+	for i, col := range schema.Columns {
+		avroType, err := avroScalarType(col.Scalar())
+		if err != nil {
+			wrapped := fferr.NewInvalidArgumentErrorf("could not derive Avro schema: %v", err)
+			wrapped.AddDetail("column", col.Name)
+			return "", wrapped
+		}
+		if col.IsVector() {
+			avroType = map[string]interface{}{"type": "array", "items": avroType}
+		}
+		fields[i] = map[string]interface{}{
+			"name":    col.Name,
+			"type":    []interface{}{"null", avroType},
+			"default": nil,
+		}
+	}
+	raw, err := json.Marshal(map[string]interface{}{
+		"type":   "record",
+		"name":   "Record",
+		"fields": fields,
+	})
+	if err != nil {
+		return "", fferr.NewInternalError(err)
+	}
+	return string(raw), nil
+}
+
+func avroScalarType(scalar types.ScalarType) (interface{}, error) {
+	switch scalar {
+	case types.Int8, types.Int16, types.Int32, types.UInt8, types.UInt16:
+		return "int", nil
+	case types.Int, types.Int64, types.UInt32, types.UInt64:
+		return "long", nil
+	case types.Float32:
+		return "float", nil
+	case types.Float64:
+		return "double", nil
+	case types.String:
+		return "string", nil
+	case types.Bool:
+		return "boolean", nil
+	case types.Timestamp, types.Datetime:
+		return map[string]interface{}{"type": "long", "logicalType": avroTimestampMicros}, nil
+	default:
+		return nil, fmt.Errorf("type %s has no Avro equivalent", scalar)
+	}
 }
 
 // This method converts the list of columns into a struct type that can be
@@ -905,6 +1635,7 @@ func (schema *TableSchema) AsReflectedStruct() reflect.Value {
 func (schema *TableSchema) Serialize() ([]byte, error) {
 	wrapper := &TableSchemaJSONWrapper{
 		SourceTable: schema.SourceTable,
+		CSVOptions:  schema.CSVOptions,
 		Columns:     make([]TableColumnJSONWrapper, len(schema.Columns)),
 	}
 	for i, col := range schema.Columns {
@@ -934,6 +1665,7 @@ func (schema *TableSchema) Deserialize(config []byte) error {
 		}
 	}
 	schema.SourceTable = wrapper.SourceTable
+	schema.CSVOptions = wrapper.CSVOptions
 	return nil
 }
 
@@ -999,7 +1731,11 @@ type TableColumn struct {
 type memoryOfflineStore struct {
 	tables           syncmap.Map
 	materializations syncmap.Map
-	trainingSets     syncmap.Map
+	// watermarks holds the latest materialized timestamp keyed by feature ResourceID.
+	watermarks   syncmap.Map
+	trainingSets syncmap.Map
+	// statePath is the file the store is saved to on Close, if set.
+	statePath string
 	BaseProvider
 }
 
@@ -1008,7 +1744,11 @@ var memoryFactorySingleton Provider
 func memoryOfflineStoreFactory(serializedConfig pc.SerializedConfig) (Provider, error) {
 	// Add mutex
 	if memoryFactorySingleton == nil {
-		memoryFactorySingleton = NewMemoryOfflineStore()
+		store, err := loadMemoryOfflineStore(config.GetLocalStateDir())
+		if err != nil {
+			return nil, err
+		}
+		memoryFactorySingleton = store
 		return memoryFactorySingleton, nil
 	} else {
 		return memoryFactorySingleton, nil
@@ -1042,10 +1782,14 @@ func (store *memoryOfflineStore) RegisterResourceFromSourceTable(id ResourceID,
 func (store *memoryOfflineStore) RegisterPrimaryFromSourceTable(
 	id ResourceID,
 	tableLocation pl.Location,
+	opts ...PrimaryOption,
 ) (PrimaryTable, error) {
 	if id.Name == "make" && id.Variant == "panic" {
 		panic("This is a panic")
 	}
+	if len(opts) > 0 {
+		return nil, fferr.NewInternalErrorf("Memory store does not support primary table options")
+	}
 	store.tables.Store(id, &memoryPrimaryTable{})
 	return &memoryPrimaryTable{}, nil
 }
@@ -1135,6 +1879,24 @@ func (store *memoryOfflineStore) ResourceLocation(id ResourceID, resource any) (
 	return nil, errors.New("ResourceLocation unsupported for this provider")
 }
 
+func (store *memoryOfflineStore) GetResourceStats(id ResourceID, resource any) (ResourceStats, error) {
+	if err := id.check(Feature, Label); err != nil {
+		return ResourceStats{}, err
+	}
+	table, err := store.getMemoryResourceTable(id)
+	if err != nil {
+		return ResourceStats{}, err
+	}
+	stats := ResourceStats{}
+	for _, rec := range table.records() {
+		stats.RowCount++
+		if rec.TS.After(stats.MaxTimestamp) {
+			stats.MaxTimestamp = rec.TS
+		}
+	}
+	return stats, nil
+}
+
 // Used to implement sort.Interface for sorting.
 type materializedRecords []ResourceRecord
 
@@ -1154,10 +1916,84 @@ func (store *memoryOfflineStore) GetBatchFeatures(tables []ResourceID) (BatchFea
 	return nil, nil
 }
 
+func (store *memoryOfflineStore) GetBatchFeaturesAsOf(ids []ResourceID, entities []AsOfEntity) (AsOfBatchFeatureIterator, error) {
+	if len(ids) == 0 {
+		return nil, fferr.NewInvalidArgumentError(fmt.Errorf("no features provided"))
+	}
+	features := make([]*memoryOfflineTable, len(ids))
+	for i, id := range ids {
+		feature, err := store.getMemoryResourceTable(id)
+		if err != nil {
+			return nil, err
+		}
+		features[i] = feature
+	}
+	rows := make([]memoryAsOfRow, len(entities))
+	for i, entity := range entities {
+		featureVals := make(GenericRecord, len(features))
+		for j, feature := range features {
+			if rec, has := feature.getLastRecordBefore(entity.Entity, entity.TS); has {
+				featureVals[j] = rec.Value
+			}
+		}
+		rows[i] = memoryAsOfRow{entity: entity, features: featureVals}
+	}
+	return &memoryAsOfBatchIterator{rows: rows, idx: -1}, nil
+}
+
+type memoryAsOfRow struct {
+	entity   AsOfEntity
+	features GenericRecord
+}
+
+type memoryAsOfBatchIterator struct {
+	rows []memoryAsOfRow
+	idx  int
+}
+
+func (it *memoryAsOfBatchIterator) Next() bool {
+	if it.idx+1 >= len(it.rows) {
+		return false
+	}
+	it.idx++
+	return true
+}
+
+func (it *memoryAsOfBatchIterator) Entity() interface{} {
+	return it.rows[it.idx].entity.Entity
+}
+
+func (it *memoryAsOfBatchIterator) AsOf() time.Time {
+	return it.rows[it.idx].entity.TS
+}
+
+func (it *memoryAsOfBatchIterator) Features() GenericRecord {
+	return it.rows[it.idx].features
+}
+
+func (it *memoryAsOfBatchIterator) Columns() []string {
+	return nil
+}
+
+func (it *memoryAsOfBatchIterator) Err() error {
+	return nil
+}
+
+func (it *memoryAsOfBatchIterator) Close() error {
+	return nil
+}
+
 func (store *memoryOfflineStore) CreateMaterialization(id ResourceID, opts MaterializationOptions) (
 	Materialization,
 	error,
 ) {
+	return store.materialize(id, time.Time{})
+}
+
+// materialize builds a materialization from the latest record of each entity
+// newer than since, and advances the feature's watermark. A zero since
+// materializes every entity.
+func (store *memoryOfflineStore) materialize(id ResourceID, since time.Time) (Materialization, error) {
 	if id.Type != Feature {
 		return nil, fferr.NewInvalidArgumentError(fmt.Errorf("only features can be materialized"))
 	}
@@ -1165,11 +2001,18 @@ func (store *memoryOfflineStore) CreateMaterialization(id ResourceID, opts Mater
 	if err != nil {
 		return nil, err
 	}
+	watermark := since
 	var matData materializedRecords
 	table.entityMap.Range(
 		func(key, value interface{}) bool {
 			records := value.([]ResourceRecord)
 			matRec := latestRecord(records)
+			if !matRec.TS.After(since) {
+				return true
+			}
+			if matRec.TS.After(watermark) {
+				watermark = matRec.TS
+			}
 			matData = append(matData, matRec)
 			return true
 		},
@@ -1183,6 +2026,7 @@ func (store *memoryOfflineStore) CreateMaterialization(id ResourceID, opts Mater
 		RowsPerChunk: defaultRowsPerChunk,
 	}
 	store.materializations.Store(matId, mat)
+	store.watermarks.Store(id, watermark)
 	return mat, nil
 }
 
@@ -1190,6 +2034,10 @@ func (store *memoryOfflineStore) SupportsMaterializationOption(opt Materializati
 	return false, nil
 }
 
+func (store *memoryOfflineStore) Capabilities() (Capabilities, error) {
+	return offlineStoreCapabilities(store)
+}
+
 func (store *memoryOfflineStore) GetMaterialization(id MaterializationID) (Materialization, error) {
 	mat, has := store.materializations.Load(id)
 	if !has {
@@ -1202,7 +2050,14 @@ func (store *memoryOfflineStore) UpdateMaterialization(id ResourceID, opts Mater
 	Materialization,
 	error,
 ) {
-	return store.CreateMaterialization(id, MaterializationOptions{Output: fs.Parquet})
+	if !opts.Incremental {
+		return store.CreateMaterialization(id, MaterializationOptions{Output: fs.Parquet})
+	}
+	watermark, has := store.watermarks.Load(id)
+	if !has {
+		return nil, fferr.NewDatasetNotFoundError(id.Name, id.Variant, fmt.Errorf("no materialization watermark found"))
+	}
+	return store.materialize(id, watermark.(time.Time))
 }
 
 func (store *memoryOfflineStore) DeleteMaterialization(id MaterializationID) error {
@@ -1227,6 +2082,12 @@ func (store *memoryOfflineStore) CreateTrainingSet(def TrainingSetDef) error {
 	if err := def.check(); err != nil {
 		return err
 	}
+	if err := def.checkSingleLabel(pt.MemoryOffline); err != nil {
+		return err
+	}
+	if err := def.checkNotPersisted(pt.MemoryOffline); err != nil {
+		return err
+	}
 	label, err := store.getMemoryResourceTable(def.Label)
 	if err != nil {
 		return err
@@ -1244,13 +2105,20 @@ func (store *memoryOfflineStore) CreateTrainingSet(def TrainingSetDef) error {
 	for i, rec := range labelRecs {
 		featureVals := make([]interface{}, len(features))
 		for i, feature := range features {
-			featureVals[i] = feature.getLastValueBefore(rec.Entity, rec.TS)
+			featureRec, has := feature.getLastRecordBefore(rec.Entity, rec.TS)
+			if !has || (def.MaxFeatureAge > 0 && featureRec.TS.Before(rec.TS.Add(-def.MaxFeatureAge))) {
+				continue
+			}
+			featureVals[i] = featureRec.Value
 		}
 		labelVal := rec.Value
 		trainingData[i] = trainingRow{
 			Features: featureVals,
 			Label:    labelVal,
 		}
+		if def.Split != nil {
+			trainingData[i].Split = def.Split.assign(rec.Entity, rec.TS)
+		}
 	}
 	store.trainingSets.Store(def.ID, trainingData)
 	return nil
@@ -1271,6 +2139,30 @@ func (store *memoryOfflineStore) GetTrainingSet(id ResourceID) (TrainingSetItera
 	return data.(trainingRows).Iterator(), nil
 }
 
+func (store *memoryOfflineStore) GetTrainingSetSplit(id ResourceID, split string) (TrainingSetIterator, error) {
+	if err := id.check(TrainingSet); err != nil {
+		return nil, err
+	}
+	if err := checkTrainingSetSplitName(split); err != nil {
+		return nil, err
+	}
+	data, has := store.trainingSets.Load(id)
+	if !has {
+		return nil, fferr.NewDatasetNotFoundError(id.Name, id.Variant, nil)
+	}
+	rows := data.(trainingRows)
+	splitRows := make(trainingRows, 0, len(rows))
+	for _, row := range rows {
+		if row.Split == "" {
+			return nil, fferr.NewInvalidArgumentErrorf("training set %s (%s) was not created with a split", id.Name, id.Variant)
+		}
+		if row.Split == split {
+			splitRows = append(splitRows, row)
+		}
+	}
+	return splitRows.Iterator(), nil
+}
+
 func (store *memoryOfflineStore) CreateTrainTestSplit(def TrainTestSplitDef) (func() error, error) {
 	// TODO properly implement this
 	dropFunc := func() error {
@@ -1279,6 +2171,10 @@ func (store *memoryOfflineStore) CreateTrainTestSplit(def TrainTestSplitDef) (fu
 	return dropFunc, nil
 }
 
+func (store *memoryOfflineStore) ExportTrainingSet(id ResourceID, location pl.Location, format filestore.FileType) ([]filestore.Filepath, error) {
+	return nil, fferr.NewUnimplementedErrorf("training set export is not supported for the in-memory offline store")
+}
+
 func (store *memoryOfflineStore) GetTrainTestSplit(def TrainTestSplitDef) (
 	TrainingSetIterator,
 	TrainingSetIterator,
@@ -1298,7 +2194,10 @@ func (store *memoryOfflineStore) GetTrainTestSplit(def TrainTestSplitDef) (
 }
 
 func (store *memoryOfflineStore) Close() error {
-	return nil
+	if store.statePath == "" {
+		return nil
+	}
+	return store.Save(store.statePath)
 }
 
 func (store *memoryOfflineStore) CheckHealth() (bool, error) {
@@ -1318,6 +2217,7 @@ func (rows trainingRows) Iterator() TrainingSetIterator {
 type trainingRow struct {
 	Features []interface{}
 	Label    interface{}
+	Split    string
 }
 
 type memoryTrainingRowsIterator struct {
@@ -1357,6 +2257,10 @@ func (it *memoryTrainingRowsIterator) Label() interface{} {
 	return it.data[it.idx].Label
 }
 
+func (it *memoryTrainingRowsIterator) Labels() []interface{} {
+	return []interface{}{it.data[it.idx].Label}
+}
+
 type memoryOfflineTable struct {
 	entityMap syncmap.Map
 }
@@ -1378,10 +2282,10 @@ func (table *memoryOfflineTable) records() []ResourceRecord {
 	return allRecs
 }
 
-func (table *memoryOfflineTable) getLastValueBefore(entity string, ts time.Time) interface{} {
+func (table *memoryOfflineTable) getLastRecordBefore(entity string, ts time.Time) (ResourceRecord, bool) {
 	recs, has := table.entityMap.Load(entity)
 	if !has {
-		return nil
+		return ResourceRecord{}, false
 	}
 	sortedRecs := ResourceRecords(recs.([]ResourceRecord))
 	sort.Sort(sortedRecs)
@@ -1390,13 +2294,13 @@ func (table *memoryOfflineTable) getLastValueBefore(entity string, ts time.Time)
 		if rec.TS.After(ts) {
 			// Entity was not yet set at timestamp, don't return a record.
 			if i == 0 {
-				return nil
+				return ResourceRecord{}, false
 			}
 			// Use the record before this, since it would have been before TS.
-			return sortedRecs[i-1].Value
+			return sortedRecs[i-1], true
 		} else if i == lastIdx {
 			// Every record happened before the TS, use the last record.
-			return rec.Value
+			return rec, true
 		}
 	}
 	// This line should never be able to be reached.
@@ -1547,6 +2451,27 @@ func replaceSourceName(query string, mapping []SourceMapping, sanitize sanitizat
 	return replacedQuery, nil
 }
 
+// ValidateTransformation checks a transformation before any job is submitted for it.
+// The query itself is checked when the transformation is registered, before its
+// templates are replaced, so this only checks the prepared config.
+func ValidateTransformation(config TransformationConfig) error {
+	if config.Type != SQLTransformation {
+		return nil
+	}
+	if strings.TrimSpace(config.Query) == "" {
+		return fferr.NewInvalidArgumentErrorf("transformation %s (%s) has an empty query", config.TargetTableID.Name, config.TargetTableID.Variant)
+	}
+	for _, m := range config.SourceMapping {
+		if m.Source == "" {
+			wrapped := fferr.NewInvalidArgumentErrorf("source mapping for template %s does not reference a registered source", m.Template)
+			wrapped.AddDetail("transformation", config.TargetTableID.Name)
+			wrapped.AddDetail("variant", config.TargetTableID.Variant)
+			return wrapped
+		}
+	}
+	return nil
+}
+
 func genericNumChunks(mat Materialization, rowsPerChunk int64) (int, error) {
 	_, numChunks, err := getNumRowsAndChunks(mat, rowsPerChunk)
 	return int(numChunks), err
@@ -1580,7 +2505,108 @@ func genericIterateChunk(mat Materialization, rowsPerChunk int64, idx int) (Feat
 	if end > rows {
 		end = rows
 	}
-	return mat.IterateSegment(start, end)
+	// Chunks are streamed a page at a time so that copying a chunk doesn't
+	// hold the whole chunk in memory.
+	return IteratePagedSegment(mat, start, end, PagedSegmentOptions{})
+}
+
+// defaultSegmentPageSize is the number of rows fetched per page by a paged segment
+// iterator when PageSize isn't set.
+const defaultSegmentPageSize int64 = 10_000
+
+// PagedSegmentOptions configures IteratePagedSegment.
+type PagedSegmentOptions struct {
+	// PageSize is the number of rows requested from the backing store at a time.
+	// Values less than 1 fall back to defaultSegmentPageSize.
+	PageSize int64
+}
+
+// IteratePagedSegment is a streaming alternative to Materialization.IterateSegment.
+// Rather than requesting the whole [begin, end) range at once, it fetches PageSize
+// rows at a time and only requests the next page once the current one is consumed,
+// so memory usage stays flat regardless of the size of the segment.
+func IteratePagedSegment(mat Materialization, begin, end int64, opts PagedSegmentOptions) (FeatureIterator, error) {
+	if begin < 0 || end < begin {
+		return nil, fferr.NewInvalidArgumentErrorf("invalid segment range\nStart: %d\nEnd: %d", begin, end)
+	}
+	pageSize := opts.PageSize
+	if pageSize < 1 {
+		pageSize = defaultSegmentPageSize
+	}
+	return &pagedSegmentIterator{
+		mat:      mat,
+		next:     begin,
+		end:      end,
+		pageSize: pageSize,
+	}, nil
+}
+
+type pagedSegmentIterator struct {
+	mat      Materialization
+	page     FeatureIterator
+	next     int64
+	end      int64
+	pageSize int64
+	err      error
+}
+
+func (it *pagedSegmentIterator) Next() bool {
+	if it.err != nil {
+		return false
+	}
+	for {
+		if it.page != nil {
+			if it.page.Next() {
+				return true
+			}
+			if err := it.closePage(); err != nil {
+				it.err = err
+				return false
+			}
+		}
+		if it.next >= it.end {
+			return false
+		}
+		pageEnd := it.next + it.pageSize
+		if pageEnd > it.end {
+			pageEnd = it.end
+		}
+		page, err := it.mat.IterateSegment(it.next, pageEnd)
+		if err != nil {
+			it.err = err
+			return false
+		}
+		it.page = page
+		it.next = pageEnd
+	}
+}
+
+func (it *pagedSegmentIterator) closePage() error {
+	page := it.page
+	it.page = nil
+	if err := page.Err(); err != nil {
+		page.Close()
+		return err
+	}
+	return page.Close()
+}
+
+func (it *pagedSegmentIterator) Value() ResourceRecord {
+	if it.page == nil {
+		return ResourceRecord{}
+	}
+	return it.page.Value()
+}
+
+func (it *pagedSegmentIterator) Err() error {
+	return it.err
+}
+
+func (it *pagedSegmentIterator) Close() error {
+	if it.page == nil {
+		return nil
+	}
+	return it.closePage()
 }
 
 func (def *TrainingSetDef) ToBuilderParams(logger logging.Logger, sanitizeTableNameFn func(pl.Location) (string, error)) (tsq.BuilderParams, error) {
@@ -1613,6 +2639,25 @@ func (def *TrainingSetDef) ToBuilderParams(logger logging.Logger, sanitizeTableN
 		logger.Debugw("Feature entity mapping", "entity_mappings", ft.EntityMappings.Mappings)
 		ftEntityNames = append(ftEntityNames, ft.EntityMappings.Mappings[0].Name)
 	}
+	lagFeatures := make([]tsq.LagFeature, len(def.LagFeatures))
+	for i, lag := range def.LagFeatures {
+		idx := -1
+		for j, id := range def.Features {
+			if id.Name == lag.FeatureName && id.Variant == lag.FeatureVariant {
+				idx = j
+				break
+			}
+		}
+		if idx == -1 || idx >= len(def.FeatureSourceMappings) {
+			logger.Errorw("Lag feature is not a feature of the training set", "lag_feature", lag)
+			return tsq.BuilderParams{}, fferr.NewInvalidArgumentErrorf("lag feature %s (%s) must also be a feature of the training set", lag.FeatureName, lag.FeatureVariant)
+		}
+		alias := lag.LagName
+		if alias == "" {
+			alias = fmt.Sprintf("feature__%s__%s__lag_%s", lag.FeatureName, lag.FeatureVariant, lag.LagDelta)
+		}
+		lagFeatures[i] = tsq.LagFeature{FeatureIndex: idx, Lag: lag.LagDelta, ColumnAlias: alias}
+	}
 	logger.Debugw("Label entity mapping", "entity_mappings", def.LabelSourceMapping.EntityMappings)
 	return tsq.BuilderParams{
 		LabelEntityMappings:    def.LabelSourceMapping.EntityMappings,
@@ -1621,5 +2666,21 @@ func (def *TrainingSetDef) ToBuilderParams(logger logging.Logger, sanitizeTableN
 		SanitizedFeatureTables: ftTableNames,
 		FeatureNameVariants:    ftNameVariants,
 		FeatureEntityNames:     ftEntityNames,
+		LagFeatures:            lagFeatures,
+		MaxFeatureAge:          def.MaxFeatureAge,
 	}, nil
 }
+
+// splitColumn returns the tsquery split column for the training set, hashed with
+// the dialect's bucket expression and aliased with quoteChar, or nil if the
+// training set isn't split.
+func (def TrainingSetDef) splitColumn(bucket func(entity, ts string, seed int64) string, quoteChar string) func(entity, ts string) string {
+	if def.Split == nil {
+		return nil
+	}
+	split := *def.Split
+	alias := quoteChar + trainingSetSplitColumn + quoteChar
+	return func(entity, ts string) string {
+		return trainingSetSplitCase(split, bucket(entity, ts, split.Seed), alias)
+	}
+}
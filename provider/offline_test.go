@@ -10,25 +10,35 @@ package provider
 import (
 	"bytes"
 	"database/sql"
+	"database/sql/driver"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"math/rand"
 	"os"
 	"reflect"
+	"regexp"
+	"runtime"
+	"sort"
+	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
+	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/featureform/fferr"
 	"github.com/featureform/filestore"
 	fs "github.com/featureform/filestore"
+	"github.com/featureform/logging"
 	"github.com/featureform/metadata"
 	pb "github.com/featureform/metadata/proto"
 	pl "github.com/featureform/provider/location"
 	pc "github.com/featureform/provider/provider_config"
 	ps "github.com/featureform/provider/provider_schema"
 	pt "github.com/featureform/provider/provider_type"
+	tsq "github.com/featureform/provider/tsquery"
 	"github.com/featureform/provider/types"
 	"github.com/google/uuid"
 	"github.com/parquet-go/parquet-go"
@@ -69,39 +79,11 @@ func (test *OfflineStoreTest) Run() {
 		"TrainingDefShorthand":   testTrainingSetDefShorthand,
 		"ResourceLocation":       testResourceLocation,
 	}
-
-	for name, fn := range testFns {
-		nameConst := name
-		fnConst := fn
-		t.Run(nameConst, func(t *testing.T) {
-			t.Parallel()
-			fnConst(t, store)
-		})
-	}
-
-	t.Cleanup(func() {
-		if err := store.Close(); err != nil {
-			t.Errorf("%v - %v\n", store.Type(), err)
+	// Every store with a SQL test dialect also runs the shared SQL fixtures.
+	if _, isSQL := sqlTestDialects[store.Type()]; isSQL {
+		for name, fn := range sqlOfflineTestFns {
+			testFns[name] = fn
 		}
-	})
-}
-
-func (test *OfflineStoreTest) RunSQL() {
-	t := test.t
-	store := test.store
-	testFns := map[string]func(*testing.T, OfflineStore){
-		"PrimaryTableCreate":                 testPrimaryCreateTable,
-		"PrimaryTableWrite":                  testPrimaryTableWrite,
-		"Transformation":                     testTransform,
-		"TransformationUpdate":               testTransformUpdate,
-		"TransformationUpdateWithFeature":    testTransformUpdateWithFeatures,
-		"CreateDuplicatePrimaryTable":        testCreateDuplicatePrimaryTable,
-		"ChainTransformations":               testChainTransform,
-		"CreateResourceFromSource":           testCreateResourceFromSource,
-		"CreateResourceFromSourceNoTS":       testCreateResourceFromSourceNoTS,
-		"CreatePrimaryFromSource":            testCreatePrimaryFromSource,
-		"CreatePrimaryFromNonExistentSource": testCreatePrimaryFromNonExistentSource,
-		"TrainTestSplit":                     testTrainTestSplit,
 	}
 
 	for name, fn := range testFns {
@@ -120,6 +102,21 @@ func (test *OfflineStoreTest) RunSQL() {
 	})
 }
 
+var sqlOfflineTestFns = map[string]func(*testing.T, OfflineStore){
+	"PrimaryTableCreate":                 testPrimaryCreateTable,
+	"PrimaryTableWrite":                  testPrimaryTableWrite,
+	"Transformation":                     testTransform,
+	"TransformationUpdate":               testTransformUpdate,
+	"TransformationUpdateWithFeature":    testTransformUpdateWithFeatures,
+	"CreateDuplicatePrimaryTable":        testCreateDuplicatePrimaryTable,
+	"ChainTransformations":               testChainTransform,
+	"CreateResourceFromSource":           testCreateResourceFromSource,
+	"CreateResourceFromSourceNoTS":       testCreateResourceFromSourceNoTS,
+	"CreatePrimaryFromSource":            testCreatePrimaryFromSource,
+	"CreatePrimaryFromNonExistentSource": testCreatePrimaryFromNonExistentSource,
+	"TrainTestSplit":                     testTrainTestSplit,
+}
+
 func randomID(types ...OfflineResourceType) ResourceID {
 	var t OfflineResourceType
 	if len(types) == 0 {
@@ -1996,7 +1993,7 @@ func testTransform(t *testing.T, store OfflineStore) {
 					Variant: uuid.NewString(),
 					Type:    Transformation,
 				},
-				Query: "SELECT * FROM tb",
+				Query: "SELECT * FROM {table}",
 				SourceMapping: []SourceMapping{
 					SourceMapping{
 						Template: "tb",
@@ -2041,7 +2038,7 @@ func testTransform(t *testing.T, store OfflineStore) {
 					Variant: uuid.NewString(),
 					Type:    Transformation,
 				},
-				Query: "SELECT COUNT(*) as total_count FROM tb",
+				Query: "SELECT COUNT(*) AS {total_count} FROM {table}",
 				SourceMapping: []SourceMapping{
 					SourceMapping{
 						Template: "tb",
@@ -2178,7 +2175,7 @@ func testTransformUpdateWithFeatures(t *testing.T, store OfflineStore) {
 					Variant: uuid.NewString(),
 					Type:    Transformation,
 				},
-				Query: "SELECT * FROM tb",
+				Query: "SELECT * FROM {table}",
 				SourceMapping: []SourceMapping{
 					SourceMapping{
 						Template: "tb",
@@ -2385,7 +2382,7 @@ func testTransformUpdate(t *testing.T, store OfflineStore) {
 					Variant: uuid.NewString(),
 					Type:    Transformation,
 				},
-				Query: "SELECT * FROM tb",
+				Query: "SELECT * FROM {table}",
 				SourceMapping: []SourceMapping{
 					{
 						Template: "tb",
@@ -2443,7 +2440,7 @@ func testTransformUpdate(t *testing.T, store OfflineStore) {
 					Variant: uuid.NewString(),
 					Type:    Transformation,
 				},
-				Query: "SELECT COUNT(*) as total_count FROM tb",
+				Query: "SELECT COUNT(*) AS {total_count} FROM {table}",
 				SourceMapping: []SourceMapping{
 					{
 						Template: "tb",
@@ -2618,7 +2615,7 @@ func testTransformCreateFeature(t *testing.T, store OfflineStore) {
 					Name: uuid.NewString(),
 					Type: Feature,
 				},
-				Query: "SELECT entity, int, ts FROM tb",
+				Query: "SELECT {entity}, {int}, {ts} FROM {table}",
 				SourceMapping: []SourceMapping{
 					SourceMapping{
 						Template: "tb",
@@ -2645,8 +2642,7 @@ func testTransformCreateFeature(t *testing.T, store OfflineStore) {
 			t.Fatalf("Could not write records: %v", err)
 		}
 
-		tableName := getTableName(string(store.Type()), table.GetName())
-		test.Config.Query = strings.Replace(test.Config.Query, "tb", tableName, 1)
+		modifyTransformationConfig(t, t.Name(), table.GetName(), store.Type(), &test.Config)
 		if err := store.CreateTransformation(test.Config); err != nil {
 			t.Fatalf("Could not create transformation: %v", err)
 		}
@@ -2739,7 +2735,7 @@ func testChainTransform(t *testing.T, store OfflineStore) {
 					Variant: firstTransformVariant,
 					Type:    Transformation,
 				},
-				Query: "SELECT entity, int_col, flt_col, str_col FROM tb",
+				Query: "SELECT {entity}, {int_col}, {flt_col}, {str_col} FROM {table}",
 				SourceMapping: []SourceMapping{
 					SourceMapping{
 						Template: "tb",
@@ -2775,7 +2771,7 @@ func testChainTransform(t *testing.T, store OfflineStore) {
 					Variant: uuid.NewString(),
 					Type:    Transformation,
 				},
-				Query: "SELECT COUNT(*) as total_count FROM tb",
+				Query: "SELECT COUNT(*) AS {total_count} FROM {table}",
 				SourceMapping: []SourceMapping{
 					SourceMapping{
 						Template: "tb",
@@ -2804,7 +2800,7 @@ func testChainTransform(t *testing.T, store OfflineStore) {
 			Variant: firstTransformVariant,
 			Type:    Transformation,
 		},
-		Query: "SELECT entity, int_col, flt_col, str_col FROM tb",
+		Query: "SELECT {entity}, {int_col}, {flt_col}, {str_col} FROM {table}",
 		SourceMapping: []SourceMapping{
 			SourceMapping{
 				Template: "tb",
@@ -2865,7 +2861,7 @@ func testChainTransform(t *testing.T, store OfflineStore) {
 			Variant: secondTransformVariant,
 			Type:    Transformation,
 		},
-		Query: "SELECT Count(*) as total_count FROM tb",
+		Query: "SELECT COUNT(*) AS {total_count} FROM {table}",
 		SourceMapping: []SourceMapping{
 			SourceMapping{
 				Template: "tb",
@@ -2936,7 +2932,7 @@ func testTransformToMaterialize(t *testing.T, store OfflineStore) {
 					Name: firstTransformName,
 					Type: Transformation,
 				},
-				Query: "SELECT entity, int, flt, str FROM tb",
+				Query: "SELECT {entity}, {int}, {flt}, {str} FROM {table}",
 				SourceMapping: []SourceMapping{
 					SourceMapping{
 						Template: "tb",
@@ -2962,21 +2958,21 @@ func testTransformToMaterialize(t *testing.T, store OfflineStore) {
 		t.Fatalf("Could not write batch: %v", err)
 	}
 
-	tableName := getTableName(string(store.Type()), table.GetName())
 	config := TransformationConfig{
 		Type: SQLTransformation,
 		TargetTableID: ResourceID{
 			Name: firstTransformName,
 			Type: Transformation,
 		},
-		Query: fmt.Sprintf("SELECT entity, int, flt, str FROM %s", tableName),
+		Query: "SELECT {entity}, {int}, {flt}, {str} FROM {table}",
 		SourceMapping: []SourceMapping{
 			SourceMapping{
-				Template: tableName,
-				Source:   tableName,
+				Template: "tb",
+				Source:   "TBD",
 			},
 		},
 	}
+	modifyTransformationConfig(t, t.Name(), table.GetName(), store.Type(), &config)
 	if err := store.CreateTransformation(config); err != nil {
 		t.Fatalf("Could not create transformation: %v", err)
 	}
@@ -3515,25 +3511,71 @@ func TestReplaceSourceName(t *testing.T) {
 
 }
 
-func getTableName(testName string, tableName string) string {
-	if strings.Contains(testName, "BIGQUERY") {
+// sqlTestDialect is how a SQL offline store's dialect quotes identifiers, so the
+// shared SQL fixtures can be written once and run against every SQL store.
+type sqlTestDialect struct {
+	// quoteTable returns a primary table's name as it's referenced in a query.
+	quoteTable func(table string) string
+	// quoteColumn quotes a column name or alias, e.g. the alias of a COUNT(*).
+	// Stores like Snowflake upper-case unquoted identifiers, so aliases have to
+	// be quoted to come back with the name the fixtures expect.
+	quoteColumn func(column string) string
+}
+
+func doubleQuotedSQLDialect() sqlTestDialect {
+	return sqlTestDialect{quoteTable: sanitize, quoteColumn: sanitize}
+}
+
+func backtickQuote(ident string) string {
+	return "`" + strings.ReplaceAll(ident, "`", "``") + "`"
+}
+
+// sqlTestDialects lists the offline stores that run SQL transformations in the
+// database. OfflineStoreTest.Run runs the shared SQL fixtures against every
+// store listed here, so a new SQL store only needs an entry to be covered.
+var sqlTestDialects = map[pt.Type]func() sqlTestDialect{
+	pt.PostgresOffline:  doubleQuotedSQLDialect,
+	pt.RedshiftOffline:  doubleQuotedSQLDialect,
+	pt.SnowflakeOffline: doubleQuotedSQLDialect,
+	pt.MySqlOffline: func() sqlTestDialect {
+		return sqlTestDialect{quoteTable: backtickQuote, quoteColumn: backtickQuote}
+	},
+	pt.ClickHouseOffline: func() sqlTestDialect {
+		return sqlTestDialect{quoteTable: SanitizeClickHouseIdentifier, quoteColumn: SanitizeClickHouseIdentifier}
+	},
+	pt.BigQueryOffline: func() sqlTestDialect {
+		// BigQuery tables have to be qualified by their project and dataset.
 		prefix := fmt.Sprintf("%s.%s", os.Getenv("BIGQUERY_PROJECT_ID"), os.Getenv("BIGQUERY_DATASET_ID"))
-		tableName = fmt.Sprintf("`%s.%s`", prefix, tableName)
-	} else if strings.Contains(testName, "CLICKHOUSE") {
-		tableName = SanitizeClickHouseIdentifier(tableName)
-	} else {
-		tableName = sanitize(tableName)
-	}
-	return tableName
+		return sqlTestDialect{
+			quoteTable: func(table string) string {
+				return backtickQuote(fmt.Sprintf("%s.%s", prefix, table))
+			},
+			quoteColumn: backtickQuote,
+		}
+	},
 }
 
-func sanitizeTableName(testName string, tableName string) string {
-	if !strings.Contains(testName, "BIGQUERY") {
-		tableName = sanitize(tableName)
-	} else if strings.Contains(testName, "CLICKHOUSE") {
-		tableName = SanitizeClickHouseIdentifier(tableName)
+func getSQLTestDialect(t *testing.T, providerType pt.Type) sqlTestDialect {
+	dialect, ok := sqlTestDialects[providerType]
+	if !ok {
+		t.Fatalf("No SQL test dialect for provider type %s", providerType)
 	}
-	return tableName
+	return dialect()
+}
+
+var sqlFixturePlaceholder = regexp.MustCompile(`\{(\w+)\}`)
+
+// render fills in a shared fixture's query. {table} is replaced with the quoted
+// table name, and any other {identifier} with the quoted column name, e.g.
+// "SELECT COUNT(*) AS {total_count} FROM {table}".
+func (dialect sqlTestDialect) render(query, table string) string {
+	return sqlFixturePlaceholder.ReplaceAllStringFunc(query, func(placeholder string) string {
+		ident := placeholder[1 : len(placeholder)-1]
+		if ident == "table" {
+			return dialect.quoteTable(table)
+		}
+		return dialect.quoteColumn(ident)
+	})
 }
 
 func modifyTransformationConfig(t *testing.T, testName, tableName string, providerType pt.Type, config *TransformationConfig) {
@@ -3542,11 +3584,30 @@ func modifyTransformationConfig(t *testing.T, testName, tableName string, provid
 		// In contrast to the SQL provider, that only needed change is the table name to perform the required transformation configuration,
 		// The Spark implementation needs to update the source mappings to ensure the source file is used in the transformation query.
 		config.SourceMapping[0].Source = tableName
-	case pt.MemoryOffline, pt.BigQueryOffline, pt.PostgresOffline, pt.MySqlOffline, pt.SnowflakeOffline, pt.ClickHouseOffline, pt.RedshiftOffline:
-		tableName := getTableName(string(providerType), tableName)
-		config.Query = strings.Replace(config.Query, "tb", tableName, 1)
 	default:
-		t.Fatalf("Unrecognized provider type %s", providerType)
+		config.Query = getSQLTestDialect(t, providerType).render(config.Query, tableName)
+	}
+}
+
+func TestSQLTestDialectRender(t *testing.T) {
+	t.Setenv("BIGQUERY_PROJECT_ID", "project")
+	t.Setenv("BIGQUERY_DATASET_ID", "dataset")
+	query := "SELECT COUNT(*) AS {total_count} FROM {table}"
+	expected := map[pt.Type]string{
+		pt.PostgresOffline:   `SELECT COUNT(*) AS "total_count" FROM "tbl"`,
+		pt.RedshiftOffline:   `SELECT COUNT(*) AS "total_count" FROM "tbl"`,
+		pt.SnowflakeOffline:  `SELECT COUNT(*) AS "total_count" FROM "tbl"`,
+		pt.MySqlOffline:      "SELECT COUNT(*) AS `total_count` FROM `tbl`",
+		pt.ClickHouseOffline: "SELECT COUNT(*) AS `total_count` FROM `tbl`",
+		pt.BigQueryOffline:   "SELECT COUNT(*) AS `total_count` FROM `project.dataset.tbl`",
+	}
+	if len(expected) != len(sqlTestDialects) {
+		t.Fatalf("Expected a case for each of the %d SQL test dialects", len(sqlTestDialects))
+	}
+	for providerType, exp := range expected {
+		if actual := getSQLTestDialect(t, providerType).render(query, "tbl"); actual != exp {
+			t.Fatalf("%s: expected %s, got %s", providerType, exp, actual)
+		}
 	}
 }
 
@@ -4967,6 +5028,16 @@ func TestResourceSchemaSerializationDeserialization(t *testing.T) {
 			},
 			expectErr: false,
 		},
+		{
+			name: "Column Casts",
+			schema: &ResourceSchema{
+				Entity:      "entity4",
+				Value:       "value4",
+				SourceTable: pl.NewSQLLocation("test_table"),
+				Casts:       map[string]types.ScalarType{"value4": types.Int},
+			},
+			expectErr: false,
+		},
 	}
 
 	for _, tc := range testCases {
@@ -4990,6 +5061,7 @@ func TestResourceSchemaSerializationDeserialization(t *testing.T) {
 			assert.Equal(t, tc.schema.SourceTable.Location(), got.SourceTable.Location())
 			assert.Equal(t, tc.schema.SourceTable.Type(), got.SourceTable.Type())
 			assert.DeepEqual(t, tc.schema.EntityMappings, got.EntityMappings)
+			assert.DeepEqual(t, tc.schema.Casts, got.Casts)
 		})
 	}
 }
@@ -5052,3 +5124,1221 @@ func TestResourceSchemaValidation(t *testing.T) {
 		})
 	}
 }
+
+func TestResourceSchemaColumnCasts(t *testing.T) {
+	schema := ResourceSchema{
+		Entity:      "user",
+		Value:       "amount",
+		TS:          "ts",
+		SourceTable: pl.NewSQLLocation("transactions"),
+	}
+	queries := postgresSQLQueries{}
+	tests := []struct {
+		name     string
+		casts    map[string]types.ScalarType
+		expected string
+	}{
+		{"String to int", map[string]types.ScalarType{"amount": types.Int}, `CAST("amount" AS INT)`},
+		{"Int to float", map[string]types.ScalarType{"amount": types.Float64}, `CAST("amount" AS FLOAT8)`},
+		{"No cast", nil, `"amount"`},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			casted, err := schema.withResourceOptions(&ColumnCastOption{Casts: test.casts})
+			if err != nil {
+				t.Fatalf("Failed to apply casts: %v", err)
+			}
+			value, err := casted.castColumn(casted.Value, sanitize(casted.Value), queries.determineColumnType)
+			if err != nil {
+				t.Fatalf("Failed to cast column: %v", err)
+			}
+			if value != test.expected {
+				t.Fatalf("Expected %s, got %s", test.expected, value)
+			}
+		})
+	}
+}
+
+func TestResourceSchemaInvalidColumnCasts(t *testing.T) {
+	schema := ResourceSchema{
+		Entity:      "user",
+		Value:       "amount",
+		TS:          "ts",
+		SourceTable: pl.NewSQLLocation("transactions"),
+	}
+	tests := []struct {
+		name  string
+		casts map[string]types.ScalarType
+	}{
+		{"Timestamp column", map[string]types.ScalarType{"ts": types.String}},
+		{"Unknown column", map[string]types.ScalarType{"missing": types.Int}},
+		{"Unsupported value type", map[string]types.ScalarType{"amount": types.Timestamp}},
+		{"Non-string entity", map[string]types.ScalarType{"user": types.Int}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if _, err := schema.withResourceOptions(&ColumnCastOption{Casts: test.casts}); err == nil {
+				t.Fatalf("Expected an error for casts %v", test.casts)
+			}
+		})
+	}
+	// Casts that are valid in general can still be unsupported by a store.
+	casted, err := schema.withResourceOptions(&ColumnCastOption{Casts: map[string]types.ScalarType{"amount": types.UInt8}})
+	if err == nil {
+		err = casted.checkCasts(sparkColumnType)
+	}
+	if err == nil {
+		t.Fatalf("Expected an error casting to %s", types.UInt8)
+	}
+}
+
+func TestResourceSchemaTimestampFormat(t *testing.T) {
+	schema := ResourceSchema{
+		Entity:      "user",
+		Value:       "amount",
+		TS:          "ts",
+		SourceTable: pl.NewSQLLocation("transactions"),
+	}
+	tests := []struct {
+		name     string
+		format   TimestampFormat
+		expected string
+	}{
+		{"Epoch seconds", TimestampFormat{Encoding: EpochSeconds}, `to_timestamp(CAST("ts" AS DOUBLE PRECISION))`},
+		{"Epoch millis", TimestampFormat{Encoding: EpochMillis}, `to_timestamp(CAST("ts" AS DOUBLE PRECISION) / 1000)`},
+		{"ISO-8601", TimestampFormat{Encoding: ISO8601}, `CAST("ts" AS TIMESTAMP) AT TIME ZONE 'UTC'`},
+		{"ISO-8601 in a non-UTC zone", TimestampFormat{Encoding: ISO8601, Timezone: "America/New_York"}, `CAST("ts" AS TIMESTAMP) AT TIME ZONE 'America/New_York'`},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			parsed, err := schema.withResourceOptions(&TimestampFormatOption{Format: test.format})
+			if err != nil {
+				t.Fatalf("Failed to apply timestamp format: %v", err)
+			}
+			ts := parsed.parseTimestamp(sanitize(parsed.TS), postgresParseTimestamp)
+			if !strings.Contains(ts, test.expected) {
+				t.Fatalf("Expected %s to contain %s", ts, test.expected)
+			}
+			serialized, err := parsed.Serialize()
+			if err != nil {
+				t.Fatalf("Failed to serialize schema: %v", err)
+			}
+			deserialized := ResourceSchema{}
+			if err := deserialized.Deserialize(serialized); err != nil {
+				t.Fatalf("Failed to deserialize schema: %v", err)
+			}
+			if !reflect.DeepEqual(deserialized.TSFormat, parsed.TSFormat) {
+				t.Fatalf("Expected format %v, got %v", parsed.TSFormat, deserialized.TSFormat)
+			}
+		})
+	}
+	if ts := schema.parseTimestamp(sanitize(schema.TS), postgresParseTimestamp); ts != `"ts"` {
+		t.Fatalf("Expected an unparsed timestamp without a format, got %s", ts)
+	}
+}
+
+func TestResourceSchemaInvalidTimestampFormat(t *testing.T) {
+	schema := ResourceSchema{
+		Entity:      "user",
+		Value:       "amount",
+		TS:          "ts",
+		SourceTable: pl.NewSQLLocation("transactions"),
+	}
+	tests := []struct {
+		name   string
+		schema ResourceSchema
+		format TimestampFormat
+	}{
+		{"Unknown encoding", schema, TimestampFormat{Encoding: "rfc822"}},
+		{"Epoch with a timezone", schema, TimestampFormat{Encoding: EpochSeconds, Timezone: "America/New_York"}},
+		{"Unknown timezone", schema, TimestampFormat{Encoding: ISO8601, Timezone: "Mars/Olympus_Mons"}},
+		{"Quoted timezone", schema, TimestampFormat{Encoding: ISO8601, Timezone: "UTC'; DROP TABLE users; --"}},
+		{"No timestamp column", ResourceSchema{Entity: "user", Value: "amount"}, TimestampFormat{Encoding: ISO8601}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if _, err := test.schema.withResourceOptions(&TimestampFormatOption{Format: test.format}); err == nil {
+				t.Fatalf("Expected an error for format %v", test.format)
+			}
+		})
+	}
+}
+
+func TestResourceSchemaCheckUnparsed(t *testing.T) {
+	schema := ResourceSchema{
+		Entity:      "user",
+		Value:       "amount",
+		TS:          "ts",
+		SourceTable: pl.NewSQLLocation("transactions"),
+	}
+	tests := []struct {
+		name string
+		opt  ResourceOption
+	}{
+		{"Column casts", &ColumnCastOption{Casts: map[string]types.ScalarType{"amount": types.Int}}},
+		{"Timestamp format", &TimestampFormatOption{Format: TimestampFormat{Encoding: EpochSeconds}}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := schema.checkUnparsed(pt.BigQueryOffline, test.opt)
+			var unimplemented *fferr.UnimplementedError
+			if !errors.As(err, &unimplemented) {
+				t.Fatalf("Expected an unimplemented error, got %v", err)
+			}
+			store := &clickHouseOfflineStore{}
+			_, err = store.RegisterResourceFromSourceTable(ResourceID{"name", "variant", Feature}, schema, test.opt)
+			if !errors.As(err, &unimplemented) {
+				t.Fatalf("Expected an unimplemented error from ClickHouse, got %v", err)
+			}
+		})
+	}
+	if err := schema.checkUnparsed(pt.BigQueryOffline); err != nil {
+		t.Fatalf("Expected a schema without options to pass: %v", err)
+	}
+}
+
+// syntheticMaterialization generates rows on demand so that tests can iterate
+// very large segments without holding them in memory.
+type syntheticMaterialization struct {
+	rows         int64
+	segmentCalls int
+	maxSegment   int64
+}
+
+func (mat *syntheticMaterialization) ID() MaterializationID {
+	return "synthetic"
+}
+
+func (mat *syntheticMaterialization) NumRows() (int64, error) {
+	return mat.rows, nil
+}
+
+func (mat *syntheticMaterialization) IterateSegment(begin, end int64) (FeatureIterator, error) {
+	mat.segmentCalls++
+	if end-begin > mat.maxSegment {
+		mat.maxSegment = end - begin
+	}
+	return &syntheticFeatureIterator{idx: begin - 1, end: end}, nil
+}
+
+func (mat *syntheticMaterialization) NumChunks() (int, error) {
+	return genericNumChunks(mat, defaultRowsPerChunk)
+}
+
+func (mat *syntheticMaterialization) IterateChunk(idx int) (FeatureIterator, error) {
+	return genericIterateChunk(mat, defaultRowsPerChunk, idx)
+}
+
+func (mat *syntheticMaterialization) Location() pl.Location {
+	return nil
+}
+
+type syntheticFeatureIterator struct {
+	idx int64
+	end int64
+}
+
+func (it *syntheticFeatureIterator) Next() bool {
+	if it.idx+1 >= it.end {
+		return false
+	}
+	it.idx++
+	return true
+}
+
+func (it *syntheticFeatureIterator) Value() ResourceRecord {
+	return ResourceRecord{Entity: strconv.FormatInt(it.idx, 10), Value: it.idx}
+}
+
+func (it *syntheticFeatureIterator) Err() error {
+	return nil
+}
+
+func (it *syntheticFeatureIterator) Close() error {
+	return nil
+}
+
+func TestIteratePagedSegment(t *testing.T) {
+	tests := []struct {
+		name          string
+		begin, end    int64
+		pageSize      int64
+		expectedPages int
+	}{
+		{"Even Pages", 0, 100, 10, 10},
+		{"Partial Last Page", 5, 28, 10, 3},
+		{"Default Page Size", 0, 25_000, 0, 3},
+		{"Empty Segment", 10, 10, 10, 0},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			mat := &syntheticMaterialization{rows: test.end}
+			it, err := IteratePagedSegment(mat, test.begin, test.end, PagedSegmentOptions{PageSize: test.pageSize})
+			if err != nil {
+				t.Fatalf("Failed to create iterator: %v", err)
+			}
+			expected := test.begin
+			for it.Next() {
+				if it.Value().Value != expected {
+					t.Fatalf("Expected value %d, got %v", expected, it.Value().Value)
+				}
+				expected++
+			}
+			if err := it.Err(); err != nil {
+				t.Fatalf("Iterator failed: %v", err)
+			}
+			if err := it.Close(); err != nil {
+				t.Fatalf("Failed to close iterator: %v", err)
+			}
+			if expected != test.end {
+				t.Fatalf("Expected to stop at %d, stopped at %d", test.end, expected)
+			}
+			if mat.segmentCalls != test.expectedPages {
+				t.Fatalf("Expected %d pages, got %d", test.expectedPages, mat.segmentCalls)
+			}
+		})
+	}
+	if _, err := IteratePagedSegment(&syntheticMaterialization{}, 10, 5, PagedSegmentOptions{}); err == nil {
+		t.Fatalf("Expected error on invalid range")
+	}
+}
+
+func TestIterateChunkIsPaged(t *testing.T) {
+	mat := &syntheticMaterialization{rows: defaultRowsPerChunk + 5}
+	it, err := mat.IterateChunk(0)
+	if err != nil {
+		t.Fatalf("Failed to iterate chunk: %v", err)
+	}
+	var count int64
+	for it.Next() {
+		count++
+	}
+	if err := it.Err(); err != nil {
+		t.Fatalf("Iterator failed: %v", err)
+	}
+	if count != defaultRowsPerChunk {
+		t.Fatalf("Expected %d rows, got %d", defaultRowsPerChunk, count)
+	}
+	if mat.maxSegment > defaultSegmentPageSize {
+		t.Fatalf("Expected pages of at most %d rows, got %d", defaultSegmentPageSize, mat.maxSegment)
+	}
+}
+
+func TestIteratePagedSegmentFlatMemory(t *testing.T) {
+	if testing.Short() {
+		t.Skip()
+	}
+	const rows = 5_000_000
+	const pageSize = 1_000
+	mat := &syntheticMaterialization{rows: rows}
+	it, err := IteratePagedSegment(mat, 0, rows, PagedSegmentOptions{PageSize: pageSize})
+	if err != nil {
+		t.Fatalf("Failed to create iterator: %v", err)
+	}
+	var stats runtime.MemStats
+	runtime.GC()
+	runtime.ReadMemStats(&stats)
+	baseline := stats.HeapAlloc
+	var peak uint64
+	count := 0
+	for it.Next() {
+		_ = it.Value()
+		count++
+		if count%500_000 == 0 {
+			runtime.GC()
+			runtime.ReadMemStats(&stats)
+			if stats.HeapAlloc > peak {
+				peak = stats.HeapAlloc
+			}
+		}
+	}
+	if err := it.Err(); err != nil {
+		t.Fatalf("Iterator failed: %v", err)
+	}
+	if count != rows {
+		t.Fatalf("Expected %d rows, got %d", rows, count)
+	}
+	if mat.maxSegment > pageSize {
+		t.Fatalf("Expected pages of at most %d rows, got %d", pageSize, mat.maxSegment)
+	}
+	// Heap usage should not scale with the number of rows iterated.
+	const maxGrowth = 16 << 20
+	if peak > baseline && peak-baseline > maxGrowth {
+		t.Fatalf("Heap grew by %d bytes while iterating", peak-baseline)
+	}
+}
+
+func TestMemoryTrainingSetSplitIsDeterministic(t *testing.T) {
+	labelID := ResourceID{"label", "v", Label}
+	featureID := ResourceID{"feature", "v", Feature}
+	def := TrainingSetDef{
+		ID:       ResourceID{"split", "v", TrainingSet},
+		Label:    labelID,
+		Features: []ResourceID{featureID},
+		Split:    &TrainingSetSplit{TrainFraction: 0.7, Seed: 42},
+	}
+	schema := TableSchema{
+		Columns: []TableColumn{
+			{Name: "entity", ValueType: types.String},
+			{Name: "value", ValueType: types.Int},
+			{Name: "ts", ValueType: types.Timestamp},
+		},
+	}
+	const numRows = 200
+	readSplits := func() map[string][]int {
+		store := NewMemoryOfflineStore()
+		labels, err := store.CreateResourceTable(labelID, schema)
+		if err != nil {
+			t.Fatalf("could not create label table: %v", err)
+		}
+		features, err := store.CreateResourceTable(featureID, schema)
+		if err != nil {
+			t.Fatalf("could not create feature table: %v", err)
+		}
+		ts := time.UnixMilli(0).UTC()
+		for i := 0; i < numRows; i++ {
+			entity := fmt.Sprintf("entity_%d", i)
+			if err := features.Write(ResourceRecord{Entity: entity, Value: i, TS: ts}); err != nil {
+				t.Fatalf("could not write feature: %v", err)
+			}
+			if err := labels.Write(ResourceRecord{Entity: entity, Value: i, TS: ts.Add(time.Hour)}); err != nil {
+				t.Fatalf("could not write label: %v", err)
+			}
+		}
+		if err := store.CreateTrainingSet(def); err != nil {
+			t.Fatalf("could not create training set: %v", err)
+		}
+		splits := make(map[string][]int)
+		for _, split := range []string{TrainingSetSplitTrain, TrainingSetSplitTest} {
+			iter, err := store.GetTrainingSetSplit(def.ID, split)
+			if err != nil {
+				t.Fatalf("could not get %s split: %v", split, err)
+			}
+			for iter.Next() {
+				splits[split] = append(splits[split], iter.Label().(int))
+			}
+			if err := iter.Err(); err != nil {
+				t.Fatalf("could not iterate %s split: %v", split, err)
+			}
+			sort.Ints(splits[split])
+		}
+		return splits
+	}
+
+	first := readSplits()
+	second := readSplits()
+	if !reflect.DeepEqual(first, second) {
+		t.Fatalf("splits differ across runs:\nfirst:  %v\nsecond: %v", first, second)
+	}
+	train, test := len(first[TrainingSetSplitTrain]), len(first[TrainingSetSplitTest])
+	if train+test != numRows {
+		t.Fatalf("expected %d rows across both splits, got %d", numRows, train+test)
+	}
+	if train == 0 || test == 0 {
+		t.Fatalf("expected rows in both splits, got train=%d test=%d", train, test)
+	}
+}
+
+func TestTrainingSetSplitCheck(t *testing.T) {
+	tests := []struct {
+		name     string
+		fraction float64
+		wantErr  bool
+	}{
+		{"Valid", 0.8, false},
+		{"Zero", 0, true},
+		{"One", 1, true},
+		{"Negative", -0.5, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := TrainingSetSplit{TrainFraction: tt.fraction}.check()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("check() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestMaxFeatureAgeFilter(t *testing.T) {
+	tests := []struct {
+		name     string
+		queries  OfflineTableQueries
+		maxAge   time.Duration
+		expected string
+	}{
+		{"DefaultUnset", &defaultOfflineSQLQueries{}, 0, ""},
+		{"Default", &defaultOfflineSQLQueries{}, 90 * time.Minute, " AND t1.ts >= t0.ts - INTERVAL '5400 seconds'"},
+		{"Postgres", &postgresSQLQueries{}, time.Hour, " AND t1.ts >= t0.ts - INTERVAL '3600 seconds'"},
+		{"MySQL", &mySQLQueries{}, time.Hour, " AND t1.ts >= t0.ts - INTERVAL 3600 SECOND"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.queries.maxFeatureAgeFilter("t1.ts", "t0.ts", tt.maxAge); got != tt.expected {
+				t.Fatalf("expected %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestRedshiftTrainingSetExport(t *testing.T) {
+	dir, err := filestore.NewEmptyDirpath(filestore.S3)
+	if err != nil {
+		t.Fatalf("could not create dir path: %v", err)
+	}
+	if err := dir.ParseDirPath("s3://bucket/exports"); err != nil {
+		t.Fatalf("could not parse dir path: %v", err)
+	}
+	location := pl.NewFileLocation(dir)
+	tests := []struct {
+		name     string
+		iamRole  string
+		expected string
+		wantErr  bool
+	}{
+		{"RoleARN", "arn:aws:iam::123456789012:role/unload", "IAM_ROLE 'arn:aws:iam::123456789012:role/unload' FORMAT AS PARQUET", false},
+		{"DefaultRole", "default", "IAM_ROLE default FORMAT AS PARQUET", false},
+		{"MissingRole", "", "", true},
+		{"InvalidRole", "unload' FORMAT AS CSV", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			if err != nil {
+				t.Fatalf("could not create mock db: %v", err)
+			}
+			defer db.Close()
+			queries := redshiftSQLQueries{iamRole: tt.iamRole}
+			if !tt.wantErr {
+				mock.ExpectExec(regexp.QuoteMeta(tt.expected)).WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectQuery("stl_unload_log").WillReturnRows(sqlmock.NewRows([]string{"path"}).AddRow("s3://bucket/exports/part_0000_part_00.parquet"))
+			}
+			files, err := queries.trainingSetExport(db, "training_set", location, filestore.Parquet)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expected an error exporting with IAM role %q", tt.iamRole)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("could not export training set: %v", err)
+			}
+			if len(files) != 1 || files[0].ToURI() != "s3://bucket/exports/part_0000_part_00.parquet" {
+				t.Fatalf("unexpected exported files: %v", files)
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Fatalf("unmet expectations: %v", err)
+			}
+		})
+	}
+}
+
+func TestRedshiftSourceImport(t *testing.T) {
+	newFile := func(t *testing.T, fsType filestore.FileStoreType, uri string) filestore.Filepath {
+		fp, err := filestore.NewEmptyFilepath(fsType)
+		if err != nil {
+			t.Fatalf("could not create file path: %v", err)
+		}
+		if err := fp.ParseFilePath(uri); err != nil {
+			t.Fatalf("could not parse file path: %v", err)
+		}
+		return fp
+	}
+	s3File := newFile(t, filestore.S3, "s3a://bucket/featureform/Transformation/t/v/part-0.parquet")
+	gcsFile := newFile(t, filestore.GCS, "gs://bucket/featureform/Transformation/t/v/part-0.parquet")
+	tests := []struct {
+		name          string
+		iamRole       string
+		file          filestore.Filepath
+		unimplemented bool
+	}{
+		{"S3", "default", s3File, false},
+		{"MissingRole", "", s3File, true},
+		{"GCS", "default", gcsFile, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			if err != nil {
+				t.Fatalf("could not create mock db: %v", err)
+			}
+			defer db.Close()
+			queries := redshiftSQLQueries{iamRole: tt.iamRole}
+			if !tt.unimplemented {
+				expected := `COPY "source" FROM 's3://bucket/featureform/Transformation/t/v/part-0.parquet' IAM_ROLE default FORMAT AS PARQUET`
+				mock.ExpectExec(regexp.QuoteMeta(expected)).WillReturnResult(sqlmock.NewResult(0, 2))
+			}
+			err = queries.sourceImport(db, "source", []filestore.Filepath{tt.file})
+			if tt.unimplemented {
+				if _, ok := err.(*fferr.UnimplementedError); !ok {
+					t.Fatalf("expected an UnimplementedError, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("could not import source: %v", err)
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Fatalf("unmet expectations: %v", err)
+			}
+		})
+	}
+}
+
+func TestIncrementalSQLTransformation(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("could not create mock db: %v", err)
+	}
+	defer db.Close()
+	queries := &postgresSQLQueries{}
+	queries.setVariableBinding(PostgresBindingStyle)
+	store := &sqlOfflineStore{db: db, query: queries}
+	config := TransformationConfig{
+		Type:              SQLTransformation,
+		TargetTableID:     ResourceID{Name: "clicks", Variant: "v1", Type: Transformation},
+		Query:             "SELECT * FROM events",
+		IncrementalColumn: "ts",
+	}
+	table, err := store.getTransformationTableName(config.TargetTableID)
+	if err != nil {
+		t.Fatalf("could not get table name: %v", err)
+	}
+	watermarks := sanitize(transformationWatermarkTable)
+	expectWatermark := func(watermark string) {
+		mock.ExpectQuery(regexp.QuoteMeta(fmt.Sprintf("SELECT MAX(\"ts\") FROM %s", sanitize(table)))).
+			WillReturnRows(sqlmock.NewRows([]string{"max"}).AddRow(watermark))
+		mock.ExpectExec(regexp.QuoteMeta(fmt.Sprintf("DELETE FROM %s", watermarks))).
+			WithArgs(table).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec(regexp.QuoteMeta(fmt.Sprintf("INSERT INTO %s", watermarks))).
+			WithArgs(table, watermark).WillReturnResult(sqlmock.NewResult(0, 1))
+	}
+
+	// The first run builds the whole transformation and stores its watermark.
+	mock.ExpectExec(regexp.QuoteMeta(fmt.Sprintf("CREATE TABLE  %s AS SELECT * FROM events", sanitize(table)))).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectExec(regexp.QuoteMeta("CREATE TABLE IF NOT EXISTS " + watermarks)).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectBegin()
+	expectWatermark("2024-05-01 00:00:00")
+	mock.ExpectCommit()
+	if err := store.CreateTransformation(config); err != nil {
+		t.Fatalf("could not create transformation: %v", err)
+	}
+
+	// An update only appends the rows past the watermark, then advances it.
+	mock.ExpectExec(regexp.QuoteMeta("CREATE TABLE IF NOT EXISTS " + watermarks)).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(regexp.QuoteMeta(fmt.Sprintf("SELECT watermark FROM %s WHERE table_name = $1", watermarks))).
+		WithArgs(table).WillReturnRows(sqlmock.NewRows([]string{"watermark"}).AddRow("2024-05-01 00:00:00"))
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta(fmt.Sprintf("INSERT INTO %s SELECT * FROM ( SELECT * FROM events ) AS incremental WHERE incremental.\"ts\" > $1", sanitize(table)))).
+		WithArgs("2024-05-01 00:00:00").WillReturnResult(sqlmock.NewResult(0, 1))
+	expectWatermark("2024-05-02 00:00:00")
+	mock.ExpectCommit()
+	if err := store.UpdateTransformation(config); err != nil {
+		t.Fatalf("could not update transformation: %v", err)
+	}
+
+	// Without a stored watermark, the update rebuilds the transformation.
+	mock.ExpectExec(regexp.QuoteMeta("CREATE TABLE IF NOT EXISTS " + watermarks)).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(regexp.QuoteMeta(fmt.Sprintf("SELECT watermark FROM %s", watermarks))).
+		WithArgs(table).WillReturnRows(sqlmock.NewRows([]string{"watermark"}))
+	mock.ExpectQuery(regexp.QuoteMeta(fmt.Sprintf("TRUNCATE TABLE %s", sanitize(table)))).
+		WillReturnRows(sqlmock.NewRows(nil))
+	mock.ExpectExec(regexp.QuoteMeta("CREATE TABLE IF NOT EXISTS " + watermarks)).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectBegin()
+	expectWatermark("2024-05-03 00:00:00")
+	mock.ExpectCommit()
+	if err := store.UpdateTransformation(config); err != nil {
+		t.Fatalf("could not rebuild transformation: %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
+
+func TestValidateTransformation(t *testing.T) {
+	mapping := []SourceMapping{
+		{Template: "{{name.variant}}", Source: "featureform_primary__name__variant"},
+	}
+	tests := []struct {
+		name    string
+		config  TransformationConfig
+		wantErr bool
+	}{
+		{"Valid", TransformationConfig{Type: SQLTransformation, Query: "SELECT * FROM {{name.variant}}", SourceMapping: mapping}, false},
+		{"DFIsSkipped", TransformationConfig{Type: DFTransformation}, false},
+		{"Empty", TransformationConfig{Type: SQLTransformation, Query: "  "}, true},
+		{"MissingSource", TransformationConfig{Type: SQLTransformation, Query: "SELECT * FROM {{name.variant}}", SourceMapping: []SourceMapping{{Template: "{{name.variant}}"}}}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateTransformation(tt.config)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ValidateTransformation() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				var invalidArg *fferr.InvalidArgumentError
+				if !errors.As(err, &invalidArg) {
+					t.Fatalf("expected an InvalidArgumentError, got %T", err)
+				}
+			}
+		})
+	}
+}
+
+func TestTrainingSetDefLabelsCheck(t *testing.T) {
+	mapping := func(entity string) SourceMapping {
+		return SourceMapping{
+			Template:       "SELECT * FROM labels",
+			Source:         "labels",
+			EntityMappings: &metadata.EntityMappings{Mappings: []metadata.EntityMapping{{Name: entity, EntityColumn: "entity"}}},
+		}
+	}
+	first := ResourceID{"label_1", "default", Label}
+	second := ResourceID{"label_2", "default", Label}
+	tests := []struct {
+		name    string
+		def     TrainingSetDef
+		wantErr bool
+	}{
+		{"TwoLabels", TrainingSetDef{Labels: []ResourceID{first, second}}, false},
+		{"SharedEntity", TrainingSetDef{Labels: []ResourceID{first, second}, LabelSourceMappings: []SourceMapping{mapping("user"), mapping("user")}}, false},
+		{"LabelIsFirst", TrainingSetDef{Label: first, Labels: []ResourceID{first, second}}, false},
+		{"DifferentEntities", TrainingSetDef{Labels: []ResourceID{first, second}, LabelSourceMappings: []SourceMapping{mapping("user"), mapping("item")}}, true},
+		{"LabelNotFirst", TrainingSetDef{Label: second, Labels: []ResourceID{first, second}}, true},
+		{"DuplicateLabel", TrainingSetDef{Labels: []ResourceID{first, first}}, true},
+		{"NotALabel", TrainingSetDef{Labels: []ResourceID{first, {"feature", "default", Feature}}}, true},
+		{"MissingMapping", TrainingSetDef{Labels: []ResourceID{first, second}, LabelSourceMappings: []SourceMapping{mapping("user")}}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			def := tt.def
+			def.ID = ResourceID{"training_set", "default", TrainingSet}
+			def.Features = []ResourceID{{"feature", "default", Feature}}
+			err := def.check()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("check() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && def.Label != first {
+				t.Fatalf("expected Label to be the first label, got %v", def.Label)
+			}
+		})
+	}
+}
+
+func TestSQLTrainingSetMultipleLabels(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("could not create mock db: %v", err)
+	}
+	defer db.Close()
+	queries := &postgresSQLQueries{}
+	queries.setVariableBinding(PostgresBindingStyle)
+	store := &sqlOfflineStore{db: db, query: queries}
+	def := TrainingSetDef{
+		ID:       ResourceID{"training_set", "default", TrainingSet},
+		Features: []ResourceID{{"feature", "default", Feature}},
+		Labels:   []ResourceID{{"clicked", "default", Label}, {"purchased", "default", Label}},
+	}
+	if err := def.check(); err != nil {
+		t.Fatalf("could not check training set: %v", err)
+	}
+	table, err := store.getTrainingSetName(def.ID)
+	if err != nil {
+		t.Fatalf("could not get table name: %v", err)
+	}
+	secondLabel := sanitize("featureform_resource_label__purchased__default")
+	mock.ExpectExec(regexp.QuoteMeta(fmt.Sprintf(
+		"CREATE TABLE %s AS (SELECT %s, l.value as label, %s FROM", sanitize(table), sanitize("featureform_resource_feature__feature__default"), secondLabel))).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	if err := queries.trainingSetCreate(store, def, table, "featureform_resource_label__clicked__default"); err != nil {
+		t.Fatalf("could not create training set: %v", err)
+	}
+
+	// The iterator returns every label column after the features.
+	mock.ExpectQuery("SELECT").WillReturnRows(
+		sqlmock.NewRows([]string{"featureform_resource_feature__feature__default", "label", "featureform_resource_label__purchased__default"}).
+			AddRow(1.5, true, false).
+			AddRow(2.5, false, nil),
+	)
+	rows, err := db.Query("SELECT")
+	if err != nil {
+		t.Fatalf("could not query rows: %v", err)
+	}
+	iter := store.newsqlTrainingSetIterator(rows, []interface{}{pgFloat, pgBool, pgBool}, 2)
+	expected := [][]interface{}{{true, false}, {false, nil}}
+	i := 0
+	for iter.Next() {
+		if !reflect.DeepEqual(iter.Labels(), expected[i]) {
+			t.Fatalf("expected labels %v, got %v", expected[i], iter.Labels())
+		}
+		if iter.Label() != expected[i][0] {
+			t.Fatalf("expected label %v, got %v", expected[i][0], iter.Label())
+		}
+		if len(iter.Features()) != 1 {
+			t.Fatalf("expected one feature, got %v", iter.Features())
+		}
+		i++
+	}
+	if err := iter.Err(); err != nil {
+		t.Fatalf("iteration failed: %v", err)
+	}
+	if i != len(expected) {
+		t.Fatalf("expected %d rows, got %d", len(expected), i)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
+
+func TestShiftTimestamp(t *testing.T) {
+	week := 7 * 24 * time.Hour
+	tests := []struct {
+		name     string
+		shift    func(string, time.Duration) string
+		expected string
+	}{
+		{"Default", (&defaultOfflineSQLQueries{}).shiftTimestamp, "(t1.ts + INTERVAL '604800 seconds')"},
+		{"Postgres", (&postgresSQLQueries{}).shiftTimestamp, "(t1.ts + INTERVAL '604800 seconds')"},
+		{"Redshift", (&redshiftSQLQueries{}).shiftTimestamp, "(t1.ts + INTERVAL '604800 seconds')"},
+		{"Snowflake", snowflakeShiftTimestamp, "DATEADD(second, 604800, t1.ts)"},
+		{"BigQuery", bqShiftTimestamp, "TIMESTAMP_ADD(t1.ts, INTERVAL 604800 SECOND)"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.shift("t1.ts", week); got != tt.expected {
+				t.Fatalf("expected %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestSQLTrainingSetLagFeatures(t *testing.T) {
+	feature := ResourceID{"wave_power", "default", Feature}
+	def := TrainingSetDef{
+		ID:       ResourceID{"training_set", "default", TrainingSet},
+		Label:    ResourceID{"wave_height", "default", Label},
+		Features: []ResourceID{feature},
+		LagFeatures: []LagFeatureDef{
+			{FeatureName: feature.Name, FeatureVariant: feature.Variant, LagName: "wave_power_last_week", LagDelta: 7 * 24 * time.Hour},
+			{FeatureName: feature.Name, FeatureVariant: feature.Variant, LagDelta: time.Hour},
+		},
+	}
+	featureTable := "featureform_resource_feature__wave_power__default"
+	tests := []struct {
+		name     string
+		queries  OfflineTableQueries
+		expected []string
+	}{
+		{
+			"Postgres",
+			&postgresSQLQueries{},
+			[]string{
+				fmt.Sprintf("SELECT %s, %s, %s, l.value as label FROM", sanitize(featureTable), sanitize("wave_power_last_week"), sanitize(featureTable+"_lag_1h0m0s")),
+				fmt.Sprintf("FROM %s WHERE entity=l.entity and (ts + INTERVAL '604800 seconds') <= l.ts ORDER BY ts desc LIMIT 1) t1 on t1.entity=l.entity", sanitize(featureTable)),
+				fmt.Sprintf("FROM %s WHERE entity=l.entity and (ts + INTERVAL '3600 seconds') <= l.ts ORDER BY ts desc LIMIT 1) t2 on t2.entity=l.entity  )", sanitize(featureTable)),
+			},
+		},
+		{
+			"Redshift",
+			&redshiftSQLQueries{},
+			[]string{
+				fmt.Sprintf("SELECT %s, %s, %s, label FROM", sanitize(featureTable), sanitize("wave_power_last_week"), sanitize(featureTable+"_lag_1h0m0s")),
+				"ORDER BY \"time\", t1_rnk, t2_rnk, t3_rnk DESC",
+				"AS t2 ON (t2.entity=t0.entity AND (t2.ts + INTERVAL '604800 seconds') <= t0.ts)",
+				"AS t3 ON (t3.entity=t0.entity AND (t3.ts + INTERVAL '3600 seconds') <= t0.ts) )) WHERE rn=1",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var query string
+			capture := sqlmock.QueryMatcherFunc(func(_, actual string) error {
+				query = actual
+				return nil
+			})
+			db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(capture))
+			if err != nil {
+				t.Fatalf("could not create mock db: %v", err)
+			}
+			defer db.Close()
+			store := &sqlOfflineStore{db: db, query: tt.queries}
+			table, err := store.getTrainingSetName(def.ID)
+			if err != nil {
+				t.Fatalf("could not get table name: %v", err)
+			}
+			mock.ExpectExec("CREATE TABLE").WillReturnResult(sqlmock.NewResult(0, 0))
+			if err := tt.queries.trainingSetCreate(store, def, table, "featureform_resource_label__wave_height__default"); err != nil {
+				t.Fatalf("could not create training set: %v", err)
+			}
+			for _, expected := range tt.expected {
+				if !strings.Contains(query, expected) {
+					t.Fatalf("expected query to contain %q, got %s", expected, query)
+				}
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Fatalf("unmet expectations: %v", err)
+			}
+		})
+	}
+}
+
+func TestSQLGetResourceStats(t *testing.T) {
+	maxTS := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name     string
+		id       ResourceID
+		columns  []string
+		values   []driver.Value
+		expected string
+		stats    ResourceStats
+	}{
+		{
+			"Feature",
+			ResourceID{"wave_power", "default", Feature},
+			[]string{"count", "max"},
+			[]driver.Value{int64(3), maxTS},
+			fmt.Sprintf("SELECT COUNT(*), MAX(ts) FROM %s", sanitize("featureform_resource_feature__wave_power__default")),
+			ResourceStats{RowCount: 3, MaxTimestamp: maxTS},
+		},
+		{
+			"EmptyLabel",
+			ResourceID{"wave_height", "default", Label},
+			[]string{"count", "max"},
+			[]driver.Value{int64(0), nil},
+			fmt.Sprintf("SELECT COUNT(*), MAX(ts) FROM %s", sanitize("featureform_resource_label__wave_height__default")),
+			ResourceStats{},
+		},
+		{
+			"Transformation",
+			ResourceID{"waves", "default", Transformation},
+			[]string{"count"},
+			[]driver.Value{int64(7)},
+			"SELECT COUNT(*) FROM",
+			ResourceStats{RowCount: 7},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var query string
+			capture := sqlmock.QueryMatcherFunc(func(_, actual string) error {
+				query = actual
+				return nil
+			})
+			db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(capture))
+			if err != nil {
+				t.Fatalf("could not create mock db: %v", err)
+			}
+			defer db.Close()
+			store := &sqlOfflineStore{db: db, query: &defaultOfflineSQLQueries{}}
+			mock.ExpectQuery("SELECT").WillReturnRows(sqlmock.NewRows(tt.columns).AddRow(tt.values...))
+			stats, err := store.GetResourceStats(tt.id, nil)
+			if err != nil {
+				t.Fatalf("could not get resource stats: %v", err)
+			}
+			if !strings.Contains(query, tt.expected) {
+				t.Fatalf("expected query to contain %q, got %s", tt.expected, query)
+			}
+			if !reflect.DeepEqual(tt.stats, stats) {
+				t.Fatalf("expected stats %v, got %v", tt.stats, stats)
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Fatalf("unmet expectations: %v", err)
+			}
+		})
+	}
+}
+
+func TestMemoryGetResourceStats(t *testing.T) {
+	store := NewMemoryOfflineStore()
+	id := ResourceID{"wave_power", "default", Feature}
+	table, err := store.CreateResourceTable(id, TableSchema{})
+	if err != nil {
+		t.Fatalf("could not create resource table: %v", err)
+	}
+	newest := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	records := []ResourceRecord{
+		{Entity: "a", Value: 1, TS: newest.Add(-time.Hour)},
+		{Entity: "a", Value: 2, TS: newest},
+		{Entity: "b", Value: 3, TS: newest.Add(-time.Minute)},
+	}
+	for _, rec := range records {
+		if err := table.Write(rec); err != nil {
+			t.Fatalf("could not write record: %v", err)
+		}
+	}
+	stats, err := store.GetResourceStats(id, nil)
+	if err != nil {
+		t.Fatalf("could not get resource stats: %v", err)
+	}
+	if expected := (ResourceStats{RowCount: 3, MaxTimestamp: newest}); !reflect.DeepEqual(expected, stats) {
+		t.Fatalf("expected stats %v, got %v", expected, stats)
+	}
+}
+
+func TestSQLProfileSource(t *testing.T) {
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	if err != nil {
+		t.Fatalf("could not create mock db: %v", err)
+	}
+	defer db.Close()
+	store := &sqlOfflineStore{
+		db:    db,
+		query: &defaultOfflineSQLQueries{},
+		getDb: func(database, schema string) (*sql.DB, error) { return db, nil },
+	}
+	sv := metadata.WrapProtoSourceVariant(&pb.SourceVariant{
+		Definition: &pb.SourceVariant_PrimaryData{
+			PrimaryData: &pb.PrimaryData{
+				Location: &pb.PrimaryData_Table{Table: &pb.SQLTable{Name: "waves"}},
+			},
+		},
+	})
+	columns := sqlmock.NewRowsWithColumnDefinition(
+		sqlmock.NewColumn("buoy").OfType("VARCHAR", ""),
+		sqlmock.NewColumn("height").OfType("FLOAT8", 0.0),
+		sqlmock.NewColumn("observed_on").OfType("TIMESTAMPTZ", time.Time{}),
+	)
+	mock.ExpectQuery(`SELECT * FROM "waves" LIMIT 0`).WillReturnRows(columns)
+	observed := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	profileQuery := `SELECT COUNT(*), COUNT(*) - COUNT("buoy"), COUNT(DISTINCT "buoy"), ` +
+		`COUNT(*) - COUNT("height"), COUNT(DISTINCT "height"), MIN("height"), MAX("height"), ` +
+		`COUNT(*) - COUNT("observed_on"), COUNT(DISTINCT "observed_on"), MIN("observed_on"), MAX("observed_on") ` +
+		`FROM (SELECT * FROM "waves" LIMIT 100) AS profile_sample`
+	mock.ExpectQuery(profileQuery).WillReturnRows(
+		sqlmock.NewRows([]string{"count", "n0", "d0", "n1", "d1", "min1", "max1", "n2", "d2", "min2", "max2"}).
+			AddRow(int64(100), int64(0), int64(4), int64(2), int64(57), 0.5, 12.25, int64(0), int64(100), observed.Add(-time.Hour), observed),
+	)
+	profile, err := store.ProfileSource(ResourceID{"waves", "default", Primary}, *sv, ProfileOptions{SampleSize: 100})
+	if err != nil {
+		t.Fatalf("could not profile source: %v", err)
+	}
+	expected := SourceProfile{
+		RowCount: 100,
+		Columns: []ColumnProfile{
+			{Name: "buoy", DistinctCount: 4},
+			{Name: "height", NullCount: 2, DistinctCount: 57, Min: "0.5", Max: "12.25"},
+			{Name: "observed_on", DistinctCount: 100, Min: "2024-03-01T11:00:00Z", Max: "2024-03-01T12:00:00Z"},
+		},
+	}
+	if !reflect.DeepEqual(expected, profile) {
+		t.Fatalf("expected profile %v, got %v", expected, profile)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
+
+func TestToBuilderParamsLagFeatures(t *testing.T) {
+	entityMappings := &metadata.EntityMappings{Mappings: []metadata.EntityMapping{{Name: "location", EntityColumn: "location_id"}}, ValueColumn: "wave_height_ft", TimestampColumn: "observed_on"}
+	def := TrainingSetDef{
+		Features:           []ResourceID{{"wave_power", "default", Feature}},
+		LabelSourceMapping: SourceMapping{Location: pl.NewSQLLocation("labels"), EntityMappings: entityMappings},
+		FeatureSourceMappings: []SourceMapping{{
+			Location:       pl.NewSQLLocation("features"),
+			Columns:        &metadata.ResourceVariantColumns{Entity: "location_id", Value: "wave_power_kj", TS: "measured_on"},
+			EntityMappings: &metadata.EntityMappings{Mappings: []metadata.EntityMapping{{Name: "location", EntityColumn: "location_id"}}},
+		}},
+		LagFeatures: []LagFeatureDef{{FeatureName: "wave_power", FeatureVariant: "default", LagDelta: time.Hour}},
+	}
+	tableName := func(loc pl.Location) (string, error) {
+		return loc.Location(), nil
+	}
+	logger := logging.NewTestLogger(t)
+	params, err := def.ToBuilderParams(logger, tableName)
+	if err != nil {
+		t.Fatalf("could not build params: %v", err)
+	}
+	expected := []tsq.LagFeature{{FeatureIndex: 0, Lag: time.Hour, ColumnAlias: "feature__wave_power__default__lag_1h0m0s"}}
+	if !reflect.DeepEqual(params.LagFeatures, expected) {
+		t.Fatalf("expected lag features %v, got %v", expected, params.LagFeatures)
+	}
+
+	def.LagFeatures[0].FeatureName = "swell_period"
+	if _, err := def.ToBuilderParams(logger, tableName); err == nil {
+		t.Fatalf("expected an error lagging a feature that isn't in the training set")
+	}
+}
+
+// countingSQLDriver counts the connections that are open, so tests can check
+// that a store closes everything it opened.
+type countingSQLDriver struct {
+	open int64
+}
+
+func (d *countingSQLDriver) Open(name string) (driver.Conn, error) {
+	atomic.AddInt64(&d.open, 1)
+	return &countingSQLConn{driver: d}, nil
+}
+
+type countingSQLConn struct {
+	driver *countingSQLDriver
+}
+
+func (c *countingSQLConn) Prepare(query string) (driver.Stmt, error) {
+	return nil, fmt.Errorf("not supported")
+}
+
+func (c *countingSQLConn) Close() error {
+	atomic.AddInt64(&c.driver.open, -1)
+	return nil
+}
+
+func (c *countingSQLConn) Begin() (driver.Tx, error) {
+	return nil, fmt.Errorf("not supported")
+}
+
+var (
+	countingDriver         = &countingSQLDriver{}
+	registerCountingDriver sync.Once
+)
+
+func TestSQLOfflineStoreCloseReleasesConnections(t *testing.T) {
+	registerCountingDriver.Do(func() {
+		sql.Register("counting", countingDriver)
+	})
+	// Each iteration is a job that gets a provider, uses connections to a few
+	// databases, and closes the provider when it's done.
+	for i := 0; i < 100; i++ {
+		store, err := NewSQLOfflineStore(SQLOfflineStoreConfig{
+			ConnectionURL: "default",
+			Driver:        "counting",
+			ProviderType:  pt.PostgresOffline,
+			QueryImpl:     &defaultOfflineSQLQueries{},
+			ConnectionStringBuilder: func(database, schema string) (string, error) {
+				return fmt.Sprintf("%s/%s", database, schema), nil
+			},
+		})
+		if err != nil {
+			t.Fatalf("could not create store: %v", err)
+		}
+		if _, err := store.CheckHealth(); err != nil {
+			t.Fatalf("could not ping store: %v", err)
+		}
+		for _, database := range []string{"a", "b", "a"} {
+			db, err := store.getDb(database, "public")
+			if err != nil {
+				t.Fatalf("could not get db: %v", err)
+			}
+			if err := db.Ping(); err != nil {
+				t.Fatalf("could not ping db: %v", err)
+			}
+		}
+		if err := store.Close(); err != nil {
+			t.Fatalf("could not close store: %v", err)
+		}
+	}
+	if open := atomic.LoadInt64(&countingDriver.open); open != 0 {
+		t.Fatalf("expected every connection to be closed, %d are still open", open)
+	}
+}
+
+func TestPersistTrainingSet(t *testing.T) {
+	id := ResourceID{Name: "fraud", Variant: "v1", Type: TrainingSet}
+	tests := []struct {
+		name      string
+		persistAs TrainingSetPersistAs
+		selectQry string
+	}{
+		{"All Rows", TrainingSetPersistAs{Table: "fraud_training"}, "SELECT * FROM"},
+		{"Dedup", TrainingSetPersistAs{Table: "fraud_training", Dedup: true}, "SELECT DISTINCT * FROM"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			if err != nil {
+				t.Fatalf("could not create mock db: %v", err)
+			}
+			defer db.Close()
+			store := &sqlOfflineStore{db: db, query: &defaultOfflineSQLQueries{}, logger: logging.NewTestLogger(t)}
+			tableName, err := store.getTrainingSetName(id)
+			if err != nil {
+				t.Fatalf("could not get table name: %v", err)
+			}
+			tempTable := sanitize("tmp_fraud_training")
+			persistTable := sanitize("fraud_training")
+			// The copy is built before the existing table is replaced, in one
+			// transaction.
+			mock.ExpectBegin()
+			mock.ExpectExec(regexp.QuoteMeta("DROP TABLE IF EXISTS " + tempTable)).WillReturnResult(sqlmock.NewResult(0, 0))
+			mock.ExpectExec(regexp.QuoteMeta(fmt.Sprintf("CREATE TABLE %s AS %s %s", tempTable, tt.selectQry, sanitize(tableName)))).
+				WillReturnResult(sqlmock.NewResult(0, 10))
+			mock.ExpectExec(regexp.QuoteMeta("DROP TABLE IF EXISTS " + persistTable)).WillReturnResult(sqlmock.NewResult(0, 0))
+			mock.ExpectExec(regexp.QuoteMeta(fmt.Sprintf("ALTER TABLE %s RENAME TO %s", tempTable, persistTable))).
+				WillReturnResult(sqlmock.NewResult(0, 0))
+			mock.ExpectCommit()
+			persistAs := tt.persistAs
+			if err := store.persistTrainingSet(TrainingSetDef{ID: id, PersistAs: &persistAs}, tableName); err != nil {
+				t.Fatalf("could not persist training set: %v", err)
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Fatalf("unmet expectations: %v", err)
+			}
+		})
+	}
+}
+
+func TestPersistTrainingSetRollsBack(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("could not create mock db: %v", err)
+	}
+	defer db.Close()
+	store := &sqlOfflineStore{db: db, query: &defaultOfflineSQLQueries{}, logger: logging.NewTestLogger(t)}
+	mock.ExpectBegin()
+	mock.ExpectExec("DROP TABLE IF EXISTS").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("CREATE TABLE").WillReturnError(fmt.Errorf("out of space"))
+	mock.ExpectRollback()
+	id := ResourceID{Name: "fraud", Variant: "v1", Type: TrainingSet}
+	def := TrainingSetDef{ID: id, PersistAs: &TrainingSetPersistAs{Table: "fraud_training"}}
+	if err := store.persistTrainingSet(def, "training_set"); err == nil {
+		t.Fatalf("expected a failed copy to fail persisting")
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("expected the existing table to be kept: %v", err)
+	}
+}
+
+func TestMySQLPersistTrainingSet(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("could not create mock db: %v", err)
+	}
+	defer db.Close()
+	queries := []string{
+		"DROP TABLE IF EXISTS `tmp_fraud_training`, `old_fraud_training`",
+		"CREATE TABLE `tmp_fraud_training` AS SELECT DISTINCT * FROM `training_set`",
+		"CREATE TABLE IF NOT EXISTS `fraud_training` LIKE `tmp_fraud_training`",
+		"RENAME TABLE `fraud_training` TO `old_fraud_training`, `tmp_fraud_training` TO `fraud_training`",
+		"DROP TABLE `old_fraud_training`",
+	}
+	for _, query := range queries {
+		mock.ExpectExec(regexp.QuoteMeta(query)).WillReturnResult(sqlmock.NewResult(0, 0))
+	}
+	persist := TrainingSetPersistAs{Table: "fraud_training", Dedup: true}
+	if err := (mySQLQueries{}).trainingSetPersist(db, "training_set", persist); err != nil {
+		t.Fatalf("could not persist training set: %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
+
+func TestTrainingSetPersistAsCheck(t *testing.T) {
+	def := TrainingSetDef{
+		ID:        ResourceID{Name: "fraud", Variant: "v1", Type: TrainingSet},
+		Label:     ResourceID{Name: "label", Variant: "v1", Type: Label},
+		Features:  []ResourceID{{Name: "feature", Variant: "v1", Type: Feature}},
+		PersistAs: &TrainingSetPersistAs{Table: " "},
+	}
+	if err := def.check(); err == nil {
+		t.Fatalf("expected a training set persisted without a table name to fail")
+	}
+	def.PersistAs.Table = "fraud_training"
+	if err := def.check(); err != nil {
+		t.Fatalf("expected training set to be valid: %v", err)
+	}
+	if err := def.checkNotPersisted(pt.MemoryOffline); err == nil {
+		t.Fatalf("expected stores that can't persist training sets to fail")
+	}
+}
@@ -8,13 +8,16 @@
 package provider
 
 import (
-	"fmt"
+	"sync"
+	"time"
 
 	pl "github.com/featureform/provider/location"
 
+	"github.com/featureform/config"
 	"github.com/featureform/fferr"
 	pc "github.com/featureform/provider/provider_config"
 	pt "github.com/featureform/provider/provider_type"
+	se "github.com/featureform/provider/serialization"
 	"github.com/featureform/provider/types"
 )
 
@@ -27,6 +30,16 @@ var cassandraTypeMap = map[string]string{
 	"bool":    "boolean",
 }
 
+// serializeJSON returns a JSON feature value as a string, checking that it's
+// well-formed unless validation is turned off.
+func serializeJSON(value any) (string, error) {
+	casted, err := se.CastJSON(value, !config.ShouldSkipJSONValidation())
+	if err != nil {
+		return "", fferr.NewTypeError(types.JSON.String(), value, err)
+	}
+	return casted, nil
+}
+
 func GetOnlineStore(t pt.Type, c pc.SerializedConfig) (OnlineStore, error) {
 	provider, err := Get(t, c)
 	if err != nil {
@@ -69,9 +82,41 @@ type BatchOnlineTable interface {
 	MaxBatchSize() (int, error)
 }
 
+// BatchGetOnlineTable is implemented by tables whose store can fetch many entities
+// in a single round trip (e.g. Redis HMGET, DynamoDB BatchGetItem). Values are
+// returned in the same order as the requested entities, and a missing entity
+// results in an EntityNotFoundError just like Get.
+type BatchGetOnlineTable interface {
+	OnlineStoreTable
+	BatchGet(entities []string) ([]interface{}, error)
+}
+
+// ExpiringOnlineTable is implemented by tables whose store can expire values
+// (e.g. Redis HPEXPIRE, DynamoDB TTL). WithTTL returns a view of the table
+// whose Set and BatchSet expire values ttl after they're written. Once a value
+// has expired, Get returns an EntityNotFoundError.
+type ExpiringOnlineTable interface {
+	OnlineStoreTable
+	WithTTL(ttl time.Duration) (OnlineStoreTable, error)
+}
+
+// TimestampedOnlineTable is implemented by tables whose store keeps the
+// timestamp of each entity's value next to it, so that an older value can't
+// overwrite a newer one. Comparing and writing the timestamp is atomic, so it
+// holds across concurrent writers.
+type TimestampedOnlineTable interface {
+	OnlineStoreTable
+	// SetIfNewer writes the value unless the entity's stored value has a later
+	// timestamp. It returns whether the value was written.
+	SetIfNewer(entity string, value interface{}, ts time.Time) (bool, error)
+}
+
 type SetItem struct {
 	Entity string
 	Value  interface{}
+	// TS is the timestamp of the value, if the feature has one. Stores that
+	// keep it don't overwrite a value with an older one.
+	TS time.Time
 }
 
 type tableKey struct {
@@ -82,15 +127,37 @@ func localOnlineStoreFactory(pc.SerializedConfig) (Provider, error) {
 	return NewLocalOnlineStore(), nil
 }
 
+// localOnlineStore keeps feature values in process memory, so it can be used
+// for local development and tests without any external dependencies. Values
+// aren't persisted or shared between processes.
 type localOnlineStore struct {
-	tables map[tableKey]localOnlineTable
+	// mu guards tables and values. SetIfNewer holds it across its comparison
+	// and write.
+	mu sync.RWMutex
+	// tables maps each table to its value type.
+	tables map[tableKey]types.ValueType
+	values map[tableKey]map[string]localOnlineValue
 	BaseProvider
 }
 
+type localOnlineValue struct {
+	value interface{}
+	// ts is the zero time for values that weren't set with a timestamp.
+	ts time.Time
+	// expires is the zero time for values that don't expire.
+	expires time.Time
+}
+
+// defaultLocalOnlineBatchSize is the number of values written per BatchSet.
+// There's no round trip to amortize, so it only bounds how long a batch holds
+// up the materialization that's writing it.
+const defaultLocalOnlineBatchSize = 10000
+
 func NewLocalOnlineStore() *localOnlineStore {
 	return &localOnlineStore{
-		make(map[tableKey]localOnlineTable),
-		BaseProvider{
+		tables: make(map[tableKey]types.ValueType),
+		values: make(map[tableKey]map[string]localOnlineValue),
+		BaseProvider: BaseProvider{
 			ProviderType:   pt.LocalOnline,
 			ProviderConfig: []byte{},
 		},
@@ -102,28 +169,43 @@ func (store *localOnlineStore) AsOnlineStore() (OnlineStore, error) {
 }
 
 func (store *localOnlineStore) GetTable(feature, variant string) (OnlineStoreTable, error) {
-	table, has := store.tables[tableKey{feature, variant}]
+	key := tableKey{feature, variant}
+	store.mu.RLock()
+	valueType, has := store.tables[key]
+	store.mu.RUnlock()
 	if !has {
 		wrapped := fferr.NewDatasetNotFoundError(feature, variant, nil)
 		wrapped.AddDetail("provider", store.ProviderType.String())
 		return nil, wrapped
 	}
-	return table, nil
+	return &localOnlineTable{store: store, key: key, valueType: valueType}, nil
 }
 
 func (store *localOnlineStore) CreateTable(feature, variant string, valueType types.ValueType) (OnlineStoreTable, error) {
 	key := tableKey{feature, variant}
+	store.mu.Lock()
+	defer store.mu.Unlock()
 	if _, has := store.tables[key]; has {
 		wrapped := fferr.NewDatasetAlreadyExistsError(feature, variant, nil)
 		wrapped.AddDetail("provider", store.ProviderType.String())
 		return nil, wrapped
 	}
-	table := make(localOnlineTable)
-	store.tables[key] = table
-	return table, nil
+	store.tables[key] = valueType
+	store.values[key] = make(map[string]localOnlineValue)
+	return &localOnlineTable{store: store, key: key, valueType: valueType}, nil
 }
 
-func (store *localOnlineStore) DeleteTable(feaute, variant string) error {
+func (store *localOnlineStore) DeleteTable(feature, variant string) error {
+	key := tableKey{feature, variant}
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	if _, has := store.tables[key]; !has {
+		wrapped := fferr.NewDatasetNotFoundError(feature, variant, nil)
+		wrapped.AddDetail("provider", store.ProviderType.String())
+		return wrapped
+	}
+	delete(store.tables, key)
+	delete(store.values, key)
 	return nil
 }
 
@@ -132,24 +214,127 @@ func (store *localOnlineStore) Close() error {
 }
 
 func (store *localOnlineStore) CheckHealth() (bool, error) {
-	return false, fmt.Errorf("provider health check not implemented")
+	return true, nil
 }
 
-func (store localOnlineStore) Delete(location pl.Location) error {
+func (store *localOnlineStore) Delete(location pl.Location) error {
 	return fferr.NewInternalErrorf("delete not implemented")
 }
 
-type localOnlineTable map[string]interface{}
+type localOnlineTable struct {
+	store     *localOnlineStore
+	key       tableKey
+	valueType types.ValueType
+	ttl       time.Duration
+}
+
+func (table localOnlineTable) WithTTL(ttl time.Duration) (OnlineStoreTable, error) {
+	if ttl <= 0 {
+		return nil, fferr.NewInvalidArgumentErrorf("TTL must be positive, got %s", ttl)
+	}
+	table.ttl = ttl
+	return &table, nil
+}
 
 func (table localOnlineTable) Set(entity string, value interface{}) error {
-	table[entity] = value
+	stored, err := table.newValue(value, time.Time{})
+	if err != nil {
+		return err
+	}
+	table.store.mu.Lock()
+	defer table.store.mu.Unlock()
+	values, err := table.valuesLocked()
+	if err != nil {
+		return err
+	}
+	values[entity] = stored
 	return nil
 }
 
-func (table localOnlineTable) Get(entity string) (interface{}, error) {
-	val, has := table[entity]
+func (table localOnlineTable) SetIfNewer(entity string, value interface{}, ts time.Time) (bool, error) {
+	stored, err := table.newValue(value, ts)
+	if err != nil {
+		return false, err
+	}
+	table.store.mu.Lock()
+	defer table.store.mu.Unlock()
+	values, err := table.valuesLocked()
+	if err != nil {
+		return false, err
+	}
+	if prev, has := values[entity]; has && ts.Before(prev.ts) {
+		return false, nil
+	}
+	values[entity] = stored
+	return true, nil
+}
+
+func (table localOnlineTable) newValue(value interface{}, ts time.Time) (localOnlineValue, error) {
+	if table.valueType == types.JSON && value != nil {
+		casted, err := serializeJSON(value)
+		if err != nil {
+			return localOnlineValue{}, err
+		}
+		value = casted
+	}
+	stored := localOnlineValue{value: value, ts: ts}
+	if table.ttl > 0 {
+		stored.expires = time.Now().Add(table.ttl)
+	}
+	return stored, nil
+}
+
+// valuesLocked returns the table's values. The store's lock must be held.
+func (table localOnlineTable) valuesLocked() (map[string]localOnlineValue, error) {
+	values, has := table.store.values[table.key]
 	if !has {
-		return nil, fferr.NewEntityNotFoundError("", "", entity, nil)
+		wrapped := fferr.NewDatasetNotFoundError(table.key.feature, table.key.variant, nil)
+		wrapped.AddDetail("provider", table.store.ProviderType.String())
+		return nil, wrapped
+	}
+	return values, nil
+}
+
+func (table localOnlineTable) BatchSet(items []SetItem) error {
+	if len(items) > defaultLocalOnlineBatchSize {
+		return fferr.NewInvalidArgumentErrorf("batch of %d values is over the max size of %d", len(items), defaultLocalOnlineBatchSize)
+	}
+	for _, item := range items {
+		if item.TS.IsZero() {
+			if err := table.Set(item.Entity, item.Value); err != nil {
+				return err
+			}
+		} else if _, err := table.SetIfNewer(item.Entity, item.Value, item.TS); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (table localOnlineTable) MaxBatchSize() (int, error) {
+	return defaultLocalOnlineBatchSize, nil
+}
+
+func (table localOnlineTable) Get(entity string) (interface{}, error) {
+	table.store.mu.RLock()
+	stored, has := table.store.values[table.key][entity]
+	table.store.mu.RUnlock()
+	// Expired values are left in place until they're overwritten or the table
+	// is deleted.
+	if !has || (!stored.expires.IsZero() && time.Now().After(stored.expires)) {
+		return nil, fferr.NewEntityNotFoundError(table.key.feature, table.key.variant, entity, nil)
+	}
+	return stored.value, nil
+}
+
+func (table localOnlineTable) BatchGet(entities []string) ([]interface{}, error) {
+	values := make([]interface{}, len(entities))
+	for i, entity := range entities {
+		value, err := table.Get(entity)
+		if err != nil {
+			return nil, err
+		}
+		values[i] = value
 	}
-	return val, nil
+	return values, nil
 }
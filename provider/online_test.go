@@ -12,6 +12,7 @@ import (
 	"io/ioutil"
 	"reflect"
 	"testing"
+	"time"
 
 	"github.com/featureform/fferr"
 	pc "github.com/featureform/provider/provider_config"
@@ -29,9 +30,11 @@ type OnlineStoreTest struct {
 	t     *testing.T
 	store OnlineStore
 	// TODO(simba) remove once we implement for all providers
-	testNil      bool
-	testFloatVec bool
-	testBatch    bool
+	testNil        bool
+	testFloatVec   bool
+	testBatch      bool
+	testTTL        bool
+	testSetIfNewer bool
 }
 
 func (test *OnlineStoreTest) Run() {
@@ -59,6 +62,14 @@ func (test *OnlineStoreTest) Run() {
 		testFns["BatchSetGetEntity"] = testBatchSetGetEntity
 	}
 
+	if test.testTTL {
+		testFns["ExpiringValues"] = testExpiringValues
+	}
+
+	if test.testSetIfNewer {
+		testFns["SetIfNewer"] = testSetIfNewer
+	}
+
 	store := test.store
 	for name, fn := range testFns {
 		testName := fmt.Sprintf("%s_%s", name, store.Type())
@@ -151,7 +162,7 @@ func testBatchSetGetEntity(t *testing.T, store OnlineStore) {
 	}
 	singleEnt := "e"
 	singleVal := "val"
-	singleSet := []SetItem{{singleEnt, singleVal}}
+	singleSet := []SetItem{{Entity: singleEnt, Value: singleVal}}
 	if err := batchTable.BatchSet(singleSet); err != nil {
 		t.Fatalf("Failed to set single entity: %s", err)
 	}
@@ -166,7 +177,7 @@ func testBatchSetGetEntity(t *testing.T, store OnlineStore) {
 	for i := 0; i < maxNum; i++ {
 		entity := fmt.Sprintf("entity_%d", i)
 		value := fmt.Sprintf("value_%d", i)
-		maxSet[i] = SetItem{entity, value}
+		maxSet[i] = SetItem{Entity: entity, Value: value}
 	}
 	if err := batchTable.BatchSet(maxSet); err != nil {
 		t.Fatalf("Failed to set multi entity: %s", err)
@@ -181,12 +192,92 @@ func testBatchSetGetEntity(t *testing.T, store OnlineStore) {
 			t.Fatalf("Values are not the same %v %v", val, gotVal)
 		}
 	}
-	overSizedSet := append(maxSet, SetItem{"a", "b"})
+	overSizedSet := append(maxSet, SetItem{Entity: "a", Value: "b"})
 	if err := batchTable.BatchSet(overSizedSet); err == nil {
 		t.Fatalf("Succeeded to batch set over max size")
 	}
 }
 
+func testSetIfNewer(t *testing.T, store OnlineStore) {
+	mockFeature, mockVariant := randomFeatureVariant()
+	defer store.DeleteTable(mockFeature, mockVariant)
+	tab, err := store.CreateTable(mockFeature, mockVariant, types.Int)
+	if err != nil {
+		t.Fatalf("Failed to create table: %s", err)
+	}
+	timestamped, ok := tab.(TimestampedOnlineTable)
+	if !ok {
+		t.Fatalf("Table does not implement timestamped interface.")
+	}
+	now := time.Now().UTC()
+	writes := []struct {
+		value   int
+		ts      time.Time
+		written bool
+	}{
+		{1, now, true},
+		{2, now.Add(-time.Minute), false},
+		{3, now, true},
+		{4, now.Add(time.Minute), true},
+	}
+	for _, write := range writes {
+		written, err := timestamped.SetIfNewer("entity", write.value, write.ts)
+		if err != nil {
+			t.Fatalf("Failed to set entity: %s", err)
+		}
+		if written != write.written {
+			t.Fatalf("Expected %d to be written: %v, got %v", write.value, write.written, written)
+		}
+	}
+	if val, err := tab.Get("entity"); err != nil || val != 4 {
+		t.Fatalf("Expected 4, got %v: %v", val, err)
+	}
+}
+
+func testExpiringValues(t *testing.T, store OnlineStore) {
+	mockFeature, mockVariant := randomFeatureVariant()
+	defer store.DeleteTable(mockFeature, mockVariant)
+	tab, err := store.CreateTable(mockFeature, mockVariant, types.String)
+	if err != nil {
+		t.Fatalf("Failed to create table: %s", err)
+	}
+	expiringTable, ok := tab.(ExpiringOnlineTable)
+	if !ok {
+		t.Fatalf("Table does not implement expiring interface.")
+	}
+	// DynamoDB expiry has one second granularity.
+	ttl := 2 * time.Second
+	expiring, err := expiringTable.WithTTL(ttl)
+	if err != nil {
+		t.Fatalf("Failed to set TTL: %s", err)
+	}
+	if err := expiring.Set("expiring", "val"); err != nil {
+		t.Fatalf("Failed to set entity: %s", err)
+	}
+	if err := tab.Set("persistent", "val"); err != nil {
+		t.Fatalf("Failed to set entity: %s", err)
+	}
+	if batchTable, ok := expiring.(BatchOnlineTable); ok {
+		if err := batchTable.BatchSet([]SetItem{{Entity: "batch", Value: "val"}}); err != nil {
+			t.Fatalf("Failed to batch set entity: %s", err)
+		}
+	}
+	if _, err := expiring.Get("expiring"); err != nil {
+		t.Fatalf("Failed to get entity before it expired: %s", err)
+	}
+	time.Sleep(ttl + time.Second)
+	for _, entity := range []string{"expiring", "batch"} {
+		if _, err := expiring.Get(entity); err == nil {
+			t.Fatalf("Succeeded in getting expired entity %s", entity)
+		} else if _, valid := err.(*fferr.EntityNotFoundError); !valid {
+			t.Fatalf("Wrong error for expired entity %s: %s, %T", entity, err, err)
+		}
+	}
+	if _, err := tab.Get("persistent"); err != nil {
+		t.Fatalf("Failed to get entity without a TTL: %s", err)
+	}
+}
+
 func testEntityNotFound(t *testing.T, store OnlineStore) {
 	mockFeature, mockVariant := uuid.NewString(), "v"
 	entity := "e"
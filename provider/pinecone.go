@@ -134,6 +134,10 @@ func (store *pineconeOnlineStore) CreateIndex(feature, variant string, vectorTyp
 	}
 }
 
+func (store *pineconeOnlineStore) Capabilities() (Capabilities, error) {
+	return Capabilities{VectorSearch: true}, nil
+}
+
 func (store *pineconeOnlineStore) getTableForReadyIndex(indexName, feature, variant string) (VectorStoreTable, error) {
 	dimension, state, err := store.client.describeIndex(indexName)
 	if err != nil {
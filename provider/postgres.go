@@ -14,10 +14,12 @@ import (
 	"time"
 
 	"github.com/featureform/fferr"
+	"github.com/featureform/filestore"
+	pl "github.com/featureform/provider/location"
 	pc "github.com/featureform/provider/provider_config"
 	pt "github.com/featureform/provider/provider_type"
 	"github.com/featureform/provider/types"
-	_ "github.com/lib/pq"
+	"github.com/lib/pq"
 )
 
 type postgresColumnType string
@@ -29,8 +31,23 @@ const (
 	pgString    postgresColumnType = "varchar"
 	pgBool      postgresColumnType = "boolean"
 	pgTimestamp postgresColumnType = "timestamp with time zone"
+	// pgVector is any numeric array. Arrays are read as []float32 so they can be
+	// used as vector features.
+	pgVector postgresColumnType = "real[]"
+	pgJSON   postgresColumnType = "jsonb"
 )
 
+// postgresNumericArrays are the database type names of the array columns that are
+// read as vectors.
+var postgresNumericArrays = map[string]bool{
+	"_FLOAT4":  true,
+	"_FLOAT8":  true,
+	"_INT2":    true,
+	"_INT4":    true,
+	"_INT8":    true,
+	"_NUMERIC": true,
+}
+
 func postgresOfflineStoreFactory(config pc.SerializedConfig) (Provider, error) {
 	sc := pc.PostgresConfig{}
 	if err := sc.Deserialize(config); err != nil {
@@ -56,6 +73,7 @@ func postgresOfflineStoreFactory(config pc.SerializedConfig) (Provider, error) {
 		ProviderType:            pt.PostgresOffline,
 		QueryImpl:               &queries,
 		ConnectionStringBuilder: connectionUrlBuilder,
+		ConnectionPool:          sc.ConnectionPool,
 		useDbConnectionCache:    true,
 	}
 
@@ -79,13 +97,21 @@ func (q postgresSQLQueries) viewExists() string {
 }
 
 func (q postgresSQLQueries) registerResources(db *sql.DB, tableName string, schema ResourceSchema, timestamp bool) error {
+	entity, err := schema.castColumn(schema.Entity, sanitize(schema.Entity), q.determineColumnType)
+	if err != nil {
+		return err
+	}
+	value, err := schema.castColumn(schema.Value, sanitize(schema.Value), q.determineColumnType)
+	if err != nil {
+		return err
+	}
 	var query string
 	if timestamp {
 		query = fmt.Sprintf("CREATE VIEW %s AS SELECT %s as entity, %s as value, %s as ts FROM %s", sanitize(tableName),
-			sanitize(schema.Entity), sanitize(schema.Value), sanitize(schema.TS), sanitize(schema.SourceTable.Location()))
+			entity, value, schema.parseTimestamp(sanitize(schema.TS), postgresParseTimestamp), sanitize(schema.SourceTable.Location()))
 	} else {
 		query = fmt.Sprintf("CREATE VIEW %s AS SELECT %s as entity, %s as value, to_timestamp('%s', 'YYYY-DD-MM HH24:MI:SS +0000 UTC')::TIMESTAMPTZ as ts FROM %s", sanitize(tableName),
-			sanitize(schema.Entity), sanitize(schema.Value), time.UnixMilli(0).UTC(), sanitize(schema.SourceTable.Location()))
+			entity, value, time.UnixMilli(0).UTC(), sanitize(schema.SourceTable.Location()))
 	}
 	fmt.Printf("Resource creation query: %s", query)
 	if _, err := db.Exec(query); err != nil {
@@ -96,6 +122,22 @@ func (q postgresSQLQueries) registerResources(db *sql.DB, tableName string, sche
 	return nil
 }
 
+// postgresParseTimestamp is the Postgres expression that parses expr with format
+// into a TIMESTAMPTZ.
+func postgresParseTimestamp(expr string, format TimestampFormat) string {
+	switch format.Encoding {
+	case EpochSeconds:
+		return fmt.Sprintf("to_timestamp(CAST(%s AS DOUBLE PRECISION))", expr)
+	case EpochMillis:
+		return fmt.Sprintf("to_timestamp(CAST(%s AS DOUBLE PRECISION) / 1000)", expr)
+	default:
+		return fmt.Sprintf(
+			"CASE WHEN %s ~ '%s' THEN CAST(%s AS TIMESTAMPTZ) ELSE CAST(%s AS TIMESTAMP) AT TIME ZONE '%s' END",
+			expr, isoOffsetPattern, expr, expr, format.timezone(),
+		)
+	}
+}
+
 func (q postgresSQLQueries) primaryTableRegister(tableName string, sourceName string) string {
 	return fmt.Sprintf("CREATE VIEW %s AS SELECT * FROM %s", sanitize(tableName), sanitize(sourceName))
 }
@@ -120,6 +162,12 @@ func (q postgresSQLQueries) materializationUpdate(db *sql.DB, tableName string,
 	return nil
 }
 
+// materializationIncrementalUpdate isn't supported since materialized views
+// can't be upserted into.
+func (q postgresSQLQueries) materializationIncrementalUpdate(db *sql.DB, tableName string, sourceName string) error {
+	return fferr.NewUnimplementedErrorf("incremental materialization is not supported for Postgres")
+}
+
 func (q postgresSQLQueries) materializationExists() string {
 	return "SELECT * FROM pg_matviews WHERE matviewname = $1"
 }
@@ -136,6 +184,8 @@ func (q postgresSQLQueries) determineColumnType(valueType types.ValueType) (stri
 		return "BOOLEAN", nil
 	case types.Timestamp:
 		return "TIMESTAMPTZ", nil
+	case types.JSON:
+		return "JSONB", nil
 	case types.NilType:
 		return "VARCHAR", nil
 	default:
@@ -163,6 +213,12 @@ func (q postgresSQLQueries) trainingSetUpdate(store *sqlOfflineStore, def Traini
 	return q.trainingSetQuery(store, def, tableName, labelName, true)
 }
 
+// trainingSetExport isn't supported since Postgres can only COPY to files on the
+// database server, not to a file store.
+func (q postgresSQLQueries) trainingSetExport(db *sql.DB, tableName string, location pl.Location, format filestore.FileType) ([]filestore.Filepath, error) {
+	return nil, fferr.NewUnimplementedErrorf("training set export is not supported for Postgres")
+}
+
 func (q postgresSQLQueries) trainingSetQuery(store *sqlOfflineStore, def TrainingSetDef, tableName string, labelName string, isUpdate bool) error {
 	columns := make([]string, 0)
 	query := fmt.Sprintf(" (SELECT entity, value , ts from %s ) l ", sanitize(labelName))
@@ -174,16 +230,47 @@ func (q postgresSQLQueries) trainingSetQuery(store *sqlOfflineStore, def Trainin
 		santizedName := sanitize(tableName)
 		tableJoinAlias := fmt.Sprintf("t%d", i)
 		columns = append(columns, santizedName)
-		query = fmt.Sprintf("%s LEFT JOIN LATERAL (SELECT entity , value as %s, ts  FROM %s WHERE entity=l.entity and ts <= l.ts ORDER BY ts desc LIMIT 1) %s on %s.entity=l.entity ",
-			query, santizedName, santizedName, tableJoinAlias, tableJoinAlias)
-		if i == len(def.Features)-1 {
-			query = fmt.Sprintf("%s )", query)
+		ageFilter := q.maxFeatureAgeFilter("ts", "l.ts", def.MaxFeatureAge)
+		query = fmt.Sprintf("%s LEFT JOIN LATERAL (SELECT entity , value as %s, ts  FROM %s WHERE entity=l.entity and ts <= l.ts%s ORDER BY ts desc LIMIT 1) %s on %s.entity=l.entity ",
+			query, santizedName, santizedName, ageFilter, tableJoinAlias, tableJoinAlias)
+	}
+	// Lag features take the latest value at least LagDelta before the label.
+	for i, lagFeature := range def.LagFeatures {
+		tableName, err := store.getResourceTableName(ResourceID{lagFeature.FeatureName, lagFeature.FeatureVariant, Feature})
+		if err != nil {
+			return err
+		}
+		lagColumnName := sanitize(lagFeatureColumnName(lagFeature, tableName))
+		tableJoinAlias := fmt.Sprintf("t%d", len(def.Features)+i)
+		columns = append(columns, lagColumnName)
+		query = fmt.Sprintf("%s LEFT JOIN LATERAL (SELECT entity , value as %s, ts  FROM %s WHERE entity=l.entity and %s <= l.ts ORDER BY ts desc LIMIT 1) %s on %s.entity=l.entity ",
+			query, lagColumnName, sanitize(tableName), q.shiftTimestamp("ts", lagFeature.LagDelta), tableJoinAlias, tableJoinAlias)
+	}
+	// Labels after the first are matched to its rows on entity and timestamp.
+	labelColumns := make([]string, 0)
+	for i, label := range def.labelIDs()[1:] {
+		tableName, err := store.getResourceTableName(label)
+		if err != nil {
+			return err
 		}
+		santizedName := sanitize(tableName)
+		tableJoinAlias := fmt.Sprintf("l%d", i+1)
+		labelColumns = append(labelColumns, santizedName)
+		query = fmt.Sprintf("%s LEFT JOIN (SELECT entity, value as %s, ts FROM %s) %s on %s.entity=l.entity and %s.ts=l.ts ",
+			query, santizedName, santizedName, tableJoinAlias, tableJoinAlias, tableJoinAlias)
+	}
+	if len(def.Features) > 0 || len(def.LagFeatures) > 0 {
+		query = fmt.Sprintf("%s )", query)
 	}
 	columnStr := strings.Join(columns, ", ")
+	labelStr := strings.Join(append([]string{"l.value as label"}, labelColumns...), ", ")
+	splitSelect := ""
+	if def.Split != nil {
+		splitSelect = trainingSetSplitSelect(*def.Split, q.trainingSetSplitBucket("l.entity", "l.ts", def.Split.Seed))
+	}
 
 	if !isUpdate {
-		fullQuery := fmt.Sprintf("CREATE TABLE %s AS (SELECT %s, l.value as label FROM %s ", sanitize(tableName), columnStr, query)
+		fullQuery := fmt.Sprintf("CREATE TABLE %s AS (SELECT %s, %s%s FROM %s ", sanitize(tableName), columnStr, labelStr, splitSelect, query)
 		if _, err := store.db.Exec(fullQuery); err != nil {
 			wrapped := fferr.NewResourceExecutionError(pt.PostgresOffline.String(), def.ID.Name, def.ID.Variant, fferr.ResourceType(def.ID.Type.String()), err)
 			wrapped.AddDetail("table_name", tableName)
@@ -192,7 +279,7 @@ func (q postgresSQLQueries) trainingSetQuery(store *sqlOfflineStore, def Trainin
 		}
 	} else {
 		tempName := sanitize(fmt.Sprintf("tmp_%s", tableName))
-		fullQuery := fmt.Sprintf("CREATE TABLE %s AS (SELECT %s, l.value as label FROM %s ", tempName, columnStr, query)
+		fullQuery := fmt.Sprintf("CREATE TABLE %s AS (SELECT %s, %s%s FROM %s ", tempName, columnStr, labelStr, splitSelect, query)
 		err := q.atomicUpdate(store.db, tableName, tempName, fullQuery)
 		if err != nil {
 			wrapped := fferr.NewResourceExecutionError(pt.PostgresOffline.String(), def.ID.Name, def.ID.Variant, fferr.ResourceType(def.ID.Type.String()), err)
@@ -204,6 +291,30 @@ func (q postgresSQLQueries) trainingSetQuery(store *sqlOfflineStore, def Trainin
 	return nil
 }
 
+// trainingSetSplitBucket uses the first 32 bits of an MD5 digest since Postgres has no
+// stable, general-purpose hash function exposed to SQL.
+func (q postgresSQLQueries) trainingSetSplitBucket(entity, ts string, seed int64) string {
+	return fmt.Sprintf("MOD(('x' || SUBSTR(MD5(CONCAT(%s::text, '|', %s::text, '|', '%d')), 1, 8))::bit(32)::bigint, %d)",
+		entity, ts, seed, trainingSetSplitBuckets)
+}
+
+// checkedCastTableItemType is castTableItemType, except that values that can't
+// be cast, like malformed arrays, return an error.
+func (q postgresSQLQueries) checkedCastTableItemType(v interface{}, t interface{}) (interface{}, error) {
+	if v != nil && t == pgVector {
+		return castPostgresVector(v)
+	}
+	return q.castTableItemType(v, t), nil
+}
+
+func castPostgresVector(v interface{}) ([]float32, error) {
+	var vec pq.Float32Array
+	if err := vec.Scan(v); err != nil {
+		return nil, fferr.NewTypeError(string(pgVector), v, err)
+	}
+	return []float32(vec), nil
+}
+
 func (q postgresSQLQueries) castTableItemType(v interface{}, t interface{}) interface{} {
 	if v == nil {
 		return v
@@ -221,12 +332,34 @@ func (q postgresSQLQueries) castTableItemType(v interface{}, t interface{}) inte
 		return v.(bool)
 	case pgTimestamp:
 		return v.(time.Time).UTC()
+	case pgVector:
+		// Vectors that fail to parse are passed through as is, iterators use
+		// checkedCastTableItemType to return the error instead.
+		vec, err := castPostgresVector(v)
+		if err != nil {
+			return v
+		}
+		return vec
+	case pgJSON:
+		// JSON is passed through as text rather than parsed.
+		if b, ok := v.([]byte); ok {
+			return string(b)
+		}
+		return v
 	default:
 		return v
 	}
 }
 
 func (q postgresSQLQueries) getValueColumnType(t *sql.ColumnType) interface{} {
+	// Arrays have no scan type of their own, so they're matched by name.
+	if postgresNumericArrays[t.DatabaseTypeName()] {
+		return pgVector
+	}
+	switch t.DatabaseTypeName() {
+	case "JSON", "JSONB":
+		return pgJSON
+	}
 	switch t.ScanType().String() {
 	case "string":
 		return pgString
@@ -53,6 +53,17 @@ func (tbl *FileStorePrimaryTable) WriteBatch(records []GenericRecord) error {
 			return err
 		}
 	}
+	if destination.Ext() == filestore.Avro {
+		avroSchema, err := tbl.schema.AsAvroSchema()
+		if err != nil {
+			return err
+		}
+		data, err := encodeAvroContainer(avroSchema, records)
+		if err != nil {
+			return err
+		}
+		return tbl.store.Write(destination, data)
+	}
 	buf := new(bytes.Buffer)
 	schema := tbl.schema.AsParquetSchema()
 	parquetRecords, err := tbl.schema.ToParquetRecords(records)
@@ -106,6 +117,55 @@ func (tbl *FileStorePrimaryTable) GetName() string {
 }
 
 func (tbl *FileStorePrimaryTable) IterateSegment(n int64) (GenericTableIterator, error) {
+	sources, err := tbl.sourceFiles()
+	if err != nil {
+		return nil, err
+	}
+	fmt.Printf("Sources: %d found\n", len(sources))
+	fmt.Printf("Source %s extension %s\n", sources[0].ToURI(), string(sources[0].Ext()))
+
+	switch {
+	case sources[0].Ext() == filestore.Parquet:
+		if err := checkUncompressed(sources[0]); err != nil {
+			return nil, err
+		}
+		return newMultipleFileParquetIterator(sources, tbl.store, n)
+	case sources[0].Ext() == filestore.Avro:
+		if err := checkUncompressed(sources[0]); err != nil {
+			return nil, err
+		}
+		return newMultipleFileAvroIterator(sources, tbl.store, n)
+	case isDelimitedFile(sources[0], tbl.schema.CSVOptions):
+		if len(sources) > 1 {
+			return nil, fferr.NewInternalErrorf("multiple CSV files found for table (%v)", tbl.id)
+		}
+		fmt.Printf("Reading file at key %s in file store type %s\n", sources[0].Key(), tbl.store.FilestoreType())
+		src, err := openDecompressed(tbl.store, sources[0])
+		if err != nil {
+			return nil, err
+		}
+		var opts pl.CSVOptions
+		if tbl.schema.CSVOptions != nil {
+			opts = *tbl.schema.CSVOptions
+		}
+		return newCSVIterator(src, n, opts)
+	case sources[0].Ext() == filestore.JSON:
+		if len(sources) > 1 {
+			return nil, fferr.NewInternalErrorf("multiple JSON files found for table (%v)", tbl.id)
+		}
+		src, err := openDecompressed(tbl.store, sources[0])
+		if err != nil {
+			return nil, err
+		}
+		return newJSONIterator(src, n)
+	default:
+		return nil, fferr.NewInvalidFileTypeError(string(sources[0].Ext()), nil)
+	}
+}
+
+// sourceFiles returns the files that make up the table. A transformation's
+// source is a directory, so its newest output files are returned.
+func (tbl *FileStorePrimaryTable) sourceFiles() ([]filestore.Filepath, error) {
 	sources := []filestore.Filepath{tbl.source}
 	if tbl.source.IsDir() {
 		// The key should only be a directory in the case of transformations.
@@ -116,7 +176,7 @@ func (tbl *FileStorePrimaryTable) IterateSegment(n int64) (GenericTableIterator,
 		// but there is an additional directory that's named using a timestamp that contains the transformation file
 		// we need to access. NewestFileOfType will recursively search for the newest file of the given type (i.e.
 		// parquet) given a path (i.e. `key`).
-		transformations, err := tbl.store.List(tbl.source, filestore.Parquet)
+		transformations, err := listOutputFiles(tbl.store, tbl.source)
 		if err != nil {
 			return nil, err
 		}
@@ -130,25 +190,7 @@ func (tbl *FileStorePrimaryTable) IterateSegment(n int64) (GenericTableIterator,
 		}
 		sources = newestFiles
 	}
-	fmt.Printf("Sources: %d found\n", len(sources))
-	fmt.Printf("Source %s extension %s\n", sources[0].ToURI(), string(sources[0].Ext()))
-
-	switch sources[0].Ext() {
-	case filestore.Parquet:
-		return newMultipleFileParquetIterator(sources, tbl.store, n)
-	case filestore.CSV:
-		if len(sources) > 1 {
-			return nil, fferr.NewInternalErrorf("multiple CSV files found for table (%v)", tbl.id)
-		}
-		fmt.Printf("Reading file at key %s in file store type %s\n", sources[0].Key(), tbl.store.FilestoreType())
-		src, err := tbl.store.Open(sources[0])
-		if err != nil {
-			return nil, err
-		}
-		return newCSVIterator(src, n)
-	default:
-		return nil, fferr.NewInvalidFileTypeError(string(sources[0].Ext()), nil)
-	}
+	return sources, nil
 }
 
 func (tbl *FileStorePrimaryTable) NumRows() (int64, error) {
@@ -22,23 +22,27 @@ import (
 
 func init() {
 	unregisteredFactories := map[pt.Type]Factory{
-		pt.LocalOnline:       localOnlineStoreFactory,
-		pt.RedisOnline:       redisOnlineStoreFactory,
-		pt.CassandraOnline:   cassandraOnlineStoreFactory,
-		pt.FirestoreOnline:   firestoreOnlineStoreFactory,
-		pt.DynamoDBOnline:    dynamodbOnlineStoreFactory,
-		pt.PineconeOnline:    pineconeOnlineStoreFactory,
-		pt.MemoryOffline:     memoryOfflineStoreFactory,
-		pt.MySqlOffline:      mySqlOfflineStoreFactory,
-		pt.PostgresOffline:   postgresOfflineStoreFactory,
-		pt.ClickHouseOffline: clickhouseOfflineStoreFactory,
-		pt.SnowflakeOffline:  snowflakeOfflineStoreFactory,
-		pt.RedshiftOffline:   redshiftOfflineStoreFactory,
-		pt.BigQueryOffline:   bigQueryOfflineStoreFactory,
-		pt.SparkOffline:      sparkOfflineStoreFactory,
-		pt.K8sOffline:        k8sOfflineStoreFactory,
-		pt.MongoDBOnline:     mongoOnlineStoreFactory,
-		pt.UNIT_TEST:         unitTestStoreFactory,
+		pt.LocalOnline:          localOnlineStoreFactory,
+		pt.MemoryOnline:         memoryOnlineStoreFactory,
+		pt.RedisOnline:          redisOnlineStoreFactory,
+		pt.CassandraOnline:      cassandraOnlineStoreFactory,
+		pt.FirestoreOnline:      firestoreOnlineStoreFactory,
+		pt.DynamoDBOnline:       dynamodbOnlineStoreFactory,
+		pt.PineconeOnline:       pineconeOnlineStoreFactory,
+		pt.MemoryOffline:        memoryOfflineStoreFactory,
+		pt.MySqlOffline:         mySqlOfflineStoreFactory,
+		pt.PostgresOffline:      postgresOfflineStoreFactory,
+		pt.ClickHouseOffline:    clickhouseOfflineStoreFactory,
+		pt.SnowflakeOffline:     snowflakeOfflineStoreFactory,
+		pt.RedshiftOffline:      redshiftOfflineStoreFactory,
+		pt.BigQueryOffline:      bigQueryOfflineStoreFactory,
+		pt.SparkOffline:         sparkOfflineStoreFactory,
+		pt.K8sOffline:           k8sOfflineStoreFactory,
+		pt.DatabricksSQLOffline: databricksSQLOfflineStoreFactory,
+		pt.MongoDBOnline:        mongoOnlineStoreFactory,
+		pt.BigtableOnline:       bigtableOnlineStoreFactory,
+		pt.Kafka:                kafkaStreamFactory,
+		pt.UNIT_TEST:            unitTestStoreFactory,
 	}
 	for name, factory := range unregisteredFactories {
 		if err := RegisterFactory(name, factory); err != nil {
@@ -49,13 +53,72 @@ func init() {
 
 type SerializedTableSchema []byte
 
+// HealthChecker is implemented by every provider to verify that it can connect
+// with its config. CheckHealth returns an error describing why it can't.
+type HealthChecker interface {
+	CheckHealth() (bool, error)
+}
+
 type Provider interface {
+	HealthChecker
 	AsOnlineStore() (OnlineStore, error)
 	AsOfflineStore() (OfflineStore, error)
 	Type() pt.Type
 	Config() pc.SerializedConfig
-	CheckHealth() (bool, error)
 	Delete(location pl.Location) error
+	// Capabilities returns which optional features the provider supports.
+	Capabilities() (Capabilities, error)
+	// Close releases the provider's connections and clients. The provider
+	// can't be used afterwards.
+	Close() error
+}
+
+// Capabilities aggregates the optional features of a provider, so that clients
+// can tell which actions are unsupported before attempting them.
+type Capabilities struct {
+	// Catalog means resources can be read from and written to a data catalog.
+	Catalog bool
+	// DirectCopyDynamo mirrors SupportsMaterializationOption(DirectCopyDynamo).
+	DirectCopyDynamo bool
+	// DirectCopyRedis mirrors SupportsMaterializationOption(DirectCopyRedis).
+	DirectCopyRedis bool
+	// DirectCopyCosmos mirrors SupportsMaterializationOption(DirectCopyCosmos).
+	DirectCopyCosmos bool
+	// FilteredMaterialization mirrors SupportsMaterializationOption(FilteredMaterialization).
+	FilteredMaterialization bool
+	// IncrementalTransformation means TransformationConfig.IncrementalColumn is
+	// used on updates rather than rebuilding the transformation.
+	IncrementalTransformation bool
+	// ResumableTransformation mirrors SupportsTransformationOption(ResumableTransformation).
+	ResumableTransformation bool
+	// SourceProfiling means the provider is a SourceProfiler.
+	SourceProfiling bool
+	// VectorSearch means the provider is a VectorStore.
+	VectorSearch bool
+}
+
+// offlineStoreCapabilities fills in the capabilities that an offline store
+// reports through its Supports methods and the interfaces it implements.
+func offlineStoreCapabilities(store OfflineStore) (Capabilities, error) {
+	var caps Capabilities
+	var err error
+	if caps.DirectCopyDynamo, err = store.SupportsMaterializationOption(DirectCopyDynamo); err != nil {
+		return Capabilities{}, err
+	}
+	if caps.DirectCopyRedis, err = store.SupportsMaterializationOption(DirectCopyRedis); err != nil {
+		return Capabilities{}, err
+	}
+	if caps.DirectCopyCosmos, err = store.SupportsMaterializationOption(DirectCopyCosmos); err != nil {
+		return Capabilities{}, err
+	}
+	if caps.FilteredMaterialization, err = store.SupportsMaterializationOption(FilteredMaterialization); err != nil {
+		return Capabilities{}, err
+	}
+	if caps.ResumableTransformation, err = store.SupportsTransformationOption(ResumableTransformation); err != nil {
+		return Capabilities{}, err
+	}
+	_, caps.SourceProfiling = store.(SourceProfiler)
+	return caps, nil
 }
 
 type BaseProvider struct {
@@ -79,6 +142,11 @@ func (provider BaseProvider) Config() pc.SerializedConfig {
 	return provider.ProviderConfig
 }
 
+// Close is a no-op for providers that don't hold any connections.
+func (provider BaseProvider) Close() error {
+	return nil
+}
+
 func (provider BaseProvider) CheckHealth() (bool, error) {
 	return false, fferr.NewInternalError(fmt.Errorf("provider health check not implemented"))
 }
@@ -87,6 +155,10 @@ func (provider BaseProvider) Delete(location pl.Location) error {
 	return fferr.NewInternalErrorf("delete not implemented")
 }
 
+func (provider BaseProvider) Capabilities() (Capabilities, error) {
+	return Capabilities{}, nil
+}
+
 type Factory func(pc.SerializedConfig) (Provider, error)
 
 var factories = make(map[pt.Type]Factory)
@@ -104,5 +176,11 @@ func Get(t pt.Type, config pc.SerializedConfig) (Provider, error) {
 	if !has {
 		return nil, fferr.NewInternalError(fmt.Errorf("no provider of type: %s", t))
 	}
-	return f(config)
+	// Secrets are resolved before the factory deserializes the config, so
+	// factories that pass the serialized config on get the values too.
+	resolved, err := resolveSecrets(config)
+	if err != nil {
+		return nil, err
+	}
+	return f(resolved)
 }
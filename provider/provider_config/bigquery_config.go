@@ -9,6 +9,8 @@ package provider_config
 
 import (
 	"encoding/json"
+	"fmt"
+	"regexp"
 
 	"github.com/featureform/fferr"
 
@@ -19,8 +21,22 @@ type BigQueryConfig struct {
 	ProjectId   string
 	DatasetId   string
 	Credentials map[string]interface{}
+	// Location is where jobs run, e.g. US or europe-west2. It must be the dataset's
+	// location. If it's unset, BigQuery infers it from the tables a job references.
+	Location string `json:",omitempty"`
+	// Labels are added to every job, e.g. to attribute its cost to a team.
+	Labels map[string]string `json:",omitempty"`
 }
 
+// BigQuery label keys must start with a lowercase letter and, like values, can only
+// contain lowercase letters, numbers, underscores and dashes.
+var (
+	bigQueryLabelKeyPattern   = regexp.MustCompile(`^[\p{Ll}\p{Lo}][\p{Ll}\p{Lo}\p{N}_-]{0,62}$`)
+	bigQueryLabelValuePattern = regexp.MustCompile(`^[\p{Ll}\p{Lo}\p{N}_-]{0,63}$`)
+)
+
+const maxBigQueryLabels = 64
+
 func (bq *BigQueryConfig) Deserialize(config SerializedConfig) error {
 	err := json.Unmarshal(config, bq)
 	if err != nil {
@@ -40,7 +56,23 @@ func (bq *BigQueryConfig) Serialize() []byte {
 func (bq BigQueryConfig) MutableFields() ss.StringSet {
 	return ss.StringSet{
 		"Credentials": true,
+		"Labels":      true,
+	}
+}
+
+func (bq *BigQueryConfig) Validate() error {
+	if len(bq.Labels) > maxBigQueryLabels {
+		return fferr.NewInvalidArgumentError(fmt.Errorf("BigQuery jobs can have at most %d labels, got %d", maxBigQueryLabels, len(bq.Labels)))
 	}
+	for key, value := range bq.Labels {
+		if !bigQueryLabelKeyPattern.MatchString(key) {
+			return fferr.NewInvalidArgumentError(fmt.Errorf("invalid BigQuery label key %q", key))
+		}
+		if !bigQueryLabelValuePattern.MatchString(value) {
+			return fferr.NewInvalidArgumentError(fmt.Errorf("invalid value %q for BigQuery label %s", value, key))
+		}
+	}
+	return nil
 }
 
 func (a BigQueryConfig) DifferingFields(b BigQueryConfig) (ss.StringSet, error) {
@@ -9,8 +9,10 @@ package provider_config
 
 import (
 	"encoding/json"
+	"fmt"
 	"io/ioutil"
 	"reflect"
+	"strings"
 	"testing"
 
 	ss "github.com/featureform/helpers/stringset"
@@ -19,6 +21,7 @@ import (
 func TestBigQueryConfigMutableFields(t *testing.T) {
 	expected := ss.StringSet{
 		"Credentials": true,
+		"Labels":      true,
 	}
 
 	config := BigQueryConfig{
@@ -106,3 +109,31 @@ func TestBigQueryConfigDifferingFields(t *testing.T) {
 	}
 
 }
+
+func TestBigQueryConfigValidate(t *testing.T) {
+	tooMany := map[string]string{}
+	for i := 0; i <= maxBigQueryLabels; i++ {
+		tooMany[fmt.Sprintf("label_%d", i)] = "value"
+	}
+	tests := []struct {
+		name    string
+		labels  map[string]string
+		wantErr bool
+	}{
+		{"No Labels", nil, false},
+		{"Valid Labels", map[string]string{"team": "fraud-detection", "cost_center": "1234", "empty": ""}, false},
+		{"Uppercase Key", map[string]string{"Team": "fraud"}, true},
+		{"Key Starts With Number", map[string]string{"1team": "fraud"}, true},
+		{"Uppercase Value", map[string]string{"team": "Fraud"}, true},
+		{"Value Too Long", map[string]string{"team": strings.Repeat("a", 64)}, true},
+		{"Too Many Labels", tooMany, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := BigQueryConfig{ProjectId: "ff-gcp-proj-id", DatasetId: "transactions-ds", Labels: tt.labels}
+			if err := config.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,61 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+//
+// Copyright 2024 FeatureForm Inc.
+//
+
+package provider_config
+
+import (
+	"encoding/json"
+
+	"github.com/featureform/fferr"
+
+	ss "github.com/featureform/helpers/stringset"
+)
+
+// BigtableConfig configures a Bigtable online store. TablePrefix is prepended
+// to the names of the tables that the store creates in the instance.
+type BigtableConfig struct {
+	ProjectID   string
+	InstanceID  string
+	TablePrefix string
+	Credentials map[string]interface{}
+}
+
+func (bt BigtableConfig) Serialize() SerializedConfig {
+	config, err := json.Marshal(bt)
+	if err != nil {
+		panic(err)
+	}
+	return config
+}
+
+func (bt *BigtableConfig) Deserialize(config SerializedConfig) error {
+	err := json.Unmarshal(config, bt)
+	if err != nil {
+		return fferr.NewInternalError(err)
+	}
+	return nil
+}
+
+func (bt BigtableConfig) Validate() error {
+	if bt.ProjectID == "" {
+		return fferr.NewInvalidArgumentErrorf("Bigtable project ID is required")
+	}
+	if bt.InstanceID == "" {
+		return fferr.NewInvalidArgumentErrorf("Bigtable instance ID is required")
+	}
+	return nil
+}
+
+func (bt BigtableConfig) MutableFields() ss.StringSet {
+	return ss.StringSet{
+		"Credentials": true,
+	}
+}
+
+func (a BigtableConfig) DifferingFields(b BigtableConfig) (ss.StringSet, error) {
+	return differingFields(a, b)
+}
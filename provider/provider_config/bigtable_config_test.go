@@ -0,0 +1,105 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+//
+// Copyright 2024 FeatureForm Inc.
+//
+
+package provider_config
+
+import (
+	"reflect"
+	"testing"
+
+	ss "github.com/featureform/helpers/stringset"
+)
+
+func TestBigtableConfigMutableFields(t *testing.T) {
+	expected := ss.StringSet{
+		"Credentials": true,
+	}
+
+	config := BigtableConfig{
+		ProjectID:   "ff-gcp-proj-id",
+		InstanceID:  "ff-instance",
+		TablePrefix: "featureform_",
+		Credentials: map[string]interface{}{},
+	}
+	actual := config.MutableFields()
+
+	if !reflect.DeepEqual(expected, actual) {
+		t.Errorf("Expected %v but received %v", expected, actual)
+	}
+}
+
+func TestBigtableConfigDifferingFields(t *testing.T) {
+	type args struct {
+		a BigtableConfig
+		b BigtableConfig
+	}
+
+	tests := []struct {
+		name     string
+		args     args
+		expected ss.StringSet
+	}{
+		{"No Differing Fields", args{
+			a: BigtableConfig{
+				ProjectID:   "ff-gcp-proj-id",
+				InstanceID:  "ff-instance",
+				TablePrefix: "featureform_",
+				Credentials: map[string]interface{}{},
+			},
+			b: BigtableConfig{
+				ProjectID:   "ff-gcp-proj-id",
+				InstanceID:  "ff-instance",
+				TablePrefix: "featureform_",
+				Credentials: map[string]interface{}{},
+			},
+		}, ss.StringSet{}},
+		{"Differing Fields", args{
+			a: BigtableConfig{
+				ProjectID:   "ff-gcp-proj-id",
+				InstanceID:  "ff-instance",
+				TablePrefix: "featureform_",
+				Credentials: map[string]interface{}{"client_email": "a@featureform.com"},
+			},
+			b: BigtableConfig{
+				ProjectID:   "ff-gcp-proj-id",
+				InstanceID:  "ff-instance-v2",
+				TablePrefix: "ff_",
+				Credentials: map[string]interface{}{"client_email": "b@featureform.com"},
+			},
+		}, ss.StringSet{
+			"InstanceID":  true,
+			"TablePrefix": true,
+			"Credentials": true,
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			actual, err := tt.args.a.DifferingFields(tt.args.b)
+
+			if err != nil {
+				t.Errorf("Failed to get differing fields due to error: %v", err)
+			}
+
+			if !reflect.DeepEqual(actual, tt.expected) {
+				t.Errorf("Expected %v, but instead found %v", tt.expected, actual)
+			}
+		})
+	}
+}
+
+func TestBigtableConfigValidate(t *testing.T) {
+	if err := (BigtableConfig{ProjectID: "ff-gcp-proj-id", InstanceID: "ff-instance"}).Validate(); err != nil {
+		t.Errorf("Expected config to be valid: %v", err)
+	}
+	if err := (BigtableConfig{ProjectID: "ff-gcp-proj-id"}).Validate(); err == nil {
+		t.Errorf("Expected config without an instance to be invalid")
+	}
+	if err := (BigtableConfig{InstanceID: "ff-instance"}).Validate(); err == nil {
+		t.Errorf("Expected config without a project to be invalid")
+	}
+}
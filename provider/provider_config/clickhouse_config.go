@@ -20,6 +20,9 @@ type ClickHouseConfig struct {
 	Password string `json:"Password"`
 	Database string `json:"Database"`
 	SSL      bool   `json:"SSL"`
+	// AsyncInsert has the server buffer inserts and write them in bulk, which is
+	// much faster than writing each row as its own part
+	AsyncInsert bool `json:"AsyncInsert"`
 }
 
 func (ch *ClickHouseConfig) Deserialize(config SerializedConfig) error {
@@ -40,10 +43,11 @@ func (pg *ClickHouseConfig) Serialize() []byte {
 
 func (ch ClickHouseConfig) MutableFields() ss.StringSet {
 	return ss.StringSet{
-		"Username": true,
-		"Password": true,
-		"Port":     true,
-		"SSL":      true,
+		"Username":    true,
+		"Password":    true,
+		"Port":        true,
+		"SSL":         true,
+		"AsyncInsert": true,
 	}
 }
 
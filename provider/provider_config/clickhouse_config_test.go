@@ -18,8 +18,9 @@ func TestClickHouseConfigMutableFields(t *testing.T) {
 	expected := ss.StringSet{
 		"Username": true,
 		"Password": true,
-		"Port":     true,
-		"SSL":      true,
+		"Port":        true,
+		"SSL":         true,
+		"AsyncInsert": true,
 	}
 
 	config := ClickHouseConfig{
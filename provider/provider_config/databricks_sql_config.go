@@ -0,0 +1,75 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+//
+// Copyright 2024 FeatureForm Inc.
+//
+
+package provider_config
+
+import (
+	"encoding/json"
+	"path"
+	"strings"
+
+	"github.com/featureform/fferr"
+
+	ss "github.com/featureform/helpers/stringset"
+)
+
+// DatabricksSQLConfig connects to a Databricks SQL Warehouse. Unlike
+// DatabricksConfig, which submits Spark jobs to a cluster, queries are run
+// directly on the warehouse.
+type DatabricksSQLConfig struct {
+	Host string `json:"Host"`
+	// HTTPPath is the warehouse's HTTP path from its connection details, like
+	// /sql/1.0/warehouses/<warehouse id>
+	HTTPPath string `json:"HTTPPath"`
+	Token    string `json:"Token"`
+	Catalog  string `json:"Catalog"`
+	Schema   string `json:"Schema"`
+	// ConnectionPool is optional, the defaults are used if it's unset
+	ConnectionPool *SQLConnectionPoolConfig `json:"ConnectionPool,omitempty"`
+}
+
+func (d *DatabricksSQLConfig) Deserialize(config SerializedConfig) error {
+	err := json.Unmarshal(config, d)
+	if err != nil {
+		return fferr.NewInternalError(err)
+	}
+	return nil
+}
+
+func (d *DatabricksSQLConfig) Serialize() []byte {
+	conf, err := json.Marshal(d)
+	if err != nil {
+		panic(err)
+	}
+	return conf
+}
+
+// WarehouseID returns the ID of the warehouse that HTTPPath points to.
+func (d DatabricksSQLConfig) WarehouseID() (string, error) {
+	dir, id := path.Split(strings.TrimRight(d.HTTPPath, "/"))
+	switch strings.TrimRight(dir, "/") {
+	case "/sql/1.0/warehouses", "/sql/1.0/endpoints":
+		if id != "" {
+			return id, nil
+		}
+	}
+	return "", fferr.NewInvalidArgumentErrorf(
+		"invalid Databricks SQL Warehouse HTTP path %q, expected /sql/1.0/warehouses/<warehouse id>", d.HTTPPath,
+	)
+}
+
+func (d DatabricksSQLConfig) MutableFields() ss.StringSet {
+	return ss.StringSet{
+		"HTTPPath":       true,
+		"Token":          true,
+		"ConnectionPool": true,
+	}
+}
+
+func (a DatabricksSQLConfig) DifferingFields(b DatabricksSQLConfig) (ss.StringSet, error) {
+	return differingFields(a, b)
+}
@@ -0,0 +1,67 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+//
+// Copyright 2024 FeatureForm Inc.
+//
+
+package provider_config
+
+import (
+	"reflect"
+	"testing"
+
+	ss "github.com/featureform/helpers/stringset"
+)
+
+func TestDatabricksSQLConfigDifferingFields(t *testing.T) {
+	a := DatabricksSQLConfig{
+		Host:     "dbc-1234.cloud.databricks.com",
+		HTTPPath: "/sql/1.0/warehouses/abc123",
+		Token:    "token",
+		Catalog:  "main",
+		Schema:   "default",
+	}
+	b := a
+	b.HTTPPath = "/sql/1.0/warehouses/def456"
+	b.Token = "rotated"
+	actual, err := a.DifferingFields(b)
+	if err != nil {
+		t.Fatalf("Failed to get differing fields due to error: %v", err)
+	}
+	expected := ss.StringSet{"HTTPPath": true, "Token": true}
+	if !reflect.DeepEqual(expected, actual) {
+		t.Errorf("Expected %v but received %v", expected, actual)
+	}
+	if !a.MutableFields().Contains(actual) {
+		t.Errorf("Expected %v to be mutable", actual)
+	}
+}
+
+func TestDatabricksSQLConfigWarehouseID(t *testing.T) {
+	tests := map[string]struct {
+		HTTPPath string
+		ID       string
+		Valid    bool
+	}{
+		"Warehouse":     {"/sql/1.0/warehouses/abc123", "abc123", true},
+		"TrailingSlash": {"/sql/1.0/warehouses/abc123/", "abc123", true},
+		"Endpoint":      {"/sql/1.0/endpoints/abc123", "abc123", true},
+		"Cluster":       {"/sql/protocolv1/o/1234/0123-456789-abc", "", false},
+		"MissingID":     {"/sql/1.0/warehouses/", "", false},
+		"Empty":         {"", "", false},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			id, err := DatabricksSQLConfig{HTTPPath: test.HTTPPath}.WarehouseID()
+			if test.Valid && err != nil {
+				t.Fatalf("Expected %q to be valid: %s", test.HTTPPath, err)
+			} else if !test.Valid && err == nil {
+				t.Fatalf("Expected %q to be invalid", test.HTTPPath)
+			}
+			if id != test.ID {
+				t.Fatalf("Expected warehouse ID %q, got %q", test.ID, id)
+			}
+		})
+	}
+}
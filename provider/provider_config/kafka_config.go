@@ -0,0 +1,107 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+//
+// Copyright 2024 FeatureForm Inc.
+//
+
+package provider_config
+
+import (
+	"encoding/json"
+
+	"github.com/featureform/fferr"
+	"github.com/featureform/filestore"
+
+	ss "github.com/featureform/helpers/stringset"
+)
+
+// KafkaFormat is the encoding of the values of a topic's messages
+type KafkaFormat string
+
+const (
+	KafkaJSON KafkaFormat = "JSON"
+	// KafkaAvro messages use the Confluent wire format, so their schema is
+	// fetched from the schema registry.
+	KafkaAvro KafkaFormat = "AVRO"
+)
+
+// KafkaConfig configures a Kafka cluster that streaming sources read topics from.
+// Username and Password authenticate with SASL/PLAIN. If RawEventsStoreType is
+// set, the events that are consumed are also written to RawEventsPath in that
+// file store.
+type KafkaConfig struct {
+	Brokers                []string
+	Format                 KafkaFormat
+	SchemaRegistryURL      string
+	SchemaRegistryUsername string
+	SchemaRegistryPassword string
+	Username               string
+	Password               string
+	TLS                    bool
+	RawEventsStoreType     filestore.FileStoreType
+	RawEventsStoreConfig   json.RawMessage
+	RawEventsPath          string
+}
+
+func (k KafkaConfig) Serialize() SerializedConfig {
+	config, err := json.Marshal(k)
+	if err != nil {
+		panic(err)
+	}
+	return config
+}
+
+func (k *KafkaConfig) Deserialize(config SerializedConfig) error {
+	err := json.Unmarshal(config, k)
+	if err != nil {
+		return fferr.NewInternalError(err)
+	}
+	return nil
+}
+
+func (k KafkaConfig) Validate() error {
+	if len(k.Brokers) == 0 {
+		return fferr.NewInvalidArgumentErrorf("Kafka requires at least one broker")
+	}
+	switch k.Format {
+	case KafkaJSON:
+	case KafkaAvro:
+		if k.SchemaRegistryURL == "" {
+			return fferr.NewInvalidArgumentErrorf("Kafka Avro topics require a schema registry URL")
+		}
+	default:
+		return fferr.NewInvalidArgumentErrorf("Kafka format must be %s or %s, got %q", KafkaJSON, KafkaAvro, k.Format)
+	}
+	if (k.Username == "") != (k.Password == "") {
+		return fferr.NewInvalidArgumentErrorf("Kafka username and password must be set together")
+	}
+	if k.RawEventsStoreType == "" {
+		return nil
+	}
+	switch k.RawEventsStoreType {
+	case filestore.S3, filestore.GCS, filestore.Azure, filestore.HDFS, filestore.FileSystem:
+	default:
+		return fferr.NewInvalidArgumentErrorf("Kafka raw events can't be written to a %s file store", k.RawEventsStoreType)
+	}
+	if k.RawEventsPath == "" {
+		return fferr.NewInvalidArgumentErrorf("Kafka raw events require a path to write them to")
+	}
+	return nil
+}
+
+func (k KafkaConfig) MutableFields() ss.StringSet {
+	return ss.StringSet{
+		"Brokers":                true,
+		"SchemaRegistryURL":      true,
+		"SchemaRegistryUsername": true,
+		"SchemaRegistryPassword": true,
+		"Username":               true,
+		"Password":               true,
+		"RawEventsStoreConfig":   true,
+	}
+}
+
+func (a KafkaConfig) DifferingFields(b KafkaConfig) (ss.StringSet, error) {
+	return differingFields(a, b)
+}
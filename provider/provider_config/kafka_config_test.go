@@ -0,0 +1,128 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+//
+// Copyright 2024 FeatureForm Inc.
+//
+
+package provider_config
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/featureform/filestore"
+	ss "github.com/featureform/helpers/stringset"
+)
+
+func TestKafkaConfigMutableFields(t *testing.T) {
+	expected := ss.StringSet{
+		"Brokers":                true,
+		"SchemaRegistryURL":      true,
+		"SchemaRegistryUsername": true,
+		"SchemaRegistryPassword": true,
+		"Username":               true,
+		"Password":               true,
+		"RawEventsStoreConfig":   true,
+	}
+
+	config := KafkaConfig{
+		Brokers: []string{"localhost:9092"},
+		Format:  KafkaJSON,
+	}
+	actual := config.MutableFields()
+
+	if !reflect.DeepEqual(expected, actual) {
+		t.Errorf("Expected %v but received %v", expected, actual)
+	}
+}
+
+func TestKafkaConfigDifferingFields(t *testing.T) {
+	type args struct {
+		a KafkaConfig
+		b KafkaConfig
+	}
+
+	tests := []struct {
+		name     string
+		args     args
+		expected ss.StringSet
+	}{
+		{"No Differing Fields", args{
+			a: KafkaConfig{
+				Brokers: []string{"localhost:9092"},
+				Format:  KafkaJSON,
+			},
+			b: KafkaConfig{
+				Brokers: []string{"localhost:9092"},
+				Format:  KafkaJSON,
+			},
+		}, ss.StringSet{}},
+		{"Differing Fields", args{
+			a: KafkaConfig{
+				Brokers:  []string{"localhost:9092"},
+				Format:   KafkaJSON,
+				Username: "featureform",
+				Password: "password",
+			},
+			b: KafkaConfig{
+				Brokers:           []string{"broker-1:9092", "broker-2:9092"},
+				Format:            KafkaAvro,
+				SchemaRegistryURL: "http://localhost:8081",
+				Username:          "featureform",
+				Password:          "password2",
+			},
+		}, ss.StringSet{
+			"Brokers":           true,
+			"Format":            true,
+			"SchemaRegistryURL": true,
+			"Password":          true,
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			actual, err := tt.args.a.DifferingFields(tt.args.b)
+
+			if err != nil {
+				t.Errorf("Failed to get differing fields due to error: %v", err)
+			}
+
+			if !reflect.DeepEqual(actual, tt.expected) {
+				t.Errorf("Expected %v, but instead found %v", tt.expected, actual)
+			}
+		})
+	}
+}
+
+func TestKafkaConfigValidate(t *testing.T) {
+	brokers := []string{"localhost:9092"}
+	rawStore := json.RawMessage(`{"DirPath": "file:///tmp/events"}`)
+	tests := []struct {
+		name    string
+		config  KafkaConfig
+		isValid bool
+	}{
+		{"JSON", KafkaConfig{Brokers: brokers, Format: KafkaJSON}, true},
+		{"Avro", KafkaConfig{Brokers: brokers, Format: KafkaAvro, SchemaRegistryURL: "http://localhost:8081"}, true},
+		{"Raw Events", KafkaConfig{Brokers: brokers, Format: KafkaJSON, RawEventsStoreType: filestore.FileSystem, RawEventsStoreConfig: rawStore, RawEventsPath: "events"}, true},
+		{"No Brokers", KafkaConfig{Format: KafkaJSON}, false},
+		{"Unknown Format", KafkaConfig{Brokers: brokers, Format: "PROTOBUF"}, false},
+		{"Avro Without Registry", KafkaConfig{Brokers: brokers, Format: KafkaAvro}, false},
+		{"Username Without Password", KafkaConfig{Brokers: brokers, Format: KafkaJSON, Username: "featureform"}, false},
+		{"Raw Events Without Path", KafkaConfig{Brokers: brokers, Format: KafkaJSON, RawEventsStoreType: filestore.S3}, false},
+		{"Raw Events In Memory", KafkaConfig{Brokers: brokers, Format: KafkaJSON, RawEventsStoreType: filestore.Memory, RawEventsPath: "events"}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.config.Validate()
+			if tt.isValid && err != nil {
+				t.Fatalf("Expected config to be valid: %v", err)
+			}
+			if !tt.isValid && err == nil {
+				t.Fatalf("Expected config to be invalid")
+			}
+		})
+	}
+}
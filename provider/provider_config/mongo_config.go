@@ -9,6 +9,7 @@ package provider_config
 
 import (
 	"encoding/json"
+	"fmt"
 
 	"github.com/featureform/fferr"
 
@@ -22,8 +23,33 @@ type MongoDBConfig struct {
 	Password   string
 	Database   string
 	Throughput int
+	// VectorIndex is the name of the Atlas Vector Search index created on each
+	// embedding's collection. Embeddings can only be searched if it's set.
+	VectorIndex string `json:",omitempty"`
+	// VectorSimilarity is the metric the vector index ranks neighbors by. It's
+	// cosine if unset.
+	VectorSimilarity MongoDBVectorSimilarity `json:",omitempty"`
+	// DirectCopy lets offline stores that support it, like Spark, write
+	// materialized features straight into Cosmos DB rather than through the
+	// materialization runner.
+	DirectCopy bool `json:",omitempty"`
+	// PartitionKey is the field that each feature's collection is sharded on.
+	// Values are looked up by entity, so it can only be entity. If it's unset,
+	// collections aren't sharded.
+	PartitionKey string `json:",omitempty"`
 }
 
+// MongoDBEntityPartitionKey shards collections on the field that holds each
+// value's entity.
+const MongoDBEntityPartitionKey = "entity"
+
+type MongoDBVectorSimilarity string
+
+const (
+	MongoDBCosineSimilarity    MongoDBVectorSimilarity = "cosine"
+	MongoDBEuclideanSimilarity MongoDBVectorSimilarity = "euclidean"
+)
+
 func (m MongoDBConfig) Serialized() SerializedConfig {
 	config, err := json.Marshal(m)
 	if err != nil {
@@ -40,12 +66,35 @@ func (m *MongoDBConfig) Deserialize(config SerializedConfig) error {
 	return nil
 }
 
+func (m MongoDBConfig) Validate() error {
+	switch m.VectorSimilarity {
+	case "", MongoDBCosineSimilarity, MongoDBEuclideanSimilarity:
+	default:
+		return fferr.NewInvalidArgumentError(fmt.Errorf("unsupported MongoDB vector similarity %q; expected %s or %s", m.VectorSimilarity, MongoDBCosineSimilarity, MongoDBEuclideanSimilarity))
+	}
+	switch m.PartitionKey {
+	case "", MongoDBEntityPartitionKey:
+	default:
+		return fferr.NewInvalidArgumentError(fmt.Errorf("unsupported MongoDB partition key %q; expected %s", m.PartitionKey, MongoDBEntityPartitionKey))
+	}
+	return nil
+}
+
+// Similarity returns the vector similarity metric, defaulting to cosine.
+func (m MongoDBConfig) Similarity() MongoDBVectorSimilarity {
+	if m.VectorSimilarity == "" {
+		return MongoDBCosineSimilarity
+	}
+	return m.VectorSimilarity
+}
+
 func (m MongoDBConfig) MutableFields() ss.StringSet {
 	return ss.StringSet{
 		"Username":   true,
 		"Password":   true,
 		"Port":       true,
 		"Throughput": true,
+		"DirectCopy": true,
 	}
 }
 
@@ -20,6 +20,7 @@ func TestMongoConfigMutableFields(t *testing.T) {
 		"Password":   true,
 		"Port":       true,
 		"Throughput": true,
+		"DirectCopy": true,
 	}
 
 	config := MongoDBConfig{
@@ -106,3 +107,30 @@ func TestMongoConfigDifferingFields(t *testing.T) {
 	}
 
 }
+
+func TestMongoConfigVectorSimilarity(t *testing.T) {
+	tests := []struct {
+		name       string
+		similarity MongoDBVectorSimilarity
+		expected   MongoDBVectorSimilarity
+		valid      bool
+	}{
+		{"Default", "", MongoDBCosineSimilarity, true},
+		{"Cosine", MongoDBCosineSimilarity, MongoDBCosineSimilarity, true},
+		{"Euclidean", MongoDBEuclideanSimilarity, MongoDBEuclideanSimilarity, true},
+		{"Unsupported", "manhattan", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := MongoDBConfig{VectorIndex: "embeddings", VectorSimilarity: tt.similarity}
+			err := config.Validate()
+			if tt.valid != (err == nil) {
+				t.Fatalf("Expected valid to be %v, got error: %v", tt.valid, err)
+			}
+			if tt.valid && config.Similarity() != tt.expected {
+				t.Errorf("Expected similarity %s, got %s", tt.expected, config.Similarity())
+			}
+		})
+	}
+}
@@ -24,6 +24,8 @@ type PostgresConfig struct {
 	Database string          `json:"Database"`
 	Schema   string          `json:"Schema"`
 	SSLMode  string          `json:"SSLMode"`
+	// ConnectionPool is optional, the defaults are used if it's unset
+	ConnectionPool *SQLConnectionPoolConfig `json:"ConnectionPool,omitempty"`
 }
 
 func (pg *PostgresConfig) Deserialize(config SerializedConfig) error {
@@ -76,10 +78,11 @@ func (pg *PostgresConfig) Serialize() []byte {
 
 func (pg PostgresConfig) MutableFields() ss.StringSet {
 	return ss.StringSet{
-		"Username": true,
-		"Password": true,
-		"Port":     true,
-		"SSLMode":  true,
+		"Username":       true,
+		"Password":       true,
+		"Port":           true,
+		"SSLMode":        true,
+		"ConnectionPool": true,
 	}
 }
 
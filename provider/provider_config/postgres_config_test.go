@@ -19,10 +19,11 @@ import (
 
 func TestPostgresConfigMutableFields(t *testing.T) {
 	expected := ss.StringSet{
-		"Username": true,
-		"Password": true,
-		"Port":     true,
-		"SSLMode":  true,
+		"Username":       true,
+		"Password":       true,
+		"Port":           true,
+		"SSLMode":        true,
+		"ConnectionPool": true,
 	}
 
 	config := PostgresConfig{
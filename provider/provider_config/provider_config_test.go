@@ -21,28 +21,32 @@ import (
 )
 
 var providerMap = map[string]string{
-	"LOCAL_ONLINE":       "LocalConfig",
-	"REDIS_ONLINE":       "RedisConfig",
-	"CASSANDRA_ONLINE":   "CassandraConfig",
-	"FIRESTORE_ONLINE":   "FirestoreConfig",
-	"DYNAMODB_ONLINE":    "DynamodbConfig",
-	"BLOB_ONLINE":        "OnlineBlobConfig",
-	"MONGODB_ONLINE":     "MongoDbConfig",
-	"PINECONE_ONLINE":    "PineconeConfig",
-	"POSTGRES_OFFLINE":   "PostgresConfig",
-	"CLICKHOUSE_OFFLINE": "ClickHouseConfig",
-	"MYSQL_OFFLINE":      "MySqlConfig",
-	"SNOWFLAKE_OFFLINE":  "SnowflakeConfig",
-	"REDSHIFT_OFFLINE":   "RedshiftConfig",
-	"SPARK_OFFLINE":      "SparkConfig",
-	"BIGQUERY_OFFLINE":   "BigQueryConfig",
-	"K8S_OFFLINE":        "K8sConfig",
-	"S3":                 "S3StoreConfig",
-	"GCS":                "GCSFileStoreConfig",
-	"HDFS":               "HDFSConfig",
-	"AZURE":              "AzureFileStoreConfig",
-	"MEMORY_OFFLINE":     "MemoryConfig",
-	"UNIT_TEST":          "UnitTestConfig",
+	"LOCAL_ONLINE":           "LocalConfig",
+	"MEMORY_ONLINE":          "MemoryOnlineConfig",
+	"REDIS_ONLINE":           "RedisConfig",
+	"CASSANDRA_ONLINE":       "CassandraConfig",
+	"FIRESTORE_ONLINE":       "FirestoreConfig",
+	"DYNAMODB_ONLINE":        "DynamodbConfig",
+	"BLOB_ONLINE":            "OnlineBlobConfig",
+	"MONGODB_ONLINE":         "MongoDbConfig",
+	"PINECONE_ONLINE":        "PineconeConfig",
+	"BIGTABLE_ONLINE":        "BigtableConfig",
+	"POSTGRES_OFFLINE":       "PostgresConfig",
+	"CLICKHOUSE_OFFLINE":     "ClickHouseConfig",
+	"MYSQL_OFFLINE":          "MySqlConfig",
+	"SNOWFLAKE_OFFLINE":      "SnowflakeConfig",
+	"REDSHIFT_OFFLINE":       "RedshiftConfig",
+	"SPARK_OFFLINE":          "SparkConfig",
+	"BIGQUERY_OFFLINE":       "BigQueryConfig",
+	"K8S_OFFLINE":            "K8sConfig",
+	"DATABRICKS_SQL_OFFLINE": "DatabricksSQLConfig",
+	"S3":                     "S3StoreConfig",
+	"GCS":                    "GCSFileStoreConfig",
+	"HDFS":                   "HDFSConfig",
+	"AZURE":                  "AzureFileStoreConfig",
+	"MEMORY_OFFLINE":         "MemoryConfig",
+	"KAFKA":                  "KafkaConfig",
+	"UNIT_TEST":              "UnitTestConfig",
 }
 
 /*
@@ -141,6 +145,60 @@ func TestFirestore(t *testing.T) {
 	assert.NotNil(t, instance)
 }
 
+func TestBigtable(t *testing.T) {
+	connectionConfigs, err := getConnectionConfigs()
+	if err != nil {
+		println(err)
+		t.FailNow()
+	}
+
+	var jsonDict map[string]interface{}
+	if err = json.Unmarshal(connectionConfigs, &jsonDict); err != nil {
+		println(err)
+		t.FailNow()
+	}
+
+	config := jsonDict["BigtableConfig"].(map[string]interface{})
+	credentials := config["Credentials"].(map[string]interface{})
+	instance := BigtableConfig{
+		ProjectID:   config["ProjectID"].(string),
+		InstanceID:  config["InstanceID"].(string),
+		TablePrefix: config["TablePrefix"].(string),
+		Credentials: credentials,
+	}
+
+	assert.NotNil(t, instance)
+}
+
+func TestKafka(t *testing.T) {
+	connectionConfigs, err := getConnectionConfigs()
+	if err != nil {
+		println(err)
+		t.FailNow()
+	}
+
+	var jsonDict map[string]interface{}
+	if err = json.Unmarshal(connectionConfigs, &jsonDict); err != nil {
+		println(err)
+		t.FailNow()
+	}
+
+	config := jsonDict["KafkaConfig"].(map[string]interface{})
+	var brokers []string
+	for _, broker := range config["Brokers"].([]interface{}) {
+		brokers = append(brokers, broker.(string))
+	}
+	instance := KafkaConfig{
+		Brokers:           brokers,
+		Format:            KafkaFormat(config["Format"].(string)),
+		SchemaRegistryURL: config["SchemaRegistryURL"].(string),
+		TLS:               config["TLS"].(bool),
+	}
+
+	assert.NotNil(t, instance)
+	assert.Nil(t, instance.Validate())
+}
+
 func TestDynamo(t *testing.T) {
 	connectionConfigs, err := getConnectionConfigs()
 	if err != nil {
@@ -15,11 +15,51 @@ import (
 	ss "github.com/featureform/helpers/stringset"
 )
 
+// RedisTopology is how a Redis deployment is laid out.
+type RedisTopology string
+
+const (
+	// RedisStandalone is a single node at Addr. It's used when Topology is unset.
+	RedisStandalone RedisTopology = "standalone"
+	// RedisCluster is a Redis Cluster, discovered from the seed nodes in
+	// ClusterAddrs. Keys are spread across the nodes by hash slot.
+	RedisCluster RedisTopology = "cluster"
+	// RedisSentinel is a primary and its replicas monitored by the Sentinels in
+	// SentinelAddrs, which are asked for the current primary on failover.
+	RedisSentinel RedisTopology = "sentinel"
+)
+
 type RedisConfig struct {
-	Prefix   string
-	Addr     string
+	Prefix string
+	Addr   string
+	// Username authenticates with an ACL user. Password alone authenticates as
+	// the default user.
+	Username string `json:",omitempty"`
 	Password string
 	DB       int
+	// PipelineSize is the number of writes batched into a single pipeline when
+	// materializing features. Defaults to 1000 when unset.
+	PipelineSize int           `json:",omitempty"`
+	Topology     RedisTopology `json:",omitempty"`
+	// ClusterAddrs are the seed nodes of a cluster. Only a few are needed, the
+	// rest of the cluster is discovered from them.
+	ClusterAddrs []string `json:",omitempty"`
+	// SentinelMasterName is the name that the Sentinels monitor the primary as.
+	SentinelMasterName string   `json:",omitempty"`
+	SentinelAddrs      []string `json:",omitempty"`
+	// TLS encrypts connections to Redis, and to the Sentinels if there are
+	// any. The server's certificate is verified against TLSCACert, or the
+	// system's CAs if it's empty. TLSCert and TLSKey are a client certificate
+	// for servers that require one. All three are PEM encoded.
+	TLS                   bool   `json:",omitempty"`
+	TLSCACert             string `json:",omitempty"`
+	TLSCert               string `json:",omitempty"`
+	TLSKey                string `json:",omitempty"`
+	TLSInsecureSkipVerify bool   `json:",omitempty"`
+	// DirectCopy lets offline stores that support it, like Spark, write
+	// materialized features straight into Redis rather than through the
+	// materialization runner.
+	DirectCopy bool `json:",omitempty"`
 }
 
 func (r RedisConfig) Serialized() SerializedConfig {
@@ -38,9 +78,78 @@ func (r *RedisConfig) Deserialize(config SerializedConfig) error {
 	return nil
 }
 
+// GetTopology returns the config's topology, defaulting to a standalone node.
+func (r RedisConfig) GetTopology() RedisTopology {
+	if r.Topology == "" {
+		return RedisStandalone
+	}
+	return r.Topology
+}
+
+// Addrs returns the addresses that a client first connects to: the node of a
+// standalone deployment, or the seed nodes or Sentinels otherwise.
+func (r RedisConfig) Addrs() []string {
+	switch r.GetTopology() {
+	case RedisCluster:
+		return r.ClusterAddrs
+	case RedisSentinel:
+		return r.SentinelAddrs
+	default:
+		return []string{r.Addr}
+	}
+}
+
+func (r RedisConfig) Validate() error {
+	switch r.GetTopology() {
+	case RedisStandalone:
+		if r.Addr == "" {
+			return fferr.NewInvalidArgumentErrorf("Redis requires an address")
+		}
+	case RedisCluster:
+		if len(r.ClusterAddrs) == 0 {
+			return fferr.NewInvalidArgumentErrorf("Redis Cluster requires at least one seed node")
+		}
+		// Cluster nodes only have database 0.
+		if r.DB != 0 {
+			return fferr.NewInvalidArgumentErrorf("Redis Cluster doesn't support selecting database %d", r.DB)
+		}
+	case RedisSentinel:
+		if r.SentinelMasterName == "" {
+			return fferr.NewInvalidArgumentErrorf("Redis Sentinel requires a master name")
+		}
+		if len(r.SentinelAddrs) == 0 {
+			return fferr.NewInvalidArgumentErrorf("Redis Sentinel requires at least one Sentinel address")
+		}
+	default:
+		return fferr.NewInvalidArgumentErrorf(
+			"Redis topology must be %s, %s or %s, got %q", RedisStandalone, RedisCluster, RedisSentinel, r.Topology,
+		)
+	}
+	usesTLSOptions := r.TLSCACert != "" || r.TLSCert != "" || r.TLSKey != "" || r.TLSInsecureSkipVerify
+	if usesTLSOptions && !r.TLS {
+		return fferr.NewInvalidArgumentErrorf("Redis TLS options are set but TLS isn't enabled")
+	}
+	if (r.TLSCert == "") != (r.TLSKey == "") {
+		return fferr.NewInvalidArgumentErrorf("Redis client certificate and key must be set together")
+	}
+	return nil
+}
+
+// MutableFields allows the seed nodes and Sentinels to change, since nodes are
+// replaced over time, but not the topology or the master name, which would
+// point at a different deployment. Credentials and certificates can be
+// rotated, and direct copies can be turned on or off.
 func (r RedisConfig) MutableFields() ss.StringSet {
 	return ss.StringSet{
-		"Password": true,
+		"Username":      true,
+		"Password":      true,
+		"PipelineSize":  true,
+		"ClusterAddrs":  true,
+		"SentinelAddrs": true,
+		"TLSCACert":     true,
+		"TLSCert":       true,
+		"TLSKey":        true,
+		"DirectCopy":    true,
 	}
 }
 
@@ -16,7 +16,15 @@ import (
 
 func TestRedisConfigMutableFields(t *testing.T) {
 	expected := ss.StringSet{
-		"Password": true,
+		"Username":      true,
+		"Password":      true,
+		"PipelineSize":  true,
+		"ClusterAddrs":  true,
+		"SentinelAddrs": true,
+		"TLSCACert":     true,
+		"TLSCert":       true,
+		"TLSKey":        true,
+		"DirectCopy":    true,
 	}
 
 	config := RedisConfig{
@@ -68,6 +76,20 @@ func TestRedisConfigDifferingFields(t *testing.T) {
 		}, ss.StringSet{
 			"Password": true,
 		}},
+		{"Differing Pipeline Size", args{
+			a: RedisConfig{
+				Addr:         "0.0.0.0:6379",
+				Password:     "password",
+				PipelineSize: 100,
+			},
+			b: RedisConfig{
+				Addr:         "0.0.0.0:6379",
+				Password:     "password",
+				PipelineSize: 500,
+			},
+		}, ss.StringSet{
+			"PipelineSize": true,
+		}},
 	}
 
 	for _, tt := range tests {
@@ -86,3 +108,31 @@ func TestRedisConfigDifferingFields(t *testing.T) {
 	}
 
 }
+
+func TestRedisConfigValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  RedisConfig
+		wantErr bool
+	}{
+		{"Standalone", RedisConfig{Addr: "0.0.0.0:6379"}, false},
+		{"Standalone Without Addr", RedisConfig{}, true},
+		{"Cluster", RedisConfig{Topology: RedisCluster, ClusterAddrs: []string{"node-1:6379"}}, false},
+		{"Cluster Without Seed Nodes", RedisConfig{Topology: RedisCluster}, true},
+		{"Cluster With DB", RedisConfig{Topology: RedisCluster, ClusterAddrs: []string{"node-1:6379"}, DB: 2}, true},
+		{"Sentinel", RedisConfig{Topology: RedisSentinel, SentinelMasterName: "mymaster", SentinelAddrs: []string{"sentinel:26379"}}, false},
+		{"Sentinel Without Master", RedisConfig{Topology: RedisSentinel, SentinelAddrs: []string{"sentinel:26379"}}, true},
+		{"Sentinel Without Addrs", RedisConfig{Topology: RedisSentinel, SentinelMasterName: "mymaster"}, true},
+		{"Unknown Topology", RedisConfig{Topology: "ring", Addr: "0.0.0.0:6379"}, true},
+		{"TLS", RedisConfig{Addr: "0.0.0.0:6379", TLS: true, TLSCACert: "ca", TLSCert: "cert", TLSKey: "key"}, false},
+		{"TLS Options Without TLS", RedisConfig{Addr: "0.0.0.0:6379", TLSInsecureSkipVerify: true}, true},
+		{"TLS Cert Without Key", RedisConfig{Addr: "0.0.0.0:6379", TLS: true, TLSCert: "cert"}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.config.Validate(); (err != nil) != tt.wantErr {
+				t.Fatalf("Expected error: %v, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
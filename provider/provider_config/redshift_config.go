@@ -22,6 +22,12 @@ type RedshiftConfig struct {
 	Username string
 	Password string
 	SSLMode  string
+	// IAMRole is the ARN of the role Redshift assumes to export training sets
+	// to S3 and import sources from it, or "default" to use the cluster's
+	// default role.
+	IAMRole string `json:",omitempty"`
+	// ConnectionPool is optional, the defaults are used if it's unset
+	ConnectionPool *SQLConnectionPoolConfig `json:",omitempty"`
 }
 
 func (rs *RedshiftConfig) Deserialize(config SerializedConfig) error {
@@ -42,10 +48,12 @@ func (rs *RedshiftConfig) Serialize() []byte {
 
 func (rs RedshiftConfig) MutableFields() ss.StringSet {
 	return ss.StringSet{
-		"Username": true,
-		"Password": true,
-		"Port":     true,
-		"SSLMode":  true,
+		"Username":       true,
+		"Password":       true,
+		"Port":           true,
+		"SSLMode":        true,
+		"IAMRole":        true,
+		"ConnectionPool": true,
 	}
 }
 
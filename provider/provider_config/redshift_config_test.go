@@ -16,10 +16,12 @@ import (
 
 func TestRedshiftConfigMutableFields(t *testing.T) {
 	expected := ss.StringSet{
-		"Username": true,
-		"Password": true,
-		"Port":     true,
-		"SSLMode":  true,
+		"Username":       true,
+		"Password":       true,
+		"Port":           true,
+		"SSLMode":        true,
+		"IAMRole":        true,
+		"ConnectionPool": true,
 	}
 
 	config := RedshiftConfig{
@@ -22,15 +22,20 @@ type S3FileStoreConfig struct {
 	BucketPath string
 	// Path is the subpath in the bucket to work in
 	Path string
-	// Endpoint is used when using a S3 compatible service outside of AWS like localstack
+	// Endpoint is used when using a S3 compatible service outside of AWS like localstack or MinIO
 	Endpoint string
+	// ForcePathStyle addresses buckets as <endpoint>/<bucket> instead of <bucket>.<endpoint>,
+	// which most S3 compatible services like MinIO require
+	ForcePathStyle bool
 }
 
 type s3FileStoreConfigTemp struct {
-	BucketRegion string
-	BucketPath   string
-	Path         string
-	Credentials  json.RawMessage
+	BucketRegion   string
+	BucketPath     string
+	Path           string
+	Endpoint       string
+	ForcePathStyle bool
+	Credentials    json.RawMessage
 }
 
 func (s *S3FileStoreConfig) Deserialize(config SerializedConfig) error {
@@ -42,6 +47,8 @@ func (s *S3FileStoreConfig) Deserialize(config SerializedConfig) error {
 	s.BucketPath = temp.BucketPath
 	s.BucketRegion = temp.BucketRegion
 	s.Path = temp.Path
+	s.Endpoint = temp.Endpoint
+	s.ForcePathStyle = temp.ForcePathStyle
 
 	creds, err := UnmarshalAWSCredentials(temp.Credentials)
 	if err != nil {
@@ -123,6 +123,21 @@ func TestS3FileStoreConfig_SerializationDeserialization(t *testing.T) {
 			},
 			wantErr: false,
 		},
+		{
+			name: "custom endpoint",
+			config: S3FileStoreConfig{
+				BucketRegion:   "us-east-1",
+				BucketPath:     "featureform.transactions",
+				Path:           "transactions",
+				Endpoint:       "http://minio:9000",
+				ForcePathStyle: true,
+				Credentials: AWSStaticCredentials{
+					AccessKeyId: "minio",
+					SecretKey:   "minio123",
+				},
+			},
+			wantErr: false,
+		},
 	}
 
 	for _, tt := range tests {
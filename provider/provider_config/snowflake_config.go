@@ -8,8 +8,13 @@
 package provider_config
 
 import (
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
 	"encoding/json"
+	"encoding/pem"
 	"fmt"
+	"net/url"
 	"strings"
 
 	"github.com/featureform/fferr"
@@ -17,6 +22,7 @@ import (
 	sr "github.com/featureform/helpers/struct_iterator"
 	"github.com/featureform/logging/redacted"
 	"github.com/featureform/provider/provider_type"
+	"github.com/youmark/pkcs8"
 )
 
 type SnowflakeTableConfig struct {
@@ -43,6 +49,12 @@ type SnowflakeConfig struct {
 	Role           string
 	Catalog        *SnowflakeCatalogConfig
 	SessionParams  map[string]string
+	// PrivateKey is a PEM encoded RSA key used to authenticate with a JWT instead of
+	// the Password. PrivateKeyPassphrase is only needed if the key is encrypted.
+	PrivateKey           string `json:",omitempty"`
+	PrivateKeyPassphrase string `json:",omitempty"`
+	// ConnectionPool is optional, the defaults are used if it's unset
+	ConnectionPool *SQLConnectionPoolConfig `json:",omitempty"`
 }
 
 func (sf *SnowflakeConfig) Deserialize(config SerializedConfig) error {
@@ -63,13 +75,16 @@ func (sf *SnowflakeConfig) Serialize() []byte {
 
 func (sf SnowflakeConfig) MutableFields() ss.StringSet {
 	return ss.StringSet{
-		"Username":      true,
-		"Password":      true,
-		"Role":          true,
-		"Schema":        true,
-		"Database":      true,
-		"Warehouse":     true,
-		"SessionParams": true,
+		"Username":             true,
+		"Password":             true,
+		"PrivateKey":           true,
+		"PrivateKeyPassphrase": true,
+		"Role":                 true,
+		"Schema":               true,
+		"Database":             true,
+		"Warehouse":            true,
+		"SessionParams":        true,
+		"ConnectionPool":       true,
 	}
 }
 
@@ -82,17 +97,20 @@ func (sf *SnowflakeConfig) Redacted() *SnowflakeConfig {
 		redactedSessionParams[key] = redacted.String
 	}
 	return &SnowflakeConfig{
-		Username: sf.Username,
-		Password: redacted.String,
-		AccountLocator: sf.AccountLocator,
-		Organization: sf.Organization,
-		Account: sf.Account,
-		Database: sf.Database,
-		Schema: sf.Schema,
-		Warehouse: sf.Warehouse,
-		Role: sf.Role,
-		Catalog: sf.Catalog,
-		SessionParams: redactedSessionParams,
+		Username:             sf.Username,
+		Password:             redacted.String,
+		PrivateKey:           redacted.String,
+		PrivateKeyPassphrase: redacted.String,
+		AccountLocator:       sf.AccountLocator,
+		Organization:         sf.Organization,
+		Account:              sf.Account,
+		Database:             sf.Database,
+		Schema:               sf.Schema,
+		Warehouse:            sf.Warehouse,
+		Role:                 sf.Role,
+		Catalog:              sf.Catalog,
+		SessionParams:        redactedSessionParams,
+		ConnectionPool:       sf.ConnectionPool,
 	}
 }
 
@@ -149,6 +167,16 @@ func (sf *SnowflakeConfig) getConnectionParameters() (string, error) {
 		switch iter.Key() {
 		case "Warehouse", "Role":
 			base = sf.addParameter(base, strings.ToLower(iter.Key()), iter.Value())
+		case "PrivateKey":
+			if !sf.UsesKeyPairAuth() {
+				continue
+			}
+			key, err := sf.encodedPrivateKey()
+			if err != nil {
+				return "", err
+			}
+			base = sf.addParameter(base, "authenticator", "SNOWFLAKE_JWT")
+			base = sf.addParameter(base, "privateKey", url.QueryEscape(key))
 		case "SessionParams":
 			for key, val := range sf.SessionParams {
 				base = sf.addParameter(base, key, val)
@@ -192,16 +220,64 @@ func (sf *SnowflakeConfig) getBaseConnection(database, schema string) (string, e
 	}
 
 	if isLegacy {
-		return fmt.Sprintf("%s:%s@%s/%s/%s", sf.Username, sf.Password, sf.AccountLocator, database, schema), nil
+		return fmt.Sprintf("%s@%s/%s/%s", sf.userInfo(), sf.AccountLocator, database, schema), nil
 	}
 
 	if isCurrent {
-		return fmt.Sprintf("%s:%s@%s-%s/%s/%s", sf.Username, sf.Password, sf.Organization, sf.Account, database, schema), nil
+		return fmt.Sprintf("%s@%s-%s/%s/%s", sf.userInfo(), sf.Organization, sf.Account, database, schema), nil
 	}
 
 	return "", fferr.NewProviderConfigError(string(provider_type.SnowflakeOffline), fmt.Errorf("credentials not found"))
 }
 
+// UsesKeyPairAuth returns true if the connection authenticates with a private key
+// rather than a password.
+func (sf *SnowflakeConfig) UsesKeyPairAuth() bool {
+	return sf.PrivateKey != ""
+}
+
+// userInfo is the user part of the DSN. The password is left out when
+// authenticating with a private key.
+func (sf *SnowflakeConfig) userInfo() string {
+	if sf.UsesKeyPairAuth() {
+		return sf.Username
+	}
+	return fmt.Sprintf("%s:%s", sf.Username, sf.Password)
+}
+
+// encodedPrivateKey decrypts the private key, if needed, and encodes it as the
+// unencrypted base64 PKCS8 DER that the Snowflake driver expects.
+func (sf *SnowflakeConfig) encodedPrivateKey() (string, error) {
+	providerType := string(provider_type.SnowflakeOffline)
+	block, _ := pem.Decode([]byte(sf.PrivateKey))
+	if block == nil {
+		return "", fferr.NewProviderConfigError(providerType, fmt.Errorf("private key is not PEM encoded"))
+	}
+	var key *rsa.PrivateKey
+	var err error
+	switch block.Type {
+	case "ENCRYPTED PRIVATE KEY":
+		if sf.PrivateKeyPassphrase == "" {
+			return "", fferr.NewProviderConfigError(providerType, fmt.Errorf("private key is encrypted but no passphrase was given"))
+		}
+		key, err = pkcs8.ParsePKCS8PrivateKeyRSA(block.Bytes, []byte(sf.PrivateKeyPassphrase))
+	case "PRIVATE KEY":
+		key, err = pkcs8.ParsePKCS8PrivateKeyRSA(block.Bytes)
+	case "RSA PRIVATE KEY":
+		key, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	default:
+		err = fmt.Errorf("unsupported PEM block type %s", block.Type)
+	}
+	if err != nil {
+		return "", fferr.NewProviderConfigError(providerType, fmt.Errorf("could not parse private key: %w", err))
+	}
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		return "", fferr.NewProviderConfigError(providerType, fmt.Errorf("could not encode private key: %w", err))
+	}
+	return base64.URLEncoding.EncodeToString(der), nil
+}
+
 func (sf *SnowflakeConfig) GetBaseURL() string {
 	suffix := "snowflakecomputing.com"
 	isLegacy := sf.HasLegacyCredentials()
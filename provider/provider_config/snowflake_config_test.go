@@ -16,13 +16,16 @@ import (
 
 func TestSnowflakeConfigMutableFields(t *testing.T) {
 	expected := ss.StringSet{
-		"Username":      true,
-		"Password":      true,
-		"Role":          true,
-		"Schema":        true,
-		"Database":      true,
-		"Warehouse":     true,
-		"SessionParams": true,
+		"Username":             true,
+		"Password":             true,
+		"PrivateKey":           true,
+		"PrivateKeyPassphrase": true,
+		"Role":                 true,
+		"Schema":               true,
+		"Database":             true,
+		"Warehouse":            true,
+		"SessionParams":        true,
+		"ConnectionPool":       true,
 	}
 
 	config := SnowflakeConfig{
@@ -10,6 +10,8 @@ package provider_config
 import (
 	"encoding/json"
 	"fmt"
+	"regexp"
+	"strings"
 
 	"github.com/featureform/fferr"
 
@@ -54,12 +56,94 @@ type GlueConfig struct {
 	TableFormat   TableFormat
 }
 
+// defaultGlueTableFormat is the table format of Glue configs that were saved
+// before the format could be chosen.
+const defaultGlueTableFormat = Iceberg
+
+// applyDefaults fills in settings that older serialized configs don't have.
+func (g *GlueConfig) applyDefaults() {
+	if g.TableFormat == "" {
+		g.TableFormat = defaultGlueTableFormat
+	}
+}
+
+// Validate returns an error if the table format isn't one the Spark offline
+// store can read and write through the Glue catalog.
+func (f TableFormat) Validate() error {
+	switch f {
+	case Iceberg, DeltaLake:
+		return nil
+	default:
+		return fferr.NewProviderConfigError(
+			"Spark",
+			fmt.Errorf("the table format '%s' is not supported; expected '%s' or '%s'", f, Iceberg, DeltaLake),
+		)
+	}
+}
+
+// Validate checks that the Glue settings required by the configured table format are set.
+// Iceberg tables are written under the warehouse, while Delta tables are written under
+// the Glue database's location, so only Iceberg requires a warehouse. An empty table
+// format is treated as Iceberg.
+func (g GlueConfig) Validate() error {
+	g.applyDefaults()
+	if err := g.TableFormat.Validate(); err != nil {
+		return err
+	}
+	if g.Database == "" {
+		return fferr.NewProviderConfigError("Spark", fmt.Errorf("a Glue database is required"))
+	}
+	if g.Region == "" {
+		return fferr.NewProviderConfigError("Spark", fmt.Errorf("a Glue region is required"))
+	}
+	if g.TableFormat == Iceberg && g.Warehouse == "" {
+		return fferr.NewProviderConfigError("Spark", fmt.Errorf("a Glue warehouse is required for %s tables", Iceberg))
+	}
+	return nil
+}
+
 type SparkFlags struct {
 	SparkParams     map[string]string `json:"SparkParams"`
 	WriteOptions    map[string]string `json:"WriteOptions"`
 	TableProperties map[string]string `json:"TableProperties"`
 }
 
+// SparkSubmitOptions are extra --conf values and --packages that are passed to
+// spark-submit for a single transformation, on top of the ones Featureform sets.
+type SparkSubmitOptions struct {
+	Configs  map[string]string `json:"Configs,omitempty"`
+	Packages []string          `json:"Packages,omitempty"`
+}
+
+// sparkPackagePattern matches Maven coordinates of the form groupId:artifactId:version.
+var sparkPackagePattern = regexp.MustCompile(`^[A-Za-z0-9_.\-]+:[A-Za-z0-9_.\-]+:[A-Za-z0-9_.\-]+$`)
+
+func (opts SparkSubmitOptions) IsEmpty() bool {
+	return len(opts.Configs) == 0 && len(opts.Packages) == 0
+}
+
+// Validate makes sure each option will be passed to spark-submit as exactly
+// one argument, so that it can't inject flags of its own.
+func (opts SparkSubmitOptions) Validate() error {
+	for key, value := range opts.Configs {
+		if !strings.HasPrefix(key, "spark.") {
+			return fferr.NewInvalidArgumentErrorf("spark config %q must start with \"spark.\"", key)
+		}
+		if strings.ContainsAny(key, "= \t\r\n") {
+			return fferr.NewInvalidArgumentErrorf("spark config %q cannot contain whitespace or \"=\"", key)
+		}
+		if strings.ContainsAny(value, "\r\n") {
+			return fferr.NewInvalidArgumentErrorf("value of spark config %q cannot contain newlines", key)
+		}
+	}
+	for _, pkg := range opts.Packages {
+		if !sparkPackagePattern.MatchString(pkg) {
+			return fferr.NewInvalidArgumentErrorf("spark package %q must be of the form groupId:artifactId:version", pkg)
+		}
+	}
+	return nil
+}
+
 type SparkConfig struct {
 	ExecutorType   SparkExecutorType
 	ExecutorConfig SparkExecutorConfig
@@ -86,6 +170,9 @@ func (s *SparkConfig) Deserialize(config SerializedConfig) error {
 	s.ExecutorType = temp.ExecutorType
 	s.StoreType = temp.StoreType
 	s.GlueConfig = temp.GlueConfig
+	if s.GlueConfig != nil {
+		s.GlueConfig.applyDefaults()
+	}
 
 	execData, err := json.Marshal(temp.ExecutorConfig)
 	if err != nil {
@@ -346,6 +433,7 @@ func (s *SparkConfig) decodeGlueConfig(configMap *GlueConfig) error {
 	if err != nil {
 		return fferr.NewInternalError(err)
 	}
+	glueConfig.applyDefaults()
 	s.GlueConfig = &glueConfig
 	return nil
 }
@@ -423,3 +423,133 @@ func TestSparkConfig_Serde(t *testing.T) {
 		}
 	}
 }
+
+func TestGlueConfigValidate(t *testing.T) {
+	tests := []struct {
+		name      string
+		config    GlueConfig
+		expectErr bool
+	}{
+		{
+			name:   "Iceberg",
+			config: GlueConfig{Database: "db", Region: "us-east-1", Warehouse: "s3://bucket/warehouse", TableFormat: Iceberg},
+		},
+		{
+			name:      "Iceberg without warehouse",
+			config:    GlueConfig{Database: "db", Region: "us-east-1", TableFormat: Iceberg},
+			expectErr: true,
+		},
+		{
+			name:   "Delta without warehouse",
+			config: GlueConfig{Database: "db", Region: "us-east-1", TableFormat: DeltaLake},
+		},
+		{
+			name:      "Missing database",
+			config:    GlueConfig{Region: "us-east-1", TableFormat: DeltaLake},
+			expectErr: true,
+		},
+		{
+			name:   "Missing table format defaults to Iceberg",
+			config: GlueConfig{Database: "db", Region: "us-east-1", Warehouse: "s3://bucket/warehouse"},
+		},
+		{
+			name:      "Missing table format without warehouse",
+			config:    GlueConfig{Database: "db", Region: "us-east-1"},
+			expectErr: true,
+		},
+		{
+			name:      "Unsupported table format",
+			config:    GlueConfig{Database: "db", Region: "us-east-1", TableFormat: "hudi"},
+			expectErr: true,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if err := test.config.Validate(); (err != nil) != test.expectErr {
+				t.Fatalf("Expected error: %v, got: %v", test.expectErr, err)
+			}
+		})
+	}
+}
+
+func TestSparkConfigDeserializeGlueDefaultTableFormat(t *testing.T) {
+	credentials := AWSStaticCredentials{AccessKeyId: "aws-key", SecretKey: "aws-secret"}
+	sparkConfig := SparkConfig{
+		ExecutorType:   SparkGeneric,
+		ExecutorConfig: &SparkGenericConfig{Master: "local"},
+		StoreType:      filestore.S3,
+		StoreConfig:    &S3FileStoreConfig{Credentials: credentials, BucketRegion: "us-east-1", BucketPath: "bucket"},
+		GlueConfig:     &GlueConfig{Database: "db", Region: "us-east-1", Warehouse: "s3://bucket/warehouse"},
+	}
+	serialized, err := sparkConfig.Serialize()
+	if err != nil {
+		t.Fatalf("Failed to serialize SparkConfig: %v", err)
+	}
+	deserialized := SparkConfig{}
+	if err := deserialized.Deserialize(serialized); err != nil {
+		t.Fatalf("Failed to deserialize SparkConfig: %v", err)
+	}
+	if deserialized.GlueConfig.TableFormat != Iceberg {
+		t.Fatalf("Expected table format %s, got %q", Iceberg, deserialized.GlueConfig.TableFormat)
+	}
+	if err := deserialized.GlueConfig.Validate(); err != nil {
+		t.Fatalf("Expected Glue config without a table format to validate: %v", err)
+	}
+}
+
+func TestSparkSubmitOptionsValidate(t *testing.T) {
+	tests := []struct {
+		name      string
+		options   SparkSubmitOptions
+		expectErr bool
+	}{
+		{
+			name:    "Empty",
+			options: SparkSubmitOptions{},
+		},
+		{
+			name: "Valid",
+			options: SparkSubmitOptions{
+				Configs:  map[string]string{"spark.executor.memory": "4g", "spark.driver.extraJavaOptions": "-Da=b -Dc=d"},
+				Packages: []string{"io.delta:delta-spark_2.12:3.2.0"},
+			},
+		},
+		{
+			name:      "Non spark config",
+			options:   SparkSubmitOptions{Configs: map[string]string{"hadoop.tmp.dir": "/tmp"}},
+			expectErr: true,
+		},
+		{
+			name:      "Key with whitespace",
+			options:   SparkSubmitOptions{Configs: map[string]string{"spark.executor.memory --master": "4g"}},
+			expectErr: true,
+		},
+		{
+			name:      "Key with equals",
+			options:   SparkSubmitOptions{Configs: map[string]string{"spark.executor.memory=1g": "4g"}},
+			expectErr: true,
+		},
+		{
+			name:      "Value with newline",
+			options:   SparkSubmitOptions{Configs: map[string]string{"spark.executor.memory": "4g\n--master local"}},
+			expectErr: true,
+		},
+		{
+			name:      "Package without version",
+			options:   SparkSubmitOptions{Packages: []string{"io.delta:delta-spark_2.12"}},
+			expectErr: true,
+		},
+		{
+			name:      "Comma separated packages",
+			options:   SparkSubmitOptions{Packages: []string{"io.delta:delta-spark_2.12:3.2.0,org.a:b:1"}},
+			expectErr: true,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if err := test.options.Validate(); (err != nil) != test.expectErr {
+				t.Fatalf("Expected error: %v, got: %v", test.expectErr, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,122 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+//
+// Copyright 2024 FeatureForm Inc.
+//
+
+package provider_config
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/featureform/fferr"
+)
+
+const (
+	DefaultSQLMaxOpenConns    = 25
+	DefaultSQLMaxIdleConns    = 25
+	DefaultSQLConnMaxLifetime = time.Hour
+)
+
+// SQLConnectionPoolConfig sets how a SQL offline store pools its connections to the
+// database. Unset fields use the defaults above.
+//
+// MaxOpenConns caps how many queries can run at once; each open training set iterator
+// holds a connection until it's exhausted or closed, so a low cap makes concurrent
+// reads wait on each other while a high one can exhaust the database's own limit when
+// there are many replicas. MaxIdleConns keeps connections around between queries to
+// avoid reconnecting, at the cost of holding them open on the database. Connections are
+// replaced after ConnMaxLifetime so load balancers and credential rotation are picked
+// up; a short lifetime means reconnecting more often.
+type SQLConnectionPoolConfig struct {
+	MaxOpenConns    int
+	MaxIdleConns    int
+	ConnMaxLifetime time.Duration
+}
+
+// sqlConnectionPoolConfigJSON is the serialized form of SQLConnectionPoolConfig.
+// ConnMaxLifetime is a duration string, like "30m", rather than nanoseconds.
+type sqlConnectionPoolConfigJSON struct {
+	MaxOpenConns    int
+	MaxIdleConns    int
+	ConnMaxLifetime json.RawMessage `json:",omitempty"`
+}
+
+func (c SQLConnectionPoolConfig) MarshalJSON() ([]byte, error) {
+	serialized := sqlConnectionPoolConfigJSON{MaxOpenConns: c.MaxOpenConns, MaxIdleConns: c.MaxIdleConns}
+	if c.ConnMaxLifetime != 0 {
+		lifetime, err := json.Marshal(c.ConnMaxLifetime.String())
+		if err != nil {
+			return nil, err
+		}
+		serialized.ConnMaxLifetime = lifetime
+	}
+	return json.Marshal(serialized)
+}
+
+// UnmarshalJSON reads ConnMaxLifetime as a duration string. Configs serialized
+// before it was a string have it in nanoseconds, which is still accepted.
+func (c *SQLConnectionPoolConfig) UnmarshalJSON(data []byte) error {
+	var serialized sqlConnectionPoolConfigJSON
+	if err := json.Unmarshal(data, &serialized); err != nil {
+		return fferr.NewInternalError(err)
+	}
+	cfg := SQLConnectionPoolConfig{MaxOpenConns: serialized.MaxOpenConns, MaxIdleConns: serialized.MaxIdleConns}
+	if len(serialized.ConnMaxLifetime) > 0 {
+		var lifetime string
+		var nanos int64
+		if err := json.Unmarshal(serialized.ConnMaxLifetime, &lifetime); err == nil {
+			parsed, err := time.ParseDuration(lifetime)
+			if err != nil {
+				return fferr.NewInvalidArgumentError(fmt.Errorf("invalid connection pool ConnMaxLifetime %q: %w", lifetime, err))
+			}
+			cfg.ConnMaxLifetime = parsed
+		} else if err := json.Unmarshal(serialized.ConnMaxLifetime, &nanos); err == nil {
+			cfg.ConnMaxLifetime = time.Duration(nanos)
+		} else {
+			return fferr.NewInvalidArgumentError(fmt.Errorf("invalid connection pool ConnMaxLifetime %s, expected a duration like \"30m\"", serialized.ConnMaxLifetime))
+		}
+	}
+	*c = cfg
+	return nil
+}
+
+// WithDefaults returns the config with unset fields replaced by their defaults. It's
+// safe to call on a nil config.
+func (c *SQLConnectionPoolConfig) WithDefaults() SQLConnectionPoolConfig {
+	cfg := SQLConnectionPoolConfig{
+		MaxOpenConns:    DefaultSQLMaxOpenConns,
+		MaxIdleConns:    DefaultSQLMaxIdleConns,
+		ConnMaxLifetime: DefaultSQLConnMaxLifetime,
+	}
+	if c == nil {
+		return cfg
+	}
+	if c.MaxOpenConns > 0 {
+		cfg.MaxOpenConns = c.MaxOpenConns
+	}
+	if c.MaxIdleConns > 0 {
+		cfg.MaxIdleConns = c.MaxIdleConns
+	}
+	if c.ConnMaxLifetime > 0 {
+		cfg.ConnMaxLifetime = c.ConnMaxLifetime
+	}
+	// Idle connections beyond the open limit would be closed immediately anyway.
+	if cfg.MaxIdleConns > cfg.MaxOpenConns {
+		cfg.MaxIdleConns = cfg.MaxOpenConns
+	}
+	return cfg
+}
+
+func (c *SQLConnectionPoolConfig) Validate() error {
+	if c == nil {
+		return nil
+	}
+	if c.MaxOpenConns < 0 || c.MaxIdleConns < 0 || c.ConnMaxLifetime < 0 {
+		return fferr.NewInvalidArgumentError(fmt.Errorf("connection pool settings cannot be negative: %+v", *c))
+	}
+	return nil
+}
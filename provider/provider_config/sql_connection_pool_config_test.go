@@ -0,0 +1,91 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+//
+// Copyright 2024 FeatureForm Inc.
+//
+
+package provider_config
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestSQLConnectionPoolConfigWithDefaults(t *testing.T) {
+	defaults := SQLConnectionPoolConfig{
+		MaxOpenConns:    DefaultSQLMaxOpenConns,
+		MaxIdleConns:    DefaultSQLMaxIdleConns,
+		ConnMaxLifetime: DefaultSQLConnMaxLifetime,
+	}
+	tests := map[string]struct {
+		config   *SQLConnectionPoolConfig
+		expected SQLConnectionPoolConfig
+	}{
+		"nil":   {nil, defaults},
+		"empty": {&SQLConnectionPoolConfig{}, defaults},
+		"set": {
+			&SQLConnectionPoolConfig{MaxOpenConns: 50, MaxIdleConns: 10, ConnMaxLifetime: time.Minute},
+			SQLConnectionPoolConfig{MaxOpenConns: 50, MaxIdleConns: 10, ConnMaxLifetime: time.Minute},
+		},
+		"idle capped at open": {
+			&SQLConnectionPoolConfig{MaxOpenConns: 5},
+			SQLConnectionPoolConfig{MaxOpenConns: 5, MaxIdleConns: 5, ConnMaxLifetime: DefaultSQLConnMaxLifetime},
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			if actual := test.config.WithDefaults(); actual != test.expected {
+				t.Fatalf("expected %+v, got %+v", test.expected, actual)
+			}
+		})
+	}
+}
+
+func TestSQLConnectionPoolConfigValidate(t *testing.T) {
+	var nilConfig *SQLConnectionPoolConfig
+	if err := nilConfig.Validate(); err != nil {
+		t.Fatalf("expected nil config to be valid: %v", err)
+	}
+	if err := (&SQLConnectionPoolConfig{MaxOpenConns: 10}).Validate(); err != nil {
+		t.Fatalf("expected config to be valid: %v", err)
+	}
+	if err := (&SQLConnectionPoolConfig{MaxOpenConns: -1}).Validate(); err == nil {
+		t.Fatalf("expected negative max open conns to be invalid")
+	}
+}
+
+func TestSQLConnectionPoolConfigSerializesLifetimeAsDuration(t *testing.T) {
+	config := SQLConnectionPoolConfig{MaxOpenConns: 10, MaxIdleConns: 5, ConnMaxLifetime: 30 * time.Minute}
+	serialized, err := json.Marshal(config)
+	if err != nil {
+		t.Fatalf("failed to serialize: %v", err)
+	}
+	if expected := `{"MaxOpenConns":10,"MaxIdleConns":5,"ConnMaxLifetime":"30m0s"}`; string(serialized) != expected {
+		t.Fatalf("expected %s, got %s", expected, serialized)
+	}
+	tests := map[string]struct {
+		serialized string
+		expected   SQLConnectionPoolConfig
+	}{
+		"round trip":  {string(serialized), config},
+		"nanoseconds": {`{"ConnMaxLifetime":60000000000}`, SQLConnectionPoolConfig{ConnMaxLifetime: time.Minute}},
+		"unset":       {`{"MaxOpenConns":3}`, SQLConnectionPoolConfig{MaxOpenConns: 3}},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			var actual SQLConnectionPoolConfig
+			if err := json.Unmarshal([]byte(test.serialized), &actual); err != nil {
+				t.Fatalf("failed to deserialize: %v", err)
+			}
+			if actual != test.expected {
+				t.Fatalf("expected %+v, got %+v", test.expected, actual)
+			}
+		})
+	}
+	var invalid SQLConnectionPoolConfig
+	if err := json.Unmarshal([]byte(`{"ConnMaxLifetime":"soon"}`), &invalid); err == nil {
+		t.Fatalf("expected an invalid duration to fail")
+	}
+}
@@ -8,11 +8,16 @@
 package provider
 
 import (
+	"context"
 	"fmt"
 	"reflect"
 	"testing"
+	"time"
 
+	"github.com/featureform/config"
 	"github.com/featureform/filestore"
+	"github.com/featureform/helpers/secrets"
+	"github.com/featureform/logging"
 	pl "github.com/featureform/provider/location"
 	pc "github.com/featureform/provider/provider_config"
 	pt "github.com/featureform/provider/provider_type"
@@ -38,6 +43,54 @@ func TestFactory(t *testing.T) {
 
 }
 
+type mockSecretFetcher struct{}
+
+func (mockSecretFetcher) Fetch(ctx context.Context, name string) (string, error) {
+	return "resolved-" + name, nil
+}
+
+func TestFactorySecretReferences(t *testing.T) {
+	secretResolver = secrets.NewResolver(mockSecretFetcher{}, time.Minute)
+	defer func() { secretResolver = nil }()
+
+	mockType := pt.Type("mock with secrets")
+	var got pc.SerializedConfig
+	factory := func(c pc.SerializedConfig) (Provider, error) {
+		got = c
+		return nil, nil
+	}
+	if err := RegisterFactory(mockType, factory); err != nil {
+		t.Fatalf("Failed to register factory: %s", err)
+	}
+	if _, err := Get(mockType, pc.SerializedConfig(`{"Password": "{{secret:prod/password}}"}`)); err != nil {
+		t.Fatalf("Failed to get provider: %s", err)
+	}
+	if expected := `{"Password":"resolved-prod/password"}`; string(got) != expected {
+		t.Fatalf("Expected factory to get %s, got %s", expected, got)
+	}
+}
+
+func TestSecretResolverRetriesFailures(t *testing.T) {
+	t.Setenv(config.EnvSecretManager, "unknown")
+	if _, err := getSecretResolver(context.Background()); err == nil {
+		t.Fatalf("Expected an unknown secret manager to fail")
+	}
+	if secretResolver != nil {
+		t.Fatalf("Expected a failed secret resolver not to be kept")
+	}
+	t.Setenv(config.EnvSecretManager, string(secrets.Vault))
+	t.Setenv(config.EnvVaultAddr, "http://localhost:8200")
+	t.Setenv(config.EnvVaultToken, "token")
+	defer func() { secretResolver = nil }()
+	resolver, err := getSecretResolver(context.Background())
+	if err != nil {
+		t.Fatalf("Expected the secret resolver to be set up on retry: %v", err)
+	}
+	if resolver == nil || secretResolver != resolver {
+		t.Fatalf("Expected the secret resolver to be kept once it's set up")
+	}
+}
+
 func TestFactoryExists(t *testing.T) {
 	mockType := pt.Type("already exists")
 	if err := RegisterFactory(mockType, mockFactory); err != nil {
@@ -79,6 +132,49 @@ func TestBaseProvider(t *testing.T) {
 	}
 }
 
+func TestProviderCapabilities(t *testing.T) {
+	tests := map[string]struct {
+		provider Provider
+		expected Capabilities
+	}{
+		"Base":   {&BaseProvider{}, Capabilities{}},
+		"Memory": {NewMemoryOfflineStore(), Capabilities{}},
+		"SQL": {
+			&sqlOfflineStore{},
+			Capabilities{FilteredMaterialization: true, IncrementalTransformation: true, SourceProfiling: true},
+		},
+		"ClickHouse": {
+			&clickHouseOfflineStore{},
+			Capabilities{SourceProfiling: true},
+		},
+		"SparkEMRWithCatalog": {
+			&SparkOfflineStore{Executor: &EMRExecutor{}, GlueConfig: &pc.GlueConfig{}, Logger: logging.NewTestLogger(t)},
+			Capabilities{
+				Catalog:                   true,
+				DirectCopyDynamo:          true,
+				DirectCopyRedis:           true,
+				DirectCopyCosmos:          true,
+				FilteredMaterialization:   true,
+				IncrementalTransformation: true,
+				ResumableTransformation:   true,
+				SourceProfiling:           true,
+			},
+		},
+		"Redis": {&redisOnlineStore{}, Capabilities{VectorSearch: true}},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			actual, err := test.provider.Capabilities()
+			if err != nil {
+				t.Fatalf("Failed to get capabilities: %s", err)
+			}
+			if !reflect.DeepEqual(test.expected, actual) {
+				t.Fatalf("Expected %+v, got %+v", test.expected, actual)
+			}
+		})
+	}
+}
+
 func TestLocationInterface(t *testing.T) {
 	tests := []struct {
 		name           string
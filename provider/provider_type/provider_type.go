@@ -16,6 +16,7 @@ func (t Type) String() string {
 const (
 	// Online
 	LocalOnline     Type = "LOCAL_ONLINE"
+	MemoryOnline    Type = "MEMORY_ONLINE"
 	RedisOnline     Type = "REDIS_ONLINE"
 	CassandraOnline Type = "CASSANDRA_ONLINE"
 	FirestoreOnline Type = "FIRESTORE_ONLINE"
@@ -23,34 +24,41 @@ const (
 	BlobOnline      Type = "BLOB_ONLINE"
 	MongoDBOnline   Type = "MONGODB_ONLINE"
 	PineconeOnline  Type = "PINECONE_ONLINE"
+	BigtableOnline  Type = "BIGTABLE_ONLINE"
 
 	// Offline
-	MemoryOffline     Type = "MEMORY_OFFLINE"
-	MySqlOffline      Type = "MYSQL_OFFLINE"
-	PostgresOffline   Type = "POSTGRES_OFFLINE"
-	ClickHouseOffline Type = "CLICKHOUSE_OFFLINE"
-	SnowflakeOffline  Type = "SNOWFLAKE_OFFLINE"
-	RedshiftOffline   Type = "REDSHIFT_OFFLINE"
-	SparkOffline      Type = "SPARK_OFFLINE"
-	BigQueryOffline   Type = "BIGQUERY_OFFLINE"
-	K8sOffline        Type = "K8S_OFFLINE"
-	S3                Type = "S3"
-	GCS               Type = "GCS"
-	HDFS              Type = "HDFS"
-	AZURE             Type = "AZURE"
-	UNIT_TEST         Type = "UNIT_TEST"
+	MemoryOffline        Type = "MEMORY_OFFLINE"
+	MySqlOffline         Type = "MYSQL_OFFLINE"
+	PostgresOffline      Type = "POSTGRES_OFFLINE"
+	ClickHouseOffline    Type = "CLICKHOUSE_OFFLINE"
+	SnowflakeOffline     Type = "SNOWFLAKE_OFFLINE"
+	RedshiftOffline      Type = "REDSHIFT_OFFLINE"
+	SparkOffline         Type = "SPARK_OFFLINE"
+	BigQueryOffline      Type = "BIGQUERY_OFFLINE"
+	K8sOffline           Type = "K8S_OFFLINE"
+	DatabricksSQLOffline Type = "DATABRICKS_SQL_OFFLINE"
+	S3                   Type = "S3"
+	GCS                  Type = "GCS"
+	HDFS                 Type = "HDFS"
+	AZURE                Type = "AZURE"
+	UNIT_TEST            Type = "UNIT_TEST"
+
+	// Streaming
+	Kafka Type = "KAFKA"
 
 	NONE Type = "NONE"
 )
 
 var AllProviderTypes = []Type{
 	LocalOnline,
+	MemoryOnline,
 	RedisOnline,
 	CassandraOnline,
 	FirestoreOnline,
 	DynamoDBOnline,
 	BlobOnline,
 	MongoDBOnline,
+	BigtableOnline,
 	MemoryOffline,
 	MySqlOffline,
 	PineconeOnline,
@@ -61,21 +69,27 @@ var AllProviderTypes = []Type{
 	SparkOffline,
 	BigQueryOffline,
 	K8sOffline,
+	DatabricksSQLOffline,
 	S3,
 	GCS,
 	HDFS,
 	AZURE,
+	Kafka,
 	UNIT_TEST,
 }
 
 func GetOnlineTypes() []Type {
-	return []Type{LocalOnline, RedisOnline, CassandraOnline, FirestoreOnline, DynamoDBOnline, BlobOnline, MongoDBOnline, PineconeOnline}
+	return []Type{LocalOnline, MemoryOnline, RedisOnline, CassandraOnline, FirestoreOnline, DynamoDBOnline, BlobOnline, MongoDBOnline, PineconeOnline, BigtableOnline}
 }
 
 func GetOfflineTypes() []Type {
-	return []Type{MemoryOffline, MySqlOffline, PostgresOffline, ClickHouseOffline, SnowflakeOffline, RedshiftOffline, SparkOffline, BigQueryOffline, K8sOffline}
+	return []Type{MemoryOffline, MySqlOffline, PostgresOffline, ClickHouseOffline, SnowflakeOffline, RedshiftOffline, SparkOffline, BigQueryOffline, K8sOffline, DatabricksSQLOffline}
 }
 
 func GetFileTypes() []Type {
 	return []Type{S3, GCS, HDFS, AZURE}
 }
+
+func GetStreamingTypes() []Type {
+	return []Type{Kafka}
+}
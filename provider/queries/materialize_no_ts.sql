@@ -13,7 +13,7 @@ WITH ordered_rows AS (
                     SELECT NULL
                 )
         ) AS row_number
-    FROM source_0
+    FROM %s
 ),
 max_row_per_entity AS (
     SELECT entity,
@@ -9,11 +9,14 @@ package provider
 
 import (
 	"context"
+	"crypto/tls"
+	"crypto/x509"
 	"encoding/base64"
 	"encoding/json"
 	"fmt"
 	pl "github.com/featureform/provider/location"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/featureform/fferr"
@@ -33,9 +36,17 @@ func (t redisTableKey) String() string {
 	return string(marshalled)
 }
 
+// defaultRedisPipelineSize is the number of writes sent to Redis in a single
+// pipeline when PipelineSize isn't set in the provider config.
+const defaultRedisPipelineSize = 1000
+
 type redisOnlineStore struct {
-	client rueidis.Client
-	prefix string
+	client       rueidis.Client
+	prefix       string
+	pipelineSize int
+	directCopy   bool
+	tls          bool
+	username     string
 	BaseProvider
 }
 
@@ -51,9 +62,18 @@ func redisOnlineStoreFactory(serialized pc.SerializedConfig) (Provider, error) {
 }
 
 func NewRedisOnlineStore(options *pc.RedisConfig) (*redisOnlineStore, error) {
+	if err := options.Validate(); err != nil {
+		return nil, err
+	}
+	tlsConfig, err := redisTLSConfig(options)
+	if err != nil {
+		return nil, err
+	}
 	redisOptions := rueidis.ClientOption{
-		InitAddress: []string{options.Addr},
+		InitAddress: options.Addrs(),
+		Username:    options.Username,
 		Password:    options.Password,
+		TLSConfig:   tlsConfig,
 		SelectDB:    options.DB,
 		/*
 			The rueidis client opts-in to server-assisted client-side caching by default.
@@ -69,20 +89,63 @@ func NewRedisOnlineStore(options *pc.RedisConfig) (*redisOnlineStore, error) {
 		*/
 		DisableCache: true,
 	}
+	switch options.GetTopology() {
+	case pc.RedisCluster:
+		// Spread the initial slot discovery across the seed nodes. rueidis
+		// routes each command to the node that owns its key's slot.
+		redisOptions.ShuffleInit = true
+	case pc.RedisSentinel:
+		redisOptions.Sentinel = rueidis.SentinelOption{
+			MasterSet: options.SentinelMasterName,
+			TLSConfig: tlsConfig,
+		}
+	}
 	redisClient, err := rueidis.NewClient(redisOptions)
 	if err != nil {
 		wrapped := fferr.NewConnectionError(pt.RedisOnline.String(), err)
 		wrapped.AddDetail("action", "client initialization")
-		wrapped.AddDetail("addr", options.Addr)
+		wrapped.AddDetail("topology", string(options.GetTopology()))
+		wrapped.AddDetail("addrs", strings.Join(options.Addrs(), ","))
 		return nil, wrapped
 	}
-	return &redisOnlineStore{redisClient, options.Prefix, BaseProvider{
+	pipelineSize := options.PipelineSize
+	if pipelineSize <= 0 {
+		pipelineSize = defaultRedisPipelineSize
+	}
+	return &redisOnlineStore{redisClient, options.Prefix, pipelineSize, options.DirectCopy, options.TLS, options.Username, BaseProvider{
 		ProviderType:   pt.RedisOnline,
 		ProviderConfig: options.Serialized(),
 	},
 	}, nil
 }
 
+// redisTLSConfig returns the TLS config for the client, or nil if TLS isn't
+// enabled.
+func redisTLSConfig(options *pc.RedisConfig) (*tls.Config, error) {
+	if !options.TLS {
+		return nil, nil
+	}
+	tlsConfig := &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		InsecureSkipVerify: options.TLSInsecureSkipVerify,
+	}
+	if options.TLSCACert != "" {
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM([]byte(options.TLSCACert)) {
+			return nil, fferr.NewInvalidArgumentErrorf("Redis TLS CA certificate isn't valid PEM")
+		}
+		tlsConfig.RootCAs = pool
+	}
+	if options.TLSCert != "" {
+		cert, err := tls.X509KeyPair([]byte(options.TLSCert), []byte(options.TLSKey))
+		if err != nil {
+			return nil, fferr.NewInvalidArgumentErrorf("Redis TLS client certificate: %v", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+	return tlsConfig, nil
+}
+
 func (store *redisOnlineStore) AsOnlineStore() (OnlineStore, error) {
 	return store, nil
 }
@@ -111,9 +174,10 @@ func (store *redisOnlineStore) GetTable(feature, variant string) (OnlineStoreTab
 	// tables hash.
 	if _, isScalarString := types.ScalarTypes[types.ScalarType(vType)]; isScalarString {
 		return &redisOnlineTable{
-			client:    store.client,
-			key:       key,
-			valueType: types.ScalarType(vType),
+			client:       store.client,
+			key:          key,
+			valueType:    types.ScalarType(vType),
+			pipelineSize: store.pipelineSize,
 		}, nil
 	}
 	valueTypeJSON := &types.ValueTypeJSONWrapper{}
@@ -136,9 +200,10 @@ func (store *redisOnlineStore) GetTable(feature, variant string) (OnlineStoreTab
 		}
 	case types.ScalarType:
 		table = &redisOnlineTable{
-			client:    store.client,
-			key:       key,
-			valueType: valueTypeJSON.ValueType,
+			client:       store.client,
+			key:          key,
+			valueType:    valueTypeJSON.ValueType,
+			pipelineSize: store.pipelineSize,
 		}
 	default:
 		return nil, fferr.NewInvalidArgumentError(fmt.Errorf("unknown value type: %T", valueTypeJSON.ValueType))
@@ -189,9 +254,10 @@ func (store *redisOnlineStore) CreateTable(feature, variant string, valueType ty
 		}
 	case types.ScalarType:
 		table = &redisOnlineTable{
-			client:    store.client,
-			key:       key,
-			valueType: valueType,
+			client:       store.client,
+			key:          key,
+			valueType:    valueType,
+			pipelineSize: store.pipelineSize,
 		}
 	default:
 		return nil, fferr.NewInvalidArgumentError(fmt.Errorf("unknown value type: %T", valueType))
@@ -237,6 +303,10 @@ func (store *redisOnlineStore) CreateIndex(feature, variant string, vectorType t
 	return table, nil
 }
 
+func (store *redisOnlineStore) Capabilities() (Capabilities, error) {
+	return Capabilities{VectorSearch: true}, nil
+}
+
 // TODO: Implement index deletion
 func (store *redisOnlineStore) DeleteIndex(feature, variant string) error {
 	return nil
@@ -262,12 +332,168 @@ func (store *redisOnlineStore) createIndexCmd(key redisIndexKey, vectorType type
 }
 
 type redisOnlineTable struct {
-	client    rueidis.Client
-	key       redisTableKey
-	valueType types.ValueType
+	client       rueidis.Client
+	key          redisTableKey
+	valueType    types.ValueType
+	pipelineSize int
+	ttl          time.Duration
+}
+
+// WithTTL expires each entity's value ttl after it's set. Values share a hash
+// per table, so this relies on per-field expiry (HPEXPIRE), which requires
+// Redis 7.4 or later.
+func (table redisOnlineTable) WithTTL(ttl time.Duration) (OnlineStoreTable, error) {
+	if ttl < time.Millisecond {
+		return nil, fferr.NewInvalidArgumentErrorf("TTL must be at least one millisecond, got %s", ttl)
+	}
+	table.ttl = ttl
+	return &table, nil
 }
 
 func (table redisOnlineTable) Set(entity string, value interface{}) error {
+	serialized, err := table.serialize(value)
+	if err != nil {
+		return err
+	}
+	for _, res := range table.client.DoMulti(context.TODO(), table.setCmds(entity, serialized)...) {
+		if res.Error() != nil {
+			wrapped := fferr.NewResourceExecutionError(pt.RedisOnline.String(), table.key.Feature, table.key.Variant, fferr.ENTITY, res.Error())
+			wrapped.AddDetail("entity", entity)
+			return wrapped
+		}
+	}
+	return nil
+}
+
+// redisSetIfNewerScript writes a value and its timestamp unless the stored
+// timestamp is later. Timestamps are kept as microseconds since the epoch,
+// which Lua's numbers represent exactly.
+var redisSetIfNewerScript = rueidis.NewLuaScript(`
+local prev = redis.call("HGET", KEYS[1], ARGV[3])
+if prev and tonumber(prev) > tonumber(ARGV[4]) then
+	return 0
+end
+redis.call("HSET", KEYS[1], ARGV[1], ARGV[2], ARGV[3], ARGV[4])
+if ARGV[5] ~= "0" then
+	redis.call("HPEXPIRE", KEYS[1], ARGV[5], "FIELDS", 2, ARGV[1], ARGV[3])
+end
+return 1
+`)
+
+// redisTimestampFieldPrefix prefixes the field that holds the timestamp of an
+// entity's value. Timestamps share the table's hash so the script touches one
+// slot in a cluster.
+const redisTimestampFieldPrefix = "\x00ts:"
+
+func (table redisOnlineTable) SetIfNewer(entity string, value interface{}, ts time.Time) (bool, error) {
+	serialized, err := table.serialize(value)
+	if err != nil {
+		return false, err
+	}
+	args := []string{
+		entity,
+		serialized,
+		redisTimestampFieldPrefix + entity,
+		strconv.FormatInt(ts.UnixMicro(), 10),
+		strconv.FormatInt(table.ttl.Milliseconds(), 10),
+	}
+	written, err := redisSetIfNewerScript.Exec(context.TODO(), table.client, []string{table.key.String()}, args).AsInt64()
+	if err != nil {
+		wrapped := fferr.NewResourceExecutionError(pt.RedisOnline.String(), table.key.Feature, table.key.Variant, fferr.ENTITY, err)
+		wrapped.AddDetail("entity", entity)
+		return false, wrapped
+	}
+	return written == 1, nil
+}
+
+// BatchSet writes all items in a single Redis pipeline. The copy runner calls it with
+// at most MaxBatchSize items, so each call results in one round trip to Redis.
+func (table redisOnlineTable) BatchSet(items []SetItem) error {
+	if len(items) == 0 {
+		return nil
+	}
+	cmdsPerItem := 1
+	if table.ttl > 0 {
+		cmdsPerItem = 2
+	}
+	cmds := make(rueidis.Commands, 0, len(items)*cmdsPerItem)
+	for _, item := range items {
+		serialized, err := table.serialize(item.Value)
+		if err != nil {
+			return err
+		}
+		cmds = append(cmds, table.setCmds(item.Entity, serialized)...)
+	}
+	succeeded := 0
+	var firstErr error
+	var failedEntity string
+	results := table.client.DoMulti(context.TODO(), cmds...)
+	for i := range items {
+		var itemErr error
+		for _, res := range results[i*cmdsPerItem : (i+1)*cmdsPerItem] {
+			if err := res.Error(); err != nil && itemErr == nil {
+				itemErr = err
+			}
+		}
+		if itemErr != nil {
+			if firstErr == nil {
+				firstErr = itemErr
+				failedEntity = items[i].Entity
+			}
+			continue
+		}
+		succeeded++
+	}
+	if firstErr != nil {
+		wrapped := fferr.NewConnectionError(pt.RedisOnline.String(), firstErr)
+		wrapped.AddDetail("action", "pipelined write")
+		wrapped.AddDetail("feature", table.key.Feature)
+		wrapped.AddDetail("variant", table.key.Variant)
+		wrapped.AddDetail("entity", failedEntity)
+		wrapped.AddDetail("succeeded_writes", strconv.Itoa(succeeded))
+		wrapped.AddDetail("total_writes", strconv.Itoa(len(items)))
+		return wrapped
+	}
+	return nil
+}
+
+func (table redisOnlineTable) MaxBatchSize() (int, error) {
+	if table.pipelineSize <= 0 {
+		return defaultRedisPipelineSize, nil
+	}
+	return table.pipelineSize, nil
+}
+
+// setCmds returns the commands that write an entity's value, followed by its
+// expiry if the table has a TTL.
+func (table redisOnlineTable) setCmds(entity, value string) rueidis.Commands {
+	cmds := rueidis.Commands{
+		table.client.B().
+			Hset().
+			Key(table.key.String()).
+			FieldValue().
+			FieldValue(entity, value).
+			Build(),
+	}
+	if table.ttl > 0 {
+		// rueidis doesn't have a builder for the hash field expiry commands yet.
+		cmds = append(cmds, table.client.B().
+			Arbitrary("HPEXPIRE").
+			Keys(table.key.String()).
+			Args(strconv.FormatInt(table.ttl.Milliseconds(), 10), "FIELDS", "1", entity).
+			Build())
+	}
+	return cmds
+}
+
+func (table redisOnlineTable) serialize(value interface{}) (string, error) {
+	if table.valueType == types.JSON {
+		return serializeJSON(value)
+	}
+	return serializeRedisValue(value)
+}
+
+func serializeRedisValue(value interface{}) (string, error) {
 	switch v := value.(type) {
 	case nil:
 		value = "nil"
@@ -297,21 +523,9 @@ func (table redisOnlineTable) Set(entity string, value interface{}) error {
 	case []float32:
 		value = rueidis.VectorString32(v)
 	default:
-		return fferr.NewDataTypeNotFoundErrorf(value, "unsupported data type")
+		return "", fferr.NewDataTypeNotFoundErrorf(value, "unsupported data type")
 	}
-	cmd := table.client.B().
-		Hset().
-		Key(table.key.String()).
-		FieldValue().
-		FieldValue(entity, value.(string)).
-		Build()
-	res := table.client.Do(context.TODO(), cmd)
-	if res.Error() != nil {
-		wrapped := fferr.NewResourceExecutionError(pt.RedisOnline.String(), table.key.Feature, table.key.Variant, fferr.ENTITY, res.Error())
-		wrapped.AddDetail("entity", entity)
-		return wrapped
-	}
-	return nil
+	return value.(string), nil
 }
 
 func (table redisOnlineTable) Get(entity string) (interface{}, error) {
@@ -324,17 +538,57 @@ func (table redisOnlineTable) Get(entity string) (interface{}, error) {
 	if resp.Error() != nil {
 		return nil, fferr.NewEntityNotFoundError(table.key.Feature, table.key.Variant, entity, resp.Error())
 	}
-	var err error
-	var result interface{}
 	val, err := resp.ToString()
 	if err != nil {
 		return nil, fferr.NewResourceExecutionError(pt.RedisOnline.String(), table.key.Feature, table.key.Variant, fferr.ENTITY, err)
 	}
+	return table.parseValue(entity, val)
+}
+
+// BatchGet fetches all entities with a single HMGET rather than issuing one HGET per entity.
+// A table is a single hash, so the HMGET only touches one slot even in a cluster.
+func (table redisOnlineTable) BatchGet(entities []string) ([]interface{}, error) {
+	if len(entities) == 0 {
+		return []interface{}{}, nil
+	}
+	cmd := table.client.B().
+		Hmget().
+		Key(table.key.String()).
+		Field(entities...).
+		Build()
+	resp, err := table.client.Do(context.TODO(), cmd).ToArray()
+	if err != nil {
+		return nil, fferr.NewResourceExecutionError(pt.RedisOnline.String(), table.key.Feature, table.key.Variant, fferr.ENTITY, err)
+	}
+	if len(resp) != len(entities) {
+		return nil, fferr.NewInternalErrorf("expected %d values from HMGET, got %d", len(entities), len(resp))
+	}
+	results := make([]interface{}, len(entities))
+	for i, msg := range resp {
+		if msg.IsNil() {
+			return nil, fferr.NewEntityNotFoundError(table.key.Feature, table.key.Variant, entities[i], nil)
+		}
+		val, err := msg.ToString()
+		if err != nil {
+			return nil, fferr.NewResourceExecutionError(pt.RedisOnline.String(), table.key.Feature, table.key.Variant, fferr.ENTITY, err)
+		}
+		result, err := table.parseValue(entities[i], val)
+		if err != nil {
+			return nil, err
+		}
+		results[i] = result
+	}
+	return results, nil
+}
+
+func (table redisOnlineTable) parseValue(entity, val string) (interface{}, error) {
 	if table.valueType.IsVector() {
 		return rueidis.ToVector32(val), nil
 	}
+	var err error
+	var result interface{}
 	switch table.valueType {
-	case types.NilType, types.String:
+	case types.NilType, types.String, types.JSON:
 		result, err = val, nil
 	case types.Int:
 		result, err = strconv.Atoi(val)
@@ -360,7 +614,7 @@ func (table redisOnlineTable) Get(entity string) (interface{}, error) {
 		result, err = val, nil
 	}
 	if err != nil {
-		wrapped := fferr.NewInternalError(fmt.Errorf("could not cast value: %v to %s: %w", resp, table.valueType, err))
+		wrapped := fferr.NewInternalError(fmt.Errorf("could not cast value: %v to %s: %w", val, table.valueType, err))
 		wrapped.AddDetail("entity", entity)
 		return nil, wrapped
 	}
@@ -451,6 +705,40 @@ func (table redisOnlineIndex) Get(entity string) (interface{}, error) {
 	return rueidis.ToVector32(val), nil
 }
 
+// BatchGet fetches each entity's vector with its own HGET, sent together with
+// DoMulti. Each entity is stored under its own key, so in a cluster the keys
+// can be in different slots; rueidis groups the commands by the node that owns
+// each slot rather than sending a cross-slot command.
+func (table redisOnlineIndex) BatchGet(entities []string) ([]interface{}, error) {
+	if len(entities) == 0 {
+		return []interface{}{}, nil
+	}
+	cmds := make(rueidis.Commands, len(entities))
+	for i, entity := range entities {
+		serializedKey, err := table.key.serialize(entity)
+		if err != nil {
+			return nil, err
+		}
+		cmds[i] = table.client.B().
+			Hget().
+			Key(string(serializedKey)).
+			Field(table.key.getVectorField()).
+			Build()
+	}
+	results := make([]interface{}, len(entities))
+	for i, resp := range table.client.DoMulti(context.TODO(), cmds...) {
+		if resp.Error() != nil {
+			return nil, fferr.NewEntityNotFoundError(table.key.Feature, table.key.Variant, entities[i], resp.Error())
+		}
+		val, err := resp.ToString()
+		if err != nil {
+			return nil, fferr.NewResourceExecutionError(pt.RedisOnline.String(), table.key.Feature, table.key.Variant, fferr.ENTITY, err)
+		}
+		results[i] = rueidis.ToVector32(val)
+	}
+	return results, nil
+}
+
 func (table redisOnlineIndex) Nearest(feature, variant string, vector []float32, k int32) ([]string, error) {
 	cmd, err := table.createNearestCmd(vector, k)
 	if err != nil {
@@ -18,6 +18,7 @@ import (
 	"github.com/alicebob/miniredis"
 	"github.com/joho/godotenv"
 
+	"github.com/featureform/fferr"
 	pc "github.com/featureform/provider/provider_config"
 	pt "github.com/featureform/provider/provider_type"
 	"github.com/featureform/provider/types"
@@ -80,6 +81,9 @@ func TestOnlineStoreRedisInsecure(t *testing.T) {
 	test := OnlineStoreTest{
 		t:     t,
 		store: store,
+		// Requires Redis 7.4+ for hash field expiry.
+		testTTL:        true,
+		testSetIfNewer: true,
 	}
 	test.Run()
 }
@@ -237,6 +241,60 @@ func Test_redisOnlineTable_Get(t *testing.T) {
 	}
 }
 
+func Test_redisOnlineTable_BatchSet(t *testing.T) {
+	miniRedis := mockRedis()
+	defer miniRedis.Close()
+	redisClient, err := instantiateMockRedisClient(miniRedis.Addr())
+	if err != nil {
+		t.Fatalf("Failed to create redis client: %v", err)
+	}
+	table := redisOnlineTable{
+		client:       redisClient,
+		key:          redisTableKey{Prefix: "prefix", Feature: "feature", Variant: "variant"},
+		valueType:    types.Int,
+		pipelineSize: 10,
+	}
+	maxBatch, err := table.MaxBatchSize()
+	if err != nil {
+		t.Fatalf("Failed to get max batch size: %v", err)
+	}
+	if maxBatch != 10 {
+		t.Fatalf("Expected max batch size 10, got %d", maxBatch)
+	}
+	items := make([]SetItem, maxBatch)
+	entities := make([]string, maxBatch)
+	for i := range items {
+		entities[i] = fmt.Sprintf("entity%d", i)
+		items[i] = SetItem{Entity: entities[i], Value: i}
+	}
+	if err := table.BatchSet(items); err != nil {
+		t.Fatalf("Failed to batch set: %v", err)
+	}
+	got, err := table.BatchGet(entities)
+	if err != nil {
+		t.Fatalf("Failed to batch get: %v", err)
+	}
+	for i, val := range got {
+		if val != i {
+			t.Fatalf("Expected %d for %s, got %v", i, entities[i], val)
+		}
+	}
+	if _, err := table.BatchGet([]string{"entity0", "missing"}); err == nil {
+		t.Fatalf("Expected error when batch getting a missing entity")
+	}
+	if err := table.BatchSet([]SetItem{{Entity: "bad", Value: struct{}{}}}); err == nil {
+		t.Fatalf("Expected error when batch setting an unsupported type")
+	}
+	miniRedis.Close()
+	err = table.BatchSet(items)
+	if err == nil {
+		t.Fatalf("Expected error when redis is unavailable")
+	}
+	if _, ok := err.(*fferr.ConnectionError); !ok {
+		t.Fatalf("Expected a ConnectionError, got %T: %v", err, err)
+	}
+}
+
 func TestGetTableBackwardsCompatibility(t *testing.T) {
 	miniRedis := mockRedis()
 	redisClient, err := instantiateMockRedisClient(miniRedis.Addr())
@@ -252,6 +310,10 @@ func TestGetTableBackwardsCompatibility(t *testing.T) {
 	redisOnlineStore := redisOnlineStore{
 		redisClient,
 		prefix,
+		defaultRedisPipelineSize,
+		false,
+		false,
+		"",
 		BaseProvider{ProviderType: pt.RedisOnline, ProviderConfig: redisConfig.Serialized()},
 	}
 	if err != nil {
@@ -309,6 +371,10 @@ func TestCreateGetTable(t *testing.T) {
 	redisOnlineStore := redisOnlineStore{
 		redisClient,
 		prefix,
+		defaultRedisPipelineSize,
+		false,
+		false,
+		"",
 		BaseProvider{ProviderType: pt.RedisOnline, ProviderConfig: redisConfig.Serialized()},
 	}
 	if err != nil {
@@ -380,3 +446,46 @@ func instantiateMockRedisClient(addr string) (rueidis.Client, error) {
 		},
 	)
 }
+
+func TestRedisDirectCopyOptIn(t *testing.T) {
+	tests := []struct {
+		name     string
+		store    *redisOnlineStore
+		expected MaterializationOptionType
+	}{
+		{"Default", &redisOnlineStore{}, NullMaterializationOptionType},
+		{"OptedIn", &redisOnlineStore{directCopy: true}, DirectCopyRedis},
+		{"TLS", &redisOnlineStore{directCopy: true, tls: true}, NullMaterializationOptionType},
+		{"Username", &redisOnlineStore{directCopy: true, username: "featureform"}, NullMaterializationOptionType},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if matOpt := DirectCopyOptionType(tt.store); matOpt != tt.expected {
+				t.Fatalf("Expected %q, got %q", tt.expected, matOpt)
+			}
+		})
+	}
+}
+
+func TestRedisTLSConfig(t *testing.T) {
+	tlsConfig, err := redisTLSConfig(&pc.RedisConfig{Addr: "0.0.0.0:6379"})
+	if err != nil {
+		t.Fatalf("Failed to build TLS config: %v", err)
+	}
+	if tlsConfig != nil {
+		t.Fatalf("Expected no TLS config when TLS is disabled")
+	}
+	tlsConfig, err = redisTLSConfig(&pc.RedisConfig{Addr: "0.0.0.0:6379", TLS: true, TLSInsecureSkipVerify: true})
+	if err != nil {
+		t.Fatalf("Failed to build TLS config: %v", err)
+	}
+	if tlsConfig == nil || !tlsConfig.InsecureSkipVerify {
+		t.Fatalf("Expected TLS config to skip verification, got %v", tlsConfig)
+	}
+	if _, err := redisTLSConfig(&pc.RedisConfig{Addr: "0.0.0.0:6379", TLS: true, TLSCACert: "not a cert"}); err == nil {
+		t.Fatalf("Expected an invalid CA certificate to fail")
+	}
+	if _, err := redisTLSConfig(&pc.RedisConfig{Addr: "0.0.0.0:6379", TLS: true, TLSCert: "not a cert", TLSKey: "not a key"}); err == nil {
+		t.Fatalf("Expected an invalid client certificate to fail")
+	}
+}
@@ -8,12 +8,15 @@
 package provider
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"strings"
 	"time"
 
 	"github.com/featureform/fferr"
+	"github.com/featureform/filestore"
+	pl "github.com/featureform/provider/location"
 	pc "github.com/featureform/provider/provider_config"
 	pt "github.com/featureform/provider/provider_type"
 	"github.com/featureform/provider/types"
@@ -45,14 +48,15 @@ func redshiftOfflineStoreFactory(config pc.SerializedConfig) (Provider, error) {
 		sslMode = "disable"
 	}
 
-	queries := redshiftSQLQueries{}
+	queries := redshiftSQLQueries{iamRole: sc.IAMRole}
 	queries.setVariableBinding(PostgresBindingStyle)
 	sgConfig := SQLOfflineStoreConfig{
-		Config:        config,
-		ConnectionURL: fmt.Sprintf("sslmode=%s user=%v password=%s host=%v port=%v dbname=%v", sslMode, sc.Username, sc.Password, sc.Host, sc.Port, sc.Database),
-		Driver:        "postgres",
-		ProviderType:  pt.RedshiftOffline,
-		QueryImpl:     &queries,
+		Config:         config,
+		ConnectionURL:  fmt.Sprintf("sslmode=%s user=%v password=%s host=%v port=%v dbname=%v", sslMode, sc.Username, sc.Password, sc.Host, sc.Port, sc.Database),
+		Driver:         "postgres",
+		ProviderType:   pt.RedshiftOffline,
+		QueryImpl:      &queries,
+		ConnectionPool: sc.ConnectionPool,
 		ConnectionStringBuilder: func(database, schema string) (string, error) {
 			redshiftDb := database
 			if redshiftDb == "" {
@@ -75,6 +79,9 @@ func redshiftOfflineStoreFactory(config pc.SerializedConfig) (Provider, error) {
 
 type redshiftSQLQueries struct {
 	defaultOfflineSQLQueries
+	// iamRole is the role UNLOAD and COPY assume to write training set exports
+	// and read imported sources.
+	iamRole string
 }
 
 func (q redshiftSQLQueries) tableExists() string {
@@ -86,13 +93,24 @@ func (q redshiftSQLQueries) viewExists() string {
 }
 
 func (q redshiftSQLQueries) registerResources(db *sql.DB, tableName string, schema ResourceSchema, timestamp bool) error {
+	if err := schema.checkNoTimestampFormat(pt.RedshiftOffline); err != nil {
+		return err
+	}
+	entity, err := schema.castColumn(schema.Entity, sanitize(schema.Entity), q.determineColumnType)
+	if err != nil {
+		return err
+	}
+	value, err := schema.castColumn(schema.Value, sanitize(schema.Value), q.determineColumnType)
+	if err != nil {
+		return err
+	}
 	var query string
 	if timestamp {
 		query = fmt.Sprintf("CREATE VIEW %s AS SELECT %s as entity, %s as value, %s as ts FROM %s", sanitize(tableName),
-			sanitize(schema.Entity), sanitize(schema.Value), sanitize(schema.TS), sanitize(schema.SourceTable.Location()))
+			entity, value, sanitize(schema.TS), sanitize(schema.SourceTable.Location()))
 	} else {
 		query = fmt.Sprintf("CREATE VIEW %s AS SELECT %s as entity, %s as value, to_timestamp('%s', 'YYYY-DD-MM HH24:MI:SS +0000 UTC')::TIMESTAMPTZ as ts FROM %s", sanitize(tableName),
-			sanitize(schema.Entity), sanitize(schema.Value), time.UnixMilli(0).UTC(), sanitize(schema.SourceTable.Location()))
+			entity, value, time.UnixMilli(0).UTC(), sanitize(schema.SourceTable.Location()))
 	}
 	if _, err := db.Exec(query); err != nil {
 		wrapped := fferr.NewExecutionError(pt.RedshiftOffline.String(), err)
@@ -140,6 +158,28 @@ func (q redshiftSQLQueries) materializationUpdate(db *sql.DB, tableName string,
 	return nil
 }
 
+func (q redshiftSQLQueries) materializationIncrementalUpdate(db *sql.DB, tableName string, sourceName string) error {
+	sanitizedTable := sanitize(tableName)
+	tempTable := sanitize(fmt.Sprintf("tmp_%s", tableName))
+	oldTable := sanitize(fmt.Sprintf("old_%s", tableName))
+	query := fmt.Sprintf(
+		"BEGIN TRANSACTION;"+
+			"CREATE TABLE %s AS (%s);"+
+			"ALTER TABLE %s RENAME TO %s;"+
+			"ALTER TABLE %s RENAME TO %s;"+
+			"DROP TABLE %s;"+
+			"COMMIT;"+
+			"", tempTable, incrementalMaterializationSelect(tableName, sourceName), sanitizedTable, oldTable, tempTable, sanitizedTable, oldTable)
+
+	if _, err := db.Exec(query); err != nil {
+		wrapped := fferr.NewExecutionError(pt.RedshiftOffline.String(), err)
+		wrapped.AddDetail("table_name", tableName)
+		wrapped.AddDetail("source_name", sourceName)
+		return wrapped
+	}
+	return nil
+}
+
 func (q redshiftSQLQueries) materializationDrop(tableName string) string {
 	return fmt.Sprintf("DROP TABLE %s", sanitize(tableName))
 }
@@ -184,6 +224,9 @@ func (q redshiftSQLQueries) trainingSetUpdate(store *sqlOfflineStore, def Traini
 }
 
 func (q redshiftSQLQueries) trainingSetQuery(store *sqlOfflineStore, def TrainingSetDef, tableName string, labelName string, isUpdate bool) error {
+	if err := def.checkSingleLabel(pt.RedshiftOffline); err != nil {
+		return err
+	}
 	columns := make([]string, 0)
 	selectColumns := make([]string, 0)
 	query := ""
@@ -196,21 +239,39 @@ func (q redshiftSQLQueries) trainingSetQuery(store *sqlOfflineStore, def Trainin
 		tableJoinAlias := fmt.Sprintf("t%d", i+1)
 		selectColumns = append(selectColumns, fmt.Sprintf("%s_rnk", tableJoinAlias))
 		columns = append(columns, santizedName)
-		query = fmt.Sprintf("%s LEFT OUTER JOIN (SELECT entity, value AS %s, ts, RANK() OVER (ORDER BY ts DESC) AS %s_rnk FROM %s ORDER BY ts desc) AS %s ON (%s.entity=t0.entity AND %s.ts <= t0.ts)",
-			query, santizedName, tableJoinAlias, santizedName, tableJoinAlias, tableJoinAlias, tableJoinAlias)
-		if i == len(def.Features)-1 {
-			query = fmt.Sprintf("%s )) WHERE rn=1", query)
+		ageFilter := q.maxFeatureAgeFilter(fmt.Sprintf("%s.ts", tableJoinAlias), "t0.ts", def.MaxFeatureAge)
+		query = fmt.Sprintf("%s LEFT OUTER JOIN (SELECT entity, value AS %s, ts, RANK() OVER (ORDER BY ts DESC) AS %s_rnk FROM %s ORDER BY ts desc) AS %s ON (%s.entity=t0.entity AND %s.ts <= t0.ts%s)",
+			query, santizedName, tableJoinAlias, santizedName, tableJoinAlias, tableJoinAlias, tableJoinAlias, ageFilter)
+	}
+	for i, lagFeature := range def.LagFeatures {
+		tableName, err := store.getResourceTableName(ResourceID{lagFeature.FeatureName, lagFeature.FeatureVariant, Feature})
+		if err != nil {
+			return err
 		}
+		lagColumnName := sanitize(lagFeatureColumnName(lagFeature, tableName))
+		tableJoinAlias := fmt.Sprintf("t%d", len(def.Features)+i+1)
+		selectColumns = append(selectColumns, fmt.Sprintf("%s_rnk", tableJoinAlias))
+		columns = append(columns, lagColumnName)
+		lagTS := q.shiftTimestamp(fmt.Sprintf("%s.ts", tableJoinAlias), lagFeature.LagDelta)
+		query = fmt.Sprintf("%s LEFT OUTER JOIN (SELECT entity, value AS %s, ts, RANK() OVER (ORDER BY ts DESC) AS %s_rnk FROM %s ORDER BY ts desc) AS %s ON (%s.entity=t0.entity AND %s <= t0.ts)",
+			query, lagColumnName, tableJoinAlias, sanitize(tableName), tableJoinAlias, tableJoinAlias, lagTS)
+	}
+	if len(columns) > 0 {
+		query = fmt.Sprintf("%s )) WHERE rn=1", query)
 	}
 	columnStr := strings.Join(columns, ", ")
 	selectColumnStr := strings.Join(selectColumns, ", ")
+	splitSelect := ""
+	if def.Split != nil {
+		splitSelect = trainingSetSplitSelect(*def.Split, q.trainingSetSplitBucket("e", "\"time\"", def.Split.Seed))
+	}
 
 	if !isUpdate {
 		fullQuery := fmt.Sprintf(
-			"CREATE TABLE %s AS (SELECT %s, label FROM ("+
+			"CREATE TABLE %s AS (SELECT %s, label%s FROM ("+
 				"SELECT *, row_number() over(PARTITION BY e, label, time ORDER BY \"time\", %s DESC) AS rn FROM ( "+
 				"SELECT t0.entity AS e, t0.value AS label, t0.ts AS time, %s, %s FROM %s AS t0 %s )",
-			sanitize(tableName), columnStr, selectColumnStr, columnStr, selectColumnStr, sanitize(labelName), query)
+			sanitize(tableName), columnStr, splitSelect, selectColumnStr, columnStr, selectColumnStr, sanitize(labelName), query)
 		if _, err := store.db.Exec(fullQuery); err != nil {
 			wrapped := fferr.NewResourceExecutionError(pt.RedshiftOffline.String(), def.ID.Name, def.ID.Variant, fferr.ResourceType(def.ID.Type.String()), err)
 			wrapped.AddDetail("table_name", tableName)
@@ -220,10 +281,10 @@ func (q redshiftSQLQueries) trainingSetQuery(store *sqlOfflineStore, def Trainin
 	} else {
 		tempTable := sanitize(fmt.Sprintf("tmp_%s", tableName))
 		fullQuery := fmt.Sprintf(
-			"CREATE TABLE %s AS (SELECT %s, label FROM ("+
+			"CREATE TABLE %s AS (SELECT %s, label%s FROM ("+
 				"SELECT *, row_number() over(PARTITION BY e, label, time ORDER BY \"time\", %s desc) AS rn FROM ( "+
 				"SELECT t0.entity AS e, t0.value AS label, t0.ts AS time, %s, %s FROM %s AS t0 %s )",
-			tempTable, columnStr, selectColumnStr, columnStr, selectColumnStr, sanitize(labelName), query)
+			tempTable, columnStr, splitSelect, selectColumnStr, columnStr, selectColumnStr, sanitize(labelName), query)
 
 		if err := q.atomicUpdate(store.db, tableName, tempTable, fullQuery); err != nil {
 			return err
@@ -232,6 +293,11 @@ func (q redshiftSQLQueries) trainingSetQuery(store *sqlOfflineStore, def Trainin
 	return nil
 }
 
+func (q redshiftSQLQueries) trainingSetSplitBucket(entity, ts string, seed int64) string {
+	return fmt.Sprintf("MOD(STRTOL(SUBSTRING(MD5(%s::varchar || '|' || %s::varchar || '|' || '%d'), 1, 8), 16), %d)",
+		entity, ts, seed, trainingSetSplitBuckets)
+}
+
 func (q redshiftSQLQueries) castTableItemType(v interface{}, t interface{}) interface{} {
 	if v == nil {
 		return v
@@ -289,3 +355,118 @@ func (q redshiftSQLQueries) transformationUpdate(db *sql.DB, tableName string, q
 	}
 	return nil
 }
+
+// trainingSetExport UNLOADs the training set table to an S3 directory and returns the
+// files Redshift wrote, as recorded in stl_unload_log for the UNLOAD query.
+func (q redshiftSQLQueries) trainingSetExport(db *sql.DB, tableName string, location pl.Location, format filestore.FileType) ([]filestore.Filepath, error) {
+	fileLocation, ok := location.(*pl.FileStoreLocation)
+	if !ok {
+		return nil, fferr.NewInvalidArgumentErrorf("redshift training set export requires a filestore location, got %T", location)
+	}
+	dir := fileLocation.Filepath()
+	if dir.Scheme() != filestore.S3Prefix {
+		return nil, fferr.NewInvalidArgumentErrorf("redshift can only export training sets to S3, got %s", dir.ToURI())
+	}
+	var formatClause string
+	switch format {
+	case filestore.Parquet:
+		formatClause = "FORMAT AS PARQUET"
+	case filestore.CSV:
+		formatClause = "FORMAT AS CSV HEADER"
+	default:
+		return nil, fferr.NewInvalidArgumentErrorf("unsupported training set export format: %s", format)
+	}
+	iamRole, err := redshiftIAMRoleClause(q.iamRole)
+	if err != nil {
+		return nil, err
+	}
+	// UNLOAD appends a slice/part suffix to the prefix, so we give it a file name stem.
+	prefix := fmt.Sprintf("%s/part_", strings.TrimSuffix(dir.ToURI(), "/"))
+	selectQuery := strings.ReplaceAll(fmt.Sprintf("SELECT * FROM %s", sanitize(tableName)), "'", "\\'")
+	query := fmt.Sprintf("UNLOAD ('%s') TO '%s' IAM_ROLE %s %s ALLOWOVERWRITE", selectQuery, prefix, iamRole, formatClause)
+
+	conn, err := db.Conn(context.Background())
+	if err != nil {
+		return nil, fferr.NewConnectionError(pt.RedshiftOffline.String(), err)
+	}
+	defer conn.Close()
+	// pg_last_query_id is scoped to the session, so the lookup must run on the same connection.
+	if _, err := conn.ExecContext(context.Background(), query); err != nil {
+		wrapped := fferr.NewExecutionError(pt.RedshiftOffline.String(), err)
+		wrapped.AddDetail("table_name", tableName)
+		return nil, wrapped
+	}
+	rows, err := conn.QueryContext(context.Background(), "SELECT TRIM(path) FROM stl_unload_log WHERE query = pg_last_query_id() ORDER BY path")
+	if err != nil {
+		wrapped := fferr.NewExecutionError(pt.RedshiftOffline.String(), err)
+		wrapped.AddDetail("table_name", tableName)
+		return nil, wrapped
+	}
+	defer rows.Close()
+	files := make([]filestore.Filepath, 0)
+	for rows.Next() {
+		var path string
+		if err := rows.Scan(&path); err != nil {
+			return nil, fferr.NewExecutionError(pt.RedshiftOffline.String(), err)
+		}
+		fp, err := filestore.NewEmptyFilepath(filestore.S3)
+		if err != nil {
+			return nil, err
+		}
+		if err := fp.ParseFilePath(path); err != nil {
+			return nil, err
+		}
+		files = append(files, fp)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fferr.NewExecutionError(pt.RedshiftOffline.String(), err)
+	}
+	return files, nil
+}
+
+// sourceImport COPYs Parquet files from S3 into the table. Without an IAM role,
+// or for files on another file store, it's unimplemented so the rows are
+// copied by the caller instead.
+func (q redshiftSQLQueries) sourceImport(db *sql.DB, tableName string, files []filestore.Filepath) error {
+	if q.iamRole == "" {
+		return fferr.NewUnimplementedErrorf("redshift source import requires an IAM role in the provider config")
+	}
+	iamRole, err := redshiftIAMRoleClause(q.iamRole)
+	if err != nil {
+		return err
+	}
+	uris := make([]string, len(files))
+	for i, file := range files {
+		switch file.Scheme() {
+		case filestore.S3Prefix, filestore.S3APrefix, filestore.S3NPrefix:
+			uris[i] = fmt.Sprintf("%s%s/%s", filestore.S3Prefix, file.Bucket(), file.Key())
+		default:
+			return fferr.NewUnimplementedErrorf("redshift can only import sources from S3, got %s", file.ToURI())
+		}
+	}
+	for _, uri := range uris {
+		query := fmt.Sprintf("COPY %s FROM '%s' IAM_ROLE %s FORMAT AS PARQUET", sanitize(tableName), uri, iamRole)
+		if _, err := db.Exec(query); err != nil {
+			wrapped := fferr.NewExecutionError(pt.RedshiftOffline.String(), err)
+			wrapped.AddDetail("table_name", tableName)
+			wrapped.AddDetail("file", uri)
+			return wrapped
+		}
+	}
+	return nil
+}
+
+// redshiftIAMRoleClause returns the IAM_ROLE argument for role, which is either
+// "default" or a role ARN.
+func redshiftIAMRoleClause(role string) (string, error) {
+	switch {
+	case role == "":
+		return "", fferr.NewInvalidArgumentErrorf("redshift requires an IAM role in the provider config to access S3")
+	case strings.EqualFold(role, "default"):
+		return "default", nil
+	case !strings.HasPrefix(role, "arn:") || strings.ContainsAny(role, "' "):
+		return "", fferr.NewInvalidArgumentErrorf("redshift IAM role must be a role ARN or default, got %q", role)
+	default:
+		return fmt.Sprintf("'%s'", role), nil
+	}
+}
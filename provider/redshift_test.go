@@ -79,7 +79,6 @@ func TestOfflineStoreRedshift(t *testing.T) {
 		store: store,
 	}
 	test.Run()
-	test.RunSQL()
 }
 
 func createRedshiftDatabase(c pc.RedshiftConfig) error {
@@ -0,0 +1,167 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+//
+// Copyright 2024 FeatureForm Inc.
+//
+
+package provider
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/featureform/fferr"
+	pl "github.com/featureform/provider/location"
+	"github.com/featureform/provider/types"
+)
+
+// defaultSchemaDetectionSampleSize is the number of rows read to infer column
+// types when no sample size is given.
+const defaultSchemaDetectionSampleSize = 1000
+
+var utf8BOM = []byte{0xEF, 0xBB, 0xBF}
+
+// detectedTimestampFormats are the timestamp formats recognized in CSV values.
+var detectedTimestampFormats = []string{
+	time.RFC3339Nano,
+	"2006-01-02 15:04:05",
+	"2006-01-02T15:04:05",
+	"2006-01-02",
+}
+
+// DetectSchema infers the columns of a CSV file in the filestore from its header
+// and the values of its first sampleSize rows. A column whose values don't share
+// a type is a String; ints and floats mixed together are Float64. If sampleSize
+// isn't positive, a default is used.
+func DetectSchema(store FileStore, location pl.FileStoreLocation, sampleSize int) (TableSchema, error) {
+	path := location.Filepath()
+	if !isDelimitedFile(path, location.CSVOptions()) {
+		return TableSchema{}, fferr.NewInvalidArgumentErrorf("schema detection only supports CSV files, got %s", location.Location())
+	}
+	file, err := openDecompressed(store, path)
+	if err != nil {
+		return TableSchema{}, err
+	}
+	defer file.Close()
+	columns, err := detectCSVColumns(file, sampleSize, location.CSVOptions())
+	if err != nil {
+		return TableSchema{}, err
+	}
+	return TableSchema{Columns: columns, SourceTable: location.Location(), CSVOptions: location.CSVOptions()}, nil
+}
+
+func detectCSVColumns(src io.Reader, sampleSize int, opts *pl.CSVOptions) ([]TableColumn, error) {
+	if sampleSize <= 0 {
+		sampleSize = defaultSchemaDetectionSampleSize
+	}
+	buffered := bufio.NewReader(src)
+	// Files saved by Excel start with a byte order mark, which would stop the
+	// first header from being read as a quoted field.
+	if bom, err := buffered.Peek(len(utf8BOM)); err == nil && bytes.Equal(bom, utf8BOM) {
+		buffered.Discard(len(utf8BOM))
+	}
+	var csvOpts pl.CSVOptions
+	if opts != nil {
+		csvOpts = *opts
+	}
+	reader := newCSVRecordReader(buffered, csvOpts)
+	if csvReader, ok := reader.(*csv.Reader); ok {
+		// Quoted fields may contain a stray quote in hand-written files.
+		csvReader.LazyQuotes = true
+	}
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, fferr.NewInvalidArgumentErrorf("CSV file is empty")
+	}
+	if err != nil {
+		return nil, fferr.NewInvalidArgumentError(fmt.Errorf("could not read CSV header: %w", err))
+	}
+	// Without a header, the first row is data and is typed with the rest.
+	var firstRow []string
+	if csvOpts.NoHeader {
+		firstRow, header = header, csvColumnNames(len(header))
+	}
+	names := make(map[string]bool, len(header))
+	columns := make([]TableColumn, len(header))
+	for i, name := range header {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			// Match the name Spark gives to a column without a header.
+			name = fmt.Sprintf("_c%d", i)
+		}
+		if names[name] {
+			return nil, fferr.NewInvalidArgumentErrorf("CSV header has duplicate column %s", name)
+		}
+		names[name] = true
+		columns[i] = TableColumn{Name: name, ValueType: types.NilType}
+	}
+	for row := 0; row < sampleSize; row++ {
+		var record []string
+		var err error
+		if row == 0 && firstRow != nil {
+			record = firstRow
+		} else {
+			record, err = reader.Read()
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fferr.NewInvalidArgumentError(fmt.Errorf("could not read CSV row %d: %w", row+1, err))
+		}
+		for i, value := range record {
+			columns[i].ValueType = mergeDetectedTypes(columns[i].ValueType, detectValueType(value))
+		}
+	}
+	for i := range columns {
+		// Columns with only empty values can't be typed.
+		if columns[i].ValueType == types.NilType {
+			columns[i].ValueType = types.String
+		}
+	}
+	return columns, nil
+}
+
+// detectValueType returns the narrowest type that a CSV value parses as. Empty
+// values are NilType so they don't affect the column's type.
+func detectValueType(value string) types.ValueType {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return types.NilType
+	}
+	if _, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return types.Int64
+	}
+	if _, err := strconv.ParseFloat(value, 64); err == nil {
+		return types.Float64
+	}
+	if lower := strings.ToLower(value); lower == "true" || lower == "false" {
+		return types.Bool
+	}
+	for _, format := range detectedTimestampFormats {
+		if _, err := time.Parse(format, value); err == nil {
+			return types.Timestamp
+		}
+	}
+	return types.String
+}
+
+func mergeDetectedTypes(column, value types.ValueType) types.ValueType {
+	switch {
+	case value == types.NilType || column == value:
+		return column
+	case column == types.NilType:
+		return value
+	case (column == types.Int64 && value == types.Float64) || (column == types.Float64 && value == types.Int64):
+		return types.Float64
+	default:
+		return types.String
+	}
+}
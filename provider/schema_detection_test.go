@@ -0,0 +1,138 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+//
+// Copyright 2024 FeatureForm Inc.
+//
+
+package provider
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	pl "github.com/featureform/provider/location"
+	"github.com/featureform/provider/types"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestDetectCSVColumns(t *testing.T) {
+	tests := map[string]struct {
+		csv        string
+		sampleSize int
+		expected   []TableColumn
+	}{
+		"Scalar Types": {
+			csv: "entity,count,score,active,ts,name\n" +
+				"a,1,0.5,true,2024-05-01T12:30:00Z,alice\n" +
+				"b,2,1.5,FALSE,2024-05-02 08:00:00,bob\n",
+			expected: []TableColumn{
+				{Name: "entity", ValueType: types.String},
+				{Name: "count", ValueType: types.Int64},
+				{Name: "score", ValueType: types.Float64},
+				{Name: "active", ValueType: types.Bool},
+				{Name: "ts", ValueType: types.Timestamp},
+				{Name: "name", ValueType: types.String},
+			},
+		},
+		"Quoted Header": {
+			csv: "\uFEFF\"user id\",\" amount, usd \",\"\"\n" +
+				"\"1\",\"2.5\",x\n",
+			expected: []TableColumn{
+				{Name: "user id", ValueType: types.Int64},
+				{Name: "amount, usd", ValueType: types.Float64},
+				{Name: "_c2", ValueType: types.String},
+			},
+		},
+		"Mixed Types": {
+			csv: "ints_and_floats,ints_and_strings,bools_and_ints\n" +
+				"1,1,true\n" +
+				"2.5,one,1\n",
+			expected: []TableColumn{
+				{Name: "ints_and_floats", ValueType: types.Float64},
+				{Name: "ints_and_strings", ValueType: types.String},
+				{Name: "bools_and_ints", ValueType: types.String},
+			},
+		},
+		"Empty Values": {
+			csv: "count,empty\n" +
+				"1,\n" +
+				",\n",
+			expected: []TableColumn{
+				{Name: "count", ValueType: types.Int64},
+				{Name: "empty", ValueType: types.String},
+			},
+		},
+		"Rows After Sample": {
+			csv: "count\n" +
+				"1\n" +
+				"one\n",
+			sampleSize: 1,
+			expected: []TableColumn{
+				{Name: "count", ValueType: types.Int64},
+			},
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			columns, err := detectCSVColumns(strings.NewReader(test.csv), test.sampleSize, nil)
+			if err != nil {
+				t.Fatalf("could not detect columns: %v", err)
+			}
+			if !reflect.DeepEqual(columns, test.expected) {
+				t.Fatalf("expected %v, got %v", test.expected, columns)
+			}
+		})
+	}
+}
+
+func TestDetectCSVColumnsFail(t *testing.T) {
+	tests := map[string]string{
+		"Empty File":       "",
+		"Duplicate Column": "a,b,a\n1,2,3\n",
+		"Uneven Rows":      "a,b\n1,2,3\n",
+	}
+	for name, csv := range tests {
+		t.Run(name, func(t *testing.T) {
+			if _, err := detectCSVColumns(strings.NewReader(csv), 0, nil); err == nil {
+				t.Fatalf("expected an error detecting columns of %q", csv)
+			}
+		})
+	}
+}
+
+func TestBlobRegisterPrimaryDetectSchema(t *testing.T) {
+	store, err := NewLocalFileStore([]byte(fmt.Sprintf(`{"DirPath": "file://%s/"}`, t.TempDir())))
+	if err != nil {
+		t.Fatalf("could not create local file store: %v", err)
+	}
+	path, err := store.CreateFilePath("transactions.csv", false)
+	if err != nil {
+		t.Fatalf("could not create file path: %v", err)
+	}
+	if err := store.Write(path, []byte("user,amount\nalice,1.5\nbob,2\n")); err != nil {
+		t.Fatalf("could not write CSV: %v", err)
+	}
+	id := ResourceID{Name: "transactions", Variant: "default", Type: Primary}
+	table, err := blobRegisterPrimary(id, *pl.NewFileLocation(path).(*pl.FileStoreLocation), zaptest.NewLogger(t).Sugar(), store, &DetectSchemaOption{})
+	if err != nil {
+		t.Fatalf("could not register primary: %v", err)
+	}
+	expected := []TableColumn{
+		{Name: "user", ValueType: types.String},
+		{Name: "amount", ValueType: types.Float64},
+	}
+	if columns := table.(*FileStorePrimaryTable).schema.Columns; !reflect.DeepEqual(columns, expected) {
+		t.Fatalf("expected %v, got %v", expected, columns)
+	}
+
+	parquetPath, err := store.CreateFilePath("transactions.parquet", false)
+	if err != nil {
+		t.Fatalf("could not create file path: %v", err)
+	}
+	if _, err := DetectSchema(store, *pl.NewFileLocation(parquetPath).(*pl.FileStoreLocation), 0); err == nil {
+		t.Fatalf("expected schema detection of a parquet file to fail")
+	}
+}
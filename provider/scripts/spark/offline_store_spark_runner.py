@@ -19,7 +19,7 @@ import logging
 from enum import Enum
 from pathlib import Path
 from typing import List
-from urllib.parse import urlparse
+from urllib.parse import quote_plus, urlparse
 from abc import ABC, abstractmethod
 import time
 import importlib.util
@@ -39,17 +39,20 @@ from pyspark.sql.types import (
     ArrayType,
     DoubleType,
     IntegerType,
+    LongType,
     StringType,
     StructField,
     StructType,
 )
 
 FILESTORES = ["local", "s3", "azure_blob_store", "google_cloud_storage", "hdfs"]
+COMPRESSION_EXTENSIONS = [".gz", ".zst"]
 
 
 class OutputFormat(str, Enum):
     CSV = "csv"
     PARQUET = "parquet"
+    AVRO = "avro"
 
 
 class Headers(str, Enum):
@@ -110,6 +113,7 @@ def main(args):
                 headers=args.headers,
                 credentials=args.credential,
                 is_update=args.is_update,
+                partition_by=args.partition_by,
             )
         elif args.transformation_type == "df":
             output_location = execute_df_job(
@@ -669,6 +673,243 @@ class DynamoDBClient:
             raise
 
 
+def serialize_redis_value(value):
+    """
+    Serializes a feature value the same way the Go Redis online store does, so that
+    directly copied values can be read back by redisOnlineTable.
+    """
+    if value is None:
+        return "nil"
+    # bool is a subclass of int, so it has to be checked first.
+    if isinstance(value, bool):
+        return "1" if value else "0"
+    if isinstance(value, int):
+        return str(value)
+    if isinstance(value, float):
+        return repr(value)
+    if isinstance(value, datetime.datetime):
+        if value.tzinfo is not None:
+            value = value.astimezone(datetime.timezone.utc)
+        return value.strftime("%Y-%m-%dT%H:%M:%SZ")
+    if isinstance(value, (list, dict)):
+        return json.dumps(value)
+    return str(value)
+
+
+def redis_connection_config(credentials):
+    """
+    Builds the connection settings of the Redis online store from the job's
+    credentials. It's a plain dict so that it can be serialized to the executors.
+    """
+
+    def addrs(key):
+        value = credentials.get(key, "")
+        return [addr for addr in value.split(",") if addr]
+
+    def pem(key):
+        value = credentials.get(key, "")
+        return base64.b64decode(value).decode("utf-8") if value else ""
+
+    return {
+        "addr": credentials.get("redis_addr", ""),
+        "username": credentials.get("redis_username", ""),
+        "password": credentials.get("redis_password", ""),
+        "db": int(credentials.get("redis_db", 0)),
+        "topology": credentials.get("redis_topology", "standalone"),
+        "cluster_addrs": addrs("redis_cluster_addrs"),
+        "sentinel_master_name": credentials.get("redis_sentinel_master_name", ""),
+        "sentinel_addrs": addrs("redis_sentinel_addrs"),
+        "tls": credentials.get("redis_tls", "false") == "true",
+        "tls_ca_cert": pem("redis_tls_ca_cert"),
+        "tls_cert": pem("redis_tls_cert"),
+        "tls_key": pem("redis_tls_key"),
+        "tls_insecure_skip_verify": credentials.get(
+            "redis_tls_insecure_skip_verify", "false"
+        )
+        == "true",
+    }
+
+
+def split_redis_addr(addr):
+    host, _, port = addr.rpartition(":")
+    return host, int(port)
+
+
+def redis_tls_kwargs(conn, cert_dir):
+    """
+    Returns the redis-py TLS arguments of a connection. The client certificate
+    and key have to be files, so they're written to cert_dir.
+    """
+    if not conn["tls"]:
+        return {}
+    kwargs = {
+        "ssl": True,
+        "ssl_cert_reqs": "none" if conn["tls_insecure_skip_verify"] else "required",
+    }
+    if conn["tls_ca_cert"]:
+        kwargs["ssl_ca_data"] = conn["tls_ca_cert"]
+    if conn["tls_cert"]:
+        for name in ("tls_cert", "tls_key"):
+            path = os.path.join(cert_dir, f"redis_{name}.pem")
+            with open(path, "w") as f:
+                f.write(conn[name])
+            kwargs["ssl_certfile" if name == "tls_cert" else "ssl_keyfile"] = path
+    return kwargs
+
+
+def redis_client(conn, cert_dir):
+    """
+    Connects to Redis the same way the Go Redis online store does: to a single
+    node, a cluster through its seed nodes, or the primary that the Sentinels
+    monitor.
+    """
+    # Imported here so the redis package is only required on clusters that use it.
+    import redis
+
+    tls_kwargs = redis_tls_kwargs(conn, cert_dir)
+    auth_kwargs = {
+        "username": conn["username"] or None,
+        "password": conn["password"] or None,
+    }
+    if conn["topology"] == "cluster":
+        from redis.cluster import ClusterNode, RedisCluster
+
+        nodes = [
+            ClusterNode(*split_redis_addr(addr)) for addr in conn["cluster_addrs"]
+        ]
+        return RedisCluster(startup_nodes=nodes, **auth_kwargs, **tls_kwargs)
+    if conn["topology"] == "sentinel":
+        from redis.sentinel import Sentinel
+
+        sentinel = Sentinel(
+            [split_redis_addr(addr) for addr in conn["sentinel_addrs"]],
+            sentinel_kwargs=tls_kwargs,
+            db=conn["db"],
+            **auth_kwargs,
+            **tls_kwargs,
+        )
+        return sentinel.master_for(conn["sentinel_master_name"])
+    host, port = split_redis_addr(conn["addr"])
+    return redis.Redis(
+        host=host, port=port, db=conn["db"], **auth_kwargs, **tls_kwargs
+    )
+
+
+# Used to only pass properties that can be serialized across Spark
+# executors.
+def redis_write_partition_closure(conn, table_key, batch_size):
+    def write_partition(partition):
+        import tempfile
+
+        with tempfile.TemporaryDirectory() as cert_dir:
+            client = redis_client(conn, cert_dir)
+            # The pipeline isn't transactional, it's only used to batch round trips.
+            pipeline = client.pipeline(transaction=False)
+            pending = 0
+            for row in partition:
+                pipeline.hset(
+                    table_key,
+                    str(row["entity"]),
+                    serialize_redis_value(row["value"]),
+                )
+                pending += 1
+                if pending == batch_size:
+                    pipeline.execute()
+                    pending = 0
+            if pending:
+                pipeline.execute()
+            client.close()
+
+    return write_partition
+
+
+# Cosmos DB returns this error code when a request exceeds the provisioned
+# throughput (RUs) of a collection.
+COSMOS_TOO_MANY_REQUESTS = 16500
+COSMOS_MAX_RETRIES = 10
+
+
+def cosmos_retry_after_seconds(errmsg, attempt):
+    """
+    Returns how long to wait before retrying a throttled Cosmos DB write. Cosmos
+    includes a RetryAfterMs hint in the error message; if it's missing we fall back
+    to an exponential backoff.
+    """
+    for part in errmsg.replace(",", " ").split():
+        if part.startswith("RetryAfterMs="):
+            try:
+                return int(part[len("RetryAfterMs=") :]) / 1000
+            except ValueError:
+                break
+    return min(2**attempt * 0.1, 30)
+
+
+def cosmos_uri(host, port, username, password):
+    # Matches the connection string used by the Go MongoDB online store.
+    return (
+        f"mongodb://{quote_plus(username)}:{quote_plus(password)}@{host}:{port}/"
+        "?ssl=true&replicaSet=globaldb&retrywrites=false&maxIdleTimeMS=120000"
+    )
+
+
+# Used to only pass properties that can be serialized across Spark
+# executors.
+def cosmos_write_partition_closure(uri, database, collection, batch_size, is_long):
+    def write_partition(partition):
+        # Imported here so pymongo is only required on clusters that use it.
+        from bson.int64 import Int64
+        from pymongo import MongoClient, UpdateOne
+        from pymongo.errors import BulkWriteError
+
+        client = MongoClient(uri)
+        table = client[database][collection]
+
+        def write_batch(ops):
+            attempt = 0
+            while ops:
+                try:
+                    table.bulk_write(ops, ordered=False)
+                    return
+                except BulkWriteError as e:
+                    errors = e.details.get("writeErrors", [])
+                    throttled = [
+                        err
+                        for err in errors
+                        if err["code"] == COSMOS_TOO_MANY_REQUESTS
+                    ]
+                    if len(throttled) != len(errors) or attempt >= COSMOS_MAX_RETRIES:
+                        raise
+                    wait = cosmos_retry_after_seconds(throttled[0]["errmsg"], attempt)
+                    time.sleep(wait)
+                    ops = [ops[err["index"]] for err in throttled]
+                    attempt += 1
+
+        ops = []
+        for row in partition:
+            value = row["value"]
+            # The Go online store reads Int64 features as BSON longs, but pymongo
+            # writes small ints as 32-bit.
+            if is_long and value is not None:
+                value = Int64(value)
+            # Upserting by entity also keeps collections that are sharded on
+            # entity working, since the shard key is always in the filter.
+            ops.append(
+                UpdateOne(
+                    {"entity": row["entity"]},
+                    {"$set": {"entity": row["entity"], "value": value}},
+                    upsert=True,
+                )
+            )
+            if len(ops) == batch_size:
+                write_batch(ops)
+                ops = []
+        if ops:
+            write_batch(ops)
+        client.close()
+
+    return write_partition
+
+
 class LatestFeaturesTransform:
     def __init__(self, entity_col, value_cols, timestamp_col):
         self.entity_col = entity_col
@@ -808,6 +1049,75 @@ class MaterializeToIcebergAndDynamoV1Operation:
         dynamo_table.merge_in(aliased_df, entity_col, value_col)
 
 
+class RedisMaterializationTable:
+    # Matches the default pipeline size of the Go Redis online store.
+    DEFAULT_PIPELINE_SIZE = 1000
+
+    def __init__(self, conn, table_key, pipeline_size=None):
+        self.conn = conn
+        self.table_key = table_key
+        self.pipeline_size = pipeline_size or self.DEFAULT_PIPELINE_SIZE
+
+    def merge_in(self, df, entity_col, value_col):
+        redis_df = df.select(
+            F.col(entity_col).cast("string").alias("entity"),
+            F.col(value_col).alias("value"),
+        )
+        write_partition_fn = redis_write_partition_closure(
+            self.conn, self.table_key, self.pipeline_size
+        )
+        redis_df.foreachPartition(write_partition_fn)
+
+
+class MaterializeToRedisOperation:
+    def __init__(self, redis_table):
+        self._redis_table = redis_table
+
+    def run(self, df, entity_col, value_col, timestamp_col=None):
+        latest_df = df
+        if timestamp_col is not None:
+            latest_df = LatestFeaturesTransform(
+                entity_col, [value_col], timestamp_col
+            ).apply(df)
+        self._redis_table.merge_in(latest_df, entity_col, value_col)
+
+
+class CosmosMaterializationTable:
+    # Small batches keep each request well under the RU limits of an autoscaled
+    # collection; throttled writes are retried either way.
+    DEFAULT_BATCH_SIZE = 100
+
+    def __init__(self, uri, database, collection, batch_size=None):
+        self.uri = uri
+        self.database = database
+        self.collection = collection
+        self.batch_size = batch_size or self.DEFAULT_BATCH_SIZE
+
+    def merge_in(self, df, entity_col, value_col):
+        is_long = isinstance(df.schema[value_col].dataType, LongType)
+        cosmos_df = df.select(
+            F.col(entity_col).cast("string").alias("entity"),
+            F.col(value_col).alias("value"),
+        )
+        write_partition_fn = cosmos_write_partition_closure(
+            self.uri, self.database, self.collection, self.batch_size, is_long
+        )
+        cosmos_df.foreachPartition(write_partition_fn)
+
+
+class MaterializeToCosmosOperation:
+    def __init__(self, cosmos_table):
+        self._cosmos_table = cosmos_table
+
+    def run(self, df, entity_col, value_col, timestamp_col=None):
+        latest_df = df
+        if timestamp_col is not None:
+            latest_df = LatestFeaturesTransform(
+                entity_col, [value_col], timestamp_col
+            ).apply(df)
+        self._cosmos_table.merge_in(latest_df, entity_col, value_col)
+
+
 def execute_materialization(
     credentials,
     spark_configs,
@@ -828,6 +1138,28 @@ def execute_materialization(
             raise Exception(f"Cannot materialize from more than one source: {sources}")
         source = sources[0]
         source_df = get_source_df(source, credentials, False, spark)
+        if target == "redis":
+            redis_table = RedisMaterializationTable(
+                redis_connection_config(credentials),
+                table_name,
+                int(credentials.get("redis_pipeline_size", 0)),
+            )
+            op = MaterializeToRedisOperation(redis_table)
+            op.run(source_df, entity_column, value_column, timestamp_column)
+            return
+        if target == "cosmos":
+            uri = cosmos_uri(
+                credentials.get("cosmos_host"),
+                credentials.get("cosmos_port"),
+                credentials.get("cosmos_username"),
+                credentials.get("cosmos_password"),
+            )
+            cosmos_table = CosmosMaterializationTable(
+                uri, credentials.get("cosmos_database"), table_name
+            )
+            op = MaterializeToCosmosOperation(cosmos_table)
+            op.run(source_df, entity_column, value_column, timestamp_column)
+            return
         if target != "dynamo":
             raise Exception(f"Cannot directly materialize into {target}")
         access = credentials.get("dynamo_aws_access_key_id")
@@ -858,6 +1190,7 @@ def execute_sql_query(
     headers,
     credentials,
     is_update=False,
+    partition_by=None,
 ):
     # Executes the SQL Queries:
     # Parameters:
@@ -866,6 +1199,7 @@ def execute_sql_query(
     #     sql_query: string (eg. "SELECT * FROM source_0)
     #     spark_configs: dict (eg. {"fs.azure.account.key.account_name.dfs.core.windows.net": "aksdfkai=="})
     #     sources: List(dict) containing the location of sources, their provider type and possible information about the file/directory
+    #     partition_by: List(string) of columns to partition file output by (eg. ["ts_date"])
     # Return:
     #     output_uri_with_timestamp: string (output s3 path)
     try:
@@ -899,24 +1233,34 @@ def execute_sql_query(
             # remove the '/' at the end of output_uri in order to avoid double slashes in the output file path.
             output_uri_with_timestamp = f"{output_location.rstrip('/')}/{safe_datetime}"
 
+            writer = output_dataframe.write
+            if partition_by:
+                print(f"Partitioning output by {partition_by}")
+                writer = writer.partitionBy(*partition_by)
+
             if output_format == OutputFormat.PARQUET:
                 if headers == Headers.EXCLUDE:
                     raise Exception(
                         f"the output format '{output_format}' does not support excluding headers. Supported types: 'csv'"
                     )
-                output_dataframe.write.option("header", "true").mode(
-                    "overwrite"
-                ).parquet(output_uri_with_timestamp)
+                writer.option("header", "true").mode("overwrite").parquet(
+                    output_uri_with_timestamp
+                )
             elif output_format == OutputFormat.CSV:
                 if headers == Headers.EXCLUDE:
-                    output_dataframe.write.mode("overwrite").csv(
+                    writer.mode("overwrite").csv(output_uri_with_timestamp)
+                else:
+                    writer.option("header", "true").mode("overwrite").csv(
                         output_uri_with_timestamp
                     )
-                else:
-                    output_dataframe.write.option("header", "true").mode(
-                        "overwrite"
-                    ).csv(output_uri_with_timestamp)
                 print(f"Successfully wrote CSV output {output_uri_with_timestamp}")
+            elif output_format == OutputFormat.AVRO:
+                if headers == Headers.EXCLUDE:
+                    raise Exception(
+                        f"the output format '{output_format}' does not support excluding headers. Supported types: 'csv'"
+                    )
+                writer.format("avro").mode("overwrite").save(output_uri_with_timestamp)
+                print(f"Successfully wrote Avro output {output_uri_with_timestamp}")
         elif output_location_type == "catalog":
             table_format = output.get("tableFormat")
 
@@ -939,7 +1283,6 @@ def execute_sql_query(
                     "delta"
                 )
 
-                final_write_obj.append()
                 final_write_obj.createOrReplace()
 
                 print(f"Delta table {output_location} created successfully")
@@ -949,7 +1292,7 @@ def execute_sql_query(
                 )
         else:
             raise Exception(
-                f"the output format '{output_format}' is not supported. Supported types: 'parquet', 'csv'"
+                f"the output format '{output_format}' is not supported. Supported types: 'parquet', 'csv', 'avro'"
             )
         print("Successfully completed SQL job")
         return output_uri_with_timestamp
@@ -1003,18 +1346,36 @@ def get_source_df(source, credentials, is_update, spark):
         timestamp_column = source.get("timestampColumnName")
         return source_df
     elif location_type == "filestore":
-        file_extension = Path(location).suffix
-        is_directory = file_extension == ""
-
-        if file_extension == ".csv":
+        path = Path(location)
+        # Spark decompresses gzip and zstd files based on their extension, so the
+        # file type is given by the extension before the compression extension.
+        if source.get("compression") and path.suffix in COMPRESSION_EXTENSIONS:
+            path = path.with_suffix("")
+        file_extension = path.suffix
+        is_directory = file_extension == "" and not source.get("compression")
+        csv_options = source.get("csvOptions")
+
+        if file_extension == ".csv" or (
+            csv_options
+            and not is_directory
+            and file_extension not in (".parquet", ".avro", ".json")
+        ):
             print(f"Reading CSV file: {location}")
             source_df = (
-                spark.read.option("header", "true")
+                csv_reader_with_options(spark, csv_options)
                 .option("ignoreCorruptFiles", "true")
                 .option("recursiveFileLookup", "true")
                 .csv(location)
             )
             return source_df
+        elif file_extension == ".json":
+            print(f"Reading JSON file: {location}")
+            source_df = (
+                spark.read.option("ignoreCorruptFiles", "true")
+                .option("recursiveFileLookup", "true")
+                .json(location)
+            )
+            return source_df
         elif file_extension == ".parquet" or is_directory:
             print(f"Reading Parquet file: {location}")
             source_df = (
@@ -1034,6 +1395,24 @@ def get_source_df(source, credentials, is_update, spark):
         )
 
 
+def csv_reader_with_options(spark, csv_options):
+    """
+    Returns a DataFrameReader for delimited files. csv_options is the source's
+    csvOptions, if any; unset options keep Spark's defaults, except that the
+    file has a header unless noHeader is set.
+    """
+    csv_options = csv_options or {}
+    header = "false" if csv_options.get("noHeader") else "true"
+    reader = spark.read.option("header", header)
+    if csv_options.get("delimiter"):
+        reader = reader.option("sep", csv_options["delimiter"])
+    if csv_options.get("quote"):
+        reader = reader.option("quote", csv_options["quote"])
+    if csv_options.get("escape"):
+        reader = reader.option("escape", csv_options["escape"])
+    return reader
+
+
 def partition_delta_by_timestamp(df, output_location, column):
     df = df.withColumn("date", F.date_format(F.col(column), "yyyy-MM-dd"))
 
@@ -1187,6 +1566,18 @@ def execute_df_job(
                     f"Successfully wrote CSV output {output_uri_with_timestamp}",
                     flush=True,
                 )
+            elif output_format == OutputFormat.AVRO:
+                if headers == Headers.EXCLUDE:
+                    raise Exception(
+                        f"the output format '{output_format}' does not support excluding headers. Supported types: 'csv'"
+                    )
+                output_dataframe.write.format("avro").mode("overwrite").save(
+                    output_uri_with_timestamp
+                )
+                print(
+                    f"Successfully wrote Avro output {output_uri_with_timestamp}",
+                    flush=True,
+                )
         elif output_location_type == "catalog":
             table_format = output.get("tableFormat")
 
@@ -1218,7 +1609,7 @@ def execute_df_job(
                 )
         else:
             raise Exception(
-                f"the output format '{output_format}' is not supported. Supported types: 'parquet', 'csv'"
+                f"the output format '{output_format}' is not supported. Supported types: 'parquet', 'csv', 'avro'"
             )
 
         return output_uri_with_timestamp
@@ -1358,7 +1749,17 @@ def get_s3_object(file_path, credentials):
             region_name=credentials.get("aws_region"),
         )
 
-    s3 = session.resource("s3")
+    s3 = session.resource(
+        "s3",
+        endpoint_url=credentials.get("aws_endpoint_url") or None,
+        config=Config(
+            s3={
+                "addressing_style": "path"
+                if credentials.get("aws_force_path_style", "false") == "true"
+                else "auto"
+            }
+        ),
+    )
     s3_object = s3.Object(credentials.get("aws_bucket_name"), file_path)
 
     return s3_object
@@ -1502,6 +1903,8 @@ def setup_sql_parser(parser):
     """
     setup_common_parser(parser)
     parser.add_argument("--sql_query", help="SQL query to run on the data source.")
+    parser.add_argument("--partition_by", action="append", default=[],
+                        help="Column to partition file output by. Can be given more than once.")
 
 def setup_df_parser(parser):
     """
@@ -8,4 +8,4 @@
 # 
 
 echo "Installing Python packages"
-sudo pip3 install boto3 dill azure-storage-blob==12.13.1 google-cloud-storage==2.7.0
\ No newline at end of file
+sudo pip3 install boto3 dill azure-storage-blob==12.13.1 google-cloud-storage==2.7.0 redis pymongo
\ No newline at end of file
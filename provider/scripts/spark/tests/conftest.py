@@ -69,6 +69,7 @@ def sql_all_arguments():
         direct_copy_entity_column=None,
         direct_copy_value_column=None,
         direct_copy_timestamp_column=None,
+        partition_by=[],
     )
     return (input_args, expected_args)
 
@@ -96,6 +97,7 @@ def sql_local_all_arguments():
         headers="include",
         submit_params_uri=None,
         is_update=False,
+        partition_by=[],
     )
     return expected_args
 
@@ -133,6 +135,7 @@ def sql_partial_arguments():
         direct_copy_entity_column=None,
         direct_copy_value_column=None,
         direct_copy_timestamp_column=None,
+        partition_by=[],
     )
     return (input_args, expected_args)
 
@@ -327,6 +330,7 @@ def sql_databricks_all_arguments():
         direct_copy_entity_column=None,
         direct_copy_value_column=None,
         direct_copy_timestamp_column=None,
+        partition_by=[],
     )
     return input_args, expected_args
 
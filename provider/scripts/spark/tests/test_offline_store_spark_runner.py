@@ -5,6 +5,7 @@
 #  Copyright 2024 FeatureForm Inc.
 #
 
+import datetime
 import os
 import sys
 
@@ -26,6 +27,10 @@ from offline_store_spark_runner import (
     delete_file,
     check_dill_exception,
     get_s3_object,
+    serialize_redis_value,
+    redis_connection_config,
+    redis_tls_kwargs,
+    cosmos_retry_after_seconds,
 )
 
 
@@ -185,6 +190,71 @@ def test_split_key_value():
     assert output == expected_output
 
 
+@pytest.mark.parametrize(
+    "value, expected",
+    [
+        (None, "nil"),
+        (True, "1"),
+        (False, "0"),
+        (42, "42"),
+        (1.5, "1.5"),
+        ("abc", "abc"),
+        (datetime.datetime(2024, 1, 2, 3, 4, 5), "2024-01-02T03:04:05Z"),
+        ({"a": 1}, '{"a": 1}'),
+    ],
+)
+def test_serialize_redis_value(value, expected):
+    assert serialize_redis_value(value) == expected
+
+
+def test_redis_connection_config():
+    conn = redis_connection_config(
+        {
+            "redis_addr": "",
+            "redis_username": "featureform",
+            "redis_password": "secret",
+            "redis_db": "0",
+            "redis_topology": "cluster",
+            "redis_cluster_addrs": "node-1:6379,node-2:6379",
+            "redis_tls": "true",
+            "redis_tls_ca_cert": "Y2E=",
+        }
+    )
+    assert conn["topology"] == "cluster"
+    assert conn["cluster_addrs"] == ["node-1:6379", "node-2:6379"]
+    assert conn["username"] == "featureform"
+    assert conn["tls"]
+    assert conn["tls_ca_cert"] == "ca"
+    assert not conn["tls_insecure_skip_verify"]
+
+
+def test_redis_tls_kwargs(tmp_path):
+    conn = redis_connection_config(
+        {"redis_addr": "localhost:6379", "redis_db": "0", "redis_tls": "true"}
+    )
+    conn["tls_cert"] = "cert"
+    conn["tls_key"] = "key"
+    kwargs = redis_tls_kwargs(conn, str(tmp_path))
+    assert kwargs["ssl"]
+    assert kwargs["ssl_cert_reqs"] == "required"
+    assert open(kwargs["ssl_certfile"]).read() == "cert"
+    assert open(kwargs["ssl_keyfile"]).read() == "key"
+    conn["tls"] = False
+    assert redis_tls_kwargs(conn, str(tmp_path)) == {}
+
+
+@pytest.mark.parametrize(
+    "errmsg, attempt, expected",
+    [
+        ("Error=16500, RetryAfterMs=250, Details='Request rate is large'", 0, 0.25),
+        ("Error=16500, Details='Request rate is large'", 3, 0.8),
+        ("Error=16500", 20, 30),
+    ],
+)
+def test_cosmos_retry_after_seconds(errmsg, attempt, expected):
+    assert cosmos_retry_after_seconds(errmsg, attempt) == expected
+
+
 @pytest.mark.skipif(sys.platform.startswith("win"), reason="should not run on windows")
 @pytest.mark.parametrize(
     "exception_message, error",
@@ -0,0 +1,58 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+//
+// Copyright 2024 FeatureForm Inc.
+//
+
+package provider
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/featureform/helpers/secrets"
+	pc "github.com/featureform/provider/provider_config"
+)
+
+const secretResolutionTimeout = 30 * time.Second
+
+var (
+	secretResolverMu sync.Mutex
+	secretResolver   *secrets.Resolver
+)
+
+// resolveSecrets replaces secret references in a provider's config with the
+// secrets' values. The secret manager is only set up the first time a config
+// references one, so deployments that don't use them need no config.
+func resolveSecrets(config pc.SerializedConfig) (pc.SerializedConfig, error) {
+	if !secrets.HasReferences(config) {
+		return config, nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), secretResolutionTimeout)
+	defer cancel()
+	resolver, err := getSecretResolver(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return resolver.Resolve(ctx, config)
+}
+
+// getSecretResolver returns the secret resolver, setting it up from the
+// environment if it hasn't been yet. Only a resolver that was set up is kept,
+// so a failure, like the secret manager being unreachable, is retried on the
+// next call.
+func getSecretResolver(ctx context.Context) (*secrets.Resolver, error) {
+	secretResolverMu.Lock()
+	defer secretResolverMu.Unlock()
+	if secretResolver != nil {
+		return secretResolver, nil
+	}
+	resolver, err := secrets.FromEnv(ctx)
+	if err != nil {
+		return nil, err
+	}
+	secretResolver = resolver
+	return resolver, nil
+}
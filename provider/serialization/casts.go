@@ -8,6 +8,7 @@
 package serialization
 
 import (
+	"encoding/json"
 	"fmt"
 	"strconv"
 )
@@ -177,3 +178,23 @@ func CastBool(value any) (bool, error) {
 		return false, fmt.Errorf("Type error: Expected numerical type and got %T", casted)
 	}
 }
+
+// CastJSON returns a JSON value as a string. If validate is set, it fails if the
+// value isn't well-formed JSON.
+func CastJSON(value any, validate bool) (string, error) {
+	var casted string
+	switch typed := value.(type) {
+	case string:
+		casted = typed
+	case []byte:
+		casted = string(typed)
+	case json.RawMessage:
+		casted = string(typed)
+	default:
+		return "", fmt.Errorf("Type error: Expected JSON string or bytes and got %T", typed)
+	}
+	if validate && !json.Valid([]byte(casted)) {
+		return "", fmt.Errorf("Type error: Value is not valid JSON: %q", casted)
+	}
+	return casted, nil
+}
@@ -0,0 +1,47 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+//
+// Copyright 2024 FeatureForm Inc.
+//
+
+package serialization
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestCastJSON(t *testing.T) {
+	tests := []struct {
+		name     string
+		value    any
+		validate bool
+		expected string
+		wantErr  bool
+	}{
+		{"String", `{"a": 1}`, true, `{"a": 1}`, false},
+		{"Bytes", []byte(`[1, 2, 3]`), true, `[1, 2, 3]`, false},
+		{"RawMessage", json.RawMessage(`"abc"`), true, `"abc"`, false},
+		{"Invalid", `{"a": 1`, true, "", true},
+		{"Invalid unvalidated", `{"a": 1`, false, `{"a": 1`, false},
+		{"Wrong type", 1, false, "", true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			casted, err := CastJSON(test.value, test.validate)
+			if test.wantErr {
+				if err == nil {
+					t.Fatalf("Succeeded to cast %v to JSON: %s", test.value, casted)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Failed to cast %v to JSON: %s", test.value, err)
+			}
+			if casted != test.expected {
+				t.Fatalf("Expected %s, got %s", test.expected, casted)
+			}
+		})
+	}
+}
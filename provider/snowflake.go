@@ -10,7 +10,9 @@ package provider
 import (
 	"context"
 	"fmt"
+	"regexp"
 	"strings"
+	"time"
 
 	"github.com/featureform/fferr"
 	"github.com/featureform/helpers/stringset"
@@ -55,6 +57,7 @@ func snowflakeOfflineStoreFactory(config pc.SerializedConfig) (Provider, error)
 		ProviderType:            pt.SnowflakeOffline,
 		QueryImpl:               &queries,
 		ConnectionStringBuilder: sc.ConnectionString,
+		ConnectionPool:          sc.ConnectionPool,
 	}
 
 	store, err := NewSQLOfflineStore(sgConfig)
@@ -82,6 +85,10 @@ func (sf *snowflakeOfflineStore) CreateTransformation(config TransformationConfi
 		logger.Errorw("Snowflake off does not support transformation options")
 		return fferr.NewInternalErrorf("Snowflake off does not support transformation options")
 	}
+	if err := ValidateTransformation(config); err != nil {
+		logger.Errorw("Transformation failed validation", "error", err)
+		return err
+	}
 	tableName, err := sf.sqlOfflineStore.getTransformationTableName(config.TargetTableID)
 	if err != nil {
 		logger.Errorw("Failed to get transformation table name", "error", err)
@@ -110,7 +117,7 @@ func (sf *snowflakeOfflineStore) CreateTransformation(config TransformationConfi
 		logger.Errorw("Failed to validate dynamic table config", "error", err)
 		return err
 	}
-	query := sf.sfQueries.dynamicIcebergTableCreate(tableName, config.Query, *resConfig)
+	query := sf.sfQueries.dynamicIcebergTableCreate(qualifiedSnowflakeTable(snowflakeConfig, tableName), config.Query, *resConfig)
 	logger.Debugw("Creating Dynamic Iceberg Table for source", "query", query)
 	if _, err := sf.sqlOfflineStore.db.Exec(query); err != nil {
 		logger.Errorw("Failed to create dynamic iceberg table", "error", err)
@@ -126,14 +133,28 @@ func (sf *snowflakeOfflineStore) UpdateTransformation(config TransformationConfi
 	return fferr.NewInternalErrorf("Snowflake Offline Store does not currently support updating transformations")
 }
 
+func (sf *snowflakeOfflineStore) Capabilities() (Capabilities, error) {
+	caps, err := offlineStoreCapabilities(sf)
+	if err != nil {
+		return Capabilities{}, err
+	}
+	var snowflakeConfig pc.SnowflakeConfig
+	if err := snowflakeConfig.Deserialize(sf.sqlOfflineStore.Config()); err != nil {
+		sf.logger.Errorw("Failed to deserialize snowflake config", "error", err)
+		return Capabilities{}, err
+	}
+	caps.Catalog = snowflakeConfig.Catalog != nil
+	return caps, nil
+}
+
 // Snowflake breaks with the pattern of other offline store that create resource tables for labels and features. (Resource tables are intermediate tables
 // between the sources on which labels and features are registered and materializations and training sets; they duplicate the data from the source tables
 // for the 2 columns provided, that is, entity, value, and timestamp.)
 func (sf *snowflakeOfflineStore) RegisterResourceFromSourceTable(id ResourceID, schema ResourceSchema, opts ...ResourceOption) (OfflineTable, error) {
 	logger := sf.logger.WithResource(logging.ResourceType(id.Type.String()), id.Name, id.Variant).With("schema", schema)
 	ctx := logger.AttachToContext(context.Background())
-	if len(opts) > 0 {
-		return nil, fferr.NewInvalidArgumentErrorf("snowflake offline store does not currently support resource options")
+	if err := schema.checkUnparsed(pt.SnowflakeOffline, opts...); err != nil {
+		return nil, err
 	}
 	missingCols, err := sf.checkSourceContainsResourceColumns(ctx, id, schema, logger, opts...)
 	if err != nil {
@@ -181,13 +202,15 @@ func (sf *snowflakeOfflineStore) checkSourceContainsResourceColumns(ctx context.
 	}
 	defer rows.Close()
 	actual := make(stringset.StringSet)
+	columnTypes := make(map[string]string)
 	for rows.Next() {
-		var column string
-		if err := rows.Scan(&column); err != nil {
+		var column, dataType string
+		if err := rows.Scan(&column, &dataType); err != nil {
 			logger.Errorw("Failed to scan resource table columns", "error", err)
 			return missingCols, err
 		}
 		actual.Add(strings.ToUpper(column))
+		columnTypes[strings.ToUpper(column)] = dataType
 	}
 	if err := rows.Err(); err != nil {
 		logger.Errorw("Error iterating over resource table columns", "error", err)
@@ -198,10 +221,45 @@ func (sf *snowflakeOfflineStore) checkSourceContainsResourceColumns(ctx context.
 		logger.Errorw("Source table does not have expected columns", "diff", diff.List())
 		return diff.List(), fferr.NewInvalidArgumentErrorf("source table does not have expected columns: %v", diff.List())
 	}
+	err = schema.checkValueColumnType(func(column string) (string, columnTypeFamily, error) {
+		dataType := columnTypes[strings.ToUpper(column)]
+		return dataType, sqlColumnTypeFamily(dataType), nil
+	})
+	if err != nil {
+		logger.Errorw("Value column type doesn't match the source table", "error", err)
+		return missingCols, err
+	}
 	logger.Info("Successfully checked source table for resource columns")
 	return missingCols, nil
 }
 
+// qualifiedSnowflakeTable places a table that Featureform creates in the configured
+// database and schema, so it doesn't depend on the defaults of the connection's user.
+// The schema defaults to PUBLIC, the same as the connection's.
+func qualifiedSnowflakeTable(config pc.SnowflakeConfig, table string) pl.FullyQualifiedObject {
+	schema := config.Schema
+	if schema == "" {
+		schema = "PUBLIC"
+	}
+	return pl.FullyQualifiedObject{
+		Database: normalizeSnowflakeIdentifier(config.Database),
+		Schema:   normalizeSnowflakeIdentifier(schema),
+		Table:    table,
+	}
+}
+
+var unquotedSnowflakeIdentifier = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_$]*$`)
+
+// normalizeSnowflakeIdentifier upper cases a configured name that Snowflake
+// would resolve case-insensitively, so that it still refers to the same object
+// once it's quoted. Names that can only be used quoted are left as is.
+func normalizeSnowflakeIdentifier(name string) string {
+	if unquotedSnowflakeIdentifier.MatchString(name) {
+		return strings.ToUpper(name)
+	}
+	return name
+}
+
 func (sf *snowflakeOfflineStore) getValidTableLocation(loc pl.Location) (pl.FullyQualifiedObject, error) {
 	sqlLoc, isSqlLoc := loc.(*pl.SQLLocation)
 	if !isSqlLoc {
@@ -234,6 +292,63 @@ func (sf *snowflakeOfflineStore) GetResourceTable(id ResourceID) (OfflineTable,
 	return nil, fferr.NewInternalErrorf("Snowflake Offline Store does not currently support getting resource tables")
 }
 
+// GetResourceStats reads the stats of the tables Snowflake creates for a resource.
+// Features aren't copied into resource tables, so their stats come from their
+// materialization, which holds the latest value of each entity. Labels are only
+// ever read from their source table, so they don't have stats of their own.
+func (sf *snowflakeOfflineStore) GetResourceStats(id ResourceID, resource any) (ResourceStats, error) {
+	logger := sf.logger.WithResource(logging.ResourceType(id.Type.String()), id.Name, id.Variant)
+	var snowflakeConfig pc.SnowflakeConfig
+	if err := snowflakeConfig.Deserialize(sf.sqlOfflineStore.Config()); err != nil {
+		logger.Errorw("Failed to deserialize snowflake config", "error", err)
+		return ResourceStats{}, err
+	}
+	var table pl.FullyQualifiedObject
+	switch id.Type {
+	case Feature:
+		tableName, err := ps.ResourceToTableName(FeatureMaterialization.String(), id.Name, id.Variant)
+		if err != nil {
+			logger.Errorw("Failed to get materialization table name", "error", err)
+			return ResourceStats{}, err
+		}
+		table = qualifiedSnowflakeTable(snowflakeConfig, tableName)
+	case TrainingSet:
+		tableName, err := sf.sqlOfflineStore.getTrainingSetName(id)
+		if err != nil {
+			logger.Errorw("Failed to get training set table name", "error", err)
+			return ResourceStats{}, err
+		}
+		table = qualifiedSnowflakeTable(snowflakeConfig, tableName)
+	case Transformation:
+		tableName, err := sf.sqlOfflineStore.getTransformationTableName(id)
+		if err != nil {
+			logger.Errorw("Failed to get transformation table name", "error", err)
+			return ResourceStats{}, err
+		}
+		table = qualifiedSnowflakeTable(snowflakeConfig, tableName)
+	case Primary:
+		sv, ok := resource.(*metadata.SourceVariant)
+		if !ok {
+			return ResourceStats{}, fferr.NewInvalidArgumentErrorf("resource is not a SourceVariant")
+		}
+		location, err := sv.GetPrimaryLocation()
+		if err != nil {
+			logger.Errorw("Failed to get primary location", "error", err)
+			return ResourceStats{}, err
+		}
+		table, err = sf.getValidTableLocation(location)
+		if err != nil {
+			return ResourceStats{}, err
+		}
+	case Label:
+		return ResourceStats{}, fferr.NewUnimplementedErrorf("resource stats are not supported for Snowflake labels, which are read from their source table")
+	default:
+		return ResourceStats{}, fferr.NewInvalidArgumentErrorf("unsupported resource type: %s", id.Type)
+	}
+	logger.Debugw("Getting resource stats", "table", table)
+	return sf.sqlOfflineStore.resourceStats(SanitizeSqlLocation(table), id.Type == Feature)
+}
+
 func (sf *snowflakeOfflineStore) CreateMaterialization(id ResourceID, opts MaterializationOptions) (Materialization, error) {
 	logger := sf.logger.WithResource(logging.FeatureVariant, id.Name, id.Variant)
 	if err := id.check(Feature); err != nil {
@@ -271,12 +386,19 @@ func (sf *snowflakeOfflineStore) CreateMaterialization(id ResourceID, opts Mater
 		logger.Errorw("Source table is not an SQL location", "location_type", fmt.Sprintf("%T", opts.Schema.SourceTable))
 		return nil, fferr.NewInvalidArgumentErrorf("source table is not an SQL location")
 	}
-	materializationAsQuery := sf.sfQueries.materializationCreateAsQuery(opts.Schema.Entity, opts.Schema.Value, opts.Schema.TS, SanitizeSqlLocation(sqlLoc.TableLocation()))
+	if opts.Filter != "" {
+		if _, err := sf.sqlOfflineStore.validateSourceFilter(opts.Schema, opts.Filter); err != nil {
+			logger.Errorw("Invalid materialization filter", "filter", opts.Filter, "error", err)
+			return nil, err
+		}
+	}
+	materializationAsQuery := sf.sfQueries.materializationCreateAsQuery(opts.Schema.Entity, opts.Schema.Value, opts.Schema.TS, SanitizeSqlLocation(sqlLoc.TableLocation()), opts.Filter)
 	if err := resConfig.Validate(); err != nil {
 		logger.Errorw("Failed to validate dynamic table config", "error", err)
 		return nil, err
 	}
-	query := sf.sfQueries.dynamicIcebergTableCreate(tableName, materializationAsQuery, *resConfig)
+	table := qualifiedSnowflakeTable(snowflakeConfig, tableName)
+	query := sf.sfQueries.dynamicIcebergTableCreate(table, materializationAsQuery, *resConfig)
 	logger.Debugw("Creating Dynamic Iceberg Table for materialization", "query", query)
 	if _, err := sf.sqlOfflineStore.db.Exec(query); err != nil {
 		logger.Errorw("Failed to create dynamic iceberg table", "error", err)
@@ -288,7 +410,7 @@ func (sf *snowflakeOfflineStore) CreateMaterialization(id ResourceID, opts Mater
 		id:           MaterializationID(fmt.Sprintf("%s__%s", id.Name, id.Variant)),
 		db:           sf.sqlOfflineStore.db,
 		tableName:    tableName,
-		location:     pl.NewSQLLocation(tableName),
+		location:     pl.NewFullyQualifiedSQLLocation(table.Database, table.Schema, table.Table),
 		query:        sf.sfQueries,
 		providerType: pt.SnowflakeOffline,
 	}, nil
@@ -306,6 +428,12 @@ func (sf *snowflakeOfflineStore) CreateTrainingSet(def TrainingSetDef) error {
 		logger.Errorw("Failed to validate training set definition", "error", err)
 		return err
 	}
+	if err := def.checkSingleLabel(pt.SnowflakeOffline); err != nil {
+		return err
+	}
+	if err := def.checkNotPersisted(pt.SnowflakeOffline); err != nil {
+		return err
+	}
 	var snowflakeConfig pc.SnowflakeConfig
 	if err := snowflakeConfig.Deserialize(sf.sqlOfflineStore.Config()); err != nil {
 		logger.Errorw("Failed to deserialize snowflake config", "error", err)
@@ -330,6 +458,7 @@ func (sf *snowflakeOfflineStore) CreateTrainingSet(def TrainingSetDef) error {
 		logger.Errorw("Failed to build training set query", "error", err)
 		return err
 	}
+	table := qualifiedSnowflakeTable(snowflakeConfig, tableName)
 	var ctaQuery string
 	switch def.Type {
 	case metadata.DynamicTrainingSet:
@@ -337,15 +466,15 @@ func (sf *snowflakeOfflineStore) CreateTrainingSet(def TrainingSetDef) error {
 			logger.Errorw("Failed to validate dynamic table config", "error", err)
 			return err
 		}
-		ctaQuery = sf.sfQueries.dynamicIcebergTableCreate(tableName, tsQuery, *resConfig)
+		ctaQuery = sf.sfQueries.dynamicIcebergTableCreate(table, tsQuery, *resConfig)
 	case metadata.StaticTrainingSet:
 		if err := resConfig.Validate(); err != nil {
 			logger.Errorw("Failed to validate dynamic table config", "error", err)
 			return err
 		}
-		ctaQuery = sf.sfQueries.staticIcebergTableCreate(tableName, tsQuery, *resConfig)
+		ctaQuery = sf.sfQueries.staticIcebergTableCreate(table, tsQuery, *resConfig)
 	case metadata.ViewTrainingSet:
-		ctaQuery = sf.sfQueries.viewCreate(tableName, tsQuery)
+		ctaQuery = sf.sfQueries.viewCreate(table, tsQuery)
 	default:
 		logger.Errorw("Unsupported training set type", "type", def.Type)
 		return fferr.NewInternalErrorf("Unsupported training set type: %v", def.Type)
@@ -427,14 +556,20 @@ func (sf snowflakeOfflineStore) buildTrainingSetQuery(def TrainingSetDef) (strin
 	sf.logger.Debugw("Training set builder params", "params", params)
 
 	queryConfig := tsq.QueryConfig{
-		UseAsOfJoin: true,
-		QuoteChar:   "\"",
-		QuoteTable:  false,
+		UseAsOfJoin:    true,
+		QuoteChar:      "\"",
+		QuoteTable:     false,
+		ShiftTimestamp: snowflakeShiftTimestamp,
 	}
 	ts := tsq.NewTrainingSet(queryConfig, params)
 	return ts.CompileSQL()
 }
 
+// snowflakeShiftTimestamp moves ts forward by delta, for lag features.
+func snowflakeShiftTimestamp(ts string, delta time.Duration) string {
+	return fmt.Sprintf("DATEADD(second, %d, %s)", int64(delta.Seconds()), ts)
+}
+
 func (sf snowflakeOfflineStore) adaptTsDefToBuilderParams(def TrainingSetDef) (tsq.BuilderParams, error) {
 	sanitizeTableNameFn := func(loc pl.Location) (string, error) {
 		lblLoc, isSQLLocation := loc.(*pl.SQLLocation)
@@ -444,5 +579,10 @@ func (sf snowflakeOfflineStore) adaptTsDefToBuilderParams(def TrainingSetDef) (t
 		return SanitizeSnowflakeIdentifier(lblLoc.TableLocation()), nil
 	}
 
-	return def.ToBuilderParams(sf.logger, sanitizeTableNameFn)
+	params, err := def.ToBuilderParams(sf.logger, sanitizeTableNameFn)
+	if err != nil {
+		return tsq.BuilderParams{}, err
+	}
+	params.SplitColumn = def.splitColumn(sf.query.trainingSetSplitBucket, "\"")
+	return params, nil
 }
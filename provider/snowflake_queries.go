@@ -8,10 +8,13 @@
 package provider
 
 import (
+	"database/sql"
 	"fmt"
 	"strings"
 	"time"
 
+	"github.com/featureform/fferr"
+	"github.com/featureform/filestore"
 	"github.com/featureform/metadata"
 	pl "github.com/featureform/provider/location"
 	db "github.com/jackc/pgx/v4"
@@ -51,10 +54,10 @@ func (q snowflakeSQLQueries) materializationDrop(tableName string) string {
 	return fmt.Sprintf("DROP TABLE %s", sanitize(tableName))
 }
 
-func (q snowflakeSQLQueries) dynamicIcebergTableCreate(tableName, query string, config metadata.ResourceSnowflakeConfig) string {
+func (q snowflakeSQLQueries) dynamicIcebergTableCreate(table pl.FullyQualifiedObject, query string, config metadata.ResourceSnowflakeConfig) string {
 	var sb strings.Builder
 
-	sb.WriteString(fmt.Sprintf("CREATE OR REPLACE DYNAMIC ICEBERG TABLE %s ", sanitize(tableName)))
+	sb.WriteString(fmt.Sprintf("CREATE OR REPLACE DYNAMIC ICEBERG TABLE %s ", SanitizeSnowflakeIdentifier(table)))
 
 	if config.DynamicTableConfig.TargetLag != "DOWNSTREAM" {
 		sb.WriteString(fmt.Sprintf("TARGET_LAG = '%s' ", config.DynamicTableConfig.TargetLag))
@@ -73,10 +76,10 @@ func (q snowflakeSQLQueries) dynamicIcebergTableCreate(tableName, query string,
 	return sb.String()
 }
 
-func (q snowflakeSQLQueries) staticIcebergTableCreate(tableName, query string, config metadata.ResourceSnowflakeConfig) string {
+func (q snowflakeSQLQueries) staticIcebergTableCreate(table pl.FullyQualifiedObject, query string, config metadata.ResourceSnowflakeConfig) string {
 	var sb strings.Builder
 
-	sb.WriteString(fmt.Sprintf("CREATE ICEBERG TABLE %s ", sanitize(tableName)))
+	sb.WriteString(fmt.Sprintf("CREATE ICEBERG TABLE %s ", SanitizeSnowflakeIdentifier(table)))
 	sb.WriteString(fmt.Sprintf("EXTERNAL_VOLUME = '%s' ", config.DynamicTableConfig.ExternalVolume))
 	sb.WriteString(CATALOG_CLAUSE)
 	sb.WriteString(fmt.Sprintf("BASE_LOCATION = '%s' ", config.DynamicTableConfig.BaseLocation))
@@ -89,16 +92,18 @@ func (q snowflakeSQLQueries) staticIcebergTableCreate(tableName, query string, c
 	return sb.String()
 }
 
-func (q snowflakeSQLQueries) viewCreate(tableName, query string) string {
+func (q snowflakeSQLQueries) viewCreate(table pl.FullyQualifiedObject, query string) string {
 	var sb strings.Builder
 
-	sb.WriteString(fmt.Sprintf("CREATE VIEW %s ", sanitize(tableName)))
+	sb.WriteString(fmt.Sprintf("CREATE VIEW %s ", SanitizeSnowflakeIdentifier(table)))
 	sb.WriteString(fmt.Sprintf("AS %s", query))
 
 	return sb.String()
 }
 
-func (q snowflakeSQLQueries) materializationCreateAsQuery(entity, value, ts, tableName string) string {
+// materializationCreateAsQuery selects the latest value of each entity. If
+// filter is set, only the source rows that match it are considered.
+func (q snowflakeSQLQueries) materializationCreateAsQuery(entity, value, ts, tableName, filter string) string {
 	var sb strings.Builder
 
 	tsSelectStmt := toIcebergTimestamp(ts)
@@ -108,8 +113,12 @@ func (q snowflakeSQLQueries) materializationCreateAsQuery(entity, value, ts, tab
 		tsOrderByStmt = "ORDER BY ts DESC"
 	}
 
+	source := tableName
+	if filter != "" {
+		source = fmt.Sprintf("%s WHERE %s", tableName, filter)
+	}
 	cteFormat := "WITH OrderedSource AS (SELECT IDENTIFIER('%s') AS entity, IDENTIFIER('%s') AS value, %s, ROW_NUMBER() OVER (PARTITION BY IDENTIFIER('%s') %s) AS rn FROM %s) "
-	cteClause := fmt.Sprintf(cteFormat, entity, value, tsSelectStmt, entity, tsOrderByStmt, tableName)
+	cteClause := fmt.Sprintf(cteFormat, entity, value, tsSelectStmt, entity, tsOrderByStmt, source)
 	sb.WriteString(cteClause)
 	sb.WriteString("SELECT entity, value, ts, ROW_NUMBER() OVER (ORDER BY (entity)) AS row_number FROM OrderedSource WHERE rn = 1")
 
@@ -146,3 +155,9 @@ func toIcebergTimestamp(tsCol string) string {
 		return fmt.Sprintf("to_timestamp_ntz('%s', 'YYYY-DD-MM HH24:MI:SS +0000 UTC')::TIMESTAMP_NTZ(6) AS ts ", time.UnixMilli(0).UTC())
 	}
 }
+
+// trainingSetExport isn't supported since unloading to a file store needs an
+// external stage, which the Snowflake provider doesn't configure.
+func (q snowflakeSQLQueries) trainingSetExport(db *sql.DB, tableName string, location pl.Location, format filestore.FileType) ([]filestore.Filepath, error) {
+	return nil, fferr.NewUnimplementedErrorf("training set export is not supported for Snowflake")
+}
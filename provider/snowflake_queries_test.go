@@ -8,9 +8,12 @@
 package provider
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/featureform/metadata"
+	pl "github.com/featureform/provider/location"
+	pc "github.com/featureform/provider/provider_config"
 )
 
 func TestGenericInsertQuery(t *testing.T) {
@@ -43,14 +46,14 @@ func TestGenericInsertQuery(t *testing.T) {
 func TestSnowflakeDynamicIcebergTableQuery(t *testing.T) {
 	tests := []struct {
 		name     string
-		table    string
+		table    pl.FullyQualifiedObject
 		query    string
 		config   metadata.ResourceSnowflakeConfig
 		expected string
 	}{
 		{
 			name:  "Test Dynamic Iceberg Table Query",
-			table: "test_table",
+			table: pl.FullyQualifiedObject{Table: "test_table"},
 			query: "SELECT * FROM raw_table",
 			config: metadata.ResourceSnowflakeConfig{
 				DynamicTableConfig: &metadata.SnowflakeDynamicTableConfig{
@@ -64,6 +67,22 @@ func TestSnowflakeDynamicIcebergTableQuery(t *testing.T) {
 			},
 			expected: "CREATE OR REPLACE DYNAMIC ICEBERG TABLE \"test_table\" TARGET_LAG = '1 hours' WAREHOUSE = 'my_warehouse' EXTERNAL_VOLUME = 's3://my-bucket' CATALOG = 'SNOWFLAKE' BASE_LOCATION = '/base' REFRESH_MODE = AUTO INITIALIZE = ON_CREATE AS SELECT * FROM raw_table",
 		},
+		{
+			name:  "Test Fully Qualified Dynamic Iceberg Table Query",
+			table: pl.FullyQualifiedObject{Database: "my_db", Schema: "my_schema", Table: "test_table"},
+			query: "SELECT * FROM raw_table",
+			config: metadata.ResourceSnowflakeConfig{
+				DynamicTableConfig: &metadata.SnowflakeDynamicTableConfig{
+					ExternalVolume: "s3://my-bucket",
+					BaseLocation:   "/base",
+					TargetLag:      "DOWNSTREAM",
+					RefreshMode:    metadata.AutoRefresh,
+					Initialize:     metadata.InitializeOnCreate,
+				},
+				Warehouse: "project_warehouse",
+			},
+			expected: "CREATE OR REPLACE DYNAMIC ICEBERG TABLE \"my_db\".\"my_schema\".\"test_table\" TARGET_LAG = DOWNSTREAM WAREHOUSE = 'project_warehouse' EXTERNAL_VOLUME = 's3://my-bucket' CATALOG = 'SNOWFLAKE' BASE_LOCATION = '/base' REFRESH_MODE = AUTO INITIALIZE = ON_CREATE AS SELECT * FROM raw_table",
+		},
 	}
 
 	for _, tt := range tests {
@@ -78,3 +97,35 @@ func TestSnowflakeDynamicIcebergTableQuery(t *testing.T) {
 	}
 
 }
+
+func TestQualifiedSnowflakeTable(t *testing.T) {
+	tests := []struct {
+		name     string
+		config   pc.SnowflakeConfig
+		expected string
+	}{
+		{"Database And Schema", pc.SnowflakeConfig{Database: "my_db", Schema: "my_schema"}, `"MY_DB"."MY_SCHEMA"."test_table"`},
+		{"Default Schema", pc.SnowflakeConfig{Database: "my_db"}, `"MY_DB"."PUBLIC"."test_table"`},
+		{"Case Sensitive Names", pc.SnowflakeConfig{Database: "my-db", Schema: "My Schema"}, `"my-db"."My Schema"."test_table"`},
+		{"No Database", pc.SnowflakeConfig{}, `"test_table"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			actual := SanitizeSnowflakeIdentifier(qualifiedSnowflakeTable(tt.config, "test_table"))
+			if actual != tt.expected {
+				t.Errorf("Expected %v, but instead found %v", tt.expected, actual)
+			}
+		})
+	}
+}
+
+func TestSnowflakeMaterializationCreateAsQueryFilter(t *testing.T) {
+	query := snowflakeSQLQueries{}.materializationCreateAsQuery("entity", "value", "ts", `"source"`, "")
+	if strings.Contains(query, "WHERE status") {
+		t.Errorf("Expected no filter, but instead found %v", query)
+	}
+	query = snowflakeSQLQueries{}.materializationCreateAsQuery("entity", "value", "ts", `"source"`, "status = 'active'")
+	if !strings.Contains(query, `FROM "source" WHERE status = 'active') `) {
+		t.Errorf("Expected the source to be filtered, but instead found %v", query)
+	}
+}
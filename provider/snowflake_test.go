@@ -9,8 +9,13 @@ package provider
 
 import (
 	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
 	"database/sql"
+	"database/sql/driver"
 	"encoding/json"
+	"encoding/pem"
 	"fmt"
 	"github.com/featureform/fferr"
 	"os"
@@ -19,6 +24,8 @@ import (
 	"testing"
 	"time"
 
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/featureform/logging"
 	"github.com/featureform/metadata"
 	"github.com/featureform/provider/location"
 	pl "github.com/featureform/provider/location"
@@ -28,7 +35,9 @@ import (
 	"github.com/featureform/provider/types"
 	"github.com/google/uuid"
 	"github.com/joho/godotenv"
+	"github.com/snowflakedb/gosnowflake"
 	"github.com/stretchr/testify/assert"
+	"github.com/youmark/pkcs8"
 )
 
 const (
@@ -502,6 +511,73 @@ func TestSnowflakeConfigConnectionString(t *testing.T) {
 	}
 }
 
+func TestSnowflakeConfigKeyPairConnectionString(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("could not generate key: %v", err)
+	}
+	pkcs8Key, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatalf("could not marshal key: %v", err)
+	}
+	encryptedKey, err := pkcs8.ConvertPrivateKeyToPKCS8(key, []byte("passphrase"))
+	if err != nil {
+		t.Fatalf("could not encrypt key: %v", err)
+	}
+	pemKey := func(blockType string, bytes []byte) string {
+		return string(pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: bytes}))
+	}
+	tests := []struct {
+		name       string
+		privateKey string
+		passphrase string
+		wantErr    bool
+	}{
+		{"PKCS8", pemKey("PRIVATE KEY", pkcs8Key), "", false},
+		{"PKCS1", pemKey("RSA PRIVATE KEY", x509.MarshalPKCS1PrivateKey(key)), "", false},
+		{"Encrypted", pemKey("ENCRYPTED PRIVATE KEY", encryptedKey), "passphrase", false},
+		{"Encrypted Without Passphrase", pemKey("ENCRYPTED PRIVATE KEY", encryptedKey), "", true},
+		{"Wrong Passphrase", pemKey("ENCRYPTED PRIVATE KEY", encryptedKey), "wrong", true},
+		{"Not PEM", "not a key", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sf := &pc.SnowflakeConfig{
+				Username:             "u",
+				Password:             "p",
+				PrivateKey:           tt.privateKey,
+				PrivateKeyPassphrase: tt.passphrase,
+				Account:              "account",
+				Organization:         "org",
+				Role:                 "myrole",
+			}
+			dsn, err := sf.ConnectionString("d", "s")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ConnectionString() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			cfg, err := gosnowflake.ParseDSN(dsn)
+			if err != nil {
+				t.Fatalf("could not parse DSN: %v", err)
+			}
+			if cfg.Authenticator != gosnowflake.AuthTypeJwt {
+				t.Errorf("expected JWT authenticator, got %s", cfg.Authenticator)
+			}
+			if cfg.Password != "" {
+				t.Errorf("expected the password to be left out of the DSN")
+			}
+			if cfg.PrivateKey == nil || !cfg.PrivateKey.Equal(key) {
+				t.Errorf("DSN private key doesn't match the configured key")
+			}
+			if cfg.Role != "myrole" || cfg.Database != "d" || cfg.Schema != "s" {
+				t.Errorf("unexpected DSN config: role %s, database %s, schema %s", cfg.Role, cfg.Database, cfg.Schema)
+			}
+		})
+	}
+}
+
 func TestSnowflakeDeserializeCurrentCredentials(t *testing.T) {
 	expected := pc.SnowflakeConfig{
 		Username:      "username",
@@ -1240,3 +1316,77 @@ func getConfiguredSnowflakeTester(t *testing.T, useCrossDBJoins bool) offlineSql
 		sanitizeTableName:   func(obj pl.FullyQualifiedObject) string { return SanitizeSnowflakeIdentifier(obj) },
 	}
 }
+
+func TestSnowflakeGetResourceStats(t *testing.T) {
+	maxTS := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	config := pc.SnowflakeConfig{Database: "db", Schema: "features"}
+	materialization := pl.FullyQualifiedObject{Database: "DB", Schema: "FEATURES", Table: "featureform_materialization_wave_power__default"}
+	trainingSet := pl.FullyQualifiedObject{Database: "DB", Schema: "FEATURES", Table: "featureform_trainingset__waves__default"}
+	tests := map[string]struct {
+		id       ResourceID
+		columns  []string
+		values   []driver.Value
+		expected string
+		stats    ResourceStats
+	}{
+		"Feature": {
+			ResourceID{"wave_power", "default", Feature},
+			[]string{"count", "max"},
+			[]driver.Value{int64(3), maxTS},
+			fmt.Sprintf("SELECT COUNT(*), MAX(ts) FROM %s", SanitizeSqlLocation(materialization)),
+			ResourceStats{RowCount: 3, MaxTimestamp: maxTS},
+		},
+		"TrainingSet": {
+			ResourceID{"waves", "default", TrainingSet},
+			[]string{"count"},
+			[]driver.Value{int64(7)},
+			fmt.Sprintf("SELECT COUNT(*) FROM %s", SanitizeSqlLocation(trainingSet)),
+			ResourceStats{RowCount: 7},
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			var query string
+			capture := sqlmock.QueryMatcherFunc(func(_, actual string) error {
+				query = actual
+				return nil
+			})
+			db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(capture))
+			if err != nil {
+				t.Fatalf("could not create mock db: %v", err)
+			}
+			defer db.Close()
+			store := &snowflakeOfflineStore{
+				sqlOfflineStore: &sqlOfflineStore{
+					db:           db,
+					query:        &snowflakeSQLQueries{},
+					BaseProvider: BaseProvider{ProviderType: pt.SnowflakeOffline, ProviderConfig: config.Serialize()},
+				},
+				logger: logging.NewTestLogger(t),
+			}
+			mock.ExpectQuery("SELECT").WillReturnRows(sqlmock.NewRows(tt.columns).AddRow(tt.values...))
+			stats, err := store.GetResourceStats(tt.id, nil)
+			if err != nil {
+				t.Fatalf("could not get resource stats: %v", err)
+			}
+			if query != tt.expected {
+				t.Fatalf("expected query %q, got %q", tt.expected, query)
+			}
+			if !reflect.DeepEqual(tt.stats, stats) {
+				t.Fatalf("expected stats %v, got %v", tt.stats, stats)
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Fatalf("unmet expectations: %v", err)
+			}
+		})
+	}
+	store := &snowflakeOfflineStore{
+		sqlOfflineStore: &sqlOfflineStore{BaseProvider: BaseProvider{ProviderConfig: config.Serialize()}},
+		logger:          logging.NewTestLogger(t),
+	}
+	if _, err := store.GetResourceStats(ResourceID{"wave_height", "default", Label}, nil); err == nil {
+		t.Fatalf("expected an error for a label")
+	} else if _, ok := err.(*fferr.UnimplementedError); !ok {
+		t.Fatalf("expected an UnimplementedError for a label, got: %v", err)
+	}
+}
@@ -0,0 +1,230 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+//
+// Copyright 2024 FeatureForm Inc.
+//
+
+package provider
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/featureform/fferr"
+	"github.com/featureform/filestore"
+	pl "github.com/featureform/provider/location"
+	pt "github.com/featureform/provider/provider_type"
+	"github.com/featureform/provider/types"
+)
+
+// SourceAccess is how a transformation on one offline store reads a source
+// registered on another.
+type SourceAccess string
+
+const (
+	// NativeSourceAccess sources are read in place by the transformation's store.
+	NativeSourceAccess SourceAccess = "native"
+	// ExportedSourceAccess sources are copied into the transformation's store
+	// with ExportSourceTable before the transformation runs.
+	ExportedSourceAccess SourceAccess = "exported"
+)
+
+// nativeSourceProviders maps an offline store to the other offline stores whose
+// sources its transformations can read in place. Spark reads Snowflake tables
+// through its Snowflake connector.
+var nativeSourceProviders = map[pt.Type][]pt.Type{
+	pt.SparkOffline: {pt.SnowflakeOffline},
+}
+
+// exportedSourceProviders maps an offline store to the other offline stores
+// whose sources are copied into it before its transformations run. Only
+// filestore backed stores are exported since their files can be read without
+// running a job.
+var exportedSourceProviders = map[pt.Type][]pt.Type{
+	pt.SnowflakeOffline:     {pt.SparkOffline, pt.K8sOffline},
+	pt.PostgresOffline:      {pt.SparkOffline, pt.K8sOffline},
+	pt.RedshiftOffline:      {pt.SparkOffline, pt.K8sOffline},
+	pt.BigQueryOffline:      {pt.SparkOffline, pt.K8sOffline},
+	pt.ClickHouseOffline:    {pt.SparkOffline, pt.K8sOffline},
+	pt.DatabricksSQLOffline: {pt.SparkOffline, pt.K8sOffline},
+}
+
+// SourceFileImporter is implemented by offline stores that can load files from
+// a file store into one of their tables themselves, so ExportSourceTable copies
+// a source store-to-store rather than streaming its rows through the caller.
+type SourceFileImporter interface {
+	// ImportSourceFiles loads the Parquet files into the existing primary table
+	// for id. It returns an UnimplementedError if the store can't read the files.
+	ImportSourceFiles(id ResourceID, files []filestore.Filepath) error
+}
+
+// sourceExportBatchSize is the number of rows written to the target store at a
+// time. The first batch is also used to infer the copy's column types.
+const sourceExportBatchSize = 1000
+
+// GetSourceAccess returns how a transformation on the target store reads a
+// source registered on a provider of the source type. Sources on the same type
+// of provider are always read in place.
+func GetSourceAccess(target, source pt.Type) (SourceAccess, error) {
+	if target == source {
+		return NativeSourceAccess, nil
+	}
+	for _, native := range nativeSourceProviders[target] {
+		if native == source {
+			return NativeSourceAccess, nil
+		}
+	}
+	for _, exported := range exportedSourceProviders[target] {
+		if exported == source {
+			return ExportedSourceAccess, nil
+		}
+	}
+	return "", fferr.NewInvalidArgumentErrorf(
+		"%s transformations can't read sources registered on %s providers; register the source on %s or on one of %v",
+		target, source, target, append(nativeSourceProviders[target], exportedSourceProviders[target]...),
+	)
+}
+
+// ExportSourceTable copies the rows of table, which lives on another provider,
+// into a primary table in store and returns the location of the copy. Source
+// variants are immutable, so an existing copy is reused unless replace is set,
+// in which case it's deleted and written again. If store is a SourceFileImporter
+// that can read table's files, it loads them itself; otherwise the rows are
+// written in batches.
+func ExportSourceTable(store OfflineStore, id ResourceID, table PrimaryTable, replace bool) (pl.Location, error) {
+	tableName, err := GetPrimaryTableName(id)
+	if err != nil {
+		return nil, err
+	}
+	location := pl.NewSQLLocation(tableName)
+	iter, err := table.IterateSegment(math.MaxInt64)
+	if err != nil {
+		return nil, err
+	}
+	defer iter.Close()
+	batch, err := nextExportBatch(iter)
+	if err != nil {
+		return nil, err
+	}
+	schema := TableSchema{Columns: exportedColumns(iter.Columns(), batch)}
+	copied, err := store.CreatePrimaryTable(id, schema)
+	var exists *fferr.DatasetAlreadyExistsError
+	if errors.As(err, &exists) {
+		if !replace {
+			return location, nil
+		}
+		if err := store.Delete(location); err != nil {
+			return nil, err
+		}
+		copied, err = store.CreatePrimaryTable(id, schema)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if imported, err := importSourceFiles(store, id, table); err != nil {
+		return nil, err
+	} else if imported {
+		return location, nil
+	}
+	for len(batch) > 0 {
+		if err := copied.WriteBatch(exportedRecords(schema, batch)); err != nil {
+			return nil, fferr.NewResourceExecutionError(store.Type().String(), id.Name, id.Variant, fferr.ResourceType(id.Type.String()), err)
+		}
+		if batch, err = nextExportBatch(iter); err != nil {
+			return nil, err
+		}
+	}
+	return location, nil
+}
+
+// importSourceFiles has store load table's files into the copy for id. It
+// returns false if the rows have to be written by the caller instead.
+func importSourceFiles(store OfflineStore, id ResourceID, table PrimaryTable) (bool, error) {
+	importer, ok := store.(SourceFileImporter)
+	if !ok {
+		return false, nil
+	}
+	fileTable, ok := table.(*FileStorePrimaryTable)
+	if !ok {
+		return false, nil
+	}
+	files, err := fileTable.sourceFiles()
+	if err != nil {
+		return false, err
+	}
+	if len(files) == 0 || files[0].Ext() != filestore.Parquet {
+		return false, nil
+	}
+	err = importer.ImportSourceFiles(id, files)
+	var unimplemented *fferr.UnimplementedError
+	if errors.As(err, &unimplemented) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fferr.NewResourceExecutionError(store.Type().String(), id.Name, id.Variant, fferr.ResourceType(id.Type.String()), err)
+	}
+	return true, nil
+}
+
+func nextExportBatch(iter GenericTableIterator) ([]GenericRecord, error) {
+	batch := make([]GenericRecord, 0, sourceExportBatchSize)
+	for len(batch) < sourceExportBatchSize && iter.Next() {
+		batch = append(batch, iter.Values())
+	}
+	return batch, iter.Err()
+}
+
+// exportedColumns infers the type of each column from its values in rows.
+// Columns without a value, or whose values don't share a type, are strings.
+func exportedColumns(names []string, rows []GenericRecord) []TableColumn {
+	columns := make([]TableColumn, len(names))
+	for i, name := range names {
+		var valueType types.ValueType = types.NilType
+		for _, row := range rows {
+			if i < len(row) {
+				valueType = mergeDetectedTypes(valueType, exportedValueType(row[i]))
+			}
+		}
+		if valueType == types.NilType {
+			valueType = types.String
+		}
+		columns[i] = TableColumn{Name: name, ValueType: valueType}
+	}
+	return columns
+}
+
+func exportedValueType(value interface{}) types.ValueType {
+	switch value.(type) {
+	case nil:
+		return types.NilType
+	case int, int8, int16, int32, int64, uint8, uint16, uint32:
+		return types.Int64
+	case float32, float64:
+		return types.Float64
+	case bool:
+		return types.Bool
+	case time.Time:
+		return types.Timestamp
+	default:
+		return types.String
+	}
+}
+
+// exportedRecords formats the values of string columns that were inferred from
+// mixed types so they can be written to the copy.
+func exportedRecords(schema TableSchema, rows []GenericRecord) []GenericRecord {
+	for _, row := range rows {
+		for i, column := range schema.Columns {
+			if i >= len(row) || row[i] == nil || column.ValueType != types.String {
+				continue
+			}
+			if _, ok := row[i].(string); !ok {
+				row[i] = fmt.Sprint(row[i])
+			}
+		}
+	}
+	return rows
+}
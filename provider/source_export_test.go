@@ -0,0 +1,197 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+//
+// Copyright 2024 FeatureForm Inc.
+//
+
+package provider
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/featureform/fferr"
+	"github.com/featureform/filestore"
+	pl "github.com/featureform/provider/location"
+	pt "github.com/featureform/provider/provider_type"
+	"github.com/featureform/provider/types"
+	"github.com/parquet-go/parquet-go"
+)
+
+func TestGetSourceAccess(t *testing.T) {
+	tests := map[string]struct {
+		target, source pt.Type
+		expected       SourceAccess
+	}{
+		"Same Provider":         {pt.PostgresOffline, pt.PostgresOffline, NativeSourceAccess},
+		"Spark Reads Snowflake": {pt.SparkOffline, pt.SnowflakeOffline, NativeSourceAccess},
+		"Snowflake Reads Spark": {pt.SnowflakeOffline, pt.SparkOffline, ExportedSourceAccess},
+		"BigQuery Reads K8s":    {pt.BigQueryOffline, pt.K8sOffline, ExportedSourceAccess},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			access, err := GetSourceAccess(test.target, test.source)
+			if err != nil {
+				t.Fatalf("Failed to get source access: %v", err)
+			}
+			if access != test.expected {
+				t.Fatalf("Expected %s access, got %s", test.expected, access)
+			}
+		})
+	}
+	_, err := GetSourceAccess(pt.PostgresOffline, pt.SnowflakeOffline)
+	if _, ok := err.(*fferr.InvalidArgumentError); !ok {
+		t.Fatalf("Expected an InvalidArgumentError for incompatible providers, got: %v", err)
+	}
+}
+
+type exportTestStore struct {
+	MockUnitTestOfflineStore
+	schemas  []TableSchema
+	written  []GenericRecord
+	exists   bool
+	deleted  []pl.Location
+	imported []filestore.Filepath
+	noImport bool
+}
+
+func (store *exportTestStore) CreatePrimaryTable(id ResourceID, schema TableSchema) (PrimaryTable, error) {
+	if store.exists {
+		return nil, fferr.NewDatasetAlreadyExistsError(id.Name, id.Variant, nil)
+	}
+	store.exists = true
+	store.schemas = append(store.schemas, schema)
+	return &exportTestTable{store: store}, nil
+}
+
+func (store *exportTestStore) Delete(location pl.Location) error {
+	store.exists = false
+	store.deleted = append(store.deleted, location)
+	return nil
+}
+
+func (store *exportTestStore) ImportSourceFiles(id ResourceID, files []filestore.Filepath) error {
+	if store.noImport {
+		return fferr.NewUnimplementedErrorf("source import is not supported")
+	}
+	store.imported = append(store.imported, files...)
+	return nil
+}
+
+type exportTestTable struct {
+	MockPrimaryTable
+	store *exportTestStore
+}
+
+func (table *exportTestTable) WriteBatch(records []GenericRecord) error {
+	table.store.written = append(table.store.written, records...)
+	return nil
+}
+
+func TestExportSourceTable(t *testing.T) {
+	store := &exportTestStore{}
+	id := ResourceID{Name: "transactions", Variant: "default", Type: Primary}
+	location, err := ExportSourceTable(store, id, MockPrimaryTable{}, false)
+	if err != nil {
+		t.Fatalf("Failed to export source table: %v", err)
+	}
+	tableName, err := GetPrimaryTableName(id)
+	if err != nil {
+		t.Fatalf("Failed to get primary table name: %v", err)
+	}
+	if location.Location() != pl.NewSQLLocation(tableName).Location() {
+		t.Fatalf("Expected copy at %s, got %s", tableName, location.Location())
+	}
+	expectedSchema := TableSchema{Columns: []TableColumn{
+		{Name: "column1", ValueType: types.String},
+		{Name: "column2", ValueType: types.Bool},
+		{Name: "column3", ValueType: types.Int64},
+	}}
+	if !reflect.DeepEqual(store.schemas, []TableSchema{expectedSchema}) {
+		t.Fatalf("Expected schema %v, got %v", expectedSchema, store.schemas)
+	}
+	if len(store.written) != 3 {
+		t.Fatalf("Expected 3 rows to be copied, got %d", len(store.written))
+	}
+
+	if _, err := ExportSourceTable(store, id, MockPrimaryTable{}, false); err != nil {
+		t.Fatalf("Failed to reuse exported source table: %v", err)
+	}
+	if len(store.schemas) != 1 || len(store.deleted) != 0 {
+		t.Fatalf("Expected the existing copy to be reused")
+	}
+
+	if _, err := ExportSourceTable(store, id, MockPrimaryTable{}, true); err != nil {
+		t.Fatalf("Failed to replace exported source table: %v", err)
+	}
+	if len(store.schemas) != 2 || len(store.deleted) != 1 || len(store.written) != 6 {
+		t.Fatalf("Expected the copy to be replaced, got %d creates, %d deletes and %d rows", len(store.schemas), len(store.deleted), len(store.written))
+	}
+}
+
+func TestExportSourceTableImport(t *testing.T) {
+	fileStore, err := NewLocalFileStore([]byte(fmt.Sprintf(`{"DirPath": "file://%s/"}`, t.TempDir())))
+	if err != nil {
+		t.Fatalf("could not create local file store: %v", err)
+	}
+	type transaction struct {
+		User   string  `parquet:"user"`
+		Amount float64 `parquet:"amount"`
+	}
+	buf := new(bytes.Buffer)
+	if err := parquet.Write(buf, []transaction{{User: "alice", Amount: 1.5}, {User: "bob", Amount: 2}}); err != nil {
+		t.Fatalf("could not write parquet: %v", err)
+	}
+	path, err := fileStore.CreateFilePath("transactions.parquet", false)
+	if err != nil {
+		t.Fatalf("could not create file path: %v", err)
+	}
+	if err := fileStore.Write(path, buf.Bytes()); err != nil {
+		t.Fatalf("could not write parquet: %v", err)
+	}
+	id := ResourceID{Name: "transactions", Variant: "default", Type: Primary}
+	table := &FileStorePrimaryTable{store: fileStore, source: path, id: id}
+
+	store := &exportTestStore{}
+	if _, err := ExportSourceTable(store, id, table, false); err != nil {
+		t.Fatalf("Failed to export source table: %v", err)
+	}
+	if len(store.imported) != 1 || store.imported[0].ToURI() != path.ToURI() {
+		t.Fatalf("Expected %s to be imported, got %v", path.ToURI(), store.imported)
+	}
+	if len(store.schemas) != 1 || len(store.written) != 0 {
+		t.Fatalf("Expected the copy to be created and loaded by the store, got %d creates and %d rows written", len(store.schemas), len(store.written))
+	}
+
+	store = &exportTestStore{noImport: true}
+	if _, err := ExportSourceTable(store, id, table, false); err != nil {
+		t.Fatalf("Failed to export source table: %v", err)
+	}
+	if len(store.written) != 2 {
+		t.Fatalf("Expected 2 rows to be copied when the store can't import, got %d", len(store.written))
+	}
+}
+
+func TestExportedColumns(t *testing.T) {
+	rows := []GenericRecord{
+		{int32(1), 1.5, nil, "a"},
+		{2.5, int64(2), nil, 3},
+	}
+	expected := []TableColumn{
+		{Name: "a", ValueType: types.Float64},
+		{Name: "b", ValueType: types.Float64},
+		{Name: "c", ValueType: types.String},
+		{Name: "d", ValueType: types.String},
+	}
+	columns := exportedColumns([]string{"a", "b", "c", "d"}, rows)
+	if !reflect.DeepEqual(columns, expected) {
+		t.Fatalf("Expected columns %v, got %v", expected, columns)
+	}
+	records := exportedRecords(TableSchema{Columns: columns}, rows)
+	if records[1][3] != "3" {
+		t.Fatalf("Expected mixed string column to be formatted, got %#v", records[1][3])
+	}
+}
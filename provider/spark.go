@@ -12,6 +12,7 @@ import (
 	"context"
 	"encoding/csv"
 	"fmt"
+	"io"
 	"os"
 	"strings"
 	"time"
@@ -20,6 +21,7 @@ import (
 	"github.com/featureform/config"
 	"github.com/featureform/fferr"
 	"github.com/featureform/filestore"
+	"github.com/featureform/helpers/tracing"
 	"github.com/featureform/logging"
 	"github.com/featureform/metadata"
 	pl "github.com/featureform/provider/location"
@@ -29,7 +31,9 @@ import (
 	sparklib "github.com/featureform/provider/spark"
 	"github.com/featureform/provider/types"
 	"github.com/google/uuid"
+	"github.com/parquet-go/parquet-go"
 	"github.com/pkg/errors"
+	"go.opentelemetry.io/otel/attribute"
 	"golang.org/x/exp/slices"
 )
 
@@ -46,18 +50,21 @@ type SparkExecutorConfig interface {
 }
 
 type PythonOfflineQueries interface {
-	materializationCreate(schema ResourceSchema) string
-	trainingSetCreate(def TrainingSetDef, featureSchemas []ResourceSchema, labelSchema ResourceSchema) string
+	materializationCreate(schema ResourceSchema, filter string) string
+	trainingSetCreate(def TrainingSetDef, featureSchemas []ResourceSchema, labelSchemas []ResourceSchema) string
 }
 
 type defaultPythonOfflineQueries struct {
 	Logger logging.Logger
 }
 
-func (q defaultPythonOfflineQueries) materializationCreate(schema ResourceSchema) (string, error) {
+func (q defaultPythonOfflineQueries) materializationCreate(schema ResourceSchema, filter string) (string, error) {
 	logger := q.Logger.With("schema", schema)
 	logger.Debug("Creating materialization query for schema")
-	timestampColumn := schema.TS
+	source := "source_0"
+	if filter != "" {
+		source = fmt.Sprintf("(SELECT * FROM source_0 WHERE %s)", filter)
+	}
 	if schema.TS == "" {
 		q.Logger.Debug("Creating materialization query without timestamp")
 		path := config.GetMaterializeNoTimestampQueryPath()
@@ -67,26 +74,62 @@ func (q defaultPythonOfflineQueries) materializationCreate(schema ResourceSchema
 			q.Logger.Errorw("Failed to read query template from path", "path", path)
 			return "", err
 		}
-		query := fmt.Sprintf(string(data), schema.Entity, schema.Value, schema.Entity)
+		entity, value, err := q.castColumns(schema)
+		if err != nil {
+			return "", err
+		}
+		query := fmt.Sprintf(string(data), entity, value, schema.Entity, source)
 		q.Logger.Debugw("Created query without TS", "query", query)
 		return query, nil
 	}
 	q.Logger.Debug("Creating materialization query with timestamp")
+	return q.materializationWithTimestamp(schema, source)
+}
+
+// incrementalMaterializationCreate builds a materialization query that only
+// reads the source rows newer than watermark. The latest of those rows per
+// entity is kept, so out-of-order rows within the delta are still resolved by
+// timestamp.
+func (q defaultPythonOfflineQueries) incrementalMaterializationCreate(schema ResourceSchema, watermark time.Time, filter string) (string, error) {
+	if schema.TS == "" {
+		return "", fferr.NewInvalidArgumentErrorf("incremental materialization requires a timestamp column")
+	}
+	predicate := fmt.Sprintf("%s > timestamp_micros(%d)", schema.parseTimestamp(schema.TS, sparkParseTimestamp), watermark.UnixMicro())
+	if filter != "" {
+		predicate = fmt.Sprintf("%s AND (%s)", predicate, filter)
+	}
+	source := fmt.Sprintf("(SELECT * FROM source_0 WHERE %s)", predicate)
+	q.Logger.Debugw("Creating incremental materialization query", "watermark", watermark)
+	return q.materializationWithTimestamp(schema, source)
+}
+
+func (q defaultPythonOfflineQueries) materializationWithTimestamp(schema ResourceSchema, source string) (string, error) {
 	path := config.GetMaterializeWithTimestampQueryPath()
 	data, err := os.ReadFile(path)
 	if err != nil {
 		q.Logger.Errorw("Failed to read SQL format from path", "path", path, "err", err)
 		return "", err
 	}
+	entity, value, err := q.castColumns(schema)
+	if err != nil {
+		return "", err
+	}
+	ts := schema.TS
+	if schema.TSFormat != nil {
+		// The query compares the timestamp column across rows, so it's parsed
+		// once into its own column.
+		source = fmt.Sprintf("(SELECT *, %s AS %s FROM %s)", schema.parseTimestamp(schema.TS, sparkParseTimestamp), sparkParsedTSColumn, source)
+		ts = sparkParsedTSColumn
+	}
 	query := fmt.Sprintf(
 		string(data),
-		schema.Entity,
-		schema.Value,
-		timestampColumn,
-		"source_0",
-		timestampColumn,
-		timestampColumn,
-		"source_0",
+		entity,
+		value,
+		ts,
+		source,
+		ts,
+		ts,
+		source,
 		schema.Entity,
 		schema.Entity,
 	)
@@ -94,17 +137,98 @@ func (q defaultPythonOfflineQueries) materializationCreate(schema ResourceSchema
 	return query, nil
 }
 
+// partitionMaterialization adds the partition column to a materialization query's
+// output. Spark moves the column into the output's directory names, so the written
+// files keep the usual entity, value, and ts columns.
+func (q defaultPythonOfflineQueries) partitionMaterialization(query string, partition MaterializationPartition) (string, error) {
+	var expr string
+	switch partition.Type {
+	case PartitionByDate:
+		expr = "to_date(ts)"
+	case PartitionByEntityBucket:
+		expr = fmt.Sprintf("pmod(hash(entity), %d)", partition.Buckets)
+	default:
+		return "", fferr.NewInvalidArgumentErrorf("unknown materialization partition type %s", partition.Type)
+	}
+	query = strings.TrimSuffix(strings.TrimSpace(query), ";")
+	return fmt.Sprintf("SELECT *, %s AS %s FROM (%s) materialized", expr, partition.Column(), query), nil
+}
+
+// castColumns returns the expressions that select the schema's entity and value
+// columns with its casts applied.
+func (q defaultPythonOfflineQueries) castColumns(schema ResourceSchema) (string, string, error) {
+	entity, err := schema.castColumn(schema.Entity, schema.Entity, sparkColumnType)
+	if err != nil {
+		return "", "", err
+	}
+	value, err := schema.castColumn(schema.Value, schema.Value, sparkColumnType)
+	if err != nil {
+		return "", "", err
+	}
+	return entity, value, nil
+}
+
+// sparkColumnType is the Spark SQL name of a type that a column can be cast to.
+func sparkColumnType(valueType types.ValueType) (string, error) {
+	switch valueType {
+	case types.Int, types.Int32:
+		return "INT", nil
+	case types.Int64:
+		return "BIGINT", nil
+	case types.Float32:
+		return "FLOAT", nil
+	case types.Float64:
+		return "DOUBLE", nil
+	case types.String:
+		return "STRING", nil
+	case types.Bool:
+		return "BOOLEAN", nil
+	default:
+		return "", fferr.NewDataTypeNotFoundErrorf(valueType, "could not determine spark column type")
+	}
+}
+
+// isoOffsetPattern matches the time and UTC offset at the end of an ISO-8601
+// timestamp. Dates and timestamps without an offset don't match.
+const isoOffsetPattern = `[T ][0-9:.]+(Z|[+-][0-9]{2}(:?[0-9]{2})?)$`
+
+// sparkParseTimestamp is the Spark SQL expression that parses expr with format.
+func sparkParseTimestamp(expr string, format TimestampFormat) string {
+	switch format.Encoding {
+	case EpochSeconds:
+		return fmt.Sprintf("timestamp_seconds(CAST(%s AS DOUBLE))", expr)
+	case EpochMillis:
+		return fmt.Sprintf("timestamp_millis(CAST(%s AS BIGINT))", expr)
+	default:
+		// to_timestamp reads strings without an offset in the session's zone, so
+		// they're shifted from there to the format's zone.
+		return fmt.Sprintf(
+			"CASE WHEN %s RLIKE '%s' THEN to_timestamp(%s) "+
+				"ELSE from_utc_timestamp(to_utc_timestamp(to_timestamp(%s), '%s'), current_timezone()) END",
+			expr, isoOffsetPattern, expr, expr, format.timezone(),
+		)
+	}
+}
+
+// sparkParsedTSColumn is the column that a parsed timestamp is added to the
+// source as, so that materialization queries can refer to it by name.
+const sparkParsedTSColumn = "__ff_parsed_ts"
+
 // Spark SQL _seems_ to have some issues with double quotes in column names based on troubleshooting
 // the offline tests. Given this, we will use backticks to quote column names in the queries.
 func createQuotedIdentifier(id ResourceID) string {
 	return fmt.Sprintf("`%s__%s__%s`", id.Type, id.Name, id.Variant)
 }
 
+// trainingSetCreate builds the query for a training set. The first label is
+// source_0 and the features follow it; any other labels come after the features
+// and are matched to the first label's rows on entity and timestamp.
 func (q defaultPythonOfflineQueries) trainingSetCreate(
 	def TrainingSetDef,
 	featureSchemas []ResourceSchema,
-	labelSchema ResourceSchema,
+	labelSchemas []ResourceSchema,
 ) string {
+	labelSchema := labelSchemas[0]
 	columns := make([]string, 0)
 	joinQueries := make([]string, 0)
 	feature_timestamps := make([]string, 0)
@@ -131,18 +255,23 @@ func (q defaultPythonOfflineQueries) trainingSetCreate(
 				i+1,
 				featureSchemas[i].Value,
 				featureColumnName,
-				featureSchemas[i].TS,
+				featureSchemas[i].parseTimestamp(featureSchemas[i].TS, sparkParseTimestamp),
 				i+1,
 				i+1,
 				i+1,
 			)
 		}
+		ageFilter := ""
+		if def.MaxFeatureAge > 0 {
+			ageFilter = fmt.Sprintf(" AND t%d_ts >= label_ts - INTERVAL %d SECOND", i+1, int64(def.MaxFeatureAge.Seconds()))
+		}
 		featureJoinQuery := fmt.Sprintf(
-			"LEFT OUTER JOIN (%s) t%d ON (t%d_entity = entity AND t%d_ts <= label_ts)",
+			"LEFT OUTER JOIN (%s) t%d ON (t%d_entity = entity AND t%d_ts <= label_ts%s)",
 			featureWindowQuery,
 			i+1,
 			i+1,
 			i+1,
+			ageFilter,
 		)
 		joinQueries = append(joinQueries, featureJoinQuery)
 		feature_timestamps = append(feature_timestamps, fmt.Sprintf("t%d_ts", i+1))
@@ -186,7 +315,7 @@ func (q defaultPythonOfflineQueries) trainingSetCreate(
 				curIdx,
 				featureSchemas[idx].Value,
 				lagColumnName,
-				featureSchemas[idx].TS,
+				featureSchemas[idx].parseTimestamp(featureSchemas[idx].TS, sparkParseTimestamp),
 				curIdx,
 				lagSource,
 				curIdx,
@@ -203,7 +332,35 @@ func (q defaultPythonOfflineQueries) trainingSetCreate(
 		joinQueries = append(joinQueries, lagJoinQuery)
 		feature_timestamps = append(feature_timestamps, fmt.Sprintf("t%d_ts", curIdx))
 	}
+	labelColumns := []string{createQuotedIdentifier(def.Label)}
+	for i, label := range def.labelIDs()[1:] {
+		schema := labelSchemas[i+1]
+		labelColumnName := createQuotedIdentifier(label)
+		labelColumns = append(labelColumns, labelColumnName)
+		ts := "CAST(0 AS TIMESTAMP)"
+		if schema.EntityMappings.TimestampColumn != "" {
+			ts = schema.parseTimestamp(schema.EntityMappings.TimestampColumn, sparkParseTimestamp)
+		}
+		labelJoinQuery := fmt.Sprintf(
+			"LEFT OUTER JOIN (SELECT %s as l%d_entity, %s as %s, %s as l%d_ts FROM source_%d) l%d ON (l%d_entity = entity AND l%d_ts = label_ts)",
+			schema.EntityMappings.Mappings[0].EntityColumn,
+			i+1,
+			schema.EntityMappings.ValueColumn,
+			labelColumnName,
+			ts,
+			i+1,
+			len(def.Features)+i+1,
+			i+1,
+			i+1,
+			i+1,
+		)
+		joinQueries = append(joinQueries, labelJoinQuery)
+	}
 	columnStr := strings.Join(columns, ", ")
+	extraLabelSelect := ""
+	if len(labelColumns) > 1 {
+		extraLabelSelect = ", " + strings.Join(labelColumns[1:], ", ")
+	}
 	joinQueryString := strings.Join(joinQueries, " ")
 	var labelWindowQuery string
 	if labelSchema.EntityMappings.TimestampColumn == "" {
@@ -217,7 +374,7 @@ func (q defaultPythonOfflineQueries) trainingSetCreate(
 			"SELECT %s AS entity, %s AS value, %s AS label_ts FROM source_0",
 			labelSchema.EntityMappings.Mappings[0].EntityColumn,
 			labelSchema.EntityMappings.ValueColumn,
-			labelSchema.EntityMappings.TimestampColumn,
+			labelSchema.parseTimestamp(labelSchema.EntityMappings.TimestampColumn, sparkParseTimestamp),
 		)
 	}
 	labelPartitionQuery := fmt.Sprintf(
@@ -229,17 +386,30 @@ func (q defaultPythonOfflineQueries) trainingSetCreate(
 	timeStamps := strings.Join(feature_timestamps, ", ")
 	timeStampsDesc := strings.Join(feature_timestamps, " DESC,")
 	fullQuery := fmt.Sprintf(
-		"SELECT %s, value AS %s, entity, label_ts, %s, ROW_NUMBER() over (PARTITION BY entity, value, label_ts ORDER BY label_ts DESC, %s DESC) as row_number FROM (%s) tt",
+		"SELECT %s, value AS %s%s, entity, label_ts, %s, ROW_NUMBER() over (PARTITION BY entity, value, label_ts ORDER BY label_ts DESC, %s DESC) as row_number FROM (%s) tt",
 		columnStr,
 		createQuotedIdentifier(def.Label),
+		extraLabelSelect,
 		timeStamps,
 		timeStampsDesc,
 		labelJoinQuery,
 	)
+	splitSelect := ""
+	if def.Split != nil {
+		splitSelect = trainingSetSplitSelect(
+			*def.Split,
+			fmt.Sprintf(
+				"pmod(xxhash64(CAST(entity AS STRING), CAST(label_ts AS STRING), CAST(%d AS BIGINT)), %d)",
+				def.Split.Seed,
+				trainingSetSplitBuckets,
+			),
+		)
+	}
 	finalQuery := fmt.Sprintf(
-		"SELECT %s, %s FROM (SELECT * FROM (SELECT *, row_number FROM (%s) WHERE row_number=1 ))  ORDER BY label_ts",
+		"SELECT %s, %s%s FROM (SELECT * FROM (SELECT *, row_number FROM (%s) WHERE row_number=1 ))  ORDER BY label_ts",
 		columnStr,
-		createQuotedIdentifier(def.Label),
+		strings.Join(labelColumns, ", "),
+		splitSelect,
 		fullQuery,
 	)
 	return finalQuery
@@ -321,7 +491,7 @@ func (store *SparkOfflineStore) GetBatchFeatures(ids []ResourceID) (BatchFeature
 		return nil, err
 	}
 	// Run the spark job
-	if err := store.Executor.RunSparkJob(sparkArgs, store.Store, SparkJobOptions{MaxJobDuration: time.Hour * 48}, nil); err != nil {
+	if err := runSparkJob(store.Executor, sparkArgs, store.Store, SparkJobOptions{MaxJobDuration: time.Hour * 48}, nil); err != nil {
 		logger.Errorw("Error running Spark job", "error", err)
 		return nil, err
 	}
@@ -365,11 +535,13 @@ func (store *SparkOfflineStore) createFilePathsFromIDs(legacyStore SparkFileStor
 		if err != nil {
 			return nil, err
 		}
-		newest, err := groups.GetFirst()
+		// The newest run's directory is used rather than its files' directory, since
+		// partitioned output nests the files in partition directories.
+		newestDir, err := groups.GetFirstDirectory()
 		if err != nil {
 			return nil, err
 		}
-		matDir, err := store.Store.CreateFilePath(newest[0].KeyPrefix(), true)
+		matDir, err := store.Store.CreateFilePath(newestDir, true)
 		if err != nil {
 			return nil, err
 		}
@@ -387,6 +559,168 @@ func (store *SparkOfflineStore) createFilePathsFromIDs(legacyStore SparkFileStor
 	return materializationPaths, nil
 }
 
+// asOfEntityRecord is a row of the file that GetBatchFeaturesAsOf passes its
+// entities to Spark in. RowIndex keeps the output in the order requested.
+type asOfEntityRecord struct {
+	RowIndex int64     `parquet:"row_index"`
+	Entity   string    `parquet:"entity"`
+	TS       time.Time `parquet:"ts,timestamp"`
+}
+
+func (store *SparkOfflineStore) GetBatchFeaturesAsOf(ids []ResourceID, entities []AsOfEntity) (AsOfBatchFeatureIterator, error) {
+	logger := store.Logger.With("operation", "GetBatchFeaturesAsOf", "ids", ids)
+	legacyStore, ok := store.Store.(SparkFileStore)
+	if !ok {
+		errMsg := "Batch Features No Longer Supported in OfflineStoreV2"
+		logger.Error(errMsg)
+		return nil, fferr.NewInternalErrorf(errMsg)
+	}
+	if len(ids) == 0 {
+		errMsg := "No feature IDs provided"
+		logger.Error(errMsg)
+		return nil, fferr.NewInvalidArgumentErrorf(errMsg)
+	}
+	// Each request gets its own directory since the entities differ between requests.
+	requestID := uuid.NewString()
+	logger = logger.With("request-id", requestID)
+	entitiesPath, err := store.Store.CreateFilePath(fmt.Sprintf("featureform/BatchFeatureEntities/%s.parquet", requestID), false)
+	if err != nil {
+		logger.Errorw("Failed to create entities file path", "err", err)
+		return nil, err
+	}
+	records := make([]asOfEntityRecord, len(entities))
+	for i, entity := range entities {
+		records[i] = asOfEntityRecord{RowIndex: int64(i), Entity: entity.Entity, TS: entity.TS.UTC()}
+	}
+	buf := new(bytes.Buffer)
+	if err := parquet.Write(buf, records); err != nil {
+		logger.Errorw("Failed to write entities to parquet", "err", err)
+		return nil, fferr.NewInternalError(err)
+	}
+	if err := store.Store.Write(entitiesPath, buf.Bytes()); err != nil {
+		logger.Errorw("Failed to write entities file", "err", err)
+		return nil, err
+	}
+	defer func() {
+		if err := store.Store.Delete(entitiesPath); err != nil {
+			logger.Warnw("Failed to delete entities file", "err", err)
+		}
+	}()
+	sources := []sparklib.SourceInfo{
+		{
+			Location:     entitiesPath.ToURI(),
+			LocationType: string(pl.FileStoreLocationType),
+			Provider:     store.Type(),
+		},
+	}
+	// Point-in-time values need each feature's full history, so features are read
+	// from their resource tables rather than their materializations.
+	schemas := make([]ResourceSchema, len(ids))
+	for i, id := range ids {
+		schema, err := store.getResourceSchema(ResourceID{Name: id.Name, Variant: id.Variant, Type: Feature})
+		if err != nil {
+			logger.Errorw("Failed to get resource schema", "id", id, "err", err)
+			return nil, err
+		}
+		schemas[i] = schema
+		var tableFormat string
+		if schema.SourceTable.Type() == pl.CatalogLocationType {
+			tableFormat = catalogTableFormat(schema.SourceTable.(*pl.CatalogLocation), store.GlueConfig)
+		}
+		sources = append(sources, sparklib.SourceInfo{
+			Location:     schema.SourceTable.Location(),
+			LocationType: string(schema.SourceTable.Type()),
+			TableFormat:  tableFormat,
+			CSVOptions:   locationCSVOptions(schema.SourceTable),
+			Compression:  locationCompression(schema.SourceTable),
+			Provider:     store.Type(),
+		})
+	}
+	query, err := store.query.asOfJoinQuery(schemas)
+	if err != nil {
+		logger.Errorw("Failed to create as-of join query", "err", err)
+		return nil, err
+	}
+	outputPath, err := legacyStore.CreateFilePath(fmt.Sprintf("featureform/BatchFeatures/%s", requestID), true)
+	if err != nil {
+		logger.Errorw("Failed to create output file path", "err", err)
+		return nil, err
+	}
+	sparkArgs, err := sparkScriptCommandDef{
+		DeployMode:     getSparkDeployModeFromEnv(),
+		TFType:         SQLTransformation,
+		OutputLocation: pl.NewFileLocation(outputPath),
+		Code:           query,
+		SourceList:     sources,
+		JobType:        types.BatchFeatures,
+		Store:          store.Store,
+		Mappings:       make([]SourceMapping, 0),
+	}.PrepareCommand(logger)
+	if err != nil {
+		logger.Errorw("Problem creating spark submit arguments", "error", err)
+		return nil, err
+	}
+	if err := runSparkJob(store.Executor, sparkArgs, store.Store, SparkJobOptions{MaxJobDuration: time.Hour * 48}, nil); err != nil {
+		logger.Errorw("Error running Spark job", "error", err)
+		return nil, err
+	}
+	outputFiles, err := legacyStore.List(outputPath, filestore.Parquet)
+	if err != nil {
+		logger.Errorw("Failed to list in path", "error", err)
+		return nil, err
+	}
+	groups, err := filestore.NewFilePathGroup(outputFiles, filestore.DateTimeDirectoryGrouping)
+	if err != nil {
+		logger.Errorw("Failed to create filegroup", "error", err)
+		return nil, err
+	}
+	newest, err := groups.GetFirst()
+	if err != nil {
+		logger.Errorw("Failed to get first file in group", "error", err)
+		return nil, err
+	}
+	iterator, err := legacyStore.Serve(newest)
+	if err != nil {
+		logger.Errorw("Failed to serve newest file", "error", err)
+		return nil, err
+	}
+	return &FileStoreBatchServing{store: legacyStore, iter: iterator, numFeatures: len(ids)}, nil
+}
+
+// asOfJoinQuery selects, for each entity in source_0, the latest value of each
+// feature at or before the entity's timestamp. Feature i is read from source_i+1.
+func (q defaultPythonOfflineQueries) asOfJoinQuery(schemas []ResourceSchema) (string, error) {
+	ctes := make([]string, len(schemas))
+	withFeatures := ""
+	joinTables := ""
+	for i, schema := range schemas {
+		entity, value, err := q.castColumns(schema)
+		if err != nil {
+			return "", err
+		}
+		// Values without a timestamp apply at any point in time.
+		ts := "CAST(0 AS TIMESTAMP)"
+		if schema.TS != "" {
+			ts = schema.parseTimestamp(schema.TS, sparkParseTimestamp)
+		}
+		name := fmt.Sprintf("as_of_%d", i+1)
+		ctes[i] = fmt.Sprintf(
+			"%s AS (SELECT row_index, value FROM ("+
+				"SELECT e.row_index, f.value, ROW_NUMBER() OVER (PARTITION BY e.row_index ORDER BY f.ts DESC) AS row_number "+
+				"FROM source_0 e JOIN (SELECT CAST(%s AS STRING) AS entity, %s AS value, %s AS ts FROM source_%d) f "+
+				"ON e.entity = f.entity AND f.ts <= e.ts"+
+				") WHERE row_number = 1)",
+			name, entity, value, ts, i+1,
+		)
+		withFeatures += fmt.Sprintf(", %s.value AS feature%d", name, i+1)
+		joinTables += fmt.Sprintf("LEFT JOIN %s ON e.row_index = %s.row_index ", name, name)
+	}
+	return fmt.Sprintf(
+		"WITH %s SELECT e.entity, unix_millis(e.ts) AS as_of%s FROM source_0 e %sORDER BY e.row_index",
+		strings.Join(ctes, ", "), withFeatures, joinTables,
+	), nil
+}
+
 func createJoinQuery(numFeatures int) string {
 	query := ""
 	asEntity := ""
@@ -419,7 +753,20 @@ func createJoinQuery(numFeatures int) string {
 	return query
 }
 
+// Close closes the file store and, if it holds a client, the executor.
 func (store *SparkOfflineStore) Close() error {
+	var err error
+	if store.Store != nil {
+		err = store.Store.Close()
+	}
+	if closer, ok := store.Executor.(io.Closer); ok {
+		if closeErr := closer.Close(); err == nil {
+			err = closeErr
+		}
+	}
+	if err != nil {
+		return fferr.NewConnectionError(store.Type().String(), err)
+	}
 	return nil
 }
 
@@ -491,7 +838,7 @@ func (store *SparkOfflineStore) CheckHealth() (bool, error) {
 		MaxJobDuration: 30 * time.Minute,
 		JobName:        "featureform-health-check",
 	}
-	if err := store.Executor.RunSparkJob(args, store.Store, opts, nil); err != nil {
+	if err := runSparkJob(store.Executor, args, store.Store, opts, nil); err != nil {
 		wrapped := fferr.NewConnectionError(store.Type().String(), err)
 		wrapped.AddDetail("action", "job_submission")
 		logger.Errorw("Spark health check failed", "error", wrapped)
@@ -509,12 +856,16 @@ func (store *SparkOfflineStore) CheckHealth() (bool, error) {
 		if err != nil {
 			return false, fferr.NewProviderConfigError(store.Type().String(), err)
 		}
-		if db.Database.LocationUri == nil {
-			return false, fferr.NewProviderConfigError(store.Type().String(), fmt.Errorf("database location is required or doesn't exist; please, check the Glue database configuration and reapply the provider"))
+		// Delta tables are created under the database location, so Glue fails to create them
+		// without one. Iceberg tables are created under the configured warehouse instead.
+		if db.Database.LocationUri == nil && store.GlueConfig.TableFormat == pc.DeltaLake {
+			return false, fferr.NewProviderConfigError(store.Type().String(), fmt.Errorf("database location is required for %s tables or doesn't exist; please, check the Glue database configuration and reapply the provider", pc.DeltaLake))
 		}
-		fp := filestore.S3Filepath{}
-		if err := fp.ParseDirPath(*db.Database.LocationUri); err != nil {
-			return false, fferr.NewProviderConfigError(store.Type().String(), err)
+		if db.Database.LocationUri != nil {
+			fp := filestore.S3Filepath{}
+			if err := fp.ParseDirPath(*db.Database.LocationUri); err != nil {
+				return false, fferr.NewProviderConfigError(store.Type().String(), err)
+			}
 		}
 	}
 
@@ -558,6 +909,10 @@ func sparkOfflineStoreFactory(config pc.SerializedConfig) (Provider, error) {
 	// But for now we use a GlueS3 store type if there is a glue config
 	var storeType = sc.StoreType
 	if sc.UsesCatalog() {
+		if err := sc.GlueConfig.Validate(); err != nil {
+			logger.Errorw("Invalid Glue config", "error", err)
+			return nil, err
+		}
 		storeType = filestore.Glue
 	}
 
@@ -619,6 +974,19 @@ type baseExecutor struct {
 	files config.SparkFileConfigs
 }
 
+// runSparkJob submits a Spark job to the executor within a tracing span. The
+// executors don't take a context, so the span starts a new trace.
+func runSparkJob(executor SparkExecutor, cmd *sparklib.Command, store SparkFileStoreV2, opts SparkJobOptions, tfOpts TransformationOptions) error {
+	_, span := tracing.StartSpan(context.Background(), "spark.RunSparkJob",
+		attribute.String("job_name", opts.JobName),
+		attribute.String("executor", fmt.Sprintf("%T", executor)),
+		attribute.String("store", string(store.Type())),
+	)
+	err := executor.RunSparkJob(cmd, store, opts, tfOpts)
+	tracing.EndSpan(span, err)
+	return err
+}
+
 type SparkExecutor interface {
 	InitializeExecutor(store SparkFileStoreV2) error
 	RunSparkJob(cmd *sparklib.Command, store SparkFileStoreV2, opts SparkJobOptions, tfOpts TransformationOptions) error
@@ -654,13 +1022,24 @@ func NewSparkExecutor(
 	}
 }
 
-func (spark *SparkOfflineStore) RegisterPrimaryFromSourceTable(id ResourceID, tableLocation pl.Location) (PrimaryTable, error) {
+func (spark *SparkOfflineStore) RegisterPrimaryFromSourceTable(id ResourceID, tableLocation pl.Location, opts ...PrimaryOption) (PrimaryTable, error) {
 	switch lt := tableLocation.(type) {
 	case *pl.SQLLocation:
 		return nil, fferr.NewInternalErrorf("SQLLocation not supported for primary table registration")
 	case *pl.FileStoreLocation:
-		return blobRegisterPrimary(id, *lt, spark.Logger.SugaredLogger, spark.Store)
+		return blobRegisterPrimary(id, *lt, spark.Logger.SugaredLogger, spark.Store, opts...)
 	case *pl.CatalogLocation:
+		if !spark.UsesCatalog() {
+			return nil, fferr.NewInvalidArgumentErrorf("catalog tables require a Glue config on the Spark provider")
+		}
+		tableFormat := pc.TableFormat(catalogTableFormat(lt, spark.GlueConfig))
+		if err := tableFormat.Validate(); err != nil {
+			return nil, err
+		}
+		if tableFormat == pc.Iceberg {
+			return spark.registerPrimaryCatalogTable(id, *lt, spark.Logger, spark.Store)
+		}
+		// Delta tables are read directly by the Spark jobs.
 		// TODO consider registering things in the catalog anyway?
 		return nil, nil
 	default:
@@ -668,13 +1047,82 @@ func (spark *SparkOfflineStore) RegisterPrimaryFromSourceTable(id ResourceID, ta
 	}
 }
 
+// registerPrimaryCatalogTable registers an Iceberg table in the Glue catalog as a primary table.
+// The table's schema is resolved from Glue and stored at the primary's filestore path, the same
+// as file primaries, so that the table can be fetched later via GetPrimaryTable.
 func (spark *SparkOfflineStore) registerPrimaryCatalogTable(id ResourceID, catalogLocation pl.CatalogLocation, logger logging.Logger, store FileStore) (PrimaryTable, error) {
-	return nil, nil
+	logger = logger.With("id", id, "location", catalogLocation.Location())
+	if tableFormat := catalogTableFormat(&catalogLocation, spark.GlueConfig); tableFormat != string(pc.Iceberg) {
+		logger.Errorw("Attempted to register a non-Iceberg catalog table as a primary table", "table_format", tableFormat)
+		return nil, fferr.NewInvalidArgumentErrorf(
+			"only %s catalog tables can be registered as primary tables; %s has table format '%s'",
+			pc.Iceberg, catalogLocation.Location(), tableFormat,
+		)
+	}
+	glueStore, ok := store.(*SparkGlueS3FileStore)
+	if !ok {
+		return nil, fferr.NewInternalErrorf("filestore is not SparkGlueS3FileStore; received %T", store)
+	}
+	_, schema, err := glueIcebergTable(glueStore, &catalogLocation)
+	if err != nil {
+		logger.Errorw("Could not resolve Iceberg table from Glue", "error", err)
+		return nil, err
+	}
+	filepath, err := store.CreateFilePath(id.ToFilestorePath(), false)
+	if err != nil {
+		return nil, err
+	}
+	primaryExists, err := store.Exists(pl.NewFileLocation(filepath))
+	if err != nil {
+		logger.Errorw("Error checking if primary exists", "error", err)
+		return nil, err
+	}
+	if primaryExists {
+		logger.Errorw("Table already registered")
+		return nil, fferr.NewDatasetAlreadyExistsError(id.Name, id.Variant, fmt.Errorf(catalogLocation.Location()))
+	}
+	data, err := schema.Serialize()
+	if err != nil {
+		return nil, err
+	}
+	if err := store.Write(filepath, data); err != nil {
+		logger.Errorw("Could not write primary table", "error", err)
+		return nil, err
+	}
+	logger.Debugw("Registered Iceberg primary table", "columns", schema.Columns)
+	return &IcebergPrimaryTable{id: id, location: &catalogLocation, schema: schema, store: glueStore}, nil
+}
+
+func (spark *SparkOfflineStore) getPrimaryCatalogTable(id ResourceID, location *pl.CatalogLocation) (PrimaryTable, error) {
+	glueStore, ok := spark.Store.(*SparkGlueS3FileStore)
+	if !ok {
+		return nil, fferr.NewInternalErrorf("filestore is not SparkGlueS3FileStore; received %T", spark.Store)
+	}
+	filepath, err := spark.Store.CreateFilePath(id.ToFilestorePath(), false)
+	if err != nil {
+		return nil, err
+	}
+	data, err := spark.Store.Read(filepath)
+	if err != nil {
+		return nil, err
+	}
+	schema := TableSchema{}
+	if err := schema.Deserialize(data); err != nil {
+		return nil, err
+	}
+	return &IcebergPrimaryTable{id: id, location: location, schema: schema, store: glueStore}, nil
 }
 
 func (spark *SparkOfflineStore) RegisterResourceFromSourceTable(id ResourceID, schema ResourceSchema, opts ...ResourceOption) (OfflineTable, error) {
-	if len(opts) > 0 {
-		spark.Logger.Errorf("Spark Offline Store does not currently support resource options; received %v for resource %v", opts, id)
+	schema, err := schema.withResourceOptions(opts...)
+	if err != nil {
+		spark.Logger.Errorw("Invalid resource options", "id", id, "options", opts, "error", err)
+		return nil, err
+	}
+	// Check the casts now rather than when the resource is materialized.
+	if err := schema.checkCasts(sparkColumnType); err != nil {
+		spark.Logger.Errorw("Unsupported column cast", "id", id, "casts", schema.Casts, "error", err)
+		return nil, err
 	}
 	return blobRegisterResourceFromSourceTable(id, schema, spark.Logger.SugaredLogger, spark.Store)
 }
@@ -691,6 +1139,10 @@ func (spark *SparkOfflineStore) SupportsTransformationOption(opt TransformationO
 }
 
 func (spark *SparkOfflineStore) CreateTransformation(config TransformationConfig, opts ...TransformationOption) error {
+	if err := ValidateTransformation(config); err != nil {
+		spark.Logger.Errorw("Transformation failed validation", "error", err)
+		return err
+	}
 	return spark.transformation(config, false, opts)
 }
 
@@ -717,10 +1169,38 @@ type pysparkFilestoreTable struct {
 }
 
 type pysparkCatalogTable struct {
-	Database  string `json:"database"`
-	Table     string `json:"table"`
-	Warehouse string `json:"warehouse"`
-	Region    string `json:"region"`
+	Database    string `json:"database"`
+	Table       string `json:"table"`
+	Warehouse   string `json:"warehouse"`
+	Region      string `json:"region"`
+	TableFormat string `json:"tableFormat"`
+}
+
+// catalogTableFormat returns the table format of a catalog location, falling back to
+// the format of the provider's Glue catalog for locations registered without one.
+func catalogTableFormat(loc *pl.CatalogLocation, glueConfig *pc.GlueConfig) string {
+	if loc.TableFormat() == "" && glueConfig != nil {
+		return string(glueConfig.TableFormat)
+	}
+	return loc.TableFormat()
+}
+
+// locationCSVOptions returns the options used to parse a delimited file source,
+// or nil if the location isn't a file or the file uses the default CSV format.
+func locationCSVOptions(loc pl.Location) *pl.CSVOptions {
+	if fileLoc, ok := loc.(*pl.FileStoreLocation); ok {
+		return fileLoc.CSVOptions()
+	}
+	return nil
+}
+
+// locationCompression returns the codec of a compressed file source, or an empty
+// string if the location isn't a file or the file isn't compressed.
+func locationCompression(loc pl.Location) string {
+	if fileLoc, ok := loc.(*pl.FileStoreLocation); ok {
+		return string(fileLoc.Filepath().Compression())
+	}
+	return ""
 }
 
 func (spark *SparkOfflineStore) sqlTransformation(config TransformationConfig, isUpdate bool, tfOpts TransformationOptions) error {
@@ -757,6 +1237,12 @@ func (spark *SparkOfflineStore) sqlTransformation(config TransformationConfig, i
 		return fferr.NewDatasetNotFoundError(config.TargetTableID.Name, config.TargetTableID.Variant, fmt.Errorf(outputLocation.Location()))
 	}
 
+	if isUpdate && config.IncrementalColumn != "" {
+		if updatedQuery, sources, err = spark.incrementalSQLTransformation(config, outputLocation, updatedQuery, sources, logger); err != nil {
+			return err
+		}
+	}
+
 	logger.Debugw("Running SQL transformation")
 	sparkArgs, err := sparkScriptCommandDef{
 		DeployMode:     getSparkDeployModeFromEnv(),
@@ -767,6 +1253,7 @@ func (spark *SparkOfflineStore) sqlTransformation(config TransformationConfig, i
 		JobType:        types.Transform,
 		Store:          spark.Store,
 		Mappings:       config.SourceMapping,
+		SubmitOptions:  config.SparkSubmitOptions,
 	}.PrepareCommand(logger)
 	logger = logger.With("args", sparkArgs.Redacted())
 	if err != nil {
@@ -783,7 +1270,7 @@ func (spark *SparkOfflineStore) sqlTransformation(config TransformationConfig, i
 		),
 	}
 	logger.Debugw("Running spark job", "options", opts)
-	if err := spark.Executor.RunSparkJob(sparkArgs, spark.Store, opts, tfOpts); err != nil {
+	if err := runSparkJob(spark.Executor, sparkArgs, spark.Store, opts, tfOpts); err != nil {
 		logger.Errorw("spark submit job for transformation failed to run", "target", config.TargetTableID, "error", err)
 		return err
 	}
@@ -791,6 +1278,43 @@ func (spark *SparkOfflineStore) sqlTransformation(config TransformationConfig, i
 	return nil
 }
 
+// incrementalSQLTransformation adds the transformation's previous run as a
+// source and returns a query that appends the rows past its watermark, the
+// greatest value of the IncrementalColumn in it. Each run writes a full copy of
+// the transformation, so the watermark is read from the previous run rather than
+// stored separately. Catalog tables can't be read while they're overwritten, so
+// they're rebuilt.
+func (spark *SparkOfflineStore) incrementalSQLTransformation(config TransformationConfig, outputLocation pl.Location, query string, sources []sparklib.SourceInfo, logger logging.Logger) (string, []sparklib.SourceInfo, error) {
+	if _, isFileStoreLocation := outputLocation.(*pl.FileStoreLocation); !isFileStoreLocation {
+		logger.Infow("Incremental updates aren't supported for catalog tables, rebuilding transformation", "location", outputLocation.Location())
+		return query, sources, nil
+	}
+	previous, err := spark.ResourceLocation(config.TargetTableID, nil)
+	if err != nil {
+		logger.Errorw("Could not get the previous run of the transformation", "error", err)
+		return "", nil, err
+	}
+	logger.Debugw("Appending to previous run of transformation", "previous", previous.Location(), "column", config.IncrementalColumn)
+	sources = append(sources, sparklib.SourceInfo{
+		Location:     previous.Location(),
+		LocationType: string(previous.Type()),
+		Provider:     pt.SparkOffline,
+	})
+	return incrementalSparkQuery(query, config.IncrementalColumn, len(sources)-1), sources, nil
+}
+
+// incrementalSparkQuery returns a query that appends the rows of query whose
+// column is greater than its greatest value in the previous run, source_<previous>,
+// to that run. If the previous run is empty, all of the rows are returned.
+func incrementalSparkQuery(query, column string, previous int) string {
+	prev := fmt.Sprintf("source_%d", previous)
+	col := fmt.Sprintf("`%s`", strings.ReplaceAll(column, "`", "``"))
+	return fmt.Sprintf(
+		"SELECT * FROM %s UNION ALL SELECT * FROM ( %s ) AS incremental WHERE NOT EXISTS (SELECT 1 FROM %s) OR incremental.%s > (SELECT MAX(%s) FROM %s)",
+		prev, query, prev, col, col, prev,
+	)
+}
+
 func (spark *SparkOfflineStore) dfTransformation(config TransformationConfig, isUpdate bool, tfOpts TransformationOptions) error {
 	logger := spark.Logger.With(
 		"type",
@@ -880,6 +1404,7 @@ func (spark *SparkOfflineStore) dfTransformation(config TransformationConfig, is
 		JobType:        types.Transform,
 		Store:          spark.Store,
 		Mappings:       config.SourceMapping,
+		SubmitOptions:  config.SparkSubmitOptions,
 	}.PrepareCommand(logger)
 	logger = logger.With("args", sparkArgs.Redacted())
 	if err != nil {
@@ -896,7 +1421,7 @@ func (spark *SparkOfflineStore) dfTransformation(config TransformationConfig, is
 		),
 	}
 	logger.Debugw("Running DF transformation", "options", opts)
-	if err := spark.Executor.RunSparkJob(sparkArgs, spark.Store, opts, tfOpts); err != nil {
+	if err := runSparkJob(spark.Executor, sparkArgs, spark.Store, opts, tfOpts); err != nil {
 		logger.Errorw("error running Spark dataframe job", "error", err)
 		return err
 	}
@@ -941,12 +1466,14 @@ func createSourceInfo(mapping []SourceMapping, logger logging.Logger) ([]sparkli
 				source = sparklib.SourceInfo{
 					Location:     lt.Location(),
 					LocationType: string(lt.Type()),
+					CSVOptions:   lt.CSVOptions(),
+					Compression:  string(lt.Filepath().Compression()),
 				}
 			case *pl.CatalogLocation:
 				source = sparklib.SourceInfo{
 					Location:     lt.Location(),
 					LocationType: string(lt.Type()),
-					TableFormat:  lt.TableFormat(),
+					TableFormat:  catalogTableFormat(lt, sparkConfig.GlueConfig),
 				}
 			default:
 				return nil, fferr.NewInternalErrorf("unsupported location type for query replacement: %T", m.Location)
@@ -1016,12 +1543,14 @@ func (spark *SparkOfflineStore) prepareQueryForSpark(query string, mapping []Sou
 				source = sparklib.SourceInfo{
 					Location:     lt.Location(),
 					LocationType: string(lt.Type()),
+					CSVOptions:   lt.CSVOptions(),
+					Compression:  string(lt.Filepath().Compression()),
 				}
 			case *pl.CatalogLocation:
 				source = sparklib.SourceInfo{
 					Location:     lt.Location(),
 					LocationType: string(lt.Type()),
-					TableFormat:  lt.TableFormat(),
+					TableFormat:  catalogTableFormat(lt, sparkConfig.GlueConfig),
 				}
 			default:
 				return "", nil, fferr.NewInternalErrorf("unsupported location type for query replacement: %T", m.Location)
@@ -1104,6 +1633,191 @@ func (spark *SparkOfflineStore) ResourceLocation(id ResourceID, resource any) (p
 	return pl.NewFileLocation(newestFileDirPathDateTime), nil
 }
 
+func (spark *SparkOfflineStore) GetResourceStats(id ResourceID, resource any) (ResourceStats, error) {
+	if spark.UsesCatalog() {
+		return ResourceStats{}, fferr.NewUnimplementedErrorf("resource stats are not supported for catalog tables")
+	}
+	return fileStoreGetResourceStats(id, spark.Store, spark.Logger.SugaredLogger)
+}
+
+// ProfileSource runs a Spark SQL job that aggregates a source into a single row
+// and reads the row back. File sources don't have a schema to read column types
+// from, so the columns with a min and max are picked using the source's first row.
+func (spark *SparkOfflineStore) ProfileSource(id ResourceID, source metadata.SourceVariant, opts ProfileOptions) (SourceProfile, error) {
+	logger := spark.Logger.With("source", id, "options", opts)
+	var table PrimaryTable
+	var location pl.Location
+	var err error
+	if source.IsPrimaryData() {
+		if location, err = source.GetPrimaryLocation(); err != nil {
+			return SourceProfile{}, err
+		}
+		table, err = spark.GetPrimaryTable(id, source)
+	} else {
+		if location, err = spark.ResourceLocation(id, source); err != nil {
+			return SourceProfile{}, err
+		}
+		table, err = spark.GetTransformationTable(id)
+	}
+	if err != nil {
+		logger.Errorw("Could not get source table", "error", err)
+		return SourceProfile{}, err
+	}
+	columns, err := sparkProfiledColumns(table)
+	if err != nil {
+		logger.Errorw("Could not read the columns of the source", "error", err)
+		return SourceProfile{}, err
+	}
+	mappings := []SourceMapping{{
+		Source:         location.Location(),
+		ProviderType:   pt.SparkOffline,
+		ProviderConfig: spark.Config(),
+		Location:       location,
+	}}
+	sources, err := createSourceInfo(mappings, logger)
+	if err != nil {
+		return SourceProfile{}, err
+	}
+	outputPath, err := spark.Store.CreateFilePath(ps.ResourceToDirectoryPath("Profile", id.Name, id.Variant), true)
+	if err != nil {
+		return SourceProfile{}, err
+	}
+	sparkArgs, err := sparkScriptCommandDef{
+		DeployMode:     getSparkDeployModeFromEnv(),
+		TFType:         SQLTransformation,
+		OutputLocation: pl.NewFileLocation(outputPath),
+		Code:           sparkProfileQuery(columns, opts.SampleSize),
+		SourceList:     sources,
+		JobType:        types.Transform,
+		Store:          spark.Store,
+		Mappings:       mappings,
+	}.PrepareCommand(logger)
+	if err != nil {
+		logger.Errorw("Problem creating spark submit arguments", "error", err)
+		return SourceProfile{}, err
+	}
+	// The job's output is only needed until the profile is read back.
+	defer func() {
+		if err := spark.Store.DeleteAll(outputPath); err != nil {
+			logger.Warnw("Failed to delete source profile output", "error", err)
+		}
+	}()
+	jobOpts := SparkJobOptions{JobName: fmt.Sprintf("featureform-profile--%s--%s", id.Name, id.Variant)}
+	if err := runSparkJob(spark.Executor, sparkArgs, spark.Store, jobOpts, nil); err != nil {
+		logger.Errorw("spark submit job for source profile failed to run", "error", err)
+		return SourceProfile{}, err
+	}
+	output := &FileStorePrimaryTable{spark.Store, outputPath, TableSchema{}, true, id}
+	return readSparkProfile(output, columns)
+}
+
+// sourceColumns returns the column names of a file source. Catalog tables
+// return nil, their columns are resolved by Spark when the job runs.
+func (spark *SparkOfflineStore) sourceColumns(id ResourceID, source pl.Location) ([]string, error) {
+	fileLocation, ok := source.(*pl.FileStoreLocation)
+	if !ok {
+		return nil, nil
+	}
+	path := fileLocation.Filepath()
+	table := &FileStorePrimaryTable{spark.Store, path, TableSchema{}, path.IsDir(), id}
+	iter, err := table.IterateSegment(1)
+	if err != nil {
+		return nil, err
+	}
+	defer iter.Close()
+	return iter.Columns(), nil
+}
+
+func sparkProfiledColumns(table PrimaryTable) ([]profiledColumn, error) {
+	iter, err := table.IterateSegment(1)
+	if err != nil {
+		return nil, err
+	}
+	defer iter.Close()
+	var first GenericRecord
+	if iter.Next() {
+		first = iter.Values()
+	}
+	if err := iter.Err(); err != nil {
+		return nil, err
+	}
+	columns := make([]profiledColumn, len(iter.Columns()))
+	for i, name := range iter.Columns() {
+		columns[i].name = name
+		if i < len(first) {
+			switch first[i].(type) {
+			case int, int32, int64, float32, float64, time.Time:
+				columns[i].hasRange = true
+			}
+		}
+	}
+	return columns, nil
+}
+
+// sparkProfileQuery returns a Spark SQL query over source_0 with a row_count
+// column followed by null_count_<i>, distinct_count_<i> and, if the column has a
+// range, min_<i> and max_<i> columns for every column i. Min and max are cast to
+// strings so that every column's can be read the same way.
+func sparkProfileQuery(columns []profiledColumn, sampleSize int64) string {
+	aggregates := []string{"COUNT(*) AS row_count"}
+	for i, column := range columns {
+		col := fmt.Sprintf("`%s`", strings.ReplaceAll(column.name, "`", "``"))
+		aggregates = append(aggregates,
+			fmt.Sprintf("COUNT(*) - COUNT(%s) AS null_count_%d", col, i),
+			fmt.Sprintf("COUNT(DISTINCT %s) AS distinct_count_%d", col, i),
+		)
+		if column.hasRange {
+			aggregates = append(aggregates,
+				fmt.Sprintf("CAST(MIN(%s) AS STRING) AS min_%d", col, i),
+				fmt.Sprintf("CAST(MAX(%s) AS STRING) AS max_%d", col, i),
+			)
+		}
+	}
+	from := "source_0"
+	if sampleSize > 0 {
+		from = fmt.Sprintf("(SELECT * FROM source_0 LIMIT %d) AS profile_sample", sampleSize)
+	}
+	return fmt.Sprintf("SELECT %s FROM %s", strings.Join(aggregates, ", "), from)
+}
+
+func readSparkProfile(output PrimaryTable, columns []profiledColumn) (SourceProfile, error) {
+	iter, err := output.IterateSegment(1)
+	if err != nil {
+		return SourceProfile{}, err
+	}
+	defer iter.Close()
+	if !iter.Next() {
+		if err := iter.Err(); err != nil {
+			return SourceProfile{}, err
+		}
+		return SourceProfile{}, fferr.NewInternalErrorf("source profile job didn't output a row")
+	}
+	row := make(map[string]interface{}, len(iter.Columns()))
+	for i, name := range iter.Columns() {
+		row[name] = iter.Values()[i]
+	}
+	count := func(name string) int64 {
+		switch n := row[name].(type) {
+		case int:
+			return int64(n)
+		case int64:
+			return n
+		}
+		return 0
+	}
+	profile := SourceProfile{RowCount: count("row_count"), Columns: make([]ColumnProfile, len(columns))}
+	for i, column := range columns {
+		profile.Columns[i] = ColumnProfile{
+			Name:          column.name,
+			NullCount:     count(fmt.Sprintf("null_count_%d", i)),
+			DistinctCount: count(fmt.Sprintf("distinct_count_%d", i)),
+			Min:           profileValueString(row[fmt.Sprintf("min_%d", i)]),
+			Max:           profileValueString(row[fmt.Sprintf("max_%d", i)]),
+		}
+	}
+	return profile, nil
+}
+
 // TODO: Currently, GetTransformationTable is only used in the context of serving source data as an iterator,
 // and given we currently cannot serve catalog tables in this way, there's no need to implement support for
 // catalog locations here. However, eventually, we'll need to address this gap in implementation.
@@ -1155,6 +1869,15 @@ func (spark *SparkOfflineStore) CreatePrimaryTable(id ResourceID, schema TableSc
 }
 
 func (spark *SparkOfflineStore) GetPrimaryTable(id ResourceID, source metadata.SourceVariant) (PrimaryTable, error) {
+	if source.IsPrimaryData() {
+		location, err := source.GetPrimaryLocation()
+		if err != nil {
+			return nil, err
+		}
+		if catalogLocation, ok := location.(*pl.CatalogLocation); ok && catalogTableFormat(catalogLocation, spark.GlueConfig) == string(pc.Iceberg) {
+			return spark.getPrimaryCatalogTable(id, catalogLocation)
+		}
+	}
 	return fileStoreGetPrimary(id, spark.Store, spark.Logger.SugaredLogger)
 }
 
@@ -1253,7 +1976,7 @@ func blobSparkMaterialization(
 	}
 	var tableFormat string
 	if sparkResourceTable.schema.SourceTable.Type() == pl.CatalogLocationType {
-		tableFormat = string(sparkResourceTable.schema.SourceTable.(*pl.CatalogLocation).TableFormat())
+		tableFormat = catalogTableFormat(sparkResourceTable.schema.SourceTable.(*pl.CatalogLocation), spark.GlueConfig)
 	}
 	// get destination path for the materialization
 	materializationID := ResourceID{Name: id.Name, Variant: id.Variant, Type: FeatureMaterialization}
@@ -1272,22 +1995,73 @@ func blobSparkMaterialization(
 		spark.Logger.Errorw("Attempted to update a materialization that doesn't exists", "id", id)
 		return nil, fferr.NewDatasetNotFoundError(id.Name, id.Variant, fmt.Errorf(destinationPath.ToURI()))
 	}
-	materializationQuery, err := spark.query.materializationCreate(sparkResourceTable.schema)
+	if opts.Filter != "" {
+		columns, err := spark.sourceColumns(id, sparkResourceTable.schema.SourceTable)
+		if err != nil {
+			spark.Logger.Errorw("Could not read the columns of the source", "id", id, "error", err)
+			return nil, err
+		}
+		if err := validateMaterializationFilter(opts.Filter, columns); err != nil {
+			spark.Logger.Errorw("Invalid materialization filter", "id", id, "filter", opts.Filter, "error", err)
+			return nil, err
+		}
+	}
+	materialization := &FileStoreMaterialization{materializationID, spark.Store}
+	incremental := isUpdate && opts.Incremental
+	var watermark time.Time
+	var previous sparklib.SourceInfo
+	var materializationQuery string
+	if incremental {
+		watermark, err = materialization.Watermark()
+		if err != nil {
+			spark.Logger.Errorw("Could not get materialization watermark", "id", id, "error", err)
+			return nil, err
+		}
+		spark.Logger.Debugw("Updating materialization incrementally", "id", id, "watermark", watermark)
+		previous, err = spark.previousMaterialization(materialization, opts)
+		if err != nil {
+			spark.Logger.Errorw("Could not get the previous materialization", "id", id, "error", err)
+			return nil, err
+		}
+		materializationQuery, err = spark.query.incrementalMaterializationCreate(sparkResourceTable.schema, watermark, opts.Filter)
+		if err == nil {
+			materializationQuery = mergeMaterializationQuery(materializationQuery, 1)
+		}
+	} else {
+		materializationQuery, err = spark.query.materializationCreate(sparkResourceTable.schema, opts.Filter)
+	}
 	if err != nil {
 		return nil, err
 	}
+	partition := opts.PartitionBy
+	if partition.Type != NoPartition {
+		if err := partition.validate(sparkResourceTable.schema); err != nil {
+			spark.Logger.Errorw("Invalid materialization partitioning", "id", id, "partition", partition, "error", err)
+			return nil, err
+		}
+		materializationQuery, err = spark.query.partitionMaterialization(materializationQuery, partition)
+		if err != nil {
+			return nil, err
+		}
+	}
 	sourcePySpark := sparklib.SourceInfo{
 		Location:     sparkResourceTable.schema.SourceTable.Location(),
 		LocationType: string(sparkResourceTable.schema.SourceTable.Type()),
 		TableFormat:  tableFormat,
+		CSVOptions:   locationCSVOptions(sparkResourceTable.schema.SourceTable),
+		Compression:  locationCompression(sparkResourceTable.schema.SourceTable),
 		Provider:     spark.Type(),
 	}
+	sources := []sparklib.SourceInfo{sourcePySpark}
+	if incremental {
+		sources = append(sources, previous)
+	}
 	sparkArgs, err := sparkScriptCommandDef{
 		DeployMode:     getSparkDeployModeFromEnv(),
 		TFType:         SQLTransformation,
 		OutputLocation: pl.NewFileLocation(destinationPath),
 		Code:           materializationQuery,
-		SourceList:     []sparklib.SourceInfo{sourcePySpark},
+		SourceList:     sources,
 		JobType:        types.Materialize,
 		Store:          spark.Store,
 		Mappings:       make([]SourceMapping, 0),
@@ -1305,6 +2079,9 @@ func blobSparkMaterialization(
 			ShouldInclude: opts.ShouldIncludeHeaders,
 		},
 	)
+	if partition.Type != NoPartition {
+		sparkArgs.AddConfigs(sparklib.PartitionByFlag{Columns: []string{partition.Column()}})
+	}
 	if isUpdate {
 		spark.Logger.Debugw("Updating materialization", "id", id)
 	} else {
@@ -1315,7 +2092,7 @@ func blobSparkMaterialization(
 		JobName:        opts.JobName,
 	}
 	spark.Logger.Debugw("Running spark job", "options", sparkOpts)
-	if err := spark.Executor.RunSparkJob(sparkArgs, spark.Store, sparkOpts, nil); err != nil {
+	if err := runSparkJob(spark.Executor, sparkArgs, spark.Store, sparkOpts, nil); err != nil {
 		spark.Logger.Errorw("Spark submit job failed to run", "error", err)
 		return nil, err
 	}
@@ -1332,8 +2109,63 @@ func blobSparkMaterialization(
 			fmt.Errorf("materialization not found in directory: %s", destinationPath.ToURI()),
 		)
 	}
+	if incremental {
+		// The delta is merged into the newest output, but rows dropped by the filter
+		// may be newer than any kept row, so the watermark never moves backwards.
+		deltaTS, err := materialization.maxTimestamp()
+		if err != nil {
+			return nil, err
+		}
+		if deltaTS.After(watermark) {
+			watermark = deltaTS
+		}
+		if err := materialization.SetWatermark(watermark); err != nil {
+			spark.Logger.Errorw("Could not store materialization watermark", "id", id, "error", err)
+			return nil, err
+		}
+	} else if err := materialization.ClearWatermark(); err != nil {
+		spark.Logger.Errorw("Could not clear materialization watermark", "id", id, "error", err)
+		return nil, err
+	}
 	spark.Logger.Debugw("Successfully created materialization", "id", id)
-	return &FileStoreMaterialization{materializationID, spark.Store}, nil
+	return materialization, nil
+}
+
+// previousMaterialization returns the newest output of a materialization as a
+// source, so an incremental update can merge its delta into it. The output
+// directory is read as Parquet, so other output types can't be merged.
+func (spark *SparkOfflineStore) previousMaterialization(mat *FileStoreMaterialization, opts MaterializationOptions) (sparklib.SourceInfo, error) {
+	if opts.Output != "" && opts.Output != filestore.Parquet {
+		return sparklib.SourceInfo{}, fferr.NewInvalidArgumentErrorf("incremental materialization requires Parquet output, got %s", opts.Output)
+	}
+	newestFiles, err := mat.newestFiles()
+	if err != nil {
+		return sparklib.SourceInfo{}, err
+	}
+	if len(newestFiles) == 0 {
+		return sparklib.SourceInfo{}, fferr.NewDatasetNotFoundError(mat.id.Name, mat.id.Variant, fmt.Errorf("materialization has no output files"))
+	}
+	dir, err := spark.Store.CreateFilePath(newestFiles[0].KeyPrefix(), true)
+	if err != nil {
+		return sparklib.SourceInfo{}, err
+	}
+	return sparklib.SourceInfo{
+		Location:     dir.ToURI(),
+		LocationType: string(pl.FileStoreLocationType),
+		Provider:     spark.Type(),
+	}, nil
+}
+
+// mergeMaterializationQuery returns a query that merges the rows of the delta
+// query into the previous materialization, source_<previous>, keeping the latest
+// row per entity. The delta only holds rows past the previous run's watermark,
+// so an entity's delta row always wins.
+func mergeMaterializationQuery(delta string, previous int) string {
+	return fmt.Sprintf(
+		"SELECT entity, value, ts FROM (SELECT entity, value, ts, ROW_NUMBER() OVER (PARTITION BY entity ORDER BY ts DESC) AS rn FROM "+
+			"(SELECT entity, value, ts FROM source_%d UNION ALL SELECT entity, value, ts FROM ( %s ) AS delta) AS merged) AS latest WHERE rn = 1",
+		previous, strings.TrimSuffix(strings.TrimSpace(delta), ";"),
+	)
 }
 
 func (spark *SparkOfflineStore) CreateMaterialization(id ResourceID, opts MaterializationOptions) (
@@ -1355,8 +2187,59 @@ func (spark *SparkOfflineStore) directCopyMaterialize(id ResourceID, opts Materi
 		logger.Error("Attempted to create a materialization of a non feature resource")
 		return err
 	}
-	dynamo, ok := online.(*dynamodbOnlineStore)
-	if !ok {
+	var creds sparklib.Config
+	var target types.DirectCopyTarget
+	var tableName string
+	switch store := online.(type) {
+	case *dynamodbOnlineStore:
+		creds = sparklib.DynamoFlags{
+			Region:    store.region,
+			AccessKey: store.accessKey,
+			SecretKey: store.secretKey,
+		}
+		target = types.DirectCopyDynamo
+		tableName = store.FormatTableName(id.Name, id.Variant)
+	case *redisOnlineStore:
+		redisConfig := pc.RedisConfig{}
+		if err := redisConfig.Deserialize(store.Config()); err != nil {
+			logger.Errorw("Failed to deserialize Redis config", "error", err)
+			return err
+		}
+		creds = sparklib.RedisFlags{
+			Addr:                  redisConfig.Addr,
+			Username:              redisConfig.Username,
+			Password:              redisConfig.Password,
+			DB:                    redisConfig.DB,
+			PipelineSize:          store.pipelineSize,
+			Topology:              string(redisConfig.GetTopology()),
+			ClusterAddrs:          redisConfig.ClusterAddrs,
+			SentinelMasterName:    redisConfig.SentinelMasterName,
+			SentinelAddrs:         redisConfig.SentinelAddrs,
+			TLS:                   redisConfig.TLS,
+			TLSCACert:             redisConfig.TLSCACert,
+			TLSCert:               redisConfig.TLSCert,
+			TLSKey:                redisConfig.TLSKey,
+			TLSInsecureSkipVerify: redisConfig.TLSInsecureSkipVerify,
+		}
+		target = types.DirectCopyRedis
+		// The features are written into the same hash that redisOnlineTable reads.
+		tableName = redisTableKey{store.prefix, id.Name, id.Variant}.String()
+	case *mongoDBOnlineStore:
+		mongoConfig := pc.MongoDBConfig{}
+		if err := mongoConfig.Deserialize(store.Config()); err != nil {
+			logger.Errorw("Failed to deserialize MongoDB config", "error", err)
+			return err
+		}
+		creds = sparklib.CosmosFlags{
+			Host:     mongoConfig.Host,
+			Port:     mongoConfig.Port,
+			Username: mongoConfig.Username,
+			Password: mongoConfig.Password,
+			Database: mongoConfig.Database,
+		}
+		target = types.DirectCopyCosmos
+		tableName = store.GetTableName(id.Name, id.Variant)
+	default:
 		errStr := fmt.Sprintf("Cannot direct copy from Spark to %T", online)
 		logger.Error(errStr)
 		return fferr.NewInternalErrorf(errStr)
@@ -1368,16 +2251,26 @@ func (spark *SparkOfflineStore) directCopyMaterialize(id ResourceID, opts Materi
 		return fferr.NewInternalErrorf(errStr)
 	}
 	logger.Debug("Got resource schema", "ResourceSchema", schema)
+	if len(schema.Casts) > 0 {
+		logger.Errorw("Direct copies don't support column casts", "casts", schema.Casts)
+		return fferr.NewInvalidArgumentErrorf("direct copies don't support column casts")
+	}
+	if schema.TSFormat != nil {
+		logger.Errorw("Direct copies don't support timestamp formats", "format", *schema.TSFormat)
+		return fferr.NewInvalidArgumentErrorf("direct copies don't support timestamp formats")
+	}
 	sourceTable := schema.SourceTable
 	tableFormat := ""
 	if sourceTable.Type() == pl.CatalogLocationType {
-		tableFormat = string(sourceTable.(*pl.CatalogLocation).TableFormat())
+		tableFormat = catalogTableFormat(sourceTable.(*pl.CatalogLocation), spark.GlueConfig)
 	}
 	sourceList := []sparklib.SourceInfo{
 		sparklib.SourceInfo{
 			Location:     sourceTable.Location(),
 			LocationType: string(sourceTable.Type()),
 			TableFormat:  tableFormat,
+			CSVOptions:   locationCSVOptions(sourceTable),
+			Compression:  locationCompression(sourceTable),
 			Provider:     spark.Type(),
 		},
 	}
@@ -1397,13 +2290,9 @@ func (spark *SparkOfflineStore) directCopyMaterialize(id ResourceID, opts Materi
 	}
 	sparkArgs.AddConfigs(
 		sparklib.DirectCopyFlags{
-			Creds: sparklib.DynamoFlags{
-				Region:    dynamo.region,
-				AccessKey: dynamo.accessKey,
-				SecretKey: dynamo.secretKey,
-			},
-			Target:          types.DirectCopyDynamo,
-			TableName:       dynamo.FormatTableName(id.Name, id.Variant),
+			Creds:           creds,
+			Target:          target,
+			TableName:       tableName,
 			FeatureName:     id.Name,
 			FeatureVariant:  id.Variant,
 			EntityColumn:    schema.Entity,
@@ -1416,7 +2305,7 @@ func (spark *SparkOfflineStore) directCopyMaterialize(id ResourceID, opts Materi
 		JobName:        opts.JobName,
 	}
 	logger.Debugw("Running spark job", "options", sparkOpts)
-	if err := spark.Executor.RunSparkJob(sparkArgs, spark.Store, sparkOpts, nil); err != nil {
+	if err := runSparkJob(spark.Executor, sparkArgs, spark.Store, sparkOpts, nil); err != nil {
 		logger.Errorw("Spark submit job failed to run", "error", err)
 		return err
 	}
@@ -1427,13 +2316,23 @@ func (spark *SparkOfflineStore) directCopyMaterialize(id ResourceID, opts Materi
 func (spark *SparkOfflineStore) SupportsMaterializationOption(opt MaterializationOptionType) (bool, error) {
 	spark.Logger.Debugw("Checking if Spark supports option", "type", opt)
 	switch opt {
-	case DirectCopyDynamo:
+	case DirectCopyDynamo, DirectCopyRedis, DirectCopyCosmos, FilteredMaterialization:
 		return true, nil
 	default:
 		return false, nil
 	}
 }
 
+func (spark *SparkOfflineStore) Capabilities() (Capabilities, error) {
+	caps, err := offlineStoreCapabilities(spark)
+	if err != nil {
+		return Capabilities{}, err
+	}
+	caps.Catalog = spark.UsesCatalog()
+	caps.IncrementalTransformation = true
+	return caps, nil
+}
+
 func (spark *SparkOfflineStore) GetMaterialization(id MaterializationID) (Materialization, error) {
 	return fileStoreGetMaterialization(id, spark.Store, spark.Logger.SugaredLogger)
 }
@@ -1501,54 +2400,38 @@ func sparkTrainingSet(def TrainingSetDef, spark *SparkOfflineStore, isUpdate boo
 		logger.Errorw("Training set does not exist")
 		return fferr.NewDatasetNotFoundError(def.ID.Name, def.ID.Variant, fmt.Errorf(destinationPath.ToURI()))
 	}
-	var labelSchema ResourceSchema
-	var labelPySparkSource sparklib.SourceInfo
-	logger.Debugw("Label provider", "provider", def.LabelSourceMapping.ProviderType)
-	switch def.LabelSourceMapping.ProviderType {
-	case pt.SparkOffline:
-		labelSchema, err = spark.getResourceSchema(def.Label)
-		if err != nil {
-			logger.Errorw("Could not get schema of label in spark store", "label", def.Label, "error", err)
+	var persistLocation pl.Location
+	if def.PersistAs != nil {
+		// Fail before the training set is built rather than after.
+		if persistLocation, err = spark.trainingSetPersistLocation(*def.PersistAs, isUpdate, logger); err != nil {
 			return err
 		}
-		var tableFormat string
-		if labelSchema.SourceTable.Type() == pl.CatalogLocationType {
-			tableFormat = labelSchema.SourceTable.(*pl.CatalogLocation).TableFormat()
-		}
-		labelPySparkSource = sparklib.SourceInfo{
-			Location:     labelSchema.SourceTable.Location(),
-			LocationType: string(labelSchema.SourceTable.Type()),
-			Provider:     def.LabelSourceMapping.ProviderType,
-			TableFormat:  tableFormat,
-		}
-	case pt.SnowflakeOffline:
-		config := pc.SnowflakeConfig{}
-		if err := config.Deserialize(def.LabelSourceMapping.ProviderConfig); err != nil {
-			logger.Errorw("Error deserializing snowflake config", "error", err)
+	}
+	labelMappings := def.labelSourceMappings()
+	labelSchemas := make([]ResourceSchema, len(labelMappings))
+	labelSources := make([]sparklib.SourceInfo, len(labelMappings))
+	for i, label := range def.labelIDs() {
+		labelSchema, labelPySparkSource, err := spark.trainingSetLabelSource(label, labelMappings[i], logger)
+		if err != nil {
 			return err
 		}
-		labelPySparkSource = sparklib.SourceInfo{
-			Location:     def.LabelSourceMapping.Source,
-			LocationType: string(pl.SQLLocationType),
-			Provider:     def.LabelSourceMapping.ProviderType,
-			Database:     config.Database,
-			Schema:       config.Schema,
+		spark.Logger.Debugw("Label schema", "label", label, "schema", labelSchema)
+		// TODO: This is a temporary check to ensure that the entity mappings are correct; once multi-label entity
+		// support is implemented, this check should be removed.
+		if len(labelSchema.EntityMappings.Mappings) != 1 {
+			spark.Logger.Errorw("Spark currently does not support multi-entity labels, so mappings must be of length 1", "length", len(labelSchema.EntityMappings.Mappings), "mappings", labelSchema.EntityMappings.Mappings)
+			return fferr.NewInternalErrorf("spark currently does not support multi-entity labels, so mappings must be of length 1; received length %v", labelSchema.EntityMappings.Mappings)
 		}
-		labelSchema = ResourceSchema{
-			EntityMappings: *def.LabelSourceMapping.EntityMappings,
+		if entity := labelSchemas[0].EntityMappings; i > 0 && labelSchema.EntityMappings.Mappings[0].Name != entity.Mappings[0].Name {
+			logger.Errorw("Training set labels have different entities", "label", label, "entity", labelSchema.EntityMappings.Mappings[0].Name, "expected", entity.Mappings[0].Name)
+			return fferr.NewInvalidArgumentErrorf("training set labels must share the entity: label %s (%s) has %s, expected %s",
+				label.Name, label.Variant, labelSchema.EntityMappings.Mappings[0].Name, entity.Mappings[0].Name)
 		}
-	default:
-		logger.Errorw("Unsupported label provider", "provider", def.LabelSourceMapping.ProviderType)
-		return fferr.NewInternalErrorf("unsupported label provider: %s", def.LabelSourceMapping.ProviderType.String())
-	}
-	sourcePaths = append(sourcePaths, labelPySparkSource)
-	spark.Logger.Debugw("Label schema", "schema", labelSchema)
-	// TODO: This is a temporary check to ensure that the entity mappings are correct; once multi-label entity
-	// support is implemented, this check should be removed.
-	if len(labelSchema.EntityMappings.Mappings) != 1 {
-		spark.Logger.Errorw("Spark currently does not support multi-entity labels, so mappings must be of length 1", "length", len(labelSchema.EntityMappings.Mappings), "mappings", labelSchema.EntityMappings.Mappings)
-		return fferr.NewInternalErrorf("spark currently does not support multi-entity labels, so mappings must be of length 1; received length %v", labelSchema.EntityMappings.Mappings)
+		labelSchemas[i] = labelSchema
+		labelSources[i] = labelPySparkSource
 	}
+	// The first label is source_0, the features follow it, and then the rest of the labels.
+	sourcePaths = append(sourcePaths, labelSources[0])
 	for idx, feature := range def.Features {
 		var featureSchema ResourceSchema
 		var featureSourceLocation pl.Location
@@ -1582,19 +2465,22 @@ func sparkTrainingSet(def TrainingSetDef, spark *SparkOfflineStore, isUpdate boo
 		}
 		var tableFormat string
 		if featureSourceLocation.Type() == pl.CatalogLocationType {
-			tableFormat = featureSourceLocation.(*pl.CatalogLocation).TableFormat()
+			tableFormat = catalogTableFormat(featureSourceLocation.(*pl.CatalogLocation), spark.GlueConfig)
 		}
 		featurePySparkSource := sparklib.SourceInfo{
 			Location:     featureSourceLocation.Location(),
 			LocationType: string(featureSourceLocation.Type()),
 			Provider:     spark.Type(),
 			TableFormat:  tableFormat,
+			CSVOptions:   locationCSVOptions(featureSourceLocation),
+			Compression:  locationCompression(featureSourceLocation),
 		}
 		sourcePaths = append(sourcePaths, featurePySparkSource)
 		featureSchemas = append(featureSchemas, featureSchema)
 	}
-	trainingSetQuery := spark.query.trainingSetCreate(def, featureSchemas, labelSchema)
-	sourceMappings := append(def.FeatureSourceMappings, def.LabelSourceMapping)
+	sourcePaths = append(sourcePaths, labelSources[1:]...)
+	trainingSetQuery := spark.query.trainingSetCreate(def, featureSchemas, labelSchemas)
+	sourceMappings := append(append([]SourceMapping{}, def.FeatureSourceMappings...), labelMappings...)
 	sparkArgs, err := sparkScriptCommandDef{
 		DeployMode:     getSparkDeployModeFromEnv(),
 		TFType:         SQLTransformation,
@@ -1614,7 +2500,7 @@ func sparkTrainingSet(def TrainingSetDef, spark *SparkOfflineStore, isUpdate boo
 		MaxJobDuration: time.Hour * 48,
 		JobName:        fmt.Sprintf("featureform-training-set--%s--%s", def.ID.Name, def.ID.Variant),
 	}
-	if err := spark.Executor.RunSparkJob(sparkArgs, spark.Store, opts, nil); err != nil {
+	if err := runSparkJob(spark.Executor, sparkArgs, spark.Store, opts, nil); err != nil {
 		logger.Errorw("Spark submit training set job failed to run", "definition", def.ID, "error", err)
 		return err
 	}
@@ -1627,6 +2513,11 @@ func sparkTrainingSet(def TrainingSetDef, spark *SparkOfflineStore, isUpdate boo
 		spark.Logger.Errorw("Training set doesn't exist after running job")
 		return fferr.NewDatasetNotFoundError(def.ID.Name, def.ID.Variant, fmt.Errorf(destinationPath.ToURI()))
 	}
+	if persistLocation != nil {
+		if err := spark.persistTrainingSet(def, destinationPath, persistLocation, logger); err != nil {
+			return err
+		}
+	}
 	spark.Logger.Infow(
 		"Successfully created training set",
 		"definition",
@@ -1637,6 +2528,121 @@ func sparkTrainingSet(def TrainingSetDef, spark *SparkOfflineStore, isUpdate boo
 	return nil
 }
 
+// trainingSetPersistLocation returns the catalog table that a training set is
+// persisted to. It fails if the table exists and can't be overwritten.
+func (spark *SparkOfflineStore) trainingSetPersistLocation(persist TrainingSetPersistAs, isUpdate bool, logger logging.Logger) (pl.Location, error) {
+	if !spark.UsesCatalog() {
+		logger.Errorw("Persisting training sets requires a Glue catalog")
+		return nil, fferr.NewInvalidArgumentErrorf("persisting training sets requires a Glue config on the Spark provider")
+	}
+	location := pl.NewCatalogLocation(spark.GlueConfig.Database, persist.Table, string(spark.GlueConfig.TableFormat))
+	if persist.Overwrite || isUpdate {
+		return location, nil
+	}
+	exists, err := spark.Store.Exists(location)
+	if err != nil {
+		logger.Errorw("Unable to check if persist table exists", "location", location.Location(), "error", err)
+		return nil, err
+	}
+	if exists {
+		logger.Errorw("Persist table already exists", "location", location.Location())
+		return nil, fferr.NewDatasetAlreadyExistsError(persist.Table, "", fmt.Errorf(location.Location()))
+	}
+	return location, nil
+}
+
+// persistTrainingSet copies the latest run of the training set into the catalog
+// table at location, replacing the table if it exists.
+func (spark *SparkOfflineStore) persistTrainingSet(def TrainingSetDef, trainingSetPath filestore.Filepath, location pl.Location, logger logging.Logger) error {
+	logger = logger.With("persist_location", location.Location())
+	newestFile, err := spark.Store.NewestFileOfType(trainingSetPath, filestore.Parquet)
+	if err != nil {
+		logger.Errorw("Could not get the latest run of the training set", "error", err)
+		return err
+	}
+	runPath, err := spark.Store.CreateFilePath(newestFile.KeyPrefix(), true)
+	if err != nil {
+		logger.Errorw("Could not create the path of the latest run of the training set", "error", err)
+		return err
+	}
+	sparkArgs, err := sparkScriptCommandDef{
+		DeployMode:     getSparkDeployModeFromEnv(),
+		TFType:         SQLTransformation,
+		OutputLocation: location,
+		Code:           def.PersistAs.selectQuery("source_0"),
+		SourceList: []sparklib.SourceInfo{{
+			Location:     runPath.ToURI(),
+			LocationType: string(pl.FileStoreLocationType),
+			Provider:     pt.SparkOffline,
+		}},
+		JobType: types.Transform,
+		Store:   spark.Store,
+	}.PrepareCommand(logger)
+	if err != nil {
+		logger.Errorw("Problem creating spark submit arguments", "error", err)
+		return err
+	}
+	opts := SparkJobOptions{
+		MaxJobDuration: time.Hour * 48,
+		JobName:        fmt.Sprintf("featureform-persist-training-set--%s--%s", def.ID.Name, def.ID.Variant),
+	}
+	if err := runSparkJob(spark.Executor, sparkArgs, spark.Store, opts, nil); err != nil {
+		logger.Errorw("Spark submit persist training set job failed to run", "error", err)
+		return err
+	}
+	logger.Infow("Persisted training set", "dedup", def.PersistAs.Dedup)
+	return nil
+}
+
+// trainingSetLabelSource returns the schema of a training set's label and the
+// source its values are read from.
+func (spark *SparkOfflineStore) trainingSetLabelSource(label ResourceID, mapping SourceMapping, logger logging.Logger) (ResourceSchema, sparklib.SourceInfo, error) {
+	logger.Debugw("Label provider", "label", label, "provider", mapping.ProviderType)
+	switch mapping.ProviderType {
+	case pt.SparkOffline:
+		labelSchema, err := spark.getResourceSchema(label)
+		if err != nil {
+			logger.Errorw("Could not get schema of label in spark store", "label", label, "error", err)
+			return ResourceSchema{}, sparklib.SourceInfo{}, err
+		}
+		var tableFormat string
+		if labelSchema.SourceTable.Type() == pl.CatalogLocationType {
+			tableFormat = catalogTableFormat(labelSchema.SourceTable.(*pl.CatalogLocation), spark.GlueConfig)
+		}
+		return labelSchema, sparklib.SourceInfo{
+			Location:     labelSchema.SourceTable.Location(),
+			LocationType: string(labelSchema.SourceTable.Type()),
+			Provider:     mapping.ProviderType,
+			TableFormat:  tableFormat,
+			CSVOptions:   locationCSVOptions(labelSchema.SourceTable),
+			Compression:  locationCompression(labelSchema.SourceTable),
+		}, nil
+	case pt.SnowflakeOffline:
+		if mapping.EntityMappings == nil {
+			logger.Errorw("Snowflake label has no entity mappings", "label", label)
+			return ResourceSchema{}, sparklib.SourceInfo{}, fferr.NewInvalidArgumentErrorf("snowflake label %s (%s) has no entity mappings", label.Name, label.Variant)
+		}
+		config := pc.SnowflakeConfig{}
+		if err := config.Deserialize(mapping.ProviderConfig); err != nil {
+			logger.Errorw("Error deserializing snowflake config", "error", err)
+			return ResourceSchema{}, sparklib.SourceInfo{}, err
+		}
+		labelSchema := ResourceSchema{
+			EntityMappings: *mapping.EntityMappings,
+		}
+		return labelSchema, sparklib.SourceInfo{
+			Location:     mapping.Source,
+			LocationType: string(pl.SQLLocationType),
+			Provider:     mapping.ProviderType,
+			Database:     config.Database,
+			Schema:       config.Schema,
+		}, nil
+	default:
+		logger.Errorw("Unsupported label provider", "provider", mapping.ProviderType)
+		return ResourceSchema{}, sparklib.SourceInfo{}, fferr.NewInternalErrorf("unsupported label provider: %s", mapping.ProviderType.String())
+	}
+}
+
 func (spark *SparkOfflineStore) CreateTrainingSet(def TrainingSetDef) error {
 	return sparkTrainingSet(def, spark, false)
 }
@@ -1649,6 +2655,10 @@ func (spark *SparkOfflineStore) GetTrainingSet(id ResourceID) (TrainingSetIterat
 	return fileStoreGetTrainingSet(id, spark.Store, spark.Logger.SugaredLogger)
 }
 
+func (spark *SparkOfflineStore) GetTrainingSetSplit(id ResourceID, split string) (TrainingSetIterator, error) {
+	return fileStoreGetTrainingSetSplit(id, split, spark.Store, spark.Logger.SugaredLogger)
+}
+
 func (spark *SparkOfflineStore) CreateTrainTestSplit(def TrainTestSplitDef) (func() error, error) {
 	return nil, fmt.Errorf("not Implemented")
 }
@@ -1661,6 +2671,10 @@ func (spark *SparkOfflineStore) GetTrainTestSplit(def TrainTestSplitDef) (
 	return nil, nil, fmt.Errorf("not Implemented")
 }
 
+func (spark *SparkOfflineStore) ExportTrainingSet(id ResourceID, location pl.Location, format filestore.FileType) ([]filestore.Filepath, error) {
+	return fileStoreExportTrainingSet(id, spark.Store, location, format, spark.Logger.SugaredLogger)
+}
+
 func (spark *SparkOfflineStore) UsesCatalog() bool {
 	return spark.GlueConfig != nil
 }
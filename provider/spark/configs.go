@@ -10,6 +10,8 @@ package spark
 import (
 	"encoding/base64"
 	"fmt"
+	"sort"
+	"strconv"
 	"strings"
 
 	"github.com/featureform/config"
@@ -728,6 +730,11 @@ type S3Flags struct {
 	// Region defaults to us-east-1 if not set.
 	Region string
 	Bucket string
+	// Endpoint is set for S3-compatible stores, it defaults to the AWS
+	// endpoint of Region.
+	Endpoint string
+	// ForcePathStyle addresses buckets by path instead of by virtual host.
+	ForcePathStyle bool
 }
 
 func (args S3Flags) SparkFlags() Flags {
@@ -735,6 +742,10 @@ func (args S3Flags) SparkFlags() Flags {
 	if args.Region == "" {
 		args.Region = "us-east-1"
 	}
+	endpoint := args.Endpoint
+	if endpoint == "" {
+		endpoint = fmt.Sprintf("s3.%s.amazonaws.com", args.Region)
+	}
 	flags := Flags{
 		ConfigFlag{
 			Key:   "spark.hadoop.fs.s3.impl",
@@ -750,13 +761,33 @@ func (args S3Flags) SparkFlags() Flags {
 		},
 		ConfigFlag{
 			Key:   "fs.s3a.endpoint",
-			Value: fmt.Sprintf("s3.%s.amazonaws.com", args.Region),
+			Value: endpoint,
 		},
 		ScriptFlag{
 			Key:   "store_type",
 			Value: "s3",
 		},
 	}
+	if args.Endpoint != "" {
+		flags = append(flags,
+			CredFlag{
+				Key:   "aws_endpoint_url",
+				Value: args.Endpoint,
+			},
+		)
+	}
+	if args.ForcePathStyle {
+		flags = append(flags,
+			ConfigFlag{
+				Key:   "fs.s3a.path.style.access",
+				Value: "true",
+			},
+			CredFlag{
+				Key:   "aws_force_path_style",
+				Value: "true",
+			},
+		)
+	}
 	if args.AccessKey != "" && args.SecretKey != "" {
 		flags = append(flags,
 			ConfigFlag{
@@ -793,10 +824,12 @@ func (args S3Flags) SparkFlags() Flags {
 
 func (args S3Flags) Redacted() Config {
 	return S3Flags{
-		AccessKey: redacted.String,
-		SecretKey: redacted.String,
-		Region:    args.Region,
-		Bucket:    args.Bucket,
+		AccessKey:      redacted.String,
+		SecretKey:      redacted.String,
+		Region:         args.Region,
+		Bucket:         args.Bucket,
+		Endpoint:       args.Endpoint,
+		ForcePathStyle: args.ForcePathStyle,
 	}
 }
 
@@ -1013,6 +1046,125 @@ func (args DynamoFlags) Redacted() Config {
 	}
 }
 
+type RedisFlags struct {
+	Addr     string
+	Username string
+	Password string
+	DB       int
+	// PipelineSize is the number of writes sent to Redis at once.
+	PipelineSize int
+	// Topology is standalone, cluster, or sentinel. The job connects to
+	// ClusterAddrs or SentinelAddrs instead of Addr for the latter two.
+	Topology           string
+	ClusterAddrs       []string
+	SentinelMasterName string
+	SentinelAddrs      []string
+	TLS                bool
+	// TLSCACert, TLSCert, and TLSKey are PEM encoded.
+	TLSCACert             string
+	TLSCert               string
+	TLSKey                string
+	TLSInsecureSkipVerify bool
+}
+
+func (args RedisFlags) SparkFlags() Flags {
+	flags := Flags{
+		CredFlag{
+			Key:   "redis_addr",
+			Value: args.Addr,
+		},
+		CredFlag{
+			Key:   "redis_password",
+			Value: args.Password,
+		},
+		CredFlag{
+			Key:   "redis_db",
+			Value: strconv.Itoa(args.DB),
+		},
+		CredFlag{
+			Key:   "redis_pipeline_size",
+			Value: strconv.Itoa(args.PipelineSize),
+		},
+	}
+	optional := []CredFlag{
+		{Key: "redis_username", Value: args.Username},
+		{Key: "redis_cluster_addrs", Value: strings.Join(args.ClusterAddrs, ",")},
+		{Key: "redis_sentinel_master_name", Value: args.SentinelMasterName},
+		{Key: "redis_sentinel_addrs", Value: strings.Join(args.SentinelAddrs, ",")},
+		// The PEM blocks span lines, so they're base64 encoded to fit in a single argument.
+		{Key: "redis_tls_ca_cert", Value: base64.StdEncoding.EncodeToString([]byte(args.TLSCACert))},
+		{Key: "redis_tls_cert", Value: base64.StdEncoding.EncodeToString([]byte(args.TLSCert))},
+		{Key: "redis_tls_key", Value: base64.StdEncoding.EncodeToString([]byte(args.TLSKey))},
+	}
+	if args.Topology != "" && args.Topology != "standalone" {
+		flags = append(flags, CredFlag{Key: "redis_topology", Value: args.Topology})
+	}
+	for _, flag := range optional {
+		if flag.Value != "" {
+			flags = append(flags, flag)
+		}
+	}
+	if args.TLS {
+		flags = append(flags, CredFlag{Key: "redis_tls", Value: "true"})
+	}
+	if args.TLSInsecureSkipVerify {
+		flags = append(flags, CredFlag{Key: "redis_tls_insecure_skip_verify", Value: "true"})
+	}
+	return flags
+}
+
+func (args RedisFlags) Redacted() Config {
+	redactedArgs := args
+	redactedArgs.Password = redacted.String
+	if args.TLSKey != "" {
+		redactedArgs.TLSKey = redacted.String
+	}
+	return redactedArgs
+}
+
+type CosmosFlags struct {
+	Host     string
+	Port     string
+	Username string
+	Password string
+	Database string
+}
+
+func (args CosmosFlags) SparkFlags() Flags {
+	return Flags{
+		CredFlag{
+			Key:   "cosmos_host",
+			Value: args.Host,
+		},
+		CredFlag{
+			Key:   "cosmos_port",
+			Value: args.Port,
+		},
+		CredFlag{
+			Key:   "cosmos_username",
+			Value: args.Username,
+		},
+		CredFlag{
+			Key:   "cosmos_password",
+			Value: args.Password,
+		},
+		CredFlag{
+			Key:   "cosmos_database",
+			Value: args.Database,
+		},
+	}
+}
+
+func (args CosmosFlags) Redacted() Config {
+	return CosmosFlags{
+		Host:     args.Host,
+		Port:     args.Port,
+		Username: args.Username,
+		Password: redacted.String,
+		Database: args.Database,
+	}
+}
+
 type JobTypeFlag struct {
 	Type types.Job
 }
@@ -1086,6 +1238,17 @@ func (flag LegacyOutputFormatFlag) SparkFlags() Flags {
 				Value: string(flag.FileType),
 			},
 		}
+	case filestore.Avro:
+		// Avro is an external data source, so its package has to be pulled in.
+		return Flags{
+			ScriptFlag{
+				Key:   "output_format",
+				Value: string(flag.FileType),
+			},
+			PackagesFlag{
+				Packages: []string{"org.apache.spark:spark-avro_2.12:3.5.1"},
+			},
+		}
 	case filestore.NilFileType:
 		// Default to Parquet
 		return Flags{
@@ -1137,6 +1300,26 @@ func (flag LegacyIncludeHeadersFlag) Redacted() Config {
 	return flag
 }
 
+// PartitionByFlag makes the script partition its file output by the given columns.
+type PartitionByFlag struct {
+	Columns []string
+}
+
+func (flag PartitionByFlag) SparkFlags() Flags {
+	flags := make(Flags, len(flag.Columns))
+	for i, col := range flag.Columns {
+		flags[i] = ScriptFlag{
+			Key:   "partition_by",
+			Value: col,
+		}
+	}
+	return flags
+}
+
+func (flag PartitionByFlag) Redacted() Config {
+	return flag
+}
+
 type MasterFlag struct {
 	Master string
 }
@@ -1194,3 +1377,45 @@ func (args HighMemoryFlags) SparkFlags() Flags {
 func (args HighMemoryFlags) Redacted() Config {
 	return args
 }
+
+// SubmitOptionsFlags are the user supplied spark-submit options of a
+// transformation. Configs are emitted in key order so that the command is
+// deterministic.
+type SubmitOptionsFlags struct {
+	Options pc.SparkSubmitOptions
+}
+
+func (args SubmitOptionsFlags) SparkFlags() Flags {
+	keys := make([]string, 0, len(args.Options.Configs))
+	for key := range args.Options.Configs {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	flags := make(Flags, 0, len(keys)+1)
+	for _, key := range keys {
+		flags = append(flags, NativeConfigFlag{
+			Key:   key,
+			Value: args.Options.Configs[key],
+		})
+	}
+	if len(args.Options.Packages) > 0 {
+		flags = append(flags, PackagesFlag{
+			Packages: args.Options.Packages,
+		})
+	}
+	return flags
+}
+
+// Redacted hides the config values since they may hold credentials.
+func (args SubmitOptionsFlags) Redacted() Config {
+	configs := make(map[string]string, len(args.Options.Configs))
+	for key := range args.Options.Configs {
+		configs[key] = redacted.String
+	}
+	return SubmitOptionsFlags{
+		Options: pc.SparkSubmitOptions{
+			Configs:  configs,
+			Packages: args.Options.Packages,
+		},
+	}
+}
@@ -12,6 +12,7 @@ import (
 	"testing"
 
 	"github.com/featureform/filestore"
+	pc "github.com/featureform/provider/provider_config"
 )
 
 func TestSparkConfig(t *testing.T) {
@@ -36,6 +37,117 @@ func TestSparkConfig(t *testing.T) {
 				"\"spark.sql.extensions=org.apache.iceberg.spark.extensions.IcebergSparkSessionExtensions\"",
 			},
 		},
+		"SubmitOptions": testCase{
+			Configs: Configs{
+				IcebergFlags{},
+				SubmitOptionsFlags{
+					Options: pc.SparkSubmitOptions{
+						Configs: map[string]string{
+							"spark.sql.shuffle.partitions": "64",
+							"spark.executor.memory":        "4g",
+						},
+						Packages: []string{
+							"org.apache.iceberg:iceberg-spark-runtime-3.5_2.12:1.6.1",
+							"io.delta:delta-spark_2.12:3.2.0",
+						},
+					},
+				},
+			},
+			Expected: []string{
+				"spark-submit",
+				"--packages",
+				"org.apache.iceberg:iceberg-spark-runtime-3.5_2.12:1.6.1,io.delta:delta-spark_2.12:3.2.0",
+				"--conf",
+				"spark.executor.memory=4g",
+				"--conf",
+				"spark.sql.shuffle.partitions=64",
+				"/",
+				"--spark_config",
+				"\"spark.sql.extensions=org.apache.iceberg.spark.extensions.IcebergSparkSessionExtensions\"",
+			},
+		},
+		"Redis": testCase{
+			Configs: Configs{
+				RedisFlags{
+					Addr:         "localhost:6379",
+					Password:     "secret",
+					DB:           2,
+					PipelineSize: 500,
+				},
+			},
+			Expected: []string{
+				"spark-submit",
+				"/",
+				"--credential",
+				"\"redis_addr=localhost:6379\"",
+				"--credential",
+				"\"redis_password=secret\"",
+				"--credential",
+				"\"redis_db=2\"",
+				"--credential",
+				"\"redis_pipeline_size=500\"",
+			},
+		},
+		"RedisClusterTLS": testCase{
+			Configs: Configs{
+				RedisFlags{
+					Username:     "featureform",
+					Password:     "secret",
+					PipelineSize: 500,
+					Topology:     "cluster",
+					ClusterAddrs: []string{"node-1:6379", "node-2:6379"},
+					TLS:          true,
+					TLSCACert:    "ca",
+				},
+			},
+			Expected: []string{
+				"spark-submit",
+				"/",
+				"--credential",
+				"\"redis_addr=\"",
+				"--credential",
+				"\"redis_password=secret\"",
+				"--credential",
+				"\"redis_db=0\"",
+				"--credential",
+				"\"redis_pipeline_size=500\"",
+				"--credential",
+				"\"redis_topology=cluster\"",
+				"--credential",
+				"\"redis_username=featureform\"",
+				"--credential",
+				"\"redis_cluster_addrs=node-1:6379,node-2:6379\"",
+				"--credential",
+				"\"redis_tls_ca_cert=Y2E=\"",
+				"--credential",
+				"\"redis_tls=true\"",
+			},
+		},
+		"Cosmos": testCase{
+			Configs: Configs{
+				CosmosFlags{
+					Host:     "account.mongo.cosmos.azure.com",
+					Port:     "10255",
+					Username: "account",
+					Password: "secret",
+					Database: "featureform",
+				},
+			},
+			Expected: []string{
+				"spark-submit",
+				"/",
+				"--credential",
+				"\"cosmos_host=account.mongo.cosmos.azure.com\"",
+				"--credential",
+				"\"cosmos_port=10255\"",
+				"--credential",
+				"\"cosmos_username=account\"",
+				"--credential",
+				"\"cosmos_password=secret\"",
+				"--credential",
+				"\"cosmos_database=featureform\"",
+			},
+		},
 	}
 	for name, test := range testCases {
 		t.Run(name, func(t *testing.T) {
@@ -46,3 +158,20 @@ func TestSparkConfig(t *testing.T) {
 		})
 	}
 }
+
+func TestRedisFlagsRedacted(t *testing.T) {
+	flags := RedisFlags{Addr: "localhost:6379", Password: "secret", DB: 2, TLS: true, TLSCert: "cert", TLSKey: "key"}
+	redactedFlags, ok := flags.Redacted().(RedisFlags)
+	if !ok {
+		t.Fatalf("Expected RedisFlags, got %T", flags.Redacted())
+	}
+	if redactedFlags.Password == flags.Password {
+		t.Fatalf("Redis password was not redacted")
+	}
+	if redactedFlags.TLSKey == flags.TLSKey {
+		t.Fatalf("Redis TLS key was not redacted")
+	}
+	if redactedFlags.Addr != flags.Addr || redactedFlags.DB != flags.DB {
+		t.Fatalf("Non-secret Redis flags changed on redaction: %#v", redactedFlags)
+	}
+}
@@ -11,6 +11,7 @@ import (
 	"encoding/json"
 
 	"github.com/featureform/fferr"
+	pl "github.com/featureform/provider/location"
 	pt "github.com/featureform/provider/provider_type"
 )
 
@@ -23,6 +24,12 @@ type SourceInfo struct {
 	// FileType and IsDir are used for file sources
 	FileType string `json:"fileType"`
 	IsDir    bool   `json:"isDir"`
+	// CSVOptions is set for delimited file sources that don't use the default
+	// CSV format
+	CSVOptions *pl.CSVOptions `json:"csvOptions,omitempty"`
+	// Compression is the codec of compressed file sources, like gzip for
+	// a .csv.gz file
+	Compression string `json:"compression,omitempty"`
 	// Database and Schema are used for Snowflake sources
 	Database string `json:"database"`
 	Schema   string `json:"schema"`
@@ -78,6 +78,8 @@ type sparkScriptCommandDef struct {
 	Store SparkFileStoreV2
 	// Mappings provides SourceMappings for use alongside SourceList
 	Mappings []SourceMapping
+	// SubmitOptions are user supplied spark-submit options for transformations.
+	SubmitOptions pc.SparkSubmitOptions
 }
 
 func (def sparkScriptCommandDef) Redacted() map[string]any {
@@ -101,6 +103,7 @@ func (def sparkScriptCommandDef) Redacted() map[string]any {
 		"SourceList":     def.SourceList,
 		"JobType":        def.JobType,
 		"Mappings":       redactedMapping,
+		"SubmitOptions":  spark.SubmitOptionsFlags{Options: def.SubmitOptions}.Redacted(),
 		"FileStoreType":  def.Store.FilestoreType(),
 		"SparkStoreType": def.Store.Type(),
 	}
@@ -171,6 +174,15 @@ func (def sparkScriptCommandDef) PrepareCommand(logger logging.Logger) (*spark.C
 			Sources: def.SourceList,
 		})
 	}
+	if !def.SubmitOptions.IsEmpty() {
+		if err := def.SubmitOptions.Validate(); err != nil {
+			logger.Errorw("Invalid spark submit options", "error", err)
+			return nil, err
+		}
+		cmd.AddConfigs(spark.SubmitOptionsFlags{
+			Options: def.SubmitOptions,
+		})
+	}
 	// EMR's API enforces a 10K-character (i.e. bytes) limit on string values passed to HadoopJarStep, so to avoid a 400, we need
 	// to check to ensure the args are below this limit. If they exceed this limit, it's most likely due to the query and/or the list
 	// of sources, so we write these as a JSON file and read them from the PySpark runner script to side-step this constraint
@@ -265,6 +277,27 @@ func removeEscapeCharacters(values []string) []string {
 	return values
 }
 
+const (
+	// sparkLogTailLines and sparkLogTailBytes bound how much of a failed job's
+	// driver log is included in the error returned to the caller.
+	sparkLogTailLines = 50
+	sparkLogTailBytes = 4096
+)
+
+// sparkLogTail returns the end of a Spark driver log, which is where the Python
+// traceback of a failed job ends up.
+func sparkLogTail(log string) string {
+	lines := strings.Split(strings.TrimRight(log, "\n"), "\n")
+	if len(lines) > sparkLogTailLines {
+		lines = lines[len(lines)-sparkLogTailLines:]
+	}
+	tail := strings.Join(lines, "\n")
+	if len(tail) > sparkLogTailBytes {
+		tail = strings.ToValidUTF8(tail[len(tail)-sparkLogTailBytes:], "")
+	}
+	return tail
+}
+
 func exceedsSubmitParamsTotalByteLimit(cmd *spark.Command) bool {
 	args := cmd.Compile()
 	totalBytes := 0
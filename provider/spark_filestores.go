@@ -110,10 +110,12 @@ func (s3 SparkS3FileStore) SparkConfigs() spark.Configs {
 	}
 	return spark.Configs{
 		spark.S3Flags{
-			AccessKey: accessKey,
-			SecretKey: secretKey,
-			Region:    s3.BucketRegion,
-			Bucket:    s3.Bucket,
+			AccessKey:      accessKey,
+			SecretKey:      secretKey,
+			Region:         s3.BucketRegion,
+			Bucket:         s3.Bucket,
+			Endpoint:       s3.Endpoint,
+			ForcePathStyle: s3.ForcePathStyle,
 		},
 	}
 }
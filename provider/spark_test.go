@@ -22,6 +22,7 @@ import (
 	"strings"
 	"testing"
 	"time"
+	"unicode/utf8"
 
 	"github.com/featureform/fferr"
 	"github.com/featureform/filestore"
@@ -1750,7 +1751,7 @@ func TestTrainingSetCreate(t *testing.T) {
 		EntityMappings: metadata.EntityMappings{Mappings: []metadata.EntityMapping{{Name: "user", EntityColumn: "entity"}}, ValueColumn: "label_value", TimestampColumn: "ts"},
 	}
 	queries := defaultPythonOfflineQueries{}
-	trainingSetQuery := queries.trainingSetCreate(testTrainingSetDef, testFeatureSchemas, testLabelSchema)
+	trainingSetQuery := queries.trainingSetCreate(testTrainingSetDef, testFeatureSchemas, []ResourceSchema{testLabelSchema})
 
 	correctQuery := "SELECT `Feature__test_feature_1__default`, `Feature__test_feature_2__default`, `Label__test_label__default` " +
 		"FROM (SELECT * FROM (SELECT *, row_number FROM (SELECT `Feature__test_feature_1__default`, `Feature__test_feature_2__default`, " +
@@ -1766,6 +1767,361 @@ func TestTrainingSetCreate(t *testing.T) {
 	}
 }
 
+func TestTrainingSetCreateWithSplit(t *testing.T) {
+	def := TrainingSetDef{
+		ID:       ResourceID{"test_training_set", "default", TrainingSet},
+		Features: []ResourceID{{"test_feature_1", "default", Feature}},
+		Label:    ResourceID{"test_label", "default", Label},
+		Split:    &TrainingSetSplit{TrainFraction: 0.8, Seed: 7},
+	}
+	featureSchemas := []ResourceSchema{
+		{
+			Entity:         "entity",
+			Value:          "feature_value_1",
+			TS:             "ts",
+			EntityMappings: metadata.EntityMappings{Mappings: []metadata.EntityMapping{{Name: "user", EntityColumn: "entity"}}},
+		},
+	}
+	labelSchema := ResourceSchema{
+		EntityMappings: metadata.EntityMappings{Mappings: []metadata.EntityMapping{{Name: "user", EntityColumn: "entity"}}, ValueColumn: "label_value", TimestampColumn: "ts"},
+	}
+	queries := defaultPythonOfflineQueries{}
+	query := queries.trainingSetCreate(def, featureSchemas, []ResourceSchema{labelSchema})
+
+	expectedSplit := "`Label__test_label__default`, CASE WHEN pmod(xxhash64(CAST(entity AS STRING), CAST(label_ts AS STRING), CAST(7 AS BIGINT)), 10000) < 8000 " +
+		"THEN 'train' ELSE 'test' END AS ff_split FROM"
+	if !strings.Contains(query, expectedSplit) {
+		t.Fatalf("training set query missing split column, got %s", query)
+	}
+	if again := queries.trainingSetCreate(def, featureSchemas, []ResourceSchema{labelSchema}); again != query {
+		t.Fatalf("training set query is not stable:\n%s\n%s", query, again)
+	}
+}
+
+func TestTrainingSetCreateWithMaxFeatureAge(t *testing.T) {
+	def := TrainingSetDef{
+		ID:            ResourceID{"test_training_set", "default", TrainingSet},
+		Features:      []ResourceID{{"test_feature_1", "default", Feature}},
+		Label:         ResourceID{"test_label", "default", Label},
+		MaxFeatureAge: 2 * time.Hour,
+	}
+	featureSchemas := []ResourceSchema{
+		{
+			Entity:         "entity",
+			Value:          "feature_value_1",
+			TS:             "ts",
+			EntityMappings: metadata.EntityMappings{Mappings: []metadata.EntityMapping{{Name: "user", EntityColumn: "entity"}}},
+		},
+	}
+	labelSchema := ResourceSchema{
+		EntityMappings: metadata.EntityMappings{Mappings: []metadata.EntityMapping{{Name: "user", EntityColumn: "entity"}}, ValueColumn: "label_value", TimestampColumn: "ts"},
+	}
+	queries := defaultPythonOfflineQueries{}
+	query := queries.trainingSetCreate(def, featureSchemas, []ResourceSchema{labelSchema})
+
+	expectedJoin := "t1 ON (t1_entity = entity AND t1_ts <= label_ts AND t1_ts >= label_ts - INTERVAL 7200 SECOND)"
+	if !strings.Contains(query, expectedJoin) {
+		t.Fatalf("training set query missing feature age window, got %s", query)
+	}
+}
+
+func TestTrainingSetCreateMultipleLabels(t *testing.T) {
+	def := TrainingSetDef{
+		ID:       ResourceID{"test_training_set", "default", TrainingSet},
+		Features: []ResourceID{{"test_feature_1", "default", Feature}},
+		Labels: []ResourceID{
+			{"test_label_1", "default", Label},
+			{"test_label_2", "default", Label},
+		},
+	}
+	if err := def.check(); err != nil {
+		t.Fatalf("could not check training set: %v", err)
+	}
+	featureSchemas := []ResourceSchema{
+		{
+			Entity:         "entity",
+			Value:          "feature_value_1",
+			TS:             "ts",
+			EntityMappings: metadata.EntityMappings{Mappings: []metadata.EntityMapping{{Name: "user", EntityColumn: "entity"}}},
+		},
+	}
+	labelSchemas := []ResourceSchema{
+		{EntityMappings: metadata.EntityMappings{Mappings: []metadata.EntityMapping{{Name: "user", EntityColumn: "entity"}}, ValueColumn: "label_value_1", TimestampColumn: "ts"}},
+		{EntityMappings: metadata.EntityMappings{Mappings: []metadata.EntityMapping{{Name: "user", EntityColumn: "user_id"}}, ValueColumn: "label_value_2"}},
+	}
+	queries := defaultPythonOfflineQueries{}
+	query := queries.trainingSetCreate(def, featureSchemas, labelSchemas)
+
+	correctQuery := "SELECT `Feature__test_feature_1__default`, `Label__test_label_1__default`, `Label__test_label_2__default` " +
+		"FROM (SELECT * FROM (SELECT *, row_number FROM (SELECT `Feature__test_feature_1__default`, " +
+		"value AS `Label__test_label_1__default`, `Label__test_label_2__default`, entity, label_ts, t1_ts, ROW_NUMBER() over (PARTITION BY entity, value, label_ts ORDER BY " +
+		"label_ts DESC, t1_ts DESC) as row_number FROM ((SELECT * FROM (SELECT entity, value, label_ts FROM (SELECT entity AS entity, label_value_1 " +
+		"AS value, ts AS label_ts FROM source_0) t ) t0) LEFT OUTER JOIN (SELECT * FROM (SELECT entity as t1_entity, feature_value_1 as " +
+		"`Feature__test_feature_1__default`, ts as t1_ts FROM source_1) ORDER BY t1_ts ASC) t1 ON (t1_entity = entity AND t1_ts <= label_ts) " +
+		"LEFT OUTER JOIN (SELECT user_id as l1_entity, label_value_2 as `Label__test_label_2__default`, CAST(0 AS TIMESTAMP) as l1_ts " +
+		"FROM source_2) l1 ON (l1_entity = entity AND l1_ts = label_ts)) tt) WHERE row_number=1 ))  ORDER BY label_ts"
+
+	if query != correctQuery {
+		t.Fatalf("training set query not correct, got %s, expected %s", query, correctQuery)
+	}
+}
+
+func TestCatalogTableFormat(t *testing.T) {
+	glueConfig := &pc.GlueConfig{TableFormat: pc.DeltaLake}
+	tests := []struct {
+		name       string
+		location   *pl.CatalogLocation
+		glueConfig *pc.GlueConfig
+		expected   string
+	}{
+		{"Explicit format", pl.NewCatalogLocation("db", "table", string(pc.Iceberg)).(*pl.CatalogLocation), glueConfig, string(pc.Iceberg)},
+		{"Falls back to Glue format", pl.NewCatalogLocation("db", "table", "").(*pl.CatalogLocation), glueConfig, string(pc.DeltaLake)},
+		{"No Glue config", pl.NewCatalogLocation("db", "table", "").(*pl.CatalogLocation), nil, ""},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := catalogTableFormat(test.location, test.glueConfig); got != test.expected {
+				t.Fatalf("expected table format %q, got %q", test.expected, got)
+			}
+		})
+	}
+}
+
+func TestIncrementalMaterializationCreate(t *testing.T) {
+	t.Setenv("MATERIALIZE_WITH_TIMESTAMP_QUERY_PATH", "queries/materialize_ts.sql")
+	queries := defaultPythonOfflineQueries{Logger: logging.NewTestLogger(t)}
+	schema := ResourceSchema{Entity: "entity", Value: "value", TS: "ts"}
+	watermark := time.UnixMicro(1_700_000_000_000_000).UTC()
+	query, err := queries.incrementalMaterializationCreate(schema, watermark, "")
+	if err != nil {
+		t.Fatalf("could not create query: %v", err)
+	}
+	expectedSource := "(SELECT * FROM source_0 WHERE ts > timestamp_micros(1700000000000000))"
+	if strings.Count(query, expectedSource) != 2 {
+		t.Fatalf("expected both source references to be filtered by the watermark, got %s", query)
+	}
+	schema.TS = ""
+	if _, err := queries.incrementalMaterializationCreate(schema, watermark, ""); err == nil {
+		t.Fatalf("expected error for schema without a timestamp column")
+	}
+}
+
+func TestMaterializationCreateWithFilter(t *testing.T) {
+	t.Setenv("MATERIALIZE_WITH_TIMESTAMP_QUERY_PATH", "queries/materialize_ts.sql")
+	t.Setenv("MATERIALIZE_NO_TIMESTAMP_QUERY_PATH", "queries/materialize_no_ts.sql")
+	queries := defaultPythonOfflineQueries{Logger: logging.NewTestLogger(t)}
+	filter := "status = 'active'"
+	schema := ResourceSchema{Entity: "entity", Value: "value", TS: "ts"}
+	query, err := queries.materializationCreate(schema, filter)
+	if err != nil {
+		t.Fatalf("could not create query: %v", err)
+	}
+	expectedSource := "(SELECT * FROM source_0 WHERE status = 'active')"
+	if strings.Count(query, expectedSource) != 2 {
+		t.Fatalf("expected both source references to be filtered, got %s", query)
+	}
+	watermark := time.UnixMicro(1_700_000_000_000_000).UTC()
+	query, err = queries.incrementalMaterializationCreate(schema, watermark, filter)
+	if err != nil {
+		t.Fatalf("could not create query: %v", err)
+	}
+	expectedSource = "(SELECT * FROM source_0 WHERE ts > timestamp_micros(1700000000000000) AND (status = 'active'))"
+	if strings.Count(query, expectedSource) != 2 {
+		t.Fatalf("expected both source references to be filtered, got %s", query)
+	}
+	schema.TS = ""
+	query, err = queries.materializationCreate(schema, filter)
+	if err != nil {
+		t.Fatalf("could not create query: %v", err)
+	}
+	if !strings.Contains(query, "FROM (SELECT * FROM source_0 WHERE status = 'active')") {
+		t.Fatalf("expected source to be filtered, got %s", query)
+	}
+}
+
+func TestSparkSourceColumns(t *testing.T) {
+	store, err := NewLocalFileStore([]byte(fmt.Sprintf(`{"DirPath": "file://%s/"}`, t.TempDir())))
+	if err != nil {
+		t.Fatalf("could not create local file store: %v", err)
+	}
+	path, err := store.CreateFilePath("users.csv", false)
+	if err != nil {
+		t.Fatalf("could not create file path: %v", err)
+	}
+	if err := store.Write(path, []byte("user_id,status\n1,active\n")); err != nil {
+		t.Fatalf("could not write file: %v", err)
+	}
+	spark := &SparkOfflineStore{Store: &SparkLocalFileStore{store.(*LocalFileStore)}, Logger: logging.NewTestLogger(t)}
+	id := ResourceID{Name: "status", Variant: "v1", Type: Feature}
+	columns, err := spark.sourceColumns(id, pl.NewFileLocation(path))
+	if err != nil {
+		t.Fatalf("could not read source columns: %v", err)
+	}
+	if !reflect.DeepEqual(columns, []string{"user_id", "status"}) {
+		t.Fatalf("unexpected columns %v", columns)
+	}
+	if err := validateMaterializationFilter("status = 'active'", columns); err != nil {
+		t.Fatalf("expected filter to be valid: %v", err)
+	}
+	if _, ok := validateMaterializationFilter("deleted = false", columns).(*fferr.InvalidArgumentError); !ok {
+		t.Fatalf("expected an invalid argument error for an unknown column")
+	}
+	columns, err = spark.sourceColumns(id, pl.NewCatalogLocation("db", "users", "iceberg"))
+	if err != nil || columns != nil {
+		t.Fatalf("expected catalog columns to be left to Spark, got %v, %v", columns, err)
+	}
+}
+
+func TestIncrementalSparkQuery(t *testing.T) {
+	query := incrementalSparkQuery("SELECT * FROM source_0", "event ts", 1)
+	expected := "SELECT * FROM source_1 UNION ALL SELECT * FROM ( SELECT * FROM source_0 ) AS incremental " +
+		"WHERE NOT EXISTS (SELECT 1 FROM source_1) OR incremental.`event ts` > (SELECT MAX(`event ts`) FROM source_1)"
+	if query != expected {
+		t.Fatalf("expected %s, got %s", expected, query)
+	}
+}
+
+func TestMaterializationCreateWithColumnCasts(t *testing.T) {
+	t.Setenv("MATERIALIZE_WITH_TIMESTAMP_QUERY_PATH", "queries/materialize_ts.sql")
+	t.Setenv("MATERIALIZE_NO_TIMESTAMP_QUERY_PATH", "queries/materialize_no_ts.sql")
+	queries := defaultPythonOfflineQueries{Logger: logging.NewTestLogger(t)}
+	tests := []struct {
+		name     string
+		ts       string
+		cast     types.ScalarType
+		expected string
+	}{
+		{"String to int", "ts", types.Int, "CAST(value AS INT) as value"},
+		{"Int to float", "ts", types.Float64, "CAST(value AS DOUBLE) as value"},
+		{"String to int without timestamp", "", types.Int, "CAST(value AS INT) AS value"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			schema, err := ResourceSchema{Entity: "entity", Value: "value", TS: test.ts}.withResourceOptions(
+				&ColumnCastOption{Casts: map[string]types.ScalarType{"value": test.cast}},
+			)
+			if err != nil {
+				t.Fatalf("could not apply casts: %v", err)
+			}
+			query, err := queries.materializationCreate(schema, "")
+			if err != nil {
+				t.Fatalf("could not create query: %v", err)
+			}
+			if !strings.Contains(query, test.expected) {
+				t.Fatalf("expected query to contain %q, got %s", test.expected, query)
+			}
+		})
+	}
+}
+
+func TestMaterializationCreateWithTimestampFormat(t *testing.T) {
+	t.Setenv("MATERIALIZE_WITH_TIMESTAMP_QUERY_PATH", "queries/materialize_ts.sql")
+	queries := defaultPythonOfflineQueries{Logger: logging.NewTestLogger(t)}
+	tests := []struct {
+		name     string
+		format   TimestampFormat
+		expected string
+	}{
+		{"Epoch seconds", TimestampFormat{Encoding: EpochSeconds}, "(SELECT *, timestamp_seconds(CAST(ts AS DOUBLE)) AS __ff_parsed_ts FROM source_0)"},
+		{"ISO-8601", TimestampFormat{Encoding: ISO8601}, "to_utc_timestamp(to_timestamp(ts), 'UTC')"},
+		{"ISO-8601 in a non-UTC zone", TimestampFormat{Encoding: ISO8601, Timezone: "Asia/Kolkata"}, "to_utc_timestamp(to_timestamp(ts), 'Asia/Kolkata')"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			schema, err := ResourceSchema{Entity: "entity", Value: "value", TS: "ts"}.withResourceOptions(
+				&TimestampFormatOption{Format: test.format},
+			)
+			if err != nil {
+				t.Fatalf("could not apply timestamp format: %v", err)
+			}
+			query, err := queries.materializationCreate(schema, "")
+			if err != nil {
+				t.Fatalf("could not create query: %v", err)
+			}
+			if !strings.Contains(query, test.expected) {
+				t.Fatalf("expected query to contain %q, got %s", test.expected, query)
+			}
+			if !strings.Contains(query, "MAX(t2.__ff_parsed_ts)") {
+				t.Fatalf("expected the latest row to be found by the parsed timestamp, got %s", query)
+			}
+		})
+	}
+}
+
+func TestMaterializationCreateWithPartition(t *testing.T) {
+	t.Setenv("MATERIALIZE_WITH_TIMESTAMP_QUERY_PATH", "queries/materialize_ts.sql")
+	queries := defaultPythonOfflineQueries{Logger: logging.NewTestLogger(t)}
+	schema := ResourceSchema{Entity: "entity", Value: "value", TS: "ts"}
+	query, err := queries.materializationCreate(schema, "")
+	if err != nil {
+		t.Fatalf("could not create query: %v", err)
+	}
+	tests := []struct {
+		name      string
+		partition MaterializationPartition
+		expected  string
+	}{
+		{"Date", MaterializationPartition{Type: PartitionByDate}, "SELECT *, to_date(ts) AS ts_date FROM ("},
+		{"Entity bucket", MaterializationPartition{Type: PartitionByEntityBucket, Buckets: 16}, "SELECT *, pmod(hash(entity), 16) AS entity_bucket FROM ("},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if err := test.partition.validate(schema); err != nil {
+				t.Fatalf("expected partition to be valid: %v", err)
+			}
+			partitioned, err := queries.partitionMaterialization(query, test.partition)
+			if err != nil {
+				t.Fatalf("could not partition query: %v", err)
+			}
+			if !strings.HasPrefix(partitioned, test.expected) {
+				t.Fatalf("expected query to start with %q, got %s", test.expected, partitioned)
+			}
+			if strings.Contains(partitioned, ";") {
+				t.Fatalf("expected the inner query's terminator to be removed, got %s", partitioned)
+			}
+		})
+	}
+	invalid := map[string]MaterializationPartition{
+		"No buckets": {Type: PartitionByEntityBucket},
+		"Unknown":    {Type: "HOUR"},
+	}
+	for name, partition := range invalid {
+		t.Run(name, func(t *testing.T) {
+			if err := partition.validate(schema); err == nil {
+				t.Fatalf("expected partition %v to be invalid", partition)
+			}
+		})
+	}
+	if err := (MaterializationPartition{Type: PartitionByDate}).validate(ResourceSchema{Entity: "entity", Value: "value"}); err == nil {
+		t.Fatalf("expected date partitioning without a timestamp to be invalid")
+	}
+}
+
+func TestAsOfJoinQuery(t *testing.T) {
+	queries := defaultPythonOfflineQueries{Logger: logging.NewTestLogger(t)}
+	schemas := []ResourceSchema{
+		{Entity: "user", Value: "amount", TS: "event_ts"},
+		{Entity: "user", Value: "country"},
+	}
+	query, err := queries.asOfJoinQuery(schemas)
+	if err != nil {
+		t.Fatalf("could not create query: %v", err)
+	}
+	expected := []string{
+		"SELECT CAST(user AS STRING) AS entity, amount AS value, event_ts AS ts FROM source_1",
+		"SELECT CAST(user AS STRING) AS entity, country AS value, CAST(0 AS TIMESTAMP) AS ts FROM source_2",
+		"ON e.entity = f.entity AND f.ts <= e.ts",
+		"as_of_1.value AS feature1, as_of_2.value AS feature2",
+		"LEFT JOIN as_of_2 ON e.row_index = as_of_2.row_index",
+		"ORDER BY e.row_index",
+	}
+	for _, part := range expected {
+		if !strings.Contains(query, part) {
+			t.Fatalf("expected query to contain %q, got %s", part, query)
+		}
+	}
+}
+
 // func TestCompareStructsFail(t *testing.T) {
 // 	t.Parallel()
 // 	type testStruct struct {
@@ -3174,6 +3530,31 @@ func TestSparkFileStoreV2Config(t *testing.T) {
 				"--credential", "\"use_service_account=true\"",
 			},
 		},
+		{
+			name: "S3 compatible endpoint",
+			store: SparkS3FileStore{
+				S3FileStore: &S3FileStore{
+					Credentials:    pc.AWSAssumeRoleCredentials{},
+					Bucket:         "test-bucket",
+					BucketRegion:   "us-east-1",
+					Path:           "path",
+					Endpoint:       "http://minio:9000",
+					ForcePathStyle: true,
+				},
+			},
+			ExpectedFlags: []string{
+				"spark-submit", "s3://test/script.py",
+				"--spark_config", "\"spark.hadoop.fs.s3.impl=org.apache.hadoop.fs.s3a.S3AFileSystem\"",
+				"--credential", "\"aws_bucket_name=test-bucket\"",
+				"--credential", "\"aws_region=us-east-1\"",
+				"--store_type", "s3",
+				"--spark_config", "\"fs.s3a.endpoint=http://minio:9000\"",
+				"--credential", "\"aws_endpoint_url=http://minio:9000\"",
+				"--spark_config", "\"fs.s3a.path.style.access=true\"",
+				"--credential", "\"aws_force_path_style=true\"",
+				"--credential", "\"use_service_account=true\"",
+			},
+		},
 		{
 			name: "S3 with Glue",
 			store: SparkGlueS3FileStore{
@@ -3517,6 +3898,25 @@ func TestExceedsSubmitParamsTotalByteLimit(t *testing.T) {
 	}
 }
 
+func TestSparkLogTail(t *testing.T) {
+	lines := make([]string, sparkLogTailLines+10)
+	for i := range lines {
+		lines[i] = fmt.Sprintf("line %d", i)
+	}
+	tail := sparkLogTail(strings.Join(lines, "\n") + "\n")
+	expected := strings.Join(lines[10:], "\n")
+	if tail != expected {
+		t.Fatalf("Expected the last %d lines, got:\n%s", sparkLogTailLines, tail)
+	}
+	if tail := sparkLogTail("Traceback\nValueError: bad"); tail != "Traceback\nValueError: bad" {
+		t.Fatalf("Expected short log to be unchanged, got: %s", tail)
+	}
+	long := strings.Repeat("é", sparkLogTailBytes)
+	if tail := sparkLogTail(long); len(tail) > sparkLogTailBytes || !utf8.ValidString(tail) {
+		t.Fatalf("Expected at most %d bytes of valid UTF-8, got %d bytes", sparkLogTailBytes, len(tail))
+	}
+}
+
 func TestNewSparkFileStores(t *testing.T) {
 	if testing.Short() {
 		t.Skip("skipping NewSparkFileStores tests")
@@ -4094,6 +4494,10 @@ func TestCreateSourceInfo(t *testing.T) {
 	if err != nil {
 		t.Fatalf("could not set local file path: %v", err)
 	}
+	gzipFp := filestore.LocalFilepath{}
+	if err := gzipFp.SetKey("/path/to/data.csv.gz"); err != nil {
+		t.Fatalf("could not set local file path: %v", err)
+	}
 
 	// Serialize the SparkConfig
 	sc := pc.SparkConfig{
@@ -4155,6 +4559,44 @@ func TestCreateSourceInfo(t *testing.T) {
 			},
 			expectError: false,
 		},
+		{
+			name: "Delimited File SourceMapping",
+			mappings: []SourceMapping{
+				{
+					ProviderType:   provider_type.SparkOffline,
+					ProviderConfig: scSerialized,
+					Location:       pl.NewCSVFileLocation(&fp, pl.CSVOptions{Delimiter: "|", NoHeader: true}),
+				},
+			},
+			expected: []spark.SourceInfo{
+				{
+					Location:     "//path/to/data",
+					LocationType: "filestore",
+					Provider:     provider_type.SparkOffline,
+					CSVOptions:   &pl.CSVOptions{Delimiter: "|", NoHeader: true},
+				},
+			},
+			expectError: false,
+		},
+		{
+			name: "Compressed File SourceMapping",
+			mappings: []SourceMapping{
+				{
+					ProviderType:   provider_type.SparkOffline,
+					ProviderConfig: scSerialized,
+					Location:       pl.NewFileLocation(&gzipFp),
+				},
+			},
+			expected: []spark.SourceInfo{
+				{
+					Location:     "//path/to/data.csv.gz",
+					LocationType: "filestore",
+					Provider:     provider_type.SparkOffline,
+					Compression:  "gzip",
+				},
+			},
+			expectError: false,
+		},
 		{
 			name: "Unsupported ProviderType",
 			mappings: []SourceMapping{
@@ -4218,3 +4660,12 @@ func TestCreateSourceInfo(t *testing.T) {
 		})
 	}
 }
+
+func TestMergeMaterializationQuery(t *testing.T) {
+	query := mergeMaterializationQuery("SELECT entity, value, ts FROM source_0;\n", 1)
+	expected := "SELECT entity, value, ts FROM (SELECT entity, value, ts, ROW_NUMBER() OVER (PARTITION BY entity ORDER BY ts DESC) AS rn FROM " +
+		"(SELECT entity, value, ts FROM source_1 UNION ALL SELECT entity, value, ts FROM ( SELECT entity, value, ts FROM source_0 ) AS delta) AS merged) AS latest WHERE rn = 1"
+	if query != expected {
+		t.Fatalf("expected %s, got %s", expected, query)
+	}
+}
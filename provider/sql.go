@@ -10,6 +10,7 @@ package provider
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 	"sort"
 	"strconv"
@@ -20,6 +21,7 @@ import (
 	sf "github.com/snowflakedb/gosnowflake"
 
 	"github.com/featureform/fferr"
+	"github.com/featureform/filestore"
 	"github.com/featureform/logging"
 	"github.com/featureform/metadata"
 	pl "github.com/featureform/provider/location"
@@ -42,7 +44,10 @@ type SQLOfflineStoreConfig struct {
 	ProviderType            pt.Type
 	QueryImpl               OfflineTableQueries
 	ConnectionStringBuilder func(database, schema string) (string, error)
-	useDbConnectionCache    bool
+	// ConnectionPool is applied to every connection the store opens, the defaults
+	// are used if it's unset
+	ConnectionPool       *pc.SQLConnectionPoolConfig
+	useDbConnectionCache bool
 }
 
 type OfflineTableQueries interface {
@@ -54,10 +59,12 @@ type OfflineTableQueries interface {
 	primaryTableRegister(tableName string, sourceName string) string
 	primaryTableCreate(name string, columnString string) string
 	getColumns(db *sql.DB, tableName string) ([]TableColumn, error)
+	sourceColumnType(db *sql.DB, location *pl.SQLLocation, column string) (string, error)
 	getValueColumnTypes(tableName string) string
 	determineColumnType(valueType types.ValueType) (string, error)
 	materializationCreate(tableName string, sourceName string) []string
 	materializationUpdate(db *sql.DB, tableName string, sourceName string) error
+	materializationIncrementalUpdate(db *sql.DB, tableName string, sourceName string) error
 	materializationExists() string
 	materializationDrop(tableName string) string
 	getTable() string
@@ -72,20 +79,46 @@ type OfflineTableQueries interface {
 	trainingSetUpdate(store *sqlOfflineStore, def TrainingSetDef, tableName string, labelName string) error
 	trainingRowSelect(columns string, trainingSetName string) string
 	trainingRowSplitSelect(columns string, trainingSetSplitName string) (string, string)
+	trainingSetExport(db *sql.DB, tableName string, location pl.Location, format filestore.FileType) ([]filestore.Filepath, error)
+	trainingSetPersist(db *sql.DB, tableName string, persist TrainingSetPersistAs) error
+	sourceImport(db *sql.DB, tableName string, files []filestore.Filepath) error
+	trainingSetSplitBucket(entity, ts string, seed int64) string
+	maxFeatureAgeFilter(featureTS, labelTS string, maxAge time.Duration) string
+	shiftTimestamp(ts string, delta time.Duration) string
 	castTableItemType(v interface{}, t interface{}) interface{}
 	getValueColumnType(t *sql.ColumnType) interface{}
 	numRows(n interface{}) (int64, error)
 	transformationCreate(name string, query string) []string
 	transformationUpdate(db *sql.DB, tableName string, query string) error
+	transformationIncrementalUpdate(db *sql.DB, tableName string, query string, column string, watermark string) error
+	transformationWatermark(db *sql.DB, tableName string) (string, bool, error)
+	setTransformationWatermark(db *sql.DB, tableName string, column string) error
 	transformationExists() string // this isn't used anywhere should I still keep it
 	resourceTableColumns(obj pl.FullyQualifiedObject) (string, error)
 }
 
+// checkedTableItemCaster is implemented by dialects whose values can fail to be
+// cast, so that iterators can return the error rather than the raw value.
+type checkedTableItemCaster interface {
+	checkedCastTableItemType(v interface{}, t interface{}) (interface{}, error)
+}
+
+// castTableItem casts v to the Go type of column type t in q's dialect.
+func castTableItem(q OfflineTableQueries, v interface{}, t interface{}) (interface{}, error) {
+	if caster, ok := q.(checkedTableItemCaster); ok {
+		return caster.checkedCastTableItemType(v, t)
+	}
+	return q.castTableItemType(v, t), nil
+}
+
 type sqlOfflineStore struct {
 	db     *sql.DB
 	parent SQLOfflineStoreConfig
 	query  OfflineTableQueries
 	getDb  func(database, schema string) (*sql.DB, error)
+	// conns holds the connections getDb opened for other databases and
+	// schemas, so they're closed along with the store.
+	conns  *sqlConnections
 	logger logging.Logger
 	BaseProvider
 }
@@ -93,8 +126,12 @@ type sqlOfflineStore struct {
 // NewPostgresOfflineStore creates a connection to a postgres database
 // and initializes a table to track currently active Resource tables.
 func NewSQLOfflineStore(config SQLOfflineStoreConfig) (*sqlOfflineStore, error) {
+	if err := config.ConnectionPool.Validate(); err != nil {
+		return nil, err
+	}
+	pool := config.ConnectionPool.WithDefaults()
 	url := config.ConnectionURL
-	pgDb, err := sql.Open(config.Driver, url)
+	pgDb, err := createDbConn(config.Driver, url, pool)
 	if err != nil {
 		wrapped := fferr.NewConnectionError(config.ProviderType.String(), err)
 		wrapped.AddDetail("action", "connection_initialization")
@@ -102,6 +139,7 @@ func NewSQLOfflineStore(config SQLOfflineStoreConfig) (*sqlOfflineStore, error)
 		return nil, wrapped
 	}
 
+	conns := newSQLConnections()
 	return &sqlOfflineStore{
 		db:     pgDb,
 		parent: config,
@@ -111,9 +149,14 @@ func NewSQLOfflineStore(config SQLOfflineStoreConfig) (*sqlOfflineStore, error)
 			if err != nil {
 				return nil, err
 			}
-
-			return getOrCreateDbConnection(config.Driver, url, config.useDbConnectionCache)
+			if config.useDbConnectionCache {
+				return getOrCreateDbConnection(config.Driver, url, pool, true)
+			}
+			return conns.get(url, func() (*sql.DB, error) {
+				return createDbConn(config.Driver, url, pool)
+			})
 		},
+		conns: conns,
 		BaseProvider: BaseProvider{
 			ProviderType:   config.ProviderType,
 			ProviderConfig: config.Config,
@@ -123,32 +166,42 @@ func NewSQLOfflineStore(config SQLOfflineStoreConfig) (*sqlOfflineStore, error)
 }
 
 var (
-	dbCache      = make(map[string]*sql.DB)
+	dbCache      = make(map[dbCacheKey]*sql.DB)
 	dbCacheMutex sync.Mutex
 )
 
-func getOrCreateDbConnection(driver, url string, useCache bool) (*sql.DB, error) {
+// dbCacheKey identifies a shared connection by everything it was opened with,
+// so stores with different pool settings for the same database don't share a
+// pool configured by whichever of them connected first.
+type dbCacheKey struct {
+	driver string
+	url    string
+	pool   pc.SQLConnectionPoolConfig
+}
+
+func getOrCreateDbConnection(driver, url string, pool pc.SQLConnectionPoolConfig, useCache bool) (*sql.DB, error) {
 	if useCache {
 		dbCacheMutex.Lock()
 		defer dbCacheMutex.Unlock()
 
+		key := dbCacheKey{driver: driver, url: url, pool: pool}
 		// Check if a connection already exists in the cache
-		if dbConn, ok := dbCache[url]; ok {
+		if dbConn, ok := dbCache[key]; ok {
 			return dbConn, nil
 		}
 
-		dbConn, err := createDbConn(driver, url)
+		dbConn, err := createDbConn(driver, url, pool)
 		if err != nil {
 			return dbConn, err
 		}
 
 		// Cache the new connection
-		dbCache[url] = dbConn
+		dbCache[key] = dbConn
 
 		return dbConn, nil
 	} else {
 		// Create a new connection without using the cache
-		dbConn, err := createDbConn(driver, url)
+		dbConn, err := createDbConn(driver, url, pool)
 		if err != nil {
 			return dbConn, err
 		}
@@ -157,7 +210,51 @@ func getOrCreateDbConnection(driver, url string, useCache bool) (*sql.DB, error)
 	}
 }
 
-func createDbConn(driver string, url string) (*sql.DB, error) {
+// sqlConnections tracks the connections a single store opens, keyed by
+// connection URL, so they're reused across calls and closed with the store.
+// Unlike dbCache, nothing is shared between stores.
+type sqlConnections struct {
+	mu    sync.Mutex
+	conns map[string]*sql.DB
+}
+
+func newSQLConnections() *sqlConnections {
+	return &sqlConnections{conns: make(map[string]*sql.DB)}
+}
+
+func (c *sqlConnections) get(key string, open func() (*sql.DB, error)) (*sql.DB, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if db, ok := c.conns[key]; ok {
+		return db, nil
+	}
+	db, err := open()
+	if err != nil {
+		return nil, err
+	}
+	c.conns[key] = db
+	return db, nil
+}
+
+// close closes every tracked connection. It's safe to call on a nil
+// sqlConnections.
+func (c *sqlConnections) close() error {
+	if c == nil {
+		return nil
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var errs []error
+	for key, db := range c.conns {
+		if err := db.Close(); err != nil {
+			errs = append(errs, err)
+		}
+		delete(c.conns, key)
+	}
+	return errors.Join(errs...)
+}
+
+func createDbConn(driver string, url string, pool pc.SQLConnectionPoolConfig) (*sql.DB, error) {
 	// Create a new connection
 	dbConn, err := sql.Open(driver, url)
 	if err != nil {
@@ -165,9 +262,9 @@ func createDbConn(driver string, url string) (*sql.DB, error) {
 	}
 
 	// Set connection pool settings
-	dbConn.SetMaxOpenConns(25)
-	dbConn.SetMaxIdleConns(25)
-	dbConn.SetConnMaxLifetime(time.Hour)
+	dbConn.SetMaxOpenConns(pool.MaxOpenConns)
+	dbConn.SetMaxIdleConns(pool.MaxIdleConns)
+	dbConn.SetConnMaxLifetime(pool.ConnMaxLifetime)
 	return dbConn, nil
 }
 
@@ -246,8 +343,10 @@ func (store *sqlOfflineStore) AsOfflineStore() (OfflineStore, error) {
 	return store, nil
 }
 
+// Close closes the store's connection along with any that getDb opened.
+// Connections in the shared dbCache are left open for other stores.
 func (store *sqlOfflineStore) Close() error {
-	if err := store.db.Close(); err != nil {
+	if err := errors.Join(store.db.Close(), store.conns.close()); err != nil {
 		return fferr.NewConnectionError(store.Type().String(), err)
 	}
 	return nil
@@ -270,9 +369,10 @@ func (store sqlOfflineStore) Delete(location pl.Location) error {
 func (store *sqlOfflineStore) RegisterResourceFromSourceTable(id ResourceID, schema ResourceSchema, opts ...ResourceOption) (OfflineTable, error) {
 	logger := logging.NewLogger("sql").WithProvider(store.Type().String(), "SQL")
 	logger.Debugw("Registering resource from source table", "id", id, "schema", schema, "options", opts)
-	if len(opts) > 0 {
-		logger.Errorw("resource options not supported", "options", opts)
-		return nil, fferr.NewInvalidArgumentError(fmt.Errorf("resource options not supported"))
+	schema, err := schema.withResourceOptions(opts...)
+	if err != nil {
+		logger.Errorw("invalid resource options", "options", opts, "error", err)
+		return nil, err
 	}
 	if err := id.check(Feature, Label); err != nil {
 		logger.Errorw("id check failed", "id", id, "error", err)
@@ -289,6 +389,16 @@ func (store *sqlOfflineStore) RegisterResourceFromSourceTable(id ResourceID, sch
 		logger.Errorw("non-empty entity and value columns required", "schema", schema)
 		return nil, fferr.NewInvalidArgumentError(fmt.Errorf("non-empty entity and value columns required"))
 	}
+	if sqlLocation, isSQLLocation := schema.SourceTable.(*pl.SQLLocation); isSQLLocation {
+		err := schema.checkValueColumnType(func(column string) (string, columnTypeFamily, error) {
+			dataType, err := store.query.sourceColumnType(store.db, sqlLocation, column)
+			return dataType, sqlColumnTypeFamily(dataType), err
+		})
+		if err != nil {
+			logger.Errorw("value column type check failed", "schema", schema, "error", err)
+			return nil, err
+		}
+	}
 	tableName, err := store.getResourceTableName(id)
 	if err != nil {
 		logger.Errorw("table name generation failed", "id", id, "error", err)
@@ -314,7 +424,10 @@ func (store *sqlOfflineStore) RegisterResourceFromSourceTable(id ResourceID, sch
 	}, nil
 }
 
-func (store *sqlOfflineStore) RegisterPrimaryFromSourceTable(id ResourceID, tableLocation pl.Location) (PrimaryTable, error) {
+func (store *sqlOfflineStore) RegisterPrimaryFromSourceTable(id ResourceID, tableLocation pl.Location, opts ...PrimaryOption) (PrimaryTable, error) {
+	if len(opts) > 0 {
+		return nil, fferr.NewInternalErrorf("%s does not support primary table options", store.Type())
+	}
 	if err := id.check(Primary); err != nil {
 		return nil, err
 	}
@@ -581,6 +694,173 @@ func (store *sqlOfflineStore) ResourceLocation(id ResourceID, resource any) (pl.
 	return pl.NewSQLLocation(tableName), err
 }
 
+func (store *sqlOfflineStore) GetResourceStats(id ResourceID, resource any) (ResourceStats, error) {
+	var tableName string
+	var err error
+	switch id.Type {
+	case Feature, Label:
+		tableName, err = store.getResourceTableName(id)
+	case TrainingSet:
+		tableName, err = store.getTrainingSetName(id)
+	case Primary:
+		sv, ok := resource.(*metadata.SourceVariant)
+		if !ok {
+			return ResourceStats{}, fferr.NewInvalidArgumentErrorf("resource is not a SourceVariant")
+		}
+		tableName = sv.PrimaryDataSQLTableName()
+	case Transformation:
+		tableName, err = store.getTransformationTableName(id)
+	default:
+		err = fferr.NewInvalidArgumentError(fmt.Errorf("unsupported resource type: %s", id.Type))
+	}
+	if err != nil {
+		return ResourceStats{}, err
+	}
+	return store.resourceStats(sanitize(tableName), id.Type == Feature || id.Type == Label)
+}
+
+// resourceStats counts the rows of a table and, if it has a ts column, reads
+// its newest timestamp. The table name must already be sanitized.
+func (store *sqlOfflineStore) resourceStats(tableName string, hasTimestamp bool) (ResourceStats, error) {
+	var count int64
+	var maxTS sql.NullTime
+	query := fmt.Sprintf("SELECT COUNT(*) FROM %s", tableName)
+	dest := []interface{}{&count}
+	if hasTimestamp {
+		query = fmt.Sprintf("SELECT COUNT(*), MAX(ts) FROM %s", tableName)
+		dest = append(dest, &maxTS)
+	}
+	if err := store.db.QueryRow(query).Scan(dest...); err != nil {
+		wrapped := fferr.NewExecutionError(store.Type().String(), err)
+		wrapped.AddDetail("table_name", tableName)
+		return ResourceStats{}, wrapped
+	}
+	stats := ResourceStats{RowCount: count}
+	if maxTS.Valid {
+		stats.MaxTimestamp = maxTS.Time.UTC()
+	}
+	return stats, nil
+}
+
+// ProfileSource computes per column null and distinct counts of a source in a
+// single aggregate query. Min and max are only computed for numeric and
+// timestamp columns, which are found by the database type names of the columns.
+func (store *sqlOfflineStore) ProfileSource(id ResourceID, source metadata.SourceVariant, opts ProfileOptions) (SourceProfile, error) {
+	var location pl.Location
+	var err error
+	if source.IsPrimaryData() {
+		location, err = source.GetPrimaryLocation()
+	} else {
+		location, err = source.GetTransformationLocation()
+	}
+	if err != nil {
+		return SourceProfile{}, err
+	}
+	sqlLocation, ok := location.(*pl.SQLLocation)
+	if !ok {
+		return SourceProfile{}, fferr.NewInvalidArgumentErrorf("source location is not a SQLLocation")
+	}
+	dbConn, err := store.getDb(sqlLocation.GetDatabase(), sqlLocation.GetSchema())
+	if err != nil {
+		return SourceProfile{}, fferr.NewConnectionError(store.Type().String(), err)
+	}
+	table := SanitizeSqlLocation(sqlLocation.TableLocation())
+	columns, err := store.profiledColumns(dbConn, table)
+	if err != nil {
+		return SourceProfile{}, err
+	}
+	profile := SourceProfile{Columns: make([]ColumnProfile, len(columns))}
+	dest := []interface{}{&profile.RowCount}
+	ranges := make([][2]interface{}, len(columns))
+	for i, column := range columns {
+		profile.Columns[i].Name = column.name
+		dest = append(dest, &profile.Columns[i].NullCount, &profile.Columns[i].DistinctCount)
+		if column.hasRange {
+			dest = append(dest, &ranges[i][0], &ranges[i][1])
+		}
+	}
+	if err := dbConn.QueryRow(sqlProfileQuery(table, columns, opts.SampleSize)).Scan(dest...); err != nil {
+		wrapped := fferr.NewResourceExecutionError(store.Type().String(), id.Name, id.Variant, fferr.ResourceType(id.Type.String()), err)
+		wrapped.AddDetail("table_name", sqlLocation.Location())
+		return SourceProfile{}, wrapped
+	}
+	for i := range columns {
+		profile.Columns[i].Min = profileValueString(ranges[i][0])
+		profile.Columns[i].Max = profileValueString(ranges[i][1])
+	}
+	return profile, nil
+}
+
+type profiledColumn struct {
+	name     string
+	hasRange bool
+}
+
+func (store *sqlOfflineStore) profiledColumns(db *sql.DB, table string) ([]profiledColumn, error) {
+	rows, err := db.Query(fmt.Sprintf("SELECT * FROM %s LIMIT 0", table))
+	if err != nil {
+		wrapped := fferr.NewExecutionError(store.Type().String(), err)
+		wrapped.AddDetail("table_name", table)
+		return nil, wrapped
+	}
+	defer rows.Close()
+	colTypes, err := rows.ColumnTypes()
+	if err != nil {
+		return nil, fferr.NewExecutionError(store.Type().String(), err)
+	}
+	columns := make([]profiledColumn, len(colTypes))
+	for i, colType := range colTypes {
+		columns[i] = profiledColumn{name: colType.Name(), hasRange: isRangeColumnType(colType.DatabaseTypeName())}
+	}
+	return columns, nil
+}
+
+// isRangeColumnType returns true for the database types of numeric and
+// timestamp columns, the ones with a meaningful min and max.
+func isRangeColumnType(dbType string) bool {
+	dbType = strings.ToUpper(dbType)
+	for _, prefix := range []string{"INT", "BIGINT", "SMALLINT", "TINYINT", "UINT", "FLOAT", "DOUBLE", "REAL", "NUMERIC", "DECIMAL", "NUMBER", "FIXED", "TIMESTAMP", "DATE"} {
+		if strings.HasPrefix(dbType, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// sqlProfileQuery returns a query for the row count followed by the null count,
+// distinct count and, if the column has a range, min and max of every column.
+// If sampleSize is positive, only the first sampleSize rows are profiled.
+func sqlProfileQuery(table string, columns []profiledColumn, sampleSize int64) string {
+	aggregates := []string{"COUNT(*)"}
+	for _, column := range columns {
+		col := sanitize(column.name)
+		aggregates = append(aggregates, fmt.Sprintf("COUNT(*) - COUNT(%s)", col), fmt.Sprintf("COUNT(DISTINCT %s)", col))
+		if column.hasRange {
+			aggregates = append(aggregates, fmt.Sprintf("MIN(%s)", col), fmt.Sprintf("MAX(%s)", col))
+		}
+	}
+	from := table
+	if sampleSize > 0 {
+		from = fmt.Sprintf("(SELECT * FROM %s LIMIT %d) AS profile_sample", table, sampleSize)
+	}
+	return fmt.Sprintf("SELECT %s FROM %s", strings.Join(aggregates, ", "), from)
+}
+
+// profileValueString formats a min or max value. NULL, which is the min and
+// max of a column without values, is formatted as an empty string.
+func profileValueString(v interface{}) string {
+	switch value := v.(type) {
+	case nil:
+		return ""
+	case []byte:
+		return string(value)
+	case time.Time:
+		return value.UTC().Format(time.RFC3339Nano)
+	default:
+		return fmt.Sprint(value)
+	}
+}
+
 type sqlMaterialization struct {
 	id           MaterializationID
 	db           *sql.DB
@@ -686,7 +966,13 @@ func (iter *sqlFeatureIterator) Next() bool {
 		iter.err = err
 		return false
 	}
-	rec.Value = iter.query.castTableItemType(value, iter.columnType)
+	castValue, err := castTableItem(iter.query, value, iter.columnType)
+	if err != nil {
+		iter.rows.Close()
+		iter.err = err
+		return false
+	}
+	rec.Value = castValue
 	if ts != nil {
 		rec.TS = ts.UTC()
 	}
@@ -759,7 +1045,13 @@ func (it *sqlBatchFeatureIterator) Next() bool {
 		if value == nil {
 			continue
 		}
-		rowValues[i] = it.query.castTableItemType(value, it.columnTypes[i])
+		castValue, err := castTableItem(it.query, value, it.columnTypes[i])
+		if err != nil {
+			it.err = err
+			it.rows.Close()
+			return false
+		}
+		rowValues[i] = castValue
 	}
 	it.currentValues = rowValues
 	return true
@@ -889,7 +1181,13 @@ func (store *sqlOfflineStore) CreateMaterialization(id ResourceID, opts Material
 	if err != nil {
 		return nil, err
 	}
-	materializeQueries := store.query.materializationCreate(matTableName, resTable.name)
+	source := resTable.name
+	if opts.Filter != "" {
+		if source, err = store.createFilteredResource(id, opts); err != nil {
+			return nil, err
+		}
+	}
+	materializeQueries := store.query.materializationCreate(matTableName, source)
 	for _, materializeQry := range materializeQueries {
 		_, err = store.db.Exec(materializeQry)
 		if err != nil {
@@ -905,8 +1203,78 @@ func (store *sqlOfflineStore) CreateMaterialization(id ResourceID, opts Material
 	}, nil
 }
 
+// validateSourceFilter checks filter against the columns of schema's source
+// table and returns the source's location.
+func (store *sqlOfflineStore) validateSourceFilter(schema ResourceSchema, filter string) (*pl.SQLLocation, error) {
+	sqlLocation, ok := schema.SourceTable.(*pl.SQLLocation)
+	if !ok {
+		return nil, fferr.NewInvalidArgumentErrorf("materialization filters require a SQL source table")
+	}
+	dbConn, err := store.getDb(sqlLocation.GetDatabase(), sqlLocation.GetSchema())
+	if err != nil {
+		return nil, fferr.NewConnectionError(store.Type().String(), err)
+	}
+	columns, err := store.query.getColumns(dbConn, sqlLocation.GetTable())
+	if err != nil {
+		return nil, err
+	}
+	columnNames := make([]string, len(columns))
+	for i, col := range columns {
+		columnNames[i] = col.Name
+	}
+	if err := validateMaterializationFilter(filter, columnNames); err != nil {
+		return nil, err
+	}
+	return sqlLocation, nil
+}
+
+// filteredResourceNames returns the names of the views a filtered
+// materialization reads from: the source rows that match the filter, and the
+// feature's entity, value, and timestamp columns over them.
+func filteredResourceNames(id ResourceID) (string, string) {
+	return fmt.Sprintf("featureform_filtered_source__%s__%s", id.Name, id.Variant),
+		fmt.Sprintf("featureform_filtered_resource__%s__%s", id.Name, id.Variant)
+}
+
+// createFilteredResource creates the views that apply opts.Filter to the
+// feature's source and returns the one to materialize from. The views are kept
+// so that materializations built on them can be refreshed.
+func (store *sqlOfflineStore) createFilteredResource(id ResourceID, opts MaterializationOptions) (string, error) {
+	sourceName, resourceName := filteredResourceNames(id)
+	if exists, err := store.tableExists(pl.NewSQLLocation(resourceName)); err != nil {
+		return "", err
+	} else if exists {
+		return resourceName, nil
+	}
+	sqlLocation, err := store.validateSourceFilter(opts.Schema, opts.Filter)
+	if err != nil {
+		return "", err
+	}
+	filterQuery := fmt.Sprintf("%s WHERE %s", store.query.primaryTableRegister(sourceName, sqlLocation.Location()), opts.Filter)
+	if _, err := store.db.Exec(filterQuery); err != nil {
+		wrapped := fferr.NewResourceExecutionError(store.Type().String(), id.Name, id.Variant, fferr.ResourceType(id.Type.String()), err)
+		wrapped.AddDetail("filter", opts.Filter)
+		return "", wrapped
+	}
+	schema := opts.Schema
+	schema.SourceTable = pl.NewSQLLocation(sourceName)
+	if err := store.query.registerResources(store.db, resourceName, schema, schema.TS != ""); err != nil {
+		return "", err
+	}
+	return resourceName, nil
+}
+
 func (store *sqlOfflineStore) SupportsMaterializationOption(opt MaterializationOptionType) (bool, error) {
-	return false, nil
+	return opt == FilteredMaterialization, nil
+}
+
+func (store *sqlOfflineStore) Capabilities() (Capabilities, error) {
+	caps, err := offlineStoreCapabilities(store)
+	if err != nil {
+		return Capabilities{}, err
+	}
+	caps.IncrementalTransformation = true
+	return caps, nil
 }
 
 func (store *sqlOfflineStore) GetMaterialization(id MaterializationID) (Materialization, error) {
@@ -970,7 +1338,17 @@ func (store *sqlOfflineStore) UpdateMaterialization(id ResourceID, opts Material
 	if !rows.Next() {
 		return nil, fferr.NewDatasetNotFoundError(id.Name, id.Variant, nil)
 	}
-	err = store.query.materializationUpdate(store.db, tableName, resTable.name)
+	source := resTable.name
+	if opts.Filter != "" {
+		if source, err = store.createFilteredResource(id, opts); err != nil {
+			return nil, err
+		}
+	}
+	if opts.Incremental {
+		err = store.query.materializationIncrementalUpdate(store.db, tableName, source)
+	} else {
+		err = store.query.materializationUpdate(store.db, tableName, source)
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -1037,15 +1415,29 @@ func (store *sqlOfflineStore) CreateTrainingSet(def TrainingSetDef) error {
 	if err != nil {
 		return err
 	}
+	for _, id := range def.labelIDs()[1:] {
+		if _, err := store.getsqlResourceTable(id); err != nil {
+			return err
+		}
+	}
 	tableName, err := store.getTrainingSetName(def.ID)
 	if err != nil {
 		return err
 	}
+	// Fail before the training set is built rather than after.
+	if def.PersistAs != nil && !def.PersistAs.Overwrite {
+		exists, err := store.tableExists(pl.NewSQLLocation(def.PersistAs.Table))
+		if err != nil {
+			return err
+		}
+		if exists {
+			return fferr.NewDatasetAlreadyExistsError(def.PersistAs.Table, "", nil)
+		}
+	}
 	if err := store.query.trainingSetCreate(store, def, tableName, label.name); err != nil {
 		return err
 	}
-
-	return nil
+	return store.persistTrainingSet(def, tableName)
 }
 
 func (store *sqlOfflineStore) UpdateTrainingSet(def TrainingSetDef) error {
@@ -1056,6 +1448,11 @@ func (store *sqlOfflineStore) UpdateTrainingSet(def TrainingSetDef) error {
 	if err != nil {
 		return err
 	}
+	for _, id := range def.labelIDs()[1:] {
+		if _, err := store.getsqlResourceTable(id); err != nil {
+			return err
+		}
+	}
 	tableName, err := store.getTrainingSetName(def.ID)
 	if err != nil {
 		return err
@@ -1063,12 +1460,41 @@ func (store *sqlOfflineStore) UpdateTrainingSet(def TrainingSetDef) error {
 	if err := store.query.trainingSetUpdate(store, def, tableName, label.name); err != nil {
 		return err
 	}
+	return store.persistTrainingSet(def, tableName)
+}
 
+// persistTrainingSet copies the training set into def.PersistAs.Table, if it's set.
+func (store *sqlOfflineStore) persistTrainingSet(def TrainingSetDef, tableName string) error {
+	if def.PersistAs == nil {
+		return nil
+	}
+	persistTable := def.PersistAs.Table
+	logger := store.logger.WithResource(logging.TrainingSetVariant, def.ID.Name, def.ID.Variant).With("table", persistTable)
+	if err := store.query.trainingSetPersist(store.db, tableName, *def.PersistAs); err != nil {
+		logger.Errorw("Failed to persist training set", "error", err)
+		wrapped := fferr.NewResourceExecutionError(store.Type().String(), def.ID.Name, def.ID.Variant, fferr.TRAINING_SET_VARIANT, err)
+		wrapped.AddDetail("persist_table", persistTable)
+		return wrapped
+	}
+	logger.Infow("Persisted training set", "dedup", def.PersistAs.Dedup)
 	return nil
 }
 
 func (store *sqlOfflineStore) GetTrainingSet(id ResourceID) (TrainingSetIterator, error) {
-	logger := store.logger.WithResource(logging.TrainingSetVariant, id.Name, id.Variant)
+	return store.getTrainingSetRows(id, "")
+}
+
+func (store *sqlOfflineStore) GetTrainingSetSplit(id ResourceID, split string) (TrainingSetIterator, error) {
+	if err := checkTrainingSetSplitName(split); err != nil {
+		return nil, err
+	}
+	return store.getTrainingSetRows(id, split)
+}
+
+// getTrainingSetRows returns an iterator over the training set. If split is set, only
+// rows assigned to that split are returned.
+func (store *sqlOfflineStore) getTrainingSetRows(id ResourceID, split string) (TrainingSetIterator, error) {
+	logger := store.logger.WithResource(logging.TrainingSetVariant, id.Name, id.Variant).With("split", split)
 	logger.Debugw("Getting training set")
 	if err := id.check(TrainingSet); err != nil {
 		return nil, err
@@ -1092,11 +1518,28 @@ func (store *sqlOfflineStore) GetTrainingSet(id ResourceID) (TrainingSetIterator
 		return nil, err
 	}
 	features := make([]string, 0)
+	hasSplit := false
+	numLabels := 0
 	for _, name := range columnNames {
+		// The split column is bookkeeping and is never returned as a feature.
+		if name.Name == trainingSetSplitColumn {
+			hasSplit = true
+			continue
+		}
+		if isTrainingSetLabelColumn(name.Name) {
+			numLabels++
+		}
 		features = append(features, sanitize(name.Name))
 	}
+	if split != "" && !hasSplit {
+		logger.Errorw("Training Set was not created with a split")
+		return nil, fferr.NewInvalidArgumentErrorf("training set %s (%s) was not created with a split", id.Name, id.Variant)
+	}
 	columns := strings.Join(features[:], ", ")
 	trainingSetQry := store.query.trainingRowSelect(columns, trainingSetName)
+	if split != "" {
+		trainingSetQry = fmt.Sprintf("%s WHERE %s = '%s'", trainingSetQry, sanitize(trainingSetSplitColumn), split)
+	}
 	store.logger.Debugw("Training Set Query", "query", trainingSetQry)
 	rows, err := store.db.Query(trainingSetQry)
 	if err != nil {
@@ -1109,7 +1552,7 @@ func (store *sqlOfflineStore) GetTrainingSet(id ResourceID) (TrainingSetIterator
 		return nil, err
 	}
 	logger.Debugw("Returning Training Set Iterator")
-	return store.newsqlTrainingSetIterator(rows, colTypes), nil
+	return store.newsqlTrainingSetIterator(rows, colTypes, numLabels), nil
 }
 
 func (store *sqlOfflineStore) CreateTrainTestSplit(def TrainTestSplitDef) (func() error, error) {
@@ -1120,6 +1563,48 @@ func (store *sqlOfflineStore) GetTrainTestSplit(def TrainTestSplitDef) (Training
 	return nil, nil, fmt.Errorf("not Implemented")
 }
 
+func (store *sqlOfflineStore) ExportTrainingSet(id ResourceID, location pl.Location, format filestore.FileType) ([]filestore.Filepath, error) {
+	if err := id.check(TrainingSet); err != nil {
+		return nil, err
+	}
+	if exists, err := store.tableExistsForResourceId(id); err != nil {
+		return nil, err
+	} else if !exists {
+		return nil, fferr.NewDatasetNotFoundError(id.Name, id.Variant, nil)
+	}
+	trainingSetName, err := store.getTrainingSetName(id)
+	if err != nil {
+		return nil, err
+	}
+	logger := store.logger.WithResource(logging.TrainingSetVariant, id.Name, id.Variant)
+	logger.Infow("Exporting training set", "location", location.Location(), "format", format)
+	files, err := store.query.trainingSetExport(store.db, trainingSetName, location, format)
+	if err != nil {
+		logger.Errorw("Error exporting training set", "error", err)
+		return nil, err
+	}
+	return files, nil
+}
+
+// ImportSourceFiles loads files into the primary table for id with the
+// dialect's bulk load, when it has one.
+func (store *sqlOfflineStore) ImportSourceFiles(id ResourceID, files []filestore.Filepath) error {
+	if err := id.check(Primary); err != nil {
+		return err
+	}
+	tableName, err := GetPrimaryTableName(id)
+	if err != nil {
+		return err
+	}
+	logger := store.logger.WithResource(logging.SourceVariant, id.Name, id.Variant)
+	logger.Infow("Importing source files", "table_name", tableName, "files", len(files))
+	if err := store.query.sourceImport(store.db, tableName, files); err != nil {
+		logger.Errorw("Error importing source files", "error", err)
+		return err
+	}
+	return nil
+}
+
 // getValueColumnTypes returns a list of column types. Columns consist of feature and label values
 // within a training set.
 func (store *sqlOfflineStore) getValueColumnTypes(table string) ([]interface{}, error) {
@@ -1148,10 +1633,18 @@ func (store *sqlOfflineStore) getValueColumnTypes(table string) ([]interface{},
 	return colTypes, nil
 }
 
+// isTrainingSetLabelColumn returns true if the training set column holds a label.
+// The first label is always named label and any others are named after their
+// resource tables.
+func isTrainingSetLabelColumn(name string) bool {
+	return name == "label" || strings.HasPrefix(name, labelColumnPrefix)
+}
+
 type sqlTrainingRowsIterator struct {
 	rows            *sql.Rows
 	currentFeatures []interface{}
-	currentLabel    interface{}
+	currentLabels   []interface{}
+	numLabels       int
 	err             error
 	columnTypes     []interface{}
 	isHeaderRow     bool
@@ -1159,11 +1652,17 @@ type sqlTrainingRowsIterator struct {
 	store           *sqlOfflineStore
 }
 
-func (store *sqlOfflineStore) newsqlTrainingSetIterator(rows *sql.Rows, columnTypes []interface{}) TrainingSetIterator {
+// newsqlTrainingSetIterator iterates over training set rows whose last numLabels
+// columns are the labels and the rest are the features.
+func (store *sqlOfflineStore) newsqlTrainingSetIterator(rows *sql.Rows, columnTypes []interface{}, numLabels int) TrainingSetIterator {
+	if numLabels < 1 {
+		numLabels = 1
+	}
 	return &sqlTrainingRowsIterator{
 		rows:            rows,
 		currentFeatures: nil,
-		currentLabel:    nil,
+		currentLabels:   nil,
+		numLabels:       numLabels,
 		err:             nil,
 		columnTypes:     columnTypes,
 		isHeaderRow:     true,
@@ -1193,21 +1692,27 @@ func (it *sqlTrainingRowsIterator) Next() bool {
 		it.rows.Close()
 		return false
 	}
-	var label interface{}
-	numFeatures := len(columnNames) - 1
+	numFeatures := len(columnNames) - it.numLabels
 	featureVals := make([]interface{}, numFeatures)
+	labelVals := make([]interface{}, it.numLabels)
 	for i, value := range values {
 		if value == nil {
 			continue
 		}
+		castValue, err := castTableItem(it.query, value, it.columnTypes[i])
+		if err != nil {
+			it.err = err
+			it.rows.Close()
+			return false
+		}
 		if i < numFeatures {
-			featureVals[i] = it.query.castTableItemType(value, it.columnTypes[i])
+			featureVals[i] = castValue
 		} else {
-			label = it.query.castTableItemType(value, it.columnTypes[i])
+			labelVals[i-numFeatures] = castValue
 		}
 	}
 	it.currentFeatures = featureVals
-	it.currentLabel = label
+	it.currentLabels = labelVals
 
 	return true
 }
@@ -1221,7 +1726,14 @@ func (it *sqlTrainingRowsIterator) Features() []interface{} {
 }
 
 func (it *sqlTrainingRowsIterator) Label() interface{} {
-	return it.currentLabel
+	if len(it.currentLabels) == 0 {
+		return nil
+	}
+	return it.currentLabels[0]
+}
+
+func (it *sqlTrainingRowsIterator) Labels() []interface{} {
+	return it.currentLabels
 }
 
 func (store *sqlOfflineStore) getsqlResourceTable(id ResourceID) (*sqlOfflineTable, error) {
@@ -1369,7 +1881,7 @@ func determineColumnType(valueType types.ValueType) (string, error) {
 		return "INT", nil
 	case types.Float32, types.Float64:
 		return "FLOAT8", nil
-	case types.String:
+	case types.String, types.JSON:
 		return "VARCHAR", nil
 	case types.Bool:
 		return "BOOLEAN", nil
@@ -1475,6 +1987,9 @@ func (store *sqlOfflineStore) CreateTransformation(config TransformationConfig,
 	if len(opts) > 0 {
 		return fferr.NewInternalErrorf("OfflineStore does not support transformation options")
 	}
+	if err := ValidateTransformation(config); err != nil {
+		return err
+	}
 	name, err := store.getTransformationTableName(config.TargetTableID)
 	if err != nil {
 		return err
@@ -1485,6 +2000,9 @@ func (store *sqlOfflineStore) CreateTransformation(config TransformationConfig,
 			return fferr.NewResourceExecutionError(store.Type().String(), config.TargetTableID.Name, config.TargetTableID.Variant, fferr.ResourceType(config.TargetTableID.Type.String()), err)
 		}
 	}
+	if config.IncrementalColumn != "" {
+		return store.query.setTransformationWatermark(store.db, name, config.IncrementalColumn)
+	}
 	return nil
 }
 
@@ -1496,6 +2014,9 @@ func (store *sqlOfflineStore) UpdateTransformation(config TransformationConfig,
 	if err != nil {
 		return err
 	}
+	if config.IncrementalColumn != "" {
+		return store.incrementalTransformationUpdate(name, config)
+	}
 	err = store.query.transformationUpdate(store.db, name, config.Query)
 	if err != nil {
 		return err
@@ -1504,6 +2025,22 @@ func (store *sqlOfflineStore) UpdateTransformation(config TransformationConfig,
 	return nil
 }
 
+// incrementalTransformationUpdate appends the rows past the transformation's
+// watermark. If it has no watermark yet, it's rebuilt and its watermark is set.
+func (store *sqlOfflineStore) incrementalTransformationUpdate(name string, config TransformationConfig) error {
+	watermark, hasWatermark, err := store.query.transformationWatermark(store.db, name)
+	if err != nil {
+		return err
+	}
+	if !hasWatermark {
+		if err := store.query.transformationUpdate(store.db, name, config.Query); err != nil {
+			return err
+		}
+		return store.query.setTransformationWatermark(store.db, name, config.IncrementalColumn)
+	}
+	return store.query.transformationIncrementalUpdate(store.db, name, config.Query, config.IncrementalColumn, watermark)
+}
+
 func (store *sqlOfflineStore) getTransformationTableName(id ResourceID) (string, error) {
 	if err := id.check(Transformation); err != nil {
 		return "", err
@@ -1560,7 +2097,13 @@ func (it *sqlGenericTableIterator) Next() bool {
 		if value == nil {
 			continue
 		}
-		rowValues[i] = it.query.castTableItemType(value, it.columnTypes[i])
+		castValue, err := castTableItem(it.query, value, it.columnTypes[i])
+		if err != nil {
+			it.err = err
+			it.rows.Close()
+			return false
+		}
+		rowValues[i] = castValue
 	}
 	it.currentValues = rowValues
 	return true
@@ -1632,13 +2175,24 @@ func (q defaultOfflineSQLQueries) viewExists() string {
 }
 
 func (q defaultOfflineSQLQueries) registerResources(db *sql.DB, tableName string, schema ResourceSchema, timestamp bool) error {
+	if schema.TSFormat != nil {
+		return fferr.NewInvalidArgumentErrorf("timestamp formats are not supported by this store")
+	}
+	entity, err := schema.castColumn(schema.Entity, fmt.Sprintf("IDENTIFIER('%s')", schema.Entity), q.determineColumnType)
+	if err != nil {
+		return err
+	}
+	value, err := schema.castColumn(schema.Value, fmt.Sprintf("IDENTIFIER('%s')", schema.Value), q.determineColumnType)
+	if err != nil {
+		return err
+	}
 	var query string
 	if timestamp {
-		query = fmt.Sprintf("CREATE VIEW %s AS SELECT IDENTIFIER('%s') as entity,  IDENTIFIER('%s') as value,  IDENTIFIER('%s') as ts FROM TABLE('%s')", sanitize(tableName),
-			schema.Entity, schema.Value, schema.TS, sanitize(schema.SourceTable.Location()))
+		query = fmt.Sprintf("CREATE VIEW %s AS SELECT %s as entity,  %s as value,  IDENTIFIER('%s') as ts FROM TABLE('%s')", sanitize(tableName),
+			entity, value, schema.TS, sanitize(schema.SourceTable.Location()))
 	} else {
-		query = fmt.Sprintf("CREATE VIEW %s AS SELECT IDENTIFIER('%s') as entity, IDENTIFIER('%s') as value, to_timestamp_ntz('%s', 'YYYY-DD-MM HH24:MI:SS +0000 UTC')::TIMESTAMP_NTZ as ts FROM TABLE('%s')", sanitize(tableName),
-			schema.Entity, schema.Value, time.UnixMilli(0).UTC(), sanitize(schema.SourceTable.Location()))
+		query = fmt.Sprintf("CREATE VIEW %s AS SELECT %s as entity, %s as value, to_timestamp_ntz('%s', 'YYYY-DD-MM HH24:MI:SS +0000 UTC')::TIMESTAMP_NTZ as ts FROM TABLE('%s')", sanitize(tableName),
+			entity, value, time.UnixMilli(0).UTC(), sanitize(schema.SourceTable.Location()))
 	}
 	if _, err := db.Exec(query); err != nil {
 		wrapped := fferr.NewExecutionError("SQL", err)
@@ -1676,6 +2230,30 @@ func (q defaultOfflineSQLQueries) getColumns(db *sql.DB, name string) ([]TableCo
 	return columnNames, nil
 }
 
+// sourceColumnType returns the data_type of a column in the source table, or an
+// empty string if the column isn't found.
+func (q defaultOfflineSQLQueries) sourceColumnType(db *sql.DB, location *pl.SQLLocation, column string) (string, error) {
+	bind := q.newVariableBindingIterator()
+	args := []interface{}{location.GetTable(), column}
+	qry := fmt.Sprintf("SELECT data_type FROM information_schema.columns WHERE table_name = %s AND column_name = %s", bind.Next(), bind.Next())
+	if schema := location.GetSchema(); schema != "" {
+		qry += fmt.Sprintf(" AND table_schema = %s", bind.Next())
+		args = append(args, schema)
+	} else {
+		qry += " AND table_schema = CURRENT_SCHEMA()"
+	}
+	var dataType string
+	if err := db.QueryRow(qry, args...).Scan(&dataType); err == sql.ErrNoRows {
+		return "", nil
+	} else if err != nil {
+		wrapped := fferr.NewExecutionError("SQL", err)
+		wrapped.AddDetail("table_name", location.GetTable())
+		wrapped.AddDetail("column", column)
+		return "", wrapped
+	}
+	return dataType, nil
+}
+
 func (q defaultOfflineSQLQueries) primaryTableCreate(name string, columnString string) string {
 	return fmt.Sprintf("CREATE TABLE %s ( %s )", sanitize(name), columnString)
 }
@@ -1713,6 +2291,44 @@ func (q defaultOfflineSQLQueries) materializationUpdate(db *sql.DB, tableName st
 	return nil
 }
 
+// incrementalMaterializationSelect merges the source rows newer than the
+// materialization's watermark into its current rows, keeping the latest row per
+// entity. Each materialized row already holds its entity's latest timestamp, so
+// MAX(ts) over the materialization is the watermark of the previous run.
+func incrementalMaterializationSelect(tableName string, sourceName string) string {
+	sanitizedTable := sanitize(tableName)
+	return fmt.Sprintf(
+		"SELECT entity, value, ts, row_number() over(ORDER BY (SELECT NULL)) as row_number FROM "+
+			"(SELECT entity, ts, value, row_number() OVER (PARTITION BY entity ORDER BY ts desc) AS rn FROM "+
+			"(SELECT entity, ts, value FROM %s UNION ALL "+
+			"SELECT entity, ts, value FROM %s WHERE NOT EXISTS (SELECT 1 FROM %s) OR ts > (SELECT MAX(ts) FROM %s)) u"+
+			") t WHERE rn=1",
+		sanitizedTable, sanitize(sourceName), sanitizedTable, sanitizedTable,
+	)
+}
+
+func (q defaultOfflineSQLQueries) materializationIncrementalUpdate(db *sql.DB, tableName string, sourceName string) error {
+	sanitizedTable := sanitize(tableName)
+	tempTable := sanitize(fmt.Sprintf("tmp_%s", tableName))
+	oldTable := sanitize(fmt.Sprintf("old_%s", tableName))
+	query := fmt.Sprintf(
+		"BEGIN TRANSACTION;"+
+			"CREATE TABLE IF NOT EXISTS %s AS (%s);"+
+			"ALTER TABLE %s RENAME TO %s;"+
+			"ALTER TABLE %s RENAME TO %s;"+
+			"DROP TABLE %s;"+
+			"COMMIT;"+
+			"", tempTable, incrementalMaterializationSelect(tableName, sourceName), sanitizedTable, oldTable, tempTable, sanitizedTable, oldTable)
+	var numStatements = 6
+	stmt, _ := sf.WithMultiStatement(context.TODO(), numStatements)
+	if _, err := db.QueryContext(stmt, query); err != nil {
+		wrapped := fferr.NewExecutionError("SQL", err)
+		wrapped.AddDetail("table_name", tableName)
+		return wrapped
+	}
+	return nil
+}
+
 func (q defaultOfflineSQLQueries) getTable() string {
 	bind := q.newVariableBindingIterator()
 	return fmt.Sprintf("SELECT DISTINCT (table_name) FROM information_schema.tables WHERE table_name=%s and table_schema = CURRENT_SCHEMA()", bind.Next())
@@ -1739,6 +2355,58 @@ func (q defaultOfflineSQLQueries) trainingRowSplitSelect(columns string, trainin
 	// throw unimiplemented error
 	return "", ""
 }
+
+// trainingSetSplitBucket returns an expression that deterministically hashes a label's
+// entity and timestamp into [0, trainingSetSplitBuckets).
+func (q defaultOfflineSQLQueries) trainingSetSplitBucket(entity, ts string, seed int64) string {
+	return fmt.Sprintf("MOD(ABS(HASH(%s, %s, %d)), %d)", entity, ts, seed, trainingSetSplitBuckets)
+}
+
+// maxFeatureAgeFilter returns an extra join condition that drops feature values older
+// than maxAge relative to the label timestamp, or an empty string if maxAge is unset.
+func (q defaultOfflineSQLQueries) maxFeatureAgeFilter(featureTS, labelTS string, maxAge time.Duration) string {
+	if maxAge <= 0 {
+		return ""
+	}
+	return fmt.Sprintf(" AND %s >= %s - INTERVAL '%d seconds'", featureTS, labelTS, int64(maxAge.Seconds()))
+}
+
+// shiftTimestamp returns an expression for ts moved forward by delta. Lag features
+// are joined on their shifted timestamps.
+func (q defaultOfflineSQLQueries) shiftTimestamp(ts string, delta time.Duration) string {
+	return fmt.Sprintf("(%s + INTERVAL '%d seconds')", ts, int64(delta.Seconds()))
+}
+
+// lagFeatureColumnName returns the training set column of a lag feature, which
+// is its LagName if it has one.
+func lagFeatureColumnName(lagFeature LagFeatureDef, tableName string) string {
+	if lagFeature.LagName != "" {
+		return lagFeature.LagName
+	}
+	return fmt.Sprintf("%s_lag_%s", tableName, lagFeature.LagDelta)
+}
+
+// trainingSetSplitSelect returns the extra select column that records which split a
+// training set row belongs to, given a dialect-specific bucket expression.
+func trainingSetSplitSelect(split TrainingSetSplit, bucket string) string {
+	return ", " + trainingSetSplitCase(split, bucket, trainingSetSplitColumn)
+}
+
+// trainingSetSplitCase returns the split column's expression, aliased as alias so
+// dialects that fold unquoted identifiers can quote it.
+func trainingSetSplitCase(split TrainingSetSplit, bucket, alias string) string {
+	return fmt.Sprintf("CASE WHEN %s < %d THEN '%s' ELSE '%s' END AS %s",
+		bucket, split.threshold(), TrainingSetSplitTrain, TrainingSetSplitTest, alias)
+}
+
+func (q defaultOfflineSQLQueries) trainingSetExport(db *sql.DB, tableName string, location pl.Location, format filestore.FileType) ([]filestore.Filepath, error) {
+	return nil, fferr.NewUnimplementedErrorf("training set export is not supported for this provider")
+}
+
+func (q defaultOfflineSQLQueries) sourceImport(db *sql.DB, tableName string, files []filestore.Filepath) error {
+	return fferr.NewUnimplementedErrorf("source import is not supported for this provider")
+}
+
 func (q defaultOfflineSQLQueries) getValueColumnTypes(tableName string) string {
 	return fmt.Sprintf("SELECT * FROM %s", sanitize(tableName))
 }
@@ -1749,7 +2417,7 @@ func (q defaultOfflineSQLQueries) determineColumnType(valueType types.ValueType)
 		return "INT", nil
 	case types.Float32, types.Float64:
 		return "FLOAT8", nil
-	case types.String:
+	case types.String, types.JSON:
 		return "VARCHAR", nil
 	case types.Bool:
 		return "BOOLEAN", nil
@@ -1807,8 +2475,9 @@ func (q defaultOfflineSQLQueries) trainingSetQuery(store *sqlOfflineStore, def T
 		}
 		tableJoinAlias := fmt.Sprintf("t%d", i+1)
 		columns = append(columns, santizedName)
-		query = fmt.Sprintf("%s LEFT OUTER JOIN (SELECT entity, value as %s, ts FROM %s ORDER BY ts desc) as %s ON (%s.entity=t0.entity AND %s.ts <= t0.ts)",
-			query, santizedName, santizedName, tableJoinAlias, tableJoinAlias, tableJoinAlias)
+		ageFilter := q.maxFeatureAgeFilter(fmt.Sprintf("%s.ts", tableJoinAlias), "t0.ts", def.MaxFeatureAge)
+		query = fmt.Sprintf("%s LEFT OUTER JOIN (SELECT entity, value as %s, ts FROM %s ORDER BY ts desc) as %s ON (%s.entity=t0.entity AND %s.ts <= t0.ts%s)",
+			query, santizedName, santizedName, tableJoinAlias, tableJoinAlias, tableJoinAlias, ageFilter)
 
 	}
 	for i, lagFeature := range def.LagFeatures {
@@ -1817,26 +2486,33 @@ func (q defaultOfflineSQLQueries) trainingSetQuery(store *sqlOfflineStore, def T
 		if err != nil {
 			return err
 		}
-		lagColumnName := sanitize(lagFeature.LagName)
-		if lagFeature.LagName == "" {
-			lagColumnName = sanitize(fmt.Sprintf("%s_lag_%s", tableName, lagFeature.LagDelta))
-		}
+		lagColumnName := sanitize(lagFeatureColumnName(lagFeature, tableName))
 		columns = append(columns, lagColumnName)
 		sanitizedName := sanitize(tableName)
 		tableJoinAlias := fmt.Sprintf("t%d", lagFeaturesOffset+i+1)
-		timeDeltaSeconds := lagFeature.LagDelta.Seconds()
-		query = fmt.Sprintf("%s LEFT OUTER JOIN (SELECT entity, value as %s, ts FROM %s ORDER BY ts desc) as %s ON (%s.entity=t0.entity AND (%s.ts + INTERVAL '%f') <= t0.ts)",
-			query, lagColumnName, sanitizedName, tableJoinAlias, tableJoinAlias, tableJoinAlias, timeDeltaSeconds)
+		lagTS := q.shiftTimestamp(fmt.Sprintf("%s.ts", tableJoinAlias), lagFeature.LagDelta)
+		query = fmt.Sprintf("%s LEFT OUTER JOIN (SELECT entity, value as %s, ts FROM %s ORDER BY ts desc) as %s ON (%s.entity=t0.entity AND %s <= t0.ts)",
+			query, lagColumnName, sanitizedName, tableJoinAlias, tableJoinAlias, lagTS)
 	}
 
-	query = fmt.Sprintf("%s )) WHERE rn=1", query)
+	labelColumns, labelJoins, err := q.extraLabelJoins(store, def)
+	if err != nil {
+		return err
+	}
+	query = fmt.Sprintf("%s%s )) WHERE rn=1", query, labelJoins)
 	columnStr := strings.Join(columns, ", ")
+	innerColumnStr := strings.Join(append(columns, labelColumns...), ", ")
+	labelStr := strings.Join(append([]string{"label"}, labelColumns...), ", ")
+	splitSelect := ""
+	if def.Split != nil {
+		splitSelect = trainingSetSplitSelect(*def.Split, q.trainingSetSplitBucket("e", "time", def.Split.Seed))
+	}
 	if !isUpdate {
 		fullQuery := fmt.Sprintf(
-			"CREATE TABLE %s AS (SELECT %s, label FROM ("+
+			"CREATE TABLE %s AS (SELECT %s, %s%s FROM ("+
 				"SELECT *, row_number() over(PARTITION BY e, label, time ORDER BY time desc) as rn FROM ( "+
 				"SELECT t0.entity as e, t0.value as label, t0.ts as time, %s from %s as t0 %s )",
-			sanitize(tableName), columnStr, columnStr, sanitize(labelName), query)
+			sanitize(tableName), columnStr, labelStr, splitSelect, innerColumnStr, sanitize(labelName), query)
 		if _, err := store.db.Exec(fullQuery); err != nil {
 			wrapped := fferr.NewExecutionError("SQL", err)
 			wrapped.AddDetail("table_name", tableName)
@@ -1845,16 +2521,36 @@ func (q defaultOfflineSQLQueries) trainingSetQuery(store *sqlOfflineStore, def T
 	} else {
 		tempTable := sanitize(fmt.Sprintf("tmp_%s", tableName))
 		fullQuery := fmt.Sprintf(
-			"CREATE TABLE %s AS (SELECT %s, label FROM ("+
+			"CREATE TABLE %s AS (SELECT %s, %s%s FROM ("+
 				"SELECT *, row_number() over(PARTITION BY e, label, time ORDER BY time desc) as rn FROM ( "+
 				"SELECT t0.entity as e, t0.value as label, t0.ts as time, %s from %s as t0 %s )",
-			tempTable, columnStr, columnStr, sanitize(labelName), query)
+			tempTable, columnStr, labelStr, splitSelect, innerColumnStr, sanitize(labelName), query)
 		err := q.atomicUpdate(store.db, tableName, tempTable, fullQuery)
 		return err
 	}
 	return nil
 }
 
+// extraLabelJoins returns the columns and joins for a training set's labels after
+// the first, which is always t0. Each label is joined on the first label's entity
+// and timestamp, and its column is named after its table.
+func (q defaultOfflineSQLQueries) extraLabelJoins(store *sqlOfflineStore, def TrainingSetDef) ([]string, string, error) {
+	columns := make([]string, 0)
+	joins := ""
+	for i, label := range def.labelIDs()[1:] {
+		tableName, err := store.getResourceTableName(label)
+		if err != nil {
+			return nil, "", err
+		}
+		sanitizedName := sanitize(tableName)
+		tableJoinAlias := fmt.Sprintf("l%d", i+1)
+		columns = append(columns, sanitizedName)
+		joins = fmt.Sprintf("%s LEFT OUTER JOIN (SELECT entity, value as %s, ts FROM %s) as %s ON (%s.entity=t0.entity AND %s.ts = t0.ts)",
+			joins, sanitizedName, sanitizedName, tableJoinAlias, tableJoinAlias, tableJoinAlias)
+	}
+	return columns, joins, nil
+}
+
 func (q defaultOfflineSQLQueries) atomicUpdate(db *sql.DB, tableName string, tempName string, query string) error {
 	sanitizedTable := sanitize(tableName)
 	transaction := fmt.Sprintf(
@@ -1876,6 +2572,36 @@ func (q defaultOfflineSQLQueries) atomicUpdate(db *sql.DB, tableName string, tem
 	return nil
 }
 
+// trainingSetPersist copies the training set in tableName into persist.Table. The
+// copy is built in a temporary table that replaces persist.Table in the same
+// transaction, so an existing table is only replaced once the copy succeeds.
+func (q defaultOfflineSQLQueries) trainingSetPersist(db *sql.DB, tableName string, persist TrainingSetPersistAs) error {
+	tempTable := sanitize(fmt.Sprintf("tmp_%s", persist.Table))
+	persistTable := sanitize(persist.Table)
+	tx, err := db.Begin()
+	if err != nil {
+		return fferr.NewExecutionError("SQL", err)
+	}
+	defer tx.Rollback()
+	queries := []string{
+		fmt.Sprintf("DROP TABLE IF EXISTS %s", tempTable),
+		fmt.Sprintf("CREATE TABLE %s AS %s", tempTable, persist.selectQuery(sanitize(tableName))),
+		fmt.Sprintf("DROP TABLE IF EXISTS %s", persistTable),
+		fmt.Sprintf("ALTER TABLE %s RENAME TO %s", tempTable, persistTable),
+	}
+	for _, query := range queries {
+		if _, err := tx.Exec(query); err != nil {
+			wrapped := fferr.NewExecutionError("SQL", err)
+			wrapped.AddDetail("query", query)
+			return wrapped
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return fferr.NewExecutionError("SQL", err)
+	}
+	return nil
+}
+
 func (q defaultOfflineSQLQueries) trainingSetCreate(store *sqlOfflineStore, def TrainingSetDef, tableName string, labelName string) error {
 	return q.trainingSetQuery(store, def, tableName, labelName, false)
 }
@@ -1954,6 +2680,117 @@ func (q defaultOfflineSQLQueries) transformationUpdate(db *sql.DB, tableName str
 	return nil
 }
 
+// transformationWatermarkTable holds the watermark of each incremental
+// transformation, the greatest value of its IncrementalColumn as of its last run.
+const transformationWatermarkTable = "featureform_transformation_watermarks"
+
+func (q defaultOfflineSQLQueries) createTransformationWatermarkTable(db *sql.DB) error {
+	qry := fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s (table_name VARCHAR(1024), watermark VARCHAR(1024))", sanitize(transformationWatermarkTable))
+	if _, err := db.Exec(qry); err != nil {
+		wrapped := fferr.NewExecutionError("SQL", err)
+		wrapped.AddDetail("table_name", transformationWatermarkTable)
+		return wrapped
+	}
+	return nil
+}
+
+// transformationWatermark returns the watermark stored for a transformation, and
+// false if it doesn't have one.
+func (q defaultOfflineSQLQueries) transformationWatermark(db *sql.DB, tableName string) (string, bool, error) {
+	if err := q.createTransformationWatermarkTable(db); err != nil {
+		return "", false, err
+	}
+	bind := q.newVariableBindingIterator()
+	qry := fmt.Sprintf("SELECT watermark FROM %s WHERE table_name = %s", sanitize(transformationWatermarkTable), bind.Next())
+	var watermark string
+	if err := db.QueryRow(qry, tableName).Scan(&watermark); err == sql.ErrNoRows {
+		return "", false, nil
+	} else if err != nil {
+		wrapped := fferr.NewExecutionError("SQL", err)
+		wrapped.AddDetail("table_name", tableName)
+		return "", false, wrapped
+	}
+	return watermark, true, nil
+}
+
+// setTransformationWatermark stores the greatest value of column in the
+// transformation as its watermark, after it's been fully built.
+func (q defaultOfflineSQLQueries) setTransformationWatermark(db *sql.DB, tableName string, column string) error {
+	if err := q.createTransformationWatermarkTable(db); err != nil {
+		return err
+	}
+	tx, err := db.Begin()
+	if err != nil {
+		return fferr.NewExecutionError("SQL", err)
+	}
+	defer tx.Rollback()
+	if err := q.writeTransformationWatermark(tx, tableName, column); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		wrapped := fferr.NewExecutionError("SQL", err)
+		wrapped.AddDetail("table_name", tableName)
+		return wrapped
+	}
+	return nil
+}
+
+// transformationIncrementalUpdate appends the rows of query whose column is past
+// watermark to the transformation and advances its watermark, in one transaction.
+func (q defaultOfflineSQLQueries) transformationIncrementalUpdate(db *sql.DB, tableName string, query string, column string, watermark string) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return fferr.NewExecutionError("SQL", err)
+	}
+	defer tx.Rollback()
+	bind := q.newVariableBindingIterator()
+	insert := fmt.Sprintf("INSERT INTO %s SELECT * FROM ( %s ) AS incremental WHERE incremental.%s > %s", sanitize(tableName), query, sanitize(column), bind.Next())
+	if _, err := tx.Exec(insert, watermark); err != nil {
+		wrapped := fferr.NewExecutionError("SQL", err)
+		wrapped.AddDetail("table_name", tableName)
+		return wrapped
+	}
+	if err := q.writeTransformationWatermark(tx, tableName, column); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		wrapped := fferr.NewExecutionError("SQL", err)
+		wrapped.AddDetail("table_name", tableName)
+		return wrapped
+	}
+	return nil
+}
+
+// writeTransformationWatermark replaces the transformation's watermark with the
+// greatest value of column in it. An empty transformation has no watermark, so
+// its next update is a full build.
+func (q defaultOfflineSQLQueries) writeTransformationWatermark(tx *sql.Tx, tableName string, column string) error {
+	wrap := func(err error) error {
+		wrapped := fferr.NewExecutionError("SQL", err)
+		wrapped.AddDetail("table_name", tableName)
+		return wrapped
+	}
+	var watermark sql.NullString
+	maxQry := fmt.Sprintf("SELECT MAX(%s) FROM %s", sanitize(column), sanitize(tableName))
+	if err := tx.QueryRow(maxQry).Scan(&watermark); err != nil {
+		return wrap(err)
+	}
+	bind := q.newVariableBindingIterator()
+	deleteQry := fmt.Sprintf("DELETE FROM %s WHERE table_name = %s", sanitize(transformationWatermarkTable), bind.Next())
+	if _, err := tx.Exec(deleteQry, tableName); err != nil {
+		return wrap(err)
+	}
+	if !watermark.Valid {
+		return nil
+	}
+	bind = q.newVariableBindingIterator()
+	insertQry := fmt.Sprintf("INSERT INTO %s (table_name, watermark) VALUES (%s, %s)", sanitize(transformationWatermarkTable), bind.Next(), bind.Next())
+	if _, err := tx.Exec(insertQry, tableName, watermark.String); err != nil {
+		return wrap(err)
+	}
+	return nil
+}
+
 func (q defaultOfflineSQLQueries) transformationExists() string {
 	bind := q.newVariableBindingIterator()
 	return fmt.Sprintf("SELECT DISTINCT (table_name) FROM information_schema.tables WHERE table_name=%s AND table_schema=CURRENT_SCHEMA()", bind.Next())
@@ -1970,7 +2807,7 @@ func (q defaultOfflineSQLQueries) resourceTableColumns(obj pl.FullyQualifiedObje
 		obj.Schema = "PUBLIC"
 	}
 	var sb strings.Builder
-	sb.WriteString("SELECT COLUMN_NAME FROM ")
+	sb.WriteString("SELECT COLUMN_NAME, DATA_TYPE FROM ")
 	sb.WriteString(fmt.Sprintf("%s.INFORMATION_SCHEMA.COLUMNS ", obj.Database))
 	sb.WriteString(fmt.Sprintf("WHERE TABLE_SCHEMA = '%s' ", obj.Schema))
 	sb.WriteString(fmt.Sprintf("AND TABLE_NAME = '%s'", obj.Table))
@@ -0,0 +1,174 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+//
+// Copyright 2024 FeatureForm Inc.
+//
+
+package sqlcheck
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// filterKeywords are the words a filter can use besides column names and
+// functions. Anything that would let a filter read from another table, like
+// SELECT or FROM, isn't in here.
+var filterKeywords = map[string]bool{
+	"AND": true, "OR": true, "NOT": true, "IS": true, "NULL": true, "IN": true,
+	"BETWEEN": true, "LIKE": true, "ILIKE": true, "RLIKE": true, "ESCAPE": true,
+	"TRUE": true, "FALSE": true, "CASE": true, "WHEN": true, "THEN": true,
+	"ELSE": true, "END": true, "AS": true, "INTERVAL": true, "DATE": true,
+	"TIMESTAMP": true, "CURRENT_DATE": true, "CURRENT_TIMESTAMP": true,
+}
+
+// filterFunctions are the functions a filter can call. They're the scalar
+// functions that every offline store's dialect has, so a filter means the same
+// thing wherever it runs.
+var filterFunctions = map[string]bool{
+	"ABS": true, "CEIL": true, "CEILING": true, "FLOOR": true, "ROUND": true,
+	"MOD": true, "POWER": true, "SQRT": true, "EXP": true, "LN": true,
+	"LOWER": true, "UPPER": true, "TRIM": true, "LTRIM": true, "RTRIM": true,
+	"LENGTH": true, "SUBSTR": true, "SUBSTRING": true, "CONCAT": true,
+	"REPLACE": true, "COALESCE": true, "NULLIF": true, "GREATEST": true,
+	"LEAST": true, "CAST": true, "DATE_TRUNC": true, "TO_DATE": true,
+	"TO_TIMESTAMP": true,
+}
+
+// filterCastTypes are the types a filter can CAST to.
+var filterCastTypes = map[string]bool{
+	"INT": true, "INTEGER": true, "SMALLINT": true, "BIGINT": true,
+	"FLOAT": true, "DOUBLE": true, "REAL": true, "DECIMAL": true,
+	"NUMERIC": true, "BOOLEAN": true, "CHAR": true, "VARCHAR": true,
+	"STRING": true, "TEXT": true, "DATE": true, "TIMESTAMP": true,
+}
+
+// CheckFilter checks that filter is a single predicate over columns, the
+// columns of a materialization's source. Every word must be a keyword in
+// filterKeywords, a function in filterFunctions followed by a '(', or one of
+// columns; the word after AS must be a type in filterCastTypes.
+func CheckFilter(filter string, columns []string) error {
+	known := make(map[string]bool, len(columns))
+	for _, col := range columns {
+		known[strings.ToLower(col)] = true
+	}
+	return checkFilter(filter, func(name string) error {
+		if !known[strings.ToLower(name)] {
+			return fmt.Errorf("unknown column %q", name)
+		}
+		return nil
+	})
+}
+
+// CheckFilterSyntax checks filter like CheckFilter, for when its source's
+// columns aren't known yet, so every other word is taken to be a column. A
+// filter that passes must still be checked with CheckFilter before it's run.
+func CheckFilterSyntax(filter string) error {
+	return checkFilter(filter, func(string) error { return nil })
+}
+
+func checkFilter(filter string, checkColumn func(name string) error) error {
+	if strings.TrimSpace(filter) == "" {
+		return fmt.Errorf("filter is empty")
+	}
+	depth := 0
+	afterAs := false
+	runes := []rune(filter)
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			continue
+		case afterAs && !unicode.IsLetter(r):
+			return fmt.Errorf("expected a type after AS at position %d", i)
+		case r == '\'' || r == '"' || r == '`':
+			end := i + 1
+			for ; end < len(runes); end++ {
+				if runes[end] == r {
+					// A doubled quote is an escaped quote inside the literal.
+					if end+1 < len(runes) && runes[end+1] == r {
+						end++
+						continue
+					}
+					break
+				}
+			}
+			if end >= len(runes) {
+				return fmt.Errorf("unterminated %c at position %d", r, i)
+			}
+			// Double quotes and backticks quote identifiers, single quotes strings.
+			if r != '\'' {
+				name := strings.ReplaceAll(string(runes[i+1:end]), string([]rune{r, r}), string(r))
+				if err := checkColumn(name); err != nil {
+					return err
+				}
+				if end+1 < len(runes) && runes[end+1] == '.' {
+					return fmt.Errorf("qualified name %q, only source columns can be referenced", name)
+				}
+			}
+			i = end
+		case r == ';':
+			return fmt.Errorf("statement separators aren't allowed")
+		case r == '-' && i+1 < len(runes) && runes[i+1] == '-',
+			r == '/' && i+1 < len(runes) && runes[i+1] == '*':
+			return fmt.Errorf("comments aren't allowed")
+		case r == '(':
+			depth++
+		case r == ')':
+			depth--
+			if depth < 0 {
+				return fmt.Errorf("unexpected ')' at position %d", i)
+			}
+		case unicode.IsDigit(r):
+			for i+1 < len(runes) && (unicode.IsDigit(runes[i+1]) || runes[i+1] == '.' || runes[i+1] == 'e' || runes[i+1] == 'E') {
+				i++
+			}
+		case unicode.IsLetter(r) || r == '_':
+			end := i
+			for end < len(runes) && (unicode.IsLetter(runes[end]) || unicode.IsDigit(runes[end]) || runes[end] == '_') {
+				end++
+			}
+			word := string(runes[i:end])
+			next := end
+			for next < len(runes) && unicode.IsSpace(runes[next]) {
+				next++
+			}
+			upper := strings.ToUpper(word)
+			switch {
+			case afterAs:
+				if !filterCastTypes[upper] {
+					return fmt.Errorf("can't CAST to %s", word)
+				}
+				afterAs = false
+			case upper == "AS":
+				afterAs = true
+			case filterKeywords[upper]:
+			case next < len(runes) && runes[next] == '(':
+				if upper == "SELECT" || upper == "EXISTS" {
+					return fmt.Errorf("subqueries aren't allowed")
+				}
+				if !filterFunctions[upper] {
+					return fmt.Errorf("function %s isn't allowed", word)
+				}
+			case next < len(runes) && runes[next] == '.':
+				return fmt.Errorf("qualified name %q, only source columns can be referenced", word)
+			case upper == "SELECT" || upper == "FROM":
+				return fmt.Errorf("subqueries aren't allowed")
+			default:
+				if err := checkColumn(word); err != nil {
+					return err
+				}
+			}
+			i = end - 1
+		}
+	}
+	if afterAs {
+		return fmt.Errorf("expected a type after AS")
+	}
+	if depth > 0 {
+		return fmt.Errorf("%d unclosed '('", depth)
+	}
+	return nil
+}
@@ -0,0 +1,112 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+//
+// Copyright 2024 FeatureForm Inc.
+//
+
+// Package sqlcheck checks SQL transformation queries as they're registered,
+// before their {{ name.variant }} templates are replaced with table names.
+package sqlcheck
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+
+	pt "github.com/featureform/provider/provider_type"
+)
+
+var templateRegex = regexp.MustCompile(`\{\{([^{}]*)\}\}`)
+
+// Templates returns the trimmed name.variant of every {{ }} template in the
+// query, in order.
+func Templates(query string) []string {
+	matches := templateRegex.FindAllStringSubmatch(query, -1)
+	templates := make([]string, len(matches))
+	for i, match := range matches {
+		templates[i] = strings.TrimSpace(match[1])
+	}
+	return templates
+}
+
+// BackslashEscapes reports whether the provider's SQL dialect allows a quote
+// inside a literal to be escaped with a backslash, e.g. 'it\'s'.
+func BackslashEscapes(t pt.Type) bool {
+	switch t {
+	case pt.SparkOffline, pt.BigQueryOffline, pt.MySqlOffline, pt.ClickHouseOffline, pt.DatabricksSQLOffline:
+		return true
+	default:
+		return false
+	}
+}
+
+// CheckQuery is a lexical check rather than a full parse, since each provider
+// speaks its own SQL dialect. It catches the mistakes that otherwise only
+// surface once a job is running: unterminated literals or comments, unbalanced
+// parentheses, and queries that aren't a SELECT.
+func CheckQuery(query string, backslashEscapes bool) error {
+	depth := 0
+	firstWord := ""
+	runes := []rune(query)
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		switch {
+		case r == '\'' || r == '"' || r == '`':
+			end := i + 1
+			for ; end < len(runes); end++ {
+				if backslashEscapes && runes[end] == '\\' {
+					end++
+					continue
+				}
+				if runes[end] == r {
+					// A doubled quote is an escaped quote inside the literal.
+					if end+1 < len(runes) && runes[end+1] == r {
+						end++
+						continue
+					}
+					break
+				}
+			}
+			if end >= len(runes) {
+				return fmt.Errorf("unterminated %c at position %d", r, i)
+			}
+			i = end
+		case r == '-' && i+1 < len(runes) && runes[i+1] == '-':
+			for i < len(runes) && runes[i] != '\n' {
+				i++
+			}
+		case r == '/' && i+1 < len(runes) && runes[i+1] == '*':
+			end := i + 2
+			for end+1 < len(runes) && !(runes[end] == '*' && runes[end+1] == '/') {
+				end++
+			}
+			if end+1 >= len(runes) {
+				return fmt.Errorf("unterminated comment at position %d", i)
+			}
+			i = end + 1
+		case r == '(':
+			depth++
+		case r == ')':
+			depth--
+			if depth < 0 {
+				return fmt.Errorf("unexpected ')' at position %d", i)
+			}
+		case firstWord == "" && unicode.IsLetter(r):
+			end := i
+			for end < len(runes) && (unicode.IsLetter(runes[end]) || unicode.IsDigit(runes[end]) || runes[end] == '_') {
+				end++
+			}
+			firstWord = strings.ToUpper(string(runes[i:end]))
+			i = end - 1
+		}
+	}
+	if depth > 0 {
+		return fmt.Errorf("%d unclosed '('", depth)
+	}
+	if firstWord != "SELECT" && firstWord != "WITH" {
+		return fmt.Errorf("expected a SELECT or WITH query, found %q", firstWord)
+	}
+	return nil
+}
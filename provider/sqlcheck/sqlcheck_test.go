@@ -0,0 +1,110 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+//
+// Copyright 2024 FeatureForm Inc.
+//
+
+package sqlcheck
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCheckQuery(t *testing.T) {
+	tests := []struct {
+		name             string
+		query            string
+		backslashEscapes bool
+		wantErr          bool
+	}{
+		{"Valid", "SELECT * FROM {{ name.variant }}", false, false},
+		{"ValidCTE", "-- daily counts\nWITH t AS (SELECT ')' AS c FROM {{name.variant}}) SELECT * FROM t", false, false},
+		{"DoubledQuote", "SELECT 'it''s' FROM {{name.variant}}", false, false},
+		{"BackslashEscape", `SELECT 'it\'s' FROM {{name.variant}}`, true, false},
+		{"BackslashWithoutEscapes", `SELECT 'it\'s' FROM {{name.variant}}`, false, true},
+		{"UnbalancedParens", "SELECT COUNT(* FROM {{name.variant}}", false, true},
+		{"UnterminatedString", "SELECT 'abc FROM {{name.variant}}", false, true},
+		{"UnterminatedComment", "SELECT * FROM {{name.variant}} /* trailing", false, true},
+		{"NotSelect", "DROP TABLE {{name.variant}}", false, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := CheckQuery(tt.query, tt.backslashEscapes); (err != nil) != tt.wantErr {
+				t.Fatalf("CheckQuery() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestTemplates(t *testing.T) {
+	templates := Templates("SELECT * FROM {{ name.variant }} JOIN {{other.v2}} ON '{'")
+	if expected := []string{"name.variant", "other.v2"}; !reflect.DeepEqual(templates, expected) {
+		t.Fatalf("expected %v, got %v", expected, templates)
+	}
+}
+
+func TestCheckFilter(t *testing.T) {
+	columns := []string{"user_id", "status", "Signup Date", "score"}
+	tests := map[string]struct {
+		Filter  string
+		Columns []string
+		Valid   bool
+	}{
+		"Comparison":        {"status = 'active'", columns, true},
+		"NestedFunctions":   {"coalesce(trim(status), '') <> ''", columns, true},
+		"DisallowedFunc":    {"pg_sleep(10) IS NULL", nil, false},
+		"UDF":               {"my_udf(score) > 1", columns, false},
+		"CaseInsensitive":   {"STATUS = 'active' AND Score > 1.5e2", columns, true},
+		"QuotedColumn":      {`"Signup Date" >= DATE '2024-01-01'`, columns, true},
+		"Function":          {"lower(status) IN ('active', 'trial')", columns, true},
+		"Cast":              {"CAST(score AS DOUBLE) BETWEEN 0 AND 1", columns, true},
+		"NullCheck":         {"status IS NOT NULL", columns, true},
+		"EscapedQuote":      {"status <> 'it''s; -- fine'", columns, true},
+		"UnknownColumn":     {"deleted = false", columns, false},
+		"NoColumns":         {"deleted = false", nil, false},
+		"Union":             {"1=1 UNION SELECT user_id FROM users", columns, false},
+		"UnknownKeyword":    {"score > 1 ORDER BY score", columns, false},
+		"CastType":          {"CAST(status AS VARCHAR(20)) = 'active'", columns, true},
+		"CastUnknownType":   {"CAST(score AS users) > 1", columns, false},
+		"AsSubquery":        {"CAST(score AS (SELECT 1)) > 1", columns, false},
+		"AsQuoted":          {`CAST(score AS "users") > 1`, columns, false},
+		"AsLast":            {"score AS", columns, false},
+		"Qualified":         {"other.status = 'active'", columns, false},
+		"QuotedQualified":   {`"other".status = 'active'`, columns, false},
+		"Subquery":          {"user_id IN (SELECT user_id FROM banned)", columns, false},
+		"Exists":            {"EXISTS (SELECT 1)", nil, false},
+		"StatementSplit":    {"status = 'active'; DROP TABLE users", columns, false},
+		"Comment":           {"status = 'active' -- AND score > 1", columns, false},
+		"Unbalanced":        {"(status = 'active'", columns, false},
+		"UnterminatedQuote": {"status = 'active", columns, false},
+		"Empty":             {"  ", nil, false},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := CheckFilter(test.Filter, test.Columns)
+			if test.Valid && err != nil {
+				t.Fatalf("Expected %q to be valid: %s", test.Filter, err)
+			} else if !test.Valid && err == nil {
+				t.Fatalf("Expected %q to be invalid", test.Filter)
+			}
+		})
+	}
+}
+
+func TestCheckFilterSyntax(t *testing.T) {
+	if err := CheckFilterSyntax("status = 'active' AND score > 1"); err != nil {
+		t.Fatalf("Expected filter over unknown columns to be valid: %s", err)
+	}
+	for _, filter := range []string{
+		"status = 'active'; DROP TABLE users",
+		"user_id IN (SELECT user_id FROM banned)",
+		"CAST(score AS (SELECT 1)) > 1",
+		"pg_sleep(10) IS NULL",
+	} {
+		if err := CheckFilterSyntax(filter); err == nil {
+			t.Fatalf("Expected %q to be invalid", filter)
+		}
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"sort"
 	"strings"
+	"time"
 
 	"github.com/featureform/fferr"
 	"github.com/featureform/logging"
@@ -21,6 +22,9 @@ type QueryConfig struct {
 	UseAsOfJoin bool
 	QuoteChar   string
 	QuoteTable  bool
+	// ShiftTimestamp returns the dialect's expression for ts moved forward by
+	// delta. It's required to compile lag features and a max feature age.
+	ShiftTimestamp func(ts string, delta time.Duration) string
 }
 
 type BuilderParams struct {
@@ -30,6 +34,23 @@ type BuilderParams struct {
 	SanitizedFeatureTables []string
 	FeatureNameVariants    []metadata.ResourceID
 	FeatureEntityNames     []string
+	LagFeatures            []LagFeature
+	// MaxFeatureAge, if set, is the oldest a feature value can be relative to
+	// its label. Older values are null. It doesn't apply to lag features.
+	MaxFeatureAge time.Duration
+	// SplitColumn, if set, returns the select expression, alias included, that
+	// assigns a row to a train or test split given the label's entity and
+	// timestamp columns. The timestamp is NULL if the label doesn't have one.
+	SplitColumn func(entity, ts string) string
+}
+
+// LagFeature is the value of one of the training set's features as of Lag
+// before each label.
+type LagFeature struct {
+	// FeatureIndex is the index of the lagged feature in BuilderParams.
+	FeatureIndex int
+	Lag          time.Duration
+	ColumnAlias  string
 }
 
 // NewTrainingSet creates a new training set query builder based on the label and feature columns provided.
@@ -37,6 +58,7 @@ func NewTrainingSet(config QueryConfig, params BuilderParams) *TrainingSet {
 	lbtTable := labelTable{
 		SanitizedTableName: params.SanitizedLabelTable,
 		EntityMappings:     params.LabelEntityMappings,
+		SplitColumn:        params.SplitColumn,
 	}
 
 	featureTables := make([]featureTable, len(params.FeatureColumns))
@@ -48,8 +70,21 @@ func NewTrainingSet(config QueryConfig, params BuilderParams) *TrainingSet {
 			SanitizedTableName: params.SanitizedFeatureTables[i],
 			ColumnAliases:      []string{fmt.Sprintf("feature__%s__%s", params.FeatureNameVariants[i].Name, params.FeatureNameVariants[i].Variant)},
 			EntityName:         params.FeatureEntityNames[i],
+			MaxAge:             params.MaxFeatureAge,
 		}
 	}
+	for _, lag := range params.LagFeatures {
+		ft := featureTables[lag.FeatureIndex]
+		featureTables = append(featureTables, featureTable{
+			Entity:             ft.Entity,
+			Values:             []string{ft.Values[0]},
+			TS:                 ft.TS,
+			SanitizedTableName: ft.SanitizedTableName,
+			ColumnAliases:      []string{lag.ColumnAlias},
+			EntityName:         ft.EntityName,
+			Lag:                lag.Lag,
+		})
+	}
 
 	return &TrainingSet{
 		labelTable:    lbtTable,
@@ -72,11 +107,47 @@ type featureTable struct {
 	SanitizedTableName string
 	ColumnAliases      []string
 	EntityName         string
+	// Lag, if set, shifts the table's timestamps forward so each label is joined
+	// to the values as of Lag before it.
+	Lag time.Duration
+	// MaxAge, if set, leaves out values older than MaxAge before the label.
+	MaxAge time.Duration
+}
+
+// minTSSQL returns the oldest timestamp a value can have to be joined to a
+// label at labelTS.
+func (ft featureTable) minTSSQL(labelTS string, config QueryConfig) string {
+	return config.ShiftTimestamp(labelTS, -ft.MaxAge)
+}
+
+// SourceSQL returns the table to join on. Lagged tables are wrapped in a
+// subquery that shifts their timestamp column.
+func (ft featureTable) SourceSQL(config QueryConfig) string {
+	if ft.Lag == 0 {
+		return ft.SanitizedTableName
+	}
+	return fmt.Sprintf("(SELECT %s, %s, %s AS %s FROM %s)",
+		ft.Entity, strings.Join(ft.Values, ", "), config.ShiftTimestamp(ft.TS, ft.Lag), ft.TS, ft.SanitizedTableName)
 }
 
 type labelTable struct {
 	SanitizedTableName string
 	EntityMappings     *metadata.EntityMappings
+	SplitColumn        func(entity, ts string) string
+}
+
+// SplitSQL returns the split column to add to the select list, including its
+// leading comma, or nothing if the training set isn't split. Rows are split by
+// the label's first entity.
+func (lt labelTable) SplitSQL() string {
+	if lt.SplitColumn == nil || len(lt.EntityMappings.Mappings) == 0 {
+		return ""
+	}
+	ts := "NULL"
+	if lt.EntityMappings.TimestampColumn != "" {
+		ts = "l." + lt.EntityMappings.TimestampColumn
+	}
+	return ", " + lt.SplitColumn("l."+lt.EntityMappings.Mappings[0].EntityColumn, ts)
 }
 
 type featureTableMap map[string]*featureTable
@@ -176,7 +247,7 @@ type asOfJoins []asOfJoin
 func (j asOfJoins) ToSQL(config QueryConfig) string {
 	joins := make([]string, len(j))
 	for i, join := range j {
-		joins[i] = join.ToSQL()
+		joins[i] = join.ToSQL(config)
 	}
 	return strings.Join(joins, " ")
 }
@@ -201,7 +272,7 @@ func (j windowJoin) EntitySQL(config QueryConfig) string {
 }
 
 // ToSQL creates an ASOF JOIN between the label and feature tables.
-func (j windowJoin) FeatureSQL(index int) string {
+func (j windowJoin) FeatureSQL(index int, config QueryConfig) string {
 	var sb strings.Builder
 	sb.WriteString(fmt.Sprintf(
 		`feature_%d AS (
@@ -228,12 +299,11 @@ func (j windowJoin) FeatureSQL(index int) string {
     FROM labels l
     LEFT JOIN %s f%d
       ON l.%s = f%d.%s
-      AND f%d.%s <= l.ts
-),`,
+      AND f%d.%s <= l.ts`,
 		j.entity,
 		index,
 		j.ft.TS,
-		j.ft.SanitizedTableName,
+		j.ft.SourceSQL(config),
 		index,
 		j.entity,
 		index,
@@ -241,6 +311,16 @@ func (j windowJoin) FeatureSQL(index int) string {
 		index,
 		j.ft.TS,
 	))
+	if j.ft.MaxAge != 0 {
+		sb.WriteString(fmt.Sprintf(`
+      AND f%d.%s >= %s`,
+			index,
+			j.ft.TS,
+			j.ft.minTSSQL("l.ts", config),
+		))
+	}
+	sb.WriteString(`
+),`)
 
 	sb.WriteString(fmt.Sprintf(`
 feature_%d_filtered AS (
@@ -311,7 +391,7 @@ func (j windowJoins) HeaderSQL(config QueryConfig) string {
 	))
 
 	for i, j := range j.windows {
-		joins[i] = j.FeatureSQL(i + 1)
+		joins[i] = j.FeatureSQL(i+1, config)
 	}
 	sb.WriteString(strings.Join(joins, ",\n"))
 	sb.WriteString("\n")
@@ -385,8 +465,8 @@ type asOfJoin struct {
 }
 
 // ToSQL creates an ASOF JOIN between the label and feature tables.
-func (j asOfJoin) ToSQL() string {
-	joinClause := fmt.Sprintf("%s %s %s", joinAsOf, j.ft.SanitizedTableName, j.alias)
+func (j asOfJoin) ToSQL(config QueryConfig) string {
+	joinClause := fmt.Sprintf("%s %s %s", joinAsOf, j.ft.SourceSQL(config), j.alias)
 	matchCondition := fmt.Sprintf("MATCH_CONDITION(l.%s >= %s.%s)", j.lblTS, j.alias, j.ft.TS)
 	onClause := fmt.Sprintf("ON(l.%s = %s.%s)", j.lblEntity, j.alias, j.ft.Entity)
 	return fmt.Sprintf("%s %s %s", joinClause, matchCondition, onClause)
@@ -397,10 +477,16 @@ type col struct {
 	tableAlias string
 	val        string
 	colAlias   string
+	// condition, if set, is what has to hold for the value to be selected
+	// rather than null.
+	condition string
 }
 
 // ToSQL returns the SQL representation of the column, with the table alias, column name, and column alias.
 func (c col) ToSQL(config QueryConfig) string {
+	if c.condition != "" {
+		return fmt.Sprintf("CASE WHEN %s THEN %s.%s END AS %s%s%s", c.condition, c.tableAlias, c.val, config.QuoteChar, c.colAlias, config.QuoteChar)
+	}
 	return fmt.Sprintf("%s.%s AS %s%s%s", c.tableAlias, c.val, config.QuoteChar, c.colAlias, config.QuoteChar)
 }
 
@@ -419,7 +505,7 @@ type pitTrainingSetQueryBuilder struct {
 // the same table, entity, and timestamp column as another feature, then the values and column aliases
 // are combined into a single feature table so that the query uses a single join for all.
 func (b *pitTrainingSetQueryBuilder) AddFeature(tbl featureTable) {
-	key := createTableKey(tbl.SanitizedTableName, tbl.EntityName, tbl.Entity, tbl.TS)
+	key := createTableKey(tbl.SanitizedTableName, tbl.EntityName, tbl.Entity, tbl.TS, tbl.Lag)
 	existing, exists := b.featureTableMap[key]
 	if exists {
 		existing.Values = append(existing.Values, tbl.Values...)
@@ -446,11 +532,23 @@ func (b *pitTrainingSetQueryBuilder) Compile() error {
 		ftAlias := fmt.Sprintf("f%d", i+1)
 		// COLUMNS
 
+		if err := validateLagFeatureTable(*ft, b.config); err != nil {
+			return err
+		}
+		if err := validateMaxAgeFeatureTable(*ft, b.config); err != nil {
+			return err
+		}
+		// An ASOF join can't bound how old its match is, so values that are too
+		// old are nulled out instead. The window joins filter them in the join.
+		condition := ""
+		if ft.MaxAge != 0 && ft.TS != "" && b.config.UseAsOfJoin {
+			condition = fmt.Sprintf("%s.%s >= %s", ftAlias, ft.TS, ft.minTSSQL("l."+b.labelTable.EntityMappings.TimestampColumn, b.config))
+		}
 		for i, val := range ft.Values {
 			if err := validateFeatureTable(*ft); err != nil {
 				return err
 			}
-			b.columns = append(b.columns, col{tableAlias: ftAlias, val: val, colAlias: ft.ColumnAliases[i]})
+			b.columns = append(b.columns, col{tableAlias: ftAlias, val: val, colAlias: ft.ColumnAliases[i], condition: condition})
 		}
 		// JOINS
 		for _, m := range b.labelTable.EntityMappings.Mappings {
@@ -488,7 +586,7 @@ func (b *pitTrainingSetQueryBuilder) ToSQL() string {
 	// WINDOW (Alternative to ASOF on platforms that don't support it)
 	sb.WriteString(b.windowJoins.HeaderSQL(b.config))
 	// SELECT
-	sb.WriteString(fmt.Sprintf("SELECT %s, l.%s AS label", b.columns.ToSQL(b.config), b.labelTable.EntityMappings.ValueColumn))
+	sb.WriteString(fmt.Sprintf("SELECT %s, l.%s AS label%s", b.columns.ToSQL(b.config), b.labelTable.EntityMappings.ValueColumn, b.labelTable.SplitSQL()))
 	// FROM
 	sb.WriteString(fmt.Sprintf(" FROM %s%s%s l ", quoteChar, b.labelTable.SanitizedTableName, quoteChar))
 	// JOIN(s)
@@ -518,7 +616,7 @@ type trainingSetQueryBuilder struct {
 // entity, and timestamp column as another feature, then the values and column aliases are combined into
 // a single feature table so that the query uses a single join and/or CTE for all.
 func (b *trainingSetQueryBuilder) AddFeature(tbl featureTable) {
-	key := createTableKey(tbl.SanitizedTableName, tbl.EntityName, tbl.Entity, tbl.TS)
+	key := createTableKey(tbl.SanitizedTableName, tbl.EntityName, tbl.Entity, tbl.TS, tbl.Lag)
 	existing, exists := b.featureTableMap[key]
 	if exists {
 		existing.Values = append(existing.Values, tbl.Values...)
@@ -538,6 +636,16 @@ func (b *trainingSetQueryBuilder) Compile() error {
 		if err := validateFeatureTable(*ft); err != nil {
 			return err
 		}
+		// Without label timestamps there's nothing for a lag or an age to be
+		// relative to.
+		if ft.Lag != 0 {
+			logging.GlobalLogger.Errorw("lag features require a label timestamp", "feature_table", ft)
+			return fferr.NewInvalidArgumentErrorf("lag features require a label timestamp column")
+		}
+		if ft.MaxAge != 0 && ft.TS != "" {
+			logging.GlobalLogger.Errorw("max feature age requires a label timestamp", "feature_table", ft)
+			return fferr.NewInvalidArgumentErrorf("max feature age requires a label timestamp column")
+		}
 		ftAlias := fmt.Sprintf("f%d", i+1)
 		usesCTE := ft.TS != ""
 		// CTE
@@ -571,7 +679,7 @@ func (b *trainingSetQueryBuilder) ToSQL() string {
 	// CTE(s)
 	sb.WriteString(b.ctes.ToSQL(b.config))
 	// SELECT
-	sb.WriteString(fmt.Sprintf("SELECT %s, l.%s AS label ", b.columns.ToSQL(b.config), b.labelTable.EntityMappings.ValueColumn))
+	sb.WriteString(fmt.Sprintf("SELECT %s, l.%s AS label%s ", b.columns.ToSQL(b.config), b.labelTable.EntityMappings.ValueColumn, b.labelTable.SplitSQL()))
 	// FROM
 	sb.WriteString(fmt.Sprintf("FROM %s%s%s l ", quoteChar, b.labelTable.SanitizedTableName, quoteChar))
 	// JOIN(s)
@@ -605,6 +713,35 @@ func validateFeatureTable(ft featureTable) error {
 	return nil
 }
 
+// validateLagFeatureTable validates that a lagged feature table can be shifted.
+func validateLagFeatureTable(ft featureTable, config QueryConfig) error {
+	if ft.Lag == 0 {
+		return nil
+	}
+	if ft.TS == "" {
+		logging.GlobalLogger.Errorw("lag features require a feature timestamp", "feature_table", ft)
+		return fferr.NewInvalidArgumentErrorf("lag features require a feature timestamp column: %v", ft.ColumnAliases)
+	}
+	if config.ShiftTimestamp == nil {
+		logging.GlobalLogger.Errorw("lag features aren't supported by this query config", "feature_table", ft)
+		return fferr.NewInternalErrorf("lag features aren't supported by this query config")
+	}
+	return nil
+}
+
+// validateMaxAgeFeatureTable validates that a max age can be applied to a
+// feature table.
+func validateMaxAgeFeatureTable(ft featureTable, config QueryConfig) error {
+	if ft.MaxAge == 0 || ft.TS == "" {
+		return nil
+	}
+	if config.ShiftTimestamp == nil {
+		logging.GlobalLogger.Errorw("max feature age isn't supported by this query config", "feature_table", ft)
+		return fferr.NewInternalErrorf("max feature age isn't supported by this query config")
+	}
+	return nil
+}
+
 // validateLabelTable validates the label table.
 func validateLabelTable(lbl labelTable) error {
 	if lbl.EntityMappings == nil || len(lbl.EntityMappings.Mappings) == 0 {
@@ -632,6 +769,9 @@ func validateLabelTable(lbl labelTable) error {
 	return nil
 }
 
-func createTableKey(tableName, entityName, entityCol, ts string) string {
+func createTableKey(tableName, entityName, entityCol, ts string, lag time.Duration) string {
+	if lag != 0 {
+		return fmt.Sprintf("%s_%s_%s_%s_%d", tableName, entityName, entityCol, ts, int64(lag.Seconds()))
+	}
 	return fmt.Sprintf("%s_%s_%s_%s", tableName, entityName, entityCol, ts)
 }
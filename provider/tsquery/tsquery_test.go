@@ -1,7 +1,10 @@
 package tsquery
 
 import (
+	"fmt"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/featureform/metadata"
 )
@@ -264,3 +267,170 @@ func TestNewTrainingSetQueryBuilder(t *testing.T) {
 		})
 	}
 }
+
+func TestTrainingSetLagFeatures(t *testing.T) {
+	shift := func(ts string, delta time.Duration) string {
+		return fmt.Sprintf("DATEADD(second, %d, %s)", int64(delta.Seconds()), ts)
+	}
+	params := func(labelTS string) BuilderParams {
+		return BuilderParams{
+			LabelEntityMappings:    &metadata.EntityMappings{Mappings: []metadata.EntityMapping{{Name: "location", EntityColumn: "location_id"}}, ValueColumn: "wave_height_ft", TimestampColumn: labelTS},
+			SanitizedLabelTable:    "labels",
+			FeatureColumns:         []metadata.ResourceVariantColumns{{Entity: "location_id", Value: "wave_power_kj", TS: "measured_on"}},
+			SanitizedFeatureTables: []string{"features"},
+			FeatureNameVariants:    []metadata.ResourceID{{Name: "wave_power_kj", Variant: "variant"}},
+			FeatureEntityNames:     []string{"location"},
+			LagFeatures:            []LagFeature{{FeatureIndex: 0, Lag: 7 * 24 * time.Hour, ColumnAlias: "wave_power_last_week"}},
+		}
+	}
+	cases := []struct {
+		name        string
+		config      QueryConfig
+		params      BuilderParams
+		expectedErr bool
+		expectedSQL string
+	}{
+		{
+			name:        "ASOF Join",
+			config:      QueryConfig{UseAsOfJoin: true, QuoteChar: "\"", ShiftTimestamp: shift},
+			params:      params("observed_on"),
+			expectedSQL: `SELECT f1.wave_power_kj AS "feature__wave_power_kj__variant", f2.wave_power_kj AS "wave_power_last_week", l.wave_height_ft AS label FROM labels l  ASOF JOIN features f1 MATCH_CONDITION(l.observed_on >= f1.measured_on) ON(l.location_id = f1.location_id) ASOF JOIN (SELECT location_id, wave_power_kj, DATEADD(second, 604800, measured_on) AS measured_on FROM features) f2 MATCH_CONDITION(l.observed_on >= f2.measured_on) ON(l.location_id = f2.location_id);`,
+		},
+		{
+			name:        "Label Without Timestamp",
+			config:      QueryConfig{UseAsOfJoin: true, QuoteChar: "\"", ShiftTimestamp: shift},
+			params:      params(""),
+			expectedErr: true,
+		},
+		{
+			name:        "Dialect Without Shift",
+			config:      QueryConfig{UseAsOfJoin: true, QuoteChar: "\""},
+			params:      params("observed_on"),
+			expectedErr: true,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			sql, err := NewTrainingSet(c.config, c.params).CompileSQL()
+			if (err != nil) != c.expectedErr {
+				t.Fatalf("Expected error %v, got %v", c.expectedErr, err)
+			}
+			if !c.expectedErr && sql != c.expectedSQL {
+				t.Fatalf("Expected SQL:\n%s\nGot:\n%s", c.expectedSQL, sql)
+			}
+		})
+	}
+}
+
+func TestTrainingSetMaxFeatureAge(t *testing.T) {
+	shift := func(ts string, delta time.Duration) string {
+		return fmt.Sprintf("DATEADD(second, %d, %s)", int64(delta.Seconds()), ts)
+	}
+	params := func(labelTS string) BuilderParams {
+		return BuilderParams{
+			LabelEntityMappings:    &metadata.EntityMappings{Mappings: []metadata.EntityMapping{{Name: "location", EntityColumn: "location_id"}}, ValueColumn: "wave_height_ft", TimestampColumn: labelTS},
+			SanitizedLabelTable:    "labels",
+			FeatureColumns:         []metadata.ResourceVariantColumns{{Entity: "location_id", Value: "wave_power_kj", TS: "measured_on"}},
+			SanitizedFeatureTables: []string{"features"},
+			FeatureNameVariants:    []metadata.ResourceID{{Name: "wave_power_kj", Variant: "variant"}},
+			FeatureEntityNames:     []string{"location"},
+			MaxFeatureAge:          time.Hour,
+		}
+	}
+	cases := []struct {
+		name        string
+		config      QueryConfig
+		params      BuilderParams
+		expectedErr bool
+		contains    string
+	}{
+		{
+			name:     "ASOF Join",
+			config:   QueryConfig{UseAsOfJoin: true, QuoteChar: "\"", ShiftTimestamp: shift},
+			params:   params("observed_on"),
+			contains: `SELECT CASE WHEN f1.measured_on >= DATEADD(second, -3600, l.observed_on) THEN f1.wave_power_kj END AS "feature__wave_power_kj__variant", l.wave_height_ft AS label FROM labels l  ASOF JOIN features f1 MATCH_CONDITION(l.observed_on >= f1.measured_on) ON(l.location_id = f1.location_id);`,
+		},
+		{
+			name:     "Window Join",
+			config:   QueryConfig{UseAsOfJoin: false, QuoteChar: "`", ShiftTimestamp: shift},
+			params:   params("observed_on"),
+			contains: "AND f1.measured_on <= l.ts\n      AND f1.measured_on >= DATEADD(second, -3600, l.ts)\n),",
+		},
+		{
+			name:        "Label Without Timestamp",
+			config:      QueryConfig{UseAsOfJoin: true, QuoteChar: "\"", ShiftTimestamp: shift},
+			params:      params(""),
+			expectedErr: true,
+		},
+		{
+			name:        "Dialect Without Shift",
+			config:      QueryConfig{UseAsOfJoin: true, QuoteChar: "\""},
+			params:      params("observed_on"),
+			expectedErr: true,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			sql, err := NewTrainingSet(c.config, c.params).CompileSQL()
+			if (err != nil) != c.expectedErr {
+				t.Fatalf("Expected error %v, got %v", c.expectedErr, err)
+			}
+			if !c.expectedErr && !strings.Contains(sql, c.contains) {
+				t.Fatalf("Expected SQL to contain:\n%s\nGot:\n%s", c.contains, sql)
+			}
+		})
+	}
+}
+
+func TestTrainingSetSplitColumn(t *testing.T) {
+	split := func(entity, ts string) string {
+		return fmt.Sprintf("SPLIT(%s, %s) AS ff_split", entity, ts)
+	}
+	params := func(labelTS string) BuilderParams {
+		return BuilderParams{
+			LabelEntityMappings:    &metadata.EntityMappings{Mappings: []metadata.EntityMapping{{Name: "location", EntityColumn: "location_id"}}, ValueColumn: "wave_height_ft", TimestampColumn: labelTS},
+			SanitizedLabelTable:    "labels",
+			FeatureColumns:         []metadata.ResourceVariantColumns{{Entity: "location_id", Value: "wave_power_kj", TS: "measured_on"}},
+			SanitizedFeatureTables: []string{"features"},
+			FeatureNameVariants:    []metadata.ResourceID{{Name: "wave_power_kj", Variant: "variant"}},
+			FeatureEntityNames:     []string{"location"},
+			SplitColumn:            split,
+		}
+	}
+	cases := []struct {
+		name     string
+		config   QueryConfig
+		params   BuilderParams
+		contains string
+	}{
+		{
+			name:     "ASOF Join",
+			config:   QueryConfig{UseAsOfJoin: true, QuoteChar: "\""},
+			params:   params("observed_on"),
+			contains: "l.wave_height_ft AS label, SPLIT(l.location_id, l.observed_on) AS ff_split FROM labels l",
+		},
+		{
+			name:     "Window Join",
+			config:   QueryConfig{UseAsOfJoin: false, QuoteChar: "`"},
+			params:   params("observed_on"),
+			contains: "l.wave_height_ft AS label, SPLIT(l.location_id, l.observed_on) AS ff_split FROM labels l",
+		},
+		{
+			name:     "Label Without Timestamp",
+			config:   QueryConfig{UseAsOfJoin: true, QuoteChar: "\""},
+			params:   params(""),
+			contains: "l.wave_height_ft AS label, SPLIT(l.location_id, NULL) AS ff_split FROM labels l",
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			sql, err := NewTrainingSet(c.config, c.params).CompileSQL()
+			if err != nil {
+				t.Fatalf("Failed to compile: %v", err)
+			}
+			if !strings.Contains(sql, c.contains) {
+				t.Fatalf("Expected SQL to contain:\n%s\nGot:\n%s", c.contains, sql)
+			}
+		})
+	}
+}
@@ -27,4 +27,6 @@ type DirectCopyTarget string
 const (
 	NoDirectCopyTarget DirectCopyTarget = ""
 	DirectCopyDynamo   DirectCopyTarget = "dynamo"
+	DirectCopyRedis    DirectCopyTarget = "redis"
+	DirectCopyCosmos   DirectCopyTarget = "cosmos"
 )
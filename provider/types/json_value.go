@@ -0,0 +1,154 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+//
+// Copyright 2024 FeatureForm Inc.
+//
+
+package types
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/featureform/fferr"
+)
+
+// ParseJSONValue decodes a JSON encoded value of type t into the Go type that
+// online stores return for t. Timestamps are RFC 3339 strings, vectors are
+// arrays of numbers, and JSON values are kept as the encoded document.
+func ParseJSONValue(t ValueType, encoded string) (interface{}, error) {
+	if t == JSON {
+		if !json.Valid([]byte(encoded)) {
+			return nil, fferr.NewTypeErrorf(t.String(), encoded, "invalid JSON")
+		}
+		return encoded, nil
+	}
+	decoder := json.NewDecoder(strings.NewReader(encoded))
+	decoder.UseNumber()
+	var decoded interface{}
+	if err := decoder.Decode(&decoded); err != nil {
+		return nil, fferr.NewTypeError(t.String(), encoded, err)
+	}
+	if _, err := decoder.Token(); err != io.EOF {
+		return nil, fferr.NewTypeErrorf(t.String(), encoded, "unexpected data after the value")
+	}
+	if decoded == nil {
+		return nil, nil
+	}
+	if t.IsVector() {
+		return parseJSONVector(t.(VectorType), decoded)
+	}
+	value, err := parseJSONScalar(t.Scalar(), decoded)
+	if err != nil {
+		return nil, fferr.NewTypeError(t.String(), encoded, err)
+	}
+	return value, nil
+}
+
+func parseJSONVector(t VectorType, decoded interface{}) (interface{}, error) {
+	if t.ScalarType != Float32 {
+		return nil, fferr.NewTypeErrorf(t.String(), decoded, "only float32 vectors are supported")
+	}
+	elems, ok := decoded.([]interface{})
+	if !ok {
+		return nil, fferr.NewTypeErrorf(t.String(), decoded, "expected an array, got %T", decoded)
+	}
+	if t.Dimension > 0 && len(elems) != int(t.Dimension) {
+		return nil, fferr.NewTypeErrorf(t.String(), decoded, "expected %d elements, got %d", t.Dimension, len(elems))
+	}
+	vector := make([]float32, len(elems))
+	for i, elem := range elems {
+		value, err := parseJSONScalar(Float32, elem)
+		if err != nil {
+			return nil, fferr.NewTypeError(t.String(), decoded, err)
+		}
+		vector[i] = value.(float32)
+	}
+	return vector, nil
+}
+
+func parseJSONScalar(t ScalarType, decoded interface{}) (interface{}, error) {
+	switch t {
+	case Int, Int8, Int16, Int32, Int64:
+		num, ok := decoded.(json.Number)
+		if !ok {
+			return nil, fmt.Errorf("expected a number, got %T", decoded)
+		}
+		bits := map[ScalarType]int{Int: strconv.IntSize, Int8: 8, Int16: 16, Int32: 32, Int64: 64}[t]
+		i, err := strconv.ParseInt(num.String(), 10, bits)
+		if err != nil {
+			return nil, err
+		}
+		switch t {
+		case Int8:
+			return int8(i), nil
+		case Int16:
+			return int16(i), nil
+		case Int32:
+			return int32(i), nil
+		case Int64:
+			return i, nil
+		default:
+			return int(i), nil
+		}
+	case UInt8, UInt16, UInt32, UInt64:
+		num, ok := decoded.(json.Number)
+		if !ok {
+			return nil, fmt.Errorf("expected a number, got %T", decoded)
+		}
+		bits := map[ScalarType]int{UInt8: 8, UInt16: 16, UInt32: 32, UInt64: 64}[t]
+		u, err := strconv.ParseUint(num.String(), 10, bits)
+		if err != nil {
+			return nil, err
+		}
+		switch t {
+		case UInt8:
+			return uint8(u), nil
+		case UInt16:
+			return uint16(u), nil
+		case UInt32:
+			return uint32(u), nil
+		default:
+			return u, nil
+		}
+	case Float32, Float64:
+		num, ok := decoded.(json.Number)
+		if !ok {
+			return nil, fmt.Errorf("expected a number, got %T", decoded)
+		}
+		if t == Float32 {
+			f, err := strconv.ParseFloat(num.String(), 32)
+			return float32(f), err
+		}
+		return num.Float64()
+	case Bool:
+		b, ok := decoded.(bool)
+		if !ok {
+			return nil, fmt.Errorf("expected a boolean, got %T", decoded)
+		}
+		return b, nil
+	case String:
+		s, ok := decoded.(string)
+		if !ok {
+			return nil, fmt.Errorf("expected a string, got %T", decoded)
+		}
+		return s, nil
+	case Timestamp, Datetime:
+		s, ok := decoded.(string)
+		if !ok {
+			return nil, fmt.Errorf("expected an RFC 3339 string, got %T", decoded)
+		}
+		ts, err := time.Parse(time.RFC3339Nano, s)
+		if err != nil {
+			return nil, err
+		}
+		return ts.UTC(), nil
+	default:
+		return nil, fmt.Errorf("values of type %s can't be parsed", t)
+	}
+}
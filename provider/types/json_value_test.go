@@ -0,0 +1,75 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+//
+// Copyright 2024 FeatureForm Inc.
+//
+
+package types
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParseJSONValue(t *testing.T) {
+	tests := map[string]struct {
+		valueType ValueType
+		encoded   string
+		expected  interface{}
+	}{
+		"Int":       {Int, "3", 3},
+		"Int32":     {Int32, "-3", int32(-3)},
+		"Int64":     {Int64, "9007199254740993", int64(9007199254740993)},
+		"UInt8":     {UInt8, "255", uint8(255)},
+		"Float32":   {Float32, "1.5", float32(1.5)},
+		"Float64":   {Float64, "0", float64(0)},
+		"Bool":      {Bool, "true", true},
+		"String":    {String, `"unknown"`, "unknown"},
+		"Timestamp": {Timestamp, `"2024-01-02T03:04:05Z"`, time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)},
+		"JSON":      {JSON, `{"a": [1, 2]}`, `{"a": [1, 2]}`},
+		"Null":      {String, "null", nil},
+		"Vector":    {VectorType{ScalarType: Float32, Dimension: 3}, "[0, 0.5, 1]", []float32{0, 0.5, 1}},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			actual, err := ParseJSONValue(test.valueType, test.encoded)
+			if err != nil {
+				t.Fatalf("could not parse %s as %s: %v", test.encoded, test.valueType, err)
+			}
+			if !reflect.DeepEqual(actual, test.expected) {
+				t.Fatalf("expected %#v, got %#v", test.expected, actual)
+			}
+		})
+	}
+}
+
+func TestParseJSONValueFail(t *testing.T) {
+	tests := map[string]struct {
+		valueType ValueType
+		encoded   string
+	}{
+		"String As Int":       {Int, `"3"`},
+		"Float As Int":        {Int, "3.5"},
+		"Int8 Overflow":       {Int8, "128"},
+		"Negative Unsigned":   {UInt32, "-1"},
+		"Number As String":    {String, "3"},
+		"Bad Timestamp":       {Timestamp, `"yesterday"`},
+		"Invalid JSON":        {JSON, "{"},
+		"Malformed":           {Float64, "1.0.0"},
+		"Vector Dimension":    {VectorType{ScalarType: Float32, Dimension: 2}, "[1]"},
+		"Unsupported Vector":  {VectorType{ScalarType: Int, Dimension: 1}, "[1]"},
+		"Untyped Feature":     {NilType, "1"},
+		"Vector Of Strings":   {VectorType{ScalarType: Float32, Dimension: 1}, `["a"]`},
+		"Scalar As Vector":    {VectorType{ScalarType: Float32, Dimension: 1}, "1"},
+		"Trailing Characters": {Bool, "true false"},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			if value, err := ParseJSONValue(test.valueType, test.encoded); err == nil {
+				t.Fatalf("expected parsing %s as %s to fail, got %#v", test.encoded, test.valueType, value)
+			}
+		})
+	}
+}
@@ -43,6 +43,9 @@ const (
 	Bool      ScalarType = "bool"
 	Timestamp ScalarType = "time.Time"
 	Datetime  ScalarType = "datetime"
+	// JSON values are well-formed JSON documents. They're stored and served as
+	// strings and never parsed.
+	JSON ScalarType = "json"
 )
 
 var ScalarTypes = map[ScalarType]bool{
@@ -62,6 +65,7 @@ var ScalarTypes = map[ScalarType]bool{
 	Bool:      true,
 	Timestamp: true,
 	Datetime:  true,
+	JSON:      true,
 }
 
 var scalarToProto = map[ScalarType]pb.ScalarType{
@@ -73,6 +77,7 @@ var scalarToProto = map[ScalarType]pb.ScalarType{
 	Float64: pb.ScalarType_FLOAT64,
 	String:  pb.ScalarType_STRING,
 	Bool:    pb.ScalarType_BOOL,
+	JSON:    pb.ScalarType_JSON,
 }
 
 // Created in init() as the inverse of scalarToProto
@@ -212,6 +217,43 @@ func (t VectorType) ToProto() *pb.ValueType {
 	}
 }
 
+// scalarWidenings lists the scalar types that each type's values can be
+// losslessly converted to. Any conversion not listed here is breaking.
+var scalarWidenings = map[ScalarType][]ScalarType{
+	Int:       {Int64, Float64},
+	Int8:      {Int, Int16, Int32, Int64, Float32, Float64},
+	Int16:     {Int, Int32, Int64, Float32, Float64},
+	Int32:     {Int, Int64, Float64},
+	Int64:     {Int},
+	UInt8:     {UInt16, UInt32, UInt64, Int, Int16, Int32, Int64, Float32, Float64},
+	UInt16:    {UInt32, UInt64, Int, Int32, Int64, Float32, Float64},
+	UInt32:    {UInt64, Int, Int64, Float64},
+	Float32:   {Float64},
+	JSON:      {String},
+	Timestamp: {Datetime},
+	Datetime:  {Timestamp},
+}
+
+// IsBackwardCompatible returns true if values of type from can be read as type to
+// without losing information, e.g. int32 to int64. Changing a vector's embedding
+// flag is compatible, but changing its scalar type or dimension is not.
+func IsBackwardCompatible(from, to ValueType) bool {
+	if from == nil || from == NilType || from == to {
+		return true
+	}
+	if from.IsVector() || to.IsVector() {
+		fromVec, fromOk := from.(VectorType)
+		toVec, toOk := to.(VectorType)
+		return fromOk && toOk && fromVec.ScalarType == toVec.ScalarType && fromVec.Dimension == toVec.Dimension
+	}
+	for _, widened := range scalarWidenings[from.Scalar()] {
+		if widened == to.Scalar() {
+			return true
+		}
+	}
+	return false
+}
+
 type ScalarType string
 
 func (t ScalarType) Scalar() ScalarType {
@@ -252,7 +294,7 @@ func (t ScalarType) Type() reflect.Type {
 		return reflect.PointerTo(reflect.TypeOf(float32(0)))
 	case Float64:
 		return reflect.PointerTo(reflect.TypeOf(float64(0)))
-	case String:
+	case String, JSON:
 		return reflect.PointerTo(reflect.TypeOf(string("")))
 	case Bool:
 		return reflect.PointerTo(reflect.TypeOf(bool(false)))
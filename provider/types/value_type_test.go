@@ -104,3 +104,37 @@ func TestVectorTypeMarshaling(t *testing.T) {
 		}
 	}
 }
+
+func TestIsBackwardCompatible(t *testing.T) {
+	vec := VectorType{ScalarType: Float32, Dimension: 3}
+	tests := []struct {
+		name     string
+		from, to ValueType
+		expected bool
+	}{
+		{"Same", String, String, true},
+		{"FromNil", NilType, Int, true},
+		{"IntWidening", Int32, Int64, true},
+		{"UnsignedToSigned", UInt16, Int32, true},
+		{"IntToFloat", Int16, Float32, true},
+		{"FloatWidening", Float32, Float64, true},
+		{"JSONToString", JSON, String, true},
+		{"TimestampToDatetime", Timestamp, Datetime, true},
+		{"EmbeddingFlag", vec, VectorType{ScalarType: Float32, Dimension: 3, IsEmbedding: true}, true},
+		{"IntToString", Int, String, false},
+		{"IntNarrowing", Int64, Int32, false},
+		{"FloatToInt", Float64, Int64, false},
+		{"Int64ToFloat64", Int64, Float64, false},
+		{"StringToJSON", String, JSON, false},
+		{"VectorDimension", vec, VectorType{ScalarType: Float32, Dimension: 4}, false},
+		{"VectorScalar", vec, VectorType{ScalarType: Float64, Dimension: 3}, false},
+		{"ScalarToVector", Float32, vec, false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if actual := IsBackwardCompatible(test.from, test.to); actual != test.expected {
+				t.Fatalf("Expected IsBackwardCompatible(%v, %v) to be %v", test.from, test.to, test.expected)
+			}
+		})
+	}
+}
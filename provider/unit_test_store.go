@@ -48,6 +48,10 @@ func (u UnitTestProvider) Delete(location pl.Location) error {
 	return fferr.NewInternalErrorf("delete not implemented")
 }
 
+func (u UnitTestProvider) Capabilities() (Capabilities, error) {
+	return Capabilities{}, nil
+}
+
 type UnitTestStore interface {
 	GetTable(feature, variant string) (UnitTestTable, error)
 	CreateTable(feature, variant string, valueType types.ValueType) (UnitTestTable, error)
@@ -197,7 +201,7 @@ func (M MockUnitTestOfflineStore) RegisterResourceFromSourceTable(id ResourceID,
 	return nil, nil
 }
 
-func (M MockUnitTestOfflineStore) RegisterPrimaryFromSourceTable(id ResourceID, stableLocation pl.Location) (PrimaryTable, error) {
+func (M MockUnitTestOfflineStore) RegisterPrimaryFromSourceTable(id ResourceID, stableLocation pl.Location, opts ...PrimaryOption) (PrimaryTable, error) {
 	return nil, nil
 }
 
@@ -243,6 +247,10 @@ func (M MockUnitTestOfflineStore) ResourceLocation(id ResourceID, resource any)
 	return pl.NewFileLocation(filePath), nil
 }
 
+func (M MockUnitTestOfflineStore) GetResourceStats(id ResourceID, resource any) (ResourceStats, error) {
+	return ResourceStats{}, nil
+}
+
 type MockMaterialization struct{}
 
 func (m MockMaterialization) ID() MaterializationID {
@@ -340,3 +348,11 @@ func (m MockUnitTestOfflineStore) CreateTrainTestSplit(TrainTestSplitDef) (func(
 func (m MockUnitTestOfflineStore) GetTrainTestSplit(TrainTestSplitDef) (TrainingSetIterator, TrainingSetIterator, error) {
 	return nil, nil, nil
 }
+
+func (m MockUnitTestOfflineStore) GetTrainingSetSplit(ResourceID, string) (TrainingSetIterator, error) {
+	return nil, nil
+}
+
+func (m MockUnitTestOfflineStore) ExportTrainingSet(ResourceID, pl.Location, filestore.FileType) ([]filestore.Filepath, error) {
+	return nil, nil
+}
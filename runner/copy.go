@@ -8,9 +8,12 @@
 package runner
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/featureform/logging"
 
@@ -45,7 +48,12 @@ type MaterializedChunkRunner struct {
 	Materialized provider.Materialization
 	Table        provider.OnlineStoreTable
 	Store        provider.OnlineStore
-	ChunkIdx     int
+	// Offline is the store Materialized was read from. Both stores are
+	// closed when the chunk is done.
+	Offline  provider.OfflineStore
+	ChunkIdx int
+	// written is the number of rows set in the online store, updated atomically.
+	written int64
 }
 
 type ResultSync struct {
@@ -62,17 +70,40 @@ func (m *MaterializedChunkRunner) IsUpdateJob() bool {
 	return false
 }
 
+// RowsWritten is the number of rows the runner has set in the online store so far.
+func (m *MaterializedChunkRunner) RowsWritten() int64 {
+	return atomic.LoadInt64(&m.written)
+}
+
 func (m *MaterializedChunkRunner) Run() (types.CompletionWatcher, error) {
+	return m.RunContext(context.Background())
+}
+
+// RunContext copies the chunk like Run, but stops reading and writing records
+// once ctx is cancelled and returns its error.
+func (m *MaterializedChunkRunner) RunContext(ctx context.Context) (types.CompletionWatcher, error) {
 	logger := logging.NewLogger("Copy_to_Online")
 	done := make(chan interface{})
 	jobWatcher := &SyncWatcher{
 		ResultSync:  &ResultSync{},
 		DoneChannel: done,
 	}
+	// endWatch closes the runner's stores before reporting the result, so
+	// their connections are released by the time the watcher is done.
+	endWatch := func(err error) {
+		if closeErr := m.close(); err == nil {
+			err = closeErr
+		}
+		jobWatcher.EndWatch(err)
+	}
 	go func() {
+		if err := ctx.Err(); err != nil {
+			endWatch(err)
+			return
+		}
 		it, err := m.Materialized.IterateChunk(m.ChunkIdx)
 		if err != nil {
-			jobWatcher.EndWatch(err)
+			endWatch(err)
 			return
 		}
 		// The logic for the below code (i.e. the channel, goroutines, wait group and iteration loop)
@@ -103,7 +134,7 @@ func (m *MaterializedChunkRunner) Run() (types.CompletionWatcher, error) {
 			maxBatch, err := batchTable.MaxBatchSize()
 			if maxBatch <= 0 {
 				logger.Errorf("max batch size must be greater than 0")
-				jobWatcher.EndWatch(fferr.NewInternalErrorf("Max batch size must be greater than 0"))
+				endWatch(fferr.NewInternalErrorf("Max batch size must be greater than 0"))
 				return
 			}
 			setterFn = func() {
@@ -118,7 +149,11 @@ func (m *MaterializedChunkRunner) Run() (types.CompletionWatcher, error) {
 				}
 				buffer := make([]provider.SetItem, 0, maxBatch)
 				for record := range ch {
-					buffer = append(buffer, provider.SetItem{record.Entity, record.Value})
+					if ctx.Err() != nil {
+						setterCancelled(ctx, errCh)
+						return
+					}
+					buffer = append(buffer, provider.SetItem{Entity: record.Entity, Value: record.Value, TS: record.TS})
 					if len(buffer) == maxBatch {
 						if err := batchTable.BatchSet(buffer); err != nil {
 							logger.Errorf("error setting batch: %v", err)
@@ -126,6 +161,8 @@ func (m *MaterializedChunkRunner) Run() (types.CompletionWatcher, error) {
 							case errCh <- err:
 							default:
 							}
+						} else {
+							atomic.AddInt64(&m.written, int64(len(buffer)))
 						}
 						buffer = buffer[:0]
 					}
@@ -138,6 +175,8 @@ func (m *MaterializedChunkRunner) Run() (types.CompletionWatcher, error) {
 						case errCh <- err:
 						default:
 						}
+					} else {
+						atomic.AddInt64(&m.written, int64(len(buffer)))
 					}
 					buffer = buffer[:0]
 				}
@@ -146,11 +185,17 @@ func (m *MaterializedChunkRunner) Run() (types.CompletionWatcher, error) {
 			setterFn = func() {
 				defer wg.Done()
 				for record := range ch {
+					if ctx.Err() != nil {
+						setterCancelled(ctx, errCh)
+						return
+					}
 					if err := m.Table.Set(record.Entity, record.Value); err != nil {
 						select {
 						case errCh <- err:
 						default:
 						}
+					} else {
+						atomic.AddInt64(&m.written, 1)
 					}
 				}
 			}
@@ -161,6 +206,10 @@ func (m *MaterializedChunkRunner) Run() (types.CompletionWatcher, error) {
 		}
 		var chanErr error
 		for it.Next() {
+			if err := ctx.Err(); err != nil {
+				chanErr = err
+				break
+			}
 			select {
 			case chanErr = <-errCh:
 				logger.Errorf("error setting value: %v", chanErr)
@@ -187,28 +236,46 @@ func (m *MaterializedChunkRunner) Run() (types.CompletionWatcher, error) {
 		}
 		close(errCh)
 		if chanErr != nil {
-			jobWatcher.EndWatch(chanErr)
+			endWatch(chanErr)
 			return
 		}
 		if err = it.Err(); err != nil {
-			jobWatcher.EndWatch(err)
+			endWatch(err)
 			return
 		}
 		err = it.Close()
 		if err != nil {
-			jobWatcher.EndWatch(err)
-			return
-		}
-		err = m.Store.Close()
-		if err != nil {
-			jobWatcher.EndWatch(err)
+			endWatch(err)
 			return
 		}
-		jobWatcher.EndWatch(nil)
+		endWatch(nil)
 	}()
 	return jobWatcher, nil
 }
 
+// setterCancelled reports ctx's error on errCh unless another error was
+// reported first.
+func setterCancelled(ctx context.Context, errCh chan<- error) {
+	select {
+	case errCh <- ctx.Err():
+	default:
+	}
+}
+
+// close closes the stores the runner was created with.
+func (m *MaterializedChunkRunner) close() error {
+	var err error
+	if m.Offline != nil {
+		err = m.Offline.Close()
+	}
+	if m.Store != nil {
+		if closeErr := m.Store.Close(); err == nil {
+			err = closeErr
+		}
+	}
+	return err
+}
+
 func (m *MaterializedChunkRunner) SetIndex(index int) error {
 	m.ChunkIdx = index
 	return nil
@@ -279,6 +346,7 @@ type MaterializedChunkRunnerConfig struct {
 	IsUpdate       bool
 	Logger         *zap.SugaredLogger
 	SkipCache      bool
+	TTL            time.Duration
 }
 
 func (m *MaterializedChunkRunnerConfig) Serialize() (Config, error) {
@@ -359,10 +427,25 @@ func MaterializedChunkRunnerFactory(config Config) (types.Runner, error) {
 	if err != nil {
 		return nil, err
 	}
+	if runnerConfig.TTL > 0 {
+		if table, err = withTTL(table, runnerConfig.TTL); err != nil {
+			return nil, err
+		}
+	}
 	return &MaterializedChunkRunner{
 		Materialized: materialization,
 		Table:        table,
 		Store:        onlineStore,
+		Offline:      offlineStore,
 		ChunkIdx:     runnerConfig.ChunkIdx,
 	}, nil
 }
+
+// withTTL sets the TTL of an online table, failing if its store can't expire values.
+func withTTL(table provider.OnlineStoreTable, ttl time.Duration) (provider.OnlineStoreTable, error) {
+	expiring, ok := table.(provider.ExpiringOnlineTable)
+	if !ok {
+		return nil, fferr.NewInvalidArgumentErrorf("online table %T doesn't support a TTL", table)
+	}
+	return expiring.WithTTL(ttl)
+}
@@ -8,6 +8,7 @@
 package runner
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"reflect"
@@ -355,6 +356,28 @@ func TestErrorCoverage(t *testing.T) {
 
 }
 
+func TestChunkRunnerCancelled(t *testing.T) {
+	rows := CreateMockFeatureRows([]interface{}{1, 2})
+	table := &MockOnlineTable{DataTable: sync.Map{}}
+	job := &MaterializedChunkRunner{
+		Materialized: &rows,
+		Table:        table,
+		Store:        NewMockOnlineStore(),
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	watcher, err := job.RunContext(ctx)
+	if err != nil {
+		t.Fatalf("Failed to start job: %v", err)
+	}
+	if err := watcher.Wait(); !errors.Is(err, context.Canceled) {
+		t.Fatalf("Expected %v, got %v", context.Canceled, err)
+	}
+	if written := job.RowsWritten(); written != 0 {
+		t.Fatalf("Expected no rows to be written, got %d", written)
+	}
+}
+
 type ErrorChunkRunnerFactoryConfigs struct {
 	Name        string
 	ErrorConfig Config
@@ -392,7 +415,7 @@ func (store *BrokenNumChunksOfflineStore) GetPrimaryTable(id provider.ResourceID
 func (store *BrokenNumChunksOfflineStore) RegisterResourceFromSourceTable(id provider.ResourceID, schema provider.ResourceSchema, opts ...provider.ResourceOption) (provider.OfflineTable, error) {
 	return nil, nil
 }
-func (store *BrokenNumChunksOfflineStore) RegisterPrimaryFromSourceTable(id provider.ResourceID, tableLocation pl.Location) (provider.PrimaryTable, error) {
+func (store *BrokenNumChunksOfflineStore) RegisterPrimaryFromSourceTable(id provider.ResourceID, tableLocation pl.Location, opts ...provider.PrimaryOption) (provider.PrimaryTable, error) {
 	return nil, nil
 }
 
@@ -456,6 +479,14 @@ func (b BrokenNumChunksOfflineStore) GetTrainTestSplit(def provider.TrainTestSpl
 	return nil, nil, fmt.Errorf("not Implemented")
 }
 
+func (b BrokenNumChunksOfflineStore) GetTrainingSetSplit(id provider.ResourceID, split string) (provider.TrainingSetIterator, error) {
+	return nil, fmt.Errorf("not Implemented")
+}
+
+func (b BrokenNumChunksOfflineStore) ExportTrainingSet(id provider.ResourceID, location pl.Location, format fs.FileType) ([]fs.Filepath, error) {
+	return nil, fmt.Errorf("not Implemented")
+}
+
 func (b BrokenNumChunksOfflineStore) GetBatchFeatures(tables []provider.ResourceID) (provider.BatchFeatureIterator, error) {
 	return nil, nil
 }
@@ -468,6 +499,10 @@ func (b BrokenNumChunksOfflineStore) ResourceLocation(id provider.ResourceID, re
 	return nil, nil
 }
 
+func (b BrokenNumChunksOfflineStore) GetResourceStats(id provider.ResourceID, resource any) (provider.ResourceStats, error) {
+	return provider.ResourceStats{}, nil
+}
+
 type BrokenGetTableOnlineStore struct {
 	provider.BaseProvider
 }
@@ -716,7 +751,7 @@ func (m MockOfflineStore) GetPrimaryTable(id provider.ResourceID, source metadat
 func (m MockOfflineStore) RegisterResourceFromSourceTable(id provider.ResourceID, schema provider.ResourceSchema, opts ...provider.ResourceOption) (provider.OfflineTable, error) {
 	return nil, nil
 }
-func (m MockOfflineStore) RegisterPrimaryFromSourceTable(id provider.ResourceID, tableLocation pl.Location) (provider.PrimaryTable, error) {
+func (m MockOfflineStore) RegisterPrimaryFromSourceTable(id provider.ResourceID, tableLocation pl.Location, opts ...provider.PrimaryOption) (provider.PrimaryTable, error) {
 	return nil, nil
 }
 func (m MockOfflineStore) SupportsTransformationOption(opt provider.TransformationOptionType) (bool, error) {
@@ -750,6 +785,10 @@ func (m MockOfflineStore) ResourceLocation(id provider.ResourceID, resource any)
 	return nil, nil
 }
 
+func (m MockOfflineStore) GetResourceStats(id provider.ResourceID, resource any) (provider.ResourceStats, error) {
+	return provider.ResourceStats{}, nil
+}
+
 func (m MockOfflineStore) CreateTrainTestSplit(def provider.TrainTestSplitDef) (func() error, error) {
 	return nil, fmt.Errorf("not Implemented")
 }
@@ -759,6 +798,14 @@ func (m MockOfflineStore) GetTrainTestSplit(def provider.TrainTestSplitDef) (pro
 
 }
 
+func (m MockOfflineStore) GetTrainingSetSplit(id provider.ResourceID, split string) (provider.TrainingSetIterator, error) {
+	return nil, fmt.Errorf("not Implemented")
+}
+
+func (m MockOfflineStore) ExportTrainingSet(id provider.ResourceID, location pl.Location, format fs.FileType) ([]fs.Filepath, error) {
+	return nil, fmt.Errorf("not Implemented")
+}
+
 type MockOnlineStoreTable struct{}
 
 func NewMockOnlineStore() *MockOnlineStore {
@@ -0,0 +1,428 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+//
+// Copyright 2024 FeatureForm Inc.
+//
+
+package runner
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+	"go.uber.org/zap"
+
+	"github.com/featureform/fferr"
+	"github.com/featureform/logging"
+	"github.com/featureform/metadata"
+	"github.com/featureform/provider"
+	pc "github.com/featureform/provider/provider_config"
+	pt "github.com/featureform/provider/provider_type"
+	vt "github.com/featureform/provider/types"
+	"github.com/featureform/types"
+)
+
+const (
+	DefaultKafkaStreamBatchSize     = 500
+	DefaultKafkaStreamFlushInterval = time.Second
+)
+
+// KafkaStreamRunnerConfig configures a runner that streams a feature from a Kafka
+// topic into its online store.
+type KafkaStreamRunnerConfig struct {
+	KafkaConfig  pc.SerializedConfig
+	Topic        string
+	GroupID      string
+	OnlineType   pt.Type
+	OnlineConfig pc.SerializedConfig
+	ResourceID   provider.ResourceID
+	VType        vt.ValueTypeJSONWrapper
+	EntityColumn string
+	ValueColumn  string
+	// TSColumn is optional. Without it, values are timestamped with their message's time.
+	TSColumn      string
+	BatchSize     int
+	FlushInterval time.Duration
+}
+
+func (c *KafkaStreamRunnerConfig) Serialize() (Config, error) {
+	config, err := json.Marshal(c)
+	if err != nil {
+		return nil, fferr.NewInternalError(err)
+	}
+	return config, nil
+}
+
+func (c *KafkaStreamRunnerConfig) Deserialize(config Config) error {
+	if err := json.Unmarshal(config, c); err != nil {
+		return fferr.NewInternalError(err)
+	}
+	return nil
+}
+
+// KafkaStreamRunner consumes a topic and writes each message's value to the
+// online table of a feature, until it's stopped or fails.
+//
+// Delivery is at-least-once. A batch of messages is committed only after its
+// values, and its raw events if there's a raw events store, are written. If the
+// runner fails or is restarted before it commits a batch, the batch is consumed
+// again. Messages that can't be decoded or cast to the feature's type are logged
+// and skipped, so that one bad message doesn't stop the stream.
+type KafkaStreamRunner struct {
+	Reader        provider.KafkaReader
+	Decoder       provider.KafkaDecoder
+	Online        provider.OnlineStore
+	Table         provider.OnlineStoreTable
+	RawStore      provider.FileStore
+	RawPath       string
+	Topic         string
+	ID            provider.ResourceID
+	VType         vt.ValueType
+	EntityColumn  string
+	ValueColumn   string
+	TSColumn      string
+	BatchSize     int
+	FlushInterval time.Duration
+	Logger        *zap.SugaredLogger
+
+	mu     sync.Mutex
+	cancel context.CancelFunc
+	// written is the timestamp of the last value written for each entity to a
+	// table that can't store timestamps itself.
+	written map[string]time.Time
+}
+
+func (r *KafkaStreamRunner) Resource() metadata.ResourceID {
+	return metadata.ResourceID{
+		Name:    r.ID.Name,
+		Variant: r.ID.Variant,
+		Type:    metadata.FEATURE_VARIANT,
+	}
+}
+
+func (r *KafkaStreamRunner) IsUpdateJob() bool {
+	return false
+}
+
+// Run starts streaming. The returned watcher completes when the runner is
+// stopped or fails.
+func (r *KafkaStreamRunner) Run() (types.CompletionWatcher, error) {
+	ctx, cancel := context.WithCancel(context.Background())
+	r.mu.Lock()
+	r.cancel = cancel
+	r.mu.Unlock()
+	done := make(chan interface{})
+	jobWatcher := &SyncWatcher{
+		ResultSync:  &ResultSync{},
+		DoneChannel: done,
+	}
+	go func() {
+		defer cancel()
+		r.Logger.Infow("Starting Kafka stream", "topic", r.Topic, "name", r.ID.Name, "variant", r.ID.Variant)
+		err := r.stream(ctx)
+		if closeErr := r.Reader.Close(); closeErr != nil {
+			r.Logger.Warnw("Failed to close Kafka reader", "topic", r.Topic, "error", closeErr)
+		}
+		if r.Online != nil {
+			if closeErr := r.Online.Close(); closeErr != nil {
+				r.Logger.Warnw("Failed to close online store", "error", closeErr)
+			}
+		}
+		if r.RawStore != nil {
+			if closeErr := r.RawStore.Close(); closeErr != nil {
+				r.Logger.Warnw("Failed to close raw events store", "error", closeErr)
+			}
+		}
+		if err != nil {
+			r.Logger.Errorw("Kafka stream failed", "topic", r.Topic, "name", r.ID.Name, "variant", r.ID.Variant, "error", err)
+		} else {
+			r.Logger.Infow("Kafka stream stopped", "topic", r.Topic, "name", r.ID.Name, "variant", r.ID.Variant)
+		}
+		jobWatcher.EndWatch(err)
+	}()
+	return jobWatcher, nil
+}
+
+// Stop stops the runner after it writes and commits the batch it's consuming.
+func (r *KafkaStreamRunner) Stop() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.cancel != nil {
+		r.cancel()
+	}
+}
+
+func (r *KafkaStreamRunner) stream(ctx context.Context) error {
+	for {
+		msgs, fetchErr := r.fetchBatch(ctx)
+		// A batch that was fetched is written and committed even if the runner
+		// was stopped, so that it isn't consumed again.
+		if len(msgs) > 0 {
+			if err := r.writeBatch(msgs); err != nil {
+				return err
+			}
+		}
+		if ctx.Err() != nil {
+			return nil
+		}
+		if fetchErr != nil {
+			return fetchErr
+		}
+	}
+}
+
+// fetchBatch waits for a message, then fetches more until the batch is full or
+// the flush interval has passed.
+func (r *KafkaStreamRunner) fetchBatch(ctx context.Context) ([]kafka.Message, error) {
+	msg, err := r.Reader.FetchMessage(ctx)
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, nil
+		}
+		return nil, r.fetchError(err)
+	}
+	msgs := []kafka.Message{msg}
+	flushCtx, cancel := context.WithTimeout(ctx, r.flushInterval())
+	defer cancel()
+	for len(msgs) < r.batchSize() {
+		msg, err := r.Reader.FetchMessage(flushCtx)
+		if err != nil {
+			if flushCtx.Err() != nil {
+				return msgs, nil
+			}
+			return msgs, r.fetchError(err)
+		}
+		msgs = append(msgs, msg)
+	}
+	return msgs, nil
+}
+
+func (r *KafkaStreamRunner) fetchError(err error) error {
+	wrapped := fferr.NewExecutionError(pt.Kafka.String(), err)
+	wrapped.AddDetail("topic", r.Topic)
+	return wrapped
+}
+
+func (r *KafkaStreamRunner) writeBatch(msgs []kafka.Message) error {
+	items := make([]provider.SetItem, 0, len(msgs))
+	records := make([]kafkaRawEvent, 0, len(msgs))
+	for _, msg := range msgs {
+		record, err := r.Decoder.Decode(msg.Value)
+		if err != nil {
+			r.skip(msg, err)
+			continue
+		}
+		item, err := r.setItem(record, msg)
+		if err != nil {
+			r.skip(msg, err)
+			continue
+		}
+		items = append(items, item)
+		records = append(records, kafkaRawEvent{partition: msg.Partition, offset: msg.Offset, record: record})
+	}
+	if err := r.set(items); err != nil {
+		return err
+	}
+	if err := r.writeRawEvents(records); err != nil {
+		return err
+	}
+	// Commits aren't cancelled with the runner, so a written batch is always committed.
+	if err := r.Reader.CommitMessages(context.Background(), msgs...); err != nil {
+		wrapped := fferr.NewExecutionError(pt.Kafka.String(), err)
+		wrapped.AddDetail("topic", r.Topic)
+		return wrapped
+	}
+	r.Logger.Debugw("Committed Kafka batch", "topic", r.Topic, "messages", len(msgs), "written", len(items))
+	return nil
+}
+
+func (r *KafkaStreamRunner) skip(msg kafka.Message, err error) {
+	r.Logger.Warnw("Skipping Kafka message", "topic", r.Topic, "partition", msg.Partition, "offset", msg.Offset, "error", err)
+}
+
+func (r *KafkaStreamRunner) setItem(record map[string]interface{}, msg kafka.Message) (provider.SetItem, error) {
+	entity, has := record[r.EntityColumn]
+	if !has || entity == nil {
+		return provider.SetItem{}, fferr.NewInvalidArgumentErrorf("message has no entity column %q", r.EntityColumn)
+	}
+	rawValue, has := record[r.ValueColumn]
+	if !has {
+		return provider.SetItem{}, fferr.NewInvalidArgumentErrorf("message has no value column %q", r.ValueColumn)
+	}
+	value, err := provider.CastKafkaValue(rawValue, r.VType)
+	if err != nil {
+		return provider.SetItem{}, err
+	}
+	ts := msg.Time.UTC()
+	if r.TSColumn != "" {
+		if ts, err = provider.CastKafkaTimestamp(record[r.TSColumn]); err != nil {
+			return provider.SetItem{}, err
+		}
+	}
+	return provider.SetItem{Entity: fmt.Sprint(entity), Value: value, TS: ts}, nil
+}
+
+func (r *KafkaStreamRunner) set(items []provider.SetItem) error {
+	batchTable, isBatch := r.Table.(provider.BatchOnlineTable)
+	if !isBatch {
+		return r.setNewest(items)
+	}
+	maxBatch, err := batchTable.MaxBatchSize()
+	if err != nil {
+		return err
+	}
+	for start := 0; start < len(items); start += maxBatch {
+		end := start + maxBatch
+		if end > len(items) {
+			end = len(items)
+		}
+		if err := batchTable.BatchSet(items[start:end]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// setNewest writes items to a table that can't store their timestamps. Only the
+// newest value of each entity in the batch is written, and values older than the
+// last one the runner wrote for their entity are skipped, so that a message that
+// arrives late doesn't overwrite a newer value.
+func (r *KafkaStreamRunner) setNewest(items []provider.SetItem) error {
+	if r.written == nil {
+		r.written = map[string]time.Time{}
+	}
+	var entities []string
+	newest := make(map[string]int, len(items))
+	for i, item := range items {
+		prev, has := newest[item.Entity]
+		if !has {
+			entities = append(entities, item.Entity)
+		}
+		if !has || !item.TS.Before(items[prev].TS) {
+			newest[item.Entity] = i
+		}
+	}
+	for _, entity := range entities {
+		item := items[newest[entity]]
+		if last, has := r.written[entity]; has && item.TS.Before(last) {
+			continue
+		}
+		if err := r.Table.Set(item.Entity, item.Value); err != nil {
+			return err
+		}
+		r.written[entity] = item.TS
+	}
+	return nil
+}
+
+type kafkaRawEvent struct {
+	partition int
+	offset    int64
+	record    map[string]interface{}
+}
+
+// writeRawEvents writes the records of each partition in the batch to a file of
+// newline-delimited JSON named after the partition and the offsets of its records.
+func (r *KafkaStreamRunner) writeRawEvents(events []kafkaRawEvent) error {
+	if r.RawStore == nil || len(events) == 0 {
+		return nil
+	}
+	var partitions []int
+	byPartition := map[int][]kafkaRawEvent{}
+	for _, event := range events {
+		if _, has := byPartition[event.partition]; !has {
+			partitions = append(partitions, event.partition)
+		}
+		byPartition[event.partition] = append(byPartition[event.partition], event)
+	}
+	for _, partition := range partitions {
+		partitionEvents := byPartition[partition]
+		var data bytes.Buffer
+		encoder := json.NewEncoder(&data)
+		for _, event := range partitionEvents {
+			if err := encoder.Encode(event.record); err != nil {
+				return fferr.NewInternalError(err)
+			}
+		}
+		key := fmt.Sprintf(
+			"%s/%s/partition=%d/%020d-%020d.json",
+			r.RawPath, r.Topic, partition, partitionEvents[0].offset, partitionEvents[len(partitionEvents)-1].offset,
+		)
+		path, err := r.RawStore.CreateFilePath(key, false)
+		if err != nil {
+			return err
+		}
+		if err := r.RawStore.Write(path, data.Bytes()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *KafkaStreamRunner) batchSize() int {
+	if r.BatchSize <= 0 {
+		return DefaultKafkaStreamBatchSize
+	}
+	return r.BatchSize
+}
+
+func (r *KafkaStreamRunner) flushInterval() time.Duration {
+	if r.FlushInterval <= 0 {
+		return DefaultKafkaStreamFlushInterval
+	}
+	return r.FlushInterval
+}
+
+func KafkaStreamRunnerFactory(config Config) (types.Runner, error) {
+	runnerConfig := &KafkaStreamRunnerConfig{}
+	if err := runnerConfig.Deserialize(config); err != nil {
+		return nil, err
+	}
+	kafkaProvider, err := provider.Get(pt.Kafka, runnerConfig.KafkaConfig)
+	if err != nil {
+		return nil, err
+	}
+	stream, ok := kafkaProvider.(*provider.KafkaStream)
+	if !ok {
+		return nil, fferr.NewInternalErrorf("expected a Kafka provider, got %T", kafkaProvider)
+	}
+	onlineProvider, err := provider.Get(runnerConfig.OnlineType, runnerConfig.OnlineConfig)
+	if err != nil {
+		return nil, err
+	}
+	onlineStore, err := onlineProvider.AsOnlineStore()
+	if err != nil {
+		return nil, err
+	}
+	table, err := onlineStore.GetTable(runnerConfig.ResourceID.Name, runnerConfig.ResourceID.Variant)
+	if err != nil {
+		return nil, err
+	}
+	rawStore, err := stream.RawEventsStore()
+	if err != nil {
+		return nil, err
+	}
+	return &KafkaStreamRunner{
+		Reader:        stream.NewReader(runnerConfig.Topic, runnerConfig.GroupID),
+		Decoder:       stream.Decoder(),
+		Online:        onlineStore,
+		Table:         table,
+		RawStore:      rawStore,
+		RawPath:       stream.RawEventsPath(),
+		Topic:         runnerConfig.Topic,
+		ID:            runnerConfig.ResourceID,
+		VType:         runnerConfig.VType.ValueType,
+		EntityColumn:  runnerConfig.EntityColumn,
+		ValueColumn:   runnerConfig.ValueColumn,
+		TSColumn:      runnerConfig.TSColumn,
+		BatchSize:     runnerConfig.BatchSize,
+		FlushInterval: runnerConfig.FlushInterval,
+		Logger:        logging.NewLogger("kafka-stream").SugaredLogger,
+	}, nil
+}
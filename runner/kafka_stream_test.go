@@ -0,0 +1,255 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+//
+// Copyright 2024 FeatureForm Inc.
+//
+
+package runner
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+
+	"github.com/featureform/logging"
+	"github.com/featureform/provider"
+	pc "github.com/featureform/provider/provider_config"
+	vt "github.com/featureform/provider/types"
+)
+
+type mockKafkaReader struct {
+	msgs      chan kafka.Message
+	mu        sync.Mutex
+	committed []kafka.Message
+}
+
+func newMockKafkaReader(values ...string) *mockKafkaReader {
+	reader := &mockKafkaReader{msgs: make(chan kafka.Message, len(values))}
+	for i, value := range values {
+		reader.msgs <- kafka.Message{Topic: "transactions", Offset: int64(i), Value: []byte(value), Time: time.UnixMilli(int64(i))}
+	}
+	return reader
+}
+
+func (r *mockKafkaReader) FetchMessage(ctx context.Context) (kafka.Message, error) {
+	select {
+	case msg := <-r.msgs:
+		return msg, nil
+	case <-ctx.Done():
+		return kafka.Message{}, ctx.Err()
+	}
+}
+
+func (r *mockKafkaReader) CommitMessages(ctx context.Context, msgs ...kafka.Message) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.committed = append(r.committed, msgs...)
+	return nil
+}
+
+func (r *mockKafkaReader) Close() error {
+	return nil
+}
+
+func (r *mockKafkaReader) numCommitted() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.committed)
+}
+
+type failingKafkaTable struct{}
+
+func (failingKafkaTable) Set(entity string, value interface{}) error {
+	return fmt.Errorf("online store is down")
+}
+
+func (failingKafkaTable) Get(entity string) (interface{}, error) {
+	return nil, fmt.Errorf("online store is down")
+}
+
+func newKafkaStreamTestRunner(t *testing.T, reader provider.KafkaReader, table provider.OnlineStoreTable) *KafkaStreamRunner {
+	kafkaProvider, err := provider.NewKafkaStream(&pc.KafkaConfig{Brokers: []string{"localhost:9092"}, Format: pc.KafkaJSON})
+	if err != nil {
+		t.Fatalf("Failed to create Kafka provider: %v", err)
+	}
+	return &KafkaStreamRunner{
+		Reader:        reader,
+		Decoder:       kafkaProvider.Decoder(),
+		Table:         table,
+		Topic:         "transactions",
+		ID:            provider.ResourceID{Name: "amount", Variant: "default", Type: provider.Feature},
+		VType:         vt.Int,
+		EntityColumn:  "user",
+		ValueColumn:   "amount",
+		BatchSize:     2,
+		FlushInterval: 10 * time.Millisecond,
+		Logger:        logging.NewTestLogger(t).SugaredLogger,
+	}
+}
+
+func TestKafkaStreamRunner(t *testing.T) {
+	store := provider.NewLocalOnlineStore()
+	table, err := store.CreateTable("amount", "default", vt.Int)
+	if err != nil {
+		t.Fatalf("Failed to create table: %v", err)
+	}
+	dir := t.TempDir()
+	rawStore, err := provider.CreateFileStore("LOCAL_FILESYSTEM", []byte(fmt.Sprintf(`{"DirPath": "file://%s"}`, dir)))
+	if err != nil {
+		t.Fatalf("Failed to create raw events store: %v", err)
+	}
+	reader := newMockKafkaReader(
+		`{"user": "a", "amount": 1}`,
+		`{"user": "b", "amount": 2}`,
+		`not json`,
+		`{"user": "c", "amount": "three"}`,
+		`{"amount": 4}`,
+		`{"user": "a", "amount": 5}`,
+	)
+	runner := newKafkaStreamTestRunner(t, reader, table)
+	runner.RawStore = rawStore
+	runner.RawPath = "events"
+
+	watcher, err := runner.Run()
+	if err != nil {
+		t.Fatalf("Failed to run: %v", err)
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for reader.numCommitted() < 6 {
+		if time.Now().After(deadline) {
+			t.Fatalf("Timed out waiting for messages to be committed, got %d", reader.numCommitted())
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	runner.Stop()
+	if err := watcher.Wait(); err != nil {
+		t.Fatalf("Expected stopped runner to succeed: %v", err)
+	}
+
+	// Messages that can't be written are skipped, but still committed.
+	expected := map[string]interface{}{"a": 5, "b": 2}
+	for entity, value := range expected {
+		actual, err := table.Get(entity)
+		if err != nil {
+			t.Fatalf("Failed to get %s: %v", entity, err)
+		}
+		if !reflect.DeepEqual(actual, value) {
+			t.Fatalf("Expected %s to be %v, got %v", entity, value, actual)
+		}
+	}
+	if _, err := table.Get("c"); err == nil {
+		t.Fatalf("Expected message that can't be cast to be skipped")
+	}
+
+	files, err := filepath.Glob(filepath.Join(dir, "events", "transactions", "partition=0", "*.json"))
+	if err != nil {
+		t.Fatalf("Failed to list raw events: %v", err)
+	}
+	var lines []string
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			t.Fatalf("Failed to read raw events: %v", err)
+		}
+		lines = append(lines, strings.Split(strings.TrimSpace(string(data)), "\n")...)
+	}
+	if len(lines) != 3 {
+		t.Fatalf("Expected 3 raw events in %v, got %v", files, lines)
+	}
+	record := map[string]interface{}{}
+	if err := json.Unmarshal([]byte(lines[0]), &record); err != nil || record["user"] != "a" {
+		t.Fatalf("Expected first raw event to be user a, got %s: %v", lines[0], err)
+	}
+}
+
+func TestKafkaStreamRunnerDoesNotCommitFailedWrites(t *testing.T) {
+	reader := newMockKafkaReader(`{"user": "a", "amount": 1}`)
+	runner := newKafkaStreamTestRunner(t, reader, failingKafkaTable{})
+	watcher, err := runner.Run()
+	if err != nil {
+		t.Fatalf("Failed to run: %v", err)
+	}
+	if err := watcher.Wait(); err == nil {
+		t.Fatalf("Expected runner to fail when the online store does")
+	}
+	if n := reader.numCommitted(); n != 0 {
+		t.Fatalf("Expected no messages to be committed, got %d", n)
+	}
+}
+
+func TestKafkaStreamRunnerKeepsNewestValue(t *testing.T) {
+	table, err := provider.NewLocalOnlineStore().CreateTable("amount", "default", vt.Int)
+	if err != nil {
+		t.Fatalf("Failed to create table: %v", err)
+	}
+	runner := newKafkaStreamTestRunner(t, newMockKafkaReader(), table)
+	runner.TSColumn = "ts"
+	msgs := func(values ...string) []kafka.Message {
+		var msgs []kafka.Message
+		for _, value := range values {
+			msgs = append(msgs, kafka.Message{Topic: "transactions", Value: []byte(value)})
+		}
+		return msgs
+	}
+	if err := runner.writeBatch(msgs(
+		`{"user": "a", "amount": 2, "ts": 2000}`,
+		`{"user": "a", "amount": 1, "ts": 1000}`,
+		`{"user": "b", "amount": 3, "ts": 1000}`,
+	)); err != nil {
+		t.Fatalf("Failed to write batch: %v", err)
+	}
+	// A late message in a later batch doesn't overwrite a newer value.
+	if err := runner.writeBatch(msgs(
+		`{"user": "a", "amount": 0, "ts": 500}`,
+		`{"user": "b", "amount": 4, "ts": 3000}`,
+	)); err != nil {
+		t.Fatalf("Failed to write batch: %v", err)
+	}
+	expected := map[string]interface{}{"a": 2, "b": 4}
+	for entity, value := range expected {
+		actual, err := table.Get(entity)
+		if err != nil {
+			t.Fatalf("Failed to get %s: %v", entity, err)
+		}
+		if !reflect.DeepEqual(actual, value) {
+			t.Fatalf("Expected %s to be %v, got %v", entity, value, actual)
+		}
+	}
+}
+
+func TestKafkaStreamRunnerConfig(t *testing.T) {
+	config := KafkaStreamRunnerConfig{
+		KafkaConfig:   pc.KafkaConfig{Brokers: []string{"localhost:9092"}, Format: pc.KafkaJSON}.Serialize(),
+		Topic:         "transactions",
+		GroupID:       "featureform-amount-default",
+		OnlineType:    "LOCAL_ONLINE",
+		ResourceID:    provider.ResourceID{Name: "amount", Variant: "default", Type: provider.Feature},
+		VType:         vt.ValueTypeJSONWrapper{ValueType: vt.VectorType{ScalarType: vt.Float32, Dimension: 3}},
+		EntityColumn:  "user",
+		ValueColumn:   "embedding",
+		TSColumn:      "ts",
+		BatchSize:     100,
+		FlushInterval: time.Second,
+	}
+	serialized, err := config.Serialize()
+	if err != nil {
+		t.Fatalf("Failed to serialize: %v", err)
+	}
+	deserialized := KafkaStreamRunnerConfig{}
+	if err := deserialized.Deserialize(serialized); err != nil {
+		t.Fatalf("Failed to deserialize: %v", err)
+	}
+	if !reflect.DeepEqual(config, deserialized) {
+		t.Fatalf("Expected %#v, got %#v", config, deserialized)
+	}
+}
@@ -25,6 +25,9 @@ func registerFactories() {
 	if err := RegisterFactory(MATERIALIZE, MaterializeRunnerFactory); err != nil {
 		panic(fmt.Errorf("failed to register 'Materialize' factory: %w", err))
 	}
+	if err := RegisterFactory(STREAM_TO_ONLINE, KafkaStreamRunnerFactory); err != nil {
+		panic(fmt.Errorf("failed to register 'Stream to online' factory: %w", err))
+	}
 }
 
 type RunnerName string
@@ -37,6 +40,9 @@ const (
 	COPY_TO_ONLINE  RunnerName = "Copy to online"
 	REGISTER_SOURCE RunnerName = "Register source"
 	MATERIALIZE     RunnerName = "Materialize"
+	// STREAM_TO_ONLINE runs until it's stopped, so its watcher only completes
+	// if the stream fails or is stopped.
+	STREAM_TO_ONLINE RunnerName = "Stream to online"
 )
 
 type Config []byte
@@ -8,11 +8,13 @@
 package runner
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"time"
 
 	"go.uber.org/zap"
+	"golang.org/x/sync/errgroup"
 
 	cfg "github.com/featureform/config"
 	"github.com/featureform/fferr"
@@ -21,15 +23,21 @@ import (
 	"github.com/featureform/kubernetes"
 	"github.com/featureform/logging"
 	"github.com/featureform/metadata"
+	"github.com/featureform/metrics"
 	"github.com/featureform/provider"
 	pc "github.com/featureform/provider/provider_config"
 	pt "github.com/featureform/provider/provider_type"
 	vt "github.com/featureform/provider/types"
+	"github.com/featureform/scheduling"
 	"github.com/featureform/types"
 )
 
 var WORKER_IMAGE string = helpers.GetEnv("WORKER_IMAGE", "featureformenterprise/worker:latest")
 
+// DefaultMaxConcurrency is the number of chunks the local runner will copy to the
+// online store at once when MaxConcurrency isn't set on the runner config.
+const DefaultMaxConcurrency = 10
+
 type JobCloud string
 
 const (
@@ -46,6 +54,24 @@ type MaterializeRunner struct {
 	Cloud    JobCloud
 	Logger   *zap.SugaredLogger
 	Options  provider.MaterializationOptions
+	// MaxConcurrency caps the number of chunks copied to the online store at once
+	// by the local runner. Values less than 1 fall back to DefaultMaxConcurrency.
+	MaxConcurrency int
+	// Observer is optionally given the number of materialized rows. It's only set
+	// when the runner is in the same process as the coordinator.
+	Observer metrics.JobObserver
+	// Progress is optionally given the materialization's progress. Like Observer,
+	// it's only set when the runner is in the same process as the coordinator.
+	// Local chunks report the rows they've copied; Kubernetes chunks report an
+	// estimate from the number of finished jobs. Direct copies only report phases.
+	Progress ProgressReporter
+	// TTL expires values in the online store after they're copied. Zero means
+	// they never expire.
+	TTL time.Duration
+	// VerifySampleSize is the number of entities compared with the online store
+	// once the copy is done, failing the materialization on a mismatch. Zero
+	// skips the check.
+	VerifySampleSize int
 }
 
 func (m MaterializeRunner) Resource() metadata.ResourceID {
@@ -101,6 +127,7 @@ func (m MaterializeRunner) Run() (types.CompletionWatcher, error) {
 	m.Logger.Infow("Starting Materialization Runner", "name", m.ID.Name, "variant", m.ID.Variant)
 	var materialization provider.Materialization
 	var err error
+	m.reportProgress(scheduling.ReadingPhase, 0, 0)
 	// offline
 	if m.IsUpdate {
 		m.Logger.Infow("Updating Materialization", "name", m.ID.Name, "variant", m.ID.Variant)
@@ -112,15 +139,38 @@ func (m MaterializeRunner) Run() (types.CompletionWatcher, error) {
 	if err != nil {
 		return nil, err
 	}
+	totalRows := m.observeRows(materialization)
 
 	// online
 	if m.Online == nil {
+		m.reportProgress(scheduling.DonePhase, totalRows, totalRows)
 		return m.handleNoOnlineStore()
 	}
-	return m.MaterializeToOnline(materialization)
+	return m.materializeToOnline(materialization, totalRows)
+}
+
+// Close closes the runner's stores. It should be called once the watcher
+// returned by Run is done.
+func (m MaterializeRunner) Close() error {
+	var err error
+	if m.Offline != nil {
+		err = m.Offline.Close()
+	}
+	if m.Online != nil {
+		if closeErr := m.Online.Close(); err == nil {
+			err = closeErr
+		}
+	}
+	return err
 }
 
 func (m MaterializeRunner) MaterializeToOnline(materialization provider.Materialization) (types.CompletionWatcher, error) {
+	return m.materializeToOnline(materialization, 0)
+}
+
+// materializeToOnline copies the materialization to the online store. totalRows
+// is only used to report progress and is zero if it isn't known.
+func (m MaterializeRunner) materializeToOnline(materialization provider.Materialization, totalRows int64) (types.CompletionWatcher, error) {
 	// Create the vector similarity index prior to writing any values to the
 	// inference store. This is currently only required for RediSearch, but other
 	// vector databases allow for manual index configuration even if they support
@@ -138,7 +188,7 @@ func (m MaterializeRunner) MaterializeToOnline(materialization provider.Material
 		}
 	}
 	m.Logger.Infow("Creating Table", "name", m.ID.Name, "variant", m.ID.Variant)
-	_, err := m.Online.CreateTable(m.ID.Name, m.ID.Variant, m.VType)
+	table, err := m.Online.CreateTable(m.ID.Name, m.ID.Variant, m.VType)
 	if err != nil {
 		_, isExistsErr := err.(*fferr.DatasetAlreadyExistsError)
 		if !isExistsErr {
@@ -149,6 +199,16 @@ func (m MaterializeRunner) MaterializeToOnline(materialization provider.Material
 			return nil, fferr.NewDatasetAlreadyExistsError(m.ID.Name, m.ID.Variant, fmt.Errorf("table already exists"))
 		}
 		// Otherwise it was an exists error, but was an update, so should be ignored.
+		if table, err = m.Online.GetTable(m.ID.Name, m.ID.Variant); err != nil {
+			return nil, err
+		}
+	}
+	if m.TTL > 0 {
+		// Enable expiry up front so an unsupported store fails before any chunks run.
+		m.Logger.Infow("Setting TTL", "name", m.ID.Name, "variant", m.ID.Variant, "ttl", m.TTL)
+		if _, err := withTTL(table, m.TTL); err != nil {
+			return nil, err
+		}
 	}
 
 	m.Logger.Infow("Getting number of chunks", "name", m.ID.Name, "variant", m.ID.Variant)
@@ -165,8 +225,13 @@ func (m MaterializeRunner) MaterializeToOnline(materialization provider.Material
 		MaterializedID: materialization.ID(),
 		ResourceID:     m.ID,
 		Logger:         m.Logger,
+		TTL:            m.TTL,
 	}
+	m.reportProgress(scheduling.WritingPhase, 0, totalRows)
+	progress := newCopyProgress()
 	var cloudWatcher types.CompletionWatcher
+	// Local chunks report their own progress, and stop before they finish.
+	stopReporting := func() {}
 	switch m.Cloud {
 	case KubernetesMaterializeRunner:
 		serializedConfig, err := config.Serialize()
@@ -190,27 +255,21 @@ func (m MaterializeRunner) MaterializeToOnline(materialization provider.Material
 		if err != nil {
 			return nil, err
 		}
-	case LocalMaterializeRunner:
-		m.Logger.Infow("Making Local Runner", "name", m.ID.Name, "variant", m.ID.Variant)
-		completionList := make([]types.CompletionWatcher, int(numChunks))
-		for i := 0; i < int(numChunks); i++ {
-			m.Logger.Infow("Creating materialization chunk", "name", m.ID.Name, "variant", m.ID.Variant, "chunkIndex", i)
-			config.ChunkIdx = i
-			serializedChunkConfig, err := config.Serialize()
-			if err != nil {
-				return nil, err
-			}
-			localRunner, err := Create(COPY_TO_ONLINE, serializedChunkConfig)
-			if err != nil {
-				return nil, err
-			}
-			watcher, err := localRunner.Run()
-			if err != nil {
-				return nil, err
-			}
-			completionList[i] = watcher
+		// The rows copied by each job aren't known here, so they're estimated
+		// from the number of chunks that have been copied.
+		if counter, ok := cloudWatcher.(chunkCounter); ok {
+			stopReporting = m.reportWritingEvery(progressInterval, totalRows, func() (int64, bool) {
+				succeeded, err := counter.Succeeded()
+				if err != nil {
+					m.Logger.Debugw("Failed to get the number of copied chunks", "name", m.ID.Name, "variant", m.ID.Variant, "error", err)
+					return 0, false
+				}
+				return chunkRows(succeeded, int64(numChunks), totalRows), true
+			})
 		}
-		cloudWatcher = WatcherMultiplex{completionList}
+	case LocalMaterializeRunner:
+		m.Logger.Infow("Making Local Runner", "name", m.ID.Name, "variant", m.ID.Variant, "max_concurrency", m.maxConcurrency())
+		cloudWatcher = m.runLocalChunks(*config, int(numChunks), progress, totalRows)
 	default:
 		return nil, fferr.NewInternalError(fmt.Errorf("no valid job cloud set"))
 	}
@@ -220,15 +279,174 @@ func (m MaterializeRunner) MaterializeToOnline(materialization provider.Material
 		DoneChannel: done,
 	}
 	go func() {
-		if err := cloudWatcher.Wait(); err != nil {
+		err := cloudWatcher.Wait()
+		stopReporting()
+		if err != nil {
 			materializeWatcher.EndWatch(err)
 			return
 		}
-		materializeWatcher.EndWatch(nil)
+		// Chunks copied by Kubernetes jobs are estimated, not counted.
+		if written := progress.rows(); written > 0 {
+			m.reportProgress(scheduling.DonePhase, written, totalRows)
+		} else {
+			m.reportProgress(scheduling.DonePhase, totalRows, totalRows)
+		}
+		materializeWatcher.EndWatch(m.verifyCopy(materialization.ID()))
 	}()
 	return materializeWatcher, nil
 }
 
+// observeRows returns the number of materialized rows, or zero if they aren't
+// needed or can't be counted.
+func (m MaterializeRunner) observeRows(materialization provider.Materialization) int64 {
+	if m.Observer == nil && m.Progress == nil {
+		return 0
+	}
+	rows, err := materialization.NumRows()
+	if err != nil {
+		m.Logger.Warnw("Failed to count materialized rows", "name", m.ID.Name, "variant", m.ID.Variant, "error", err)
+		return 0
+	}
+	if m.Observer != nil {
+		m.Observer.AddRows(rows)
+	}
+	return rows
+}
+
+func (m MaterializeRunner) reportProgress(phase scheduling.ProgressPhase, rowsProcessed, totalRows int64) {
+	if m.Progress == nil {
+		return
+	}
+	m.Progress.ReportProgress(scheduling.RunProgress{Phase: phase, RowsProcessed: rowsProcessed, TotalRows: totalRows})
+}
+
+// reportWritingEvery reports the rows returned by rows every interval, skipping
+// the reports where it returns false, until the returned function is called.
+// That function waits for a report in progress to finish, so none can follow
+// the ones made after it.
+func (m MaterializeRunner) reportWritingEvery(interval time.Duration, totalRows int64, rows func() (int64, bool)) func() {
+	if m.Progress == nil {
+		return func() {}
+	}
+	stop := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				if written, ok := rows(); ok {
+					m.reportProgress(scheduling.WritingPhase, written, totalRows)
+				}
+			case <-stop:
+				return
+			}
+		}
+	}()
+	return func() {
+		close(stop)
+		<-done
+	}
+}
+
+func (m MaterializeRunner) maxConcurrency() int {
+	if m.MaxConcurrency < 1 {
+		return DefaultMaxConcurrency
+	}
+	return m.MaxConcurrency
+}
+
+// contextRunner is implemented by runners that can be cancelled while running.
+type contextRunner interface {
+	RunContext(ctx context.Context) (types.CompletionWatcher, error)
+}
+
+// runLocalChunks copies every chunk to the online store using a pool of at most
+// maxConcurrency workers. The first chunk to fail cancels the pool's context so that
+// no further chunks are started and the running ones stop, and its error is returned
+// by the watcher. The rows copied so far are reported every progressInterval.
+func (m MaterializeRunner) runLocalChunks(config MaterializedChunkRunnerConfig, numChunks int, progress *copyProgress, totalRows int64) types.CompletionWatcher {
+	watcher := &SyncWatcher{
+		ResultSync:  &ResultSync{},
+		DoneChannel: make(chan interface{}),
+	}
+	stopReporting := m.reportWritingEvery(progressInterval, totalRows, func() (int64, bool) {
+		return progress.rows(), true
+	})
+	go func() {
+		group, ctx := errgroup.WithContext(context.Background())
+		group.SetLimit(m.maxConcurrency())
+		for i := 0; i < numChunks; i++ {
+			if ctx.Err() != nil {
+				break
+			}
+			chunkConfig := config
+			chunkConfig.ChunkIdx = i
+			group.Go(func() error {
+				return m.runLocalChunk(ctx, chunkConfig, progress)
+			})
+		}
+		err := group.Wait()
+		// Stop reporting before finishing so a late report can't follow the final one.
+		stopReporting()
+		watcher.EndWatch(err)
+	}()
+	return watcher
+}
+
+func (m MaterializeRunner) runLocalChunk(ctx context.Context, config MaterializedChunkRunnerConfig, progress *copyProgress) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	m.Logger.Infow("Creating materialization chunk", "name", m.ID.Name, "variant", m.ID.Variant, "chunkIndex", config.ChunkIdx)
+	serializedChunkConfig, err := config.Serialize()
+	if err != nil {
+		return err
+	}
+	localRunner, err := Create(COPY_TO_ONLINE, serializedChunkConfig)
+	if err != nil {
+		return err
+	}
+	if counter, ok := localRunner.(rowCounter); ok {
+		progress.start(config.ChunkIdx, counter)
+		defer progress.finish(config.ChunkIdx)
+	}
+	var watcher types.CompletionWatcher
+	if ctxRunner, ok := localRunner.(contextRunner); ok {
+		watcher, err = ctxRunner.RunContext(ctx)
+	} else {
+		watcher, err = localRunner.Run()
+	}
+	if err != nil {
+		return err
+	}
+	if err := watcher.Wait(); err != nil {
+		m.Logger.Errorw("Materialization chunk failed", "name", m.ID.Name, "variant", m.ID.Variant, "chunkIndex", config.ChunkIdx, "error", err)
+		return err
+	}
+	return nil
+}
+
+// verifyCopy returns an error if a sample of the materialization doesn't match
+// the online store. It's a no-op unless VerifySampleSize is set.
+func (m MaterializeRunner) verifyCopy(id provider.MaterializationID) error {
+	if m.VerifySampleSize < 1 {
+		return nil
+	}
+	m.Logger.Infow("Verifying materialization", "name", m.ID.Name, "variant", m.ID.Variant, "sample_size", m.VerifySampleSize)
+	report, err := m.VerifyMaterialization(id)
+	if err != nil {
+		return err
+	}
+	if !report.Consistent() {
+		m.Logger.Errorw("Online store doesn't match materialization", "name", m.ID.Name, "variant", m.ID.Variant, "sampled", report.Sampled, "mismatches", report.Mismatches)
+		return fferr.NewResourceFailedErrorf(m.ID.Name, m.ID.Variant, fferr.FEATURE_VARIANT, "%d of %d sampled entities don't match the online store, first: %s", len(report.Mismatches), report.Sampled, report.Mismatches[0])
+	}
+	return nil
+}
+
 func (m MaterializeRunner) handleNoOnlineStore() (types.CompletionWatcher, error) {
 	m.Logger.Infow("No Online Store, skipping materialization", "name", m.ID.Name, "variant", m.ID.Variant)
 	done := make(chan interface{})
@@ -252,18 +470,27 @@ type MaterializedRunnerConfig struct {
 	Cloud         JobCloud
 	IsUpdate      bool
 	Options       provider.MaterializationOptions
+	// MaxConcurrency is the number of chunks the local runner copies at once.
+	MaxConcurrency int
+	TTL            time.Duration
+	// VerifySampleSize is the number of entities checked against the online
+	// store after the copy. Zero skips the check.
+	VerifySampleSize int
 }
 
 type MaterializedRunnerConfigJSON struct {
-	OnlineType    pt.Type                    `json:"OnlineType"`
-	OfflineType   pt.Type                    `json:"OfflineType"`
-	OnlineConfig  pc.SerializedConfig        `json:"OnlineConfig"`
-	OfflineConfig pc.SerializedConfig        `json:"OfflineConfig"`
-	ResourceID    provider.ResourceID        `json:"ResourceID"`
-	VType         vt.ValueTypeJSONWrapper    `json:"VType"`
-	Cloud         JobCloud                   `json:"Cloud"`
-	IsUpdate      bool                       `json:"IsUpdate"`
-	Options       MaterializationOptionsJSON `json:"Options"`
+	OnlineType       pt.Type                    `json:"OnlineType"`
+	OfflineType      pt.Type                    `json:"OfflineType"`
+	OnlineConfig     pc.SerializedConfig        `json:"OnlineConfig"`
+	OfflineConfig    pc.SerializedConfig        `json:"OfflineConfig"`
+	ResourceID       provider.ResourceID        `json:"ResourceID"`
+	VType            vt.ValueTypeJSONWrapper    `json:"VType"`
+	Cloud            JobCloud                   `json:"Cloud"`
+	IsUpdate         bool                       `json:"IsUpdate"`
+	Options          MaterializationOptionsJSON `json:"Options"`
+	MaxConcurrency   int                        `json:"MaxConcurrency,omitempty"`
+	TTL              time.Duration              `json:"TTL,omitempty"`
+	VerifySampleSize int                        `json:"VerifySampleSize,omitempty"`
 }
 
 type MaterializationOptionsJSON struct {
@@ -273,6 +500,9 @@ type MaterializationOptionsJSON struct {
 	JobName                 string                            `json:"JobName"`
 	ResourceSnowflakeConfig *metadata.ResourceSnowflakeConfig `json:"ResourceSnowflakeConfig,omitempty"`
 	Schema                  json.RawMessage                   `json:"Schema"`
+	Incremental             bool                              `json:"Incremental,omitempty"`
+	Filter                  string                            `json:"Filter,omitempty"`
+	PartitionBy             provider.MaterializationPartition `json:"PartitionBy,omitempty"`
 }
 
 func (m *MaterializedRunnerConfig) Serialize() (Config, error) {
@@ -282,14 +512,17 @@ func (m *MaterializedRunnerConfig) Serialize() (Config, error) {
 	}
 
 	data := MaterializedRunnerConfigJSON{
-		OnlineType:    m.OnlineType,
-		OfflineType:   m.OfflineType,
-		OnlineConfig:  m.OnlineConfig,
-		OfflineConfig: m.OfflineConfig,
-		ResourceID:    m.ResourceID,
-		VType:         m.VType,
-		Cloud:         m.Cloud,
-		IsUpdate:      m.IsUpdate,
+		OnlineType:       m.OnlineType,
+		OfflineType:      m.OfflineType,
+		OnlineConfig:     m.OnlineConfig,
+		OfflineConfig:    m.OfflineConfig,
+		ResourceID:       m.ResourceID,
+		VType:            m.VType,
+		Cloud:            m.Cloud,
+		IsUpdate:         m.IsUpdate,
+		MaxConcurrency:   m.MaxConcurrency,
+		TTL:              m.TTL,
+		VerifySampleSize: m.VerifySampleSize,
 		Options: MaterializationOptionsJSON{
 			Output:                  m.Options.Output,
 			ShouldIncludeHeaders:    m.Options.ShouldIncludeHeaders,
@@ -297,6 +530,9 @@ func (m *MaterializedRunnerConfig) Serialize() (Config, error) {
 			JobName:                 m.Options.JobName,
 			ResourceSnowflakeConfig: m.Options.ResourceSnowflakeConfig,
 			Schema:                  json.RawMessage(schemaBytes),
+			Incremental:             m.Options.Incremental,
+			Filter:                  m.Options.Filter,
+			PartitionBy:             m.Options.PartitionBy,
 		},
 	}
 
@@ -323,6 +559,9 @@ func (config *MaterializedRunnerConfig) Deserialize(data []byte) error {
 	config.VType = intermediate.VType
 	config.Cloud = intermediate.Cloud
 	config.IsUpdate = intermediate.IsUpdate
+	config.MaxConcurrency = intermediate.MaxConcurrency
+	config.TTL = intermediate.TTL
+	config.VerifySampleSize = intermediate.VerifySampleSize
 
 	options := provider.MaterializationOptions{}
 	options.Output = intermediate.Options.Output
@@ -330,6 +569,9 @@ func (config *MaterializedRunnerConfig) Deserialize(data []byte) error {
 	options.MaxJobDuration = intermediate.Options.MaxJobDuration
 	options.JobName = intermediate.Options.JobName
 	options.ResourceSnowflakeConfig = intermediate.Options.ResourceSnowflakeConfig
+	options.Incremental = intermediate.Options.Incremental
+	options.Filter = intermediate.Options.Filter
+	options.PartitionBy = intermediate.Options.PartitionBy
 
 	var schema provider.ResourceSchema
 	err = schema.Deserialize(intermediate.Options.Schema)
@@ -368,13 +610,16 @@ func MaterializeRunnerFactory(config Config) (types.Runner, error) {
 		return nil, err
 	}
 	return &MaterializeRunner{
-		Online:   onlineStore, // This can be nil if onlineProvider is nil
-		Offline:  offlineStore,
-		ID:       runnerConfig.ResourceID,
-		VType:    runnerConfig.VType.ValueType,
-		IsUpdate: runnerConfig.IsUpdate,
-		Cloud:    runnerConfig.Cloud,
-		Logger:   logging.NewLogger("materializer").SugaredLogger,
-		Options:  runnerConfig.Options,
+		Online:           onlineStore, // This can be nil if onlineProvider is nil
+		Offline:          offlineStore,
+		ID:               runnerConfig.ResourceID,
+		VType:            runnerConfig.VType.ValueType,
+		IsUpdate:         runnerConfig.IsUpdate,
+		Cloud:            runnerConfig.Cloud,
+		Logger:           logging.NewLogger("materializer").SugaredLogger,
+		Options:          runnerConfig.Options,
+		MaxConcurrency:   runnerConfig.MaxConcurrency,
+		TTL:              runnerConfig.TTL,
+		VerifySampleSize: runnerConfig.VerifySampleSize,
 	}, nil
 }
@@ -8,7 +8,12 @@
 package runner
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"strconv"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -18,6 +23,7 @@ import (
 	pl "github.com/featureform/provider/location"
 	pt "github.com/featureform/provider/provider_type"
 	vt "github.com/featureform/provider/types"
+	"github.com/featureform/scheduling"
 	"github.com/featureform/types"
 	"github.com/google/uuid"
 	"go.uber.org/zap/zaptest"
@@ -220,13 +226,15 @@ func TestMaterializedRunnerConfigSerde(t *testing.T) {
 		{
 			name: "Valid config",
 			config: MaterializedRunnerConfig{
-				OnlineType:    pt.RedisOnline,
-				OfflineType:   pt.SnowflakeOffline,
-				OnlineConfig:  []byte("{}"),
-				OfflineConfig: []byte("{}"),
-				ResourceID:    provider.ResourceID{Name: "name", Variant: "variant", Type: provider.Feature},
-				VType:         vt.ValueTypeJSONWrapper{ValueType: vt.UInt64},
-				Cloud:         LocalMaterializeRunner,
+				OnlineType:       pt.RedisOnline,
+				OfflineType:      pt.SnowflakeOffline,
+				OnlineConfig:     []byte("{}"),
+				OfflineConfig:    []byte("{}"),
+				ResourceID:       provider.ResourceID{Name: "name", Variant: "variant", Type: provider.Feature},
+				VType:            vt.ValueTypeJSONWrapper{ValueType: vt.UInt64},
+				Cloud:            LocalMaterializeRunner,
+				TTL:              time.Hour,
+				VerifySampleSize: 50,
 				Options: provider.MaterializationOptions{
 					Output:               filestore.Parquet,
 					ShouldIncludeHeaders: true,
@@ -247,6 +255,9 @@ func TestMaterializedRunnerConfigSerde(t *testing.T) {
 						TS:          "ts",
 						SourceTable: pl.NewSQLLocation("table"),
 					},
+					Incremental: true,
+					Filter:      "status = 'active'",
+					PartitionBy: provider.MaterializationPartition{Type: provider.PartitionByEntityBucket, Buckets: 8},
 				},
 			},
 		},
@@ -262,6 +273,319 @@ func TestMaterializedRunnerConfigSerde(t *testing.T) {
 			if err := config.Deserialize(data); (err != nil) != test.expectErr {
 				t.Fatalf("Failed to deserialize config: %v", err)
 			}
+			if config.Options.Incremental != test.config.Options.Incremental {
+				t.Fatalf("Expected Incremental %v, got %v", test.config.Options.Incremental, config.Options.Incremental)
+			}
+			if config.Options.Filter != test.config.Options.Filter {
+				t.Fatalf("Expected Filter %q, got %q", test.config.Options.Filter, config.Options.Filter)
+			}
+			if config.Options.PartitionBy != test.config.Options.PartitionBy {
+				t.Fatalf("Expected PartitionBy %v, got %v", test.config.Options.PartitionBy, config.Options.PartitionBy)
+			}
+			if config.TTL != test.config.TTL {
+				t.Fatalf("Expected TTL %v, got %v", test.config.TTL, config.TTL)
+			}
+			if config.VerifySampleSize != test.config.VerifySampleSize {
+				t.Fatalf("Expected VerifySampleSize %d, got %d", test.config.VerifySampleSize, config.VerifySampleSize)
+			}
+		})
+	}
+}
+
+type countingChunkRunner struct {
+	active    *int32
+	maxActive *int32
+	started   *int32
+	err       error
+}
+
+func (m countingChunkRunner) Run() (types.CompletionWatcher, error) {
+	atomic.AddInt32(m.started, 1)
+	cur := atomic.AddInt32(m.active, 1)
+	for {
+		prev := atomic.LoadInt32(m.maxActive)
+		if cur <= prev || atomic.CompareAndSwapInt32(m.maxActive, prev, cur) {
+			break
+		}
+	}
+	time.Sleep(10 * time.Millisecond)
+	atomic.AddInt32(m.active, -1)
+	watcher := &SyncWatcher{ResultSync: &ResultSync{}, DoneChannel: make(chan interface{})}
+	watcher.EndWatch(m.err)
+	return watcher, nil
+}
+
+func (m countingChunkRunner) Resource() metadata.ResourceID {
+	return metadata.ResourceID{}
+}
+
+func (m countingChunkRunner) IsUpdateJob() bool {
+	return false
+}
+
+func TestMaterializeRunnerLocalChunkConcurrency(t *testing.T) {
+	tests := []struct {
+		name           string
+		maxConcurrency int
+		numChunks      int
+		chunkErr       error
+	}{
+		{"Bounded", 3, 20, nil},
+		{"Default", 0, 25, nil},
+		{"Sequential", 1, 5, nil},
+		{"Failure", 1, 20, fmt.Errorf("chunk failed")},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var active, maxActive, started int32
+			delete(factoryMap, COPY_TO_ONLINE)
+			defer delete(factoryMap, COPY_TO_ONLINE)
+			factory := func(config Config) (types.Runner, error) {
+				return countingChunkRunner{active: &active, maxActive: &maxActive, started: &started, err: test.chunkErr}, nil
+			}
+			if err := RegisterFactory(COPY_TO_ONLINE, factory); err != nil {
+				t.Fatalf("Failed to register factory: %v", err)
+			}
+			materializeRunner := MaterializeRunner{
+				ID:             provider.ResourceID{Name: "test", Variant: "test", Type: provider.Feature},
+				Logger:         zaptest.NewLogger(t).Sugar(),
+				MaxConcurrency: test.maxConcurrency,
+			}
+			err := materializeRunner.runLocalChunks(MaterializedChunkRunnerConfig{}, test.numChunks, newCopyProgress(), 0).Wait()
+			if test.chunkErr != nil {
+				if err == nil {
+					t.Fatalf("Expected chunk error to be returned")
+				}
+				if int(started) >= test.numChunks {
+					t.Fatalf("Expected failure to stop remaining chunks, started %d of %d", started, test.numChunks)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Failed to run chunks: %v", err)
+			}
+			if int(started) != test.numChunks {
+				t.Fatalf("Expected %d chunks to run, got %d", test.numChunks, started)
+			}
+			expectedMax := test.maxConcurrency
+			if expectedMax < 1 {
+				expectedMax = DefaultMaxConcurrency
+			}
+			if int(maxActive) > expectedMax {
+				t.Fatalf("Expected at most %d concurrent chunks, got %d", expectedMax, maxActive)
+			}
+		})
+	}
+}
+
+// blockingChunkRunner runs until its context is cancelled, unless it's chunk 0,
+// which fails once the other chunks have started.
+type blockingChunkRunner struct {
+	chunkIdx int
+	started  *sync.WaitGroup
+	stopped  chan error
+	err      error
+}
+
+func (m blockingChunkRunner) Run() (types.CompletionWatcher, error) {
+	return m.RunContext(context.Background())
+}
+
+func (m blockingChunkRunner) RunContext(ctx context.Context) (types.CompletionWatcher, error) {
+	watcher := &SyncWatcher{ResultSync: &ResultSync{}, DoneChannel: make(chan interface{})}
+	if m.chunkIdx == 0 {
+		go func() {
+			m.started.Wait()
+			watcher.EndWatch(m.err)
+		}()
+		return watcher, nil
+	}
+	m.started.Done()
+	go func() {
+		select {
+		case <-ctx.Done():
+			m.stopped <- ctx.Err()
+			watcher.EndWatch(ctx.Err())
+		case <-time.After(10 * time.Second):
+			m.stopped <- nil
+			watcher.EndWatch(nil)
+		}
+	}()
+	return watcher, nil
+}
+
+func (m blockingChunkRunner) Resource() metadata.ResourceID {
+	return metadata.ResourceID{}
+}
+
+func (m blockingChunkRunner) IsUpdateJob() bool {
+	return false
+}
+
+func TestMaterializeRunnerLocalChunkFailureCancelsRunning(t *testing.T) {
+	const numChunks = 3
+	var started sync.WaitGroup
+	started.Add(numChunks - 1)
+	stopped := make(chan error, numChunks-1)
+	chunkErr := fmt.Errorf("chunk failed")
+	delete(factoryMap, COPY_TO_ONLINE)
+	defer delete(factoryMap, COPY_TO_ONLINE)
+	factory := func(config Config) (types.Runner, error) {
+		chunkConfig := &MaterializedChunkRunnerConfig{}
+		if err := chunkConfig.Deserialize(config); err != nil {
+			return nil, err
+		}
+		return blockingChunkRunner{chunkIdx: chunkConfig.ChunkIdx, started: &started, stopped: stopped, err: chunkErr}, nil
+	}
+	if err := RegisterFactory(COPY_TO_ONLINE, factory); err != nil {
+		t.Fatalf("Failed to register factory: %v", err)
+	}
+	materializeRunner := MaterializeRunner{
+		ID:             provider.ResourceID{Name: "test", Variant: "test", Type: provider.Feature},
+		Logger:         zaptest.NewLogger(t).Sugar(),
+		MaxConcurrency: numChunks,
+	}
+	err := materializeRunner.runLocalChunks(MaterializedChunkRunnerConfig{}, numChunks, newCopyProgress(), 0).Wait()
+	if !errors.Is(err, chunkErr) {
+		t.Fatalf("Expected %v, got %v", chunkErr, err)
+	}
+	for i := 0; i < numChunks-1; i++ {
+		if err := <-stopped; !errors.Is(err, context.Canceled) {
+			t.Fatalf("Expected the running chunk to stop with %v, got %v", context.Canceled, err)
+		}
+	}
+}
+
+type fixedRowsChunkRunner struct {
+	rows int64
+}
+
+func (m fixedRowsChunkRunner) Run() (types.CompletionWatcher, error) {
+	time.Sleep(10 * time.Millisecond)
+	watcher := &SyncWatcher{ResultSync: &ResultSync{}, DoneChannel: make(chan interface{})}
+	watcher.EndWatch(nil)
+	return watcher, nil
+}
+
+func (m fixedRowsChunkRunner) Resource() metadata.ResourceID {
+	return metadata.ResourceID{}
+}
+
+func (m fixedRowsChunkRunner) IsUpdateJob() bool {
+	return false
+}
+
+func (m fixedRowsChunkRunner) RowsWritten() int64 {
+	return m.rows
+}
+
+type recordingProgressReporter struct {
+	mu      sync.Mutex
+	reports []scheduling.RunProgress
+}
+
+func (r *recordingProgressReporter) ReportProgress(progress scheduling.RunProgress) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.reports = append(r.reports, progress)
+}
+
+func TestMaterializeRunnerLocalChunkProgress(t *testing.T) {
+	delete(factoryMap, COPY_TO_ONLINE)
+	defer delete(factoryMap, COPY_TO_ONLINE)
+	factory := func(config Config) (types.Runner, error) {
+		return fixedRowsChunkRunner{rows: 5}, nil
+	}
+	if err := RegisterFactory(COPY_TO_ONLINE, factory); err != nil {
+		t.Fatalf("Failed to register factory: %v", err)
+	}
+	defaultInterval := progressInterval
+	progressInterval = time.Millisecond
+	defer func() { progressInterval = defaultInterval }()
+
+	reporter := &recordingProgressReporter{}
+	materializeRunner := MaterializeRunner{
+		ID:             provider.ResourceID{Name: "test", Variant: "test", Type: provider.Feature},
+		Logger:         zaptest.NewLogger(t).Sugar(),
+		MaxConcurrency: 2,
+		Progress:       reporter,
+	}
+	progress := newCopyProgress()
+	if err := materializeRunner.runLocalChunks(MaterializedChunkRunnerConfig{}, 8, progress, 40).Wait(); err != nil {
+		t.Fatalf("Failed to run chunks: %v", err)
+	}
+	if rows := progress.rows(); rows != 40 {
+		t.Fatalf("Expected 40 rows written, got %d", rows)
+	}
+	reporter.mu.Lock()
+	defer reporter.mu.Unlock()
+	if len(reporter.reports) == 0 {
+		t.Fatalf("Expected progress to be reported while copying")
+	}
+	var last int64
+	for _, report := range reporter.reports {
+		if report.Phase != scheduling.WritingPhase || report.TotalRows != 40 {
+			t.Fatalf("Expected writing progress out of 40 rows, got %+v", report)
+		}
+		if report.RowsProcessed < last || report.RowsProcessed > 40 {
+			t.Fatalf("Expected increasing progress of at most 40 rows, got %d after %d", report.RowsProcessed, last)
+		}
+		last = report.RowsProcessed
+	}
+}
+
+func TestChunkRows(t *testing.T) {
+	tests := map[string]struct {
+		succeeded int32
+		numChunks int64
+		totalRows int64
+		expected  int64
+	}{
+		"None":      {0, 4, 100, 0},
+		"Half":      {2, 4, 100, 50},
+		"All":       {4, 4, 100, 100},
+		"Uneven":    {1, 3, 100, 33},
+		"Capped":    {5, 4, 100, 100},
+		"No Chunks": {1, 0, 100, 0},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			if rows := chunkRows(test.succeeded, test.numChunks, test.totalRows); rows != test.expected {
+				t.Fatalf("Expected %d rows, got %d", test.expected, rows)
+			}
 		})
 	}
 }
+
+func TestMaterializeRunnerReportWritingEvery(t *testing.T) {
+	reporter := &recordingProgressReporter{}
+	materializeRunner := MaterializeRunner{
+		ID:       provider.ResourceID{Name: "test", Variant: "test", Type: provider.Feature},
+		Logger:   zaptest.NewLogger(t).Sugar(),
+		Progress: reporter,
+	}
+	var mu sync.Mutex
+	calls := 0
+	stop := materializeRunner.reportWritingEvery(time.Millisecond, 40, func() (int64, bool) {
+		mu.Lock()
+		defer mu.Unlock()
+		calls++
+		// Failed lookups aren't reported.
+		if calls%2 == 0 {
+			return 0, false
+		}
+		return 20, true
+	})
+	time.Sleep(20 * time.Millisecond)
+	stop()
+	reporter.mu.Lock()
+	defer reporter.mu.Unlock()
+	if len(reporter.reports) == 0 {
+		t.Fatalf("Expected progress to be reported")
+	}
+	for _, report := range reporter.reports {
+		if report.Phase != scheduling.WritingPhase || report.RowsProcessed != 20 || report.TotalRows != 40 {
+			t.Fatalf("Expected 20 of 40 rows written, got %+v", report)
+		}
+	}
+}
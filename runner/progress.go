@@ -0,0 +1,86 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+//
+// Copyright 2024 FeatureForm Inc.
+//
+
+package runner
+
+import (
+	"sync"
+	"time"
+
+	"github.com/featureform/scheduling"
+)
+
+// ProgressReporter is given a job's progress as it runs. Progress is only
+// informational, so implementations should log failures rather than return them.
+type ProgressReporter interface {
+	ReportProgress(progress scheduling.RunProgress)
+}
+
+// progressInterval is how often the materialize runner reports the number of
+// rows copied to the online store.
+var progressInterval = 10 * time.Second
+
+// rowCounter is implemented by runners that count the rows they write.
+type rowCounter interface {
+	RowsWritten() int64
+}
+
+// copyProgress sums the rows written by chunk runners, including the ones
+// still running.
+type copyProgress struct {
+	mu       sync.Mutex
+	finished int64
+	running  map[int]rowCounter
+}
+
+func newCopyProgress() *copyProgress {
+	return &copyProgress{running: make(map[int]rowCounter)}
+}
+
+func (p *copyProgress) start(chunk int, counter rowCounter) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.running[chunk] = counter
+}
+
+func (p *copyProgress) finish(chunk int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if counter, ok := p.running[chunk]; ok {
+		p.finished += counter.RowsWritten()
+		delete(p.running, chunk)
+	}
+}
+
+func (p *copyProgress) rows() int64 {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	rows := p.finished
+	for _, counter := range p.running {
+		rows += counter.RowsWritten()
+	}
+	return rows
+}
+
+// chunkCounter is implemented by watchers of jobs that copy chunks elsewhere,
+// like on Kubernetes, which can only say how many chunks have been copied.
+type chunkCounter interface {
+	Succeeded() (int32, error)
+}
+
+// chunkRows estimates the rows copied once succeeded of numChunks equal sized
+// chunks have been copied.
+func chunkRows(succeeded int32, numChunks, totalRows int64) int64 {
+	if numChunks <= 0 {
+		return 0
+	}
+	rows := totalRows * int64(succeeded) / numChunks
+	if rows > totalRows {
+		return totalRows
+	}
+	return rows
+}
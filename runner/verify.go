@@ -0,0 +1,189 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+//
+// Copyright 2024 FeatureForm Inc.
+//
+
+package runner
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"time"
+
+	"github.com/featureform/fferr"
+	"github.com/featureform/provider"
+)
+
+// DefaultVerifySampleSize is the number of entities VerifyMaterialization
+// compares when VerifySampleSize isn't set on the runner.
+const DefaultVerifySampleSize = 100
+
+// MaterializationMismatch is a sampled entity whose online value doesn't match
+// the value in the offline materialization.
+type MaterializationMismatch struct {
+	Entity  string
+	Offline interface{}
+	Online  interface{}
+	// Missing is true if the entity isn't in the online store at all.
+	Missing bool
+}
+
+func (m MaterializationMismatch) String() string {
+	if m.Missing {
+		return fmt.Sprintf("%s: missing online, expected %v", m.Entity, m.Offline)
+	}
+	return fmt.Sprintf("%s: online %v, expected %v", m.Entity, m.Online, m.Offline)
+}
+
+// MaterializationReport is the result of comparing a sample of a
+// materialization against the online store.
+type MaterializationReport struct {
+	Sampled    int
+	Mismatches []MaterializationMismatch
+}
+
+func (r MaterializationReport) Consistent() bool {
+	return len(r.Mismatches) == 0
+}
+
+// VerifyMaterialization samples entities from the materialization with the
+// given id and compares their values with the ones in the runner's online
+// store. Rows are sampled from every chunk, or evenly spaced chunks if there
+// are more chunks than samples, so a chunk that failed to copy is noticed.
+func (m MaterializeRunner) VerifyMaterialization(id provider.MaterializationID) (MaterializationReport, error) {
+	if m.Online == nil {
+		return MaterializationReport{}, fferr.NewInternalErrorf("cannot verify materialization %s without an online store", id)
+	}
+	materialization, err := m.Offline.GetMaterialization(id)
+	if err != nil {
+		return MaterializationReport{}, err
+	}
+	table, err := m.Online.GetTable(m.ID.Name, m.ID.Variant)
+	if err != nil {
+		return MaterializationReport{}, err
+	}
+	return verifyMaterialization(materialization, table, m.verifySampleSize())
+}
+
+func (m MaterializeRunner) verifySampleSize() int {
+	if m.VerifySampleSize < 1 {
+		return DefaultVerifySampleSize
+	}
+	return m.VerifySampleSize
+}
+
+// verifyMaterialization returns an error if the values in table can't be read,
+// and a report listing every sampled entity whose value differs otherwise.
+func verifyMaterialization(materialization provider.Materialization, table provider.OnlineStoreTable, sampleSize int) (MaterializationReport, error) {
+	records, err := sampleMaterialization(materialization, sampleSize)
+	if err != nil {
+		return MaterializationReport{}, err
+	}
+	entities := make([]string, len(records))
+	for i, record := range records {
+		entities[i] = record.Entity
+	}
+	values, found, err := getOnlineValues(table, entities)
+	if err != nil {
+		return MaterializationReport{}, err
+	}
+	report := MaterializationReport{Sampled: len(records)}
+	for i, record := range records {
+		if !found[i] {
+			report.Mismatches = append(report.Mismatches, MaterializationMismatch{Entity: record.Entity, Offline: record.Value, Missing: true})
+		} else if !onlineValueMatches(record.Value, values[i]) {
+			report.Mismatches = append(report.Mismatches, MaterializationMismatch{Entity: record.Entity, Offline: record.Value, Online: values[i]})
+		}
+	}
+	return report, nil
+}
+
+func sampleMaterialization(materialization provider.Materialization, sampleSize int) ([]provider.ResourceRecord, error) {
+	numChunks, err := materialization.NumChunks()
+	if err != nil {
+		return nil, err
+	}
+	if numChunks == 0 || sampleSize < 1 {
+		return nil, nil
+	}
+	chunks := numChunks
+	if chunks > sampleSize {
+		chunks = sampleSize
+	}
+	perChunk := sampleSize / chunks
+	records := make([]provider.ResourceRecord, 0, sampleSize)
+	for i := 0; i < chunks; i++ {
+		idx := i * numChunks / chunks
+		chunkRecords, err := readChunk(materialization, idx, perChunk)
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, chunkRecords...)
+	}
+	return records, nil
+}
+
+// readChunk returns up to limit records from the start of the chunk.
+func readChunk(materialization provider.Materialization, idx, limit int) ([]provider.ResourceRecord, error) {
+	it, err := materialization.IterateChunk(idx)
+	if err != nil {
+		return nil, err
+	}
+	defer it.Close()
+	records := make([]provider.ResourceRecord, 0, limit)
+	for len(records) < limit && it.Next() {
+		records = append(records, it.Value())
+	}
+	if err := it.Err(); err != nil {
+		return nil, err
+	}
+	return records, nil
+}
+
+// getOnlineValues gets the entities' values with a single multi-get when the
+// table supports it. found is false for entities that aren't in the table.
+func getOnlineValues(table provider.OnlineStoreTable, entities []string) ([]interface{}, []bool, error) {
+	found := make([]bool, len(entities))
+	if batchTable, ok := table.(provider.BatchGetOnlineTable); ok && len(entities) > 0 {
+		values, err := batchTable.BatchGet(entities)
+		var notFound *fferr.EntityNotFoundError
+		if err == nil {
+			for i := range found {
+				found[i] = true
+			}
+			return values, found, nil
+		} else if !errors.As(err, &notFound) {
+			return nil, nil, err
+		}
+		// A batch fails as a whole, so get each entity to find the missing ones.
+	}
+	values := make([]interface{}, len(entities))
+	for i, entity := range entities {
+		value, err := table.Get(entity)
+		var notFound *fferr.EntityNotFoundError
+		if errors.As(err, &notFound) {
+			continue
+		} else if err != nil {
+			return nil, nil, err
+		}
+		values[i], found[i] = value, true
+	}
+	return values, found, nil
+}
+
+// onlineValueMatches compares values loosely since stores may return a
+// different Go type than the offline store, e.g. int64 for an int.
+func onlineValueMatches(offline, online interface{}) bool {
+	if reflect.DeepEqual(offline, online) {
+		return true
+	}
+	offlineTS, isOfflineTS := offline.(time.Time)
+	onlineTS, isOnlineTS := online.(time.Time)
+	if isOfflineTS && isOnlineTS {
+		return offlineTS.Equal(onlineTS)
+	}
+	return fmt.Sprint(offline) == fmt.Sprint(online)
+}
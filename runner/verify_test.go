@@ -0,0 +1,99 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+//
+// Copyright 2024 FeatureForm Inc.
+//
+
+package runner
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/featureform/provider"
+	vt "github.com/featureform/provider/types"
+)
+
+func TestVerifyMaterialization(t *testing.T) {
+	records := make([]provider.ResourceRecord, 20)
+	for i := range records {
+		records[i] = provider.ResourceRecord{Entity: strconv.Itoa(i), Value: i}
+	}
+	materialization := &provider.MemoryMaterialization{Id: "mat", Data: records, RowsPerChunk: 5}
+
+	tests := map[string]struct {
+		set        func(table provider.OnlineStoreTable) error
+		sampleSize int
+		sampled    int
+		mismatches map[string]bool
+	}{
+		"Consistent": {
+			set:        func(table provider.OnlineStoreTable) error { return setRecords(table, records) },
+			sampleSize: 8,
+			sampled:    8,
+		},
+		"Stale Value": {
+			set: func(table provider.OnlineStoreTable) error {
+				if err := setRecords(table, records); err != nil {
+					return err
+				}
+				return table.Set("10", 100)
+			},
+			sampleSize: 8,
+			sampled:    8,
+			mismatches: map[string]bool{"10": false},
+		},
+		"Missing Chunk": {
+			set:        func(table provider.OnlineStoreTable) error { return setRecords(table, records[:15]) },
+			sampleSize: 4,
+			sampled:    4,
+			mismatches: map[string]bool{"15": true},
+		},
+		"More Chunks Than Samples": {
+			set:        func(table provider.OnlineStoreTable) error { return setRecords(table, records) },
+			sampleSize: 2,
+			sampled:    2,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			store := provider.NewMemoryOnlineStore()
+			table, err := store.CreateTable("feature", "variant", vt.Int)
+			if err != nil {
+				t.Fatalf("Failed to create table: %v", err)
+			}
+			if err := test.set(table); err != nil {
+				t.Fatalf("Failed to set values: %v", err)
+			}
+			report, err := verifyMaterialization(materialization, table, test.sampleSize)
+			if err != nil {
+				t.Fatalf("Failed to verify materialization: %v", err)
+			}
+			if report.Sampled != test.sampled {
+				t.Fatalf("Expected %d sampled entities, got %d", test.sampled, report.Sampled)
+			}
+			if len(report.Mismatches) != len(test.mismatches) {
+				t.Fatalf("Expected mismatches for %v, got %v", test.mismatches, report.Mismatches)
+			}
+			for _, mismatch := range report.Mismatches {
+				missing, has := test.mismatches[mismatch.Entity]
+				if !has || missing != mismatch.Missing {
+					t.Fatalf("Unexpected mismatch %s", mismatch)
+				}
+			}
+			if report.Consistent() != (len(test.mismatches) == 0) {
+				t.Fatalf("Expected report consistency to be %v", len(test.mismatches) == 0)
+			}
+		})
+	}
+}
+
+func setRecords(table provider.OnlineStoreTable, records []provider.ResourceRecord) error {
+	for _, record := range records {
+		if err := table.Set(record.Entity, record.Value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -10,6 +10,7 @@ package worker
 import (
 	"errors"
 	"fmt"
+	"io"
 	"os"
 	"strconv"
 
@@ -54,6 +55,13 @@ func CreateAndRun() error {
 		}
 		jobRunner = indexRunner
 	}
+	if closer, ok := jobRunner.(io.Closer); ok {
+		defer func() {
+			if err := closer.Close(); err != nil {
+				logger.Warnw("Failed to close runner", "error", err)
+			}
+		}()
+	}
 	watcher, err := jobRunner.Run()
 	if err != nil {
 		return err
@@ -0,0 +1,157 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+//
+// Copyright 2024 FeatureForm Inc.
+//
+
+package scheduling
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/featureform/fferr"
+	"github.com/featureform/ffsync"
+	"github.com/featureform/logging"
+)
+
+func taskScheduleLockPath(taskID TaskID) string {
+	return fmt.Sprintf("/tasks/schedule/lock/task_id=%s", taskID.String())
+}
+
+// CronScheduler creates runs of tasks that have a cron schedule set. The
+// coordinator picks the runs up like any other, and since the task already
+// has a successful run they update the existing transformation or
+// materialization. Every coordinator replica can run a CronScheduler; each
+// due schedule is locked while it's triggered so only one of them creates
+// the run.
+type CronScheduler struct {
+	manager      *TaskMetadataManager
+	locker       ffsync.Locker
+	pollInterval time.Duration
+	logger       logging.Logger
+	stop         chan struct{}
+	stopOnce     sync.Once
+	stopped      context.Context
+	markStopped  context.CancelFunc
+}
+
+func NewCronScheduler(manager *TaskMetadataManager, locker ffsync.Locker, pollInterval time.Duration, logger logging.Logger) *CronScheduler {
+	stopped, markStopped := context.WithCancel(context.Background())
+	return &CronScheduler{
+		manager:      manager,
+		locker:       locker,
+		pollInterval: pollInterval,
+		logger:       logger,
+		stop:         make(chan struct{}),
+		stopped:      stopped,
+		markStopped:  markStopped,
+	}
+}
+
+// Start checks for due schedules every poll interval until ctx is done or
+// Stop is called.
+func (s *CronScheduler) Start(ctx context.Context) {
+	defer s.markStopped()
+	s.logger.Infow("Starting cron scheduler", "poll_interval", s.pollInterval)
+	ticker := time.NewTicker(s.pollInterval)
+	defer ticker.Stop()
+	for {
+		if err := s.RunDue(ctx, time.Now().UTC()); err != nil {
+			s.logger.Errorw("Failed to run scheduled tasks", "error", err)
+		}
+		select {
+		case <-ctx.Done():
+			s.logger.Info("Stopping cron scheduler")
+			return
+		case <-s.stop:
+			s.logger.Info("Stopping cron scheduler")
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Stop stops the scheduler from checking for due schedules. A check that's
+// already running finishes; the returned context is done once it has and
+// Start has returned.
+func (s *CronScheduler) Stop() context.Context {
+	s.stopOnce.Do(func() { close(s.stop) })
+	return s.stopped
+}
+
+// RunDue creates a run for every task whose schedule is due at now.
+func (s *CronScheduler) RunDue(ctx context.Context, now time.Time) error {
+	schedules, err := s.manager.GetTaskSchedules()
+	if err != nil {
+		return err
+	}
+	for _, schedule := range schedules {
+		if !schedule.IsDue(now) {
+			continue
+		}
+		if err := s.trigger(ctx, schedule.TaskID, now); err != nil {
+			s.logger.Errorw("Failed to trigger scheduled task", "task_id", schedule.TaskID.String(), "error", err)
+		}
+	}
+	return nil
+}
+
+func (s *CronScheduler) trigger(ctx context.Context, taskID TaskID, now time.Time) error {
+	logger := s.logger.With("task_id", taskID.String())
+	lock, err := s.locker.Lock(ctx, taskScheduleLockPath(taskID), false)
+	if fferr.IsKeyAlreadyLockedError(err) {
+		logger.Debug("Schedule is being triggered by another scheduler, skipping")
+		return nil
+	} else if err != nil {
+		return err
+	}
+	defer func() {
+		if err := s.locker.Unlock(ctx, lock); err != nil {
+			logger.Errorw("Failed to unlock schedule", "error", err)
+		}
+	}()
+
+	// Another replica may have triggered the schedule between listing it and
+	// taking the lock.
+	schedule, err := s.manager.GetTaskSchedule(taskID)
+	if err != nil {
+		return err
+	}
+	if !schedule.IsDue(now) {
+		return nil
+	}
+	if skip, err := s.hasUnfinishedRun(taskID); err != nil {
+		return err
+	} else if skip {
+		logger.Infow("Previous scheduled run hasn't finished, skipping", "schedule", schedule.Schedule)
+	} else {
+		task, err := s.manager.GetTaskByID(taskID)
+		if err != nil {
+			return err
+		}
+		trigger := ScheduleTrigger{TriggerName: "schedule", Schedule: schedule.Schedule}
+		run, err := s.manager.CreateTaskRun(ctx, task.Name, taskID, trigger)
+		if err != nil {
+			return err
+		}
+		logger.Infow("Created scheduled run", "run_id", run.ID.String(), "schedule", schedule.Schedule)
+	}
+	updated, err := s.manager.recordScheduledRun(taskID, now)
+	if err != nil {
+		return err
+	}
+	logger.Debugw("Recorded scheduled run", "last_run", updated.LastRun, "next_run", updated.NextRun)
+	return nil
+}
+
+func (s *CronScheduler) hasUnfinishedRun(taskID TaskID) (bool, error) {
+	runs, err := s.manager.GetTaskRunMetadata(taskID)
+	if err != nil {
+		return false, err
+	}
+	return len(runs.FilterByStatus(PENDING, RUNNING)) > 0, nil
+}
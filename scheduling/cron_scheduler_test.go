@@ -0,0 +1,179 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+//
+// Copyright 2024 FeatureForm Inc.
+//
+
+package scheduling
+
+import (
+	"testing"
+	"time"
+
+	"github.com/featureform/logging"
+)
+
+func TestParseSchedule(t *testing.T) {
+	start := time.Date(2024, 1, 1, 10, 7, 0, 0, time.UTC)
+	tests := map[string]struct {
+		Schedule string
+		Next     time.Time
+	}{
+		"every minute":  {"* * * * *", start.Add(time.Minute)},
+		"every 15 mins": {"*/15 * * * *", time.Date(2024, 1, 1, 10, 15, 0, 0, time.UTC)},
+		"daily":         {"30 2 * * *", time.Date(2024, 1, 2, 2, 30, 0, 0, time.UTC)},
+		"weekdays":      {"0 9 * * 1-5", time.Date(2024, 1, 2, 9, 0, 0, 0, time.UTC)},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			schedule, err := ParseSchedule(test.Schedule)
+			if err != nil {
+				t.Fatalf("failed to parse schedule: %v", err)
+			}
+			if next := schedule.Next(start); !next.Equal(test.Next) {
+				t.Fatalf("expected %v, got: %v", test.Next, next)
+			}
+		})
+	}
+	for _, invalid := range []string{"", "* * * *", "0 * * * * *", "61 * * * *", "not a cron"} {
+		if _, err := ParseSchedule(invalid); err == nil {
+			t.Fatalf("expected error parsing schedule '%s'", invalid)
+		}
+	}
+}
+
+func TestCronSchedulerRunDue(t *testing.T) {
+	ctx := logging.NewTestContext(t)
+	manager, err := NewMemoryTaskMetadataManager(ctx)
+	if err != nil {
+		t.Fatalf("failed to create memory task metadata manager: %v", err)
+	}
+	task, err := manager.CreateTask(ctx, "name", ResourceCreation, NameVariant{"name", "variant", "type"})
+	if err != nil {
+		t.Fatalf("failed to create task: %v", err)
+	}
+	if err := manager.SetTaskSchedule(task.ID, "* * * * *"); err != nil {
+		t.Fatalf("failed to set task schedule: %v", err)
+	}
+	schedules, err := manager.GetTaskSchedules()
+	if err != nil {
+		t.Fatalf("failed to get task schedules: %v", err)
+	}
+	if len(schedules) != 1 || schedules[0].TaskID.String() != task.ID.String() {
+		t.Fatalf("expected a schedule for task %s, got: %v", task.ID, schedules)
+	}
+
+	scheduler := NewCronScheduler(&manager, manager.Storage.Locker, time.Minute, logging.GetLoggerFromContext(ctx))
+	countRuns := func() int {
+		runs, err := manager.GetTaskRunMetadata(task.ID)
+		if err != nil {
+			t.Fatalf("failed to get runs: %v", err)
+		}
+		return len(runs)
+	}
+
+	// Not due yet.
+	if err := scheduler.RunDue(ctx, schedules[0].NextRun.Add(-time.Second)); err != nil {
+		t.Fatalf("failed to run due schedules: %v", err)
+	}
+	if runs := countRuns(); runs != 0 {
+		t.Fatalf("expected no runs before the schedule is due, got: %d", runs)
+	}
+
+	now := schedules[0].NextRun
+	if err := scheduler.RunDue(ctx, now); err != nil {
+		t.Fatalf("failed to run due schedules: %v", err)
+	}
+	if runs := countRuns(); runs != 1 {
+		t.Fatalf("expected 1 run, got: %d", runs)
+	}
+	run, err := manager.GetLatestRun(task.ID)
+	if err != nil {
+		t.Fatalf("failed to get latest run: %v", err)
+	}
+	if trigger, ok := run.Trigger.(ScheduleTrigger); !ok || trigger.Schedule != "* * * * *" {
+		t.Fatalf("expected a schedule trigger, got: %#v", run.Trigger)
+	}
+	schedule, err := manager.GetTaskSchedule(task.ID)
+	if err != nil {
+		t.Fatalf("failed to get task schedule: %v", err)
+	}
+	if !schedule.LastRun.Equal(now) || !schedule.NextRun.Equal(now.Add(time.Minute)) {
+		t.Fatalf("expected last run %v and next run %v, got: %v and %v", now, now.Add(time.Minute), schedule.LastRun, schedule.NextRun)
+	}
+
+	// The same tick on another replica shouldn't create a second run.
+	if err := scheduler.RunDue(ctx, now); err != nil {
+		t.Fatalf("failed to run due schedules: %v", err)
+	}
+	if runs := countRuns(); runs != 1 {
+		t.Fatalf("expected 1 run after rerunning the same tick, got: %d", runs)
+	}
+
+	// The first run is still pending, so the next tick is skipped.
+	if err := scheduler.RunDue(ctx, schedule.NextRun); err != nil {
+		t.Fatalf("failed to run due schedules: %v", err)
+	}
+	if runs := countRuns(); runs != 1 {
+		t.Fatalf("expected 1 run while the previous one is pending, got: %d", runs)
+	}
+
+	if err := manager.SetTaskSchedule(task.ID, ""); err != nil {
+		t.Fatalf("failed to remove task schedule: %v", err)
+	}
+	if schedules, err := manager.GetTaskSchedules(); err != nil || len(schedules) != 0 {
+		t.Fatalf("expected no schedules, got: %v: %v", schedules, err)
+	}
+}
+
+func TestCronSchedulerSkipsLockedSchedule(t *testing.T) {
+	ctx := logging.NewTestContext(t)
+	manager, err := NewMemoryTaskMetadataManager(ctx)
+	if err != nil {
+		t.Fatalf("failed to create memory task metadata manager: %v", err)
+	}
+	task, err := manager.CreateTask(ctx, "name", ResourceCreation, NameVariant{"name", "variant", "type"})
+	if err != nil {
+		t.Fatalf("failed to create task: %v", err)
+	}
+	if err := manager.SetTaskSchedule(task.ID, "0 * * * *"); err != nil {
+		t.Fatalf("failed to set task schedule: %v", err)
+	}
+	lock, err := manager.Storage.Locker.Lock(ctx, taskScheduleLockPath(task.ID), false)
+	if err != nil {
+		t.Fatalf("failed to lock schedule: %v", err)
+	}
+	defer manager.Storage.Locker.Unlock(ctx, lock)
+
+	scheduler := NewCronScheduler(&manager, manager.Storage.Locker, time.Minute, logging.GetLoggerFromContext(ctx))
+	if err := scheduler.RunDue(ctx, time.Now().UTC().Add(2*time.Hour)); err != nil {
+		t.Fatalf("failed to run due schedules: %v", err)
+	}
+	runs, err := manager.GetTaskRunMetadata(task.ID)
+	if err != nil {
+		t.Fatalf("failed to get runs: %v", err)
+	}
+	if len(runs) != 0 {
+		t.Fatalf("expected no runs while another scheduler holds the lock, got: %d", len(runs))
+	}
+}
+
+func TestCronSchedulerStop(t *testing.T) {
+	ctx := logging.NewTestContext(t)
+	manager, err := NewMemoryTaskMetadataManager(ctx)
+	if err != nil {
+		t.Fatalf("failed to create memory task metadata manager: %v", err)
+	}
+	scheduler := NewCronScheduler(&manager, manager.Storage.Locker, time.Minute, logging.GetLoggerFromContext(ctx))
+	go scheduler.Start(ctx)
+	select {
+	case <-scheduler.Stop().Done():
+	case <-time.After(5 * time.Second):
+		t.Fatalf("expected the scheduler to stop")
+	}
+	// Stopping again returns the same, already done, context.
+	if err := scheduler.Stop().Err(); err == nil {
+		t.Fatalf("expected the stopped context to be done")
+	}
+}
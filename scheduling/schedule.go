@@ -0,0 +1,190 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+//
+// Copyright 2024 FeatureForm Inc.
+//
+
+package scheduling
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gorhill/cronexpr"
+
+	"github.com/featureform/fferr"
+	"github.com/featureform/ffsync"
+)
+
+const taskScheduleKeyPrefix = "/tasks/schedule/task_id="
+
+type TaskScheduleKey struct {
+	taskID TaskID
+}
+
+func (tsk TaskScheduleKey) String() string {
+	if tsk.taskID == nil {
+		return taskScheduleKeyPrefix
+	}
+	return fmt.Sprintf("%s%s", taskScheduleKeyPrefix, tsk.taskID.String())
+}
+
+// Schedule is a parsed cron expression in the standard 5-field syntax:
+// minute, hour, day of month, month and day of week.
+type Schedule struct {
+	expr *cronexpr.Expression
+}
+
+func ParseSchedule(schedule string) (Schedule, error) {
+	if fields := strings.Fields(schedule); len(fields) != 5 {
+		return Schedule{}, fferr.NewInvalidArgumentErrorf("schedule '%s' must have 5 fields (minute hour day-of-month month day-of-week), got %d", schedule, len(fields))
+	}
+	expr, err := cronexpr.Parse(schedule)
+	if err != nil {
+		return Schedule{}, fferr.NewInvalidArgumentErrorf("invalid schedule '%s': %v", schedule, err)
+	}
+	return Schedule{expr: expr}, nil
+}
+
+// Next returns the first time after t that the schedule is due.
+func (s Schedule) Next(t time.Time) time.Time {
+	return s.expr.Next(t)
+}
+
+// TaskSchedule is the cron schedule of a task along with when it was last
+// triggered and when it's next due.
+type TaskSchedule struct {
+	TaskID   TaskID    `json:"taskId"`
+	Schedule string    `json:"schedule"`
+	LastRun  time.Time `json:"lastRun"`
+	NextRun  time.Time `json:"nextRun"`
+}
+
+func (s *TaskSchedule) Marshal() ([]byte, error) {
+	bytes, err := json.Marshal(s)
+	if err != nil {
+		errMessage := fmt.Errorf("failed to serialize task schedule: %w", err)
+		return nil, fferr.NewInternalError(errMessage)
+	}
+	return bytes, nil
+}
+
+func (s *TaskSchedule) Unmarshal(data []byte) error {
+	type tempConfig struct {
+		TaskID   uint64    `json:"taskId"`
+		Schedule string    `json:"schedule"`
+		LastRun  time.Time `json:"lastRun"`
+		NextRun  time.Time `json:"nextRun"`
+	}
+
+	var temp tempConfig
+	if err := json.Unmarshal(data, &temp); err != nil {
+		errMessage := fmt.Errorf("failed to deserialize task schedule: %w", err)
+		return fferr.NewInternalError(errMessage)
+	}
+	if temp.Schedule == "" {
+		return fferr.NewInvalidArgumentError(fmt.Errorf("task schedule is missing schedule"))
+	}
+	s.TaskID = TaskID(ffsync.Uint64OrderedId(temp.TaskID))
+	s.Schedule = temp.Schedule
+	s.LastRun = temp.LastRun
+	s.NextRun = temp.NextRun
+	return nil
+}
+
+// IsDue returns true if the schedule should have been triggered by now.
+func (s TaskSchedule) IsDue(now time.Time) bool {
+	return !s.NextRun.After(now)
+}
+
+// SetTaskSchedule sets the cron schedule that runs of the task are created
+// on. An empty schedule removes it.
+func (m *TaskMetadataManager) SetTaskSchedule(taskID TaskID, schedule string) error {
+	key := TaskScheduleKey{taskID: taskID}.String()
+	if schedule == "" {
+		_, err := m.Storage.Delete(key)
+		var notFound *fferr.KeyNotFoundError
+		if errors.As(err, &notFound) {
+			return nil
+		}
+		return err
+	}
+	parsed, err := ParseSchedule(schedule)
+	if err != nil {
+		return err
+	}
+	taskSchedule := TaskSchedule{
+		TaskID:   taskID,
+		Schedule: schedule,
+		NextRun:  parsed.Next(time.Now().UTC()),
+	}
+	existing, err := m.GetTaskSchedule(taskID)
+	if err == nil {
+		taskSchedule.LastRun = existing.LastRun
+	}
+	serialized, err := taskSchedule.Marshal()
+	if err != nil {
+		return err
+	}
+	return m.Storage.Create(key, string(serialized))
+}
+
+func (m *TaskMetadataManager) GetTaskSchedule(taskID TaskID) (TaskSchedule, error) {
+	rec, err := m.Storage.Get(TaskScheduleKey{taskID: taskID}.String())
+	if err != nil {
+		return TaskSchedule{}, err
+	}
+	schedule := TaskSchedule{}
+	if err := schedule.Unmarshal([]byte(rec)); err != nil {
+		return TaskSchedule{}, err
+	}
+	return schedule, nil
+}
+
+func (m *TaskMetadataManager) GetTaskSchedules() ([]TaskSchedule, error) {
+	recs, err := m.Storage.List(TaskScheduleKey{}.String())
+	if err != nil {
+		return nil, err
+	}
+	schedules := make([]TaskSchedule, 0, len(recs))
+	for _, rec := range recs {
+		schedule := TaskSchedule{}
+		if err := schedule.Unmarshal([]byte(rec)); err != nil {
+			return nil, err
+		}
+		schedules = append(schedules, schedule)
+	}
+	return schedules, nil
+}
+
+// recordScheduledRun sets when the task was last triggered by its schedule
+// and when it's due next.
+func (m *TaskMetadataManager) recordScheduledRun(taskID TaskID, ranAt time.Time) (TaskSchedule, error) {
+	var updated TaskSchedule
+	updateFn := func(rec string) (string, error) {
+		schedule := TaskSchedule{}
+		if err := schedule.Unmarshal([]byte(rec)); err != nil {
+			return "", err
+		}
+		parsed, err := ParseSchedule(schedule.Schedule)
+		if err != nil {
+			return "", err
+		}
+		schedule.LastRun = ranAt
+		schedule.NextRun = parsed.Next(ranAt)
+		updated = schedule
+		serialized, err := schedule.Marshal()
+		if err != nil {
+			return "", err
+		}
+		return string(serialized), nil
+	}
+	if err := m.Storage.Update(TaskScheduleKey{taskID: taskID}.String(), updateFn); err != nil {
+		return TaskSchedule{}, err
+	}
+	return updated, nil
+}
@@ -210,6 +210,42 @@ func (t ScheduleTrigger) Name() string {
 	return t.TriggerName
 }
 
+// ProgressPhase is the stage a job that reports progress is in.
+type ProgressPhase string
+
+const (
+	ReadingPhase ProgressPhase = "READING"
+	WritingPhase ProgressPhase = "WRITING"
+	DonePhase    ProgressPhase = "DONE"
+)
+
+// RunProgress is how far along a long running job, like a materialization, is.
+// TotalRows is zero until it's known.
+type RunProgress struct {
+	Phase         ProgressPhase `json:"phase"`
+	RowsProcessed int64         `json:"rowsProcessed"`
+	TotalRows     int64         `json:"totalRows"`
+	UpdatedAt     time.Time     `json:"updatedAt"`
+}
+
+func (p RunProgress) ToProto() *sch.RunProgress {
+	return &sch.RunProgress{
+		Phase:         string(p.Phase),
+		RowsProcessed: p.RowsProcessed,
+		TotalRows:     p.TotalRows,
+		Updated:       wrapTimestampProto(p.UpdatedAt),
+	}
+}
+
+func RunProgressFromProto(progress *sch.RunProgress) RunProgress {
+	return RunProgress{
+		Phase:         ProgressPhase(progress.GetPhase()),
+		RowsProcessed: progress.GetRowsProcessed(),
+		TotalRows:     progress.GetTotalRows(),
+		UpdatedAt:     progress.GetUpdated().AsTime(),
+	}
+}
+
 type TaskRunMetadata struct {
 	ID             TaskRunID       `json:"runId"`
 	TaskId         TaskID          `json:"taskId"`
@@ -229,6 +265,13 @@ type TaskRunMetadata struct {
 	IsDelete       bool            `json:"isDelete"`
 	ResumeID       ptypes.ResumeID `json:"resumeID"`
 	ErrorProto     *pb.ErrorStatus
+	// Attempts is the number of times the run has been retried after failing.
+	Attempts int `json:"attempts"`
+	// LastFailedAttempt is when the run last failed with a retryable error. The
+	// next attempt waits out the retry backoff from then.
+	LastFailedAttempt time.Time `json:"lastFailedAttempt"`
+	// Progress is only set for runs that report it.
+	Progress *RunProgress `json:"progress,omitempty"`
 }
 
 func (t *TaskRunMetadata) Marshal() ([]byte, error) {
@@ -241,22 +284,25 @@ func (t *TaskRunMetadata) Marshal() ([]byte, error) {
 
 func (t *TaskRunMetadata) Unmarshal(data []byte) error {
 	type tempConfig struct {
-		ID             uint64          `json:"runId"`
-		TaskId         uint64          `json:"taskId"`
-		Name           string          `json:"name"`
-		Trigger        json.RawMessage `json:"trigger"`
-		TriggerType    TriggerType     `json:"triggerType"`
-		Target         json.RawMessage `json:"target"`
-		TargetType     TargetType      `json:"targetType"`
-		Status         Status          `json:"status"`
-		StartTime      time.Time       `json:"startTime"`
-		EndTime        time.Time       `json:"endTime"`
-		Logs           []string        `json:"logs"`
-		Error          string          `json:"error"`
-		ResumeID       string          `json:"resumeID"`
-		ErrorProto     *pb.ErrorStatus
-		LastSuccessful uint64 `json:"lastSuccessful"`
-		IsDelete       bool   `json:"isDelete"`
+		ID                uint64          `json:"runId"`
+		TaskId            uint64          `json:"taskId"`
+		Name              string          `json:"name"`
+		Trigger           json.RawMessage `json:"trigger"`
+		TriggerType       TriggerType     `json:"triggerType"`
+		Target            json.RawMessage `json:"target"`
+		TargetType        TargetType      `json:"targetType"`
+		Status            Status          `json:"status"`
+		StartTime         time.Time       `json:"startTime"`
+		EndTime           time.Time       `json:"endTime"`
+		Logs              []string        `json:"logs"`
+		Error             string          `json:"error"`
+		ResumeID          string          `json:"resumeID"`
+		ErrorProto        *pb.ErrorStatus
+		LastSuccessful    uint64       `json:"lastSuccessful"`
+		IsDelete          bool         `json:"isDelete"`
+		Attempts          int          `json:"attempts"`
+		LastFailedAttempt time.Time    `json:"lastFailedAttempt"`
+		Progress          *RunProgress `json:"progress,omitempty"`
 	}
 
 	var temp tempConfig
@@ -294,6 +340,9 @@ func (t *TaskRunMetadata) Unmarshal(data []byte) error {
 	t.Logs = temp.Logs
 	t.Error = temp.Error
 	t.IsDelete = temp.IsDelete
+	t.Attempts = temp.Attempts
+	t.LastFailedAttempt = temp.LastFailedAttempt
+	t.Progress = temp.Progress
 
 	triggerMap := make(map[string]interface{})
 	if err := json.Unmarshal(temp.Trigger, &triggerMap); err != nil {
@@ -342,6 +391,13 @@ func (t *TaskRunMetadata) Unmarshal(data []byte) error {
 			return fferr.NewInternalError(errMessage)
 		}
 		t.Target = providerTarget
+	case SourceProfileTarget:
+		var sourceProfileTarget SourceProfile
+		if err := json.Unmarshal(temp.Target, &sourceProfileTarget); err != nil {
+			errMessage := fmt.Errorf("failed to deserialize SourceProfile target data: %w", err)
+			return fferr.NewInternalError(errMessage)
+		}
+		t.Target = sourceProfileTarget
 	default:
 		errMessage := fmt.Errorf("unknown target type: %s", temp.Target)
 		return fferr.NewInvalidArgumentError(errMessage)
@@ -374,6 +430,13 @@ func (run *TaskRunMetadata) ToProto() (*sch.TaskRunMetadata, error) {
 		ResumeID:       &sch.ResumeID{Id: run.ResumeID.String()},
 		LastSuccessful: lsid,
 		IsDelete:       run.IsDelete,
+		Attempts:       int32(run.Attempts),
+	}
+	if run.Progress != nil {
+		taskRunMetadata.Progress = run.Progress.ToProto()
+	}
+	if !run.LastFailedAttempt.IsZero() {
+		taskRunMetadata.LastFailedAttempt = wrapTimestampProto(run.LastFailedAttempt)
 	}
 
 	taskRunMetadata, err := setTriggerProto(taskRunMetadata, run.Trigger)
@@ -414,6 +477,8 @@ func setTaskRunMetadataTargetProto(proto *sch.TaskRunMetadata, target TaskTarget
 		proto.Target = getTaskRunNameVariantTargetProto(t)
 	case Provider:
 		proto.Target = getTaskRunProviderTargetProto(t)
+	case SourceProfile:
+		proto.Target = &sch.TaskRunMetadata_SourceProfile{SourceProfile: getSourceProfileTargetProto(t)}
 	default:
 		return nil, fferr.NewUnimplementedErrorf("could not convert target to proto: type: %T", target)
 	}
@@ -486,23 +551,35 @@ func TaskRunMetadataFromProto(run *sch.TaskRunMetadata) (TaskRunMetadata, error)
 	if err != nil {
 		return TaskRunMetadata{}, err
 	}
+	var progress *RunProgress
+	if run.GetProgress() != nil {
+		fromProto := RunProgressFromProto(run.GetProgress())
+		progress = &fromProto
+	}
+	var lastFailedAttempt time.Time
+	if run.GetLastFailedAttempt() != nil {
+		lastFailedAttempt = run.GetLastFailedAttempt().AsTime()
+	}
 	return TaskRunMetadata{
-		ID:             rid,
-		TaskId:         tid,
-		Name:           run.Name,
-		Trigger:        t,
-		TriggerType:    TriggerType(run.TriggerType),
-		Target:         target,
-		TargetType:     TargetType(run.TargetType),
-		Status:         Status(run.Status.Status),
-		StartTime:      run.StartTime.AsTime(),
-		EndTime:        run.EndTime.AsTime(),
-		Logs:           run.Logs,
-		Error:          run.Status.ErrorMessage,
-		ErrorProto:     run.Status.ErrorStatus,
-		ResumeID:       ptypes.ResumeID(run.GetResumeID().GetId()),
-		LastSuccessful: lsid,
-		IsDelete:       run.IsDelete,
+		ID:                rid,
+		TaskId:            tid,
+		Name:              run.Name,
+		Trigger:           t,
+		TriggerType:       TriggerType(run.TriggerType),
+		Target:            target,
+		TargetType:        TargetType(run.TargetType),
+		Status:            Status(run.Status.Status),
+		StartTime:         run.StartTime.AsTime(),
+		EndTime:           run.EndTime.AsTime(),
+		Logs:              run.Logs,
+		Error:             run.Status.ErrorMessage,
+		ErrorProto:        run.Status.ErrorStatus,
+		ResumeID:          ptypes.ResumeID(run.GetResumeID().GetId()),
+		LastSuccessful:    lsid,
+		IsDelete:          run.IsDelete,
+		Attempts:          int(run.Attempts),
+		LastFailedAttempt: lastFailedAttempt,
+		Progress:          progress,
 	}, nil
 }
 
@@ -529,6 +606,12 @@ func convertTaskRunProtoTarget(target interface{}) (TaskTarget, error) {
 		return Provider{
 			Name: t.Provider.Name,
 		}, nil
+	case *sch.TaskRunMetadata_SourceProfile:
+		return SourceProfile{
+			Name:       t.SourceProfile.Source.GetName(),
+			Variant:    t.SourceProfile.Source.GetVariant(),
+			SampleSize: t.SourceProfile.SampleSize,
+		}, nil
 	default:
 		return nil, fferr.NewUnimplementedErrorf("could not convert target proto type: %T", target)
 	}
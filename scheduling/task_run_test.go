@@ -169,6 +169,29 @@ func TestSerializeTaskRunMetadata(t *testing.T) {
 			},
 			triggerType: ScheduleTriggerType,
 		},
+		{
+			name: "WithSourceProfileTarget",
+			task: TaskRunMetadata{
+				ID:     TaskRunID(id1),
+				TaskId: TaskID(id1),
+				Name:   "profile_taskrun",
+				Trigger: OnApplyTrigger{
+					TriggerName: "name3",
+				},
+				TriggerType: OnApplyTriggerType,
+				Target: SourceProfile{
+					Name:       "name",
+					Variant:    "variant",
+					SampleSize: 100,
+				},
+				TargetType: SourceProfileTarget,
+				Status:     PENDING,
+				StartTime:  time.Now().Truncate(0).UTC(),
+				EndTime:    time.Now().Truncate(0).UTC(),
+				ResumeID:   ptypes.ResumeID("resume"),
+			},
+			triggerType: OnApplyTriggerType,
+		},
 	}
 
 	for _, currTest := range testCases {
@@ -466,6 +489,28 @@ func Test_wrapTaskRunMetadataProto(t *testing.T) {
 			},
 			false,
 		},
+		{
+			"Source Profile",
+			TaskRunMetadata{
+				ID:     TaskRunID(id),
+				TaskId: TaskID(id),
+				Trigger: OnApplyTrigger{
+					TriggerName: "trigger_name",
+				},
+				TriggerType: OnApplyTriggerType,
+				Target: SourceProfile{
+					Name:       "name",
+					Variant:    "variant",
+					SampleSize: 100,
+				},
+				TargetType: SourceProfileTarget,
+				Status:     PENDING,
+				StartTime:  time.Now().UTC(),
+				EndTime:    time.Now().AddDate(0, 0, 1).UTC(),
+				Logs:       []string{"log1"},
+			},
+			false,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -51,6 +51,12 @@ type TaskMetadataManager struct {
 	notifier    notifications.Notifier
 }
 
+// SetIdGenerator replaces the generator that task and run IDs are created
+// with, such as when they're shared with other processes.
+func (m *TaskMetadataManager) SetIdGenerator(generator ffsync.OrderedIdGenerator) {
+	m.idGenerator = generator
+}
+
 func (m *TaskMetadataManager) SyncIncompleteRuns() error {
 	runs, err := m.GetAllTaskRuns()
 	if err != nil {
@@ -595,6 +601,65 @@ func (m *TaskMetadataManager) SetResumeID(runID TaskRunID, taskID TaskID, id pty
 	return err
 }
 
+// IncrementRunAttempts records another failed attempt of a run that's being
+// retried, along with when it failed, and returns the new number of attempts.
+func (m *TaskMetadataManager) IncrementRunAttempts(runID TaskRunID, taskID TaskID) (int, error) {
+	metadata, err := m.GetRunByID(taskID, runID)
+	if err != nil {
+		return 0, err
+	}
+	var attempts int
+	incrementAttempts := func(runMetadata string) (string, error) {
+		metadata := TaskRunMetadata{}
+		err := metadata.Unmarshal([]byte(runMetadata))
+		if err != nil {
+			return "", err
+		}
+		metadata.Attempts++
+		metadata.LastFailedAttempt = time.Now().UTC()
+		attempts = metadata.Attempts
+		serializedMetadata, err := metadata.Marshal()
+		if err != nil {
+			return "", err
+		}
+		return string(serializedMetadata), nil
+	}
+	taskRunMetadataKey := TaskRunMetadataKey{taskID: taskID, runID: metadata.ID, date: metadata.StartTime}
+	if err := m.Storage.Update(taskRunMetadataKey.String(), incrementAttempts); err != nil {
+		return 0, err
+	}
+	return attempts, nil
+}
+
+// SetRunProgress records how far along a run is. The update time defaults to now.
+func (m *TaskMetadataManager) SetRunProgress(runID TaskRunID, taskID TaskID, progress RunProgress) error {
+	if progress.RowsProcessed < 0 || progress.TotalRows < 0 {
+		return fferr.NewInvalidArgumentErrorf("row counts can't be negative: %d of %d", progress.RowsProcessed, progress.TotalRows)
+	}
+	if progress.UpdatedAt.IsZero() {
+		progress.UpdatedAt = time.Now().UTC()
+	}
+	metadata, err := m.GetRunByID(taskID, runID)
+	if err != nil {
+		return err
+	}
+	updateProgress := func(runMetadata string) (string, error) {
+		metadata := TaskRunMetadata{}
+		err := metadata.Unmarshal([]byte(runMetadata))
+		if err != nil {
+			return "", err
+		}
+		metadata.Progress = &progress
+		serializedMetadata, err := metadata.Marshal()
+		if err != nil {
+			return "", err
+		}
+		return string(serializedMetadata), nil
+	}
+	taskRunMetadataKey := TaskRunMetadataKey{taskID: taskID, runID: metadata.ID, date: metadata.StartTime}
+	return m.Storage.Update(taskRunMetadataKey.String(), updateProgress)
+}
+
 func (m *TaskMetadataManager) SetRunEndTime(runID TaskRunID, taskID TaskID, time time.Time) error {
 	if time.IsZero() {
 		errMessage := fmt.Errorf("end time cannot be zero")
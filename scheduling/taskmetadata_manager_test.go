@@ -9,12 +9,14 @@ package scheduling
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"reflect"
 	"sync"
 	"testing"
 	"time"
 
+	"github.com/featureform/fferr"
 	"github.com/featureform/ffsync"
 	"github.com/featureform/logging"
 	"github.com/featureform/metadata/proto"
@@ -916,6 +918,91 @@ func TestSetResumeID(t *testing.T) {
 		})
 	}
 }
+func TestIncrementRunAttempts(t *testing.T) {
+	ctx := logging.NewTestContext(t)
+	manager, err := NewMemoryTaskMetadataManager(ctx)
+	if err != nil {
+		t.Fatalf("failed to create memory task metadata manager: %v", err)
+	}
+	task, err := manager.CreateTask(ctx, "name", ResourceCreation, NameVariant{"name", "variant", "type"})
+	if err != nil {
+		t.Fatalf("failed to create task: %v", err)
+	}
+	run, err := manager.CreateTaskRun(ctx, "name", task.ID, OnApplyTrigger{"name"})
+	if err != nil {
+		t.Fatalf("failed to create task run: %v", err)
+	}
+	if run.Attempts != 0 {
+		t.Fatalf("expected a new run to have 0 attempts, got: %d", run.Attempts)
+	}
+	for i := 1; i <= 3; i++ {
+		attempts, err := manager.IncrementRunAttempts(run.ID, task.ID)
+		if err != nil {
+			t.Fatalf("failed to increment run attempts: %v", err)
+		}
+		if attempts != i {
+			t.Fatalf("expected %d attempts, got: %d", i, attempts)
+		}
+	}
+	recvRun, err := manager.GetRunByID(task.ID, run.ID)
+	if err != nil {
+		t.Fatalf("failed to get run by ID %d: %v", run.ID, err)
+	}
+	if recvRun.Attempts != 3 {
+		t.Fatalf("expected 3 attempts, got: %d", recvRun.Attempts)
+	}
+	if recvRun.LastFailedAttempt.IsZero() {
+		t.Fatalf("expected the last failed attempt to be set")
+	}
+}
+
+func TestSetRunProgress(t *testing.T) {
+	ctx := logging.NewTestContext(t)
+	manager, err := NewMemoryTaskMetadataManager(ctx)
+	if err != nil {
+		t.Fatalf("failed to create memory task metadata manager: %v", err)
+	}
+	task, err := manager.CreateTask(ctx, "name", ResourceCreation, NameVariant{"name", "variant", "type"})
+	if err != nil {
+		t.Fatalf("failed to create task: %v", err)
+	}
+	run, err := manager.CreateTaskRun(ctx, "name", task.ID, OnApplyTrigger{"name"})
+	if err != nil {
+		t.Fatalf("failed to create task run: %v", err)
+	}
+	if run.Progress != nil {
+		t.Fatalf("expected a new run to have no progress, got: %v", run.Progress)
+	}
+	if err := manager.SetRunProgress(run.ID, task.ID, RunProgress{Phase: WritingPhase, RowsProcessed: -1}); err == nil {
+		t.Fatalf("expected an error setting a negative row count")
+	}
+	if err := manager.SetRunProgress(run.ID, task.ID, RunProgress{Phase: WritingPhase, RowsProcessed: 25, TotalRows: 100}); err != nil {
+		t.Fatalf("failed to set run progress: %v", err)
+	}
+	recvRun, err := manager.GetRunByID(task.ID, run.ID)
+	if err != nil {
+		t.Fatalf("failed to get run by ID %d: %v", run.ID, err)
+	}
+	progress := recvRun.Progress
+	if progress == nil || progress.Phase != WritingPhase || progress.RowsProcessed != 25 || progress.TotalRows != 100 {
+		t.Fatalf("expected 25 of 100 rows written, got: %v", progress)
+	}
+	if progress.UpdatedAt.IsZero() {
+		t.Fatalf("expected the update time to be set")
+	}
+	serialized, err := recvRun.ToProto()
+	if err != nil {
+		t.Fatalf("failed to convert run to proto: %v", err)
+	}
+	fromProto, err := TaskRunMetadataFromProto(serialized)
+	if err != nil {
+		t.Fatalf("failed to convert run from proto: %v", err)
+	}
+	if !reflect.DeepEqual(fromProto.Progress, progress) {
+		t.Fatalf("expected %v after a proto round trip, got: %v", progress, fromProto.Progress)
+	}
+}
+
 func TestSetEndTimeByRunID(t *testing.T) {
 	type taskInfo struct {
 		Name   string
@@ -1199,6 +1286,45 @@ func TestGetRunsByDate(t *testing.T) {
 	}
 }
 
+func TestTransformationCacheEntry(t *testing.T) {
+	ctx := logging.NewTestContext(t)
+	manager, err := NewMemoryTaskMetadataManager(ctx)
+	if err != nil {
+		t.Fatalf("failed to create memory task metadata manager: %v", err)
+	}
+	var notFound *fferr.KeyNotFoundError
+	if _, err := manager.GetTransformationCacheEntry("name", "variant"); !errors.As(err, &notFound) {
+		t.Fatalf("expected a key not found error for a missing entry, got: %v", err)
+	}
+	if err := manager.SetTransformationCacheEntry("name", "variant", ""); err == nil {
+		t.Fatalf("expected an error setting an empty hash")
+	}
+	for _, hash := range []string{"first", "second"} {
+		if err := manager.SetTransformationCacheEntry("name", "variant", hash); err != nil {
+			t.Fatalf("failed to set transformation cache entry: %v", err)
+		}
+		entry, err := manager.GetTransformationCacheEntry("name", "variant")
+		if err != nil {
+			t.Fatalf("failed to get transformation cache entry: %v", err)
+		}
+		if entry.Hash != hash || entry.CreatedAt.IsZero() {
+			t.Fatalf("expected hash %s with a creation time, got: %v", hash, entry)
+		}
+	}
+	if _, err := manager.GetTransformationCacheEntry("name", "other"); !errors.As(err, &notFound) {
+		t.Fatalf("expected entries to be separate per variant, got: %v", err)
+	}
+	if err := manager.DeleteTransformationCacheEntry("name", "variant"); err != nil {
+		t.Fatalf("failed to delete transformation cache entry: %v", err)
+	}
+	if _, err := manager.GetTransformationCacheEntry("name", "variant"); !errors.As(err, &notFound) {
+		t.Fatalf("expected the entry to be deleted, got: %v", err)
+	}
+	if err := manager.DeleteTransformationCacheEntry("name", "variant"); err != nil {
+		t.Fatalf("expected deleting a missing entry to succeed, got: %v", err)
+	}
+}
+
 func TestKeyPaths(t *testing.T) {
 	type testCase struct {
 		Name        string
@@ -1240,6 +1366,11 @@ func TestKeyPaths(t *testing.T) {
 			},
 			ExpectedKey: "/tasks/runs/metadata/2023/01/20/01/01/task_id=1/run_id=1",
 		},
+		{
+			Name:        "TransformationCacheKeyIndividual",
+			Key:         TransformationCacheKey{name: "name", variant: "variant"},
+			ExpectedKey: "/tasks/transformation_cache/name=name/variant=variant",
+		},
 		{
 			Name: "TaskRunMetadataKeyYearOnly",
 			Key: TaskRunMetadataKey{
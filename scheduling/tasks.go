@@ -72,8 +72,9 @@ func (tt TaskType) Proto() schpb.TaskType {
 type TargetType int32
 
 const (
-	ProviderTarget    TargetType = TargetType(schpb.TargetType_PROVIDER)
-	NameVariantTarget TargetType = TargetType(schpb.TargetType_NAME_VARIANT)
+	ProviderTarget      TargetType = TargetType(schpb.TargetType_PROVIDER)
+	NameVariantTarget   TargetType = TargetType(schpb.TargetType_NAME_VARIANT)
+	SourceProfileTarget TargetType = TargetType(schpb.TargetType_SOURCE_PROFILE)
 )
 
 func (tt TargetType) String() string {
@@ -116,6 +117,25 @@ func (nv NameVariant) FailedError() error {
 	return err
 }
 
+// SourceProfile profiles the columns of a source variant, only reading the
+// first SampleSize rows if it's set.
+type SourceProfile struct {
+	Name       string `json:"name"`
+	Variant    string `json:"variant"`
+	SampleSize int64  `json:"sampleSize"`
+}
+
+func (sp SourceProfile) Type() TargetType {
+	return SourceProfileTarget
+}
+
+func (sp SourceProfile) FailedError() error {
+	err := fferr.NewDependencyFailedErrorf("dependent SourceProfile task failed")
+	err.AddDetail("Name", sp.Name)
+	err.AddDetail("Variant", sp.Variant)
+	return err
+}
+
 type TaskTarget interface {
 	Type() TargetType
 	FailedError() error
@@ -199,6 +219,13 @@ func (t *TaskMetadata) Unmarshal(data []byte) error {
 			return fferr.NewInternalError(errMessage)
 		}
 		t.Target = nameVariant
+	case SourceProfileTarget:
+		var sourceProfile SourceProfile
+		if err := json.Unmarshal(temp.Target, &sourceProfile); err != nil {
+			errMessage := fmt.Errorf("failed to deserialize SourceProfile data: %w", err)
+			return fferr.NewInternalError(errMessage)
+		}
+		t.Target = sourceProfile
 	default:
 		err := fferr.NewInvalidArgumentError(fmt.Errorf("unknown target type"))
 		err.AddDetail("TargetType", string(temp.TargetType))
@@ -257,6 +284,12 @@ func convertTaskProtoTarget(target interface{}) (TaskTarget, error) {
 		return Provider{
 			Name: t.Provider.Name,
 		}, nil
+	case *schpb.TaskMetadata_SourceProfile:
+		return SourceProfile{
+			Name:       t.SourceProfile.Source.GetName(),
+			Variant:    t.SourceProfile.Source.GetVariant(),
+			SampleSize: t.SourceProfile.SampleSize,
+		}, nil
 	default:
 		return nil, fferr.NewUnimplementedErrorf("could not convert target proto type: %T", target)
 	}
@@ -284,12 +317,24 @@ func getProviderTargetProto(target Provider) *schpb.TaskMetadata_Provider {
 	}
 }
 
+func getSourceProfileTargetProto(target SourceProfile) *schpb.SourceProfileTarget {
+	return &schpb.SourceProfileTarget{
+		Source: &metapb.NameVariant{
+			Name:    target.Name,
+			Variant: target.Variant,
+		},
+		SampleSize: target.SampleSize,
+	}
+}
+
 func setTaskMetadataTargetProto(proto *schpb.TaskMetadata, target TaskTarget) (*schpb.TaskMetadata, error) {
 	switch t := target.(type) {
 	case NameVariant:
 		proto.Target = getTaskNameVariantTargetProto(t)
 	case Provider:
 		proto.Target = getProviderTargetProto(t)
+	case SourceProfile:
+		proto.Target = &schpb.TaskMetadata_SourceProfile{SourceProfile: getSourceProfileTargetProto(t)}
 	default:
 		return nil, fferr.NewUnimplementedErrorf("could not convert target to proto: type: %T", target)
 	}
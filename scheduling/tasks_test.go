@@ -52,6 +52,22 @@ func TestSerializeTaskMetadata(t *testing.T) {
 			},
 			targettype: NameVariantTarget,
 		},
+		{
+			name: "WithSourceProfileTarget",
+			task: TaskMetadata{
+				ID:       TaskID(id1),
+				Name:     "profile_task",
+				TaskType: Monitoring,
+				Target: SourceProfile{
+					Name:       "transaction",
+					Variant:    "default",
+					SampleSize: 100,
+				},
+				TargetType:  SourceProfileTarget,
+				DateCreated: time.Now().Truncate(0).UTC(),
+			},
+			targettype: SourceProfileTarget,
+		},
 	}
 
 	for _, currTest := range testCases {
@@ -265,6 +281,22 @@ func Test_wrapTaskMetadataProto(t *testing.T) {
 			},
 			false,
 		},
+		{
+			"Source Profile",
+			TaskMetadata{
+				ID:         TaskID(id),
+				Name:       "Some Name",
+				TaskType:   Monitoring,
+				TargetType: SourceProfileTarget,
+				Target: SourceProfile{
+					Name:       "name",
+					Variant:    "variant",
+					SampleSize: 100,
+				},
+				DateCreated: time.Now().UTC(),
+			},
+			false,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -0,0 +1,95 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+//
+// Copyright 2024 FeatureForm Inc.
+//
+
+package scheduling
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/featureform/fferr"
+)
+
+const transformationCacheKeyPrefix = "/tasks/transformation_cache/"
+
+type TransformationCacheKey struct {
+	name    string
+	variant string
+}
+
+func (tck TransformationCacheKey) String() string {
+	if tck.name == "" {
+		return transformationCacheKeyPrefix
+	}
+	return fmt.Sprintf("%sname=%s/variant=%s", transformationCacheKeyPrefix, tck.name, tck.variant)
+}
+
+// TransformationCacheEntry is the hash of the inputs that the current result
+// of a transformation was built from. A run whose inputs hash to the same
+// value can reuse the result instead of recomputing it.
+type TransformationCacheEntry struct {
+	Hash      string    `json:"hash"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+func (e *TransformationCacheEntry) Marshal() ([]byte, error) {
+	bytes, err := json.Marshal(e)
+	if err != nil {
+		errMessage := fmt.Errorf("failed to serialize transformation cache entry: %w", err)
+		return nil, fferr.NewInternalError(errMessage)
+	}
+	return bytes, nil
+}
+
+func (e *TransformationCacheEntry) Unmarshal(data []byte) error {
+	if err := json.Unmarshal(data, e); err != nil {
+		errMessage := fmt.Errorf("failed to deserialize transformation cache entry: %w", err)
+		return fferr.NewInternalError(errMessage)
+	}
+	if e.Hash == "" {
+		return fferr.NewInvalidArgumentError(fmt.Errorf("transformation cache entry is missing hash"))
+	}
+	return nil
+}
+
+// SetTransformationCacheEntry records the hash of the inputs that the
+// transformation's current result was built from.
+func (m *TaskMetadataManager) SetTransformationCacheEntry(name, variant, hash string) error {
+	if hash == "" {
+		return fferr.NewInvalidArgumentError(fmt.Errorf("transformation cache hash cannot be empty"))
+	}
+	entry := TransformationCacheEntry{Hash: hash, CreatedAt: time.Now().UTC()}
+	serialized, err := entry.Marshal()
+	if err != nil {
+		return err
+	}
+	return m.Storage.Create(TransformationCacheKey{name: name, variant: variant}.String(), string(serialized))
+}
+
+func (m *TaskMetadataManager) GetTransformationCacheEntry(name, variant string) (TransformationCacheEntry, error) {
+	rec, err := m.Storage.Get(TransformationCacheKey{name: name, variant: variant}.String())
+	if err != nil {
+		return TransformationCacheEntry{}, err
+	}
+	entry := TransformationCacheEntry{}
+	if err := entry.Unmarshal([]byte(rec)); err != nil {
+		return TransformationCacheEntry{}, err
+	}
+	return entry, nil
+}
+
+// DeleteTransformationCacheEntry removes the transformation's entry, if it has one.
+func (m *TaskMetadataManager) DeleteTransformationCacheEntry(name, variant string) error {
+	_, err := m.Storage.Delete(TransformationCacheKey{name: name, variant: variant}.String())
+	var notFound *fferr.KeyNotFoundError
+	if errors.As(err, &notFound) {
+		return nil
+	}
+	return err
+}
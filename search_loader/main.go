@@ -33,23 +33,13 @@ func main() {
 
 	metadataHost := helpers.GetEnv("METADATA_HOST", "localhost")
 	metadataPort := helpers.GetEnv("METADATA_PORT", "8080")
-	searchHost := helpers.GetEnv("MEILISEARCH_HOST", "localhost")
-	searchPort := helpers.GetEnv("MEILISEARCH_PORT", "7700")
-	searchApiKey := helpers.GetEnv("MEILISEARCH_APIKEY", "")
 
 	logger.Infof("METADATA_HOST:", metadataHost)
 	logger.Infof("METADATA_PORT:", metadataPort)
-	logger.Infof("MEILISEARCH_HOST:", searchHost)
-	logger.Infof("MEILISEARCH_PORT:", searchPort)
-	logger.Infof("MEILISEARCH_APIKEY:", searchApiKey)
 
-	searcher, err := search.NewMeilisearch(&search.MeilisearchParams{
-		Host:   searchHost,
-		Port:   searchPort,
-		ApiKey: searchApiKey,
-	})
+	searcher, err := search.NewSearcherFromEnv()
 	if err != nil {
-		logger.Panicw("Failed to create meilisearch connection", err)
+		logger.Panicw("Failed to connect to search", "error", err)
 	}
 
 	metadataAddress := fmt.Sprintf("%s:%s", metadataHost, metadataPort)
@@ -106,8 +96,7 @@ func fetchAndUpload(up Upload) {
 				return
 			}
 			for _, variant := range variants {
-				doc := getDocument(variant.Name(), variant.Variant(), up.resourceType.String(), variant.Tags())
-				up.searcher.Upsert(doc)
+				upsertVariant(up, variant.Name(), variant.Variant(), variant.Tags(), variant.IsArchived())
 			}
 		}
 	case metadata.FEATURE:
@@ -124,8 +113,7 @@ func fetchAndUpload(up Upload) {
 				return
 			}
 			for _, variant := range variants {
-				doc := getDocument(variant.Name(), variant.Variant(), up.resourceType.String(), variant.Tags())
-				up.searcher.Upsert(doc)
+				upsertVariant(up, variant.Name(), variant.Variant(), variant.Tags(), variant.IsArchived())
 			}
 		}
 	case metadata.ENTITY:
@@ -153,8 +141,7 @@ func fetchAndUpload(up Upload) {
 				return
 			}
 			for _, variant := range variants {
-				doc := getDocument(variant.Name(), variant.Variant(), up.resourceType.String(), variant.Tags())
-				up.searcher.Upsert(doc)
+				upsertVariant(up, variant.Name(), variant.Variant(), variant.Tags(), variant.IsArchived())
 			}
 		}
 	case metadata.MODEL:
@@ -182,8 +169,7 @@ func fetchAndUpload(up Upload) {
 				return
 			}
 			for _, variant := range variants {
-				doc := getDocument(variant.Name(), variant.Variant(), up.resourceType.String(), variant.Tags())
-				up.searcher.Upsert(doc)
+				upsertVariant(up, variant.Name(), variant.Variant(), variant.Tags(), variant.IsArchived())
 			}
 		}
 	case metadata.PROVIDER:
@@ -203,6 +189,20 @@ func fetchAndUpload(up Upload) {
 	} //switch
 }
 
+// upsertVariant indexes a variant unless it's archived. Archived variants are
+// hidden from search, so a document left from before one was archived is
+// removed instead.
+func upsertVariant(up Upload, name, variant string, tags metadata.Tags, archived bool) {
+	doc := getDocument(name, variant, up.resourceType.String(), tags)
+	if archived {
+		if err := up.searcher.Delete(search.DocumentID(doc)); err != nil {
+			up.logger.Errorw("failed to remove archived variant from search", "name", name, "variant", variant, "error", err)
+		}
+		return
+	}
+	up.searcher.Upsert(doc)
+}
+
 func getDocument(name, variant, resType string, tags metadata.Tags) search.ResourceDoc {
 	doc := search.ResourceDoc{
 		Name:    name,
@@ -0,0 +1,62 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+//
+// Copyright 2024 FeatureForm Inc.
+//
+
+package serving
+
+import (
+	"context"
+
+	"github.com/featureform/fferr"
+	"github.com/featureform/filestore"
+	pb "github.com/featureform/proto"
+	"github.com/featureform/provider"
+	pl "github.com/featureform/provider/location"
+)
+
+// ExportTrainingSet writes a training set to a directory in a file store, so it
+// can be downloaded as files rather than streamed row by row.
+func (serv *FeatureServer) ExportTrainingSet(ctx context.Context, req *pb.ExportTrainingSetRequest) (*pb.ExportTrainingSetResponse, error) {
+	name, variant := req.GetId().GetName(), req.GetId().GetVersion()
+	logger := serv.Logger.With("Name", name, "Variant", variant, "Location", req.GetLocation())
+	logger.Info("Exporting training set")
+	format, err := exportFormat(req.GetFormat())
+	if err != nil {
+		return nil, err
+	}
+	dir, err := filestore.NewDirpathFromURI(req.GetLocation())
+	if err != nil {
+		logger.Errorw("Invalid export location", "error", err)
+		return nil, err
+	}
+	_, store, err := serv.getOfflineResource(ctx, name, variant, int32(provider.TrainingSet))
+	if err != nil {
+		return nil, err
+	}
+	id := provider.ResourceID{Name: name, Variant: variant, Type: provider.TrainingSet}
+	files, err := store.ExportTrainingSet(id, pl.NewFileLocation(dir), format)
+	if err != nil {
+		logger.Errorw("Failed to export training set", "error", err)
+		return nil, err
+	}
+	resp := &pb.ExportTrainingSetResponse{Files: make([]string, len(files))}
+	for i, file := range files {
+		resp.Files[i] = file.ToURI()
+	}
+	logger.Infow("Exported training set", "files", len(files))
+	return resp, nil
+}
+
+func exportFormat(format string) (filestore.FileType, error) {
+	switch filestore.FileType(format) {
+	case filestore.NilFileType, filestore.Parquet:
+		return filestore.Parquet, nil
+	case filestore.CSV:
+		return filestore.CSV, nil
+	default:
+		return filestore.NilFileType, fferr.NewInvalidArgumentErrorf("training sets can be exported as parquet or csv, got %s", format)
+	}
+}
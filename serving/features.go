@@ -9,6 +9,7 @@ package serving
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"sort"
 
@@ -25,6 +26,21 @@ type indexedValue struct {
 	value interface{}
 }
 
+// missingValue is served for entities without a value in the online store. A
+// nil missingValue serves an entity not found error instead.
+type missingValue struct {
+	value interface{}
+}
+
+// orMissing replaces an entity not found error with the missing value.
+func (missing *missingValue) orMissing(val interface{}, err error) (interface{}, error) {
+	var notFound *fferr.EntityNotFoundError
+	if missing != nil && errors.As(err, &notFound) {
+		return missing.value, nil
+	}
+	return val, err
+}
+
 type indexedFeatureRow struct {
 	index  int
 	values *pb.ValueList
@@ -106,10 +122,17 @@ func (serv *FeatureServer) getFeatureValues(ctx context.Context, name, variant s
 	if err != nil {
 		return nil, err
 	}
+	return serv.getMetadataFeatureValues(ctx, meta, entityMap)
+}
 
+// getMetadataFeatureValues gets the values of an already fetched feature. The context
+// must hold the feature's observer.
+func (serv *FeatureServer) getMetadataFeatureValues(ctx context.Context, meta *metadata.FeatureVariant, entityMap map[string][]string) (*pb.ValueList, error) {
+	name, variant := meta.Name(), meta.Variant()
 	var values []interface{}
 	switch meta.Mode() {
-	case metadata.PRECOMPUTED:
+	// Streaming features are written to the online store by WriteFeatures.
+	case metadata.PRECOMPUTED, metadata.STREAMING:
 		if meta.Provider() == "" {
 			return nil, fferr.NewInvalidArgumentError(fmt.Errorf("feature %s:%s is not saved in an inference store", name, variant))
 		}
@@ -174,7 +197,18 @@ func (serv *FeatureServer) getPrecomputedValues(ctx context.Context, entityMap m
 		return nil, err
 	}
 
-	featureValues, err := serv.getEntityValues(ctx, entities, featureTable)
+	var missing *missingValue
+	if meta.HasDefaultValue() {
+		defaultValue, err := meta.DefaultValue()
+		if err != nil {
+			logger.Errorw("Invalid default value", "Error", err)
+			obs.SetError()
+			return nil, err
+		}
+		missing = &missingValue{defaultValue}
+	}
+
+	featureValues, err := serv.getEntityValues(ctx, entities, featureTable, missing)
 	if err != nil {
 		return nil, err
 	}
@@ -240,16 +274,37 @@ func (serv *FeatureServer) cacheFeatureTable(ctx context.Context, store provider
 	return featureTable, nil
 }
 
-func (serv *FeatureServer) getEntityValues(ctx context.Context, entities []string, featureTable provider.OnlineStoreTable) ([]indexedValue, error) {
+func (serv *FeatureServer) getEntityValues(ctx context.Context, entities []string, featureTable provider.OnlineStoreTable, missing *missingValue) ([]indexedValue, error) {
 	obs := ctx.Value(observer{}).(metrics.FeatureObserver)
 
+	// Prefer the store's native multi-get over one request per entity
+	if batchTable, ok := featureTable.(provider.BatchGetOnlineTable); ok {
+		values, err := batchTable.BatchGet(entities)
+		var notFound *fferr.EntityNotFoundError
+		switch {
+		case missing != nil && errors.As(err, &notFound):
+			// A batch fails as a whole, so get each entity to find the missing ones.
+			serv.Logger.Debugw("batch get missed an entity, getting entities individually", "Error", err)
+		case err != nil:
+			serv.Logger.Errorw("batch get failed", "Error", err)
+			obs.SetError()
+			return nil, err
+		default:
+			results := make([]indexedValue, len(values))
+			for i, val := range values {
+				results[i] = indexedValue{index: i, value: val}
+			}
+			return results, nil
+		}
+	}
+
 	valCh := make(chan indexedValue, len(entities))
 	errCh := make(chan error, len(entities))
 
 	for i, entityVal := range entities {
 		// Start a goroutine for each entity
 		go func(index int, ev string) {
-			val, err := featureTable.Get(ev)
+			val, err := missing.orMissing(featureTable.Get(ev))
 			if err != nil {
 				// Push error into the error channel
 				errCh <- err
@@ -0,0 +1,386 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+//
+// Copyright 2024 FeatureForm Inc.
+//
+
+package serving
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/apache/arrow/go/v17/arrow"
+	"github.com/apache/arrow/go/v17/arrow/array"
+	"github.com/apache/arrow/go/v17/arrow/flight"
+	"github.com/apache/arrow/go/v17/arrow/ipc"
+	"github.com/apache/arrow/go/v17/arrow/memory"
+	"github.com/featureform/fferr"
+	"github.com/featureform/metadata"
+	"github.com/featureform/provider/types"
+)
+
+// GetTrainingSetCommand is the ticket command that streams a training set.
+const GetTrainingSetCommand = "GetTrainingSet"
+
+// FlightServer serves training sets over Arrow Flight. Rows are streamed as
+// Arrow record batches of DataBatchSize rows, which is much cheaper for
+// columnar clients to consume than the row-by-row TrainingData stream.
+type FlightServer struct {
+	flight.BaseFlightServer
+	serv *FeatureServer
+}
+
+func NewFlightServer(serv *FeatureServer) *FlightServer {
+	return &FlightServer{serv: serv}
+}
+
+// FlightTicket is the JSON body of a ticket passed to DoGet.
+type FlightTicket struct {
+	Command string `json:"command"`
+	Name    string `json:"name"`
+	Variant string `json:"variant"`
+	// Model is optionally registered as a consumer of the training set.
+	Model string `json:"model,omitempty"`
+	// Split optionally limits the rows to the train or test split.
+	Split string `json:"split,omitempty"`
+}
+
+func parseFlightTicket(ticket *flight.Ticket) (FlightTicket, error) {
+	parsed := FlightTicket{}
+	if err := json.Unmarshal(ticket.GetTicket(), &parsed); err != nil {
+		return parsed, fferr.NewInvalidArgumentErrorf("could not parse Flight ticket: %v", err)
+	}
+	if parsed.Command != GetTrainingSetCommand {
+		return parsed, fferr.NewInvalidArgumentErrorf("unsupported Flight command '%s'; supported commands: %s", parsed.Command, GetTrainingSetCommand)
+	}
+	if parsed.Name == "" || parsed.Variant == "" {
+		return parsed, fferr.NewInvalidArgumentErrorf("Flight ticket requires a name and variant")
+	}
+	return parsed, nil
+}
+
+// DoGet streams the training set named in the ticket as Arrow record batches.
+// The columns match TrainingDataColumns, with the label last.
+func (fs *FlightServer) DoGet(ticket *flight.Ticket, stream flight.FlightService_DoGetServer) error {
+	parsed, err := parseFlightTicket(ticket)
+	if err != nil {
+		return err
+	}
+	name, variant := parsed.Name, parsed.Variant
+	featureObserver := fs.serv.Metrics.BeginObservingTrainingServe(name, variant)
+	defer featureObserver.Finish()
+	logger := fs.serv.Logger.With("Name", name, "Variant", variant)
+	logger.Info("Serving training data over Flight")
+	ctx := stream.Context()
+	if parsed.Model != "" {
+		trainingSets := []metadata.NameVariant{{Name: name, Variant: variant}}
+		if err := fs.serv.Metadata.CreateModel(ctx, metadata.ModelDef{Name: parsed.Model, Trainingsets: trainingSets}); err != nil {
+			return err
+		}
+	}
+	schema, err := fs.trainingSetSchema(ctx, name, variant)
+	if err != nil {
+		logger.Errorw("Failed to derive training set schema", "Error", err)
+		featureObserver.SetError()
+		return err
+	}
+	iter, err := fs.serv.getTrainingSetIterator(name, variant, parsed.Split)
+	if err != nil {
+		logger.Errorw("Failed to get training set iterator", "Error", err)
+		featureObserver.SetError()
+		return err
+	}
+	writer := flight.NewRecordWriter(stream, ipc.WithSchema(schema))
+	defer writer.Close()
+	builder := array.NewRecordBuilder(memory.DefaultAllocator, schema)
+	defer builder.Release()
+	labelIdx := len(schema.Fields()) - 1
+	bufRows := 0
+	flush := func() error {
+		record := builder.NewRecord()
+		defer record.Release()
+		if err := writer.Write(record); err != nil {
+			logger.Errorw("Failed to write to Flight stream", "Error", err)
+			return fferr.NewInternalError(err)
+		}
+		bufRows = 0
+		return nil
+	}
+	for iter.Next() {
+		features := iter.Features()
+		if len(features) != labelIdx {
+			featureObserver.SetError()
+			return fferr.NewInternalErrorf("training set row has %d features but the schema has %d", len(features), labelIdx)
+		}
+		for i, feature := range features {
+			if err := appendArrowValue(builder.Field(i), feature); err != nil {
+				logger.Errorw("Failed to serialize row", "Error", err)
+				featureObserver.SetError()
+				return err
+			}
+		}
+		if err := appendArrowValue(builder.Field(labelIdx), iter.Label()); err != nil {
+			logger.Errorw("Failed to serialize row", "Error", err)
+			featureObserver.SetError()
+			return err
+		}
+		featureObserver.ServeRow()
+		bufRows++
+		if bufRows == DataBatchSize {
+			if err := flush(); err != nil {
+				featureObserver.SetError()
+				return err
+			}
+		}
+	}
+	if bufRows != 0 {
+		if err := flush(); err != nil {
+			featureObserver.SetError()
+			return err
+		}
+	}
+	if err := iter.Err(); err != nil {
+		logger.Errorw("Training set iterator error", "Error", err)
+		featureObserver.SetError()
+		return err
+	}
+	return nil
+}
+
+// trainingSetSchema derives the Arrow schema of a training set from the value
+// types of its features and label.
+func (fs *FlightServer) trainingSetSchema(ctx context.Context, name, variant string) (*arrow.Schema, error) {
+	ts, err := fs.serv.Metadata.GetTrainingSetVariant(ctx, metadata.NameVariant{Name: name, Variant: variant})
+	if err != nil {
+		return nil, err
+	}
+	featureIDs := ts.Features()
+	variants, err := fs.serv.Metadata.GetFeatureVariants(ctx, featureIDs)
+	if err != nil {
+		return nil, err
+	}
+	// The columns have to follow the training set's feature order, which the
+	// iterator's rows are in.
+	byID := make(map[metadata.NameVariant]*metadata.FeatureVariant, len(variants))
+	for _, fv := range variants {
+		byID[metadata.NameVariant{Name: fv.Name(), Variant: fv.Variant()}] = fv
+	}
+	features := make([]*metadata.FeatureVariant, len(featureIDs))
+	for i, id := range featureIDs {
+		feature, has := byID[id]
+		if !has {
+			return nil, fferr.NewInternalErrorf("feature %s (%s) of training set %s (%s) not found", id.Name, id.Variant, name, variant)
+		}
+		features[i] = feature
+	}
+	label, err := fs.serv.Metadata.GetLabelVariant(ctx, ts.Label())
+	if err != nil {
+		return nil, err
+	}
+	fields := make([]arrow.Field, 0, len(features)+1)
+	for _, feature := range features {
+		valueType, err := feature.Type()
+		if err != nil {
+			return nil, err
+		}
+		fields = append(fields, arrow.Field{
+			Name:     fmt.Sprintf("feature__%s__%s", feature.Name(), feature.Variant()),
+			Type:     arrowType(valueType),
+			Nullable: true,
+		})
+	}
+	labelType, err := label.Type()
+	if err != nil {
+		return nil, err
+	}
+	fields = append(fields, arrow.Field{
+		Name:     fmt.Sprintf("label__%s__%s", label.Name(), label.Variant()),
+		Type:     arrowType(labelType),
+		Nullable: true,
+	})
+	return arrow.NewSchema(fields, nil), nil
+}
+
+// arrowType maps a value type to its Arrow type. Vectors become lists of their
+// scalar type, and values without a type are sent as strings.
+func arrowType(valueType types.ValueType) arrow.DataType {
+	scalar := arrowScalarType(valueType.Scalar())
+	if valueType.IsVector() {
+		return arrow.ListOf(scalar)
+	}
+	return scalar
+}
+
+func arrowScalarType(scalar types.ScalarType) arrow.DataType {
+	switch scalar {
+	case types.Int, types.Int64:
+		return arrow.PrimitiveTypes.Int64
+	case types.Int8:
+		return arrow.PrimitiveTypes.Int8
+	case types.Int16:
+		return arrow.PrimitiveTypes.Int16
+	case types.Int32:
+		return arrow.PrimitiveTypes.Int32
+	case types.UInt8:
+		return arrow.PrimitiveTypes.Uint8
+	case types.UInt16:
+		return arrow.PrimitiveTypes.Uint16
+	case types.UInt32:
+		return arrow.PrimitiveTypes.Uint32
+	case types.UInt64:
+		return arrow.PrimitiveTypes.Uint64
+	case types.Float32:
+		return arrow.PrimitiveTypes.Float32
+	case types.Float64:
+		return arrow.PrimitiveTypes.Float64
+	case types.Bool:
+		return arrow.FixedWidthTypes.Boolean
+	case types.Timestamp, types.Datetime:
+		return arrow.FixedWidthTypes.Timestamp_us
+	default:
+		return arrow.BinaryTypes.String
+	}
+}
+
+// appendArrowValue appends a training set value to the builder of its column,
+// converting between numeric types as needed.
+func appendArrowValue(builder array.Builder, value interface{}) error {
+	if value == nil {
+		builder.AppendNull()
+		return nil
+	}
+	var ok bool
+	switch b := builder.(type) {
+	case *array.Int64Builder:
+		var v int64
+		if v, ok = arrowInt(value); ok {
+			b.Append(v)
+		}
+	case *array.Int8Builder:
+		var v int64
+		if v, ok = arrowInt(value); ok {
+			b.Append(int8(v))
+		}
+	case *array.Int16Builder:
+		var v int64
+		if v, ok = arrowInt(value); ok {
+			b.Append(int16(v))
+		}
+	case *array.Int32Builder:
+		var v int64
+		if v, ok = arrowInt(value); ok {
+			b.Append(int32(v))
+		}
+	case *array.Uint8Builder:
+		var v int64
+		if v, ok = arrowInt(value); ok {
+			b.Append(uint8(v))
+		}
+	case *array.Uint16Builder:
+		var v int64
+		if v, ok = arrowInt(value); ok {
+			b.Append(uint16(v))
+		}
+	case *array.Uint32Builder:
+		var v int64
+		if v, ok = arrowInt(value); ok {
+			b.Append(uint32(v))
+		}
+	case *array.Uint64Builder:
+		var v int64
+		if v, ok = arrowInt(value); ok {
+			b.Append(uint64(v))
+		}
+	case *array.Float32Builder:
+		var v float64
+		if v, ok = arrowFloat(value); ok {
+			b.Append(float32(v))
+		}
+	case *array.Float64Builder:
+		var v float64
+		if v, ok = arrowFloat(value); ok {
+			b.Append(v)
+		}
+	case *array.BooleanBuilder:
+		var v bool
+		if v, ok = value.(bool); ok {
+			b.Append(v)
+		}
+	case *array.TimestampBuilder:
+		var v time.Time
+		if v, ok = value.(time.Time); ok {
+			b.Append(arrow.Timestamp(v.UnixMicro()))
+		}
+	case *array.StringBuilder:
+		ok = true
+		switch v := value.(type) {
+		case string:
+			b.Append(v)
+		case time.Time:
+			b.Append(v.Format(time.RFC3339))
+		default:
+			b.Append(fmt.Sprint(v))
+		}
+	case *array.ListBuilder:
+		items, isVec := value.([]float32)
+		if !isVec {
+			break
+		}
+		ok = true
+		b.Append(true)
+		for _, item := range items {
+			if err := appendArrowValue(b.ValueBuilder(), item); err != nil {
+				return err
+			}
+		}
+	default:
+		return fferr.NewInternalErrorf("unsupported Arrow builder %T", builder)
+	}
+	if !ok {
+		return fferr.NewDataTypeNotFoundError(fmt.Sprintf("%T", value), fmt.Errorf("cannot write %v as Arrow %s", value, builder.Type()))
+	}
+	return nil
+}
+
+func arrowInt(value interface{}) (int64, bool) {
+	switch v := value.(type) {
+	case int:
+		return int64(v), true
+	case int8:
+		return int64(v), true
+	case int16:
+		return int64(v), true
+	case int32:
+		return int64(v), true
+	case int64:
+		return v, true
+	case uint8:
+		return int64(v), true
+	case uint16:
+		return int64(v), true
+	case uint32:
+		return int64(v), true
+	case uint64:
+		return int64(v), true
+	default:
+		return 0, false
+	}
+}
+
+func arrowFloat(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case float32:
+		return float64(v), true
+	case float64:
+		return v, true
+	default:
+		if i, ok := arrowInt(value); ok {
+			return float64(i), true
+		}
+		return 0, false
+	}
+}
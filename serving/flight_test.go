@@ -0,0 +1,203 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+//
+// Copyright 2024 FeatureForm Inc.
+//
+
+package serving
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/apache/arrow/go/v17/arrow"
+	"github.com/apache/arrow/go/v17/arrow/array"
+	"github.com/apache/arrow/go/v17/arrow/flight"
+	"github.com/apache/arrow/go/v17/arrow/memory"
+	"github.com/featureform/provider/types"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+func startFlightServer(t *testing.T, serv *FeatureServer) flight.Client {
+	t.Helper()
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %s", err)
+	}
+	grpcServer := grpc.NewServer()
+	flight.RegisterFlightServiceServer(grpcServer, NewFlightServer(serv))
+	go grpcServer.Serve(lis)
+	t.Cleanup(grpcServer.Stop)
+	client, err := flight.NewClientWithMiddleware(lis.Addr().String(), nil, nil, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatalf("Failed to create Flight client: %s", err)
+	}
+	t.Cleanup(func() { client.Close() })
+	return client
+}
+
+func flightTicket(t *testing.T, ticket FlightTicket) *flight.Ticket {
+	t.Helper()
+	data, err := json.Marshal(ticket)
+	if err != nil {
+		t.Fatalf("Failed to marshal ticket: %s", err)
+	}
+	return &flight.Ticket{Ticket: data}
+}
+
+func TestFlightTrainingSetServe(t *testing.T) {
+	ctx := onlineTestContext{
+		ResourceDefsFn: simpleResourceDefsFn,
+		FactoryFn:      createMockOfflineStoreFactory(simpleFeatureRecords(), simpleTrainingSetDefs()),
+	}
+	serv := ctx.Create(t)
+	defer ctx.Destroy()
+	client := startFlightServer(t, serv)
+	stream, err := client.DoGet(context.Background(), flightTicket(t, FlightTicket{
+		Command: GetTrainingSetCommand,
+		Name:    "training-set",
+		Variant: "variant",
+	}))
+	if err != nil {
+		t.Fatalf("Failed to call DoGet: %s", err)
+	}
+	reader, err := flight.NewRecordReader(stream)
+	if err != nil {
+		t.Fatalf("Failed to read Flight stream: %s", err)
+	}
+	defer reader.Release()
+	expectedFields := []string{"feature__feature__variant", "label__label__variant"}
+	fields := make([]string, 0)
+	for _, field := range reader.Schema().Fields() {
+		fields = append(fields, field.Name)
+	}
+	if !reflect.DeepEqual(expectedFields, fields) {
+		t.Fatalf("Fields aren't equal: %v\n%v", expectedFields, fields)
+	}
+	type Row struct {
+		Feature string
+		Label   string
+	}
+	// We use a map since the order is not guaranteed.
+	expectedRows := map[Row]bool{
+		{"12.5", "true"}: true,
+		{"def", "false"}: true,
+	}
+	actualRows := make(map[Row]bool)
+	for reader.Next() {
+		record := reader.Record()
+		features := record.Column(0).(*array.String)
+		labels := record.Column(1).(*array.String)
+		for i := 0; i < int(record.NumRows()); i++ {
+			actualRows[Row{Feature: features.Value(i), Label: labels.Value(i)}] = true
+		}
+	}
+	if err := reader.Err(); err != nil {
+		t.Fatalf("Failed to read records: %s", err)
+	}
+	if !reflect.DeepEqual(expectedRows, actualRows) {
+		t.Fatalf("Rows arent equal: %v\n%v", expectedRows, actualRows)
+	}
+}
+
+func TestFlightInvalidTicket(t *testing.T) {
+	ctx := onlineTestContext{
+		ResourceDefsFn: simpleResourceDefsFn,
+		FactoryFn:      createMockOfflineStoreFactory(simpleFeatureRecords(), simpleTrainingSetDefs()),
+	}
+	serv := ctx.Create(t)
+	defer ctx.Destroy()
+	client := startFlightServer(t, serv)
+	tickets := map[string]*flight.Ticket{
+		"not json":        {Ticket: []byte("training-set")},
+		"unknown command": flightTicket(t, FlightTicket{Command: "GetSource", Name: "training-set", Variant: "variant"}),
+		"missing variant": flightTicket(t, FlightTicket{Command: GetTrainingSetCommand, Name: "training-set"}),
+	}
+	for name, ticket := range tickets {
+		t.Run(name, func(t *testing.T) {
+			stream, err := client.DoGet(context.Background(), ticket)
+			if err != nil {
+				return
+			}
+			if _, err := stream.Recv(); err == nil {
+				t.Fatalf("Expected DoGet to fail")
+			}
+		})
+	}
+}
+
+func TestArrowType(t *testing.T) {
+	tests := map[string]struct {
+		ValueType types.ValueType
+		Expected  arrow.DataType
+	}{
+		"int":       {types.Int, arrow.PrimitiveTypes.Int64},
+		"int32":     {types.Int32, arrow.PrimitiveTypes.Int32},
+		"float32":   {types.Float32, arrow.PrimitiveTypes.Float32},
+		"float64":   {types.Float64, arrow.PrimitiveTypes.Float64},
+		"string":    {types.String, arrow.BinaryTypes.String},
+		"bool":      {types.Bool, arrow.FixedWidthTypes.Boolean},
+		"timestamp": {types.Timestamp, arrow.FixedWidthTypes.Timestamp_us},
+		"untyped":   {types.NilType, arrow.BinaryTypes.String},
+		"vector":    {types.VectorType{ScalarType: types.Float32, Dimension: 3}, arrow.ListOf(arrow.PrimitiveTypes.Float32)},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			if got := arrowType(test.ValueType); !arrow.TypeEqual(got, test.Expected) {
+				t.Fatalf("Expected %s, got %s", test.Expected, got)
+			}
+		})
+	}
+}
+
+func TestAppendArrowValue(t *testing.T) {
+	ts := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	schema := arrow.NewSchema([]arrow.Field{
+		{Name: "int", Type: arrow.PrimitiveTypes.Int64, Nullable: true},
+		{Name: "float", Type: arrow.PrimitiveTypes.Float32, Nullable: true},
+		{Name: "ts", Type: arrow.FixedWidthTypes.Timestamp_us, Nullable: true},
+		{Name: "vec", Type: arrow.ListOf(arrow.PrimitiveTypes.Float32), Nullable: true},
+	}, nil)
+	builder := array.NewRecordBuilder(memory.DefaultAllocator, schema)
+	defer builder.Release()
+	rows := [][]interface{}{
+		{int32(1), 1.5, ts, []float32{1, 2}},
+		{nil, nil, nil, nil},
+	}
+	for _, row := range rows {
+		for i, value := range row {
+			if err := appendArrowValue(builder.Field(i), value); err != nil {
+				t.Fatalf("Failed to append %v: %s", value, err)
+			}
+		}
+	}
+	record := builder.NewRecord()
+	defer record.Release()
+	if v := record.Column(0).(*array.Int64).Value(0); v != 1 {
+		t.Fatalf("Expected 1, got %d", v)
+	}
+	if v := record.Column(1).(*array.Float32).Value(0); v != 1.5 {
+		t.Fatalf("Expected 1.5, got %f", v)
+	}
+	if v := record.Column(2).(*array.Timestamp).Value(0); v != arrow.Timestamp(ts.UnixMicro()) {
+		t.Fatalf("Expected %d, got %d", ts.UnixMicro(), v)
+	}
+	vec := record.Column(3).(*array.List)
+	if start, end := vec.ValueOffsets(0); end-start != 2 {
+		t.Fatalf("Expected a vector of length 2, got %d", end-start)
+	}
+	for i := 0; i < int(record.NumCols()); i++ {
+		if !record.Column(i).IsNull(1) {
+			t.Fatalf("Expected column %d to be null in the second row", i)
+		}
+	}
+	if err := appendArrowValue(builder.Field(0), "abc"); err == nil {
+		t.Fatalf("Expected error appending a string to an int column")
+	}
+}
@@ -0,0 +1,40 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+//
+// Copyright 2024 FeatureForm Inc.
+//
+
+package serving
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/encoding/protojson"
+
+	"github.com/featureform/fferr"
+	pb "github.com/featureform/proto"
+)
+
+// NewGateway returns an HTTP/JSON handler for the serving API, for clients that
+// can't use gRPC. Requests are proxied to the serving gRPC server at endpoint, so
+// they go through the same handlers and interceptors as gRPC requests, and
+// errors keep their gRPC status and details.
+//
+// Routes are defined in proto/serving_gateway.yaml:
+//
+//	GET /features/{name}/{variant}?entity=...
+func NewGateway(ctx context.Context, endpoint string, opts ...grpc.DialOption) (http.Handler, error) {
+	mux := runtime.NewServeMux(
+		runtime.WithMarshalerOption(runtime.MIMEWildcard, &runtime.JSONPb{
+			MarshalOptions: protojson.MarshalOptions{UseProtoNames: true},
+		}),
+	)
+	if err := pb.RegisterFeatureHandlerFromEndpoint(ctx, mux, endpoint, opts); err != nil {
+		return nil, fferr.NewInternalErrorf("failed to register serving gateway: %v", err)
+	}
+	return mux, nil
+}
@@ -0,0 +1,87 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+//
+// Copyright 2024 FeatureForm Inc.
+//
+
+package serving
+
+import (
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+
+	"github.com/featureform/helpers/interceptors"
+	pb "github.com/featureform/proto"
+)
+
+func TestGateway(t *testing.T) {
+	ctx := onlineTestContext{
+		ResourceDefsFn: simpleResourceDefsFn,
+		FactoryFn:      createMockOnlineStoreFactory(simpleFeatureRecords()),
+	}
+	serv := ctx.Create(t)
+	defer ctx.Destroy()
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %s", err)
+	}
+	grpcServer := grpc.NewServer(grpc.UnaryInterceptor(interceptors.UnaryServerErrorInterceptor))
+	pb.RegisterFeatureServer(grpcServer, serv)
+	go grpcServer.Serve(lis)
+	defer grpcServer.Stop()
+
+	gateway, err := NewGateway(ctx, lis.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatalf("Failed to create gateway: %s", err)
+	}
+	httpServer := httptest.NewServer(gateway)
+	defer httpServer.Close()
+
+	get := func(path string) (int, map[string]interface{}) {
+		resp, err := http.Get(httpServer.URL + path)
+		if err != nil {
+			t.Fatalf("Failed to GET %s: %s", path, err)
+		}
+		defer resp.Body.Close()
+		body, err := io.ReadAll(resp.Body)
+		if err != nil {
+			t.Fatalf("Failed to read response: %s", err)
+		}
+		parsed := map[string]interface{}{}
+		if err := json.Unmarshal(body, &parsed); err != nil {
+			t.Fatalf("Failed to parse response %s: %s", body, err)
+		}
+		return resp.StatusCode, parsed
+	}
+
+	status, body := get("/features/feature/variant?entity=b&entity=a")
+	if status != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %v", status, body)
+	}
+	expected := map[string]interface{}{
+		"values": []interface{}{
+			map[string]interface{}{"str_value": "def"},
+			map[string]interface{}{"double_value": 12.5},
+		},
+	}
+	if !reflect.DeepEqual(body, expected) {
+		t.Fatalf("Expected %v, got %v", expected, body)
+	}
+
+	if status, body := get("/features/feature/variant"); status != http.StatusBadRequest {
+		t.Fatalf("Expected status 400 without entities, got %d: %v", status, body)
+	}
+	if status, body := get("/features/missing/variant?entity=a"); status == http.StatusOK || body["message"] == nil {
+		t.Fatalf("Expected an error for a missing feature, got %d: %v", status, body)
+	}
+}
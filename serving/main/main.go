@@ -12,18 +12,26 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"net"
+	"net/http"
 	_ "net/http/pprof"
+	"strconv"
 
+	"github.com/apache/arrow/go/v17/arrow/flight"
+	"github.com/featureform/config"
 	help "github.com/featureform/helpers"
+	"github.com/featureform/helpers/grpctls"
 	"github.com/featureform/helpers/interceptors"
+	"github.com/featureform/helpers/tracing"
 	"github.com/featureform/logging"
 	"github.com/featureform/metadata"
 	"github.com/featureform/metrics"
 	pb "github.com/featureform/proto"
 	"github.com/featureform/serving"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/reflection"
 )
 
 func main() {
@@ -34,6 +42,19 @@ func main() {
 	port := help.GetEnv("SERVING_PORT", "8080")
 	logger.Infow("Using serving port", "port", port)
 	address := fmt.Sprintf("%s:%s", host, port)
+	flightPort := help.GetEnv("SERVING_FLIGHT_PORT", "8087")
+	// The HTTP gateway's port is checked before anything starts listening, so
+	// that a bad value fails the server instead of the gateway alone.
+	var httpPort int
+	if val := help.GetEnv(config.EnvServingHTTPPort, ""); val != "" {
+		var err error
+		if httpPort, err = config.ParsePort(config.EnvServingHTTPPort, val); err != nil {
+			logger.Panicw("Invalid serving HTTP port", "Err", err)
+		}
+		if p := strconv.Itoa(httpPort); p == port || p == flightPort {
+			logger.Panicw("Serving HTTP port is already used by the serving gRPC or Flight server", "port", httpPort)
+		}
+	}
 	lis, err := net.Listen("tcp", address)
 	if err != nil {
 		logger.Panicw("Failed to listen on port", "Err", err)
@@ -43,6 +64,14 @@ func main() {
 	metricsPort := help.GetEnv("METRICS_PORT", ":9090")
 	logger.Infow("Using metrics port", "port", metricsPort)
 
+	shutdownTracing, err := tracing.Init(context.Background(), "serving", help.GetEnv(tracing.EndpointEnv, ""))
+	if err != nil {
+		logger.Panicw("Failed to initialize tracing", "Err", err)
+	}
+	defer func() {
+		logger.LogIfErr("Failed to shut down tracing", shutdownTracing(context.Background()))
+	}()
+
 	metadataHost := help.GetEnv("METADATA_HOST", "localhost")
 	metadataPort := help.GetEnv("METADATA_PORT", "8080")
 	metadataConn := fmt.Sprintf("%s:%s", metadataHost, metadataPort)
@@ -57,9 +86,60 @@ func main() {
 	if err != nil {
 		logger.Panicw("Failed to create training server", "Err", err)
 	}
-	grpcServer := grpc.NewServer(grpc.UnaryInterceptor(interceptors.UnaryServerErrorInterceptor), grpc.StreamInterceptor(interceptors.StreamServerErrorInterceptor))
+	tlsOpts, err := grpctls.FromEnv().ServerOptions()
+	if err != nil {
+		logger.Panicw("Failed to load TLS credentials", "Err", err)
+	}
+	grpcServer := grpc.NewServer(append([]grpc.ServerOption{
+		grpc.ChainUnaryInterceptor(interceptors.UnaryServerTracingInterceptor, interceptors.UnaryServerErrorInterceptor),
+		grpc.ChainStreamInterceptor(interceptors.StreamServerTracingInterceptor, interceptors.StreamServerErrorInterceptor),
+	}, tlsOpts...)...)
 
 	pb.RegisterFeatureServer(grpcServer, serv)
+	if config.ShouldEnableGRPCReflection() {
+		logger.Infow("Registering gRPC reflection service")
+		reflection.Register(grpcServer)
+	}
+
+	flightAddress := fmt.Sprintf("%s:%s", host, flightPort)
+	flightLis, err := net.Listen("tcp", flightAddress)
+	if err != nil {
+		logger.Panicw("Failed to listen on Flight port", "Err", err)
+	}
+	// The Flight server uses the same mTLS config as the serving gRPC server.
+	flightServer := grpc.NewServer(append([]grpc.ServerOption{
+		grpc.ChainStreamInterceptor(interceptors.StreamServerTracingInterceptor, interceptors.StreamServerErrorInterceptor),
+	}, tlsOpts...)...)
+	flight.RegisterFlightServiceServer(flightServer, serving.NewFlightServer(serv))
+	go func() {
+		logger.Infow("Flight server starting", "Addr", flightAddress)
+		if err := flightServer.Serve(flightLis); err != nil {
+			logger.Errorw("Flight serve failed with error", "Err", err)
+		}
+	}()
+
+	if httpPort != 0 {
+		dialOpt, err := grpctls.FromEnv().DialOption()
+		if err != nil {
+			logger.Panicw("Failed to load TLS credentials", "Err", err)
+		}
+		gateway, err := serving.NewGateway(context.Background(), address, dialOpt)
+		if err != nil {
+			logger.Panicw("Failed to create HTTP gateway", "Err", err)
+		}
+		httpAddress := fmt.Sprintf("%s:%d", host, httpPort)
+		httpLis, err := net.Listen("tcp", httpAddress)
+		if err != nil {
+			logger.Panicw("Failed to listen on HTTP port", "Err", err)
+		}
+		go func() {
+			logger.Infow("HTTP gateway starting", "Addr", httpAddress)
+			if err := http.Serve(httpLis, gateway); err != nil {
+				logger.Errorw("HTTP gateway failed with error", "Err", err)
+			}
+		}()
+	}
+
 	logger.Infow("Serving metrics", "Port", metricsPort)
 	go promMetrics.ExposePort(metricsPort)
 	logger.Infow("Server starting", "Addr", address)
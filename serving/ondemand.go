@@ -0,0 +1,476 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+//
+// Copyright 2024 FeatureForm Inc.
+//
+
+package serving
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/featureform/fferr"
+	pb "github.com/featureform/proto"
+)
+
+// On-demand feature definitions are the Python source of a function that takes
+// (client, params, entity). Only functions whose body is a single return of an
+// arithmetic expression over params and numeric literals can be evaluated on the
+// server, for example:
+//
+//	@ff.ondemand_feature(variant="v1")
+//	def doubled_amount(client, params, entity):
+//	    return params[0] * 2 + 1
+//
+// Anything else returns an UnimplementedError so the client knows to run the
+// function itself.
+
+var onDemandSignature = regexp.MustCompile(`(?s)def\s+\w+\s*\(([^)]*)\)\s*(?:->[^:]*)?:`)
+
+// onDemandExpr is a parsed on-demand feature expression.
+type onDemandExpr interface {
+	eval(params []onDemandNumber) (onDemandNumber, error)
+}
+
+// onDemandNumber follows Python's numeric semantics: arithmetic on two ints
+// stays an int, anything involving a float is a float.
+type onDemandNumber struct {
+	i       int64
+	f       float64
+	isFloat bool
+}
+
+func intNumber(i int64) onDemandNumber {
+	return onDemandNumber{i: i}
+}
+
+func floatNumber(f float64) onDemandNumber {
+	return onDemandNumber{f: f, isFloat: true}
+}
+
+func (n onDemandNumber) float() float64 {
+	if n.isFloat {
+		return n.f
+	}
+	return float64(n.i)
+}
+
+func (n onDemandNumber) isZero() bool {
+	return n.float() == 0
+}
+
+func (n onDemandNumber) value() interface{} {
+	if n.isFloat {
+		return n.f
+	}
+	return n.i
+}
+
+// evaluateOnDemandDefinition evaluates the definition of an on-demand feature
+// with the given params.
+func evaluateOnDemandDefinition(definition string, params []*pb.Value) (interface{}, error) {
+	expr, err := parseOnDemandDefinition(definition)
+	if err != nil {
+		return nil, err
+	}
+	numbers := make([]onDemandNumber, len(params))
+	for i, param := range params {
+		number, err := onDemandParam(param)
+		if err != nil {
+			return nil, fferr.NewInvalidArgumentErrorf("param %d: %v", i, err)
+		}
+		numbers[i] = number
+	}
+	result, err := expr.eval(numbers)
+	if err != nil {
+		return nil, err
+	}
+	return result.value(), nil
+}
+
+func onDemandParam(val *pb.Value) (onDemandNumber, error) {
+	switch casted := val.GetValue().(type) {
+	case *pb.Value_IntValue:
+		return intNumber(int64(casted.IntValue)), nil
+	case *pb.Value_Int32Value:
+		return intNumber(int64(casted.Int32Value)), nil
+	case *pb.Value_Int64Value:
+		return intNumber(casted.Int64Value), nil
+	case *pb.Value_Uint32Value:
+		return intNumber(int64(casted.Uint32Value)), nil
+	case *pb.Value_Uint64Value:
+		if casted.Uint64Value > math.MaxInt64 {
+			return onDemandNumber{}, fmt.Errorf("value %d overflows int64", casted.Uint64Value)
+		}
+		return intNumber(int64(casted.Uint64Value)), nil
+	case *pb.Value_FloatValue:
+		return floatNumber(float64(casted.FloatValue)), nil
+	case *pb.Value_DoubleValue:
+		return floatNumber(casted.DoubleValue), nil
+	case *pb.Value_BoolValue:
+		// Python bools are ints.
+		if casted.BoolValue {
+			return intNumber(1), nil
+		}
+		return intNumber(0), nil
+	default:
+		return onDemandNumber{}, fmt.Errorf("only numeric params are supported, got %T", val.GetValue())
+	}
+}
+
+func unsupportedDefinition(format string, a ...any) error {
+	return fferr.NewUnimplementedErrorf("on-demand feature definition can't be evaluated on the server, %s", fmt.Sprintf(format, a...))
+}
+
+// parseOnDemandDefinition extracts the return expression from the function in
+// definition and parses it.
+func parseOnDemandDefinition(definition string) (onDemandExpr, error) {
+	loc := onDemandSignature.FindStringSubmatchIndex(definition)
+	if loc == nil {
+		return nil, unsupportedDefinition("no function definition found")
+	}
+	args := strings.Split(definition[loc[2]:loc[3]], ",")
+	if len(args) != 3 {
+		return nil, unsupportedDefinition("expected the arguments (client, params, entity), got (%s)", definition[loc[2]:loc[3]])
+	}
+	paramsName := strings.TrimSpace(strings.SplitN(strings.SplitN(args[1], ":", 2)[0], "=", 2)[0])
+
+	statement, err := onDemandBody(definition[loc[1]:])
+	if err != nil {
+		return nil, err
+	}
+	if !strings.HasPrefix(statement, "return ") {
+		return nil, unsupportedDefinition("only a single return statement is supported, got: %s", statement)
+	}
+	tokens, err := tokenizeOnDemand(strings.TrimPrefix(statement, "return "))
+	if err != nil {
+		return nil, err
+	}
+	parser := &onDemandParser{tokens: tokens, paramsName: paramsName}
+	expr, err := parser.parseExpr()
+	if err != nil {
+		return nil, err
+	}
+	if !parser.done() {
+		return nil, unsupportedDefinition("unexpected %q", parser.peek())
+	}
+	return expr, nil
+}
+
+// onDemandBody returns the function's body as a single line, without comments
+// or a docstring.
+func onDemandBody(body string) (string, error) {
+	lines := make([]string, 0)
+	inDocstring := ""
+	for _, line := range strings.Split(body, "\n") {
+		line = strings.TrimSpace(line)
+		if inDocstring != "" {
+			if strings.Contains(line, inDocstring) {
+				inDocstring = ""
+			}
+			continue
+		}
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if len(lines) == 0 {
+			if quote := docstringQuote(line); quote != "" {
+				if len(line) < 6 || !strings.HasSuffix(line, quote) {
+					inDocstring = quote
+				}
+				continue
+			}
+		}
+		if idx := strings.Index(line, "#"); idx >= 0 {
+			line = strings.TrimSpace(line[:idx])
+		}
+		lines = append(lines, line)
+	}
+	if len(lines) == 0 {
+		return "", unsupportedDefinition("function has no body")
+	}
+	return strings.Join(lines, " "), nil
+}
+
+func docstringQuote(line string) string {
+	for _, quote := range []string{`"""`, `'''`} {
+		if strings.HasPrefix(line, quote) {
+			return quote
+		}
+	}
+	return ""
+}
+
+func tokenizeOnDemand(expr string) ([]string, error) {
+	tokens := make([]string, 0)
+	for i := 0; i < len(expr); {
+		c := expr[i]
+		switch {
+		case c == ' ' || c == '\t':
+			i++
+		case strings.HasPrefix(expr[i:], "**") || strings.HasPrefix(expr[i:], "//"):
+			tokens = append(tokens, expr[i:i+2])
+			i += 2
+		case strings.ContainsRune("+-*/%()[]", rune(c)):
+			tokens = append(tokens, string(c))
+			i++
+		case c >= '0' && c <= '9' || c == '.':
+			j := i
+			for j < len(expr) && (expr[j] >= '0' && expr[j] <= '9' || expr[j] == '.' || expr[j] == '_' ||
+				expr[j] == 'e' || expr[j] == 'E' || (expr[j] == '-' || expr[j] == '+') && (expr[j-1] == 'e' || expr[j-1] == 'E')) {
+				j++
+			}
+			tokens = append(tokens, expr[i:j])
+			i = j
+		case c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z':
+			j := i
+			for j < len(expr) && (expr[j] == '_' || expr[j] >= 'a' && expr[j] <= 'z' || expr[j] >= 'A' && expr[j] <= 'Z' || expr[j] >= '0' && expr[j] <= '9') {
+				j++
+			}
+			tokens = append(tokens, expr[i:j])
+			i = j
+		default:
+			return nil, unsupportedDefinition("unsupported character %q in expression", c)
+		}
+	}
+	return tokens, nil
+}
+
+// onDemandParser is a recursive descent parser that follows Python's operator
+// precedence:
+//
+//	expr  := term (('+' | '-') term)*
+//	term  := unary (('*' | '/' | '//' | '%') unary)*
+//	unary := ('+' | '-') unary | power
+//	power := atom ('**' unary)?
+//	atom  := NUMBER | params '[' INT ']' | '(' expr ')'
+type onDemandParser struct {
+	tokens     []string
+	pos        int
+	paramsName string
+}
+
+func (p *onDemandParser) done() bool {
+	return p.pos >= len(p.tokens)
+}
+
+func (p *onDemandParser) peek() string {
+	if p.done() {
+		return ""
+	}
+	return p.tokens[p.pos]
+}
+
+func (p *onDemandParser) next() string {
+	tok := p.peek()
+	p.pos++
+	return tok
+}
+
+func (p *onDemandParser) expect(tok string) error {
+	if got := p.next(); got != tok {
+		return unsupportedDefinition("expected %q, got %q", tok, got)
+	}
+	return nil
+}
+
+func (p *onDemandParser) parseExpr() (onDemandExpr, error) {
+	left, err := p.parseTerm()
+	if err != nil {
+		return nil, err
+	}
+	for p.peek() == "+" || p.peek() == "-" {
+		op := p.next()
+		right, err := p.parseTerm()
+		if err != nil {
+			return nil, err
+		}
+		left = binaryExpr{op: op, left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *onDemandParser) parseTerm() (onDemandExpr, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for p.peek() == "*" || p.peek() == "/" || p.peek() == "//" || p.peek() == "%" {
+		op := p.next()
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = binaryExpr{op: op, left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *onDemandParser) parseUnary() (onDemandExpr, error) {
+	if p.peek() == "+" || p.peek() == "-" {
+		op := p.next()
+		operand, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		if op == "+" {
+			return operand, nil
+		}
+		return binaryExpr{op: "-", left: literalExpr{intNumber(0)}, right: operand}, nil
+	}
+	return p.parsePower()
+}
+
+func (p *onDemandParser) parsePower() (onDemandExpr, error) {
+	base, err := p.parseAtom()
+	if err != nil {
+		return nil, err
+	}
+	if p.peek() == "**" {
+		p.next()
+		// ** is right associative and binds tighter than a unary minus on its
+		// left, but not on its right: -2**-1 == -(2**(-1)).
+		exp, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return binaryExpr{op: "**", left: base, right: exp}, nil
+	}
+	return base, nil
+}
+
+func (p *onDemandParser) parseAtom() (onDemandExpr, error) {
+	tok := p.next()
+	switch {
+	case tok == "":
+		return nil, unsupportedDefinition("unexpected end of expression")
+	case tok == "(":
+		expr, err := p.parseExpr()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect(")"); err != nil {
+			return nil, err
+		}
+		return expr, nil
+	case tok == p.paramsName:
+		if err := p.expect("["); err != nil {
+			return nil, err
+		}
+		idx, err := strconv.Atoi(p.next())
+		if err != nil || idx < 0 {
+			return nil, unsupportedDefinition("params can only be indexed by a non-negative integer literal")
+		}
+		if err := p.expect("]"); err != nil {
+			return nil, err
+		}
+		return paramExpr{idx: idx}, nil
+	case tok[0] >= '0' && tok[0] <= '9' || tok[0] == '.':
+		literal := strings.ReplaceAll(tok, "_", "")
+		if i, err := strconv.ParseInt(literal, 10, 64); err == nil {
+			return literalExpr{intNumber(i)}, nil
+		}
+		if f, err := strconv.ParseFloat(literal, 64); err == nil {
+			return literalExpr{floatNumber(f)}, nil
+		}
+		return nil, unsupportedDefinition("invalid number %q", tok)
+	default:
+		return nil, unsupportedDefinition("only params and numeric literals can be used, got %q", tok)
+	}
+}
+
+type literalExpr struct {
+	val onDemandNumber
+}
+
+func (e literalExpr) eval([]onDemandNumber) (onDemandNumber, error) {
+	return e.val, nil
+}
+
+type paramExpr struct {
+	idx int
+}
+
+func (e paramExpr) eval(params []onDemandNumber) (onDemandNumber, error) {
+	if e.idx >= len(params) {
+		return onDemandNumber{}, fferr.NewInvalidArgumentErrorf("definition uses params[%d] but only %d params were provided", e.idx, len(params))
+	}
+	return params[e.idx], nil
+}
+
+type binaryExpr struct {
+	op          string
+	left, right onDemandExpr
+}
+
+func (e binaryExpr) eval(params []onDemandNumber) (onDemandNumber, error) {
+	l, err := e.left.eval(params)
+	if err != nil {
+		return onDemandNumber{}, err
+	}
+	r, err := e.right.eval(params)
+	if err != nil {
+		return onDemandNumber{}, err
+	}
+	if (e.op == "/" || e.op == "//" || e.op == "%") && r.isZero() {
+		return onDemandNumber{}, fferr.NewInvalidArgumentErrorf("division by zero")
+	}
+	isFloat := l.isFloat || r.isFloat
+	switch e.op {
+	case "+":
+		if isFloat {
+			return floatNumber(l.float() + r.float()), nil
+		}
+		return intNumber(l.i + r.i), nil
+	case "-":
+		if isFloat {
+			return floatNumber(l.float() - r.float()), nil
+		}
+		return intNumber(l.i - r.i), nil
+	case "*":
+		if isFloat {
+			return floatNumber(l.float() * r.float()), nil
+		}
+		return intNumber(l.i * r.i), nil
+	case "/":
+		return floatNumber(l.float() / r.float()), nil
+	case "//":
+		if isFloat {
+			return floatNumber(math.Floor(l.float() / r.float())), nil
+		}
+		q := l.i / r.i
+		if (l.i%r.i != 0) && ((l.i < 0) != (r.i < 0)) {
+			q--
+		}
+		return intNumber(q), nil
+	case "%":
+		if isFloat {
+			m := math.Mod(l.float(), r.float())
+			if m != 0 && (m < 0) != (r.float() < 0) {
+				m += r.float()
+			}
+			return floatNumber(m), nil
+		}
+		m := l.i % r.i
+		if m != 0 && (m < 0) != (r.i < 0) {
+			m += r.i
+		}
+		return intNumber(m), nil
+	case "**":
+		if isFloat || r.i < 0 {
+			return floatNumber(math.Pow(l.float(), r.float())), nil
+		}
+		result := int64(1)
+		for i := int64(0); i < r.i; i++ {
+			result *= l.i
+		}
+		return intNumber(result), nil
+	default:
+		return onDemandNumber{}, fferr.NewInternalErrorf("unknown operator %s", e.op)
+	}
+}
@@ -0,0 +1,103 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+//
+// Copyright 2024 FeatureForm Inc.
+//
+
+package serving
+
+import (
+	"testing"
+
+	"github.com/featureform/fferr"
+	pb "github.com/featureform/proto"
+)
+
+func onDemandDef(body string) string {
+	return "@ff.ondemand_feature\ndef feature(client, params, entity):\n" + body
+}
+
+func TestEvaluateOnDemandDefinition(t *testing.T) {
+	params := []*pb.Value{
+		{Value: &pb.Value_IntValue{IntValue: 7}},
+		{Value: &pb.Value_Int64Value{Int64Value: -2}},
+		{Value: &pb.Value_DoubleValue{DoubleValue: 1.5}},
+		{Value: &pb.Value_BoolValue{BoolValue: true}},
+	}
+	tests := map[string]struct {
+		Definition string
+		Expected   interface{}
+	}{
+		"literal":          {onDemandDef("    return 3"), int64(3)},
+		"param":            {onDemandDef("    return params[0]"), int64(7)},
+		"precedence":       {onDemandDef("    return params[0] + params[1] * 3"), int64(1)},
+		"parens":           {onDemandDef("    return (params[0] + params[1]) * 3"), int64(15)},
+		"float":            {onDemandDef("    return params[0] * params[2]"), 10.5},
+		"true division":    {onDemandDef("    return params[0] / 2"), 3.5},
+		"floor division":   {onDemandDef("    return params[0] // params[1]"), int64(-4)},
+		"modulo sign":      {onDemandDef("    return params[0] % params[1]"), int64(-1)},
+		"power":            {onDemandDef("    return 2 ** 3 ** 2"), int64(512)},
+		"negative power":   {onDemandDef("    return -2 ** -1"), -0.5},
+		"bool param":       {onDemandDef("    return params[3] + 1"), int64(2)},
+		"float literal":    {onDemandDef("    return 1_000 * 2.5e-1"), 250.0},
+		"renamed params":   {"def f(c, p, e):\n    return p[1] - -p[0]", int64(5)},
+		"docstring":        {onDemandDef("    \"\"\"Doubles the first param.\n    \"\"\"\n    # comment\n    return params[0] * 2  # trailing"), int64(14)},
+		"one line doc":     {onDemandDef("    '''Doubles the first param.'''\n    return params[0] * 2"), int64(14)},
+		"return type hint": {"def f(client, params: list, entity) -> float:\n    return params[2]", 1.5},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			result, err := evaluateOnDemandDefinition(test.Definition, params)
+			if err != nil {
+				t.Fatalf("Failed to evaluate %q: %v", test.Definition, err)
+			}
+			if result != test.Expected {
+				t.Fatalf("Wrong result for %q: %#v\nExpected: %#v", test.Definition, result, test.Expected)
+			}
+		})
+	}
+}
+
+func TestEvaluateOnDemandDefinitionErrors(t *testing.T) {
+	params := []*pb.Value{
+		{Value: &pb.Value_IntValue{IntValue: 1}},
+		{Value: &pb.Value_StrValue{StrValue: "a"}},
+	}
+	unsupported := map[string]string{
+		"not a function":      "lambda client, params, entity: params[0]",
+		"wrong arguments":     "def f(params):\n    return params[0]",
+		"multiple statements": onDemandDef("    x = params[0]\n    return x"),
+		"function call":       onDemandDef("    return abs(params[0])"),
+		"client use":          onDemandDef("    return client.features([('f', 'v')], {})"),
+		"variable index":      onDemandDef("    return params[params[0]]"),
+		"comparison":          onDemandDef("    return params[0] > 1"),
+		"unbalanced parens":   onDemandDef("    return (params[0] + 1"),
+		"empty body":          onDemandDef(""),
+	}
+	for name, definition := range unsupported {
+		t.Run(name, func(t *testing.T) {
+			_, err := evaluateOnDemandDefinition(definition, params[:1])
+			if _, ok := err.(*fferr.UnimplementedError); !ok {
+				t.Fatalf("Expected an UnimplementedError for %q, got: %v", definition, err)
+			}
+		})
+	}
+
+	invalid := map[string]string{
+		"missing param":    onDemandDef("    return params[2]"),
+		"division by zero": onDemandDef("    return params[0] / 0"),
+		"modulo by zero":   onDemandDef("    return params[0] % 0.0"),
+	}
+	for name, definition := range invalid {
+		t.Run(name, func(t *testing.T) {
+			_, err := evaluateOnDemandDefinition(definition, params[:1])
+			if _, ok := err.(*fferr.InvalidArgumentError); !ok {
+				t.Fatalf("Expected an InvalidArgumentError for %q, got: %v", definition, err)
+			}
+		})
+	}
+	if _, err := evaluateOnDemandDefinition(onDemandDef("    return params[0]"), params); err == nil {
+		t.Fatalf("Expected an error for a string param")
+	}
+}
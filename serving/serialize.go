@@ -306,3 +306,33 @@ func wrapVec32(val []float32) *pb.Value {
 		},
 	}
 }
+
+// unwrapValue is the inverse of wrapValue for values a client can write.
+func unwrapValue(val *pb.Value) (interface{}, error) {
+	switch typed := val.GetValue().(type) {
+	case *pb.Value_StrValue:
+		return typed.StrValue, nil
+	case *pb.Value_IntValue:
+		return int(typed.IntValue), nil
+	case *pb.Value_FloatValue:
+		return typed.FloatValue, nil
+	case *pb.Value_DoubleValue:
+		return typed.DoubleValue, nil
+	case *pb.Value_Int64Value:
+		return typed.Int64Value, nil
+	case *pb.Value_Int32Value:
+		return typed.Int32Value, nil
+	case *pb.Value_BoolValue:
+		return typed.BoolValue, nil
+	case *pb.Value_Vector32Value:
+		return typed.Vector32Value.GetValue(), nil
+	case *pb.Value_Uint32Value:
+		return typed.Uint32Value, nil
+	case *pb.Value_Uint64Value:
+		return typed.Uint64Value, nil
+	case nil:
+		return nil, fferr.NewInvalidArgumentErrorf("value is missing")
+	default:
+		return nil, fferr.NewInvalidArgumentErrorf("values of type %T can't be written", typed)
+	}
+}
@@ -16,13 +16,17 @@ import (
 	"fmt"
 
 	"github.com/featureform/fferr"
+	"github.com/featureform/helpers/tracing"
 	"github.com/featureform/logging"
 	"github.com/featureform/metadata"
 	"github.com/featureform/metrics"
 	pb "github.com/featureform/proto"
 	"github.com/featureform/provider"
 	pt "github.com/featureform/provider/provider_type"
+	"github.com/featureform/provider/types"
 	"github.com/featureform/scheduling"
+	"go.opentelemetry.io/otel/attribute"
+	"google.golang.org/protobuf/types/known/timestamppb"
 
 	"io"
 	"sync"
@@ -30,6 +34,9 @@ import (
 
 const (
 	DataBatchSize = 1024
+	// MaxTrainingDataBatchSize bounds the batch size a TrainingData request can
+	// ask for, so wide rows don't push a message over gRPC's size limit.
+	MaxTrainingDataBatchSize = 16384
 )
 
 type FeatureServer struct {
@@ -40,6 +47,8 @@ type FeatureServer struct {
 	Providers *sync.Map
 	Tables    *sync.Map
 	Features  *sync.Map
+	// Stats holds a *cachedResourceStats per offline resource.
+	Stats *sync.Map
 }
 
 func NewFeatureServer(meta *metadata.Client, promMetrics metrics.MetricsHandler, logger logging.Logger) (*FeatureServer, error) {
@@ -51,6 +60,7 @@ func NewFeatureServer(meta *metadata.Client, promMetrics metrics.MetricsHandler,
 		Providers: &sync.Map{},
 		Tables:    &sync.Map{},
 		Features:  &sync.Map{},
+		Stats:     &sync.Map{},
 	}, nil
 }
 
@@ -61,6 +71,11 @@ func (serv *FeatureServer) TrainingData(req *pb.TrainingDataRequest, stream pb.F
 	defer featureObserver.Finish()
 	logger := serv.Logger.With("Name", name, "Variant", variant)
 	logger.Info("Serving training data")
+	batchSize, err := trainingDataBatchSize(req)
+	if err != nil {
+		featureObserver.SetError()
+		return err
+	}
 	if model := req.GetModel(); model != nil {
 		trainingSets := []metadata.NameVariant{{Name: name, Variant: variant}}
 		err := serv.Metadata.CreateModel(stream.Context(), metadata.ModelDef{Name: model.GetName(), Trainingsets: trainingSets})
@@ -68,45 +83,67 @@ func (serv *FeatureServer) TrainingData(req *pb.TrainingDataRequest, stream pb.F
 			return err
 		}
 	}
-	iter, err := serv.getTrainingSetIterator(name, variant)
+	iter, err := serv.getTrainingSetIterator(name, variant, req.GetSplit())
 	if err != nil {
 		logger.Errorw("Failed to get training set iterator", "Error", err)
 		featureObserver.SetError()
 		return err
 	}
-	rows := &pb.TrainingDataRows{Rows: make([]*pb.TrainingDataRow, 0, DataBatchSize)}
-	bufRows := 0
+	if err := sendTrainingRows(stream, iter, batchSize, featureObserver, logger); err != nil {
+		featureObserver.SetError()
+		return err
+	}
+	return nil
+}
+
+// trainingDataBatchSize returns the number of rows to send per message for req.
+func trainingDataBatchSize(req *pb.TrainingDataRequest) (int, error) {
+	size := req.GetBatchSize()
+	if size == 0 {
+		return DataBatchSize, nil
+	}
+	if size > MaxTrainingDataBatchSize {
+		return 0, fferr.NewInvalidArgumentErrorf(
+			"training data batch size %d is over the max of %d", size, MaxTrainingDataBatchSize,
+		)
+	}
+	return int(size), nil
+}
+
+// sendTrainingRows streams every row of iter in messages of batchSize rows.
+func sendTrainingRows(
+	stream pb.Feature_TrainingDataServer,
+	iter provider.TrainingSetIterator,
+	batchSize int,
+	featureObserver metrics.FeatureObserver,
+	logger logging.Logger,
+) error {
+	rows := &pb.TrainingDataRows{Rows: make([]*pb.TrainingDataRow, 0, batchSize)}
 	for iter.Next() {
 		sRow, err := serializedRow(iter.Features(), iter.Label())
 		if err != nil {
 			logger.Errorw("Failed to serialize row", "Error", err)
-			featureObserver.SetError()
 			return err
 		}
 		featureObserver.ServeRow()
 		rows.Rows = append(rows.Rows, sRow)
-		bufRows++
-		if bufRows == DataBatchSize {
+		if len(rows.Rows) == batchSize {
 			if err := stream.Send(rows); err != nil {
 				logger.Errorw("Failed to write to training data stream", "Error", err)
-				featureObserver.SetError()
 				return fferr.NewInternalError(err)
 			}
 			// Reset buffer rather than allocating a new one.
 			rows.Rows = rows.Rows[:0]
-			bufRows = 0
 		}
 	}
-	if bufRows != 0 {
+	if len(rows.Rows) != 0 {
 		if err := stream.Send(rows); err != nil {
 			logger.Errorw("Failed to write to training data stream", "Error", err)
-			featureObserver.SetError()
 			return fferr.NewInternalError(err)
 		}
 	}
 	if err := iter.Err(); err != nil {
 		logger.Errorw("Training set iterator error", "Error", err)
-		featureObserver.SetError()
 		return err
 	}
 	return nil
@@ -352,7 +389,9 @@ func (serv *FeatureServer) SourceData(req *pb.SourceDataRequest, stream pb.Featu
 	return nil
 }
 
-func (serv *FeatureServer) getTrainingSetIterator(name, variant string) (provider.TrainingSetIterator, error) {
+// getTrainingSetIterator returns the rows of a training set, or only those in
+// split if it's set.
+func (serv *FeatureServer) getTrainingSetIterator(name, variant, split string) (provider.TrainingSetIterator, error) {
 	ctx := context.TODO()
 	serv.Logger.Infow("Getting Training Set Iterator", "name", name, "variant", variant)
 	ts, err := serv.Metadata.GetTrainingSetVariant(ctx, metadata.NameVariant{Name: name, Variant: variant})
@@ -373,8 +412,12 @@ func (serv *FeatureServer) getTrainingSetIterator(name, variant string) (provide
 		serv.Logger.Errorw("Training set provider is not an offline store", "Error", err)
 		return nil, err
 	}
-	serv.Logger.Debugw("Get Training Set From Store", "name", name, "variant", variant)
-	return store.GetTrainingSet(provider.ResourceID{Name: name, Variant: variant})
+	serv.Logger.Debugw("Get Training Set From Store", "name", name, "variant", variant, "split", split)
+	id := provider.ResourceID{Name: name, Variant: variant}
+	if split != "" {
+		return store.GetTrainingSetSplit(id, split)
+	}
+	return store.GetTrainingSet(id)
 }
 
 func (serv *FeatureServer) createTrainTestSplit(def provider.TrainTestSplitDef) (func() error, error) {
@@ -425,6 +468,46 @@ func (serv *FeatureServer) getTrainTestSplitIterators(def provider.TrainTestSpli
 }
 
 func (serv *FeatureServer) getBatchFeatureIterator(ids []provider.ResourceID) (provider.BatchFeatureIterator, error) {
+	store, err := serv.getBatchFeatureStore(ids)
+	if err != nil {
+		return nil, err
+	}
+	return store.GetBatchFeatures(ids)
+}
+
+func (serv *FeatureServer) getAsOfBatchFeatureIterator(ids []provider.ResourceID, asOf []*pb.AsOfEntity) (provider.AsOfBatchFeatureIterator, error) {
+	entities := make([]provider.AsOfEntity, len(asOf))
+	for i, entity := range asOf {
+		if entity.GetTs() == nil {
+			return nil, fferr.NewInvalidArgumentErrorf("as of entity %s is missing a timestamp", entity.GetEntity())
+		}
+		entities[i] = provider.AsOfEntity{Entity: entity.GetEntity(), TS: entity.GetTs().AsTime()}
+	}
+	store, err := serv.getBatchFeatureStore(ids)
+	if err != nil {
+		return nil, err
+	}
+	asOfStore, err := asOfBatchFeatureStore(store)
+	if err != nil {
+		return nil, err
+	}
+	return asOfStore.GetBatchFeaturesAsOf(ids, entities)
+}
+
+// asOfBatchFeatureStore returns an UnimplementedError for offline stores that
+// can't serve batch features as of a timestamp. Only Spark and the in-memory
+// store can.
+func asOfBatchFeatureStore(store provider.OfflineStore) (provider.AsOfBatchFeatureStore, error) {
+	asOfStore, ok := store.(provider.AsOfBatchFeatureStore)
+	if !ok {
+		return nil, fferr.NewUnimplementedErrorf("serving batch features as of a timestamp is not supported for %s", store.Type())
+	}
+	return asOfStore, nil
+}
+
+// getBatchFeatureStore returns the offline store that all of the features are
+// registered in.
+func (serv *FeatureServer) getBatchFeatureStore(ids []provider.ResourceID) (provider.OfflineStore, error) {
 	ctx := context.TODO()
 	_, err := serv.checkEntityOfFeature(ids)
 	if err != nil {
@@ -462,7 +545,7 @@ func (serv *FeatureServer) getBatchFeatureIterator(ids []provider.ResourceID) (p
 		// That shouldn't be possible.
 		return nil, err
 	}
-	return store.GetBatchFeatures(ids)
+	return store, nil
 }
 
 func (serv *FeatureServer) checkFeatureSources(firstProvider string, ids []provider.ResourceID, ctx context.Context) error {
@@ -604,6 +687,70 @@ func (serv *FeatureServer) FeatureServe(ctx context.Context, req *pb.FeatureServ
 	}, nil
 }
 
+// BatchGetFeatures returns the values of every requested feature for every entity key
+// in a single response. Tables that support it are read with their store's native
+// multi-get; the rest fall back to concurrent Gets.
+func (serv *FeatureServer) BatchGetFeatures(ctx context.Context, req *pb.BatchGetFeaturesRequest) (*pb.BatchGetFeaturesResponse, error) {
+	features := req.GetFeatures()
+	if len(features) == 0 {
+		return nil, fferr.NewInvalidArgumentErrorf("at least one feature must be requested")
+	}
+	entityMap := make(map[string][]string)
+	for _, entity := range req.GetEntities() {
+		entityMap[entity.GetName()] = entity.GetValues()
+	}
+	serv.Logger.Debugw("Batch getting features", "num_features", len(features), "num_entities", len(entityMap))
+	rows, err := serv.getFeatureRows(ctx, features, entityMap)
+	if err != nil {
+		return nil, err
+	}
+	return &pb.BatchGetFeaturesResponse{
+		ValueLists: rows,
+	}, nil
+}
+
+// GetFeature returns one feature's values for the given keys of the feature's
+// entity. It backs the HTTP gateway's GET /features/{name}/{variant} route.
+func (serv *FeatureServer) GetFeature(ctx context.Context, req *pb.GetFeatureRequest) (*pb.ValueList, error) {
+	name, variant := req.GetName(), req.GetVariant()
+	if len(req.GetEntity()) == 0 {
+		return nil, fferr.NewInvalidArgumentErrorf("at least one entity must be requested")
+	}
+	obs := serv.Metrics.BeginObservingOnlineServe(name, variant)
+	ctx = context.WithValue(ctx, observer{}, obs)
+	defer obs.Finish()
+	meta, err := serv.getOrCacheFeatureMetadata(ctx, name, variant)
+	if err != nil {
+		return nil, err
+	}
+	entityMap := map[string][]string{meta.Entity(): req.GetEntity()}
+	return serv.getMetadataFeatureValues(ctx, meta, entityMap)
+}
+
+// EvaluateOnDemandFeature evaluates an on-demand feature's definition with the
+// given params. Only definitions that return an arithmetic expression of params
+// and numeric literals are supported; others return an UnimplementedError and
+// have to be computed by the client.
+func (serv *FeatureServer) EvaluateOnDemandFeature(ctx context.Context, req *pb.OnDemandFeatureRequest) (*pb.Value, error) {
+	name, variant := req.GetFeature().GetName(), req.GetFeature().GetVersion()
+	logger := serv.Logger.WithRequestIDFromContext(ctx).With("name", name, "variant", variant)
+	logger.Debug("Evaluating on-demand feature")
+	feature, err := serv.Metadata.GetFeatureVariant(ctx, metadata.NameVariant{Name: name, Variant: variant})
+	if err != nil {
+		logger.Errorw("Failed to get feature variant", "error", err)
+		return nil, err
+	}
+	if !feature.IsOnDemand() {
+		return nil, fferr.NewInvalidArgumentErrorf("feature %s (%s) is not an on-demand feature", name, variant)
+	}
+	result, err := evaluateOnDemandDefinition(feature.Definition(), req.GetParams())
+	if err != nil {
+		logger.Infow("Failed to evaluate on-demand feature", "error", err)
+		return nil, err
+	}
+	return wrapValue(result)
+}
+
 func (serv *FeatureServer) getNVCacheKey(name, variant string) string {
 	return fmt.Sprintf("%s:%s", name, variant)
 }
@@ -656,7 +803,13 @@ func (serv *FeatureServer) getFeatureValue(ctx context.Context, name, variant st
 			obs.SetError()
 			return nil, err
 		}
+		_, span := tracing.StartSpan(ctx, "serving.OnlineStoreGet",
+			attribute.String("feature", name),
+			attribute.String("variant", variant),
+			attribute.String("provider", providerEntry.Type()),
+		)
 		val, err = table.Get(entity)
+		tracing.EndSpan(span, err)
 		if err != nil {
 			logger.Errorw("entity not found", "Error", err)
 			obs.SetError()
@@ -686,18 +839,34 @@ func (serv *FeatureServer) BatchFeatureServe(req *pb.BatchFeatureServeRequest, s
 		serv.Logger.Infow("Serving feature", "Name", name, "Variant", variant)
 		resourceIDList[i] = provider.ResourceID{Name: name, Variant: variant, Type: provider.Feature}
 	}
-	iter, err := serv.getBatchFeatureIterator(resourceIDList)
+	var iter provider.BatchFeatureIterator
+	var asOfIter provider.AsOfBatchFeatureIterator
+	var err error
+	if asOf := req.GetAsOf(); len(asOf) > 0 {
+		logger.Infow("Serving batch features as of entity timestamps", "entities", len(asOf))
+		asOfIter, err = serv.getAsOfBatchFeatureIterator(resourceIDList, asOf)
+		iter = asOfIter
+	} else {
+		iter, err = serv.getBatchFeatureIterator(resourceIDList)
+	}
+	if err != nil {
+		return err
+	}
+	valueTypes, err := serv.batchFeatureValueTypes(stream.Context(), resourceIDList)
 	if err != nil {
 		return err
 	}
 
-	rows := &pb.BatchFeatureRows{Rows: make([]*pb.BatchFeatureRow, 0, DataBatchSize)}
+	rows := &pb.BatchFeatureRows{Rows: make([]*pb.BatchFeatureRow, 0, DataBatchSize), ValueTypes: valueTypes}
 	bufRows := 0
 	for iter.Next() {
 		sRow, err := serializedBatchRow(iter.Entity(), iter.Features())
 		if err != nil {
 			return err
 		}
+		if asOfIter != nil {
+			sRow.AsOf = timestamppb.New(asOfIter.AsOf())
+		}
 		rows.Rows = append(rows.Rows, sRow)
 		bufRows++
 		if bufRows == DataBatchSize {
@@ -723,6 +892,47 @@ func (serv *FeatureServer) BatchFeatureServe(req *pb.BatchFeatureServeRequest, s
 	return nil
 }
 
+// batchFeatureValueTypes returns the value types of the features, in the order
+// they were requested.
+func (serv *FeatureServer) batchFeatureValueTypes(ctx context.Context, ids []provider.ResourceID) ([]*pb.FeatureValueType, error) {
+	nameVariants := make([]metadata.NameVariant, len(ids))
+	for i, id := range ids {
+		nameVariants[i] = metadata.NameVariant{Name: id.Name, Variant: id.Variant}
+	}
+	variants, err := serv.Metadata.GetFeatureVariants(ctx, nameVariants)
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[metadata.NameVariant]*metadata.FeatureVariant, len(variants))
+	for _, fv := range variants {
+		byID[metadata.NameVariant{Name: fv.Name(), Variant: fv.Variant()}] = fv
+	}
+	valueTypes := make([]*pb.FeatureValueType, len(ids))
+	for i, id := range nameVariants {
+		feature, has := byID[id]
+		if !has {
+			return nil, fferr.NewDatasetNotFoundError(id.Name, id.Variant, nil)
+		}
+		valueType, err := feature.Type()
+		if err != nil {
+			return nil, err
+		}
+		valueTypes[i] = featureValueTypeProto(valueType)
+	}
+	return valueTypes, nil
+}
+
+func featureValueTypeProto(valueType types.ValueType) *pb.FeatureValueType {
+	proto := &pb.FeatureValueType{
+		Scalar:   valueType.Scalar().String(),
+		IsVector: valueType.IsVector(),
+	}
+	if vector, isVector := valueType.(types.VectorType); isVector {
+		proto.Dimension = vector.Dimension
+	}
+	return proto
+}
+
 func (serv *FeatureServer) SourceColumns(ctx context.Context, req *pb.SourceColumnRequest) (*pb.SourceDataColumns, error) {
 	id := req.GetId()
 	name, variant := id.GetName(), id.GetVersion()
@@ -820,8 +1030,6 @@ func (serv *FeatureServer) GetResourceLocation(ctx context.Context, req *pb.Reso
 }
 
 func (serv *FeatureServer) getOfflineResourceLocation(ctx context.Context, name, variant string, resourceType int32) (string, error) {
-	var providerEntry *metadata.Provider
-	var resource any
 	switch provider.OfflineResourceType(resourceType) {
 	case provider.Primary, provider.Transformation:
 		serv.Logger.Infow("Getting Source Variant Provider", "name", name, "variant", variant)
@@ -829,7 +1037,6 @@ func (serv *FeatureServer) getOfflineResourceLocation(ctx context.Context, name,
 		if err != nil {
 			return "", err
 		}
-		resource = sv
 
 		primaryLocation, err := sv.GetPrimaryLocation()
 		if err != nil {
@@ -848,18 +1055,48 @@ func (serv *FeatureServer) getOfflineResourceLocation(ctx context.Context, name,
 		}
 
 		return transLocation.Location(), nil
+	}
+	resource, store, err := serv.getOfflineResource(ctx, name, variant, resourceType)
+	if err != nil {
+		return "", err
+	}
+
+	resourceID := provider.ResourceID{Name: name, Variant: variant, Type: provider.OfflineResourceType(resourceType)}
+	fileLocation, err := store.ResourceLocation(resourceID, resource)
+	if err != nil {
+		return "", err
+	}
+	return fileLocation.Location(), nil
+}
 
+// getOfflineResource fetches a resource's metadata along with the offline store that holds it.
+func (serv *FeatureServer) getOfflineResource(ctx context.Context, name, variant string, resourceType int32) (any, provider.OfflineStore, error) {
+	var providerEntry *metadata.Provider
+	var resource any
+	switch provider.OfflineResourceType(resourceType) {
+	case provider.Primary, provider.Transformation:
+		serv.Logger.Infow("Getting Source Variant Provider", "name", name, "variant", variant)
+		sv, err := serv.Metadata.GetSourceVariant(ctx, metadata.NameVariant{Name: name, Variant: variant})
+		if err != nil {
+			return nil, nil, err
+		}
+		providerEntry, err = sv.FetchProvider(serv.Metadata, ctx)
+		if err != nil {
+			serv.Logger.Errorw("Failed to fetch provider", "Error", err)
+			return nil, nil, err
+		}
+		resource = sv
 	case provider.TrainingSet:
 		serv.Logger.Infow("Getting Training Set Provider", "name", name, "variant", variant)
 		ts, err := serv.Metadata.GetTrainingSetVariant(ctx, metadata.NameVariant{Name: name, Variant: variant})
 		if err != nil {
 			serv.Logger.Errorw("Failed to get training set variant", "Error", err)
-			return "", err
+			return nil, nil, err
 		}
 		providerEntry, err = ts.FetchProvider(serv.Metadata, ctx)
 		if err != nil {
 			serv.Logger.Errorw("Failed to fetch provider", "Error", err)
-			return "", err
+			return nil, nil, err
 		}
 		resource = ts
 	case provider.Label:
@@ -867,12 +1104,12 @@ func (serv *FeatureServer) getOfflineResourceLocation(ctx context.Context, name,
 		l, err := serv.Metadata.GetLabelVariant(ctx, metadata.NameVariant{Name: name, Variant: variant})
 		if err != nil {
 			serv.Logger.Errorw("Failed to get label variant", "Error", err)
-			return "", err
+			return nil, nil, err
 		}
 		providerEntry, err = l.FetchProvider(serv.Metadata, ctx)
 		if err != nil {
 			serv.Logger.Errorw("Failed to fetch provider", "Error", err)
-			return "", err
+			return nil, nil, err
 		}
 		resource = l
 	case provider.Feature:
@@ -880,32 +1117,26 @@ func (serv *FeatureServer) getOfflineResourceLocation(ctx context.Context, name,
 		f, err := serv.Metadata.GetFeatureVariant(ctx, metadata.NameVariant{Name: name, Variant: variant})
 		if err != nil {
 			serv.Logger.Errorw("Failed to get feature variant", "Error", err)
-			return "", err
+			return nil, nil, err
 		}
 		providerEntry, err = f.FetchProvider(serv.Metadata, ctx)
 		if err != nil {
 			serv.Logger.Errorw("Failed to fetch provider", "Error", err)
-			return "", err
+			return nil, nil, err
 		}
 		resource = f
 	default:
-		return "", fferr.NewInvalidResourceTypeError(name, variant, fferr.ResourceType(metadata.ResourceType(resourceType).String()), fmt.Errorf("invalid resource type"))
+		return nil, nil, fferr.NewInvalidResourceTypeError(name, variant, fferr.ResourceType(metadata.ResourceType(resourceType).String()), fmt.Errorf("invalid resource type"))
 	}
 	p, err := provider.Get(pt.Type(providerEntry.Type()), providerEntry.SerializedConfig())
 	if err != nil {
-		return "", err
+		return nil, nil, err
 	}
 	store, err := p.AsOfflineStore()
 	if err != nil {
-		return "", err
-	}
-
-	resourceID := provider.ResourceID{Name: name, Variant: variant, Type: provider.OfflineResourceType(resourceType)}
-	fileLocation, err := store.ResourceLocation(resourceID, resource)
-	if err != nil {
-		return "", err
+		return nil, nil, err
 	}
-	return fileLocation.Location(), nil
+	return resource, store, nil
 }
 
 func (serv *FeatureServer) getOnlineResourceLocation(_ context.Context, _, _ string, _ int32) (string, error) {
@@ -19,16 +19,23 @@ import (
 	"math/rand"
 	"net"
 	"reflect"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/featureform/scheduling"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"google.golang.org/protobuf/proto"
+	tspb "google.golang.org/protobuf/types/known/timestamppb"
 
 	"github.com/google/uuid"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
 	grpcmeta "google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/test/bufconn"
 
+	"github.com/featureform/fferr"
 	"github.com/featureform/logging"
 	"github.com/featureform/metadata"
 	"github.com/featureform/metrics"
@@ -42,6 +49,10 @@ import (
 const PythonFunc = `def average_user_transaction(transactions):
 	return transactions.groupby("CustomerID")["TransactionAmount"].mean()`
 
+const OnDemandDefinition = `@ff.ondemand_feature(variant="on-demand")
+def feature_od_expr(client, params, entity):
+    return params[0] * 2 + params[1]`
+
 func simpleFeatureRecords() map[provider.ResourceID][]provider.ResourceRecord {
 	featureId := provider.ResourceID{
 		Name:    "feature",
@@ -448,6 +459,17 @@ func onDemandResourceDefsFn(providerType string) []metadata.ResourceDef {
 			Mode:       metadata.CLIENT_COMPUTED,
 			IsOnDemand: true,
 		},
+		metadata.FeatureDef{
+			Name:    "feature-od-expr",
+			Variant: "on-demand",
+			Owner:   "Featureform",
+			Location: metadata.PythonFunction{
+				Query: []byte(OnDemandDefinition),
+			},
+			Mode:       metadata.CLIENT_COMPUTED,
+			IsOnDemand: true,
+			Definition: OnDemandDefinition,
+		},
 	}
 }
 
@@ -548,6 +570,36 @@ func onlineStoreNoTables(cfg pc.SerializedConfig) (provider.Provider, error) {
 	return store, nil
 }
 
+// untimestampedOnlineStore hides SetIfNewer from the tables of a local store,
+// like the stores that don't keep timestamps.
+type untimestampedOnlineStore struct {
+	provider.OnlineStore
+}
+
+func (store untimestampedOnlineStore) AsOnlineStore() (provider.OnlineStore, error) {
+	return store, nil
+}
+
+func (store untimestampedOnlineStore) GetTable(feature, variant string) (provider.OnlineStoreTable, error) {
+	table, err := store.OnlineStore.GetTable(feature, variant)
+	if err != nil {
+		return nil, err
+	}
+	return struct{ provider.OnlineStoreTable }{table}, nil
+}
+
+func (store untimestampedOnlineStore) CreateTable(feature, variant string, valueType types.ValueType) (provider.OnlineStoreTable, error) {
+	table, err := store.OnlineStore.CreateTable(feature, variant, valueType)
+	if err != nil {
+		return nil, err
+	}
+	return struct{ provider.OnlineStoreTable }{table}, nil
+}
+
+func untimestampedOnlineStoreNoTables(cfg pc.SerializedConfig) (provider.Provider, error) {
+	return untimestampedOnlineStore{provider.NewLocalOnlineStore()}, nil
+}
+
 func startMetadata(t *testing.T, ctx context.Context, logger logging.Logger) (*metadata.MetadataServer, string) {
 	manager, err := scheduling.NewMemoryTaskMetadataManager(ctx)
 	if err != nil {
@@ -686,6 +738,169 @@ func TestFeatureServeMultipleEntities(t *testing.T) {
 	}
 }
 
+type batchGetOnlineStore struct {
+	provider.OnlineStore
+	batchGets *int32
+}
+
+func (store batchGetOnlineStore) AsOnlineStore() (provider.OnlineStore, error) {
+	return store, nil
+}
+
+func (store batchGetOnlineStore) GetTable(feature, variant string) (provider.OnlineStoreTable, error) {
+	table, err := store.OnlineStore.GetTable(feature, variant)
+	if err != nil {
+		return nil, err
+	}
+	return batchGetOnlineTable{OnlineStoreTable: table, batchGets: store.batchGets}, nil
+}
+
+type batchGetOnlineTable struct {
+	provider.OnlineStoreTable
+	batchGets *int32
+}
+
+func (table batchGetOnlineTable) BatchGet(entities []string) ([]interface{}, error) {
+	atomic.AddInt32(table.batchGets, 1)
+	values := make([]interface{}, len(entities))
+	for i, entity := range entities {
+		val, err := table.Get(entity)
+		if err != nil {
+			return nil, err
+		}
+		values[i] = val
+	}
+	return values, nil
+}
+
+func createMockBatchGetOnlineStoreFactory(recsMap map[provider.ResourceID][]provider.ResourceRecord, batchGets *int32) provider.Factory {
+	factory := createMockOnlineStoreFactory(recsMap)
+	return func(cfg pc.SerializedConfig) (provider.Provider, error) {
+		p, err := factory(cfg)
+		if err != nil {
+			return nil, err
+		}
+		store, err := p.AsOnlineStore()
+		if err != nil {
+			return nil, err
+		}
+		return batchGetOnlineStore{OnlineStore: store, batchGets: batchGets}, nil
+	}
+}
+
+func TestBatchGetFeatures(t *testing.T) {
+	var batchGets int32
+	ctx := onlineTestContext{
+		ResourceDefsFn: simpleResourceDefsFn,
+		FactoryFn:      createMockBatchGetOnlineStoreFactory(simpleFeatureRecords(), &batchGets),
+	}
+	serv := ctx.Create(t)
+	defer ctx.Destroy()
+	req := &pb.BatchGetFeaturesRequest{
+		Features: []*pb.FeatureID{
+			{
+				Name:    "feature",
+				Version: "variant",
+			},
+		},
+		Entities: []*pb.Entity{
+			{
+				Name:   "mockEntity",
+				Values: []string{"b", "a", "b"},
+			},
+		},
+	}
+	resp, err := serv.BatchGetFeatures(ctx, req)
+	if err != nil {
+		t.Fatalf("Failed to batch get features: %s", err)
+	}
+	if len(resp.ValueLists) != len(req.Features) {
+		t.Fatalf("Wrong number of value lists: %d\nExpected: %d", len(resp.ValueLists), len(req.Features))
+	}
+	var values []interface{}
+	for _, v := range resp.ValueLists[0].Values {
+		values = append(values, unwrapVal(v))
+	}
+	expectedValues := []interface{}{"def", 12.5, "def"}
+	if !reflect.DeepEqual(values, expectedValues) {
+		t.Fatalf("Wrong feature values: %v\nExpected: %v", values, expectedValues)
+	}
+	if batchGets != 1 {
+		t.Fatalf("Expected a single batch get, got %d", batchGets)
+	}
+	if _, err := serv.BatchGetFeatures(ctx, &pb.BatchGetFeaturesRequest{}); err == nil {
+		t.Fatalf("Expected error when no features are requested")
+	}
+}
+
+func TestFeatureValueTypeProto(t *testing.T) {
+	tests := []struct {
+		name      string
+		valueType types.ValueType
+		expected  *pb.FeatureValueType
+	}{
+		{"Int", types.Int64, &pb.FeatureValueType{Scalar: "int64"}},
+		{"Float", types.Float32, &pb.FeatureValueType{Scalar: "float32"}},
+		{"JSON", types.JSON, &pb.FeatureValueType{Scalar: "json"}},
+		{"Vector", types.VectorType{ScalarType: types.Float32, Dimension: 384}, &pb.FeatureValueType{Scalar: "float32", IsVector: true, Dimension: 384}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if actual := featureValueTypeProto(tt.valueType); !proto.Equal(actual, tt.expected) {
+				t.Fatalf("Expected %v, got %v", tt.expected, actual)
+			}
+		})
+	}
+}
+
+func TestBatchFeatureValueTypes(t *testing.T) {
+	ctx := onlineTestContext{
+		ResourceDefsFn: simpleResourceDefsFn,
+		FactoryFn:      createMockBatchGetOnlineStoreFactory(simpleFeatureRecords(), new(int32)),
+	}
+	serv := ctx.Create(t)
+	defer ctx.Destroy()
+	ids := []provider.ResourceID{
+		{Name: "feature", Variant: "variant2", Type: provider.Feature},
+		{Name: "feature", Variant: "variant", Type: provider.Feature},
+	}
+	valueTypes, err := serv.batchFeatureValueTypes(ctx, ids)
+	if err != nil {
+		t.Fatalf("Failed to get value types: %v", err)
+	}
+	if len(valueTypes) != len(ids) {
+		t.Fatalf("Expected a value type per feature, got %d", len(valueTypes))
+	}
+	missing := []provider.ResourceID{{Name: "feature", Variant: "missing", Type: provider.Feature}}
+	if _, err := serv.batchFeatureValueTypes(ctx, missing); err == nil {
+		t.Fatalf("Expected an error for a feature that doesn't exist")
+	}
+}
+
+func TestGetFeature(t *testing.T) {
+	ctx := onlineTestContext{
+		ResourceDefsFn: simpleResourceDefsFn,
+		FactoryFn:      createMockOnlineStoreFactory(simpleFeatureRecords()),
+	}
+	serv := ctx.Create(t)
+	defer ctx.Destroy()
+	resp, err := serv.GetFeature(ctx, &pb.GetFeatureRequest{Name: "feature", Variant: "variant", Entity: []string{"b", "a"}})
+	if err != nil {
+		t.Fatalf("Failed to get feature: %s", err)
+	}
+	var values []interface{}
+	for _, v := range resp.Values {
+		values = append(values, unwrapVal(v))
+	}
+	expectedValues := []interface{}{"def", 12.5}
+	if !reflect.DeepEqual(values, expectedValues) {
+		t.Fatalf("Wrong feature values: %v\nExpected: %v", values, expectedValues)
+	}
+	if _, err := serv.GetFeature(ctx, &pb.GetFeatureRequest{Name: "feature", Variant: "variant"}); err == nil {
+		t.Fatalf("Expected error when no entities are requested")
+	}
+}
+
 // todo: should be able to delete
 type mockBatchServingStream struct {
 	RowChan    chan *pb.BatchFeatureRow
@@ -909,6 +1124,64 @@ func TestEntityNotFoundInOnlineStore(t *testing.T) {
 	}
 }
 
+func defaultValueResourceDefsFn(providerType string) []metadata.ResourceDef {
+	defs := simpleResourceDefsFn(providerType)
+	for i, def := range defs {
+		if feature, ok := def.(metadata.FeatureDef); ok {
+			feature.Type = types.String
+			if feature.Variant == "variant" {
+				feature.DefaultValue = `"unknown"`
+			}
+			defs[i] = feature
+		}
+	}
+	return defs
+}
+
+func TestDefaultValueForMissingEntity(t *testing.T) {
+	var batchGets int32
+	factories := map[string]provider.Factory{
+		"Get":      createMockOnlineStoreFactory(simpleFeatureRecords()),
+		"BatchGet": createMockBatchGetOnlineStoreFactory(simpleFeatureRecords(), &batchGets),
+	}
+	for name, factory := range factories {
+		t.Run(name, func(t *testing.T) {
+			ctx := onlineTestContext{
+				ResourceDefsFn: defaultValueResourceDefsFn,
+				FactoryFn:      factory,
+			}
+			serv := ctx.Create(t)
+			defer ctx.Destroy()
+			req := &pb.FeatureServeRequest{
+				Features: []*pb.FeatureID{
+					{
+						Name:    "feature",
+						Version: "variant",
+					},
+				},
+				Entities: []*pb.Entity{
+					{
+						Name:   "mockEntity",
+						Values: []string{"a", "NonExistantEntity", "b"},
+					},
+				},
+			}
+			resp, err := serv.FeatureServe(ctx, req)
+			if err != nil {
+				t.Fatalf("Failed to serve feature with a default value: %s", err)
+			}
+			var values []interface{}
+			for _, v := range resp.ValueLists[0].Values {
+				values = append(values, unwrapVal(v))
+			}
+			expectedValues := []interface{}{12.5, "unknown", "def"}
+			if !reflect.DeepEqual(values, expectedValues) {
+				t.Fatalf("Wrong feature values: %v\nExpected: %v", values, expectedValues)
+			}
+		})
+	}
+}
+
 func TestEntityNotInRequest(t *testing.T) {
 	ctx := onlineTestContext{
 		ResourceDefsFn: simpleResourceDefsFn,
@@ -1121,6 +1394,155 @@ func TestOnDemandFeatureServe(t *testing.T) {
 	}
 }
 
+func TestEvaluateOnDemandFeature(t *testing.T) {
+	ctx := onlineTestContext{
+		ResourceDefsFn: onDemandResourceDefsFn,
+		FactoryFn:      createMockOnlineStoreFactory(onDemandFeatureRecords()),
+	}
+	serv := ctx.Create(t)
+	defer ctx.Destroy()
+	req := &pb.OnDemandFeatureRequest{
+		Feature: &pb.FeatureID{Name: "feature-od-expr", Version: "on-demand"},
+		Params: []*pb.Value{
+			{Value: &pb.Value_IntValue{IntValue: 4}},
+			{Value: &pb.Value_DoubleValue{DoubleValue: 0.5}},
+		},
+	}
+	resp, err := serv.EvaluateOnDemandFeature(ctx, req)
+	if err != nil {
+		t.Fatalf("Failed to evaluate on-demand feature: %s", err)
+	}
+	if val := unwrapVal(resp); val != 8.5 {
+		t.Fatalf("Wrong feature value: %v\nExpected: %v", val, 8.5)
+	}
+
+	// The definition of feature-od isn't a simple expression.
+	req.Feature = &pb.FeatureID{Name: "feature-od", Version: "on-demand"}
+	if _, err := serv.EvaluateOnDemandFeature(ctx, req); err == nil {
+		t.Fatalf("Expected an error evaluating an unsupported definition")
+	}
+}
+
+func streamingResourceDefsFn(providerType string) []metadata.ResourceDef {
+	return append(simpleResourceDefsFn(providerType), metadata.FeatureDef{
+		Name:     "feature-stream",
+		Variant:  "variant",
+		Provider: "mockOnline",
+		Entity:   "mockEntity",
+		Source:   metadata.NameVariant{Name: "mockSource", Variant: "var"},
+		Owner:    "Featureform",
+		Location: metadata.Streaming{OfflineProvider: "mockOnline"},
+		Mode:     metadata.STREAMING,
+		Type:     types.Float64,
+	})
+}
+
+type mockWriteFeaturesStream struct {
+	pb.Feature_WriteFeaturesServer
+	ctx      context.Context
+	requests []*pb.WriteFeaturesRequest
+	resp     *pb.WriteFeaturesResponse
+}
+
+func (stream *mockWriteFeaturesStream) Recv() (*pb.WriteFeaturesRequest, error) {
+	if len(stream.requests) == 0 {
+		return nil, io.EOF
+	}
+	req := stream.requests[0]
+	stream.requests = stream.requests[1:]
+	return req, nil
+}
+
+func (stream *mockWriteFeaturesStream) SendAndClose(resp *pb.WriteFeaturesResponse) error {
+	stream.resp = resp
+	return nil
+}
+
+func (stream *mockWriteFeaturesStream) Context() context.Context {
+	return stream.ctx
+}
+
+func memoryOnlineStoreNoTables(cfg pc.SerializedConfig) (provider.Provider, error) {
+	return provider.NewMemoryOnlineStore(), nil
+}
+
+func TestWriteFeatures(t *testing.T) {
+	ctx := onlineTestContext{
+		ResourceDefsFn: streamingResourceDefsFn,
+		FactoryFn:      memoryOnlineStoreNoTables,
+	}
+	serv := ctx.Create(t)
+	defer ctx.Destroy()
+	record := func(entity string, value float64, ts time.Time) *pb.FeatureRecord {
+		return &pb.FeatureRecord{
+			Feature: &pb.FeatureID{Name: "feature-stream", Version: "variant"},
+			Entity:  entity,
+			Value:   &pb.Value{Value: &pb.Value_DoubleValue{DoubleValue: value}},
+			Ts:      tspb.New(ts),
+		}
+	}
+	now := time.Now()
+	stream := &mockWriteFeaturesStream{
+		ctx: ctx,
+		requests: []*pb.WriteFeaturesRequest{
+			{Records: []*pb.FeatureRecord{record("a", 1, now), record("b", 2, now)}},
+			// The older value for a is skipped and the newer one for b wins.
+			{Records: []*pb.FeatureRecord{record("a", 3, now.Add(-time.Minute)), record("b", 4, now.Add(time.Minute))}},
+		},
+	}
+	if err := serv.WriteFeatures(stream); err != nil {
+		t.Fatalf("Failed to write features: %s", err)
+	}
+	if stream.resp.Written != 3 || stream.resp.Skipped != 1 {
+		t.Fatalf("Expected 3 written and 1 skipped, got: %v", stream.resp)
+	}
+	resp, err := serv.FeatureServe(ctx, &pb.FeatureServeRequest{
+		Features: []*pb.FeatureID{{Name: "feature-stream", Version: "variant"}},
+		Entities: []*pb.Entity{{Name: "mockEntity", Values: []string{"a", "b"}}},
+	})
+	if err != nil {
+		t.Fatalf("Failed to serve written feature: %s", err)
+	}
+	values := resp.ValueLists[0].Values
+	if a, b := unwrapVal(values[0]), unwrapVal(values[1]); a != 1.0 || b != 4.0 {
+		t.Fatalf("Wrong feature values: %v, %v\nExpected: 1, 4", a, b)
+	}
+
+	// Materialized features can't be written directly.
+	stream = &mockWriteFeaturesStream{
+		ctx: ctx,
+		requests: []*pb.WriteFeaturesRequest{{Records: []*pb.FeatureRecord{{
+			Feature: &pb.FeatureID{Name: "feature", Version: "variant"},
+			Entity:  "a",
+			Value:   &pb.Value{Value: &pb.Value_DoubleValue{DoubleValue: 1}},
+		}}}},
+	}
+	if err := serv.WriteFeatures(stream); err == nil {
+		t.Fatalf("Expected an error writing a precomputed feature")
+	}
+}
+
+func TestWriteFeaturesRequiresTimestamps(t *testing.T) {
+	ctx := onlineTestContext{
+		ResourceDefsFn: streamingResourceDefsFn,
+		FactoryFn:      untimestampedOnlineStoreNoTables,
+	}
+	serv := ctx.Create(t)
+	defer ctx.Destroy()
+	stream := &mockWriteFeaturesStream{
+		ctx: ctx,
+		requests: []*pb.WriteFeaturesRequest{{Records: []*pb.FeatureRecord{{
+			Feature: &pb.FeatureID{Name: "feature-stream", Version: "variant"},
+			Entity:  "a",
+			Value:   &pb.Value{Value: &pb.Value_DoubleValue{DoubleValue: 1}},
+		}}}},
+	}
+	err := serv.WriteFeatures(stream)
+	if _, ok := err.(*fferr.UnimplementedError); !ok {
+		t.Fatalf("Expected an unimplemented error writing to a store without timestamps, got %T: %v", err, err)
+	}
+}
+
 type mockTrainingStream struct {
 	RowChan    chan *pb.TrainingDataRows
 	ShouldFail bool
@@ -1163,6 +1585,61 @@ func (stream *mockTrainingStream) RecvMsg(interface{}) error {
 	return nil
 }
 
+func TestGetResourceStats(t *testing.T) {
+	ctx := onlineTestContext{
+		ResourceDefsFn: simpleResourceDefsFn,
+		FactoryFn:      createMockOfflineStoreFactory(simpleFeatureRecords(), simpleTrainingSetDefs()),
+	}
+	serv := ctx.Create(t)
+	defer ctx.Destroy()
+	id := provider.ResourceID{Name: "label", Variant: "variant", Type: provider.Label}
+	req := &pb.ResourceIdRequest{Name: id.Name, Variant: id.Variant, Type: int32(id.Type)}
+	stats, err := serv.GetResourceStats(ctx, req)
+	if err != nil {
+		t.Fatalf("Failed to get resource stats: %s", err)
+	}
+	if stats.RowCount != 2 {
+		t.Fatalf("Unexpected resource stats: %v", stats)
+	}
+	// Cached stats are served until they expire.
+	serv.Stats.Store(id, &cachedResourceStats{stats: &pb.ResourceStats{RowCount: 5}, expires: time.Now().Add(time.Minute)})
+	if stats, err := serv.GetResourceStats(ctx, req); err != nil {
+		t.Fatalf("Failed to get resource stats: %s", err)
+	} else if stats.RowCount != 5 {
+		t.Fatalf("Expected cached row count 5, got %d", stats.RowCount)
+	}
+	serv.Stats.Store(id, &cachedResourceStats{stats: &pb.ResourceStats{RowCount: 5}, expires: time.Now().Add(-time.Second)})
+	if stats, err := serv.GetResourceStats(ctx, req); err != nil {
+		t.Fatalf("Failed to get resource stats: %s", err)
+	} else if stats.RowCount != 2 {
+		t.Fatalf("Expected refreshed row count 2, got %d", stats.RowCount)
+	}
+}
+
+func TestExportTrainingSet(t *testing.T) {
+	ctx := onlineTestContext{
+		ResourceDefsFn: simpleResourceDefsFn,
+		FactoryFn:      createMockOfflineStoreFactory(simpleFeatureRecords(), simpleTrainingSetDefs()),
+	}
+	serv := ctx.Create(t)
+	defer ctx.Destroy()
+	id := &pb.TrainingDataID{Name: "training-set", Version: "variant"}
+	export := func(location, format string) error {
+		_, err := serv.ExportTrainingSet(ctx, &pb.ExportTrainingSetRequest{Id: id, Location: location, Format: format})
+		return err
+	}
+	if _, ok := export("s3://bucket/exports", "avro").(*fferr.InvalidArgumentError); !ok {
+		t.Fatalf("Expected an invalid argument error for an unsupported format")
+	}
+	if _, ok := export("/tmp/exports", "parquet").(*fferr.InvalidArgumentError); !ok {
+		t.Fatalf("Expected an invalid argument error for a location that isn't a file store URI")
+	}
+	// The in-memory offline store can't export, which is passed on.
+	if _, ok := export("s3://bucket/exports", "").(*fferr.UnimplementedError); !ok {
+		t.Fatalf("Expected the offline store's unimplemented error")
+	}
+}
+
 func TestSimpleTrainingSetServe(t *testing.T) {
 	ctx := onlineTestContext{
 		ResourceDefsFn: simpleResourceDefsFn,
@@ -1219,6 +1696,57 @@ func TestSimpleTrainingSetServe(t *testing.T) {
 	}
 }
 
+func TestTrainingSetServeSplit(t *testing.T) {
+	defs := simpleTrainingSetDefs()
+	defs[0].Split = &provider.TrainingSetSplit{TrainFraction: 0.5, Seed: 1}
+	ctx := onlineTestContext{
+		ResourceDefsFn: simpleResourceDefsFn,
+		FactoryFn:      createMockOfflineStoreFactory(simpleFeatureRecords(), defs),
+	}
+	serv := ctx.Create(t)
+	defer ctx.Destroy()
+	serve := func(split string) (int, error) {
+		req := &pb.TrainingDataRequest{
+			Id:    &pb.TrainingDataID{Name: "training-set", Version: "variant"},
+			Split: split,
+		}
+		stream := newMockTrainingStream()
+		errChan := make(chan error)
+		go func() {
+			errChan <- serv.TrainingData(req, stream)
+		}()
+		numRows := 0
+		for {
+			select {
+			case rows := <-stream.RowChan:
+				numRows += len(rows.Rows)
+			case err := <-errChan:
+				return numRows, err
+			}
+		}
+	}
+	all, err := serve("")
+	if err != nil {
+		t.Fatalf("Failed to serve training set: %s", err)
+	}
+	train, err := serve(provider.TrainingSetSplitTrain)
+	if err != nil {
+		t.Fatalf("Failed to serve train split: %s", err)
+	}
+	test, err := serve(provider.TrainingSetSplitTest)
+	if err != nil {
+		t.Fatalf("Failed to serve test split: %s", err)
+	}
+	if all == 0 || train+test != all {
+		t.Fatalf("Expected the %d rows to be split between train and test, got %d and %d", all, train, test)
+	}
+	if _, err := serve("validation"); err == nil {
+		t.Fatalf("Expected an unknown split to fail")
+	} else if _, ok := err.(*fferr.InvalidArgumentError); !ok {
+		t.Fatalf("Expected an invalid argument error, got %T: %s", err, err)
+	}
+}
+
 func TestTrainingSetNotFound(t *testing.T) {
 	ctx := onlineTestContext{
 		ResourceDefsFn: simpleResourceDefsFn,
@@ -1580,3 +2108,151 @@ func TestTrainTestSplit_DataRequest(t *testing.T) {
 	assert.NotEmpty(t, mockTrainTestSplitServer.Responses)
 	assert.Equal(t, pb.RequestType_INITIALIZE, mockTrainTestSplitServer.Responses[0].RequestType)
 }
+
+type syntheticTrainingSetIterator struct {
+	rows, idx int
+}
+
+func (it *syntheticTrainingSetIterator) Next() bool {
+	it.idx++
+	return it.idx <= it.rows
+}
+
+func (it *syntheticTrainingSetIterator) Features() []interface{} {
+	return []interface{}{float64(it.idx), "entity"}
+}
+
+func (it *syntheticTrainingSetIterator) Label() interface{} {
+	return it.idx%2 == 0
+}
+
+func (it *syntheticTrainingSetIterator) Labels() []interface{} {
+	return []interface{}{it.Label()}
+}
+
+func (it *syntheticTrainingSetIterator) Err() error {
+	return nil
+}
+
+type collectingTrainingStream struct {
+	mockTrainingStream
+	batches []int
+}
+
+func (stream *collectingTrainingStream) Send(rows *pb.TrainingDataRows) error {
+	stream.batches = append(stream.batches, len(rows.Rows))
+	return nil
+}
+
+func TestTrainingDataBatchSize(t *testing.T) {
+	logger := logging.NewTestLogger(t)
+	tests := map[string]struct {
+		BatchSize uint32
+		Rows      int
+		Expected  []int
+	}{
+		"Default":   {0, DataBatchSize + 1, []int{DataBatchSize, 1}},
+		"SingleRow": {1, 3, []int{1, 1, 1}},
+		"Custom":    {2, 5, []int{2, 2, 1}},
+		"Empty":     {2, 0, nil},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			batchSize, err := trainingDataBatchSize(&pb.TrainingDataRequest{BatchSize: test.BatchSize})
+			if err != nil {
+				t.Fatalf("Failed to get batch size: %s", err)
+			}
+			stream := &collectingTrainingStream{}
+			iter := &syntheticTrainingSetIterator{rows: test.Rows}
+			if err := sendTrainingRows(stream, iter, batchSize, &metrics.NoOpFeatureObserver{}, logger); err != nil {
+				t.Fatalf("Failed to send training rows: %s", err)
+			}
+			if !reflect.DeepEqual(stream.batches, test.Expected) {
+				t.Fatalf("Expected batches %v, got %v", test.Expected, stream.batches)
+			}
+		})
+	}
+	if _, err := trainingDataBatchSize(&pb.TrainingDataRequest{BatchSize: MaxTrainingDataBatchSize + 1}); err == nil {
+		t.Fatalf("Expected batch size over the max to fail")
+	}
+}
+
+type benchmarkTrainingServer struct {
+	pb.UnimplementedFeatureServer
+	rows   int
+	logger logging.Logger
+}
+
+func (serv *benchmarkTrainingServer) TrainingData(req *pb.TrainingDataRequest, stream pb.Feature_TrainingDataServer) error {
+	batchSize, err := trainingDataBatchSize(req)
+	if err != nil {
+		return err
+	}
+	iter := &syntheticTrainingSetIterator{rows: serv.rows}
+	return sendTrainingRows(stream, iter, batchSize, &metrics.NoOpFeatureObserver{}, serv.logger)
+}
+
+// BenchmarkTrainingDataBatchSize streams a million row training set over an
+// in-process gRPC connection at different batch sizes.
+func BenchmarkTrainingDataBatchSize(b *testing.B) {
+	const rows = 1_000_000
+	lis := bufconn.Listen(1 << 20)
+	server := grpc.NewServer()
+	pb.RegisterFeatureServer(server, &benchmarkTrainingServer{rows: rows, logger: logging.NewLogger("benchmark")})
+	go server.Serve(lis)
+	defer server.Stop()
+	conn, err := grpc.NewClient(
+		"passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return lis.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		b.Fatalf("Failed to connect: %s", err)
+	}
+	defer conn.Close()
+	client := pb.NewFeatureClient(conn)
+	for _, batchSize := range []uint32{1, 64, DataBatchSize} {
+		b.Run(fmt.Sprintf("BatchSize%d", batchSize), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				stream, err := client.TrainingData(context.Background(), &pb.TrainingDataRequest{BatchSize: batchSize})
+				if err != nil {
+					b.Fatalf("Failed to start stream: %s", err)
+				}
+				received := 0
+				for {
+					batch, err := stream.Recv()
+					if err == io.EOF {
+						break
+					} else if err != nil {
+						b.Fatalf("Failed to receive rows: %s", err)
+					}
+					received += len(batch.Rows)
+				}
+				if received != rows {
+					b.Fatalf("Expected %d rows, got %d", rows, received)
+				}
+			}
+			b.ReportMetric(float64(rows*b.N)/b.Elapsed().Seconds(), "rows/s")
+		})
+	}
+}
+
+// noAsOfOfflineStore hides GetBatchFeaturesAsOf from an offline store, like the
+// stores that can't serve batch features as of a timestamp.
+type noAsOfOfflineStore struct {
+	provider.OfflineStore
+}
+
+func TestAsOfBatchFeatureStore(t *testing.T) {
+	store := provider.NewMemoryOfflineStore()
+	if _, err := asOfBatchFeatureStore(store); err != nil {
+		t.Fatalf("Expected the in-memory store to serve batch features as of a timestamp: %v", err)
+	}
+	if _, err := asOfBatchFeatureStore(noAsOfOfflineStore{store}); err == nil {
+		t.Fatalf("Expected an error for a store that can't serve batch features as of a timestamp")
+	} else if _, ok := err.(*fferr.UnimplementedError); !ok {
+		t.Fatalf("Expected an UnimplementedError, got: %v", err)
+	}
+}
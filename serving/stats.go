@@ -0,0 +1,68 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+//
+// Copyright 2024 FeatureForm Inc.
+//
+
+package serving
+
+import (
+	"context"
+	"time"
+
+	pb "github.com/featureform/proto"
+	"github.com/featureform/provider"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// ResourceStatsTTL is how long resource stats are cached before the offline
+// store is queried again. Stats are only used to flag stale resources, so they
+// don't need to be exact.
+const ResourceStatsTTL = time.Minute
+
+type cachedResourceStats struct {
+	stats   *pb.ResourceStats
+	expires time.Time
+}
+
+// GetResourceStats returns the row count and newest timestamp of a feature,
+// label, source, or training set in its offline store.
+func (serv *FeatureServer) GetResourceStats(ctx context.Context, req *pb.ResourceIdRequest) (*pb.ResourceStats, error) {
+	name, variant, resourceType := req.GetName(), req.GetVariant(), req.GetType()
+	logger := serv.Logger.With("Name", name, "Variant", variant, "Type", resourceType)
+	id := provider.ResourceID{Name: name, Variant: variant, Type: provider.OfflineResourceType(resourceType)}
+	if stats, ok := serv.cachedResourceStats(id); ok {
+		logger.Debug("Serving cached resource stats")
+		return stats, nil
+	}
+	logger.Info("Getting resource stats")
+	resource, store, err := serv.getOfflineResource(ctx, name, variant, resourceType)
+	if err != nil {
+		return nil, err
+	}
+	stats, err := store.GetResourceStats(id, resource)
+	if err != nil {
+		logger.Errorw("Failed to get resource stats", "error", err)
+		return nil, err
+	}
+	resp := &pb.ResourceStats{RowCount: stats.RowCount}
+	if !stats.MaxTimestamp.IsZero() {
+		resp.MaxTimestamp = timestamppb.New(stats.MaxTimestamp)
+	}
+	serv.Stats.Store(id, &cachedResourceStats{stats: resp, expires: time.Now().Add(ResourceStatsTTL)})
+	return resp, nil
+}
+
+func (serv *FeatureServer) cachedResourceStats(id provider.ResourceID) (*pb.ResourceStats, bool) {
+	cached, ok := serv.Stats.Load(id)
+	if !ok {
+		return nil, false
+	}
+	entry := cached.(*cachedResourceStats)
+	if time.Now().After(entry.expires) {
+		serv.Stats.Delete(id)
+		return nil, false
+	}
+	return entry.stats, true
+}
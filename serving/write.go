@@ -0,0 +1,129 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+//
+// Copyright 2024 FeatureForm Inc.
+//
+
+package serving
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/featureform/fferr"
+	"github.com/featureform/metadata"
+	"github.com/featureform/metrics"
+	pb "github.com/featureform/proto"
+	"github.com/featureform/provider"
+)
+
+// WriteFeatures writes streamed records straight to the online store,
+// bypassing materialization. The latest timestamp wins: a record older than
+// the value stored for its feature and entity is skipped. Timestamps are kept
+// by the inference store, so this holds across servers, and only stores that
+// keep them can be written to.
+func (serv *FeatureServer) WriteFeatures(stream pb.Feature_WriteFeaturesServer) error {
+	ctx := context.WithValue(stream.Context(), observer{}, metrics.FeatureObserver(&metrics.NoOpFeatureObserver{}))
+	logger := serv.Logger.WithRequestIDFromContext(ctx)
+	logger.Info("Opened Write Features stream")
+	resp := &pb.WriteFeaturesResponse{}
+	for {
+		req, err := stream.Recv()
+		if err == io.EOF {
+			logger.Infow("Write Features stream closed", "written", resp.Written, "skipped", resp.Skipped)
+			return stream.SendAndClose(resp)
+		}
+		if err != nil {
+			logger.Errorw("Failed to receive from write features stream", "error", err)
+			return fferr.NewInternalError(err)
+		}
+		for _, record := range req.GetRecords() {
+			written, err := serv.writeFeatureRecord(ctx, record)
+			if err != nil {
+				logger.Errorw("Failed to write feature record", "feature", record.GetFeature().GetName(), "variant", record.GetFeature().GetVersion(), "entity", record.GetEntity(), "error", err)
+				return err
+			}
+			if written {
+				resp.Written++
+			} else {
+				resp.Skipped++
+			}
+		}
+	}
+}
+
+func (serv *FeatureServer) writeFeatureRecord(ctx context.Context, record *pb.FeatureRecord) (bool, error) {
+	name, variant := record.GetFeature().GetName(), record.GetFeature().GetVersion()
+	if record.GetEntity() == "" {
+		return false, fferr.NewInvalidArgumentErrorf("record for feature %s (%s) is missing an entity", name, variant)
+	}
+	meta, err := serv.getOrCacheFeatureMetadata(ctx, name, variant)
+	if err != nil {
+		return false, err
+	}
+	if mode := meta.Mode(); mode != metadata.STREAMING && mode != metadata.CLIENT_COMPUTED {
+		return false, fferr.NewInvalidArgumentErrorf("feature %s (%s) is written by materialization and can't be written directly", name, variant)
+	}
+	if meta.Provider() == "" {
+		return false, fferr.NewInvalidArgumentError(fmt.Errorf("feature %s:%s is not saved in an inference store", name, variant))
+	}
+	value, err := unwrapValue(record.GetValue())
+	if err != nil {
+		return false, err
+	}
+	ts := time.Now().UTC()
+	if record.GetTs() != nil {
+		ts = record.GetTs().AsTime()
+	}
+	table, err := serv.getOrCreateWriteTable(ctx, meta)
+	if err != nil {
+		return false, err
+	}
+	timestamped, ok := table.(provider.TimestampedOnlineTable)
+	if !ok {
+		return false, fferr.NewUnimplementedErrorf("the inference store of feature %s (%s) doesn't keep timestamps, so it can't be written to directly", name, variant)
+	}
+	return timestamped.SetIfNewer(record.GetEntity(), value, ts)
+}
+
+// getOrCreateWriteTable creates the feature's table if nothing has been
+// written to it yet, and applies the feature's TTL.
+func (serv *FeatureServer) getOrCreateWriteTable(ctx context.Context, meta *metadata.FeatureVariant) (provider.OnlineStoreTable, error) {
+	name, variant := meta.Name(), meta.Variant()
+	if table, has := serv.Tables.Load(serv.getNVCacheKey(name, variant)); has {
+		return table.(provider.OnlineStoreTable), nil
+	}
+	store, err := serv.getOrCacheFeatureProvider(ctx, meta)
+	if err != nil {
+		return nil, err
+	}
+	table, err := store.GetTable(name, variant)
+	if _, notFound := err.(*fferr.DatasetNotFoundError); notFound {
+		valueType, typeErr := meta.Type()
+		if typeErr != nil {
+			return nil, typeErr
+		}
+		table, err = store.CreateTable(name, variant, valueType)
+		// Another writer may have created it first.
+		if _, exists := err.(*fferr.DatasetAlreadyExistsError); exists {
+			table, err = store.GetTable(name, variant)
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	if ttl := meta.TTL(); ttl > 0 {
+		expiring, ok := table.(provider.ExpiringOnlineTable)
+		if !ok {
+			return nil, fferr.NewInvalidArgumentErrorf("feature %s (%s) has a TTL but its inference store doesn't support one", name, variant)
+		}
+		if table, err = expiring.WithTTL(ttl); err != nil {
+			return nil, err
+		}
+	}
+	serv.Tables.Store(serv.getNVCacheKey(name, variant), table)
+	return table, nil
+}
@@ -9,6 +9,8 @@ package storage
 
 import (
 	"fmt"
+	"slices"
+	"sort"
 	"strings"
 	"sync"
 
@@ -63,7 +65,49 @@ func (m *memoryStorageImplementation) List(prefix string, opts ...query.Query) (
 		return true
 	})
 
-	return result, nil
+	return applyMemoryPage(result, opts), nil
+}
+
+// applyMemoryPage pages through the results by key when a limit or a key to
+// start after is set. Other query options aren't supported by memory storage.
+func applyMemoryPage(result map[string]string, opts []query.Query) map[string]string {
+	var limit *query.Limit
+	var after *query.KeyAfter
+	dir := query.Asc
+	for _, opt := range opts {
+		switch casted := opt.(type) {
+		case query.Limit:
+			limit = &casted
+		case query.KeyAfter:
+			after = &casted
+		case query.KeySort:
+			dir = casted.Direction()
+		}
+	}
+	if limit == nil && after == nil {
+		return result
+	}
+	keys := make([]string, 0, len(result))
+	for key := range result {
+		if after == nil || key > after.Key {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	if dir == query.Desc {
+		slices.Reverse(keys)
+	}
+	if limit != nil {
+		keys = keys[min(limit.Offset, len(keys)):]
+		if limit.Limit > 0 && limit.Limit < len(keys) {
+			keys = keys[:limit.Limit]
+		}
+	}
+	paged := make(map[string]string, len(keys))
+	for _, key := range keys {
+		paged[key] = result[key]
+	}
+	return paged
 }
 
 func (m *memoryStorageImplementation) ListColumn(prefix string, columns []query.Column, opts ...query.Query) ([]map[string]interface{}, error) {
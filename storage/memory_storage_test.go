@@ -7,7 +7,12 @@
 
 package storage
 
-import "testing"
+import (
+	"reflect"
+	"testing"
+
+	"github.com/featureform/storage/query"
+)
 
 func TestMemoryMetadataStorage(t *testing.T) {
 	storage, err := NewMemoryStorageImplementation()
@@ -21,3 +26,59 @@ func TestMemoryMetadataStorage(t *testing.T) {
 	}
 	test.Run()
 }
+
+func TestMemoryStorageListLimit(t *testing.T) {
+	storage, err := NewMemoryStorageImplementation()
+	if err != nil {
+		t.Fatalf("Failed to create Memory storage: %v", err)
+	}
+	for _, key := range []string{"list/c", "list/a", "list/d", "list/b", "other/e"} {
+		if err := storage.Set(key, key); err != nil {
+			t.Fatalf("Failed to set key %s: %v", key, err)
+		}
+	}
+	tests := map[string]struct {
+		Opts     []query.Query
+		Expected map[string]string
+	}{
+		"NoLimit": {
+			Opts:     []query.Query{query.KeySort{}},
+			Expected: map[string]string{"list/a": "list/a", "list/b": "list/b", "list/c": "list/c", "list/d": "list/d"},
+		},
+		"FirstPage": {
+			Opts:     []query.Query{query.Limit{Limit: 2}},
+			Expected: map[string]string{"list/a": "list/a", "list/b": "list/b"},
+		},
+		"Offset": {
+			Opts:     []query.Query{query.KeySort{}, query.Limit{Limit: 2, Offset: 3}},
+			Expected: map[string]string{"list/d": "list/d"},
+		},
+		"Descending": {
+			Opts:     []query.Query{query.KeySort{Dir: query.Desc}, query.Limit{Limit: 2}},
+			Expected: map[string]string{"list/d": "list/d", "list/c": "list/c"},
+		},
+		"AfterKey": {
+			Opts:     []query.Query{query.KeySort{}, query.KeyAfter{Key: "list/b"}, query.Limit{Limit: 1}},
+			Expected: map[string]string{"list/c": "list/c"},
+		},
+		"AfterDeletedKey": {
+			Opts:     []query.Query{query.KeySort{}, query.KeyAfter{Key: "list/bb"}},
+			Expected: map[string]string{"list/c": "list/c", "list/d": "list/d"},
+		},
+		"PastEnd": {
+			Opts:     []query.Query{query.Limit{Limit: 2, Offset: 10}},
+			Expected: map[string]string{},
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			result, err := storage.List("list/", test.Opts...)
+			if err != nil {
+				t.Fatalf("Failed to list keys: %v", err)
+			}
+			if !reflect.DeepEqual(result, test.Expected) {
+				t.Fatalf("Expected %v, got %v", test.Expected, result)
+			}
+		})
+	}
+}
@@ -155,6 +155,13 @@ func (s *MetadataStorage) Count(prefix string, opts ...query.Query) (int, error)
 	return s.Storage.Count(prefix, opts...)
 }
 
+// Ping checks that the storage backend is reachable. It skips locking since
+// it only needs a round trip, not a consistent read.
+func (s *MetadataStorage) Ping() error {
+	_, err := s.Storage.Count(storagePingPrefix)
+	return err
+}
+
 func (s *MetadataStorage) Get(key string, opts ...query.Query) (string, error) {
 	ctx := context.Background()
 	reqID := uuid.NewString()
@@ -201,6 +208,9 @@ func (s *MetadataStorage) Close() {
 	s.Storage.Close()
 }
 
+// storagePingPrefix doesn't match any keys, so counting it is cheap.
+const storagePingPrefix = "/__ping__/"
+
 type MetadataStorageType string
 
 const (
@@ -18,6 +18,16 @@ func (qry KeyPrefix) Category() Category {
 	return FilterQuery
 }
 
+// KeyAfter filters to the keys that sort strictly after Key. It lets a list
+// resume after the last key of the previous page.
+type KeyAfter struct {
+	Key string
+}
+
+func (qry KeyAfter) Category() Category {
+	return FilterQuery
+}
+
 type ValueEquals struct {
 	Not    bool
 	Column Column
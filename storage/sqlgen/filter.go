@@ -40,6 +40,8 @@ func compileFilter(filter query.Query, argNum int) (string, []any, error) {
 	switch casted := filter.(type) {
 	case query.KeyPrefix:
 		return compileKeyPrefix(casted, argNum)
+	case query.KeyAfter:
+		return compileKeyAfter(casted, argNum)
 	case query.ValueEquals:
 		return compileValueEquals(casted, argNum)
 	case query.ValueIn:
@@ -71,6 +73,14 @@ func compileKeyPrefix(filter query.KeyPrefix, argNum int) (string, []any, error)
 	return fmt.Sprintf("key %s %s", operation, argStr), []any{filter.Prefix + "%"}, nil
 }
 
+func compileKeyAfter(filter query.KeyAfter, argNum int) (string, []any, error) {
+	argStr, err := compileArgNum(argNum)
+	if err != nil {
+		return "", nil, err
+	}
+	return fmt.Sprintf("key > %s", argStr), []any{filter.Key}, nil
+}
+
 func compileValueEquals(qry query.ValueEquals, argNum int) (string, []any, error) {
 	argStr, err := compileArgNum(argNum)
 	if err != nil {
@@ -140,6 +140,11 @@ func TestCompileFilter(t *testing.T) {
 			Expected:    "key NOT LIKE $1",
 			ExpectedArg: []any{"LABEL__%"},
 		},
+		"key after": {
+			Filter:      query.KeyAfter{Key: "FEATURE__a"},
+			Expected:    "key > $1",
+			ExpectedArg: []any{"FEATURE__a"},
+		},
 		"JSON value equals": {
 			Filter: query.ValueEquals{
 				Column: query.JSONColumn{